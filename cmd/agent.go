@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,12 +11,14 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	"github.com/joescharf/pm/internal/agent"
 	"github.com/joescharf/pm/internal/git"
 	"github.com/joescharf/pm/internal/models"
 	"github.com/joescharf/pm/internal/output"
 	"github.com/joescharf/pm/internal/sessions"
+	"github.com/joescharf/pm/internal/sla"
 	"github.com/joescharf/pm/internal/store"
 	"github.com/joescharf/pm/internal/wt"
 	"github.com/joescharf/wt/pkg/lifecycle"
@@ -23,14 +27,29 @@ import (
 var (
 	agentIssue   string
 	agentBranch  string
+	agentSnapshot bool
+	agentInline  bool
+	agentSparse      bool
+	agentSparsePaths []string
 	agentLimit   int
 	closeDone    bool
 	closeAbandon bool
+	closeForce   bool
+	closeCheckOnly bool
+	closeJSON      bool
 	syncRebase   bool
 	syncForce    bool
 	mergeRebase    bool
+	mergeStrategy  string
 	mergeForce     bool
 	mergeNoCleanup bool
+	mergeDelRemote bool
+	agentStatus    string
+	agentLabels    []string
+	agentLabel     string
+	agentByLabel   bool
+	blockReason    string
+	blockCondition string
 )
 
 var agentCmd = &cobra.Command{
@@ -43,11 +62,26 @@ var agentCmd = &cobra.Command{
 }
 
 var agentLaunchCmd = &cobra.Command{
-	Use:   "launch <project>",
-	Short: "Launch a Claude agent in a new worktree",
-	Args:  cobra.ExactArgs(1),
+	Use:     "launch [project]",
+	Aliases: []string{"start"},
+	Short:   "Launch a Claude agent in a new worktree",
+	Long: `Launch a Claude agent in a new worktree for --branch, or for a branch named
+after --issue. Resumes the branch's idle session if there is one.
+
+With --inline, no worktree is created: the session tracks the project repo
+itself on its current branch, so work done directly on main still gets
+session tracking, time and issue cascade. Inline sessions are skipped by
+worktree reconciliation and cannot be synced, merged or have their worktree
+deleted.
+
+With --sparse, or --sparse-path, or when the project has sparse paths set
+('pm project sparse'), a new worktree checks out only those directories and
+the ones the issue mentions. Useful for huge repos.
+
+The project defaults to the --issue's project, then to the current directory.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return agentLaunchRun(args[0])
+		return agentLaunchRun(argOrEmpty(args))
 	},
 }
 
@@ -55,7 +89,13 @@ var agentListCmd = &cobra.Command{
 	Use:     "list [project]",
 	Aliases: []string{"ls"},
 	Short:   "List active agent sessions",
-	Args:    cobra.MaximumNArgs(1),
+	Long: `List active and idle agent sessions. Blocked sessions are hidden unless
+--status includes blocked, but sessions blocked longer than
+sessions.blocked.remind_after are always called out below the table.
+
+With --watch, redraws the table every --interval with the cells that changed
+highlighted, as pm status --watch does.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var projectRef string
 		if len(args) > 0 {
@@ -68,13 +108,22 @@ var agentListCmd = &cobra.Command{
 var agentHistoryCmd = &cobra.Command{
 	Use:   "history [project]",
 	Short: "Show agent session history",
+	Long: `Show past and current agent sessions, newest first, with their focus
+time: the time between heartbeats (hook calls, MCP tool calls, worktree file
+changes) at most 5 minutes apart. The rest of a session's duration, up to now
+for open sessions, is idle time.
+
+With --by-label, summarize the sessions per label instead: how many ran,
+completed or were abandoned, their commits and average run time. A session
+counts under each of its labels; sessions without labels are grouped as
+(none). Use it to compare experimental agent workflows.`,
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var projectRef string
 		if len(args) > 0 {
 			projectRef = args[0]
 		}
-		return agentHistoryRun(projectRef)
+		return agentHistoryRun(projectRef, cmd.Flags().Changed("limit"))
 	},
 }
 
@@ -86,17 +135,51 @@ Use --done to mark completed (issues → done) or --abandon to abandon (issues 
 
 When no session_id is given:
   - In a worktree directory: closes the session for that worktree
-  - In a project directory: lists active/idle sessions to choose from`,
+  - In a project directory: lists active/idle sessions to choose from
+
+Use --check-only to run the close-check without closing, for CI jobs and
+scripts. The exit code gives the session's state: 0 clean, 2 uncommitted
+changes, 3 commits not merged to main, 4 a sync or merge conflict (the most
+severe wins); 1 is any other error. --json prints the check, or the closed
+session, as JSON.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var sessionRef string
 		if len(args) > 0 {
 			sessionRef = args[0]
 		}
+		if closeCheckOnly {
+			return agentCloseCheckRun(sessionRef)
+		}
 		return agentCloseRun(sessionRef)
 	},
 }
 
+var agentBlockCmd = &cobra.Command{
+	Use:   "block [session_id]",
+	Short: "Mark a session as blocked on something outside it",
+	Long: `Mark an active or idle session as blocked, e.g. waiting on a design, an API
+key or an answer. The worktree is kept and the issue stays in progress, but
+the session drops out of the default session list and the next-task ranking.
+pm reminds you about sessions blocked longer than sessions.blocked.remind_after.
+
+Auto-detects the session from cwd if no session_id is given.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return agentBlockRun(argOrEmpty(args))
+	},
+}
+
+var agentUnblockCmd = &cobra.Command{
+	Use:   "unblock [session_id]",
+	Short: "Return a blocked session to idle",
+	Long:  "Clears a session's blocked reason and returns it to idle so it can be resumed.\nAuto-detects the session from cwd if no session_id is given.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return agentUnblockRun(argOrEmpty(args))
+	},
+}
+
 var agentSyncCmd = &cobra.Command{
 	Use:   "sync [session_id]",
 	Short: "Sync a session's worktree with the base branch",
@@ -114,8 +197,16 @@ var agentSyncCmd = &cobra.Command{
 var agentMergeCmd = &cobra.Command{
 	Use:   "merge [session_id]",
 	Short: "Merge a session's branch into the base branch",
-	Long:  "Merges or rebases the feature branch into the base branch (default: main).\nAuto-detects session from cwd if no session_id is given.",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Merges the feature branch into the base branch (default: main) with
+--strategy, or the project's default strategy ('pm project merge-strategy'):
+
+  merge     merge commit (the default)
+  rebase    rebase onto the base branch, then fast-forward (same as --rebase)
+  squash    one commit whose message lists the branch's issues and commits
+  ff-only   fast-forward only; refused when the branch is behind the base
+
+Auto-detects session from cwd if no session_id is given.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var sessionRef string
 		if len(args) > 0 {
@@ -142,23 +233,46 @@ var agentDiscoverCmd = &cobra.Command{
 func init() {
 	agentLaunchCmd.Flags().StringVar(&agentIssue, "issue", "", "Issue ID to work on")
 	agentLaunchCmd.Flags().StringVar(&agentBranch, "branch", "", "Branch name (auto-generated from issue if not specified)")
+	agentLaunchCmd.Flags().BoolVar(&agentSnapshot, "snapshot", false, "Commit edits made in an idle worktree before resuming, without asking")
+	agentLaunchCmd.Flags().BoolVar(&agentInline, "inline", false, "Track a session in the project repo on its current branch, without a worktree")
+	agentLaunchCmd.Flags().BoolVar(&agentSparse, "sparse", false, "Create a sparse-checkout worktree of the project's sparse paths and the paths the issue mentions")
+	agentLaunchCmd.Flags().StringSliceVar(&agentSparsePaths, "sparse-path", nil, "Directory to add to a sparse-checkout worktree (implies --sparse; repeatable)")
+
+	agentLaunchCmd.Flags().StringSliceVar(&agentLabels, "label", nil, "Label the session, e.g. experiment or hotfix (repeatable)")
+
+	agentListCmd.Flags().StringVar(&agentStatus, "status", "", "Comma-separated statuses to show: active, idle, blocked, completed, abandoned (default: active,idle)")
+	agentListCmd.Flags().StringVar(&agentLabel, "label", "", "Only show sessions with this label")
+	addWatchFlags(agentListCmd)
 
-	agentHistoryCmd.Flags().IntVar(&agentLimit, "limit", 20, "Max sessions to show")
+	agentHistoryCmd.Flags().IntVar(&agentLimit, "limit", 20, "Max sessions to show (default with --by-label: all)")
+	agentHistoryCmd.Flags().StringVar(&agentLabel, "label", "", "Only show sessions with this label")
+	agentHistoryCmd.Flags().BoolVar(&agentByLabel, "by-label", false, "Summarize sessions per label instead of listing them")
+
+	agentBlockCmd.Flags().StringVar(&blockReason, "reason", "", "What the session is waiting on (required)")
+	agentBlockCmd.Flags().StringVar(&blockCondition, "condition", "", "What has to happen before work can resume")
+	_ = agentBlockCmd.MarkFlagRequired("reason")
 
 	agentCloseCmd.Flags().BoolVar(&closeDone, "done", false, "Mark session as completed (issues → done)")
 	agentCloseCmd.Flags().BoolVar(&closeAbandon, "abandon", false, "Mark session as abandoned (issues → open)")
+	agentCloseCmd.Flags().BoolVar(&closeForce, "force", false, "Skip close-check confirmation when using --done")
+	agentCloseCmd.Flags().BoolVar(&closeCheckOnly, "check-only", false, "Only run the close-check; the exit code reports the session's state")
+	agentCloseCmd.Flags().BoolVar(&closeJSON, "json", false, "Output the close-check or closed session as JSON")
 
 	agentSyncCmd.Flags().BoolVar(&syncRebase, "rebase", false, "Use rebase instead of merge")
 	agentSyncCmd.Flags().BoolVar(&syncForce, "force", false, "Skip dirty worktree check")
 
 	agentMergeCmd.Flags().BoolVar(&mergeRebase, "rebase", false, "Use rebase instead of merge")
-	agentMergeCmd.Flags().BoolVar(&mergeForce, "force", false, "Skip dirty worktree check")
+	agentMergeCmd.Flags().StringVar(&mergeStrategy, "strategy", "", "Merge strategy: merge, rebase, squash or ff-only (default: the project's)")
+	agentMergeCmd.Flags().BoolVar(&mergeForce, "force", false, "Skip the dirty worktree and commit convention checks")
 	agentMergeCmd.Flags().BoolVar(&mergeNoCleanup, "no-cleanup", false, "Skip post-merge cleanup (worktree removal, branch deletion, iTerm close)")
+	agentMergeCmd.Flags().BoolVar(&mergeDelRemote, "delete-remote", false, "Also delete the remote branch the session's branch tracks during cleanup (asks first)")
 
 	agentCmd.AddCommand(agentLaunchCmd)
 	agentCmd.AddCommand(agentListCmd)
 	agentCmd.AddCommand(agentHistoryCmd)
 	agentCmd.AddCommand(agentCloseCmd)
+	agentCmd.AddCommand(agentBlockCmd)
+	agentCmd.AddCommand(agentUnblockCmd)
 	agentCmd.AddCommand(agentSyncCmd)
 	agentCmd.AddCommand(agentMergeCmd)
 	agentCmd.AddCommand(agentDiscoverCmd)
@@ -172,59 +286,134 @@ func agentLaunchRun(projectRef string) error {
 	}
 	ctx := context.Background()
 
-	p, err := resolveProject(ctx, s, projectRef)
+	p, err := resolveLaunchProject(ctx, s, projectRef)
 	if err != nil {
 		return err
 	}
+	if agentInline {
+		return agentLaunchInline(ctx, s, p)
+	}
 
 	// Determine branch name
 	branch := agentBranch
 	resolvedIssueID := agentIssue
+	var issue *models.Issue
 	if branch == "" && agentIssue != "" {
-		issue, err := findIssue(ctx, s, agentIssue)
+		issue, err = findIssue(ctx, s, agentIssue)
 		if err != nil {
 			return fmt.Errorf("find issue: %w", err)
 		}
 		branch = issueToBranch(issue.Title)
 		resolvedIssueID = issue.ID
-
-		// Update issue status to in_progress
-		issue.Status = models.IssueStatusInProgress
-		_ = s.UpdateIssue(ctx, issue)
 	}
 	if branch == "" {
 		return fmt.Errorf("specify --branch or --issue to generate a branch name")
 	}
+	issueRef := shortID(resolvedIssueID)
+	if issue != nil {
+		issueRef = issueCode(issue)
+	}
 
 	// Compute worktree path to match wt's convention: {project}.worktrees/{last-branch-segment}
 	branchParts := strings.Split(branch, "/")
 	worktreeDirname := branchParts[len(branchParts)-1]
 	worktreePath := filepath.Join(p.Path+".worktrees", worktreeDirname)
 
-	// Check for existing idle session on this branch
+	// Check WIP limits against the work this launch would start
 	existingSessions, _ := s.ListAgentSessions(ctx, p.ID, 0)
+	if err := agent.CheckBranchNotBlocked(existingSessions, branch); err != nil {
+		return err
+	}
+	if err := agent.CheckBranchNotInline(existingSessions, branch); err != nil {
+		return err
+	}
+	if err := agent.CheckBranchNotActive(existingSessions, branch); err != nil {
+		return err
+	}
+	if resolvedIssueID != "" {
+		if err := agent.CheckIssueNotHeld(existingSessions, resolvedIssueID, issueLockExpiry(), time.Now()); err != nil {
+			return err
+		}
+	}
+	addSessions, addIssues := 1, 0
+	for _, sess := range existingSessions {
+		if sess.Branch == branch && sess.Status == models.SessionStatusIdle {
+			addSessions = 0
+		}
+	}
+	if issue != nil && issue.Status != models.IssueStatusInProgress {
+		addIssues = 1
+	}
+	wip, err := agent.CheckWIP(ctx, s, p, addIssues, addSessions)
+	if err != nil {
+		return err
+	}
+	for _, warn := range wip.Warnings {
+		ui.Warning("WIP limit: %s", warn)
+	}
+	if wip.Blocked {
+		return fmt.Errorf("launch blocked by WIP limits for %s; finish in-progress work or raise the limits with 'pm project wip'", p.Name)
+	}
+
+	// Warn when the issue is underspecified for an agent
+	if issue != nil {
+		if r := agent.AssessReadiness(ctx, s, issue); !r.Ready {
+			ui.Warning("Issue %s is %d%% ready for an agent: %s", issueCode(issue), r.Score, strings.Join(r.Missing(), ", "))
+		}
+	}
+
+	if resolvedIssueID != "" && !dryRun {
+		if err := agent.ReleaseExpiredIssueLocks(ctx, s, existingSessions, []string{resolvedIssueID}, issueLockExpiry(), time.Now()); err != nil {
+			return err
+		}
+	}
+
+	// Update issue status to in_progress
+	if issue != nil {
+		issue.Status = models.IssueStatusInProgress
+		if err := s.UpdateIssue(ctx, issue); err != nil {
+			return fmt.Errorf("start issue %s: %w", issueCode(issue), err)
+		}
+	}
+
+	// Check for existing idle session on this branch
 	for _, sess := range existingSessions {
 		if sess.Branch == branch && sess.Status == models.SessionStatusIdle {
 			if dryRun {
-				ui.DryRunMsg("Would resume session %s for %s on branch %s", shortID(sess.ID), p.Name, branch)
+				ui.DryRunMsg("Would resume session %s for %s on branch %s", sessionCode(sess), p.Name, branch)
 				return nil
 			}
+			// Warn if the worktree was edited while idle; offer to commit those edits first
+			gc := git.NewClient()
+			if check := agent.CheckCoEdit(ctx, sess, gc); check != nil {
+				ui.Warning("%s", check.Warning)
+				if check.Dirty && (agentSnapshot || ui.Confirm("Snapshot these changes as a commit before resuming?")) {
+					hash, err := agent.SnapshotHumanEdits(ctx, sess, gc)
+					if err != nil {
+						return err
+					}
+					ui.Success("Committed outside edits as %s", output.Cyan(hash))
+				}
+			}
+			sess.WorktreeFingerprint = ""
+
 			// Resume: reactivate existing session, open iTerm window
 			wtClient := wt.NewClient()
 			ui.Info("Opening worktree for branch: %s", output.Cyan(branch))
 			if err := wtClient.Create(p.Path, branch); err != nil {
 				return fmt.Errorf("wt open: %w", err)
 			}
-			sess.Status = models.SessionStatusActive
-			now := time.Now().UTC()
-			sess.LastActiveAt = &now
-			if err := s.UpdateAgentSession(ctx, sess); err != nil {
-				return fmt.Errorf("failed to reactivate session %s: %w", shortID(sess.ID), err)
+			agent.RecordWTTerminal(sess, wtClient)
+			sess.EditLabels(agentLabels, nil)
+			if err := agent.Transition(ctx, s, sess, models.SessionStatusActive, agent.CauseLaunch); err != nil {
+				return fmt.Errorf("failed to reactivate session %s: %w", sessionCode(sess), err)
 			}
 			resumePath := sess.WorktreePath
-			ui.Success("Resumed session %s for %s on branch %s", output.Cyan(shortID(sess.ID)), output.Cyan(p.Name), output.Cyan(branch))
+			ui.Success("Resumed session %s for %s on branch %s", output.Cyan(sessionCode(sess)), output.Cyan(p.Name), output.Cyan(branch))
+			ui.Result(sess.ID)
 			if resolvedIssueID != "" {
-				ui.Info("Run: cd %s && claude \"Use pm MCP tools to look up issue %s and implement it. Update the issue status when complete.\"", resumePath, shortID(resolvedIssueID))
+				prompt := agent.WithLanguageGuidance(fmt.Sprintf("Use pm MCP tools to look up issue %s and implement it. Update the issue status when complete.", issueRef), p.MainLanguages())
+				ui.Info("Run: cd %s && claude \"%s\"", resumePath, prompt)
 			} else {
 				ui.Info("Run: cd %s && claude", resumePath)
 			}
@@ -233,8 +422,8 @@ func agentLaunchRun(projectRef string) error {
 	}
 
 	// Auto-purge stale abandoned sessions for this branch
-	if _, err := s.DeleteStaleSessions(ctx, p.ID, branch); err != nil {
-		ui.Warning("Failed to purge stale sessions: %v", err)
+	if err := purgeStaleSessions(ctx, s, p.ID, branch); err != nil {
+		return err
 	}
 
 	if dryRun {
@@ -244,10 +433,20 @@ func agentLaunchRun(projectRef string) error {
 
 	// Create worktree via wt CLI
 	wtClient := wt.NewClient()
+	var issues []*models.Issue
+	if issue != nil {
+		issues = append(issues, issue)
+	}
 	ui.Info("Creating worktree for branch: %s", output.Cyan(branch))
-	if err := wtClient.Create(p.Path, branch); err != nil {
+	sparse, err := wtClient.CreateWith(p.Path, branch, wt.CreateOptions{
+		SparsePaths: agent.LaunchSparsePaths(p, issues, agentSparse, agent.ParseSparsePaths(strings.Join(agentSparsePaths, ","))),
+	})
+	if err != nil {
 		return fmt.Errorf("create worktree: %w", err)
 	}
+	if len(sparse) > 0 {
+		ui.Info("Sparse checkout: %s", strings.Join(sparse, ", "))
+	}
 
 	// Record session
 	session := &models.AgentSession{
@@ -256,24 +455,182 @@ func agentLaunchRun(projectRef string) error {
 		Branch:       branch,
 		WorktreePath: worktreePath,
 		Status:       models.SessionStatusActive,
+		SparsePaths:  strings.Join(sparse, ","),
 	}
+	session.EditLabels(agentLabels, nil)
+	agent.RecordWTTerminal(session, wtClient)
 	if err := s.CreateAgentSession(ctx, session); err != nil {
-		ui.Warning("Session recording failed: %v", err)
+		return fmt.Errorf("record session (worktree created at %s): %w", worktreePath, err)
 	}
 
 	ui.Success("Agent launched for %s on branch %s", output.Cyan(p.Name), output.Cyan(branch))
+	ui.Result(session.ID)
 
 	// Show the command to run
 	if resolvedIssueID != "" {
-		shortIssueID := shortID(resolvedIssueID)
-		ui.Info("Run: cd %s && claude \"Use pm MCP tools to look up issue %s and implement it. Update the issue status when complete.\"", worktreePath, shortIssueID)
+		prompt := agent.WithLanguageGuidance(fmt.Sprintf("Use pm MCP tools to look up issue %s and implement it. Update the issue status when complete.", issueRef), p.MainLanguages())
+		ui.Info("Run: cd %s && claude \"%s\"", worktreePath, prompt)
 	} else {
 		ui.Info("Run: cd %s && claude", worktreePath)
 	}
 	return nil
 }
 
+// resolveLaunchProject resolves the project to launch in: the named project,
+// else the --issue's project, else the project in the current directory.
+func resolveLaunchProject(ctx context.Context, s store.Store, ref string) (*models.Project, error) {
+	if ref == "" && agentIssue != "" {
+		issue, err := findIssue(ctx, s, agentIssue)
+		if err != nil {
+			return nil, fmt.Errorf("find issue: %w", err)
+		}
+		return s.GetProject(ctx, issue.ProjectID)
+	}
+	return resolveProjectOrCwd(ctx, s, ref)
+}
+
+// purgeStaleSessions deletes the stale sessions of a project branch by the
+// sessions.cleanup thresholds before a launch reuses the branch.
+func purgeStaleSessions(ctx context.Context, s store.Store, projectID, branch string) error {
+	criteria, err := staleSessionCriteria(viper.GetViper())
+	if err != nil {
+		return err
+	}
+	criteria.ProjectID = projectID
+	criteria.Branch = branch
+	if _, err := s.DeleteStaleSessions(ctx, criteria); err != nil {
+		return fmt.Errorf("purge stale sessions: %w", err)
+	}
+	return nil
+}
+
+// agentLaunchInline starts (or resumes) an inline session that tracks the
+// project repo itself instead of a worktree.
+func agentLaunchInline(ctx context.Context, s store.Store, p *models.Project) error {
+	if agentBranch != "" {
+		return fmt.Errorf("--inline works on the repo's current branch; check out %s and drop --branch", agentBranch)
+	}
+	branch, err := git.NewClient().CurrentBranch(ctx, p.Path)
+	if err != nil {
+		return fmt.Errorf("get current branch of %s: %w", p.Path, err)
+	}
+
+	var issue *models.Issue
+	if agentIssue != "" {
+		issue, err = findIssue(ctx, s, agentIssue)
+		if err != nil {
+			return fmt.Errorf("find issue: %w", err)
+		}
+		if issue.ProjectID != p.ID {
+			return fmt.Errorf("issue %s belongs to another project", issueCode(issue))
+		}
+	}
+
+	existingSessions, _ := s.ListAgentSessions(ctx, p.ID, 0)
+	if err := agent.CheckBranchNotBlocked(existingSessions, branch); err != nil {
+		return err
+	}
+	var resume *models.AgentSession
+	for _, sess := range existingSessions {
+		if sess.Branch != branch || !sess.Status.IsOpen() {
+			continue
+		}
+		if !sess.Inline {
+			return fmt.Errorf("branch %s already has worktree session %s", branch, sessionCode(sess))
+		}
+		if sess.Status == models.SessionStatusActive {
+			return fmt.Errorf("inline session %s is already active on %s", sessionCode(sess), branch)
+		}
+		if issue != nil && sess.IssueID != "" && sess.IssueID != issue.ID {
+			return fmt.Errorf("inline session %s is working on issue %s; close it first", sessionCode(sess), shortID(sess.IssueID))
+		}
+		resume = sess
+	}
+	if issue != nil {
+		if err := agent.CheckIssueNotHeld(existingSessions, issue.ID, issueLockExpiry(), time.Now()); err != nil {
+			return err
+		}
+	}
+
+	addSessions, addIssues := 1, 0
+	if resume != nil {
+		addSessions = 0
+	}
+	if issue != nil && issue.Status != models.IssueStatusInProgress {
+		addIssues = 1
+	}
+	wip, err := agent.CheckWIP(ctx, s, p, addIssues, addSessions)
+	if err != nil {
+		return err
+	}
+	for _, warn := range wip.Warnings {
+		ui.Warning("WIP limit: %s", warn)
+	}
+	if wip.Blocked {
+		return fmt.Errorf("launch blocked by WIP limits for %s; finish in-progress work or raise the limits with 'pm project wip'", p.Name)
+	}
+
+	if dryRun {
+		if resume != nil {
+			ui.DryRunMsg("Would resume inline session %s for %s on branch %s", sessionCode(resume), p.Name, branch)
+		} else {
+			ui.DryRunMsg("Would start an inline session for %s on branch %s", p.Name, branch)
+		}
+		return nil
+	}
+
+	if issue != nil {
+		if err := agent.ReleaseExpiredIssueLocks(ctx, s, existingSessions, []string{issue.ID}, issueLockExpiry(), time.Now()); err != nil {
+			return err
+		}
+		issue.Status = models.IssueStatusInProgress
+		if err := s.UpdateIssue(ctx, issue); err != nil {
+			return fmt.Errorf("start issue %s: %w", issueCode(issue), err)
+		}
+	}
+
+	now := time.Now().UTC()
+	if resume != nil {
+		if issue != nil {
+			resume.IssueID = issue.ID
+		}
+		resume.EditLabels(agentLabels, nil)
+		if err := agent.Transition(ctx, s, resume, models.SessionStatusActive, agent.CauseLaunch); err != nil {
+			return fmt.Errorf("failed to reactivate session %s: %w", sessionCode(resume), err)
+		}
+		ui.Success("Resumed inline session %s for %s on branch %s", output.Cyan(sessionCode(resume)), output.Cyan(p.Name), output.Cyan(branch))
+		ui.Result(resume.ID)
+	} else {
+		if err := purgeStaleSessions(ctx, s, p.ID, branch); err != nil {
+			return err
+		}
+		session := &models.AgentSession{
+			ProjectID:    p.ID,
+			Branch:       branch,
+			WorktreePath: p.Path,
+			Status:       models.SessionStatusActive,
+			Inline:       true,
+			LastActiveAt: &now,
+		}
+		session.EditLabels(agentLabels, nil)
+		if issue != nil {
+			session.IssueID = issue.ID
+		}
+		if err := s.CreateAgentSession(ctx, session); err != nil {
+			return fmt.Errorf("record session: %w", err)
+		}
+		ui.Success("Started inline session %s for %s on branch %s", output.Cyan(sessionCode(session)), output.Cyan(p.Name), output.Cyan(branch))
+		ui.Result(session.ID)
+	}
+	ui.Info("Work in %s; finish with: pm agent close --done", p.Path)
+	return nil
+}
+
 func agentListRun(projectRef string) error {
+	show, err := parseSessionStatusFilter(agentStatus)
+	if err != nil {
+		return err
+	}
 	s, err := getStore()
 	if err != nil {
 		return err
@@ -289,30 +646,89 @@ func agentListRun(projectRef string) error {
 		projectID = p.ID
 	}
 
-	sessions, err := s.ListAgentSessions(ctx, projectID, 0)
+	build := func(ctx context.Context, refresh watchRefresh) (*watchFrame, error) {
+		return agentListFrame(ctx, s, projectID, show, refresh)
+	}
+	if watchMode {
+		return runWatch(build)
+	}
+	frame, err := build(ctx, watchRefresh{All: true})
 	if err != nil {
 		return err
 	}
+	frame.print()
+	return nil
+}
+
+// parseSessionStatusFilter parses the comma-separated --status values of
+// pm agent list, each one of models.SessionStatuses. Empty means active and
+// idle.
+func parseSessionStatusFilter(raw string) (map[models.SessionStatus]bool, error) {
+	if strings.TrimSpace(raw) == "" {
+		return map[models.SessionStatus]bool{models.SessionStatusActive: true, models.SessionStatusIdle: true}, nil
+	}
+	show := make(map[models.SessionStatus]bool)
+	for _, st := range strings.Split(raw, ",") {
+		status, err := models.ParseSessionStatus(strings.TrimSpace(st))
+		if err != nil {
+			return nil, err
+		}
+		show[status] = true
+	}
+	return show, nil
+}
+
+// agentListFrame builds the agent list table. Only the sessions of the
+// projects refresh names are reconciled with their worktrees and processes.
+func agentListFrame(ctx context.Context, s store.Store, projectID string, show map[models.SessionStatus]bool, refresh watchRefresh) (*watchFrame, error) {
+	sessions, err := s.ListAgentSessions(ctx, projectID, 0)
+	if err != nil {
+		return nil, err
+	}
 
 	// Reconcile orphaned worktrees and detect active claude processes
+	var reconcile []*models.AgentSession
+	for _, sess := range sessions {
+		if refresh.has(sess.ProjectID) {
+			reconcile = append(reconcile, sess)
+		}
+	}
 	detector := &agent.OSProcessDetector{}
-	agent.ReconcileSessions(ctx, s, sessions, agent.WithProcessDetector(detector))
+	agent.ReconcileSessions(ctx, s, reconcile, agent.WithProcessDetector(detector), agent.WithFingerprinter(git.NewClient()))
 
-	// Filter to active/idle
-	var live []*models.AgentSession
+	var live, blocked []*models.AgentSession
 	for _, sess := range sessions {
-		if sess.Status == models.SessionStatusActive || sess.Status == models.SessionStatusIdle {
+		if sess.Status == models.SessionStatusBlocked {
+			blocked = append(blocked, sess)
+		}
+		if show[sess.Status] && (agentLabel == "" || sess.HasLabel(agentLabel)) {
 			live = append(live, sess)
 		}
 	}
 
-	if len(live) == 0 {
-		ui.Info("No active or idle agent sessions.")
-		return nil
+	frame := &watchFrame{Headers: []string{"ID", "Project", "Branch", "Status", "Labels", "Worktree", "Last Active", "Started"}}
+	frame.Footer = func() {
+		if len(live) == 0 {
+			if agentLabel != "" {
+				ui.Info("No matching agent sessions labeled %s.", agentLabel)
+			} else if agentStatus != "" {
+				ui.Info("No %s agent sessions.", agentStatus)
+			} else {
+				ui.Info("No active or idle agent sessions.")
+			}
+		}
+		for _, sess := range live {
+			if sess.Status == models.SessionStatusBlocked {
+				fmt.Fprintf(ui.Out, "  %s blocked %s: %s\n", output.Cyan(sessionCode(sess)), blockedSince(sess), blockedDetail(sess))
+			}
+		}
+		if len(blocked) > 0 && !show[models.SessionStatusBlocked] {
+			ui.Info("%d blocked session(s) hidden; use --status blocked to list them.", len(blocked))
+		}
+		printBlockedReminders(blocked)
 	}
 
 	projectNames := make(map[string]string)
-	table := ui.Table([]string{"ID", "Project", "Branch", "Status", "Worktree", "Last Active", "Started"})
 	for _, sess := range live {
 		projName := projectNames[sess.ProjectID]
 		if projName == "" {
@@ -326,22 +742,99 @@ func agentListRun(projectRef string) error {
 		if sess.LastActiveAt != nil {
 			lastActive = timeAgo(*sess.LastActiveAt)
 		}
+		worktree := sess.WorktreePath
+		if sess.Inline {
+			worktree += " (inline)"
+		}
 
-		_ = table.Append([]string{
-			shortID(sess.ID),
+		frame.Rows = append(frame.Rows, []string{
+			sessionCode(sess),
 			projName,
 			sess.Branch,
 			output.StatusColor(string(sess.Status)),
-			sess.WorktreePath,
+			labelsOrDash(sess),
+			worktree,
 			lastActive,
 			timeAgo(sess.StartedAt),
 		})
 	}
-	_ = table.Render()
-	return nil
+	return frame, nil
+}
+
+// blockedDetail describes why a session is blocked.
+func blockedDetail(sess *models.AgentSession) string {
+	detail := sess.BlockedReason
+	if sess.UnblockCondition != "" {
+		detail += " (until: " + sess.UnblockCondition + ")"
+	}
+	return detail
+}
+
+// blockedSince says how long ago a session was blocked.
+func blockedSince(sess *models.AgentSession) string {
+	if sess.BlockedAt == nil {
+		return "—"
+	}
+	return timeAgo(*sess.BlockedAt)
+}
+
+// blockedRemindAfter reads sessions.blocked.remind_after ("3d", "36h"; 0
+// disables reminders).
+func blockedRemindAfter() time.Duration {
+	d, err := sla.ParseDuration(viper.GetString("sessions.blocked.remind_after"))
+	if err != nil {
+		ui.Warning("sessions.blocked.remind_after: %v; using the default", err)
+		return agent.DefaultBlockedRemindAfter
+	}
+	return d
+}
+
+// issueLockExpiry reads sessions.issue_lock.expire_after, how long an active
+// session without activity keeps its issue locked ("2h"; 0 never expires).
+func issueLockExpiry() time.Duration {
+	d, err := sla.ParseDuration(viper.GetString("sessions.issue_lock.expire_after"))
+	if err != nil {
+		ui.Warning("sessions.issue_lock.expire_after: %v; using the default", err)
+		return agent.DefaultIssueLockExpiry
+	}
+	return d
 }
 
-func agentHistoryRun(projectRef string) error {
+// heartbeatInterval reads sessions.heartbeat.interval, how often at most a
+// session's LastActiveAt is written for MCP tool calls and file changes.
+func heartbeatInterval() time.Duration {
+	d, err := time.ParseDuration(viper.GetString("sessions.heartbeat.interval"))
+	if err != nil || d < 0 {
+		ui.Warning("Invalid sessions.heartbeat.interval %q, using the default", viper.GetString("sessions.heartbeat.interval"))
+		return agent.DefaultHeartbeatInterval
+	}
+	return d
+}
+
+// discoverOptions reads sessions.discover.*, how discovery across all
+// projects is bounded.
+func discoverOptions() sessions.DiscoverOptions {
+	opts := sessions.DiscoverOptions{
+		Workers: viper.GetInt("sessions.discover.workers"),
+		Exclude: viper.GetStringSlice("sessions.discover.exclude"),
+	}
+	d, err := sla.ParseDuration(viper.GetString("sessions.discover.timeout"))
+	if err != nil || d <= 0 {
+		ui.Warning("Invalid sessions.discover.timeout %q, using the default", viper.GetString("sessions.discover.timeout"))
+		d = sessions.DefaultDiscoverTimeout
+	}
+	opts.Timeout = d
+	return opts
+}
+
+// printBlockedReminders warns about sessions blocked longer than the reminder threshold.
+func printBlockedReminders(sessions []*models.AgentSession) {
+	for _, sess := range agent.OverdueBlocked(sessions, blockedRemindAfter(), time.Now()) {
+		ui.Warning("Session %s (%s) blocked %s: %s", sessionCode(sess), sess.Branch, blockedSince(sess), blockedDetail(sess))
+	}
+}
+
+func agentHistoryRun(projectRef string, limitSet bool) error {
 	s, err := getStore()
 	if err != nil {
 		return err
@@ -357,7 +850,11 @@ func agentHistoryRun(projectRef string) error {
 		projectID = p.ID
 	}
 
-	sessions, err := s.ListAgentSessions(ctx, projectID, agentLimit)
+	limit := agentLimit
+	if agentByLabel && !limitSet {
+		limit = 0
+	}
+	sessions, err := s.ListAgentSessionsFiltered(ctx, store.SessionListFilter{ProjectID: projectID, Label: agentLabel, Limit: limit})
 	if err != nil {
 		return err
 	}
@@ -366,9 +863,14 @@ func agentHistoryRun(projectRef string) error {
 		ui.Info("No agent session history.")
 		return nil
 	}
+	if agentByLabel {
+		printLabelStats(agent.AggregateByLabel(sessions))
+		return nil
+	}
 
 	projectNames := make(map[string]string)
-	table := ui.Table([]string{"ID", "Project", "Branch", "Status", "Commits", "Last Commit", "Duration"})
+	now := time.Now()
+	table := ui.Table([]string{"ID", "Project", "Branch", "Status", "Labels", "Commits", "Last Commit", "Duration", "Focus", "Idle"})
 	for _, sess := range sessions {
 		projName := projectNames[sess.ProjectID]
 		if projName == "" {
@@ -384,6 +886,11 @@ func agentHistoryRun(projectRef string) error {
 			duration = formatDuration(d)
 		}
 
+		focus := "—"
+		if sess.FocusSeconds > 0 {
+			focus = formatDuration(sess.FocusTime())
+		}
+
 		lastCommit := "—"
 		if sess.LastCommitHash != "" {
 			msg := sess.LastCommitMessage
@@ -394,19 +901,59 @@ func agentHistoryRun(projectRef string) error {
 		}
 
 		_ = table.Append([]string{
-			shortID(sess.ID),
+			sessionCode(sess),
 			projName,
 			sess.Branch,
 			output.StatusColor(string(sess.Status)),
+			labelsOrDash(sess),
 			fmt.Sprintf("%d", sess.CommitCount),
 			lastCommit,
 			duration,
+			focus,
+			formatDuration(sess.IdleTime(now)),
 		})
 	}
 	_ = table.Render()
 	return nil
 }
 
+// labelsOrDash lists a session's labels for a table cell.
+func labelsOrDash(sess *models.AgentSession) string {
+	if sess.Labels == "" {
+		return "—"
+	}
+	return strings.ReplaceAll(sess.Labels, ",", ", ")
+}
+
+// printLabelStats prints a per-label summary of sessions.
+func printLabelStats(stats []agent.LabelStats) {
+	table := ui.Table([]string{"Label", "Sessions", "Open", "Completed", "Abandoned", "Success", "Commits", "Avg Duration"})
+	for _, st := range stats {
+		label := st.Label
+		if label == "" {
+			label = "(none)"
+		}
+		success, avg := "—", "—"
+		if st.Completed+st.Abandoned > 0 {
+			success = fmt.Sprintf("%.0f%%", st.SuccessRate()*100)
+		}
+		if st.AvgDurationSeconds > 0 {
+			avg = formatDuration(time.Duration(st.AvgDurationSeconds) * time.Second)
+		}
+		_ = table.Append([]string{
+			label,
+			fmt.Sprintf("%d", st.Sessions),
+			fmt.Sprintf("%d", st.Open),
+			fmt.Sprintf("%d", st.Completed),
+			fmt.Sprintf("%d", st.Abandoned),
+			success,
+			fmt.Sprintf("%d", st.Commits),
+			avg,
+		})
+	}
+	_ = table.Render()
+}
+
 func agentCloseRun(sessionRef string) error {
 	s, err := getStore()
 	if err != nil {
@@ -423,25 +970,62 @@ func agentCloseRun(sessionRef string) error {
 	}
 
 	// Resolve session ID
-	sessionID := sessionRef
-	if sessionID == "" {
+	var sessionID string
+	if sessionRef == "" {
 		sessionID, err = resolveSessionFromCwd(ctx, s)
-		if err != nil {
-			return err
+	} else {
+		sessionID, err = findSessionID(ctx, s, sessionRef)
+	}
+	if err != nil {
+		return err
+	}
+
+	gc := git.NewClient()
+
+	// With sessions.close.enforce_check, run close-check before completing a
+	// live session and ask before discarding warnings
+	if target == models.SessionStatusCompleted && !closeForce && viper.GetBool("sessions.close.enforce_check") {
+		if sess, err := s.GetAgentSession(ctx, sessionID); err == nil && sess.Status.IsOpen() {
+			project, _ := s.GetProject(ctx, sess.ProjectID)
+			check := agent.CheckClose(ctx, sess, project, gc)
+			if !check.ReadyToClose && closeJSON {
+				// Scripts can't answer a prompt: report the check and fail
+				if err := printCloseCheckJSON(check); err != nil {
+					return err
+				}
+				if err := closeCheckExit(check); err != nil {
+					return err
+				}
+				return fmt.Errorf("close cancelled; resolve the close-check warnings or use --force")
+			}
+			if !check.ReadyToClose {
+				failed := make([]string, 0, len(check.Warnings))
+				for _, warn := range check.Warnings {
+					ui.Warning("%s", warn.Message)
+					failed = append(failed, warn.Type)
+				}
+				if !ui.Interactive() {
+					return fmt.Errorf("close-check failed (%s) and there is no terminal to confirm on; resolve the warnings or use --force", strings.Join(failed, ", "))
+				}
+				if !ui.Confirm("Close session %s anyway?", sessionCode(sess)) {
+					return fmt.Errorf("close cancelled; resolve the warnings above or use --force")
+				}
+			}
 		}
 	}
 
 	// Enrich session with git info before closing
-	gc := git.NewClient()
 	if sess, err := s.GetAgentSession(ctx, sessionID); err == nil {
-		agent.EnrichSessionWithGitInfo(sess, gc)
-		_ = s.UpdateAgentSession(ctx, sess)
+		agent.EnrichSessionWithGitInfo(ctx, sess, gc)
+		if err := s.UpdateAgentSession(ctx, sess); err != nil {
+			return fmt.Errorf("record session git info: %w", err)
+		}
 	}
 
 	// Get worktree path and project path before closing (for lifecycle cleanup)
 	var worktreePath string
 	var projectPath string
-	if sess, lookupErr := s.GetAgentSession(ctx, sessionID); lookupErr == nil {
+	if sess, lookupErr := s.GetAgentSession(ctx, sessionID); lookupErr == nil && !sess.Inline {
 		worktreePath = sess.WorktreePath
 		if proj, projErr := s.GetProject(ctx, sess.ProjectID); projErr == nil {
 			projectPath = proj.Path
@@ -453,16 +1037,177 @@ func agentCloseRun(sessionRef string) error {
 		return err
 	}
 
+	// Remember the worktree state so a later resume can detect outside edits
+	if target != models.SessionStatusAbandoned {
+		if err := agent.RecordWorktreeFingerprint(ctx, s, session, gc); err != nil {
+			return fmt.Errorf("session %s closed, but: %w", sessionCode(session), err)
+		}
+	}
+
 	// For abandoned: full worktree teardown via lifecycle (close iTerm + remove worktree + untrust + cleanup state)
 	if worktreePath != "" && target == models.SessionStatusAbandoned && projectPath != "" {
 		wtClient := wt.NewClient()
 		lm := wtClient.LifecycleForRepo(projectPath)
-		_ = lm.Delete(context.Background(), worktreePath, lifecycle.DeleteOptions{Force: true})
+		if err := lm.Delete(context.Background(), worktreePath, lifecycle.DeleteOptions{Force: true}); err != nil {
+			return fmt.Errorf("session %s abandoned, but removing its worktree failed: %w", sessionCode(session), err)
+		}
 		session.WorktreePath = ""
-		_ = s.UpdateAgentSession(ctx, session)
+		if err := s.UpdateAgentSession(ctx, session); err != nil {
+			return fmt.Errorf("session %s abandoned, but clearing its worktree path failed: %w", sessionCode(session), err)
+		}
+	}
+
+	if closeJSON {
+		enc := json.NewEncoder(ui.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(closedSession{
+			SessionID:    session.ID,
+			Status:       session.Status,
+			Branch:       session.Branch,
+			WorktreePath: session.WorktreePath,
+		})
+	}
+	ui.Success("Session %s → %s", output.Cyan(sessionCode(session)), output.Cyan(string(session.Status)))
+	return nil
+}
+
+// printCleanup reports what a worktree cleanup deleted and what it kept.
+func printCleanup(c *sessions.CleanupResult) {
+	if c == nil {
+		return
+	}
+	if c.WorktreeRemoved {
+		ui.Success("Removed worktree")
+	}
+	if c.BranchDeleted {
+		ui.Success("Deleted branch '%s'", c.Branch)
+	}
+	if c.RemoteDeleted {
+		ui.Success("Deleted remote branch '%s'", c.Upstream)
+	}
+	for _, k := range c.Kept {
+		ui.Info("Kept %s", k)
+	}
+}
+
+// closedSession is the --json output of a successful pm agent close.
+type closedSession struct {
+	SessionID    string               `json:"session_id"`
+	Status       models.SessionStatus `json:"status"`
+	Branch       string               `json:"branch"`
+	WorktreePath string               `json:"worktree_path,omitempty"`
+}
+
+// closeCheckResult is the --json output of pm agent close --check-only.
+type closeCheckResult struct {
+	*agent.CloseCheck
+	ExitCode int `json:"exit_code"`
+}
+
+// agentCloseCheckRun runs the close-check for a session without closing it,
+// exiting with the check's agent.CloseExit code.
+func agentCloseCheckRun(sessionRef string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var sessionID string
+	if sessionRef == "" {
+		sessionID, err = resolveSessionFromCwd(ctx, s)
+	} else {
+		sessionID, err = findSessionID(ctx, s, sessionRef)
+	}
+	if err != nil {
+		return err
+	}
+	sess, err := s.GetAgentSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	project, _ := s.GetProject(ctx, sess.ProjectID)
+	check := agent.CheckClose(ctx, sess, project, git.NewClient())
+
+	if closeJSON {
+		if err := printCloseCheckJSON(check); err != nil {
+			return err
+		}
+	} else if check.ReadyToClose {
+		ui.Success("Session %s is ready to close", output.Cyan(sessionCode(sess)))
+	} else {
+		for _, warn := range check.Warnings {
+			ui.Warning("%s", warn.Message)
+		}
 	}
+	return closeCheckExit(check)
+}
 
-	ui.Success("Session %s → %s", output.Cyan(shortID(session.ID)), output.Cyan(string(session.Status)))
+func printCloseCheckJSON(check *agent.CloseCheck) error {
+	enc := json.NewEncoder(ui.Out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(closeCheckResult{CloseCheck: check, ExitCode: check.ExitCode()})
+}
+
+// closeCheckExit returns an error exiting with the check's exit code, or nil
+// when the session is clean. The check has already been printed.
+func closeCheckExit(check *agent.CloseCheck) error {
+	if code := check.ExitCode(); code != agent.CloseExitClean {
+		return &exitCodeError{code: code}
+	}
+	return nil
+}
+
+func agentBlockRun(sessionRef string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var sessionID string
+	if sessionRef == "" {
+		sessionID, err = resolveSessionFromCwd(ctx, s)
+	} else {
+		sessionID, err = findSessionID(ctx, s, sessionRef)
+	}
+	if err != nil {
+		return err
+	}
+
+	session, err := agent.BlockSession(ctx, s, sessionID, blockReason, blockCondition)
+	if err != nil {
+		return err
+	}
+	// Remember the worktree state so a later resume can detect outside edits
+	_ = agent.RecordWorktreeFingerprint(ctx, s, session, git.NewClient())
+
+	ui.Success("Session %s → %s: %s", output.Cyan(sessionCode(session)), output.Cyan(string(session.Status)), blockedDetail(session))
+	return nil
+}
+
+func agentUnblockRun(sessionRef string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var sessionID string
+	if sessionRef == "" {
+		sessionID, err = resolveSessionFromCwd(ctx, s)
+	} else {
+		sessionID, err = findSessionID(ctx, s, sessionRef)
+	}
+	if err != nil {
+		return err
+	}
+
+	session, err := agent.UnblockSession(ctx, s, sessionID)
+	if err != nil {
+		return err
+	}
+	ui.Success("Session %s → %s", output.Cyan(sessionCode(session)), output.Cyan(string(session.Status)))
 	return nil
 }
 
@@ -484,7 +1229,7 @@ func resolveSessionFromCwd(ctx context.Context, s store.Store) (string, error) {
 		return "", fmt.Errorf("no session found for current directory; specify a session ID")
 	}
 
-	// List active/idle sessions for this project
+	// List open sessions for this project
 	sessions, err := s.ListAgentSessions(ctx, p.ID, 0)
 	if err != nil {
 		return "", err
@@ -492,31 +1237,25 @@ func resolveSessionFromCwd(ctx context.Context, s store.Store) (string, error) {
 
 	var live []*models.AgentSession
 	for _, sess := range sessions {
-		if sess.Status == models.SessionStatusActive || sess.Status == models.SessionStatusIdle {
+		if sess.Status.IsOpen() {
 			live = append(live, sess)
 		}
 	}
 
 	if len(live) == 0 {
-		return "", fmt.Errorf("no active/idle sessions for project %s", p.Name)
+		return "", fmt.Errorf("no open sessions for project %s", p.Name)
 	}
 	if len(live) == 1 {
 		return live[0].ID, nil
 	}
 
-	// Multiple sessions — list them for the user
-	fmt.Println("Multiple active sessions. Specify a session ID:")
-	table := ui.Table([]string{"ID", "Branch", "Status", "Started"})
+	// Multiple sessions — list them in the error, keeping stdout for data
+	var b strings.Builder
+	b.WriteString("ambiguous: multiple open sessions; specify a session ID:")
 	for _, sess := range live {
-		_ = table.Append([]string{
-			shortID(sess.ID),
-			sess.Branch,
-			string(sess.Status),
-			timeAgo(sess.StartedAt),
-		})
+		fmt.Fprintf(&b, "\n  %s  %s (%s, started %s)", sessionCode(sess), sess.Branch, sess.Status, timeAgo(sess.StartedAt))
 	}
-	_ = table.Render()
-	return "", fmt.Errorf("ambiguous: multiple sessions found")
+	return "", errors.New(b.String())
 }
 
 func agentSyncRun(sessionRef string) error {
@@ -526,12 +1265,14 @@ func agentSyncRun(sessionRef string) error {
 	}
 	ctx := context.Background()
 
-	sessionID := sessionRef
-	if sessionID == "" {
+	var sessionID string
+	if sessionRef == "" {
 		sessionID, err = resolveSessionFromCwd(ctx, s)
-		if err != nil {
-			return err
-		}
+	} else {
+		sessionID, err = findSessionID(ctx, s, sessionRef)
+	}
+	if err != nil {
+		return err
 	}
 
 	mgr := sessions.NewManager(s, nil)
@@ -557,7 +1298,7 @@ func agentSyncRun(sessionRef string) error {
 	} else if len(result.Conflicts) > 0 {
 		ui.Error("Sync conflicts detected:")
 		for _, f := range result.Conflicts {
-			ui.Info("  %s", f)
+			fmt.Fprintf(ui.ErrOut, "  %s\n", f)
 		}
 		return fmt.Errorf("resolve conflicts, then sync again")
 	} else if result.Error != "" {
@@ -574,21 +1315,33 @@ func agentMergeRun(sessionRef string) error {
 	}
 	ctx := context.Background()
 
-	sessionID := sessionRef
-	if sessionID == "" {
+	var sessionID string
+	if sessionRef == "" {
 		sessionID, err = resolveSessionFromCwd(ctx, s)
-		if err != nil {
-			return err
-		}
+	} else {
+		sessionID, err = findSessionID(ctx, s, sessionRef)
+	}
+	if err != nil {
+		return err
+	}
+
+	strategy, err := sessions.ParseMergeStrategy(mergeStrategy)
+	if err != nil {
+		return err
 	}
 
 	wtClient := wt.NewClient()
 	mgr := sessions.NewManager(s, wtClient)
+	mgr.SetAutoClose(autoCloseConfig())
 	opts := sessions.MergeOptions{
-		Rebase:  mergeRebase,
-		Force:   mergeForce,
-		DryRun:  dryRun,
-		Cleanup: !mergeNoCleanup,
+		Strategy: strategy,
+		Rebase:   mergeRebase,
+		Force:    mergeForce,
+		DryRun:   dryRun,
+		Cleanup:  !mergeNoCleanup,
+	}
+	if mergeDelRemote && opts.Cleanup && !dryRun {
+		opts.DeleteRemote = ui.Confirm("Delete the remote branch of session %s after merging? This affects everyone using it", shortID(sessionID))
 	}
 
 	result, err := mgr.MergeSession(ctx, sessionID, opts)
@@ -600,19 +1353,32 @@ func agentMergeRun(sessionRef string) error {
 		if result.PRCreated {
 			ui.Success("PR created: %s", result.PRURL)
 		} else {
-			strategy := "Merged"
-			if mergeRebase {
-				strategy = "Rebased"
+			verb := map[models.MergeStrategy]string{
+				models.MergeStrategyRebase: "Rebased",
+				models.MergeStrategySquash: "Squash-merged",
+				models.MergeStrategyFFOnly: "Fast-forwarded",
+			}[result.Strategy]
+			if verb == "" {
+				verb = "Merged"
+			}
+			ui.Success("%s '%s' into base branch", verb, result.Branch)
+			if result.MergeCommit != "" {
+				ui.Info("Base branch is now at %s", output.Cyan(result.MergeCommit))
+			}
+			for _, c := range result.Closures {
+				ui.Success("Closed issue %s (%s)", output.Cyan(shortID(c.IssueID)), closureOrigin(c))
 			}
-			ui.Success("%s '%s' into base branch", strategy, result.Branch)
 			if result.Cleaned {
-				ui.Success("Cleaned up worktree and branch")
+				printCleanup(result.Cleanup)
 			}
 		}
 	} else if len(result.Conflicts) > 0 {
 		ui.Error("Merge conflicts detected:")
 		for _, f := range result.Conflicts {
-			ui.Info("  %s", f)
+			fmt.Fprintf(ui.ErrOut, "  %s\n", f)
+		}
+		if result.Strategy == models.MergeStrategySquash {
+			return fmt.Errorf("the squash was undone; sync the session with the base branch, then merge again")
 		}
 		return fmt.Errorf("resolve conflicts, then merge again")
 	} else if result.Error != "" {
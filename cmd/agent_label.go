@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joescharf/pm/internal/output"
+)
+
+var (
+	labelAdd    []string
+	labelRemove []string
+)
+
+var agentLabelCmd = &cobra.Command{
+	Use:   "label [session_id]",
+	Short: "Show or change an agent session's labels",
+	Long: `Labels group sessions independently of their issues (experiment, hotfix,
+spike-2024Q3), so 'pm agent list --label' and 'pm agent history --by-label'
+can filter and compare them. Sessions can also be labeled at launch with
+'pm agent launch --label'.
+
+Without --add or --remove, prints the session's labels. Accepts a session ID
+prefix; auto-detects the session from cwd if no session_id is given.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return agentLabelRun(argOrEmpty(args))
+	},
+}
+
+func init() {
+	agentLabelCmd.Flags().StringSliceVar(&labelAdd, "add", nil, "Labels to add (repeatable or comma-separated)")
+	agentLabelCmd.Flags().StringSliceVar(&labelRemove, "remove", nil, "Labels to remove (repeatable or comma-separated)")
+	agentCmd.AddCommand(agentLabelCmd)
+}
+
+func agentLabelRun(sessionRef string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var sessionID string
+	if sessionRef == "" {
+		sessionID, err = resolveSessionFromCwd(ctx, s)
+	} else {
+		sessionID, err = findSessionID(ctx, s, sessionRef)
+	}
+	if err != nil {
+		return err
+	}
+	sess, err := s.GetAgentSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if len(labelAdd) == 0 && len(labelRemove) == 0 {
+		fmt.Fprintf(ui.Out, "%s %s\n", output.Cyan(sessionCode(sess)), labelsOrDash(sess))
+		return nil
+	}
+
+	sess.EditLabels(labelAdd, labelRemove)
+	if dryRun {
+		ui.DryRunMsg("Would set labels of session %s to %s", sessionCode(sess), labelsOrDash(sess))
+		return nil
+	}
+	if err := s.UpdateAgentSession(ctx, sess); err != nil {
+		return err
+	}
+	ui.Success("Session %s labels: %s", output.Cyan(sessionCode(sess)), labelsOrDash(sess))
+	return nil
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joescharf/pm/internal/agent"
+	"github.com/joescharf/pm/internal/capabilities"
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/llm"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/store"
+)
+
+var (
+	replaySummarize bool
+	replayJSON      bool
+)
+
+var agentReplayCmd = &cobra.Command{
+	Use:   "replay [session_id]",
+	Short: "Replay what an agent session did, step by step",
+	Long: `Reconstruct a session's timeline for a post-mortem: when it started, went
+idle, was blocked or ended, every sync attempt, merge conflicts and errors,
+the commits on its branch, its reviews and its issue's status changes.
+Steps where something went wrong are marked.
+
+With --summarize, an LLM writes a short post-mortem from the timeline
+(requires ANTHROPIC_API_KEY). Accepts a session ID prefix; auto-detects the
+session from cwd if no session_id is given.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return agentReplayRun(argOrEmpty(args))
+	},
+}
+
+func init() {
+	agentReplayCmd.Flags().BoolVar(&replaySummarize, "summarize", false, "Add an LLM-written post-mortem")
+	agentReplayCmd.Flags().BoolVar(&replayJSON, "json", false, "Output the timeline as JSON")
+	agentCmd.AddCommand(agentReplayCmd)
+}
+
+func agentReplayRun(sessionRef string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var sessionID string
+	if sessionRef == "" {
+		sessionID, err = resolveSessionFromCwd(ctx, s)
+	} else {
+		sessionID, err = findSessionID(ctx, s, sessionRef)
+	}
+	if err != nil {
+		return err
+	}
+
+	replay, err := agent.BuildReplay(ctx, s, git.NewClient(), sessionID)
+	if err != nil {
+		return err
+	}
+	if replaySummarize {
+		client := newLLMClient()
+		if client == nil {
+			return fmt.Errorf("--summarize: %s", capabilities.ReasonNoLLM)
+		}
+		if err := summarizeReplay(ctx, s, client, replay); err != nil {
+			return fmt.Errorf("summarize session: %w", err)
+		}
+	}
+
+	if replayJSON {
+		enc := json.NewEncoder(ui.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(replay)
+	}
+	printReplay(replay)
+	return nil
+}
+
+// summarizeReplay has the LLM write the replay's post-mortem, unless the
+// session's project is over its monthly LLM budget, and records the tokens
+// spent against the session's issue.
+func summarizeReplay(ctx context.Context, s store.Store, client *llm.Client, replay *agent.Replay) error {
+	sess, err := s.GetAgentSession(ctx, replay.SessionID)
+	if err != nil {
+		return err
+	}
+	p, err := s.GetProject(ctx, sess.ProjectID)
+	if err != nil {
+		return err
+	}
+	budget, err := llmBudget()
+	if err != nil {
+		return err
+	}
+	if err := budget.Check(ctx, s, p, time.Now()); err != nil {
+		return err
+	}
+	summary, usage, err := client.SummarizeSession(ctx, p.Name, replay.Narrative())
+	if usage != (llm.Usage{}) {
+		_ = s.RecordLLMUsage(ctx, usage.Record(p.ID, sess.IssueID, models.LLMUsageSessionSummary))
+	}
+	if err != nil {
+		return err
+	}
+	replay.Summary = summary
+	return nil
+}
+
+func printReplay(r *agent.Replay) {
+	fmt.Fprintf(ui.Out, "Session %s  %s  %s\n", output.Cyan(shortID(r.SessionID)), r.Branch, output.StatusColor(r.Status))
+	if r.ProjectName != "" {
+		fmt.Fprintf(ui.Out, "Project: %s\n", r.ProjectName)
+	}
+	if r.IssueTitle != "" {
+		fmt.Fprintf(ui.Out, "Issue:   %s  %s\n", shortID(r.IssueID), r.IssueTitle)
+	}
+	duration := "still open"
+	if r.EndedAt != nil {
+		duration = formatDuration(r.EndedAt.Sub(r.StartedAt))
+	}
+	fmt.Fprintf(ui.Out, "Started: %s (%s)\n\n", displayTime(r.StartedAt).Format("2006-01-02 15:04"), duration)
+
+	for i, e := range r.Entries {
+		summary := e.Summary
+		if e.Problem {
+			summary = output.Red("✗ " + summary)
+		}
+		if e.Cause != "" {
+			summary += output.Cyan(" (" + strings.ReplaceAll(e.Cause, "_", " ") + ")")
+		}
+		fmt.Fprintf(ui.Out, "%3d. %s  %s\n", i+1, displayTime(e.At).Format("01-02 15:04"), summary)
+		if e.Detail != "" {
+			fmt.Fprintf(ui.Out, "                   %s\n", e.Detail)
+		}
+	}
+
+	fmt.Fprintln(ui.Out)
+	if r.Problems == 0 {
+		ui.Success("No problems recorded")
+	} else {
+		ui.Warning("%d step(s) went wrong", r.Problems)
+	}
+	if r.Summary != "" {
+		fmt.Fprintf(ui.Out, "\n%s\n", r.Summary)
+	}
+}
+
+// findSessionID resolves a session by full ID or ID prefix.
+func findSessionID(ctx context.Context, s store.Store, ref string) (string, error) {
+	if sess, err := s.GetAgentSession(ctx, ref); err == nil {
+		return sess.ID, nil
+	}
+	if code, isSession, ok := models.ParseShortCode(ref); ok && isSession {
+		if sess, err := s.GetAgentSessionByCode(ctx, code); err == nil {
+			return sess.ID, nil
+		}
+	}
+
+	upper := strings.ToUpper(ref)
+	sessions, err := s.ListAgentSessions(ctx, "", 0)
+	if err != nil {
+		return "", err
+	}
+	var matches []*models.AgentSession
+	for _, sess := range sessions {
+		if strings.HasPrefix(sess.ID, upper) {
+			matches = append(matches, sess)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("session not found: %s", ref)
+	case 1:
+		return matches[0].ID, nil
+	default:
+		return "", fmt.Errorf("ambiguous session ID %s: matches %d sessions", ref, len(matches))
+	}
+}
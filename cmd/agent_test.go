@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+func TestParseSessionStatusFilter(t *testing.T) {
+	show, err := parseSessionStatusFilter("")
+	require.NoError(t, err)
+	assert.Equal(t, map[models.SessionStatus]bool{models.SessionStatusActive: true, models.SessionStatusIdle: true}, show)
+
+	show, err = parseSessionStatusFilter("blocked, completed")
+	require.NoError(t, err)
+	assert.Equal(t, map[models.SessionStatus]bool{models.SessionStatusBlocked: true, models.SessionStatusCompleted: true}, show)
+
+	for _, raw := range []string{"running", "active,", "Active"} {
+		_, err = parseSessionStatusFilter(raw)
+		assert.ErrorContains(t, err, "invalid session status", raw)
+	}
+}
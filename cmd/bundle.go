@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joescharf/pm/internal/bundle"
+	"github.com/joescharf/pm/internal/output"
+)
+
+var (
+	bundleOutput string
+	bundleNewIDs bool
+	bundleName   string
+	bundlePath   string
+)
+
+var exportBundleCmd = &cobra.Command{
+	Use:   "bundle [project]",
+	Short: "Export a project and its history as a portable bundle",
+	Long: `Write one project to a bundle file for moving it to another pm database,
+such as a new machine: the project record, its issues with their tags,
+comments, relations and reviews, its session history and its notes.
+Sessions carry no worktree.
+
+Auto-detects the project from the current directory. The bundle is written
+to <project>.pmz unless -o is given ("-" writes to stdout).`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportBundleRun(argOrEmpty(args))
+	},
+}
+
+var importDataCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import data exported from another pm database",
+}
+
+var importBundleCmd = &cobra.Command{
+	Use:   "bundle <file>",
+	Short: "Import a project bundle written by pm export bundle",
+	Long: `Create the project in a bundle, with all of its records and their original
+timestamps. Sessions that were still open have no worktree here and are
+imported as abandoned.
+
+Record IDs are kept, so importing into a database that already holds them
+fails; --new-ids gives every record a new ID instead, for importing a copy
+next to the original. Use --name and --path when the project's name or
+checkout path differs on this machine.
+
+Example: pm import bundle api.pmz --path ~/code/api`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importBundleRun(args[0])
+	},
+}
+
+func init() {
+	exportBundleCmd.Flags().StringVarP(&bundleOutput, "output", "o", "", "Bundle file to write (default: <project>.pmz)")
+	exportCmd.AddCommand(exportBundleCmd)
+
+	importBundleCmd.Flags().BoolVar(&bundleNewIDs, "new-ids", false, "Give every imported record a new ID")
+	importBundleCmd.Flags().StringVar(&bundleName, "name", "", "Project name (default: the bundle's)")
+	importBundleCmd.Flags().StringVar(&bundlePath, "path", "", "Project path on this machine (default: the bundle's)")
+	importDataCmd.AddCommand(importBundleCmd)
+	rootCmd.AddCommand(importDataCmd)
+}
+
+func exportBundleRun(projectRef string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	p, err := resolveProjectOrCwd(ctx, s, projectRef)
+	if err != nil {
+		return err
+	}
+	b, err := bundle.Export(ctx, s, p.ID)
+	if err != nil {
+		return err
+	}
+	b.Manifest.PMVersion = buildVersion
+
+	if bundleOutput == "-" {
+		return b.Write(os.Stdout)
+	}
+	path := bundleOutput
+	if path == "" {
+		path = p.Name + ".pmz"
+	}
+	if dryRun {
+		ui.DryRunMsg("Would write %s to %s (%s)", p.Name, path, bundleSummary(b.Manifest.Counts))
+		return nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := b.Write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	ui.Success("Exported %s to %s (%s)", output.Cyan(p.Name), path, bundleSummary(b.Manifest.Counts))
+	return nil
+}
+
+func importBundleRun(file string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	b, err := bundle.Read(f, info.Size())
+	if err != nil {
+		return err
+	}
+
+	opts := bundle.ImportOptions{NewIDs: bundleNewIDs, Name: bundleName}
+	if bundlePath != "" {
+		if opts.Path, err = filepath.Abs(bundlePath); err != nil {
+			return err
+		}
+	}
+	if dryRun {
+		name := b.Project.Name
+		if opts.Name != "" {
+			name = opts.Name
+		}
+		ui.DryRunMsg("Would import %s (%s)", name, bundleSummary(b.Manifest.Counts))
+		return nil
+	}
+	res, err := bundle.Import(ctx, s, b, opts)
+	if err != nil {
+		return err
+	}
+	ui.Success("Imported %s [%s] (%s)", output.Cyan(res.Project.Name), shortID(res.Project.ID), bundleSummary(res.Counts))
+	if res.Abandoned > 0 {
+		ui.Info("%d open session(s) imported as abandoned: their worktrees are on the other machine", res.Abandoned)
+	}
+	if _, err := os.Stat(res.Project.Path); err != nil {
+		ui.Warning("Project path %s does not exist here; re-import with --path to point it at the checkout", res.Project.Path)
+	}
+	return nil
+}
+
+// bundleSummary describes a bundle's record counts.
+func bundleSummary(counts map[string]int) string {
+	return fmt.Sprintf("%d issues, %d reviews, %d comments, %d sessions, %d notes",
+		counts["issues"], counts["reviews"], counts["comments"], counts["sessions"], counts["notes"])
+}
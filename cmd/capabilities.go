@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/capabilities"
+	"github.com/joescharf/pm/internal/git"
+)
+
+// detectCapabilities works out which optional integrations this host has.
+func detectCapabilities(llmConfigured bool) *capabilities.Capabilities {
+	return capabilities.Detect(capabilities.Probe{
+		LLMConfigured:   llmConfigured,
+		TerminalBackend: viper.GetString("agent.terminal"),
+		ForgeURL:        viper.GetString("github.api_url"),
+	})
+}
+
+// requireForge fails commands that need GitHub when gh is missing or logged
+// out, before they get as far as a gh error.
+func requireForge() error {
+	probe := capabilities.Probe{LLMConfigured: true, ForgeURL: viper.GetString("github.api_url")}
+	return capabilities.Detect(probe).Require(capabilities.Forge)
+}
+
+// newGitHubClient returns the GitHub client: gh, or the REST API at
+// github.api_url when set.
+func newGitHubClient() *git.RealGitHubClient {
+	if url := viper.GetString("github.api_url"); url != "" {
+		return git.NewGitHubAPIClient(url, os.Getenv("GITHUB_TOKEN"))
+	}
+	return git.NewGitHubClient()
+}
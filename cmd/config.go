@@ -79,11 +79,72 @@ const configTemplate = `# pm configuration
 # SQLite database path (default: ~/.config/pm/pm.db)
 # db_path: {{ .DBPath }}
 
+# Large text values (issue bodies and AI prompts, review summaries) of at
+# least compression_min_size bytes are stored compressed. compression is
+# "zstd" or "none"; changing it affects new writes, 'pm db compress' rewrites
+# the existing values. See 'pm db stats'.
+db:
+  compression: zstd
+  compression_min_size: 1024
+
+# Other pm databases shown with this one by pm status --all-profiles and the
+# read-only GET /api/v1/combined/... endpoints. Each profile has a db_path or
+# the url of a pm server (plus a token if it requires auth); projects are
+# named "<profile>/<project>". profile_name names this database.
+profile_name: default
+profiles: []
+#   - name: personal
+#     db_path: /Users/me/.config/pm/personal.db
+#   - name: team
+#     url: http://pm.internal:8080
+#     token: pm_...
+
 # GitHub
 github:
   # Default GitHub organization for project lookups
   default_org: "{{ .GitHubDefaultOrg }}"
 
+  # Base URL of a GitHub-compatible REST API to call instead of the gh CLI,
+  # such as a pm fakeforge server for offline development. Empty uses gh.
+  api_url: ""
+
+  # Import human reviews of session pull requests into their issues as
+  # comments (pm issue pr-reviews). pm serve syncs every interval ("15m";
+  # "0" disables); follow_ups opens a follow-up issue for each review
+  # requesting changes.
+  review_sync:
+    interval: "0"
+    follow_ups: false
+
+  # Sync issue status and priority with each project's GitHub Projects (v2)
+  # board (pm project board, pm issue board-sync). pm serve syncs every
+  # interval ("0" disables). columns maps pm statuses to options of the
+  # board's status field and priorities pm priorities to its priority field's;
+  # entries left out keep their defaults.
+  board_sync:
+    interval: "0"
+    status_field: Status
+    priority_field: Priority
+    # columns:
+    #   open: Todo
+    #   in_progress: In Progress
+    #   in_review: In Progress
+    #   done: Done
+    #   closed: Done
+    # priorities:
+    #   low: Low
+    #   medium: Medium
+    #   high: High
+
+  # pm serve keeps each project's latest GitHub release in the database,
+  # fetching it every active interval for projects with an open session or
+  # a commit within active_within, and every idle interval for the others.
+  # Status and health read the stored release ("0" active disables polling).
+  release_poll:
+    active: 15m
+    idle: 6h
+    active_within: 7d
+
 # Agent settings
 agent:
   # Claude model to use (default: "opus")
@@ -91,6 +152,183 @@ agent:
 
   # Auto-launch Claude agent when creating worktrees (default: false)
   auto_launch: {{ .AgentAutoLaunch }}
+
+  # Terminal backend pm serve uses to spawn sessions for API launches with
+  # spawn=true: "", "iterm" or "tmux" (default: "" = disabled)
+  terminal: ""
+
+# Time zone CLI output shows times in: "UTC", "Local" or an IANA name such as
+# "Europe/Berlin" (default: "" = the system's). Stored and API times are UTC.
+display:
+  timezone: ""
+
+# Session settings
+sessions:
+  # Thresholds for stale-session cleanup (DELETE /api/v1/sessions/cleanup)
+  # and the purge before a launch reuses a branch. A session is stale when it
+  # has one of the statuses, at most max_commits commits, ran for less than
+  # max_duration (required, e.g. "60s"), and ended at least min_age ago ("7d").
+  cleanup:
+    statuses: [abandoned]
+    max_commits: 0
+    max_duration: "60s"
+    min_age: "0s"
+
+  # Refuse to complete a session while close-check reports dirty, unmerged,
+  # behind or conflict warnings, unless the caller forces it: the API answers
+  # 409 and pm agent close --done asks first (or fails without a terminal).
+  close:
+    enforce_check: false
+
+  # Remind about sessions blocked longer than this in pm agent list, pm digest
+  # and pm_my_focus ("3d", "36h"; "0" disables reminders)
+  blocked:
+    remind_after: "3d"
+
+  # An active session locks its issue: launches on it from any surface are
+  # refused until the session closes or goes this long without activity
+  # ("2h", "1d"; "0" never expires).
+  issue_lock:
+    expire_after: "2h"
+
+  # pm serve counts MCP tool calls and file changes in worktrees as session
+  # activity, writing LastActiveAt at most once per interval per session.
+  # watch: false stops watching worktrees.
+  heartbeat:
+    interval: "1m"
+    watch: true
+
+  # Discovering untracked worktrees across all projects scans workers
+  # projects at once, gives up on one after timeout, and skips the projects
+  # named or under the paths in exclude (e.g. a volume that is often
+  # unmounted). Archived projects and missing paths are always skipped.
+  discover:
+    workers: 4
+    timeout: "30s"
+    exclude: []
+
+# Review settings
+review:
+  # On a failed pm_save_review, launch a follow-up session on the same branch
+  # seeded with the failure reasons (default: false)
+  auto_relaunch: false
+
+  # Maximum follow-up sessions per issue before giving up (0 = no limit)
+  max_attempts: 3
+
+  # Have pm_prepare_review ask the LLM for a pre-analysis of the diff (risk
+  # areas, files to scrutinize, missing tests); needs ANTHROPIC_API_KEY and
+  # records its token usage (default: false)
+  pre_analysis: false
+
+  # Review links for reviewers without pm (pm issue share): how long they
+  # stay open ("72h", "3d", "1w"), and the URL they start with. The URL is
+  # required unless pm serve is reached on a loopback address
+  share:
+    ttl: "3d"
+    base_url: ""
+
+# Notifications
+notify:
+  # SMTP digest email (pm digest send). Only projects listed under projects
+  # are included; recipients can opt out by being removed from to.
+  email:
+    host: ""
+    port: 587
+    username: ""
+    password: ""
+    from: ""
+    to: []
+    projects: []
+    # Send automatically from pm serve: "", "daily" or "weekly"
+    schedule: ""
+  # Browser notifications from pm serve when sessions complete or hit
+  # conflicts. Enable them from the dashboard; the key pair is kept in
+  # <state_dir>/vapid.json.
+  push:
+    enabled: true
+    # Contact push services can reach the sender at (mailto: or https: URL)
+    subject: "mailto:pm@localhost"
+    # How often sessions are checked for changes
+    interval: "15s"
+  # Issue webhook watchers. With a secret, each POST carries the body's
+  # HMAC-SHA256 in X-PM-Signature-256 ("sha256=<hex>").
+  webhook:
+    secret: ""
+
+# Git command execution
+git:
+  # Maximum git subprocesses running at once (default: 8)
+  max_concurrent: 8
+  # Kill a git command that runs longer than this (default: "1m0s")
+  timeout: "1m0s"
+
+# API settings
+api:
+  # How long pm serve caches the status, project dashboard and health
+  # endpoints. Any write clears the cache. "0s" disables caching.
+  cache_ttl: "30s"
+  # Per-session limit for each enrichment of GET /api/v1/sessions?expand=
+  expand_timeout: "5s"
+
+# Inbound issue intake (POST /api/v1/intake). Requests must send the secret
+# in the X-PM-Intake-Secret header; intake is disabled while it is empty.
+intake:
+  secret: ""
+  # Project for messages without a ?project= parameter, [project] subject
+  # tag or matching route
+  default_project: ""
+  # Recipient address -> project name
+  routes: {}
+  #   bugs+api@example.com: api
+
+# Redaction of issue text before it is sent to the LLM (enrichment, import).
+# Built-in rules catch private keys, common API tokens, passwords in
+# key=value form, URL credentials and email addresses; min_entropy also
+# catches random-looking tokens (0 disables). Matches of an allow pattern are
+# kept; deny patterns are always redacted.
+redaction:
+  enabled: true
+  min_entropy: 4.0
+  allow: []
+  #   - "@example\\.com$"
+  deny: []
+  #   - "CUST-[0-9]{6}"
+  # Per-project additions, keyed by project name
+  projects: {}
+  #   internal-tools:
+  #     disabled: true
+
+# Monthly LLM spend caps per project (0 = uncapped), checked before issue
+# enrichment, import and review pre-analysis call the LLM. Costs are
+# estimated from the recorded token usage at the prices below (USD per
+# million tokens). Over budget, on_exceeded "heuristics" skips the LLM and
+# falls back to what pm does without one; "reject" fails the request.
+llm:
+  budget:
+    monthly_tokens: 0
+    monthly_cost: 0
+    input_cost_per_mtok: 1.0
+    output_cost_per_mtok: 5.0
+    on_exceeded: heuristics
+    # Per-project limits replacing the ones above, keyed by project name
+    projects: {}
+    #   api:
+    #     monthly_tokens: 2000000
+    #     monthly_cost: 10
+
+# Issue SLA policies (pm report sla, GET /api/v1/reports/sla). The first policy
+# whose priority and type match an issue applies; leave either empty to match
+# any. Deadlines accept Go durations or days/weeks ("3d", "2w").
+sla:
+  # How often pm serve re-evaluates policies and records breaches
+  interval: "1h"
+  policies: []
+  # - name: high-bugs
+  #   priority: high
+  #   type: bug
+  #   start_within: 3d
+  #   complete_within: 14d
 `
 
 type configTemplateData struct {
@@ -174,9 +412,76 @@ type configKeyInfo struct {
 var configKeys = []configKeyInfo{
 	{Key: "state_dir", EnvVar: "PM_STATE_DIR"},
 	{Key: "db_path", EnvVar: "PM_DB_PATH"},
+	{Key: "db.compression", EnvVar: "PM_DB_COMPRESSION"},
+	{Key: "db.compression_min_size", EnvVar: "PM_DB_COMPRESSION_MIN_SIZE"},
+	{Key: "profile_name", EnvVar: "PM_PROFILE_NAME"},
 	{Key: "github.default_org", EnvVar: "PM_GITHUB_DEFAULT_ORG"},
+	{Key: "github.api_url", EnvVar: "PM_GITHUB_API_URL"},
+	{Key: "github.review_sync.interval", EnvVar: "PM_GITHUB_REVIEW_SYNC_INTERVAL"},
+	{Key: "github.review_sync.follow_ups", EnvVar: "PM_GITHUB_REVIEW_SYNC_FOLLOW_UPS"},
+	{Key: "github.board_sync.interval", EnvVar: "PM_GITHUB_BOARD_SYNC_INTERVAL"},
+	{Key: "github.board_sync.status_field", EnvVar: "PM_GITHUB_BOARD_SYNC_STATUS_FIELD"},
+	{Key: "github.board_sync.priority_field", EnvVar: "PM_GITHUB_BOARD_SYNC_PRIORITY_FIELD"},
+	{Key: "github.release_poll.active", EnvVar: "PM_GITHUB_RELEASE_POLL_ACTIVE"},
+	{Key: "github.release_poll.idle", EnvVar: "PM_GITHUB_RELEASE_POLL_IDLE"},
+	{Key: "github.release_poll.active_within", EnvVar: "PM_GITHUB_RELEASE_POLL_ACTIVE_WITHIN"},
 	{Key: "agent.model", EnvVar: "PM_AGENT_MODEL"},
 	{Key: "agent.auto_launch", EnvVar: "PM_AGENT_AUTO_LAUNCH"},
+	{Key: "agent.terminal", EnvVar: "PM_AGENT_TERMINAL"},
+	{Key: "display.timezone", EnvVar: "PM_DISPLAY_TIMEZONE"},
+	{Key: "sessions.cleanup.statuses", EnvVar: "PM_SESSIONS_CLEANUP_STATUSES"},
+	{Key: "sessions.cleanup.max_commits", EnvVar: "PM_SESSIONS_CLEANUP_MAX_COMMITS"},
+	{Key: "sessions.cleanup.max_duration", EnvVar: "PM_SESSIONS_CLEANUP_MAX_DURATION"},
+	{Key: "sessions.cleanup.min_age", EnvVar: "PM_SESSIONS_CLEANUP_MIN_AGE"},
+	{Key: "sessions.close.enforce_check", EnvVar: "PM_SESSIONS_CLOSE_ENFORCE_CHECK"},
+	{Key: "sessions.blocked.remind_after", EnvVar: "PM_SESSIONS_BLOCKED_REMIND_AFTER"},
+	{Key: "sessions.issue_lock.expire_after", EnvVar: "PM_SESSIONS_ISSUE_LOCK_EXPIRE_AFTER"},
+	{Key: "sessions.reconcile.interval", EnvVar: "PM_SESSIONS_RECONCILE_INTERVAL"},
+	{Key: "sessions.reconcile.cooldown", EnvVar: "PM_SESSIONS_RECONCILE_COOLDOWN"},
+	{Key: "sessions.heartbeat.interval", EnvVar: "PM_SESSIONS_HEARTBEAT_INTERVAL"},
+	{Key: "sessions.heartbeat.watch", EnvVar: "PM_SESSIONS_HEARTBEAT_WATCH"},
+	{Key: "sessions.discover.workers", EnvVar: "PM_SESSIONS_DISCOVER_WORKERS"},
+	{Key: "sessions.discover.timeout", EnvVar: "PM_SESSIONS_DISCOVER_TIMEOUT"},
+	{Key: "sessions.discover.exclude", EnvVar: "PM_SESSIONS_DISCOVER_EXCLUDE"},
+	{Key: "autoclose.enabled", EnvVar: "PM_AUTOCLOSE_ENABLED"},
+	{Key: "autoclose.github", EnvVar: "PM_AUTOCLOSE_GITHUB"},
+	{Key: "review.auto_relaunch", EnvVar: "PM_REVIEW_AUTO_RELAUNCH"},
+	{Key: "review.max_attempts", EnvVar: "PM_REVIEW_MAX_ATTEMPTS"},
+	{Key: "review.pre_analysis", EnvVar: "PM_REVIEW_PRE_ANALYSIS"},
+	{Key: "review.share.ttl", EnvVar: "PM_REVIEW_SHARE_TTL"},
+	{Key: "review.share.base_url", EnvVar: "PM_REVIEW_SHARE_BASE_URL"},
+	{Key: "notify.email.host", EnvVar: "PM_NOTIFY_EMAIL_HOST"},
+	{Key: "notify.email.port", EnvVar: "PM_NOTIFY_EMAIL_PORT"},
+	{Key: "notify.email.username", EnvVar: "PM_NOTIFY_EMAIL_USERNAME"},
+	{Key: "notify.email.from", EnvVar: "PM_NOTIFY_EMAIL_FROM"},
+	{Key: "notify.email.to", EnvVar: "PM_NOTIFY_EMAIL_TO"},
+	{Key: "notify.email.projects", EnvVar: "PM_NOTIFY_EMAIL_PROJECTS"},
+	{Key: "notify.email.schedule", EnvVar: "PM_NOTIFY_EMAIL_SCHEDULE"},
+	{Key: "notify.push.enabled", EnvVar: "PM_NOTIFY_PUSH_ENABLED"},
+	{Key: "notify.push.subject", EnvVar: "PM_NOTIFY_PUSH_SUBJECT"},
+	{Key: "notify.push.interval", EnvVar: "PM_NOTIFY_PUSH_INTERVAL"},
+	{Key: "notify.watch.interval", EnvVar: "PM_NOTIFY_WATCH_INTERVAL"},
+	{Key: "intake.default_project", EnvVar: "PM_INTAKE_DEFAULT_PROJECT"},
+	{Key: "redaction.enabled", EnvVar: "PM_REDACTION_ENABLED"},
+	{Key: "redaction.min_entropy", EnvVar: "PM_REDACTION_MIN_ENTROPY"},
+	{Key: "llm.budget.monthly_tokens", EnvVar: "PM_LLM_BUDGET_MONTHLY_TOKENS"},
+	{Key: "llm.budget.monthly_cost", EnvVar: "PM_LLM_BUDGET_MONTHLY_COST"},
+	{Key: "llm.budget.input_cost_per_mtok", EnvVar: "PM_LLM_BUDGET_INPUT_COST_PER_MTOK"},
+	{Key: "llm.budget.output_cost_per_mtok", EnvVar: "PM_LLM_BUDGET_OUTPUT_COST_PER_MTOK"},
+	{Key: "llm.budget.on_exceeded", EnvVar: "PM_LLM_BUDGET_ON_EXCEEDED"},
+	{Key: "git.max_concurrent", EnvVar: "PM_GIT_MAX_CONCURRENT"},
+	{Key: "git.timeout", EnvVar: "PM_GIT_TIMEOUT"},
+	{Key: "api.cache_ttl", EnvVar: "PM_API_CACHE_TTL"},
+	{Key: "api.expand_timeout", EnvVar: "PM_API_EXPAND_TIMEOUT"},
+	{Key: "bind", EnvVar: "PM_BIND"},
+	{Key: "grpc", EnvVar: "PM_GRPC"},
+	{Key: "api.require_auth", EnvVar: "PM_API_REQUIRE_AUTH"},
+	{Key: "api.public_badges", EnvVar: "PM_API_PUBLIC_BADGES"},
+	{Key: "api.cors.allowed_origins", EnvVar: "PM_API_CORS_ALLOWED_ORIGINS"},
+	{Key: "server.allow_remote", EnvVar: "PM_SERVER_ALLOW_REMOTE"},
+	{Key: "server.watch_config", EnvVar: "PM_SERVER_WATCH_CONFIG"},
+	{Key: "sla.interval", EnvVar: "PM_SLA_INTERVAL"},
+	{Key: "changes.retention", EnvVar: "PM_CHANGES_RETENTION"},
 }
 
 func configShowRun() error {
@@ -250,13 +555,22 @@ func detectSource(key, envVar string, fileValues map[string]bool) string {
 	return "(default)"
 }
 
-func configEditRun() error {
+// editorFromEnv returns the user's editor from $EDITOR or $VISUAL.
+func editorFromEnv() (string, error) {
 	editor := os.Getenv("EDITOR")
 	if editor == "" {
 		editor = os.Getenv("VISUAL")
 	}
 	if editor == "" {
-		return fmt.Errorf("$EDITOR is not set — set it to your preferred editor (e.g. export EDITOR=vim)")
+		return "", fmt.Errorf("$EDITOR is not set — set it to your preferred editor (e.g. export EDITOR=vim)")
+	}
+	return editor, nil
+}
+
+func configEditRun() error {
+	editor, err := editorFromEnv()
+	if err != nil {
+		return err
 	}
 
 	cfgPath, err := configFilePath()
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -178,3 +179,18 @@ func TestConfigInit_DryRun(t *testing.T) {
 	_, err = os.Stat(cfgPath)
 	assert.True(t, os.IsNotExist(err), "config file should not exist in dry-run mode")
 }
+
+func TestDisplayTime(t *testing.T) {
+	testEnv(t)
+	at := time.Date(2026, 3, 2, 1, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Local, displayTime(at).Location(), "system zone by default")
+
+	viper.Set("display.timezone", "UTC")
+	assert.Equal(t, "2026-03-02 01:30", displayTime(at).Format("2006-01-02 15:04"))
+
+	viper.Set("display.timezone", "Nowhere/Atlantis")
+	got := displayTime(at)
+	assert.Equal(t, time.Local, got.Location(), "invalid zones fall back to the system's")
+	assert.True(t, at.Equal(got))
+}
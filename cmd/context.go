@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+var (
+	contextCwd  string
+	contextJSON bool
+)
+
+var contextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Print the project, session and issue of a directory",
+	Long: `Print which tracked project, agent session and issue a directory belongs
+to, on one line, for shell prompts and tmux status bars. Prints nothing when
+the directory is not in a tracked project.
+
+Results are cached in the state directory until the database changes, so
+repeated calls don't open it.
+
+Examples:
+  PS1='$(pm context) \$ '
+  set -g status-right '#(pm context --cwd "#{pane_current_path}")'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return contextRun(contextCwd)
+	},
+}
+
+func init() {
+	contextCmd.Flags().StringVar(&contextCwd, "cwd", "", "Directory to describe (default: current directory)")
+	contextCmd.Flags().BoolVar(&contextJSON, "json", false, "Output the context as JSON")
+	rootCmd.AddCommand(contextCmd)
+}
+
+// dirContext is what pm context reports for a directory. Session and Issue
+// are only set inside an open session's worktree.
+type dirContext struct {
+	Cwd     string      `json:"cwd"`
+	Project *ctxProject `json:"project,omitempty"`
+	Session *ctxSession `json:"session,omitempty"`
+	Issue   *ctxIssue   `json:"issue,omitempty"`
+}
+
+type ctxProject struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+type ctxSession struct {
+	ID           string               `json:"id"`
+	Code         string               `json:"code"`
+	Branch       string               `json:"branch"`
+	Status       models.SessionStatus `json:"status"`
+	WorktreePath string               `json:"worktree_path"`
+}
+
+type ctxIssue struct {
+	ID     string             `json:"id"`
+	Code   string             `json:"code"`
+	Title  string             `json:"title"`
+	Status models.IssueStatus `json:"status"`
+}
+
+// maxContextTitle caps the issue title in the one-line output.
+const maxContextTitle = 40
+
+// Line renders the context for a prompt: project, branch and status of the
+// session, then the issue. It is empty outside tracked projects.
+func (c *dirContext) Line() string {
+	if c.Project == nil {
+		return ""
+	}
+	parts := []string{c.Project.Name}
+	if c.Session != nil {
+		parts = append(parts, fmt.Sprintf("%s (%s)", c.Session.Branch, c.Session.Status))
+	}
+	if c.Issue != nil {
+		title := c.Issue.Title
+		if r := []rune(title); len(r) > maxContextTitle {
+			title = string(r[:maxContextTitle-1]) + "…"
+		}
+		ref := c.Issue.Code
+		if ref == "" {
+			ref = shortID(c.Issue.ID)
+		}
+		parts = append(parts, ref+" "+title)
+	}
+	return strings.Join(parts, " · ")
+}
+
+func contextRun(dir string) error {
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get working directory: %w", err)
+		}
+		dir = wd
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	cache := loadContextCache()
+	c, ok := cache.lookup(dir, databaseStamp())
+	if !ok {
+		s, err := getStore()
+		if err != nil {
+			return err
+		}
+		if c, err = resolveDirContext(context.Background(), s, dir); err != nil {
+			return err
+		}
+		// Stamp after opening the database, which may create its WAL file.
+		cache.save(dir, databaseStamp(), c)
+	}
+
+	if contextJSON {
+		enc := json.NewEncoder(ui.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(c)
+	}
+	if line := c.Line(); line != "" {
+		fmt.Fprintln(ui.Out, line)
+	}
+	return nil
+}
+
+// resolveDirContext finds the open session whose worktree, or else the
+// project whose path, is dir or its nearest ancestor.
+func resolveDirContext(ctx context.Context, s store.Store, dir string) (*dirContext, error) {
+	c := &dirContext{Cwd: dir}
+	for d := dir; ; d = filepath.Dir(d) {
+		if sess, err := s.GetAgentSessionByWorktreePath(ctx, d); err == nil {
+			p, err := s.GetProject(ctx, sess.ProjectID)
+			if err != nil {
+				return nil, err
+			}
+			c.Project = &ctxProject{ID: p.ID, Name: p.Name, Path: p.Path}
+			c.Session = &ctxSession{ID: sess.ID, Code: sess.Code, Branch: sess.Branch, Status: sess.Status, WorktreePath: sess.WorktreePath}
+			if sess.IssueID != "" {
+				if issue, err := s.GetIssue(ctx, sess.IssueID); err == nil {
+					c.Issue = &ctxIssue{ID: issue.ID, Code: issue.Code, Title: issue.Title, Status: issue.Status}
+				}
+			}
+			return c, nil
+		}
+		if p, err := s.GetProjectByPath(ctx, d); err == nil {
+			c.Project = &ctxProject{ID: p.ID, Name: p.Name, Path: p.Path}
+			return c, nil
+		}
+		if filepath.Dir(d) == d {
+			return c, nil
+		}
+	}
+}
+
+// maxContextCacheEntries bounds the cache; it starts over when full.
+const maxContextCacheEntries = 256
+
+// contextCache holds resolved contexts by directory, for as long as the
+// database is unchanged.
+type contextCache struct {
+	Stamp   string                 `json:"stamp"`
+	Entries map[string]*dirContext `json:"entries"`
+}
+
+func contextCachePath() string {
+	return filepath.Join(viper.GetString("state_dir"), "context-cache.json")
+}
+
+// loadContextCache reads the cache, returning an empty one if it is missing
+// or unreadable.
+func loadContextCache() *contextCache {
+	var cache contextCache
+	if data, err := os.ReadFile(contextCachePath()); err == nil {
+		_ = json.Unmarshal(data, &cache)
+	}
+	return &cache
+}
+
+func (c *contextCache) lookup(dir, stamp string) (*dirContext, bool) {
+	if stamp == "" || c.Stamp != stamp {
+		return nil, false
+	}
+	dc, ok := c.Entries[dir]
+	return dc, ok
+}
+
+// save records dir's context under stamp and writes the cache. Failures are
+// ignored: the next call resolves the context again.
+func (c *contextCache) save(dir, stamp string, dc *dirContext) {
+	if stamp == "" {
+		return
+	}
+	if c.Stamp != stamp || len(c.Entries) >= maxContextCacheEntries {
+		c.Stamp = stamp
+		c.Entries = map[string]*dirContext{}
+	}
+	c.Entries[dir] = dc
+	data, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+	tmp := contextCachePath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, contextCachePath())
+}
+
+// databaseStamp identifies the database's current contents by the size and
+// modification time of its file and WAL, which every write changes. It is
+// empty if the database does not exist.
+func databaseStamp() string {
+	dbPath := viper.GetString("db_path")
+	var parts []string
+	for _, path := range []string{dbPath, dbPath + "-wal"} {
+		info, err := os.Stat(path)
+		if err != nil {
+			if path == dbPath {
+				return ""
+			}
+			parts = append(parts, "-")
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%d:%d", info.Size(), info.ModTime().UnixNano()))
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+func TestResolveDirContext(t *testing.T) {
+	s := store.NewMemoryStore()
+	ctx := context.Background()
+
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Add rate limiting to every public endpoint of the API", Status: models.IssueStatusInProgress, Priority: models.IssuePriorityHigh, Type: models.IssueTypeFeature}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	sess := &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "feature/rate-limit", WorktreePath: "/code/api-feature-rate-limit", Status: models.SessionStatusActive}
+	require.NoError(t, s.CreateAgentSession(ctx, sess))
+
+	c, err := resolveDirContext(ctx, s, "/code/api-feature-rate-limit/internal/api")
+	require.NoError(t, err)
+	require.NotNil(t, c.Session)
+	assert.Equal(t, sess.ID, c.Session.ID)
+	assert.Equal(t, issue.ID, c.Issue.ID)
+	assert.Equal(t, "api · feature/rate-limit (active) · API-1 Add rate limiting to every public endpo…", c.Line())
+
+	c, err = resolveDirContext(ctx, s, "/code/api/cmd")
+	require.NoError(t, err)
+	assert.Equal(t, "api", c.Line())
+	assert.Nil(t, c.Session)
+
+	c, err = resolveDirContext(ctx, s, "/code/web")
+	require.NoError(t, err)
+	assert.Nil(t, c.Project)
+	assert.Empty(t, c.Line())
+}
+
+func TestContextCache(t *testing.T) {
+	dir := testEnv(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pm.db"), []byte("db"), 0644))
+	stamp := databaseStamp()
+	require.NotEmpty(t, stamp)
+
+	dc := &dirContext{Cwd: "/code/api", Project: &ctxProject{Name: "api"}}
+	loadContextCache().save("/code/api", stamp, dc)
+
+	got, ok := loadContextCache().lookup("/code/api", stamp)
+	require.True(t, ok)
+	assert.Equal(t, dc, got)
+	_, ok = loadContextCache().lookup("/code/web", stamp)
+	assert.False(t, ok)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pm.db-wal"), []byte("write"), 0644))
+	assert.NotEqual(t, stamp, databaseStamp(), "a write to the WAL changes the stamp")
+	_, ok = loadContextCache().lookup("/code/api", databaseStamp())
+	assert.False(t, ok)
+}
@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/store"
+)
+
+var (
+	dbMigrateTo       int
+	dbMigratePlan     bool
+	dbMigrateNoBackup bool
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Inspect and migrate the pm database schema",
+	Long: `Inspect and migrate the pm database schema.
+
+pm migrates the database to the latest schema automatically, after an
+integrity check and a backup next to the database file. Use 'pm db migrate'
+to preview a migration (--plan) or to move to a specific version, including
+down to an older one (--to).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return dbStatusRun()
+	},
+}
+
+var dbStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the schema version and migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return dbStatusRun()
+	},
+}
+
+var dbStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the database size, tables, indexes and compressed text",
+	Long: `Show the size of the database file and its WAL, the rows and bytes of
+each table, the bytes of each index and, for each large text column (issue
+bodies and AI prompts, review summaries), how many values are stored
+compressed and how much space compression saves.
+
+SQLite keeps no index usage counters. Once 'pm db maintain' has run ANALYZE,
+the Rows/key column shows how many rows share an index key on average: an
+index whose keys each match a large share of its table rarely helps.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return dbStatsRun()
+	},
+}
+
+var dbCompressCmd = &cobra.Command{
+	Use:   "compress",
+	Short: "Rewrite large text values under the current compression settings",
+	Long: `Rewrite every large text value under the current db.compression and
+db.compression_min_size settings, compressing or decompressing it, then
+vacuum the database to give the freed space back. New writes follow the
+settings on their own; run this after changing them.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return dbCompressRun()
+	},
+}
+
+var dbMaintainCheckOnly bool
+
+var dbMaintainCmd = &cobra.Command{
+	Use:   "maintain",
+	Short: "Check the database's integrity and orphan rows, then ANALYZE and VACUUM",
+	Long: `Run SQLite's full integrity check and report orphan rows: rows whose
+reference to another table's row dangles, both for declared foreign keys and
+for the references pm keeps itself (a session's issue, a review's session).
+Orphans are reported, not deleted.
+
+Unless --check-only (or --dry-run) is given, it then refreshes the query
+planner's statistics (ANALYZE), truncates the WAL and rebuilds the file
+(VACUUM). A database that fails the integrity check is left untouched; restore
+it from a backup. pm serve exposes the same at POST /api/v1/admin/db/maintain.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return dbMaintainRun()
+	},
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the schema to the latest or a given version",
+	Long: `Migrate the schema to the latest version, or up or down to --to <version>.
+
+Before changing an existing database pm checks its integrity and backs it up
+to <db_path>.vNNN-<timestamp>.bak (skip with --no-backup). --plan (or
+--dry-run) lists the migrations that would run without applying them.
+Migrations without a down file are irreversible and stop a downgrade.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target := store.LatestSchemaVersion()
+		if cmd.Flags().Changed("to") {
+			target = dbMigrateTo
+		}
+		return dbMigrateRun(target)
+	},
+}
+
+func init() {
+	dbMigrateCmd.Flags().IntVar(&dbMigrateTo, "to", 0, "Schema version to migrate to (default: latest)")
+	dbMigrateCmd.Flags().BoolVar(&dbMigratePlan, "plan", false, "Show the migrations that would run without applying them")
+	dbMigrateCmd.Flags().BoolVar(&dbMigrateNoBackup, "no-backup", false, "Do not back up the database before migrating")
+	dbCmd.AddCommand(dbStatusCmd)
+	dbCmd.AddCommand(dbMigrateCmd)
+	dbCmd.AddCommand(dbStatsCmd)
+	dbCmd.AddCommand(dbCompressCmd)
+	dbMaintainCmd.Flags().BoolVar(&dbMaintainCheckOnly, "check-only", false, "Only check integrity and report orphans; skip ANALYZE and VACUUM")
+	dbCmd.AddCommand(dbMaintainCmd)
+	rootCmd.AddCommand(dbCmd)
+}
+
+func dbStatusRun() error {
+	s, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	version, err := s.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	migrations, err := s.Migrations(ctx)
+	if err != nil {
+		return err
+	}
+	latest := store.LatestSchemaVersion()
+
+	table := ui.Table([]string{"Version", "Migration", "Applied", "Down"})
+	pending := 0
+	for _, m := range migrations {
+		applied := output.Yellow("pending")
+		if m.AppliedAt != nil {
+			applied = displayTime(*m.AppliedAt).Format("2006-01-02 15:04")
+		} else {
+			pending++
+		}
+		down := "no"
+		if m.Reversible {
+			down = "yes"
+		}
+		_ = table.Append([]string{fmt.Sprintf("%d", m.Version), strings.TrimSuffix(m.Name, ".sql"), applied, down})
+	}
+	_ = table.Render()
+
+	fmt.Fprintf(ui.Out, "\nDatabase: %s\n", viper.GetString("db_path"))
+	fmt.Fprintf(ui.Out, "Schema version: %d (this binary: %d)\n", version, latest)
+	switch {
+	case version > latest:
+		ui.Warning("%v", &store.SchemaVersionError{Database: version, Binary: latest})
+	case pending > 0:
+		ui.Info("%d pending migration(s); run 'pm db migrate' or any pm command to apply them", pending)
+	}
+	return nil
+}
+
+func dbMigrateRun(target int) error {
+	s, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	plan, err := s.PlanMigration(ctx, target)
+	if err != nil {
+		return err
+	}
+	if len(plan.Steps) == 0 {
+		ui.Info("Schema is at version %d; nothing to migrate", plan.From)
+		return nil
+	}
+
+	direction, verb := "up", "apply"
+	if plan.Down {
+		direction, verb = "down", "revert"
+	}
+	ui.Info("Migrating %s from version %d to %d:", direction, plan.From, plan.To)
+	for _, m := range plan.Steps {
+		fmt.Fprintf(ui.Out, "  %s %s\n", verb, m.Name)
+	}
+
+	if dryRun {
+		ui.DryRunMsg("Would %s %d migration(s)", verb, len(plan.Steps))
+		return nil
+	}
+	if dbMigratePlan {
+		return nil
+	}
+
+	backup, err := s.ApplyMigration(ctx, plan, !dbMigrateNoBackup)
+	if backup != "" {
+		ui.Info("Backed up database to %s", backup)
+	}
+	if err != nil {
+		return err
+	}
+	ui.Success("Schema migrated to version %s", output.Cyan(fmt.Sprintf("%d", plan.To)))
+	return nil
+}
+
+func dbStatsRun() error {
+	s, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+	stats, err := s.Stats(context.Background())
+	if err != nil {
+		return err
+	}
+
+	tables := ui.Table([]string{"Table", "Rows", "Size"})
+	for _, t := range stats.Tables {
+		_ = tables.Append([]string{t.Name, fmt.Sprintf("%d", t.Rows), formatBytes(t.Bytes)})
+	}
+	_ = tables.Render()
+	fmt.Fprintln(ui.Out)
+
+	indexes := ui.Table([]string{"Index", "Table", "Size", "Rows/key"})
+	for _, idx := range stats.Indexes {
+		perKey := "-"
+		if idx.RowsPerKey > 0 {
+			perKey = fmt.Sprintf("%d", idx.RowsPerKey)
+		}
+		_ = indexes.Append([]string{idx.Name, idx.Table, formatBytes(idx.Bytes), perKey})
+	}
+	_ = indexes.Render()
+	fmt.Fprintln(ui.Out)
+
+	columns := ui.Table([]string{"Column", "Values", "Compressed", "Stored", "Text", "Saved"})
+	for _, c := range stats.Columns {
+		saved := "-"
+		if c.TextBytes > 0 {
+			saved = fmt.Sprintf("%.0f%%", 100*float64(c.TextBytes-c.StoredBytes)/float64(c.TextBytes))
+		}
+		_ = columns.Append([]string{
+			c.Table + "." + c.Column,
+			fmt.Sprintf("%d", c.Values),
+			fmt.Sprintf("%d", c.Compressed),
+			formatBytes(c.StoredBytes),
+			formatBytes(c.TextBytes),
+			saved,
+		})
+	}
+	_ = columns.Render()
+
+	fmt.Fprintf(ui.Out, "\nDatabase: %s\n", stats.Path)
+	fmt.Fprintf(ui.Out, "Size: %s (%d pages of %s, %d free), WAL: %s\n",
+		formatBytes(stats.SizeBytes()), stats.Pages, formatBytes(stats.PageSize), stats.FreePages, formatBytes(stats.WALBytes))
+	if stats.Compression.Codec == "none" {
+		fmt.Fprintln(ui.Out, "Compression: off")
+	} else {
+		fmt.Fprintf(ui.Out, "Compression: %s, values of %s or more\n", stats.Compression.Codec, formatBytes(int64(stats.Compression.MinSize)))
+	}
+	return nil
+}
+
+func dbMaintainRun() error {
+	s, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+
+	checkOnly := dbMaintainCheckOnly || dryRun
+	report, err := s.Maintain(context.Background(), checkOnly)
+	if report != nil {
+		for _, problem := range report.Integrity {
+			ui.Warning("Integrity: %s", problem)
+		}
+		if len(report.Integrity) == 0 {
+			ui.Success("Integrity check passed")
+		}
+		if len(report.Orphans) == 0 {
+			ui.Success("No orphan rows")
+		}
+		for _, o := range report.Orphans {
+			kind := "reference"
+			if o.Declared {
+				kind = "foreign key"
+			}
+			ui.Warning("%d row(s) of %s with a dangling %s %s -> %s", o.Rows, o.Table, kind, o.Column, o.Parent)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if checkOnly {
+		if dryRun {
+			ui.DryRunMsg("Would run ANALYZE and VACUUM")
+		}
+		return nil
+	}
+	ui.Success("Analyzed and vacuumed; database %s -> %s", formatBytes(report.SizeBefore), output.Cyan(formatBytes(report.SizeAfter)))
+	return nil
+}
+
+func dbCompressRun() error {
+	if dryRun {
+		ui.DryRunMsg("Would rewrite large text values under db.compression %q and vacuum the database", viper.GetString("db.compression"))
+		return nil
+	}
+	s, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	before, err := s.Stats(ctx)
+	if err != nil {
+		return err
+	}
+	n, err := s.RepackText(ctx)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		ui.Info("Every large text value already follows the compression settings")
+		return nil
+	}
+	if err := s.Vacuum(ctx); err != nil {
+		return err
+	}
+	after, err := s.Stats(ctx)
+	if err != nil {
+		return err
+	}
+	ui.Success("Rewrote %d value(s); database %s -> %s", n, formatBytes(before.SizeBytes()), output.Cyan(formatBytes(after.SizeBytes())))
+	return nil
+}
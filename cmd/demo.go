@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/demo"
+	"github.com/joescharf/pm/internal/fakeforge"
+	"github.com/joescharf/pm/internal/output"
+)
+
+var (
+	demoPort int
+	demoKeep bool
+)
+
+var demoCmd = &cobra.Command{
+	Use:   "demo",
+	Short: "Serve the web UI on a throwaway database of sample data",
+	Long: `Create a temporary database with sample projects, issues, agent sessions,
+reviews and comments, backed by disposable git repositories with session
+worktrees, and serve the web UI on it as pm serve does. GitHub features
+talk to an in-process fake forge (see pm fakeforge) serving the widgets
+project's repository.
+
+Your own database and repositories are not touched. Everything is deleted
+when the server stops, unless --keep is given.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return demoRun(cmd)
+	},
+}
+
+func init() {
+	demoCmd.Flags().IntVarP(&demoPort, "port", "p", 8080, "port to listen on")
+	demoCmd.Flags().BoolVar(&demoKeep, "keep", false, "keep the demo database and repositories after exiting")
+	rootCmd.AddCommand(demoCmd)
+}
+
+func demoRun(cmd *cobra.Command) (err error) {
+	dir, err := os.MkdirTemp("", "pm-demo-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if dataStore != nil {
+			_ = dataStore.Close()
+			dataStore = nil
+		}
+		if demoKeep {
+			ui.Info("Demo data kept in %s", dir)
+			return
+		}
+		if rerr := os.RemoveAll(dir); rerr != nil && err == nil {
+			err = fmt.Errorf("remove demo data: %w", rerr)
+		}
+	}()
+
+	forgeURL, stopForge := fakeforge.New(fakeforge.Demo()...).Start()
+	defer stopForge()
+
+	viper.Set("db_path", filepath.Join(dir, "pm.db"))
+	viper.Set("github.api_url", forgeURL)
+	viper.Set("port", demoPort)
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	res, err := demo.Seed(cmd.Context(), s, filepath.Join(dir, "repos"))
+	if err != nil {
+		return fmt.Errorf("seed demo data: %w", err)
+	}
+	ui.Success("Seeded %d projects, %d issues, %d sessions and %d reviews in %s",
+		len(res.Projects), res.Issues, res.Sessions, res.Reviews, output.Cyan(dir))
+
+	return serveRun(cmd.Context(), false)
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/digest"
+	"github.com/joescharf/pm/internal/notify"
+	"github.com/joescharf/pm/internal/store"
+)
+
+var (
+	digestPeriod   string
+	digestFormat   string
+	digestProjects []string
+)
+
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Show a daily or weekly activity digest",
+	Long: `Summarize issues opened, closed and in progress, agent sessions and WIP
+warnings across projects for the last day or week.
+
+Use "pm digest send" to email the digest for projects listed in
+notify.email.projects to the notify.email.to recipients.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return digestRun()
+	},
+}
+
+var digestSendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Email the digest to configured recipients",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return digestSendRun()
+	},
+}
+
+func init() {
+	digestCmd.PersistentFlags().StringVar(&digestPeriod, "period", "daily", "Digest period: daily, weekly")
+	digestCmd.Flags().StringVar(&digestFormat, "format", "text", "Output format: text, html, json")
+	digestCmd.Flags().StringSliceVar(&digestProjects, "project", nil, "Limit to these projects (default: all)")
+	digestCmd.AddCommand(digestSendCmd)
+	rootCmd.AddCommand(digestCmd)
+}
+
+func digestRun() error {
+	period, err := digest.ParsePeriod(digestPeriod)
+	if err != nil {
+		return err
+	}
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+
+	d, err := digest.Build(context.Background(), s, period, time.Now(), digestProjects, blockedRemindAfter())
+	if err != nil {
+		return err
+	}
+
+	switch digestFormat {
+	case "text":
+		_, _ = fmt.Fprint(ui.Out, d.Text())
+		return nil
+	case "html":
+		html, err := d.HTML()
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(ui.Out, html)
+		return nil
+	case "json":
+		enc := json.NewEncoder(ui.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	default:
+		return fmt.Errorf("unknown format: %s", digestFormat)
+	}
+}
+
+func digestSendRun() error {
+	period, err := digest.ParsePeriod(digestPeriod)
+	if err != nil {
+		return err
+	}
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		ui.DryRunMsg("Would email %s digest to %s", period, strings.Join(viper.GetStringSlice("notify.email.to"), ", "))
+		return nil
+	}
+	if err := sendDigest(context.Background(), s, period, time.Now()); err != nil {
+		return err
+	}
+	ui.Success("Sent %s digest to %s", period, strings.Join(viper.GetStringSlice("notify.email.to"), ", "))
+	return nil
+}
+
+// emailConfig reads the SMTP notifier settings from viper.
+func emailConfig() notify.EmailConfig {
+	return notify.EmailConfig{
+		Host:     viper.GetString("notify.email.host"),
+		Port:     viper.GetInt("notify.email.port"),
+		Username: viper.GetString("notify.email.username"),
+		Password: viper.GetString("notify.email.password"),
+		From:     viper.GetString("notify.email.from"),
+		To:       viper.GetStringSlice("notify.email.to"),
+	}
+}
+
+// sendDigest builds the digest for opted-in projects and emails it.
+func sendDigest(ctx context.Context, s store.Store, period digest.Period, now time.Time) error {
+	projects := viper.GetStringSlice("notify.email.projects")
+	if len(projects) == 0 {
+		return errors.New("no projects opted in to the email digest (set notify.email.projects)")
+	}
+	n, err := notify.NewEmailNotifier(emailConfig())
+	if err != nil {
+		return fmt.Errorf("email digest: %w", err)
+	}
+
+	d, err := digest.Build(ctx, s, period, now, projects, blockedRemindAfter())
+	if err != nil {
+		return err
+	}
+	html, err := d.HTML()
+	if err != nil {
+		return err
+	}
+	return n.Send(notify.Message{Subject: d.Subject(), Text: d.Text(), HTML: html})
+}
+
+// digestStatePath is where the scheduler records when it last sent a digest.
+func digestStatePath() string {
+	return filepath.Join(viper.GetString("state_dir"), "digest-last-sent")
+}
+
+// startDigestScheduler emails the digest from `pm serve` when
+// notify.email.schedule is "daily" or "weekly". It checks hourly and sends once
+// a full period has passed since the last send, so restarts don't resend.
+func startDigestScheduler(ctx context.Context, s store.Store) {
+	schedule := viper.GetString("notify.email.schedule")
+	if schedule == "" {
+		return
+	}
+	period, err := digest.ParsePeriod(schedule)
+	if err != nil {
+		ui.Warning("Email digest disabled: %v", err)
+		return
+	}
+
+	check := func() {
+		now := time.Now()
+		if last, ok := digestLastSent(); ok && now.Sub(last) < period.Duration() {
+			return
+		}
+		if err := sendDigest(ctx, s, period, now); err != nil {
+			ui.Warning("Email digest: %v", err)
+			return
+		}
+		if err := os.WriteFile(digestStatePath(), []byte(now.UTC().Format(time.RFC3339)), 0644); err != nil {
+			ui.Warning("Email digest: record last send: %v", err)
+		}
+	}
+
+	go func() {
+		check()
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				check()
+			}
+		}
+	}()
+}
+
+func digestLastSent() (time.Time, bool) {
+	data, err := os.ReadFile(digestStatePath())
+	if err != nil {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joescharf/pm/internal/fakeforge"
+)
+
+var fakeforgeAddr string
+
+var fakeforgeCmd = &cobra.Command{
+	Use:   "fakeforge [seed.json]",
+	Short: "Serve a fake GitHub API for offline development",
+	Long: `Serve an in-memory emulation of the parts of the GitHub REST API pm uses:
+repository info, Pages, releases, pull requests with their reviews, and
+issues. Point pm at it with github.api_url (or PM_GITHUB_API_URL) to run
+GitHub features without gh or a network.
+
+The seed is a JSON array of repositories (see internal/fakeforge); without
+one, a demo repository acme/widgets is served. Changes, such as closed
+issues, last until the server stops.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		seed := ""
+		if len(args) == 1 {
+			seed = args[0]
+		}
+		return fakeforgeRun(seed)
+	},
+}
+
+func init() {
+	fakeforgeCmd.Flags().StringVar(&fakeforgeAddr, "addr", "127.0.0.1:8089", "Address to listen on")
+	rootCmd.AddCommand(fakeforgeCmd)
+}
+
+func fakeforgeRun(seed string) error {
+	forge := fakeforge.New(fakeforge.Demo()...)
+	if seed != "" {
+		f, err := os.Open(seed)
+		if err != nil {
+			return err
+		}
+		forge, err = fakeforge.Load(f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	lis, err := net.Listen("tcp", fakeforgeAddr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: forge.Handler()}
+
+	ctx, stop := signal.NotifyContext(context.Background(), shutdownSignals()...)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	ui.Info("Serving a fake GitHub API at http://%s", lis.Addr())
+	ui.Info("  Use it with: PM_GITHUB_API_URL=http://%s pm ...", lis.Addr())
+	if err := srv.Serve(lis); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("fakeforge: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,392 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joescharf/pm/internal/flow"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/store"
+)
+
+var (
+	flowJSON       bool
+	flowHeight     int
+	flowWidth      int
+	flowDays       int
+	burndownTag    string
+	burndownSince  string
+	burndownUntil  string
+	boardDiffSince string
+)
+
+var reportBurndownCmd = &cobra.Command{
+	Use:   "burndown [project]",
+	Short: "Chart remaining issues over time",
+	Long: `Chart how many issues in scope are not yet done or closed at the end of each
+day, against an ideal line that reaches zero on the last day.
+
+Milestones are tags: --milestone M limits the scope to issues tagged M. The
+chart starts when the first issue in scope was created (or --since) and runs
+to today (or --until, to project the ideal line to a target date).`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reportBurndownRun(argOrEmpty(args))
+	},
+}
+
+var reportCFDCmd = &cobra.Command{
+	Use:   "cfd [project]",
+	Short: "Chart a cumulative flow diagram of issue statuses",
+	Long: `Chart how many of a project's issues were open, in progress, done and closed
+at the end of each of the last --days days, from recorded status changes.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reportCFDRun(argOrEmpty(args))
+	},
+}
+
+var reportCycleTimeCmd = &cobra.Command{
+	Use:   "cycle-time [project]",
+	Short: "Report lead and cycle times of recently completed issues",
+	Long: `Summarize how long the issues completed in the last --days days took. Lead
+time runs from creation to completion; cycle time from when the issue was
+first moved to in_progress to completion. Both come from the started_at and
+completed_at timestamps recorded automatically on status changes.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reportCycleTimeRun(argOrEmpty(args))
+	},
+}
+
+var reportBoardDiffCmd = &cobra.Command{
+	Use:   "board-diff [project]",
+	Short: "Show what changed on the issue board since a point in time",
+	Long: `Diff the issue board now with the board --since ago (default 7d), as a
+changelog of the backlog for planning meetings: the issues added, deleted and
+moved between statuses, and the net growth of the backlog (issues not done or
+closed) per project. Without a project, every project with changes is shown.
+
+Moves come from recorded status changes and compare the start of the window
+with now, so an issue that moved and came back did not move. Deleted issues
+come from the change feed, which keeps changes.retention (default 7d): a
+report reaching back further says from when deletions are known.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reportBoardDiffRun(argOrEmpty(args))
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{reportBurndownCmd, reportCFDCmd} {
+		c.Flags().BoolVar(&flowJSON, "json", false, "Output the daily data as JSON")
+		c.Flags().IntVar(&flowHeight, "height", 12, "Chart height in rows")
+		c.Flags().IntVar(&flowWidth, "width", 60, "Maximum chart width in columns (days are sampled to fit)")
+		reportCmd.AddCommand(c)
+	}
+	reportBurndownCmd.Flags().StringVar(&burndownTag, "milestone", "", "Only include issues with this tag")
+	reportBurndownCmd.Flags().StringVar(&burndownSince, "since", "", "First day (YYYY-MM-DD, default: first issue created)")
+	reportBurndownCmd.Flags().StringVar(&burndownUntil, "until", "", "Last day (YYYY-MM-DD, default: today)")
+	reportCFDCmd.Flags().IntVar(&flowDays, "days", 30, "Number of days to chart")
+	reportCycleTimeCmd.Flags().IntVar(&flowDays, "days", 30, "Include issues completed in the last N days")
+	reportCycleTimeCmd.Flags().BoolVar(&flowJSON, "json", false, "Output the statistics as JSON")
+	reportCmd.AddCommand(reportCycleTimeCmd)
+	reportBoardDiffCmd.Flags().StringVar(&boardDiffSince, "since", "7d", "Start of the window: a duration back from now (7d, 2w, 36h) or a date (YYYY-MM-DD)")
+	reportBoardDiffCmd.Flags().BoolVar(&flowJSON, "json", false, "Output the diff as JSON")
+	reportCmd.AddCommand(reportBoardDiffCmd)
+}
+
+func argOrEmpty(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return ""
+}
+
+func reportBurndownRun(ref string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	p, err := resolveProjectOrCwd(ctx, s, ref)
+	if err != nil {
+		return err
+	}
+
+	issues, err := s.ListIssues(ctx, store.IssueListFilter{ProjectID: p.ID, Tag: burndownTag})
+	if err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		if burndownTag != "" {
+			return fmt.Errorf("no issues in %s tagged %s", p.Name, burndownTag)
+		}
+		return fmt.Errorf("no issues in %s", p.Name)
+	}
+	scope := make(map[string]bool, len(issues))
+	from := time.Now()
+	for _, i := range issues {
+		scope[i.ID] = true
+		if i.CreatedAt.Before(from) {
+			from = i.CreatedAt
+		}
+	}
+	to := time.Now()
+	if from, err = parseDay(burndownSince, from); err != nil {
+		return fmt.Errorf("--since: %w", err)
+	}
+	if to, err = parseDay(burndownUntil, to); err != nil {
+		return fmt.Errorf("--until: %w", err)
+	}
+	if to.Before(from) {
+		return fmt.Errorf("--until is before the first day (%s)", from.Format("2006-01-02"))
+	}
+
+	events, err := s.ListIssueStatusEvents(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	days := flow.Burndown(events, scope, flow.Days(from, to, time.Local), now)
+
+	if flowJSON {
+		return writeFlowJSON(days)
+	}
+	title := p.Name
+	if burndownTag != "" {
+		title += " / " + burndownTag
+	}
+	last := days[0]
+	for _, d := range days {
+		if !d.Future {
+			last = d
+		}
+	}
+	fmt.Fprintf(ui.Out, "Burndown: %s (%d of %d remaining)\n\n", output.Cyan(title), last.Remaining, last.Scope)
+	fmt.Fprint(ui.Out, flow.RenderBurndown(days, flowHeight, flowWidth, flowPainter))
+	return nil
+}
+
+func reportCFDRun(ref string) error {
+	if flowDays < 1 {
+		return fmt.Errorf("--days must be at least 1")
+	}
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	p, err := resolveProjectOrCwd(ctx, s, ref)
+	if err != nil {
+		return err
+	}
+
+	events, err := s.ListIssueStatusEvents(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	days := flow.CFD(events, nil, flow.Days(now.AddDate(0, 0, 1-flowDays), now, time.Local))
+
+	if flowJSON {
+		return writeFlowJSON(days)
+	}
+	today := days[len(days)-1]
+	fmt.Fprintf(ui.Out, "Cumulative flow: %s (%d open, %d in progress, %d in review, %d done, %d closed)\n\n",
+		output.Cyan(p.Name), today.Open, today.InProgress, today.InReview, today.Done, today.Closed)
+	fmt.Fprint(ui.Out, flow.RenderCFD(days, flowHeight, flowWidth, flowPainter))
+	return nil
+}
+
+func reportCycleTimeRun(ref string) error {
+	if flowDays < 1 {
+		return fmt.Errorf("--days must be at least 1")
+	}
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	p, err := resolveProjectOrCwd(ctx, s, ref)
+	if err != nil {
+		return err
+	}
+
+	issues, err := s.ListIssues(ctx, store.IssueListFilter{ProjectID: p.ID})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	r := flow.CycleTimes(issues, now.AddDate(0, 0, -flowDays), now)
+
+	if flowJSON {
+		return writeFlowJSON(r)
+	}
+	fmt.Fprintf(ui.Out, "Cycle time: %s (%d issue(s) completed in the last %d days)\n\n", output.Cyan(p.Name), r.All.Completed, flowDays)
+	if r.All.Completed == 0 {
+		return nil
+	}
+
+	types := make([]string, 0, len(r.ByType))
+	for t := range r.ByType {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+
+	table := ui.Table([]string{"Type", "Completed", "Lead (median)", "Lead (85th)", "Cycle (median)", "Cycle (85th)", "Cycle (max)"})
+	row := func(label string, c flow.CycleStats) {
+		_ = table.Append([]string{
+			label,
+			fmt.Sprintf("%d", c.Completed),
+			formatHours(c.LeadTime.Count, c.LeadTime.Median),
+			formatHours(c.LeadTime.Count, c.LeadTime.P85),
+			formatHours(c.CycleTime.Count, c.CycleTime.Median),
+			formatHours(c.CycleTime.Count, c.CycleTime.P85),
+			formatHours(c.CycleTime.Count, c.CycleTime.Max),
+		})
+	}
+	for _, t := range types {
+		row(t, r.ByType[models.IssueType(t)])
+	}
+	row(output.Cyan("all"), r.All)
+	_ = table.Render()
+	return nil
+}
+
+func reportBoardDiffRun(ref string) error {
+	now := time.Now()
+	since, err := flow.ParseSince(boardDiffSince, now)
+	if err != nil {
+		return fmt.Errorf("--since: %w", err)
+	}
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var projects []*models.Project
+	if ref != "" {
+		p, err := resolveProject(ctx, s, ref)
+		if err != nil {
+			return err
+		}
+		projects = []*models.Project{p}
+	} else if projects, err = s.ListProjects(ctx, ""); err != nil {
+		return err
+	}
+
+	diff, err := flow.BuildBoardDiff(ctx, s, projects, since, now)
+	if err != nil {
+		return err
+	}
+	if flowJSON {
+		return writeFlowJSON(diff)
+	}
+
+	fmt.Fprintf(ui.Out, "# Board diff: %s to %s\n\n", since.Format("2006-01-02 15:04"), now.Format("2006-01-02 15:04"))
+	if diff.RemovedSince != nil {
+		fmt.Fprintf(ui.Out, "_Deleted issues are only known from %s (changes.retention)._\n\n", diff.RemovedSince.Local().Format("2006-01-02 15:04"))
+	}
+	shown := 0
+	for _, d := range diff.Projects {
+		if d.Empty() && ref == "" {
+			continue
+		}
+		shown++
+		fmt.Fprintf(ui.Out, "## %s\n", d.Project)
+		fmt.Fprintf(ui.Out, "- Backlog: %d → %d (%s)\n", d.BacklogBefore, d.BacklogAfter, signed(d.NetGrowth))
+		if len(d.Added) > 0 {
+			fmt.Fprintf(ui.Out, "- Added (%d):\n", len(d.Added))
+			for _, i := range d.Added {
+				fmt.Fprintf(ui.Out, "  - %s %s [%s]\n", shortID(i.IssueID), i.Title, i.Status)
+			}
+		}
+		if len(d.Moved) > 0 {
+			var counts []string
+			for _, t := range d.Transitions {
+				counts = append(counts, fmt.Sprintf("%s → %s: %d", t.From, t.To, t.Count))
+			}
+			fmt.Fprintf(ui.Out, "- Moved (%d; %s):\n", len(d.Moved), strings.Join(counts, ", "))
+			for _, m := range d.Moved {
+				fmt.Fprintf(ui.Out, "  - %s %s: %s → %s\n", shortID(m.IssueID), m.Title, m.From, m.To)
+			}
+		}
+		if len(d.Removed) > 0 {
+			ids := make([]string, len(d.Removed))
+			for i, id := range d.Removed {
+				ids[i] = shortID(id)
+			}
+			fmt.Fprintf(ui.Out, "- Deleted (%d): %s\n", len(d.Removed), strings.Join(ids, ", "))
+		}
+		fmt.Fprintln(ui.Out)
+	}
+	if shown == 0 {
+		fmt.Fprintln(ui.Out, "No changes.")
+		return nil
+	}
+	if len(diff.Projects) > 1 {
+		fmt.Fprintf(ui.Out, "Net backlog growth: %s\n", signed(diff.NetGrowth))
+	}
+	return nil
+}
+
+// signed formats n with its sign, "+2" or "-1"; zero is "0".
+func signed(n int) string {
+	if n > 0 {
+		return fmt.Sprintf("+%d", n)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// formatHours shows a duration in hours as hours below two days and days
+// above, or "-" when there was nothing to measure.
+func formatHours(count int, h float64) string {
+	switch {
+	case count == 0:
+		return "-"
+	case h < 48:
+		return fmt.Sprintf("%.1fh", h)
+	default:
+		return fmt.Sprintf("%.1fd", h/24)
+	}
+}
+
+// parseDay parses a YYYY-MM-DD flag value in local time, or returns def.
+func parseDay(value string, def time.Time) (time.Time, error) {
+	if value == "" {
+		return def, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+func writeFlowJSON(v any) error {
+	enc := json.NewEncoder(ui.Out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// flowPainter colors chart bands (closed stays uncolored); color is disabled
+// automatically when output is not a terminal.
+func flowPainter(b flow.Band, s string) string {
+	switch b {
+	case flow.BandDone, flow.BandRemaining:
+		return output.Green(s)
+	case flow.BandInProgress:
+		return output.Yellow(s)
+	case flow.BandInReview:
+		return output.Magenta(s)
+	case flow.BandOpen:
+		return output.Cyan(s)
+	default:
+		return s
+	}
+}
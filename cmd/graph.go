@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joescharf/pm/internal/deps"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/store"
+)
+
+var (
+	graphDot     bool
+	dependRemove bool
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Show dependencies between projects",
+	Long: `Show the dependency graph between tracked projects.
+Declare dependencies with 'pm project depend'. Use --dot for Graphviz output:
+
+  pm graph --dot | dot -Tsvg > projects.svg`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return graphRun()
+	},
+}
+
+var projectDependCmd = &cobra.Command{
+	Use:   "depend <project> <upstream>",
+	Short: "Declare that a project depends on another",
+	Long: `Record that <project> depends on <upstream>. pm status then warns about
+<project> when <upstream> has failing health or unreleased breaking changes.
+
+Example: pm project depend pm wt`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return projectDependRun(args[0], args[1])
+	},
+}
+
+func init() {
+	graphCmd.Flags().BoolVar(&graphDot, "dot", false, "Output Graphviz DOT")
+	rootCmd.AddCommand(graphCmd)
+
+	projectDependCmd.Flags().BoolVar(&dependRemove, "remove", false, "Remove the dependency instead of adding it")
+	projectCmd.AddCommand(projectDependCmd)
+}
+
+// loadGraph builds the dependency graph of all tracked projects.
+func loadGraph(ctx context.Context, s store.Store) (*deps.Graph, error) {
+	projects, err := s.ListProjects(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	edges, err := s.ListProjectDependencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return deps.Build(projects, edges), nil
+}
+
+func graphRun() error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+
+	g, err := loadGraph(context.Background(), s)
+	if err != nil {
+		return err
+	}
+	if graphDot {
+		fmt.Fprint(ui.Out, g.DOT())
+	} else {
+		fmt.Fprint(ui.Out, g.Text())
+	}
+	return nil
+}
+
+func projectDependRun(name, upstreamName string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	p, err := resolveProject(ctx, s, name)
+	if err != nil {
+		return err
+	}
+	up, err := resolveProject(ctx, s, upstreamName)
+	if err != nil {
+		return err
+	}
+
+	if dependRemove {
+		if dryRun {
+			ui.DryRunMsg("Would remove dependency: %s -> %s", p.Name, up.Name)
+			return nil
+		}
+		if err := s.DeleteProjectDependency(ctx, p.ID, up.ID); err != nil {
+			return err
+		}
+		ui.Success("Removed dependency: %s -> %s", output.Cyan(p.Name), output.Cyan(up.Name))
+		return nil
+	}
+
+	g, err := loadGraph(ctx, s)
+	if err != nil {
+		return err
+	}
+	if g.WouldCycle(p.ID, up.ID) {
+		return fmt.Errorf("%s already depends on %s; adding this would create a cycle", up.Name, p.Name)
+	}
+
+	if dryRun {
+		ui.DryRunMsg("Would add dependency: %s -> %s", p.Name, up.Name)
+		return nil
+	}
+	if err := s.CreateProjectDependency(ctx, &models.ProjectDependency{ProjectID: p.ID, DependsOnID: up.ID}); err != nil {
+		return err
+	}
+	ui.Success("%s now depends on %s", output.Cyan(p.Name), output.Cyan(up.Name))
+	return nil
+}
+
+// projectNames joins project names with commas.
+func projectNames(projects []*models.Project) string {
+	names := make([]string, len(projects))
+	for i, p := range projects {
+		names[i] = p.Name
+	}
+	return strings.Join(names, ", ")
+}
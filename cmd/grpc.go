@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+
+	"github.com/joescharf/pm/internal/api"
+	"github.com/joescharf/pm/pkg/pmpb"
+)
+
+// grpcAddr resolves the --grpc address. A bare port (":9090") listens on the
+// same interface as the HTTP server.
+func grpcAddr(addr, bind string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("grpc: invalid address %q: %w", addr, err)
+	}
+	if host == "" {
+		host = bind
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// startGRPCServer serves the gRPC API on addr until ctx is done. The service
+// runs every call through apiServer's REST handlers, so tokens, validation
+// and agent behavior match the HTTP API.
+func startGRPCServer(ctx context.Context, addr string, apiServer *api.Server) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("grpc: %w", err)
+	}
+
+	gs := grpc.NewServer()
+	pmpb.RegisterPMServer(gs, apiServer.GRPC())
+
+	go func() {
+		<-ctx.Done()
+		gs.GracefulStop()
+	}()
+	go func() {
+		if serr := gs.Serve(lis); serr != nil {
+			ui.Warning("gRPC server error: %v", serr)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/hooks"
+	"github.com/joescharf/pm/internal/output"
+)
+
+var (
+	hooksURL   string
+	hooksForce bool
+)
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage git hooks that link commits to issues and sessions",
+	Long: `Install git hooks that make a project's commits traceable to pm issues and
+sessions without the agent's cooperation:
+
+  commit-msg   adds PM-Issue and PM-Session trailers for the worktree's session
+  post-commit  pings POST /api/v1/agent/heartbeat so pm serve sees the commit
+
+The hooks live in the repo's hooks directory, so they cover every worktree.
+Commits outside a pm session are left untouched, and both hooks do nothing
+when pm or the server is unavailable.`,
+}
+
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install [project]",
+	Short: "Install pm's git hooks into a project",
+	Long: `Install the commit-msg and post-commit hooks into a project's repo (default:
+the project in the current directory). Existing hooks pm did not write are
+left alone unless --force, which moves them aside to <hook>.pre-pm; pm's
+hooks run them first.
+
+The post-commit hook reports to --url (default: http://localhost:<port>);
+PM_URL and PM_TOKEN in the committing environment override the server and
+supply an API token.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return hooksInstallRun(argOrEmpty(args))
+	},
+}
+
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall [project]",
+	Short: "Remove pm's git hooks from a project",
+	Long:  "Remove the hooks written by 'pm hooks install', restoring any hooks it moved aside.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return hooksUninstallRun(argOrEmpty(args))
+	},
+}
+
+var hooksCommitMsgCmd = &cobra.Command{
+	Use:    "commit-msg <message-file>",
+	Short:  "Add issue and session trailers to a commit message (run by the commit-msg hook)",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return hooksCommitMsgRun(args[0])
+	},
+}
+
+func init() {
+	hooksInstallCmd.Flags().StringVar(&hooksURL, "url", "", "pm serve URL for the post-commit heartbeat (default: http://localhost:<port>)")
+	hooksInstallCmd.Flags().BoolVar(&hooksForce, "force", false, "Replace existing hooks, keeping them as <hook>.pre-pm")
+	hooksCmd.AddCommand(hooksInstallCmd)
+	hooksCmd.AddCommand(hooksUninstallCmd)
+	hooksCmd.AddCommand(hooksCommitMsgCmd)
+	rootCmd.AddCommand(hooksCmd)
+}
+
+func hooksInstallRun(ref string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	p, err := resolveProjectOrCwd(context.Background(), s, ref)
+	if err != nil {
+		return err
+	}
+	dir, err := git.HooksDir(context.Background(), p.Path)
+	if err != nil {
+		return fmt.Errorf("find hooks directory for %s: %w", p.Name, err)
+	}
+
+	url := hooksURL
+	if url == "" {
+		url = fmt.Sprintf("http://localhost:%d", viper.GetInt("port"))
+	}
+	results, err := hooks.Install(dir, url, hooksForce)
+	for _, r := range results {
+		switch r.Action {
+		case "skipped":
+			ui.Warning("Skipped %s: an existing hook is in place (use --force to replace it)", r.Name)
+		case "replaced":
+			ui.Success("Installed %s (previous hook kept as %s)", output.Cyan(r.Name), r.Backup)
+		case "updated":
+			ui.Success("Updated %s", output.Cyan(r.Name))
+		default:
+			ui.Success("Installed %s", output.Cyan(r.Name))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	ui.Info("Hooks directory: %s", dir)
+	return nil
+}
+
+func hooksUninstallRun(ref string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	p, err := resolveProjectOrCwd(context.Background(), s, ref)
+	if err != nil {
+		return err
+	}
+	dir, err := git.HooksDir(context.Background(), p.Path)
+	if err != nil {
+		return fmt.Errorf("find hooks directory for %s: %w", p.Name, err)
+	}
+
+	results, err := hooks.Uninstall(dir)
+	for _, r := range results {
+		if r.Action == "restored" {
+			ui.Success("Removed %s and restored the previous hook", output.Cyan(r.Name))
+			continue
+		}
+		ui.Success("Removed %s", output.Cyan(r.Name))
+	}
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		ui.Info("No pm hooks installed in %s", p.Name)
+	}
+	return nil
+}
+
+// hooksCommitMsgRun adds the trailers of the session owning the current
+// worktree to a commit message file. Commits outside a session are left as
+// they are.
+func hooksCommitMsgRun(file string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	root, err := git.NewClient().RepoRoot(context.Background(), cwd)
+	if err != nil {
+		return nil
+	}
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	sess, err := s.GetAgentSessionByWorktreePath(context.Background(), root)
+	if err != nil {
+		return nil
+	}
+
+	var trailers []hooks.Trailer
+	if sess.IssueID != "" {
+		trailers = append(trailers, hooks.Trailer{Key: "PM-Issue", Value: shortID(sess.IssueID)})
+	}
+	trailers = append(trailers, hooks.Trailer{Key: "PM-Session", Value: shortID(sess.ID)})
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	msg := hooks.AddTrailers(string(data), trailers)
+	if msg == string(data) {
+		return nil
+	}
+	return os.WriteFile(file, []byte(msg), 0o644)
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -9,7 +10,10 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/joescharf/pm/internal/agent"
 	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/llm"
+	"github.com/joescharf/pm/internal/llmbudget"
 	"github.com/joescharf/pm/internal/models"
 	"github.com/joescharf/pm/internal/output"
 	"github.com/joescharf/pm/internal/store"
@@ -27,6 +31,10 @@ var (
 	issueAll      bool
 	issueGitHub   int
 	issueNoEnrich bool
+	issueAssignee string
+	issueEstimate string
+	issueCriteria []string
+	relateRemove  bool
 
 	reviewBaseRef string
 	reviewHeadRef string
@@ -85,7 +93,7 @@ var issueUpdateCmd = &cobra.Command{
 	Short: "Update an issue",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return issueUpdateRun(args[0])
+		return issueUpdateRun(args[0], cmd.Flags().Changed("assignee"), cmd.Flags().Changed("estimate"), cmd.Flags().Changed("criterion"))
 	},
 }
 
@@ -117,6 +125,19 @@ var issueReviewCmd = &cobra.Command{
 	},
 }
 
+var issueRelateCmd = &cobra.Command{
+	Use:   "relate <issue-id> <type> <target-issue-id>",
+	Short: "Relate two issues",
+	Long: `Record a typed relation from one issue to another, possibly in another project.
+Types: relates_to, duplicates, caused_by, blocks, split_from.
+
+Example: pm issue relate 01JREGRESS caused_by 01JORIGBUG`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return issueRelateRun(args[0], args[1], args[2])
+	},
+}
+
 func init() {
 	issueAddCmd.Flags().StringVar(&issueTitle, "title", "", "Issue title (required)")
 	issueAddCmd.Flags().StringVar(&issueDesc, "desc", "", "Issue description")
@@ -125,12 +146,16 @@ func init() {
 	issueAddCmd.Flags().StringVar(&issuePriority, "priority", "medium", "Priority: low, medium, high")
 	issueAddCmd.Flags().StringVar(&issueType, "type", "feature", "Type: feature, bug, chore")
 	issueAddCmd.Flags().StringVar(&issueTag, "tag", "", "Tag to apply")
+	issueAddCmd.Flags().StringVar(&issueAssignee, "assignee", "", "Person or agent to assign the issue to")
+	issueAddCmd.Flags().StringVar(&issueEstimate, "estimate", "", "Size estimate, e.g. 2h, 3pt, M")
+	issueAddCmd.Flags().StringArrayVar(&issueCriteria, "criterion", nil, "Acceptance criterion (repeatable)")
 	issueAddCmd.Flags().BoolVar(&issueNoEnrich, "no-enrich", false, "Skip LLM enrichment")
 	_ = issueAddCmd.MarkFlagRequired("title")
 
-	issueListCmd.Flags().StringVar(&issueStatus, "status", "", "Filter by status: open, in_progress, done, closed")
+	issueListCmd.Flags().StringVar(&issueStatus, "status", "", "Filter by status: open, in_progress, in_review, done, closed")
 	issueListCmd.Flags().StringVar(&issuePriority, "priority", "", "Filter by priority")
 	issueListCmd.Flags().StringVar(&issueTag, "tag", "", "Filter by tag")
+	issueListCmd.Flags().StringVar(&issueAssignee, "assignee", "", "Filter by assignee")
 	issueListCmd.Flags().BoolVar(&issueAll, "all", false, "Show all issues across projects")
 
 	issueUpdateCmd.Flags().StringVar(&issueStatus, "status", "", "New status")
@@ -139,10 +164,15 @@ func init() {
 	issueUpdateCmd.Flags().StringVar(&issueDesc, "desc", "", "New description")
 	issueUpdateCmd.Flags().StringVar(&issueBody, "body", "", "New body text")
 	issueUpdateCmd.Flags().StringVar(&issueAIPrompt, "ai-prompt", "", "New AI prompt")
+	issueUpdateCmd.Flags().StringVar(&issueAssignee, "assignee", "", "New assignee (empty to unassign)")
+	issueUpdateCmd.Flags().StringVar(&issueEstimate, "estimate", "", "New size estimate (empty to clear)")
+	issueUpdateCmd.Flags().StringArrayVar(&issueCriteria, "criterion", nil, "Acceptance criterion (repeatable; replaces the list, empty to clear)")
 
 	issueLinkCmd.Flags().IntVar(&issueGitHub, "github", 0, "GitHub issue number")
 	_ = issueLinkCmd.MarkFlagRequired("github")
 
+	issueRelateCmd.Flags().BoolVar(&relateRemove, "remove", false, "Remove the relation instead of adding it")
+
 	issueReviewCmd.Flags().StringVar(&reviewBaseRef, "base-ref", "main", "Base ref for diff")
 	issueReviewCmd.Flags().StringVar(&reviewHeadRef, "head-ref", "", "Head ref for diff (default: session branch or HEAD)")
 	issueReviewCmd.Flags().StringVar(&reviewAppURL, "app-url", "", "URL of running app for UI review")
@@ -153,6 +183,7 @@ func init() {
 	issueCmd.AddCommand(issueUpdateCmd)
 	issueCmd.AddCommand(issueCloseCmd)
 	issueCmd.AddCommand(issueLinkCmd)
+	issueCmd.AddCommand(issueRelateCmd)
 	issueCmd.AddCommand(issueReviewCmd)
 	rootCmd.AddCommand(issueCmd)
 }
@@ -168,6 +199,14 @@ func issueAddRun(projectRef string) error {
 	if err != nil {
 		return err
 	}
+	priority, err := models.ParseIssuePriority(issuePriority)
+	if err != nil {
+		return err
+	}
+	itype, err := models.ParseIssueType(issueType)
+	if err != nil {
+		return err
+	}
 
 	issue := &models.Issue{
 		ProjectID:   p.ID,
@@ -176,8 +215,12 @@ func issueAddRun(projectRef string) error {
 		Body:        issueBody,
 		AIPrompt:    issueAIPrompt,
 		Status:      models.IssueStatusOpen,
-		Priority:    models.IssuePriority(issuePriority),
-		Type:        models.IssueType(issueType),
+		Priority:    priority,
+		Type:        itype,
+		Assignee:    issueAssignee,
+		Estimate:    issueEstimate,
+
+		AcceptanceCriteria: models.TrimCriteria(issueCriteria),
 	}
 
 	if dryRun {
@@ -185,19 +228,39 @@ func issueAddRun(projectRef string) error {
 		return nil
 	}
 
-	// LLM enrichment (non-fatal)
+	// LLM enrichment (non-fatal, but skipped or refused over budget)
+	var usage *llm.Usage
 	if !issueNoEnrich {
 		if client := newLLMClient(); client != nil {
-			ui.Info("Enriching issue with LLM...")
-			enriched, err := client.EnrichIssue(ctx, issue.Title, issue.Body, issue.Description)
+			budget, err := llmBudget()
 			if err != nil {
-				ui.Warning("LLM enrichment failed (issue will still be created): %v", err)
+				return err
+			}
+			if err := budget.Check(ctx, s, p, time.Now()); err != nil {
+				if errors.Is(err, llmbudget.ErrExceeded) && !budget.Degrade() {
+					return err
+				}
+				ui.Warning("Skipping LLM enrichment: %v", err)
 			} else {
-				if issue.Description == "" && enriched.Description != "" {
-					issue.Description = enriched.Description
+				ui.Info("Enriching issue with LLM...")
+				enriched, err := client.EnrichIssue(ctx, p.Name, p.MainLanguages(), issue.Title, issue.Body, issue.Description)
+				if enriched != nil {
+					usage = &enriched.Usage
 				}
-				if issue.AIPrompt == "" && enriched.AIPrompt != "" {
-					issue.AIPrompt = enriched.AIPrompt
+				if err != nil {
+					ui.Warning("LLM enrichment failed (issue will still be created): %v", err)
+				} else {
+					issue.Redactions = enriched.Redactions
+					reportRedactions(enriched.Redactions)
+					if issue.Description == "" && enriched.Description != "" {
+						issue.Description = enriched.Description
+					}
+					if issue.AIPrompt == "" && enriched.AIPrompt != "" {
+						issue.AIPrompt = enriched.AIPrompt
+					}
+					if len(issue.AcceptanceCriteria) == 0 {
+						issue.AcceptanceCriteria = enriched.AcceptanceCriteria
+					}
 				}
 			}
 		}
@@ -206,15 +269,19 @@ func issueAddRun(projectRef string) error {
 	if err := s.CreateIssue(ctx, issue); err != nil {
 		return fmt.Errorf("create issue: %w", err)
 	}
+	if usage != nil {
+		_ = s.RecordLLMUsage(ctx, usage.Record(p.ID, issue.ID, models.LLMUsageEnrichment))
+	}
+
+	ui.Success("Created issue %s: %s", output.Cyan(issueCode(issue)), issueTitle)
+	ui.Result(issue.ID)
 
 	// Apply tag if specified
 	if issueTag != "" {
 		if err := applyTag(ctx, s, issue.ID, issueTag); err != nil {
-			ui.Warning("Issue created but tag failed: %v", err)
+			return fmt.Errorf("issue %s created, but tagging it failed: %w", issueCode(issue), err)
 		}
 	}
-
-	ui.Success("Created issue %s: %s", output.Cyan(shortID(issue.ID)), issueTitle)
 	return nil
 }
 
@@ -226,9 +293,18 @@ func issueListRun(projectRef string) error {
 	ctx := context.Background()
 
 	filter := store.IssueListFilter{
-		Status:   models.IssueStatus(issueStatus),
-		Priority: models.IssuePriority(issuePriority),
 		Tag:      issueTag,
+		Assignee: issueAssignee,
+	}
+	if issueStatus != "" {
+		if filter.Status, err = models.ParseIssueStatus(issueStatus); err != nil {
+			return err
+		}
+	}
+	if issuePriority != "" {
+		if filter.Priority, err = models.ParseIssuePriority(issuePriority); err != nil {
+			return err
+		}
 	}
 
 	// Resolve project if specified, otherwise show all if --all or cwd
@@ -275,7 +351,7 @@ func issueListRun(projectRef string) error {
 		}
 
 		_ = table.Append([]string{
-			shortID(issue.ID),
+			issueCode(issue),
 			projName,
 			issue.Title,
 			output.StatusColor(string(issue.Status)),
@@ -305,11 +381,30 @@ func issueShowRun(id string) error {
 		projName = p.Name
 	}
 
-	fmt.Fprintf(ui.Out, "%s  %s\n", output.Cyan(shortID(issue.ID)), issue.Title)
+	fmt.Fprintf(ui.Out, "%s  %s\n", output.Cyan(issueCode(issue)), issue.Title)
+	fmt.Fprintf(ui.Out, "  ID:         %s\n", issue.ID)
 	fmt.Fprintf(ui.Out, "  Project:    %s\n", projName)
 	fmt.Fprintf(ui.Out, "  Status:     %s\n", output.StatusColor(string(issue.Status)))
 	fmt.Fprintf(ui.Out, "  Priority:   %s\n", issue.Priority)
 	fmt.Fprintf(ui.Out, "  Type:       %s\n", issue.Type)
+	if issue.Assignee != "" {
+		fmt.Fprintf(ui.Out, "  Assignee:   %s\n", issue.Assignee)
+	}
+	if issue.Estimate != "" {
+		fmt.Fprintf(ui.Out, "  Estimate:   %s\n", issue.Estimate)
+	}
+	if issue.Rank != "" {
+		fmt.Fprintf(ui.Out, "  Rank:       %s\n", issue.Rank)
+	}
+	if issue.SLAState != models.SLAStateNone {
+		fmt.Fprintf(ui.Out, "  SLA:        %s\n", issue.SLAState)
+	}
+	readiness := agent.AssessReadiness(ctx, s, issue)
+	if readiness.Ready {
+		fmt.Fprintf(ui.Out, "  Readiness:  %d%% (ready for an agent)\n", readiness.Score)
+	} else {
+		fmt.Fprintf(ui.Out, "  Readiness:  %d%% (%s)\n", readiness.Score, strings.Join(readiness.Missing(), ", "))
+	}
 	if issue.Description != "" {
 		fmt.Fprintf(ui.Out, "  Desc:       %s\n", issue.Description)
 	}
@@ -319,22 +414,54 @@ func issueShowRun(id string) error {
 	if issue.AIPrompt != "" {
 		fmt.Fprintf(ui.Out, "  AI Prompt:  %s\n", issue.AIPrompt)
 	}
+	if len(issue.AcceptanceCriteria) > 0 {
+		fmt.Fprintln(ui.Out, "  Acceptance criteria:")
+		for i, c := range issue.AcceptanceCriteria {
+			fmt.Fprintf(ui.Out, "    %d. %s\n", i+1, c)
+		}
+	}
 	if issue.GitHubIssue > 0 {
 		fmt.Fprintf(ui.Out, "  GitHub:     #%d\n", issue.GitHubIssue)
 	}
 	if len(issue.Tags) > 0 {
 		fmt.Fprintf(ui.Out, "  Tags:       %s\n", strings.Join(issue.Tags, ", "))
 	}
-	fmt.Fprintf(ui.Out, "  Created:    %s\n", issue.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(ui.Out, "  Created:    %s\n", displayTime(issue.CreatedAt).Format(time.RFC3339))
+	if issue.StartedAt != nil {
+		fmt.Fprintf(ui.Out, "  Started:    %s\n", displayTime(*issue.StartedAt).Format(time.RFC3339))
+	}
+	if issue.CompletedAt != nil {
+		completed := displayTime(*issue.CompletedAt).Format(time.RFC3339)
+		if d, ok := issue.CycleTime(); ok {
+			completed += fmt.Sprintf(" (cycle time %s)", formatHours(1, d.Hours()))
+		}
+		fmt.Fprintf(ui.Out, "  Completed:  %s\n", completed)
+	}
 	if issue.ClosedAt != nil {
-		fmt.Fprintf(ui.Out, "  Closed:     %s\n", issue.ClosedAt.Format(time.RFC3339))
+		fmt.Fprintf(ui.Out, "  Closed:     %s\n", displayTime(*issue.ClosedAt).Format(time.RFC3339))
+	}
+	if merged := issueMerge(issue); merged != "" {
+		fmt.Fprintf(ui.Out, "  Merged:     %s\n", merged)
 	}
 	fmt.Fprintf(ui.Out, "  Full ID:    %s\n", issue.ID)
 
+	if related, err := store.RelatedIssues(ctx, s, issue.ID); err == nil && len(related) > 0 {
+		fmt.Fprintln(ui.Out, "  Relations:")
+		for _, ri := range related {
+			ref := ri.Issue.Title
+			if ri.Issue.ProjectID != issue.ProjectID {
+				if p, err := s.GetProject(ctx, ri.Issue.ProjectID); err == nil {
+					ref = fmt.Sprintf("%s (%s)", ri.Issue.Title, p.Name)
+				}
+			}
+			fmt.Fprintf(ui.Out, "    %-13s %s  %s [%s]\n", ri.Label, output.Cyan(issueCode(ri.Issue)), ref, output.StatusColor(string(ri.Issue.Status)))
+		}
+	}
+
 	return nil
 }
 
-func issueUpdateRun(id string) error {
+func issueUpdateRun(id string, setAssignee, setEstimate, setCriteria bool) error {
 	s, err := getStore()
 	if err != nil {
 		return err
@@ -348,11 +475,15 @@ func issueUpdateRun(id string) error {
 
 	changed := false
 	if issueStatus != "" {
-		issue.Status = models.IssueStatus(issueStatus)
+		if issue.Status, err = models.ParseIssueStatus(issueStatus); err != nil {
+			return err
+		}
 		changed = true
 	}
 	if issuePriority != "" {
-		issue.Priority = models.IssuePriority(issuePriority)
+		if issue.Priority, err = models.ParseIssuePriority(issuePriority); err != nil {
+			return err
+		}
 		changed = true
 	}
 	if issueTitle != "" {
@@ -371,13 +502,25 @@ func issueUpdateRun(id string) error {
 		issue.AIPrompt = issueAIPrompt
 		changed = true
 	}
+	if setAssignee {
+		issue.Assignee = issueAssignee
+		changed = true
+	}
+	if setEstimate {
+		issue.Estimate = issueEstimate
+		changed = true
+	}
+	if setCriteria {
+		issue.AcceptanceCriteria = models.TrimCriteria(issueCriteria)
+		changed = true
+	}
 
 	if !changed {
-		return fmt.Errorf("no updates specified (use --status, --priority, --title, --desc, --body, or --ai-prompt)")
+		return fmt.Errorf("no updates specified (use --status, --priority, --title, --desc, --body, --ai-prompt, --assignee, --estimate, or --criterion)")
 	}
 
 	if dryRun {
-		ui.DryRunMsg("Would update issue %s", shortID(issue.ID))
+		ui.DryRunMsg("Would update issue %s", issueCode(issue))
 		return nil
 	}
 
@@ -385,7 +528,7 @@ func issueUpdateRun(id string) error {
 		return fmt.Errorf("update issue: %w", err)
 	}
 
-	ui.Success("Updated issue %s", output.Cyan(shortID(issue.ID)))
+	ui.Success("Updated issue %s", output.Cyan(issueCode(issue)))
 	return nil
 }
 
@@ -406,7 +549,7 @@ func issueCloseRun(id string) error {
 	issue.ClosedAt = &now
 
 	if dryRun {
-		ui.DryRunMsg("Would close issue %s: %s", shortID(issue.ID), issue.Title)
+		ui.DryRunMsg("Would close issue %s: %s", issueCode(issue), issue.Title)
 		return nil
 	}
 
@@ -414,7 +557,7 @@ func issueCloseRun(id string) error {
 		return fmt.Errorf("close issue: %w", err)
 	}
 
-	ui.Success("Closed issue %s: %s", output.Cyan(shortID(issue.ID)), issue.Title)
+	ui.Success("Closed issue %s: %s", output.Cyan(issueCode(issue)), issue.Title)
 	return nil
 }
 
@@ -433,7 +576,7 @@ func issueLinkRun(id string) error {
 	issue.GitHubIssue = issueGitHub
 
 	if dryRun {
-		ui.DryRunMsg("Would link issue %s to GitHub #%d", shortID(issue.ID), issueGitHub)
+		ui.DryRunMsg("Would link issue %s to GitHub #%d", issueCode(issue), issueGitHub)
 		return nil
 	}
 
@@ -441,7 +584,62 @@ func issueLinkRun(id string) error {
 		return fmt.Errorf("link issue: %w", err)
 	}
 
-	ui.Success("Linked issue %s to GitHub #%d", output.Cyan(shortID(issue.ID)), issueGitHub)
+	ui.Success("Linked issue %s to GitHub #%d", output.Cyan(issueCode(issue)), issueGitHub)
+	return nil
+}
+
+func issueRelateRun(id, relType, targetID string) error {
+	rt := models.RelationType(relType)
+	if !rt.Valid() {
+		return fmt.Errorf("invalid relation type: %s (use: relates_to, duplicates, caused_by, blocks, split_from)", relType)
+	}
+
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	source, err := findIssue(ctx, s, id)
+	if err != nil {
+		return err
+	}
+	target, err := findIssue(ctx, s, targetID)
+	if err != nil {
+		return err
+	}
+
+	if relateRemove {
+		rels, err := s.ListIssueRelations(ctx, source.ID)
+		if err != nil {
+			return err
+		}
+		for _, rel := range rels {
+			if rel.SourceIssueID != source.ID || rel.TargetIssueID != target.ID || rel.Type != rt {
+				continue
+			}
+			if dryRun {
+				ui.DryRunMsg("Would remove relation: %s %s %s", issueCode(source), rt.Label(), issueCode(target))
+				return nil
+			}
+			if err := s.DeleteIssueRelation(ctx, rel.ID); err != nil {
+				return err
+			}
+			ui.Success("Removed relation: %s %s %s", output.Cyan(issueCode(source)), rt.Label(), output.Cyan(issueCode(target)))
+			return nil
+		}
+		return fmt.Errorf("no %s relation from %s to %s", rt, issueCode(source), issueCode(target))
+	}
+
+	if dryRun {
+		ui.DryRunMsg("Would relate: %s %s %s", issueCode(source), rt.Label(), issueCode(target))
+		return nil
+	}
+	rel := &models.IssueRelation{SourceIssueID: source.ID, TargetIssueID: target.ID, Type: rt}
+	if err := s.CreateIssueRelation(ctx, rel); err != nil {
+		return err
+	}
+	ui.Success("%s %s %s", output.Cyan(issueCode(source)), rt.Label(), output.Cyan(issueCode(target)))
 	return nil
 }
 
@@ -464,7 +662,7 @@ func issueReviewRun(id string) error {
 	}
 
 	fmt.Fprintf(ui.Out, "\nIssue %s: %s (status: %s)\n\n",
-		output.Cyan(shortID(issue.ID)), issue.Title, string(issue.Status))
+		output.Cyan(issueCode(issue)), issue.Title, string(issue.Status))
 
 	if len(reviews) == 0 {
 		ui.Info("No reviews yet")
@@ -479,12 +677,27 @@ func issueReviewRun(id string) error {
 		if r.Verdict == models.ReviewVerdictFail {
 			verdict = output.Red("FAIL")
 		}
-		fmt.Fprintf(ui.Out, "  %s  %s  %s\n", verdict, r.ReviewedAt.Format("2006-01-02 15:04"), r.Summary)
+		summary := r.Summary
+		if r.Reviewer != "" {
+			summary = fmt.Sprintf("%s (by %s)", summary, r.Reviewer)
+		}
+		fmt.Fprintf(ui.Out, "  %s  %s  %s\n", verdict, displayTime(r.ReviewedAt).Format("2006-01-02 15:04"), summary)
 		if len(r.FailureReasons) > 0 {
 			for _, reason := range r.FailureReasons {
 				fmt.Fprintf(ui.Out, "         - %s\n", reason)
 			}
 		}
+		for _, c := range r.Criteria {
+			mark := output.Green("✓")
+			if !c.Passed {
+				mark = output.Red("✗")
+			}
+			line := c.Criterion
+			if c.Note != "" {
+				line += " — " + c.Note
+			}
+			fmt.Fprintf(ui.Out, "         %s %s\n", mark, line)
+		}
 		fmt.Fprintln(ui.Out)
 	}
 
@@ -514,7 +727,7 @@ func resolveProjectFromCwd(ctx context.Context, s store.Store) (*models.Project,
 
 	// Try git repo root (supports subdirectories)
 	gc := git.NewClient()
-	if root, err := gc.RepoRoot(cwd); err == nil && root != cwd {
+	if root, err := gc.RepoRoot(ctx, cwd); err == nil && root != cwd {
 		if p, err := s.GetProjectByPath(ctx, root); err == nil {
 			return p, nil
 		}
@@ -529,6 +742,11 @@ func findIssue(ctx context.Context, s store.Store, id string) (*models.Issue, er
 	if issue, err := s.GetIssue(ctx, id); err == nil {
 		return issue, nil
 	}
+	if code, isSession, ok := models.ParseShortCode(id); ok && !isSession {
+		if issue, err := s.GetIssueByCode(ctx, code); err == nil {
+			return issue, nil
+		}
+	}
 
 	// Try prefix match - list all and filter
 	upper := strings.ToUpper(id)
@@ -563,6 +781,43 @@ func shortID(id string) string {
 	return id
 }
 
+// issueCode returns how an issue is shown and referred to: its short code,
+// or its truncated ID if it has none.
+func issueCode(issue *models.Issue) string {
+	if issue.Code != "" {
+		return issue.Code
+	}
+	return shortID(issue.ID)
+}
+
+// sessionCode returns how a session is shown and referred to: its short
+// code, or its truncated ID if it has none.
+func sessionCode(sess *models.AgentSession) string {
+	if sess.Code != "" {
+		return sess.Code
+	}
+	return shortID(sess.ID)
+}
+
+// issueMerge describes where an issue's code landed ("1a2b3c4 into main at
+// ..."), or returns "" if none of its sessions has been merged.
+func issueMerge(issue *models.Issue) string {
+	if issue.MergeCommit == "" {
+		return ""
+	}
+	merged := issue.MergeCommit
+	if len(merged) > 7 {
+		merged = merged[:7]
+	}
+	if issue.MergeBranch != "" {
+		merged += " into " + issue.MergeBranch
+	}
+	if issue.MergedAt != nil {
+		merged += " at " + displayTime(*issue.MergedAt).Format(time.RFC3339)
+	}
+	return merged
+}
+
 // applyTag creates a tag if needed and applies it to an issue.
 func applyTag(ctx context.Context, s store.Store, issueID, tagName string) error {
 	// Find or create the tag
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/autoclose"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/store"
+)
+
+var autocloseLimit int
+
+var issueAutocloseCmd = &cobra.Command{
+	Use:   "autoclose [project]",
+	Short: "Close issues referenced by merged pull requests",
+	Long: `Scans the titles and bodies of the project's recently merged pull requests
+for closing keywords (close, fix, resolve and their forms) followed by issue
+references: "#123" for the issue linked to GitHub issue 123, or "PM-<id>" for
+a pm issue ID or ID prefix. Open and in-progress issues they reference move
+to done. Session commits are checked the same way when the session merges.
+
+Every closure is recorded ('pm issue closures') and can be undone with
+'pm issue revert-closure'. A reference is applied once, so rescanning is safe.
+Without <project>, auto-detects from cwd.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return issueAutocloseRun(argOrEmpty(args))
+	},
+}
+
+var issueClosuresCmd = &cobra.Command{
+	Use:   "closures [issue_id]",
+	Short: "List automatic issue closures",
+	Long:  "List the closures made from commit and pull request references, for one issue or, without <issue_id>, for the project in cwd.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return issueClosuresRun(argOrEmpty(args))
+	},
+}
+
+var issueRevertClosureCmd = &cobra.Command{
+	Use:   "revert-closure <closure_id>",
+	Short: "Undo an automatic issue closure",
+	Long: `Restores the issue's status from before the closure and reopens the GitHub
+issue closed with it. Refused when the issue's status has changed since.
+Accepts a closure ID prefix.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return issueRevertClosureRun(args[0])
+	},
+}
+
+func init() {
+	issueAutocloseCmd.Flags().IntVar(&autocloseLimit, "limit", 30, "Number of recently merged pull requests to scan")
+	issueCmd.AddCommand(issueAutocloseCmd)
+	issueCmd.AddCommand(issueClosuresCmd)
+	issueCmd.AddCommand(issueRevertClosureCmd)
+}
+
+// autoCloseConfig builds the issue auto-closure settings from config.
+func autoCloseConfig() autoclose.Config {
+	cfg := autoclose.Config{Enabled: viper.GetBool("autoclose.enabled")}
+	if viper.GetBool("autoclose.github") {
+		cfg.GitHub = newGitHubClient()
+	}
+	return cfg
+}
+
+func issueAutocloseRun(projectRef string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	p, err := resolveProjectOrCwd(ctx, s, projectRef)
+	if err != nil {
+		return err
+	}
+	cfg := autoCloseConfig()
+	if !cfg.Enabled {
+		ui.Warning("Automatic issue closure is disabled (autoclose.enabled)")
+		return nil
+	}
+
+	if err := requireForge(); err != nil {
+		return err
+	}
+	closures, err := autoclose.ScanMergedPRs(ctx, s, cfg, newGitHubClient(), p, autocloseLimit, dryRun)
+	if err != nil {
+		return err
+	}
+	if len(closures) == 0 {
+		ui.Info("No open issues referenced by the last %d merged pull requests", autocloseLimit)
+		return nil
+	}
+	for _, c := range closures {
+		if dryRun {
+			ui.DryRunMsg("Would close issue %s (%s)", shortID(c.IssueID), closureOrigin(c))
+		} else {
+			ui.Success("Closed issue %s (%s)", output.Cyan(shortID(c.IssueID)), closureOrigin(c))
+		}
+	}
+	return nil
+}
+
+func issueClosuresRun(issueRef string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var closures []*models.IssueClosure
+	if issueRef != "" {
+		issue, err := findIssue(ctx, s, issueRef)
+		if err != nil {
+			return err
+		}
+		closures, err = s.ListIssueClosures(ctx, "", issue.ID)
+		if err != nil {
+			return err
+		}
+	} else {
+		p, err := resolveProjectFromCwd(ctx, s)
+		if err != nil {
+			return err
+		}
+		closures, err = s.ListIssueClosures(ctx, p.ID, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(closures) == 0 {
+		ui.Info("No automatic issue closures")
+		return nil
+	}
+
+	table := ui.Table([]string{"ID", "Issue", "Source", "Change", "Message", "Closed", "Reverted"})
+	for _, c := range closures {
+		reverted := "-"
+		if c.RevertedAt != nil {
+			reverted = timeAgo(*c.RevertedAt)
+		}
+		_ = table.Append([]string{
+			shortID(c.ID),
+			shortID(c.IssueID),
+			closureOrigin(c),
+			fmt.Sprintf("%s → %s", c.FromStatus, c.ToStatus),
+			c.Message,
+			timeAgo(c.CreatedAt),
+			reverted,
+		})
+	}
+	_ = table.Render()
+	return nil
+}
+
+func issueRevertClosureRun(ref string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	c, err := findIssueClosure(ctx, s, ref)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		ui.DryRunMsg("Would revert closure %s: issue %s back to %s", shortID(c.ID), shortID(c.IssueID), c.FromStatus)
+		return nil
+	}
+
+	c, err = autoclose.Revert(ctx, s, newGitHubClient(), c.ID)
+	if err != nil {
+		return err
+	}
+	ui.Success("Reverted closure %s: issue %s is %s again", shortID(c.ID), output.Cyan(shortID(c.IssueID)), c.FromStatus)
+	return nil
+}
+
+// findIssueClosure resolves a closure by full ID or unique ID prefix.
+func findIssueClosure(ctx context.Context, s store.Store, ref string) (*models.IssueClosure, error) {
+	if c, err := s.GetIssueClosure(ctx, ref); err == nil {
+		return c, nil
+	}
+	all, err := s.ListIssueClosures(ctx, "", "")
+	if err != nil {
+		return nil, err
+	}
+	var match *models.IssueClosure
+	for _, c := range all {
+		if strings.HasPrefix(strings.ToUpper(c.ID), strings.ToUpper(ref)) {
+			if match != nil {
+				return nil, fmt.Errorf("ambiguous closure ID prefix: %s", ref)
+			}
+			match = c
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("issue closure not found: %s", ref)
+	}
+	return match, nil
+}
+
+// closureOrigin describes what closed an issue: "commit 1a2b3c4" or "pr #42".
+func closureOrigin(c *models.IssueClosure) string {
+	ref := c.Ref
+	if c.Source == models.ClosureSourceCommit && len(ref) > 7 {
+		ref = ref[:7]
+	}
+	return c.Source + " " + ref
+}
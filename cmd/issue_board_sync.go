@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/boardsync"
+	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/sla"
+	"github.com/joescharf/pm/internal/store"
+)
+
+var issueBoardSyncCmd = &cobra.Command{
+	Use:   "board-sync [project]",
+	Short: "Sync issue status and priority with the project's GitHub board",
+	Long: `Syncs the status and priority of the project's issues linked to GitHub
+issues with their items on the project's GitHub Projects (v2) board, set with
+pm project board. Issues that are not done or closed are added to the board
+when missing from it.
+
+Statuses map to the options of the board's status field through
+github.board_sync.columns (default: open Todo, in_progress and in_review
+In Progress, done and closed Done) and priorities to its priority field's
+through github.board_sync.priorities (default: Low, Medium, High); the
+priority is not synced when the board has no such field. A side that changed
+since the last sync wins; when both did, the most recent change does. pm
+serve syncs every project with a board on its own when
+github.board_sync.interval is set. Without <project>, auto-detects from cwd.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return issueBoardSyncRun(argOrEmpty(args))
+	},
+}
+
+func init() {
+	issueCmd.AddCommand(issueBoardSyncCmd)
+}
+
+// boardSyncOptions reads the board sync settings from the config.
+func boardSyncOptions() boardsync.Options {
+	return boardsync.Options{
+		StatusField:   viper.GetString("github.board_sync.status_field"),
+		PriorityField: viper.GetString("github.board_sync.priority_field"),
+		Columns:       viper.GetStringMapString("github.board_sync.columns"),
+		Priorities:    viper.GetStringMapString("github.board_sync.priorities"),
+	}
+}
+
+func issueBoardSyncRun(projectRef string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	p, err := resolveProjectOrCwd(ctx, s, projectRef)
+	if err != nil {
+		return err
+	}
+	if p.GitHubBoard == "" {
+		return fmt.Errorf("project %s has no GitHub board: set one with pm project board %s --set owner/number", p.Name, p.Name)
+	}
+	if err := requireForge(); err != nil {
+		return err
+	}
+	opts := boardSyncOptions()
+	opts.DryRun = dryRun
+	changes, err := boardsync.Sync(ctx, s, newGitHubClient(), p, opts)
+	for _, c := range changes {
+		verb, what := "set", boardChangeSummary(c)
+		if c.Field == "item" {
+			verb = "add"
+		}
+		if dryRun {
+			ui.DryRunMsg("Would %s %s", verb, what)
+			continue
+		}
+		if verb == "add" {
+			ui.Success("Added %s", what)
+		} else {
+			ui.Success("Set %s", what)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		ui.Info("Board %s is in sync with %s", p.GitHubBoard, output.Cyan(p.Name))
+	}
+	return nil
+}
+
+// boardChangeSummary describes a board sync change, e.g. "#7 to the board"
+// or "status of #7 to done in pm (was in_progress)".
+func boardChangeSummary(c boardsync.Change) string {
+	ref := fmt.Sprintf("#%d", c.GitHubIssue)
+	if c.Field == "item" {
+		return ref + " to the board"
+	}
+	where := "on the board"
+	if c.Direction == boardsync.ToPM {
+		where = "in pm"
+		ref = fmt.Sprintf("%s (issue %s)", ref, shortID(c.IssueID))
+	}
+	if c.From == "" {
+		return fmt.Sprintf("%s of %s to %s %s", c.Field, ref, c.To, where)
+	}
+	return fmt.Sprintf("%s of %s to %s %s (was %s)", c.Field, ref, c.To, where, c.From)
+}
+
+// startBoardSync syncs every project with a GitHub board every
+// github.board_sync.interval, if set.
+func startBoardSync(ctx context.Context, s store.Store) {
+	raw := viper.GetString("github.board_sync.interval")
+	interval, err := sla.ParseDuration(raw)
+	if err != nil {
+		ui.Warning("GitHub board sync disabled: invalid github.board_sync.interval %q", raw)
+		return
+	}
+	if interval <= 0 {
+		return
+	}
+	gh := newGitHubClient()
+
+	// The options are read on every pass, so config reloads reach them.
+	sync := func() {
+		opts := boardSyncOptions()
+		projects, err := s.ListProjects(ctx, "")
+		if err != nil {
+			ui.Warning("GitHub board sync: %v", err)
+			return
+		}
+		for _, p := range projects {
+			if p.GitHubBoard == "" {
+				continue
+			}
+			if _, err := boardsync.Sync(ctx, s, gh, p, opts); err != nil && ctx.Err() == nil {
+				ui.Warning("GitHub board sync (%s): %v", p.Name, err)
+			}
+		}
+	}
+
+	go func() {
+		sync()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sync()
+			}
+		}
+	}()
+}
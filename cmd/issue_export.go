@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/store"
+)
+
+var (
+	exportMDOutput string
+	exportMDStatus string
+)
+
+var issueExportMDCmd = &cobra.Command{
+	Use:   "export-md <issue|project>",
+	Short: "Export an issue or a project's issues as Markdown",
+	Long: `Export an issue, or every issue of a project, as GitHub-flavored Markdown
+with its full body, AI prompt, relations, review history and agent sessions.
+
+An issue is written to <short-id>-<title>.md, or to stdout with --output -,
+ready to paste into a pull request. A project is written to a directory
+(default <project>-issues) with one file per issue and a README.md index.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return issueExportMDRun(args[0])
+	},
+}
+
+func init() {
+	issueExportMDCmd.Flags().StringVarP(&exportMDOutput, "output", "o", "", "File or directory to write (- for stdout; issues only)")
+	issueExportMDCmd.Flags().StringVar(&exportMDStatus, "status", "", "Only export a project's issues with this status")
+	issueCmd.AddCommand(issueExportMDCmd)
+}
+
+func issueExportMDRun(ref string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	issue, err := findIssue(ctx, s, ref)
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		return err
+	}
+	if issue != nil {
+		return exportIssueMD(ctx, s, issue)
+	}
+
+	p, err := resolveProject(ctx, s, ref)
+	if err != nil {
+		return fmt.Errorf("no issue or project matches %s", ref)
+	}
+	return exportProjectMD(ctx, s, p)
+}
+
+func exportIssueMD(ctx context.Context, s store.Store, issue *models.Issue) error {
+	md := issueMarkdown(ctx, s, issue)
+	if exportMDOutput == "-" {
+		fmt.Fprint(ui.Out, md)
+		return nil
+	}
+
+	path := exportMDOutput
+	if path == "" {
+		path = issueMarkdownFile(issue)
+	}
+	if dryRun {
+		ui.DryRunMsg("Would write %s", path)
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(md), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	ui.Success("Exported issue %s to %s", output.Cyan(issueCode(issue)), path)
+	return nil
+}
+
+func exportProjectMD(ctx context.Context, s store.Store, p *models.Project) error {
+	if exportMDOutput == "-" {
+		return fmt.Errorf("--output - exports a single issue; give a directory for a project")
+	}
+	filter := store.IssueListFilter{ProjectID: p.ID}
+	if exportMDStatus != "" {
+		status, err := models.ParseIssueStatus(exportMDStatus)
+		if err != nil {
+			return err
+		}
+		filter.Status = status
+	}
+	issues, err := s.ListIssues(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		ui.Info("No issues to export for %s", p.Name)
+		return nil
+	}
+
+	dir := exportMDOutput
+	if dir == "" {
+		dir = p.Name + "-issues"
+	}
+	if dryRun {
+		ui.DryRunMsg("Would write %d issue(s) and README.md to %s", len(issues), dir)
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", dir, err)
+	}
+
+	var index strings.Builder
+	fmt.Fprintf(&index, "# %s issues\n\n", p.Name)
+	if p.Description != "" {
+		fmt.Fprintf(&index, "%s\n\n", p.Description)
+	}
+	index.WriteString("| ID | Title | Status | Priority | Type |\n|----|-------|--------|----------|------|\n")
+	for _, listed := range issues {
+		// Lists leave out tags; load the full issue.
+		issue, err := s.GetIssue(ctx, listed.ID)
+		if err != nil {
+			return err
+		}
+		file := issueMarkdownFile(issue)
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(issueMarkdown(ctx, s, issue)), 0o644); err != nil {
+			return fmt.Errorf("write %s: %w", file, err)
+		}
+		fmt.Fprintf(&index, "| [%s](%s) | %s | %s | %s | %s |\n",
+			issueCode(issue), file, mdCell(issue.Title), issue.Status, issue.Priority, issue.Type)
+	}
+	fmt.Fprintf(&index, "\n_Exported from pm on %s._\n", displayTime(time.Now()).Format("2006-01-02"))
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte(index.String()), 0o644); err != nil {
+		return fmt.Errorf("write README.md: %w", err)
+	}
+
+	ui.Success("Exported %d issue(s) of %s to %s", len(issues), output.Cyan(p.Name), dir)
+	return nil
+}
+
+// issueMarkdownFile names an issue's Markdown file: <short-id>-<title-slug>.md.
+func issueMarkdownFile(issue *models.Issue) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return '-'
+		}
+	}, issue.Title)
+	slug = strings.Join(strings.FieldsFunc(slug, func(r rune) bool { return r == '-' }), "-")
+	if len(slug) > 50 {
+		slug = strings.TrimRight(slug[:50], "-")
+	}
+	if slug == "" {
+		return shortID(issue.ID) + ".md"
+	}
+	return shortID(issue.ID) + "-" + slug + ".md"
+}
+
+// issueMarkdown renders an issue with its relations, reviews and sessions.
+// Parts that fail to load are left out rather than failing the export.
+func issueMarkdown(ctx context.Context, s store.Store, issue *models.Issue) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", issue.Title)
+
+	projName := issue.ProjectID
+	if p, err := s.GetProject(ctx, issue.ProjectID); err == nil {
+		projName = p.Name
+	}
+	b.WriteString("| | |\n|---|---|\n")
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "| **%s** | %s |\n", name, mdCell(value))
+		}
+	}
+	field("Project", projName)
+	field("Status", string(issue.Status))
+	field("Priority", string(issue.Priority))
+	field("Type", string(issue.Type))
+	field("Assignee", issue.Assignee)
+	field("Estimate", issue.Estimate)
+	field("Tags", strings.Join(issue.Tags, ", "))
+	if issue.GitHubIssue > 0 {
+		field("GitHub", fmt.Sprintf("#%d", issue.GitHubIssue))
+	}
+	field("Created", displayTime(issue.CreatedAt).Format(time.RFC3339))
+	if issue.StartedAt != nil {
+		field("Started", displayTime(*issue.StartedAt).Format(time.RFC3339))
+	}
+	if issue.CompletedAt != nil {
+		completed := displayTime(*issue.CompletedAt).Format(time.RFC3339)
+		if d, ok := issue.CycleTime(); ok {
+			completed += fmt.Sprintf(" (cycle time %s)", formatHours(1, d.Hours()))
+		}
+		field("Completed", completed)
+	}
+	if issue.MergeCommit != "" {
+		merged := "`" + issue.MergeCommit + "`"
+		if issue.MergeBranch != "" {
+			merged += " into `" + issue.MergeBranch + "`"
+		}
+		if issue.MergedAt != nil {
+			merged += " at " + displayTime(*issue.MergedAt).Format(time.RFC3339)
+		}
+		field("Merged", merged)
+	}
+	field("ID", "`"+issue.ID+"`")
+
+	section := func(title, text string) {
+		if text = strings.TrimSpace(text); text != "" {
+			fmt.Fprintf(&b, "\n## %s\n\n%s\n", title, text)
+		}
+	}
+	section("Description", issue.Description)
+	section("Body", issue.Body)
+	section("AI Prompt", issue.AIPrompt)
+
+	if len(issue.AcceptanceCriteria) > 0 {
+		b.WriteString("\n## Acceptance Criteria\n\n")
+		for _, c := range issue.AcceptanceCriteria {
+			fmt.Fprintf(&b, "- %s\n", c)
+		}
+	}
+
+	if related, err := store.RelatedIssues(ctx, s, issue.ID); err == nil && len(related) > 0 {
+		b.WriteString("\n## Relations\n\n")
+		for _, ri := range related {
+			fmt.Fprintf(&b, "- %s `%s` %s (%s)\n", ri.Label, issueCode(ri.Issue), ri.Issue.Title, ri.Issue.Status)
+		}
+	}
+
+	if reviews, err := s.ListIssueReviews(ctx, issue.ID); err == nil && len(reviews) > 0 {
+		b.WriteString("\n## Reviews\n")
+		for _, r := range reviews {
+			fmt.Fprintf(&b, "\n### %s: %s\n\n", displayTime(r.ReviewedAt).Format("2006-01-02 15:04"), strings.ToUpper(string(r.Verdict)))
+			if r.Summary != "" {
+				fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(r.Summary))
+			}
+			for _, c := range []struct {
+				name  string
+				value models.ReviewCategory
+			}{
+				{"Code quality", r.CodeQuality},
+				{"Requirements", r.RequirementsMatch},
+				{"Test coverage", r.TestCoverage},
+				{"UI/UX", r.UIUX},
+			} {
+				if c.value != "" {
+					fmt.Fprintf(&b, "- %s: %s\n", c.name, c.value)
+				}
+			}
+			if len(r.FailureReasons) > 0 {
+				b.WriteString("\nFailure reasons:\n\n")
+				for _, reason := range r.FailureReasons {
+					fmt.Fprintf(&b, "- %s\n", reason)
+				}
+			}
+			if len(r.Criteria) > 0 {
+				b.WriteString("\nAcceptance criteria:\n\n")
+				for _, c := range r.Criteria {
+					mark := " "
+					if c.Passed {
+						mark = "x"
+					}
+					line := c.Criterion
+					if c.Note != "" {
+						line += " — " + c.Note
+					}
+					fmt.Fprintf(&b, "- [%s] %s\n", mark, line)
+				}
+			}
+			if r.DiffStats != "" {
+				fmt.Fprintf(&b, "\n```\n%s\n```\n", strings.TrimSpace(r.DiffStats))
+			}
+		}
+	}
+
+	if sessions, err := s.ListAgentSessionsFiltered(ctx, store.SessionListFilter{IssueID: issue.ID}); err == nil {
+		wrote := false
+		for _, sess := range sessions {
+			if !wrote {
+				b.WriteString("\n## Agent Sessions\n")
+				wrote = true
+			}
+			fmt.Fprintf(&b, "\n### `%s` (%s)\n\n", sess.Branch, sess.Status)
+			started := displayTime(sess.StartedAt).Format("2006-01-02 15:04")
+			if sess.EndedAt != nil {
+				started += fmt.Sprintf(" to %s (%s)", displayTime(*sess.EndedAt).Format("2006-01-02 15:04"), sess.EndedAt.Sub(sess.StartedAt).Round(time.Minute))
+			}
+			fmt.Fprintf(&b, "- Started: %s\n", started)
+			if sess.CommitCount > 0 {
+				fmt.Fprintf(&b, "- Commits: %d", sess.CommitCount)
+				if sess.LastCommitHash != "" {
+					hash := sess.LastCommitHash
+					if len(hash) > 7 {
+						hash = hash[:7]
+					}
+					fmt.Fprintf(&b, ", last `%s` %s", hash, sess.LastCommitMessage)
+				}
+				b.WriteString("\n")
+			}
+			if sess.ReviewAttempt > 0 {
+				fmt.Fprintf(&b, "- Review follow-up: attempt %d\n", sess.ReviewAttempt)
+			}
+			if sess.BlockedReason != "" {
+				fmt.Fprintf(&b, "- Blocked: %s\n", sess.BlockedReason)
+			}
+			if sess.Outcome != "" {
+				fmt.Fprintf(&b, "- Outcome: %s\n", sess.Outcome)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// mdCell makes text safe for a Markdown table cell.
+func mdCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
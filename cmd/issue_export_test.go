@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/store"
+)
+
+func TestIssueMarkdownFile(t *testing.T) {
+	issue := &models.Issue{ID: "01JABCDEFGHIJKLMNOP", Title: "Fix: login | SSO (v2)!"}
+	assert.Equal(t, "01JABCDEFGHI-fix-login-sso-v2.md", issueMarkdownFile(issue))
+	issue.Title = "???"
+	assert.Equal(t, "01JABCDEFGHI.md", issueMarkdownFile(issue))
+}
+
+func TestIssueMarkdown(t *testing.T) {
+	s := store.NewMemoryStore()
+	ctx := context.Background()
+
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{
+		ProjectID: p.ID, Title: "Add rate limiting", Description: "Limit requests per token.",
+		Body: "Original report", AIPrompt: "Use a token bucket.", Status: models.IssueStatusInProgress,
+		Priority: models.IssuePriorityHigh, Type: models.IssueTypeFeature, Assignee: "bot-1",
+	}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	blocker := &models.Issue{ProjectID: p.ID, Title: "Add token model", Status: models.IssueStatusOpen, Priority: models.IssuePriorityMedium, Type: models.IssueTypeFeature}
+	require.NoError(t, s.CreateIssue(ctx, blocker))
+	require.NoError(t, s.CreateIssueRelation(ctx, &models.IssueRelation{SourceIssueID: blocker.ID, TargetIssueID: issue.ID, Type: models.RelationBlocks}))
+	require.NoError(t, s.CreateIssueReview(ctx, &models.IssueReview{
+		IssueID: issue.ID, Verdict: models.ReviewVerdictFail, Summary: "Missing tests.",
+		TestCoverage: "fail", FailureReasons: []string{"no limiter tests"}, ReviewedAt: time.Now(),
+	}))
+	sess := &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "feature/add-rate-limiting", WorktreePath: "/tmp/rl",
+		Status: models.SessionStatusIdle, CommitCount: 3, LastCommitHash: "abcdef123456", LastCommitMessage: "feat: limiter"}
+	require.NoError(t, s.CreateAgentSession(ctx, sess))
+	require.NoError(t, s.RecordIssueMerge(ctx, issue.ID, "deadbeef1234", "main", time.Now()))
+
+	got, err := s.GetIssue(ctx, issue.ID)
+	require.NoError(t, err)
+	md := issueMarkdown(ctx, s, got)
+
+	for _, want := range []string{
+		"# Add rate limiting\n",
+		"| **Project** | api |",
+		"| **Assignee** | bot-1 |",
+		"| **Merged** | `deadbeef1234` into `main` at ",
+		"## Description\n\nLimit requests per token.",
+		"## Body\n\nOriginal report",
+		"## AI Prompt\n\nUse a token bucket.",
+		"Add token model (open)",
+		": FAIL\n\nMissing tests.",
+		"- Test coverage: fail",
+		"- no limiter tests",
+		"### `feature/add-rate-limiting` (idle)",
+		"- Commits: 3, last `abcdef1` feat: limiter",
+	} {
+		assert.Contains(t, md, want)
+	}
+}
+
+func TestExportProjectMD(t *testing.T) {
+	s := store.NewMemoryStore()
+	ctx := context.Background()
+	ui = output.New()
+
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	for _, title := range []string{"First", "Second"} {
+		require.NoError(t, s.CreateIssue(ctx, &models.Issue{ProjectID: p.ID, Title: title, Status: models.IssueStatusOpen, Priority: models.IssuePriorityLow, Type: models.IssueTypeChore}))
+	}
+
+	dir := filepath.Join(t.TempDir(), "out")
+	exportMDOutput = dir
+	t.Cleanup(func() { exportMDOutput = "" })
+	require.NoError(t, exportProjectMD(ctx, s, p))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+	index, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(index), "# api issues")
+	assert.Contains(t, string(index), "-first.md) | First | open | low | chore |")
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joescharf/pm/internal/groom"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/output"
+)
+
+var (
+	splitParts  []int
+	splitTitles []string
+)
+
+var issueSplitCmd = &cobra.Command{
+	Use:   "split <issue-id>",
+	Short: "Split an issue into smaller ones",
+	Long: `Create issues from checklist items ("- [ ] ...") and Markdown sections of an
+issue's body, selected by number, and from extra titles. Each new issue gets
+the original's project, type, priority and tags and is related to it as
+split_from; the original is left as it is.
+
+Without --part or --title, lists the numbered parts of the body.
+
+Example: pm issue split 01JEPIC --part 1,3 --title "Document the API"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return issueSplitRun(args[0])
+	},
+}
+
+var issueMergeCmd = &cobra.Command{
+	Use:   "merge <issue-id> <duplicate-id>...",
+	Short: "Fold duplicate issues into one",
+	Long: `Merge duplicates into an issue. Each duplicate is closed and related to the
+issue as a duplicate, keeping its own comments, reviews and sessions. The
+issue gains its text, acceptance criteria, tags, watchers and relations, its
+GitHub link if it has none and its priority if that is higher. Both get a
+comment recording the merge.
+
+Example: pm issue merge 01JLOGIN 01JSIGNIN 01JAUTHFAIL`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return issueMergeRun(args[0], args[1:])
+	},
+}
+
+func init() {
+	issueSplitCmd.Flags().IntSliceVar(&splitParts, "part", nil, "Numbers of the parts to split off (repeatable or comma-separated)")
+	issueSplitCmd.Flags().StringArrayVar(&splitTitles, "title", nil, "Title of a further issue to split off (repeatable)")
+	issueCmd.AddCommand(issueSplitCmd)
+	issueCmd.AddCommand(issueMergeCmd)
+}
+
+func issueSplitRun(id string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	issue, err := findIssue(ctx, s, id)
+	if err != nil {
+		return err
+	}
+	available := groom.Parts(issue.Body)
+
+	if len(splitParts) == 0 && len(splitTitles) == 0 {
+		if len(available) == 0 {
+			ui.Info("Issue %s has no checklist items or sections. Use --title to split off issues.", issueCode(issue))
+			return nil
+		}
+		table := ui.Table([]string{"#", "Kind", "Title"})
+		for i, p := range available {
+			title := p.Title
+			if p.Done {
+				title += output.Green(" (done)")
+			}
+			_ = table.Append([]string{fmt.Sprint(i + 1), p.Kind, title})
+		}
+		_ = table.Render()
+		return nil
+	}
+
+	var parts []groom.Part
+	for _, n := range splitParts {
+		if n < 1 || n > len(available) {
+			return fmt.Errorf("invalid part %d (issue %s has %d)", n, issueCode(issue), len(available))
+		}
+		parts = append(parts, available[n-1])
+	}
+	for _, title := range splitTitles {
+		parts = append(parts, groom.Part{Kind: groom.PartItem, Title: title})
+	}
+
+	if dryRun {
+		for _, p := range parts {
+			ui.DryRunMsg("Would split off %q from issue %s", p.Title, issueCode(issue))
+		}
+		return nil
+	}
+
+	children, err := groom.Split(ctx, s, issue, parts)
+	for _, child := range children {
+		ui.Success("Split off issue %s: %s", output.Cyan(issueCode(child)), child.Title)
+	}
+	return err
+}
+
+func issueMergeRun(id string, duplicateIDs []string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	target, err := findIssue(ctx, s, id)
+	if err != nil {
+		return err
+	}
+	var dups []*models.Issue
+	for _, ref := range duplicateIDs {
+		dup, err := findIssue(ctx, s, ref)
+		if err != nil {
+			return err
+		}
+		dups = append(dups, dup)
+	}
+	if err := groom.CheckMerge(target, dups); err != nil {
+		return err
+	}
+
+	if dryRun {
+		for _, dup := range dups {
+			ui.DryRunMsg("Would merge issue %s into %s and close it", issueCode(dup), issueCode(target))
+		}
+		return nil
+	}
+
+	if err := groom.Merge(ctx, s, target, dups); err != nil {
+		return err
+	}
+	for _, dup := range dups {
+		ui.Success("Merged issue %s into %s and closed it", issueCode(dup), output.Cyan(issueCode(target)))
+	}
+	return nil
+}
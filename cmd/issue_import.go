@@ -2,13 +2,18 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/joescharf/pm/internal/capabilities"
 	"github.com/joescharf/pm/internal/llm"
+	"github.com/joescharf/pm/internal/llmbudget"
 	"github.com/joescharf/pm/internal/models"
 	"github.com/joescharf/pm/internal/store"
 )
@@ -68,7 +73,7 @@ func issueImportRun(file string) error {
 func importWithLLM(ctx context.Context, s store.Store, content string) error {
 	client := newLLMClient()
 	if client == nil {
-		return fmt.Errorf("ANTHROPIC_API_KEY not set (set env var or anthropic.api_key in config)")
+		return errors.New(capabilities.ReasonNoLLM)
 	}
 
 	// Get known project names for the LLM
@@ -76,15 +81,9 @@ func importWithLLM(ctx context.Context, s store.Store, content string) error {
 	if err != nil {
 		return fmt.Errorf("list projects: %w", err)
 	}
-	projectNames := make([]string, len(projects))
-	for i, p := range projects {
-		projectNames[i] = p.Name
-	}
-
-	ui.Info("Extracting issues with LLM...")
-	extracted, err := client.ExtractIssues(ctx, content, projectNames)
+	extracted, err := extractIssues(ctx, s, client, content, projects)
 	if err != nil {
-		return fmt.Errorf("extract issues: %w", err)
+		return err
 	}
 
 	if len(extracted) == 0 {
@@ -143,6 +142,89 @@ func importWithLLM(ctx context.Context, s store.Store, content string) error {
 	return createExtractedIssues(ctx, s, extracted)
 }
 
+// extractIssues has the LLM extract issues from content and assign them to
+// projects, recording the tokens spent against those projects. If a project
+// the content names in a "## Project <name>" heading is over its monthly LLM
+// budget, the content is parsed by its headings and keywords instead, or
+// refused when llm.budget.on_exceeded is reject. Content naming no known
+// project may be assigned to any of them, so all of them are checked.
+func extractIssues(ctx context.Context, s store.Store, client *llm.Client, content string, projects []*models.Project) ([]llm.ExtractedIssue, error) {
+	budget, err := llmBudget()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*models.Project, len(projects))
+	projectNames := make([]string, len(projects))
+	for i, p := range projects {
+		byName[p.Name] = p
+		projectNames[i] = p.Name
+	}
+
+	parsed := parseMarkdownIssues(content)
+	var named []*models.Project
+	for _, e := range parsed {
+		if p := byName[e.Project]; p != nil && !slices.Contains(named, p) {
+			named = append(named, p)
+		}
+	}
+	if len(named) == 0 {
+		named = projects
+	}
+	for _, p := range named {
+		if err := budget.Check(ctx, s, p, time.Now()); err != nil {
+			if !errors.Is(err, llmbudget.ErrExceeded) || !budget.Degrade() {
+				return nil, err
+			}
+			ui.Warning("%v; extracting issues by headings and keywords instead", err)
+			return parsed, nil
+		}
+	}
+
+	ui.Info("Extracting issues with LLM...")
+	extracted, redactions, usage, err := client.ExtractIssues(ctx, content, projectNames)
+	// Recorded even for dry runs: the tokens were spent.
+	recordImportUsage(ctx, s, usage, extracted, byName)
+	if err != nil {
+		return nil, fmt.Errorf("extract issues: %w", err)
+	}
+	reportRedactions(redactions)
+	return extracted, nil
+}
+
+// recordImportUsage splits the tokens an import spent between the projects
+// its issues were assigned to, by their number of issues. Nothing is
+// recorded if no issue was assigned to a known project.
+func recordImportUsage(ctx context.Context, s store.Store, u llm.Usage, extracted []llm.ExtractedIssue, byName map[string]*models.Project) {
+	counts := make(map[string]int64)
+	var order []string
+	var total int64
+	for _, e := range extracted {
+		p := byName[e.Project]
+		if p == nil || isPlaceholderTitle(e.Title) {
+			continue
+		}
+		if counts[p.ID] == 0 {
+			order = append(order, p.ID)
+		}
+		counts[p.ID]++
+		total++
+	}
+	var in, out int64
+	for i, id := range order {
+		share := u
+		if i < len(order)-1 {
+			share.InputTokens = u.InputTokens * counts[id] / total
+			share.OutputTokens = u.OutputTokens * counts[id] / total
+		} else {
+			share.InputTokens = u.InputTokens - in
+			share.OutputTokens = u.OutputTokens - out
+		}
+		in += share.InputTokens
+		out += share.OutputTokens
+		_ = s.RecordLLMUsage(ctx, share.Record(id, "", models.LLMUsageImport))
+	}
+}
+
 // importWithProject assigns all issues from a simple parse to the given project.
 func importWithProject(ctx context.Context, s store.Store, content, projectName string) error {
 	p, err := resolveProject(ctx, s, projectName)
@@ -362,6 +444,7 @@ func createExtractedIssues(ctx context.Context, s store.Store, extracted []llm.E
 	created := 0
 	duplicates := 0
 	skipped := 0
+	failed := 0
 
 	for _, e := range extracted {
 		// Skip empty or placeholder titles (e.g., LLM generating "no issues specified")
@@ -375,7 +458,7 @@ func createExtractedIssues(ctx context.Context, s store.Store, extracted []llm.E
 			p, err := s.GetProjectByName(ctx, e.Project)
 			if err != nil {
 				ui.Warning("Skipping issue %q: project %q not found", e.Title, e.Project)
-				skipped++
+				failed++
 				continue
 			}
 			projectCache[e.Project] = p
@@ -417,7 +500,7 @@ func createExtractedIssues(ctx context.Context, s store.Store, extracted []llm.E
 
 		if err := s.CreateIssue(ctx, issue); err != nil {
 			ui.Warning("Failed to create issue %q: %v", e.Title, err)
-			skipped++
+			failed++
 			continue
 		}
 		created++
@@ -436,8 +519,10 @@ func createExtractedIssues(ctx context.Context, s store.Store, extracted []llm.E
 		ui.Info("Skipped %d duplicate issues", duplicates)
 	}
 	if skipped > 0 {
-		ui.Warning("Skipped %d issues (errors)", skipped)
+		ui.Info("Skipped %d issues without a title", skipped)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d issue(s) could not be imported", failed)
 	}
-
 	return nil
 }
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/prreviews"
+	"github.com/joescharf/pm/internal/sla"
+	"github.com/joescharf/pm/internal/store"
+)
+
+var prReviewsFollowUps bool
+
+var issuePRReviewsCmd = &cobra.Command{
+	Use:   "pr-reviews [project]",
+	Short: "Import GitHub pull request reviews into issues",
+	Long: `Imports the reviews of the project's open pull requests opened from pm
+sessions: each review, with its inline comments, becomes a comment on the
+session's issue. With --follow-ups, a review requesting changes also opens a
+follow-up issue, related to the session's issue, for the agent loop to pick
+up.
+
+Reviews by bots and by the pull request's author are skipped, as are
+dismissed reviews and reviews with nothing to say. A review is imported
+once, so syncing again is safe. pm serve syncs every project on its own
+when github.review_sync.interval is set. Without <project>, auto-detects
+from cwd.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return issuePRReviewsRun(argOrEmpty(args))
+	},
+}
+
+func init() {
+	issuePRReviewsCmd.Flags().BoolVar(&prReviewsFollowUps, "follow-ups", false, "Open a follow-up issue for each review requesting changes (default: github.review_sync.follow_ups)")
+	issueCmd.AddCommand(issuePRReviewsCmd)
+}
+
+func issuePRReviewsRun(projectRef string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	p, err := resolveProjectOrCwd(ctx, s, projectRef)
+	if err != nil {
+		return err
+	}
+	if err := requireForge(); err != nil {
+		return err
+	}
+	opts := prreviews.Options{
+		FollowUps: prReviewsFollowUps || viper.GetBool("github.review_sync.follow_ups"),
+		DryRun:    dryRun,
+	}
+	imports, err := prreviews.Sync(ctx, s, newGitHubClient(), p, opts)
+	if err != nil {
+		return err
+	}
+	if len(imports) == 0 {
+		ui.Info("No new reviews on the project's open session pull requests")
+		return nil
+	}
+	for _, r := range imports {
+		what := reviewSummary(r)
+		if dryRun {
+			ui.DryRunMsg("Would import %s into issue %s", what, shortID(r.IssueID))
+			continue
+		}
+		ui.Success("Imported %s into issue %s", what, output.Cyan(shortID(r.IssueID)))
+		if r.FollowUpIssueID != "" {
+			ui.Info("Opened follow-up issue %s", output.Cyan(shortID(r.FollowUpIssueID)))
+		}
+	}
+	return nil
+}
+
+// reviewSummary describes an imported review, e.g. "review by @ada on PR #7
+// (changes requested)".
+func reviewSummary(r *models.PRReviewImport) string {
+	state := strings.ToLower(strings.ReplaceAll(r.State, "_", " "))
+	return fmt.Sprintf("review by @%s on PR #%d (%s)", r.Reviewer, r.PRNumber, state)
+}
+
+// startReviewSync imports new pull request reviews for every project with a
+// GitHub repo every github.review_sync.interval, if set.
+func startReviewSync(ctx context.Context, s store.Store) {
+	raw := viper.GetString("github.review_sync.interval")
+	interval, err := sla.ParseDuration(raw)
+	if err != nil {
+		ui.Warning("Pull request review sync disabled: invalid github.review_sync.interval %q", raw)
+		return
+	}
+	if interval <= 0 {
+		return
+	}
+	gh := newGitHubClient()
+	opts := prreviews.Options{FollowUps: viper.GetBool("github.review_sync.follow_ups")}
+
+	sync := func() {
+		projects, err := s.ListProjects(ctx, "")
+		if err != nil {
+			ui.Warning("Pull request review sync: %v", err)
+			return
+		}
+		for _, p := range projects {
+			if _, _, err := git.ExtractOwnerRepo(p.RepoURL); err != nil {
+				continue
+			}
+			if _, err := prreviews.Sync(ctx, s, gh, p, opts); err != nil && ctx.Err() == nil {
+				ui.Warning("Pull request review sync (%s): %v", p.Name, err)
+			}
+		}
+	}
+
+	go func() {
+		sync()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sync()
+			}
+		}
+	}()
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/store"
+)
+
+var (
+	rankBefore string
+	rankAfter  string
+	rankTop    bool
+	rankBottom bool
+)
+
+var issueRankCmd = &cobra.Command{
+	Use:   "rank <issue-id>",
+	Short: "Move an issue within its status column",
+	Long: `Order an issue by hand among the issues of its project that share its
+status. pm issue list and the board show ranked issues first, in rank order,
+then the rest by priority. Changing an issue's status drops its rank.
+
+Example: pm issue rank 01JBUG --before 01JFEAT`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return issueRankRun(args[0])
+	},
+}
+
+func init() {
+	issueRankCmd.Flags().StringVar(&rankBefore, "before", "", "Place the issue directly before this issue")
+	issueRankCmd.Flags().StringVar(&rankAfter, "after", "", "Place the issue directly after this issue")
+	issueRankCmd.Flags().BoolVar(&rankTop, "top", false, "Place the issue at the top of its column")
+	issueRankCmd.Flags().BoolVar(&rankBottom, "bottom", false, "Place the issue at the bottom of its column")
+	issueRankCmd.MarkFlagsMutuallyExclusive("before", "after", "top", "bottom")
+	issueRankCmd.MarkFlagsOneRequired("before", "after", "top", "bottom")
+	issueCmd.AddCommand(issueRankCmd)
+}
+
+func issueRankRun(id string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	issue, err := findIssue(ctx, s, id)
+	if err != nil {
+		return err
+	}
+	at := store.RankPlacement{Top: rankTop, Bottom: rankBottom}
+	if rankBefore != "" {
+		other, err := findIssue(ctx, s, rankBefore)
+		if err != nil {
+			return err
+		}
+		at.Before = other.ID
+	}
+	if rankAfter != "" {
+		other, err := findIssue(ctx, s, rankAfter)
+		if err != nil {
+			return err
+		}
+		at.After = other.ID
+	}
+	if dryRun {
+		ui.DryRunMsg("Would rank issue %s in the %s column", issueCode(issue), issue.Status)
+		return nil
+	}
+
+	ranked, err := store.RankIssue(ctx, s, issue.ID, at)
+	if err != nil {
+		return err
+	}
+	ui.Success("Ranked issue %s (%s) in the %s column", output.Cyan(issueCode(ranked)), ranked.Rank, ranked.Status)
+	return nil
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/api"
+	"github.com/joescharf/pm/internal/auth"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/sla"
+	"github.com/joescharf/pm/internal/store"
+)
+
+var (
+	shareReviewer string
+	shareExpires  string
+	shareList     bool
+)
+
+var issueShareCmd = &cobra.Command{
+	Use:   "share <issue-id>",
+	Short: "Create a review link for someone without pm",
+	Long: `Create a time-limited, read-only link to an issue's review context: its
+requirements, acceptance criteria and the diff of its branch. The reviewer
+opens it in a browser served by pm serve and submits one verdict, which is
+recorded in the issue's review history like an agent's.
+
+The link is shown only once. With --list, lists the issue's links instead.
+
+Example: pm issue share 01JBUG --reviewer "Dana" --expires 1w`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if shareList {
+			return issueSharesRun(args[0])
+		}
+		return issueShareRun(args[0])
+	},
+}
+
+var issueUnshareCmd = &cobra.Command{
+	Use:   "unshare <issue-id> <share-id>",
+	Short: "Revoke a review link by ID or unique prefix",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return issueUnshareRun(args[0], args[1])
+	},
+}
+
+func init() {
+	issueShareCmd.Flags().StringVar(&shareReviewer, "reviewer", "", "Who the link is for; recorded on their review")
+	issueShareCmd.Flags().StringVar(&shareExpires, "expires", "", `How long the link stays open, e.g. "72h", "3d", "1w" (default: review.share.ttl)`)
+	issueShareCmd.Flags().BoolVar(&shareList, "list", false, "List the issue's review links")
+	issueCmd.AddCommand(issueShareCmd)
+	issueCmd.AddCommand(issueUnshareCmd)
+}
+
+// reviewShareTTL reads review.share.ttl, how long review links stay open.
+func reviewShareTTL() time.Duration {
+	d, err := sla.ParseDuration(viper.GetString("review.share.ttl"))
+	if err != nil || d <= 0 {
+		ui.Warning("Invalid review.share.ttl %q, using the default", viper.GetString("review.share.ttl"))
+		return api.DefaultReviewShareTTL
+	}
+	return d
+}
+
+// reviewShareURL is the link for token: under review.share.base_url, or else
+// the address pm serve listens on.
+func reviewShareURL(token string) string {
+	base := strings.TrimRight(viper.GetString("review.share.base_url"), "/")
+	if base == "" {
+		base = serveURL(viper.GetString("bind"), viper.GetInt("port"))
+	}
+	return base + api.ReviewSharePath(token)
+}
+
+func issueShareRun(id string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	issue, err := findIssue(ctx, s, id)
+	if err != nil {
+		return err
+	}
+	ttl := reviewShareTTL()
+	if shareExpires != "" {
+		if ttl, err = sla.ParseDuration(shareExpires); err != nil || ttl <= 0 {
+			return fmt.Errorf("invalid --expires %q", shareExpires)
+		}
+	}
+
+	if dryRun {
+		ui.DryRunMsg("Would create a review link for issue %s", issueCode(issue))
+		return nil
+	}
+
+	token, hash, err := auth.NewShareToken()
+	if err != nil {
+		return err
+	}
+	share := &models.ReviewShare{IssueID: issue.ID, Reviewer: shareReviewer, TokenHash: hash, ExpiresAt: time.Now().UTC().Add(ttl)}
+	if err := s.CreateReviewShare(ctx, share); err != nil {
+		return err
+	}
+
+	ui.Success("Created review link %s for issue %s, open until %s",
+		shortID(share.ID), output.Cyan(issueCode(issue)), displayTime(share.ExpiresAt).Format("2006-01-02 15:04"))
+	ui.Warning("Copy the link now; it cannot be shown again. It works while pm serve runs.")
+	fmt.Fprintln(ui.Out, reviewShareURL(token))
+	return nil
+}
+
+func issueSharesRun(id string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	issue, err := findIssue(ctx, s, id)
+	if err != nil {
+		return err
+	}
+	shares, err := s.ListReviewShares(ctx, issue.ID)
+	if err != nil {
+		return err
+	}
+	if len(shares) == 0 {
+		ui.Info("No review links for issue %s. Use 'pm issue share %s' to create one.", issueCode(issue), issueCode(issue))
+		return nil
+	}
+
+	now := time.Now()
+	table := ui.Table([]string{"ID", "Reviewer", "Expires", "Verdict"})
+	for _, rs := range shares {
+		expires := displayTime(rs.ExpiresAt).Format("2006-01-02 15:04")
+		if rs.Expired(now) {
+			expires = output.Yellow("expired")
+		}
+		verdict := "-"
+		if rs.ReviewID != "" {
+			verdict = "submitted"
+		}
+		_ = table.Append([]string{shortID(rs.ID), rs.Reviewer, expires, verdict})
+	}
+	_ = table.Render()
+	return nil
+}
+
+func issueUnshareRun(id, ref string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	issue, err := findIssue(ctx, s, id)
+	if err != nil {
+		return err
+	}
+	share, err := findReviewShare(ctx, s, issue.ID, ref)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		ui.DryRunMsg("Would revoke review link %s", shortID(share.ID))
+		return nil
+	}
+	if err := s.DeleteReviewShare(ctx, share.ID); err != nil {
+		return err
+	}
+	ui.Success("Revoked review link %s", shortID(share.ID))
+	return nil
+}
+
+// findReviewShare resolves an ID prefix among an issue's review links.
+func findReviewShare(ctx context.Context, s store.Store, issueID, ref string) (*models.ReviewShare, error) {
+	shares, err := s.ListReviewShares(ctx, issueID)
+	if err != nil {
+		return nil, err
+	}
+	var matches []*models.ReviewShare
+	for _, rs := range shares {
+		if strings.HasPrefix(rs.ID, strings.ToUpper(ref)) {
+			matches = append(matches, rs)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("review link not found: %s", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("ambiguous review link ID %s matches %d links", ref, len(matches))
+	}
+}
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/notify"
+	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/store"
+)
+
+var (
+	watchKind     string
+	commentAuthor string
+)
+
+var issueWatchCmd = &cobra.Command{
+	Use:   "watch <issue-id> [target...]",
+	Short: "Watch an issue, or list its watchers",
+	Long: `Subscribe email addresses or webhook URLs to an issue. While pm serve runs,
+watchers are notified when the issue changes status, gets a comment or
+gets a review verdict; nobody else is. Emails go out through the
+notify.email SMTP settings, webhooks receive a JSON POST.
+
+The kind is inferred: http(s) URLs are webhooks, anything else must be an
+email address. Without a target, lists the issue's watchers.
+
+Example: pm issue watch 01JBUG dev@example.com https://hooks.example.com/pm`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return issueWatchRun(args[0], args[1:])
+	},
+}
+
+var issueUnwatchCmd = &cobra.Command{
+	Use:   "unwatch <issue-id> <target>",
+	Short: "Stop notifying a watcher about an issue",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return issueUnwatchRun(args[0], args[1])
+	},
+}
+
+var issueCommentCmd = &cobra.Command{
+	Use:   "comment <issue-id> [text]",
+	Short: "Comment on an issue, or list its comments",
+	Long: `Add a Markdown comment to an issue. The issue's watchers are notified.
+Without text, lists the issue's comments, oldest first.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			return issueCommentsRun(args[0])
+		}
+		return issueCommentRun(args[0], args[1])
+	},
+}
+
+func init() {
+	issueWatchCmd.Flags().StringVar(&watchKind, "kind", "", "Watcher kind: email or webhook (default: inferred from the target)")
+	issueCommentCmd.Flags().StringVar(&commentAuthor, "author", "", "Comment author (default: $USER)")
+	issueCmd.AddCommand(issueWatchCmd)
+	issueCmd.AddCommand(issueUnwatchCmd)
+	issueCmd.AddCommand(issueCommentCmd)
+}
+
+func issueWatchRun(id string, targets []string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	issue, err := findIssue(ctx, s, id)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return printIssueWatchers(ctx, s, issue)
+	}
+
+	for _, t := range targets {
+		kind, target, err := models.ParseWatcherTarget(watchKind, t)
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			ui.DryRunMsg("Would add %s watcher %s to issue %s", kind, target, issueCode(issue))
+			continue
+		}
+		added, err := s.AddIssueWatcher(ctx, &models.IssueWatcher{IssueID: issue.ID, Kind: kind, Target: target})
+		if err != nil {
+			return err
+		}
+		if added {
+			ui.Success("%s now watches issue %s", target, output.Cyan(issueCode(issue)))
+		} else {
+			ui.Info("%s already watches issue %s", target, issueCode(issue))
+		}
+	}
+	return nil
+}
+
+func printIssueWatchers(ctx context.Context, s store.Store, issue *models.Issue) error {
+	watchers, err := s.ListIssueWatchers(ctx, issue.ID)
+	if err != nil {
+		return err
+	}
+	if len(watchers) == 0 {
+		ui.Info("Nobody watches issue %s", issueCode(issue))
+		return nil
+	}
+	table := ui.Table([]string{"Kind", "Target", "Since"})
+	for _, w := range watchers {
+		_ = table.Append([]string{string(w.Kind), w.Target, timeAgo(w.CreatedAt)})
+	}
+	_ = table.Render()
+	return nil
+}
+
+func issueUnwatchRun(id, target string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	issue, err := findIssue(ctx, s, id)
+	if err != nil {
+		return err
+	}
+	// Stored targets are normalized, so "Dev <dev@example.com>" matches too.
+	if _, normalized, err := models.ParseWatcherTarget("", target); err == nil {
+		target = normalized
+	}
+	if dryRun {
+		ui.DryRunMsg("Would remove watcher %s from issue %s", target, issueCode(issue))
+		return nil
+	}
+	if err := s.RemoveIssueWatcher(ctx, issue.ID, target); err != nil {
+		return err
+	}
+	ui.Success("%s no longer watches issue %s", target, output.Cyan(issueCode(issue)))
+	return nil
+}
+
+func issueCommentRun(id, text string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	issue, err := findIssue(ctx, s, id)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(text) == "" {
+		return errors.New("comment text is empty")
+	}
+	author := commentAuthor
+	if author == "" {
+		author = os.Getenv("USER")
+	}
+	if dryRun {
+		ui.DryRunMsg("Would comment on issue %s as %s", issueCode(issue), author)
+		return nil
+	}
+	if err := s.CreateIssueComment(ctx, &models.IssueComment{IssueID: issue.ID, Author: author, Body: text}); err != nil {
+		return err
+	}
+	ui.Success("Commented on issue %s", output.Cyan(issueCode(issue)))
+	return nil
+}
+
+func issueCommentsRun(id string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	issue, err := findIssue(ctx, s, id)
+	if err != nil {
+		return err
+	}
+	comments, err := s.ListIssueComments(ctx, issue.ID)
+	if err != nil {
+		return err
+	}
+	if len(comments) == 0 {
+		ui.Info("No comments on issue %s", issueCode(issue))
+		return nil
+	}
+	for _, c := range comments {
+		author := c.Author
+		if author == "" {
+			author = "unknown"
+		}
+		ui.Info("%s, %s:", output.Cyan(author), timeAgo(c.CreatedAt))
+		ui.Info("%s", c.Body)
+	}
+	return nil
+}
+
+// startIssueNotifier notifies issue watchers about activity on their issues,
+// checking every notify.watch.interval. Email watchers are skipped unless
+// notify.email.host and notify.email.from are set; webhook bodies are signed
+// with notify.webhook.secret when it is.
+func startIssueNotifier(ctx context.Context, s store.Store) {
+	interval, err := time.ParseDuration(viper.GetString("notify.watch.interval"))
+	if err != nil || interval <= 0 {
+		ui.Warning("Issue watcher notifications disabled: invalid notify.watch.interval %q", viper.GetString("notify.watch.interval"))
+		return
+	}
+
+	var email *notify.EmailConfig
+	if cfg := emailConfig(); cfg.Host != "" && cfg.From != "" {
+		email = &cfg
+	}
+	n := notify.NewIssueNotifier(s, email)
+	n.SetWebhookSecret(viper.GetString("notify.webhook.secret"))
+	check := func() {
+		if err := n.Check(ctx); err != nil {
+			ui.Warning("Issue watcher notifications: %v", err)
+		}
+	}
+
+	go func() {
+		check()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				check()
+			}
+		}
+	}()
+}
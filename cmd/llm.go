@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/viper"
 
 	"github.com/joescharf/pm/internal/llm"
+	"github.com/joescharf/pm/internal/llmbudget"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/redact"
 )
 
 // newLLMClient creates an LLM client from config/env, or returns nil if no API key is configured.
@@ -17,5 +22,51 @@ func newLLMClient() *llm.Client {
 	if apiKey == "" {
 		return nil
 	}
-	return llm.NewClient(apiKey, viper.GetString("anthropic.model"))
+	client := llm.NewClient(apiKey, viper.GetString("anthropic.model"))
+	policy, err := redactionPolicy()
+	if err != nil {
+		ui.Warning("%v; using the default redaction rules", err)
+		policy = redact.Default()
+	}
+	client.SetRedaction(policy)
+	return client
+}
+
+// redactionPolicy reads the redaction settings applied to text sent to the LLM.
+func redactionPolicy() (*redact.Policy, error) {
+	var cfg redact.Config
+	if err := viper.UnmarshalKey("redaction", &cfg); err != nil {
+		return nil, fmt.Errorf("read redaction: %w", err)
+	}
+	return redact.NewPolicy(cfg)
+}
+
+// llmBudget reads the monthly LLM spend caps of projects.
+func llmBudget() (*llmbudget.Budget, error) {
+	cfg, err := llmBudgetConfig(viper.GetViper())
+	if err != nil {
+		return nil, err
+	}
+	return llmbudget.New(cfg)
+}
+
+// llmBudgetConfig reads the llm.budget section of v.
+func llmBudgetConfig(v *viper.Viper) (llmbudget.Config, error) {
+	var cfg llmbudget.Config
+	if err := v.UnmarshalKey("llm.budget", &cfg); err != nil {
+		return cfg, fmt.Errorf("read llm.budget: %w", err)
+	}
+	return cfg, nil
+}
+
+// reportRedactions tells the user what was withheld from the LLM.
+func reportRedactions(r []models.Redaction) {
+	if len(r) == 0 {
+		return
+	}
+	parts := make([]string, len(r))
+	for i, red := range r {
+		parts[i] = fmt.Sprintf("%s x%d", red.Rule, red.Count)
+	}
+	ui.Info("Redacted before sending to the LLM: %s", strings.Join(parts, ", "))
 }
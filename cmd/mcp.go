@@ -6,14 +6,25 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
+	"github.com/joescharf/pm/internal/agent"
+	"github.com/joescharf/pm/internal/auth"
+	"github.com/joescharf/pm/internal/capabilities"
 	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/llmbudget"
 	pmcp "github.com/joescharf/pm/internal/mcp"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/sessions"
+	"github.com/joescharf/pm/internal/store"
 	"github.com/joescharf/pm/internal/wt"
 )
 
+var mcpScope string
+
 var mcpCmd = &cobra.Command{
 	Use:   "mcp",
 	Short: "MCP server for Claude Code integration",
@@ -32,6 +43,11 @@ Or manually add to ~/.claude.json:
     }
   }
 
+With --scope cwd, the server is bound to the project of the current
+directory: tools take no project argument and refuse other projects'
+issues and sessions. pm mcp install --scope cwd writes that setup to the
+project's .mcp.json.
+
 Available tools: pm_list_projects, pm_project_status, pm_list_issues,
 pm_create_issue, pm_update_issue, pm_launch_agent, pm_health_score`,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -50,7 +66,9 @@ var mcpServeCmd = &cobra.Command{
 var mcpInstallCmd = &cobra.Command{
 	Use:   "install",
 	Short: "Install pm as an MCP server in Claude Code",
-	Long:  "Write the MCP server configuration to ~/.claude.json so Claude Code can use pm tools.",
+	Long: `Write the MCP server configuration to ~/.claude.json so Claude Code can use pm tools.
+With --scope cwd, write a server bound to the current project to the
+project's .mcp.json instead.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return mcpInstallRun()
 	},
@@ -65,6 +83,9 @@ var mcpStatusCmd = &cobra.Command{
 }
 
 func init() {
+	mcpCmd.PersistentFlags().String("token", "", "project-scoped API token limiting tools to one project (or PM_MCP_TOKEN)")
+	_ = viper.BindPFlag("mcp_token", mcpCmd.PersistentFlags().Lookup("token"))
+	mcpCmd.PersistentFlags().StringVar(&mcpScope, "scope", "", "cwd to bind the server to the project of the current directory")
 	mcpCmd.AddCommand(mcpServeCmd)
 	mcpCmd.AddCommand(mcpInstallCmd)
 	mcpCmd.AddCommand(mcpStatusCmd)
@@ -76,13 +97,75 @@ func mcpServeRun() error {
 	if err != nil {
 		return err
 	}
+	ctx := context.Background()
 
 	gc := git.NewClient()
-	ghc := git.NewGitHubClient()
+	ghc := newGitHubClient()
 	wtc := wt.NewClient()
 
-	srv := pmcp.NewServer(s, gc, ghc, wtc, newLLMClient())
-	return srv.ServeStdio(context.Background())
+	project, err := mcpScopeProject(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	llmClient := newLLMClient()
+	budget, err := llmBudget()
+	if err != nil {
+		return err
+	}
+	opts := mcpServerOptions(agent.NewHeartbeats(heartbeatInterval()), sessions.NewOperations(), detectCapabilities(llmClient != nil), budget)
+	if token := viper.GetString("mcp_token"); token != "" {
+		sc, err := auth.Authenticate(ctx, s, token)
+		if err != nil {
+			return err
+		}
+		if project != nil && !sc.Allows(project.ID) {
+			return fmt.Errorf("API token is not scoped to project %s", project.Name)
+		}
+		opts = append(opts, pmcp.WithScope(sc))
+	}
+	if project != nil {
+		opts = append(opts, pmcp.WithProject(project))
+	}
+
+	srv := pmcp.NewServer(s, gc, ghc, wtc, llmClient, opts...)
+	return srv.ServeStdio(ctx)
+}
+
+// mcpScopeProject returns the project --scope binds the server to, or nil
+// for a server over all projects.
+func mcpScopeProject(ctx context.Context, s store.Store) (*models.Project, error) {
+	switch mcpScope {
+	case "":
+		return nil, nil
+	case "cwd":
+		p, err := resolveProjectFromCwd(ctx, s)
+		if err != nil {
+			return nil, fmt.Errorf("--scope cwd: %w", err)
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("invalid --scope %q (use cwd)", mcpScope)
+	}
+}
+
+// mcpServerOptions builds MCP server options from config. Tool calls beat
+// sessions through hb and track their syncs and merges in ops; tools needing
+// a capability missing from caps are left out, and LLM calls are capped by
+// budget.
+func mcpServerOptions(hb *agent.Heartbeats, ops *sessions.Operations, caps *capabilities.Capabilities, budget *llmbudget.Budget) []pmcp.Option {
+	return []pmcp.Option{
+		pmcp.WithReviewRelaunch(viper.GetBool("review.auto_relaunch"), viper.GetInt("review.max_attempts")),
+		pmcp.WithReviewPreAnalysis(viper.GetBool("review.pre_analysis")),
+		pmcp.WithBlockedReminder(blockedRemindAfter()),
+		pmcp.WithIssueLockExpiry(issueLockExpiry()),
+		pmcp.WithAutoClose(autoCloseConfig()),
+		pmcp.WithHeartbeats(hb),
+		pmcp.WithOperations(ops),
+		pmcp.WithCapabilities(caps),
+		pmcp.WithLLMBudget(budget),
+		pmcp.WithReleaseSchedule(releaseSchedule()),
+	}
 }
 
 func mcpInstallRun() error {
@@ -92,6 +175,21 @@ func mcpInstallRun() error {
 	}
 
 	claudeJSON := filepath.Join(home, ".claude.json")
+	args := []string{"mcp"}
+	if mcpScope != "" {
+		s, err := getStore()
+		if err != nil {
+			return err
+		}
+		p, err := mcpScopeProject(context.Background(), s)
+		if err != nil {
+			return err
+		}
+		// A bound server belongs in the project's own config, which Claude
+		// Code reads when started there.
+		claudeJSON = filepath.Join(p.Path, ".mcp.json")
+		args = append(args, "--scope", mcpScope)
+	}
 
 	// Get the full path to the current executable
 	exePath, err := os.Executable()
@@ -118,7 +216,7 @@ func mcpInstallRun() error {
 	}
 	servers["pm"] = map[string]any{
 		"command": exePath,
-		"args":    []string{"mcp"},
+		"args":    args,
 	}
 	config["mcpServers"] = servers
 
@@ -138,7 +236,7 @@ func mcpInstallRun() error {
 	}
 
 	ui.Success("Installed pm MCP server in %s", claudeJSON)
-	ui.Info("  Command: %s mcp", exePath)
+	ui.Info("  Command: %s %s", exePath, strings.Join(args, " "))
 	ui.Info("  Restart Claude Code to pick up the change.")
 	return nil
 }
@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/store"
+)
+
+var (
+	noteProject string
+	noteTitle   string
+	noteBody    string
+	noteFile    string
+)
+
+var noteCmd = &cobra.Command{
+	Use:     "note",
+	Aliases: []string{"notes"},
+	Short:   "Manage project notes (the project wiki)",
+	Long: `Keep free-form Markdown notes on a project, such as architecture decisions
+and runbooks. Notes are stored in the pm database, served at
+/api/v1/projects/{id}/notes and exposed to agents as MCP resources
+(pm://projects/<project>/notes).
+
+Commands work on the project in the current directory unless --project is
+given. A note is named by its title, ID or unique ID prefix.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return noteListRun("")
+	},
+}
+
+var noteListCmd = &cobra.Command{
+	Use:     "list [project]",
+	Aliases: []string{"ls"},
+	Short:   "List a project's notes",
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return noteListRun(argOrEmpty(args))
+	},
+}
+
+var noteAddCmd = &cobra.Command{
+	Use:   "add <title>",
+	Short: "Add a note",
+	Long: `Add a note. The Markdown body comes from --body, from --file (- for stdin),
+or from $EDITOR when neither is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return noteAddRun(args[0])
+	},
+}
+
+var noteShowCmd = &cobra.Command{
+	Use:   "show <note>",
+	Short: "Print a note as Markdown",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return noteShowRun(args[0])
+	},
+}
+
+var noteEditCmd = &cobra.Command{
+	Use:   "edit <note>",
+	Short: "Edit a note",
+	Long: `Edit a note's title (--title) or body (--body, or --file with - for stdin).
+Without any of them the body is opened in $EDITOR.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return noteEditRun(cmd, args[0])
+	},
+}
+
+var noteRemoveCmd = &cobra.Command{
+	Use:     "remove <note>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a note",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return noteRemoveRun(args[0])
+	},
+}
+
+func init() {
+	noteCmd.PersistentFlags().StringVarP(&noteProject, "project", "P", "", "Project name (default: the project in the current directory)")
+	for _, c := range []*cobra.Command{noteAddCmd, noteEditCmd} {
+		c.Flags().StringVar(&noteBody, "body", "", "Markdown body")
+		c.Flags().StringVar(&noteFile, "file", "", "Read the Markdown body from a file (- for stdin)")
+		c.MarkFlagsMutuallyExclusive("body", "file")
+	}
+	noteEditCmd.Flags().StringVar(&noteTitle, "title", "", "New title")
+
+	noteCmd.AddCommand(noteListCmd)
+	noteCmd.AddCommand(noteAddCmd)
+	noteCmd.AddCommand(noteShowCmd)
+	noteCmd.AddCommand(noteEditCmd)
+	noteCmd.AddCommand(noteRemoveCmd)
+	rootCmd.AddCommand(noteCmd)
+}
+
+func noteListRun(ref string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	if ref == "" {
+		ref = noteProject
+	}
+	p, err := resolveProjectOrCwd(ctx, s, ref)
+	if err != nil {
+		return err
+	}
+	notes, err := s.ListProjectNotes(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	if len(notes) == 0 {
+		ui.Info("No notes for %s. Use 'pm note add <title>' to write one.", p.Name)
+		return nil
+	}
+
+	table := ui.Table([]string{"ID", "Title", "Lines", "Updated"})
+	for _, n := range notes {
+		_ = table.Append([]string{
+			shortID(n.ID),
+			n.Title,
+			fmt.Sprintf("%d", lineCount(n.Body)),
+			timeAgo(n.UpdatedAt),
+		})
+	}
+	_ = table.Render()
+	return nil
+}
+
+func noteAddRun(title string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	p, err := resolveProjectOrCwd(ctx, s, noteProject)
+	if err != nil {
+		return err
+	}
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return fmt.Errorf("note title is required")
+	}
+
+	if dryRun {
+		ui.DryRunMsg("Would add note %q to %s", title, p.Name)
+		return nil
+	}
+
+	body, err := noteBodyInput(noteBody, noteFile, "")
+	if err != nil {
+		return err
+	}
+	note := &models.ProjectNote{ProjectID: p.ID, Title: title, Body: body}
+	if err := s.CreateProjectNote(ctx, note); err != nil {
+		return err
+	}
+	ui.Success("Added note %s to %s: %s", output.Cyan(shortID(note.ID)), output.Cyan(p.Name), note.Title)
+	ui.Result(note.ID)
+	return nil
+}
+
+func noteShowRun(ref string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	_, note, err := resolveNote(ctx, s, ref)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(ui.Out, "# %s\n\n%s\n", note.Title, strings.TrimRight(note.Body, "\n"))
+	return nil
+}
+
+func noteEditRun(cmd *cobra.Command, ref string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	_, note, err := resolveNote(ctx, s, ref)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	if dryRun {
+		ui.DryRunMsg("Would edit note %s (%s)", shortID(note.ID), note.Title)
+		return nil
+	}
+
+	if flags.Changed("title") {
+		title := strings.TrimSpace(noteTitle)
+		if title == "" {
+			return fmt.Errorf("note title cannot be empty")
+		}
+		note.Title = title
+	}
+	// Only open the editor when nothing else was asked for
+	if flags.Changed("body") || flags.Changed("file") || !flags.Changed("title") {
+		body, err := noteBodyInput(noteBody, noteFile, note.Body)
+		if err != nil {
+			return err
+		}
+		note.Body = body
+	}
+
+	if err := s.UpdateProjectNote(ctx, note); err != nil {
+		return err
+	}
+	ui.Success("Updated note %s: %s", output.Cyan(shortID(note.ID)), note.Title)
+	return nil
+}
+
+func noteRemoveRun(ref string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	_, note, err := resolveNote(ctx, s, ref)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		ui.DryRunMsg("Would remove note %s (%s)", shortID(note.ID), note.Title)
+		return nil
+	}
+	if err := s.DeleteProjectNote(ctx, note.ID); err != nil {
+		return err
+	}
+	ui.Success("Removed note %s: %s", shortID(note.ID), note.Title)
+	return nil
+}
+
+// resolveNote finds a note of the --project (or current directory) project by
+// title, ID or unique ID prefix.
+func resolveNote(ctx context.Context, s store.Store, ref string) (*models.Project, *models.ProjectNote, error) {
+	p, err := resolveProjectOrCwd(ctx, s, noteProject)
+	if err != nil {
+		return nil, nil, err
+	}
+	note, err := store.FindProjectNote(ctx, s, p.ID, ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, note, nil
+}
+
+// noteBodyInput returns the note body from --body, --file (- for stdin) or,
+// when neither is set, from $EDITOR starting with current.
+func noteBodyInput(body, file, current string) (string, error) {
+	switch {
+	case body != "":
+		return body, nil
+	case file == "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("read stdin: %w", err)
+		}
+		return string(data), nil
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	return editText(current)
+}
+
+// editText opens text in $EDITOR and returns the saved result.
+func editText(text string) (string, error) {
+	editor, err := editorFromEnv()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp("", "pm-note-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	if _, err := f.WriteString(text); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	editCmd := exec.Command(editor, f.Name())
+	editCmd.Stdin = os.Stdin
+	editCmd.Stdout = os.Stdout
+	editCmd.Stderr = os.Stderr
+	if err := editCmd.Run(); err != nil {
+		return "", fmt.Errorf("run editor: %w", err)
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// lineCount counts the lines of a note body.
+func lineCount(s string) int {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return 0
+	}
+	return strings.Count(s, "\n") + 1
+}
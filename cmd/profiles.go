@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/federation"
+	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// openProfiles returns the current database, as the profile_name profile,
+// followed by the databases and servers configured under profiles. Close
+// them with federation.CloseAll.
+func openProfiles(s store.Store) ([]*federation.Profile, error) {
+	others, err := configuredProfiles()
+	if err != nil {
+		return nil, err
+	}
+	current := federation.Local(viper.GetString("profile_name"), viper.GetString("db_path"), s)
+	return append([]*federation.Profile{current}, others...), nil
+}
+
+// configuredProfiles opens the profiles configured under profiles, leaving
+// out the current database, so one list of profiles can be shared by
+// machines or configs that each set their own db_path and profile_name.
+func configuredProfiles() ([]*federation.Profile, error) {
+	var cfgs []federation.Config
+	if err := viper.UnmarshalKey("profiles", &cfgs); err != nil {
+		return nil, fmt.Errorf("read profiles: %w", err)
+	}
+	name, dbPath := viper.GetString("profile_name"), viper.GetString("db_path")
+	for _, c := range cfgs {
+		if c.Name == name && filepath.Clean(c.DBPath) != filepath.Clean(dbPath) {
+			return nil, fmt.Errorf("profile %s: name is used by the current database (profile_name)", c.Name)
+		}
+	}
+	return federation.Open(cfgs, dbPath)
+}
+
+// statusAllProfilesRun shows the projects of every profile, namespaced by
+// profile. Git state is not shown: other profiles' repos may be on other
+// machines.
+func statusAllProfilesRun() error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	profiles, err := openProfiles(s)
+	if err != nil {
+		return err
+	}
+	defer federation.CloseAll(profiles[1:]) // the current database stays open
+
+	view := federation.Combine(context.Background(), profiles)
+
+	table := ui.Table([]string{"Project", "Issues", "Done", "Sessions", "Activity"})
+	for _, p := range view.Projects {
+		if statusGroup != "" && p.GroupName != statusGroup {
+			continue
+		}
+		if statusStale && p.LastActivityAt != nil && time.Since(*p.LastActivityAt) < 7*24*time.Hour {
+			continue
+		}
+		activity := "n/a"
+		if p.LastActivityAt != nil {
+			activity = timeAgo(*p.LastActivityAt)
+		}
+		_ = table.Append([]string{
+			output.Cyan(p.Name),
+			fmt.Sprintf("%d/%d", p.OpenIssues, p.InProgress),
+			fmt.Sprint(p.DoneIssues),
+			fmt.Sprint(p.OpenSessions),
+			activity,
+		})
+	}
+	_ = table.Render()
+
+	for _, p := range view.Profiles {
+		if p.Error != "" {
+			ui.Warning("Profile %s (%s): %s", p.Name, p.Location, p.Error)
+		}
+	}
+	return nil
+}
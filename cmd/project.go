@@ -5,22 +5,59 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
+	"github.com/joescharf/pm/internal/agent"
+	"github.com/joescharf/pm/internal/commitmsg"
 	"github.com/joescharf/pm/internal/git"
 	"github.com/joescharf/pm/internal/golang"
+	"github.com/joescharf/pm/internal/langstats"
 	"github.com/joescharf/pm/internal/models"
 	"github.com/joescharf/pm/internal/output"
 	"github.com/joescharf/pm/internal/refresh"
+	"github.com/joescharf/pm/internal/sessions"
 	"github.com/joescharf/pm/internal/store"
+	"github.com/joescharf/pm/internal/uireview"
 )
 
 var (
 	projectGroup string
 	projectName  string
+
+	wipMaxIssues   int
+	wipMaxSessions int
+	wipHardBlock   bool
+
+	commitStyle        string
+	commitTicketPrefix string
+
+	refreshDisable []string
+	refreshEnable  []string
+
+	sparseSet   []string
+	sparseClear bool
+
+	uiReviewSet   []string
+	uiReviewClear bool
+
+	mergeStrategySet string
+
+	cascadeSet   string
+	cascadeReset bool
+
+	boardSet   string
+	boardClear bool
+
+	projectKey string
+	keySet     string
+
+	listArchived   bool
+	archiveRestore bool
 )
 
 var projectCmd = &cobra.Command{
@@ -73,8 +110,9 @@ var projectShowCmd = &cobra.Command{
 var projectRefreshCmd = &cobra.Command{
 	Use:   "refresh [name]",
 	Short: "Refresh project metadata",
-	Long:  "Re-detect language, remote URL, and fetch GitHub description for one or all projects.",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Re-detect language, remote URL, and fetch GitHub description for one or all projects.
+Without a name, all projects (or those in --group) are refreshed concurrently.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) > 0 {
 			return projectRefreshOneRun(args[0])
@@ -92,18 +130,196 @@ var projectScanCmd = &cobra.Command{
 	},
 }
 
+var projectWIPCmd = &cobra.Command{
+	Use:   "wip <name>",
+	Short: "Show or set a project's WIP limits",
+	Long: `Show a project's work in progress against its WIP limits, or set them.
+Limits of 0 mean no limit. Launches that exceed a limit warn, or fail with --hard-block.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return projectWIPRun(cmd, args[0])
+	},
+}
+
+var projectCommitsCmd = &cobra.Command{
+	Use:   "commits <name>",
+	Short: "Show or set a project's commit message conventions",
+	Long: `Show a project's commit message conventions, or set them.
+
+--style conventional requires Conventional Commits subjects such as
+"feat(api): add login"; --ticket-prefix PROJ- requires each message to
+reference a ticket like PROJ-123. Pass "none" to clear either. Close-check
+and merge refuse sessions whose commits break the conventions unless forced.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return projectCommitsRun(cmd, args[0])
+	},
+}
+
+var projectSparseCmd = &cobra.Command{
+	Use:   "sparse <name>",
+	Short: "Show or set the paths of a project's sparse-checkout worktrees",
+	Long: `Show or set the directories agent worktrees of a huge repo check out.
+
+With sparse paths set, 'pm agent launch' creates worktrees with a cone-mode
+sparse-checkout of these directories plus any the issue mentions, instead of
+the whole repo. Files at the repo root are always checked out. If git cannot
+set up the sparse-checkout, the worktree falls back to a full checkout.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return projectSparseRun(cmd, args[0])
+	},
+}
+
+var projectUIReviewCmd = &cobra.Command{
+	Use:   "ui-review <name>",
+	Short: "Show or set which changed files make a review check the UI",
+	Long: `Show or set the glob rules pm_prepare_review matches a change's files
+against to tell whether the review needs to look at the UI.
+
+A rule ending in / names a directory. Like .gitignore patterns, a rule with a
+/ elsewhere is matched from the project directory (internal/ui/,
+docs/*.html) and one without at any depth (templates/, *.tsx). Without rules
+of its own a project uses defaults for its languages: ui/, web/, templates/,
+*.css and the like for every project, plus e.g. *.tsx and components/ for
+TypeScript or *.templ for Go.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return projectUIReviewRun(cmd, args[0])
+	},
+}
+
+var projectMergeStrategyCmd = &cobra.Command{
+	Use:   "merge-strategy <name>",
+	Short: "Show or set how a project's session branches are merged",
+	Long: `Show or set the default strategy 'pm agent merge' uses for a project's
+session branches: merge (a merge commit, the default), rebase (rebase, then
+fast-forward), squash (one commit whose message lists the branch's issues and
+commits) or ff-only (fast-forward only; branches behind the base are refused).
+A merge's --strategy overrides it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return projectMergeStrategyRun(cmd, args[0])
+	},
+}
+
+var projectCascadeCmd = &cobra.Command{
+	Use:   "cascade <name>",
+	Short: "Show or set how session and review events move a project's issues",
+	Long: `Show or set the status each event moves its issue to. Events:
+
+  completed    a session completed, by close or merge (default: done)
+  abandoned    a session was abandoned or its worktree deleted (default: open)
+  review_pass  a review of the issue passed (default: closed)
+  review_fail  a review of the issue failed (default: in_progress)
+
+--set takes comma-separated event=status rules, e.g. completed=in_review to
+hold finished work for review; a status of none leaves the issue alone.
+Events not named keep their current rule. --reset goes back to the defaults.
+Session events only move issues that are in progress.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return projectCascadeRun(cmd, args[0])
+	},
+}
+
+var projectBoardCmd = &cobra.Command{
+	Use:   "board <name>",
+	Short: "Show or set the GitHub Projects board a project's issues sync with",
+	Long: `Show or set the GitHub Projects (v2) board the status and priority of the
+project's issues linked to GitHub issues are synced with, as owner/number
+(the user or organization owning the board and its number in the board's
+URL). pm issue board-sync syncs it; --clear stops syncing.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return projectBoardRun(cmd, args[0])
+	},
+}
+
+var projectKeyCmd = &cobra.Command{
+	Use:   "key <name>",
+	Short: "Show or change the key prefixing a project's short codes",
+	Long: `Show or change a project's key, the prefix of the short codes its issues
+(KEY-12) and agent sessions (KEY-S3) are shown and looked up by. Changing it
+renames the codes of the project's existing issues and sessions, keeping
+their numbers.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return projectKeyRun(cmd, args[0])
+	},
+}
+
+var projectRefreshStepsCmd = &cobra.Command{
+	Use:   "refresh-steps <name>",
+	Short: "Show or choose the refresh steps run for a project",
+	Long: `Show the refresh pipeline steps and whether each runs for a project, or
+turn steps off (--disable) and back on (--enable) for it.
+
+Steps: language, git, github, conventions, deps, todos.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return projectRefreshStepsRun(args[0])
+	},
+}
+
+var projectArchiveCmd = &cobra.Command{
+	Use:   "archive <name>...",
+	Short: "Archive projects",
+	Long: `Archive projects so they are hidden from project lists, status and refresh-all.
+Archived projects keep their issues and history; use --restore to bring them back.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return projectArchiveRun(args)
+	},
+}
+
 func init() {
 	projectAddCmd.Flags().StringVar(&projectName, "name", "", "Override project name (default: directory name)")
 	projectAddCmd.Flags().StringVar(&projectGroup, "group", "", "Project group name")
+	projectAddCmd.Flags().StringVar(&projectKey, "key", "", "Prefix of the project's issue and session short codes (default: derived from the name)")
 
 	projectListCmd.Flags().StringVar(&projectGroup, "group", "", "Filter by group")
+	projectListCmd.Flags().BoolVar(&listArchived, "archived", false, "List archived projects instead")
+
+	projectRefreshCmd.Flags().StringVar(&projectGroup, "group", "", "Only refresh projects in this group")
+
+	projectArchiveCmd.Flags().BoolVar(&archiveRestore, "restore", false, "Unarchive the projects")
+
+	projectWIPCmd.Flags().IntVar(&wipMaxIssues, "max-issues", 0, "Max in-progress issues (0 = no limit)")
+	projectWIPCmd.Flags().IntVar(&wipMaxSessions, "max-sessions", 0, "Max active/idle agent sessions (0 = no limit)")
+	projectWIPCmd.Flags().BoolVar(&wipHardBlock, "hard-block", false, "Refuse launches that would exceed a limit")
 
 	projectCmd.AddCommand(projectAddCmd)
 	projectCmd.AddCommand(projectRemoveCmd)
 	projectCmd.AddCommand(projectListCmd)
 	projectCmd.AddCommand(projectShowCmd)
 	projectCmd.AddCommand(projectRefreshCmd)
+	projectCmd.AddCommand(projectRefreshStepsCmd)
 	projectCmd.AddCommand(projectScanCmd)
+	projectRefreshStepsCmd.Flags().StringSliceVar(&refreshDisable, "disable", nil, "Steps to stop running for this project")
+	projectRefreshStepsCmd.Flags().StringSliceVar(&refreshEnable, "enable", nil, "Steps to run again for this project")
+	projectCommitsCmd.Flags().StringVar(&commitStyle, "style", "", "Commit style: conventional, or none")
+	projectCommitsCmd.Flags().StringVar(&commitTicketPrefix, "ticket-prefix", "", "Required ticket reference prefix, e.g. PROJ- (none to clear)")
+	projectSparseCmd.Flags().StringSliceVar(&sparseSet, "set", nil, "Directories to check out, comma-separated")
+	projectSparseCmd.Flags().BoolVar(&sparseClear, "clear", false, "Go back to full-checkout worktrees")
+	projectUIReviewCmd.Flags().StringSliceVar(&uiReviewSet, "set", nil, "Glob rules, comma-separated, e.g. '*.tsx,templates/'")
+	projectUIReviewCmd.Flags().BoolVar(&uiReviewClear, "clear", false, "Go back to the defaults for the project's languages")
+	projectMergeStrategyCmd.Flags().StringVar(&mergeStrategySet, "set", "", "Strategy: merge, rebase, squash or ff-only")
+	projectCascadeCmd.Flags().StringVar(&cascadeSet, "set", "", "Rules to change, e.g. completed=in_review,abandoned=none")
+	projectCascadeCmd.Flags().BoolVar(&cascadeReset, "reset", false, "Go back to the default rules")
+	projectBoardCmd.Flags().StringVar(&boardSet, "set", "", "Board as owner/number, e.g. acme/3")
+	projectBoardCmd.Flags().BoolVar(&boardClear, "clear", false, "Stop syncing with a board")
+	projectKeyCmd.Flags().StringVar(&keySet, "set", "", "New key, e.g. WEB")
+
+	projectCmd.AddCommand(projectWIPCmd)
+	projectCmd.AddCommand(projectCommitsCmd)
+	projectCmd.AddCommand(projectSparseCmd)
+	projectCmd.AddCommand(projectUIReviewCmd)
+	projectCmd.AddCommand(projectMergeStrategyCmd)
+	projectCmd.AddCommand(projectCascadeCmd)
+	projectCmd.AddCommand(projectBoardCmd)
+	projectCmd.AddCommand(projectKeyCmd)
+	projectCmd.AddCommand(projectArchiveCmd)
 	rootCmd.AddCommand(projectCmd)
 }
 
@@ -136,7 +352,7 @@ func projectAddRun(rawPath string) error {
 
 	// Try to get remote URL
 	gc := git.NewClient()
-	remoteURL, _ := gc.RemoteURL(absPath)
+	remoteURL, _ := gc.RemoteURL(context.Background(), absPath)
 
 	p := &models.Project{
 		Name:      name,
@@ -144,6 +360,12 @@ func projectAddRun(rawPath string) error {
 		Language:  lang,
 		RepoURL:   remoteURL,
 		GroupName: projectGroup,
+		Key:       strings.ToUpper(projectKey),
+	}
+	if p.Key != "" {
+		if err := models.ValidateProjectKey(p.Key); err != nil {
+			return err
+		}
 	}
 
 	if dryRun {
@@ -155,7 +377,8 @@ func projectAddRun(rawPath string) error {
 		return fmt.Errorf("add project: %w", err)
 	}
 
-	ui.Success("Added project: %s (%s)", output.Cyan(name), absPath)
+	ui.Success("Added project: %s (%s), key %s", output.Cyan(name), absPath, output.Cyan(p.Key))
+	ui.Result(p.ID)
 	if lang != "" {
 		ui.VerboseLog("Language: %s", lang)
 	}
@@ -197,12 +420,21 @@ func projectListRun() error {
 	}
 	ctx := context.Background()
 
-	projects, err := s.ListProjects(ctx, projectGroup)
+	var projects []*models.Project
+	if listArchived {
+		projects, err = s.ListArchivedProjects(ctx)
+	} else {
+		projects, err = s.ListProjects(ctx, projectGroup)
+	}
 	if err != nil {
 		return err
 	}
 
 	if len(projects) == 0 {
+		if listArchived {
+			ui.Info("No archived projects.")
+			return nil
+		}
 		ui.Info("No projects tracked. Use 'pm project add <path>' to get started.")
 		return nil
 	}
@@ -242,6 +474,9 @@ func projectShowRun(name string) error {
 	// Header
 	fmt.Fprintf(ui.Out, "%s\n", output.Cyan(p.Name))
 	fmt.Fprintf(ui.Out, "  Path:       %s\n", p.Path)
+	if p.Key != "" {
+		fmt.Fprintf(ui.Out, "  Key:        %s\n", p.Key)
+	}
 	if p.Description != "" {
 		fmt.Fprintf(ui.Out, "  Desc:       %s\n", p.Description)
 	}
@@ -251,6 +486,9 @@ func projectShowRun(name string) error {
 	if p.Language != "" {
 		fmt.Fprintf(ui.Out, "  Language:   %s\n", p.Language)
 	}
+	if len(p.Languages) > 0 {
+		fmt.Fprintf(ui.Out, "  Languages:  %s\n", langstats.Summary(p.Languages))
+	}
 	if p.RepoURL != "" {
 		fmt.Fprintf(ui.Out, "  Remote:     %s\n", p.RepoURL)
 	}
@@ -260,29 +498,46 @@ func projectShowRun(name string) error {
 	if p.HasGitHubPages {
 		fmt.Fprintf(ui.Out, "  Pages:      %s\n", p.PagesURL)
 	}
+	if p.SparsePaths != "" {
+		fmt.Fprintf(ui.Out, "  Sparse:     %s\n", strings.ReplaceAll(p.SparsePaths, ",", ", "))
+	}
+	if p.GitHubBoard != "" {
+		fmt.Fprintf(ui.Out, "  Board:      %s\n", p.GitHubBoard)
+	}
+	if p.UIReviewPaths != "" {
+		fmt.Fprintf(ui.Out, "  UI review:  %s\n", strings.ReplaceAll(p.UIReviewPaths, ",", ", "))
+	}
+	if g, err := loadGraph(ctx, s); err == nil {
+		if up := projectNames(g.Upstream(p.ID)); up != "" {
+			fmt.Fprintf(ui.Out, "  Depends on: %s\n", up)
+		}
+		if down := projectNames(g.Downstream(p.ID)); down != "" {
+			fmt.Fprintf(ui.Out, "  Used by:    %s\n", down)
+		}
+	}
 	fmt.Fprintln(ui.Out)
 
 	// Git info
-	if branch, err := gc.CurrentBranch(p.Path); err == nil {
+	if branch, err := gc.CurrentBranch(ctx, p.Path); err == nil {
 		fmt.Fprintf(ui.Out, "  Branch:     %s\n", branch)
 	}
-	if dirty, err := gc.IsDirty(p.Path); err == nil {
+	if dirty, err := gc.IsDirty(ctx, p.Path); err == nil {
 		status := output.Green("clean")
 		if dirty {
 			status = output.Red("dirty")
 		}
 		fmt.Fprintf(ui.Out, "  Status:     %s\n", status)
 	}
-	if hash, err := gc.LastCommitHash(p.Path); err == nil {
-		msg, _ := gc.LastCommitMessage(p.Path)
+	if hash, err := gc.LastCommitHash(ctx, p.Path); err == nil {
+		msg, _ := gc.LastCommitMessage(ctx, p.Path)
 		fmt.Fprintf(ui.Out, "  Last commit: %s %s\n", hash, msg)
 	}
-	if date, err := gc.LastCommitDate(p.Path); err == nil {
+	if date, err := gc.LastCommitDate(ctx, p.Path); err == nil {
 		fmt.Fprintf(ui.Out, "  Activity:   %s\n", timeAgo(date))
 	}
 
 	// Worktrees
-	if wts, err := gc.WorktreeList(p.Path); err == nil && len(wts) > 1 {
+	if wts, err := gc.WorktreeList(ctx, p.Path); err == nil && len(wts) > 1 {
 		fmt.Fprintf(ui.Out, "  Worktrees:  %d\n", len(wts)-1) // exclude main
 	}
 
@@ -299,21 +554,27 @@ func projectShowRun(name string) error {
 	// Issue counts
 	issues, err := s.ListIssues(ctx, store.IssueListFilter{ProjectID: p.ID})
 	if err == nil && len(issues) > 0 {
-		open, inProg := 0, 0
+		open, inProg, inReview := 0, 0, 0
 		for _, i := range issues {
 			switch i.Status {
 			case models.IssueStatusOpen:
 				open++
 			case models.IssueStatusInProgress:
 				inProg++
+			case models.IssueStatusInReview:
+				inReview++
 			}
 		}
-		fmt.Fprintf(ui.Out, "  Issues:     %d open, %d in-progress\n", open, inProg)
+		if inReview > 0 {
+			fmt.Fprintf(ui.Out, "  Issues:     %d open, %d in-progress, %d in-review\n", open, inProg, inReview)
+		} else {
+			fmt.Fprintf(ui.Out, "  Issues:     %d open, %d in-progress\n", open, inProg)
+		}
 	}
 
 	// Version / Release info
-	ghClient := git.NewGitHubClient()
-	vi := getVersionInfo(gc, ghClient, p)
+	ghClient := newGitHubClient()
+	vi := getVersionInfo(ctx, s, gc, ghClient, p)
 	if vi != nil {
 		fmt.Fprintln(ui.Out)
 		fmt.Fprintf(ui.Out, "  Version:    %s", output.Green(vi.Version))
@@ -326,6 +587,12 @@ func projectShowRun(name string) error {
 		if !vi.Date.IsZero() {
 			fmt.Fprintf(ui.Out, "  Released:   %s\n", timeAgo(vi.Date))
 		}
+		if rel := vi.Release; rel != nil {
+			fmt.Fprintf(ui.Out, "  Fetched:    %s\n", timeAgo(*rel.FetchedAt))
+			if rel.Error != "" {
+				ui.Warning("Last release fetch failed: %s", rel.Error)
+			}
+		}
 		if len(vi.Assets) > 0 {
 			fmt.Fprintf(ui.Out, "  Assets:     %d files\n", len(vi.Assets))
 			for _, a := range vi.Assets {
@@ -338,6 +605,425 @@ func projectShowRun(name string) error {
 	return nil
 }
 
+func projectWIPRun(cmd *cobra.Command, name string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	p, err := resolveProject(ctx, s, name)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("max-issues") || flags.Changed("max-sessions") || flags.Changed("hard-block") {
+		if wipMaxIssues < 0 || wipMaxSessions < 0 {
+			return fmt.Errorf("WIP limits must be >= 0")
+		}
+		if flags.Changed("max-issues") {
+			p.MaxInProgress = wipMaxIssues
+		}
+		if flags.Changed("max-sessions") {
+			p.MaxActiveSessions = wipMaxSessions
+		}
+		if flags.Changed("hard-block") {
+			p.WIPHardBlock = wipHardBlock
+		}
+		if dryRun {
+			ui.DryRunMsg("Would set WIP limits for %s", p.Name)
+		} else {
+			if err := s.UpdateProject(ctx, p); err != nil {
+				return err
+			}
+			ui.Success("Updated WIP limits for %s", output.Cyan(p.Name))
+		}
+	}
+
+	wip, err := agent.CheckWIP(ctx, s, p, 0, 0)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(ui.Out, "%s\n", output.Cyan(p.Name))
+	fmt.Fprintf(ui.Out, "  In progress: %s\n", formatWIPLimit(wip.InProgressIssues, wip.MaxInProgress))
+	fmt.Fprintf(ui.Out, "  Sessions:    %s\n", formatWIPLimit(wip.ActiveSessions, wip.MaxActiveSessions))
+	mode := "warn"
+	if p.WIPHardBlock {
+		mode = "block"
+	}
+	fmt.Fprintf(ui.Out, "  Mode:        %s\n", mode)
+	for _, warn := range wip.Warnings {
+		ui.Warning("WIP limit: %s", warn)
+	}
+	return nil
+}
+
+func projectCommitsRun(cmd *cobra.Command, name string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	p, err := resolveProject(ctx, s, name)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("style") || flags.Changed("ticket-prefix") {
+		if flags.Changed("style") {
+			style := commitStyle
+			if style == "none" {
+				style = ""
+			}
+			if err := commitmsg.ValidateStyle(style); err != nil {
+				return err
+			}
+			p.CommitStyle = style
+		}
+		if flags.Changed("ticket-prefix") {
+			p.CommitTicketPrefix = commitTicketPrefix
+			if p.CommitTicketPrefix == "none" {
+				p.CommitTicketPrefix = ""
+			}
+		}
+		if dryRun {
+			ui.DryRunMsg("Would set commit conventions for %s", p.Name)
+		} else {
+			if err := s.UpdateProject(ctx, p); err != nil {
+				return err
+			}
+			ui.Success("Updated commit conventions for %s", output.Cyan(p.Name))
+		}
+	}
+
+	conv := commitmsg.ForProject(p)
+	fmt.Fprintf(ui.Out, "%s\n", output.Cyan(p.Name))
+	fmt.Fprintf(ui.Out, "  Conventions: %s\n", conv.Describe())
+
+	// Check the commits on the current branch that aren't on main yet
+	if conv.Enabled() {
+		if msgs, err := git.NewClient().CommitMessagesSince(ctx, p.Path, "main"); err == nil && len(msgs) > 0 {
+			if vs := conv.CheckAll(msgs); len(vs) > 0 {
+				ui.Warning("%s", commitmsg.Summarize(vs))
+			} else {
+				fmt.Fprintf(ui.Out, "  Branch:      %d commit(s) ahead of main comply\n", len(msgs))
+			}
+		}
+	}
+	return nil
+}
+
+func projectSparseRun(cmd *cobra.Command, name string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	p, err := resolveProject(ctx, s, name)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("set") || sparseClear {
+		if sparseClear && flags.Changed("set") {
+			return fmt.Errorf("use either --set or --clear")
+		}
+		p.SparsePaths = strings.Join(agent.ParseSparsePaths(strings.Join(sparseSet, ",")), ",")
+		if dryRun {
+			ui.DryRunMsg("Would set sparse paths for %s", p.Name)
+		} else {
+			if err := s.UpdateProject(ctx, p); err != nil {
+				return err
+			}
+			ui.Success("Updated sparse paths for %s", output.Cyan(p.Name))
+		}
+	}
+
+	fmt.Fprintf(ui.Out, "%s\n", output.Cyan(p.Name))
+	paths := agent.ParseSparsePaths(p.SparsePaths)
+	if len(paths) == 0 {
+		fmt.Fprintf(ui.Out, "  Worktrees: full checkout\n")
+		return nil
+	}
+	fmt.Fprintf(ui.Out, "  Worktrees: sparse checkout of %s\n", strings.Join(paths, ", "))
+	return nil
+}
+
+func projectUIReviewRun(cmd *cobra.Command, name string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	p, err := resolveProject(ctx, s, name)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("set") || uiReviewClear {
+		if uiReviewClear && flags.Changed("set") {
+			return fmt.Errorf("use either --set or --clear")
+		}
+		rules := uireview.ParseRules(strings.Join(uiReviewSet, ","))
+		if err := uireview.Validate(rules); err != nil {
+			return err
+		}
+		p.UIReviewPaths = strings.Join(rules, ",")
+		if dryRun {
+			ui.DryRunMsg("Would set UI review rules for %s", p.Name)
+		} else {
+			if err := s.UpdateProject(ctx, p); err != nil {
+				return err
+			}
+			ui.Success("Updated UI review rules for %s", output.Cyan(p.Name))
+		}
+	}
+
+	fmt.Fprintf(ui.Out, "%s\n", output.Cyan(p.Name))
+	source := "project rules"
+	if p.UIReviewPaths == "" {
+		source = "defaults"
+	}
+	fmt.Fprintf(ui.Out, "  UI review: %s (%s)\n", strings.Join(uireview.Rules(p), ", "), source)
+	return nil
+}
+
+func projectMergeStrategyRun(cmd *cobra.Command, name string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	p, err := resolveProject(ctx, s, name)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Flags().Changed("set") {
+		strategy, err := sessions.ParseMergeStrategy(mergeStrategySet)
+		if err != nil {
+			return err
+		}
+		p.MergeStrategy = strategy
+		if dryRun {
+			ui.DryRunMsg("Would set the merge strategy for %s", p.Name)
+		} else {
+			if err := s.UpdateProject(ctx, p); err != nil {
+				return err
+			}
+			ui.Success("Updated the merge strategy for %s", output.Cyan(p.Name))
+		}
+	}
+
+	strategy := p.MergeStrategy
+	if strategy == "" {
+		strategy = models.MergeStrategyMerge
+	}
+	fmt.Fprintf(ui.Out, "%s\n", output.Cyan(p.Name))
+	fmt.Fprintf(ui.Out, "  Merge strategy: %s\n", strategy)
+	return nil
+}
+
+func projectCascadeRun(cmd *cobra.Command, name string) error {
+	if cascadeReset && cmd.Flags().Changed("set") {
+		return fmt.Errorf("--set and --reset are mutually exclusive")
+	}
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	p, err := resolveProject(ctx, s, name)
+	if err != nil {
+		return err
+	}
+
+	if cascadeReset || cmd.Flags().Changed("set") {
+		rules := ""
+		if !cascadeReset {
+			// New rules apply on top of the project's current ones.
+			c, err := models.ParseCascade(p.Cascade + "," + cascadeSet)
+			if err != nil {
+				return err
+			}
+			rules = c.String()
+		}
+		p.Cascade = rules
+		if dryRun {
+			ui.DryRunMsg("Would set the cascade rules for %s", p.Name)
+		} else {
+			if err := s.UpdateProject(ctx, p); err != nil {
+				return err
+			}
+			ui.Success("Updated the cascade rules for %s", output.Cyan(p.Name))
+		}
+	}
+
+	rules := p.CascadeRules()
+	fmt.Fprintf(ui.Out, "%s\n", output.Cyan(p.Name))
+	for _, e := range models.CascadeEvents {
+		to := string(rules.Status(e))
+		if to == "" {
+			to = models.CascadeNone
+		} else {
+			to = output.StatusColor(to)
+		}
+		note := ""
+		if rules.Status(e) != models.DefaultCascade[e] {
+			note = " (overridden)"
+		}
+		fmt.Fprintf(ui.Out, "  %-12s -> %s%s\n", e, to, note)
+	}
+	return nil
+}
+
+func projectBoardRun(cmd *cobra.Command, name string) error {
+	if boardClear && cmd.Flags().Changed("set") {
+		return fmt.Errorf("use either --set or --clear")
+	}
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	p, err := resolveProject(ctx, s, name)
+	if err != nil {
+		return err
+	}
+
+	if boardClear || cmd.Flags().Changed("set") {
+		board := ""
+		if !boardClear {
+			owner, number, err := git.ParseBoardRef(boardSet)
+			if err != nil {
+				return err
+			}
+			board = fmt.Sprintf("%s/%d", owner, number)
+		}
+		p.GitHubBoard = board
+		if dryRun {
+			ui.DryRunMsg("Would set the GitHub board for %s", p.Name)
+		} else {
+			if err := s.UpdateProject(ctx, p); err != nil {
+				return err
+			}
+			ui.Success("Updated the GitHub board for %s", output.Cyan(p.Name))
+		}
+	}
+
+	fmt.Fprintf(ui.Out, "%s\n", output.Cyan(p.Name))
+	if p.GitHubBoard == "" {
+		fmt.Fprintf(ui.Out, "  Board: none\n")
+		return nil
+	}
+	fmt.Fprintf(ui.Out, "  Board: %s\n", p.GitHubBoard)
+	return nil
+}
+
+func projectKeyRun(cmd *cobra.Command, name string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	p, err := resolveProject(ctx, s, name)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Flags().Changed("set") {
+		key := strings.ToUpper(keySet)
+		if err := models.ValidateProjectKey(key); err != nil {
+			return err
+		}
+		if dryRun {
+			ui.DryRunMsg("Would change the key of %s from %s to %s", p.Name, p.Key, key)
+			return nil
+		}
+		if err := s.SetProjectKey(ctx, p.ID, key); err != nil {
+			return err
+		}
+		ui.Success("Changed the key of %s from %s to %s", output.Cyan(p.Name), p.Key, output.Cyan(key))
+		return nil
+	}
+
+	fmt.Fprintf(ui.Out, "%s\n", output.Cyan(p.Name))
+	fmt.Fprintf(ui.Out, "  Key: %s\n", p.Key)
+	return nil
+}
+
+func projectRefreshStepsRun(name string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	p, err := resolveProject(ctx, s, name)
+	if err != nil {
+		return err
+	}
+
+	if len(refreshDisable) > 0 || len(refreshEnable) > 0 {
+		skip := refresh.SkippedSteps(p)
+		skip = append(skip, refreshDisable...)
+		skip = slices.DeleteFunc(skip, func(step string) bool {
+			return slices.Contains(refreshEnable, step)
+		})
+		if _, err := refresh.FormatSkip(refreshEnable); err != nil {
+			return err
+		}
+		value, err := refresh.FormatSkip(skip)
+		if err != nil {
+			return err
+		}
+		p.RefreshSkip = value
+		if dryRun {
+			ui.DryRunMsg("Would set refresh steps for %s", p.Name)
+		} else {
+			if err := s.UpdateProject(ctx, p); err != nil {
+				return err
+			}
+			ui.Success("Updated refresh steps for %s", output.Cyan(p.Name))
+		}
+	}
+
+	skip := refresh.SkippedSteps(p)
+	table := ui.Table([]string{"Step", "Runs", "Description"})
+	for _, step := range refresh.Steps() {
+		runs := output.Green("yes")
+		if slices.Contains(skip, step.Name) {
+			runs = output.Yellow("no")
+		}
+		_ = table.Append([]string{step.Name, runs, step.Description})
+	}
+	_ = table.Render()
+	return nil
+}
+
+// formatWIPLimit renders a count against its limit, e.g. "3/2" in red when over.
+func formatWIPLimit(count, limit int) string {
+	if limit <= 0 {
+		return fmt.Sprintf("%d (no limit)", count)
+	}
+	str := fmt.Sprintf("%d/%d", count, limit)
+	if count > limit {
+		return output.Red(str)
+	}
+	return output.Green(str)
+}
+
 func projectScanRun(dir string) error {
 	s, err := getStore()
 	if err != nil {
@@ -356,7 +1042,7 @@ func projectScanRun(dir string) error {
 
 	gc := git.NewClient()
 	ctx := context.Background()
-	added := 0
+	added, failed := 0, 0
 
 	for _, entry := range entries {
 		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
@@ -366,7 +1052,7 @@ func projectScanRun(dir string) error {
 		entryPath := filepath.Join(absDir, entry.Name())
 
 		// Check if it's a git repo
-		if _, err := gc.RepoRoot(entryPath); err != nil {
+		if _, err := gc.RepoRoot(ctx, entryPath); err != nil {
 			continue
 		}
 
@@ -377,7 +1063,7 @@ func projectScanRun(dir string) error {
 		}
 
 		lang := golang.DetectLanguage(entryPath)
-		remoteURL, _ := gc.RemoteURL(entryPath)
+		remoteURL, _ := gc.RemoteURL(ctx, entryPath)
 
 		p := &models.Project{
 			Name:     entry.Name(),
@@ -394,6 +1080,7 @@ func projectScanRun(dir string) error {
 
 		if err := s.CreateProject(ctx, p); err != nil {
 			ui.Warning("Skipped %s: %v", entry.Name(), err)
+			failed++
 			continue
 		}
 
@@ -406,6 +1093,9 @@ func projectScanRun(dir string) error {
 	} else {
 		ui.Info("Discovered %d project(s)", added)
 	}
+	if failed > 0 {
+		return fmt.Errorf("%d project(s) could not be added", failed)
+	}
 	return nil
 }
 
@@ -422,14 +1112,14 @@ func projectRefreshOneRun(nameOrPath string) error {
 	}
 
 	gc := git.NewClient()
-	ghc := git.NewGitHubClient()
+	ghc := newGitHubClient()
 
 	if dryRun {
 		ui.DryRunMsg("Would refresh project: %s", p.Name)
 		return nil
 	}
 
-	changed, err := refresh.Project(ctx, s, p, gc, ghc)
+	steps, changed, err := refresh.Run(ctx, refresh.Env{Store: s, Git: gc, GitHub: ghc}, p)
 	if err != nil {
 		return fmt.Errorf("refresh %s: %w", p.Name, err)
 	}
@@ -439,9 +1129,47 @@ func projectRefreshOneRun(nameOrPath string) error {
 	} else {
 		ui.Info("No changes for project: %s", p.Name)
 	}
+	printRefreshSteps(steps)
+
+	changes, err := refresh.Snapshot(ctx, s, p, gc, ghc)
+	if err != nil {
+		return fmt.Errorf("health snapshot: %w", err)
+	}
+	printHealthChanges(changes)
 	return nil
 }
 
+// printRefreshSteps lists each pipeline step's outcome and timing.
+func printRefreshSteps(steps []refresh.StepResult) {
+	for _, st := range steps {
+		status := st.Status
+		switch st.Status {
+		case refresh.StepChanged:
+			status = output.Green(st.Status)
+		case refresh.StepFailed:
+			status = output.Red(st.Status)
+		case refresh.StepSkipped:
+			status = output.Yellow(st.Status)
+		}
+		detail := st.Summary
+		if st.Error != "" {
+			detail = st.Error
+		}
+		fmt.Fprintf(ui.Out, "  %-12s %-9s %5dms  %s\n", st.Name, status, st.DurationMS, detail)
+	}
+}
+
+// printHealthChanges lists health deltas since the previous refresh.
+func printHealthChanges(changes []string) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Fprintln(ui.Out, "  Since last refresh:")
+	for _, c := range changes {
+		fmt.Fprintf(ui.Out, "    %s\n", c)
+	}
+}
+
 func projectRefreshAllRun() error {
 	s, err := getStore()
 	if err != nil {
@@ -449,11 +1177,12 @@ func projectRefreshAllRun() error {
 	}
 	ctx := context.Background()
 
+	projects, err := s.ListProjects(ctx, projectGroup)
+	if err != nil {
+		return err
+	}
+
 	if dryRun {
-		projects, err := s.ListProjects(ctx, "")
-		if err != nil {
-			return err
-		}
 		for _, p := range projects {
 			ui.DryRunMsg("Would refresh: %s", p.Name)
 		}
@@ -461,14 +1190,15 @@ func projectRefreshAllRun() error {
 	}
 
 	gc := git.NewClient()
-	ghc := git.NewGitHubClient()
+	ghc := newGitHubClient()
 
-	result, err := refresh.All(ctx, s, gc, ghc)
-	if err != nil {
-		return err
-	}
+	result := refresh.Projects(ctx, s, projects, gc, ghc, refresh.DefaultWorkers)
 
 	if result.Total == 0 {
+		if projectGroup != "" {
+			ui.Info("No projects in group: %s", projectGroup)
+			return nil
+		}
 		ui.Info("No projects tracked.")
 		return nil
 	}
@@ -481,12 +1211,50 @@ func projectRefreshAllRun() error {
 		} else {
 			ui.Info("No changes: %s", r.Name)
 		}
+		for _, st := range r.Steps {
+			if st.Status == refresh.StepFailed {
+				ui.Warning("  %s step failed: %s", st.Name, st.Error)
+			}
+		}
+		printHealthChanges(r.Changes)
 	}
 
 	if result.Failed > 0 {
-		ui.Info("Refreshed %d of %d project(s), %d failed", result.Refreshed, result.Total, result.Failed)
-	} else {
-		ui.Info("Refreshed %d of %d project(s)", result.Refreshed, result.Total)
+		return fmt.Errorf("refreshed %d of %d project(s), %d failed", result.Refreshed, result.Total, result.Failed)
+	}
+	ui.Info("Refreshed %d of %d project(s)", result.Refreshed, result.Total)
+	return nil
+}
+
+func projectArchiveRun(names []string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	verb := "Archived"
+	if archiveRestore {
+		verb = "Restored"
+	}
+	for _, name := range names {
+		p, err := resolveProject(ctx, s, name)
+		if err != nil {
+			return err
+		}
+		if p.Archived == !archiveRestore {
+			ui.Info("Already %s: %s", strings.ToLower(verb), p.Name)
+			continue
+		}
+		if dryRun {
+			ui.DryRunMsg("Would set archived=%t for project: %s", !archiveRestore, p.Name)
+			continue
+		}
+		p.Archived = !archiveRestore
+		if err := s.UpdateProject(ctx, p); err != nil {
+			return fmt.Errorf("archive %s: %w", p.Name, err)
+		}
+		ui.Success("%s project: %s", verb, output.Cyan(p.Name))
 	}
 	return nil
 }
@@ -526,6 +1294,30 @@ func timeAgo(t time.Time) string {
 	}
 }
 
+// displayZone caches the location display.timezone names.
+var displayZone struct {
+	name string
+	loc  *time.Location
+}
+
+// displayTime converts t to the time zone set by display.timezone ("UTC",
+// "Local" or an IANA name), or the system's when unset, for CLI output.
+func displayTime(t time.Time) time.Time {
+	name := viper.GetString("display.timezone")
+	if displayZone.loc == nil || displayZone.name != name {
+		loc := time.Local
+		if name != "" {
+			if l, err := time.LoadLocation(name); err == nil {
+				loc = l
+			} else {
+				ui.Warning("Invalid display.timezone %q, using the system time zone", name)
+			}
+		}
+		displayZone.name, displayZone.loc = name, loc
+	}
+	return t.In(displayZone.loc)
+}
+
 // formatBytes returns a human-readable byte size string.
 func formatBytes(b int64) string {
 	const unit = 1024
@@ -23,23 +23,60 @@ type mockGitClient struct {
 	remoteURL string
 }
 
-func (m *mockGitClient) RepoRoot(path string) (string, error)          { return path, nil }
-func (m *mockGitClient) CurrentBranch(path string) (string, error)     { return "main", nil }
-func (m *mockGitClient) LastCommitDate(path string) (time.Time, error) { return time.Now(), nil }
-func (m *mockGitClient) LastCommitMessage(path string) (string, error) { return "msg", nil }
-func (m *mockGitClient) LastCommitHash(path string) (string, error)    { return "abc123", nil }
-func (m *mockGitClient) BranchList(path string) ([]string, error)      { return []string{"main"}, nil }
-func (m *mockGitClient) IsDirty(path string) (bool, error)             { return false, nil }
-func (m *mockGitClient) WorktreeList(path string) ([]git.WorktreeInfo, error) {
+func (m *mockGitClient) RepoRoot(_ context.Context, path string) (string, error) { return path, nil }
+func (m *mockGitClient) CurrentBranch(_ context.Context, path string) (string, error) {
+	return "main", nil
+}
+func (m *mockGitClient) LastCommitDate(_ context.Context, path string) (time.Time, error) {
+	return time.Now(), nil
+}
+func (m *mockGitClient) LastCommitMessage(_ context.Context, path string) (string, error) {
+	return "msg", nil
+}
+func (m *mockGitClient) LastCommitHash(_ context.Context, path string) (string, error) {
+	return "abc123", nil
+}
+func (m *mockGitClient) BranchList(_ context.Context, path string) ([]string, error) {
+	return []string{"main"}, nil
+}
+func (m *mockGitClient) IsDirty(_ context.Context, path string) (bool, error) { return false, nil }
+func (m *mockGitClient) WorktreeList(_ context.Context, path string) ([]git.WorktreeInfo, error) {
+	return nil, nil
+}
+func (m *mockGitClient) RemoteURL(_ context.Context, path string) (string, error) {
+	return m.remoteURL, nil
+}
+func (m *mockGitClient) LatestTag(_ context.Context, path string) (string, error) { return "", nil }
+func (m *mockGitClient) CommitCountSince(_ context.Context, path, base string) (int, error) {
+	return 0, nil
+}
+func (m *mockGitClient) AheadBehind(_ context.Context, path, base string) (int, int, error) {
+	return 0, 0, nil
+}
+func (m *mockGitClient) Diff(_ context.Context, path, base, head string) (string, error) {
+	return "", nil
+}
+func (m *mockGitClient) DiffStat(_ context.Context, path, base, head string) (string, error) {
+	return "", nil
+}
+func (m *mockGitClient) DiffNameOnly(_ context.Context, path, base, head string) ([]string, error) {
+	return nil, nil
+}
+func (m *mockGitClient) WorktreeFingerprint(_ context.Context, path string) (string, error) {
+	return "", nil
+}
+func (m *mockGitClient) CommitAll(_ context.Context, path, message string) (string, error) {
+	return "", nil
+}
+func (m *mockGitClient) CommitMessagesSince(_ context.Context, path, base string) ([]string, error) {
+	return nil, nil
+}
+func (m *mockGitClient) CommitLog(_ context.Context, path, base, head string) ([]git.Commit, error) {
+	return nil, nil
+}
+func (m *mockGitClient) Blame(_ context.Context, path, rev, file string, ranges []git.LineRange) ([]git.BlameLine, error) {
 	return nil, nil
 }
-func (m *mockGitClient) RemoteURL(path string) (string, error) { return m.remoteURL, nil }
-func (m *mockGitClient) LatestTag(path string) (string, error) { return "", nil }
-func (m *mockGitClient) CommitCountSince(path, base string) (int, error) { return 0, nil }
-func (m *mockGitClient) AheadBehind(path, base string) (int, int, error)         { return 0, 0, nil }
-func (m *mockGitClient) Diff(path, base, head string) (string, error)            { return "", nil }
-func (m *mockGitClient) DiffStat(path, base, head string) (string, error)        { return "", nil }
-func (m *mockGitClient) DiffNameOnly(path, base, head string) ([]string, error)  { return nil, nil }
 
 // mockGitHubClient implements git.GitHubClient for testing.
 type mockGitHubClient struct {
@@ -110,6 +147,36 @@ func TestRefreshProject_UpdatesLanguage(t *testing.T) {
 	assert.Equal(t, "go", got.Language)
 }
 
+func TestRefreshProject_LanguageBreakdown(t *testing.T) {
+	s := refreshTestEnv(t)
+	ctx := context.Background()
+
+	projDir := t.TempDir()
+	p := &models.Project{Name: "test", Path: projDir}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	// No manifest: the primary language comes from the breakdown
+	require.NoError(t, os.WriteFile(filepath.Join(projDir, "app.py"), []byte("import os\n\nprint(os.getcwd())\nprint(1)\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projDir, "run.sh"), []byte("#!/bin/sh\npython app.py\n"), 0644))
+
+	changed, err := refresh.Project(ctx, s, p, &mockGitClient{}, &mockGitHubClient{})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "python", p.Language)
+
+	got, err := s.GetProject(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []models.LanguageShare{
+		{Language: "python", Lines: 3, Percent: 60},
+		{Language: "shell", Lines: 2, Percent: 40},
+	}, got.Languages)
+	assert.Equal(t, []string{"python", "shell"}, got.MainLanguages())
+
+	changed, err = refresh.Project(ctx, s, got, &mockGitClient{}, &mockGitHubClient{})
+	require.NoError(t, err)
+	assert.False(t, changed, "an unchanged tree leaves the project unchanged")
+}
+
 func TestRefreshProject_UpdatesRepoURL(t *testing.T) {
 	s := refreshTestEnv(t)
 	ctx := context.Background()
@@ -246,3 +313,180 @@ func TestRefreshProject_DetectsGitHubPages(t *testing.T) {
 	assert.True(t, got.HasGitHubPages)
 	assert.Equal(t, "https://test.github.io", got.PagesURL)
 }
+
+func TestRefreshProject_IngestsConventions(t *testing.T) {
+	s := refreshTestEnv(t)
+	ctx := context.Background()
+
+	projDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projDir, "AGENTS.md"), []byte("Use table-driven tests.\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projDir, "CONVENTIONS.md"), []byte("Wrap errors with %w.\n"), 0644))
+
+	p := &models.Project{Name: "test", Path: projDir}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	gc := &mockGitClient{}
+	ghc := &mockGitHubClient{}
+
+	changed, err := refresh.Project(ctx, s, p, gc, ghc)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "# AGENTS.md\n\nUse table-driven tests.\n\n# CONVENTIONS.md\n\nWrap errors with %w.", p.Conventions)
+
+	// Unchanged files: no update
+	changed, err = refresh.Project(ctx, s, p, gc, ghc)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// Edited file: stale cache is replaced
+	require.NoError(t, os.Remove(filepath.Join(projDir, "CONVENTIONS.md")))
+	require.NoError(t, os.WriteFile(filepath.Join(projDir, "CLAUDE.md"), []byte("Run make test.\n"), 0644))
+	changed, err = refresh.Project(ctx, s, p, gc, ghc)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	got, err := s.GetProject(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "# AGENTS.md\n\nUse table-driven tests.\n\n# CLAUDE.md\n\nRun make test.", got.Conventions)
+}
+
+func TestRefreshSnapshot_ReportsChangesSinceLastRefresh(t *testing.T) {
+	s := refreshTestEnv(t)
+	ctx := context.Background()
+
+	p := &models.Project{Name: "test", Path: t.TempDir()}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	gc := &mockGitClient{}
+	ghc := &mockGitHubClient{}
+
+	// First snapshot has nothing to compare against
+	changes, err := refresh.Snapshot(ctx, s, p, gc, ghc)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// Pretend the previous refresh saw a dirty repo with more branches
+	snaps, err := s.ListHealthSnapshots(ctx, p.ID, 1)
+	require.NoError(t, err)
+	require.Len(t, snaps, 1)
+	prev := *snaps[0]
+	prev.ID = ""
+	prev.IsDirty = true
+	prev.BranchCount = 4
+	require.NoError(t, s.CreateHealthSnapshot(ctx, &prev))
+
+	changes, err = refresh.Snapshot(ctx, s, p, gc, ghc)
+	require.NoError(t, err)
+	assert.Contains(t, changes, "git dirty → clean")
+	assert.Contains(t, changes, "branches 4 → 1 (-3)")
+
+	snaps, err = s.ListHealthSnapshots(ctx, p.ID, 0)
+	require.NoError(t, err)
+	assert.Len(t, snaps, 3)
+}
+
+func TestRefreshProjects_ConcurrentKeepsOrderAndIsolatesFailures(t *testing.T) {
+	s := refreshTestEnv(t)
+	ctx := context.Background()
+
+	var projects []*models.Project
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		path := t.TempDir()
+		if name == "c" {
+			path = filepath.Join(path, "gone")
+		}
+		p := &models.Project{Name: name, Path: path}
+		require.NoError(t, s.CreateProject(ctx, p))
+		projects = append(projects, p)
+	}
+
+	result := refresh.Projects(ctx, s, projects, &mockGitClient{}, &mockGitHubClient{}, 3)
+	assert.Equal(t, 5, result.Total)
+	assert.Equal(t, 1, result.Failed)
+	require.Len(t, result.Results, 5)
+	for i, r := range result.Results {
+		assert.Equal(t, projects[i].ID, r.ID)
+		assert.Equal(t, projects[i].Name, r.Name)
+	}
+	assert.Contains(t, result.Results[2].Error, "project path missing")
+	assert.Empty(t, result.Results[3].Error)
+}
+
+func TestRefreshRun_StepResultsAndSkip(t *testing.T) {
+	s := refreshTestEnv(t)
+	ctx := context.Background()
+
+	projDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projDir, "go.mod"), []byte("module example.com/app\n\ngo 1.26\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(projDir, "main.go"), []byte("package main\n\n// TODO: wire flags\nfunc main() {} // FIXME\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(projDir, "node_modules", "dep"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(projDir, "node_modules", "dep", "index.js"), []byte("// TODO ignored\n"), 0o644))
+	p := &models.Project{Name: "app", Path: projDir}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	env := refresh.Env{Store: s, Git: &mockGitClient{}, GitHub: &mockGitHubClient{}}
+	steps, changed, err := refresh.Run(ctx, env, p)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	require.Len(t, steps, len(refresh.StepNames()))
+	byName := make(map[string]refresh.StepResult)
+	for i, st := range steps {
+		assert.Equal(t, refresh.StepNames()[i], st.Name, "steps run in registration order")
+		byName[st.Name] = st
+	}
+	assert.Equal(t, refresh.StepChanged, byName["language"].Status)
+	assert.Equal(t, "go 100%", byName["language"].Summary)
+	assert.Equal(t, refresh.StepUnchanged, byName["github"].Status)
+	assert.Equal(t, "2 in 1 files", byName["todos"].Summary)
+
+	p.RefreshSkip, err = refresh.FormatSkip([]string{"todos", " github", "todos"})
+	require.NoError(t, err)
+	assert.Equal(t, "todos,github", p.RefreshSkip)
+	_, err = refresh.FormatSkip([]string{"nope"})
+	assert.ErrorContains(t, err, `unknown refresh step "nope"`)
+
+	steps, changed, err = refresh.Run(ctx, env, p)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	for _, st := range steps {
+		if st.Name == "todos" || st.Name == "github" {
+			assert.Equal(t, refresh.StepSkipped, st.Status, st.Name)
+		} else {
+			assert.NotEqual(t, refresh.StepSkipped, st.Status, st.Name)
+		}
+	}
+}
+
+func TestRefreshRun_DepsStepRecordsTrackedModules(t *testing.T) {
+	s := refreshTestEnv(t)
+	ctx := context.Background()
+
+	upDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(upDir, "go.mod"), []byte("module example.com/lib\n\ngo 1.26\n"), 0o644))
+	up := &models.Project{Name: "lib", Path: upDir}
+	require.NoError(t, s.CreateProject(ctx, up))
+	wt := &models.Project{Name: "wt", Path: t.TempDir(), RepoURL: "git@github.com:joescharf/wt.git"}
+	require.NoError(t, s.CreateProject(ctx, wt))
+
+	appDir := t.TempDir()
+	goMod := "module example.com/app\n\ngo 1.26\n\nrequire (\n\texample.com/lib v0.1.0\n\tgithub.com/joescharf/wt/v2 v2.0.0\n\tgithub.com/spf13/cobra v1.9.1\n)\n"
+	require.NoError(t, os.WriteFile(filepath.Join(appDir, "go.mod"), []byte(goMod), 0o644))
+	app := &models.Project{Name: "app", Path: appDir}
+	require.NoError(t, s.CreateProject(ctx, app))
+
+	env := refresh.Env{Store: s, Git: &mockGitClient{}, GitHub: &mockGitHubClient{}}
+	for range 2 {
+		_, _, err := refresh.Run(ctx, env, app)
+		require.NoError(t, err)
+	}
+
+	edges, err := s.ListProjectDependencies(ctx)
+	require.NoError(t, err)
+	require.Len(t, edges, 2, "a second refresh adds nothing")
+	var upstream []string
+	for _, e := range edges {
+		assert.Equal(t, app.ID, e.ProjectID)
+		upstream = append(upstream, e.DependsOnID)
+	}
+	assert.ElementsMatch(t, []string{up.ID, wt.ID}, upstream)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/notify"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// vapidKeysPath is where pm serve keeps its Web Push key pair. Browsers
+// subscribe with the public key, so it must survive restarts.
+func vapidKeysPath() string {
+	return filepath.Join(viper.GetString("state_dir"), "vapid.json")
+}
+
+// loadVAPIDKeys reads the server's VAPID key pair, generating and saving one
+// on first use.
+func loadVAPIDKeys(path string) (notify.VAPIDKeys, error) {
+	var keys notify.VAPIDKeys
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(data, &keys); err != nil {
+			return keys, fmt.Errorf("parse %s: %w", path, err)
+		}
+		return keys, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return keys, err
+	}
+
+	if keys, err = notify.GenerateVAPIDKeys(); err != nil {
+		return keys, err
+	}
+	data, err = json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return keys, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return keys, err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return keys, fmt.Errorf("save vapid keys: %w", err)
+	}
+	return keys, nil
+}
+
+// pushNotifier returns the Web Push notifier for pm serve, or nil when
+// notify.push.enabled is off.
+func pushNotifier() (*notify.PushNotifier, error) {
+	if !viper.GetBool("notify.push.enabled") {
+		return nil, nil
+	}
+	keys, err := loadVAPIDKeys(vapidKeysPath())
+	if err != nil {
+		return nil, err
+	}
+	return notify.NewPushNotifier(notify.PushConfig{Keys: keys, Subject: viper.GetString("notify.push.subject")})
+}
+
+// startPushWatcher pushes session completions and conflicts to subscribed
+// browsers, checking every notify.push.interval.
+func startPushWatcher(ctx context.Context, s store.Store, n *notify.PushNotifier) {
+	interval, err := time.ParseDuration(viper.GetString("notify.push.interval"))
+	if err != nil || interval <= 0 {
+		ui.Warning("Push notifications disabled: invalid notify.push.interval %q", viper.GetString("notify.push.interval"))
+		return
+	}
+
+	w := notify.NewSessionWatcher(s, n)
+	check := func() {
+		if err := w.Check(ctx); err != nil {
+			ui.Warning("Push notifications: %v", err)
+		}
+	}
+
+	go func() {
+		check()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				check()
+			}
+		}
+	}()
+}
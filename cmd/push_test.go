@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadVAPIDKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "vapid.json")
+
+	keys, err := loadVAPIDKeys(path)
+	require.NoError(t, err)
+	assert.NotEmpty(t, keys.PublicKey)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	again, err := loadVAPIDKeys(path)
+	require.NoError(t, err)
+	assert.Equal(t, keys, again, "browsers stay subscribed across restarts")
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	_, err = loadVAPIDKeys(path)
+	assert.Error(t, err)
+}
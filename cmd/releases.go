@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/releases"
+	"github.com/joescharf/pm/internal/sla"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// releasePollTick is how often the release poller looks for projects due
+// a fetch.
+const releasePollTick = time.Minute
+
+// releaseSchedule reads github.release_poll.*, falling back to the default
+// of each setting that does not parse.
+func releaseSchedule() releases.Schedule {
+	sched := releases.DefaultSchedule()
+	for key, d := range map[string]*time.Duration{
+		"github.release_poll.active":        &sched.Active,
+		"github.release_poll.idle":          &sched.Idle,
+		"github.release_poll.active_within": &sched.ActiveWithin,
+	} {
+		v, err := sla.ParseDuration(viper.GetString(key))
+		if err != nil {
+			ui.Warning("%s: %v; using the default", key, err)
+			continue
+		}
+		*d = v
+	}
+	return sched
+}
+
+// startReleasePoller keeps the stored GitHub release of every project
+// current in the background, polling on sched.
+func startReleasePoller(ctx context.Context, s store.Store, sched releases.Schedule) {
+	if sched.Active <= 0 {
+		return
+	}
+	if sched.Idle < sched.Active {
+		sched.Idle = sched.Active
+	}
+	poller := releases.NewPoller(s, newGitHubClient(), git.NewClient(), sched)
+	go poller.Run(ctx, releasePollTick, func(err error) {
+		ui.Warning("GitHub release poll: %v", err)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -66,7 +67,7 @@ func exportProjects(ctx context.Context, s store.Store) error {
 		w := csv.NewWriter(ui.Out)
 		_ = w.Write([]string{"ID", "Name", "Path", "Language", "Group", "Created"})
 		for _, p := range projects {
-			_ = w.Write([]string{p.ID, p.Name, p.Path, p.Language, p.GroupName, p.CreatedAt.Format("2006-01-02")})
+			_ = w.Write([]string{p.ID, p.Name, p.Path, p.Language, p.GroupName, p.CreatedAt.UTC().Format("2006-01-02")})
 		}
 		w.Flush()
 		return w.Error()
@@ -97,13 +98,18 @@ func exportIssues(ctx context.Context, s store.Store) error {
 		return enc.Encode(issues)
 	case "csv":
 		w := csv.NewWriter(ui.Out)
-		_ = w.Write([]string{"ID", "ProjectID", "Title", "Status", "Priority", "Type", "GitHub#", "Created"})
+		_ = w.Write([]string{"ID", "ProjectID", "Title", "Status", "Priority", "Type", "GitHub#", "Created", "MergeCommit", "MergeBranch", "Merged"})
 		for _, i := range issues {
 			gh := ""
 			if i.GitHubIssue > 0 {
 				gh = fmt.Sprintf("%d", i.GitHubIssue)
 			}
-			_ = w.Write([]string{i.ID, i.ProjectID, i.Title, string(i.Status), string(i.Priority), string(i.Type), gh, i.CreatedAt.Format("2006-01-02")})
+			merged := ""
+			if i.MergedAt != nil {
+				merged = i.MergedAt.UTC().Format(time.RFC3339)
+			}
+			_ = w.Write([]string{i.ID, i.ProjectID, i.Title, string(i.Status), string(i.Priority), string(i.Type), gh, i.CreatedAt.UTC().Format("2006-01-02"),
+				i.MergeCommit, i.MergeBranch, merged})
 		}
 		w.Flush()
 		return w.Error()
@@ -137,7 +143,7 @@ func exportSessions(ctx context.Context, s store.Store) error {
 		_ = w.Write([]string{"ID", "ProjectID", "Branch", "Status", "Commits", "Started"})
 		for _, sess := range sessions {
 			_ = w.Write([]string{sess.ID, sess.ProjectID, sess.Branch, string(sess.Status),
-				fmt.Sprintf("%d", sess.CommitCount), sess.StartedAt.Format("2006-01-02T15:04:05Z")})
+				fmt.Sprintf("%d", sess.CommitCount), sess.StartedAt.UTC().Format(time.RFC3339)})
 		}
 		w.Flush()
 		return w.Error()
@@ -195,13 +201,17 @@ func reportWeeklyRun() error {
 		sessions, _ := s.ListAgentSessions(ctx, p.ID, 0)
 
 		open, closed, inProg := 0, 0, 0
+		var merged []string
 		for _, i := range issues {
+			if i.MergedAt != nil && time.Since(*i.MergedAt) < 7*24*time.Hour {
+				merged = append(merged, fmt.Sprintf("%s %s (%s)", issueCode(i), i.Title, issueMerge(i)))
+			}
 			switch i.Status {
 			case "open":
 				open++
 			case "closed", "done":
 				closed++
-			case "in_progress":
+			case "in_progress", "in_review":
 				inProg++
 			}
 		}
@@ -216,6 +226,12 @@ func reportWeeklyRun() error {
 		if len(sessionBranches) > 0 {
 			fmt.Fprintf(ui.Out, "- Agent sessions: %s\n", strings.Join(sessionBranches, ", "))
 		}
+		if len(merged) > 0 {
+			fmt.Fprintln(ui.Out, "- Merged this week:")
+			for _, m := range merged {
+				fmt.Fprintf(ui.Out, "  - %s\n", m)
+			}
+		}
 		fmt.Fprintln(ui.Out)
 	}
 
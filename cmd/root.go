@@ -2,15 +2,19 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/joescharf/pm/internal/api"
 	"github.com/joescharf/pm/internal/git"
 	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/redact"
 	"github.com/joescharf/pm/internal/refresh"
 	"github.com/joescharf/pm/internal/store"
 )
@@ -22,6 +26,7 @@ var (
 
 	verbose bool
 	dryRun  bool
+	quiet   bool
 )
 
 var rootCmd = &cobra.Command{
@@ -42,11 +47,32 @@ func Execute(version, commit, date string) {
 	buildDate = date
 
 	if err := rootCmd.Execute(); err != nil {
+		var exit *exitCodeError
+		if errors.As(err, &exit) {
+			if exit.msg != "" {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", exit.msg)
+			}
+			os.Exit(exit.code)
+		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// exitCodeError makes pm exit with a specific status, for commands whose exit
+// code is part of their interface. msg, if any, is printed like other errors.
+type exitCodeError struct {
+	code int
+	msg  string
+}
+
+func (e *exitCodeError) Error() string {
+	if e.msg != "" {
+		return e.msg
+	}
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
 func init() {
 	cobra.OnInitialize(initConfig, initDeps)
 
@@ -56,6 +82,7 @@ func init() {
 
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
 	rootCmd.PersistentFlags().BoolVarP(&dryRun, "dry-run", "n", false, "Show what would happen without making changes")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Print only data (tables, JSON, values) on stdout; warnings and errors still go to stderr")
 	rootCmd.PersistentFlags().String("config", "", "Config file (default ~/.config/pm/config.yaml)")
 }
 
@@ -76,32 +103,120 @@ func initConfig() {
 		viper.SetConfigType("yaml")
 	}
 
-	viper.SetEnvPrefix("PM")
-	viper.AutomaticEnv()
+	setConfigDefaults(viper.GetViper())
+
+	// Read config file if it exists (optional)
+	_ = viper.ReadInConfig()
+}
+
+// setConfigDefaults sets up v's environment variables and defaults. Config
+// reloads build a new viper with it to check a changed file.
+func setConfigDefaults(v *viper.Viper) {
+	v.SetEnvPrefix("PM")
+	v.AutomaticEnv()
 
-	// Defaults via viper.SetDefault()
 	home, _ := os.UserHomeDir()
 	defaultConfigDir := filepath.Join(home, ".config", "pm")
 
-	viper.SetDefault("state_dir", defaultConfigDir)
-	viper.SetDefault("db_path", filepath.Join(defaultConfigDir, "pm.db"))
-	viper.SetDefault("github.default_org", "")
-	viper.SetDefault("agent.model", "opus")
-	viper.SetDefault("agent.auto_launch", false)
-	viper.SetDefault("anthropic.api_key", "")
-	viper.SetDefault("anthropic.model", "claude-haiku-4-5-20251001")
-
-	// Read config file if it exists (optional)
-	_ = viper.ReadInConfig()
+	v.SetDefault("state_dir", defaultConfigDir)
+	v.SetDefault("db_path", filepath.Join(defaultConfigDir, "pm.db"))
+	v.SetDefault("profile_name", "default")
+	v.SetDefault("github.default_org", "")
+	v.SetDefault("github.api_url", "")
+	v.SetDefault("github.review_sync.interval", "0")
+	v.SetDefault("github.review_sync.follow_ups", false)
+	v.SetDefault("github.board_sync.interval", "0")
+	v.SetDefault("github.board_sync.status_field", "Status")
+	v.SetDefault("github.board_sync.priority_field", "Priority")
+	v.SetDefault("github.release_poll.active", "15m")
+	v.SetDefault("github.release_poll.idle", "6h")
+	v.SetDefault("github.release_poll.active_within", "7d")
+	v.SetDefault("agent.model", "opus")
+	v.SetDefault("agent.auto_launch", false)
+	v.SetDefault("agent.terminal", "")
+	v.SetDefault("display.timezone", "")
+	v.SetDefault("anthropic.api_key", "")
+	v.SetDefault("anthropic.model", "claude-haiku-4-5-20251001")
+	v.SetDefault("redaction.enabled", true)
+	v.SetDefault("redaction.min_entropy", redact.DefaultMinEntropy)
+	v.SetDefault("llm.budget.monthly_tokens", 0)
+	v.SetDefault("llm.budget.monthly_cost", 0)
+	v.SetDefault("llm.budget.input_cost_per_mtok", 1.0)
+	v.SetDefault("llm.budget.output_cost_per_mtok", 5.0)
+	v.SetDefault("llm.budget.on_exceeded", "heuristics")
+	v.SetDefault("sessions.cleanup.statuses", []string{"abandoned"})
+	v.SetDefault("sessions.cleanup.max_commits", 0)
+	v.SetDefault("sessions.cleanup.max_duration", "60s")
+	v.SetDefault("sessions.cleanup.min_age", "0s")
+	v.SetDefault("sessions.close.enforce_check", false)
+	v.SetDefault("sessions.blocked.remind_after", "3d")
+	v.SetDefault("sessions.issue_lock.expire_after", "2h")
+	v.SetDefault("sessions.reconcile.interval", "30s")
+	v.SetDefault("sessions.reconcile.cooldown", "1m")
+	v.SetDefault("sessions.heartbeat.interval", "1m")
+	v.SetDefault("sessions.heartbeat.watch", true)
+	v.SetDefault("sessions.discover.workers", 4)
+	v.SetDefault("sessions.discover.timeout", "30s")
+	v.SetDefault("sessions.discover.exclude", []string{})
+	v.SetDefault("autoclose.enabled", true)
+	v.SetDefault("autoclose.github", false)
+	v.SetDefault("review.auto_relaunch", false)
+	v.SetDefault("review.max_attempts", 3)
+	v.SetDefault("review.pre_analysis", false)
+	v.SetDefault("review.share.ttl", "3d")
+	v.SetDefault("review.share.base_url", "")
+	v.SetDefault("notify.email.host", "")
+	v.SetDefault("notify.email.port", 587)
+	v.SetDefault("notify.email.username", "")
+	v.SetDefault("notify.email.password", "")
+	v.SetDefault("notify.email.from", "")
+	v.SetDefault("notify.email.to", []string{})
+	v.SetDefault("notify.email.projects", []string{})
+	v.SetDefault("notify.email.schedule", "")
+	v.SetDefault("notify.push.enabled", true)
+	v.SetDefault("notify.push.subject", "mailto:pm@localhost")
+	v.SetDefault("notify.push.interval", "15s")
+	v.SetDefault("notify.watch.interval", "30s")
+	v.SetDefault("notify.webhook.secret", "")
+	v.SetDefault("api.cache_ttl", "30s")
+	v.SetDefault("api.expand_timeout", "5s")
+	v.SetDefault("api.require_auth", false)
+	v.SetDefault("api.public_badges", false)
+	v.SetDefault("api.cors.allowed_origins", api.DefaultCORSConfig().AllowedOrigins)
+	v.SetDefault("api.cors.allowed_methods", api.DefaultCORSConfig().AllowedMethods)
+	v.SetDefault("api.cors.allowed_headers", api.DefaultCORSConfig().AllowedHeaders)
+	v.SetDefault("server.allow_remote", false)
+	v.SetDefault("server.watch_config", true)
+	v.SetDefault("intake.secret", "")
+	v.SetDefault("intake.default_project", "")
+	v.SetDefault("git.max_concurrent", git.DefaultMaxConcurrent)
+	v.SetDefault("git.timeout", git.DefaultTimeout.String())
+	v.SetDefault("sla.interval", "1h")
+	v.SetDefault("changes.retention", "7d")
+	v.SetDefault("db.compression", store.DefaultCompression.Codec)
+	v.SetDefault("db.compression_min_size", store.DefaultCompression.MinSize)
 }
 
 func initDeps() {
 	ui = output.New()
 	ui.Verbose = verbose
 	ui.DryRun = dryRun
+	ui.Quiet = quiet
 
 	// Initialize store lazily — only when commands actually need it.
 	// This allows config/version commands to run without a db.
+
+	configureGit()
+}
+
+// configureGit bounds concurrent git subprocesses and their run time from
+// git.max_concurrent and git.timeout.
+func configureGit() {
+	timeout, err := time.ParseDuration(viper.GetString("git.timeout"))
+	if err != nil {
+		ui.Warning("Invalid git.timeout %q, using %s", viper.GetString("git.timeout"), git.DefaultTimeout)
+	}
+	git.SetDefaultExecutor(git.NewExecutor(viper.GetInt("git.max_concurrent"), timeout))
 }
 
 // rootRun handles `pm` with no subcommand: detect project from cwd, refresh, and show.
@@ -119,7 +234,7 @@ func rootRun(cmd *cobra.Command) error {
 
 	// Best-effort refresh
 	gc := git.NewClient()
-	ghc := git.NewGitHubClient()
+	ghc := newGitHubClient()
 	_, _ = refresh.Project(ctx, s, p, gc, ghc)
 
 	return projectShowRun(p.Name)
@@ -131,10 +246,9 @@ func getStore() (store.Store, error) {
 		return dataStore, nil
 	}
 
-	dbPath := viper.GetString("db_path")
-	s, err := store.NewSQLiteStore(dbPath)
+	s, err := openDatabase()
 	if err != nil {
-		return nil, fmt.Errorf("open database: %w", err)
+		return nil, err
 	}
 
 	if err := s.Migrate(rootCmd.Context()); err != nil {
@@ -145,3 +259,20 @@ func getStore() (store.Store, error) {
 	dataStore = s
 	return dataStore, nil
 }
+
+// openDatabase opens the configured database without migrating it.
+func openDatabase() (*store.SQLiteStore, error) {
+	s, err := store.NewSQLiteStore(viper.GetString("db_path"))
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	compression := store.Compression{
+		Codec:   viper.GetString("db.compression"),
+		MinSize: viper.GetInt("db.compression_min_size"),
+	}
+	if err := s.SetCompression(compression); err != nil {
+		_ = s.Close()
+		return nil, fmt.Errorf("db.compression: %w", err)
+	}
+	return s, nil
+}
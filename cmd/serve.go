@@ -3,23 +3,34 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/joescharf/pm/internal/agent"
 	"github.com/joescharf/pm/internal/api"
+	"github.com/joescharf/pm/internal/capabilities"
 	"github.com/joescharf/pm/internal/daemon"
+	"github.com/joescharf/pm/internal/federation"
 	"github.com/joescharf/pm/internal/git"
 	pmcp "github.com/joescharf/pm/internal/mcp"
+	"github.com/joescharf/pm/internal/models"
 	"github.com/joescharf/pm/internal/refresh"
+	"github.com/joescharf/pm/internal/sessions"
+	"github.com/joescharf/pm/internal/sla"
+	"github.com/joescharf/pm/internal/store"
+	"github.com/joescharf/pm/internal/terminal"
 	embedui "github.com/joescharf/pm/internal/ui"
 	"github.com/joescharf/pm/internal/wt"
 )
@@ -27,7 +38,17 @@ import (
 var serveCmd = &cobra.Command{
 	Use:   "serve",
 	Short: "Start the web UI and API server",
-	Long:  "Start an HTTP server serving the REST API, embedded web UI, and MCP server.\nBy default it listens on port 8080 (API/UI) and 8081 (MCP).\n\nUse subcommands (start, stop, restart, status) for background management.",
+	Long: `Start an HTTP server serving the REST API, embedded web UI, and MCP server.
+By default it listens on 127.0.0.1, port 8080 (API/UI) and 8081 (MCP).
+
+--grpc :9090 also serves the gRPC API (pkg/pmpb) for Go clients; a bare
+port listens on the --bind address.
+
+Binding beyond localhost (--bind 0.0.0.0) is refused unless the config sets
+server.allow_remote: true, or api.require_auth: true to make every request
+carry an API token ('pm token create').
+
+Use subcommands (start, stop, restart, status) for background management.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		isDaemon := viper.GetBool("daemon")
 		return serveRun(cmd.Context(), isDaemon)
@@ -90,6 +111,10 @@ func serveLogPath() string {
 // This distinguishes "user ran --daemon" (needs to fork) from "I am the child" (run server).
 const serveDaemonEnv = "_PM_SERVE_DAEMON"
 
+// worktreeRescanInterval is how often the worktree watcher picks up sessions
+// opened or closed since its last scan.
+const worktreeRescanInterval = 30 * time.Second
+
 // serveRun runs the HTTP server, optionally in daemon mode.
 func serveRun(ctx context.Context, isDaemon bool) error {
 	// --daemon was passed: if we are NOT the child process, fork one and exit.
@@ -102,14 +127,37 @@ func serveRun(ctx context.Context, isDaemon bool) error {
 	port := viper.GetInt("port")
 	mcpEnabled := viper.GetBool("mcp")
 	mcpPort := viper.GetInt("mcp_port")
+	bind := viper.GetString("bind")
+	requireAuth := viper.GetBool("api.require_auth")
+	if err := checkExposure(bind, viper.GetBool("server.allow_remote"), requireAuth); err != nil {
+		return err
+	}
+	var grpcListen string
+	if g := viper.GetString("grpc"); g != "" {
+		var err error
+		if grpcListen, err = grpcAddr(g, bind); err != nil {
+			return err
+		}
+		host, _, _ := net.SplitHostPort(grpcListen)
+		if err := checkExposure(host, viper.GetBool("server.allow_remote"), requireAuth); err != nil {
+			return err
+		}
+	}
+	if !isLoopback(bind) && slices.Contains(viper.GetStringSlice("api.cors.allowed_origins"), "*") {
+		ui.Warning("api.cors.allowed_origins lets any website call the API on %s", bind)
+	}
 
 	s, err := getStore()
 	if err != nil {
 		return err
 	}
+	// Tokens only scope the requests that carry them.
+	if tokens, err := s.ListAPITokens(context.Background()); err == nil && len(tokens) > 0 && !requireAuth {
+		ui.Warning("Requests without an API token have full access to every project; set api.require_auth: true to enforce the %d project-scoped token(s)", len(tokens))
+	}
 
 	gc := git.NewClient()
-	ghc := git.NewGitHubClient()
+	ghc := newGitHubClient()
 	wtc := wt.NewClient()
 
 	// Refresh all projects in the background.
@@ -122,8 +170,93 @@ func serveRun(ctx context.Context, isDaemon bool) error {
 	// Create LLM client (may be nil if no API key configured)
 	llmClient := newLLMClient()
 
-	// Create API server.
-	apiServer := api.NewServer(s, gc, ghc, wtc, llmClient)
+	// Endpoints, tools and background jobs needing an integration this host
+	// lacks are disabled up front rather than failing as they run.
+	caps := detectCapabilities(llmClient != nil)
+	if !caps.Has(capabilities.Forge) {
+		ui.Warning("%s; GitHub features are disabled", caps.Reasons[capabilities.Forge])
+	}
+
+	// SLA policies are evaluated by the API report and a background job.
+	policies, err := slaPolicies()
+	if err != nil {
+		return err
+	}
+
+	budget, err := llmBudget()
+	if err != nil {
+		return err
+	}
+	staleCriteria, err := staleSessionCriteria(viper.GetViper())
+	if err != nil {
+		return err
+	}
+
+	// Hook heartbeats, MCP tool calls and worktree file changes share one
+	// per-session write interval.
+	heartbeats := agent.NewHeartbeats(heartbeatInterval())
+	operations := sessions.NewOperations()
+	releaseSched := releaseSchedule()
+
+	// Create API server. The reloadable options are applied again when the
+	// config file is reloaded.
+	var reloader *configReloader
+	apiOpts := append([]api.Option{
+		api.WithHeartbeats(heartbeats),
+		api.WithOperations(operations),
+		api.WithSLAPolicies(policies),
+		api.WithBlockedReminder(blockedRemindAfter()),
+		api.WithIssueLockExpiry(issueLockExpiry()),
+		api.WithAutoClose(autoCloseConfig()),
+		api.WithReleaseSchedule(releaseSched),
+		api.WithCapabilities(caps),
+		api.WithLLMBudget(budget),
+		api.WithRequireAuth(requireAuth),
+		api.WithReloader(func(ctx context.Context) (*api.ReloadReport, error) { return reloader.Reload(ctx) }),
+	}, reloadableAPIOptions(staleCriteria)...)
+	if ttl, err := time.ParseDuration(viper.GetString("api.cache_ttl")); err == nil {
+		apiOpts = append(apiOpts, api.WithResponseCache(ttl))
+	} else {
+		ui.Warning("Response cache disabled: invalid api.cache_ttl %q", viper.GetString("api.cache_ttl"))
+	}
+	if d, err := time.ParseDuration(viper.GetString("api.expand_timeout")); err == nil {
+		apiOpts = append(apiOpts, api.WithSessionExpandTimeout(d))
+	} else {
+		ui.Warning("Invalid api.expand_timeout %q, using the default", viper.GetString("api.expand_timeout"))
+	}
+	if d, err := time.ParseDuration(viper.GetString("sessions.reconcile.cooldown")); err == nil {
+		apiOpts = append(apiOpts, api.WithReconcileCooldown(d))
+	} else {
+		ui.Warning("Invalid sessions.reconcile.cooldown %q, using the default", viper.GetString("sessions.reconcile.cooldown"))
+	}
+	if backend := viper.GetString("agent.terminal"); backend != "" {
+		spawner, err := terminal.New(backend)
+		if err != nil {
+			return fmt.Errorf("agent.terminal: %w", err)
+		}
+		if caps.Has(capabilities.Terminal) {
+			apiOpts = append(apiOpts, api.WithTerminalSpawner(spawner))
+		} else {
+			ui.Warning("%s", caps.Reasons[capabilities.Terminal])
+		}
+	}
+	pusher, err := pushNotifier()
+	if err != nil {
+		ui.Warning("Push notifications disabled: %v", err)
+	} else if pusher != nil {
+		apiOpts = append(apiOpts, api.WithPush(pusher))
+	}
+	profiles, err := configuredProfiles()
+	if err != nil {
+		return err
+	}
+	defer federation.CloseAll(profiles)
+	apiOpts = append(apiOpts, api.WithProfiles(viper.GetString("profile_name"), profiles))
+	if db, ok := s.(*store.SQLiteStore); ok {
+		apiOpts = append(apiOpts, api.WithDatabase(db))
+	}
+	apiServer := api.NewServer(s, gc, ghc, wtc, llmClient, apiOpts...)
+	reloader = newConfigReloader(apiServer, budget)
 
 	// Create UI handler.
 	uiHandler, err := embedui.Handler()
@@ -133,21 +266,30 @@ func serveRun(ctx context.Context, isDaemon bool) error {
 
 	// Mount API routes and UI.
 	mux := http.NewServeMux()
-	mux.Handle("/api/", apiServer.Router())
+	apiHandler := apiServer.Router()
+	mux.Handle("/api/", apiHandler)
+	mux.Handle("/healthz", apiHandler)
 	mux.Handle("/", uiHandler)
 
-	addr := fmt.Sprintf(":%d", port)
-	url := fmt.Sprintf("http://localhost%s", addr)
+	addr := net.JoinHostPort(bind, strconv.Itoa(port))
+	url := serveURL(bind, port)
 	ui.Info("Serving API at %s/api/v1/", url)
 	ui.Info("Serving UI at %s", url)
 
 	// Start MCP StreamableHTTP server concurrently.
 	if mcpEnabled {
-		mcpSrv := pmcp.NewServer(s, gc, ghc, wtc, llmClient)
-		httpMCP := server.NewStreamableHTTPServer(mcpSrv.MCPServer())
-		mcpAddr := fmt.Sprintf(":%d", mcpPort)
-		mcpURL := fmt.Sprintf("http://localhost%s/mcp", mcpAddr)
-		ui.Info("Serving MCP at %s", mcpURL)
+		mcpSrv := pmcp.NewServer(s, gc, ghc, wtc, llmClient, mcpServerOptions(heartbeats, operations, caps, budget)...)
+		mcpMux := http.NewServeMux()
+		httpMCP := server.NewStreamableHTTPServer(mcpSrv.MCPServer(),
+			server.WithHTTPContextFunc(mcpSrv.HTTPContext),
+			server.WithStreamableHTTPServer(&http.Server{Handler: mcpMux}))
+		if requireAuth {
+			mcpMux.Handle("/mcp", pmcp.RequireToken(httpMCP))
+		} else {
+			mcpMux.Handle("/mcp", httpMCP)
+		}
+		mcpAddr := net.JoinHostPort(bind, strconv.Itoa(mcpPort))
+		ui.Info("Serving MCP at %s/mcp", serveURL(bind, mcpPort))
 
 		go func() {
 			if merr := httpMCP.Start(mcpAddr); merr != nil {
@@ -172,6 +314,62 @@ func serveRun(ctx context.Context, isDaemon bool) error {
 	ctx, stop := signal.NotifyContext(ctx, shutdownSignals()...)
 	defer stop()
 
+	// Apply config file changes without a restart where possible.
+	if viper.GetBool("server.watch_config") {
+		if err := watchConfig(ctx, reloader); err != nil {
+			ui.Warning("Config watcher disabled: %v", err)
+		}
+	}
+
+	// Email the activity digest on the configured schedule, if any.
+	startDigestScheduler(ctx, s)
+
+	// Keep issue SLA states and breach events current.
+	startSLAEvaluator(ctx, s, policies)
+
+	// Apply session reconciliation in the background; session lists only
+	// flag what it will change.
+	if interval, err := time.ParseDuration(viper.GetString("sessions.reconcile.interval")); err == nil && interval > 0 {
+		go apiServer.RunReconciler(ctx, interval, func(err error) { ui.Warning("Session reconciliation: %v", err) })
+	} else {
+		ui.Warning("Session reconciliation disabled: invalid sessions.reconcile.interval %q", viper.GetString("sessions.reconcile.interval"))
+	}
+
+	// Count file changes in open sessions' worktrees as session activity.
+	if viper.GetBool("sessions.heartbeat.watch") {
+		go func() {
+			if err := agent.RunWorktreeWatcher(ctx, s, heartbeats, worktreeRescanInterval, func(err error) { ui.Warning("Worktree watcher: %v", err) }); err != nil {
+				ui.Warning("Worktree watcher disabled: %v", err)
+			}
+		}()
+	}
+
+	// Keep the change feed to its retention window.
+	startChangePruner(ctx, s)
+
+	// Tell issue watchers about status changes, comments and reviews.
+	startIssueNotifier(ctx, s)
+
+	// Import reviews of session pull requests into their issues, keep
+	// GitHub boards in step and poll releases, when gh can reach GitHub.
+	if caps.Has(capabilities.Forge) {
+		startReviewSync(ctx, s)
+		startBoardSync(ctx, s)
+		startReleasePoller(ctx, s, releaseSched)
+	}
+
+	// Notify subscribed browsers about finished and conflicted sessions.
+	if pusher != nil {
+		startPushWatcher(ctx, s, pusher)
+	}
+
+	if grpcListen != "" {
+		if err := startGRPCServer(ctx, grpcListen, apiServer); err != nil {
+			return err
+		}
+		ui.Info("Serving gRPC at %s", grpcListen)
+	}
+
 	srv := &http.Server{
 		Addr:    addr,
 		Handler: mux,
@@ -232,6 +430,16 @@ func serveStartRun() error {
 	if mcpPort != 8081 {
 		args = append(args, "--mcp-port", fmt.Sprintf("%d", mcpPort))
 	}
+	bind := viper.GetString("bind")
+	if bind != defaultBind {
+		args = append(args, "--bind", bind)
+	}
+	if g := viper.GetString("grpc"); g != "" {
+		args = append(args, "--grpc", g)
+	}
+	if err := checkExposure(bind, viper.GetBool("server.allow_remote"), viper.GetBool("api.require_auth")); err != nil {
+		return err
+	}
 
 	child := exec.Command(exePath, args...)
 	child.Stdout = logFile
@@ -247,7 +455,7 @@ func serveStartRun() error {
 
 	ui.Success("Server started (PID %d)", child.Process.Pid)
 	ui.Info("  Log: %s", logPath)
-	ui.Info("  URL: http://localhost:%d", port)
+	ui.Info("  URL: %s", serveURL(bind, port))
 	return nil
 }
 
@@ -293,14 +501,116 @@ func serveStatusRun() error {
 		return nil
 	}
 
-	port := viper.GetInt("port")
 	ui.Info("Server is running.")
 	ui.Info("  PID:  %d", pid)
-	ui.Info("  URL:  http://localhost:%d", port)
+	ui.Info("  URL:  %s", serveURL(viper.GetString("bind"), viper.GetInt("port")))
 	ui.Info("  Log:  %s", serveLogPath())
 	return nil
 }
 
+// defaultBind keeps the server on the loopback interface.
+const defaultBind = "127.0.0.1"
+
+// isLoopback reports whether binding to host only accepts local connections.
+// An empty host binds every interface.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// checkExposure refuses to bind beyond localhost unless the config opts in
+// with server.allow_remote or protects the server with api.require_auth.
+func checkExposure(bind string, allowRemote, requireAuth bool) error {
+	if isLoopback(bind) || allowRemote || requireAuth {
+		return nil
+	}
+	display := bind
+	if display == "" {
+		display = "all interfaces"
+	}
+	return fmt.Errorf("refusing to serve on %s without auth: set api.require_auth: true (API tokens from 'pm token create'), or server.allow_remote: true to expose pm unauthenticated", display)
+}
+
+// serveURL is the URL to reach a server bound to bind on port.
+func serveURL(bind string, port int) string {
+	host := bind
+	if isLoopback(bind) || bind == "" || bind == "0.0.0.0" || bind == "::" {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// staleSessionCriteria builds the session cleanup thresholds from the
+// sessions.cleanup section of v, keeping the store defaults for unset keys.
+// Durations take days and weeks too ("7d").
+func staleSessionCriteria(v *viper.Viper) (store.StaleSessionCriteria, error) {
+	c := store.DefaultStaleSessionCriteria()
+
+	if statuses := v.GetStringSlice("sessions.cleanup.statuses"); len(statuses) > 0 {
+		c.Statuses = nil
+		for _, s := range statuses {
+			st, err := models.ParseSessionStatus(s)
+			if err != nil {
+				return c, fmt.Errorf("invalid sessions.cleanup.statuses: %w", err)
+			}
+			c.Statuses = append(c.Statuses, st)
+		}
+	}
+	c.MaxCommits = v.GetInt("sessions.cleanup.max_commits")
+	var err error
+	if s := v.GetString("sessions.cleanup.max_duration"); s != "" {
+		if c.MaxDuration, err = sla.ParseDuration(s); err != nil {
+			return c, fmt.Errorf("invalid sessions.cleanup.max_duration: %w", err)
+		}
+	}
+	if s := v.GetString("sessions.cleanup.min_age"); s != "" {
+		if c.MinAge, err = sla.ParseDuration(s); err != nil {
+			return c, fmt.Errorf("invalid sessions.cleanup.min_age: %w", err)
+		}
+	}
+	if err := c.Validate(); err != nil {
+		return c, fmt.Errorf("sessions.cleanup: %w", err)
+	}
+	return c, nil
+}
+
+// startChangePruner drops change feed entries older than changes.retention
+// ("7d", "36h"; 0 keeps them all), hourly. Clients whose cursor falls behind
+// the retained changes are told to reload.
+func startChangePruner(ctx context.Context, s store.Store) {
+	retention, err := sla.ParseDuration(viper.GetString("changes.retention"))
+	if err != nil {
+		ui.Warning("Change feed pruning disabled: invalid changes.retention %q", viper.GetString("changes.retention"))
+		return
+	}
+	if retention <= 0 {
+		return
+	}
+
+	prune := func() {
+		if _, err := s.PruneChanges(ctx, time.Now().Add(-retention)); err != nil && ctx.Err() == nil {
+			ui.Warning("Change feed pruning: %v", err)
+		}
+	}
+
+	go func() {
+		prune()
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				prune()
+			}
+		}
+	}()
+}
+
 func openBrowser(url string) {
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
@@ -322,17 +632,23 @@ func init() {
 	serveCmd.PersistentFlags().IntP("port", "p", 8080, "port to listen on")
 	serveCmd.PersistentFlags().Bool("mcp", true, "enable MCP StreamableHTTP server")
 	serveCmd.PersistentFlags().Int("mcp-port", 8081, "MCP server port")
+	serveCmd.PersistentFlags().String("bind", defaultBind, "address to listen on (0.0.0.0 needs server.allow_remote or api.require_auth)")
+	serveCmd.PersistentFlags().String("grpc", "", "also serve the gRPC API on this address, e.g. :9090")
 
 	serveCmd.Flags().BoolP("daemon", "d", false, "run server in the background")
 
 	viper.SetDefault("port", 8080)
 	viper.SetDefault("mcp", true)
 	viper.SetDefault("mcp_port", 8081)
+	viper.SetDefault("bind", defaultBind)
+	viper.SetDefault("grpc", "")
 	viper.SetDefault("daemon", false)
 
 	_ = viper.BindPFlag("port", serveCmd.PersistentFlags().Lookup("port"))
 	_ = viper.BindPFlag("mcp", serveCmd.PersistentFlags().Lookup("mcp"))
 	_ = viper.BindPFlag("mcp_port", serveCmd.PersistentFlags().Lookup("mcp-port"))
+	_ = viper.BindPFlag("bind", serveCmd.PersistentFlags().Lookup("bind"))
+	_ = viper.BindPFlag("grpc", serveCmd.PersistentFlags().Lookup("grpc"))
 	_ = viper.BindPFlag("daemon", serveCmd.Flags().Lookup("daemon"))
 
 	serveCmd.AddCommand(serveStartCmd)
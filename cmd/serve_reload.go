@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/api"
+	"github.com/joescharf/pm/internal/intake"
+	"github.com/joescharf/pm/internal/llmbudget"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// reloadableKeys are the config keys pm serve applies without a restart,
+// with the keys under them. Everything else (ports, bind address, auth,
+// tokens, background job intervals...) needs one. sessions.issue_lock does
+// too: the MCP server pm serve hosts reads it once, and the REST launches
+// must not disagree with it.
+var reloadableKeys = []string{
+	"intake",
+	"api.cors",
+	"api.public_badges",
+	"review.share",
+	"sessions.close.enforce_check",
+	"sessions.cleanup",
+	"sessions.discover",
+	"github.board_sync.status_field",
+	"github.board_sync.priority_field",
+	"github.board_sync.columns",
+	"github.board_sync.priorities",
+	"llm.budget",
+}
+
+// configReloadDebounce is how long the config file must be left alone
+// before a change to it is reloaded; editors often write a file in steps.
+const configReloadDebounce = 500 * time.Millisecond
+
+// reloadable reports whether key is, or is under, one of reloadableKeys.
+func reloadable(key string) bool {
+	for _, k := range reloadableKeys {
+		if key == k || strings.HasPrefix(key, k+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// reloadableAPIOptions are the API server options built from reloadable
+// keys, for NewServer and for Reconfigure after a reload. The stale session
+// criteria are passed in, checked by staleSessionCriteria.
+func reloadableAPIOptions(stale store.StaleSessionCriteria) []api.Option {
+	return []api.Option{
+		api.WithStaleSessionCriteria(stale),
+		api.WithCloseCheckEnforcement(viper.GetBool("sessions.close.enforce_check")),
+		api.WithBoardSync(boardSyncOptions()),
+		api.WithDiscover(discoverOptions()),
+		api.WithReviewShares(reviewShareTTL(), viper.GetString("review.share.base_url")),
+		api.WithCORS(corsConfig()),
+		api.WithPublicBadges(viper.GetBool("api.public_badges")),
+		api.WithIntake(intake.Config{
+			Secret:         viper.GetString("intake.secret"),
+			Routes:         viper.GetStringMapString("intake.routes"),
+			DefaultProject: viper.GetString("intake.default_project"),
+		}),
+	}
+}
+
+// corsConfig is the api.cors section of the config.
+func corsConfig() api.CORSConfig {
+	return api.CORSConfig{
+		AllowedOrigins: viper.GetStringSlice("api.cors.allowed_origins"),
+		AllowedMethods: viper.GetStringSlice("api.cors.allowed_methods"),
+		AllowedHeaders: viper.GetStringSlice("api.cors.allowed_headers"),
+	}
+}
+
+// configReloader rereads the config file of a running pm serve and applies
+// the reloadable settings to its API server and LLM budget.
+type configReloader struct {
+	server *api.Server
+	budget *llmbudget.Budget
+
+	mu sync.Mutex
+	// applied is the flattened config the server runs with. Keys needing a
+	// restart keep their startup values, so they are reported by every
+	// reload until then.
+	applied map[string]any
+}
+
+func newConfigReloader(server *api.Server, budget *llmbudget.Budget) *configReloader {
+	return &configReloader{server: server, budget: budget, applied: flattenSettings(viper.AllSettings())}
+}
+
+// Reload rereads the config file. It reports the changed keys it applied and
+// those that need a restart, by name only since values may be secrets. The
+// file is read and checked in a viper of its own first, so an unreadable file,
+// an invalid llm.budget or invalid sessions.cleanup thresholds fail the reload
+// without changing anything.
+func (r *configReloader) Reload(ctx context.Context) (*api.ReloadReport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return nil, errors.New("no config file to reload")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	next := viper.New()
+	setConfigDefaults(next)
+	next.SetConfigFile(path)
+	if err := next.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	cfg, err := llmBudgetConfig(next)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	stale, err := staleSessionCriteria(next)
+	if err != nil {
+		return nil, err
+	}
+
+	// The file is valid: the running config takes it over.
+	if err := viper.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	current := flattenSettings(viper.AllSettings())
+	report := &api.ReloadReport{Applied: []string{}, Restart: []string{}, ReloadedAt: time.Now().UTC()}
+	for _, key := range changedKeys(r.applied, current) {
+		if reloadable(key) {
+			report.Applied = append(report.Applied, key)
+		} else {
+			report.Restart = append(report.Restart, key)
+		}
+	}
+	if len(report.Applied) == 0 {
+		return report, nil
+	}
+
+	if err := r.budget.Update(cfg); err != nil {
+		return nil, err
+	}
+	r.server.Reconfigure(reloadableAPIOptions(stale)...)
+	for _, key := range report.Applied {
+		if v, ok := current[key]; ok {
+			r.applied[key] = v
+		} else {
+			delete(r.applied, key)
+		}
+	}
+	return report, nil
+}
+
+// flattenSettings flattens nested config maps into dotted keys.
+func flattenSettings(settings map[string]any) map[string]any {
+	flat := make(map[string]any)
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for k, v := range m {
+			if sub, ok := v.(map[string]any); ok && len(sub) > 0 {
+				walk(prefix+k+".", sub)
+				continue
+			}
+			flat[prefix+k] = v
+		}
+	}
+	walk("", settings)
+	return flat
+}
+
+// changedKeys returns the sorted keys whose values differ between old and
+// current, including keys only one of them has.
+func changedKeys(old, current map[string]any) []string {
+	var keys []string
+	for k, v := range current {
+		if ov, ok := old[k]; !ok || !reflect.DeepEqual(ov, v) {
+			keys = append(keys, k)
+		}
+	}
+	for k := range old {
+		if _, ok := current[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// watchConfig reloads the config file whenever it changes, until ctx is
+// done. The file's directory is watched rather than the file, so editors
+// that save by replacing the file are followed too.
+func watchConfig(ctx context.Context, r *configReloader) error {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return nil
+	}
+	path = filepath.Clean(path)
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := fw.Add(filepath.Dir(path)); err != nil {
+		_ = fw.Close()
+		return err
+	}
+
+	go func() {
+		defer func() { _ = fw.Close() }()
+		debounce := time.NewTimer(configReloadDebounce)
+		debounce.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-fw.Errors:
+				ui.Warning("Config watcher: %v", err)
+			case ev := <-fw.Events:
+				if filepath.Clean(ev.Name) == path && ev.Op != fsnotify.Chmod {
+					debounce.Reset(configReloadDebounce)
+				}
+			case <-debounce.C:
+				report, err := r.Reload(ctx)
+				if err != nil {
+					ui.Warning("Config reload: %v", err)
+					continue
+				}
+				logReload(report)
+			}
+		}
+	}()
+	return nil
+}
+
+// logReload tells the user what a reload changed.
+func logReload(report *api.ReloadReport) {
+	if len(report.Applied) > 0 {
+		ui.Info("Config reloaded: %s", strings.Join(report.Applied, ", "))
+	}
+	if len(report.Restart) > 0 {
+		ui.Warning("Restart pm serve to apply: %s", strings.Join(report.Restart, ", "))
+	}
+}
@@ -1,14 +1,20 @@
 package cmd
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/joescharf/pm/internal/api"
 	"github.com/joescharf/pm/internal/daemon"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
 )
 
 func TestPidFile_Path(t *testing.T) {
@@ -44,6 +50,25 @@ func TestServeStopRun_NotRunning(t *testing.T) {
 	assert.Contains(t, err.Error(), "not running")
 }
 
+func TestCheckExposure(t *testing.T) {
+	assert.NoError(t, checkExposure("127.0.0.1", false, false))
+	assert.NoError(t, checkExposure("localhost", false, false))
+	assert.NoError(t, checkExposure("::1", false, false))
+
+	err := checkExposure("0.0.0.0", false, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "api.require_auth")
+	assert.Error(t, checkExposure("", false, false), "an empty host binds every interface")
+	assert.NoError(t, checkExposure("0.0.0.0", true, false))
+	assert.NoError(t, checkExposure("192.168.1.5", false, true))
+}
+
+func TestServeURL(t *testing.T) {
+	assert.Equal(t, "http://localhost:8080", serveURL("127.0.0.1", 8080))
+	assert.Equal(t, "http://localhost:8080", serveURL("0.0.0.0", 8080))
+	assert.Equal(t, "http://192.168.1.5:8081", serveURL("192.168.1.5", 8081))
+}
+
 func TestServeStartRun_AlreadyRunning(t *testing.T) {
 	dir := testEnv(t)
 
@@ -56,3 +81,99 @@ func TestServeStartRun_AlreadyRunning(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "already running")
 }
+
+func TestGRPCAddr(t *testing.T) {
+	addr, err := grpcAddr(":9090", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:9090", addr, "a bare port uses the bind address")
+
+	addr, err = grpcAddr("0.0.0.0:9090", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.0.0:9090", addr)
+
+	_, err = grpcAddr("9090", "127.0.0.1")
+	assert.Error(t, err)
+}
+
+func TestConfigReloader(t *testing.T) {
+	dir := testEnv(t)
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("port: 8080\nllm:\n  budget:\n    monthly_tokens: 1000\n"), 0o644))
+	viper.SetConfigFile(path)
+	require.NoError(t, viper.ReadInConfig())
+
+	budget, err := llmBudget()
+	require.NoError(t, err)
+	srv := api.NewServer(store.NewMemoryStore(), nil, nil, nil, nil, reloadableAPIOptions(store.DefaultStaleSessionCriteria())...)
+	rl := newConfigReloader(srv, budget)
+
+	report, err := rl.Reload(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, report.Applied)
+	assert.Empty(t, report.Restart)
+
+	require.NoError(t, os.WriteFile(path, []byte("port: 9090\nllm:\n  budget:\n    monthly_tokens: 5000\nintake:\n  secret: s3cret\n"), 0o644))
+	report, err = rl.Reload(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"intake.secret", "llm.budget.monthly_tokens"}, report.Applied)
+	assert.Equal(t, []string{"port"}, report.Restart)
+	assert.Equal(t, int64(5000), budget.Limit("api").MonthlyTokens)
+
+	report, err = rl.Reload(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, report.Applied)
+	assert.Equal(t, []string{"port"}, report.Restart, "still needs a restart")
+
+	require.NoError(t, os.WriteFile(path, []byte("port: 9090\nllm:\n  budget:\n    monthly_tokens: -1\n"), 0o644))
+	_, err = rl.Reload(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, int64(5000), budget.Limit("api").MonthlyTokens, "an invalid budget is not applied")
+	assert.Equal(t, 5000, viper.GetInt("llm.budget.monthly_tokens"), "the running config keeps its values")
+	assert.Equal(t, "s3cret", viper.GetString("intake.secret"))
+
+	require.NoError(t, os.WriteFile(path, []byte("port: 9090\nllm:\n  budget:\n    monthly_tokens: 5000\nsessions:\n  cleanup:\n    max_duration: 0\n"), 0o644))
+	_, err = rl.Reload(context.Background())
+	assert.ErrorContains(t, err, "sessions.cleanup")
+	assert.Empty(t, viper.GetString("sessions.cleanup.max_duration"), "invalid cleanup thresholds are not applied")
+}
+
+func TestStaleSessionCriteria(t *testing.T) {
+	v := viper.New()
+	setConfigDefaults(v)
+	c, err := staleSessionCriteria(v)
+	require.NoError(t, err)
+	assert.Equal(t, store.DefaultStaleSessionCriteria(), c)
+
+	v.Set("sessions.cleanup.statuses", []string{"abandoned", "completed"})
+	v.Set("sessions.cleanup.max_commits", 2)
+	v.Set("sessions.cleanup.max_duration", "5m")
+	v.Set("sessions.cleanup.min_age", "7d")
+	c, err = staleSessionCriteria(v)
+	require.NoError(t, err)
+	assert.Equal(t, []models.SessionStatus{models.SessionStatusAbandoned, models.SessionStatusCompleted}, c.Statuses)
+	assert.Equal(t, 2, c.MaxCommits)
+	assert.Equal(t, 5*time.Minute, c.MaxDuration)
+	assert.Equal(t, 7*24*time.Hour, c.MinAge)
+
+	for key, bad := range map[string]any{
+		"sessions.cleanup.statuses":     []string{"gone"},
+		"sessions.cleanup.max_commits":  -1,
+		"sessions.cleanup.max_duration": "0",
+		"sessions.cleanup.min_age":      "soon",
+	} {
+		v := viper.New()
+		setConfigDefaults(v)
+		v.Set(key, bad)
+		_, err := staleSessionCriteria(v)
+		assert.Error(t, err, key)
+	}
+}
+
+func TestReloadable(t *testing.T) {
+	assert.True(t, reloadable("api.cors.allowed_origins"))
+	assert.True(t, reloadable("api.public_badges"))
+	assert.True(t, reloadable("github.board_sync.columns.in_progress"))
+	assert.False(t, reloadable("github.board_sync.interval"))
+	assert.False(t, reloadable("api.require_auth"))
+	assert.False(t, reloadable("intakes"))
+}
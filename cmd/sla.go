@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/sla"
+	"github.com/joescharf/pm/internal/store"
+)
+
+var slaFormat string
+
+var reportSLACmd = &cobra.Command{
+	Use:   "sla",
+	Short: "Evaluate issue SLA policies and report breaches",
+	Long: `Evaluate the issue SLA policies configured under sla.policies, record each
+issue's SLA state and any new breaches, and summarize the results.
+
+pm serve runs the same evaluation in the background every sla.interval.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reportSLARun()
+	},
+}
+
+func init() {
+	reportSLACmd.Flags().StringVar(&slaFormat, "format", "text", "Output format: text, json")
+	reportCmd.AddCommand(reportSLACmd)
+}
+
+// slaPolicies reads and validates sla.policies from the config.
+func slaPolicies() ([]sla.Policy, error) {
+	var cfgs []sla.PolicyConfig
+	if err := viper.UnmarshalKey("sla.policies", &cfgs); err != nil {
+		return nil, fmt.Errorf("read sla.policies: %w", err)
+	}
+	return sla.ParsePolicies(cfgs)
+}
+
+func reportSLARun() error {
+	policies, err := slaPolicies()
+	if err != nil {
+		return err
+	}
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+
+	r, err := sla.Run(context.Background(), s, policies, time.Now())
+	if err != nil {
+		return err
+	}
+
+	switch slaFormat {
+	case "text":
+	case "json":
+		enc := json.NewEncoder(ui.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	default:
+		return fmt.Errorf("unknown format: %s", slaFormat)
+	}
+
+	if len(policies) == 0 {
+		ui.Info("No SLA policies configured. Add them under sla.policies in the config file.")
+		return nil
+	}
+
+	table := ui.Table([]string{"Policy", "Issues", "OK", "At Risk", "Breached"})
+	for _, p := range r.Policies {
+		_ = table.Append([]string{
+			output.Cyan(p.Name),
+			fmt.Sprintf("%d", p.Issues),
+			fmt.Sprintf("%d", p.OK),
+			fmt.Sprintf("%d", p.AtRisk),
+			fmt.Sprintf("%d", p.Breached),
+		})
+	}
+	_ = table.Render()
+
+	printSLAIssues("Breached", r.Breached)
+	printSLAIssues("At risk", r.AtRisk)
+	if r.NewBreaches > 0 {
+		ui.Warning("%d new breach(es) recorded", r.NewBreaches)
+	}
+	return nil
+}
+
+func printSLAIssues(heading string, items []sla.IssueSLA) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(ui.Out, "\n%s:\n", heading)
+	for _, i := range items {
+		detail := fmt.Sprintf("%s %s, %dd old", i.Priority, i.Type, i.AgeDays)
+		if len(i.Missed) > 0 {
+			missed := make([]string, len(i.Missed))
+			for j, k := range i.Missed {
+				missed[j] = string(k)
+			}
+			detail += ", missed " + strings.Join(missed, "+")
+		}
+		fmt.Fprintf(ui.Out, "  %s  %s %s (%s; %s)\n", output.Cyan(i.Project), shortID(i.IssueID), i.Title, i.Policy, detail)
+	}
+}
+
+// startSLAEvaluator re-evaluates SLA policies from `pm serve` every
+// sla.interval so issue SLA states and breach events stay current.
+func startSLAEvaluator(ctx context.Context, s store.Store, policies []sla.Policy) {
+	if len(policies) == 0 {
+		return
+	}
+	interval, err := sla.ParseDuration(viper.GetString("sla.interval"))
+	if err != nil || interval <= 0 {
+		ui.Warning("SLA evaluation disabled: invalid sla.interval %q", viper.GetString("sla.interval"))
+		return
+	}
+
+	check := func() {
+		r, err := sla.Run(ctx, s, policies, time.Now())
+		if err != nil {
+			ui.Warning("SLA evaluation: %v", err)
+			return
+		}
+		if r.NewBreaches > 0 {
+			ui.Warning("SLA: %d new breach(es)", r.NewBreaches)
+		}
+	}
+
+	go func() {
+		check()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				check()
+			}
+		}
+	}()
+}
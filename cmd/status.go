@@ -7,16 +7,20 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/joescharf/pm/internal/agent"
+	"github.com/joescharf/pm/internal/deps"
 	"github.com/joescharf/pm/internal/git"
 	"github.com/joescharf/pm/internal/health"
 	"github.com/joescharf/pm/internal/models"
 	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/releases"
 	"github.com/joescharf/pm/internal/store"
 )
 
 var (
-	statusStale bool
-	statusGroup string
+	statusStale       bool
+	statusGroup       string
+	statusAllProfiles bool
 )
 
 var statusCmd = &cobra.Command{
@@ -25,10 +29,30 @@ var statusCmd = &cobra.Command{
 	Long: `Show a cross-project status overview or detailed status for one project.
 
 Without arguments, shows a summary table of all tracked projects.
-With a project name, shows detailed status for that project.`,
+With a project name, shows detailed status for that project.
+
+With --all-profiles, shows the projects of the current database and of every
+database or pm server configured under profiles, named "<profile>/<project>".
+
+With --watch, redraws the overview every --interval with the cells that
+changed highlighted. While pm serve runs on this machine, it redraws as soon
+as the server records a change and only re-reads git for the projects that
+changed (and for every project once a minute) instead of on every frame.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if statusAllProfiles {
+			if watchMode {
+				return fmt.Errorf("--watch does not combine with --all-profiles")
+			}
+			if len(args) == 1 {
+				return fmt.Errorf("--all-profiles shows every project; drop the project argument")
+			}
+			return statusAllProfilesRun()
+		}
 		if len(args) == 1 {
+			if watchMode {
+				return fmt.Errorf("--watch redraws the overview table; drop the project argument")
+			}
 			return projectShowRun(args[0]) // reuse project show for detail
 		}
 		return statusOverviewRun()
@@ -38,6 +62,8 @@ With a project name, shows detailed status for that project.`,
 func init() {
 	statusCmd.Flags().BoolVar(&statusStale, "stale", false, "Show only stale projects (no activity in 7+ days)")
 	statusCmd.Flags().StringVar(&statusGroup, "group", "", "Filter by project group")
+	statusCmd.Flags().BoolVar(&statusAllProfiles, "all-profiles", false, "Combine the projects of every configured profile (read-only)")
+	addWatchFlags(statusCmd)
 	rootCmd.AddCommand(statusCmd)
 }
 
@@ -46,22 +72,131 @@ func statusOverviewRun() error {
 	if err != nil {
 		return err
 	}
-	ctx := context.Background()
-
-	projects, err := s.ListProjects(ctx, statusGroup)
+	build := newStatusOverview(s, git.NewClient(), newGitHubClient())
+	if watchMode {
+		return runWatch(build)
+	}
+	frame, err := build(context.Background(), watchRefresh{All: true})
 	if err != nil {
 		return err
 	}
+	frame.print()
+	return nil
+}
 
-	if len(projects) == 0 {
-		ui.Info("No projects tracked. Use 'pm project add <path>' to get started.")
-		return nil
-	}
+// statusGit is what the overview reads from git and GitHub for a project.
+type statusGit struct {
+	meta     *health.ProjectMetadata
+	version  *versionInfo
+	branch   string
+	upstream *deps.UpstreamState // set when other projects depend on it
+}
 
-	gc := git.NewClient()
-	ghClient := git.NewGitHubClient()
+// newStatusOverview returns the builder of the status overview table. It
+// keeps each project's git state between frames, re-reading it for the
+// projects a refresh names.
+func newStatusOverview(s store.Store, gc git.Client, ghClient git.GitHubClient) func(context.Context, watchRefresh) (*watchFrame, error) {
+	cache := make(map[string]*statusGit)
 	scorer := health.NewScorer()
 
+	return func(ctx context.Context, refresh watchRefresh) (*watchFrame, error) {
+		projects, err := s.ListProjects(ctx, statusGroup)
+		if err != nil {
+			return nil, err
+		}
+		if len(projects) == 0 {
+			return &watchFrame{Footer: func() {
+				ui.Info("No projects tracked. Use 'pm project add <path>' to get started.")
+			}}, nil
+		}
+
+		var graph *deps.Graph
+		if edges, err := s.ListProjectDependencies(ctx); err == nil {
+			graph = deps.Build(projects, edges)
+		}
+
+		var stale []*models.Project
+		for _, p := range projects {
+			if cache[p.ID] == nil || refresh.has(p.ID) {
+				stale = append(stale, p)
+			}
+		}
+		for id, g := range gatherStatusGit(ctx, s, gc, ghClient, graph, stale) {
+			cache[id] = g
+		}
+
+		frame := &watchFrame{Headers: []string{"Project", "Version", "Branch", "Status", "Issues", "Health", "Activity"}}
+		var wipWarnings []string
+		upstreams := make(map[string]deps.UpstreamState)
+
+		for _, p := range projects {
+			g := cache[p.ID]
+			meta := g.meta
+
+			// Get issues
+			issues, _ := s.ListIssues(ctx, store.IssueListFilter{ProjectID: p.ID})
+
+			// Skip non-stale if --stale flag
+			if statusStale && !meta.LastCommitDate.IsZero() {
+				if time.Since(meta.LastCommitDate) < 7*24*time.Hour {
+					continue
+				}
+			}
+
+			// Compute health
+			h := scorer.Score(p, meta, issues)
+
+			if wip, err := agent.CheckWIP(ctx, s, p, 0, 0); err == nil {
+				wipWarnings = append(wipWarnings, wip.Warnings...)
+			}
+			if g.upstream != nil {
+				st := *g.upstream
+				st.Health = h.Total
+				upstreams[p.ID] = st
+			}
+
+			// Format fields
+			gitStatus := getGitStatus(meta)
+			issueStr := formatIssueCounts(issues)
+			healthStr := output.HealthColor(h.Total)
+			activity := "n/a"
+			if !meta.LastCommitDate.IsZero() {
+				activity = timeAgo(meta.LastCommitDate)
+			}
+
+			versionStr := "-"
+			if g.version != nil {
+				versionStr = g.version.Version
+			}
+
+			frame.Rows = append(frame.Rows, []string{
+				output.Cyan(p.Name),
+				versionStr,
+				g.branch,
+				gitStatus,
+				issueStr,
+				healthStr,
+				activity,
+			})
+		}
+
+		frame.Footer = func() {
+			for _, warn := range wipWarnings {
+				ui.Warning("WIP limit: %s", warn)
+			}
+			if graph != nil {
+				for _, warn := range deps.Warnings(graph, upstreams) {
+					ui.Warning("Upstream: %s", warn)
+				}
+			}
+		}
+		return frame, nil
+	}
+}
+
+// gatherStatusGit reads the git state of projects, fetching their versions
+// in parallel, by project ID.
+func gatherStatusGit(ctx context.Context, s store.Store, gc git.Client, ghClient git.GitHubClient, graph *deps.Graph, projects []*models.Project) map[string]*statusGit {
 	// Fetch version info in parallel
 	type projectVersion struct {
 		index int
@@ -71,7 +206,7 @@ func statusOverviewRun() error {
 
 	for i, p := range projects {
 		go func(idx int, proj *models.Project) {
-			vi := getVersionInfo(gc, ghClient, proj)
+			vi := getVersionInfo(ctx, s, gc, ghClient, proj)
 			versionCh <- projectVersion{index: idx, vi: vi}
 		}(i, p)
 	}
@@ -82,76 +217,54 @@ func statusOverviewRun() error {
 		versions[pv.index] = pv.vi
 	}
 
-	table := ui.Table([]string{"Project", "Version", "Branch", "Status", "Issues", "Health", "Activity"})
-
+	out := make(map[string]*statusGit, len(projects))
 	for i, p := range projects {
-		meta := gatherMetadata(gc, p)
-		populateReleaseMeta(meta, versions[i])
-
-		// Get issues
-		issues, _ := s.ListIssues(ctx, store.IssueListFilter{ProjectID: p.ID})
-
-		// Skip non-stale if --stale flag
-		if statusStale && !meta.LastCommitDate.IsZero() {
-			if time.Since(meta.LastCommitDate) < 7*24*time.Hour {
-				continue
-			}
-		}
-
-		// Compute health
-		h := scorer.Score(p, meta, issues)
-
-		// Format fields
-		branch := getBranch(gc, p.Path)
-		gitStatus := getGitStatus(meta)
-		issueStr := formatIssueCounts(issues)
-		healthStr := output.HealthColor(h.Total)
-		activity := "n/a"
-		if !meta.LastCommitDate.IsZero() {
-			activity = timeAgo(meta.LastCommitDate)
+		g := &statusGit{meta: gatherMetadata(ctx, gc, p), version: versions[i], branch: getBranch(ctx, gc, p.Path)}
+		populateReleaseMeta(g.meta, versions[i])
+		if graph != nil && len(graph.Downstream(p.ID)) > 0 {
+			st := upstreamState(ctx, gc, p, 0, versions[i])
+			g.upstream = &st
 		}
-
-		versionStr := "-"
-		if versions[i] != nil {
-			versionStr = versions[i].Version
-		}
-
-		_ = table.Append([]string{
-			output.Cyan(p.Name),
-			versionStr,
-			branch,
-			gitStatus,
-			issueStr,
-			healthStr,
-			activity,
-		})
+		out[p.ID] = g
 	}
+	return out
+}
 
-	_ = table.Render()
-	return nil
+// upstreamState collects what dependents of p should be warned about: its
+// health and any breaking commits since its latest release tag.
+func upstreamState(ctx context.Context, gc git.Client, p *models.Project, score int, vi *versionInfo) deps.UpstreamState {
+	st := deps.UpstreamState{Health: score}
+	if vi == nil {
+		return st
+	}
+	st.Release = vi.Version
+	if msgs, err := gc.CommitMessagesSince(ctx, p.Path, vi.Version); err == nil {
+		st.Breaking = deps.BreakingSubjects(msgs)
+	}
+	return st
 }
 
-func gatherMetadata(gc git.Client, p *models.Project) *health.ProjectMetadata {
+func gatherMetadata(ctx context.Context, gc git.Client, p *models.Project) *health.ProjectMetadata {
 	meta := &health.ProjectMetadata{}
 
-	if dirty, err := gc.IsDirty(p.Path); err == nil {
+	if dirty, err := gc.IsDirty(ctx, p.Path); err == nil {
 		meta.IsDirty = dirty
 	}
-	if date, err := gc.LastCommitDate(p.Path); err == nil {
+	if date, err := gc.LastCommitDate(ctx, p.Path); err == nil {
 		meta.LastCommitDate = date
 	}
-	if branches, err := gc.BranchList(p.Path); err == nil {
+	if branches, err := gc.BranchList(ctx, p.Path); err == nil {
 		meta.BranchCount = len(branches)
 	}
-	if wts, err := gc.WorktreeList(p.Path); err == nil {
+	if wts, err := gc.WorktreeList(ctx, p.Path); err == nil {
 		meta.WorktreeCount = len(wts)
 	}
 
 	return meta
 }
 
-func getBranch(gc git.Client, path string) string {
-	branch, err := gc.CurrentBranch(path)
+func getBranch(ctx context.Context, gc git.Client, path string) string {
+	branch, err := gc.CurrentBranch(ctx, path)
 	if err != nil {
 		return "?"
 	}
@@ -174,7 +287,7 @@ func formatIssueCounts(issues []*models.Issue) string {
 		switch i.Status {
 		case models.IssueStatusOpen:
 			open++
-		case models.IssueStatusInProgress:
+		case models.IssueStatusInProgress, models.IssueStatusInReview:
 			inProg++
 		}
 	}
@@ -185,29 +298,28 @@ type versionInfo struct {
 	Version string
 	Date    time.Time
 	Source  string // "github" or "git-tag"
-	Assets  []git.ReleaseAsset
+	Assets  []models.ReleaseAsset
+	// Release is the stored GitHub release the version comes from.
+	Release *models.ProjectRelease
 }
 
-func getVersionInfo(gc git.Client, ghClient git.GitHubClient, p *models.Project) *versionInfo {
-	// Primary: GitHub release
-	if p.RepoURL != "" {
-		if owner, repo, err := git.ExtractOwnerRepo(p.RepoURL); err == nil {
-			if rel, err := ghClient.LatestRelease(owner, repo); err == nil {
-				vi := &versionInfo{
-					Version: rel.TagName,
-					Source:  "github",
-					Assets:  rel.Assets,
-				}
-				if t, err := time.Parse(time.RFC3339, rel.PublishedAt); err == nil {
-					vi.Date = t
-				}
-				return vi
-			}
+func getVersionInfo(ctx context.Context, s store.Store, gc git.Client, ghClient git.GitHubClient, p *models.Project) *versionInfo {
+	// Primary: GitHub release, as last fetched by the poller
+	if rel := releases.Cached(ctx, s, ghClient, p, releaseSchedule()); rel != nil {
+		vi := &versionInfo{
+			Version: rel.TagName,
+			Source:  "github",
+			Assets:  rel.Assets,
+			Release: rel,
+		}
+		if rel.PublishedAt != nil {
+			vi.Date = *rel.PublishedAt
 		}
+		return vi
 	}
 
 	// Fallback: local git tag
-	if tag, err := gc.LatestTag(p.Path); err == nil {
+	if tag, err := gc.LatestTag(ctx, p.Path); err == nil {
 		return &versionInfo{
 			Version: tag,
 			Source:  "git-tag",
@@ -3,17 +3,20 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 
 	"github.com/spf13/cobra"
 
 	"github.com/joescharf/pm/internal/models"
 	"github.com/joescharf/pm/internal/output"
+	"github.com/joescharf/pm/internal/store"
 )
 
 var tagCmd = &cobra.Command{
 	Use:   "tag",
 	Short: "Manage issue tags",
-	Long:  "Create, list, and delete tags for organizing issues.",
+	Long:  "Create, list, rename, merge and delete tags for organizing issues.",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return tagListRun()
 	},
@@ -47,10 +50,52 @@ var tagDeleteCmd = &cobra.Command{
 	},
 }
 
+var tagShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Count a tag's issues by status, priority, type and project",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return tagShowRun(args[0])
+	},
+}
+
+var tagRenameCmd = &cobra.Command{
+	Use:   "rename <name> <new-name>",
+	Short: "Rename a tag; its issues keep it",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return tagRenameRun(args[0], args[1])
+	},
+}
+
+var tagMergeCmd = &cobra.Command{
+	Use:   "merge <name> <into>",
+	Short: "Move a tag's issues to another tag and delete it",
+	Long: `Give every issue tagged <name> the tag <into> instead, then delete <name>.
+
+Example: pm tag merge bugs bug`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return tagMergeRun(args[0], args[1])
+	},
+}
+
+var tagPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete tags no issue carries",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return tagPruneRun()
+	},
+}
+
 func init() {
 	tagCmd.AddCommand(tagListCmd)
 	tagCmd.AddCommand(tagCreateCmd)
 	tagCmd.AddCommand(tagDeleteCmd)
+	tagCmd.AddCommand(tagShowCmd)
+	tagCmd.AddCommand(tagRenameCmd)
+	tagCmd.AddCommand(tagMergeCmd)
+	tagCmd.AddCommand(tagPruneCmd)
 	rootCmd.AddCommand(tagCmd)
 }
 
@@ -60,7 +105,7 @@ func tagListRun() error {
 		return err
 	}
 
-	tags, err := s.ListTags(context.Background())
+	tags, err := s.ListTagUsage(context.Background())
 	if err != nil {
 		return err
 	}
@@ -70,11 +115,13 @@ func tagListRun() error {
 		return nil
 	}
 
-	table := ui.Table([]string{"Name", "Created"})
+	table := ui.Table([]string{"Name", "Issues", "Open", "Created"})
 	for _, t := range tags {
 		_ = table.Append([]string{
 			output.Cyan(t.Name),
-			t.CreatedAt.Format("2006-01-02"),
+			fmt.Sprint(t.Issues),
+			fmt.Sprint(t.Open),
+			displayTime(t.CreatedAt).Format("2006-01-02"),
 		})
 	}
 	_ = table.Render()
@@ -138,3 +185,132 @@ func tagDeleteRun(name string) error {
 	ui.Success("Deleted tag: %s", name)
 	return nil
 }
+
+func tagShowRun(name string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	tag, err := store.FindTag(ctx, s, name)
+	if err != nil {
+		return err
+	}
+	b, err := store.BreakdownTag(ctx, s, tag)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(ui.Out, "%s  issues: %d\n", output.Cyan(tag.Name), b.Issues)
+	if b.Issues == 0 {
+		return nil
+	}
+	table := ui.Table([]string{"By", "Value", "Issues"})
+	appendCounts := func(by string, counts map[string]int) {
+		for _, k := range slices.Sorted(maps.Keys(counts)) {
+			_ = table.Append([]string{by, k, fmt.Sprint(counts[k])})
+			by = ""
+		}
+	}
+	appendCounts("Status", stringKeys(b.ByStatus))
+	appendCounts("Priority", stringKeys(b.ByPriority))
+	appendCounts("Type", stringKeys(b.ByType))
+	appendCounts("Project", b.ByProject)
+	_ = table.Render()
+	return nil
+}
+
+// stringKeys converts a count map keyed by a string type to one keyed by string.
+func stringKeys[K ~string](counts map[K]int) map[string]int {
+	out := make(map[string]int, len(counts))
+	for k, n := range counts {
+		out[string(k)] = n
+	}
+	return out
+}
+
+func tagRenameRun(name, newName string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	tag, err := store.FindTag(ctx, s, name)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		ui.DryRunMsg("Would rename tag %s to %s", tag.Name, newName)
+		return nil
+	}
+	if err := s.RenameTag(ctx, tag.ID, newName); err != nil {
+		return err
+	}
+	ui.Success("Renamed tag %s to %s", tag.Name, output.Cyan(newName))
+	return nil
+}
+
+func tagMergeRun(name, into string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	tag, err := store.FindTag(ctx, s, name)
+	if err != nil {
+		return err
+	}
+	target, err := store.FindTag(ctx, s, into)
+	if err != nil {
+		return err
+	}
+	if tag.ID == target.ID {
+		return fmt.Errorf("cannot merge tag %s into itself", tag.Name)
+	}
+	if dryRun {
+		ui.DryRunMsg("Would move the issues tagged %s to %s and delete %s", tag.Name, target.Name, tag.Name)
+		return nil
+	}
+	if err := s.MergeTag(ctx, tag.ID, target.ID); err != nil {
+		return err
+	}
+	ui.Success("Merged tag %s into %s", tag.Name, output.Cyan(target.Name))
+	return nil
+}
+
+func tagPruneRun() error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	if dryRun {
+		usage, err := s.ListTagUsage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, t := range usage {
+			if t.Issues == 0 {
+				ui.DryRunMsg("Would delete tag: %s", t.Name)
+			}
+		}
+		return nil
+	}
+
+	deleted, err := s.DeleteUnusedTags(ctx)
+	if err != nil {
+		return err
+	}
+	if len(deleted) == 0 {
+		ui.Info("No unused tags.")
+		return nil
+	}
+	for _, t := range deleted {
+		ui.Success("Deleted tag: %s", t.Name)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/auth"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/output"
+)
+
+var tokenName string
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage project-scoped API tokens",
+	Long: `Create, list, and revoke API tokens that limit an agent to one project.
+
+A request to the REST API or MCP server carrying a token as
+"Authorization: Bearer <token>" can only read and write that project's
+issues and sessions. For the stdio MCP server, pass the token with --token
+or PM_MCP_TOKEN.
+
+Scoping is per request: requests without a token keep full access to every
+project unless api.require_auth is true, so a token only sandboxes an agent
+when the server requires one.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return tokenListRun()
+	},
+}
+
+var tokenListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List API tokens",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return tokenListRun()
+	},
+}
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create [project]",
+	Short: "Create a token scoped to a project",
+	Long:  "Create a token scoped to a project (default: the project in the current directory). The token is shown only once.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return tokenCreateRun(argOrEmpty(args))
+	},
+}
+
+var tokenRevokeCmd = &cobra.Command{
+	Use:     "revoke <id>",
+	Aliases: []string{"rm"},
+	Short:   "Revoke a token by ID or unique prefix",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return tokenRevokeRun(args[0])
+	},
+}
+
+func init() {
+	tokenCreateCmd.Flags().StringVar(&tokenName, "name", "", "Label for the token, e.g. the agent using it")
+	tokenCmd.AddCommand(tokenListCmd)
+	tokenCmd.AddCommand(tokenCreateCmd)
+	tokenCmd.AddCommand(tokenRevokeCmd)
+	rootCmd.AddCommand(tokenCmd)
+}
+
+func tokenListRun() error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	tokens, err := s.ListAPITokens(ctx)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		ui.Info("No API tokens. Use 'pm token create <project>' to create one.")
+		return nil
+	}
+
+	table := ui.Table([]string{"ID", "Name", "Project", "Created"})
+	for _, t := range tokens {
+		project := t.ProjectID
+		if p, err := s.GetProject(ctx, t.ProjectID); err == nil {
+			project = p.Name
+		}
+		_ = table.Append([]string{
+			shortID(t.ID),
+			t.Name,
+			output.Cyan(project),
+			displayTime(t.CreatedAt).Format("2006-01-02"),
+		})
+	}
+	_ = table.Render()
+	return nil
+}
+
+func tokenCreateRun(ref string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	p, err := resolveProjectOrCwd(ctx, s, ref)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		ui.DryRunMsg("Would create API token for %s", p.Name)
+		return nil
+	}
+
+	token, hash, err := auth.NewToken()
+	if err != nil {
+		return err
+	}
+	t := &models.APIToken{Name: tokenName, ProjectID: p.ID, TokenHash: hash}
+	if err := s.CreateAPIToken(ctx, t); err != nil {
+		return err
+	}
+
+	ui.Success("Created API token %s for %s", shortID(t.ID), output.Cyan(p.Name))
+	ui.Warning("Copy the token now; it cannot be shown again.")
+	fmt.Fprintln(ui.Out, token)
+	if !viper.GetBool("api.require_auth") {
+		ui.Warning("api.require_auth is off: requests without a token still have full access to every project.")
+	}
+	return nil
+}
+
+func tokenRevokeRun(ref string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	tokens, err := s.ListAPITokens(ctx)
+	if err != nil {
+		return err
+	}
+	var matches []*models.APIToken
+	for _, t := range tokens {
+		if strings.HasPrefix(t.ID, strings.ToUpper(ref)) {
+			matches = append(matches, t)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("API token not found: %s", ref)
+	case 1:
+	default:
+		return fmt.Errorf("ambiguous token ID %s matches %d tokens", ref, len(matches))
+	}
+
+	if dryRun {
+		ui.DryRunMsg("Would revoke API token %s", shortID(matches[0].ID))
+		return nil
+	}
+	if err := s.DeleteAPIToken(ctx, matches[0].ID); err != nil {
+		return err
+	}
+	ui.Success("Revoked API token %s", shortID(matches[0].ID))
+	return nil
+}
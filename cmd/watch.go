@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/joescharf/pm/internal/api"
+	"github.com/joescharf/pm/internal/output"
+)
+
+var (
+	watchMode     bool
+	watchInterval time.Duration
+)
+
+// watchGitInterval is how often --watch re-reads every project's git state
+// while it follows a server's change feed, to pick up commits and checkouts
+// made outside pm.
+const watchGitInterval = time.Minute
+
+// maxFeedWait is the longest wait the change feed accepts.
+const maxFeedWait = 60 * time.Second
+
+// addWatchFlags adds --watch and --interval to a command that prints a table.
+func addWatchFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&watchMode, "watch", false, "Redraw the table every --interval, highlighting changed cells (Ctrl-C to stop)")
+	cmd.Flags().DurationVar(&watchInterval, "interval", 5*time.Second, "How often --watch redraws")
+}
+
+// watchFrame is one drawing of a table command's output.
+type watchFrame struct {
+	Headers []string
+	Rows    [][]string // the first cell identifies the row between frames
+	Footer  func()     // prints what follows the table: warnings, or why it is empty
+}
+
+// print writes the frame as the command prints it without --watch.
+func (f *watchFrame) print() {
+	f.draw(nil)
+}
+
+// draw writes the frame, highlighting the cells changed since prev.
+func (f *watchFrame) draw(prev *watchFrame) {
+	if len(f.Rows) > 0 {
+		changed := changedCells(prev, f)
+		table := ui.Table(f.Headers)
+		for i, row := range f.Rows {
+			cells := make([]string, len(row))
+			for j, cell := range row {
+				if changed[i][j] {
+					cell = output.Highlight(cell)
+				}
+				cells[j] = cell
+			}
+			_ = table.Append(cells)
+		}
+		_ = table.Render()
+	}
+	if f.Footer != nil {
+		f.Footer()
+	}
+}
+
+// changedCells marks the cells of cur that differ from the same row of prev,
+// and every cell of rows prev did not have. Nothing is marked without prev.
+func changedCells(prev, cur *watchFrame) [][]bool {
+	changed := make([][]bool, len(cur.Rows))
+	before := make(map[string][]string)
+	if prev != nil {
+		for _, row := range prev.Rows {
+			if len(row) > 0 {
+				before[row[0]] = row
+			}
+		}
+	}
+	for i, row := range cur.Rows {
+		changed[i] = make([]bool, len(row))
+		if prev == nil || len(row) == 0 {
+			continue
+		}
+		old, ok := before[row[0]]
+		for j, cell := range row {
+			changed[i][j] = !ok || j >= len(old) || old[j] != cell
+		}
+	}
+	return changed
+}
+
+// watchRefresh says which projects' git state a frame re-reads; the rest
+// come from the previous frame.
+type watchRefresh struct {
+	All      bool
+	Projects map[string]bool
+}
+
+func (r watchRefresh) has(projectID string) bool {
+	return r.All || r.Projects[projectID]
+}
+
+// runWatch draws build's frames until interrupted. While a pm serve runs on
+// this machine, it redraws as soon as its change feed reports a write and
+// only has the projects written to re-read from git (every project once per
+// watchGitInterval); otherwise every frame re-reads everything.
+func runWatch(build func(ctx context.Context, r watchRefresh) (*watchFrame, error)) error {
+	if watchInterval < time.Second {
+		return fmt.Errorf("--interval must be at least 1s")
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	feed := localChangeFeed(ctx)
+	refresh := watchRefresh{All: true}
+	lastFull := time.Now()
+	var prev *watchFrame
+	for {
+		frame, err := build(ctx, refresh)
+		if err != nil {
+			return err
+		}
+		source := "polling"
+		if feed != nil {
+			source = "following pm serve"
+		}
+		fmt.Fprint(ui.Out, "\033[H\033[2J")
+		fmt.Fprintf(ui.Out, "Every %s, %s: %s\n\n", watchInterval, source, time.Now().Format("15:04:05"))
+		frame.draw(prev)
+		prev = frame
+
+		refresh = watchRefresh{All: true}
+		if feed == nil {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(watchInterval):
+			}
+		} else {
+			projects, err := feed.wait(ctx, watchInterval)
+			if ctx.Err() != nil {
+				return nil
+			}
+			switch {
+			case err != nil:
+				feed = nil // the server went away: poll from now on
+			case projects != nil && time.Since(lastFull) < watchGitInterval:
+				refresh = watchRefresh{Projects: projects}
+			}
+		}
+		if refresh.All {
+			lastFull = time.Now()
+		}
+	}
+}
+
+// changeFeed follows a pm server's change feed (GET /api/v1/changes).
+type changeFeed struct {
+	url    string
+	client *http.Client
+	cursor int64
+}
+
+// localChangeFeed returns the change feed of the pm serve running on this
+// machine, or nil when there is none or it does not answer, e.g. because it
+// requires an API token.
+func localChangeFeed(ctx context.Context) *changeFeed {
+	if _, running := pidFile().IsRunning(); !running {
+		return nil
+	}
+	f := newChangeFeed(serveURL(viper.GetString("bind"), viper.GetInt("port")))
+	if err := f.start(ctx); err != nil {
+		return nil
+	}
+	return f
+}
+
+func newChangeFeed(serverURL string) *changeFeed {
+	return &changeFeed{
+		url:    serverURL + "/api/v1/changes",
+		client: &http.Client{Timeout: maxFeedWait + 10*time.Second},
+	}
+}
+
+// start sets the cursor to the end of the feed.
+func (f *changeFeed) start(ctx context.Context) error {
+	resp, err := f.get(ctx, url.Values{})
+	if err != nil {
+		return err
+	}
+	f.cursor = resp.Cursor
+	return nil
+}
+
+// wait waits up to d for changes after the cursor and returns the IDs of the
+// projects they belong to, or nil when the feed was reset and everything
+// should be re-read.
+func (f *changeFeed) wait(ctx context.Context, d time.Duration) (map[string]bool, error) {
+	projects := make(map[string]bool)
+	q := url.Values{"wait": {min(d, maxFeedWait).String()}}
+	for {
+		q.Set("since", strconv.FormatInt(f.cursor, 10))
+		resp, err := f.get(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		f.cursor = resp.Cursor
+		if resp.Reset {
+			return nil, nil
+		}
+		for _, c := range resp.Changes {
+			if c.ProjectID == "" {
+				return nil, nil // cascaded deletes do not say whose
+			}
+			projects[c.ProjectID] = true
+		}
+		if !resp.HasMore {
+			return projects, nil
+		}
+		q.Del("wait")
+	}
+}
+
+func (f *changeFeed) get(ctx context.Context, q url.Values) (*api.ChangesResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("change feed: %s", resp.Status)
+	}
+	var out api.ChangesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("change feed: %w", err)
+	}
+	return &out, nil
+}
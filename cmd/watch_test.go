@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/api"
+	"github.com/joescharf/pm/internal/models"
+)
+
+func TestChangedCells(t *testing.T) {
+	prev := &watchFrame{Rows: [][]string{
+		{"api", "v1.0.0", "clean"},
+		{"web", "v2.0.0", "clean"},
+	}}
+	cur := &watchFrame{Rows: [][]string{
+		{"web", "v2.0.0", "dirty"},
+		{"cli", "-", "clean"},
+		{"api", "v1.0.0", "clean"},
+	}}
+
+	assert.Equal(t, [][]bool{
+		{false, false, true},
+		{true, true, true},
+		{false, false, false},
+	}, changedCells(prev, cur), "rows are matched by their first cell")
+	assert.Equal(t, [][]bool{{false, false, false}, {false, false, false}}, changedCells(nil, prev), "the first frame has nothing to compare with")
+}
+
+func TestChangeFeed(t *testing.T) {
+	var queries []string
+	pages := []api.ChangesResponse{
+		{Cursor: 10},
+		{Changes: []*models.Change{{Seq: 11, ProjectID: "p1"}, {Seq: 12, ProjectID: "p2"}}, Cursor: 12, HasMore: true},
+		{Changes: []*models.Change{{Seq: 13, ProjectID: "p1"}}, Cursor: 13},
+		{Changes: []*models.Change{}, Cursor: 13},
+		{Changes: []*models.Change{}, Cursor: 20, Reset: true},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		_ = json.NewEncoder(w).Encode(pages[0])
+		pages = pages[1:]
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	feed := newChangeFeed(srv.URL)
+	require.NoError(t, feed.start(ctx))
+	assert.Equal(t, int64(10), feed.cursor)
+
+	projects, err := feed.wait(ctx, 5*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"p1": true, "p2": true}, projects)
+	assert.Equal(t, []string{"", "since=10&wait=5s", "since=12"}, queries, "further pages are not waited for")
+
+	projects, err = feed.wait(ctx, 5*time.Second)
+	require.NoError(t, err)
+	assert.Empty(t, projects)
+	assert.NotNil(t, projects, "no changes is not a reset")
+
+	projects, err = feed.wait(ctx, 5*time.Second)
+	require.NoError(t, err)
+	assert.Nil(t, projects)
+	assert.Equal(t, int64(20), feed.cursor)
+}
+
+func TestChangeFeed_Unauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	assert.Error(t, newChangeFeed(srv.URL).start(context.Background()))
+}
@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// CascadeStore is the subset of store.Store needed to cascade an event to
+// its issue.
+type CascadeStore interface {
+	GetProject(ctx context.Context, id string) (*models.Project, error)
+	UpdateIssue(ctx context.Context, issue *models.Issue) error
+}
+
+// CascadeIssue moves issue to the status its project's cascade rules map
+// event to (models.DefaultCascade unless the project overrides it). Session
+// events only move issues in progress, so work already reviewed or closed by
+// hand stays put; review events move the issue whatever its status. It
+// reports whether the issue changed.
+func CascadeIssue(ctx context.Context, s CascadeStore, issue *models.Issue, event models.CascadeEvent) (bool, error) {
+	rules := models.Cascade(nil)
+	if p, err := s.GetProject(ctx, issue.ProjectID); err == nil {
+		rules = p.CascadeRules()
+	}
+	target := rules.Status(event)
+	if target == "" || target == issue.Status {
+		return false, nil
+	}
+	if (event == models.CascadeCompleted || event == models.CascadeAbandoned) && issue.Status != models.IssueStatusInProgress {
+		return false, nil
+	}
+
+	issue.Status = target
+	if target == models.IssueStatusClosed {
+		now := time.Now().UTC()
+		issue.ClosedAt = &now
+	} else {
+		issue.ClosedAt = nil
+	}
+	if err := s.UpdateIssue(ctx, issue); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+func TestCascadeIssue_Defaults(t *testing.T) {
+	store := newMockStore()
+	issue := &models.Issue{ID: "issue-1", ProjectID: "proj-1", Status: models.IssueStatusInProgress}
+	store.issues[issue.ID] = issue
+	ctx := context.Background()
+
+	changed, err := CascadeIssue(ctx, store, issue, models.CascadeCompleted)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, models.IssueStatusDone, issue.Status)
+
+	// Session events leave issues that are not in progress alone.
+	changed, err = CascadeIssue(ctx, store, issue, models.CascadeAbandoned)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, models.IssueStatusDone, issue.Status)
+
+	changed, err = CascadeIssue(ctx, store, issue, models.CascadeReviewPass)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, models.IssueStatusClosed, issue.Status)
+	assert.NotNil(t, issue.ClosedAt)
+
+	changed, err = CascadeIssue(ctx, store, issue, models.CascadeReviewFail)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, models.IssueStatusInProgress, issue.Status)
+	assert.Nil(t, issue.ClosedAt)
+}
+
+func TestCascadeIssue_ProjectRules(t *testing.T) {
+	store := newMockStore()
+	store.projects = map[string]*models.Project{
+		"proj-1": {ID: "proj-1", Cascade: "completed=in_review,abandoned=none"},
+	}
+	issue := &models.Issue{ID: "issue-1", ProjectID: "proj-1", Status: models.IssueStatusInProgress}
+	store.issues[issue.ID] = issue
+	ctx := context.Background()
+
+	changed, err := CascadeIssue(ctx, store, issue, models.CascadeAbandoned)
+	require.NoError(t, err)
+	assert.False(t, changed, "none leaves the issue alone")
+	assert.Equal(t, models.IssueStatusInProgress, issue.Status)
+
+	changed, err = CascadeIssue(ctx, store, issue, models.CascadeCompleted)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, models.IssueStatusInReview, store.issues["issue-1"].Status)
+
+	changed, err = CascadeIssue(ctx, store, issue, models.CascadeReviewPass)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, models.IssueStatusClosed, issue.Status)
+}
+
+func TestCloseSession_CascadeRules(t *testing.T) {
+	store := newMockStore()
+	store.projects = map[string]*models.Project{
+		"proj-1": {ID: "proj-1", Cascade: "completed=in_review"},
+	}
+	store.sessions["sess-1"] = &models.AgentSession{ID: "sess-1", ProjectID: "proj-1", IssueID: "issue-1", Status: models.SessionStatusActive}
+	store.issues["issue-1"] = &models.Issue{ID: "issue-1", ProjectID: "proj-1", Status: models.IssueStatusInProgress}
+
+	_, err := CloseSession(context.Background(), store, "sess-1", models.SessionStatusCompleted)
+	require.NoError(t, err)
+	assert.Equal(t, models.IssueStatusInReview, store.issues["issue-1"].Status)
+}
@@ -0,0 +1,207 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/joescharf/pm/internal/commitmsg"
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/models"
+)
+
+// CloseCheckWarning describes one reason a session may not be safe to close.
+type CloseCheckWarning struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// CloseCheck summarizes the worktree state that matters when closing a session.
+type CloseCheck struct {
+	SessionID      string              `json:"session_id"`
+	WorktreeExists bool                `json:"worktree_exists"`
+	IsDirty        bool                `json:"is_dirty"`
+	AheadCount     int                 `json:"ahead_count"`
+	BehindCount    int                 `json:"behind_count"`
+	ConflictState  string              `json:"conflict_state"`
+	Branch         string              `json:"branch"`
+	BaseBranch     string              `json:"base_branch"`
+	ReadyToClose   bool                `json:"ready_to_close"`
+	Warnings       []CloseCheckWarning `json:"warnings"`
+
+	// Commits on the branch that break the project's commit conventions
+	CommitViolations []commitmsg.Violation `json:"commit_violations,omitempty"`
+
+	// Uncommitted files, when the git client can list them
+	DirtyFiles []git.FileChange `json:"dirty_files,omitempty"`
+	// What the uncommitted files are: DirtyLockfileOnly or DirtySource
+	DirtyKind string `json:"dirty_kind,omitempty"`
+}
+
+// Kinds of uncommitted changes in a CloseCheck.
+const (
+	DirtyLockfileOnly = "lockfile_only" // only dependency lockfiles, often a tool's side effect
+	DirtySource       = "source"        // anything else: work that would be left behind
+)
+
+// DirtyFileLister lists a worktree's uncommitted files.
+// *git.RealClient satisfies this interface.
+type DirtyFileLister interface {
+	DirtyFiles(ctx context.Context, path string) ([]git.FileChange, error)
+}
+
+// lockfiles are the dependency lockfiles package managers rewrite on their own.
+var lockfiles = map[string]bool{
+	"go.sum": true, "go.work.sum": true,
+	"package-lock.json": true, "npm-shrinkwrap.json": true, "yarn.lock": true, "pnpm-lock.yaml": true, "bun.lockb": true, "bun.lock": true,
+	"Cargo.lock": true, "Gemfile.lock": true, "poetry.lock": true, "Pipfile.lock": true, "uv.lock": true, "pdm.lock": true,
+	"composer.lock": true, "mix.lock": true, "Podfile.lock": true, "Package.resolved": true, "pubspec.lock": true, "flake.lock": true,
+}
+
+// IsLockfile reports whether file is a dependency lockfile.
+func IsLockfile(file string) bool {
+	return lockfiles[path.Base(file)]
+}
+
+// dirtyKind classifies a worktree's uncommitted files.
+func dirtyKind(files []git.FileChange) string {
+	if len(files) == 0 {
+		return ""
+	}
+	for _, f := range files {
+		if !IsLockfile(f.Path) {
+			return DirtySource
+		}
+	}
+	return DirtyLockfileOnly
+}
+
+// dirtyMessage describes uncommitted files for a close-check warning.
+func dirtyMessage(files []git.FileChange, kind string) string {
+	if len(files) == 0 {
+		return "Worktree has uncommitted changes"
+	}
+	names := make([]string, 0, 3)
+	var added, deleted int
+	for _, f := range files {
+		if len(names) < 3 {
+			names = append(names, f.Path)
+		}
+		added += f.Additions
+		deleted += f.Deletions
+	}
+	list := strings.Join(names, ", ")
+	if len(files) > len(names) {
+		list += fmt.Sprintf(" and %d more", len(files)-len(names))
+	}
+	if kind == DirtyLockfileOnly {
+		return fmt.Sprintf("Only lockfiles have uncommitted changes (%s), likely left by a dependency tool", list)
+	}
+	return fmt.Sprintf("Worktree has %d uncommitted file(s), +%d/-%d lines: %s", len(files), added, deleted, list)
+}
+
+// CheckClose inspects a session's worktree and reports whether it is clean
+// enough to close as completed, including whether the branch's commits follow
+// the project's commit conventions (p may be nil to skip that). When gc is a
+// DirtyFileLister, a dirty worktree's files are listed and classified. Git
+// errors are treated as "no data".
+func CheckClose(ctx context.Context, sess *models.AgentSession, p *models.Project, gc git.Client) *CloseCheck {
+	check := &CloseCheck{
+		SessionID:     sess.ID,
+		Branch:        sess.Branch,
+		BaseBranch:    "main",
+		ConflictState: string(sess.ConflictState),
+	}
+
+	if sess.WorktreePath != "" && gc != nil {
+		if _, err := os.Stat(sess.WorktreePath); err == nil {
+			check.WorktreeExists = true
+
+			if dirty, err := gc.IsDirty(ctx, sess.WorktreePath); err == nil {
+				check.IsDirty = dirty
+			}
+			if lister, ok := gc.(DirtyFileLister); ok && check.IsDirty {
+				if files, err := lister.DirtyFiles(ctx, sess.WorktreePath); err == nil {
+					check.DirtyFiles = files
+					check.DirtyKind = dirtyKind(files)
+				}
+			}
+			if ahead, behind, err := gc.AheadBehind(ctx, sess.WorktreePath, check.BaseBranch); err == nil {
+				check.AheadCount = ahead
+				check.BehindCount = behind
+			}
+			if conv := commitmsg.ForProject(p); conv.Enabled() {
+				if msgs, err := gc.CommitMessagesSince(ctx, sess.WorktreePath, check.BaseBranch); err == nil {
+					check.CommitViolations = conv.CheckAll(msgs)
+				}
+			}
+		}
+	}
+
+	hasConflict := sess.ConflictState != "" && sess.ConflictState != models.ConflictStateNone
+
+	if check.IsDirty {
+		check.Warnings = append(check.Warnings, CloseCheckWarning{
+			Type:    "dirty",
+			Message: dirtyMessage(check.DirtyFiles, check.DirtyKind),
+		})
+	}
+	if check.AheadCount > 0 {
+		check.Warnings = append(check.Warnings, CloseCheckWarning{
+			Type:    "unmerged",
+			Message: fmt.Sprintf("%d commit(s) not merged to %s", check.AheadCount, check.BaseBranch),
+		})
+	}
+	if check.BehindCount > 0 {
+		check.Warnings = append(check.Warnings, CloseCheckWarning{
+			Type:    "behind",
+			Message: fmt.Sprintf("%d commit(s) behind %s", check.BehindCount, check.BaseBranch),
+		})
+	}
+	if len(check.CommitViolations) > 0 {
+		check.Warnings = append(check.Warnings, CloseCheckWarning{
+			Type:    "commit_message",
+			Message: commitmsg.Summarize(check.CommitViolations),
+		})
+	}
+	if hasConflict {
+		check.Warnings = append(check.Warnings, CloseCheckWarning{
+			Type:    "conflict",
+			Message: fmt.Sprintf("Session has %s", sess.ConflictState),
+		})
+	}
+
+	check.ReadyToClose = !check.IsDirty && check.AheadCount == 0 && !hasConflict && len(check.CommitViolations) == 0
+
+	if check.Warnings == nil {
+		check.Warnings = []CloseCheckWarning{}
+	}
+	return check
+}
+
+// Exit codes of `pm agent close --check-only`, so CI jobs and scripts can gate
+// on a session's state without parsing text. 1 is left for ordinary errors.
+const (
+	CloseExitClean    = 0
+	CloseExitDirty    = 2 // uncommitted changes in the worktree
+	CloseExitUnmerged = 3 // commits not merged to the base branch
+	CloseExitConflict = 4 // an unresolved sync or merge conflict
+)
+
+// ExitCode maps the check to a CloseExit code. When several apply the most
+// severe wins: a conflict over unmerged commits over uncommitted changes.
+// Commit convention violations and being behind the base branch are reported
+// in the warnings but leave the code alone.
+func (c *CloseCheck) ExitCode() int {
+	switch {
+	case c.ConflictState != "" && c.ConflictState != string(models.ConflictStateNone):
+		return CloseExitConflict
+	case c.AheadCount > 0:
+		return CloseExitUnmerged
+	case c.IsDirty:
+		return CloseExitDirty
+	}
+	return CloseExitClean
+}
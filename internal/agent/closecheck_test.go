@@ -0,0 +1,32 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+func TestCloseCheck_ExitCode(t *testing.T) {
+	conflict := string(models.ConflictStateMergeConflict)
+	tests := []struct {
+		name  string
+		check CloseCheck
+		want  int
+	}{
+		{"clean", CloseCheck{}, CloseExitClean},
+		{"no conflict", CloseCheck{ConflictState: string(models.ConflictStateNone)}, CloseExitClean},
+		{"behind only", CloseCheck{BehindCount: 3}, CloseExitClean},
+		{"dirty", CloseCheck{IsDirty: true}, CloseExitDirty},
+		{"unmerged", CloseCheck{AheadCount: 1}, CloseExitUnmerged},
+		{"unmerged and dirty", CloseCheck{AheadCount: 1, IsDirty: true}, CloseExitUnmerged},
+		{"conflict", CloseCheck{ConflictState: conflict}, CloseExitConflict},
+		{"conflict, unmerged and dirty", CloseCheck{ConflictState: conflict, AheadCount: 2, IsDirty: true}, CloseExitConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.check.ExitCode())
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// CodeStore is the subset of store.Store needed to trace an issue's code.
+type CodeStore interface {
+	GetIssue(ctx context.Context, id string) (*models.Issue, error)
+	GetProject(ctx context.Context, id string) (*models.Project, error)
+	ListAgentSessionsFiltered(ctx context.Context, filter store.SessionListFilter) ([]*models.AgentSession, error)
+	ListIssueClosures(ctx context.Context, projectID, issueID string) ([]*models.IssueClosure, error)
+}
+
+// TagFinder finds the tags that contain a commit. *git.RealClient satisfies
+// this interface.
+type TagFinder interface {
+	TagsContaining(ctx context.Context, path, commit string) ([]string, error)
+}
+
+// BranchPRLister lists the pull requests opened from a branch.
+// *git.RealGitHubClient satisfies this interface.
+type BranchPRLister interface {
+	BranchPRs(owner, repo, branch string) ([]git.PullRequest, error)
+}
+
+// CodeCommit is one commit that carried an issue's work.
+type CodeCommit struct {
+	Hash    string    `json:"hash"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+}
+
+// BranchCode is the code of one session of an issue.
+type BranchCode struct {
+	SessionID string     `json:"session_id"`
+	Branch    string     `json:"branch"`
+	Status    string     `json:"status"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	// Commits on the branch that are not on main; once the branch is gone,
+	// the commits its merge brought into the base.
+	Commits     []CodeCommit `json:"commits"`
+	MergeCommit string       `json:"merge_commit,omitempty"`
+	// MergedRange is the first-parent range of the merge ("<merge>^..<merge>").
+	MergedRange  string            `json:"merged_range,omitempty"`
+	Releases     []string          `json:"releases,omitempty"` // tags containing the merge, oldest first
+	PullRequests []git.PullRequest `json:"pull_requests,omitempty"`
+}
+
+// IssueCode traces an issue to the code that implemented it: the branches
+// and commits of all its sessions, their pull requests and merges, the
+// commits and pull requests that closed it, and the releases that shipped it.
+type IssueCode struct {
+	IssueID      string                 `json:"issue_id"`
+	IssueTitle   string                 `json:"issue_title"`
+	IssueStatus  string                 `json:"issue_status"`
+	ProjectName  string                 `json:"project_name"`
+	Branches     []BranchCode           `json:"branches"` // oldest session first
+	PullRequests []git.PullRequest      `json:"pull_requests"`
+	Closures     []*models.IssueClosure `json:"closures"`
+	// ShippedIn is the first release containing the issue's latest merged
+	// work ("" while it is unreleased or nothing was merged).
+	ShippedIn string `json:"shipped_in,omitempty"`
+}
+
+// BuildIssueCode traces an issue's code. Git and GitHub lookups are best
+// effort: gc, tags and prs may be nil, and failures leave their parts empty.
+func BuildIssueCode(ctx context.Context, s CodeStore, gc git.Client, tags TagFinder, prs BranchPRLister, issueID string) (*IssueCode, error) {
+	issue, err := s.GetIssue(ctx, issueID)
+	if err != nil {
+		return nil, err
+	}
+	project, err := s.GetProject(ctx, issue.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.ListAgentSessionsFiltered(ctx, store.SessionListFilter{IssueID: issue.ID})
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	closures, err := s.ListIssueClosures(ctx, project.ID, issue.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list closures: %w", err)
+	}
+
+	code := &IssueCode{
+		IssueID:      issue.ID,
+		IssueTitle:   issue.Title,
+		IssueStatus:  string(issue.Status),
+		ProjectName:  project.Name,
+		Branches:     []BranchCode{},
+		PullRequests: []git.PullRequest{},
+		Closures:     closures,
+	}
+	if code.Closures == nil {
+		code.Closures = []*models.IssueClosure{}
+	}
+	owner, repo, repoErr := git.ExtractOwnerRepo(project.RepoURL)
+
+	// The issue's latest merged work: a session merge or a closing commit
+	var latest struct {
+		at     time.Time
+		tags   []string
+		merged bool
+	}
+	shipped := func(at time.Time, tags []string) {
+		if !latest.merged || at.After(latest.at) {
+			latest.at, latest.tags, latest.merged = at, tags, true
+		}
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].StartedAt.Before(sessions[j].StartedAt) })
+	for _, sess := range sessions {
+		b := BranchCode{
+			SessionID:   sess.ID,
+			Branch:      sess.Branch,
+			Status:      string(sess.Status),
+			StartedAt:   sess.StartedAt,
+			EndedAt:     sess.EndedAt,
+			Commits:     []CodeCommit{},
+			MergeCommit: sess.MergeCommit,
+		}
+		if gc != nil && sess.Branch != "" {
+			commits, _ := gc.CommitLog(ctx, project.Path, "main", sess.Branch)
+			if len(commits) == 0 && sess.MergeCommit != "" {
+				commits, _ = gc.CommitLog(ctx, project.Path, sess.MergeCommit+"^", sess.MergeCommit)
+			}
+			for _, c := range commits {
+				b.Commits = append(b.Commits, CodeCommit{Hash: c.Hash, Date: c.Date, Subject: c.Subject})
+			}
+		}
+		if sess.MergeCommit != "" {
+			b.MergedRange = sess.MergeCommit + "^.." + sess.MergeCommit
+			if tags != nil {
+				b.Releases, _ = tags.TagsContaining(ctx, project.Path, sess.MergeCommit)
+			}
+			at := sess.StartedAt
+			if sess.EndedAt != nil {
+				at = *sess.EndedAt
+			}
+			shipped(at, b.Releases)
+		}
+		if prs != nil && repoErr == nil && sess.Branch != "" && !sess.Inline {
+			b.PullRequests, _ = prs.BranchPRs(owner, repo, sess.Branch)
+			for _, pr := range b.PullRequests {
+				code.addPR(pr)
+			}
+		}
+		code.Branches = append(code.Branches, b)
+	}
+
+	for _, c := range closures {
+		if c.RevertedAt != nil {
+			continue
+		}
+		switch c.Source {
+		case models.ClosureSourcePR:
+			if n, err := strconv.Atoi(strings.TrimPrefix(c.Ref, "#")); err == nil {
+				code.addPR(git.PullRequest{Number: n, Title: c.Message, State: "MERGED"})
+			}
+		case models.ClosureSourceCommit:
+			var releases []string
+			if tags != nil {
+				releases, _ = tags.TagsContaining(ctx, project.Path, c.Ref)
+			}
+			shipped(c.CreatedAt, releases)
+		}
+	}
+
+	if len(latest.tags) > 0 {
+		code.ShippedIn = latest.tags[0]
+	}
+	return code, nil
+}
+
+// addPR adds a pull request unless one with its number is already listed.
+func (c *IssueCode) addPR(pr git.PullRequest) {
+	for _, p := range c.PullRequests {
+		if p.Number == pr.Number {
+			return
+		}
+	}
+	c.PullRequests = append(c.PullRequests, pr)
+}
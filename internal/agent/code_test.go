@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+type fakeTagFinder map[string][]string
+
+func (f fakeTagFinder) TagsContaining(_ context.Context, path, commit string) ([]string, error) {
+	return f[commit], nil
+}
+
+type fakeBranchPRs map[string][]git.PullRequest
+
+func (f fakeBranchPRs) BranchPRs(owner, repo, branch string) ([]git.PullRequest, error) {
+	return f[branch], nil
+}
+
+func TestBuildIssueCode(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: t.TempDir(), RepoURL: "https://github.com/acme/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Add login", Status: models.IssueStatusOpen}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	other := &models.Issue{ProjectID: p.ID, Title: "Other", Status: models.IssueStatusOpen}
+	require.NoError(t, s.CreateIssue(ctx, other))
+
+	start := time.Now().UTC().Add(-time.Hour)
+	first := &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "feature/login", Status: models.SessionStatusAbandoned, StartedAt: start}
+	require.NoError(t, s.CreateAgentSession(ctx, first))
+	ended := start.Add(30 * time.Minute)
+	second := &models.AgentSession{
+		ProjectID: p.ID, IssueID: issue.ID, Branch: "feature/login-2", Status: models.SessionStatusCompleted,
+		StartedAt: start.Add(10 * time.Minute), EndedAt: &ended, MergeCommit: "m1",
+	}
+	require.NoError(t, s.CreateAgentSession(ctx, second))
+	require.NoError(t, s.CreateAgentSession(ctx, &models.AgentSession{ProjectID: p.ID, IssueID: other.ID, Branch: "feature/other", Status: models.SessionStatusActive}))
+
+	_, err := s.RecordIssueClosure(ctx, &models.IssueClosure{
+		IssueID: issue.ID, ProjectID: p.ID, Source: models.ClosureSourcePR, Ref: "#7", Message: "Login",
+		FromStatus: models.IssueStatusOpen, ToStatus: models.IssueStatusDone,
+	})
+	require.NoError(t, err)
+
+	tags := fakeTagFinder{"m1": {"v1.2.0", "v1.3.0"}}
+	prs := fakeBranchPRs{"feature/login-2": {{Number: 7, Title: "Login", State: "MERGED", Branch: "feature/login-2"}}}
+	code, err := BuildIssueCode(ctx, s, nil, tags, prs, issue.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Add login", code.IssueTitle)
+	assert.Equal(t, "api", code.ProjectName)
+	require.Len(t, code.Branches, 2)
+	assert.Equal(t, "feature/login", code.Branches[0].Branch)
+	assert.Empty(t, code.Branches[0].MergedRange)
+	assert.Equal(t, "m1^..m1", code.Branches[1].MergedRange)
+	assert.Equal(t, []string{"v1.2.0", "v1.3.0"}, code.Branches[1].Releases)
+	require.Len(t, code.PullRequests, 1, "the closing PR is the branch's PR")
+	assert.Equal(t, 7, code.PullRequests[0].Number)
+	assert.Len(t, code.Closures, 1)
+	assert.Equal(t, "v1.2.0", code.ShippedIn)
+
+	_, err = BuildIssueCode(ctx, s, nil, nil, nil, "missing")
+	assert.Error(t, err)
+}
+
+func TestBuildIssueCode_Unreleased(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: t.TempDir()}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Fix crash", Status: models.IssueStatusOpen}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	require.NoError(t, s.CreateAgentSession(ctx, &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "fix/crash", Status: models.SessionStatusCompleted, MergeCommit: "m2"}))
+
+	code, err := BuildIssueCode(ctx, s, nil, fakeTagFinder{}, nil, issue.ID)
+	require.NoError(t, err)
+	require.Len(t, code.Branches, 1)
+	assert.Equal(t, "m2", code.Branches[0].MergeCommit)
+	assert.Empty(t, code.ShippedIn)
+	assert.Empty(t, code.PullRequests)
+}
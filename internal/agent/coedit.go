@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/models"
+)
+
+// Fingerprinter computes a content fingerprint of a worktree.
+type Fingerprinter interface {
+	WorktreeFingerprint(ctx context.Context, path string) (string, error)
+}
+
+// CoEditCheck reports whether a session's worktree changed while no session
+// was running in it, e.g. because a human edited files while it was idle.
+type CoEditCheck struct {
+	Changed        bool   `json:"changed"`
+	Dirty          bool   `json:"dirty"`
+	Warning        string `json:"warning,omitempty"`
+	SnapshotCommit string `json:"snapshot_commit,omitempty"`
+}
+
+// worktreeFingerprint returns the current fingerprint of sess's worktree, or
+// "" if the worktree is missing or git fails. Inline sessions share the repo
+// with ordinary work, so they are never fingerprinted.
+func worktreeFingerprint(ctx context.Context, sess *models.AgentSession, f Fingerprinter) string {
+	if sess.WorktreePath == "" || sess.Inline || f == nil {
+		return ""
+	}
+	if _, err := os.Stat(sess.WorktreePath); err != nil {
+		return ""
+	}
+	fp, err := f.WorktreeFingerprint(ctx, sess.WorktreePath)
+	if err != nil {
+		return ""
+	}
+	return fp
+}
+
+// RecordWorktreeFingerprint stores the current fingerprint of the session's
+// worktree so a later resume can detect edits made in between. It is a no-op
+// when the worktree is missing.
+func RecordWorktreeFingerprint(ctx context.Context, s SessionStore, sess *models.AgentSession, f Fingerprinter) error {
+	fp := worktreeFingerprint(ctx, sess, f)
+	if fp == "" || fp == sess.WorktreeFingerprint {
+		return nil
+	}
+	sess.WorktreeFingerprint = fp
+	if err := s.UpdateAgentSession(ctx, sess); err != nil {
+		return fmt.Errorf("record worktree fingerprint: %w", err)
+	}
+	return nil
+}
+
+// CheckCoEdit compares the session's recorded fingerprint with the worktree's
+// current contents. Returns nil when nothing was recorded or nothing changed.
+func CheckCoEdit(ctx context.Context, sess *models.AgentSession, gc git.Client) *CoEditCheck {
+	if sess.WorktreeFingerprint == "" {
+		return nil
+	}
+	fp := worktreeFingerprint(ctx, sess, gc)
+	if fp == "" || fp == sess.WorktreeFingerprint {
+		return nil
+	}
+
+	check := &CoEditCheck{Changed: true}
+	if dirty, err := gc.IsDirty(ctx, sess.WorktreePath); err == nil {
+		check.Dirty = dirty
+	}
+	if check.Dirty {
+		check.Warning = "Worktree has uncommitted changes made outside a recorded session; consider snapshotting them as a commit before resuming"
+	} else {
+		check.Warning = "Worktree changed outside a recorded session (new commits since it went idle)"
+	}
+	return check
+}
+
+// SnapshotHumanEdits commits all uncommitted changes in the session's worktree
+// so they are kept separate from the agent's own commits.
+func SnapshotHumanEdits(ctx context.Context, sess *models.AgentSession, gc git.Client) (string, error) {
+	msg := fmt.Sprintf("Snapshot edits made outside pm session %s", shortSessionID(sess.ID))
+	hash, err := gc.CommitAll(ctx, sess.WorktreePath, msg)
+	if err != nil {
+		return "", fmt.Errorf("snapshot human edits: %w", err)
+	}
+	return hash, nil
+}
+
+// PrepareResume runs the co-editing guard for a session about to be resumed.
+// If snapshot is set and the worktree has uncommitted outside edits, they are
+// committed first. The recorded fingerprint is cleared either way since the
+// session is becoming active; the caller persists the session.
+func PrepareResume(ctx context.Context, sess *models.AgentSession, gc git.Client, snapshot bool) (*CoEditCheck, error) {
+	check := CheckCoEdit(ctx, sess, gc)
+	if check != nil && check.Dirty && snapshot {
+		hash, err := SnapshotHumanEdits(ctx, sess, gc)
+		if err != nil {
+			return check, err
+		}
+		check.SnapshotCommit = hash
+		check.Dirty = false
+	}
+	sess.WorktreeFingerprint = ""
+	return check, nil
+}
+
+func shortSessionID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
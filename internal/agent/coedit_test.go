@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dirtyGitClient reports the worktree as dirty.
+type dirtyGitClient struct{ mockGitClient }
+
+func (m *dirtyGitClient) IsDirty(_ context.Context, path string) (bool, error) { return true, nil }
+
+func TestRecordWorktreeFingerprint(t *testing.T) {
+	session := &models.AgentSession{ID: "sess-1", WorktreePath: t.TempDir(), Status: models.SessionStatusIdle}
+	ms := &mockSessionStore{sessions: map[string]*models.AgentSession{"sess-1": session}}
+
+	require.NoError(t, RecordWorktreeFingerprint(context.Background(), ms, session, &mockGitClient{fingerprint: "fp1"}))
+	assert.Equal(t, "fp1", ms.sessions["sess-1"].WorktreeFingerprint)
+
+	missing := &models.AgentSession{ID: "sess-2", WorktreePath: "/nonexistent/path"}
+	require.NoError(t, RecordWorktreeFingerprint(context.Background(), ms, missing, &mockGitClient{fingerprint: "fp1"}))
+	assert.Empty(t, missing.WorktreeFingerprint)
+}
+
+func TestCheckCoEdit(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// Nothing recorded: no check.
+	assert.Nil(t, CheckCoEdit(ctx, &models.AgentSession{WorktreePath: dir}, &mockGitClient{fingerprint: "fp2"}))
+
+	// Unchanged fingerprint: no check.
+	assert.Nil(t, CheckCoEdit(ctx, &models.AgentSession{WorktreePath: dir, WorktreeFingerprint: "fp1"}, &mockGitClient{fingerprint: "fp1"}))
+
+	// Changed with new commits only.
+	check := CheckCoEdit(ctx, &models.AgentSession{WorktreePath: dir, WorktreeFingerprint: "fp1"}, &mockGitClient{fingerprint: "fp2"})
+	require.NotNil(t, check)
+	assert.True(t, check.Changed)
+	assert.False(t, check.Dirty)
+	assert.Contains(t, check.Warning, "new commits")
+
+	// Changed with uncommitted edits.
+	check = CheckCoEdit(ctx, &models.AgentSession{WorktreePath: dir, WorktreeFingerprint: "fp1"}, &dirtyGitClient{mockGitClient{fingerprint: "fp2"}})
+	require.NotNil(t, check)
+	assert.True(t, check.Dirty)
+	assert.Contains(t, check.Warning, "snapshot")
+}
+
+func TestPrepareResume_Snapshot(t *testing.T) {
+	ctx := context.Background()
+	gc := &dirtyGitClient{mockGitClient{fingerprint: "fp2", lastCommitHash: "def456"}}
+	session := &models.AgentSession{ID: "sess-1", WorktreePath: t.TempDir(), WorktreeFingerprint: "fp1"}
+
+	check, err := PrepareResume(ctx, session, gc, true)
+	require.NoError(t, err)
+	require.NotNil(t, check)
+	assert.Equal(t, "def456", check.SnapshotCommit)
+	assert.False(t, check.Dirty)
+	require.Len(t, gc.committed, 1)
+	assert.Contains(t, gc.committed[0], "sess-1")
+	assert.Empty(t, session.WorktreeFingerprint, "fingerprint is cleared on resume")
+}
+
+func TestPrepareResume_NoSnapshot(t *testing.T) {
+	ctx := context.Background()
+	gc := &dirtyGitClient{mockGitClient{fingerprint: "fp2"}}
+	session := &models.AgentSession{ID: "sess-1", WorktreePath: t.TempDir(), WorktreeFingerprint: "fp1"}
+
+	check, err := PrepareResume(ctx, session, gc, false)
+	require.NoError(t, err)
+	require.NotNil(t, check)
+	assert.True(t, check.Dirty)
+	assert.Empty(t, check.SnapshotCommit)
+	assert.Empty(t, gc.committed)
+}
+
+func TestReconcileSessions_RecordsFingerprintOnIdle(t *testing.T) {
+	session := &models.AgentSession{ID: "sess-1", WorktreePath: t.TempDir(), Status: models.SessionStatusActive}
+	ms := &mockSessionStore{sessions: map[string]*models.AgentSession{"sess-1": session}}
+
+	cleaned := ReconcileSessions(context.Background(), ms, []*models.AgentSession{session},
+		WithProcessDetector(&mockProcessDetector{}), WithFingerprinter(&mockGitClient{fingerprint: "fp1"}))
+	assert.Equal(t, 1, cleaned)
+	assert.Equal(t, models.SessionStatusIdle, ms.sessions["sess-1"].Status)
+	assert.Equal(t, "fp1", ms.sessions["sess-1"].WorktreeFingerprint)
+}
@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/joescharf/pm/internal/store"
+)
+
+// FocusStats adds up the focus and idle time of one group of sessions.
+type FocusStats struct {
+	IssueID      string  `json:"issue_id"`
+	Title        string  `json:"title,omitempty"`
+	ProjectID    string  `json:"project_id,omitempty"`
+	Sessions     int     `json:"sessions"`
+	FocusSeconds float64 `json:"focus_seconds"`
+	IdleSeconds  float64 `json:"idle_seconds"`
+	// Share of the sessions' wall-clock time that was focus time, from 0 to 1
+	FocusShare float64 `json:"focus_share"`
+}
+
+func (f *FocusStats) add(focus, idle time.Duration) {
+	f.Sessions++
+	f.FocusSeconds += focus.Seconds()
+	f.IdleSeconds += idle.Seconds()
+	if total := f.FocusSeconds + f.IdleSeconds; total > 0 {
+		f.FocusShare = f.FocusSeconds / total
+	}
+}
+
+// FocusReport breaks the focus time of sessions down by issue.
+type FocusReport struct {
+	Total   FocusStats   `json:"total"`
+	ByIssue []FocusStats `json:"by_issue"`
+}
+
+// BuildFocusReport adds up the focus and idle time of the sessions matching
+// filter per issue, counting open sessions up to now. Sessions without an
+// issue are grouped under the issue ID "". Issues are sorted by focus time,
+// then ID.
+func BuildFocusReport(ctx context.Context, s OutcomeStore, filter store.SessionListFilter, now time.Time) (*FocusReport, error) {
+	sessions, err := s.ListAgentSessionsFiltered(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &FocusReport{}
+	byIssue := make(map[string]*FocusStats)
+	for _, sess := range sessions {
+		focus, idle := sess.FocusTime(), sess.IdleTime(now)
+		report.Total.add(focus, idle)
+		g := byIssue[sess.IssueID]
+		if g == nil {
+			g = &FocusStats{IssueID: sess.IssueID}
+			if sess.IssueID != "" {
+				g.ProjectID = sess.ProjectID
+			}
+			byIssue[sess.IssueID] = g
+		}
+		g.add(focus, idle)
+	}
+
+	report.ByIssue = make([]FocusStats, 0, len(byIssue))
+	for id, g := range byIssue {
+		if id != "" {
+			if issue, err := s.GetIssue(ctx, id); err == nil {
+				g.Title = issue.Title
+			}
+		}
+		report.ByIssue = append(report.ByIssue, *g)
+	}
+	sort.Slice(report.ByIssue, func(i, j int) bool {
+		a, b := report.ByIssue[i], report.ByIssue[j]
+		if a.FocusSeconds != b.FocusSeconds {
+			return a.FocusSeconds > b.FocusSeconds
+		}
+		return a.IssueID < b.IssueID
+	})
+	return report, nil
+}
@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+func TestBuildFocusReport(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	bug := &models.Issue{ProjectID: p.ID, Title: "Bug"}
+	require.NoError(t, s.CreateIssue(ctx, bug))
+
+	// Sessions start now in the store; report as of two hours later.
+	now := time.Now().Add(2 * time.Hour)
+	beat := func(sess *models.AgentSession, minutes ...int) {
+		for _, m := range minutes {
+			_, err := s.TouchAgentSession(ctx, sess.ID, sess.StartedAt.Add(time.Duration(m)*time.Minute))
+			require.NoError(t, err)
+		}
+	}
+	first := &models.AgentSession{ProjectID: p.ID, IssueID: bug.ID, Branch: "fix/a", Status: models.SessionStatusActive}
+	require.NoError(t, s.CreateAgentSession(ctx, first))
+	beat(first, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	second := &models.AgentSession{ProjectID: p.ID, IssueID: bug.ID, Branch: "fix/b", Status: models.SessionStatusIdle}
+	require.NoError(t, s.CreateAgentSession(ctx, second))
+	beat(second, 0, 5, 30, 32)
+	loose := &models.AgentSession{ProjectID: p.ID, Branch: "spike", Status: models.SessionStatusActive}
+	require.NoError(t, s.CreateAgentSession(ctx, loose))
+
+	report, err := BuildFocusReport(ctx, s, store.SessionListFilter{ProjectID: p.ID}, now)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Total.Sessions)
+	require.Len(t, report.ByIssue, 2)
+
+	issue := report.ByIssue[0]
+	assert.Equal(t, bug.ID, issue.IssueID)
+	assert.Equal(t, "Bug", issue.Title)
+	assert.Equal(t, p.ID, issue.ProjectID)
+	assert.Equal(t, 2, issue.Sessions)
+	assert.InDelta(t, 17*60, issue.FocusSeconds, 1)
+	assert.InDelta(t, 4*3600-17*60, issue.IdleSeconds, 5)
+	assert.InDelta(t, 17.0/240, issue.FocusShare, 0.001)
+
+	none := report.ByIssue[1]
+	assert.Empty(t, none.IssueID)
+	assert.Empty(t, none.ProjectID)
+	assert.Zero(t, none.FocusSeconds)
+	assert.InDelta(t, 2*3600, none.IdleSeconds, 5)
+}
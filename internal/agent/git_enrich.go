@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"context"
 	"time"
 
 	"github.com/joescharf/pm/internal/git"
@@ -9,15 +10,15 @@ import (
 
 // EnrichSessionWithGitInfo populates last commit and activity info on a session
 // using the worktree path. Best-effort: errors are silently ignored.
-func EnrichSessionWithGitInfo(session *models.AgentSession, gc git.Client) {
+func EnrichSessionWithGitInfo(ctx context.Context, session *models.AgentSession, gc git.Client) {
 	if session.WorktreePath == "" || gc == nil {
 		return
 	}
 
-	if hash, err := gc.LastCommitHash(session.WorktreePath); err == nil {
+	if hash, err := gc.LastCommitHash(ctx, session.WorktreePath); err == nil {
 		session.LastCommitHash = hash
 	}
-	if msg, err := gc.LastCommitMessage(session.WorktreePath); err == nil {
+	if msg, err := gc.LastCommitMessage(ctx, session.WorktreePath); err == nil {
 		session.LastCommitMessage = msg
 	}
 
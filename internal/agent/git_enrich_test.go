@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -13,29 +14,64 @@ import (
 type mockGitClient struct {
 	lastCommitHash    string
 	lastCommitMessage string
+	fingerprint       string
+	committed         []string
 }
 
-func (m *mockGitClient) RepoRoot(path string) (string, error)          { return path, nil }
-func (m *mockGitClient) CurrentBranch(path string) (string, error)     { return "main", nil }
-func (m *mockGitClient) LastCommitDate(path string) (time.Time, error) { return time.Now(), nil }
-func (m *mockGitClient) LastCommitMessage(path string) (string, error) {
+func (m *mockGitClient) RepoRoot(_ context.Context, path string) (string, error) { return path, nil }
+func (m *mockGitClient) CurrentBranch(_ context.Context, path string) (string, error) {
+	return "main", nil
+}
+func (m *mockGitClient) LastCommitDate(_ context.Context, path string) (time.Time, error) {
+	return time.Now(), nil
+}
+func (m *mockGitClient) LastCommitMessage(_ context.Context, path string) (string, error) {
 	return m.lastCommitMessage, nil
 }
-func (m *mockGitClient) LastCommitHash(path string) (string, error) {
+func (m *mockGitClient) LastCommitHash(_ context.Context, path string) (string, error) {
+	return m.lastCommitHash, nil
+}
+func (m *mockGitClient) BranchList(_ context.Context, path string) ([]string, error) { return nil, nil }
+func (m *mockGitClient) IsDirty(_ context.Context, path string) (bool, error)        { return false, nil }
+func (m *mockGitClient) WorktreeList(_ context.Context, path string) ([]git.WorktreeInfo, error) {
+	return nil, nil
+}
+func (m *mockGitClient) RemoteURL(_ context.Context, path string) (string, error) { return "", nil }
+func (m *mockGitClient) LatestTag(_ context.Context, path string) (string, error) { return "", nil }
+func (m *mockGitClient) CommitCountSince(_ context.Context, path, base string) (int, error) {
+	return 0, nil
+}
+func (m *mockGitClient) AheadBehind(_ context.Context, path, base string) (int, int, error) {
+	return 0, 0, nil
+}
+func (m *mockGitClient) Diff(_ context.Context, path, base, head string) (string, error) {
+	return "", nil
+}
+func (m *mockGitClient) DiffStat(_ context.Context, path, base, head string) (string, error) {
+	return "", nil
+}
+func (m *mockGitClient) DiffNameOnly(_ context.Context, path, base, head string) ([]string, error) {
+	return nil, nil
+}
+func (m *mockGitClient) WorktreeFingerprint(_ context.Context, path string) (string, error) {
+	return m.fingerprint, nil
+}
+func (m *mockGitClient) CommitAll(_ context.Context, path, message string) (string, error) {
+	m.committed = append(m.committed, message)
 	return m.lastCommitHash, nil
 }
-func (m *mockGitClient) BranchList(path string) ([]string, error)                { return nil, nil }
-func (m *mockGitClient) IsDirty(path string) (bool, error)                       { return false, nil }
-func (m *mockGitClient) WorktreeList(path string) ([]git.WorktreeInfo, error)     { return nil, nil }
-func (m *mockGitClient) RemoteURL(path string) (string, error)                   { return "", nil }
-func (m *mockGitClient) LatestTag(path string) (string, error)                   { return "", nil }
-func (m *mockGitClient) CommitCountSince(path, base string) (int, error)         { return 0, nil }
-func (m *mockGitClient) AheadBehind(path, base string) (int, int, error)         { return 0, 0, nil }
-func (m *mockGitClient) Diff(path, base, head string) (string, error)           { return "", nil }
-func (m *mockGitClient) DiffStat(path, base, head string) (string, error)       { return "", nil }
-func (m *mockGitClient) DiffNameOnly(path, base, head string) ([]string, error) { return nil, nil }
+func (m *mockGitClient) CommitMessagesSince(_ context.Context, path, base string) ([]string, error) {
+	return nil, nil
+}
+func (m *mockGitClient) CommitLog(_ context.Context, path, base, head string) ([]git.Commit, error) {
+	return nil, nil
+}
+func (m *mockGitClient) Blame(_ context.Context, path, rev, file string, ranges []git.LineRange) ([]git.BlameLine, error) {
+	return nil, nil
+}
 
 func TestEnrichSessionWithGitInfo_SetsFields(t *testing.T) {
+	ctx := context.Background()
 	session := &models.AgentSession{
 		ID:           "sess-1",
 		WorktreePath: "/tmp/some-worktree",
@@ -48,7 +84,7 @@ func TestEnrichSessionWithGitInfo_SetsFields(t *testing.T) {
 	}
 
 	before := time.Now().UTC()
-	EnrichSessionWithGitInfo(session, gc)
+	EnrichSessionWithGitInfo(ctx, session, gc)
 	after := time.Now().UTC()
 
 	assert.Equal(t, "abc1234", session.LastCommitHash)
@@ -59,6 +95,7 @@ func TestEnrichSessionWithGitInfo_SetsFields(t *testing.T) {
 }
 
 func TestEnrichSessionWithGitInfo_NilClient(t *testing.T) {
+	ctx := context.Background()
 	session := &models.AgentSession{
 		ID:           "sess-2",
 		WorktreePath: "/tmp/some-worktree",
@@ -66,7 +103,7 @@ func TestEnrichSessionWithGitInfo_NilClient(t *testing.T) {
 	}
 
 	// Pass nil git client — should return early without panic
-	EnrichSessionWithGitInfo(session, nil)
+	EnrichSessionWithGitInfo(ctx, session, nil)
 
 	assert.Empty(t, session.LastCommitHash)
 	assert.Empty(t, session.LastCommitMessage)
@@ -74,6 +111,7 @@ func TestEnrichSessionWithGitInfo_NilClient(t *testing.T) {
 }
 
 func TestEnrichSessionWithGitInfo_EmptyWorktreePath(t *testing.T) {
+	ctx := context.Background()
 	session := &models.AgentSession{
 		ID:           "sess-3",
 		WorktreePath: "", // empty
@@ -86,7 +124,7 @@ func TestEnrichSessionWithGitInfo_EmptyWorktreePath(t *testing.T) {
 	}
 
 	// Should return early without modifying session
-	EnrichSessionWithGitInfo(session, gc)
+	EnrichSessionWithGitInfo(ctx, session, gc)
 
 	assert.Empty(t, session.LastCommitHash)
 	assert.Empty(t, session.LastCommitMessage)
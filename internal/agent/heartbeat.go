@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultHeartbeatInterval is how often, at most, Heartbeats writes a
+// session's LastActiveAt.
+const DefaultHeartbeatInterval = time.Minute
+
+// SessionToucher records activity on a session without rewriting it.
+type SessionToucher interface {
+	TouchAgentSession(ctx context.Context, id string, at time.Time) (bool, error)
+}
+
+// Heartbeats records activity on sessions from any number of sources (commit
+// hooks, MCP tool calls, file changes in worktrees), writing a session's
+// LastActiveAt at most once per interval so bursts of activity don't turn
+// into bursts of writes. It is safe for concurrent use.
+type Heartbeats struct {
+	interval time.Duration
+	now      func() time.Time
+
+	mu   sync.Mutex
+	last map[string]time.Time // session ID -> when its LastActiveAt was last written
+}
+
+// NewHeartbeats returns Heartbeats writing each session at most once per
+// interval; 0 writes every beat.
+func NewHeartbeats(interval time.Duration) *Heartbeats {
+	return &Heartbeats{interval: interval, now: time.Now, last: make(map[string]time.Time)}
+}
+
+// Beat records activity on a session now, unless its LastActiveAt was written
+// within the interval. It reports whether it wrote, which it does not for
+// ended or missing sessions either.
+func (h *Heartbeats) Beat(ctx context.Context, s SessionToucher, sessionID string) (bool, error) {
+	now := h.now()
+	h.mu.Lock()
+	if last, ok := h.last[sessionID]; ok && now.Sub(last) < h.interval {
+		h.mu.Unlock()
+		return false, nil
+	}
+	// Claim the slot before writing, so concurrent beats skip it.
+	h.last[sessionID] = now
+	h.prune(now)
+	h.mu.Unlock()
+
+	touched, err := s.TouchAgentSession(ctx, sessionID, now)
+	if err != nil {
+		h.mu.Lock()
+		if h.last[sessionID].Equal(now) {
+			delete(h.last, sessionID)
+		}
+		h.mu.Unlock()
+	}
+	return touched, err
+}
+
+// Recorded notes that a session's LastActiveAt was set to at some other way,
+// such as a full session update, so beats within the interval are skipped.
+func (h *Heartbeats) Recorded(sessionID string, at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if at.After(h.last[sessionID]) {
+		h.last[sessionID] = at
+	}
+}
+
+// maxHeartbeatSessions is how many sessions Heartbeats tracks before it
+// forgets those past their interval.
+const maxHeartbeatSessions = 1024
+
+// prune forgets sessions past their interval once many are tracked; the
+// caller holds h.mu.
+func (h *Heartbeats) prune(now time.Time) {
+	if len(h.last) <= maxHeartbeatSessions {
+		return
+	}
+	for id, at := range h.last {
+		if now.Sub(at) >= h.interval {
+			delete(h.last, id)
+		}
+	}
+}
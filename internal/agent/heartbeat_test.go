@@ -0,0 +1,114 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+func TestHeartbeats_Coalesce(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: t.TempDir()}
+	require.NoError(t, s.CreateProject(ctx, p))
+	sess := &models.AgentSession{ProjectID: p.ID, Branch: "feature/a", WorktreePath: p.Path, Status: models.SessionStatusActive}
+	require.NoError(t, s.CreateAgentSession(ctx, sess))
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	hb := NewHeartbeats(time.Minute)
+	hb.now = func() time.Time { return now }
+
+	wrote, err := hb.Beat(ctx, s, sess.ID)
+	require.NoError(t, err)
+	assert.True(t, wrote)
+
+	// A burst of beats from several sources makes no further writes.
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wrote, err := hb.Beat(ctx, s, sess.ID)
+			assert.NoError(t, err)
+			assert.False(t, wrote)
+		}()
+	}
+	wg.Wait()
+
+	now = now.Add(time.Minute)
+	wrote, err = hb.Beat(ctx, s, sess.ID)
+	require.NoError(t, err)
+	assert.True(t, wrote)
+	got, err := s.GetAgentSession(ctx, sess.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.LastActiveAt)
+	assert.True(t, now.Equal(*got.LastActiveAt))
+
+	// A write made another way restarts the interval.
+	hb.Recorded(sess.ID, now.Add(30*time.Second))
+	now = now.Add(time.Minute)
+	wrote, _ = hb.Beat(ctx, s, sess.ID)
+	assert.False(t, wrote)
+
+	sess.Status = models.SessionStatusCompleted
+	require.NoError(t, s.UpdateAgentSession(ctx, sess))
+	now = now.Add(time.Hour)
+	wrote, err = hb.Beat(ctx, s, sess.ID)
+	require.NoError(t, err)
+	assert.False(t, wrote, "ended sessions are not touched")
+}
+
+func TestRunWorktreeWatcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := store.NewMemoryStore()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "internal", "api"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "node_modules", "left-pad"), 0o755))
+	p := &models.Project{Name: "api", Path: dir}
+	require.NoError(t, s.CreateProject(ctx, p))
+	sess := &models.AgentSession{ProjectID: p.ID, Branch: "feature/a", WorktreePath: dir, Status: models.SessionStatusActive}
+	require.NoError(t, s.CreateAgentSession(ctx, sess))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- RunWorktreeWatcher(ctx, s, NewHeartbeats(0), time.Hour, func(err error) { t.Log(err) })
+	}()
+	lastActive := func() *time.Time {
+		got, err := s.GetAgentSession(ctx, sess.ID)
+		require.NoError(t, err)
+		return got.LastActiveAt
+	}
+
+	// Edits in the worktree are activity, including in new directories.
+	require.Eventually(t, func() bool {
+		_ = os.WriteFile(filepath.Join(dir, "internal", "api", "api.go"), []byte("package api"), 0o644)
+		return lastActive() != nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "internal", "badge"), 0o755))
+	first := *lastActive()
+	require.Eventually(t, func() bool {
+		_ = os.WriteFile(filepath.Join(dir, "internal", "badge", "badge.go"), []byte("package badge"), 0o644)
+		return lastActive().After(first)
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Dependency trees are not. Let earlier events drain first.
+	time.Sleep(200 * time.Millisecond)
+	latest := *lastActive()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "node_modules", "left-pad", "index.js"), []byte("x"), 0o644))
+	time.Sleep(200 * time.Millisecond)
+	assert.True(t, latest.Equal(*lastActive()))
+
+	cancel()
+	assert.NoError(t, <-done)
+}
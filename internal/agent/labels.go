@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"sort"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// LabelStats summarizes the sessions carrying one label, for comparing
+// agent workflows.
+type LabelStats struct {
+	Label     string `json:"label"` // "" for sessions without labels
+	Sessions  int    `json:"sessions"`
+	Open      int    `json:"open"`
+	Completed int    `json:"completed"`
+	Abandoned int    `json:"abandoned"`
+	Commits   int    `json:"commits"`
+	// Average run time of the sessions that ended
+	AvgDurationSeconds int64 `json:"avg_duration_seconds"`
+}
+
+// SuccessRate is the share of ended sessions that completed, from 0 to 1.
+func (l LabelStats) SuccessRate() float64 {
+	if ended := l.Completed + l.Abandoned; ended > 0 {
+		return float64(l.Completed) / float64(ended)
+	}
+	return 0
+}
+
+// AggregateByLabel groups sessions by label, counting a session under each of
+// its labels, and unlabeled sessions under "". Groups are sorted by label,
+// with the unlabeled group last.
+func AggregateByLabel(sessions []*models.AgentSession) []LabelStats {
+	groups := make(map[string]*LabelStats)
+	durations := make(map[string]time.Duration)
+	ended := make(map[string]int)
+	for _, sess := range sessions {
+		labels := sess.LabelList()
+		if len(labels) == 0 {
+			labels = []string{""}
+		}
+		for _, label := range labels {
+			g := groups[label]
+			if g == nil {
+				g = &LabelStats{Label: label}
+				groups[label] = g
+			}
+			g.Sessions++
+			g.Commits += sess.CommitCount
+			switch {
+			case sess.Status.IsOpen():
+				g.Open++
+			case sess.Status == models.SessionStatusCompleted:
+				g.Completed++
+			case sess.Status == models.SessionStatusAbandoned:
+				g.Abandoned++
+			}
+			if sess.EndedAt != nil {
+				durations[label] += sess.EndedAt.Sub(sess.StartedAt)
+				ended[label]++
+			}
+		}
+	}
+
+	stats := make([]LabelStats, 0, len(groups))
+	for label, g := range groups {
+		if n := ended[label]; n > 0 {
+			g.AvgDurationSeconds = int64((durations[label] / time.Duration(n)).Seconds())
+		}
+		stats = append(stats, *g)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i].Label, stats[j].Label
+		if a == "" || b == "" {
+			return b == "" && a != ""
+		}
+		return a < b
+	})
+	return stats
+}
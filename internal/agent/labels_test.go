@@ -0,0 +1,32 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+func TestAggregateByLabel(t *testing.T) {
+	start := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	ended := func(d time.Duration) *time.Time {
+		at := start.Add(d)
+		return &at
+	}
+	sessions := []*models.AgentSession{
+		{Labels: "experiment,spike", Status: models.SessionStatusCompleted, CommitCount: 3, StartedAt: start, EndedAt: ended(time.Hour)},
+		{Labels: "experiment", Status: models.SessionStatusAbandoned, CommitCount: 1, StartedAt: start, EndedAt: ended(3 * time.Hour)},
+		{Labels: "experiment", Status: models.SessionStatusActive, StartedAt: start},
+		{Status: models.SessionStatusCompleted, CommitCount: 2, StartedAt: start, EndedAt: ended(30 * time.Minute)},
+	}
+
+	stats := AggregateByLabel(sessions)
+	assert.Equal(t, []LabelStats{
+		{Label: "experiment", Sessions: 3, Open: 1, Completed: 1, Abandoned: 1, Commits: 4, AvgDurationSeconds: 7200},
+		{Label: "spike", Sessions: 1, Completed: 1, Commits: 3, AvgDurationSeconds: 3600},
+		{Label: "", Sessions: 1, Completed: 1, Commits: 2, AvgDurationSeconds: 1800},
+	}, stats)
+	assert.Equal(t, 0.5, stats[0].SuccessRate())
+}
@@ -3,6 +3,9 @@ package agent
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/joescharf/pm/internal/models"
@@ -12,48 +15,46 @@ import (
 type SessionStore interface {
 	GetAgentSession(ctx context.Context, id string) (*models.AgentSession, error)
 	UpdateAgentSession(ctx context.Context, session *models.AgentSession) error
+	TransitionStore
+	CascadeStore
 	GetIssue(ctx context.Context, id string) (*models.Issue, error)
-	UpdateIssue(ctx context.Context, issue *models.Issue) error
 }
 
-// CloseSession transitions a session to the given status and cascades issue changes.
+// CloseSession transitions a session to the given status and cascades issue
+// changes under the project's cascade rules.
 // Valid target statuses: idle, completed, abandoned.
-// Only active or idle sessions can be closed.
+// Only active, idle or blocked sessions can be closed; closing a blocked
+// session clears its blocked reason.
 func CloseSession(ctx context.Context, s SessionStore, sessionID string, target models.SessionStatus) (*models.AgentSession, error) {
+	return closeSession(ctx, s, sessionID, target, CauseClose)
+}
+
+func closeSession(ctx context.Context, s SessionStore, sessionID string, target models.SessionStatus, cause string) (*models.AgentSession, error) {
 	session, err := s.GetAgentSession(ctx, sessionID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Only active or idle sessions can transition
-	if session.Status != models.SessionStatusActive && session.Status != models.SessionStatusIdle {
+	// Only open sessions can transition
+	if !session.Status.IsOpen() {
 		return nil, fmt.Errorf("session %s is already %s", sessionID, session.Status)
 	}
 
-	session.Status = target
-
-	// Terminal statuses get an end time
-	if target == models.SessionStatusCompleted || target == models.SessionStatusAbandoned {
-		now := time.Now().UTC()
-		session.EndedAt = &now
-	}
-
-	if err := s.UpdateAgentSession(ctx, session); err != nil {
+	if err := Transition(ctx, s, session, target, cause); err != nil {
 		return nil, fmt.Errorf("update session: %w", err)
 	}
 
 	// Cascade issue status
-	if session.IssueID != "" {
-		issue, err := s.GetIssue(ctx, session.IssueID)
-		if err == nil && issue.Status == models.IssueStatusInProgress {
-			switch target {
-			case models.SessionStatusCompleted:
-				issue.Status = models.IssueStatusDone
-				_ = s.UpdateIssue(ctx, issue)
-			case models.SessionStatusAbandoned:
-				issue.Status = models.IssueStatusOpen
-				_ = s.UpdateIssue(ctx, issue)
-			}
+	var event models.CascadeEvent
+	switch target {
+	case models.SessionStatusCompleted:
+		event = models.CascadeCompleted
+	case models.SessionStatusAbandoned:
+		event = models.CascadeAbandoned
+	}
+	if session.IssueID != "" && event != "" {
+		if issue, err := s.GetIssue(ctx, session.IssueID); err == nil {
+			_, _ = CascadeIssue(ctx, s, issue, event)
 		}
 	}
 
@@ -72,10 +73,7 @@ func ReactivateSession(ctx context.Context, s SessionStore, sessionID string) (*
 		return nil, fmt.Errorf("session %s is already %s", sessionID, session.Status)
 	}
 
-	session.Status = models.SessionStatusIdle
-	session.EndedAt = nil
-
-	if err := s.UpdateAgentSession(ctx, session); err != nil {
+	if err := Transition(ctx, s, session, models.SessionStatusIdle, CauseReactivate); err != nil {
 		return nil, fmt.Errorf("update session: %w", err)
 	}
 
@@ -90,3 +88,248 @@ func ReactivateSession(ctx context.Context, s SessionStore, sessionID string) (*
 
 	return session, nil
 }
+
+// BlockSession marks an active or idle session as blocked on something outside
+// it. The reason is required; condition optionally says what would unblock it.
+// The session keeps its worktree and its issue stays in progress.
+func BlockSession(ctx context.Context, s SessionStore, sessionID, reason, condition string) (*models.AgentSession, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("a reason is required to block a session")
+	}
+	session, err := s.GetAgentSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != models.SessionStatusActive && session.Status != models.SessionStatusIdle {
+		return nil, fmt.Errorf("session %s is %s; only active or idle sessions can be blocked", sessionID, session.Status)
+	}
+
+	now := time.Now().UTC()
+	session.BlockedReason = reason
+	session.UnblockCondition = strings.TrimSpace(condition)
+	session.BlockedAt = &now
+
+	if err := Transition(ctx, s, session, models.SessionStatusBlocked, CauseBlock); err != nil {
+		return nil, fmt.Errorf("update session: %w", err)
+	}
+	return session, nil
+}
+
+// UnblockSession returns a blocked session to idle so it can be resumed.
+func UnblockSession(ctx context.Context, s SessionStore, sessionID string) (*models.AgentSession, error) {
+	session, err := s.GetAgentSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != models.SessionStatusBlocked {
+		return nil, fmt.Errorf("session %s is %s, not blocked", sessionID, session.Status)
+	}
+
+	if err := Transition(ctx, s, session, models.SessionStatusIdle, CauseUnblock); err != nil {
+		return nil, fmt.Errorf("update session: %w", err)
+	}
+	return session, nil
+}
+
+func clearBlocked(session *models.AgentSession) {
+	session.BlockedReason = ""
+	session.UnblockCondition = ""
+	session.BlockedAt = nil
+}
+
+// DefaultBlockedRemindAfter is how long a session may stay blocked before
+// pm starts reminding about it.
+const DefaultBlockedRemindAfter = 3 * 24 * time.Hour
+
+// BlockedTooLong reports whether a session has been blocked for at least after.
+// A non-positive after disables reminders.
+func BlockedTooLong(session *models.AgentSession, after time.Duration, now time.Time) bool {
+	if session.Status != models.SessionStatusBlocked || session.BlockedAt == nil || after <= 0 {
+		return false
+	}
+	return now.Sub(*session.BlockedAt) >= after
+}
+
+// OverdueBlocked returns the sessions that have been blocked for at least
+// after, longest-blocked first.
+func OverdueBlocked(sessions []*models.AgentSession, after time.Duration, now time.Time) []*models.AgentSession {
+	var overdue []*models.AgentSession
+	for _, sess := range sessions {
+		if BlockedTooLong(sess, after, now) {
+			overdue = append(overdue, sess)
+		}
+	}
+	sort.SliceStable(overdue, func(i, j int) bool { return overdue[i].BlockedAt.Before(*overdue[j].BlockedAt) })
+	return overdue
+}
+
+// CheckBranchNotBlocked returns an error when branch already has a blocked
+// session: launching there would start work the session is still waiting on,
+// so the session has to be unblocked first.
+func CheckBranchNotBlocked(sessions []*models.AgentSession, branch string) error {
+	for _, sess := range sessions {
+		if sess.Branch == branch && sess.Status == models.SessionStatusBlocked {
+			id := sess.ID
+			if len(id) > 12 {
+				id = id[:12]
+			}
+			return fmt.Errorf("session %s on %s is blocked (%s); unblock it first", id, branch, sess.BlockedReason)
+		}
+	}
+	return nil
+}
+
+// CheckBranchNotActive returns an error naming the active worktree session
+// on branch, if any. REST, MCP and the CLI each name branches after issue
+// titles, so near-identical titles launched from different surfaces land on
+// the same branch; the second launch is refused here rather than clashing
+// over the first one's worktree.
+func CheckBranchNotActive(sessions []*models.AgentSession, branch string) error {
+	for _, sess := range sessions {
+		if sess.Branch == branch && sess.Status == models.SessionStatusActive && !sess.Inline {
+			id := sess.ID
+			if len(id) > 12 {
+				id = id[:12]
+			}
+			if issueID := sess.IssueID; issueID != "" {
+				if len(issueID) > 12 {
+					issueID = issueID[:12]
+				}
+				return fmt.Errorf("branch %s is reserved by active session %s (issue %s); close it or launch on another branch", branch, id, issueID)
+			}
+			return fmt.Errorf("branch %s is reserved by active session %s; close it or launch on another branch", branch, id)
+		}
+	}
+	return nil
+}
+
+// CheckBranchNotInline returns an error when branch already has an open
+// inline session: a worktree launch there would fight over the branch with
+// the repo checkout the inline session works in.
+func CheckBranchNotInline(sessions []*models.AgentSession, branch string) error {
+	for _, sess := range sessions {
+		if sess.Branch == branch && sess.Inline && sess.Status.IsOpen() {
+			id := sess.ID
+			if len(id) > 12 {
+				id = id[:12]
+			}
+			return fmt.Errorf("branch %s has inline session %s; resume it with pm agent launch --inline or close it first", branch, id)
+		}
+	}
+	return nil
+}
+
+// DefaultIssueLockExpiry is how long an active session without activity keeps
+// the lock on its issue.
+const DefaultIssueLockExpiry = 2 * time.Hour
+
+// IssueLock is the lock an active session holds on each of its issues, so a
+// second agent is not launched on the issue from another surface. The store
+// enforces it (store.ErrIssueLocked). It is released when the session
+// closes, goes idle or is blocked, and expires once the session has been
+// inactive for the lock expiry; ReleaseExpiredIssueLocks then moves the
+// session to idle so a launch can take the issue.
+type IssueLock struct {
+	IssueID      string     `json:"issue_id"`
+	SessionID    string     `json:"session_id"`
+	SessionCode  string     `json:"session_code,omitempty"`
+	Branch       string     `json:"branch"`
+	WorktreePath string     `json:"worktree_path"`
+	Since        time.Time  `json:"since"`
+	LastActiveAt *time.Time `json:"last_active_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"` // nil: held until the session stops
+}
+
+// IssueLockedError is returned by CheckIssueNotHeld for an issue another
+// session holds.
+type IssueLockedError struct {
+	Lock IssueLock
+}
+
+func (e *IssueLockedError) Error() string {
+	holder := e.Lock.SessionCode
+	if holder == "" {
+		holder = e.Lock.SessionID
+		if len(holder) > 12 {
+			holder = holder[:12]
+		}
+	}
+	issueID := e.Lock.IssueID
+	if len(issueID) > 12 {
+		issueID = issueID[:12]
+	}
+	msg := fmt.Sprintf("issue %s is locked by active session %s on %s", issueID, holder, e.Lock.Branch)
+	if e.Lock.ExpiresAt != nil {
+		msg += fmt.Sprintf(" until %s unless it stays active", e.Lock.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	return msg + "; close that session first"
+}
+
+// IssueHolder returns the lock on issueID held by one of sessions: an active
+// session on the issue that was active within expiry (<= 0 never expires).
+// It returns nil when the issue is free.
+func IssueHolder(sessions []*models.AgentSession, issueID string, expiry time.Duration, now time.Time) *IssueLock {
+	for _, sess := range sessions {
+		if !slices.Contains(sess.IssueIDs(), issueID) || sess.Status != models.SessionStatusActive {
+			continue
+		}
+		last := lastActivity(sess)
+		lock := &IssueLock{
+			IssueID:      issueID,
+			SessionID:    sess.ID,
+			SessionCode:  sess.Code,
+			Branch:       sess.Branch,
+			WorktreePath: sess.WorktreePath,
+			Since:        sess.StartedAt,
+			LastActiveAt: sess.LastActiveAt,
+		}
+		if expiry > 0 {
+			expires := last.Add(expiry)
+			if !now.Before(expires) {
+				continue
+			}
+			lock.ExpiresAt = &expires
+		}
+		return lock
+	}
+	return nil
+}
+
+// CheckIssueNotHeld returns an *IssueLockedError when another session holds
+// the lock on issueID. Launching on the holder's own branch is refused by
+// CheckBranchNotActive; this catches launches on other branches.
+func CheckIssueNotHeld(sessions []*models.AgentSession, issueID string, expiry time.Duration, now time.Time) error {
+	if lock := IssueHolder(sessions, issueID, expiry, now); lock != nil {
+		return &IssueLockedError{Lock: *lock}
+	}
+	return nil
+}
+
+// ReleaseExpiredIssueLocks moves the active sessions whose lock on one of
+// issueIDs has expired to idle, so the store lets a launch take the issues.
+// The idle session can be resumed like any other.
+func ReleaseExpiredIssueLocks(ctx context.Context, s TransitionStore, sessions []*models.AgentSession, issueIDs []string, expiry time.Duration, now time.Time) error {
+	if expiry <= 0 {
+		return nil
+	}
+	for _, sess := range sessions {
+		if sess.Status != models.SessionStatusActive || now.Before(lastActivity(sess).Add(expiry)) ||
+			!slices.ContainsFunc(sess.IssueIDs(), func(id string) bool { return slices.Contains(issueIDs, id) }) {
+			continue
+		}
+		if err := Transition(ctx, s, sess, models.SessionStatusIdle, CauseIssueLockExpired); err != nil {
+			return fmt.Errorf("release issue lock of session %s: %w", sess.ID, err)
+		}
+	}
+	return nil
+}
+
+// lastActivity returns when sess was last active: LastActiveAt, else
+// StartedAt.
+func lastActivity(sess *models.AgentSession) time.Time {
+	if sess.LastActiveAt != nil && sess.LastActiveAt.After(sess.StartedAt) {
+		return *sess.LastActiveAt
+	}
+	return sess.StartedAt
+}
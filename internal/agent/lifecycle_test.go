@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -15,6 +16,15 @@ import (
 type mockSessionStore struct {
 	sessions map[string]*models.AgentSession
 	issues   map[string]*models.Issue
+	projects map[string]*models.Project
+}
+
+func (m *mockSessionStore) GetProject(_ context.Context, id string) (*models.Project, error) {
+	p, ok := m.projects[id]
+	if !ok {
+		return nil, fmt.Errorf("project %s not found", id)
+	}
+	return p, nil
 }
 
 func (m *mockSessionStore) GetAgentSession(_ context.Context, id string) (*models.AgentSession, error) {
@@ -33,6 +43,12 @@ func (m *mockSessionStore) UpdateAgentSession(_ context.Context, session *models
 	return nil
 }
 
+// TransitionAgentSession does not check from: the mock hands out the stored
+// sessions themselves, and its tests have no concurrent writers.
+func (m *mockSessionStore) TransitionAgentSession(ctx context.Context, session *models.AgentSession, _ models.SessionStatus, _ string) error {
+	return m.UpdateAgentSession(ctx, session)
+}
+
 func (m *mockSessionStore) GetIssue(_ context.Context, id string) (*models.Issue, error) {
 	i, ok := m.issues[id]
 	if !ok {
@@ -214,3 +230,181 @@ func TestReactivateSession_AlreadyActive(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "already active")
 }
+
+func TestBlockSession(t *testing.T) {
+	store := newMockStore()
+	store.sessions["sess-b1"] = &models.AgentSession{
+		ID:      "sess-b1",
+		IssueID: "issue-b1",
+		Status:  models.SessionStatusIdle,
+	}
+	store.issues["issue-b1"] = &models.Issue{
+		ID:     "issue-b1",
+		Status: models.IssueStatusInProgress,
+	}
+
+	ctx := context.Background()
+	_, err := BlockSession(ctx, store, "sess-b1", "  ", "")
+	require.Error(t, err, "a reason is required")
+
+	session, err := BlockSession(ctx, store, "sess-b1", "waiting on API key", "ops provisions a sandbox key")
+	require.NoError(t, err)
+	assert.Equal(t, models.SessionStatusBlocked, session.Status)
+	assert.Equal(t, "waiting on API key", session.BlockedReason)
+	assert.Equal(t, "ops provisions a sandbox key", session.UnblockCondition)
+	require.NotNil(t, session.BlockedAt)
+	assert.Nil(t, session.EndedAt)
+	assert.Equal(t, models.IssueStatusInProgress, store.issues["issue-b1"].Status, "blocking keeps the issue in progress")
+
+	_, err = BlockSession(ctx, store, "sess-b1", "again", "")
+	require.Error(t, err, "already blocked")
+
+	session, err = UnblockSession(ctx, store, "sess-b1")
+	require.NoError(t, err)
+	assert.Equal(t, models.SessionStatusIdle, session.Status)
+	assert.Empty(t, session.BlockedReason)
+	assert.Nil(t, session.BlockedAt)
+
+	_, err = UnblockSession(ctx, store, "sess-b1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not blocked")
+}
+
+func TestCloseSession_FromBlocked(t *testing.T) {
+	store := newMockStore()
+	blockedAt := time.Now().UTC()
+	store.sessions["sess-b2"] = &models.AgentSession{
+		ID:            "sess-b2",
+		Status:        models.SessionStatusBlocked,
+		BlockedReason: "waiting on design",
+		BlockedAt:     &blockedAt,
+	}
+
+	session, err := CloseSession(context.Background(), store, "sess-b2", models.SessionStatusAbandoned)
+	require.NoError(t, err)
+	assert.Equal(t, models.SessionStatusAbandoned, session.Status)
+	assert.Empty(t, session.BlockedReason)
+	assert.Nil(t, session.BlockedAt)
+}
+
+func TestOverdueBlocked(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	fiveDays, fourDays, oneDay := now.Add(-5*24*time.Hour), now.Add(-4*24*time.Hour), now.Add(-24*time.Hour)
+	sessions := []*models.AgentSession{
+		{ID: "recent", Status: models.SessionStatusBlocked, BlockedAt: &oneDay},
+		{ID: "newer", Status: models.SessionStatusBlocked, BlockedAt: &fourDays},
+		{ID: "older", Status: models.SessionStatusBlocked, BlockedAt: &fiveDays},
+		{ID: "idle", Status: models.SessionStatusIdle, BlockedAt: &fiveDays},
+	}
+
+	overdue := OverdueBlocked(sessions, DefaultBlockedRemindAfter, now)
+	require.Len(t, overdue, 2)
+	assert.Equal(t, "older", overdue[0].ID)
+	assert.Equal(t, "newer", overdue[1].ID)
+	assert.Empty(t, OverdueBlocked(sessions, 0, now), "a zero threshold disables reminders")
+}
+
+func TestCheckBranchNotBlocked(t *testing.T) {
+	sessions := []*models.AgentSession{
+		{ID: "01HBLOCKEDSESSION", Branch: "feature/sso", Status: models.SessionStatusBlocked, BlockedReason: "waiting on IdP"},
+		{ID: "s2", Branch: "feature/theme", Status: models.SessionStatusIdle},
+	}
+	err := CheckBranchNotBlocked(sessions, "feature/sso")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "01HBLOCKEDSE")
+	assert.Contains(t, err.Error(), "waiting on IdP")
+	assert.NoError(t, CheckBranchNotBlocked(sessions, "feature/theme"))
+}
+
+func TestCheckBranchNotActive(t *testing.T) {
+	sessions := []*models.AgentSession{
+		{ID: "01HACTIVESESSION", IssueID: "01HLOGINISSUE", Branch: "feature/fix-login", Status: models.SessionStatusActive},
+		{ID: "s2", Branch: "feature/theme", Status: models.SessionStatusIdle},
+		{ID: "s3", Branch: "main", Status: models.SessionStatusActive, Inline: true},
+	}
+	err := CheckBranchNotActive(sessions, "feature/fix-login")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "01HACTIVESES")
+	assert.Contains(t, err.Error(), "01HLOGINISSU")
+	assert.NoError(t, CheckBranchNotActive(sessions, "feature/theme"), "idle sessions are resumed")
+	assert.NoError(t, CheckBranchNotActive(sessions, "main"), "inline sessions are checked by CheckBranchNotInline")
+}
+
+func TestIssueHolder(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-30 * time.Minute)
+	stale := now.Add(-3 * time.Hour)
+	sessions := []*models.AgentSession{
+		{ID: "01HIDLESESSION", IssueID: "01HLOGINISSUE", Branch: "feature/fix-login", Status: models.SessionStatusIdle, StartedAt: stale},
+		{ID: "01HACTIVESESSION", Code: "APP-S2", IssueID: "01HLOGINISSUE", Branch: "feature/login-v2", Status: models.SessionStatusActive, StartedAt: stale, LastActiveAt: &recent},
+		{ID: "01HSTALESESSION", IssueID: "01HTHEMEISSUE", Branch: "feature/theme", Status: models.SessionStatusActive, StartedAt: stale, LastActiveAt: &stale},
+		{ID: "01HMULTISESSION", IssueID: "01HAUTHISSUE", ExtraIssueIDs: "01HLOGOUTISSUE", Branch: "feature/auth", Status: models.SessionStatusActive, StartedAt: recent},
+	}
+
+	lock := IssueHolder(sessions, "01HLOGINISSUE", DefaultIssueLockExpiry, now)
+	require.NotNil(t, lock, "held by the active session")
+	assert.Equal(t, "01HACTIVESESSION", lock.SessionID)
+	assert.Equal(t, "feature/login-v2", lock.Branch)
+	require.NotNil(t, lock.ExpiresAt)
+	assert.Equal(t, recent.Add(DefaultIssueLockExpiry), *lock.ExpiresAt)
+
+	assert.Nil(t, IssueHolder(sessions, "01HTHEMEISSUE", DefaultIssueLockExpiry, now), "expired after 2h without activity")
+	assert.NotNil(t, IssueHolder(sessions, "01HTHEMEISSUE", 0, now), "0 never expires")
+	assert.Nil(t, IssueHolder(sessions, "01HOTHERISSUE", DefaultIssueLockExpiry, now))
+
+	err := CheckIssueNotHeld(sessions, "01HLOGINISSUE", DefaultIssueLockExpiry, now)
+	var locked *IssueLockedError
+	require.ErrorAs(t, err, &locked)
+	assert.Contains(t, err.Error(), "APP-S2")
+	assert.Contains(t, err.Error(), "feature/login-v2")
+	assert.NoError(t, CheckIssueNotHeld(sessions, "01HTHEMEISSUE", DefaultIssueLockExpiry, now))
+
+	lock = IssueHolder(sessions, "01HLOGOUTISSUE", DefaultIssueLockExpiry, now)
+	require.NotNil(t, lock, "every issue of a multi-issue launch is held")
+	assert.Equal(t, "01HMULTISESSION", lock.SessionID)
+}
+
+func TestReleaseExpiredIssueLocks(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	held := &models.AgentSession{ProjectID: p.ID, IssueID: "01HAUTHISSUE", ExtraIssueIDs: "01HLOGINISSUE", Branch: "feature/auth", Status: models.SessionStatusActive}
+	require.NoError(t, s.CreateAgentSession(ctx, held))
+	other := &models.AgentSession{ProjectID: p.ID, IssueID: "01HTHEMEISSUE", Branch: "feature/theme", Status: models.SessionStatusActive}
+	require.NoError(t, s.CreateAgentSession(ctx, other))
+	sessions, err := s.ListAgentSessions(ctx, p.ID, 0)
+	require.NoError(t, err)
+
+	// Not yet expired: nothing is released
+	require.NoError(t, ReleaseExpiredIssueLocks(ctx, s, sessions, []string{"01HLOGINISSUE"}, DefaultIssueLockExpiry, time.Now()))
+	got, err := s.GetAgentSession(ctx, held.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SessionStatusActive, got.Status)
+
+	later := time.Now().Add(DefaultIssueLockExpiry + time.Minute)
+	require.NoError(t, ReleaseExpiredIssueLocks(ctx, s, sessions, []string{"01HLOGINISSUE"}, 0, later), "0 never expires")
+	require.NoError(t, ReleaseExpiredIssueLocks(ctx, s, sessions, []string{"01HLOGINISSUE"}, DefaultIssueLockExpiry, later))
+	got, err = s.GetAgentSession(ctx, held.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SessionStatusIdle, got.Status, "expired holder of an extra issue is idled")
+	got, err = s.GetAgentSession(ctx, other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SessionStatusActive, got.Status, "sessions on other issues are left alone")
+
+	// The released issue can be locked again
+	require.NoError(t, s.CreateAgentSession(ctx, &models.AgentSession{ProjectID: p.ID, IssueID: "01HLOGINISSUE", Branch: "feature/login", Status: models.SessionStatusActive}))
+}
+
+func TestCheckBranchNotInline(t *testing.T) {
+	sessions := []*models.AgentSession{
+		{ID: "01HINLINESESSION", Branch: "main", Status: models.SessionStatusIdle, Inline: true},
+		{ID: "s2", Branch: "release", Status: models.SessionStatusCompleted, Inline: true},
+		{ID: "s3", Branch: "feature/theme", Status: models.SessionStatusIdle},
+	}
+	err := CheckBranchNotInline(sessions, "main")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "01HINLINESES")
+	assert.NoError(t, CheckBranchNotInline(sessions, "release"))
+	assert.NoError(t, CheckBranchNotInline(sessions, "feature/theme"))
+}
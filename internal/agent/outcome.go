@@ -0,0 +1,243 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// SessionEventLister lists the events recorded for a session.
+// store.Store satisfies this interface.
+type SessionEventLister interface {
+	ListSessionEvents(ctx context.Context, sessionID string) ([]*models.SessionEvent, error)
+}
+
+// ClassifyOutcome classifies an ended session from its status, commits and
+// conflict history (its current conflict state and its recorded events). It
+// returns "" for open sessions. Failed reviews are recorded separately, by
+// MarkReviewFailed.
+func ClassifyOutcome(session *models.AgentSession, events []*models.SessionEvent) models.OutcomeClass {
+	switch session.Status {
+	case models.SessionStatusCompleted:
+		if hadConflicts(session, events) {
+			return models.OutcomeSuccessAfterConflicts
+		}
+		return models.OutcomeCleanSuccess
+	case models.SessionStatusAbandoned:
+		if session.CommitCount == 0 {
+			return models.OutcomeAbandonedNoCommits
+		}
+		return models.OutcomeAbandoned
+	}
+	return ""
+}
+
+// hadConflicts reports whether the session ran into a sync or merge conflict.
+func hadConflicts(session *models.AgentSession, events []*models.SessionEvent) bool {
+	if session.ConflictState != "" && session.ConflictState != models.ConflictStateNone {
+		return true
+	}
+	for _, e := range events {
+		if e.Kind == models.SessionEventConflict && e.Value != string(models.ConflictStateNone) {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyEnding sets the outcome class of a session Transition is about to
+// save. Ending sessions are classified, reopened ones lose their class; a
+// failed review's verdict sticks either way. The conflict history comes from s
+// when it can list session events.
+func classifyEnding(ctx context.Context, s TransitionStore, session *models.AgentSession) {
+	if session.OutcomeClass == models.OutcomeReviewFailed {
+		return
+	}
+	if session.Status.IsOpen() {
+		session.OutcomeClass = ""
+		return
+	}
+	var events []*models.SessionEvent
+	if l, ok := s.(SessionEventLister); ok {
+		events, _ = l.ListSessionEvents(ctx, session.ID)
+	}
+	session.OutcomeClass = ClassifyOutcome(session, events)
+}
+
+// OutcomeStore is the subset of store.Store needed to record and report
+// session outcomes.
+type OutcomeStore interface {
+	TransitionStore
+	GetAgentSession(ctx context.Context, id string) (*models.AgentSession, error)
+	ListAgentSessionsFiltered(ctx context.Context, filter store.SessionListFilter) ([]*models.AgentSession, error)
+	GetIssue(ctx context.Context, id string) (*models.Issue, error)
+	GetProject(ctx context.Context, id string) (*models.Project, error)
+}
+
+// MarkReviewFailed classifies the session whose work a failed review covers
+// as review_failed: the review's session, or else the issue's most recently
+// started session. Passing reviews change nothing. It returns the session it
+// marked, or nil when there is none. The write is guarded like Transition's,
+// so a status change racing with it is kept: the session is read again and
+// marked on top of it.
+func MarkReviewFailed(ctx context.Context, s OutcomeStore, review *models.IssueReview) (*models.AgentSession, error) {
+	if review.Verdict != models.ReviewVerdictFail {
+		return nil, nil
+	}
+
+	const attempts = 3
+	for i := 1; ; i++ {
+		session, err := reviewedSession(ctx, s, review)
+		if err != nil || session == nil {
+			return nil, err
+		}
+		session.OutcomeClass = models.OutcomeReviewFailed
+		err = s.TransitionAgentSession(ctx, session, session.Status, CauseReview)
+		if errors.Is(err, store.ErrSessionStatusChanged) && i < attempts {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return session, nil
+	}
+}
+
+// reviewedSession returns the session a review covers, as MarkReviewFailed
+// picks it, or nil when the issue has none.
+func reviewedSession(ctx context.Context, s OutcomeStore, review *models.IssueReview) (*models.AgentSession, error) {
+	if review.SessionID != "" {
+		return s.GetAgentSession(ctx, review.SessionID)
+	}
+	issue, err := s.GetIssue(ctx, review.IssueID)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.ListAgentSessionsFiltered(ctx, store.SessionListFilter{IssueID: issue.ID})
+	if err != nil {
+		return nil, err
+	}
+	var session *models.AgentSession
+	for _, sess := range sessions {
+		if session == nil || sess.StartedAt.After(session.StartedAt) {
+			session = sess
+		}
+	}
+	return session, nil
+}
+
+// Prompt templates an agent session was launched with, as reported by
+// PromptTemplate.
+const (
+	PromptTemplateIssue          = "issue"            // the standard "look up issue(s) ... and implement" prompt
+	PromptTemplateReviewFollowUp = "review_follow_up" // ReviewFollowUpPrompt, after a failed review
+	PromptTemplateNone           = "none"             // discovered worktrees pm did not launch
+)
+
+// PromptTemplate names the prompt template pm launched a session with.
+func PromptTemplate(session *models.AgentSession) string {
+	switch {
+	case session.Discovered:
+		return PromptTemplateNone
+	case session.ReviewAttempt > 0 || session.ReviewID != "":
+		return PromptTemplateReviewFollowUp
+	default:
+		return PromptTemplateIssue
+	}
+}
+
+// OutcomeStats counts the outcome classes of one group of ended sessions.
+type OutcomeStats struct {
+	Key      string                      `json:"key"`            // issue type, project ID or prompt template
+	Name     string                      `json:"name,omitempty"` // project name
+	Sessions int                         `json:"sessions"`
+	Outcomes map[models.OutcomeClass]int `json:"outcomes"`
+	// Share of the group's sessions that completed, from 0 to 1
+	SuccessRate float64 `json:"success_rate"`
+}
+
+func (o *OutcomeStats) add(class models.OutcomeClass) {
+	o.Sessions++
+	o.Outcomes[class]++
+	success := o.Outcomes[models.OutcomeCleanSuccess] + o.Outcomes[models.OutcomeSuccessAfterConflicts]
+	o.SuccessRate = float64(success) / float64(o.Sessions)
+}
+
+// OutcomeReport breaks the outcomes of ended sessions down by issue type,
+// project and prompt template.
+type OutcomeReport struct {
+	Total            OutcomeStats   `json:"total"`
+	ByIssueType      []OutcomeStats `json:"by_issue_type"`
+	ByProject        []OutcomeStats `json:"by_project"`
+	ByPromptTemplate []OutcomeStats `json:"by_prompt_template"`
+}
+
+// BuildOutcomeReport aggregates the outcome classes of the sessions matching
+// filter. Open and unclassified sessions are left out, and sessions without
+// an issue count under the issue type "". Groups are sorted by session count,
+// then key.
+func BuildOutcomeReport(ctx context.Context, s OutcomeStore, filter store.SessionListFilter) (*OutcomeReport, error) {
+	sessions, err := s.ListAgentSessionsFiltered(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	byType := make(map[string]*OutcomeStats)
+	byProject := make(map[string]*OutcomeStats)
+	byTemplate := make(map[string]*OutcomeStats)
+	group := func(m map[string]*OutcomeStats, key string) *OutcomeStats {
+		g := m[key]
+		if g == nil {
+			g = &OutcomeStats{Key: key, Outcomes: map[models.OutcomeClass]int{}}
+			m[key] = g
+		}
+		return g
+	}
+	issueTypes := make(map[string]string)
+
+	report := &OutcomeReport{Total: OutcomeStats{Outcomes: map[models.OutcomeClass]int{}}}
+	for _, sess := range sessions {
+		if sess.OutcomeClass == "" {
+			continue
+		}
+		issueType, seen := issueTypes[sess.IssueID]
+		if !seen && sess.IssueID != "" {
+			if issue, err := s.GetIssue(ctx, sess.IssueID); err == nil {
+				issueType = string(issue.Type)
+			}
+			issueTypes[sess.IssueID] = issueType
+		}
+
+		report.Total.add(sess.OutcomeClass)
+		group(byType, issueType).add(sess.OutcomeClass)
+		group(byProject, sess.ProjectID).add(sess.OutcomeClass)
+		group(byTemplate, PromptTemplate(sess)).add(sess.OutcomeClass)
+	}
+
+	for id, g := range byProject {
+		if p, err := s.GetProject(ctx, id); err == nil {
+			g.Name = p.Name
+		}
+	}
+	report.ByIssueType = sortedOutcomeStats(byType)
+	report.ByProject = sortedOutcomeStats(byProject)
+	report.ByPromptTemplate = sortedOutcomeStats(byTemplate)
+	return report, nil
+}
+
+func sortedOutcomeStats(m map[string]*OutcomeStats) []OutcomeStats {
+	stats := make([]OutcomeStats, 0, len(m))
+	for _, g := range m {
+		stats = append(stats, *g)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Sessions != stats[j].Sessions {
+			return stats[i].Sessions > stats[j].Sessions
+		}
+		return stats[i].Key < stats[j].Key
+	})
+	return stats
+}
@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+func TestClassifyOutcome(t *testing.T) {
+	conflict := []*models.SessionEvent{{Kind: models.SessionEventConflict, Value: string(models.ConflictStateSyncConflict)}}
+	resolved := []*models.SessionEvent{{Kind: models.SessionEventConflict, Value: string(models.ConflictStateNone)}}
+	tests := []struct {
+		name    string
+		session models.AgentSession
+		events  []*models.SessionEvent
+		want    models.OutcomeClass
+	}{
+		{"clean", models.AgentSession{Status: models.SessionStatusCompleted, CommitCount: 2}, resolved, models.OutcomeCleanSuccess},
+		{"conflict history", models.AgentSession{Status: models.SessionStatusCompleted, ConflictState: models.ConflictStateNone}, conflict, models.OutcomeSuccessAfterConflicts},
+		{"current conflict", models.AgentSession{Status: models.SessionStatusCompleted, ConflictState: models.ConflictStateMergeConflict}, nil, models.OutcomeSuccessAfterConflicts},
+		{"abandoned empty", models.AgentSession{Status: models.SessionStatusAbandoned}, nil, models.OutcomeAbandonedNoCommits},
+		{"abandoned with commits", models.AgentSession{Status: models.SessionStatusAbandoned, CommitCount: 1}, conflict, models.OutcomeAbandoned},
+		{"open", models.AgentSession{Status: models.SessionStatusIdle}, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClassifyOutcome(&tt.session, tt.events))
+		})
+	}
+}
+
+func TestTransition_ClassifiesOutcome(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	sess := &models.AgentSession{ProjectID: p.ID, Branch: "feature/a", Status: models.SessionStatusActive, CommitCount: 1}
+	require.NoError(t, s.CreateAgentSession(ctx, sess))
+
+	sess.ConflictState = models.ConflictStateSyncConflict
+	require.NoError(t, s.UpdateAgentSession(ctx, sess))
+	sess.ConflictState = models.ConflictStateNone
+	require.NoError(t, s.UpdateAgentSession(ctx, sess))
+
+	require.NoError(t, Transition(ctx, s, sess, models.SessionStatusCompleted, CauseClose))
+	got, err := s.GetAgentSession(ctx, sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.OutcomeSuccessAfterConflicts, got.OutcomeClass, "the resolved conflict is in the event history")
+
+	require.NoError(t, Transition(ctx, s, sess, models.SessionStatusIdle, CauseReactivate))
+	assert.Empty(t, sess.OutcomeClass, "reopening clears the class")
+
+	sess.OutcomeClass = models.OutcomeReviewFailed
+	require.NoError(t, Transition(ctx, s, sess, models.SessionStatusCompleted, CauseReview))
+	assert.Equal(t, models.OutcomeReviewFailed, sess.OutcomeClass, "a failed review sticks")
+}
+
+func TestMarkReviewFailed(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Add caching"}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	start := time.Now().UTC().Add(-2 * time.Hour)
+	older := &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "feature/a", Status: models.SessionStatusCompleted, OutcomeClass: models.OutcomeCleanSuccess, StartedAt: start}
+	latest := &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "feature/b", Status: models.SessionStatusCompleted, OutcomeClass: models.OutcomeCleanSuccess, StartedAt: start.Add(time.Hour)}
+	require.NoError(t, s.CreateAgentSession(ctx, older))
+	require.NoError(t, s.CreateAgentSession(ctx, latest))
+
+	marked, err := MarkReviewFailed(ctx, s, &models.IssueReview{IssueID: issue.ID, Verdict: models.ReviewVerdictPass})
+	require.NoError(t, err)
+	assert.Nil(t, marked, "passing reviews change nothing")
+
+	marked, err = MarkReviewFailed(ctx, s, &models.IssueReview{IssueID: issue.ID, Verdict: models.ReviewVerdictFail})
+	require.NoError(t, err)
+	require.NotNil(t, marked)
+	assert.Equal(t, latest.ID, marked.ID, "without a linked session the latest one is marked")
+
+	_, err = MarkReviewFailed(ctx, s, &models.IssueReview{IssueID: issue.ID, SessionID: older.ID, Verdict: models.ReviewVerdictFail})
+	require.NoError(t, err)
+	got, err := s.GetAgentSession(ctx, older.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.OutcomeReviewFailed, got.OutcomeClass)
+}
+
+// reopeningStore reopens a session just before the first guarded write to
+// it, as when the session is resumed while its review is being saved.
+type reopeningStore struct {
+	*store.MemoryStore
+	reopened bool
+}
+
+func (r *reopeningStore) TransitionAgentSession(ctx context.Context, session *models.AgentSession, from models.SessionStatus, cause string) error {
+	if !r.reopened {
+		r.reopened = true
+		current, err := r.MemoryStore.GetAgentSession(ctx, session.ID)
+		if err != nil {
+			return err
+		}
+		current.Status = models.SessionStatusIdle
+		if err := r.MemoryStore.TransitionAgentSession(ctx, current, models.SessionStatusCompleted, CauseReview); err != nil {
+			return err
+		}
+	}
+	return r.MemoryStore.TransitionAgentSession(ctx, session, from, cause)
+}
+
+func TestMarkReviewFailed_KeepsRacingStatus(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Add caching"}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	sess := &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "feature/a", Status: models.SessionStatusCompleted}
+	require.NoError(t, s.CreateAgentSession(ctx, sess))
+
+	marked, err := MarkReviewFailed(ctx, &reopeningStore{MemoryStore: s}, &models.IssueReview{IssueID: issue.ID, SessionID: sess.ID, Verdict: models.ReviewVerdictFail})
+	require.NoError(t, err)
+	require.NotNil(t, marked)
+	got, err := s.GetAgentSession(ctx, sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SessionStatusIdle, got.Status, "the racing reopen is not overwritten")
+	assert.Equal(t, models.OutcomeReviewFailed, got.OutcomeClass)
+}
+
+func TestBuildOutcomeReport(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	api := &models.Project{Name: "api", Path: "/code/api"}
+	web := &models.Project{Name: "web", Path: "/code/web"}
+	require.NoError(t, s.CreateProject(ctx, api))
+	require.NoError(t, s.CreateProject(ctx, web))
+	bug := &models.Issue{ProjectID: api.ID, Title: "Fix crash", Type: models.IssueTypeBug}
+	feature := &models.Issue{ProjectID: web.ID, Title: "Add page", Type: models.IssueTypeFeature}
+	require.NoError(t, s.CreateIssue(ctx, bug))
+	require.NoError(t, s.CreateIssue(ctx, feature))
+
+	for i, sess := range []*models.AgentSession{
+		{ProjectID: api.ID, IssueID: bug.ID, Status: models.SessionStatusCompleted, OutcomeClass: models.OutcomeCleanSuccess},
+		{ProjectID: api.ID, IssueID: bug.ID, Status: models.SessionStatusCompleted, OutcomeClass: models.OutcomeReviewFailed},
+		{ProjectID: api.ID, IssueID: bug.ID, Status: models.SessionStatusIdle, ReviewAttempt: 1, ReviewID: "r1"},
+		{ProjectID: web.ID, IssueID: feature.ID, Status: models.SessionStatusAbandoned, OutcomeClass: models.OutcomeAbandonedNoCommits},
+		{ProjectID: web.ID, IssueID: feature.ID, Status: models.SessionStatusCompleted, OutcomeClass: models.OutcomeSuccessAfterConflicts, ReviewAttempt: 1, ReviewID: "r2"},
+	} {
+		sess.Branch = "feature/" + string(rune('a'+i))
+		require.NoError(t, s.CreateAgentSession(ctx, sess))
+	}
+
+	report, err := BuildOutcomeReport(ctx, s, store.SessionListFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Total.Sessions, "open sessions are left out")
+	assert.Equal(t, 0.5, report.Total.SuccessRate)
+
+	require.Len(t, report.ByIssueType, 2)
+	assert.Equal(t, "bug", report.ByIssueType[0].Key)
+	assert.Equal(t, map[models.OutcomeClass]int{models.OutcomeCleanSuccess: 1, models.OutcomeReviewFailed: 1}, report.ByIssueType[0].Outcomes)
+
+	require.Len(t, report.ByProject, 2)
+	assert.ElementsMatch(t, []string{"api", "web"}, []string{report.ByProject[0].Name, report.ByProject[1].Name})
+
+	require.Len(t, report.ByPromptTemplate, 2)
+	assert.Equal(t, OutcomeStats{Key: PromptTemplateIssue, Sessions: 3, SuccessRate: 1.0 / 3,
+		Outcomes: map[models.OutcomeClass]int{models.OutcomeCleanSuccess: 1, models.OutcomeReviewFailed: 1, models.OutcomeAbandonedNoCommits: 1}},
+		report.ByPromptTemplate[0])
+	assert.Equal(t, PromptTemplateReviewFollowUp, report.ByPromptTemplate[1].Key)
+}
@@ -0,0 +1,22 @@
+package agent
+
+import (
+	"strings"
+
+	"github.com/joescharf/pm/internal/langstats"
+)
+
+// WithLanguageGuidance appends the guidance for a project's main languages
+// (see Project.MainLanguages) to an agent launch prompt. The prompt is
+// returned unchanged when there is no guidance for them.
+func WithLanguageGuidance(prompt string, languages []string) string {
+	var b strings.Builder
+	b.WriteString(prompt)
+	for _, lang := range languages {
+		if g := langstats.Guidance(lang); g != "" {
+			b.WriteString(" ")
+			b.WriteString(g)
+		}
+	}
+	return b.String()
+}
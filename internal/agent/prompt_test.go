@@ -0,0 +1,17 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLanguageGuidance(t *testing.T) {
+	base := "Use pm MCP tools to look up issue X and implement it."
+	assert.Equal(t, base, WithLanguageGuidance(base, nil))
+	assert.Equal(t, base, WithLanguageGuidance(base, []string{"cobol"}))
+
+	got := WithLanguageGuidance(base, []string{"go", "typescript"})
+	assert.Contains(t, got, base+" Go: ")
+	assert.Contains(t, got, " TypeScript: ")
+}
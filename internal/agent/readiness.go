@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// ReadinessStore is the subset of store.Store needed to assess issue readiness.
+type ReadinessStore interface {
+	ListIssueRelations(ctx context.Context, issueID string) ([]*models.IssueRelation, error)
+	GetIssue(ctx context.Context, id string) (*models.Issue, error)
+}
+
+// acceptanceRe matches an acceptance criteria (or definition of done)
+// heading or a Markdown task list item.
+var acceptanceRe = regexp.MustCompile(`(?im)acceptance criteria|definition of done|^\s*[-*]\s+\[[ xX]\]\s`)
+
+// AssessReadiness runs the "ready for agent" checklist against an issue: it
+// has a description or body, an AI prompt, acceptance criteria (listed, or in
+// any of its text), no unfinished issue blocking it, and an estimate. Relations that
+// cannot be read count as no blockers.
+func AssessReadiness(ctx context.Context, s ReadinessStore, issue *models.Issue) *models.IssueReadiness {
+	blocked, _ := isBlocked(ctx, s, issue.ID)
+	text := strings.Join([]string{issue.Description, issue.Body, issue.AIPrompt}, "\n")
+
+	r := &models.IssueReadiness{Checks: []models.ReadinessCheck{
+		{Name: "body", Passed: strings.TrimSpace(issue.Description+issue.Body) != "", Hint: "add a description or body"},
+		{Name: "ai_prompt", Passed: strings.TrimSpace(issue.AIPrompt) != "", Hint: "add an AI prompt (or enrich the issue)"},
+		{Name: "acceptance_criteria", Passed: len(issue.AcceptanceCriteria) > 0 || acceptanceRe.MatchString(text), Hint: "list acceptance criteria"},
+		{Name: "no_open_blockers", Passed: !blocked, Hint: "finish the issues blocking it"},
+		{Name: "estimate", Passed: strings.TrimSpace(issue.Estimate) != "", Hint: "set an estimate"},
+	}}
+	passed := 0
+	for _, c := range r.Checks {
+		if c.Passed {
+			passed++
+		}
+	}
+	r.Score = passed * 100 / len(r.Checks)
+	r.Ready = passed == len(r.Checks)
+	return r
+}
+
+// AssessReadinessAll sets Readiness on each issue.
+func AssessReadinessAll(ctx context.Context, s ReadinessStore, issues []*models.Issue) {
+	for _, issue := range issues {
+		issue.Readiness = AssessReadiness(ctx, s, issue)
+	}
+}
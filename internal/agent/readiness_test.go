@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+func TestAssessReadiness(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: t.TempDir()}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	bare := &models.Issue{ProjectID: p.ID, Title: "Add login", Status: models.IssueStatusOpen}
+	require.NoError(t, s.CreateIssue(ctx, bare))
+	r := AssessReadiness(ctx, s, bare)
+	assert.Equal(t, 20, r.Score) // only "no open blockers"
+	assert.False(t, r.Ready)
+	assert.Equal(t, []string{"add a description or body", "add an AI prompt (or enrich the issue)", "list acceptance criteria", "set an estimate"}, r.Missing())
+
+	ready := &models.Issue{
+		ProjectID: p.ID, Title: "Add logout", Status: models.IssueStatusOpen,
+		Description: "Users need to sign out.\n\n- [ ] session cookie is cleared",
+		AIPrompt:    "Add a logout handler.", Estimate: "2h",
+	}
+	require.NoError(t, s.CreateIssue(ctx, ready))
+	r = AssessReadiness(ctx, s, ready)
+	assert.Equal(t, 100, r.Score)
+	assert.True(t, r.Ready)
+	assert.Empty(t, r.Missing())
+
+	require.NoError(t, s.CreateIssueRelation(ctx, &models.IssueRelation{SourceIssueID: bare.ID, TargetIssueID: ready.ID, Type: models.RelationBlocks}))
+	r = AssessReadiness(ctx, s, ready)
+	assert.Equal(t, 80, r.Score)
+	assert.Equal(t, []string{"finish the issues blocking it"}, r.Missing())
+
+	bare.Status = models.IssueStatusDone
+	require.NoError(t, s.UpdateIssue(ctx, bare))
+	assert.True(t, AssessReadiness(ctx, s, ready).Ready)
+}
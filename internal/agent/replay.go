@@ -0,0 +1,286 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/models"
+)
+
+// ReplayStore is the subset of store.Store needed to replay a session.
+type ReplayStore interface {
+	GetAgentSession(ctx context.Context, id string) (*models.AgentSession, error)
+	GetProject(ctx context.Context, id string) (*models.Project, error)
+	GetIssue(ctx context.Context, id string) (*models.Issue, error)
+	ListSessionEvents(ctx context.Context, sessionID string) ([]*models.SessionEvent, error)
+	ListIssueReviews(ctx context.Context, issueID string) ([]*models.IssueReview, error)
+	ListIssueStatusEvents(ctx context.Context, projectID string) ([]*models.IssueStatusEvent, error)
+}
+
+// Replay entry kinds beyond the session event kinds (started, status, sync,
+// conflict, error).
+const (
+	ReplayCommit = "commit"
+	ReplayReview = "review"
+	ReplayIssue  = "issue"
+)
+
+// ReplayEntry is one step of a session replay.
+type ReplayEntry struct {
+	At      time.Time `json:"at"`
+	Kind    string    `json:"kind"`
+	Summary string    `json:"summary"`
+	Detail  string    `json:"detail,omitempty"`
+	Cause   string    `json:"cause,omitempty"`   // status steps: what made the transition
+	Problem bool      `json:"problem,omitempty"` // a step where something went wrong
+}
+
+// Replay is the reconstructed timeline of an agent session.
+type Replay struct {
+	SessionID       string        `json:"session_id"`
+	ProjectName     string        `json:"project_name"`
+	IssueID         string        `json:"issue_id,omitempty"`
+	IssueTitle      string        `json:"issue_title,omitempty"`
+	Branch          string        `json:"branch"`
+	Status          string        `json:"status"`
+	StartedAt       time.Time     `json:"started_at"`
+	EndedAt         *time.Time    `json:"ended_at,omitempty"`
+	DurationSeconds int64         `json:"duration_seconds,omitempty"` // run time, once ended
+	Entries         []ReplayEntry `json:"entries"`
+	Problems        int           `json:"problems"`
+	Summary         string        `json:"summary,omitempty"` // LLM post-mortem, when asked for
+}
+
+// BuildReplay reconstructs what happened in a session from its recorded
+// events, the commits on its branch, its reviews and its issue's status
+// changes, oldest first. gc may be nil to leave out the branch's commits;
+// the session's recorded commit summary is used instead.
+func BuildReplay(ctx context.Context, s ReplayStore, gc git.Client, sessionID string) (*Replay, error) {
+	sess, err := s.GetAgentSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	r := &Replay{
+		SessionID: sess.ID,
+		IssueID:   sess.IssueID,
+		Branch:    sess.Branch,
+		Status:    string(sess.Status),
+		StartedAt: sess.StartedAt,
+		EndedAt:   sess.EndedAt,
+		Entries:   []ReplayEntry{},
+	}
+	if sess.EndedAt != nil {
+		r.DurationSeconds = int64(sess.EndedAt.Sub(sess.StartedAt).Seconds())
+	}
+	project, _ := s.GetProject(ctx, sess.ProjectID)
+	if project != nil {
+		r.ProjectName = project.Name
+	}
+
+	events, err := s.ListSessionEvents(ctx, sess.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list session events: %w", err)
+	}
+	for _, e := range events {
+		r.Entries = append(r.Entries, eventEntry(e))
+	}
+
+	r.Entries = append(r.Entries, commitEntries(ctx, sess, project, gc)...)
+
+	if sess.IssueID != "" {
+		if issue, err := s.GetIssue(ctx, sess.IssueID); err == nil {
+			r.IssueTitle = issue.Title
+		}
+		reviews, err := s.ListIssueReviews(ctx, sess.IssueID)
+		if err != nil {
+			return nil, fmt.Errorf("list reviews: %w", err)
+		}
+		for _, rev := range reviews {
+			switch {
+			case rev.ID == sess.ReviewID:
+				r.Entries = append(r.Entries, ReplayEntry{
+					At:      rev.ReviewedAt,
+					Kind:    ReplayReview,
+					Summary: fmt.Sprintf("Follow-up %d to a failed review", sess.ReviewAttempt),
+					Detail:  reviewDetail(rev),
+				})
+			case rev.SessionID == sess.ID:
+				r.Entries = append(r.Entries, ReplayEntry{
+					At:      rev.ReviewedAt,
+					Kind:    ReplayReview,
+					Summary: "Review " + string(rev.Verdict) + "ed",
+					Detail:  reviewDetail(rev),
+					Problem: rev.Verdict == models.ReviewVerdictFail,
+				})
+			}
+		}
+
+		statusEvents, err := s.ListIssueStatusEvents(ctx, sess.ProjectID)
+		if err != nil {
+			return nil, fmt.Errorf("list issue status events: %w", err)
+		}
+		// Status history is stored to the second.
+		from := sess.StartedAt.Truncate(time.Second)
+		for _, e := range statusEvents {
+			if e.IssueID != sess.IssueID || e.FromStatus == "" || e.ChangedAt.Before(from) || (sess.EndedAt != nil && e.ChangedAt.After(*sess.EndedAt)) {
+				continue
+			}
+			r.Entries = append(r.Entries, ReplayEntry{
+				At:      e.ChangedAt,
+				Kind:    ReplayIssue,
+				Summary: fmt.Sprintf("Issue %s → %s", e.FromStatus, e.ToStatus),
+			})
+		}
+	}
+
+	sort.SliceStable(r.Entries, func(i, j int) bool { return r.Entries[i].At.Before(r.Entries[j].At) })
+	for _, e := range r.Entries {
+		if e.Problem {
+			r.Problems++
+		}
+	}
+	return r, nil
+}
+
+// eventEntry describes a recorded session event.
+func eventEntry(e *models.SessionEvent) ReplayEntry {
+	entry := ReplayEntry{At: e.At, Kind: string(e.Kind), Detail: e.Detail, Cause: e.Cause}
+	switch e.Kind {
+	case models.SessionEventStarted:
+		entry.Summary = "Started on " + e.Detail
+		entry.Detail = ""
+	case models.SessionEventStatus:
+		switch models.SessionStatus(e.Value) {
+		case models.SessionStatusActive:
+			entry.Summary = "Resumed"
+		case models.SessionStatusIdle:
+			entry.Summary = "Went idle"
+		case models.SessionStatusBlocked:
+			entry.Summary = "Blocked"
+			entry.Problem = true
+		case models.SessionStatusCompleted:
+			entry.Summary = "Completed"
+		case models.SessionStatusAbandoned:
+			entry.Summary = "Abandoned"
+			entry.Problem = true
+		default:
+			entry.Summary = "Status → " + e.Value
+		}
+	case models.SessionEventSync:
+		switch {
+		case models.ConflictState(e.Value) == models.ConflictStateSyncConflict:
+			entry.Summary = "Sync with base hit conflicts"
+			entry.Problem = true
+		case e.Detail != "":
+			entry.Summary = "Sync with base failed"
+			entry.Problem = true
+		default:
+			entry.Summary = "Synced with base"
+		}
+	case models.SessionEventConflict:
+		switch models.ConflictState(e.Value) {
+		case models.ConflictStateNone:
+			entry.Summary = "Conflicts cleared"
+		case models.ConflictStateMergeConflict:
+			entry.Summary = "Merge hit conflicts"
+			entry.Problem = true
+		default:
+			entry.Summary = "Conflict state → " + e.Value
+			entry.Problem = true
+		}
+		var files []string
+		if json.Unmarshal([]byte(e.Detail), &files) == nil {
+			entry.Detail = strings.Join(files, ", ")
+		}
+	case models.SessionEventError:
+		entry.Summary = "Operation failed"
+		entry.Problem = true
+	case models.SessionEventIssueDeleted:
+		entry.Summary = "Issue deleted"
+	default:
+		entry.Summary = string(e.Kind)
+	}
+	return entry
+}
+
+// commitEntries lists the commits on the session's branch that are not on
+// main. When the branch is gone (merged and deleted) or the session is
+// inline, it falls back to the commit summary recorded on the session.
+func commitEntries(ctx context.Context, sess *models.AgentSession, project *models.Project, gc git.Client) []ReplayEntry {
+	if gc != nil && project != nil && !sess.Inline && sess.Branch != "" {
+		if commits, err := gc.CommitLog(ctx, project.Path, "main", sess.Branch); err == nil && len(commits) > 0 {
+			entries := make([]ReplayEntry, len(commits))
+			for i, c := range commits {
+				entries[i] = ReplayEntry{At: c.Date, Kind: ReplayCommit, Summary: "Committed " + shortHash(c.Hash), Detail: c.Subject}
+			}
+			return entries
+		}
+	}
+	if sess.CommitCount == 0 {
+		return nil
+	}
+	at := sess.StartedAt
+	if sess.LastActiveAt != nil {
+		at = *sess.LastActiveAt
+	}
+	entry := ReplayEntry{At: at, Kind: ReplayCommit, Summary: fmt.Sprintf("%d commits", sess.CommitCount)}
+	if sess.LastCommitHash != "" {
+		entry.Detail = fmt.Sprintf("last %s %s", shortHash(sess.LastCommitHash), sess.LastCommitMessage)
+	}
+	return []ReplayEntry{entry}
+}
+
+func reviewDetail(rev *models.IssueReview) string {
+	parts := []string{}
+	if rev.Summary != "" {
+		parts = append(parts, rev.Summary)
+	}
+	if len(rev.FailureReasons) > 0 {
+		parts = append(parts, "failures: "+strings.Join(rev.FailureReasons, "; "))
+	}
+	return strings.Join(parts, " | ")
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// Narrative renders the replay as numbered plain-text steps, marking the
+// ones where something went wrong. It is also what an LLM summary is
+// written from.
+func (r *Replay) Narrative() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Session %s on branch %s", r.SessionID, r.Branch)
+	if r.ProjectName != "" {
+		fmt.Fprintf(&b, " of project %s", r.ProjectName)
+	}
+	fmt.Fprintf(&b, " (%s)\n", r.Status)
+	if r.IssueTitle != "" {
+		fmt.Fprintf(&b, "Issue: %s\n", r.IssueTitle)
+	}
+	fmt.Fprintf(&b, "Started %s", r.StartedAt.UTC().Format("2006-01-02 15:04 UTC"))
+	if r.EndedAt != nil {
+		fmt.Fprintf(&b, ", ended %s after %s", r.EndedAt.UTC().Format("2006-01-02 15:04 UTC"), r.EndedAt.Sub(r.StartedAt).Round(time.Minute))
+	}
+	fmt.Fprintf(&b, "; %d steps, %d problems\n\n", len(r.Entries), r.Problems)
+
+	for i, e := range r.Entries {
+		mark := " "
+		if e.Problem {
+			mark = "!"
+		}
+		fmt.Fprintf(&b, "%s %2d. %s  %s\n", mark, i+1, e.At.UTC().Format("01-02 15:04"), e.Summary)
+		if e.Detail != "" {
+			fmt.Fprintf(&b, "        %s\n", e.Detail)
+		}
+	}
+	return b.String()
+}
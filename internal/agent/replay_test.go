@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+func TestBuildReplay(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: t.TempDir()}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Add login", Status: models.IssueStatusOpen}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+
+	sess := &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "feature/login", Status: models.SessionStatusActive}
+	require.NoError(t, s.CreateAgentSession(ctx, sess))
+	issue.Status = models.IssueStatusInProgress
+	require.NoError(t, s.UpdateIssue(ctx, issue))
+
+	synced := time.Now().UTC().Add(time.Minute)
+	sess.LastSyncAt = &synced
+	sess.ConflictState = models.ConflictStateSyncConflict
+	sess.ConflictFiles = `["login.go"]`
+	sess.LastError = "conflict in login.go"
+	sess.CommitCount = 2
+	sess.LastCommitHash = "abcdef1234567"
+	sess.LastCommitMessage = "Add login form"
+	require.NoError(t, s.UpdateAgentSession(ctx, sess))
+
+	ended := time.Now().UTC().Add(2 * time.Minute)
+	sess.Status = models.SessionStatusAbandoned
+	sess.EndedAt = &ended
+	require.NoError(t, s.UpdateAgentSession(ctx, sess))
+
+	require.NoError(t, s.CreateIssueReview(ctx, &models.IssueReview{
+		IssueID: issue.ID, SessionID: sess.ID, Verdict: models.ReviewVerdictFail,
+		Summary: "Tests missing", FailureReasons: []string{"no tests"}, ReviewedAt: time.Now().UTC().Add(90 * time.Second),
+	}))
+
+	r, err := BuildReplay(ctx, s, nil, sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "api", r.ProjectName)
+	assert.Equal(t, "Add login", r.IssueTitle)
+
+	var kinds, summaries []string
+	for _, e := range r.Entries {
+		kinds = append(kinds, e.Kind)
+		summaries = append(summaries, e.Summary)
+	}
+	assert.Equal(t, []string{"started", "commit", "issue", "sync", "review", "status"}, kinds)
+	assert.Equal(t, []string{
+		"Started on feature/login",
+		"2 commits", // no branch to read, so the session's summary
+		"Issue open → in_progress",
+		"Sync with base hit conflicts",
+		"Review failed",
+		"Abandoned",
+	}, summaries)
+	assert.Equal(t, 3, r.Problems, "conflict, failed review, abandon")
+	assert.Equal(t, int64(ended.Sub(r.StartedAt).Seconds()), r.DurationSeconds)
+
+	n := r.Narrative()
+	assert.Contains(t, n, "Session "+sess.ID+" on branch feature/login of project api (abandoned)")
+	assert.Contains(t, n, "Issue: Add login")
+	assert.Contains(t, n, "Started "+r.StartedAt.UTC().Format("2006-01-02 15:04 UTC"))
+	assert.Contains(t, n, "! ")
+	assert.Contains(t, n, "last abcdef1 Add login form")
+
+	_, err = BuildReplay(ctx, s, nil, "missing")
+	assert.Error(t, err)
+}
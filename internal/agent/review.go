@@ -0,0 +1,131 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/terminal"
+)
+
+// ReviewStore is the subset of store.Store needed for review round-trips.
+type ReviewStore interface {
+	SessionStore
+	GetProject(ctx context.Context, id string) (*models.Project, error)
+	CreateAgentSession(ctx context.Context, session *models.AgentSession) error
+}
+
+// WorktreeOpener creates (or reopens) the worktree for a branch.
+// wt.Client satisfies this interface.
+type WorktreeOpener interface {
+	Create(repoPath, branch string) error
+}
+
+// ReviewFollowUp describes the session launched to address a failed review.
+type ReviewFollowUp struct {
+	SessionID     string `json:"session_id"`
+	Branch        string `json:"branch"`
+	WorktreePath  string `json:"worktree_path"`
+	ReviewAttempt int    `json:"review_attempt"`
+	Command       string `json:"command"`
+}
+
+// RelaunchAfterFailedReview starts a follow-up session on the reviewed session's
+// branch, seeded with the review's failure reasons. The reviewed session is
+// ended (without cascading the issue) so the branch can host the new session.
+// maxAttempts caps the number of follow-ups per review chain; 0 means no limit.
+func RelaunchAfterFailedReview(ctx context.Context, s ReviewStore, wtc WorktreeOpener, review *models.IssueReview, maxAttempts int) (*ReviewFollowUp, error) {
+	if review.Verdict != models.ReviewVerdictFail {
+		return nil, fmt.Errorf("review %s did not fail", review.ID)
+	}
+	if review.SessionID == "" {
+		return nil, fmt.Errorf("review %s has no linked session", review.ID)
+	}
+
+	prev, err := s.GetAgentSession(ctx, review.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt := prev.ReviewAttempt + 1
+	if maxAttempts > 0 && attempt > maxAttempts {
+		return nil, fmt.Errorf("review attempt limit reached (%d)", maxAttempts)
+	}
+
+	project, err := s.GetProject(ctx, prev.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	worktreePath := prev.WorktreePath
+	if worktreePath == "" {
+		branchParts := strings.Split(prev.Branch, "/")
+		worktreePath = filepath.Join(project.Path+".worktrees", branchParts[len(branchParts)-1])
+	}
+
+	if wtc != nil {
+		if err := wtc.Create(project.Path, prev.Branch); err != nil {
+			return nil, fmt.Errorf("open worktree: %w", err)
+		}
+	}
+
+	// Only one open session is allowed per branch. If the follow-up cannot
+	// be created, the reviewed session gets its status back.
+	reviewed := *prev
+	if prev.Status.IsOpen() {
+		if err := Transition(ctx, s, prev, models.SessionStatusCompleted, CauseReview); err != nil {
+			return nil, fmt.Errorf("end reviewed session: %w", err)
+		}
+	}
+
+	session := &models.AgentSession{
+		ProjectID:     project.ID,
+		IssueID:       review.IssueID,
+		Branch:        prev.Branch,
+		WorktreePath:  worktreePath,
+		Status:        models.SessionStatusActive,
+		ReviewAttempt: attempt,
+		ReviewID:      review.ID,
+	}
+	if tl, ok := wtc.(TerminalLocator); ok {
+		RecordWTTerminal(session, tl)
+	}
+	if err := s.CreateAgentSession(ctx, session); err != nil {
+		if prev.Status != reviewed.Status {
+			if rerr := s.TransitionAgentSession(ctx, &reviewed, prev.Status, CauseReview); rerr != nil {
+				return nil, fmt.Errorf("create follow-up session: %w (restoring session %s: %v)", err, prev.ID, rerr)
+			}
+		}
+		return nil, fmt.Errorf("create follow-up session: %w", err)
+	}
+
+	return &ReviewFollowUp{
+		SessionID:     session.ID,
+		Branch:        session.Branch,
+		WorktreePath:  worktreePath,
+		ReviewAttempt: attempt,
+		Command:       fmt.Sprintf("cd %s && claude %s", terminal.ShellQuote(worktreePath), terminal.ShellQuote(ReviewFollowUpPrompt(review, attempt))),
+	}, nil
+}
+
+// ReviewFollowUpPrompt builds the agent prompt for addressing a failed review.
+func ReviewFollowUpPrompt(review *models.IssueReview, attempt int) string {
+	issueRef := review.IssueID
+	if len(issueRef) > 12 {
+		issueRef = issueRef[:12]
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Issue %s failed review (follow-up attempt %d). Use pm MCP tools to look up the issue, fix the following review failures, and update the issue status when complete:", issueRef, attempt)
+	reasons := review.FailureReasons
+	if len(reasons) == 0 {
+		reasons = []string{review.Summary}
+	}
+	for _, r := range reasons {
+		b.WriteString("\n- ")
+		b.WriteString(r)
+	}
+	return b.String()
+}
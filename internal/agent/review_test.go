@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// failingCreateStore fails to create sessions.
+type failingCreateStore struct {
+	*store.MemoryStore
+}
+
+func (failingCreateStore) CreateAgentSession(context.Context, *models.AgentSession) error {
+	return errors.New("disk full")
+}
+
+func setupFailedReview(t *testing.T, s *store.MemoryStore) (*models.AgentSession, *models.IssueReview) {
+	t.Helper()
+	ctx := context.Background()
+	p := &models.Project{Name: "relaunch", Path: "/tmp/relaunch $HOME"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Fix it", Status: models.IssueStatusInProgress, Priority: models.IssuePriorityMedium, Type: models.IssueTypeBug}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	sess := &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "feature/fix", WorktreePath: "/tmp/wt's dir", Status: models.SessionStatusIdle}
+	require.NoError(t, s.CreateAgentSession(ctx, sess))
+	review := &models.IssueReview{
+		IssueID:        issue.ID,
+		SessionID:      sess.ID,
+		Verdict:        models.ReviewVerdictFail,
+		FailureReasons: []string{"tests call $(touch subst) and `touch backtick` with $HOME"},
+	}
+	return sess, review
+}
+
+func TestRelaunchAfterFailedReview_QuotesCommand(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	_, review := setupFailedReview(t, s)
+
+	fu, err := RelaunchAfterFailedReview(ctx, s, nil, review, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, fu.ReviewAttempt)
+
+	// The shell hands the path and prompt over verbatim. cd and claude are
+	// stubbed, and no PATH is searched, so nothing but printf runs.
+	dir := t.TempDir()
+	cmd := exec.Command("/bin/sh", "-c", `cd() { printf '%s\n' "$1"; }; claude() { printf '%s\n' "$1"; }; `+fu.Command)
+	cmd.Dir = dir
+	cmd.Env = []string{"PATH=" + filepath.Join(dir, "nobin")}
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	lines := strings.SplitN(string(out), "\n", 2)
+	assert.Equal(t, "/tmp/wt's dir", lines[0])
+	assert.Equal(t, ReviewFollowUpPrompt(review, 1)+"\n", lines[1])
+	assert.NoFileExists(t, filepath.Join(dir, "subst"))
+	assert.NoFileExists(t, filepath.Join(dir, "backtick"))
+}
+
+func TestRelaunchAfterFailedReview_RestoresSessionOnFailure(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	sess, review := setupFailedReview(t, s)
+
+	_, err := RelaunchAfterFailedReview(ctx, failingCreateStore{s}, nil, review, 0)
+	require.ErrorContains(t, err, "disk full")
+
+	got, err := s.GetAgentSession(ctx, sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SessionStatusIdle, got.Status, "reviewed session is reopened")
+	assert.Nil(t, got.EndedAt)
+}
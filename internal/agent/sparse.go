@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// ParseSparsePaths splits a comma-separated list of sparse-checkout paths,
+// dropping blanks and trailing slashes.
+func ParseSparsePaths(s string) []string {
+	var paths []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.Trim(strings.TrimSpace(p), "/"); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// SparsePaths resolves the directories a sparse worktree for issues checks
+// out: the project's configured paths, the explicit paths, and the
+// directories of repo paths the issues mention (e.g. "internal/api" or
+// "ui/src/App.tsx"). Nested paths are folded into their parent. An empty
+// result means nothing resolved and the worktree should be a full checkout.
+func SparsePaths(project *models.Project, issues []*models.Issue, explicit []string) []string {
+	candidates := append(ParseSparsePaths(project.SparsePaths), explicit...)
+	for _, issue := range issues {
+		for _, text := range []string{issue.Title, issue.Description, issue.Body, issue.AIPrompt} {
+			candidates = append(candidates, mentionedDirs(project.Path, text)...)
+		}
+	}
+
+	seen := map[string]bool{}
+	var paths []string
+	for _, p := range candidates {
+		p = filepath.ToSlash(filepath.Clean(p))
+		if p == "." || p == ".." || strings.HasPrefix(p, "../") || filepath.IsAbs(p) || seen[p] {
+			continue
+		}
+		seen[p] = true
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	// Sorted, a path's ancestors come before it.
+	var folded []string
+	for _, p := range paths {
+		if n := len(folded); n > 0 && strings.HasPrefix(p, folded[n-1]+"/") {
+			continue
+		}
+		folded = append(folded, p)
+	}
+	return folded
+}
+
+// mentionedDirs returns the directories of slash-separated paths in text that
+// exist under repoPath. A file contributes its parent directory; files at the
+// repo root are always checked out in cone mode and contribute nothing.
+func mentionedDirs(repoPath, text string) []string {
+	var dirs []string
+	for _, tok := range strings.FieldsFunc(text, func(r rune) bool {
+		return strings.ContainsRune(" \t\n\r`'\"()[]{}<>,;", r)
+	}) {
+		tok = strings.TrimPrefix(strings.TrimRight(tok, ".:!?"), "./")
+		if !strings.Contains(tok, "/") || strings.Contains(tok, "://") || strings.HasPrefix(tok, "/") {
+			continue
+		}
+		tok = strings.TrimSuffix(tok, "/")
+		info, err := os.Stat(filepath.Join(repoPath, filepath.FromSlash(tok)))
+		if err != nil {
+			continue
+		}
+		if !info.IsDir() {
+			tok = filepath.ToSlash(filepath.Dir(tok))
+		}
+		if tok != "." {
+			dirs = append(dirs, tok)
+		}
+	}
+	return dirs
+}
+
+// LaunchSparsePaths returns the sparse-checkout paths for a new worktree, or
+// nil for a full checkout. A worktree is sparse when the project configures
+// sparse paths or the launch asks for one (sparse, or explicit paths).
+func LaunchSparsePaths(project *models.Project, issues []*models.Issue, sparse bool, explicit []string) []string {
+	if !sparse && len(explicit) == 0 && project.SparsePaths == "" {
+		return nil
+	}
+	return SparsePaths(project, issues, explicit)
+}
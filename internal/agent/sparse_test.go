@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+func TestParseSparsePaths(t *testing.T) {
+	assert.Equal(t, []string{"internal/api", "ui"}, ParseSparsePaths(" internal/api/, ,ui"))
+	assert.Nil(t, ParseSparsePaths(""))
+}
+
+func TestSparsePaths(t *testing.T) {
+	repo := t.TempDir()
+	for _, f := range []string{"internal/api/api.go", "internal/api/v2/v2.go", "ui/src/App.tsx", "README.md"} {
+		path := filepath.Join(repo, f)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, nil, 0o644))
+	}
+	project := &models.Project{Path: repo, SparsePaths: "docs, internal/api/v2"}
+
+	issue := &models.Issue{
+		Title:       "Fix the login endpoint",
+		Description: "The handler in `internal/api/api.go` returns 500; see https://example.com/a/b.",
+		AIPrompt:    "Also update ui/src/App.tsx, README.md and missing/dir.",
+	}
+	assert.Equal(t, []string{"docs", "internal/api", "ui/src", "vendor/big"},
+		SparsePaths(project, []*models.Issue{issue}, []string{"vendor/big/", "../outside"}))
+
+	assert.Empty(t, SparsePaths(&models.Project{Path: repo}, []*models.Issue{{Title: "Tidy up"}}, nil))
+}
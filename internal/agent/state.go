@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// Transition causes, recorded with the status event of each transition.
+const (
+	CauseLaunch          = "launch"           // an idle session resumed by an agent launch
+	CauseClose           = "close"            // pm agent close / the close endpoint and tool
+	CauseReactivate      = "reactivate"       // a completed or abandoned session reopened
+	CauseBlock           = "block"            // marked blocked
+	CauseUnblock         = "unblock"          // blocked session released
+	CauseReconcile       = "reconcile"        // worktree or claude process state changed
+	CauseMerge           = "merge"            // branch merged into base
+	CauseWorktreeDeleted = "worktree_deleted" // worktree removed through pm
+	CauseReview          = "review"           // ended for a review follow-up session
+
+	CauseIssueLockExpired = "issue_lock_expired" // idled so a launch can take its expired issue lock
+)
+
+// ErrIllegalTransition is returned for a status change the session state
+// machine does not allow.
+var ErrIllegalTransition = errors.New("illegal session status transition")
+
+// transitions lists the statuses each status may move to. Open sessions can
+// go anywhere; ended sessions only come back as idle.
+var transitions = map[models.SessionStatus][]models.SessionStatus{
+	models.SessionStatusActive:    {models.SessionStatusIdle, models.SessionStatusBlocked, models.SessionStatusCompleted, models.SessionStatusAbandoned},
+	models.SessionStatusIdle:      {models.SessionStatusActive, models.SessionStatusBlocked, models.SessionStatusCompleted, models.SessionStatusAbandoned},
+	models.SessionStatusBlocked:   {models.SessionStatusIdle, models.SessionStatusCompleted, models.SessionStatusAbandoned},
+	models.SessionStatusCompleted: {models.SessionStatusIdle},
+	models.SessionStatusAbandoned: {models.SessionStatusIdle},
+}
+
+// CanTransition reports whether a session may move from one status to
+// another. Staying in the same status is always allowed.
+func CanTransition(from, to models.SessionStatus) bool {
+	return from == to || slices.Contains(transitions[from], to)
+}
+
+// TransitionStore persists guarded session transitions. store.Store
+// satisfies this interface.
+type TransitionStore interface {
+	TransitionAgentSession(ctx context.Context, session *models.AgentSession, from models.SessionStatus, cause string) error
+}
+
+// Transition moves session to status to and saves it, along with any other
+// changes the caller made to it. It keeps the time fields consistent: a
+// session going active gets LastActiveAt, an ending one EndedAt (and loses
+// its blocked reason), a reopened one loses EndedAt. An ending session gets
+// its OutcomeClass (see ClassifyOutcome). The write only succeeds
+// if the stored session still has the status session was read with, so of
+// two racing writers (say the reconciler abandoning a session that is being
+// closed) the second fails with store.ErrSessionStatusChanged instead of
+// overwriting the first. On any error session is left as it was.
+func Transition(ctx context.Context, s TransitionStore, session *models.AgentSession, to models.SessionStatus, cause string) error {
+	from := session.Status
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: session %s cannot go from %s to %s", ErrIllegalTransition, session.ID, from, to)
+	}
+
+	prev := *session
+	now := time.Now().UTC()
+	session.Status = to
+	switch to {
+	case models.SessionStatusActive:
+		session.LastActiveAt = &now
+	case models.SessionStatusCompleted, models.SessionStatusAbandoned:
+		if from != to {
+			session.EndedAt = &now
+		}
+	}
+	if to != models.SessionStatusBlocked {
+		clearBlocked(session)
+	}
+	if to.IsOpen() {
+		session.EndedAt = nil
+	}
+	if from != to {
+		classifyEnding(ctx, s, session)
+	}
+
+	if err := s.TransitionAgentSession(ctx, session, from, cause); err != nil {
+		*session = prev
+		return err
+	}
+	return nil
+}
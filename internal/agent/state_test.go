@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to models.SessionStatus
+		want     bool
+	}{
+		{models.SessionStatusActive, models.SessionStatusIdle, true},
+		{models.SessionStatusIdle, models.SessionStatusActive, true},
+		{models.SessionStatusIdle, models.SessionStatusIdle, true},
+		{models.SessionStatusBlocked, models.SessionStatusIdle, true},
+		{models.SessionStatusBlocked, models.SessionStatusActive, false},
+		{models.SessionStatusCompleted, models.SessionStatusIdle, true},
+		{models.SessionStatusCompleted, models.SessionStatusActive, false},
+		{models.SessionStatusAbandoned, models.SessionStatusCompleted, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, CanTransition(tt.from, tt.to), "%s -> %s", tt.from, tt.to)
+	}
+}
+
+func TestTransition(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	sess := &models.AgentSession{ProjectID: p.ID, Branch: "feature/a", Status: models.SessionStatusIdle}
+	require.NoError(t, s.CreateAgentSession(ctx, sess))
+
+	sess.BlockedReason = "waiting on API key"
+	require.NoError(t, Transition(ctx, s, sess, models.SessionStatusBlocked, CauseBlock))
+
+	err := Transition(ctx, s, sess, models.SessionStatusActive, CauseLaunch)
+	require.ErrorIs(t, err, ErrIllegalTransition)
+	assert.Equal(t, models.SessionStatusBlocked, sess.Status, "left as it was")
+
+	require.NoError(t, Transition(ctx, s, sess, models.SessionStatusAbandoned, CauseClose))
+	assert.NotNil(t, sess.EndedAt)
+	assert.Empty(t, sess.BlockedReason, "ending clears the blocked reason")
+
+	require.NoError(t, Transition(ctx, s, sess, models.SessionStatusIdle, CauseReactivate))
+	assert.Nil(t, sess.EndedAt)
+	require.NoError(t, Transition(ctx, s, sess, models.SessionStatusActive, CauseLaunch))
+	assert.NotNil(t, sess.LastActiveAt)
+}
+
+// The reconciler and a close read the same active session; whichever writes
+// second must not overwrite the first.
+func TestTransition_RacingWriters(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	sess := &models.AgentSession{ProjectID: p.ID, Branch: "feature/a", Status: models.SessionStatusActive}
+	require.NoError(t, s.CreateAgentSession(ctx, sess))
+
+	reconciler, err := s.GetAgentSession(ctx, sess.ID)
+	require.NoError(t, err)
+
+	closed, err := CloseSession(ctx, s, sess.ID, models.SessionStatusCompleted)
+	require.NoError(t, err)
+	assert.Equal(t, models.SessionStatusCompleted, closed.Status)
+
+	err = Transition(ctx, s, reconciler, models.SessionStatusAbandoned, CauseReconcile)
+	require.ErrorIs(t, err, store.ErrSessionStatusChanged)
+	assert.Equal(t, models.SessionStatusActive, reconciler.Status)
+	assert.Nil(t, reconciler.EndedAt)
+
+	got, err := s.GetAgentSession(ctx, sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SessionStatusCompleted, got.Status)
+
+	events, err := s.ListSessionEvents(ctx, sess.ID)
+	require.NoError(t, err)
+	last := events[len(events)-1]
+	assert.Equal(t, "active", last.From)
+	assert.Equal(t, "completed", last.Value)
+	assert.Equal(t, CauseClose, last.Cause)
+}
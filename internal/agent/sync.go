@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/joescharf/pm/internal/models"
@@ -13,6 +14,11 @@ type ReconcileOption func(*reconcileConfig)
 
 type reconcileConfig struct {
 	processDetector ProcessDetector
+	fingerprinter   Fingerprinter
+
+	// Set by Reconciler to honor its cooldown.
+	skip    func(*models.AgentSession) bool
+	changed func(*models.AgentSession)
 }
 
 // WithProcessDetector enables active/idle transitions based on claude process detection.
@@ -22,13 +28,23 @@ func WithProcessDetector(d ProcessDetector) ReconcileOption {
 	}
 }
 
+// WithFingerprinter records a worktree fingerprint whenever a session goes idle,
+// for the co-editing guard on resume.
+func WithFingerprinter(f Fingerprinter) ReconcileOption {
+	return func(c *reconcileConfig) {
+		c.fingerprinter = f
+	}
+}
+
 // ReconcileSessions checks sessions and:
-// 1. Marks active/idle sessions with missing worktree directories as abandoned.
+// 1. Marks active/idle/blocked sessions with missing worktree directories as abandoned.
 // 2. Recovers abandoned sessions whose worktree still exists back to idle.
 // 3. If a ProcessDetector is provided:
 //   - Transitions idle -> active when a claude process is detected in the worktree.
 //   - Transitions active -> idle when no claude process is detected.
 //
+// Inline sessions are skipped: they have no worktree of their own.
+//
 // Returns the count of sessions updated.
 func ReconcileSessions(ctx context.Context, s SessionStore, sessions []*models.AgentSession, opts ...ReconcileOption) int {
 	cfg := &reconcileConfig{}
@@ -38,67 +54,145 @@ func ReconcileSessions(ctx context.Context, s SessionStore, sessions []*models.A
 
 	cleaned := 0
 	for _, sess := range sessions {
-		if sess.Status == models.SessionStatusCompleted {
+		if cfg.skip != nil && cfg.skip(sess) {
 			continue
 		}
-		if sess.WorktreePath == "" {
+		target, ok := reconcileTarget(sess, sessions, cfg)
+		if !ok {
 			continue
 		}
-		wtExists := true
-		if _, err := os.Stat(sess.WorktreePath); err != nil {
-			wtExists = false
-		}
 
-		switch {
-		case !wtExists && (sess.Status == models.SessionStatusActive || sess.Status == models.SessionStatusIdle):
+		var err error
+		switch target {
+		case models.SessionStatusAbandoned:
 			// Worktree is gone — abandon the session
-			if _, err := CloseSession(ctx, s, sess.ID, models.SessionStatusAbandoned); err == nil {
-				cleaned++
-			}
-		case wtExists && sess.Status == models.SessionStatusAbandoned:
-			// Worktree recovered/still exists — transition back to idle,
-			// but only if no other active/idle session owns this branch.
-			if branchHasLiveSession(sessions, sess) {
-				continue
-			}
-			now := time.Now().UTC()
-			sess.LastActiveAt = &now
-			sess.Status = models.SessionStatusIdle
-			sess.EndedAt = nil
-			if err := s.UpdateAgentSession(ctx, sess); err == nil {
-				cleaned++
-			}
-		case wtExists && cfg.processDetector != nil && sess.Status == models.SessionStatusIdle:
-			// Idle + claude running → active
-			if cfg.processDetector.IsClaudeRunning(sess.WorktreePath) {
+			_, err = closeSession(ctx, s, sess.ID, models.SessionStatusAbandoned, CauseReconcile)
+		case models.SessionStatusIdle:
+			if sess.Status == models.SessionStatusAbandoned {
+				// Worktree recovered/still exists — back to idle
 				now := time.Now().UTC()
 				sess.LastActiveAt = &now
-				sess.Status = models.SessionStatusActive
-				if err := s.UpdateAgentSession(ctx, sess); err == nil {
-					cleaned++
+			} else if cfg.fingerprinter != nil {
+				// Active + no claude running → idle
+				if fp := worktreeFingerprint(ctx, sess, cfg.fingerprinter); fp != "" {
+					sess.WorktreeFingerprint = fp
 				}
 			}
-		case wtExists && cfg.processDetector != nil && sess.Status == models.SessionStatusActive:
-			// Active + no claude running → idle
-			if !cfg.processDetector.IsClaudeRunning(sess.WorktreePath) {
-				sess.Status = models.SessionStatusIdle
-				if err := s.UpdateAgentSession(ctx, sess); err == nil {
-					cleaned++
-				}
+			err = Transition(ctx, s, sess, models.SessionStatusIdle, CauseReconcile)
+		case models.SessionStatusActive:
+			// Idle + claude running → active
+			sess.WorktreeFingerprint = ""
+			err = Transition(ctx, s, sess, models.SessionStatusActive, CauseReconcile)
+		}
+		if err == nil {
+			cleaned++
+			if cfg.changed != nil {
+				cfg.changed(sess)
 			}
 		}
 	}
 	return cleaned
 }
 
-// branchHasLiveSession checks if another active or idle session exists for the same branch.
+// PendingReconciliation reports, by session ID, the status ReconcileSessions
+// would move each session to, without writing anything.
+func PendingReconciliation(sessions []*models.AgentSession, opts ...ReconcileOption) map[string]models.SessionStatus {
+	cfg := &reconcileConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	pending := make(map[string]models.SessionStatus)
+	for _, sess := range sessions {
+		if target, ok := reconcileTarget(sess, sessions, cfg); ok {
+			pending[sess.ID] = target
+		}
+	}
+	return pending
+}
+
+// reconcileTarget decides the status reconciliation moves a session to, if
+// any. sessions is the full set being reconciled, consulted before reviving
+// an abandoned session.
+func reconcileTarget(sess *models.AgentSession, sessions []*models.AgentSession, cfg *reconcileConfig) (models.SessionStatus, bool) {
+	if sess.Status == models.SessionStatusCompleted || sess.Inline {
+		return "", false
+	}
+	if sess.WorktreePath == "" {
+		return "", false
+	}
+	wtExists := true
+	if _, err := os.Stat(sess.WorktreePath); err != nil {
+		wtExists = false
+	}
+
+	switch {
+	case !wtExists && sess.Status.IsOpen():
+		return models.SessionStatusAbandoned, true
+	case wtExists && sess.Status == models.SessionStatusAbandoned:
+		// Only if no other open session owns this branch.
+		if branchHasLiveSession(sessions, sess) {
+			return "", false
+		}
+		return models.SessionStatusIdle, true
+	case wtExists && cfg.processDetector != nil && sess.Status == models.SessionStatusIdle:
+		if cfg.processDetector.IsClaudeRunning(sess.WorktreePath) {
+			return models.SessionStatusActive, true
+		}
+	case wtExists && cfg.processDetector != nil && sess.Status == models.SessionStatusActive:
+		if !cfg.processDetector.IsClaudeRunning(sess.WorktreePath) {
+			return models.SessionStatusIdle, true
+		}
+	}
+	return "", false
+}
+
+// Reconciler runs ReconcileSessions repeatedly with a per-session cooldown:
+// a session it changed is left alone until the cooldown has passed, so a
+// flapping worktree or agent process does not rewrite the same row on every
+// run. It is safe for concurrent use.
+type Reconciler struct {
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	changed map[string]time.Time // session ID -> when this reconciler last changed it
+}
+
+// NewReconciler returns a Reconciler with the given cooldown; 0 disables it.
+func NewReconciler(cooldown time.Duration) *Reconciler {
+	return &Reconciler{cooldown: cooldown, now: time.Now, changed: make(map[string]time.Time)}
+}
+
+// Reconcile runs ReconcileSessions over sessions, skipping those changed
+// within the cooldown. Returns the count of sessions updated.
+func (r *Reconciler) Reconcile(ctx context.Context, s SessionStore, sessions []*models.AgentSession, opts ...ReconcileOption) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	for id, at := range r.changed {
+		if now.Sub(at) >= r.cooldown {
+			delete(r.changed, id)
+		}
+	}
+	opts = append(opts,
+		func(c *reconcileConfig) {
+			c.skip = func(sess *models.AgentSession) bool {
+				_, cooling := r.changed[sess.ID]
+				return cooling
+			}
+			c.changed = func(sess *models.AgentSession) { r.changed[sess.ID] = now }
+		})
+	return ReconcileSessions(ctx, s, sessions, opts...)
+}
+
+// branchHasLiveSession checks if another active, idle or blocked session exists for the same branch.
 func branchHasLiveSession(sessions []*models.AgentSession, target *models.AgentSession) bool {
 	for _, s := range sessions {
 		if s.ID == target.ID {
 			continue
 		}
-		if s.Branch == target.Branch && s.ProjectID == target.ProjectID &&
-			(s.Status == models.SessionStatusActive || s.Status == models.SessionStatusIdle) {
+		if s.Branch == target.Branch && s.ProjectID == target.ProjectID && s.Status.IsOpen() {
 			return true
 		}
 	}
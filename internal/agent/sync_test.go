@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/joescharf/pm/internal/models"
 	"github.com/stretchr/testify/assert"
@@ -95,6 +96,31 @@ func TestReconcileSessions_AbandonedWithMissingWorktree_StaysAbandoned(t *testin
 	assert.Equal(t, models.SessionStatusAbandoned, ms.sessions["sess-1"].Status)
 }
 
+func TestReconcileSessions_SkipsInline(t *testing.T) {
+	abandoned := &models.AgentSession{
+		ID:           "sess-1",
+		WorktreePath: t.TempDir(),
+		Status:       models.SessionStatusAbandoned,
+		Inline:       true,
+	}
+	active := &models.AgentSession{
+		ID:           "sess-2",
+		WorktreePath: t.TempDir(),
+		Status:       models.SessionStatusActive,
+		Inline:       true,
+	}
+	ms := &mockSessionStore{
+		sessions: map[string]*models.AgentSession{"sess-1": abandoned, "sess-2": active},
+		issues:   map[string]*models.Issue{},
+	}
+
+	detector := &mockProcessDetector{activePaths: map[string]bool{}}
+	cleaned := ReconcileSessions(context.Background(), ms, []*models.AgentSession{abandoned, active}, WithProcessDetector(detector))
+	assert.Equal(t, 0, cleaned)
+	assert.Equal(t, models.SessionStatusAbandoned, ms.sessions["sess-1"].Status)
+	assert.Equal(t, models.SessionStatusActive, ms.sessions["sess-2"].Status)
+}
+
 func TestReconcileSessions_SkipsTerminal(t *testing.T) {
 	session := &models.AgentSession{
 		ID:           "sess-1",
@@ -222,3 +248,46 @@ func TestReconcileSessions_AbandonedNotRecovered_WhenBranchHasLiveSession(t *tes
 	assert.Equal(t, models.SessionStatusIdle, ms.sessions["sess-live"].Status)
 	assert.Equal(t, models.SessionStatusAbandoned, ms.sessions["sess-dup"].Status)
 }
+
+func TestPendingReconciliation_DoesNotWrite(t *testing.T) {
+	dir := t.TempDir()
+	gone := &models.AgentSession{ID: "sess-gone", WorktreePath: "/nonexistent/path", Status: models.SessionStatusIdle}
+	running := &models.AgentSession{ID: "sess-run", WorktreePath: dir, Status: models.SessionStatusIdle}
+	steady := &models.AgentSession{ID: "sess-steady", WorktreePath: t.TempDir(), Status: models.SessionStatusIdle}
+	detector := &mockProcessDetector{activePaths: map[string]bool{dir: true}}
+
+	pending := PendingReconciliation([]*models.AgentSession{gone, running, steady}, WithProcessDetector(detector))
+	assert.Equal(t, map[string]models.SessionStatus{
+		"sess-gone": models.SessionStatusAbandoned,
+		"sess-run":  models.SessionStatusActive,
+	}, pending)
+	assert.Equal(t, models.SessionStatusIdle, gone.Status)
+	assert.Equal(t, models.SessionStatusIdle, running.Status)
+}
+
+func TestReconciler_Cooldown(t *testing.T) {
+	dir := t.TempDir()
+	session := &models.AgentSession{ID: "sess-1", WorktreePath: dir, Status: models.SessionStatusIdle}
+	ms := &mockSessionStore{
+		sessions: map[string]*models.AgentSession{"sess-1": session},
+		issues:   map[string]*models.Issue{},
+	}
+	detector := &mockProcessDetector{activePaths: map[string]bool{dir: true}}
+	now := time.Now()
+	r := NewReconciler(time.Minute)
+	r.now = func() time.Time { return now }
+	sessions := []*models.AgentSession{session}
+
+	assert.Equal(t, 1, r.Reconcile(context.Background(), ms, sessions, WithProcessDetector(detector)))
+	assert.Equal(t, models.SessionStatusActive, session.Status)
+
+	// The agent exits right away: the session is left alone while cooling down.
+	detector.activePaths[dir] = false
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, 0, r.Reconcile(context.Background(), ms, sessions, WithProcessDetector(detector)))
+	assert.Equal(t, models.SessionStatusActive, session.Status)
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, 1, r.Reconcile(context.Background(), ms, sessions, WithProcessDetector(detector)))
+	assert.Equal(t, models.SessionStatusIdle, session.Status)
+}
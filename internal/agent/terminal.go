@@ -0,0 +1,25 @@
+package agent
+
+import (
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/terminal"
+)
+
+// TerminalLocator looks up the iTerm session wt opened for a worktree.
+// wt.Client satisfies this interface.
+type TerminalLocator interface {
+	TerminalID(worktreePath string) string
+}
+
+// RecordWTTerminal records the iTerm window wt opened for the session's
+// worktree, so focus and close can target it by ID rather than by worktree
+// path. The caller persists the session.
+func RecordWTTerminal(sess *models.AgentSession, l TerminalLocator) {
+	if l == nil || sess.WorktreePath == "" {
+		return
+	}
+	if id := l.TerminalID(sess.WorktreePath); id != "" {
+		sess.TerminalBackend = terminal.BackendITerm
+		sess.TerminalID = id
+	}
+}
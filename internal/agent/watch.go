@@ -0,0 +1,173 @@
+package agent
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// WatchStore is the store access RunWorktreeWatcher needs.
+type WatchStore interface {
+	SessionToucher
+	ListAgentSessionsByStatus(ctx context.Context, projectID string, statuses []models.SessionStatus, limit int) ([]*models.AgentSession, error)
+}
+
+// maxWatchedDirs caps the directories watched per worktree, to stay within
+// the system's limit on file watches.
+const maxWatchedDirs = 2000
+
+// unwatchedDirs are skipped when watching a worktree: git internals and
+// dependency or cache trees, which are large and change without anyone
+// working on the session.
+var unwatchedDirs = map[string]bool{
+	".git": true, "node_modules": true, "vendor": true, ".venv": true,
+	"__pycache__": true, "target": true,
+}
+
+// RunWorktreeWatcher turns file changes in the worktrees of open sessions
+// into heartbeats until ctx is done. Which worktrees are watched is refreshed
+// every rescan. File watches are not recursive, so every directory of a
+// worktree is watched, except unwatchedDirs. Errors are passed to onErr.
+func RunWorktreeWatcher(ctx context.Context, s WatchStore, hb *Heartbeats, rescan time.Duration, onErr func(error)) error {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = fw.Close() }()
+
+	w := &worktreeWatcher{fw: fw, sessions: make(map[string]string), dirs: make(map[string]string), counts: make(map[string]int)}
+	refresh := func() {
+		sessions, err := s.ListAgentSessionsByStatus(ctx, "", []models.SessionStatus{models.SessionStatusActive, models.SessionStatusIdle, models.SessionStatusBlocked}, 0)
+		if err != nil {
+			onErr(err)
+			return
+		}
+		w.sync(sessions)
+	}
+	refresh()
+
+	ticker := time.NewTicker(rescan)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			refresh()
+		case err := <-fw.Errors:
+			onErr(err)
+		case ev := <-fw.Events:
+			id := w.handle(ev)
+			if id == "" {
+				continue
+			}
+			if _, err := hb.Beat(ctx, s, id); err != nil && ctx.Err() == nil {
+				onErr(err)
+			}
+		}
+	}
+}
+
+// worktreeWatcher tracks the watched directories of session worktrees. It is
+// only used from RunWorktreeWatcher's goroutine.
+type worktreeWatcher struct {
+	fw       *fsnotify.Watcher
+	sessions map[string]string // worktree path -> session ID
+	dirs     map[string]string // watched directory -> its worktree path
+	counts   map[string]int    // worktree path -> directories watched in it
+}
+
+// sync watches the worktrees of sessions and stops watching all others.
+func (w *worktreeWatcher) sync(sessions []*models.AgentSession) {
+	open := make(map[string]string, len(sessions))
+	for _, sess := range sessions {
+		if sess.WorktreePath != "" {
+			open[filepath.Clean(sess.WorktreePath)] = sess.ID
+		}
+	}
+	for root := range w.sessions {
+		if _, ok := open[root]; !ok {
+			w.unwatch(root)
+		}
+	}
+	for root, id := range open {
+		_, watched := w.sessions[root]
+		w.sessions[root] = id
+		if !watched {
+			w.watchTree(root, root)
+		}
+	}
+}
+
+// watchTree watches dir and the directories below it as part of root's
+// worktree.
+func (w *worktreeWatcher) watchTree(root, dir string) {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != root && unwatchedDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+		if w.counts[root] >= maxWatchedDirs {
+			return filepath.SkipAll
+		}
+		if _, ok := w.dirs[path]; ok {
+			return nil
+		}
+		if err := w.fw.Add(path); err == nil {
+			w.dirs[path] = root
+			w.counts[root]++
+		}
+		return nil
+	})
+}
+
+func (w *worktreeWatcher) unwatch(root string) {
+	for dir, r := range w.dirs {
+		if r == root {
+			_ = w.fw.Remove(dir)
+			delete(w.dirs, dir)
+		}
+	}
+	delete(w.sessions, root)
+	delete(w.counts, root)
+}
+
+// handle returns the session a file event is activity on, if any, watching
+// directories created in a worktree.
+func (w *worktreeWatcher) handle(ev fsnotify.Event) string {
+	if ev.Op == fsnotify.Chmod {
+		return ""
+	}
+	root, ok := w.dirs[filepath.Dir(ev.Name)]
+	if !ok {
+		return ""
+	}
+	rel, err := filepath.Rel(root, ev.Name)
+	if err != nil {
+		return ""
+	}
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if unwatchedDirs[part] {
+			return ""
+		}
+	}
+	if ev.Has(fsnotify.Create) {
+		if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+			w.watchTree(root, ev.Name)
+		}
+	}
+	if _, ok := w.dirs[ev.Name]; ok && (ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename)) {
+		delete(w.dirs, ev.Name)
+		w.counts[root]--
+	}
+	return w.sessions[root]
+}
@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// WIPStore is the subset of store.Store needed for WIP limit checks.
+type WIPStore interface {
+	ListIssues(ctx context.Context, filter store.IssueListFilter) ([]*models.Issue, error)
+	ListAgentSessionsByStatus(ctx context.Context, projectID string, statuses []models.SessionStatus, limit int) ([]*models.AgentSession, error)
+}
+
+// WIPCheck reports a project's work in progress against its limits.
+type WIPCheck struct {
+	InProgressIssues  int      `json:"in_progress_issues"`
+	MaxInProgress     int      `json:"max_in_progress"`
+	ActiveSessions    int      `json:"active_sessions"`
+	MaxActiveSessions int      `json:"max_active_sessions"`
+	Warnings          []string `json:"warnings,omitempty"`
+	Blocked           bool     `json:"blocked"`
+}
+
+// CheckWIP counts the project's in-progress issues and active/idle sessions,
+// adds the work a pending action would start (addIssues, addSessions), and
+// warns for each limit the result would exceed. Blocked is set when there are
+// warnings and the project has WIPHardBlock enabled.
+func CheckWIP(ctx context.Context, s WIPStore, p *models.Project, addIssues, addSessions int) (*WIPCheck, error) {
+	issues, err := s.ListIssues(ctx, store.IssueListFilter{ProjectID: p.ID, Status: models.IssueStatusInProgress})
+	if err != nil {
+		return nil, fmt.Errorf("list in-progress issues: %w", err)
+	}
+	sessions, err := s.ListAgentSessionsByStatus(ctx, p.ID, []models.SessionStatus{models.SessionStatusActive, models.SessionStatusIdle}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("list active sessions: %w", err)
+	}
+
+	check := &WIPCheck{
+		InProgressIssues:  len(issues) + addIssues,
+		MaxInProgress:     p.MaxInProgress,
+		ActiveSessions:    len(sessions) + addSessions,
+		MaxActiveSessions: p.MaxActiveSessions,
+	}
+	if p.MaxInProgress > 0 && check.InProgressIssues > p.MaxInProgress {
+		check.Warnings = append(check.Warnings, fmt.Sprintf("%s: %d issues in progress exceeds WIP limit of %d", p.Name, check.InProgressIssues, p.MaxInProgress))
+	}
+	if p.MaxActiveSessions > 0 && check.ActiveSessions > p.MaxActiveSessions {
+		check.Warnings = append(check.Warnings, fmt.Sprintf("%s: %d active sessions exceeds WIP limit of %d", p.Name, check.ActiveSessions, p.MaxActiveSessions))
+	}
+	check.Blocked = p.WIPHardBlock && len(check.Warnings) > 0
+	return check, nil
+}
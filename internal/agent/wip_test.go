@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWIPStore returns a memory store where project p1 has three issues in
+// progress and two open sessions.
+func newWIPStore(t *testing.T) *store.MemoryStore {
+	t.Helper()
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	for _, p := range []*models.Project{
+		{ID: "p1", Name: "proj", Path: "/code/proj"},
+		{ID: "p2", Name: "other", Path: "/code/other"},
+	} {
+		require.NoError(t, s.CreateProject(ctx, p))
+	}
+	for _, i := range []*models.Issue{
+		{ID: "i1", ProjectID: "p1", Title: "One", Status: models.IssueStatusInProgress},
+		{ID: "i2", ProjectID: "p1", Title: "Two", Status: models.IssueStatusInProgress},
+		{ID: "i3", ProjectID: "p1", Title: "Three", Status: models.IssueStatusOpen},
+		{ID: "i4", ProjectID: "p2", Title: "Four", Status: models.IssueStatusInProgress},
+	} {
+		require.NoError(t, s.CreateIssue(ctx, i))
+	}
+	for _, sess := range []*models.AgentSession{
+		{ID: "s1", ProjectID: "p1", Branch: "feature/a", Status: models.SessionStatusActive},
+		{ID: "s2", ProjectID: "p1", Branch: "feature/b", Status: models.SessionStatusIdle},
+		{ID: "s3", ProjectID: "p1", Branch: "feature/c", Status: models.SessionStatusCompleted},
+	} {
+		require.NoError(t, s.CreateAgentSession(ctx, sess))
+	}
+	return s
+}
+
+func TestCheckWIP_NoLimits(t *testing.T) {
+	p := &models.Project{ID: "p1", Name: "proj"}
+	check, err := CheckWIP(context.Background(), newWIPStore(t), p, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, check.InProgressIssues)
+	assert.Equal(t, 3, check.ActiveSessions)
+	assert.Empty(t, check.Warnings)
+	assert.False(t, check.Blocked)
+}
+
+func TestCheckWIP_AtLimit(t *testing.T) {
+	p := &models.Project{ID: "p1", Name: "proj", MaxInProgress: 2, MaxActiveSessions: 2, WIPHardBlock: true}
+	check, err := CheckWIP(context.Background(), newWIPStore(t), p, 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, check.Warnings, "reaching a limit is fine; exceeding it is not")
+	assert.False(t, check.Blocked)
+}
+
+func TestCheckWIP_ExceedsWarns(t *testing.T) {
+	p := &models.Project{ID: "p1", Name: "proj", MaxInProgress: 2, MaxActiveSessions: 2}
+	check, err := CheckWIP(context.Background(), newWIPStore(t), p, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, check.Warnings, 2)
+	assert.Contains(t, check.Warnings[0], "3 issues in progress exceeds WIP limit of 2")
+	assert.Contains(t, check.Warnings[1], "3 active sessions exceeds WIP limit of 2")
+	assert.False(t, check.Blocked)
+}
+
+func TestCheckWIP_HardBlock(t *testing.T) {
+	p := &models.Project{ID: "p1", Name: "proj", MaxActiveSessions: 2, WIPHardBlock: true}
+	check, err := CheckWIP(context.Background(), newWIPStore(t), p, 0, 1)
+	require.NoError(t, err)
+	assert.Len(t, check.Warnings, 1)
+	assert.True(t, check.Blocked)
+}
@@ -0,0 +1,206 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// WorklistStore is the subset of store.Store needed to build a cross-project worklist.
+type WorklistStore interface {
+	WIPStore
+	ListProjects(ctx context.Context, group string) ([]*models.Project, error)
+	ListAgentSessions(ctx context.Context, projectID string, limit int) ([]*models.AgentSession, error)
+	ListIssueReviews(ctx context.Context, issueID string) ([]*models.IssueReview, error)
+	ListIssueRelations(ctx context.Context, issueID string) ([]*models.IssueRelation, error)
+	GetIssue(ctx context.Context, id string) (*models.Issue, error)
+}
+
+// Task is an issue that could be launched next, with its priority score and
+// the reasons behind it.
+type Task struct {
+	Issue   *models.Issue
+	Project *models.Project
+	Score   int
+	Reasons []string
+}
+
+// FailingReview is an unresolved issue whose most recent review failed.
+type FailingReview struct {
+	Issue   *models.Issue
+	Project *models.Project
+	Review  *models.IssueReview
+}
+
+// SessionRef pairs a session with its project.
+type SessionRef struct {
+	Session *models.AgentSession
+	Project *models.Project
+}
+
+// Worklist is a prioritized view of work across projects.
+type Worklist struct {
+	Tasks              []Task // ranked, highest score first
+	FailingReviews     []FailingReview
+	ConflictedSessions []SessionRef
+	LiveSessions       []SessionRef
+	BlockedSessions    []SessionRef // longest-blocked first
+	WIPWarnings        []string
+}
+
+// Scoring weights added to store.SmartScore when ranking tasks.
+const (
+	scoreFailedReview = 400
+	scoreBug          = 50
+)
+
+// BuildWorklist ranks launchable issues across all projects (or just
+// projectID when set) by store.SmartScore, boosting failed reviews and bugs.
+// Open issues and in-progress issues with a failed latest review are
+// candidates; issues with a live or blocked session, issues blocked by an
+// unfinished issue, and projects hard-blocked by WIP limits are skipped.
+func BuildWorklist(ctx context.Context, s WorklistStore, projectID string, now time.Time) (*Worklist, error) {
+	projects, err := s.ListProjects(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+
+	wl := &Worklist{}
+	for _, p := range projects {
+		if projectID != "" && p.ID != projectID {
+			continue
+		}
+		if err := addProjectWork(ctx, s, p, now, wl); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(wl.Tasks, func(i, j int) bool {
+		if wl.Tasks[i].Score != wl.Tasks[j].Score {
+			return wl.Tasks[i].Score > wl.Tasks[j].Score
+		}
+		return wl.Tasks[i].Issue.CreatedAt.Before(wl.Tasks[j].Issue.CreatedAt)
+	})
+	sort.SliceStable(wl.BlockedSessions, func(i, j int) bool {
+		a, b := wl.BlockedSessions[i].Session.BlockedAt, wl.BlockedSessions[j].Session.BlockedAt
+		return a != nil && (b == nil || a.Before(*b))
+	})
+	return wl, nil
+}
+
+func addProjectWork(ctx context.Context, s WorklistStore, p *models.Project, now time.Time, wl *Worklist) error {
+	sessions, err := s.ListAgentSessions(ctx, p.ID, 0)
+	if err != nil {
+		return fmt.Errorf("list sessions for %s: %w", p.Name, err)
+	}
+	liveIssues := make(map[string]bool)
+	for _, sess := range sessions {
+		if sess.Status == models.SessionStatusBlocked {
+			wl.BlockedSessions = append(wl.BlockedSessions, SessionRef{Session: sess, Project: p})
+			if sess.IssueID != "" {
+				liveIssues[sess.IssueID] = true
+			}
+			continue
+		}
+		if sess.Status != models.SessionStatusActive && sess.Status != models.SessionStatusIdle {
+			continue
+		}
+		wl.LiveSessions = append(wl.LiveSessions, SessionRef{Session: sess, Project: p})
+		if sess.ConflictState != "" && sess.ConflictState != models.ConflictStateNone {
+			wl.ConflictedSessions = append(wl.ConflictedSessions, SessionRef{Session: sess, Project: p})
+		}
+		if sess.IssueID != "" {
+			liveIssues[sess.IssueID] = true
+		}
+	}
+
+	wip, err := CheckWIP(ctx, s, p, 1, 1)
+	if err != nil {
+		return err
+	}
+	current, err := CheckWIP(ctx, s, p, 0, 0)
+	if err != nil {
+		return err
+	}
+	wl.WIPWarnings = append(wl.WIPWarnings, current.Warnings...)
+
+	issues, err := s.ListIssues(ctx, store.IssueListFilter{ProjectID: p.ID})
+	if err != nil {
+		return fmt.Errorf("list issues for %s: %w", p.Name, err)
+	}
+	for _, issue := range issues {
+		if issue.Status != models.IssueStatusOpen && issue.Status != models.IssueStatusInProgress {
+			continue
+		}
+
+		review := latestReview(ctx, s, issue.ID)
+		failed := review != nil && review.Verdict == models.ReviewVerdictFail
+		if failed {
+			wl.FailingReviews = append(wl.FailingReviews, FailingReview{Issue: issue, Project: p, Review: review})
+		}
+
+		if liveIssues[issue.ID] || wip.Blocked {
+			continue
+		}
+		if issue.Status == models.IssueStatusInProgress && !failed {
+			continue
+		}
+		if blocked, _ := isBlocked(ctx, s, issue.ID); blocked {
+			continue
+		}
+
+		// Blocked issues were skipped above.
+		score, reasons := store.SmartScore(issue, false, now)
+		task := Task{Issue: issue, Project: p, Score: score, Reasons: reasons}
+		if failed {
+			task.Score += scoreFailedReview
+			task.Reasons = append(task.Reasons, "latest review failed")
+		}
+		if issue.Type == models.IssueTypeBug {
+			task.Score += scoreBug
+			task.Reasons = append(task.Reasons, "bug")
+		}
+		wl.Tasks = append(wl.Tasks, task)
+	}
+	return nil
+}
+
+// latestReview returns the most recent review of an issue, or nil.
+func latestReview(ctx context.Context, s WorklistStore, issueID string) *models.IssueReview {
+	reviews, err := s.ListIssueReviews(ctx, issueID)
+	if err != nil {
+		return nil
+	}
+	var latest *models.IssueReview
+	for _, r := range reviews {
+		if latest == nil || r.ReviewedAt.After(latest.ReviewedAt) {
+			latest = r
+		}
+	}
+	return latest
+}
+
+// isBlocked reports whether another unfinished issue has a "blocks" relation to issueID.
+func isBlocked(ctx context.Context, s ReadinessStore, issueID string) (bool, error) {
+	rels, err := s.ListIssueRelations(ctx, issueID)
+	if err != nil {
+		return false, err
+	}
+	for _, rel := range rels {
+		if rel.Type != models.RelationBlocks || rel.TargetIssueID != issueID {
+			continue
+		}
+		blocker, err := s.GetIssue(ctx, rel.SourceIssueID)
+		if err != nil {
+			continue
+		}
+		if blocker.Status != models.IssueStatusDone && blocker.Status != models.IssueStatusClosed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,122 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWorklistStore returns a memory store with open, failed, blocked and
+// live issues across projects p1 (alpha) and p2 (beta). wip limits p2's
+// issues in progress, with a hard block, when set.
+func newWorklistStore(t *testing.T, now time.Time, wip int) *store.MemoryStore {
+	t.Helper()
+	ctx := store.WithImportedTimestamps(context.Background())
+	s := store.NewMemoryStore()
+	day := 24 * time.Hour
+	for _, p := range []*models.Project{
+		{ID: "p1", Name: "alpha", Path: "/code/alpha"},
+		{ID: "p2", Name: "beta", Path: "/code/beta", MaxInProgress: wip, WIPHardBlock: wip > 0},
+	} {
+		require.NoError(t, s.CreateProject(ctx, p))
+	}
+	for _, i := range []*models.Issue{
+		{ID: "low", ProjectID: "p1", Title: "Low", Status: models.IssueStatusOpen, Priority: models.IssuePriorityLow, Type: models.IssueTypeFeature, CreatedAt: now.Add(-5 * day)},
+		{ID: "high", ProjectID: "p1", Title: "High", Status: models.IssueStatusOpen, Priority: models.IssuePriorityHigh, Type: models.IssueTypeFeature, CreatedAt: now.Add(-2 * day)},
+		{ID: "bug", ProjectID: "p2", Title: "Bug", Status: models.IssueStatusOpen, Priority: models.IssuePriorityHigh, Type: models.IssueTypeBug, CreatedAt: now},
+		{ID: "failed", ProjectID: "p2", Title: "Failed", Status: models.IssueStatusInProgress, Priority: models.IssuePriorityMedium, Type: models.IssueTypeFeature, CreatedAt: now},
+		{ID: "working", ProjectID: "p2", Title: "Working", Status: models.IssueStatusInProgress, Priority: models.IssuePriorityHigh, CreatedAt: now},
+		{ID: "live", ProjectID: "p1", Title: "Live", Status: models.IssueStatusOpen, Priority: models.IssuePriorityHigh, CreatedAt: now},
+		{ID: "blocked", ProjectID: "p1", Title: "Blocked", Status: models.IssueStatusOpen, Priority: models.IssuePriorityHigh, CreatedAt: now},
+		{ID: "done", ProjectID: "p1", Title: "Done", Status: models.IssueStatusDone, Priority: models.IssuePriorityHigh, CreatedAt: now},
+	} {
+		require.NoError(t, s.CreateIssue(ctx, i))
+	}
+	for _, sess := range []*models.AgentSession{
+		{ID: "s1", ProjectID: "p1", IssueID: "live", Branch: "feature/live", Status: models.SessionStatusActive, ConflictState: models.ConflictStateMergeConflict},
+		{ID: "s2", ProjectID: "p2", Branch: "feature/old", Status: models.SessionStatusCompleted},
+	} {
+		require.NoError(t, s.CreateAgentSession(ctx, sess))
+	}
+	for _, r := range []*models.IssueReview{
+		{ID: "r1", IssueID: "failed", Verdict: models.ReviewVerdictPass, ReviewedAt: now.Add(-2 * time.Hour)},
+		{ID: "r2", IssueID: "failed", Verdict: models.ReviewVerdictFail, ReviewedAt: now.Add(-time.Hour)},
+	} {
+		require.NoError(t, s.CreateIssueReview(ctx, r))
+	}
+	for _, r := range []*models.IssueRelation{
+		{ID: "rel1", SourceIssueID: "high", TargetIssueID: "blocked", Type: models.RelationBlocks},
+		{ID: "rel2", SourceIssueID: "done", TargetIssueID: "low", Type: models.RelationBlocks},
+	} {
+		require.NoError(t, s.CreateIssueRelation(ctx, r))
+	}
+	return s
+}
+
+func taskIDs(tasks []Task) []string {
+	var ids []string
+	for _, t := range tasks {
+		ids = append(ids, t.Issue.ID)
+	}
+	return ids
+}
+
+func TestBuildWorklist(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	wl, err := BuildWorklist(context.Background(), newWorklistStore(t, now, 0), "", now)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"failed", "bug", "high", "low"}, taskIDs(wl.Tasks))
+	assert.Equal(t, 600, wl.Tasks[0].Score)
+	assert.Contains(t, wl.Tasks[0].Reasons, "latest review failed")
+	assert.Equal(t, 302, wl.Tasks[2].Score, "age adds a point per day")
+
+	require.Len(t, wl.FailingReviews, 1)
+	assert.Equal(t, "r2", wl.FailingReviews[0].Review.ID)
+	assert.Equal(t, "beta", wl.FailingReviews[0].Project.Name)
+
+	require.Len(t, wl.LiveSessions, 1)
+	require.Len(t, wl.ConflictedSessions, 1)
+	assert.Equal(t, "s1", wl.ConflictedSessions[0].Session.ID)
+}
+
+func TestBuildWorklist_SLA(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	s := newWorklistStore(t, now, 0)
+	ctx := context.Background()
+	low, err := s.GetIssue(ctx, "low")
+	require.NoError(t, err)
+	low.Priority = models.IssuePriorityMedium
+	require.NoError(t, s.UpdateIssue(ctx, low))
+	require.NoError(t, s.SetIssueSLAState(ctx, "low", models.SLAStateBreached))
+	wl, err := BuildWorklist(context.Background(), s, "p1", now)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"low", "high"}, taskIDs(wl.Tasks), "a breach lifts an issue a priority level")
+	assert.Equal(t, 305, wl.Tasks[0].Score)
+	assert.Contains(t, wl.Tasks[0].Reasons, "SLA breached")
+}
+
+func TestBuildWorklist_ProjectFilter(t *testing.T) {
+	now := time.Now()
+	wl, err := BuildWorklist(context.Background(), newWorklistStore(t, now, 0), "p1", now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"high", "low"}, taskIDs(wl.Tasks))
+	assert.Empty(t, wl.FailingReviews)
+}
+
+func TestBuildWorklist_WIPHardBlock(t *testing.T) {
+	now := time.Now()
+	ms := newWorklistStore(t, now, 1)
+
+	wl, err := BuildWorklist(context.Background(), ms, "", now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"high", "low"}, taskIDs(wl.Tasks), "hard-blocked project is skipped")
+	assert.NotEmpty(t, wl.WIPWarnings)
+	assert.Len(t, wl.FailingReviews, 1, "failing reviews are still reported")
+}
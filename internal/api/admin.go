@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/joescharf/pm/internal/store"
+)
+
+// Database is the maintenance side of the SQLite database behind the store.
+type Database interface {
+	Stats(ctx context.Context) (*store.DBStats, error)
+	Maintain(ctx context.Context, checkOnly bool) (*store.MaintenanceReport, error)
+}
+
+// WithDatabase enables the database admin endpoints
+// (/api/v1/admin/db/...).
+func WithDatabase(db Database) Option {
+	return func(s *Server) {
+		s.database = db
+	}
+}
+
+// databaseStats reports the size of the database, its tables, indexes and
+// compressed text columns.
+func (s *Server) databaseStats(w http.ResponseWriter, r *http.Request) {
+	if s.database == nil {
+		writeError(w, http.StatusNotImplemented, "database admin is not available")
+		return
+	}
+	stats, err := s.database.Stats(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// MaintainDatabaseRequest is the JSON body for POST /api/v1/admin/db/maintain.
+type MaintainDatabaseRequest struct {
+	// CheckOnly runs the integrity check and orphan report without ANALYZE
+	// and VACUUM.
+	CheckOnly bool `json:"check_only"`
+}
+
+// maintainDatabase checks the database and, unless asked to only check,
+// analyzes and vacuums it. A failed integrity check answers 500 with the
+// report.
+func (s *Server) maintainDatabase(w http.ResponseWriter, r *http.Request) {
+	if s.database == nil {
+		writeError(w, http.StatusNotImplemented, "database admin is not available")
+		return
+	}
+	var req MaintainDatabaseRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	report, err := s.database.Maintain(r.Context(), req.CheckOnly)
+	switch {
+	case errors.Is(err, store.ErrIntegrity):
+		writeJSON(w, http.StatusInternalServerError, report)
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	default:
+		writeJSON(w, http.StatusOK, report)
+	}
+}
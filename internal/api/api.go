@@ -1,24 +1,49 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/joescharf/pm/internal/agent"
+	"github.com/joescharf/pm/internal/auth"
+	"github.com/joescharf/pm/internal/autoclose"
+	"github.com/joescharf/pm/internal/boardsync"
+	"github.com/joescharf/pm/internal/capabilities"
+	"github.com/joescharf/pm/internal/federation"
+	"github.com/joescharf/pm/internal/flow"
 	"github.com/joescharf/pm/internal/git"
 	"github.com/joescharf/pm/internal/health"
+	"github.com/joescharf/pm/internal/intake"
 	"github.com/joescharf/pm/internal/llm"
+	"github.com/joescharf/pm/internal/llmbudget"
+	"github.com/joescharf/pm/internal/markdown"
 	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/notify"
+	"github.com/joescharf/pm/internal/prreviews"
 	"github.com/joescharf/pm/internal/refresh"
+	"github.com/joescharf/pm/internal/releases"
 	"github.com/joescharf/pm/internal/sessions"
+	"github.com/joescharf/pm/internal/sla"
 	"github.com/joescharf/pm/internal/store"
+	"github.com/joescharf/pm/internal/terminal"
+	"github.com/joescharf/pm/internal/uireview"
 	"github.com/joescharf/pm/internal/wt"
 )
 
@@ -29,15 +54,175 @@ type Server struct {
 	gh              git.GitHubClient
 	wt              wt.Client
 	llm             *llm.Client
+	budget          *llmbudget.Budget
 	scorer          *health.Scorer
 	sessions        *sessions.Manager
 	processDetector agent.ProcessDetector
+	reconciler      *agent.Reconciler
+	reconcileKick   chan struct{}
+	heartbeats      *agent.Heartbeats
+	spawner         terminal.Spawner
+	terminals       func(backend string) (terminal.Terminal, error)
+	slaPolicies     []sla.Policy
+	cache           *responseCache
+	requireAuth     bool
+	autoClose       autoclose.Config
+	releaseSchedule releases.Schedule
+	caps            *capabilities.Capabilities
+	push            *notify.PushNotifier
+	profileName     string
+	profiles        []*federation.Profile
+	database        Database
+	reloader        Reloader
+
+	blockedRemindAfter time.Duration
+	issueLockExpiry    time.Duration
+	expandTimeout      time.Duration
+
+	// launchMu serializes agent launches, so two agents pulling work at the
+	// same time cannot be handed the same issue.
+	launchMu sync.Mutex
+
+	// changeCh is closed by the next store write, waking change feed
+	// requests that wait for one.
+	changeMu sync.Mutex
+	changeCh chan struct{}
+
+	// live holds the settings Reconfigure may change while serving; handlers
+	// read them through settings().
+	liveMu sync.RWMutex
+	live   liveSettings
+}
+
+// Option configures optional Server behavior.
+type Option func(*Server)
+
+// WithStaleSessionCriteria sets the default thresholds used by session cleanup.
+func WithStaleSessionCriteria(c store.StaleSessionCriteria) Option {
+	return func(s *Server) {
+		s.live.staleCriteria = c
+	}
+}
+
+// WithCloseCheckEnforcement controls whether closing a session as completed is
+// refused (409) when the close-check reports uncommitted or unmerged work.
+func WithCloseCheckEnforcement(enforce bool) Option {
+	return func(s *Server) {
+		s.live.enforceClose = enforce
+	}
+}
+
+// WithTerminalSpawner enables server-side terminal spawning for launch and
+// resume requests that set spawn=true.
+func WithTerminalSpawner(sp terminal.Spawner) Option {
+	return func(s *Server) {
+		s.spawner = sp
+	}
+}
+
+// WithSLAPolicies sets the issue SLA policies evaluated by the SLA report.
+func WithSLAPolicies(policies []sla.Policy) Option {
+	return func(s *Server) {
+		s.slaPolicies = policies
+	}
+}
+
+// WithResponseCache caches the status, project dashboard and health endpoints
+// for ttl. The cache is cleared on every write to the store (when the store
+// supports write hooks) and by mutating API requests. A ttl <= 0 disables it.
+func WithResponseCache(ttl time.Duration) Option {
+	return func(s *Server) {
+		if ttl > 0 {
+			s.cache = newResponseCache(ttl)
+		}
+	}
+}
+
+// WithIntake enables POST /api/v1/intake, which turns inbound emails and
+// webhook payloads into issues.
+func WithIntake(c intake.Config) Option {
+	return func(s *Server) {
+		s.live.intake = c
+	}
+}
+
+// WithBlockedReminder sets how long a session may stay blocked before session
+// responses flag it as BlockedOverdue (<= 0 never flags).
+func WithBlockedReminder(after time.Duration) Option {
+	return func(s *Server) {
+		s.blockedRemindAfter = after
+	}
+}
+
+// WithIssueLockExpiry sets how long an active session without activity keeps
+// its issue locked against other launches (<= 0 never expires).
+func WithIssueLockExpiry(d time.Duration) Option {
+	return func(s *Server) {
+		s.issueLockExpiry = d
+	}
+}
+
+// WithAutoClose configures closing the issues referenced by merged session
+// commits and pull requests ("Fixes #12").
+func WithAutoClose(c autoclose.Config) Option {
+	return func(s *Server) {
+		s.autoClose = c
+		s.sessions.SetAutoClose(c)
+	}
+}
+
+// WithBoardSync sets the field names and mappings GitHub board syncs use.
+func WithBoardSync(opts boardsync.Options) Option {
+	return func(s *Server) {
+		s.live.boardSync = opts
+	}
+}
+
+// WithDiscover sets how POST /api/v1/sessions/discover scans all projects:
+// how many at once, for how long each, and which to leave out.
+func WithDiscover(opts sessions.DiscoverOptions) Option {
+	return func(s *Server) {
+		s.live.discover = opts
+	}
+}
+
+// WithReleaseSchedule sets the release polling schedule, which status reads
+// use to tell whether a stored release is stale or due a fetch.
+func WithReleaseSchedule(sched releases.Schedule) Option {
+	return func(s *Server) {
+		s.releaseSchedule = sched
+	}
+}
+
+// WithCapabilities sets the capabilities detected at startup, reported by
+// GET /api/v1/capabilities and checked by the endpoints that need them.
+func WithCapabilities(c *capabilities.Capabilities) Option {
+	return func(s *Server) {
+		s.caps = c
+	}
+}
+
+// WithLLMBudget caps each project's monthly LLM spend: issue enrichment
+// checks it before calling the LLM, and GET /api/v1/projects/{id}/llm-budget
+// reports it.
+func WithLLMBudget(b *llmbudget.Budget) Option {
+	return func(s *Server) {
+		s.budget = b
+	}
+}
+
+// WithHeartbeats shares h with the server, so hook heartbeats count toward
+// the per-session write interval of the other heartbeat sources.
+func WithHeartbeats(h *agent.Heartbeats) Option {
+	return func(s *Server) {
+		s.heartbeats = h
+	}
 }
 
 // NewServer creates a new API server.
 // The llmClient may be nil if no API key is configured.
-func NewServer(s store.Store, gc git.Client, ghc git.GitHubClient, wtc wt.Client, llmClient *llm.Client) *Server {
-	return &Server{
+func NewServer(s store.Store, gc git.Client, ghc git.GitHubClient, wtc wt.Client, llmClient *llm.Client, opts ...Option) *Server {
+	srv := &Server{
 		store:           s,
 		git:             gc,
 		gh:              ghc,
@@ -46,69 +231,188 @@ func NewServer(s store.Store, gc git.Client, ghc git.GitHubClient, wtc wt.Client
 		scorer:          health.NewScorer(),
 		sessions:        sessions.NewManager(s, wtc),
 		processDetector: &agent.OSProcessDetector{},
+		reconciler:      agent.NewReconciler(defaultReconcileCooldown),
+		reconcileKick:   make(chan struct{}, 1),
+		heartbeats:      agent.NewHeartbeats(agent.DefaultHeartbeatInterval),
+		terminals:       terminal.New,
+		autoClose:       autoclose.Config{Enabled: true},
+		releaseSchedule: releases.DefaultSchedule(),
+
+		blockedRemindAfter: agent.DefaultBlockedRemindAfter,
+		issueLockExpiry:    agent.DefaultIssueLockExpiry,
+		expandTimeout:      defaultExpandTimeout,
+
+		live: liveSettings{
+			staleCriteria: store.DefaultStaleSessionCriteria(),
+			cors:          DefaultCORSConfig(),
+			shareTTL:      DefaultReviewShareTTL,
+		},
+	}
+	if gc != nil {
+		srv.sessions.SetGitClient(gc)
+	}
+	for _, opt := range opts {
+		opt(srv)
+	}
+	if wn, ok := s.(store.WriteNotifier); ok {
+		if srv.cache != nil {
+			wn.OnWrite(srv.InvalidateCache)
+		}
+		wn.OnWrite(srv.notifyChange)
 	}
+	return srv
 }
 
 // Router returns an http.Handler for the API routes.
 func (s *Server) Router() http.Handler {
 	mux := http.NewServeMux()
-
-	mux.HandleFunc("GET /api/v1/projects", s.listProjects)
-	mux.HandleFunc("POST /api/v1/projects", s.createProject)
-	mux.HandleFunc("GET /api/v1/projects/{id}", s.getProject)
-	mux.HandleFunc("PUT /api/v1/projects/{id}", s.updateProject)
-	mux.HandleFunc("DELETE /api/v1/projects/{id}", s.deleteProject)
-
-	mux.HandleFunc("POST /api/v1/projects/refresh", s.refreshAllProjects)
-
-	mux.HandleFunc("GET /api/v1/projects/{id}/issues", s.listProjectIssues)
-	mux.HandleFunc("POST /api/v1/projects/{id}/issues", s.createProjectIssue)
-
-	mux.HandleFunc("GET /api/v1/issues", s.listIssues)
-	mux.HandleFunc("POST /api/v1/issues/bulk-update", s.bulkUpdateIssues)
-	mux.HandleFunc("POST /api/v1/issues/bulk-delete", s.bulkDeleteIssues)
-	mux.HandleFunc("GET /api/v1/issues/{id}", s.getIssue)
-	mux.HandleFunc("PUT /api/v1/issues/{id}", s.updateIssue)
-	mux.HandleFunc("DELETE /api/v1/issues/{id}", s.deleteIssue)
-	mux.HandleFunc("POST /api/v1/issues/{id}/enrich", s.enrichIssue)
-
-	mux.HandleFunc("GET /api/v1/issues/{id}/reviews", s.listIssueReviews)
-	mux.HandleFunc("POST /api/v1/issues/{id}/reviews", s.createIssueReview)
-
-	mux.HandleFunc("GET /api/v1/status", s.statusOverview)
-	mux.HandleFunc("GET /api/v1/status/{id}", s.statusProject)
-
-	mux.HandleFunc("GET /api/v1/sessions", s.listSessions)
-	mux.HandleFunc("DELETE /api/v1/sessions/cleanup", s.cleanupSessions)
-	mux.HandleFunc("GET /api/v1/sessions/{id}", s.getSession)
-	mux.HandleFunc("POST /api/v1/sessions/{id}/sync", s.syncSession)
-	mux.HandleFunc("POST /api/v1/sessions/{id}/merge", s.mergeSession)
-	mux.HandleFunc("DELETE /api/v1/sessions/{id}/worktree", s.deleteWorktree)
-	mux.HandleFunc("GET /api/v1/sessions/{id}/close-check", s.closeCheck)
-	mux.HandleFunc("POST /api/v1/sessions/{id}/reactivate", s.reactivateSession)
-	mux.HandleFunc("POST /api/v1/sessions/discover", s.discoverWorktrees)
-
-	mux.HandleFunc("GET /api/v1/tags", s.listTags)
-
-	mux.HandleFunc("GET /api/v1/health/{id}", s.projectHealth)
-
-	mux.HandleFunc("POST /api/v1/agent/launch", s.launchAgent)
-	mux.HandleFunc("POST /api/v1/agent/resume", s.resumeAgent)
-	mux.HandleFunc("POST /api/v1/agent/close", s.closeAgent)
-
-	return corsMiddleware(mux)
+	// route registers a handler with the access rule applied to
+	// project-scoped API tokens (nil closes the route to them).
+	route := func(pattern string, rule accessRule, h http.HandlerFunc) {
+		h = s.scoped(rule, h)
+		switch {
+		case strings.Contains(pattern, " /api/v1/issues/{id}"):
+			h = s.issueCodePath(h)
+		case strings.Contains(pattern, " /api/v1/sessions/{id}"):
+			h = s.sessionCodePath(h)
+		}
+		mux.HandleFunc(pattern, h)
+	}
+
+	route("GET /healthz", anyScope, s.healthz)
+	route("GET /api/v1/capabilities", anyScope, s.getCapabilities)
+
+	route("GET /api/v1/projects", nil, s.listProjects)
+	route("POST /api/v1/projects", nil, s.createProject)
+	route("GET /api/v1/projects/{id}", projectPath, s.getProject)
+	route("PUT /api/v1/projects/{id}", projectPath, s.updateProject)
+	route("DELETE /api/v1/projects/{id}", nil, s.deleteProject)
+
+	route("POST /api/v1/projects/refresh", nil, s.refreshAllProjects)
+	route("POST /api/v1/projects/bulk", nil, s.bulkProjects)
+
+	route("GET /api/v1/projects/{id}/issues", projectPath, s.listProjectIssues)
+	route("POST /api/v1/projects/{id}/issues", projectPath, s.createProjectIssue)
+
+	route("GET /api/v1/projects/{id}/notes", projectPath, s.listProjectNotes)
+	route("POST /api/v1/projects/{id}/notes", projectPath, s.createProjectNote)
+	route("GET /api/v1/projects/{id}/notes/{noteId}", projectPath, s.getProjectNote)
+	route("PUT /api/v1/projects/{id}/notes/{noteId}", projectPath, s.updateProjectNote)
+	route("DELETE /api/v1/projects/{id}/notes/{noteId}", projectPath, s.deleteProjectNote)
+
+	route("GET /api/v1/projects/{id}/llm-budget", projectPath, s.getLLMBudget)
+
+	route("POST /api/v1/intake", nil, s.intakeIssue)
+
+	route("GET /api/v1/issues", projectQuery, s.listIssues)
+	route("POST /api/v1/issues/bulk-update", nil, s.bulkUpdateIssues)
+	route("POST /api/v1/issues/bulk-delete", nil, s.bulkDeleteIssues)
+	route("GET /api/v1/issues/{id}", issuePath, s.getIssue)
+	route("PUT /api/v1/issues/{id}", issuePath, s.updateIssue)
+	route("DELETE /api/v1/issues/{id}", issuePath, s.deleteIssue)
+	route("POST /api/v1/issues/{id}/enrich", issuePath, s.enrichIssue)
+
+	route("GET /api/v1/issues/{id}/reviews", issuePath, s.listIssueReviews)
+	route("POST /api/v1/issues/{id}/reviews", issuePath, s.createIssueReview)
+	route("GET /api/v1/issues/{id}/review-shares", issuePath, s.listReviewShares)
+	route("POST /api/v1/issues/{id}/review-shares", issuePath, s.createReviewShare)
+	route("DELETE /api/v1/issues/{id}/review-shares/{shareId}", issuePath, s.deleteReviewShare)
+
+	// Review links: the token in the path is the credential.
+	route("GET /api/v1/review-shares/{token}", anyScope, s.getSharedReview)
+	route("POST /api/v1/review-shares/{token}/review", anyScope, s.submitSharedReview)
+
+	route("GET /api/v1/issues/{id}/relations", issuePath, s.listIssueRelations)
+	route("POST /api/v1/issues/{id}/relations", relationBody, s.createIssueRelation)
+	route("DELETE /api/v1/issues/{id}/relations/{relationId}", issuePath, s.deleteIssueRelation)
+	route("GET /api/v1/issues/{id}/split", issuePath, s.listSplitParts)
+	route("POST /api/v1/issues/{id}/split", issuePath, s.splitIssue)
+	route("POST /api/v1/issues/{id}/merge", mergeBody, s.mergeIssues)
+	route("PATCH /api/v1/issues/{id}/rank", issuePath, s.rankIssue)
+
+	route("GET /api/v1/issues/{id}/watchers", issuePath, s.listIssueWatchers)
+	route("POST /api/v1/issues/{id}/watchers", issuePath, s.watchIssue)
+	route("DELETE /api/v1/issues/{id}/watchers", issuePath, s.unwatchIssue)
+	route("GET /api/v1/issues/{id}/comments", issuePath, s.listIssueComments)
+	route("POST /api/v1/issues/{id}/comments", issuePath, s.createIssueComment)
+
+	route("GET /api/v1/issues/{id}/code", issuePath, s.issueCode)
+	route("GET /api/v1/issues/{id}/rendered", issuePath, s.renderIssue)
+	route("GET /api/v1/issues/{id}/closures", issuePath, s.listIssueClosures)
+	route("POST /api/v1/issues/{id}/closures/{closureId}/revert", issuePath, s.revertIssueClosure)
+	route("GET /api/v1/issues/{id}/sessions", issuePath, s.listIssueSessions)
+	route("POST /api/v1/projects/{id}/autoclose", projectPath, s.autoCloseProject)
+	route("POST /api/v1/projects/{id}/pr-reviews/sync", projectPath, s.syncPRReviews)
+	route("POST /api/v1/projects/{id}/board/sync", projectPath, s.syncBoard)
+
+	route("GET /api/v1/reports/sla", nil, s.slaReport)
+	route("GET /api/v1/reports/outcomes", projectQuery, s.outcomeReport)
+	route("GET /api/v1/reports/focus", projectQuery, s.focusReport)
+	route("GET /api/v1/reports/board-diff", projectQuery, s.boardDiffReport)
+
+	route("GET /api/v1/status", nil, s.cached(s.statusOverview))
+	route("GET /api/v1/status/{id}", projectPath, s.cached(s.statusProject))
+
+	route("GET /api/v1/sessions", projectQuery, s.listSessions)
+	route("DELETE /api/v1/sessions/cleanup", nil, s.cleanupSessions)
+	route("GET /api/v1/sessions/labels", projectQuery, s.sessionLabelStats)
+	route("GET /api/v1/sessions/{id}", sessionPath, s.getSession)
+	route("POST /api/v1/sessions/{id}/sync", sessionPath, s.syncSession)
+	route("POST /api/v1/sessions/{id}/merge", sessionPath, s.mergeSession)
+	route("GET /api/v1/sessions/{id}/operations", sessionPath, s.listOperations)
+	route("POST /api/v1/sessions/{id}/operations/{op}/cancel", sessionPath, s.cancelOperation)
+	route("DELETE /api/v1/sessions/{id}/worktree", sessionPath, s.deleteWorktree)
+	route("GET /api/v1/sessions/{id}/close-check", sessionPath, s.closeCheck)
+	route("GET /api/v1/sessions/{id}/replay", sessionPath, s.replaySession)
+	route("PUT /api/v1/sessions/{id}/labels", sessionPath, s.labelSession)
+	route("GET /api/v1/sessions/{id}/files", sessionPath, s.listSessionFiles)
+	route("GET /api/v1/sessions/{id}/file", sessionPath, s.getSessionFile)
+	route("POST /api/v1/sessions/{id}/reactivate", sessionPath, s.reactivateSession)
+	route("POST /api/v1/sessions/{id}/focus", sessionPath, s.focusSession)
+	route("POST /api/v1/sessions/discover", nil, s.discoverWorktrees)
+
+	route("GET /api/v1/tags", nil, s.listTags)
+	route("PUT /api/v1/tags/{id}", nil, s.renameTag)
+	route("GET /api/v1/tags/{id}/breakdown", nil, s.tagBreakdown)
+	route("POST /api/v1/tags/{id}/merge-into/{target}", nil, s.mergeTag)
+	route("DELETE /api/v1/tags/orphans", nil, s.deleteOrphanTags)
+
+	route("GET /api/v1/changes", projectQuery, s.listChanges)
+
+	route("GET /api/v1/combined/status", nil, s.combinedStatus)
+	route("GET /api/v1/combined/issues", nil, s.combinedIssues)
+
+	route("GET /api/v1/admin/db/stats", nil, s.databaseStats)
+	route("POST /api/v1/admin/db/maintain", nil, s.maintainDatabase)
+	route("POST /api/v1/admin/reload", nil, s.reloadConfig)
+
+	route("GET /api/v1/push/key", nil, s.pushKey)
+	route("POST /api/v1/push/subscriptions", nil, s.subscribePush)
+	route("DELETE /api/v1/push/subscriptions", nil, s.unsubscribePush)
+	route("POST /api/v1/push/test", nil, s.testPush)
+
+	route("GET /api/v1/health/{id}", projectPath, s.cached(s.projectHealth))
+	route("GET /api/v1/projects/{id}/badge/health.svg", projectPath, s.badgeHandler(s.healthBadge))
+	route("GET /api/v1/projects/{id}/badge/issues.svg", projectPath, s.badgeHandler(s.issuesBadge))
+	route("GET /api/v1/metrics/git", nil, s.gitMetrics)
+
+	route("POST /api/v1/agent/launch", launchBody, s.launchAgent)
+	route("POST /api/v1/agent/next", nextBody, s.agentNext)
+	route("POST /api/v1/agent/resume", sessionBody, s.resumeAgent)
+	route("POST /api/v1/agent/close", sessionBody, s.closeAgent)
+	route("POST /api/v1/agent/heartbeat", heartbeatBody, s.agentHeartbeat)
+
+	return s.corsMiddleware(s.authenticate(s.invalidateOnWrite(mux)))
 }
 
-func corsMiddleware(next http.Handler) http.Handler {
+// invalidateOnWrite clears the response cache after mutating requests, which
+// may change git state (merges, syncs, worktrees) the store never sees.
+func (s *Server) invalidateOnWrite(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusNoContent)
-			return
-		}
 		next.ServeHTTP(w, r)
+		if r.Method != http.MethodGet && r.Method != http.MethodOptions {
+			s.InvalidateCache()
+		}
 	})
 }
 
@@ -131,11 +435,46 @@ func patchString(patch map[string]any, key string, target *string) {
 	}
 }
 
+// patchInt applies a non-negative integer value from a JSON patch map to the target if the key is present,
+// and returns an error when the value is anything else. Zero is allowed so limits can be cleared.
+func patchInt(patch map[string]any, key string, target *int) error {
+	v, ok := patch[key]
+	if !ok {
+		return nil
+	}
+	n, ok := v.(float64)
+	if !ok || n < 0 || n != math.Trunc(n) || n > math.MaxInt32 {
+		return fmt.Errorf("%s must be a non-negative integer", key)
+	}
+	*target = int(n)
+	return nil
+}
+
+// patchBool applies a boolean value from a JSON patch map to the target if the key is present,
+// and returns an error when the value is not a boolean.
+func patchBool(patch map[string]any, key string, target *bool) error {
+	v, ok := patch[key]
+	if !ok {
+		return nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return fmt.Errorf("%s must be true or false", key)
+	}
+	*target = b
+	return nil
+}
+
 // --- Projects ---
 
 func (s *Server) listProjects(w http.ResponseWriter, r *http.Request) {
-	group := r.URL.Query().Get("group")
-	projects, err := s.store.ListProjects(r.Context(), group)
+	var projects []*models.Project
+	var err error
+	if r.URL.Query().Get("archived") == "true" {
+		projects, err = s.store.ListArchivedProjects(r.Context())
+	} else {
+		projects, err = s.store.ListProjects(r.Context(), r.URL.Query().Get("group"))
+	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -163,8 +502,14 @@ func (s *Server) createProject(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
+	if p.Key != "" {
+		if err := models.ValidateProjectKey(p.Key); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 	if err := s.store.CreateProject(r.Context(), &p); err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, projectKeyStatus(err), err.Error())
 		return
 	}
 	writeJSON(w, http.StatusCreated, p)
@@ -196,6 +541,79 @@ func (s *Server) updateProject(w http.ResponseWriter, r *http.Request) {
 	patchString(patch, "RepoURL", &existing.RepoURL)
 	patchString(patch, "Language", &existing.Language)
 	patchString(patch, "GroupName", &existing.GroupName)
+	if err := errors.Join(
+		patchInt(patch, "MaxInProgress", &existing.MaxInProgress),
+		patchInt(patch, "MaxActiveSessions", &existing.MaxActiveSessions),
+		patchBool(patch, "WIPHardBlock", &existing.WIPHardBlock),
+		patchBool(patch, "Archived", &existing.Archived),
+	); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// RefreshSkip may be cleared with "", so it is not patched like the strings above.
+	if v, ok := patch["RefreshSkip"].(string); ok {
+		skip, err := refresh.FormatSkip(strings.Split(v, ","))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		existing.RefreshSkip = skip
+	}
+	if v, ok := patch["MergeStrategy"].(string); ok {
+		strategy, err := sessions.ParseMergeStrategy(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		existing.MergeStrategy = strategy
+	}
+	// Cascade holds event=status overrides; "" restores the defaults.
+	if v, ok := patch["Cascade"].(string); ok {
+		c, err := models.ParseCascade(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		existing.Cascade = c.String()
+	}
+	// SparsePaths may be cleared with "" too.
+	if v, ok := patch["SparsePaths"].(string); ok {
+		existing.SparsePaths = strings.Join(agent.ParseSparsePaths(v), ",")
+	}
+	// UIReviewPaths holds glob rules; "" restores the language defaults.
+	if v, ok := patch["UIReviewPaths"].(string); ok {
+		rules := uireview.ParseRules(v)
+		if err := uireview.Validate(rules); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		existing.UIReviewPaths = strings.Join(rules, ",")
+	}
+	// GitHubBoard is owner/number; "" stops syncing with a board.
+	if v, ok := patch["GitHubBoard"].(string); ok {
+		existing.GitHubBoard = ""
+		if v != "" {
+			owner, number, err := git.ParseBoardRef(v)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			existing.GitHubBoard = fmt.Sprintf("%s/%d", owner, number)
+		}
+	}
+	// Key is set apart from the other fields, since changing it renames the
+	// codes of the project's issues and sessions.
+	if v, ok := patch["Key"].(string); ok && v != "" && v != existing.Key {
+		if err := models.ValidateProjectKey(v); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := s.store.SetProjectKey(r.Context(), existing.ID, v); err != nil {
+			writeError(w, projectKeyStatus(err), err.Error())
+			return
+		}
+		existing.Key = v
+	}
 
 	if err := s.store.UpdateProject(r.Context(), existing); err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
@@ -222,262 +640,1364 @@ func (s *Server) refreshAllProjects(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, result)
 }
 
-// --- Issues ---
+// Bulk project actions.
+const (
+	bulkArchive   = "archive"
+	bulkUnarchive = "unarchive"
+	bulkSetGroup  = "set_group"
+	bulkRefresh   = "refresh"
+)
 
-func (s *Server) listIssues(w http.ResponseWriter, r *http.Request) {
-	filter := store.IssueListFilter{
-		Status:   models.IssueStatus(r.URL.Query().Get("status")),
-		Priority: models.IssuePriority(r.URL.Query().Get("priority")),
-		Tag:      r.URL.Query().Get("tag"),
-	}
-	issues, err := s.store.ListIssues(r.Context(), filter)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-	writeJSON(w, http.StatusOK, issues)
+// BulkProjectsRequest selects projects by ID and an action to apply to each.
+type BulkProjectsRequest struct {
+	IDs    []string `json:"ids"`
+	Action string   `json:"action"`          // archive, unarchive, set_group, refresh
+	Group  string   `json:"group,omitempty"` // new group for set_group; "" clears it
 }
 
-func (s *Server) listProjectIssues(w http.ResponseWriter, r *http.Request) {
-	projectID := r.PathValue("id")
-	filter := store.IssueListFilter{ProjectID: projectID}
-	issues, err := s.store.ListIssues(r.Context(), filter)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-	writeJSON(w, http.StatusOK, issues)
+// BulkProjectsResponse reports the outcome per project; one failure does not
+// stop the others.
+type BulkProjectsResponse struct {
+	Action  string           `json:"action"`
+	Total   int              `json:"total"`
+	Failed  int              `json:"failed"`
+	Results []refresh.Result `json:"results"`
 }
 
-func (s *Server) createProjectIssue(w http.ResponseWriter, r *http.Request) {
-	projectID := r.PathValue("id")
-	var issue models.Issue
-	if err := json.NewDecoder(r.Body).Decode(&issue); err != nil {
+func (s *Server) bulkProjects(w http.ResponseWriter, r *http.Request) {
+	var req BulkProjectsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
-	issue.ProjectID = projectID
-	if issue.Status == "" {
-		issue.Status = models.IssueStatusOpen
+	if len(req.IDs) == 0 {
+		writeError(w, http.StatusBadRequest, "ids is required")
+		return
 	}
-	if issue.Priority == "" {
-		issue.Priority = models.IssuePriorityMedium
+	switch req.Action {
+	case bulkArchive, bulkUnarchive, bulkSetGroup, bulkRefresh:
+	default:
+		writeError(w, http.StatusBadRequest, "action must be one of: archive, unarchive, set_group, refresh")
+		return
 	}
-	if issue.Type == "" {
-		issue.Type = models.IssueTypeFeature
+
+	ctx := r.Context()
+	resp := BulkProjectsResponse{Action: req.Action, Total: len(req.IDs)}
+
+	// Resolve every ID first so missing projects are reported in place.
+	results := make([]refresh.Result, len(req.IDs))
+	var projects []*models.Project
+	var positions []int
+	for i, id := range req.IDs {
+		p, err := s.store.GetProject(ctx, id)
+		if err != nil {
+			results[i] = refresh.Result{ID: id, Error: err.Error()}
+			continue
+		}
+		results[i] = refresh.Result{ID: p.ID, Name: p.Name}
+		projects = append(projects, p)
+		positions = append(positions, i)
 	}
 
-	// Auto-enrich if LLM available and AIPrompt not already set
-	if s.llm != nil && issue.AIPrompt == "" {
-		enriched, err := s.llm.EnrichIssue(r.Context(), issue.Title, issue.Body, issue.Description)
-		if err == nil {
-			if issue.Description == "" && enriched.Description != "" {
-				issue.Description = enriched.Description
+	if req.Action == bulkRefresh {
+		refreshed := refresh.Projects(ctx, s.store, projects, s.git, s.gh, refresh.DefaultWorkers)
+		for j, res := range refreshed.Results {
+			results[positions[j]] = res
+		}
+	} else {
+		for j, p := range projects {
+			res := &results[positions[j]]
+			switch req.Action {
+			case bulkArchive, bulkUnarchive:
+				res.Changed = p.Archived != (req.Action == bulkArchive)
+				p.Archived = req.Action == bulkArchive
+			case bulkSetGroup:
+				res.Changed = p.GroupName != req.Group
+				p.GroupName = req.Group
+			}
+			if !res.Changed {
+				continue
 			}
-			if enriched.AIPrompt != "" {
-				issue.AIPrompt = enriched.AIPrompt
+			if err := s.store.UpdateProject(ctx, p); err != nil {
+				res.Changed = false
+				res.Error = err.Error()
 			}
 		}
 	}
 
-	if err := s.store.CreateIssue(r.Context(), &issue); err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
-		return
+	for _, res := range results {
+		if res.Error != "" {
+			resp.Failed++
+		}
 	}
-	writeJSON(w, http.StatusCreated, issue)
+	resp.Results = results
+	writeJSON(w, http.StatusOK, resp)
 }
 
-func (s *Server) getIssue(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("id")
-	issue, err := s.store.GetIssue(r.Context(), id)
+// --- Project Notes ---
+
+// ProjectNoteRequest is the JSON body for creating or updating a project note.
+// On update, an empty title and a missing body keep the current values.
+type ProjectNoteRequest struct {
+	Title string  `json:"title"`
+	Body  *string `json:"body"`
+}
+
+func (s *Server) listProjectNotes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	projectID := r.PathValue("id")
+	if _, err := s.store.GetProject(ctx, projectID); err != nil {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+	notes, err := s.store.ListProjectNotes(ctx, projectID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, http.StatusNotFound, err.Error())
-			return
-		}
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, issue)
+	if notes == nil {
+		notes = []*models.ProjectNote{}
+	}
+	writeJSON(w, http.StatusOK, notes)
 }
 
-func (s *Server) updateIssue(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("id")
-	var issue models.Issue
-	if err := json.NewDecoder(r.Body).Decode(&issue); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid JSON")
+// getLLMBudget reports the project's LLM spend this month against its
+// budget. Without a budget nothing is capped, but spend is still reported.
+func (s *Server) getLLMBudget(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	p, err := s.store.GetProject(ctx, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "project not found")
 		return
 	}
-	issue.ID = id
-	if err := s.store.UpdateIssue(r.Context(), &issue); err != nil {
+	st, err := s.budget.Status(ctx, s.store, p, time.Now())
+	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, issue)
+	writeJSON(w, http.StatusOK, st)
 }
 
-func (s *Server) deleteIssue(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("id")
-	if err := s.store.DeleteIssue(r.Context(), id); err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+func (s *Server) createProjectNote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	projectID := r.PathValue("id")
+
+	var req ProjectNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
-	w.WriteHeader(http.StatusNoContent)
-}
-
-func (s *Server) enrichIssue(w http.ResponseWriter, r *http.Request) {
-	if s.llm == nil {
-		writeError(w, http.StatusServiceUnavailable, "LLM not configured (set ANTHROPIC_API_KEY)")
+	if strings.TrimSpace(req.Title) == "" {
+		writeError(w, http.StatusBadRequest, "title is required")
+		return
+	}
+	if _, err := s.store.GetProject(ctx, projectID); err != nil {
+		writeError(w, http.StatusNotFound, "project not found")
 		return
 	}
 
-	id := r.PathValue("id")
-	issue, err := s.store.GetIssue(r.Context(), id)
-	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, http.StatusNotFound, err.Error())
+	note := &models.ProjectNote{ProjectID: projectID, Title: strings.TrimSpace(req.Title)}
+	if req.Body != nil {
+		note.Body = *req.Body
+	}
+	if err := s.store.CreateProjectNote(ctx, note); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			writeError(w, http.StatusConflict, err.Error())
 			return
 		}
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	writeJSON(w, http.StatusCreated, note)
+}
 
-	enriched, err := s.llm.EnrichIssue(r.Context(), issue.Title, issue.Body, issue.Description)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, fmt.Sprintf("LLM enrichment failed: %v", err))
-		return
-	}
-
-	if enriched.Description != "" {
-		issue.Description = enriched.Description
-	}
-	if enriched.AIPrompt != "" {
-		issue.AIPrompt = enriched.AIPrompt
+// projectNote loads the note in the request path, writing 404 unless it
+// belongs to the project in the path.
+func (s *Server) projectNote(w http.ResponseWriter, r *http.Request) (*models.ProjectNote, bool) {
+	note, err := s.store.GetProjectNote(r.Context(), r.PathValue("noteId"))
+	if err != nil || note.ProjectID != r.PathValue("id") {
+		writeError(w, http.StatusNotFound, "note not found")
+		return nil, false
 	}
+	return note, true
+}
 
-	if err := s.store.UpdateIssue(r.Context(), issue); err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+func (s *Server) getProjectNote(w http.ResponseWriter, r *http.Request) {
+	note, ok := s.projectNote(w, r)
+	if !ok {
 		return
 	}
-	writeJSON(w, http.StatusOK, issue)
+	writeJSON(w, http.StatusOK, note)
 }
 
-func (s *Server) bulkUpdateIssues(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		IDs    []string `json:"ids"`
-		Status string   `json:"status"`
+func (s *Server) updateProjectNote(w http.ResponseWriter, r *http.Request) {
+	note, ok := s.projectNote(w, r)
+	if !ok {
+		return
 	}
+
+	var req ProjectNoteRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
-	if len(req.IDs) == 0 {
-		writeError(w, http.StatusBadRequest, "ids is required")
-		return
+	if title := strings.TrimSpace(req.Title); title != "" {
+		note.Title = title
 	}
-	if req.Status == "" {
-		writeError(w, http.StatusBadRequest, "status is required")
-		return
+	if req.Body != nil {
+		note.Body = *req.Body
 	}
-	n, err := s.store.BulkUpdateIssueStatus(r.Context(), req.IDs, models.IssueStatus(req.Status))
-	if err != nil {
+	if err := s.store.UpdateProjectNote(r.Context(), note); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]int64{"updated": n})
+	writeJSON(w, http.StatusOK, note)
 }
 
-func (s *Server) bulkDeleteIssues(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		IDs []string `json:"ids"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid JSON")
-		return
-	}
-	if len(req.IDs) == 0 {
-		writeError(w, http.StatusBadRequest, "ids is required")
+func (s *Server) deleteProjectNote(w http.ResponseWriter, r *http.Request) {
+	note, ok := s.projectNote(w, r)
+	if !ok {
 		return
 	}
-	n, err := s.store.BulkDeleteIssues(r.Context(), req.IDs)
-	if err != nil {
+	if err := s.store.DeleteProjectNote(r.Context(), note.ID); err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]int64{"deleted": n})
+	w.WriteHeader(http.StatusNoContent)
 }
 
-// --- Issue Reviews ---
+// --- Issues ---
 
-func (s *Server) listIssueReviews(w http.ResponseWriter, r *http.Request) {
-	issueID := r.PathValue("id")
-	reviews, err := s.store.ListIssueReviews(r.Context(), issueID)
+func (s *Server) listIssues(w http.ResponseWriter, r *http.Request) {
+	order, err := store.ParseIssueSort(r.URL.Query().Get("sort"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter := store.IssueListFilter{
+		ProjectID: r.URL.Query().Get("project_id"),
+		Tag:       r.URL.Query().Get("tag"),
+		Assignee:  r.URL.Query().Get("assignee"),
+		Sort:      order,
+	}
+	if v := r.URL.Query().Get("status"); v != "" {
+		if filter.Status, err = models.ParseIssueStatus(v); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if v := r.URL.Query().Get("priority"); v != "" {
+		if filter.Priority, err = models.ParseIssuePriority(v); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	issues, err := s.store.ListIssues(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	agent.AssessReadinessAll(r.Context(), s.store, issues)
+	writeJSON(w, http.StatusOK, issues)
+}
+
+func (s *Server) listProjectIssues(w http.ResponseWriter, r *http.Request) {
+	order, err := store.ParseIssueSort(r.URL.Query().Get("sort"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter := store.IssueListFilter{ProjectID: r.PathValue("id"), Sort: order}
+	issues, err := s.store.ListIssues(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	agent.AssessReadinessAll(r.Context(), s.store, issues)
+	writeJSON(w, http.StatusOK, issues)
+}
+
+func (s *Server) createProjectIssue(w http.ResponseWriter, r *http.Request) {
+	projectID := r.PathValue("id")
+	var issue models.Issue
+	if err := json.NewDecoder(r.Body).Decode(&issue); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	issue.ProjectID = projectID
+	if issue.Status == "" {
+		issue.Status = models.IssueStatusOpen
+	}
+	if issue.Priority == "" {
+		issue.Priority = models.IssuePriorityMedium
+	}
+	if issue.Type == "" {
+		issue.Type = models.IssueTypeFeature
+	}
+	issue.AcceptanceCriteria = models.TrimCriteria(issue.AcceptanceCriteria)
+	if err := issue.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	usage, err := s.autoEnrich(r.Context(), &issue)
+	if err != nil {
+		writeError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+
+	if err := s.store.CreateIssue(r.Context(), &issue); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.recordLLMUsage(r.Context(), usage, issue.ProjectID, issue.ID, models.LLMUsageEnrichment)
+	writeJSON(w, http.StatusCreated, issue)
+}
+
+// autoEnrich fills in the description, AI prompt and acceptance criteria of
+// a new issue when an LLM is available and the prompt is not already set.
+// Failures are ignored, as is the project being over its LLM budget unless
+// llm.budget.on_exceeded is reject: then the budget error is returned. The
+// tokens spent are returned for recording once the issue exists.
+func (s *Server) autoEnrich(ctx context.Context, issue *models.Issue) (*llm.Usage, error) {
+	if s.llm == nil || issue.AIPrompt != "" {
+		return nil, nil
+	}
+	if err := s.checkLLMBudget(ctx, issue.ProjectID); err != nil {
+		if errors.Is(err, llmbudget.ErrExceeded) && !s.budget.Degrade() {
+			return nil, err
+		}
+		return nil, nil
+	}
+	name, langs := s.projectLanguages(ctx, issue.ProjectID)
+	enriched, err := s.llm.EnrichIssue(ctx, name, langs, issue.Title, issue.Body, issue.Description)
+	if err != nil {
+		if enriched != nil {
+			return &enriched.Usage, nil
+		}
+		return nil, nil
+	}
+	issue.Redactions = enriched.Redactions
+	if issue.Description == "" && enriched.Description != "" {
+		issue.Description = enriched.Description
+	}
+	if enriched.AIPrompt != "" {
+		issue.AIPrompt = enriched.AIPrompt
+	}
+	if len(issue.AcceptanceCriteria) == 0 {
+		issue.AcceptanceCriteria = enriched.AcceptanceCriteria
+	}
+	return &enriched.Usage, nil
+}
+
+// checkLLMBudget returns an error wrapping llmbudget.ErrExceeded if the
+// project has used up this month's LLM budget.
+func (s *Server) checkLLMBudget(ctx context.Context, projectID string) error {
+	if s.budget == nil {
+		return nil
+	}
+	p, err := s.store.GetProject(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	return s.budget.Check(ctx, s.store, p, time.Now())
+}
+
+// recordLLMUsage records the tokens an LLM call spent, if it was made.
+// Failures are ignored: the call has already been made.
+func (s *Server) recordLLMUsage(ctx context.Context, u *llm.Usage, projectID, issueID string, purpose models.LLMUsagePurpose) {
+	if u == nil {
+		return
+	}
+	_ = s.store.RecordLLMUsage(ctx, u.Record(projectID, issueID, purpose))
+}
+
+// projectLanguages returns the name of a project, for per-project settings,
+// and its main languages, for prompts tailored to them. Both are empty if the
+// project cannot be found.
+func (s *Server) projectLanguages(ctx context.Context, id string) (string, []string) {
+	p, err := s.store.GetProject(ctx, id)
+	if err != nil {
+		return "", nil
+	}
+	return p.Name, p.MainLanguages()
+}
+
+// maxIntakeBytes caps inbound intake payloads.
+const maxIntakeBytes = 1 << 20
+
+// IntakeResponse is the JSON body returned by POST /api/v1/intake.
+type IntakeResponse struct {
+	IssueID string `json:"issue_id"`
+	Project string `json:"project"`
+	Title   string `json:"title"`
+}
+
+// intakeIssue creates an issue from a raw email or text payload, or from a
+// JSON {from, to, subject, body} webhook payload. The target project is the
+// project query parameter, else a [project] tag in the subject, else the
+// configured route for the recipient, else the default project.
+func (s *Server) intakeIssue(w http.ResponseWriter, r *http.Request) {
+	cfg := s.settings().intake
+	if !cfg.Enabled() {
+		writeError(w, http.StatusServiceUnavailable, "intake not configured (set intake.secret)")
+		return
+	}
+	// Only a header: query strings end up in access logs and proxies
+	if !cfg.Authorized(r.Header.Get("X-PM-Intake-Secret")) {
+		writeError(w, http.StatusUnauthorized, "invalid intake secret")
+		return
+	}
+
+	raw, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIntakeBytes))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, "payload too large")
+		return
+	}
+	var msg intake.Message
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	} else if msg, err = intake.Parse(raw); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	title, tag := intake.CleanSubject(msg.Subject)
+	if title == "" {
+		writeError(w, http.StatusBadRequest, "subject is required")
+		return
+	}
+
+	ctx := r.Context()
+	var project *models.Project
+	// An explicit project must exist rather than fall through to routing
+	if name := r.URL.Query().Get("project"); name != "" {
+		if project, err = s.store.GetProjectByName(ctx, name); err != nil {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("project not found: %s", name))
+			return
+		}
+	} else {
+		for _, name := range []string{tag, cfg.Route(msg)} {
+			if name == "" {
+				continue
+			}
+			if project, err = s.store.GetProjectByName(ctx, name); err == nil {
+				break
+			}
+			project = nil
+		}
+	}
+	if project == nil {
+		writeError(w, http.StatusUnprocessableEntity, "no project matches this message (use ?project=, a [project] subject tag, or intake.routes)")
+		return
+	}
+
+	issue := models.Issue{
+		ProjectID: project.ID,
+		Title:     title,
+		Body:      msg.Body,
+		Status:    models.IssueStatusOpen,
+		Priority:  models.IssuePriorityMedium,
+		Type:      models.IssueTypeBug,
+	}
+	if msg.From != "" {
+		issue.Body = fmt.Sprintf("From: %s\n\n%s", msg.From, msg.Body)
+	}
+	usage, err := s.autoEnrich(ctx, &issue)
+	if err != nil {
+		writeError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+
+	if err := s.store.CreateIssue(ctx, &issue); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.recordLLMUsage(ctx, usage, issue.ProjectID, issue.ID, models.LLMUsageEnrichment)
+	writeJSON(w, http.StatusCreated, IntakeResponse{IssueID: issue.ID, Project: project.Name, Title: issue.Title})
+}
+
+func (s *Server) getIssue(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	issue, err := s.store.GetIssue(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	issue.Readiness = agent.AssessReadiness(r.Context(), s.store, issue)
+	writeJSON(w, http.StatusOK, issue)
+}
+
+// RenderedIssue is the response of GET /api/v1/issues/{id}/rendered: the
+// issue's Markdown text as sanitized HTML.
+type RenderedIssue struct {
+	IssueID         string `json:"issue_id"`
+	Title           string `json:"title"`
+	DescriptionHTML string `json:"description_html"`
+	BodyHTML        string `json:"body_html"`
+	// Stylesheet for the highlighted code blocks; with ?css=false it is left out
+	HighlightCSS string `json:"highlight_css,omitempty"`
+}
+
+// renderIssue renders an issue's description and body from Markdown to
+// sanitized HTML with highlighted code blocks.
+func (s *Server) renderIssue(w http.ResponseWriter, r *http.Request) {
+	issue, err := s.store.GetIssue(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := RenderedIssue{IssueID: issue.ID, Title: issue.Title}
+	if resp.DescriptionHTML, err = markdown.Render(issue.Description); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("render description: %v", err))
+		return
+	}
+	if resp.BodyHTML, err = markdown.Render(issue.Body); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("render body: %v", err))
+		return
+	}
+	if r.URL.Query().Get("css") != "false" {
+		resp.HighlightCSS = markdown.HighlightCSS()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) updateIssue(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	var issue models.Issue
+	if err := json.NewDecoder(r.Body).Decode(&issue); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	issue.ID = id
+	issue.AcceptanceCriteria = models.TrimCriteria(issue.AcceptanceCriteria)
+	if err := issue.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.store.UpdateIssue(r.Context(), &issue); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, issue)
+}
+
+func (s *Server) deleteIssue(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := s.store.DeleteIssue(r.Context(), id); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) enrichIssue(w http.ResponseWriter, r *http.Request) {
+	if s.llm == nil {
+		writeError(w, http.StatusServiceUnavailable, s.unavailable(capabilities.LLM))
+		return
+	}
+
+	id := r.PathValue("id")
+	issue, err := s.store.GetIssue(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Enrichment was asked for, so there is nothing to fall back to.
+	if err := s.checkLLMBudget(r.Context(), issue.ProjectID); err != nil {
+		if errors.Is(err, llmbudget.ErrExceeded) {
+			writeError(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	name, langs := s.projectLanguages(r.Context(), issue.ProjectID)
+	enriched, err := s.llm.EnrichIssue(r.Context(), name, langs, issue.Title, issue.Body, issue.Description)
+	if enriched != nil {
+		s.recordLLMUsage(r.Context(), &enriched.Usage, issue.ProjectID, issue.ID, models.LLMUsageEnrichment)
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("LLM enrichment failed: %v", err))
+		return
+	}
+	issue.Redactions = enriched.Redactions
+
+	if enriched.Description != "" {
+		issue.Description = enriched.Description
+	}
+	if enriched.AIPrompt != "" {
+		issue.AIPrompt = enriched.AIPrompt
+	}
+	if len(issue.AcceptanceCriteria) == 0 {
+		issue.AcceptanceCriteria = enriched.AcceptanceCriteria
+	}
+
+	if err := s.store.UpdateIssue(r.Context(), issue); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, issue)
+}
+
+func (s *Server) bulkUpdateIssues(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		IDs    []string `json:"ids"`
+		Status string   `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if len(req.IDs) == 0 {
+		writeError(w, http.StatusBadRequest, "ids is required")
+		return
+	}
+	if req.Status == "" {
+		writeError(w, http.StatusBadRequest, "status is required")
+		return
+	}
+	status, err := models.ParseIssueStatus(req.Status)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	n, err := s.store.BulkUpdateIssueStatus(r.Context(), req.IDs, status)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int64{"updated": n})
+}
+
+// bulkDeleteIssues deletes issues. Issues with open sessions are refused
+// with 409 unless force is set.
+func (s *Server) bulkDeleteIssues(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		IDs   []string `json:"ids"`
+		Force bool     `json:"force"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if len(req.IDs) == 0 {
+		writeError(w, http.StatusBadRequest, "ids is required")
+		return
+	}
+	n, err := s.store.BulkDeleteIssues(r.Context(), req.IDs, req.Force)
+	if errors.Is(err, store.ErrIssuesHaveOpenSessions) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int64{"deleted": n})
+}
+
+// --- Reports ---
+
+// slaReport evaluates the SLA policies and returns the summary. It writes
+// nothing: the scheduled evaluation records states and breaches.
+func (s *Server) slaReport(w http.ResponseWriter, r *http.Request) {
+	report, err := sla.Check(r.Context(), s.store, s.slaPolicies, time.Now())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// outcomeReport breaks ended sessions' outcome classes down by issue type,
+// project and prompt template.
+func (s *Server) outcomeReport(w http.ResponseWriter, r *http.Request) {
+	report, err := agent.BuildOutcomeReport(r.Context(), s.store, store.SessionListFilter{
+		ProjectID: r.URL.Query().Get("project_id"),
+		Label:     r.URL.Query().Get("label"),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// focusReport adds up sessions' focus and idle time per issue.
+func (s *Server) focusReport(w http.ResponseWriter, r *http.Request) {
+	report, err := agent.BuildFocusReport(r.Context(), s.store, store.SessionListFilter{
+		ProjectID: r.URL.Query().Get("project_id"),
+		Label:     r.URL.Query().Get("label"),
+	}, time.Now())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// boardDiffReport diffs the issue boards of every project, or project_id's,
+// now with the boards since ago (default 7d): issues added, deleted and moved
+// between statuses, and net backlog growth.
+func (s *Server) boardDiffReport(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	sinceParam := r.URL.Query().Get("since")
+	if sinceParam == "" {
+		sinceParam = "7d"
+	}
+	since, err := flow.ParseSince(sinceParam, now)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var projects []*models.Project
+	if id := r.URL.Query().Get("project_id"); id != "" {
+		p, err := s.store.GetProject(r.Context(), id)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "project not found")
+			return
+		}
+		projects = []*models.Project{p}
+	} else if projects, err = s.store.ListProjects(r.Context(), ""); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	diff, err := flow.BuildBoardDiff(r.Context(), s.store, projects, since, now)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, diff)
+}
+
+// --- Issue Reviews ---
+
+func (s *Server) listIssueReviews(w http.ResponseWriter, r *http.Request) {
+	issueID := r.PathValue("id")
+	reviews, err := s.store.ListIssueReviews(r.Context(), issueID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if reviews == nil {
+		reviews = []*models.IssueReview{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(reviews)
+}
+
+func (s *Server) createIssueReview(w http.ResponseWriter, r *http.Request) {
+	issueID := r.PathValue("id")
+
+	var body struct {
+		Verdict           string   `json:"verdict"`
+		Summary           string   `json:"summary"`
+		CodeQuality       string   `json:"code_quality"`
+		RequirementsMatch string   `json:"requirements_match"`
+		TestCoverage      string   `json:"test_coverage"`
+		UIUX              string   `json:"ui_ux"`
+		FailureReasons    []string `json:"failure_reasons"`
+		DiffStats         string   `json:"diff_stats"`
+		Criteria          []struct {
+			Criterion string `json:"criterion"`
+			Passed    bool   `json:"passed"`
+			Note      string `json:"note"`
+		} `json:"criteria"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	verdict, err := models.ParseReviewVerdict(body.Verdict)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := &models.IssueReview{
+		IssueID:           issueID,
+		Verdict:           verdict,
+		Summary:           body.Summary,
+		CodeQuality:       models.ReviewCategory(body.CodeQuality),
+		RequirementsMatch: models.ReviewCategory(body.RequirementsMatch),
+		TestCoverage:      models.ReviewCategory(body.TestCoverage),
+		UIUX:              models.ReviewCategory(body.UIUX),
+		FailureReasons:    body.FailureReasons,
+		DiffStats:         body.DiffStats,
+		ReviewedAt:        time.Now().UTC(),
+	}
+	if err := review.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, c := range body.Criteria {
+		review.Criteria = append(review.Criteria, models.CriterionResult{Criterion: c.Criterion, Passed: c.Passed, Note: c.Note})
+	}
+
+	if err := s.store.CreateIssueReview(r.Context(), review); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := agent.MarkReviewFailed(r.Context(), s.store, review); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(review)
+}
+
+// --- Issue Relations ---
+
+// issueRelationEntry is one relation as seen from the requested issue.
+type issueRelationEntry struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Label     string `json:"label"`
+	Direction string `json:"direction"` // outgoing or incoming
+	IssueID   string `json:"issue_id"`
+	Title     string `json:"title"`
+	Status    string `json:"status"`
+	ProjectID string `json:"project_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+func toRelationEntry(ri store.RelatedIssue) issueRelationEntry {
+	direction := "incoming"
+	if ri.Outgoing {
+		direction = "outgoing"
+	}
+	return issueRelationEntry{
+		ID:        ri.Relation.ID,
+		Type:      string(ri.Relation.Type),
+		Label:     ri.Label,
+		Direction: direction,
+		IssueID:   ri.Issue.ID,
+		Title:     ri.Issue.Title,
+		Status:    string(ri.Issue.Status),
+		ProjectID: ri.Issue.ProjectID,
+		CreatedAt: ri.Relation.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func (s *Server) listIssueRelations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	issueID := r.PathValue("id")
+	if _, err := s.store.GetIssue(ctx, issueID); err != nil {
+		writeError(w, http.StatusNotFound, "issue not found")
+		return
+	}
+
+	related, err := store.RelatedIssues(ctx, s.store, issueID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	entries := make([]issueRelationEntry, 0, len(related))
+	for _, ri := range related {
+		entries = append(entries, toRelationEntry(ri))
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// CreateIssueRelationRequest is the JSON body for POST /api/v1/issues/{id}/relations.
+type CreateIssueRelationRequest struct {
+	TargetIssueID string `json:"target_issue_id"`
+	Type          string `json:"type"`
+}
+
+func (s *Server) createIssueRelation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	issueID := r.PathValue("id")
+
+	var req CreateIssueRelationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	relType := models.RelationType(req.Type)
+	if !relType.Valid() {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid relation type: %s (must be relates_to, duplicates, caused_by, blocks, or split_from)", req.Type))
+		return
+	}
+	if req.TargetIssueID == "" {
+		writeError(w, http.StatusBadRequest, "target_issue_id is required")
 		return
 	}
-	if reviews == nil {
-		reviews = []*models.IssueReview{}
+	if _, err := s.store.GetIssue(ctx, issueID); err != nil {
+		writeError(w, http.StatusNotFound, "issue not found")
+		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(reviews)
+	target, err := s.store.GetIssue(ctx, req.TargetIssueID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "target issue not found")
+		return
+	}
+
+	rel := &models.IssueRelation{SourceIssueID: issueID, TargetIssueID: target.ID, Type: relType}
+	if err := s.store.CreateIssueRelation(ctx, rel); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, toRelationEntry(store.RelatedIssue{
+		Relation: rel,
+		Label:    relType.Label(),
+		Outgoing: true,
+		Issue:    target,
+	}))
 }
 
-func (s *Server) createIssueReview(w http.ResponseWriter, r *http.Request) {
+func (s *Server) deleteIssueRelation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	issueID := r.PathValue("id")
+	relationID := r.PathValue("relationId")
 
-	var body struct {
-		Verdict           string   `json:"verdict"`
-		Summary           string   `json:"summary"`
-		CodeQuality       string   `json:"code_quality"`
-		RequirementsMatch string   `json:"requirements_match"`
-		TestCoverage      string   `json:"test_coverage"`
-		UIUX              string   `json:"ui_ux"`
-		FailureReasons    []string `json:"failure_reasons"`
-		DiffStats         string   `json:"diff_stats"`
+	// Only delete relations attached to the issue in the path
+	rels, err := s.store.ListIssueRelations(ctx, issueID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+	found := false
+	for _, rel := range rels {
+		if rel.ID == relationID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		writeError(w, http.StatusNotFound, "relation not found")
 		return
 	}
 
-	review := &models.IssueReview{
-		IssueID:           issueID,
-		Verdict:           models.ReviewVerdict(body.Verdict),
-		Summary:           body.Summary,
-		CodeQuality:       models.ReviewCategory(body.CodeQuality),
-		RequirementsMatch: models.ReviewCategory(body.RequirementsMatch),
-		TestCoverage:      models.ReviewCategory(body.TestCoverage),
-		UIUX:              models.ReviewCategory(body.UIUX),
-		FailureReasons:    body.FailureReasons,
-		DiffStats:         body.DiffStats,
-		ReviewedAt:        time.Now().UTC(),
+	if err := s.store.DeleteIssueRelation(ctx, relationID); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
 	}
+	w.WriteHeader(http.StatusNoContent)
+}
 
-	if err := s.store.CreateIssueReview(r.Context(), review); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+// --- Issue Watchers and Comments ---
+
+func (s *Server) listIssueWatchers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	issueID := r.PathValue("id")
+	if _, err := s.store.GetIssue(ctx, issueID); err != nil {
+		writeError(w, http.StatusNotFound, "issue not found")
+		return
+	}
+	watchers, err := s.store.ListIssueWatchers(ctx, issueID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if watchers == nil {
+		watchers = []*models.IssueWatcher{}
+	}
+	writeJSON(w, http.StatusOK, watchers)
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(review)
+// RankIssueRequest is the JSON body for PATCH /api/v1/issues/{id}/rank. Set
+// one of Before, After (issue IDs of the same status column) or Position.
+type RankIssueRequest struct {
+	Before   string `json:"before,omitempty"`
+	After    string `json:"after,omitempty"`
+	Position string `json:"position,omitempty"` // top or bottom
+}
+
+// rankIssue moves an issue within its status column and returns it with its
+// new rank.
+func (s *Server) rankIssue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	issueID := r.PathValue("id")
+
+	var req RankIssueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.Position != "" && req.Position != "top" && req.Position != "bottom" {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid position %q (want top or bottom)", req.Position))
+		return
+	}
+	if _, err := s.store.GetIssue(ctx, issueID); err != nil {
+		writeError(w, http.StatusNotFound, "issue not found")
+		return
+	}
+
+	issue, err := store.RankIssue(ctx, s.store, issueID, store.RankPlacement{
+		Before: req.Before,
+		After:  req.After,
+		Top:    req.Position == "top",
+		Bottom: req.Position == "bottom",
+	})
+	if errors.Is(err, store.ErrRankPlacement) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, issue)
+}
+
+// WatchIssueRequest is the JSON body for POST /api/v1/issues/{id}/watchers.
+type WatchIssueRequest struct {
+	Target string `json:"target"`         // email address or webhook URL
+	Kind   string `json:"kind,omitempty"` // email or webhook; inferred from target when empty
+}
+
+// watchIssue subscribes a target to the issue: 201 when it was added, 200
+// when it already watched the issue.
+func (s *Server) watchIssue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	issueID := r.PathValue("id")
+
+	var req WatchIssueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	kind, target, err := models.ParseWatcherTarget(req.Kind, req.Target)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, scoped := auth.FromContext(ctx); scoped && kind == models.WatcherWebhook {
+		if err := notify.CheckWebhookHost(ctx, target); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if _, err := s.store.GetIssue(ctx, issueID); err != nil {
+		writeError(w, http.StatusNotFound, "issue not found")
+		return
+	}
+
+	watcher := &models.IssueWatcher{IssueID: issueID, Kind: kind, Target: target}
+	added, err := s.store.AddIssueWatcher(ctx, watcher)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !added {
+		watchers, err := s.store.ListIssueWatchers(ctx, issueID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		for _, existing := range watchers {
+			if existing.Target == target {
+				writeJSON(w, http.StatusOK, existing)
+				return
+			}
+		}
+	}
+	writeJSON(w, http.StatusCreated, watcher)
+}
+
+// unwatchIssue removes the watcher given by ?target=.
+func (s *Server) unwatchIssue(w http.ResponseWriter, r *http.Request) {
+	target := strings.TrimSpace(r.URL.Query().Get("target"))
+	if target == "" {
+		writeError(w, http.StatusBadRequest, "target is required")
+		return
+	}
+	if _, normalized, err := models.ParseWatcherTarget("", target); err == nil {
+		target = normalized
+	}
+	if err := s.store.RemoveIssueWatcher(r.Context(), r.PathValue("id"), target); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listIssueComments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	issueID := r.PathValue("id")
+	if _, err := s.store.GetIssue(ctx, issueID); err != nil {
+		writeError(w, http.StatusNotFound, "issue not found")
+		return
+	}
+	comments, err := s.store.ListIssueComments(ctx, issueID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if comments == nil {
+		comments = []*models.IssueComment{}
+	}
+	writeJSON(w, http.StatusOK, comments)
+}
+
+// CreateIssueCommentRequest is the JSON body for POST /api/v1/issues/{id}/comments.
+type CreateIssueCommentRequest struct {
+	Author string `json:"author"`
+	Body   string `json:"body"`
+}
+
+func (s *Server) createIssueComment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	issueID := r.PathValue("id")
+
+	var req CreateIssueCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		writeError(w, http.StatusBadRequest, "body is required")
+		return
+	}
+	if _, err := s.store.GetIssue(ctx, issueID); err != nil {
+		writeError(w, http.StatusNotFound, "issue not found")
+		return
+	}
+
+	c := &models.IssueComment{IssueID: issueID, Author: strings.TrimSpace(req.Author), Body: req.Body}
+	if err := s.store.CreateIssueComment(ctx, c); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, c)
+}
+
+// --- Issue Closures ---
+
+func (s *Server) listIssueClosures(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	issueID := r.PathValue("id")
+	if _, err := s.store.GetIssue(ctx, issueID); err != nil {
+		writeError(w, http.StatusNotFound, "issue not found")
+		return
+	}
+
+	closures, err := s.store.ListIssueClosures(ctx, "", issueID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if closures == nil {
+		closures = []*models.IssueClosure{}
+	}
+	writeJSON(w, http.StatusOK, closures)
+}
+
+func (s *Server) revertIssueClosure(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c, err := s.store.GetIssueClosure(ctx, r.PathValue("closureId"))
+	if err != nil || c.IssueID != r.PathValue("id") {
+		writeError(w, http.StatusNotFound, "issue closure not found")
+		return
+	}
+
+	gh, _ := s.gh.(autoclose.GitHub)
+	c, err = autoclose.Revert(ctx, s.store, gh, c.ID)
+	if err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+// AutoCloseRequest is the JSON body for POST /api/v1/projects/{id}/autoclose.
+type AutoCloseRequest struct {
+	Limit  int  `json:"limit"` // merged pull requests to scan (default 30)
+	DryRun bool `json:"dry_run"`
+}
+
+// autoCloseProject closes the project's issues referenced by its recently
+// merged pull requests.
+func (s *Server) autoCloseProject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	p, err := s.store.GetProject(ctx, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+
+	var req AutoCloseRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	if req.Limit <= 0 {
+		req.Limit = 30
+	}
+	if !s.autoClose.Enabled {
+		writeError(w, http.StatusConflict, "automatic issue closure is disabled")
+		return
+	}
+	if err := s.caps.Require(capabilities.Forge); err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	prs, ok := s.gh.(autoclose.PRLister)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "GitHub client cannot list merged pull requests")
+		return
+	}
+
+	closures, err := autoclose.ScanMergedPRs(ctx, s.store, s.autoClose, prs, p, req.Limit, req.DryRun)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	if closures == nil {
+		closures = []*models.IssueClosure{}
+	}
+	writeJSON(w, http.StatusOK, closures)
+}
+
+// SyncPRReviewsRequest is the JSON body for POST /api/v1/projects/{id}/pr-reviews/sync.
+type SyncPRReviewsRequest struct {
+	FollowUps bool `json:"follow_ups"` // open a follow-up issue per changes-requested review
+	DryRun    bool `json:"dry_run"`
+}
+
+// syncPRReviews imports the new reviews of the project's open session pull
+// requests into the sessions' issues.
+func (s *Server) syncPRReviews(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	p, err := s.store.GetProject(ctx, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+
+	var req SyncPRReviewsRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	if err := s.caps.Require(capabilities.Forge); err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	gh, ok := s.gh.(prreviews.GitHub)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "GitHub client cannot list pull request reviews")
+		return
+	}
+
+	imports, err := prreviews.Sync(ctx, s.store, gh, p, prreviews.Options{FollowUps: req.FollowUps, DryRun: req.DryRun})
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	if imports == nil {
+		imports = []*models.PRReviewImport{}
+	}
+	writeJSON(w, http.StatusOK, imports)
+}
+
+// SyncBoardRequest is the JSON body for POST /api/v1/projects/{id}/board/sync.
+type SyncBoardRequest struct {
+	DryRun bool `json:"dry_run"`
+}
+
+// syncBoard syncs the status and priority of the project's linked issues
+// with its GitHub Projects board.
+func (s *Server) syncBoard(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	p, err := s.store.GetProject(ctx, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+
+	var req SyncBoardRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	if p.GitHubBoard == "" {
+		writeError(w, http.StatusBadRequest, "project has no GitHub board")
+		return
+	}
+	if err := s.caps.Require(capabilities.Forge); err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	gh, ok := s.gh.(boardsync.GitHub)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "GitHub client cannot sync project boards")
+		return
+	}
+
+	opts := s.settings().boardSync
+	opts.DryRun = req.DryRun
+	changes, err := boardsync.Sync(ctx, s.store, gh, p, opts)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	if changes == nil {
+		changes = []boardsync.Change{}
+	}
+	writeJSON(w, http.StatusOK, changes)
 }
 
 // --- Status ---
 
 type statusEntry struct {
-	Project       *models.Project    `json:"project"`
-	Branch        string             `json:"branch"`
-	IsDirty       bool               `json:"isDirty"`
-	OpenIssues    int                `json:"openIssues"`
-	InProgress    int                `json:"inProgressIssues"`
-	Health        int                `json:"health"`
-	LastActivity  string             `json:"lastActivity"`
-	LatestVersion string             `json:"latestVersion,omitempty"`
-	ReleaseDate   string             `json:"releaseDate,omitempty"`
-	VersionSource string             `json:"versionSource,omitempty"`
-	ReleaseAssets []git.ReleaseAsset `json:"releaseAssets,omitempty"`
+	Project       *models.Project       `json:"project"`
+	Branch        string                `json:"branch"`
+	IsDirty       bool                  `json:"isDirty"`
+	OpenIssues    int                   `json:"openIssues"`
+	InProgress    int                   `json:"inProgressIssues"`
+	Health        int                   `json:"health"`
+	LastActivity  string                `json:"lastActivity"`
+	LatestVersion string                `json:"latestVersion,omitempty"`
+	ReleaseDate   string                `json:"releaseDate,omitempty"`
+	VersionSource string                `json:"versionSource,omitempty"`
+	ReleaseAssets []models.ReleaseAsset `json:"releaseAssets,omitempty"`
+	// ReleaseCache says how fresh the stored GitHub release is.
+	ReleaseCache *releases.Freshness `json:"releaseCache,omitempty"`
+	WIPWarnings  []string            `json:"wipWarnings,omitempty"`
 }
 
 func (s *Server) statusOverview(w http.ResponseWriter, r *http.Request) {
@@ -513,18 +2033,18 @@ func (s *Server) buildStatusEntry(ctx context.Context, p *models.Project) status
 	meta := &health.ProjectMetadata{}
 
 	// Git info
-	if branch, err := s.git.CurrentBranch(p.Path); err == nil {
+	if branch, err := s.git.CurrentBranch(ctx, p.Path); err == nil {
 		entry.Branch = branch
 	}
-	if dirty, err := s.git.IsDirty(p.Path); err == nil {
+	if dirty, err := s.git.IsDirty(ctx, p.Path); err == nil {
 		entry.IsDirty = dirty
 		meta.IsDirty = dirty
 	}
-	if date, err := s.git.LastCommitDate(p.Path); err == nil {
-		entry.LastActivity = date.Format("2006-01-02T15:04:05Z")
+	if date, err := s.git.LastCommitDate(ctx, p.Path); err == nil {
+		entry.LastActivity = date.UTC().Format(time.RFC3339)
 		meta.LastCommitDate = date
 	}
-	if branches, err := s.git.BranchList(p.Path); err == nil {
+	if branches, err := s.git.BranchList(ctx, p.Path); err == nil {
 		meta.BranchCount = len(branches)
 	}
 
@@ -534,28 +2054,29 @@ func (s *Server) buildStatusEntry(ctx context.Context, p *models.Project) status
 		switch i.Status {
 		case models.IssueStatusOpen:
 			entry.OpenIssues++
-		case models.IssueStatusInProgress:
+		case models.IssueStatusInProgress, models.IssueStatusInReview:
 			entry.InProgress++
 		}
 	}
-
-	// Version info: GitHub release primary, local git tag fallback
-	if p.RepoURL != "" {
-		if owner, repo, err := git.ExtractOwnerRepo(p.RepoURL); err == nil {
-			if rel, err := s.gh.LatestRelease(owner, repo); err == nil {
-				entry.LatestVersion = rel.TagName
-				entry.ReleaseDate = rel.PublishedAt
-				entry.VersionSource = "github"
-				entry.ReleaseAssets = rel.Assets
-				if t, parseErr := time.Parse(time.RFC3339, rel.PublishedAt); parseErr == nil {
-					meta.LatestRelease = rel.TagName
-					meta.ReleaseDate = t
-				}
-			}
+	if wip, err := agent.CheckWIP(ctx, s.store, p, 0, 0); err == nil {
+		entry.WIPWarnings = wip.Warnings
+	}
+
+	// Version info: stored GitHub release primary, local git tag fallback
+	if rel := releases.Cached(ctx, s.store, s.gh, p, s.releaseSchedule); rel != nil {
+		entry.LatestVersion = rel.TagName
+		entry.VersionSource = "github"
+		entry.ReleaseAssets = rel.Assets
+		fresh := releases.FreshnessOf(rel, s.releaseSchedule, time.Now())
+		entry.ReleaseCache = &fresh
+		if rel.PublishedAt != nil {
+			entry.ReleaseDate = rel.PublishedAt.Format(time.RFC3339)
+			meta.LatestRelease = rel.TagName
+			meta.ReleaseDate = *rel.PublishedAt
 		}
 	}
 	if entry.LatestVersion == "" {
-		if tag, err := s.git.LatestTag(p.Path); err == nil {
+		if tag, err := s.git.LatestTag(ctx, p.Path); err == nil {
 			entry.LatestVersion = tag
 			entry.VersionSource = "git-tag"
 			meta.LatestRelease = tag
@@ -573,12 +2094,23 @@ func (s *Server) buildStatusEntry(ctx context.Context, p *models.Project) status
 
 type sessionResponse struct {
 	*models.AgentSession
-	ProjectName string `json:"ProjectName"`
+	ProjectName    string `json:"ProjectName"`
+	BlockedOverdue bool   `json:"BlockedOverdue,omitempty"` // blocked longer than the reminder threshold
+	// Status the background reconciler will move the session to (its
+	// worktree is gone or back, or its agent process started or stopped)
+	PendingStatus models.SessionStatus `json:"PendingStatus,omitempty"`
+
+	// Set by ?expand=: the live git state and close-check of the session,
+	// and why an enrichment is missing (keyed by its expand name).
+	Git          *sessionGit       `json:"Git,omitempty"`
+	CloseCheck   *agent.CloseCheck `json:"CloseCheck,omitempty"`
+	ExpandErrors map[string]string `json:"ExpandErrors,omitempty"`
 }
 
 type sessionDetailResponse struct {
 	*models.AgentSession
 	ProjectName    string `json:"ProjectName"`
+	BlockedOverdue bool   `json:"BlockedOverdue,omitempty"`
 	WorktreeExists bool   `json:"WorktreeExists"`
 	IsDirty        bool   `json:"IsDirty,omitempty"`
 	CurrentBranch  string `json:"CurrentBranch,omitempty"`
@@ -587,61 +2119,81 @@ type sessionDetailResponse struct {
 }
 
 func (s *Server) listSessions(w http.ResponseWriter, r *http.Request) {
-	projectID := r.URL.Query().Get("project_id")
-	statusFilter := r.URL.Query().Get("status")
+	q := r.URL.Query()
+	s.writeSessionList(w, r, store.SessionListFilter{
+		ProjectID: q.Get("project_id"),
+		IssueID:   q.Get("issue_id"),
+		Branch:    q.Get("branch"),
+		Label:     q.Get("label"),
+		Limit:     50,
+	})
+}
 
-	var allSessions []*models.AgentSession
-	var err error
+// listIssueSessions lists every session that worked on an issue, newest
+// first.
+func (s *Server) listIssueSessions(w http.ResponseWriter, r *http.Request) {
+	issue, err := s.store.GetIssue(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.writeSessionList(w, r, store.SessionListFilter{IssueID: issue.ID})
+}
 
-	if statusFilter != "" {
-		// Parse comma-separated statuses
-		var statuses []models.SessionStatus
-		for _, st := range strings.Split(statusFilter, ",") {
-			st = strings.TrimSpace(st)
-			if st != "" {
-				statuses = append(statuses, models.SessionStatus(st))
-			}
+// writeSessionList writes the sessions filter selects, narrowed by the
+// request's status parameter and enriched as its expand parameter asks.
+func (s *Server) writeSessionList(w http.ResponseWriter, r *http.Request, filter store.SessionListFilter) {
+	expand, err := parseSessionExpand(r.URL.Query().Get("expand"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Parse comma-separated statuses
+	for _, st := range strings.Split(r.URL.Query().Get("status"), ",") {
+		st = strings.TrimSpace(st)
+		if st == "" {
+			continue
 		}
-		allSessions, err = s.store.ListAgentSessionsByStatus(r.Context(), projectID, statuses, 50)
-	} else {
-		allSessions, err = s.store.ListAgentSessions(r.Context(), projectID, 50)
+		status, err := models.ParseSessionStatus(st)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		filter.Statuses = append(filter.Statuses, status)
 	}
+
+	allSessions, err := s.store.ListAgentSessionsFiltered(r.Context(), filter)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	// Lightweight reconcile: check worktree status for returned sessions only.
-	// Reconciliation may change session statuses (e.g. idle → abandoned),
-	// so re-query from DB afterward to get consistent results matching the filter.
-	var reconcileOpts []agent.ReconcileOption
-	if s.processDetector != nil {
-		reconcileOpts = append(reconcileOpts, agent.WithProcessDetector(s.processDetector))
-	}
-	if changed := agent.ReconcileSessions(r.Context(), s.store, allSessions, reconcileOpts...); changed > 0 {
-		// Always re-query from DB after reconciliation to get consistent state.
-		// In-memory session objects may have stale statuses if updates were
-		// skipped (e.g. unique constraint) or only partially applied.
-		if statusFilter != "" {
-			var statuses []models.SessionStatus
-			for _, st := range strings.Split(statusFilter, ",") {
-				st = strings.TrimSpace(st)
-				if st != "" {
-					statuses = append(statuses, models.SessionStatus(st))
-				}
+	// Reconciliation writes happen in the background reconciler, so that
+	// polling the list does not contend for the database. Here sessions it
+	// would change are only flagged with their pending status, and dropped
+	// when that status falls outside the requested ones.
+	pending := agent.PendingReconciliation(allSessions, s.reconcileOptions()...)
+	if len(pending) > 0 {
+		s.requestReconcile()
+	}
+	if len(filter.Statuses) > 0 {
+		kept := allSessions[:0]
+		for _, sess := range allSessions {
+			if st, ok := pending[sess.ID]; ok && !slices.Contains(filter.Statuses, st) {
+				continue
 			}
-			allSessions, err = s.store.ListAgentSessionsByStatus(r.Context(), projectID, statuses, 50)
-		} else {
-			allSessions, err = s.store.ListAgentSessions(r.Context(), projectID, 50)
-		}
-		if err != nil {
-			writeError(w, http.StatusInternalServerError, err.Error())
-			return
+			kept = append(kept, sess)
 		}
+		allSessions = kept
 	}
 	sessions := allSessions
 
 	// Build enriched responses with project names (cached by project ID)
+	now := time.Now()
 	nameCache := make(map[string]string)
 	result := make([]sessionResponse, 0, len(sessions))
 	for _, sess := range sessions {
@@ -653,10 +2205,13 @@ func (s *Server) listSessions(w http.ResponseWriter, r *http.Request) {
 			nameCache[sess.ProjectID] = name
 		}
 		result = append(result, sessionResponse{
-			AgentSession: sess,
-			ProjectName:  name,
+			AgentSession:   sess,
+			ProjectName:    name,
+			BlockedOverdue: agent.BlockedTooLong(sess, s.blockedRemindAfter, now),
+			PendingStatus:  pending[sess.ID],
 		})
 	}
+	s.expandSessions(r.Context(), result, expand)
 	writeJSON(w, http.StatusOK, result)
 }
 
@@ -679,35 +2234,19 @@ func (s *Server) getSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	resp := sessionDetailResponse{
-		AgentSession: sess,
-		ProjectName:  projectName,
+		AgentSession:   sess,
+		ProjectName:    projectName,
+		BlockedOverdue: agent.BlockedTooLong(sess, s.blockedRemindAfter, time.Now()),
 	}
 
-	// Check if worktree path exists and enrich with live git data
-	if _, err := os.Stat(sess.WorktreePath); err == nil {
-		resp.WorktreeExists = true
-
-		if dirty, err := s.git.IsDirty(sess.WorktreePath); err == nil {
-			resp.IsDirty = dirty
-		}
-		if branch, err := s.git.CurrentBranch(sess.WorktreePath); err == nil {
-			resp.CurrentBranch = branch
-		}
-		if ahead, behind, err := s.git.AheadBehind(sess.WorktreePath, "main"); err == nil {
-			resp.AheadCount = ahead
-			resp.BehindCount = behind
-			// Use ahead count as commit count when stored value is stale
-			if ahead > sess.CommitCount {
-				sess.CommitCount = ahead
-			}
-		}
-		if hash, err := s.git.LastCommitHash(sess.WorktreePath); err == nil {
-			sess.LastCommitHash = hash
-		}
-		if msg, err := s.git.LastCommitMessage(sess.WorktreePath); err == nil {
-			sess.LastCommitMessage = msg
-		}
-	}
+	// Enrich with live git data when the worktree exists
+	g, _ := s.readSessionGit(r.Context(), sess)
+	resp.WorktreeExists = g.WorktreeExists
+	resp.IsDirty = g.IsDirty
+	resp.CurrentBranch = g.CurrentBranch
+	resp.AheadCount = g.AheadCount
+	resp.BehindCount = g.BehindCount
+	applySessionGit(sess, g)
 
 	writeJSON(w, http.StatusOK, resp)
 }
@@ -735,6 +2274,10 @@ func (s *Server) syncSession(w http.ResponseWriter, r *http.Request) {
 		DryRun: req.DryRun,
 	})
 	if err != nil {
+		if errors.Is(err, sessions.ErrInlineSession) || errors.Is(err, sessions.ErrOperationInProgress) || errors.Is(err, sessions.ErrOperationCanceled) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
 			writeError(w, http.StatusNotFound, err.Error())
 			return
@@ -750,6 +2293,7 @@ func (s *Server) mergeSession(w http.ResponseWriter, r *http.Request) {
 
 	var req struct {
 		BaseBranch string `json:"base_branch"`
+		Strategy   string `json:"strategy"`
 		Rebase     bool   `json:"rebase"`
 		CreatePR   bool   `json:"create_pr"`
 		PRTitle    string `json:"pr_title"`
@@ -758,6 +2302,8 @@ func (s *Server) mergeSession(w http.ResponseWriter, r *http.Request) {
 		Force      bool   `json:"force"`
 		DryRun     bool   `json:"dry_run"`
 		Cleanup    *bool  `json:"cleanup,omitempty"`
+		// DeleteRemote also deletes the branch's upstream during cleanup
+		DeleteRemote bool `json:"delete_remote"`
 	}
 	if r.Body != nil && r.ContentLength > 0 {
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -766,6 +2312,12 @@ func (s *Server) mergeSession(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	strategy, err := sessions.ParseMergeStrategy(req.Strategy)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Default cleanup to true when not specified
 	cleanup := true
 	if req.Cleanup != nil {
@@ -773,17 +2325,24 @@ func (s *Server) mergeSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	result, err := s.sessions.MergeSession(r.Context(), id, sessions.MergeOptions{
-		BaseBranch: req.BaseBranch,
-		Rebase:     req.Rebase,
-		CreatePR:   req.CreatePR,
-		PRTitle:    req.PRTitle,
-		PRBody:     req.PRBody,
-		PRDraft:    req.PRDraft,
-		Force:      req.Force,
-		DryRun:     req.DryRun,
-		Cleanup:    cleanup,
+		BaseBranch:   req.BaseBranch,
+		Strategy:     strategy,
+		Rebase:       req.Rebase,
+		CreatePR:     req.CreatePR,
+		PRTitle:      req.PRTitle,
+		PRBody:       req.PRBody,
+		PRDraft:      req.PRDraft,
+		Force:        req.Force,
+		DryRun:       req.DryRun,
+		Cleanup:      cleanup,
+		DeleteRemote: req.DeleteRemote,
 	})
 	if err != nil {
+		if errors.Is(err, sessions.ErrCommitConventions) || errors.Is(err, sessions.ErrInlineSession) || errors.Is(err, sessions.ErrNotFastForward) ||
+			errors.Is(err, sessions.ErrOperationInProgress) || errors.Is(err, sessions.ErrOperationCanceled) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
 			writeError(w, http.StatusNotFound, err.Error())
 			return
@@ -794,17 +2353,36 @@ func (s *Server) mergeSession(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, result)
 }
 
+// deleteWorktree removes a session's worktree and abandons the session. The
+// body's (or query's) delete_branch and delete_remote also delete its local
+// branch and the remote branch it tracks; the response reports what was and
+// wasn't deleted.
 func (s *Server) deleteWorktree(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
 	var req struct {
-		Force bool `json:"force"`
+		Force        bool `json:"force"`
+		DeleteBranch bool `json:"delete_branch"`
+		DeleteRemote bool `json:"delete_remote"`
 	}
 	if r.Body != nil && r.ContentLength > 0 {
 		_ = json.NewDecoder(r.Body).Decode(&req)
 	}
+	q := r.URL.Query()
+	req.Force = req.Force || q.Get("force") == "true"
+	req.DeleteBranch = req.DeleteBranch || q.Get("delete_branch") == "true"
+	req.DeleteRemote = req.DeleteRemote || q.Get("delete_remote") == "true"
 
-	if err := s.sessions.DeleteWorktree(r.Context(), id, req.Force); err != nil {
+	result, err := s.sessions.DeleteWorktree(r.Context(), id, sessions.CleanupOptions{
+		Force:        req.Force,
+		DeleteBranch: req.DeleteBranch,
+		DeleteRemote: req.DeleteRemote,
+	})
+	if err != nil {
+		if errors.Is(err, sessions.ErrInlineSession) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
 			writeError(w, http.StatusNotFound, err.Error())
 			return
@@ -812,29 +2390,11 @@ func (s *Server) deleteWorktree(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	w.WriteHeader(http.StatusNoContent)
+	writeJSON(w, http.StatusOK, result)
 }
 
 // --- Close Check ---
 
-type closeCheckWarning struct {
-	Type    string `json:"type"`
-	Message string `json:"message"`
-}
-
-type closeCheckResponse struct {
-	SessionID      string              `json:"session_id"`
-	WorktreeExists bool                `json:"worktree_exists"`
-	IsDirty        bool                `json:"is_dirty"`
-	AheadCount     int                 `json:"ahead_count"`
-	BehindCount    int                 `json:"behind_count"`
-	ConflictState  string              `json:"conflict_state"`
-	Branch         string              `json:"branch"`
-	BaseBranch     string              `json:"base_branch"`
-	ReadyToClose   bool                `json:"ready_to_close"`
-	Warnings       []closeCheckWarning `json:"warnings"`
-}
-
 func (s *Server) closeCheck(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
@@ -844,62 +2404,292 @@ func (s *Server) closeCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp := closeCheckResponse{
-		SessionID:     sess.ID,
-		Branch:        sess.Branch,
-		BaseBranch:    "main",
-		ConflictState: string(sess.ConflictState),
+	project, _ := s.store.GetProject(r.Context(), sess.ProjectID)
+	writeJSON(w, http.StatusOK, agent.CheckClose(r.Context(), sess, project, s.git))
+}
+
+// LabelSessionRequest is the JSON body for PUT /api/v1/sessions/{id}/labels.
+type LabelSessionRequest struct {
+	Add    []string `json:"add"`
+	Remove []string `json:"remove"`
+}
+
+// labelSession adds and removes a session's labels.
+func (s *Server) labelSession(w http.ResponseWriter, r *http.Request) {
+	var req LabelSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	sess, err := s.store.GetAgentSession(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	sess.EditLabels(req.Add, req.Remove)
+	if err := s.store.UpdateAgentSession(r.Context(), sess); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, sess)
+}
+
+// sessionLabelStats summarizes sessions per label, optionally for one
+// project (?project_id=) or one label (?label=).
+func (s *Server) sessionLabelStats(w http.ResponseWriter, r *http.Request) {
+	sessions, err := s.store.ListAgentSessionsFiltered(r.Context(), store.SessionListFilter{
+		ProjectID: r.URL.Query().Get("project_id"),
+		Label:     r.URL.Query().Get("label"),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
 	}
+	writeJSON(w, http.StatusOK, agent.AggregateByLabel(sessions))
+}
 
-	if sess.WorktreePath != "" {
-		if _, err := os.Stat(sess.WorktreePath); err == nil {
-			resp.WorktreeExists = true
+// issueCode traces an issue to its branches, commits, pull requests, merges
+// and releases across all its sessions.
+func (s *Server) issueCode(w http.ResponseWriter, r *http.Request) {
+	tags, _ := s.git.(agent.TagFinder)
+	prs, _ := s.gh.(agent.BranchPRLister)
+	code, err := agent.BuildIssueCode(r.Context(), s.store, s.git, tags, prs, r.PathValue("id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, code)
+}
 
-			if dirty, err := s.git.IsDirty(sess.WorktreePath); err == nil {
-				resp.IsDirty = dirty
-			}
-			if ahead, behind, err := s.git.AheadBehind(sess.WorktreePath, "main"); err == nil {
-				resp.AheadCount = ahead
-				resp.BehindCount = behind
+// replaySession returns a session's reconstructed timeline. With
+// ?summarize=true it adds an LLM post-mortem, refused with 429 when the
+// session's project is over its monthly LLM budget.
+func (s *Server) replaySession(w http.ResponseWriter, r *http.Request) {
+	summarize := r.URL.Query().Get("summarize") == "true"
+	if summarize && s.llm == nil {
+		writeError(w, http.StatusServiceUnavailable, s.unavailable(capabilities.LLM))
+		return
+	}
+
+	replay, err := agent.BuildReplay(r.Context(), s.store, s.git, r.PathValue("id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if summarize {
+		sess, err := s.store.GetAgentSession(r.Context(), replay.SessionID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		// A summary was asked for, so there is nothing to fall back to.
+		if err := s.checkLLMBudget(r.Context(), sess.ProjectID); err != nil {
+			if errors.Is(err, llmbudget.ErrExceeded) {
+				writeError(w, http.StatusTooManyRequests, err.Error())
+				return
 			}
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
 		}
+		summary, usage, err := s.llm.SummarizeSession(r.Context(), replay.ProjectName, replay.Narrative())
+		if usage != (llm.Usage{}) {
+			s.recordLLMUsage(r.Context(), &usage, sess.ProjectID, sess.IssueID, models.LLMUsageSessionSummary)
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("LLM summary failed: %v", err))
+			return
+		}
+		replay.Summary = summary
 	}
+	writeJSON(w, http.StatusOK, replay)
+}
 
-	// Build warnings
-	if resp.IsDirty {
-		resp.Warnings = append(resp.Warnings, closeCheckWarning{
-			Type:    "dirty",
-			Message: "Worktree has uncommitted changes",
-		})
+// --- Session Files ---
+
+// maxFileViewBytes caps how much of a worktree file is returned by the file endpoint.
+const maxFileViewBytes = 1 << 20
+
+type fileEntry struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	IsDir   bool      `json:"is_dir"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+type fileListResponse struct {
+	Path    string      `json:"path"`
+	Entries []fileEntry `json:"entries"`
+}
+
+type fileContentResponse struct {
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Binary    bool   `json:"binary"`
+	Truncated bool   `json:"truncated"`
+	Encoding  string `json:"encoding"` // "utf-8" or "base64"
+	Content   string `json:"content"`
+}
+
+// sessionWorktreePath resolves a path relative to the session's worktree,
+// rejecting anything that escapes it (including via symlinks) or lies under
+// .git, which listings hide. It writes the error response and returns an
+// empty full path on failure.
+func (s *Server) sessionWorktreePath(w http.ResponseWriter, r *http.Request) (full, rel string) {
+	sess, err := s.store.GetAgentSession(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "session not found")
+		return "", ""
 	}
-	if resp.AheadCount > 0 {
-		resp.Warnings = append(resp.Warnings, closeCheckWarning{
-			Type:    "unmerged",
-			Message: fmt.Sprintf("%d commit(s) not merged to main", resp.AheadCount),
-		})
+	if sess.WorktreePath == "" {
+		writeError(w, http.StatusBadRequest, "session has no worktree path")
+		return "", ""
 	}
-	if resp.BehindCount > 0 {
-		resp.Warnings = append(resp.Warnings, closeCheckWarning{
-			Type:    "behind",
-			Message: fmt.Sprintf("%d commit(s) behind main", resp.BehindCount),
-		})
+	root, err := filepath.EvalSymlinks(sess.WorktreePath)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "worktree no longer exists on disk")
+		return "", ""
 	}
-	if sess.ConflictState != models.ConflictStateNone {
-		resp.Warnings = append(resp.Warnings, closeCheckWarning{
-			Type:    "conflict",
-			Message: fmt.Sprintf("Session has %s", sess.ConflictState),
-		})
+
+	rel = filepath.Clean("/" + r.URL.Query().Get("path"))[1:]
+	full, err = filepath.EvalSymlinks(filepath.Join(root, rel))
+	if err != nil || inGitDir(rel) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("path not found: %s", rel))
+		return "", ""
+	}
+	if full != root && !strings.HasPrefix(full, root+string(filepath.Separator)) {
+		writeError(w, http.StatusBadRequest, "path escapes worktree")
+		return "", ""
+	}
+	if inGitDir(strings.TrimPrefix(full, root)) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("path not found: %s", rel))
+		return "", ""
+	}
+	return full, filepath.ToSlash(rel)
+}
+
+// inGitDir reports whether a worktree-relative path is .git or under it.
+func inGitDir(rel string) bool {
+	return slices.Contains(strings.Split(filepath.ToSlash(rel), "/"), ".git")
+}
+
+func (s *Server) listSessionFiles(w http.ResponseWriter, r *http.Request) {
+	dir, rel := s.sessionWorktreePath(w, r)
+	if dir == "" {
+		return
+	}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("not a directory: %s", rel))
+		return
+	}
+
+	entries := []fileEntry{}
+	for _, de := range dirEntries {
+		if de.Name() == ".git" {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		entry := fileEntry{
+			Name:    de.Name(),
+			Path:    strings.TrimPrefix(rel+"/"+de.Name(), "/"),
+			IsDir:   de.IsDir(),
+			ModTime: info.ModTime().UTC(),
+		}
+		if !entry.IsDir {
+			entry.Size = info.Size()
+		}
+		entries = append(entries, entry)
+	}
+
+	// Directories first, then by name
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].IsDir != entries[j].IsDir {
+			return entries[i].IsDir
+		}
+		return entries[i].Name < entries[j].Name
+	})
+
+	writeJSON(w, http.StatusOK, fileListResponse{Path: rel, Entries: entries})
+}
+
+func (s *Server) getSessionFile(w http.ResponseWriter, r *http.Request) {
+	path, rel := s.sessionWorktreePath(w, r)
+	if path == "" {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("path not found: %s", rel))
+		return
+	}
+	if info.IsDir() {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("path is a directory: %s", rel))
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
 	}
+	defer func() { _ = f.Close() }()
 
-	resp.ReadyToClose = !resp.IsDirty && resp.AheadCount == 0 && sess.ConflictState == models.ConflictStateNone
+	buf := make([]byte, maxFileViewBytes)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	data := buf[:n]
 
-	if resp.Warnings == nil {
-		resp.Warnings = []closeCheckWarning{}
+	resp := fileContentResponse{
+		Path:      rel,
+		Size:      info.Size(),
+		Truncated: info.Size() > int64(n),
+		Encoding:  "utf-8",
+	}
+	if text := trimPartialRune(data, resp.Truncated); bytes.IndexByte(text, 0) < 0 && utf8.Valid(text) {
+		resp.Content = string(text)
+	} else {
+		resp.Binary = true
+		resp.Encoding = "base64"
+		resp.Content = base64.StdEncoding.EncodeToString(data)
 	}
 
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// trimPartialRune drops a trailing incomplete UTF-8 sequence left by truncation
+// so a cut-off text file is not misdetected as binary.
+func trimPartialRune(data []byte, truncated bool) []byte {
+	if !truncated {
+		return data
+	}
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			break
+		}
+	}
+	return data
+}
+
 // --- Reactivate Session ---
 
 func (s *Server) reactivateSession(w http.ResponseWriter, r *http.Request) {
@@ -923,7 +2713,7 @@ func (s *Server) reactivateSession(w http.ResponseWriter, r *http.Request) {
 
 	session, err := agent.ReactivateSession(r.Context(), s.store, id)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeError(w, transitionStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
@@ -933,6 +2723,38 @@ func (s *Server) reactivateSession(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// focusSession brings the terminal window hosting the session to the front.
+func (s *Server) focusSession(w http.ResponseWriter, r *http.Request) {
+	sess, err := s.store.GetAgentSession(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	if sess.TerminalID == "" {
+		writeError(w, http.StatusBadRequest, "session has no recorded terminal")
+		return
+	}
+
+	t, err := s.terminals(sess.TerminalBackend)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := t.Focus(sess.TerminalID); err != nil {
+		if errors.Is(err, terminal.ErrNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"session_id": sess.ID,
+		"terminal":   terminal.Result{Backend: sess.TerminalBackend, ID: sess.TerminalID},
+	})
+}
+
 func (s *Server) discoverWorktrees(w http.ResponseWriter, r *http.Request) {
 	// Accept project_id from query param or JSON body
 	projectID := r.URL.Query().Get("project_id")
@@ -945,8 +2767,6 @@ func (s *Server) discoverWorktrees(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	var allDiscovered []*models.AgentSession
-
 	if projectID != "" {
 		// Discover for a specific project
 		discovered, err := s.sessions.DiscoverWorktrees(r.Context(), projectID)
@@ -958,53 +2778,168 @@ func (s *Server) discoverWorktrees(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
-		allDiscovered = discovered
-	} else {
-		// Discover across all projects
-		projects, err := s.store.ListProjects(r.Context(), "")
-		if err != nil {
-			writeError(w, http.StatusInternalServerError, err.Error())
+		if discovered == nil {
+			discovered = []*models.AgentSession{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"discovered": discovered,
+			"count":      len(discovered),
+		})
+		return
+	}
+
+	// Discover across all projects
+	projects, err := s.store.ListProjects(r.Context(), "")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	result := s.sessions.DiscoverAll(r.Context(), projects, s.settings().discover)
+	if result.Discovered == nil {
+		result.Discovered = []*models.AgentSession{}
+	}
+	if result.Projects == nil {
+		result.Projects = []sessions.DiscoverProjectResult{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"discovered": result.Discovered,
+		"count":      len(result.Discovered),
+		"projects":   result.Projects,
+		"failed":     result.Failed,
+		"skipped":    result.Skipped,
+	})
+}
+
+// --- Cleanup ---
+
+// cleanupResponse is the JSON response for DELETE /api/v1/sessions/cleanup.
+type cleanupResponse struct {
+	Deleted  int64             `json:"deleted"`
+	DryRun   bool              `json:"dry_run"`
+	Criteria cleanupCriteria   `json:"criteria"`
+	Sessions []sessionResponse `json:"sessions"`
+}
+
+// cleanupCriteria echoes the effective thresholds used for a cleanup run.
+type cleanupCriteria struct {
+	ProjectID          string   `json:"project_id,omitempty"`
+	Statuses           []string `json:"statuses"`
+	MaxCommits         int      `json:"max_commits"`
+	MaxDuration        string   `json:"max_duration"`
+	MaxDurationSeconds int64    `json:"max_duration_seconds"`
+	MinAge             string   `json:"min_age"`
+	MinAgeSeconds      int64    `json:"min_age_seconds"`
+}
+
+// cleanupSessions deletes stale sessions. Thresholds default to the server's
+// configured criteria and may be overridden per request via query parameters:
+// project_id, statuses (comma-separated), max_commits, max_duration, min_age
+// (durations such as "90s", "24h" or "7d"), and dry_run.
+func (s *Server) cleanupSessions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	criteria := s.settings().staleCriteria
+	criteria.ProjectID = q.Get("project_id")
+
+	if v := q.Get("statuses"); v != "" {
+		criteria.Statuses = nil
+		for _, st := range strings.Split(v, ",") {
+			st = strings.TrimSpace(st)
+			switch models.SessionStatus(st) {
+			case models.SessionStatusIdle, models.SessionStatusBlocked, models.SessionStatusCompleted, models.SessionStatusAbandoned:
+				criteria.Statuses = append(criteria.Statuses, models.SessionStatus(st))
+			case "":
+			default:
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid status for cleanup: %s", st))
+				return
+			}
+		}
+	}
+	if v := q.Get("max_commits"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "max_commits must be a non-negative integer")
 			return
 		}
-		for _, p := range projects {
-			discovered, err := s.sessions.DiscoverWorktrees(r.Context(), p.ID)
-			if err != nil {
-				// Skip projects that fail (e.g., missing repo)
-				continue
+		criteria.MaxCommits = n
+	}
+	if v := q.Get("max_duration"); v != "" {
+		d, err := sla.ParseDuration(v)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, "max_duration must be a positive duration like 60s or 5m")
+			return
+		}
+		criteria.MaxDuration = d
+	}
+	if v := q.Get("min_age"); v != "" {
+		d, err := sla.ParseDuration(v)
+		if err != nil || d < 0 {
+			writeError(w, http.StatusBadRequest, "min_age must be a duration like 1h or 7d")
+			return
+		}
+		criteria.MinAge = d
+	}
+	dryRun := q.Get("dry_run") == "true"
+
+	stale, err := s.store.ListStaleSessions(r.Context(), criteria)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := cleanupResponse{
+		DryRun:   dryRun,
+		Criteria: toCleanupCriteria(criteria),
+		Sessions: make([]sessionResponse, 0, len(stale)),
+	}
+
+	nameCache := make(map[string]string)
+	ids := make([]string, 0, len(stale))
+	for _, sess := range stale {
+		name, ok := nameCache[sess.ProjectID]
+		if !ok {
+			if p, err := s.store.GetProject(r.Context(), sess.ProjectID); err == nil {
+				name = p.Name
 			}
-			allDiscovered = append(allDiscovered, discovered...)
+			nameCache[sess.ProjectID] = name
 		}
+		resp.Sessions = append(resp.Sessions, sessionResponse{AgentSession: sess, ProjectName: name})
+		ids = append(ids, sess.ID)
 	}
 
-	if allDiscovered == nil {
-		allDiscovered = []*models.AgentSession{}
+	if dryRun {
+		resp.Deleted = int64(len(ids))
+		writeJSON(w, http.StatusOK, resp)
+		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{
-		"discovered": allDiscovered,
-		"count":      len(allDiscovered),
-	})
-}
-
-// --- Cleanup ---
 
-func (s *Server) cleanupSessions(w http.ResponseWriter, r *http.Request) {
-	count, err := s.store.DeleteAllStaleSessions(r.Context())
+	// The criteria are applied again, so sessions that changed since they
+	// were listed are kept.
+	n, err := s.store.DeleteStaleSessionsByID(r.Context(), criteria, ids)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]int64{"deleted": count})
+	resp.Deleted = n
+	writeJSON(w, http.StatusOK, resp)
 }
 
-// --- Tags ---
-
-func (s *Server) listTags(w http.ResponseWriter, r *http.Request) {
-	tags, err := s.store.ListTags(r.Context())
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
-		return
+func toCleanupCriteria(c store.StaleSessionCriteria) cleanupCriteria {
+	statuses := c.Statuses
+	if len(statuses) == 0 {
+		statuses = []models.SessionStatus{models.SessionStatusAbandoned}
+	}
+	out := cleanupCriteria{
+		ProjectID:          c.ProjectID,
+		MaxCommits:         c.MaxCommits,
+		MaxDuration:        c.MaxDuration.String(),
+		MaxDurationSeconds: int64(c.MaxDuration.Seconds()),
+		MinAge:             c.MinAge.String(),
+		MinAgeSeconds:      int64(c.MinAge.Seconds()),
 	}
-	writeJSON(w, http.StatusOK, tags)
+	for _, st := range statuses {
+		out.Statuses = append(out.Statuses, string(st))
+	}
+	return out
 }
 
 // --- Health ---
@@ -1018,38 +2953,44 @@ func (s *Server) projectHealth(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotFound, err.Error())
 		return
 	}
+	writeJSON(w, http.StatusOK, s.scoreProject(ctx, p))
+}
 
+// scoreProject computes a project's live health score from its repository,
+// latest release and issues.
+func (s *Server) scoreProject(ctx context.Context, p *models.Project) *health.HealthScore {
 	meta := &health.ProjectMetadata{}
-	if dirty, err := s.git.IsDirty(p.Path); err == nil {
+	if dirty, err := s.git.IsDirty(ctx, p.Path); err == nil {
 		meta.IsDirty = dirty
 	}
-	if date, err := s.git.LastCommitDate(p.Path); err == nil {
+	if date, err := s.git.LastCommitDate(ctx, p.Path); err == nil {
 		meta.LastCommitDate = date
 	}
-	if branches, err := s.git.BranchList(p.Path); err == nil {
+	if branches, err := s.git.BranchList(ctx, p.Path); err == nil {
 		meta.BranchCount = len(branches)
 	}
 
 	// Version info for release freshness scoring
-	if p.RepoURL != "" {
-		if owner, repo, err := git.ExtractOwnerRepo(p.RepoURL); err == nil {
-			if rel, err := s.gh.LatestRelease(owner, repo); err == nil {
-				meta.LatestRelease = rel.TagName
-				if t, parseErr := time.Parse(time.RFC3339, rel.PublishedAt); parseErr == nil {
-					meta.ReleaseDate = t
-				}
-			}
+	if rel := releases.Cached(ctx, s.store, s.gh, p, s.releaseSchedule); rel != nil {
+		meta.LatestRelease = rel.TagName
+		if rel.PublishedAt != nil {
+			meta.ReleaseDate = *rel.PublishedAt
 		}
 	}
 	if meta.LatestRelease == "" {
-		if tag, err := s.git.LatestTag(p.Path); err == nil {
+		if tag, err := s.git.LatestTag(ctx, p.Path); err == nil {
 			meta.LatestRelease = tag
 		}
 	}
 
 	issues, _ := s.store.ListIssues(ctx, store.IssueListFilter{ProjectID: p.ID})
-	h := s.scorer.Score(p, meta, issues)
-	writeJSON(w, http.StatusOK, h)
+	return s.scorer.Score(p, meta, issues)
+}
+
+// gitMetrics reports latency and failure counts of the git commands run by
+// the shared executor since the server started.
+func (s *Server) gitMetrics(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, git.DefaultExecutor().Stats())
 }
 
 // --- Agent Launch ---
@@ -1058,14 +2999,50 @@ func (s *Server) projectHealth(w http.ResponseWriter, r *http.Request) {
 type LaunchAgentRequest struct {
 	IssueIDs  []string `json:"issue_ids"`
 	ProjectID string   `json:"project_id"`
+	// SnapshotHumanEdits commits uncommitted edits made in an idle session's
+	// worktree before resuming it.
+	SnapshotHumanEdits bool `json:"snapshot_human_edits"`
+	// Spawn runs the command in a terminal on the server instead of only
+	// returning it. Requires a configured terminal backend.
+	Spawn bool `json:"spawn"`
+	// Sparse creates a new worktree with a sparse-checkout limited to the
+	// project's sparse paths, SparsePaths and paths the issues mention.
+	// Implied by SparsePaths or a project with sparse paths configured.
+	Sparse      bool     `json:"sparse,omitempty"`
+	SparsePaths []string `json:"sparse_paths,omitempty"`
+	// Labels are added to the new or resumed session.
+	Labels []string `json:"labels,omitempty"`
 }
 
 // LaunchAgentResponse is the JSON response for a successful agent launch.
 type LaunchAgentResponse struct {
-	SessionID    string `json:"session_id"`
-	Branch       string `json:"branch"`
-	WorktreePath string `json:"worktree_path"`
-	Command      string `json:"command"`
+	SessionID    string   `json:"session_id"`
+	Branch       string   `json:"branch"`
+	WorktreePath string   `json:"worktree_path"`
+	Command      string   `json:"command"`
+	Conventions  string   `json:"conventions,omitempty"`
+	WIPWarnings  []string `json:"wip_warnings,omitempty"`
+	// CoEdit is set when a resumed session's worktree changed while it was idle.
+	CoEdit *agent.CoEditCheck `json:"co_edit,omitempty"`
+	// SparsePaths lists the directories a sparse worktree checked out.
+	SparsePaths []string `json:"sparse_paths,omitempty"`
+	// Terminal identifies the spawned terminal when spawn was requested.
+	Terminal   *terminal.Result `json:"terminal,omitempty"`
+	SpawnError string           `json:"spawn_error,omitempty"`
+}
+
+// WIPBlockedResponse is returned with 409 when a launch would exceed a project's
+// WIP limits and the project has hard blocking enabled.
+type WIPBlockedResponse struct {
+	Error string          `json:"error"`
+	WIP   *agent.WIPCheck `json:"wip"`
+}
+
+// IssueLockedResponse is the 409 body of a launch on an issue another active
+// session holds.
+type IssueLockedResponse struct {
+	Error string          `json:"error"`
+	Lock  agent.IssueLock `json:"lock"`
 }
 
 func (s *Server) launchAgent(w http.ResponseWriter, r *http.Request) {
@@ -1085,6 +3062,10 @@ func (s *Server) launchAgent(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "issue_ids is required")
 		return
 	}
+	if req.Spawn && s.spawner == nil {
+		writeError(w, http.StatusBadRequest, s.unavailable(capabilities.Terminal))
+		return
+	}
 
 	// Validate project exists
 	project, err := s.store.GetProject(ctx, req.ProjectID)
@@ -1116,65 +3097,117 @@ func (s *Server) launchAgent(w http.ResponseWriter, r *http.Request) {
 		issues = append(issues, issue)
 	}
 
-	// Generate branch name from first issue title
-	branch := issueToBranch(issues[0].Title)
+	if r.URL.Query().Get("plan") == "true" {
+		plan, fail := s.planLaunch(ctx, project, issues, req)
+		if fail != nil {
+			writeJSON(w, fail.status, fail.body)
+			return
+		}
+		writeJSON(w, http.StatusOK, plan)
+		return
+	}
+
+	s.launchMu.Lock()
+	defer s.launchMu.Unlock()
+	resp, fail := s.launch(ctx, project, issues, req)
+	if fail != nil {
+		writeJSON(w, fail.status, fail.body)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
 
-	// Worktree path: <project.Path>.worktrees/<last-branch-segment> to match wt convention
-	branchParts := strings.Split(branch, "/")
-	worktreeDirname := branchParts[len(branchParts)-1]
-	worktreePath := filepath.Join(project.Path+".worktrees", worktreeDirname)
+// launchFailure is a refused or failed launch, with the HTTP status and body
+// to report it with.
+type launchFailure struct {
+	status int
+	body   any
+}
+
+func launchError(status int, msg string) *launchFailure {
+	return &launchFailure{status: status, body: map[string]string{"error": msg}}
+}
+
+// launch starts an agent session on issues of project, or resumes the idle
+// session on their branch, and marks new work in progress. The caller has
+// validated the issues and holds launchMu.
+func (s *Server) launch(ctx context.Context, project *models.Project, issues []*models.Issue, req LaunchAgentRequest) (*LaunchAgentResponse, *launchFailure) {
+	branch, worktreePath := launchTarget(project, issues)
+	existingSessions, wip, fail := s.checkLaunch(ctx, project, issues, branch)
+	if fail != nil {
+		return nil, fail
+	}
+	issueIDs := make([]string, len(issues))
+	for i, issue := range issues {
+		issueIDs[i] = issue.ID
+	}
+	if err := agent.ReleaseExpiredIssueLocks(ctx, s.store, existingSessions, issueIDs, s.issueLockExpiry, time.Now()); err != nil {
+		return nil, launchError(http.StatusInternalServerError, err.Error())
+	}
 
 	// Check for existing idle session on this branch
-	existingSessions, _ := s.store.ListAgentSessions(ctx, project.ID, 0)
 	for _, sess := range existingSessions {
 		if sess.Branch == branch && sess.Status == models.SessionStatusIdle {
-			sess.Status = models.SessionStatusActive
-			now := time.Now().UTC()
-			sess.LastActiveAt = &now
-			if err := s.store.UpdateAgentSession(ctx, sess); err == nil {
-				var issueRefs []string
-				for _, issue := range issues {
-					id := issue.ID
-					if len(id) > 12 {
-						id = id[:12]
-					}
-					issueRefs = append(issueRefs, id)
-				}
-				prompt := fmt.Sprintf("Use pm MCP tools to look up issue(s) %s and implement them. Update issue status when complete.", strings.Join(issueRefs, ", "))
-				command := fmt.Sprintf(`cd %s && claude "%s"`, sess.WorktreePath, prompt)
-				writeJSON(w, http.StatusOK, LaunchAgentResponse{
+			coEdit, err := agent.PrepareResume(ctx, sess, s.git, req.SnapshotHumanEdits)
+			if err != nil {
+				return nil, launchError(http.StatusInternalServerError, err.Error())
+			}
+			sess.EditLabels(req.Labels, nil)
+			if err := agent.Transition(ctx, s.store, sess, models.SessionStatusActive, agent.CauseLaunch); err == nil {
+				claudeCmd := launchClaudeCommand(project, issues)
+				resp := LaunchAgentResponse{
 					SessionID:    sess.ID,
 					Branch:       branch,
 					WorktreePath: sess.WorktreePath,
-					Command:      command,
-				})
-				return
+					Command:      fmt.Sprintf("cd %s && %s", sess.WorktreePath, claudeCmd),
+					Conventions:  s.projectConventions(ctx, project),
+					WIPWarnings:  wip.Warnings,
+					CoEdit:       coEdit,
+				}
+				if req.Spawn {
+					resp.Terminal, resp.SpawnError = s.spawnTerminal(ctx, project, sess, claudeCmd)
+				}
+				return &resp, nil
 			}
 		}
 	}
 
 	// Auto-purge stale abandoned sessions for this branch
-	if _, err := s.store.DeleteStaleSessions(ctx, project.ID, branch); err != nil {
+	stale := s.settings().staleCriteria
+	stale.ProjectID = project.ID
+	stale.Branch = branch
+	if _, err := s.store.DeleteStaleSessions(ctx, stale); err != nil {
 		slog.Warn("failed to purge stale sessions", "error", err)
 	}
 
-	// Create worktree
-	if err := s.wt.Create(project.Path, branch); err != nil {
-		writeError(w, http.StatusInternalServerError, fmt.Sprintf("create worktree: %v", err))
-		return
+	// Create worktree; wt opens its own iTerm window unless we spawn one
+	sparse, err := s.wt.CreateWith(project.Path, branch, wt.CreateOptions{
+		Headless:    req.Spawn,
+		SparsePaths: agent.LaunchSparsePaths(project, issues, req.Sparse, req.SparsePaths),
+	})
+	if err != nil {
+		return nil, launchError(http.StatusInternalServerError, fmt.Sprintf("create worktree: %v", err))
 	}
 
-	// Record agent session (use first issue ID for the session record)
+	// Record agent session on the first issue, locking the others with it
 	session := &models.AgentSession{
-		ProjectID:    project.ID,
-		IssueID:      req.IssueIDs[0],
-		Branch:       branch,
-		WorktreePath: worktreePath,
-		Status:       models.SessionStatusActive,
+		ProjectID:     project.ID,
+		IssueID:       issueIDs[0],
+		ExtraIssueIDs: strings.Join(issueIDs[1:], ","),
+		Branch:        branch,
+		WorktreePath:  worktreePath,
+		Status:        models.SessionStatusActive,
+		SparsePaths:   strings.Join(sparse, ","),
+	}
+	session.EditLabels(req.Labels, nil)
+	if !req.Spawn {
+		agent.RecordWTTerminal(session, s.wt)
 	}
 	if err := s.store.CreateAgentSession(ctx, session); err != nil {
-		writeError(w, http.StatusInternalServerError, fmt.Sprintf("create session: %v", err))
-		return
+		if errors.Is(err, store.ErrBranchReserved) || errors.Is(err, store.ErrIssueLocked) {
+			return nil, launchError(http.StatusConflict, fmt.Sprintf("create session: %v", err))
+		}
+		return nil, launchError(http.StatusInternalServerError, fmt.Sprintf("create session: %v", err))
 	}
 
 	// Mark all issues as in_progress
@@ -1183,31 +3216,308 @@ func (s *Server) launchAgent(w http.ResponseWriter, r *http.Request) {
 		_ = s.store.UpdateIssue(ctx, issue)
 	}
 
-	// Build command prompt with issue IDs for MCP lookup
+	claudeCmd := launchClaudeCommand(project, issues)
+
+	resp := LaunchAgentResponse{
+		SessionID:    session.ID,
+		Branch:       branch,
+		WorktreePath: worktreePath,
+		Command:      fmt.Sprintf("cd %s && %s", worktreePath, claudeCmd),
+		Conventions:  s.projectConventions(ctx, project),
+		WIPWarnings:  wip.Warnings,
+		SparsePaths:  sparse,
+	}
+	if req.Spawn {
+		resp.Terminal, resp.SpawnError = s.spawnTerminal(ctx, project, session, claudeCmd)
+	}
+	return &resp, nil
+}
+
+// launchTarget returns the branch a launch on issues works on, named after
+// the first issue, and the path of its new worktree:
+// <project.Path>.worktrees/<last-branch-segment>, to match the wt convention.
+func launchTarget(project *models.Project, issues []*models.Issue) (branch, worktreePath string) {
+	branch = issueToBranch(issues[0].Title)
+	branchParts := strings.Split(branch, "/")
+	worktreeDirname := branchParts[len(branchParts)-1]
+	return branch, filepath.Join(project.Path+".worktrees", worktreeDirname)
+}
+
+// checkLaunch refuses a launch on branch that is blocked, inline or would
+// exceed the project's WIP limits, or on issues another session holds,
+// returning the project's sessions and the WIP check otherwise.
+func (s *Server) checkLaunch(ctx context.Context, project *models.Project, issues []*models.Issue, branch string) ([]*models.AgentSession, *agent.WIPCheck, *launchFailure) {
+	// Check WIP limits against the work this launch would start
+	existingSessions, _ := s.store.ListAgentSessions(ctx, project.ID, 0)
+	if err := agent.CheckBranchNotBlocked(existingSessions, branch); err != nil {
+		return nil, nil, launchError(http.StatusConflict, err.Error())
+	}
+	if err := agent.CheckBranchNotInline(existingSessions, branch); err != nil {
+		return nil, nil, launchError(http.StatusConflict, err.Error())
+	}
+	if err := agent.CheckBranchNotActive(existingSessions, branch); err != nil {
+		return nil, nil, launchError(http.StatusConflict, err.Error())
+	}
+	for _, issue := range issues {
+		var locked *agent.IssueLockedError
+		if err := agent.CheckIssueNotHeld(existingSessions, issue.ID, s.issueLockExpiry, time.Now()); errors.As(err, &locked) {
+			return nil, nil, &launchFailure{status: http.StatusConflict, body: IssueLockedResponse{Error: err.Error(), Lock: locked.Lock}}
+		}
+	}
+	addSessions := 1
+	if idleSession(existingSessions, branch) != nil {
+		addSessions = 0
+	}
+	addIssues := 0
+	for _, issue := range issues {
+		if issue.Status != models.IssueStatusInProgress {
+			addIssues++
+		}
+	}
+	wip, err := agent.CheckWIP(ctx, s.store, project, addIssues, addSessions)
+	if err != nil {
+		return nil, nil, launchError(http.StatusInternalServerError, err.Error())
+	}
+	if wip.Blocked {
+		return nil, nil, &launchFailure{status: http.StatusConflict, body: WIPBlockedResponse{
+			Error: "launch would exceed project WIP limits",
+			WIP:   wip,
+		}}
+	}
+	return existingSessions, wip, nil
+}
+
+// idleSession returns the idle session on branch, if any.
+func idleSession(sessions []*models.AgentSession, branch string) *models.AgentSession {
+	for _, sess := range sessions {
+		if sess.Branch == branch && sess.Status == models.SessionStatusIdle {
+			return sess
+		}
+	}
+	return nil
+}
+
+// launchClaudeCommand builds the claude command for a launch, prompting the
+// agent with the issue codes to look up through MCP.
+func launchClaudeCommand(project *models.Project, issues []*models.Issue) string {
 	var issueRefs []string
 	for _, issue := range issues {
-		id := issue.ID
-		if len(id) > 12 {
-			id = id[:12]
+		ref := issue.Code
+		if ref == "" {
+			ref = issue.ID[:min(len(issue.ID), 12)]
 		}
-		issueRefs = append(issueRefs, id)
+		issueRefs = append(issueRefs, ref)
 	}
-	prompt := fmt.Sprintf("Use pm MCP tools to look up issue(s) %s and implement them. Update issue status when complete.", strings.Join(issueRefs, ", "))
-	command := fmt.Sprintf(`cd %s && claude "%s"`, worktreePath, prompt)
+	prompt := agent.WithLanguageGuidance(fmt.Sprintf("Use pm MCP tools to look up issue(s) %s and implement them. Update issue status when complete.", strings.Join(issueRefs, ", ")), project.MainLanguages())
+	return fmt.Sprintf(`claude "%s"`, prompt)
+}
 
-	writeJSON(w, http.StatusOK, LaunchAgentResponse{
-		SessionID:    session.ID,
-		Branch:       branch,
-		WorktreePath: worktreePath,
-		Command:      command,
-	})
+// LaunchPlanResponse is the JSON response for POST
+// /api/v1/agent/launch?plan=true: what the launch would do, for a
+// confirmation dialog.
+type LaunchPlanResponse struct {
+	Branch       string `json:"branch"`
+	WorktreePath string `json:"worktree_path"`
+	// Resume is set when the idle session ResumeSessionID would be resumed
+	// instead of creating a worktree.
+	Resume          bool   `json:"resume"`
+	ResumeSessionID string `json:"resume_session_id,omitempty"`
+	// PurgeSessionIDs lists the stale abandoned sessions on the branch that
+	// would be deleted before creating the worktree.
+	PurgeSessionIDs []string `json:"purge_session_ids"`
+	// StartIssueIDs lists the issues that would move to in_progress.
+	StartIssueIDs []string `json:"start_issue_ids"`
+	Command       string   `json:"command"`
+	SparsePaths   []string `json:"sparse_paths,omitempty"`
+	WIPWarnings   []string `json:"wip_warnings,omitempty"`
+}
+
+// planLaunch reports what launch would do with the same request, refusing
+// it the same way, without touching sessions, issues or worktrees.
+func (s *Server) planLaunch(ctx context.Context, project *models.Project, issues []*models.Issue, req LaunchAgentRequest) (*LaunchPlanResponse, *launchFailure) {
+	branch, worktreePath := launchTarget(project, issues)
+	existingSessions, wip, fail := s.checkLaunch(ctx, project, issues, branch)
+	if fail != nil {
+		return nil, fail
+	}
+
+	plan := &LaunchPlanResponse{
+		Branch:          branch,
+		WorktreePath:    worktreePath,
+		PurgeSessionIDs: []string{},
+		StartIssueIDs:   []string{},
+		WIPWarnings:     wip.Warnings,
+	}
+	if sess := idleSession(existingSessions, branch); sess != nil {
+		plan.Resume = true
+		plan.ResumeSessionID = sess.ID
+		plan.WorktreePath = sess.WorktreePath
+	} else {
+		criteria := s.settings().staleCriteria
+		criteria.ProjectID = project.ID
+		criteria.Branch = branch
+		stale, err := s.store.ListStaleSessions(ctx, criteria)
+		if err != nil {
+			return nil, launchError(http.StatusInternalServerError, err.Error())
+		}
+		for _, sess := range stale {
+			plan.PurgeSessionIDs = append(plan.PurgeSessionIDs, sess.ID)
+		}
+		for _, issue := range issues {
+			if issue.Status != models.IssueStatusInProgress {
+				plan.StartIssueIDs = append(plan.StartIssueIDs, issue.ID)
+			}
+		}
+		plan.SparsePaths = agent.LaunchSparsePaths(project, issues, req.Sparse, req.SparsePaths)
+	}
+	plan.Command = fmt.Sprintf("cd %s && %s", plan.WorktreePath, launchClaudeCommand(project, issues))
+	return plan, nil
+}
+
+// NextAgentRequest is the JSON body for POST /api/v1/agent/next. All fields
+// are optional.
+type NextAgentRequest struct {
+	// ProjectID limits the pull to one project; empty pulls from all of them.
+	ProjectID string `json:"project_id"`
+	// Assignee names the agent pulling work. It may take issues assigned to it
+	// or to nobody, and the issue it takes is assigned to it. Without one only
+	// unassigned issues are pulled.
+	Assignee string `json:"assignee"`
+	Spawn    bool   `json:"spawn"`
+}
+
+// NextAgentResponse is the JSON response for a pulled issue: the launch plus
+// the issue and why it ranked first.
+type NextAgentResponse struct {
+	LaunchAgentResponse
+	ProjectID   string   `json:"project_id"`
+	ProjectName string   `json:"project_name"`
+	IssueID     string   `json:"issue_id"`
+	IssueTitle  string   `json:"issue_title"`
+	Score       int      `json:"score"`
+	Reasons     []string `json:"reasons"`
+}
+
+// agentNext pulls the top-ranked launchable issue from the worklist, marks it
+// in progress and launches a session on it, so a loop calling it keeps an
+// agent busy. An issue that cannot launch on its branch (409) is passed over
+// for the next one. It responds 204 when there is nothing to pull.
+func (s *Server) agentNext(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req NextAgentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.Spawn && s.spawner == nil {
+		writeError(w, http.StatusBadRequest, s.unavailable(capabilities.Terminal))
+		return
+	}
+	if sc, ok := auth.FromContext(ctx); ok && req.ProjectID == "" {
+		req.ProjectID = sc.ProjectID
+	}
+	if req.ProjectID != "" {
+		if _, err := s.store.GetProject(ctx, req.ProjectID); err != nil {
+			writeError(w, http.StatusNotFound, "project not found")
+			return
+		}
+	}
+
+	s.launchMu.Lock()
+	defer s.launchMu.Unlock()
+
+	wl, err := agent.BuildWorklist(ctx, s.store, req.ProjectID, time.Now())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var conflict *launchFailure
+	for _, task := range wl.Tasks {
+		if task.Issue.Assignee != "" && task.Issue.Assignee != req.Assignee {
+			continue
+		}
+		issue := task.Issue
+		if req.Assignee != "" {
+			issue.Assignee = req.Assignee
+		}
+		resp, fail := s.launch(ctx, task.Project, []*models.Issue{issue}, LaunchAgentRequest{
+			ProjectID: task.Project.ID,
+			IssueIDs:  []string{issue.ID},
+			Spawn:     req.Spawn,
+		})
+		if fail != nil && fail.status == http.StatusConflict {
+			if conflict == nil {
+				conflict = fail
+			}
+			continue
+		}
+		if fail != nil {
+			writeJSON(w, fail.status, fail.body)
+			return
+		}
+		// Resuming an idle session leaves the issue as it was.
+		if issue.Status != models.IssueStatusInProgress {
+			issue.Status = models.IssueStatusInProgress
+			if err := s.store.UpdateIssue(ctx, issue); err != nil {
+				slog.Warn("failed to mark pulled issue in progress", "issue", issue.ID, "error", err)
+			}
+		}
+		writeJSON(w, http.StatusOK, NextAgentResponse{
+			LaunchAgentResponse: *resp,
+			ProjectID:           task.Project.ID,
+			ProjectName:         task.Project.Name,
+			IssueID:             issue.ID,
+			IssueTitle:          issue.Title,
+			Score:               task.Score,
+			Reasons:             task.Reasons,
+		})
+		return
+	}
+	if conflict != nil {
+		writeJSON(w, conflict.status, conflict.body)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// spawnTerminal runs claudeCmd in a new terminal in the session's worktree and
+// records the terminal on the session. A spawn failure is returned as a
+// message rather than failing the launch, since the command can still be run
+// by hand.
+func (s *Server) spawnTerminal(ctx context.Context, p *models.Project, sess *models.AgentSession, claudeCmd string) (*terminal.Result, string) {
+	name := fmt.Sprintf("pm:%s:%s", p.Name, filepath.Base(sess.WorktreePath))
+	res, err := s.spawner.Spawn(sess.WorktreePath, name, claudeCmd)
+	if err != nil {
+		slog.Warn("failed to spawn terminal", "session", sess.ID, "error", err)
+		return nil, err.Error()
+	}
+	sess.TerminalBackend = res.Backend
+	sess.TerminalID = res.ID
+	if err := s.store.UpdateAgentSession(ctx, sess); err != nil {
+		slog.Warn("failed to record session terminal", "session", sess.ID, "error", err)
+	}
+	return res, ""
+}
+
+// projectConventions returns the project's cached conventions, re-ingesting
+// them first if the repo's convention files changed since the last refresh.
+func (s *Server) projectConventions(ctx context.Context, p *models.Project) string {
+	if refresh.SyncConventions(p) {
+		if err := s.store.UpdateProject(ctx, p); err != nil {
+			slog.Warn("failed to update project conventions", "project", p.Name, "error", err)
+		}
+	}
+	return p.Conventions
 }
 
 func (s *Server) resumeAgent(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var req struct {
-		SessionID string `json:"session_id"`
+		SessionID          string `json:"session_id"`
+		SnapshotHumanEdits bool   `json:"snapshot_human_edits"`
+		Spawn              bool   `json:"spawn"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON")
@@ -1217,6 +3527,10 @@ func (s *Server) resumeAgent(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "session_id is required")
 		return
 	}
+	if req.Spawn && s.spawner == nil {
+		writeError(w, http.StatusBadRequest, s.unavailable(capabilities.Terminal))
+		return
+	}
 
 	sess, err := s.store.GetAgentSession(ctx, req.SessionID)
 	if err != nil {
@@ -1235,35 +3549,51 @@ func (s *Server) resumeAgent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Open iTerm window via wt open
-	if err := s.wt.Create(project.Path, sess.Branch); err != nil {
+	coEdit, err := agent.PrepareResume(ctx, sess, s.git, req.SnapshotHumanEdits)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Open iTerm window via wt open, unless we spawn our own terminal
+	openWorktree := s.wt.Create
+	if req.Spawn {
+		openWorktree = s.wt.CreateHeadless
+	}
+	if err := openWorktree(project.Path, sess.Branch); err != nil {
 		writeError(w, http.StatusInternalServerError, fmt.Sprintf("wt open: %v", err))
 		return
 	}
+	if !req.Spawn {
+		agent.RecordWTTerminal(sess, s.wt)
+	}
 
-	sess.Status = models.SessionStatusActive
-	now := time.Now().UTC()
-	sess.LastActiveAt = &now
-	if err := s.store.UpdateAgentSession(ctx, sess); err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+	if err := agent.Transition(ctx, s.store, sess, models.SessionStatusActive, agent.CauseLaunch); err != nil {
+		writeError(w, transitionStatus(err, http.StatusInternalServerError), err.Error())
 		return
 	}
 
-	command := fmt.Sprintf("cd %s && claude", sess.WorktreePath)
+	claudeCmd := "claude"
 	if sess.IssueID != "" {
 		shortID := sess.IssueID
 		if len(shortID) > 12 {
 			shortID = shortID[:12]
 		}
-		command = fmt.Sprintf(`cd %s && claude "Use pm MCP tools to look up issue %s and implement it. Update the issue status when complete."`, sess.WorktreePath, shortID)
+		prompt := agent.WithLanguageGuidance(fmt.Sprintf("Use pm MCP tools to look up issue %s and implement it. Update the issue status when complete.", shortID), project.MainLanguages())
+		claudeCmd = fmt.Sprintf(`claude "%s"`, prompt)
 	}
 
-	writeJSON(w, http.StatusOK, LaunchAgentResponse{
+	resp := LaunchAgentResponse{
 		SessionID:    sess.ID,
 		Branch:       sess.Branch,
 		WorktreePath: sess.WorktreePath,
-		Command:      command,
-	})
+		Command:      fmt.Sprintf("cd %s && %s", sess.WorktreePath, claudeCmd),
+		CoEdit:       coEdit,
+	}
+	if req.Spawn {
+		resp.Terminal, resp.SpawnError = s.spawnTerminal(ctx, project, sess, claudeCmd)
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 // issueToBranch converts an issue title to a branch name.
@@ -1296,8 +3626,18 @@ func issueToBranch(title string) string {
 
 // CloseAgentRequest is the JSON body for POST /api/v1/agent/close.
 type CloseAgentRequest struct {
-	SessionID string `json:"session_id"`
-	Status    string `json:"status"` // idle, completed, abandoned
+	SessionID        string `json:"session_id"`
+	Status           string `json:"status"`            // idle, completed, abandoned, blocked
+	Force            bool   `json:"force"`             // complete even if close-check reports warnings
+	Reason           string `json:"reason"`            // required for blocked
+	UnblockCondition string `json:"unblock_condition"` // optional, for blocked
+}
+
+// CloseConflictResponse is returned with 409 when completing a session that
+// has not passed its close-check. Retry with force=true to override.
+type CloseConflictResponse struct {
+	Error      string            `json:"error"`
+	CloseCheck *agent.CloseCheck `json:"close_check"`
 }
 
 // CloseAgentResponse is the JSON response for closing an agent session.
@@ -1326,6 +3666,11 @@ func (s *Server) closeAgent(w http.ResponseWriter, r *http.Request) {
 
 	switch target {
 	case models.SessionStatusIdle, models.SessionStatusCompleted, models.SessionStatusAbandoned:
+	case models.SessionStatusBlocked:
+		if strings.TrimSpace(req.Reason) == "" {
+			writeError(w, http.StatusBadRequest, "reason is required when status is blocked")
+			return
+		}
 	default:
 		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid status: %s", req.Status))
 		return
@@ -1333,26 +3678,213 @@ func (s *Server) closeAgent(w http.ResponseWriter, r *http.Request) {
 
 	// Enrich session with git info before closing
 	if sess, err := s.store.GetAgentSession(r.Context(), req.SessionID); err == nil {
-		agent.EnrichSessionWithGitInfo(sess, s.git)
+		// Refuse to complete a session with outstanding work unless forced
+		if target == models.SessionStatusCompleted && s.settings().enforceClose && !req.Force && sess.Status.IsOpen() {
+			project, _ := s.store.GetProject(r.Context(), sess.ProjectID)
+			if check := agent.CheckClose(r.Context(), sess, project, s.git); !check.ReadyToClose {
+				writeJSON(w, http.StatusConflict, CloseConflictResponse{
+					Error:      "session is not ready to close; resolve the warnings or retry with force=true",
+					CloseCheck: check,
+				})
+				return
+			}
+		}
+
+		agent.EnrichSessionWithGitInfo(r.Context(), sess, s.git)
 		_ = s.store.UpdateAgentSession(r.Context(), sess)
 	}
 
-	session, err := agent.CloseSession(r.Context(), s.store, req.SessionID, target)
+	var session *models.AgentSession
+	var err error
+	if target == models.SessionStatusBlocked {
+		session, err = agent.BlockSession(r.Context(), s.store, req.SessionID, req.Reason, req.UnblockCondition)
+	} else {
+		session, err = agent.CloseSession(r.Context(), s.store, req.SessionID, target)
+	}
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			writeError(w, http.StatusNotFound, err.Error())
 			return
 		}
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeError(w, transitionStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
+	// Remember the worktree state so a later resume can detect outside edits
+	if err := agent.RecordWorktreeFingerprint(r.Context(), s.store, session, s.git); err != nil {
+		slog.Warn("failed to record worktree fingerprint", "session", session.ID, "error", err)
+	}
+
 	resp := CloseAgentResponse{
 		SessionID: session.ID,
 		Status:    string(session.Status),
 	}
 	if session.EndedAt != nil {
-		resp.EndedAt = session.EndedAt.Format(time.RFC3339)
+		resp.EndedAt = session.EndedAt.UTC().Format(time.RFC3339)
 	}
 	writeJSON(w, http.StatusOK, resp)
 }
+
+// transitionStatus is the response status for a failed session status
+// change: 409 when another writer changed the session's status first,
+// otherwise fallback.
+func transitionStatus(err error, fallback int) int {
+	if errors.Is(err, store.ErrSessionStatusChanged) {
+		return http.StatusConflict
+	}
+	return fallback
+}
+
+// --- Agent Heartbeat ---
+
+// HeartbeatRequest is the JSON body for POST /api/v1/agent/heartbeat. The
+// session is named by ID or by its worktree path, which is what the
+// post-commit hook installed by pm hooks install sends.
+type HeartbeatRequest struct {
+	SessionID    string `json:"session_id"`
+	WorktreePath string `json:"worktree_path"`
+}
+
+// HealthzResponse is the JSON response for the server health check.
+type HealthzResponse struct {
+	Status                string `json:"status"`
+	SchemaVersion         int    `json:"schema_version"`
+	ExpectedSchemaVersion int    `json:"expected_schema_version"`
+	Error                 string `json:"error,omitempty"`
+}
+
+// healthz reports whether the server can reach its database and whether the
+// database schema matches this binary. A mismatch (say, a newer pm migrated
+// the database under a running server) answers 503 so it is noticed before
+// anything writes to a schema it does not understand.
+// getCapabilities reports the optional integrations the server can use.
+// Servers not given detected capabilities report those of their clients.
+func (s *Server) getCapabilities(w http.ResponseWriter, r *http.Request) {
+	if s.caps != nil {
+		writeJSON(w, http.StatusOK, s.caps)
+		return
+	}
+	c := &capabilities.Capabilities{LLM: capabilities.On, Forge: capabilities.ForgeGitHub, Terminal: capabilities.None, Reasons: map[capabilities.Name]string{}}
+	if s.llm == nil {
+		c.LLM = capabilities.Off
+		c.Reasons[capabilities.LLM] = s.unavailable(capabilities.LLM)
+	}
+	if s.gh == nil {
+		c.Forge = capabilities.None
+		c.Reasons[capabilities.Forge] = s.unavailable(capabilities.Forge)
+	}
+	switch s.spawner.(type) {
+	case nil:
+		c.Reasons[capabilities.Terminal] = s.unavailable(capabilities.Terminal)
+	case *terminal.ITerm:
+		c.Terminal = terminal.BackendITerm
+	case *terminal.Tmux:
+		c.Terminal = terminal.BackendTmux
+	default:
+		c.Terminal = capabilities.On
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+// unavailable explains why a capability the request needs is missing.
+func (s *Server) unavailable(name capabilities.Name) string {
+	if s.caps != nil && s.caps.Reasons[name] != "" {
+		return s.caps.Reasons[name]
+	}
+	return map[capabilities.Name]string{
+		capabilities.LLM:      capabilities.ReasonNoLLM,
+		capabilities.Forge:    capabilities.ReasonNoGH,
+		capabilities.Terminal: capabilities.ReasonNoTerminal,
+	}[name]
+}
+
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	resp := HealthzResponse{Status: "ok", ExpectedSchemaVersion: store.LatestSchemaVersion()}
+	version, err := s.store.SchemaVersion(r.Context())
+	switch {
+	case err != nil:
+		resp.Status = "error"
+		resp.Error = err.Error()
+	case version != resp.ExpectedSchemaVersion:
+		resp.Status = "schema_mismatch"
+		resp.Error = fmt.Sprintf("database schema version %d, this binary expects %d", version, resp.ExpectedSchemaVersion)
+	}
+	resp.SchemaVersion = version
+
+	status := http.StatusOK
+	if resp.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, resp)
+}
+
+// HeartbeatResponse is the JSON response for an agent heartbeat.
+type HeartbeatResponse struct {
+	SessionID      string `json:"session_id"`
+	IssueID        string `json:"issue_id,omitempty"`
+	Status         string `json:"status"`
+	CommitCount    int    `json:"commit_count"`
+	LastCommitHash string `json:"last_commit_hash,omitempty"`
+}
+
+// agentHeartbeat records activity on an open session: it refreshes the last
+// commit and commit count from the worktree and bumps LastActiveAt.
+func (s *Server) agentHeartbeat(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req HeartbeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	var sess *models.AgentSession
+	var err error
+	switch {
+	case req.SessionID != "":
+		sess, err = s.store.GetAgentSession(ctx, req.SessionID)
+	case req.WorktreePath != "":
+		sess, err = s.store.GetAgentSessionByWorktreePath(ctx, filepath.Clean(req.WorktreePath))
+	default:
+		writeError(w, http.StatusBadRequest, "session_id or worktree_path is required")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	if !sess.Status.IsOpen() {
+		writeError(w, http.StatusConflict, fmt.Sprintf("session is %s", sess.Status))
+		return
+	}
+
+	agent.EnrichSessionWithGitInfo(ctx, sess, s.git)
+	if ahead, _, err := s.git.AheadBehind(ctx, sess.WorktreePath, "main"); err == nil && ahead > sess.CommitCount {
+		sess.CommitCount = ahead
+	}
+	// Count the heartbeat toward the session's focus time, which the update
+	// below leaves alone.
+	if sess.LastActiveAt != nil {
+		if _, err := s.store.TouchAgentSession(ctx, sess.ID, *sess.LastActiveAt); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	// Keep the status as read: if the session was closed or changed status
+	// meanwhile, don't write the old one back over it.
+	if err := s.store.TransitionAgentSession(ctx, sess, sess.Status, ""); err != nil {
+		writeError(w, transitionStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	if sess.LastActiveAt != nil {
+		s.heartbeats.Recorded(sess.ID, *sess.LastActiveAt)
+	}
+
+	writeJSON(w, http.StatusOK, HeartbeatResponse{
+		SessionID:      sess.ID,
+		IssueID:        sess.IssueID,
+		Status:         string(sess.Status),
+		CommitCount:    sess.CommitCount,
+		LastCommitHash: sess.LastCommitHash,
+	})
+}
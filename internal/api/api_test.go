@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -15,8 +16,18 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/joescharf/pm/internal/agent"
+	"github.com/joescharf/pm/internal/auth"
+	"github.com/joescharf/pm/internal/capabilities"
+	"github.com/joescharf/pm/internal/federation"
+	"github.com/joescharf/pm/internal/flow"
 	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/intake"
+	"github.com/joescharf/pm/internal/llm"
+	"github.com/joescharf/pm/internal/llmbudget"
 	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/notify"
+	"github.com/joescharf/pm/internal/sla"
 	"github.com/joescharf/pm/internal/store"
 	"github.com/joescharf/pm/internal/wt"
 )
@@ -93,114 +104,1322 @@ func TestProjectCRUD_API(t *testing.T) {
 	assert.Equal(t, http.StatusNoContent, w.Code)
 }
 
+func TestListIssues_SmartSort(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	p := &models.Project{Name: "proj", Path: "/tmp/proj"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	low := &models.Issue{ProjectID: p.ID, Title: "low", Status: models.IssueStatusOpen, Priority: models.IssuePriorityLow}
+	require.NoError(t, s.CreateIssue(ctx, low))
+	breached := &models.Issue{ProjectID: p.ID, Title: "breached", Status: models.IssueStatusOpen, Priority: models.IssuePriorityLow}
+	require.NoError(t, s.CreateIssue(ctx, breached))
+	require.NoError(t, s.SetIssueSLAState(ctx, breached.ID, models.SLAStateBreached))
+
+	for _, path := range []string{"/api/v1/issues?sort=smart", "/api/v1/projects/" + p.ID + "/issues?sort=smart"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		require.Equal(t, http.StatusOK, w.Code, path)
+		listed := decodeJSON[[]models.Issue](t, w)
+		require.Len(t, listed, 2)
+		assert.Equal(t, breached.ID, listed[0].ID, path)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/issues?sort=age", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestIssues_InvalidEnums(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	p := &models.Project{Name: "proj", Path: "/tmp/proj"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "x"}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+
+	cases := []struct{ method, path, body string }{
+		{"GET", "/api/v1/issues?status=opne", ""},
+		{"GET", "/api/v1/issues?priority=urgent", ""},
+		{"POST", "/api/v1/projects/" + p.ID + "/issues", `{"Title":"y","Type":"epic"}`},
+		{"PUT", "/api/v1/issues/" + issue.ID, `{"Title":"x","Status":"finished","Priority":"medium","Type":"feature"}`},
+		{"POST", "/api/v1/issues/bulk-update", `{"ids":["` + issue.ID + `"],"status":"finished"}`},
+		{"POST", "/api/v1/issues/" + issue.ID + "/reviews", `{"verdict":"maybe","summary":"s"}`},
+		{"POST", "/api/v1/issues/" + issue.ID + "/reviews", `{"verdict":"pass","summary":"s","code_quality":"great"}`},
+		{"GET", "/api/v1/sessions?status=active,runing", ""},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(c.method, c.path, bytes.NewBufferString(c.body)))
+		assert.Equal(t, http.StatusBadRequest, w.Code, "%s %s: %s", c.method, c.path, w.Body.String())
+		assert.Contains(t, w.Body.String(), "invalid", "%s %s", c.method, c.path)
+	}
+
+	got, err := s.GetIssue(ctx, issue.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.IssueStatusOpen, got.Status)
+}
+
 func TestIssuesCRUD_API(t *testing.T) {
 	srv, s := setupTestServer(t)
 	router := srv.Router()
 	ctx := context.Background()
 
-	// Create project first
-	p := &models.Project{Name: "proj", Path: "/tmp/proj"}
+	// Create project first
+	p := &models.Project{Name: "proj", Path: "/tmp/proj"}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	// Create issue via API
+	body := `{"title":"test issue","priority":"high","type":"bug"}`
+	req := httptest.NewRequest("POST", "/api/v1/projects/"+p.ID+"/issues", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var created models.Issue
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "test issue", created.Title)
+	assert.Equal(t, models.IssuePriorityHigh, created.Priority)
+	assert.Equal(t, models.IssueStatusOpen, created.Status)
+
+	// List project issues
+	req = httptest.NewRequest("GET", "/api/v1/projects/"+p.ID+"/issues", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	listed := decodeJSON[[]models.Issue](t, w)
+	require.Len(t, listed, 1)
+	require.NotNil(t, listed[0].Readiness)
+	assert.Equal(t, 20, listed[0].Readiness.Score)
+
+	// Get issue
+	req = httptest.NewRequest("GET", "/api/v1/issues/"+created.ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	got := decodeJSON[models.Issue](t, w)
+	require.NotNil(t, got.Readiness)
+	assert.False(t, got.Readiness.Ready)
+	assert.Len(t, got.Readiness.Checks, 5)
+
+	// Delete issue
+	req = httptest.NewRequest("DELETE", "/api/v1/issues/"+created.ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestIssueAcceptanceCriteria_API(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	p := &models.Project{Name: "proj", Path: "/tmp/proj"}
+	require.NoError(t, s.CreateProject(context.Background(), p))
+
+	w := doJSON(t, router, "POST", "/api/v1/projects/"+p.ID+"/issues", map[string]any{
+		"Title": "Add logout", "AcceptanceCriteria": []string{" Logout clears the session cookie ", ""},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	created := decodeJSON[models.Issue](t, w)
+	assert.Equal(t, []string{"Logout clears the session cookie"}, created.AcceptanceCriteria)
+
+	w = doJSON(t, router, "POST", "/api/v1/issues/"+created.ID+"/reviews", map[string]any{
+		"verdict": "pass", "summary": "ok",
+		"criteria": []map[string]any{{"criterion": "Logout clears the session cookie", "passed": true, "note": "TestLogout"}},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = doJSON(t, router, "GET", "/api/v1/issues/"+created.ID+"/reviews", nil)
+	reviews := decodeJSON[[]models.IssueReview](t, w)
+	require.Len(t, reviews, 1)
+	assert.Equal(t, []models.CriterionResult{{Criterion: "Logout clears the session cookie", Passed: true, Note: "TestLogout"}}, reviews[0].Criteria)
+}
+
+func TestTags_API(t *testing.T) {
+	srv, _ := setupTestServer(t)
+	router := srv.Router()
+
+	req := httptest.NewRequest("GET", "/api/v1/tags", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestTagManagement_API(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	app := createProject(t, s, "app", "/tmp/app")
+	issue := createIssue(t, s, app.ID, "Login fails")
+	tags := make(map[string]*models.Tag)
+	for _, name := range []string{"bug", "bugs", "stale"} {
+		tags[name] = &models.Tag{Name: name}
+		require.NoError(t, s.CreateTag(ctx, tags[name]))
+	}
+	require.NoError(t, s.TagIssue(ctx, issue.ID, tags["bugs"].ID))
+
+	w := doJSON(t, router, "GET", "/api/v1/tags", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	usage := decodeJSON[[]models.TagUsage](t, w)
+	require.Len(t, usage, 3)
+	assert.Equal(t, 1, usage[1].Issues)
+	assert.Equal(t, 1, usage[1].Open)
+
+	w = doJSON(t, router, "POST", "/api/v1/tags/bugs/merge-into/bugs", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(t, router, "POST", "/api/v1/tags/bugs/merge-into/missing", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doJSON(t, router, "POST", "/api/v1/tags/bugs/merge-into/"+tags["bug"].ID, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	merged := decodeJSON[models.TagUsage](t, w)
+	assert.Equal(t, "bug", merged.Name)
+	assert.Equal(t, 1, merged.Issues)
+
+	w = doJSON(t, router, "PUT", "/api/v1/tags/stale", map[string]string{"name": "bug"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = doJSON(t, router, "PUT", "/api/v1/tags/bug", map[string]string{"name": " "})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(t, router, "PUT", "/api/v1/tags/bug", map[string]string{"name": "defect"})
+	require.Equal(t, http.StatusOK, w.Code)
+	got, err := s.GetIssue(ctx, issue.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"defect"}, got.Tags)
+
+	w = doJSON(t, router, "GET", "/api/v1/tags/defect/breakdown", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	b := decodeJSON[TagBreakdownResponse](t, w)
+	assert.Equal(t, 1, b.Issues)
+	assert.Equal(t, map[models.IssueStatus]int{models.IssueStatusOpen: 1}, b.ByStatus)
+	assert.Equal(t, map[string]int{"app": 1}, b.ByProject)
+
+	w = doJSON(t, router, "DELETE", "/api/v1/tags/orphans?dry_run=true", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	cleanup := decodeJSON[TagCleanupResponse](t, w)
+	assert.Equal(t, 1, cleanup.Deleted)
+	assert.Equal(t, "stale", cleanup.Tags[0].Name)
+	w = doJSON(t, router, "DELETE", "/api/v1/tags/orphans", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	remaining, err := s.ListTags(ctx)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "defect", remaining[0].Name)
+}
+
+func TestCombined_API(t *testing.T) {
+	srv, s := setupTestServer(t)
+	app := createProject(t, s, "app", "/tmp/app")
+	createIssue(t, s, app.ID, "Login fails")
+
+	// A second pm server, read over HTTP as the "home" profile
+	homeSrv, home := setupTestServer(t)
+	blog := createProject(t, home, "app", "/tmp/home-app")
+	done := createIssue(t, home, blog.ID, "Publish post")
+	done.Status = models.IssueStatusDone
+	require.NoError(t, home.UpdateIssue(context.Background(), done))
+	ts := httptest.NewServer(homeSrv.Router())
+	defer ts.Close()
+
+	profiles, err := federation.Open([]federation.Config{{Name: "home", URL: ts.URL}}, "")
+	require.NoError(t, err)
+	WithProfiles("work", profiles)(srv)
+	router := srv.Router()
+
+	w := doJSON(t, router, "GET", "/api/v1/combined/status", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	view := decodeJSON[federation.View](t, w)
+	require.Len(t, view.Profiles, 2)
+	assert.Equal(t, federation.ProfileStatus{Name: "work", Projects: 1}, view.Profiles[0])
+	assert.Equal(t, federation.ProfileStatus{Name: "home", Location: ts.URL, Projects: 1}, view.Profiles[1])
+	require.Len(t, view.Projects, 2)
+	assert.Equal(t, "home/app", view.Projects[0].Name)
+	assert.Equal(t, 1, view.Projects[0].DoneIssues)
+	assert.Equal(t, "work/app", view.Projects[1].Name)
+	assert.Equal(t, "work:"+app.ID, view.Projects[1].ID)
+	assert.Equal(t, 1, view.Projects[1].OpenIssues)
+
+	w = doJSON(t, router, "GET", "/api/v1/combined/issues?status=done", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	issues := decodeJSON[federation.IssueView](t, w)
+	require.Len(t, issues.Issues, 1)
+	assert.Equal(t, "Publish post", issues.Issues[0].Title)
+	assert.Equal(t, "home/app", issues.Issues[0].ProjectName)
+	assert.Equal(t, "home:"+done.ID, issues.Issues[0].NamespacedID)
+
+	w = doJSON(t, router, "GET", "/api/v1/combined/issues?status=bogus", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// A server that is down is reported, not fatal
+	ts.Close()
+	w = doJSON(t, router, "GET", "/api/v1/combined/status", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	view = decodeJSON[federation.View](t, w)
+	assert.NotEmpty(t, view.Profiles[1].Error)
+	assert.Len(t, view.Projects, 1)
+}
+
+func TestHealthz(t *testing.T) {
+	srv, st := setupTestServer(t)
+	router := srv.Router()
+
+	w := doJSON(t, router, "GET", "/healthz", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	resp := decodeJSON[HealthzResponse](t, w)
+	assert.Equal(t, "ok", resp.Status)
+	assert.Equal(t, store.LatestSchemaVersion(), resp.SchemaVersion)
+
+	// A database on another schema version fails the check loudly.
+	sqlite := st.(*store.SQLiteStore)
+	plan, err := sqlite.PlanMigration(context.Background(), store.LatestSchemaVersion()-1)
+	require.NoError(t, err)
+	_, err = sqlite.ApplyMigration(context.Background(), plan, false)
+	require.NoError(t, err)
+
+	w = doJSON(t, router, "GET", "/healthz", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	resp = decodeJSON[HealthzResponse](t, w)
+	assert.Equal(t, "schema_mismatch", resp.Status)
+	assert.Equal(t, store.LatestSchemaVersion()-1, resp.SchemaVersion)
+	assert.Equal(t, store.LatestSchemaVersion(), resp.ExpectedSchemaVersion)
+}
+
+func TestCapabilities(t *testing.T) {
+	srv, s := setupTestServer(t)
+
+	// Without detected capabilities, the server reports its clients'.
+	w := doJSON(t, srv.Router(), "GET", "/api/v1/capabilities", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	got := decodeJSON[capabilities.Capabilities](t, w)
+	assert.Equal(t, capabilities.Off, got.LLM)
+	assert.Equal(t, capabilities.ForgeGitHub, got.Forge)
+	assert.Equal(t, capabilities.None, got.Terminal)
+	assert.Equal(t, capabilities.ReasonNoTerminal, got.Reasons[capabilities.Terminal])
+
+	caps := &capabilities.Capabilities{LLM: capabilities.Off, Forge: capabilities.None, Terminal: capabilities.None, Reasons: map[capabilities.Name]string{
+		capabilities.LLM:      capabilities.ReasonNoLLM,
+		capabilities.Forge:    capabilities.ReasonGHLogin,
+		capabilities.Terminal: "terminal spawning is not available: agent.terminal tmux needs tmux, which is not installed",
+	}}
+	WithCapabilities(caps)(srv)
+	router := srv.Router()
+	w = doJSON(t, router, "GET", "/api/v1/capabilities", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, *caps, decodeJSON[capabilities.Capabilities](t, w))
+
+	// Endpoints needing a missing capability say why.
+	p := &models.Project{Name: "board", Path: "/nonexistent/board", RepoURL: "https://github.com/acme/board", GitHubBoard: "acme/3"}
+	require.NoError(t, s.CreateProject(context.Background(), p))
+	w = doJSON(t, router, "POST", "/api/v1/projects/"+p.ID+"/board/sync", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "gh auth login")
+	w = doJSON(t, router, "POST", "/api/v1/projects/"+p.ID+"/pr-reviews/sync", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestSessions_API(t *testing.T) {
+	srv, _ := setupTestServer(t)
+	router := srv.Router()
+
+	req := httptest.NewRequest("GET", "/api/v1/sessions", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSessionLabels_API(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	p := &models.Project{Name: "api", Path: t.TempDir()}
+	require.NoError(t, s.CreateProject(ctx, p))
+	a := &models.AgentSession{ProjectID: p.ID, Branch: "feature/a", WorktreePath: t.TempDir(), Status: models.SessionStatusIdle, Labels: "experiment"}
+	require.NoError(t, s.CreateAgentSession(ctx, a))
+	b := &models.AgentSession{ProjectID: p.ID, Branch: "feature/b", WorktreePath: t.TempDir(), Status: models.SessionStatusIdle}
+	require.NoError(t, s.CreateAgentSession(ctx, b))
+
+	req := httptest.NewRequest("PUT", "/api/v1/sessions/"+b.ID+"/labels", strings.NewReader(`{"add":["hotfix","experiment"]}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var labeled models.AgentSession
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &labeled))
+	assert.Equal(t, "hotfix,experiment", labeled.Labels)
+
+	req = httptest.NewRequest("PUT", "/api/v1/sessions/"+a.ID+"/labels", strings.NewReader(`{"remove":["experiment"]}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	req = httptest.NewRequest("GET", "/api/v1/sessions?label=hotfix", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var sessions []sessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sessions))
+	require.Len(t, sessions, 1)
+	assert.Equal(t, b.ID, sessions[0].ID)
+
+	req = httptest.NewRequest("GET", "/api/v1/sessions/labels?project_id="+p.ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var stats []agent.LabelStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	require.Len(t, stats, 3)
+	assert.Equal(t, "experiment", stats[0].Label)
+	assert.Equal(t, "hotfix", stats[1].Label)
+	assert.Equal(t, "", stats[2].Label)
+	assert.Equal(t, 1, stats[2].Open)
+}
+
+func TestIssueClosures_API(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	p := &models.Project{Name: "api", Path: t.TempDir()}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Crash", Status: models.IssueStatusDone}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	c := &models.IssueClosure{IssueID: issue.ID, ProjectID: p.ID, Source: models.ClosureSourceCommit, Ref: "abc123",
+		FromStatus: models.IssueStatusInProgress, ToStatus: models.IssueStatusDone}
+	_, err := s.RecordIssueClosure(ctx, c)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/api/v1/issues/"+issue.ID+"/closures", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var closures []models.IssueClosure
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &closures))
+	require.Len(t, closures, 1)
+	assert.Equal(t, "abc123", closures[0].Ref)
+
+	req = httptest.NewRequest("POST", "/api/v1/issues/other/closures/"+c.ID+"/revert", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "closure of another issue")
+
+	req = httptest.NewRequest("POST", "/api/v1/issues/"+issue.ID+"/closures/"+c.ID+"/revert", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	got, err := s.GetIssue(ctx, issue.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.IssueStatusInProgress, got.Status)
+
+	req = httptest.NewRequest("POST", "/api/v1/issues/"+issue.ID+"/closures/"+c.ID+"/revert", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code, "already reverted")
+}
+
+func TestIssueCode_API(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	p := &models.Project{Name: "api", Path: t.TempDir()}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Crash", Status: models.IssueStatusDone}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	require.NoError(t, s.CreateAgentSession(ctx, &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID,
+		Branch: "fix/crash", Status: models.SessionStatusCompleted, MergeCommit: "abc123"}))
+
+	req := httptest.NewRequest("GET", "/api/v1/issues/"+issue.ID+"/code", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var code agent.IssueCode
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &code))
+	require.Len(t, code.Branches, 1)
+	assert.Equal(t, "fix/crash", code.Branches[0].Branch)
+	assert.Equal(t, "abc123^..abc123", code.Branches[0].MergedRange)
+
+	req = httptest.NewRequest("GET", "/api/v1/issues/missing/code", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestShortCodePaths_API(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	p := &models.Project{Name: "widgets", Path: t.TempDir()}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Crash", Status: models.IssueStatusOpen}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	sess := &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "fix/crash", WorktreePath: t.TempDir(), Status: models.SessionStatusIdle}
+	require.NoError(t, s.CreateAgentSession(ctx, sess))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/issues/wid-1", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	got := decodeJSON[models.Issue](t, w)
+	assert.Equal(t, issue.ID, got.ID)
+	assert.Equal(t, "WID-1", got.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/sessions/WID-S1", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, sess.ID, decodeJSON[sessionResponse](t, w).ID)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/issues/WID-S1", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code, "a session code is not an issue")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("PUT", "/api/v1/projects/"+p.ID, strings.NewReader(`{"Key": "GIZ"}`)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "GIZ", decodeJSON[models.Project](t, w).Key)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/issues/GIZ-1", nil))
+	assert.Equal(t, http.StatusOK, w.Code, "codes follow the key")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("PUT", "/api/v1/projects/"+p.ID, strings.NewReader(`{"Key": "giz-2"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/projects", strings.NewReader(`{"Name": "gizmos", "Path": "/code/gizmos", "Key": "GIZ"}`)))
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestRenderIssue_API(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	p := &models.Project{Name: "api", Path: t.TempDir()}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Crash",
+		Description: "Fails on **save** <script>alert(1)</script>",
+		Body:        "```go\nfunc main() {}\n```"}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+
+	req := httptest.NewRequest("GET", "/api/v1/issues/"+issue.ID+"/rendered", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var got RenderedIssue
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, issue.ID, got.IssueID)
+	assert.Contains(t, got.DescriptionHTML, "<strong>save</strong>")
+	assert.NotContains(t, got.DescriptionHTML, "<script")
+	assert.Contains(t, got.BodyHTML, `<pre class="chroma">`)
+	assert.Contains(t, got.HighlightCSS, ".chroma")
+
+	req = httptest.NewRequest("GET", "/api/v1/issues/"+issue.ID+"/rendered?css=false", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "highlight_css")
+
+	req = httptest.NewRequest("GET", "/api/v1/issues/missing/rendered", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestIssueWatchersAndComments_API(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	p := &models.Project{Name: "api", Path: t.TempDir()}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Crash"}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	base := "/api/v1/issues/" + issue.ID
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", base+"/watchers", `{"target": "https://hooks.example.com/pm"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var watcher models.IssueWatcher
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &watcher))
+	assert.Equal(t, models.WatcherWebhook, watcher.Kind)
+
+	w = do("POST", base+"/watchers", `{"target": "Dev <dev@example.com>"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = do("POST", base+"/watchers", `{"target": "dev@example.com"}`)
+	assert.Equal(t, http.StatusOK, w.Code, "already watching")
+	assert.Equal(t, http.StatusBadRequest, do("POST", base+"/watchers", `{"target": "not an address"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", base+"/watchers", `{"target": "ftp://x", "kind": "webhook"}`).Code)
+	assert.Equal(t, http.StatusNotFound, do("POST", "/api/v1/issues/missing/watchers", `{"target": "dev@example.com"}`).Code)
+
+	w = do("GET", base+"/watchers", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var watchers []models.IssueWatcher
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &watchers))
+	require.Len(t, watchers, 2)
+	assert.Equal(t, "dev@example.com", watchers[1].Target)
+
+	assert.Equal(t, http.StatusNoContent, do("DELETE", base+"/watchers?target=dev@example.com", "").Code)
+	assert.Equal(t, http.StatusNotFound, do("DELETE", base+"/watchers?target=dev@example.com", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do("DELETE", base+"/watchers", "").Code)
+
+	w = do("POST", base+"/comments", `{"author": "joe", "body": "Repro attached"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusBadRequest, do("POST", base+"/comments", `{"body": " "}`).Code)
+	w = do("GET", base+"/comments", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var comments []models.IssueComment
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &comments))
+	require.Len(t, comments, 1)
+	assert.Equal(t, "joe", comments[0].Author)
+}
+
+func TestRankIssue_API(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	p := &models.Project{Name: "api", Path: t.TempDir()}
+	require.NoError(t, s.CreateProject(ctx, p))
+	first := &models.Issue{ProjectID: p.ID, Title: "First", Priority: models.IssuePriorityHigh}
+	require.NoError(t, s.CreateIssue(ctx, first))
+	second := &models.Issue{ProjectID: p.ID, Title: "Second", Priority: models.IssuePriorityLow}
+	require.NoError(t, s.CreateIssue(ctx, second))
+
+	patch := func(id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PATCH", "/api/v1/issues/"+id+"/rank", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := patch(second.ID, `{"before": "`+first.ID+`"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var ranked models.Issue
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ranked))
+	assert.NotEmpty(t, ranked.Rank)
+
+	req := httptest.NewRequest("GET", "/api/v1/projects/"+p.ID+"/issues", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var issues []models.Issue
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &issues))
+	require.Len(t, issues, 2)
+	assert.Equal(t, "Second", issues[0].Title)
+
+	assert.Equal(t, http.StatusOK, patch(second.ID, `{"position": "bottom"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, patch(second.ID, `{"position": "middle"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, patch(second.ID, `{}`).Code)
+	assert.Equal(t, http.StatusBadRequest, patch(second.ID, `{"after": "missing"}`).Code)
+	assert.Equal(t, http.StatusNotFound, patch("missing", `{"position": "top"}`).Code)
+}
+
+func TestChangeFeed_API(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	get := func(path string) ChangesResponse {
+		t.Helper()
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp ChangesResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	p := &models.Project{Name: "api", Path: t.TempDir()}
+	require.NoError(t, s.CreateProject(ctx, p))
+	start := get("/api/v1/changes")
+	assert.Empty(t, start.Changes, "without since only the cursor is returned")
+	assert.Positive(t, start.Cursor)
+
+	var issues []*models.Issue
+	for _, title := range []string{"One", "Two", "Three"} {
+		issue := &models.Issue{ProjectID: p.ID, Title: title}
+		require.NoError(t, s.CreateIssue(ctx, issue))
+		issues = append(issues, issue)
+	}
+
+	page := get(fmt.Sprintf("/api/v1/changes?since=%d&limit=2", start.Cursor))
+	require.Len(t, page.Changes, 2)
+	assert.True(t, page.HasMore)
+	assert.Equal(t, issues[0].ID, page.Changes[0].EntityID)
+	assert.Equal(t, models.ChangeInsert, page.Changes[0].Op)
+	page = get(fmt.Sprintf("/api/v1/changes?since=%d&limit=2", page.Cursor))
+	require.Len(t, page.Changes, 1)
+	assert.False(t, page.HasMore)
+	assert.Equal(t, issues[2].ID, page.Changes[0].EntityID)
+
+	assert.Empty(t, get(fmt.Sprintf("/api/v1/changes?since=%d&entity=session", start.Cursor)).Changes)
+	assert.Len(t, get(fmt.Sprintf("/api/v1/changes?since=%d&entity=issue,project&project_id=%s", start.Cursor, p.ID)).Changes, 3)
+
+	// A waiting request returns as soon as something changes
+	done := make(chan ChangesResponse)
+	go func() { done <- get(fmt.Sprintf("/api/v1/changes?since=%d&wait=10s", page.Cursor)) }()
+	time.Sleep(50 * time.Millisecond)
+	issues[0].Title = "One, renamed"
+	require.NoError(t, s.UpdateIssue(ctx, issues[0]))
+	select {
+	case resp := <-done:
+		require.Len(t, resp.Changes, 1)
+		assert.Equal(t, models.ChangeUpdate, resp.Changes[0].Op)
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiting request did not return after a write")
+	}
+
+	// Cursors the feed no longer covers ask the client to reload
+	_, err := s.PruneChanges(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	reset := get("/api/v1/changes?since=1")
+	assert.True(t, reset.Reset)
+	assert.Empty(t, reset.Changes)
+	assert.False(t, get(fmt.Sprintf("/api/v1/changes?since=%d", reset.Cursor)).Reset)
+
+	for _, q := range []string{"since=-1", "since=x", "limit=0", "limit=1001", "entity=tag", "wait=2m"} {
+		req := httptest.NewRequest("GET", "/api/v1/changes?"+q, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, q)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	srv, _ := setupTestServer(t)
+	router := srv.Router()
+
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("OPTIONS", "/api/v1/projects", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Localhost on any port is allowed by default
+	w := preflight("http://localhost:5173")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "http://localhost:5173", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST, PUT, PATCH, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+
+	w = preflight("https://evil.example")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	// Other origins' requests go through without CORS headers
+	req := httptest.NewRequest("GET", "/api/v1/projects", nil)
+	req.Header.Set("Origin", "https://evil.example")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	// Reconfigured CORS applies to the running router
+	srv.Reconfigure(WithCORS(CORSConfig{AllowedOrigins: []string{"https://pm.example.com"}, AllowedMethods: []string{"GET"}, AllowedHeaders: []string{"Authorization"}}))
+	w = preflight("https://pm.example.com")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "GET", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, http.StatusForbidden, preflight("http://localhost:5173").Code)
+}
+
+func TestCORSConfig_AllowsOrigin(t *testing.T) {
+	c := CORSConfig{AllowedOrigins: []string{"http://localhost:*", "https://pm.example.com"}}
+	assert.True(t, c.AllowsOrigin("http://localhost:3000"))
+	assert.True(t, c.AllowsOrigin("http://localhost"))
+	assert.True(t, c.AllowsOrigin("https://pm.example.com"))
+	assert.False(t, c.AllowsOrigin("http://localhost:3000.evil.example"))
+	assert.False(t, c.AllowsOrigin("http://localhost.evil.example"))
+	assert.False(t, c.AllowsOrigin("https://pm.example.com:8443"))
+	assert.True(t, CORSConfig{AllowedOrigins: []string{"*"}}.AllowsOrigin("https://any.example"))
+}
+
+func TestRequireAuth(t *testing.T) {
+	srv, _ := setupTestServer(t)
+	WithRequireAuth(true)(srv)
+	router := srv.Router()
+
+	assert.Equal(t, http.StatusUnauthorized, doJSON(t, router, "GET", "/api/v1/projects", nil).Code)
+	assert.Equal(t, http.StatusOK, doJSON(t, router, "GET", "/healthz", nil).Code)
+}
+
+func TestProjectBadges(t *testing.T) {
+	srv, s := setupTestServer(t)
+	ctx := context.Background()
+	p := &models.Project{Name: "badged", Path: t.TempDir()}
+	require.NoError(t, s.CreateProject(ctx, p))
+	for _, status := range []models.IssueStatus{models.IssueStatusOpen, models.IssueStatusInProgress, models.IssueStatusDone} {
+		require.NoError(t, s.CreateIssue(ctx, &models.Issue{ProjectID: p.ID, Title: string(status), Status: status}))
+	}
+
+	get := func(router http.Handler, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+	router := srv.Router()
+	base := "/api/v1/projects/" + p.ID + "/badge/"
+
+	w := get(router, base+"issues.svg")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "image/svg+xml", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache, max-age=0", w.Header().Get("Cache-Control"))
+	assert.Contains(t, w.Body.String(), "issues: 2 open")
+
+	w = get(router, base+"health.svg")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Regexp(t, `health: \d+/100`, w.Body.String())
+	assert.Equal(t, http.StatusNotFound, get(router, "/api/v1/projects/missing/badge/health.svg").Code)
+
+	// Behind auth, badges are only public when configured
+	WithRequireAuth(true)(srv)
+	assert.Equal(t, http.StatusUnauthorized, get(srv.Router(), base+"issues.svg").Code)
+	WithPublicBadges(true)(srv)
+	router = srv.Router()
+	assert.Equal(t, http.StatusOK, get(router, base+"issues.svg").Code)
+	assert.Equal(t, http.StatusUnauthorized, get(router, "/api/v1/projects/"+p.ID).Code)
+}
+
+func TestStatusOverview_HasVersionFields(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	p := &models.Project{Name: "status-test", Path: "/tmp/status-test"}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	req := httptest.NewRequest("GET", "/api/v1/status", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Verify JSON structure includes version fields
+	var entries []map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+	require.Len(t, entries, 1)
+
+	// The entry should have standard fields
+	_, hasProject := entries[0]["project"]
+	assert.True(t, hasProject, "should have project field")
+	_, hasBranch := entries[0]["branch"]
+	assert.True(t, hasBranch, "should have branch field")
+	_, hasHealth := entries[0]["health"]
+	assert.True(t, hasHealth, "should have health field")
+}
+
+func TestResponseCache(t *testing.T) {
+	dir := t.TempDir()
+	s, err := store.NewSQLiteStore(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+	require.NoError(t, s.Migrate(context.Background()))
+	t.Cleanup(func() { _ = s.Close() })
+	srv := NewServer(s, git.NewClient(), git.NewGitHubClient(), wt.NewClient(), nil, WithResponseCache(time.Minute))
+	router := srv.Router()
+	ctx := context.Background()
+
+	p := &models.Project{Name: "cached", Path: "/nonexistent/cached"}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	get := func(path string) (*httptest.ResponseRecorder, []map[string]any) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		var entries []map[string]any
+		if w.Code == http.StatusOK && path == "/api/v1/status" {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+		}
+		return w, entries
+	}
+
+	w, entries := get("/api/v1/status")
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	assert.Len(t, entries, 1)
+	w, entries = get("/api/v1/status")
+	assert.Equal(t, "HIT", w.Header().Get("X-Cache"))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Len(t, entries, 1)
+
+	// Store writes invalidate the cache
+	require.NoError(t, s.CreateProject(ctx, &models.Project{Name: "other", Path: "/nonexistent/other"}))
+	w, entries = get("/api/v1/status")
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	assert.Len(t, entries, 2)
+
+	// Per-project endpoints are cached separately; errors are not cached
+	w, _ = get("/api/v1/health/" + p.ID)
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	w, _ = get("/api/v1/health/" + p.ID)
+	assert.Equal(t, "HIT", w.Header().Get("X-Cache"))
+	get("/api/v1/status/missing")
+	w, _ = get("/api/v1/status/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+
+	// Mutating requests invalidate even when the store is untouched
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/v1/issues/bulk-delete", bytes.NewBufferString(`{"ids":[]}`)))
+	w, _ = get("/api/v1/health/" + p.ID)
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+
+	// Entries expire after the TTL
+	srv.cache.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	w, _ = get("/api/v1/health/" + p.ID)
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+}
+
+func TestResponseCache_WriteDuringCompute(t *testing.T) {
+	srv := NewServer(store.NewMemoryStore(), git.NewClient(), git.NewGitHubClient(), wt.NewClient(), nil, WithResponseCache(time.Minute))
+	calls := 0
+	h := srv.cached(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			srv.InvalidateCache() // a write lands while the response is computed
+		}
+		writeJSON(w, http.StatusOK, calls)
+	})
+
+	for _, want := range []string{"MISS", "MISS", "HIT"} {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest("GET", "/api/v1/status", nil))
+		assert.Equal(t, want, w.Header().Get("X-Cache"))
+	}
+	assert.Equal(t, 2, calls, "the response computed across the write was not cached")
+}
+
+func TestGitMetrics(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	git.SetDefaultExecutor(git.NewExecutor(2, time.Minute))
+	t.Cleanup(func() { git.SetDefaultExecutor(git.NewExecutor(0, 0)) })
+
+	p := &models.Project{Name: "metrics", Path: t.TempDir()}
+	require.NoError(t, s.CreateProject(context.Background(), p))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/health/"+p.ID, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/metrics/git", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var stats []git.CommandStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	require.NotEmpty(t, stats)
+	for _, st := range stats {
+		assert.Equal(t, st.Count, st.Failures, "%s ran outside a repo", st.Command)
+	}
+}
+
+func TestIntake(t *testing.T) {
+	dir := t.TempDir()
+	s, err := store.NewSQLiteStore(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+	require.NoError(t, s.Migrate(context.Background()))
+	t.Cleanup(func() { _ = s.Close() })
+	srv := NewServer(s, git.NewClient(), git.NewGitHubClient(), wt.NewClient(), nil, WithIntake(intake.Config{
+		Secret:         "s3cret",
+		Routes:         map[string]string{"bugs+api@example.com": "api"},
+		DefaultProject: "inbox",
+	}))
+	router := srv.Router()
+	ctx := context.Background()
+	for _, name := range []string{"api", "web", "inbox"} {
+		require.NoError(t, s.CreateProject(ctx, &models.Project{Name: name, Path: "/nonexistent/" + name}))
+	}
+
+	post := func(path, contentType, body string, secret string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		if secret != "" {
+			req.Header.Set("X-PM-Intake-Secret", secret)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	created := func(w *httptest.ResponseRecorder) (IntakeResponse, *models.Issue) {
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var resp IntakeResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		issue, err := s.GetIssue(ctx, resp.IssueID)
+		require.NoError(t, err)
+		return resp, issue
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post("/api/v1/intake", "text/plain", "Broken", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, post("/api/v1/intake", "text/plain", "Broken", "wrong").Code)
+
+	// Raw email routed by recipient
+	email := "From: Ada <ada@example.com>\nTo: bugs+api@example.com\nSubject: Fwd: Upload fails\n\nFiles over 2MB are rejected.\n"
+	resp, issue := created(post("/api/v1/intake", "message/rfc822", email, "s3cret"))
+	assert.Equal(t, "api", resp.Project)
+	assert.Equal(t, "Upload fails", issue.Title)
+	assert.Equal(t, "From: Ada <ada@example.com>\n\nFiles over 2MB are rejected.", issue.Body)
+	assert.Equal(t, models.IssueTypeBug, issue.Type)
+	assert.Equal(t, models.IssueStatusOpen, issue.Status)
+
+	// Subject tag beats the route; plain text falls back to the default project
+	resp, _ = created(post("/api/v1/intake", "message/rfc822", strings.Replace(email, "Fwd:", "[web]", 1), "s3cret"))
+	assert.Equal(t, "web", resp.Project)
+	resp, issue = created(post("/api/v1/intake", "text/plain", "Typo on pricing page\nSays 'anual'.", "s3cret"))
+	assert.Equal(t, "inbox", resp.Project)
+	assert.Equal(t, "Says 'anual'.", issue.Body)
+
+	// JSON webhook payload with an explicit project
+	payload := `{"from":"ops@example.com","subject":"Cert expiring","body":"Renew by Friday"}`
+	resp, issue = created(post("/api/v1/intake?project=web", "application/json", payload, "s3cret"))
+	assert.Equal(t, "web", resp.Project)
+	assert.Equal(t, "Cert expiring", issue.Title)
+	assert.Equal(t, http.StatusUnauthorized, post("/api/v1/intake?project=web&secret=s3cret", "application/json", payload, "").Code,
+		"the secret is only read from the header")
+
+	w := post("/api/v1/intake?project=nope", "text/plain", "Lost", "s3cret")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "an unknown explicit project is not routed elsewhere")
+	assert.Equal(t, http.StatusBadRequest, post("/api/v1/intake", "application/json", `{"body":"no subject"}`, "s3cret").Code)
+
+	disabled, _ := setupTestServer(t)
+	req := httptest.NewRequest("POST", "/api/v1/intake", bytes.NewBufferString("x"))
+	w = httptest.NewRecorder()
+	disabled.Router().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestGetProject_NotFound(t *testing.T) {
+	srv, _ := setupTestServer(t)
+	router := srv.Router()
+
+	req := httptest.NewRequest("GET", "/api/v1/projects/nonexistent", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestBulkProjects(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	a := &models.Project{Name: "a", Path: "/nonexistent/a"}
+	b := &models.Project{Name: "b", Path: "/nonexistent/b", GroupName: "old"}
+	require.NoError(t, s.CreateProject(ctx, a))
+	require.NoError(t, s.CreateProject(ctx, b))
+
+	do := func(body string) (int, BulkProjectsResponse) {
+		req := httptest.NewRequest("POST", "/api/v1/projects/bulk", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var resp BulkProjectsResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	code, _ := do(`{"ids":["` + a.ID + `"],"action":"explode"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do(`{"action":"archive"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// set_group reports unknown IDs per project without failing the rest
+	code, resp := do(`{"ids":["` + a.ID + `","missing","` + b.ID + `"],"action":"set_group","group":"client-x"}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 3, resp.Total)
+	assert.Equal(t, 1, resp.Failed)
+	require.Len(t, resp.Results, 3)
+	assert.True(t, resp.Results[0].Changed)
+	assert.Contains(t, resp.Results[1].Error, "not found")
+	assert.Equal(t, "b", resp.Results[2].Name)
+	projects, err := s.ListProjects(ctx, "client-x")
+	require.NoError(t, err)
+	assert.Len(t, projects, 2)
+
+	// archive hides projects from the default list
+	code, resp = do(`{"ids":["` + b.ID + `"],"action":"archive"}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, resp.Results[0].Changed)
+	projects, err = s.ListProjects(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, "a", projects[0].Name)
+
+	req := httptest.NewRequest("GET", "/api/v1/projects?archived=true", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var archived []*models.Project
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &archived))
+	require.Len(t, archived, 1)
+	assert.Equal(t, "b", archived[0].Name)
+
+	// refresh returns per-project results; a missing path fails only that project
+	code, resp = do(`{"ids":["` + a.ID + `","` + b.ID + `"],"action":"refresh"}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, resp.Failed)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, a.ID, resp.Results[0].ID)
+	assert.Contains(t, resp.Results[0].Error, "project path missing")
+}
+
+func TestSLAReport(t *testing.T) {
+	srv, s := setupTestServer(t)
+	ctx := context.Background()
+	WithSLAPolicies([]sla.Policy{{Name: "bugs", Type: models.IssueTypeBug, StartWithin: time.Nanosecond}})(srv)
+	router := srv.Router()
+
+	p := &models.Project{Name: "pm", Path: "/tmp/pm"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Crash", Status: models.IssueStatusOpen, Priority: models.IssuePriorityHigh, Type: models.IssueTypeBug}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+
+	req := httptest.NewRequest("GET", "/api/v1/reports/sla", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var report sla.Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, 1, report.NewBreaches)
+	require.Len(t, report.Breached, 1)
+	assert.Equal(t, "Crash", report.Breached[0].Title)
+
+	// The report is read-only; the scheduled evaluation records breaches
+	got, err := s.GetIssue(ctx, issue.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SLAStateNone, got.SLAState)
+	breaches, err := s.ListSLABreaches(ctx, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, breaches)
+}
+
+func TestOutcomeReport(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	p := &models.Project{Name: "outcomes", Path: "/nonexistent/outcomes"}
 	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Crash", Status: models.IssueStatusInProgress, Type: models.IssueTypeBug}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	session := &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "feature/crash", WorktreePath: "/nonexistent/crash", Status: models.SessionStatusActive, CommitCount: 2}
+	require.NoError(t, s.CreateAgentSession(ctx, session))
 
-	// Create issue via API
-	body := `{"title":"test issue","priority":"high","type":"bug"}`
-	req := httptest.NewRequest("POST", "/api/v1/projects/"+p.ID+"/issues", bytes.NewBufferString(body))
+	post := func(path, body string) int {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	require.Equal(t, http.StatusOK, post("/api/v1/agent/close", `{"session_id":"`+session.ID+`","status":"completed"}`))
+	got, err := s.GetAgentSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.OutcomeCleanSuccess, got.OutcomeClass, "classified on close")
+
+	require.Equal(t, http.StatusCreated, post("/api/v1/issues/"+issue.ID+"/reviews", `{"verdict":"fail","summary":"no tests"}`))
+
+	req := httptest.NewRequest("GET", "/api/v1/reports/outcomes?project_id="+p.ID, nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, http.StatusOK, w.Code)
+	report := decodeJSON[agent.OutcomeReport](t, w)
+	assert.Equal(t, 1, report.Total.Sessions)
+	assert.Equal(t, map[models.OutcomeClass]int{models.OutcomeReviewFailed: 1}, report.Total.Outcomes, "a failed review reclassifies the session")
+	require.Len(t, report.ByIssueType, 1)
+	assert.Equal(t, "bug", report.ByIssueType[0].Key)
+	require.Len(t, report.ByProject, 1)
+	assert.Equal(t, "outcomes", report.ByProject[0].Name)
+	require.Len(t, report.ByPromptTemplate, 1)
+	assert.Equal(t, agent.PromptTemplateIssue, report.ByPromptTemplate[0].Key)
+}
 
-	var created models.Issue
-	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
-	assert.Equal(t, "test issue", created.Title)
-	assert.Equal(t, models.IssuePriorityHigh, created.Priority)
-	assert.Equal(t, models.IssueStatusOpen, created.Status)
+func TestFocusReport(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
 
-	// List project issues
-	req = httptest.NewRequest("GET", "/api/v1/projects/"+p.ID+"/issues", nil)
-	w = httptest.NewRecorder()
+	p := &models.Project{Name: "focus", Path: "/nonexistent/focus"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Crash", Status: models.IssueStatusInProgress}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	session := &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "feature/crash", WorktreePath: "/nonexistent/crash", Status: models.SessionStatusActive}
+	require.NoError(t, s.CreateAgentSession(ctx, session))
+	for _, m := range []int{0, 2, 4} {
+		_, err := s.TouchAgentSession(ctx, session.ID, session.StartedAt.Add(time.Duration(m)*time.Minute))
+		require.NoError(t, err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/reports/focus?project_id="+p.ID, nil)
+	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, http.StatusOK, w.Code)
+	report := decodeJSON[agent.FocusReport](t, w)
+	assert.Equal(t, 1, report.Total.Sessions)
+	require.Len(t, report.ByIssue, 1)
+	assert.Equal(t, "Crash", report.ByIssue[0].Title)
+	assert.InDelta(t, 240, report.ByIssue[0].FocusSeconds, 0.001)
+}
+
+func TestLLMBudget(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "spendy", Path: "/nonexistent/spendy"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	require.NoError(t, s.RecordLLMUsage(ctx, &models.LLMUsage{ProjectID: p.ID, Purpose: models.LLMUsageEnrichment, InputTokens: 900, OutputTokens: 200}))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Crash", Status: models.IssueStatusOpen}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+
+	newRouter := func(onExceeded string) http.Handler {
+		budget, err := llmbudget.New(llmbudget.Config{Limit: llmbudget.Limit{MonthlyTokens: 1000}, OnExceeded: onExceeded})
+		require.NoError(t, err)
+		// The budget is checked first, so the client never calls the API.
+		return NewServer(s, git.NewClient(), git.NewGitHubClient(), wt.NewClient(), llm.NewClient("test-key", ""), WithLLMBudget(budget)).Router()
+	}
+	router := newRouter("")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/projects/"+p.ID+"/llm-budget", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	st := decodeJSON[llmbudget.Status](t, w)
+	assert.Equal(t, int64(1100), st.TotalTokens)
+	assert.Equal(t, int64(1000), st.Limit.MonthlyTokens)
+	assert.True(t, st.Exceeded)
+	assert.Equal(t, llmbudget.OnExceededHeuristics, st.OnExceeded)
+	assert.Equal(t, map[string]int64{"enrichment": 1100}, st.ByPurpose)
 
-	// Get issue
-	req = httptest.NewRequest("GET", "/api/v1/issues/"+created.ID, nil)
 	w = httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusOK, w.Code)
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/issues/"+issue.ID+"/enrich", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "explicit enrichment has no heuristic to fall back to")
+	assert.Contains(t, w.Body.String(), "budget exceeded")
 
-	// Delete issue
-	req = httptest.NewRequest("DELETE", "/api/v1/issues/"+created.ID, nil)
+	body := `{"title": "Slow start"}`
 	w = httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusNoContent, w.Code)
-}
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/projects/"+p.ID+"/issues", strings.NewReader(body)))
+	require.Equal(t, http.StatusCreated, w.Code, "issues are created unenriched")
+	assert.Empty(t, decodeJSON[models.Issue](t, w).AIPrompt)
 
-func TestTags_API(t *testing.T) {
-	srv, _ := setupTestServer(t)
-	router := srv.Router()
+	w = httptest.NewRecorder()
+	newRouter(llmbudget.OnExceededReject).ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/projects/"+p.ID+"/issues", strings.NewReader(body)))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
 
-	req := httptest.NewRequest("GET", "/api/v1/tags", nil)
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusOK, w.Code)
+	sess := &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "feature/crash", Status: models.SessionStatusCompleted}
+	require.NoError(t, s.CreateAgentSession(ctx, sess))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/sessions/"+sess.ID+"/replay?summarize=true", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "summaries have no heuristic to fall back to")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/projects/nope/llm-budget", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
-func TestSessions_API(t *testing.T) {
-	srv, _ := setupTestServer(t)
+func TestBoardDiffReport(t *testing.T) {
+	srv, s := setupTestServer(t)
 	router := srv.Router()
+	ctx := context.Background()
 
-	req := httptest.NewRequest("GET", "/api/v1/sessions", nil)
+	p := &models.Project{Name: "board", Path: "/nonexistent/board"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "New", Status: models.IssueStatusOpen, Type: models.IssueTypeFeature}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+
+	req := httptest.NewRequest("GET", "/api/v1/reports/board-diff?since=7d&project_id="+p.ID, nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, http.StatusOK, w.Code)
+	diff := decodeJSON[flow.BoardDiff](t, w)
+	require.Len(t, diff.Projects, 1)
+	assert.Equal(t, "board", diff.Projects[0].Project)
+	require.Len(t, diff.Projects[0].Added, 1)
+	assert.Equal(t, issue.ID, diff.Projects[0].Added[0].IssueID)
+	assert.Equal(t, 1, diff.NetGrowth)
+
+	req = httptest.NewRequest("GET", "/api/v1/reports/board-diff?since=soon", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
-func TestCORS(t *testing.T) {
-	srv, _ := setupTestServer(t)
+func TestProjectGitHubBoard(t *testing.T) {
+	srv, s := setupTestServer(t)
 	router := srv.Router()
+	ctx := context.Background()
 
-	req := httptest.NewRequest("OPTIONS", "/api/v1/projects", nil)
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
+	p := &models.Project{Name: "board", Path: "/nonexistent/board", RepoURL: "https://github.com/acme/board"}
+	require.NoError(t, s.CreateProject(ctx, p))
 
-	assert.Equal(t, http.StatusNoContent, w.Code)
-	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	w := doJSON(t, router, "POST", "/api/v1/projects/"+p.ID+"/board/sync", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "no board set")
+
+	w = doJSON(t, router, "PUT", "/api/v1/projects/"+p.ID, map[string]any{"GitHubBoard": "acme"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(t, router, "PUT", "/api/v1/projects/"+p.ID, map[string]any{"GitHubBoard": "acme/3"})
+	require.Equal(t, http.StatusOK, w.Code)
+	got, err := s.GetProject(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "acme/3", got.GitHubBoard)
+
+	w = doJSON(t, router, "PUT", "/api/v1/projects/"+p.ID, map[string]any{"GitHubBoard": ""})
+	require.Equal(t, http.StatusOK, w.Code)
+	got, err = s.GetProject(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.GitHubBoard)
 }
 
-func TestStatusOverview_HasVersionFields(t *testing.T) {
+func TestUpdateProject_WIPLimits(t *testing.T) {
 	srv, s := setupTestServer(t)
 	router := srv.Router()
 	ctx := context.Background()
 
-	p := &models.Project{Name: "status-test", Path: "/tmp/status-test"}
+	p := &models.Project{Name: "wip", Path: "/nonexistent/wip", MaxInProgress: 3}
 	require.NoError(t, s.CreateProject(ctx, p))
 
-	req := httptest.NewRequest("GET", "/api/v1/status", nil)
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusOK, w.Code)
-
-	// Verify JSON structure includes version fields
-	var entries []map[string]any
-	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
-	require.Len(t, entries, 1)
+	for _, patch := range []map[string]any{
+		{"MaxInProgress": -1},
+		{"MaxInProgress": 1.5},
+		{"MaxInProgress": "2"},
+		{"MaxActiveSessions": nil},
+		{"WIPHardBlock": "yes"},
+		{"MaxInProgress": 1, "Archived": 1},
+	} {
+		w := doJSON(t, router, "PUT", "/api/v1/projects/"+p.ID, patch)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "patch %v", patch)
+	}
+	got, err := s.GetProject(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, got.MaxInProgress, "rejected patches change nothing")
 
-	// The entry should have standard fields
-	_, hasProject := entries[0]["project"]
-	assert.True(t, hasProject, "should have project field")
-	_, hasBranch := entries[0]["branch"]
-	assert.True(t, hasBranch, "should have branch field")
-	_, hasHealth := entries[0]["health"]
-	assert.True(t, hasHealth, "should have health field")
+	w := doJSON(t, router, "PUT", "/api/v1/projects/"+p.ID, map[string]any{"MaxInProgress": 0, "MaxActiveSessions": 2, "WIPHardBlock": true})
+	require.Equal(t, http.StatusOK, w.Code)
+	got, err = s.GetProject(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Zero(t, got.MaxInProgress)
+	assert.Equal(t, 2, got.MaxActiveSessions)
+	assert.True(t, got.WIPHardBlock)
 }
 
-func TestGetProject_NotFound(t *testing.T) {
-	srv, _ := setupTestServer(t)
+func TestProjectUIReviewPaths(t *testing.T) {
+	srv, s := setupTestServer(t)
 	router := srv.Router()
+	ctx := context.Background()
 
-	req := httptest.NewRequest("GET", "/api/v1/projects/nonexistent", nil)
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusNotFound, w.Code)
+	p := &models.Project{Name: "web", Path: "/nonexistent/web"}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	w := doJSON(t, router, "PUT", "/api/v1/projects/"+p.ID, map[string]any{"UIReviewPaths": "*.tsx,[bad"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(t, router, "PUT", "/api/v1/projects/"+p.ID, map[string]any{"UIReviewPaths": " *.tsx , templates/,*.tsx"})
+	require.Equal(t, http.StatusOK, w.Code)
+	got, err := s.GetProject(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "*.tsx,templates/", got.UIReviewPaths)
+
+	w = doJSON(t, router, "PUT", "/api/v1/projects/"+p.ID, map[string]any{"UIReviewPaths": ""})
+	require.Equal(t, http.StatusOK, w.Code)
+	got, err = s.GetProject(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.UIReviewPaths)
 }
 
 func TestUpdateProject_PartialUpdate_PreservesOmittedFields(t *testing.T) {
@@ -367,7 +1586,362 @@ func TestCleanupSessions_API(t *testing.T) {
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var result map[string]int64
+	var result cleanupResponse
 	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
-	assert.Equal(t, int64(1), result["deleted"])
+	assert.Equal(t, int64(1), result.Deleted)
+}
+
+func TestIssueRelations_API(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+
+	app := createProject(t, s, "app", "/tmp/app")
+	lib := createProject(t, s, "lib", "/tmp/lib")
+	regression := createIssue(t, s, app.ID, "Login broken again")
+	bug := createIssue(t, s, lib.ID, "Token refresh bug")
+
+	// Invalid type and unknown target
+	w := doJSON(t, router, "POST", "/api/v1/issues/"+regression.ID+"/relations", map[string]any{
+		"target_issue_id": bug.ID, "type": "parent_of",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(t, router, "POST", "/api/v1/issues/"+regression.ID+"/relations", map[string]any{
+		"target_issue_id": "missing", "type": "caused_by",
+	})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Create a cross-project relation
+	w = doJSON(t, router, "POST", "/api/v1/issues/"+regression.ID+"/relations", map[string]any{
+		"target_issue_id": bug.ID, "type": "caused_by",
+	})
+	require.Equal(t, http.StatusCreated, w.Code, "body: %s", w.Body.String())
+	created := decodeJSON[issueRelationEntry](t, w)
+	assert.Equal(t, "caused by", created.Label)
+	assert.Equal(t, bug.ID, created.IssueID)
+
+	// Duplicate is a conflict
+	w = doJSON(t, router, "POST", "/api/v1/issues/"+regression.ID+"/relations", map[string]any{
+		"target_issue_id": bug.ID, "type": "caused_by",
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Listed from the other end with the inverse label
+	w = doJSON(t, router, "GET", "/api/v1/issues/"+bug.ID+"/relations", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	entries := decodeJSON[[]issueRelationEntry](t, w)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "causes", entries[0].Label)
+	assert.Equal(t, "incoming", entries[0].Direction)
+	assert.Equal(t, regression.ID, entries[0].IssueID)
+	assert.Equal(t, app.ID, entries[0].ProjectID)
+
+	// Delete via either issue; unknown relation is 404
+	w = doJSON(t, router, "DELETE", "/api/v1/issues/"+bug.ID+"/relations/nope", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doJSON(t, router, "DELETE", "/api/v1/issues/"+bug.ID+"/relations/"+created.ID, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = doJSON(t, router, "GET", "/api/v1/issues/"+regression.ID+"/relations", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, decodeJSON[[]issueRelationEntry](t, w))
+}
+
+func TestSplitAndMergeIssues_API(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	app := createProject(t, s, "app", "/tmp/app")
+	epic := createIssue(t, s, app.ID, "Grooming")
+	epic.Body = "- [ ] Split endpoint\n\n## Merge\nFold duplicates"
+	require.NoError(t, s.UpdateIssue(ctx, epic))
+
+	w := doJSON(t, router, "GET", "/api/v1/issues/"+epic.ID+"/split", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	listed := decodeJSON[SplitIssueResponse](t, w)
+	require.Len(t, listed.Parts, 2)
+	assert.Equal(t, "Merge", listed.Parts[1].Title)
+
+	w = doJSON(t, router, "POST", "/api/v1/issues/"+epic.ID+"/split", map[string]any{"parts": []int{3}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(t, router, "POST", "/api/v1/issues/"+epic.ID+"/split", map[string]any{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doJSON(t, router, "POST", "/api/v1/issues/"+epic.ID+"/split", map[string]any{
+		"parts": []int{1, 2}, "titles": []string{"UI buttons"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, "body: %s", w.Body.String())
+	split := decodeJSON[SplitIssueResult](t, w)
+	require.Len(t, split.Children, 3)
+	assert.Equal(t, "Split endpoint", split.Children[0].Title)
+	assert.Equal(t, "Fold duplicates", split.Children[1].Body)
+	related, err := store.RelatedIssues(ctx, s, epic.ID)
+	require.NoError(t, err)
+	assert.Len(t, related, 3)
+
+	dup := createIssue(t, s, app.ID, "Merge dupes")
+	w = doJSON(t, router, "POST", "/api/v1/issues/"+split.Children[1].ID+"/merge", map[string]any{"duplicates": []string{"missing"}})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doJSON(t, router, "POST", "/api/v1/issues/"+split.Children[1].ID+"/merge", map[string]any{"duplicates": []string{split.Children[1].ID}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(t, router, "POST", "/api/v1/issues/"+split.Children[1].ID+"/merge", map[string]any{"duplicates": []string{dup.ID, dup.ID}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	other := createProject(t, s, "other", "/tmp/other")
+	foreign := createIssue(t, s, other.ID, "Merge dupes")
+	w = doJSON(t, router, "POST", "/api/v1/issues/"+split.Children[1].ID+"/merge", map[string]any{"duplicates": []string{dup.ID, foreign.ID}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "another project")
+	got, err := s.GetIssue(ctx, dup.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.IssueStatusOpen, got.Status, "a refused merge writes nothing")
+
+	w = doJSON(t, router, "POST", "/api/v1/issues/"+split.Children[1].ID+"/merge", map[string]any{"duplicates": []string{dup.ID}})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	got, err = s.GetIssue(ctx, dup.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.IssueStatusClosed, got.Status)
+}
+
+func TestBulkDeleteIssues_OpenSessions(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	app := createProject(t, s, "app", "/tmp/app")
+	issue := createIssue(t, s, app.ID, "In flight")
+	sess := &models.AgentSession{ProjectID: app.ID, IssueID: issue.ID, Branch: "feature/x", Status: models.SessionStatusActive}
+	require.NoError(t, s.CreateAgentSession(ctx, sess))
+
+	w := doJSON(t, router, "POST", "/api/v1/issues/bulk-delete", map[string]any{"ids": []string{issue.ID}})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), issue.ID)
+
+	w = doJSON(t, router, "POST", "/api/v1/issues/bulk-delete", map[string]any{"ids": []string{issue.ID}, "force": true})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	assert.Equal(t, map[string]int64{"deleted": 1}, decodeJSON[map[string]int64](t, w))
+	got, err := s.GetAgentSession(ctx, sess.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.IssueID)
+}
+
+func TestProjectNotes_API(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+
+	app := createProject(t, s, "app", "/tmp/app")
+	lib := createProject(t, s, "lib", "/tmp/lib")
+	base := "/api/v1/projects/" + app.ID + "/notes"
+
+	w := doJSON(t, router, "POST", base, map[string]any{"body": "no title"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(t, router, "POST", "/api/v1/projects/missing/notes", map[string]any{"title": "Runbook"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doJSON(t, router, "POST", base, map[string]any{"title": "Runbook", "body": "# Deploy"})
+	require.Equal(t, http.StatusCreated, w.Code, "body: %s", w.Body.String())
+	note := decodeJSON[models.ProjectNote](t, w)
+	assert.Equal(t, app.ID, note.ProjectID)
+
+	w = doJSON(t, router, "POST", base, map[string]any{"title": "Runbook"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Partial update keeps the title
+	w = doJSON(t, router, "PUT", base+"/"+note.ID, map[string]any{"body": "# Deploy\n\nmake release"})
+	require.Equal(t, http.StatusOK, w.Code)
+	updated := decodeJSON[models.ProjectNote](t, w)
+	assert.Equal(t, "Runbook", updated.Title)
+	assert.Equal(t, "# Deploy\n\nmake release", updated.Body)
+
+	w = doJSON(t, router, "GET", base, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, decodeJSON[[]models.ProjectNote](t, w), 1)
+
+	// A note is only reachable through its own project
+	w = doJSON(t, router, "GET", "/api/v1/projects/"+lib.ID+"/notes/"+note.ID, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doJSON(t, router, "DELETE", "/api/v1/projects/"+lib.ID+"/notes/"+note.ID, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doJSON(t, router, "DELETE", base+"/"+note.ID, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doJSON(t, router, "GET", base+"/"+note.ID, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestProjectScopedToken(t *testing.T) {
+	srv, s := setupTestServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	mine := &models.Project{Name: "mine", Path: "/nonexistent/mine"}
+	other := &models.Project{Name: "other", Path: "/nonexistent/other"}
+	require.NoError(t, s.CreateProject(ctx, mine))
+	require.NoError(t, s.CreateProject(ctx, other))
+	newIssue := func(p *models.Project) *models.Issue {
+		issue := &models.Issue{ProjectID: p.ID, Title: "Issue in " + p.Name, Status: models.IssueStatusOpen, Priority: models.IssuePriorityMedium, Type: models.IssueTypeFeature}
+		require.NoError(t, s.CreateIssue(ctx, issue))
+		return issue
+	}
+	myIssue, otherIssue := newIssue(mine), newIssue(other)
+	otherSession := &models.AgentSession{ProjectID: other.ID, IssueID: otherIssue.ID, Branch: "feature/x", WorktreePath: "/nonexistent/x", Status: models.SessionStatusIdle}
+	require.NoError(t, s.CreateAgentSession(ctx, otherSession))
+
+	token, hash, err := auth.NewToken()
+	require.NoError(t, err)
+	require.NoError(t, s.CreateAPIToken(ctx, &models.APIToken{ProjectID: mine.ID, TokenHash: hash}))
+
+	do := func(method, path, bearer, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, do("GET", "/api/v1/issues/"+myIssue.ID, "pm_bogus", "").Code)
+	assert.Equal(t, http.StatusOK, do("GET", "/api/v1/issues/"+otherIssue.ID, "", "").Code, "no token keeps full access")
+
+	assert.Equal(t, http.StatusOK, do("GET", "/api/v1/issues/"+myIssue.ID, token, "").Code)
+	assert.Equal(t, http.StatusForbidden, do("GET", "/api/v1/issues/"+otherIssue.ID, token, "").Code)
+	assert.Equal(t, http.StatusForbidden, do("PUT", "/api/v1/issues/"+otherIssue.ID, token, `{"title":"x"}`).Code)
+	assert.Equal(t, http.StatusOK, do("GET", "/api/v1/projects/"+mine.ID+"/issues", token, "").Code)
+	assert.Equal(t, http.StatusForbidden, do("POST", "/api/v1/projects/"+other.ID+"/issues", token, `{"title":"x"}`).Code)
+	assert.Equal(t, http.StatusForbidden, do("GET", "/api/v1/sessions/"+otherSession.ID, token, "").Code)
+	assert.Equal(t, http.StatusForbidden, do("POST", "/api/v1/agent/close", token, `{"session_id":"`+otherSession.ID+`"}`).Code)
+	assert.Equal(t, http.StatusForbidden, do("POST", "/api/v1/issues/"+myIssue.ID+"/relations", token,
+		`{"target_issue_id":"`+otherIssue.ID+`","type":"blocks"}`).Code)
+	assert.Equal(t, http.StatusForbidden, do("GET", "/api/v1/projects", token, "").Code, "routes without a rule are closed")
+
+	w := do("GET", "/api/v1/issues", token, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	issues := decodeJSON[[]models.Issue](t, w)
+	require.Len(t, issues, 1, "list endpoints are pinned to the scoped project")
+	assert.Equal(t, myIssue.ID, issues[0].ID)
+	assert.Equal(t, http.StatusForbidden, do("GET", "/api/v1/sessions?project_id="+other.ID, token, "").Code)
+}
+
+func TestPush_API(t *testing.T) {
+	srv, s := setupTestServer(t)
+	ctx := context.Background()
+
+	req := httptest.NewRequest("GET", "/api/v1/push/key", nil)
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "push disabled")
+
+	keys, err := notify.GenerateVAPIDKeys()
+	require.NoError(t, err)
+	n, err := notify.NewPushNotifier(notify.PushConfig{Keys: keys, Subject: "mailto:pm@example.com"})
+	require.NoError(t, err)
+	WithPush(n)(srv)
+	router := srv.Router()
+
+	req = httptest.NewRequest("GET", "/api/v1/push/key", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"public_key":"`+keys.PublicKey+`"}`, w.Body.String())
+
+	req = httptest.NewRequest("POST", "/api/v1/push/subscriptions", strings.NewReader(`{"endpoint":"http://push.example/x","keys":{"p256dh":"k","auth":"a"}}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "plain http endpoint")
+
+	body := `{"endpoint":"https://push.example/x","expirationTime":null,"keys":{"p256dh":"k","auth":"a"}}`
+	req = httptest.NewRequest("POST", "/api/v1/push/subscriptions", strings.NewReader(body))
+	req.Header.Set("User-Agent", "Firefox")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	subs, err := s.ListPushSubscriptions(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, "Firefox", subs[0].UserAgent)
+
+	req = httptest.NewRequest("DELETE", "/api/v1/push/subscriptions", strings.NewReader(`{"endpoint":"https://push.example/x"}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	req = httptest.NewRequest("DELETE", "/api/v1/push/subscriptions", strings.NewReader(`{"endpoint":"https://push.example/x"}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDatabaseAdmin_API(t *testing.T) {
+	srv, s := setupTestServer(t)
+
+	req := httptest.NewRequest("GET", "/api/v1/admin/db/stats", nil)
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+
+	WithDatabase(s.(*store.SQLiteStore))(srv)
+	router := srv.Router()
+
+	req = httptest.NewRequest("GET", "/api/v1/admin/db/stats", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var stats store.DBStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Positive(t, stats.Pages)
+	assert.NotEmpty(t, stats.Tables)
+
+	req = httptest.NewRequest("POST", "/api/v1/admin/db/maintain", bytes.NewBufferString(`{"check_only":true}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var report store.MaintenanceReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Empty(t, report.Integrity)
+	assert.False(t, report.Vacuumed)
+
+	req = httptest.NewRequest("POST", "/api/v1/admin/db/maintain", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.True(t, report.Analyzed)
+	assert.True(t, report.Vacuumed)
+}
+
+func TestReloadConfig_API(t *testing.T) {
+	srv, _ := setupTestServer(t)
+	router := srv.Router()
+
+	w := doJSON(t, router, "POST", "/api/v1/admin/reload", nil)
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+
+	var fail bool
+	WithReloader(func(context.Context) (*ReloadReport, error) {
+		if fail {
+			return nil, errors.New("llm.budget: limits and prices must not be negative")
+		}
+		// What a reloader does with the new config
+		srv.Reconfigure(WithPublicBadges(true), WithIntake(intake.Config{Secret: "s3cret"}))
+		return &ReloadReport{Applied: []string{"api.public_badges", "intake.secret"}, Restart: []string{"port"}}, nil
+	})(srv)
+
+	w = doJSON(t, router, "POST", "/api/v1/admin/reload", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var report ReloadReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, []string{"api.public_badges", "intake.secret"}, report.Applied)
+	assert.Equal(t, []string{"port"}, report.Restart)
+
+	// The router built before the reload serves the new settings
+	assert.True(t, srv.settings().publicBadges)
+	req := httptest.NewRequest("POST", "/api/v1/intake", strings.NewReader("Subject: hi\n\nbody"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "intake is configured now")
+
+	fail = true
+	w = doJSON(t, router, "POST", "/api/v1/admin/reload", nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "llm.budget")
 }
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/joescharf/pm/internal/badge"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// WithPublicBadges serves the project badge routes without a token even when
+// the server requires auth, so READMEs can embed them as images.
+func WithPublicBadges(public bool) Option {
+	return func(s *Server) {
+		s.live.publicBadges = public
+	}
+}
+
+// isBadgePath reports whether path is a project badge route.
+func isBadgePath(urlPath string) bool {
+	ok, _ := path.Match("/api/v1/projects/*/badge/*.svg", urlPath)
+	return ok
+}
+
+// badgeHandler serves the badge render returns for the {id} project. Image
+// proxies such as GitHub's are told not to keep it, so READMEs show the live
+// state.
+func (s *Server) badgeHandler(render func(r *http.Request, p *models.Project) (badge.Badge, error)) http.HandlerFunc {
+	cached := s.cached(func(w http.ResponseWriter, r *http.Request) {
+		p, err := s.store.GetProject(r.Context(), r.PathValue("id"))
+		if err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		b, err := render(r, p)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "image/svg+xml")
+		_, _ = w.Write(b.SVG())
+	})
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache, max-age=0")
+		cached(w, r)
+	}
+}
+
+// healthBadge shows the project's live health score, colored like the CLI's
+// health column.
+func (s *Server) healthBadge(r *http.Request, p *models.Project) (badge.Badge, error) {
+	h := s.scoreProject(r.Context(), p)
+	return badge.Badge{Label: "health", Message: fmt.Sprintf("%d/100", h.Total), Color: badge.HealthColor(h.Total)}, nil
+}
+
+// issuesBadge shows how many of the project's issues are open or in
+// progress: blue while any are, green when none are.
+func (s *Server) issuesBadge(r *http.Request, p *models.Project) (badge.Badge, error) {
+	issues, err := s.store.ListIssues(r.Context(), store.IssueListFilter{ProjectID: p.ID})
+	if err != nil {
+		return badge.Badge{}, err
+	}
+	open := 0
+	for _, issue := range issues {
+		if issue.Status == models.IssueStatusOpen || issue.Status == models.IssueStatusInProgress || issue.Status == models.IssueStatusInReview {
+			open++
+		}
+	}
+	color := badge.Blue
+	if open == 0 {
+		color = badge.Green
+	}
+	return badge.Badge{Label: "issues", Message: fmt.Sprintf("%d open", open), Color: color}, nil
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// responseCache holds successful responses of expensive read endpoints
+// for a short TTL so polling clients don't re-run git and GitHub calls on
+// every request. It is cleared whenever the store is written to.
+//
+// Each clear starts a new generation. A response is stored only if no clear
+// happened while it was computed, so one computed from data read before a
+// write is not served after it.
+type responseCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu         sync.Mutex
+	entries    map[string]cacheEntry
+	generation uint64
+}
+
+type cacheEntry struct {
+	body        []byte
+	contentType string
+	expires     time.Time
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{ttl: ttl, now: time.Now, entries: make(map[string]cacheEntry)}
+}
+
+func (c *responseCache) get(key string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	if c.now().After(e.expires) {
+		delete(c.entries, key)
+		return cacheEntry{}, false
+	}
+	return e, true
+}
+
+// current returns the generation, to pass to set once the response is
+// computed.
+func (c *responseCache) current() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// set stores a response computed during generation, unless the cache has
+// been cleared since.
+func (c *responseCache) set(key string, generation uint64, body []byte, contentType string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	c.entries[key] = cacheEntry{body: body, contentType: contentType, expires: c.now().Add(c.ttl)}
+}
+
+func (c *responseCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+	c.generation++
+}
+
+// InvalidateCache drops all cached responses.
+func (s *Server) InvalidateCache() {
+	if s.cache != nil {
+		s.cache.clear()
+	}
+}
+
+// cached serves h's 200 responses from the response cache, keyed by request
+// URI. The X-Cache header reports HIT or MISS. Without a cache h runs as is.
+func (s *Server) cached(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.cache == nil {
+			h(w, r)
+			return
+		}
+		key := r.URL.RequestURI()
+		if e, ok := s.cache.get(key); ok {
+			w.Header().Set("Content-Type", e.contentType)
+			w.Header().Set("X-Cache", "HIT")
+			_, _ = w.Write(e.body)
+			return
+		}
+
+		w.Header().Set("X-Cache", "MISS")
+		generation := s.cache.current()
+		rec := &cacheRecorder{ResponseWriter: w, status: http.StatusOK}
+		h(rec, r)
+		if rec.status == http.StatusOK {
+			s.cache.set(key, generation, rec.body.Bytes(), w.Header().Get("Content-Type"))
+		}
+	}
+}
+
+// cacheRecorder passes a response through while keeping a copy of it.
+type cacheRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *cacheRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *cacheRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
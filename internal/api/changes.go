@@ -0,0 +1,149 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+const (
+	defaultChangesLimit = 500
+	maxChangesLimit     = 1000
+	maxChangesWait      = 60 * time.Second
+	// changesPollInterval bounds how long a waiting request misses writes
+	// the store's hooks do not see, such as the CLI's in another process.
+	changesPollInterval = time.Second
+)
+
+// ChangesResponse is a page of the change feed. Cursor is the since value of
+// the next request. Reset means since is no longer in the feed (its changes
+// were pruned, or the database was replaced): the client reloads what it
+// follows and continues from Cursor.
+type ChangesResponse struct {
+	Changes []*models.Change `json:"changes"`
+	Cursor  int64            `json:"cursor"`
+	HasMore bool             `json:"has_more"`
+	Reset   bool             `json:"reset"`
+}
+
+// notifyChange wakes the change feed requests waiting for a write.
+func (s *Server) notifyChange() {
+	s.changeMu.Lock()
+	defer s.changeMu.Unlock()
+	if s.changeCh != nil {
+		close(s.changeCh)
+		s.changeCh = nil
+	}
+}
+
+// changeSignal returns a channel that is closed by the next write.
+func (s *Server) changeSignal() <-chan struct{} {
+	s.changeMu.Lock()
+	defer s.changeMu.Unlock()
+	if s.changeCh == nil {
+		s.changeCh = make(chan struct{})
+	}
+	return s.changeCh
+}
+
+// listChanges returns the change feed after a cursor. Without since it
+// returns only the current cursor, for clients that have just loaded full
+// lists. wait (e.g. 30s) holds the request open until there are changes.
+func (s *Server) listChanges(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := store.ChangeFilter{ProjectID: q.Get("project_id"), Limit: defaultChangesLimit}
+	hasSince := q.Get("since") != ""
+	if hasSince {
+		n, err := strconv.ParseInt(q.Get("since"), 10, 64)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "since must be a non-negative integer")
+			return
+		}
+		filter.Since = n
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxChangesLimit {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 1000")
+			return
+		}
+		filter.Limit = n
+	}
+	for _, e := range strings.Split(q.Get("entity"), ",") {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		entity, err := models.ParseChangeEntity(e)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		filter.Entities = append(filter.Entities, entity)
+	}
+	var wait time.Duration
+	if v := q.Get("wait"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 || d > maxChangesWait {
+			writeError(w, http.StatusBadRequest, "wait must be a duration of at most 60s")
+			return
+		}
+		wait = d
+	}
+
+	ctx := r.Context()
+	deadline := time.Now().Add(wait)
+	for {
+		signal := s.changeSignal()
+		first, last, err := s.store.ChangeSeqRange(ctx)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !hasSince {
+			writeJSON(w, http.StatusOK, ChangesResponse{Changes: []*models.Change{}, Cursor: last})
+			return
+		}
+		if filter.Since > last || filter.Since < first-1 {
+			writeJSON(w, http.StatusOK, ChangesResponse{Changes: []*models.Change{}, Cursor: last, Reset: true})
+			return
+		}
+
+		page := filter
+		page.Limit++
+		changes, err := s.store.ListChanges(ctx, page)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		resp := ChangesResponse{Changes: changes, Cursor: last}
+		if len(changes) > filter.Limit {
+			resp.Changes, resp.HasMore = changes[:filter.Limit], true
+			resp.Cursor = resp.Changes[filter.Limit-1].Seq
+		} else if n := len(changes); n > 0 {
+			// Changes recorded since the range was read are in the page
+			resp.Cursor = max(last, changes[n-1].Seq)
+		}
+		if resp.Changes == nil {
+			resp.Changes = []*models.Change{}
+		}
+
+		remaining := time.Until(deadline)
+		if len(resp.Changes) > 0 || remaining <= 0 {
+			writeJSON(w, http.StatusOK, resp)
+			return
+		}
+		// Changes the filter skips still advance the cursor while waiting
+		filter.Since = resp.Cursor
+		select {
+		case <-signal:
+		case <-time.After(min(remaining, changesPollInterval)):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/joescharf/pm/internal/federation"
+	"github.com/joescharf/pm/internal/models"
+)
+
+// WithProfiles names the server's own database's profile and adds other pm
+// databases to the read-only combined view (GET /api/v1/combined/...).
+func WithProfiles(name string, profiles []*federation.Profile) Option {
+	return func(s *Server) {
+		s.profileName = name
+		s.profiles = profiles
+	}
+}
+
+// allProfiles is the server's own database followed by the configured
+// profiles.
+func (s *Server) allProfiles() []*federation.Profile {
+	name := s.profileName
+	if name == "" {
+		name = "default"
+	}
+	return append([]*federation.Profile{federation.Local(name, "", s.store)}, s.profiles...)
+}
+
+// combinedStatus summarizes the projects of every profile, namespaced as
+// "<profile>/<name>".
+func (s *Server) combinedStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, federation.Combine(r.Context(), s.allProfiles()))
+}
+
+// combinedIssues lists the issues of every profile, filtered by ?status= and
+// ?priority=.
+func (s *Server) combinedIssues(w http.ResponseWriter, r *http.Request) {
+	var (
+		status   models.IssueStatus
+		priority models.IssuePriority
+		err      error
+	)
+	if v := r.URL.Query().Get("status"); v != "" {
+		if status, err = models.ParseIssueStatus(v); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if v := r.URL.Query().Get("priority"); v != "" {
+		if priority, err = models.ParseIssuePriority(v); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	match := func(i *models.Issue) bool {
+		return (status == "" || i.Status == status) && (priority == "" || i.Priority == priority)
+	}
+	writeJSON(w, http.StatusOK, federation.Issues(r.Context(), s.allProfiles(), match))
+}
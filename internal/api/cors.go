@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// CORSConfig controls which browser origins may call the API cross-origin.
+type CORSConfig struct {
+	// AllowedOrigins are exact origins ("https://pm.example.com"), origins
+	// with any port ("http://localhost:*"), or "*" for every origin.
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+}
+
+// DefaultCORSConfig allows pages served from localhost on any port, such as
+// the UI dev server.
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins: []string{"http://localhost:*", "http://127.0.0.1:*"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+	}
+}
+
+// WithCORS sets the allowed CORS origins, methods and headers. Empty method
+// and header lists keep the defaults.
+func WithCORS(c CORSConfig) Option {
+	return func(s *Server) {
+		d := DefaultCORSConfig()
+		if len(c.AllowedMethods) == 0 {
+			c.AllowedMethods = d.AllowedMethods
+		}
+		if len(c.AllowedHeaders) == 0 {
+			c.AllowedHeaders = d.AllowedHeaders
+		}
+		s.live.cors = c
+	}
+}
+
+// AllowsOrigin reports whether origin may make cross-origin requests.
+func (c CORSConfig) AllowsOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+		// "scheme://host:*" matches the host on any port, or none
+		if base, ok := strings.CutSuffix(allowed, ":*"); ok {
+			if origin == base {
+				return true
+			}
+			if rest, ok := strings.CutPrefix(origin, base+":"); ok && rest != "" && strings.Trim(rest, "0123456789") == "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// corsMiddleware adds CORS headers for allowed origins. Preflight requests
+// from other origins are refused with 403; their other requests get no CORS
+// headers, so browsers will not expose the responses. The config is read per
+// request, so Reconfigure applies to the next one.
+func (s *Server) corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cors := s.settings().cors
+		origin := r.Header.Get("Origin")
+		allowed := origin != "" && cors.AllowsOrigin(origin)
+		if allowed {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(cors.AllowedMethods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(cors.AllowedHeaders, ", "))
+		}
+		if r.Method == http.MethodOptions {
+			if origin != "" && !allowed {
+				writeError(w, http.StatusForbidden, "origin not allowed: "+origin)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
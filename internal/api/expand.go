@@ -0,0 +1,213 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joescharf/pm/internal/agent"
+	"github.com/joescharf/pm/internal/models"
+)
+
+// defaultExpandTimeout bounds each enrichment of one session in
+// GET /api/v1/sessions?expand=.
+const defaultExpandTimeout = 5 * time.Second
+
+// Session list enrichments selectable with ?expand=.
+const (
+	expandGit        = "git"
+	expandCloseCheck = "close_check"
+)
+
+// sessionExpand is the set of enrichments a session list request asked for.
+type sessionExpand struct {
+	git        bool
+	closeCheck bool
+}
+
+// WithSessionExpandTimeout bounds each enrichment of one session in
+// GET /api/v1/sessions?expand= (<= 0 keeps the default of 5s).
+func WithSessionExpandTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		if d > 0 {
+			s.expandTimeout = d
+		}
+	}
+}
+
+// parseSessionExpand parses a comma-separated ?expand= value.
+func parseSessionExpand(v string) (sessionExpand, error) {
+	var e sessionExpand
+	for _, name := range strings.Split(v, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case expandGit:
+			e.git = true
+		case expandCloseCheck:
+			e.closeCheck = true
+		default:
+			return e, fmt.Errorf("unknown expand %q (want %s or %s)", name, expandGit, expandCloseCheck)
+		}
+	}
+	return e, nil
+}
+
+// sessionGit is the live git state of a session's worktree.
+type sessionGit struct {
+	WorktreeExists    bool   `json:"WorktreeExists"`
+	IsDirty           bool   `json:"IsDirty,omitempty"`
+	CurrentBranch     string `json:"CurrentBranch,omitempty"`
+	AheadCount        int    `json:"AheadCount,omitempty"`
+	BehindCount       int    `json:"BehindCount,omitempty"`
+	LastCommitHash    string `json:"LastCommitHash,omitempty"`
+	LastCommitMessage string `json:"LastCommitMessage,omitempty"`
+}
+
+// readSessionGit reads the git state of sess's worktree. A missing worktree
+// is not an error; failing git commands leave their fields empty and are
+// returned joined.
+func (s *Server) readSessionGit(ctx context.Context, sess *models.AgentSession) (sessionGit, error) {
+	var g sessionGit
+	if sess.WorktreePath == "" {
+		return g, nil
+	}
+	if _, err := os.Stat(sess.WorktreePath); err != nil {
+		return g, nil
+	}
+	g.WorktreeExists = true
+
+	var errs []error
+	var err error
+	if g.IsDirty, err = s.git.IsDirty(ctx, sess.WorktreePath); err != nil {
+		errs = append(errs, err)
+	}
+	if g.CurrentBranch, err = s.git.CurrentBranch(ctx, sess.WorktreePath); err != nil {
+		errs = append(errs, err)
+	}
+	if g.AheadCount, g.BehindCount, err = s.git.AheadBehind(ctx, sess.WorktreePath, "main"); err != nil {
+		errs = append(errs, err)
+	}
+	if g.LastCommitHash, err = s.git.LastCommitHash(ctx, sess.WorktreePath); err != nil {
+		errs = append(errs, err)
+	}
+	if g.LastCommitMessage, err = s.git.LastCommitMessage(ctx, sess.WorktreePath); err != nil {
+		errs = append(errs, err)
+	}
+	return g, errors.Join(errs...)
+}
+
+// applySessionGit refreshes sess's stored commit summary from its live git
+// state.
+func applySessionGit(sess *models.AgentSession, g sessionGit) {
+	// Use ahead count as commit count when stored value is stale
+	if g.AheadCount > sess.CommitCount {
+		sess.CommitCount = g.AheadCount
+	}
+	if g.LastCommitHash != "" {
+		sess.LastCommitHash = g.LastCommitHash
+	}
+	if g.LastCommitMessage != "" {
+		sess.LastCommitMessage = g.LastCommitMessage
+	}
+}
+
+// expandSessions adds the requested enrichments to every item, all sessions
+// and enrichments at once (git.max_concurrent still bounds the git
+// subprocesses). An enrichment that fails or takes longer than the expand
+// timeout is reported in the item's ExpandErrors; what it found so far is
+// kept for git, dropped on timeout.
+func (s *Server) expandSessions(ctx context.Context, items []sessionResponse, expand sessionExpand) {
+	if !expand.git && !expand.closeCheck {
+		return
+	}
+	projects := make(map[string]*models.Project)
+	if expand.closeCheck {
+		for _, item := range items {
+			if _, ok := projects[item.ProjectID]; !ok {
+				projects[item.ProjectID], _ = s.store.GetProject(ctx, item.ProjectID)
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range items {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.expandSession(ctx, &items[i], expand, projects[items[i].ProjectID])
+		}()
+	}
+	wg.Wait()
+}
+
+// expandSession enriches one item. Each enrichment works on its own copy of
+// the session, so one abandoned at the timeout cannot touch the response.
+func (s *Server) expandSession(ctx context.Context, item *sessionResponse, expand sessionExpand, project *models.Project) {
+	ctx, cancel := context.WithTimeout(ctx, s.expandTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	fail := func(name string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if item.ExpandErrors == nil {
+			item.ExpandErrors = make(map[string]string)
+		}
+		item.ExpandErrors[name] = err.Error()
+	}
+
+	var wg sync.WaitGroup
+	if expand.git {
+		sess := *item.AgentSession
+		wg.Go(func() {
+			g, err := withDeadline(ctx, func() (sessionGit, error) { return s.readSessionGit(ctx, &sess) })
+			if err == nil || g.WorktreeExists {
+				item.Git = &g
+			}
+			if err != nil {
+				fail(expandGit, err)
+			}
+		})
+	}
+	if expand.closeCheck {
+		sess := *item.AgentSession
+		wg.Go(func() {
+			check, err := withDeadline(ctx, func() (*agent.CloseCheck, error) { return agent.CheckClose(ctx, &sess, project, s.git), nil })
+			if err != nil {
+				fail(expandCloseCheck, err)
+				return
+			}
+			item.CloseCheck = check
+		})
+	}
+	wg.Wait()
+}
+
+// withDeadline runs fn and returns its result, or the context's error once
+// ctx is done; fn then keeps running in the background and its result is
+// discarded.
+func withDeadline[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	type result struct {
+		v   T
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		v, err := fn()
+		done <- result{v, err}
+	}()
+	select {
+	case r := <-done:
+		return r.v, r.err
+	case <-ctx.Done():
+		var zero T
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return zero, errors.New("timed out")
+		}
+		return zero, ctx.Err()
+	}
+}
@@ -0,0 +1,87 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/api"
+	"github.com/joescharf/pm/internal/apitest"
+	"github.com/joescharf/pm/internal/models"
+)
+
+type expandedSession struct {
+	ID  string
+	Git *struct {
+		WorktreeExists bool
+		IsDirty        bool
+		CurrentBranch  string
+		AheadCount     int
+		LastCommitHash string
+	}
+	CloseCheck *struct {
+		Warnings []struct{ Type string }
+	}
+	ExpandErrors map[string]string
+}
+
+func expandSessionFixture(t *testing.T, h *apitest.Harness) *models.AgentSession {
+	t.Helper()
+	p := h.Project("api")
+	worktree := filepath.Join(h.Dir, "worktrees", "api-login")
+	require.NoError(t, os.MkdirAll(worktree, 0o755))
+	sess := &models.AgentSession{ProjectID: p.ID, Branch: "feature/login", WorktreePath: worktree, Status: models.SessionStatusIdle}
+	require.NoError(t, h.Store.CreateAgentSession(context.Background(), sess))
+	return sess
+}
+
+func TestListSessions_Expand(t *testing.T) {
+	h := apitest.New(t)
+	sess := expandSessionFixture(t, h)
+	h.Git.Branch = "feature/login"
+	h.Git.Dirty = true
+	h.Git.Ahead = 2
+
+	var plain []expandedSession
+	h.Get("/api/v1/sessions").JSON(t, &plain)
+	require.Len(t, plain, 1)
+	assert.Nil(t, plain[0].Git, "no enrichment unless asked")
+	assert.Nil(t, plain[0].CloseCheck)
+
+	resp := h.Get("/api/v1/sessions?expand=git,close_check")
+	require.Equal(t, http.StatusOK, resp.Code, string(resp.Body))
+	var got []expandedSession
+	resp.JSON(t, &got)
+	require.Len(t, got, 1)
+	assert.Equal(t, sess.ID, got[0].ID)
+	require.NotNil(t, got[0].Git)
+	assert.True(t, got[0].Git.WorktreeExists)
+	assert.True(t, got[0].Git.IsDirty)
+	assert.Equal(t, "feature/login", got[0].Git.CurrentBranch)
+	assert.Equal(t, 2, got[0].Git.AheadCount)
+	require.NotNil(t, got[0].CloseCheck)
+	assert.NotEmpty(t, got[0].CloseCheck.Warnings, "dirty worktree")
+	assert.Empty(t, got[0].ExpandErrors)
+
+	assert.Equal(t, http.StatusBadRequest, h.Get("/api/v1/sessions?expand=bogus").Code)
+}
+
+func TestListSessions_ExpandTimeout(t *testing.T) {
+	h := apitest.New(t, api.WithSessionExpandTimeout(20*time.Millisecond))
+	expandSessionFixture(t, h)
+	h.Git.Delay = 50 * time.Millisecond
+
+	resp := h.Get("/api/v1/sessions?expand=git")
+	require.Equal(t, http.StatusOK, resp.Code, string(resp.Body))
+	var got []expandedSession
+	resp.JSON(t, &got)
+	require.Len(t, got, 1)
+	assert.Nil(t, got[0].Git, "abandoned enrichments are left out")
+	assert.Equal(t, map[string]string{"git": "timed out"}, got[0].ExpandErrors)
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/joescharf/pm/internal/groom"
+	"github.com/joescharf/pm/internal/models"
+)
+
+// SplitIssueResponse is the response of GET /api/v1/issues/{id}/split: the
+// checklist items and sections of the issue's body, numbered from 1 in the
+// order they appear.
+type SplitIssueResponse struct {
+	IssueID string       `json:"issue_id"`
+	Parts   []groom.Part `json:"parts"`
+}
+
+func (s *Server) listSplitParts(w http.ResponseWriter, r *http.Request) {
+	issue, err := s.store.GetIssue(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "issue not found")
+		return
+	}
+	parts := groom.Parts(issue.Body)
+	if parts == nil {
+		parts = []groom.Part{}
+	}
+	writeJSON(w, http.StatusOK, SplitIssueResponse{IssueID: issue.ID, Parts: parts})
+}
+
+// SplitIssueRequest is the JSON body for POST /api/v1/issues/{id}/split.
+type SplitIssueRequest struct {
+	Parts  []int    `json:"parts"`  // numbers of the parts listed by GET .../split
+	Titles []string `json:"titles"` // further issues, by title
+}
+
+// SplitIssueResult is the response of POST /api/v1/issues/{id}/split.
+type SplitIssueResult struct {
+	IssueID  string          `json:"issue_id"`
+	Children []*models.Issue `json:"children"`
+}
+
+// splitIssue creates an issue for each selected part of an issue's body and
+// each extra title, related to it as split_from.
+func (s *Server) splitIssue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	issue, err := s.store.GetIssue(ctx, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "issue not found")
+		return
+	}
+	var req SplitIssueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	available := groom.Parts(issue.Body)
+	var parts []groom.Part
+	for _, n := range req.Parts {
+		if n < 1 || n > len(available) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid part %d (the issue has %d)", n, len(available)))
+			return
+		}
+		parts = append(parts, available[n-1])
+	}
+	for _, title := range req.Titles {
+		if strings.TrimSpace(title) == "" {
+			writeError(w, http.StatusBadRequest, "titles must not be empty")
+			return
+		}
+		parts = append(parts, groom.Part{Kind: groom.PartItem, Title: title})
+	}
+	if len(parts) == 0 {
+		writeError(w, http.StatusBadRequest, "parts or titles is required")
+		return
+	}
+
+	children, err := groom.Split(ctx, s.store, issue, parts)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, SplitIssueResult{IssueID: issue.ID, Children: children})
+}
+
+// MergeIssuesRequest is the JSON body for POST /api/v1/issues/{id}/merge.
+type MergeIssuesRequest struct {
+	Duplicates []string `json:"duplicates"` // issue IDs to fold into the issue in the path
+}
+
+// mergeIssues folds duplicates into the issue in the path and returns it.
+func (s *Server) mergeIssues(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	target, err := s.store.GetIssue(ctx, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "issue not found")
+		return
+	}
+	var req MergeIssuesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if len(req.Duplicates) == 0 {
+		writeError(w, http.StatusBadRequest, "duplicates is required")
+		return
+	}
+	var dups []*models.Issue
+	for _, id := range req.Duplicates {
+		dup, err := s.store.GetIssue(ctx, id)
+		if err != nil {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("duplicate issue not found: %s", id))
+			return
+		}
+		dups = append(dups, dup)
+	}
+	if err := groom.CheckMerge(target, dups); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := groom.Merge(ctx, s.store, target, dups); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, target)
+}
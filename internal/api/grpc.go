@@ -0,0 +1,472 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/pkg/pmpb"
+)
+
+// GRPCServer implements the pm gRPC API (pkg/pmpb). Each call runs through
+// the REST handler it mirrors, so both APIs share validation, API token
+// scopes and agent logic. A call's "authorization" metadata is passed on as
+// the request's Authorization header.
+type GRPCServer struct {
+	pmpb.UnimplementedPMServer
+	rest http.Handler
+}
+
+// GRPC returns the gRPC implementation of the API served by s. Register it
+// with pmpb.RegisterPMServer.
+func (s *Server) GRPC() *GRPCServer {
+	return &GRPCServer{rest: s.Router()}
+}
+
+// --- Projects ---
+
+func (g *GRPCServer) ListProjects(ctx context.Context, req *pmpb.ListProjectsRequest) (*pmpb.ListProjectsResponse, error) {
+	q := url.Values{}
+	setQuery(q, "group", req.GetGroup())
+	if req.GetArchived() {
+		q.Set("archived", "true")
+	}
+	var projects []*models.Project
+	if err := g.call(ctx, http.MethodGet, "/api/v1/projects", q, nil, &projects); err != nil {
+		return nil, err
+	}
+	resp := &pmpb.ListProjectsResponse{}
+	for _, p := range projects {
+		resp.Projects = append(resp.Projects, projectToPB(p))
+	}
+	return resp, nil
+}
+
+func (g *GRPCServer) GetProject(ctx context.Context, req *pmpb.GetProjectRequest) (*pmpb.Project, error) {
+	var p models.Project
+	if err := g.call(ctx, http.MethodGet, "/api/v1/projects/"+url.PathEscape(req.GetId()), nil, nil, &p); err != nil {
+		return nil, err
+	}
+	return projectToPB(&p), nil
+}
+
+func (g *GRPCServer) CreateProject(ctx context.Context, req *pmpb.CreateProjectRequest) (*pmpb.Project, error) {
+	p := models.Project{
+		Name:        req.GetName(),
+		Path:        req.GetPath(),
+		Description: req.GetDescription(),
+		RepoURL:     req.GetRepoUrl(),
+		Language:    req.GetLanguage(),
+		GroupName:   req.GetGroupName(),
+	}
+	if err := g.call(ctx, http.MethodPost, "/api/v1/projects", nil, p, &p); err != nil {
+		return nil, err
+	}
+	return projectToPB(&p), nil
+}
+
+func (g *GRPCServer) DeleteProject(ctx context.Context, req *pmpb.DeleteProjectRequest) (*emptypb.Empty, error) {
+	if err := g.call(ctx, http.MethodDelete, "/api/v1/projects/"+url.PathEscape(req.GetId()), nil, nil, nil); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// --- Issues ---
+
+func (g *GRPCServer) ListIssues(ctx context.Context, req *pmpb.ListIssuesRequest) (*pmpb.ListIssuesResponse, error) {
+	q := url.Values{}
+	setQuery(q, "project_id", req.GetProjectId())
+	setQuery(q, "status", req.GetStatus())
+	setQuery(q, "priority", req.GetPriority())
+	setQuery(q, "tag", req.GetTag())
+	setQuery(q, "assignee", req.GetAssignee())
+	setQuery(q, "sort", req.GetSort())
+	var issues []*models.Issue
+	if err := g.call(ctx, http.MethodGet, "/api/v1/issues", q, nil, &issues); err != nil {
+		return nil, err
+	}
+	resp := &pmpb.ListIssuesResponse{}
+	for _, i := range issues {
+		resp.Issues = append(resp.Issues, issueToPB(i))
+	}
+	return resp, nil
+}
+
+func (g *GRPCServer) GetIssue(ctx context.Context, req *pmpb.GetIssueRequest) (*pmpb.Issue, error) {
+	var issue models.Issue
+	if err := g.call(ctx, http.MethodGet, "/api/v1/issues/"+url.PathEscape(req.GetId()), nil, nil, &issue); err != nil {
+		return nil, err
+	}
+	return issueToPB(&issue), nil
+}
+
+func (g *GRPCServer) CreateIssue(ctx context.Context, req *pmpb.CreateIssueRequest) (*pmpb.Issue, error) {
+	issue := models.Issue{
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+		Body:        req.GetBody(),
+		AIPrompt:    req.GetAiPrompt(),
+		Assignee:    req.GetAssignee(),
+		Estimate:    req.GetEstimate(),
+	}
+	if err := parseIssueEnums(&issue, req.GetStatus(), req.GetPriority(), req.GetType()); err != nil {
+		return nil, err
+	}
+	path := "/api/v1/projects/" + url.PathEscape(req.GetProjectId()) + "/issues"
+	if err := g.call(ctx, http.MethodPost, path, nil, issue, &issue); err != nil {
+		return nil, err
+	}
+	return issueToPB(&issue), nil
+}
+
+func (g *GRPCServer) UpdateIssue(ctx context.Context, req *pmpb.Issue) (*pmpb.Issue, error) {
+	issue, err := issueFromPB(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.call(ctx, http.MethodPut, "/api/v1/issues/"+url.PathEscape(req.GetId()), nil, issue, issue); err != nil {
+		return nil, err
+	}
+	return issueToPB(issue), nil
+}
+
+func (g *GRPCServer) DeleteIssue(ctx context.Context, req *pmpb.DeleteIssueRequest) (*emptypb.Empty, error) {
+	if err := g.call(ctx, http.MethodDelete, "/api/v1/issues/"+url.PathEscape(req.GetId()), nil, nil, nil); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// --- Sessions ---
+
+// grpcSession decodes the session list and detail responses.
+type grpcSession struct {
+	models.AgentSession
+	ProjectName string
+}
+
+func (g *GRPCServer) ListSessions(ctx context.Context, req *pmpb.ListSessionsRequest) (*pmpb.ListSessionsResponse, error) {
+	q := url.Values{}
+	setQuery(q, "project_id", req.GetProjectId())
+	setQuery(q, "status", strings.Join(req.GetStatuses(), ","))
+	setQuery(q, "label", req.GetLabel())
+	var sessions []grpcSession
+	if err := g.call(ctx, http.MethodGet, "/api/v1/sessions", q, nil, &sessions); err != nil {
+		return nil, err
+	}
+	resp := &pmpb.ListSessionsResponse{}
+	for i := range sessions {
+		resp.Sessions = append(resp.Sessions, sessionToPB(&sessions[i]))
+	}
+	return resp, nil
+}
+
+func (g *GRPCServer) GetSession(ctx context.Context, req *pmpb.GetSessionRequest) (*pmpb.Session, error) {
+	var sess grpcSession
+	if err := g.call(ctx, http.MethodGet, "/api/v1/sessions/"+url.PathEscape(req.GetId()), nil, nil, &sess); err != nil {
+		return nil, err
+	}
+	return sessionToPB(&sess), nil
+}
+
+// --- Agent operations ---
+
+func (g *GRPCServer) LaunchAgent(ctx context.Context, req *pmpb.LaunchAgentRequest) (*pmpb.LaunchAgentResponse, error) {
+	body := LaunchAgentRequest{
+		ProjectID:          req.GetProjectId(),
+		IssueIDs:           req.GetIssueIds(),
+		SnapshotHumanEdits: req.GetSnapshotHumanEdits(),
+		Spawn:              req.GetSpawn(),
+		Sparse:             req.GetSparse(),
+		SparsePaths:        req.GetSparsePaths(),
+		Labels:             req.GetLabels(),
+	}
+	var resp LaunchAgentResponse
+	if err := g.call(ctx, http.MethodPost, "/api/v1/agent/launch", nil, body, &resp); err != nil {
+		return nil, err
+	}
+	return launchToPB(&resp), nil
+}
+
+func (g *GRPCServer) CloseAgent(ctx context.Context, req *pmpb.CloseAgentRequest) (*pmpb.CloseAgentResponse, error) {
+	body := CloseAgentRequest{
+		SessionID:        req.GetSessionId(),
+		Status:           req.GetStatus(),
+		Force:            req.GetForce(),
+		Reason:           req.GetReason(),
+		UnblockCondition: req.GetUnblockCondition(),
+	}
+	var resp CloseAgentResponse
+	if err := g.call(ctx, http.MethodPost, "/api/v1/agent/close", nil, body, &resp); err != nil {
+		return nil, err
+	}
+	out := &pmpb.CloseAgentResponse{SessionId: resp.SessionID, Status: resp.Status}
+	if t, err := time.Parse(time.RFC3339, resp.EndedAt); err == nil {
+		out.EndedAt = timestamppb.New(t)
+	}
+	return out, nil
+}
+
+func (g *GRPCServer) NextAgent(ctx context.Context, req *pmpb.NextAgentRequest) (*pmpb.NextAgentResponse, error) {
+	body := NextAgentRequest{ProjectID: req.GetProjectId(), Assignee: req.GetAssignee(), Spawn: req.GetSpawn()}
+	var resp NextAgentResponse
+	if err := g.call(ctx, http.MethodPost, "/api/v1/agent/next", nil, body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.IssueID == "" {
+		return &pmpb.NextAgentResponse{}, nil
+	}
+	return &pmpb.NextAgentResponse{
+		Launch:      launchToPB(&resp.LaunchAgentResponse),
+		ProjectId:   resp.ProjectID,
+		ProjectName: resp.ProjectName,
+		IssueId:     resp.IssueID,
+		IssueTitle:  resp.IssueTitle,
+		Score:       int32(resp.Score),
+		Reasons:     resp.Reasons,
+	}, nil
+}
+
+// --- Dispatch ---
+
+// call serves a REST request in process and decodes its JSON response into
+// out. An error response becomes a gRPC status; a 204 leaves out untouched.
+func (g *GRPCServer) call(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var rd io.Reader = http.NoBody
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return status.Errorf(codes.Internal, "encode request: %v", err)
+		}
+		rd = bytes.NewReader(b)
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	r, err := http.NewRequestWithContext(ctx, method, path, rd)
+	if err != nil {
+		return status.Errorf(codes.Internal, "build request: %v", err)
+	}
+	r.Header.Set("Content-Type", "application/json")
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("authorization"); len(v) > 0 {
+			r.Header.Set("Authorization", v[0])
+		}
+	}
+
+	w := &responseBuffer{header: http.Header{}, code: http.StatusOK}
+	g.rest.ServeHTTP(w, r)
+	if w.code >= 300 {
+		return status.Error(grpcCode(w.code), errorMessage(w.body.Bytes()))
+	}
+	if out == nil || w.code == http.StatusNoContent {
+		return nil
+	}
+	if err := json.Unmarshal(w.body.Bytes(), out); err != nil {
+		return status.Errorf(codes.Internal, "decode response: %v", err)
+	}
+	return nil
+}
+
+// responseBuffer collects a REST response served for a gRPC call.
+type responseBuffer struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (b *responseBuffer) Header() http.Header         { return b.header }
+func (b *responseBuffer) Write(p []byte) (int, error) { return b.body.Write(p) }
+func (b *responseBuffer) WriteHeader(code int)        { b.code = code }
+
+// grpcCode maps an HTTP error status to the closest gRPC code.
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
+
+// errorMessage extracts the message of a REST error body: the "error" field
+// of a JSON body, or the text of a plain one.
+func errorMessage(body []byte) string {
+	var e struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &e) == nil && e.Error != "" {
+		return e.Error
+	}
+	return strings.TrimSpace(string(body))
+}
+
+func setQuery(q url.Values, key, value string) {
+	if value != "" {
+		q.Set(key, value)
+	}
+}
+
+// --- Conversions ---
+
+func timestampPB(t *time.Time) *timestamppb.Timestamp {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func timeFromPB(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func projectToPB(p *models.Project) *pmpb.Project {
+	return &pmpb.Project{
+		Id:                p.ID,
+		Name:              p.Name,
+		Path:              p.Path,
+		Description:       p.Description,
+		RepoUrl:           p.RepoURL,
+		Language:          p.Language,
+		GroupName:         p.GroupName,
+		MaxInProgress:     int32(p.MaxInProgress),
+		MaxActiveSessions: int32(p.MaxActiveSessions),
+		WipHardBlock:      p.WIPHardBlock,
+		Archived:          p.Archived,
+		CreatedAt:         timestampPB(&p.CreatedAt),
+		UpdatedAt:         timestampPB(&p.UpdatedAt),
+	}
+}
+
+func issueToPB(i *models.Issue) *pmpb.Issue {
+	return &pmpb.Issue{
+		Id:          i.ID,
+		ProjectId:   i.ProjectID,
+		Title:       i.Title,
+		Description: i.Description,
+		Body:        i.Body,
+		AiPrompt:    i.AIPrompt,
+		Status:      string(i.Status),
+		Priority:    string(i.Priority),
+		Type:        string(i.Type),
+		Tags:        i.Tags,
+		GithubIssue: int32(i.GitHubIssue),
+		Assignee:    i.Assignee,
+		Estimate:    i.Estimate,
+		SlaState:    string(i.SLAState),
+		CreatedAt:   timestampPB(&i.CreatedAt),
+		UpdatedAt:   timestampPB(&i.UpdatedAt),
+		ClosedAt:    timestampPB(i.ClosedAt),
+		StartedAt:   timestampPB(i.StartedAt),
+		CompletedAt: timestampPB(i.CompletedAt),
+	}
+}
+
+func issueFromPB(i *pmpb.Issue) (*models.Issue, error) {
+	issue := &models.Issue{
+		ID:          i.GetId(),
+		ProjectID:   i.GetProjectId(),
+		Title:       i.GetTitle(),
+		Description: i.GetDescription(),
+		Body:        i.GetBody(),
+		AIPrompt:    i.GetAiPrompt(),
+		GitHubIssue: int(i.GetGithubIssue()),
+		Assignee:    i.GetAssignee(),
+		Estimate:    i.GetEstimate(),
+		ClosedAt:    timeFromPB(i.GetClosedAt()),
+	}
+	if err := parseIssueEnums(issue, i.GetStatus(), i.GetPriority(), i.GetType()); err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
+
+// parseIssueEnums sets the issue's status, priority and type from a request,
+// failing with InvalidArgument on an unknown value. Empty values are left for
+// the REST API to default.
+func parseIssueEnums(issue *models.Issue, st, priority, typ string) error {
+	var err error
+	if st != "" {
+		if issue.Status, err = models.ParseIssueStatus(st); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if priority != "" {
+		if issue.Priority, err = models.ParseIssuePriority(priority); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if typ != "" {
+		if issue.Type, err = models.ParseIssueType(typ); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	return nil
+}
+
+func sessionToPB(s *grpcSession) *pmpb.Session {
+	return &pmpb.Session{
+		Id:                s.ID,
+		ProjectId:         s.ProjectID,
+		ProjectName:       s.ProjectName,
+		IssueId:           s.IssueID,
+		Branch:            s.Branch,
+		WorktreePath:      s.WorktreePath,
+		Status:            string(s.Status),
+		Outcome:           s.Outcome,
+		OutcomeClass:      string(s.OutcomeClass),
+		CommitCount:       int32(s.CommitCount),
+		LastCommitHash:    s.LastCommitHash,
+		LastCommitMessage: s.LastCommitMessage,
+		ConflictState:     string(s.ConflictState),
+		BlockedReason:     s.BlockedReason,
+		UnblockCondition:  s.UnblockCondition,
+		Labels:            s.LabelList(),
+		StartedAt:         timestampPB(&s.StartedAt),
+		EndedAt:           timestampPB(s.EndedAt),
+		LastActiveAt:      timestampPB(s.LastActiveAt),
+	}
+}
+
+func launchToPB(r *LaunchAgentResponse) *pmpb.LaunchAgentResponse {
+	return &pmpb.LaunchAgentResponse{
+		SessionId:    r.SessionID,
+		Branch:       r.Branch,
+		WorktreePath: r.WorktreePath,
+		Command:      r.Command,
+		Conventions:  r.Conventions,
+		WipWarnings:  r.WIPWarnings,
+		SparsePaths:  r.SparsePaths,
+		SpawnError:   r.SpawnError,
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/joescharf/pm/internal/auth"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/pkg/pmpb"
+)
+
+// grpcClient serves srv's gRPC API over an in-memory connection.
+func grpcClient(t *testing.T, srv *Server) pmpb.PMClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	pmpb.RegisterPMServer(gs, srv.GRPC())
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return pmpb.NewPMClient(conn)
+}
+
+func TestGRPC_ProjectsAndIssues(t *testing.T) {
+	srv, _ := setupTestServer(t)
+	client := grpcClient(t, srv)
+	ctx := context.Background()
+
+	p, err := client.CreateProject(ctx, &pmpb.CreateProjectRequest{Name: "grpc-proj", Path: t.TempDir()})
+	require.NoError(t, err)
+	assert.NotEmpty(t, p.Id)
+	assert.NotNil(t, p.CreatedAt)
+
+	projects, err := client.ListProjects(ctx, &pmpb.ListProjectsRequest{})
+	require.NoError(t, err)
+	require.Len(t, projects.Projects, 1)
+	assert.Equal(t, "grpc-proj", projects.Projects[0].Name)
+
+	issue, err := client.CreateIssue(ctx, &pmpb.CreateIssueRequest{ProjectId: p.Id, Title: "Wire it up", Priority: "high"})
+	require.NoError(t, err)
+	assert.Equal(t, "open", issue.Status)
+	assert.Equal(t, "high", issue.Priority)
+
+	issue.Status = "in_progress"
+	updated, err := client.UpdateIssue(ctx, issue)
+	require.NoError(t, err)
+	assert.Equal(t, "in_progress", updated.Status)
+	assert.Equal(t, "Wire it up", updated.Title)
+
+	list, err := client.ListIssues(ctx, &pmpb.ListIssuesRequest{ProjectId: p.Id, Status: "in_progress"})
+	require.NoError(t, err)
+	require.Len(t, list.Issues, 1)
+	assert.Equal(t, issue.Id, list.Issues[0].Id)
+
+	_, err = client.DeleteIssue(ctx, &pmpb.DeleteIssueRequest{Id: issue.Id})
+	require.NoError(t, err)
+	_, err = client.GetIssue(ctx, &pmpb.GetIssueRequest{Id: issue.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGRPC_Errors(t *testing.T) {
+	srv, s := setupTestServer(t)
+	client := grpcClient(t, srv)
+	ctx := context.Background()
+
+	_, err := client.GetProject(ctx, &pmpb.GetProjectRequest{Id: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	p := &models.Project{Name: "p", Path: "/nonexistent/p"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	_, err = client.CreateIssue(ctx, &pmpb.CreateIssueRequest{ProjectId: p.ID, Title: "x", Status: "bogus"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "invalid issue status")
+	_, err = client.CreateIssue(ctx, &pmpb.CreateIssueRequest{ProjectId: p.ID, Title: "x", Type: "epic"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "invalid issue type")
+
+	issue := &models.Issue{ProjectID: p.ID, Title: "x"}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	_, err = client.UpdateIssue(ctx, &pmpb.Issue{Id: issue.ID, ProjectId: p.ID, Title: "x", Status: "open", Priority: "urgent", Type: "bug"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "invalid issue priority")
+
+	_, err = client.ListSessions(ctx, &pmpb.ListSessionsRequest{Statuses: []string{"bogus"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPC_Token(t *testing.T) {
+	srv, s := setupTestServer(t)
+	client := grpcClient(t, srv)
+	ctx := context.Background()
+
+	mine := &models.Project{Name: "mine", Path: "/nonexistent/mine"}
+	require.NoError(t, s.CreateProject(ctx, mine))
+	token, hash, err := auth.NewToken()
+	require.NoError(t, err)
+	require.NoError(t, s.CreateAPIToken(ctx, &models.APIToken{ProjectID: mine.ID, TokenHash: hash}))
+
+	withToken := func(tok string) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tok)
+	}
+
+	_, err = client.ListProjects(withToken("pm_bogus"), &pmpb.ListProjectsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = client.ListProjects(withToken(token), &pmpb.ListProjectsRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "project-scoped tokens keep their REST scope")
+
+	got, err := client.GetProject(withToken(token), &pmpb.GetProjectRequest{Id: mine.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "mine", got.Name)
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/joescharf/pm/internal/sessions"
+)
+
+// WithOperations shares ops with the server, so syncs and merges started
+// through other servers of this process (MCP) can be listed and canceled too.
+func WithOperations(ops *sessions.Operations) Option {
+	return func(s *Server) {
+		s.sessions.SetOperations(ops)
+	}
+}
+
+// listOperations lists the session's running sync or merge, if any, and its
+// recently finished ones, newest first.
+func (s *Server) listOperations(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := s.store.GetAgentSession(r.Context(), id); err != nil {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.sessions.Operations().List(id))
+}
+
+// cancelOperation cancels a running sync or merge: its git process is killed
+// and the worktree and repository are put back as they were before it
+// started. It responds once that is done, with the canceled operation.
+func (s *Server) cancelOperation(w http.ResponseWriter, r *http.Request) {
+	op, err := s.sessions.Operations().Cancel(r.Context(), r.PathValue("id"), r.PathValue("op"))
+	switch {
+	case errors.Is(err, sessions.ErrOperationNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, sessions.ErrOperationFinished):
+		writeError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	default:
+		writeJSON(w, http.StatusOK, op)
+	}
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/notify"
+)
+
+// WithPush enables the Web Push endpoints the dashboard subscribes through.
+func WithPush(n *notify.PushNotifier) Option {
+	return func(s *Server) {
+		s.push = n
+	}
+}
+
+// pushEnabled writes a 404 and returns false when web push is off.
+func (s *Server) pushEnabled(w http.ResponseWriter) bool {
+	if s.push == nil {
+		writeError(w, http.StatusNotFound, "web push is not enabled")
+		return false
+	}
+	return true
+}
+
+// pushKey returns the VAPID public key browsers subscribe with.
+func (s *Server) pushKey(w http.ResponseWriter, r *http.Request) {
+	if !s.pushEnabled(w) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"public_key": s.push.PublicKey()})
+}
+
+// subscribePush registers a browser's PushSubscription.
+func (s *Server) subscribePush(w http.ResponseWriter, r *http.Request) {
+	if !s.pushEnabled(w) {
+		return
+	}
+	var sub notify.Subscription
+	if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if u, err := url.Parse(sub.Endpoint); err != nil || u.Scheme != "https" || u.Host == "" {
+		writeError(w, http.StatusBadRequest, "endpoint must be an https URL")
+		return
+	}
+	if sub.Keys.P256dh == "" || sub.Keys.Auth == "" {
+		writeError(w, http.StatusBadRequest, "keys.p256dh and keys.auth are required")
+		return
+	}
+
+	ps := &models.PushSubscription{
+		Endpoint:  sub.Endpoint,
+		P256dh:    sub.Keys.P256dh,
+		Auth:      sub.Keys.Auth,
+		UserAgent: r.UserAgent(),
+	}
+	if err := s.store.SavePushSubscription(r.Context(), ps); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, ps)
+}
+
+// unsubscribePush removes a browser's subscription by endpoint.
+func (s *Server) unsubscribePush(w http.ResponseWriter, r *http.Request) {
+	if !s.pushEnabled(w) {
+		return
+	}
+	var req struct {
+		Endpoint string `json:"endpoint"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Endpoint == "" {
+		writeError(w, http.StatusBadRequest, "endpoint is required")
+		return
+	}
+	if err := s.store.DeletePushSubscription(r.Context(), req.Endpoint); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// testPush sends a test notification to every subscribed browser.
+func (s *Server) testPush(w http.ResponseWriter, r *http.Request) {
+	if !s.pushEnabled(w) {
+		return
+	}
+	sent, err := notify.Broadcast(r.Context(), s.store, s.push, notify.PushMessage{
+		Title: "pm notifications are on",
+		Body:  "You will be notified when sessions complete or hit conflicts.",
+		URL:   "/sessions",
+		Tag:   "pm-test",
+	})
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"sent": sent})
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/joescharf/pm/internal/agent"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// defaultReconcileCooldown is how long the background reconciler leaves a
+// session alone after changing it.
+const defaultReconcileCooldown = time.Minute
+
+// WithReconcileCooldown sets how long the background reconciler leaves a
+// session alone after changing it (0 disables the cooldown).
+func WithReconcileCooldown(d time.Duration) Option {
+	return func(s *Server) {
+		if d >= 0 {
+			s.reconciler = agent.NewReconciler(d)
+		}
+	}
+}
+
+// reconcileOptions returns the ReconcileSessions options for this server.
+func (s *Server) reconcileOptions() []agent.ReconcileOption {
+	if s.processDetector == nil {
+		return nil
+	}
+	return []agent.ReconcileOption{agent.WithProcessDetector(s.processDetector), agent.WithFingerprinter(s.git)}
+}
+
+// ReconcileSessions reconciles every session with a worktree to check, across
+// all projects, honoring the reconciler's per-session cooldown. Returns the
+// count of sessions updated.
+func (s *Server) ReconcileSessions(ctx context.Context) (int, error) {
+	sessions, err := s.store.ListAgentSessionsFiltered(ctx, store.SessionListFilter{
+		Statuses: []models.SessionStatus{
+			models.SessionStatusActive, models.SessionStatusIdle,
+			models.SessionStatusBlocked, models.SessionStatusAbandoned,
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return s.reconciler.Reconcile(ctx, s.store, sessions, s.reconcileOptions()...), nil
+}
+
+// RunReconciler reconciles sessions every interval until ctx is done. A
+// session list that finds sessions pending reconciliation triggers an early
+// run, so list requests themselves never write. Errors are passed to onError
+// when it is non-nil.
+func (s *Server) RunReconciler(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.ReconcileSessions(ctx); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.reconcileKick:
+		}
+	}
+}
+
+// requestReconcile asks the background reconciler for an early run. It never
+// blocks, and requests made while one is pending are merged into it.
+func (s *Server) requestReconcile() {
+	select {
+	case s.reconcileKick <- struct{}{}:
+	default:
+	}
+}
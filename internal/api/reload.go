@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/joescharf/pm/internal/boardsync"
+	"github.com/joescharf/pm/internal/intake"
+	"github.com/joescharf/pm/internal/sessions"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// liveSettings are the Server settings that can change while it serves. The
+// options that set them (WithIntake, WithCORS, WithPublicBadges,
+// WithReviewShares, WithCloseCheckEnforcement, WithStaleSessionCriteria,
+// WithBoardSync and WithDiscover) may be passed to Reconfigure.
+type liveSettings struct {
+	intake        intake.Config
+	cors          CORSConfig
+	publicBadges  bool
+	shareTTL      time.Duration
+	shareBaseURL  string
+	enforceClose  bool
+	staleCriteria store.StaleSessionCriteria
+	boardSync     boardsync.Options
+	discover      sessions.DiscoverOptions
+}
+
+// settings returns a copy of the live settings.
+func (s *Server) settings() liveSettings {
+	s.liveMu.RLock()
+	defer s.liveMu.RUnlock()
+	return s.live
+}
+
+// Reconfigure applies opts to a serving Server. Only the options of
+// liveSettings take effect: they are applied to a copy of the live settings,
+// which then replaces them at once, and other options are ignored. Calls must
+// not overlap.
+func (s *Server) Reconfigure(opts ...Option) {
+	next := &Server{live: s.settings()}
+	for _, opt := range opts {
+		opt(next)
+	}
+	s.liveMu.Lock()
+	s.live = next.live
+	s.liveMu.Unlock()
+}
+
+// ReloadReport is the result of reloading the configuration: the settings
+// now in effect and those that only take effect after a restart.
+type ReloadReport struct {
+	Applied    []string  `json:"applied"`
+	Restart    []string  `json:"restart"`
+	ReloadedAt time.Time `json:"reloaded_at"`
+}
+
+// Reloader rereads the configuration and applies what it can.
+type Reloader func(ctx context.Context) (*ReloadReport, error)
+
+// WithReloader enables POST /api/v1/admin/reload.
+func WithReloader(fn Reloader) Option {
+	return func(s *Server) {
+		s.reloader = fn
+	}
+}
+
+// reloadConfig rereads the configuration. A configuration that cannot be
+// read or is invalid answers 422 and leaves the running settings alone.
+func (s *Server) reloadConfig(w http.ResponseWriter, r *http.Request) {
+	if s.reloader == nil {
+		writeError(w, http.StatusNotImplemented, "config reload is not available")
+		return
+	}
+	report, err := s.reloader(r.Context())
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,435 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joescharf/pm/internal/agent"
+	"github.com/joescharf/pm/internal/auth"
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/sla"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// DefaultReviewShareTTL is how long a review link stays open unless its
+// creator says otherwise.
+const DefaultReviewShareTTL = 72 * time.Hour
+
+// maxSharedDiffBytes caps the diff a review link shows.
+const maxSharedDiffBytes = 500000
+
+// WithReviewShares sets how long new review links stay open (<= 0 keeps the
+// default) and the base URL their links start with, for servers reached
+// through a proxy ("" uses the host of the request creating the link, if it
+// is a loopback address).
+func WithReviewShares(ttl time.Duration, baseURL string) Option {
+	return func(s *Server) {
+		if ttl > 0 {
+			s.live.shareTTL = ttl
+		}
+		s.live.shareBaseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// ReviewSharePath is the UI path of the review link with token.
+func ReviewSharePath(token string) string {
+	return "/review/" + token
+}
+
+// isReviewSharePath reports whether path is a route opened by a review link
+// token rather than an API token.
+func isReviewSharePath(urlPath string) bool {
+	if ok, _ := path.Match("/api/v1/review-shares/*", urlPath); ok {
+		return true
+	}
+	ok, _ := path.Match("/api/v1/review-shares/*/review", urlPath)
+	return ok
+}
+
+// CreateReviewShareRequest is the JSON body for
+// POST /api/v1/issues/{id}/review-shares.
+type CreateReviewShareRequest struct {
+	Reviewer  string `json:"reviewer"`
+	ExpiresIn string `json:"expires_in"` // "72h", "3d", "1w"
+}
+
+// ReviewShareResponse describes a review link. Token and URL are only
+// returned when the link is created.
+type ReviewShareResponse struct {
+	ID               string `json:"id"`
+	IssueID          string `json:"issue_id"`
+	Reviewer         string `json:"reviewer"`
+	ExpiresAt        string `json:"expires_at"`
+	ExpiresInSeconds int64  `json:"expires_in_seconds"` // 0 once expired
+	Expired          bool   `json:"expired"`
+	ReviewID         string `json:"review_id,omitempty"`
+	CreatedAt        string `json:"created_at"`
+	Token            string `json:"token,omitempty"`
+	URL              string `json:"url,omitempty"`
+}
+
+func toReviewShareResponse(rs *models.ReviewShare, now time.Time) ReviewShareResponse {
+	return ReviewShareResponse{
+		ID:               rs.ID,
+		IssueID:          rs.IssueID,
+		Reviewer:         rs.Reviewer,
+		ExpiresAt:        rs.ExpiresAt.UTC().Format(time.RFC3339),
+		ExpiresInSeconds: int64(max(rs.ExpiresAt.Sub(now), 0).Seconds()),
+		Expired:          rs.Expired(now),
+		ReviewID:         rs.ReviewID,
+		CreatedAt:        rs.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// createReviewShare makes a review link for the {id} issue. The token is
+// shown only in this response.
+func (s *Server) createReviewShare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	issue, err := s.store.GetIssue(ctx, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "issue not found")
+		return
+	}
+
+	var req CreateReviewShareRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	ttl := s.settings().shareTTL
+	if req.ExpiresIn != "" {
+		d, err := sla.ParseDuration(req.ExpiresIn)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid expires_in %q", req.ExpiresIn))
+			return
+		}
+		ttl = d
+	}
+
+	base, ok := s.shareBase(r)
+	if !ok {
+		writeError(w, http.StatusServiceUnavailable, "set review.share.base_url to create review links on a server reached by a non-local address")
+		return
+	}
+
+	token, hash, err := auth.NewShareToken()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	share := &models.ReviewShare{
+		IssueID:   issue.ID,
+		Reviewer:  strings.TrimSpace(req.Reviewer),
+		TokenHash: hash,
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	}
+	if err := s.store.CreateReviewShare(ctx, share); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := toReviewShareResponse(share, time.Now())
+	resp.Token = token
+	resp.URL = base + ReviewSharePath(token)
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// shareBase is the scheme and host review links start with. Without
+// review.share.base_url only a loopback Host is trusted: any other Host
+// header is the client's to choose, and would put the token in a link to
+// wherever it names. ok is false then.
+func (s *Server) shareBase(r *http.Request) (base string, ok bool) {
+	if base := s.settings().shareBaseURL; base != "" {
+		return base, true
+	}
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return "", false
+	}
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host, true
+}
+
+func (s *Server) listReviewShares(w http.ResponseWriter, r *http.Request) {
+	shares, err := s.store.ListReviewShares(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	now := time.Now()
+	resp := make([]ReviewShareResponse, 0, len(shares))
+	for _, rs := range shares {
+		resp = append(resp, toReviewShareResponse(rs, now))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// deleteReviewShare revokes one of the {id} issue's review links.
+func (s *Server) deleteReviewShare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	shares, err := s.store.ListReviewShares(ctx, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	idx := slices.IndexFunc(shares, func(rs *models.ReviewShare) bool { return rs.ID == r.PathValue("shareId") })
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "review share not found")
+		return
+	}
+	if err := s.store.DeleteReviewShare(ctx, shares[idx].ID); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// openReviewShare resolves the {token} of a review link, answering 404 for
+// unknown or revoked links and 410 for expired ones.
+func (s *Server) openReviewShare(w http.ResponseWriter, r *http.Request) (*models.ReviewShare, *models.Issue, bool) {
+	ctx := r.Context()
+	share, err := s.store.GetReviewShareByHash(ctx, auth.HashToken(r.PathValue("token")))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "review link not found")
+		return nil, nil, false
+	}
+	if share.Expired(time.Now()) {
+		writeError(w, http.StatusGone, "review link expired")
+		return nil, nil, false
+	}
+	issue, err := s.store.GetIssue(ctx, share.IssueID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "issue not found")
+		return nil, nil, false
+	}
+	return share, issue, true
+}
+
+// SharedReviewIssue is the part of an issue a review link shows.
+type SharedReviewIssue struct {
+	ID                 string   `json:"id"`
+	Title              string   `json:"title"`
+	Description        string   `json:"description"`
+	Body               string   `json:"body"`
+	Type               string   `json:"type"`
+	Priority           string   `json:"priority"`
+	Status             string   `json:"status"`
+	AcceptanceCriteria []string `json:"acceptance_criteria"`
+}
+
+// SharedReview is what a review link shows: the issue's requirements and the
+// diff of its branch, like pm_prepare_review, without local paths.
+type SharedReview struct {
+	Reviewer         string                `json:"reviewer"`
+	ExpiresAt        string                `json:"expires_at"`
+	ExpiresInSeconds int64                 `json:"expires_in_seconds"`
+	Project          string                `json:"project"`
+	Issue            SharedReviewIssue     `json:"issue"`
+	BaseRef          string                `json:"base_ref"`
+	HeadRef          string                `json:"head_ref"`
+	Diff             string                `json:"diff"`
+	DiffTruncated    bool                  `json:"diff_truncated"`
+	DiffStats        string                `json:"diff_stats"`
+	FileDiffs        []git.FileDiff        `json:"file_diffs"`
+	Reviews          []*models.IssueReview `json:"reviews"`
+	Submitted        *models.IssueReview   `json:"submitted,omitempty"` // this link's verdict, once in
+}
+
+// getSharedReview serves the review context of a review link.
+func (s *Server) getSharedReview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	share, issue, ok := s.openReviewShare(w, r)
+	if !ok {
+		return
+	}
+	project, err := s.store.GetProject(ctx, issue.ProjectID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+
+	out := SharedReview{
+		Reviewer:         share.Reviewer,
+		ExpiresAt:        share.ExpiresAt.UTC().Format(time.RFC3339),
+		ExpiresInSeconds: int64(time.Until(share.ExpiresAt).Seconds()),
+		Project:          project.Name,
+		Issue: SharedReviewIssue{
+			ID:                 issue.ID,
+			Title:              issue.Title,
+			Description:        issue.Description,
+			Body:               issue.Body,
+			Type:               string(issue.Type),
+			Priority:           string(issue.Priority),
+			Status:             string(issue.Status),
+			AcceptanceCriteria: issue.AcceptanceCriteria,
+		},
+		BaseRef: "main",
+		HeadRef: "HEAD",
+		Reviews: []*models.IssueReview{},
+	}
+	if sess := s.reviewSession(ctx, issue); sess != nil && sess.Branch != "" {
+		out.HeadRef = sess.Branch
+	}
+	if project.Path != "" {
+		diff, _ := s.git.Diff(ctx, project.Path, out.BaseRef, out.HeadRef)
+		out.DiffStats, _ = s.git.DiffStat(ctx, project.Path, out.BaseRef, out.HeadRef)
+		out.FileDiffs = git.SplitDiff(diff)
+		out.Diff, out.DiffTruncated = git.TruncateDiff(diff, maxSharedDiffBytes)
+	}
+
+	reviews, err := s.store.ListIssueReviews(ctx, issue.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, rv := range reviews {
+		if rv.ID == share.ReviewID {
+			out.Submitted = rv
+		}
+		out.Reviews = append(out.Reviews, rv)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// reviewSession is the session an issue's work is reviewed on: its open
+// session, or else its latest one.
+func (s *Server) reviewSession(ctx context.Context, issue *models.Issue) *models.AgentSession {
+	sessions, err := s.store.ListAgentSessionsFiltered(ctx, store.SessionListFilter{IssueID: issue.ID})
+	if err != nil {
+		return nil
+	}
+	var latest *models.AgentSession
+	for _, sess := range sessions {
+		if sess.Status.IsOpen() {
+			return sess
+		}
+		if latest == nil {
+			latest = sess
+		}
+	}
+	return latest
+}
+
+// SharedReviewRequest is the JSON body for
+// POST /api/v1/review-shares/{token}/review.
+type SharedReviewRequest struct {
+	Verdict        string   `json:"verdict"`
+	Summary        string   `json:"summary"`
+	Reviewer       string   `json:"reviewer"` // used when the link names no reviewer
+	FailureReasons []string `json:"failure_reasons"`
+	Criteria       []struct {
+		Criterion string `json:"criterion"`
+		Passed    bool   `json:"passed"`
+		Note      string `json:"note"`
+	} `json:"criteria"`
+}
+
+// submitSharedReview records the verdict of a review link's reviewer. Each
+// link takes one verdict; criteria must be the issue's acceptance criteria,
+// and failed ones are added to the failure reasons, as with pm_save_review.
+func (s *Server) submitSharedReview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	share, issue, ok := s.openReviewShare(w, r)
+	if !ok {
+		return
+	}
+
+	var req SharedReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	verdict, err := models.ParseReviewVerdict(req.Verdict)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if strings.TrimSpace(req.Summary) == "" {
+		writeError(w, http.StatusBadRequest, "summary is required")
+		return
+	}
+	reviewer := share.Reviewer
+	if reviewer == "" {
+		reviewer = strings.TrimSpace(req.Reviewer)
+	}
+
+	review := &models.IssueReview{
+		IssueID:           issue.ID,
+		Reviewer:          reviewer,
+		Verdict:           verdict,
+		Summary:           strings.TrimSpace(req.Summary),
+		CodeQuality:       "skip",
+		RequirementsMatch: "skip",
+		TestCoverage:      "skip",
+		UIUX:              "na",
+		ReviewedAt:        time.Now().UTC(),
+	}
+	for _, reason := range req.FailureReasons {
+		if reason = strings.TrimSpace(reason); reason != "" {
+			review.FailureReasons = append(review.FailureReasons, reason)
+		}
+	}
+	for _, c := range req.Criteria {
+		if !slices.Contains(issue.AcceptanceCriteria, c.Criterion) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("not an acceptance criterion of the issue: %q", c.Criterion))
+			return
+		}
+		if !c.Passed {
+			if verdict == models.ReviewVerdictPass {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("verdict is pass but acceptance criterion failed: %s", c.Criterion))
+				return
+			}
+			reason := "Acceptance criterion not met: " + c.Criterion
+			if c.Note != "" {
+				reason += " (" + c.Note + ")"
+			}
+			review.FailureReasons = append(review.FailureReasons, reason)
+		}
+		review.Criteria = append(review.Criteria, models.CriterionResult{Criterion: c.Criterion, Passed: c.Passed, Note: c.Note})
+	}
+	if sess := s.reviewSession(ctx, issue); sess != nil {
+		review.SessionID = sess.ID
+	}
+
+	if err := s.store.SubmitReviewShare(ctx, share.ID, review); err != nil {
+		if errors.Is(err, store.ErrReviewShareUsed) {
+			writeError(w, http.StatusConflict, "a verdict was already submitted through this link")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if _, err := agent.MarkReviewFailed(ctx, s.store, review); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// Transition issue status under the project's cascade rules, as for
+	// reviews saved with pm_save_review
+	event := models.CascadeReviewFail
+	if verdict == models.ReviewVerdictPass {
+		event = models.CascadeReviewPass
+	}
+	if _, err := agent.CascadeIssue(ctx, s.store, issue, event); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("review saved but issue update failed: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, review)
+}
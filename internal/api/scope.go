@@ -0,0 +1,245 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+
+	"github.com/joescharf/pm/internal/auth"
+)
+
+// errOutOfScope is returned by access rules when a project-scoped token
+// reaches for another project's data.
+var errOutOfScope = errors.New("API token is not scoped to this project")
+
+// accessRule decides whether a request authenticated with a project-scoped
+// token may reach a handler. Routes registered with a nil rule are closed to
+// scoped tokens; unscoped requests skip rules entirely.
+type accessRule func(s *Server, r *http.Request, sc auth.Scope) error
+
+// WithRequireAuth makes every request except /healthz carry a valid API
+// token, for servers exposed beyond localhost.
+func WithRequireAuth(require bool) Option {
+	return func(s *Server) {
+		s.requireAuth = require
+	}
+}
+
+// authenticate resolves a bearer token to its project scope.
+//
+// Scoping is opt-in per request: a request without an Authorization header
+// keeps full access to every project unless the server requires auth, so a
+// project-scoped token only sandboxes a client that cannot drop it when
+// WithRequireAuth is on. Health checks, review links and, with
+// WithPublicBadges, badges are always open.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			if s.requireAuth && r.URL.Path != "/healthz" && !isReviewSharePath(r.URL.Path) && !(s.settings().publicBadges && isBadgePath(r.URL.Path)) {
+				writeError(w, http.StatusUnauthorized, "API token required")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		sc, err := auth.Authenticate(r.Context(), s.store, auth.BearerToken(header))
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithScope(r.Context(), sc)))
+	})
+}
+
+// scoped guards h with rule for project-scoped requests.
+func (s *Server) scoped(rule accessRule, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sc, ok := auth.FromContext(r.Context())
+		if !ok {
+			h(w, r)
+			return
+		}
+		if rule == nil {
+			writeError(w, http.StatusForbidden, "not available to project-scoped API tokens")
+			return
+		}
+		if err := rule(s, r, sc); err != nil {
+			if errors.Is(err, errOutOfScope) {
+				writeError(w, http.StatusForbidden, err.Error())
+				return
+			}
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h(w, r)
+	}
+}
+
+// anyScope allows every project-scoped token, for routes that expose no
+// project data.
+func anyScope(*Server, *http.Request, auth.Scope) error {
+	return nil
+}
+
+// projectPath allows routes whose {id} is the scoped project.
+func projectPath(_ *Server, r *http.Request, sc auth.Scope) error {
+	if !sc.Allows(r.PathValue("id")) {
+		return errOutOfScope
+	}
+	return nil
+}
+
+// issuePath allows routes whose {id} is an issue in the scoped project.
+func issuePath(s *Server, r *http.Request, sc auth.Scope) error {
+	return s.checkIssue(r, sc, r.PathValue("id"))
+}
+
+// sessionPath allows routes whose {id} is a session in the scoped project.
+func sessionPath(s *Server, r *http.Request, sc auth.Scope) error {
+	return s.checkSession(r, sc, r.PathValue("id"))
+}
+
+// projectQuery pins a list endpoint's project_id filter to the scoped project.
+func projectQuery(_ *Server, r *http.Request, sc auth.Scope) error {
+	q := r.URL.Query()
+	if id := q.Get("project_id"); id != "" && !sc.Allows(id) {
+		return errOutOfScope
+	}
+	q.Set("project_id", sc.ProjectID)
+	r.URL.RawQuery = q.Encode()
+	return nil
+}
+
+// relationBody allows linking an issue in the scoped project to another one
+// in the same project.
+func relationBody(s *Server, r *http.Request, sc auth.Scope) error {
+	if err := issuePath(s, r, sc); err != nil {
+		return err
+	}
+	var req CreateIssueRelationRequest
+	if err := peekJSON(r, &req); err != nil || req.TargetIssueID == "" {
+		return nil // the handler reports the bad request
+	}
+	return s.checkIssue(r, sc, req.TargetIssueID)
+}
+
+// mergeBody allows merging the scoped project's issues into one another.
+func mergeBody(s *Server, r *http.Request, sc auth.Scope) error {
+	if err := issuePath(s, r, sc); err != nil {
+		return err
+	}
+	var req MergeIssuesRequest
+	if err := peekJSON(r, &req); err != nil {
+		return nil
+	}
+	for _, id := range req.Duplicates {
+		if err := s.checkIssue(r, sc, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// launchBody allows launching agents for the scoped project's issues.
+func launchBody(s *Server, r *http.Request, sc auth.Scope) error {
+	var req LaunchAgentRequest
+	if err := peekJSON(r, &req); err != nil {
+		return nil
+	}
+	if req.ProjectID != "" && !sc.Allows(req.ProjectID) {
+		return errOutOfScope
+	}
+	for _, id := range req.IssueIDs {
+		if err := s.checkIssue(r, sc, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextBody allows pulling work from the scoped project. The handler limits a
+// request without a project_id to it.
+func nextBody(_ *Server, r *http.Request, sc auth.Scope) error {
+	var req NextAgentRequest
+	if err := peekJSON(r, &req); err != nil {
+		return nil
+	}
+	if req.ProjectID != "" && !sc.Allows(req.ProjectID) {
+		return errOutOfScope
+	}
+	return nil
+}
+
+// sessionBody allows agent requests naming a session_id in the scoped project.
+func sessionBody(s *Server, r *http.Request, sc auth.Scope) error {
+	var req struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := peekJSON(r, &req); err != nil || req.SessionID == "" {
+		return nil
+	}
+	return s.checkSession(r, sc, req.SessionID)
+}
+
+// heartbeatBody allows heartbeats for sessions in the scoped project, named
+// by session_id or worktree_path.
+func heartbeatBody(s *Server, r *http.Request, sc auth.Scope) error {
+	var req HeartbeatRequest
+	if err := peekJSON(r, &req); err != nil {
+		return nil
+	}
+	if req.SessionID != "" {
+		return s.checkSession(r, sc, req.SessionID)
+	}
+	if req.WorktreePath == "" {
+		return nil
+	}
+	sess, err := s.store.GetAgentSessionByWorktreePath(r.Context(), filepath.Clean(req.WorktreePath))
+	if err != nil {
+		return err
+	}
+	if !sc.Allows(sess.ProjectID) {
+		return errOutOfScope
+	}
+	return nil
+}
+
+func (s *Server) checkIssue(r *http.Request, sc auth.Scope, id string) error {
+	issue, err := s.store.GetIssue(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	if !sc.Allows(issue.ProjectID) {
+		return errOutOfScope
+	}
+	return nil
+}
+
+func (s *Server) checkSession(r *http.Request, sc auth.Scope, id string) error {
+	sess, err := s.store.GetAgentSession(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	if !sc.Allows(sess.ProjectID) {
+		return errOutOfScope
+	}
+	return nil
+}
+
+// peekJSON decodes the request body into v and restores it for the handler.
+func peekJSON(r *http.Request, v any) error {
+	if r.Body == nil {
+		return io.EOF
+	}
+	data, err := io.ReadAll(r.Body)
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -17,9 +18,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/joescharf/pm/internal/agent"
+	"github.com/joescharf/pm/internal/auth"
 	"github.com/joescharf/pm/internal/git"
 	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/sessions"
 	"github.com/joescharf/pm/internal/store"
+	"github.com/joescharf/pm/internal/terminal"
 	"github.com/joescharf/pm/internal/wt"
 	"github.com/joescharf/wt/pkg/gitops"
 	"github.com/joescharf/wt/pkg/lifecycle"
@@ -33,6 +38,7 @@ import (
 // window management, Claude trust, and wt state — making it safe for tests.
 type testWTClient struct {
 	createCalls []struct{ repo, branch string }
+	createOpts  []wt.CreateOptions
 }
 
 func (c *testWTClient) Create(repoPath, branch string) error {
@@ -66,6 +72,20 @@ func (c *testWTClient) Create(repoPath, branch string) error {
 	return nil
 }
 
+func (c *testWTClient) CreateHeadless(repoPath, branch string) error {
+	return c.Create(repoPath, branch)
+}
+
+// CreateWith records opts and creates a full worktree; it reports the
+// requested sparse paths as if git applied them.
+func (c *testWTClient) CreateWith(repoPath, branch string, opts wt.CreateOptions) ([]string, error) {
+	c.createOpts = append(c.createOpts, opts)
+	if err := c.Create(repoPath, branch); err != nil {
+		return nil, err
+	}
+	return opts.SparsePaths, nil
+}
+
 func (c *testWTClient) List(repoPath string) ([]wt.WorktreeInfo, error) {
 	out, err := exec.Command("git", "-C", repoPath, "worktree", "list", "--porcelain").CombinedOutput()
 	if err != nil {
@@ -103,6 +123,8 @@ func (c *testWTClient) Delete(repoPath, branch string) error {
 	return nil
 }
 
+func (c *testWTClient) TerminalID(worktreePath string) string { return "" }
+
 func (c *testWTClient) Lifecycle() *lifecycle.Manager { return nil }
 func (c *testWTClient) LifecycleForRepo(repoPath string) *lifecycle.Manager {
 	git := &testGitopsClient{repoPath: repoPath}
@@ -434,9 +456,8 @@ func TestSessionLifecycle_E2E(t *testing.T) {
 	// -----------------------------------------------------------------------
 	w = doJSON(t, router, "DELETE", "/api/v1/sessions/cleanup", nil)
 	require.Equal(t, http.StatusOK, w.Code)
-	var cleanupResp map[string]int64
-	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &cleanupResp))
-	assert.Equal(t, int64(1), cleanupResp["deleted"])
+	cleanupResp := decodeJSON[cleanupResponse](t, w)
+	assert.Equal(t, int64(1), cleanupResp.Deleted)
 
 	_, err = s.GetAgentSession(ctx, sessionID)
 	assert.Error(t, err, "session should be deleted after cleanup")
@@ -479,6 +500,298 @@ func TestLaunchAgent_ResumesIdleSession(t *testing.T) {
 	assert.Len(t, sessions, 1)
 }
 
+// TestLaunchAgent_BranchReserved verifies that an issue whose title maps to
+// the branch of an active session is refused with a conflict naming it,
+// without creating a second worktree or session.
+func TestLaunchAgent_BranchReserved(t *testing.T) {
+	srv, s, wtc, repoPath := setupE2EServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	proj := createProject(t, s, "reserve-test", repoPath)
+	first := createIssue(t, s, proj.ID, "Fix login")
+	second := createIssue(t, s, proj.ID, "Fix login!")
+
+	w := doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+		"project_id": proj.ID,
+		"issue_ids":  []string{first.ID},
+	})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	launched := decodeJSON[LaunchAgentResponse](t, w)
+	created := len(wtc.createCalls)
+
+	w = doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+		"project_id": proj.ID,
+		"issue_ids":  []string{second.ID},
+	})
+	require.Equal(t, http.StatusConflict, w.Code, "body: %s", w.Body.String())
+	assert.Contains(t, w.Body.String(), launched.SessionID[:12])
+	assert.Contains(t, w.Body.String(), launched.Branch)
+	assert.Len(t, wtc.createCalls, created, "no second worktree")
+
+	sessions, _ := s.ListAgentSessions(ctx, proj.ID, 50)
+	assert.Len(t, sessions, 1)
+	issue, _ := s.GetIssue(ctx, second.ID)
+	assert.Equal(t, models.IssueStatusOpen, issue.Status)
+
+	// A session recorded behind the check's back is refused by the store.
+	err := s.CreateAgentSession(ctx, &models.AgentSession{ProjectID: proj.ID, Branch: launched.Branch, Status: models.SessionStatusActive})
+	require.ErrorIs(t, err, store.ErrBranchReserved)
+	assert.Contains(t, err.Error(), launched.SessionID)
+}
+
+func TestLaunchAgent_IssueLocked(t *testing.T) {
+	srv, s, wtc, repoPath := setupE2EServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	proj := createProject(t, s, "lock-test", repoPath)
+	held := createIssue(t, s, proj.ID, "Fix login")
+	other := createIssue(t, s, proj.ID, "Add logout")
+
+	w := doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+		"project_id": proj.ID,
+		"issue_ids":  []string{held.ID},
+	})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	launched := decodeJSON[LaunchAgentResponse](t, w)
+	created := len(wtc.createCalls)
+
+	// A launch on another branch that includes the held issue is refused
+	w = doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+		"project_id": proj.ID,
+		"issue_ids":  []string{other.ID, held.ID},
+	})
+	require.Equal(t, http.StatusConflict, w.Code, "body: %s", w.Body.String())
+	resp := decodeJSON[IssueLockedResponse](t, w)
+	assert.Equal(t, held.ID, resp.Lock.IssueID)
+	assert.Equal(t, launched.SessionID, resp.Lock.SessionID)
+	assert.Equal(t, launched.Branch, resp.Lock.Branch)
+	require.NotNil(t, resp.Lock.ExpiresAt)
+	assert.Contains(t, resp.Error, "locked")
+	assert.Len(t, wtc.createCalls, created, "no second worktree")
+
+	// Closing the holder releases the lock
+	w = doJSON(t, router, "POST", "/api/v1/agent/close", map[string]any{"session_id": launched.SessionID, "status": "idle"})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	sess, err := s.GetAgentSession(ctx, launched.SessionID)
+	require.NoError(t, err)
+	require.Equal(t, models.SessionStatusIdle, sess.Status)
+	w = doJSON(t, router, "POST", "/api/v1/agent/launch?plan=true", map[string]any{
+		"project_id": proj.ID,
+		"issue_ids":  []string{other.ID, held.ID},
+	})
+	assert.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+
+	// A multi-issue launch locks every issue, not just the first
+	w = doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+		"project_id": proj.ID,
+		"issue_ids":  []string{other.ID, held.ID},
+	})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	launched2 := decodeJSON[LaunchAgentResponse](t, w)
+	w = doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+		"project_id": proj.ID,
+		"issue_ids":  []string{held.ID},
+	})
+	require.Equal(t, http.StatusConflict, w.Code, "body: %s", w.Body.String())
+	resp = decodeJSON[IssueLockedResponse](t, w)
+	assert.Equal(t, held.ID, resp.Lock.IssueID)
+	assert.Equal(t, launched2.SessionID, resp.Lock.SessionID)
+
+	// The store refuses a session recorded behind the check's back
+	err = s.CreateAgentSession(ctx, &models.AgentSession{ProjectID: proj.ID, IssueID: held.ID, Branch: "feature/sneaky", Status: models.SessionStatusActive})
+	require.ErrorIs(t, err, store.ErrIssueLocked)
+	assert.Contains(t, err.Error(), launched2.SessionID)
+}
+
+// TestLaunchAgent_Plan verifies that ?plan=true reports what a launch would
+// do without creating, resuming or purging anything.
+func TestLaunchAgent_Plan(t *testing.T) {
+	srv, s, wtc, repoPath := setupE2EServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	proj := createProject(t, s, "plan-test", repoPath)
+	issue := createIssue(t, s, proj.ID, "Add user login")
+	branch := "feature/add-user-login"
+	wtDir := filepath.Join(repoPath+".worktrees", "add-user-login")
+
+	stale := createSession(t, s, proj.ID, issue.ID, branch, wtDir, models.SessionStatusAbandoned)
+	ended := stale.StartedAt.Add(5 * time.Second)
+	stale.EndedAt = &ended
+	require.NoError(t, s.UpdateAgentSession(ctx, stale))
+
+	w := doJSON(t, router, "POST", "/api/v1/agent/launch?plan=true", map[string]any{
+		"project_id": proj.ID,
+		"issue_ids":  []string{issue.ID},
+	})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	plan := decodeJSON[LaunchPlanResponse](t, w)
+	assert.Equal(t, branch, plan.Branch)
+	assert.Equal(t, wtDir, plan.WorktreePath)
+	assert.False(t, plan.Resume)
+	assert.Equal(t, []string{stale.ID}, plan.PurgeSessionIDs)
+	assert.Equal(t, []string{issue.ID}, plan.StartIssueIDs)
+	assert.True(t, strings.HasPrefix(plan.Command, "cd "+wtDir+" && claude "), plan.Command)
+
+	assert.Empty(t, wtc.createOpts, "no worktree created")
+	assert.NoDirExists(t, wtDir)
+	sessions, _ := s.ListAgentSessions(ctx, proj.ID, 0)
+	assert.Len(t, sessions, 1, "stale session kept")
+	got, _ := s.GetIssue(ctx, issue.ID)
+	assert.Equal(t, models.IssueStatusOpen, got.Status)
+
+	idle := createSession(t, s, proj.ID, issue.ID, branch, "/tmp/elsewhere/add-user-login", models.SessionStatusIdle)
+	w = doJSON(t, router, "POST", "/api/v1/agent/launch?plan=true", map[string]any{
+		"project_id": proj.ID,
+		"issue_ids":  []string{issue.ID},
+	})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	plan = decodeJSON[LaunchPlanResponse](t, w)
+	assert.True(t, plan.Resume)
+	assert.Equal(t, idle.ID, plan.ResumeSessionID)
+	assert.Equal(t, "/tmp/elsewhere/add-user-login", plan.WorktreePath)
+	assert.Empty(t, plan.PurgeSessionIDs)
+	assert.Empty(t, plan.StartIssueIDs)
+
+	sess, _ := s.GetAgentSession(ctx, idle.ID)
+	assert.Equal(t, models.SessionStatusIdle, sess.Status, "not resumed")
+}
+
+// TestLaunchAgent_CoEditGuard verifies that edits made in an idle session's
+// worktree are reported on resume and can be snapshotted as a commit.
+func TestLaunchAgent_CoEditGuard(t *testing.T) {
+	srv, s, _, repoPath := setupE2EServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	proj := createProject(t, s, "coedit-test", repoPath)
+	issue := createIssue(t, s, proj.ID, "Add user login")
+
+	branch := "feature/add-user-login"
+	wtDir := filepath.Join(repoPath+".worktrees", "add-user-login")
+	out, err := exec.Command("git", "-C", repoPath, "worktree", "add", "-b", branch, wtDir, "main").CombinedOutput()
+	require.NoError(t, err, "git worktree add: %s", string(out))
+
+	sess := createSession(t, s, proj.ID, issue.ID, branch, wtDir, models.SessionStatusActive)
+
+	// Closing to idle records the worktree fingerprint
+	w := doJSON(t, router, "POST", "/api/v1/agent/close", map[string]any{"session_id": sess.ID})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	dbSess, _ := s.GetAgentSession(ctx, sess.ID)
+	require.NotEmpty(t, dbSess.WorktreeFingerprint)
+
+	// A human edits a file while the session is idle
+	require.NoError(t, os.WriteFile(filepath.Join(wtDir, "human.txt"), []byte("manual fix\n"), 0o644))
+
+	w = doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+		"project_id":           proj.ID,
+		"issue_ids":            []string{issue.ID},
+		"snapshot_human_edits": true,
+	})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	resp := decodeJSON[LaunchAgentResponse](t, w)
+	require.NotNil(t, resp.CoEdit)
+	assert.True(t, resp.CoEdit.Changed)
+	assert.NotEmpty(t, resp.CoEdit.SnapshotCommit)
+
+	dirty, err := git.NewClient().IsDirty(ctx, wtDir)
+	require.NoError(t, err)
+	assert.False(t, dirty, "human edits should be committed")
+
+	dbSess, _ = s.GetAgentSession(ctx, sess.ID)
+	assert.Equal(t, models.SessionStatusActive, dbSess.Status)
+	assert.Empty(t, dbSess.WorktreeFingerprint)
+
+	// Closing and resuming without edits reports nothing
+	w = doJSON(t, router, "POST", "/api/v1/agent/close", map[string]any{"session_id": sess.ID})
+	require.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(t, router, "POST", "/api/v1/agent/resume", map[string]any{"session_id": sess.ID})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	assert.Nil(t, decodeJSON[LaunchAgentResponse](t, w).CoEdit)
+}
+
+// fakeSpawner records terminal requests instead of opening a terminal.
+type fakeSpawner struct {
+	dirs, names, commands []string
+	focused, closed       []string
+	err                   error
+}
+
+func (f *fakeSpawner) Focus(id string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.focused = append(f.focused, id)
+	return nil
+}
+
+func (f *fakeSpawner) Close(id string) error {
+	f.closed = append(f.closed, id)
+	return nil
+}
+
+func (f *fakeSpawner) Spawn(dir, name, command string) (*terminal.Result, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.dirs = append(f.dirs, dir)
+	f.names = append(f.names, name)
+	f.commands = append(f.commands, command)
+	return &terminal.Result{Backend: terminal.BackendTmux, ID: fmt.Sprintf("pm-term-%d", len(f.dirs))}, nil
+}
+
+func TestLaunchAgent_Spawn(t *testing.T) {
+	srv, s, _, repoPath := setupE2EServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	proj := createProject(t, s, "spawn-test", repoPath)
+	issue := createIssue(t, s, proj.ID, "Add dark mode")
+
+	// Without a configured backend spawn is rejected
+	w := doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+		"project_id": proj.ID, "issue_ids": []string{issue.ID}, "spawn": true,
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "agent.terminal")
+
+	sp := &fakeSpawner{}
+	WithTerminalSpawner(sp)(srv)
+
+	w = doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+		"project_id": proj.ID, "issue_ids": []string{issue.ID}, "spawn": true,
+	})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	resp := decodeJSON[LaunchAgentResponse](t, w)
+	require.NotNil(t, resp.Terminal)
+	assert.Equal(t, "pm-term-1", resp.Terminal.ID)
+	assert.Empty(t, resp.SpawnError)
+
+	require.Len(t, sp.dirs, 1)
+	assert.Equal(t, resp.WorktreePath, sp.dirs[0])
+	assert.Equal(t, "pm:spawn-test:add-dark-mode", sp.names[0])
+	assert.True(t, strings.HasPrefix(sp.commands[0], `claude "Use pm MCP tools`))
+	assert.True(t, strings.HasSuffix(resp.Command, sp.commands[0]), "spawned command matches the returned one")
+
+	dbSess, err := s.GetAgentSession(ctx, resp.SessionID)
+	require.NoError(t, err)
+	assert.Equal(t, terminal.BackendTmux, dbSess.TerminalBackend)
+	assert.Equal(t, "pm-term-1", dbSess.TerminalID)
+
+	// A spawn failure still launches the session and reports the error
+	sp.err = errors.New("tmux: no server running")
+	w = doJSON(t, router, "POST", "/api/v1/agent/close", map[string]any{"session_id": resp.SessionID})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	w = doJSON(t, router, "POST", "/api/v1/agent/resume", map[string]any{"session_id": resp.SessionID, "spawn": true})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	resumed := decodeJSON[LaunchAgentResponse](t, w)
+	assert.Nil(t, resumed.Terminal)
+	assert.Contains(t, resumed.SpawnError, "no server running")
+	assert.NotEmpty(t, resumed.Command)
+}
+
 // TestLaunchAgent_WorktreePathMatchesConvention verifies the bug fix: the
 // worktree path stored in the session uses the .worktrees/<dirname> convention.
 func TestLaunchAgent_WorktreePathMatchesConvention(t *testing.T) {
@@ -618,6 +931,147 @@ func TestLaunchAgent_AutoPurgesStaleSessions(t *testing.T) {
 	assert.Error(t, err, "stale session should be purged on launch")
 }
 
+// TestLaunchAgent_WIPLimits verifies launches warn past a project's WIP limits
+// and are refused when hard blocking is enabled.
+func TestLaunchAgent_WIPLimits(t *testing.T) {
+	srv, s, _, repoPath := setupE2EServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	proj := createProject(t, s, "wip-test", repoPath)
+	proj.MaxActiveSessions = 1
+	require.NoError(t, s.UpdateProject(ctx, proj))
+	createSession(t, s, proj.ID, "", "feature/existing", "/tmp/wip-existing", models.SessionStatusActive)
+
+	issue := createIssue(t, s, proj.ID, "Over the limit")
+	w := doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+		"project_id": proj.ID,
+		"issue_ids":  []string{issue.ID},
+	})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	resp := decodeJSON[LaunchAgentResponse](t, w)
+	require.Len(t, resp.WIPWarnings, 1)
+	assert.Contains(t, resp.WIPWarnings[0], "2 active sessions exceeds WIP limit of 1")
+
+	// Hard block: refused with 409 and the issue is left untouched
+	w = doJSON(t, router, "PUT", "/api/v1/projects/"+proj.ID, map[string]any{"WIPHardBlock": true})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	blocked := createIssue(t, s, proj.ID, "Blocked launch")
+	w = doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+		"project_id": proj.ID,
+		"issue_ids":  []string{blocked.ID},
+	})
+	require.Equal(t, http.StatusConflict, w.Code, "body: %s", w.Body.String())
+	blockedResp := decodeJSON[WIPBlockedResponse](t, w)
+	require.NotNil(t, blockedResp.WIP)
+	assert.True(t, blockedResp.WIP.Blocked)
+	assert.Equal(t, 3, blockedResp.WIP.ActiveSessions)
+
+	dbIssue, err := s.GetIssue(ctx, blocked.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.IssueStatusOpen, dbIssue.Status)
+}
+
+// TestLaunchAgent_Sparse verifies sparse worktrees are asked for with the
+// project's paths plus the ones the issue mentions, and recorded on the
+// session. Launches stay full checkouts unless sparse is configured or asked for.
+func TestLaunchAgent_Sparse(t *testing.T) {
+	srv, s, wtc, repoPath := setupE2EServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(repoPath, "services", "billing"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "services", "billing", "invoice.go"), nil, 0o644))
+	proj := createProject(t, s, "sparse-test", repoPath)
+
+	full := createIssue(t, s, proj.ID, "Full checkout")
+	w := doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+		"project_id": proj.ID,
+		"issue_ids":  []string{full.ID},
+	})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	assert.Empty(t, decodeJSON[LaunchAgentResponse](t, w).SparsePaths)
+	assert.Empty(t, wtc.createOpts[0].SparsePaths)
+
+	w = doJSON(t, router, "PUT", "/api/v1/projects/"+proj.ID, map[string]any{"SparsePaths": " docs/, shared"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	issue := createIssue(t, s, proj.ID, "Fix rounding in services/billing/invoice.go")
+	w = doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+		"project_id":   proj.ID,
+		"issue_ids":    []string{issue.ID},
+		"sparse_paths": []string{"vendor/money"},
+	})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	resp := decodeJSON[LaunchAgentResponse](t, w)
+	want := []string{"docs", "services/billing", "shared", "vendor/money"}
+	assert.Equal(t, want, resp.SparsePaths)
+	assert.Equal(t, want, wtc.createOpts[1].SparsePaths)
+
+	sess, err := s.GetAgentSession(ctx, resp.SessionID)
+	require.NoError(t, err)
+	assert.Equal(t, "docs,services/billing,shared,vendor/money", sess.SparsePaths)
+
+	w = doJSON(t, router, "PUT", "/api/v1/projects/"+proj.ID, map[string]any{"SparsePaths": ""})
+	require.Equal(t, http.StatusOK, w.Code)
+	dbProj, err := s.GetProject(ctx, proj.ID)
+	require.NoError(t, err)
+	assert.Empty(t, dbProj.SparsePaths)
+}
+
+// TestAgentNext verifies pulling work launches the top-ranked issue the
+// caller may take, marks it in progress and assigns it.
+func TestAgentNext(t *testing.T) {
+	srv, s, _, repoPath := setupE2EServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	proj := createProject(t, s, "next-test", repoPath)
+	low := createIssue(t, s, proj.ID, "Low priority chore")
+	low.Priority = models.IssuePriorityLow
+	require.NoError(t, s.UpdateIssue(ctx, low))
+	theirs := createIssue(t, s, proj.ID, "Someone elses work")
+	theirs.Priority = models.IssuePriorityHigh
+	theirs.Assignee = "alice"
+	require.NoError(t, s.UpdateIssue(ctx, theirs))
+	mine := createIssue(t, s, proj.ID, "Medium priority feature")
+
+	w := doJSON(t, router, "POST", "/api/v1/agent/next", map[string]any{
+		"project_id": proj.ID,
+		"assignee":   "bot-1",
+	})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	resp := decodeJSON[NextAgentResponse](t, w)
+	assert.Equal(t, mine.ID, resp.IssueID, "issues assigned to others are skipped")
+	assert.Equal(t, "feature/medium-priority-feature", resp.Branch)
+	assert.Contains(t, resp.Command, "claude")
+	assert.Contains(t, resp.Reasons, "medium priority")
+
+	dbIssue, err := s.GetIssue(ctx, mine.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.IssueStatusInProgress, dbIssue.Status)
+	assert.Equal(t, "bot-1", dbIssue.Assignee)
+	sess, err := s.GetAgentSession(ctx, resp.SessionID)
+	require.NoError(t, err)
+	assert.Equal(t, mine.ID, sess.IssueID)
+
+	// The launched issue is not handed out again.
+	w = doJSON(t, router, "POST", "/api/v1/agent/next", map[string]any{"assignee": "bot-2"})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	assert.Equal(t, low.ID, decodeJSON[NextAgentResponse](t, w).IssueID)
+
+	w = doJSON(t, router, "POST", "/api/v1/agent/next", map[string]any{"assignee": "bot-3"})
+	assert.Equal(t, http.StatusNoContent, w.Code, "body: %s", w.Body.String())
+
+	w = doJSON(t, router, "POST", "/api/v1/agent/next", map[string]any{"assignee": "alice"})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	assert.Equal(t, theirs.ID, decodeJSON[NextAgentResponse](t, w).IssueID)
+
+	w = doJSON(t, router, "POST", "/api/v1/agent/next", map[string]any{"project_id": "NONEXISTENT"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
 // TestCloseAgent_Validation tests error responses for bad close requests.
 func TestCloseAgent_Validation(t *testing.T) {
 	srv, s, _, repoPath := setupE2EServer(t)
@@ -642,7 +1096,7 @@ func TestCloseAgent_Validation(t *testing.T) {
 	})
 
 	t.Run("invalid status", func(t *testing.T) {
-		sess := createSession(t, s, proj.ID, issue.ID, "feature/bar", "/tmp/wt", models.SessionStatusActive)
+		sess := createSession(t, s, proj.ID, issue.ID, "feature/bar", "/tmp/wt", models.SessionStatusIdle)
 		w := doJSON(t, router, "POST", "/api/v1/agent/close", map[string]any{
 			"session_id": sess.ID,
 			"status":     "invalid_status",
@@ -817,64 +1271,361 @@ func TestListSessions_StatusFilter(t *testing.T) {
 	})
 }
 
-// TestListSessions_ReconciliationRefilters verifies that reconciliation
-// doesn't leak abandoned sessions into active/idle filtered results.
-func TestListSessions_ReconciliationRefilters(t *testing.T) {
+func TestListSessions_IssueAndBranch(t *testing.T) {
 	srv, s, _, repoPath := setupE2EServer(t)
 	router := srv.Router()
 
-	proj := createProject(t, s, "reconcile-test", repoPath)
+	proj := createProject(t, s, "issue-filter", repoPath)
+	issue := createIssue(t, s, proj.ID, "Flaky login")
+	first := createSession(t, s, proj.ID, issue.ID, "feature/login", "/tmp/nonexistent-login", models.SessionStatusAbandoned)
+	retry := createSession(t, s, proj.ID, issue.ID, "feature/login-2", t.TempDir(), models.SessionStatusIdle)
+	createSession(t, s, proj.ID, "", "feature/login", t.TempDir(), models.SessionStatusIdle)
 
-	// Idle session with missing worktree — reconciliation will abandon it
-	_ = createSession(t, s, proj.ID, "", "feature/ghost", "/tmp/nonexistent-worktree-path", models.SessionStatusIdle)
+	list := func(t *testing.T, path string) []string {
+		w := doJSON(t, router, "GET", path, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var sessions []sessionResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sessions))
+		var ids []string
+		for _, sess := range sessions {
+			ids = append(ids, sess.ID)
+		}
+		return ids
+	}
 
-	// Idle session with valid worktree — should survive
-	validSess := createSession(t, s, proj.ID, "", "feature/valid", t.TempDir(), models.SessionStatusIdle)
+	assert.ElementsMatch(t, []string{first.ID, retry.ID}, list(t, "/api/v1/sessions?issue_id="+issue.ID))
+	assert.Equal(t, []string{first.ID}, list(t, "/api/v1/sessions?issue_id="+issue.ID+"&branch=feature/login"))
+	assert.Len(t, list(t, "/api/v1/sessions?branch=feature/login"), 2)
 
-	w := doJSON(t, router, "GET", "/api/v1/sessions?status=active,idle", nil)
+	assert.ElementsMatch(t, []string{first.ID, retry.ID}, list(t, "/api/v1/issues/"+issue.ID+"/sessions"))
+	assert.Equal(t, []string{retry.ID}, list(t, "/api/v1/issues/"+issue.ID+"/sessions?status=idle"))
+
+	w := doJSON(t, router, "GET", "/api/v1/issues/01NOPE/sessions", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestBlockedSession_API(t *testing.T) {
+	srv, s, _, repoPath := setupE2EServer(t)
+	router := srv.Router()
+
+	proj := createProject(t, s, "blocked-test", repoPath)
+	sess := createSession(t, s, proj.ID, "", "feature/sso", t.TempDir(), models.SessionStatusIdle)
+
+	w := doJSON(t, router, "POST", "/api/v1/agent/close", map[string]any{
+		"session_id": sess.ID,
+		"status":     "blocked",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "a reason is required")
+
+	w = doJSON(t, router, "POST", "/api/v1/agent/close", map[string]any{
+		"session_id":        sess.ID,
+		"status":            "blocked",
+		"reason":            "waiting on IdP credentials",
+		"unblock_condition": "ops shares the client secret",
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doJSON(t, router, "GET", "/api/v1/sessions?status=blocked", nil)
 	require.Equal(t, http.StatusOK, w.Code)
 	var sessions []sessionResponse
 	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sessions))
+	require.Len(t, sessions, 1, "reconciliation leaves blocked sessions alone")
+	assert.Equal(t, "waiting on IdP credentials", sessions[0].BlockedReason)
+	assert.Equal(t, "ops shares the client secret", sessions[0].UnblockCondition)
+	assert.False(t, sessions[0].BlockedOverdue)
 
-	assert.Len(t, sessions, 1, "ghost session should be excluded after reconciliation")
-	assert.Equal(t, validSess.ID, sessions[0].ID)
+	w = doJSON(t, router, "GET", "/api/v1/sessions?status=active,idle", nil)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sessions))
+	assert.Empty(t, sessions)
+
+	// Closing a blocked session as idle unblocks it
+	w = doJSON(t, router, "POST", "/api/v1/agent/close", map[string]any{
+		"session_id": sess.ID,
+		"status":     "idle",
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	got, err := s.GetAgentSession(context.Background(), sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SessionStatusIdle, got.Status)
+	assert.Empty(t, got.BlockedReason)
 }
 
-// TestCloseCheck verifies the close-check endpoint returns correct warnings.
-func TestCloseCheck(t *testing.T) {
+func TestAgentHeartbeat(t *testing.T) {
 	srv, s, _, repoPath := setupE2EServer(t)
 	router := srv.Router()
 	ctx := context.Background()
 
-	proj := createProject(t, s, "check-test", repoPath)
+	proj := createProject(t, s, "heartbeat-test", repoPath)
+	issue := createIssue(t, s, proj.ID, "Heartbeat")
+	wtPath := filepath.Join(t.TempDir(), "wt")
+	out, err := exec.Command("git", "-C", repoPath, "worktree", "add", "-b", "feature/heartbeat", wtPath).CombinedOutput()
+	require.NoError(t, err, "git worktree add: %s", string(out))
+	sess := createSession(t, s, proj.ID, issue.ID, "feature/heartbeat", wtPath, models.SessionStatusActive)
+	gitCommitFile(t, wtPath, "a.txt", "a", "Add a")
 
-	t.Run("session with real clean worktree", func(t *testing.T) {
-		issue := createIssue(t, s, proj.ID, "Close check clean")
-		w := doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
-			"project_id": proj.ID,
-			"issue_ids":  []string{issue.ID},
-		})
-		require.Equal(t, http.StatusOK, w.Code)
-		launchResp := decodeJSON[LaunchAgentResponse](t, w)
+	w := doJSON(t, router, "POST", "/api/v1/agent/heartbeat", map[string]any{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
 
-		w = doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/close-check", launchResp.SessionID), nil)
-		require.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(t, router, "POST", "/api/v1/agent/heartbeat", map[string]any{"worktree_path": "/nonexistent"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
 
-		var resp closeCheckResponse
-		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
-		assert.True(t, resp.WorktreeExists)
-		assert.False(t, resp.IsDirty)
-		assert.True(t, resp.ReadyToClose, "clean worktree with no ahead commits should be ready")
-		assert.Equal(t, "main", resp.BaseBranch)
-	})
+	w = doJSON(t, router, "POST", "/api/v1/agent/heartbeat", map[string]any{"worktree_path": wtPath + "/"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	resp := decodeJSON[HeartbeatResponse](t, w)
+	assert.Equal(t, sess.ID, resp.SessionID)
+	assert.Equal(t, issue.ID, resp.IssueID)
+	assert.Equal(t, 1, resp.CommitCount)
+	assert.NotEmpty(t, resp.LastCommitHash)
 
-	t.Run("session with dirty worktree", func(t *testing.T) {
-		issue := createIssue(t, s, proj.ID, "Close check dirty")
-		w := doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
-			"project_id": proj.ID,
-			"issue_ids":  []string{issue.ID},
-		})
-		require.Equal(t, http.StatusOK, w.Code)
+	got, err := s.GetAgentSession(ctx, sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Add a", got.LastCommitMessage)
+	assert.NotNil(t, got.LastActiveAt)
+	assert.Zero(t, got.FocusSeconds, "the first heartbeat starts the focus time")
+
+	w = doJSON(t, router, "POST", "/api/v1/agent/heartbeat", map[string]any{"session_id": sess.ID})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	again, err := s.GetAgentSession(ctx, sess.ID)
+	require.NoError(t, err)
+	assert.InDelta(t, again.LastActiveAt.Sub(*got.LastActiveAt).Seconds(), again.FocusSeconds, 0.001)
+
+	_, err = agent.CloseSession(ctx, s, sess.ID, models.SessionStatusAbandoned)
+	require.NoError(t, err)
+	w = doJSON(t, router, "POST", "/api/v1/agent/heartbeat", map[string]any{"session_id": sess.ID})
+	assert.Equal(t, http.StatusConflict, w.Code, "closed sessions take no heartbeats")
+}
+
+// TestReviewShares verifies that a review link opens an issue's review
+// context without an API token and takes exactly one verdict.
+func TestReviewShares(t *testing.T) {
+	srv, s, _, repoPath := setupE2EServer(t)
+	WithRequireAuth(true)(srv)
+	router := srv.Router()
+	ctx := context.Background()
+
+	proj := createProject(t, s, "share-test", repoPath)
+	issue := createIssue(t, s, proj.ID, "Add logout")
+	issue.AcceptanceCriteria = []string{"Logout clears the session", "Logout redirects home"}
+	require.NoError(t, s.UpdateIssue(ctx, issue))
+	wtPath := filepath.Join(t.TempDir(), "wt")
+	out, err := exec.Command("git", "-C", repoPath, "worktree", "add", "-b", "feature/logout", wtPath).CombinedOutput()
+	require.NoError(t, err, "git worktree add: %s", string(out))
+	sess := createSession(t, s, proj.ID, issue.ID, "feature/logout", wtPath, models.SessionStatusIdle)
+	gitCommitFile(t, wtPath, "logout.go", "package logout\n", "Add logout")
+
+	apiToken, hash, err := auth.NewToken()
+	require.NoError(t, err)
+	require.NoError(t, s.CreateAPIToken(ctx, &models.APIToken{ProjectID: proj.ID, TokenHash: hash}))
+	host := "localhost:8080"
+	do := func(method, path, bearer string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			require.NoError(t, json.NewEncoder(&buf).Encode(body))
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Host = host
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	sharesPath := "/api/v1/issues/" + issue.ID + "/review-shares"
+	assert.Equal(t, http.StatusUnauthorized, do("POST", sharesPath, "", nil).Code, "creating links needs a token")
+	assert.Equal(t, http.StatusBadRequest, do("POST", sharesPath, apiToken, map[string]any{"expires_in": "soon"}).Code)
+	w := do("POST", sharesPath, apiToken, map[string]any{"reviewer": "Dana", "expires_in": "2d"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	created := decodeJSON[ReviewShareResponse](t, w)
+	assert.True(t, strings.HasPrefix(created.Token, auth.ShareTokenPrefix))
+	assert.Equal(t, "http://localhost:8080/review/"+created.Token, created.URL)
+	assert.Equal(t, "Dana", created.Reviewer)
+	assert.True(t, strings.HasSuffix(created.ExpiresAt, "Z"), "times are UTC")
+	assert.InDelta(t, 2*24*60*60, created.ExpiresInSeconds, 5)
+
+	// Only a loopback Host is trusted to build links from
+	host = "evil.example.com"
+	assert.Equal(t, http.StatusServiceUnavailable, do("POST", sharesPath, apiToken, nil).Code)
+	WithReviewShares(0, "https://pm.example.com/")(srv)
+	w = do("POST", sharesPath, apiToken, nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	other := decodeJSON[ReviewShareResponse](t, w)
+	assert.Equal(t, "https://pm.example.com/review/"+other.Token, other.URL)
+	assert.Equal(t, http.StatusNoContent, do("DELETE", sharesPath+"/"+other.ID, apiToken, nil).Code)
+
+	w = do("GET", sharesPath, apiToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	listed := decodeJSON[[]ReviewShareResponse](t, w)
+	require.Len(t, listed, 1)
+	assert.Empty(t, listed[0].Token, "tokens are only shown on creation")
+
+	// The link alone opens the review; it is not an API token.
+	sharePath := "/api/v1/review-shares/" + created.Token
+	assert.Equal(t, http.StatusUnauthorized, do("GET", "/api/v1/issues/"+issue.ID, created.Token, nil).Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/review-shares/pmr_bogus", "", nil).Code)
+	w = do("GET", sharePath, "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	shared := decodeJSON[SharedReview](t, w)
+	assert.Equal(t, "share-test", shared.Project)
+	assert.Equal(t, issue.AcceptanceCriteria, shared.Issue.AcceptanceCriteria)
+	assert.Equal(t, "feature/logout", shared.HeadRef)
+	assert.Contains(t, shared.Diff, "package logout")
+	require.Len(t, shared.FileDiffs, 1)
+	assert.Equal(t, "logout.go", shared.FileDiffs[0].File)
+	assert.NotContains(t, w.Body.String(), wtPath, "local paths are not shared")
+	assert.Nil(t, shared.Submitted)
+
+	verdict := map[string]any{
+		"verdict": "pass",
+		"summary": "Works, but doesn't redirect.",
+		"criteria": []map[string]any{
+			{"criterion": "Logout clears the session", "passed": true},
+			{"criterion": "Logout redirects home", "passed": false, "note": "stays on the page"},
+		},
+	}
+	assert.Equal(t, http.StatusBadRequest, do("POST", sharePath+"/review", "", verdict).Code, "pass with a failed criterion")
+	verdict["criteria"] = []map[string]any{{"criterion": "Something else", "passed": true}}
+	assert.Equal(t, http.StatusBadRequest, do("POST", sharePath+"/review", "", verdict).Code, "unknown criterion")
+
+	verdict["verdict"] = "fail"
+	verdict["criteria"] = []map[string]any{{"criterion": "Logout redirects home", "passed": false, "note": "stays on the page"}}
+	w = do("POST", sharePath+"/review", "", verdict)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusConflict, do("POST", sharePath+"/review", "", verdict).Code, "one verdict per link")
+
+	reviews, err := s.ListIssueReviews(ctx, issue.ID)
+	require.NoError(t, err)
+	require.Len(t, reviews, 1)
+	assert.Equal(t, "Dana", reviews[0].Reviewer)
+	assert.Equal(t, sess.ID, reviews[0].SessionID)
+	assert.Equal(t, []string{"Acceptance criterion not met: Logout redirects home (stays on the page)"}, reviews[0].FailureReasons)
+	got, err := s.GetIssue(ctx, issue.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultCascade[models.CascadeReviewFail], got.Status, "the verdict cascades to the issue")
+
+	w = do("GET", sharePath, "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	shared = decodeJSON[SharedReview](t, w)
+	require.NotNil(t, shared.Submitted)
+	assert.Equal(t, reviews[0].ID, shared.Submitted.ID)
+
+	// Revoked and expired links stop working.
+	assert.Equal(t, http.StatusNoContent, do("DELETE", sharesPath+"/"+created.ID, apiToken, nil).Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", sharePath, "", nil).Code)
+	token, hash, err := auth.NewShareToken()
+	require.NoError(t, err)
+	require.NoError(t, s.CreateReviewShare(ctx, &models.ReviewShare{IssueID: issue.ID, TokenHash: hash, ExpiresAt: time.Now().Add(-time.Minute)}))
+	assert.Equal(t, http.StatusGone, do("GET", "/api/v1/review-shares/"+token, "", nil).Code)
+}
+
+// TestListSessions_ReconciliationRefilters verifies that reconciliation
+// doesn't leak abandoned sessions into active/idle filtered results.
+func TestListSessions_ReconciliationRefilters(t *testing.T) {
+	srv, s, _, repoPath := setupE2EServer(t)
+	router := srv.Router()
+
+	proj := createProject(t, s, "reconcile-test", repoPath)
+
+	// Idle session with missing worktree — reconciliation will abandon it
+	_ = createSession(t, s, proj.ID, "", "feature/ghost", "/tmp/nonexistent-worktree-path", models.SessionStatusIdle)
+
+	// Idle session with valid worktree — should survive
+	validSess := createSession(t, s, proj.ID, "", "feature/valid", t.TempDir(), models.SessionStatusIdle)
+
+	w := doJSON(t, router, "GET", "/api/v1/sessions?status=active,idle", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var sessions []sessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sessions))
+
+	assert.Len(t, sessions, 1, "ghost session should be excluded after reconciliation")
+	assert.Equal(t, validSess.ID, sessions[0].ID)
+}
+
+// TestSessionReplay verifies the replay endpoint rebuilds a session's timeline
+// from its recorded events, branch commits and issue status changes.
+func TestSessionReplay(t *testing.T) {
+	srv, s, _, repoPath := setupE2EServer(t)
+	router := srv.Router()
+
+	proj := createProject(t, s, "replay-test", repoPath)
+	issue := createIssue(t, s, proj.ID, "Replay me")
+	w := doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+		"project_id": proj.ID,
+		"issue_ids":  []string{issue.ID},
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	launchResp := decodeJSON[LaunchAgentResponse](t, w)
+	gitCommitFile(t, launchResp.WorktreePath, "replay.go", "package main\n", "Add replay")
+
+	w = doJSON(t, router, "POST", "/api/v1/agent/close", map[string]any{
+		"session_id": launchResp.SessionID,
+		"status":     "idle",
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/replay", launchResp.SessionID), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	replay := decodeJSON[agent.Replay](t, w)
+	assert.Equal(t, "replay-test", replay.ProjectName)
+	assert.Equal(t, "Replay me", replay.IssueTitle)
+	assert.Equal(t, "idle", replay.Status)
+	assert.Zero(t, replay.Problems)
+
+	var summaries, commits []string
+	for _, e := range replay.Entries {
+		summaries = append(summaries, e.Summary)
+		if e.Kind == agent.ReplayCommit {
+			commits = append(commits, e.Detail)
+		}
+	}
+	assert.Equal(t, []string{"Add replay"}, commits, "commits come from the branch")
+	assert.Contains(t, summaries, "Started on "+launchResp.Branch)
+	assert.Contains(t, summaries, "Issue open → in_progress")
+	assert.Contains(t, summaries, "Went idle")
+
+	w = doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/replay?summarize=true", launchResp.SessionID), nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "no LLM configured")
+
+	w = doJSON(t, router, "GET", "/api/v1/sessions/NONEXISTENT/replay", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestCloseCheck verifies the close-check endpoint returns correct warnings.
+func TestCloseCheck(t *testing.T) {
+	srv, s, _, repoPath := setupE2EServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	proj := createProject(t, s, "check-test", repoPath)
+
+	t.Run("session with real clean worktree", func(t *testing.T) {
+		issue := createIssue(t, s, proj.ID, "Close check clean")
+		w := doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+			"project_id": proj.ID,
+			"issue_ids":  []string{issue.ID},
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+		launchResp := decodeJSON[LaunchAgentResponse](t, w)
+
+		w = doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/close-check", launchResp.SessionID), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp agent.CloseCheck
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.True(t, resp.WorktreeExists)
+		assert.False(t, resp.IsDirty)
+		assert.True(t, resp.ReadyToClose, "clean worktree with no ahead commits should be ready")
+		assert.Equal(t, "main", resp.BaseBranch)
+	})
+
+	t.Run("session with dirty worktree", func(t *testing.T) {
+		issue := createIssue(t, s, proj.ID, "Close check dirty")
+		w := doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+			"project_id": proj.ID,
+			"issue_ids":  []string{issue.ID},
+		})
+		require.Equal(t, http.StatusOK, w.Code)
 		launchResp := decodeJSON[LaunchAgentResponse](t, w)
 
 		// Add an uncommitted file to make it dirty
@@ -883,7 +1634,7 @@ func TestCloseCheck(t *testing.T) {
 		w = doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/close-check", launchResp.SessionID), nil)
 		require.Equal(t, http.StatusOK, w.Code)
 
-		var resp closeCheckResponse
+		var resp agent.CloseCheck
 		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
 		assert.True(t, resp.IsDirty)
 		assert.False(t, resp.ReadyToClose)
@@ -896,6 +1647,32 @@ func TestCloseCheck(t *testing.T) {
 			}
 		}
 		assert.True(t, hasWarning, "should have a dirty warning")
+		assert.Equal(t, []git.FileChange{{Path: "dirty.txt", Untracked: true, Additions: 1}}, resp.DirtyFiles)
+		assert.Equal(t, agent.DirtySource, resp.DirtyKind)
+	})
+
+	t.Run("session with only a dirty lockfile", func(t *testing.T) {
+		issue := createIssue(t, s, proj.ID, "Close check lockfile")
+		w := doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+			"project_id": proj.ID,
+			"issue_ids":  []string{issue.ID},
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+		launchResp := decodeJSON[LaunchAgentResponse](t, w)
+
+		require.NoError(t, os.WriteFile(filepath.Join(launchResp.WorktreePath, "go.sum"), []byte("a v1 h1:x\nb v2 h1:y\n"), 0o644))
+
+		w = doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/close-check", launchResp.SessionID), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp agent.CloseCheck
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.False(t, resp.ReadyToClose)
+		assert.Equal(t, agent.DirtyLockfileOnly, resp.DirtyKind)
+		require.Len(t, resp.DirtyFiles, 1)
+		assert.Equal(t, 2, resp.DirtyFiles[0].Additions)
+		require.NotEmpty(t, resp.Warnings)
+		assert.Contains(t, resp.Warnings[0].Message, "Only lockfiles")
 	})
 
 	t.Run("session with unmerged commits", func(t *testing.T) {
@@ -912,7 +1689,7 @@ func TestCloseCheck(t *testing.T) {
 		w = doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/close-check", launchResp.SessionID), nil)
 		require.Equal(t, http.StatusOK, w.Code)
 
-		var resp closeCheckResponse
+		var resp agent.CloseCheck
 		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
 		assert.Equal(t, 1, resp.AheadCount)
 		assert.False(t, resp.ReadyToClose)
@@ -926,6 +1703,33 @@ func TestCloseCheck(t *testing.T) {
 		assert.True(t, hasWarning, "should have an unmerged warning")
 	})
 
+	t.Run("commits breaking the commit conventions", func(t *testing.T) {
+		proj.CommitStyle = "conventional"
+		require.NoError(t, s.UpdateProject(ctx, proj))
+		t.Cleanup(func() {
+			proj.CommitStyle = ""
+			_ = s.UpdateProject(ctx, proj)
+		})
+		wtPath := filepath.Join(t.TempDir(), "wt")
+		out, err := exec.Command("git", "-C", repoPath, "worktree", "add", "-b", "feature/conventions", wtPath).CombinedOutput()
+		require.NoError(t, err, "git worktree add: %s", string(out))
+		sess := createSession(t, s, proj.ID, "", "feature/conventions", wtPath, models.SessionStatusActive)
+		gitCommitFile(t, wtPath, "ok.go", "package main\n", "feat: add ok")
+		gitCommitFile(t, wtPath, "bad.go", "package main\n", "Add bad")
+
+		w := doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/close-check", sess.ID), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp agent.CloseCheck
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.CommitViolations, 1)
+		assert.Equal(t, "Add bad", resp.CommitViolations[0].Subject)
+		assert.False(t, resp.ReadyToClose)
+
+		w = doJSON(t, router, "POST", fmt.Sprintf("/api/v1/sessions/%s/merge", sess.ID), map[string]any{})
+		assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), "Add bad")
+	})
+
 	t.Run("session with conflict state", func(t *testing.T) {
 		sess := createSession(t, s, proj.ID, "", "feature/conf-check", "/tmp/conf", models.SessionStatusActive)
 		sess.ConflictState = models.ConflictStateSyncConflict
@@ -934,7 +1738,7 @@ func TestCloseCheck(t *testing.T) {
 		w := doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/close-check", sess.ID), nil)
 		require.Equal(t, http.StatusOK, w.Code)
 
-		var resp closeCheckResponse
+		var resp agent.CloseCheck
 		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
 		assert.False(t, resp.ReadyToClose)
 
@@ -953,6 +1757,175 @@ func TestCloseCheck(t *testing.T) {
 	})
 }
 
+// TestCloseAgent_CloseCheckEnforcement verifies completing a session with
+// close-check warnings is rejected unless forced.
+func TestCloseAgent_CloseCheckEnforcement(t *testing.T) {
+	srv, s, _, repoPath := setupE2EServer(t)
+	WithCloseCheckEnforcement(true)(srv)
+	router := srv.Router()
+	ctx := context.Background()
+
+	proj := createProject(t, s, "enforce-test", repoPath)
+	issue := createIssue(t, s, proj.ID, "Enforce close check")
+
+	w := doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+		"project_id": proj.ID,
+		"issue_ids":  []string{issue.ID},
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	launchResp := decodeJSON[LaunchAgentResponse](t, w)
+	require.NoError(t, os.WriteFile(filepath.Join(launchResp.WorktreePath, "dirty.txt"), []byte("dirty"), 0o644))
+
+	// Without force: 409 with the close-check payload, session untouched
+	w = doJSON(t, router, "POST", "/api/v1/agent/close", map[string]any{
+		"session_id": launchResp.SessionID,
+		"status":     "completed",
+	})
+	require.Equal(t, http.StatusConflict, w.Code, "body: %s", w.Body.String())
+	conflict := decodeJSON[CloseConflictResponse](t, w)
+	require.NotNil(t, conflict.CloseCheck)
+	assert.True(t, conflict.CloseCheck.IsDirty)
+	assert.False(t, conflict.CloseCheck.ReadyToClose)
+	assert.NotEmpty(t, conflict.CloseCheck.Warnings)
+
+	dbSess, err := s.GetAgentSession(ctx, launchResp.SessionID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SessionStatusActive, dbSess.Status)
+
+	// Idle close is never gated
+	w = doJSON(t, router, "POST", "/api/v1/agent/close", map[string]any{
+		"session_id": launchResp.SessionID,
+		"status":     "idle",
+	})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+
+	// With force: completes despite warnings
+	w = doJSON(t, router, "POST", "/api/v1/agent/close", map[string]any{
+		"session_id": launchResp.SessionID,
+		"status":     "completed",
+		"force":      true,
+	})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	assert.Equal(t, "completed", decodeJSON[CloseAgentResponse](t, w).Status)
+}
+
+// TestCloseAgent_CloseCheckEnforcementDisabled verifies close-check gating is
+// off unless enabled.
+func TestCloseAgent_CloseCheckEnforcementDisabled(t *testing.T) {
+	srv, s, _, repoPath := setupE2EServer(t)
+	router := srv.Router()
+
+	proj := createProject(t, s, "enforce-off", repoPath)
+	sess := createSession(t, s, proj.ID, "", "feature/enforce-off", repoPath, models.SessionStatusActive)
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "dirty.txt"), []byte("dirty"), 0o644))
+
+	w := doJSON(t, router, "POST", "/api/v1/agent/close", map[string]any{
+		"session_id": sess.ID,
+		"status":     "completed",
+	})
+	assert.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+}
+
+// TestSessionFiles verifies the worktree file browser endpoints.
+func TestSessionFiles(t *testing.T) {
+	srv, s, _, repoPath := setupE2EServer(t)
+	router := srv.Router()
+
+	proj := createProject(t, s, "files-test", repoPath)
+	sess := createSession(t, s, proj.ID, "", "feature/files", repoPath, models.SessionStatusActive)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(repoPath, "pkg", "util"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "pkg", "util", "util.go"), []byte("package util\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "logo.bin"), []byte{0x89, 'P', 'N', 'G', 0x00, 0x01}, 0o644))
+
+	t.Run("list root", func(t *testing.T) {
+		w := doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/files", sess.ID), nil)
+		require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+		resp := decodeJSON[fileListResponse](t, w)
+		assert.Equal(t, "", resp.Path)
+
+		var names []string
+		for _, e := range resp.Entries {
+			names = append(names, e.Name)
+		}
+		assert.Equal(t, []string{"pkg", "README.md", "logo.bin"}, names, "dirs first, .git hidden")
+		assert.True(t, resp.Entries[0].IsDir)
+	})
+
+	t.Run("list subdirectory", func(t *testing.T) {
+		w := doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/files?path=pkg/util", sess.ID), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		resp := decodeJSON[fileListResponse](t, w)
+		require.Len(t, resp.Entries, 1)
+		assert.Equal(t, "pkg/util/util.go", resp.Entries[0].Path)
+		assert.Equal(t, int64(13), resp.Entries[0].Size)
+	})
+
+	t.Run("text file", func(t *testing.T) {
+		w := doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/file?path=pkg/util/util.go", sess.ID), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		resp := decodeJSON[fileContentResponse](t, w)
+		assert.False(t, resp.Binary)
+		assert.Equal(t, "utf-8", resp.Encoding)
+		assert.Equal(t, "package util\n", resp.Content)
+	})
+
+	t.Run("binary file", func(t *testing.T) {
+		w := doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/file?path=logo.bin", sess.ID), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		resp := decodeJSON[fileContentResponse](t, w)
+		assert.True(t, resp.Binary)
+		assert.Equal(t, "base64", resp.Encoding)
+		assert.Equal(t, "iVBORwAB", resp.Content)
+	})
+
+	t.Run("large file is truncated", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, "big.txt"), bytes.Repeat([]byte("a"), maxFileViewBytes+10), 0o644))
+		w := doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/file?path=big.txt", sess.ID), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		resp := decodeJSON[fileContentResponse](t, w)
+		assert.True(t, resp.Truncated)
+		assert.Equal(t, int64(maxFileViewBytes+10), resp.Size)
+		assert.Len(t, resp.Content, maxFileViewBytes)
+	})
+
+	t.Run("path traversal is clamped to worktree", func(t *testing.T) {
+		w := doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/file?path=../../../etc/passwd", sess.ID), nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("symlink escaping worktree", func(t *testing.T) {
+		outside := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(outside, "secret"), []byte("x"), 0o644))
+		require.NoError(t, os.Symlink(outside, filepath.Join(repoPath, "escape")))
+		w := doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/file?path=escape/secret", sess.ID), nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run(".git is not served", func(t *testing.T) {
+		for _, path := range []string{".git", ".git/config", "pkg/../.git/HEAD"} {
+			w := doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/file?path=%s", sess.ID, path), nil)
+			assert.Equal(t, http.StatusNotFound, w.Code, path)
+		}
+		w := doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/files?path=.git", sess.ID), nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		require.NoError(t, os.Symlink(".git", filepath.Join(repoPath, "gitdir")))
+		w = doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/file?path=gitdir/config", sess.ID), nil)
+		assert.Equal(t, http.StatusNotFound, w.Code, "nor through a symlink")
+	})
+
+	t.Run("directory passed to file endpoint", func(t *testing.T) {
+		w := doJSON(t, router, "GET", fmt.Sprintf("/api/v1/sessions/%s/file?path=pkg", sess.ID), nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("session not found", func(t *testing.T) {
+		w := doJSON(t, router, "GET", "/api/v1/sessions/NONEXISTENT/files", nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
 // TestReactivateSession tests reactivation from terminal states.
 func TestReactivateSession(t *testing.T) {
 	srv, s, _, repoPath := setupE2EServer(t)
@@ -1040,7 +2013,12 @@ func TestDeleteWorktree(t *testing.T) {
 
 	// Delete the worktree
 	w = doJSON(t, router, "DELETE", fmt.Sprintf("/api/v1/sessions/%s/worktree", launchResp.SessionID), nil)
-	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, http.StatusOK, w.Code)
+	cleanup := decodeJSON[sessions.CleanupResult](t, w)
+	assert.True(t, cleanup.WorktreeRemoved)
+	assert.False(t, cleanup.BranchDeleted, "the branch is kept unless asked")
+	assert.Empty(t, cleanup.Upstream)
+	assert.Equal(t, []string{"local branch " + launchResp.Branch + ": not requested"}, cleanup.Kept)
 
 	dbSess, _ := s.GetAgentSession(ctx, launchResp.SessionID)
 	assert.Equal(t, models.SessionStatusAbandoned, dbSess.Status)
@@ -1055,6 +2033,74 @@ func TestDeleteWorktree(t *testing.T) {
 	assert.True(t, os.IsNotExist(err), "worktree should be removed from disk")
 }
 
+// TestDeleteWorktree_RemoteTrackingBranch verifies a session whose branch
+// tracks a remote branch has its local branch deleted, and its remote branch
+// only when asked.
+func TestDeleteWorktree_RemoteTrackingBranch(t *testing.T) {
+	srv, s, _, repoPath := setupE2EServer(t)
+	router := srv.Router()
+
+	remote := filepath.Join(t.TempDir(), "origin.git")
+	for _, args := range [][]string{
+		{"git", "init", "--bare", "-b", "main", remote},
+		{"git", "-C", repoPath, "remote", "add", "origin", remote},
+	} {
+		out, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+		require.NoError(t, err, "cmd %v: %s", args, string(out))
+	}
+	proj := createProject(t, s, "del-remote", repoPath)
+
+	// launch starts a session and pushes its branch to origin, as if it had
+	// been checked out from a PR
+	launch := func(title string) LaunchAgentResponse {
+		issue := createIssue(t, s, proj.ID, title)
+		w := doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+			"project_id": proj.ID,
+			"issue_ids":  []string{issue.ID},
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+		resp := decodeJSON[LaunchAgentResponse](t, w)
+		gitCommitFile(t, resp.WorktreePath, title+".txt", title, "Add "+title)
+		out, err := exec.Command("git", "-C", resp.WorktreePath, "push", "-u", "origin", resp.Branch).CombinedOutput()
+		require.NoError(t, err, "git push: %s", string(out))
+		return resp
+	}
+	remoteHas := func(branch string) bool {
+		return exec.Command("git", "-C", remote, "show-ref", "--verify", "--quiet", "refs/heads/"+branch).Run() == nil
+	}
+	localHas := func(branch string) bool {
+		return exec.Command("git", "-C", repoPath, "show-ref", "--verify", "--quiet", "refs/heads/"+branch).Run() == nil
+	}
+
+	t.Run("keeps the remote branch unless asked", func(t *testing.T) {
+		sess := launch("kept")
+		w := doJSON(t, router, "DELETE", fmt.Sprintf("/api/v1/sessions/%s/worktree", sess.SessionID), map[string]any{
+			"delete_branch": true,
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+		cleanup := decodeJSON[sessions.CleanupResult](t, w)
+		assert.True(t, cleanup.WorktreeRemoved)
+		assert.True(t, cleanup.BranchDeleted, "a pushed branch is merged into its upstream")
+		assert.Equal(t, "origin/"+sess.Branch, cleanup.Upstream)
+		assert.False(t, cleanup.RemoteDeleted)
+		assert.Equal(t, []string{"remote branch origin/" + sess.Branch + ": not requested"}, cleanup.Kept)
+		assert.False(t, localHas(sess.Branch))
+		assert.True(t, remoteHas(sess.Branch))
+	})
+
+	t.Run("deletes the remote branch when asked", func(t *testing.T) {
+		sess := launch("gone")
+		w := doJSON(t, router, "DELETE", fmt.Sprintf("/api/v1/sessions/%s/worktree?delete_branch=true&delete_remote=true", sess.SessionID), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		cleanup := decodeJSON[sessions.CleanupResult](t, w)
+		assert.True(t, cleanup.BranchDeleted)
+		assert.True(t, cleanup.RemoteDeleted)
+		assert.Empty(t, cleanup.Kept)
+		assert.False(t, localHas(sess.Branch))
+		assert.False(t, remoteHas(sess.Branch))
+	})
+}
+
 // TestDeleteWorktree_NotFound verifies 404.
 func TestDeleteWorktree_NotFound(t *testing.T) {
 	srv, _, _, _ := setupE2EServer(t)
@@ -1096,9 +2142,11 @@ func TestCleanupSessions_PreservesNonStale(t *testing.T) {
 
 	w := doJSON(t, router, "DELETE", "/api/v1/sessions/cleanup", nil)
 	require.Equal(t, http.StatusOK, w.Code)
-	var result map[string]int64
-	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
-	assert.Equal(t, int64(1), result["deleted"])
+	result := decodeJSON[cleanupResponse](t, w)
+	assert.Equal(t, int64(1), result.Deleted)
+	require.Len(t, result.Sessions, 1)
+	assert.Equal(t, stale.ID, result.Sessions[0].ID)
+	assert.Equal(t, "cleanup-preserve", result.Sessions[0].ProjectName)
 
 	_, err := s.GetAgentSession(ctx, stale.ID)
 	assert.Error(t, err, "stale session should be deleted")
@@ -1113,7 +2161,71 @@ func TestCleanupSessions_PreservesNonStale(t *testing.T) {
 	assert.NoError(t, err, "active session should survive")
 }
 
-// TestReconcileSessions_Flows tests reconciliation through the list API.
+// TestCleanupSessions_Overrides verifies request-level thresholds and dry-run.
+func TestCleanupSessions_Overrides(t *testing.T) {
+	srv, s, _, repoPath := setupE2EServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	proj := createProject(t, s, "cleanup-overrides", repoPath)
+
+	short := createSession(t, s, proj.ID, "", "feature/short", "/tmp/o1", models.SessionStatusAbandoned)
+	shortEnd := short.StartedAt.Add(10 * time.Second)
+	short.EndedAt = &shortEnd
+	require.NoError(t, s.UpdateAgentSession(ctx, short))
+
+	withCommits := createSession(t, s, proj.ID, "", "feature/two-commits", "/tmp/o2", models.SessionStatusAbandoned)
+	withCommits.CommitCount = 2
+	commitsEnd := withCommits.StartedAt.Add(3 * time.Minute)
+	withCommits.EndedAt = &commitsEnd
+	require.NoError(t, s.UpdateAgentSession(ctx, withCommits))
+
+	completed := createSession(t, s, proj.ID, "", "feature/done", "/tmp/o3", models.SessionStatusCompleted)
+	doneEnd := completed.StartedAt.Add(5 * time.Second)
+	completed.EndedAt = &doneEnd
+	require.NoError(t, s.UpdateAgentSession(ctx, completed))
+
+	// Dry run with widened thresholds reports both abandoned sessions but deletes nothing.
+	w := doJSON(t, router, "DELETE", "/api/v1/sessions/cleanup?max_commits=2&max_duration=5m&dry_run=true", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	result := decodeJSON[cleanupResponse](t, w)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, int64(2), result.Deleted)
+	assert.Len(t, result.Sessions, 2)
+	assert.Equal(t, 2, result.Criteria.MaxCommits)
+	assert.Equal(t, "5m0s", result.Criteria.MaxDuration)
+	assert.Equal(t, int64(300), result.Criteria.MaxDurationSeconds)
+
+	_, err := s.GetAgentSession(ctx, short.ID)
+	assert.NoError(t, err, "dry run must not delete")
+
+	// Status override targets completed sessions only.
+	w = doJSON(t, router, "DELETE", "/api/v1/sessions/cleanup?statuses=completed", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	result = decodeJSON[cleanupResponse](t, w)
+	assert.Equal(t, int64(1), result.Deleted)
+	assert.Equal(t, []string{"completed"}, result.Criteria.Statuses)
+
+	_, err = s.GetAgentSession(ctx, completed.ID)
+	assert.Error(t, err, "completed short session should be deleted")
+
+	// min_age excludes sessions that ended recently.
+	w = doJSON(t, router, "DELETE", "/api/v1/sessions/cleanup?min_age=24h", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	result = decodeJSON[cleanupResponse](t, w)
+	assert.Equal(t, int64(0), result.Deleted)
+
+	// Invalid parameters are rejected.
+	w = doJSON(t, router, "DELETE", "/api/v1/sessions/cleanup?max_duration=soon", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(t, router, "DELETE", "/api/v1/sessions/cleanup?max_duration=0", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "no duration bound would delete every abandoned session")
+	w = doJSON(t, router, "DELETE", "/api/v1/sessions/cleanup?statuses=active", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestReconcileSessions_Flows tests that the list API only flags sessions
+// pending reconciliation and the background reconciler updates them.
 func TestReconcileSessions_Flows(t *testing.T) {
 	srv, s, _, repoPath := setupE2EServer(t)
 	router := srv.Router()
@@ -1121,12 +2233,31 @@ func TestReconcileSessions_Flows(t *testing.T) {
 
 	proj := createProject(t, s, "reconcile-flows", repoPath)
 
+	listPending := func(t *testing.T, id string) models.SessionStatus {
+		t.Helper()
+		w := doJSON(t, router, "GET", "/api/v1/sessions", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		for _, sess := range decodeJSON[[]sessionResponse](t, w) {
+			if sess.ID == id {
+				return sess.PendingStatus
+			}
+		}
+		t.Fatalf("session %s not listed", id)
+		return ""
+	}
+
 	t.Run("idle with missing worktree gets abandoned", func(t *testing.T) {
 		sess := createSession(t, s, proj.ID, "", "feature/missing-r", "/tmp/does-not-exist", models.SessionStatusIdle)
-		doJSON(t, router, "GET", "/api/v1/sessions", nil)
+		assert.Equal(t, models.SessionStatusAbandoned, listPending(t, sess.ID))
 
 		dbSess, _ := s.GetAgentSession(ctx, sess.ID)
+		assert.Equal(t, models.SessionStatusIdle, dbSess.Status, "listing does not write")
+
+		_, err := srv.ReconcileSessions(ctx)
+		require.NoError(t, err)
+		dbSess, _ = s.GetAgentSession(ctx, sess.ID)
 		assert.Equal(t, models.SessionStatusAbandoned, dbSess.Status)
+		assert.Empty(t, listPending(t, sess.ID))
 	})
 
 	t.Run("abandoned with existing worktree recovers to idle", func(t *testing.T) {
@@ -1136,7 +2267,9 @@ func TestReconcileSessions_Flows(t *testing.T) {
 		sess.EndedAt = &now
 		require.NoError(t, s.UpdateAgentSession(ctx, sess))
 
-		doJSON(t, router, "GET", "/api/v1/sessions", nil)
+		assert.Equal(t, models.SessionStatusIdle, listPending(t, sess.ID))
+		_, err := srv.ReconcileSessions(ctx)
+		require.NoError(t, err)
 
 		dbSess, _ := s.GetAgentSession(ctx, sess.ID)
 		assert.Equal(t, models.SessionStatusIdle, dbSess.Status)
@@ -1150,11 +2283,32 @@ func TestReconcileSessions_Flows(t *testing.T) {
 		sess.EndedAt = &now
 		require.NoError(t, s.UpdateAgentSession(ctx, sess))
 
-		doJSON(t, router, "GET", "/api/v1/sessions", nil)
+		assert.Empty(t, listPending(t, sess.ID))
+		_, err := srv.ReconcileSessions(ctx)
+		require.NoError(t, err)
 
 		dbSess, _ := s.GetAgentSession(ctx, sess.ID)
 		assert.Equal(t, models.SessionStatusCompleted, dbSess.Status)
 	})
+
+	t.Run("recently changed sessions cool down", func(t *testing.T) {
+		wtDir := t.TempDir()
+		sess := createSession(t, s, proj.ID, "", "feature/flapping", wtDir, models.SessionStatusIdle)
+		require.NoError(t, os.Remove(wtDir))
+		n, err := srv.ReconcileSessions(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		// The worktree comes back at once: the session stays abandoned
+		// until the cooldown has passed.
+		require.NoError(t, os.Mkdir(wtDir, 0o755))
+		n, err = srv.ReconcileSessions(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, n)
+		dbSess, _ := s.GetAgentSession(ctx, sess.ID)
+		assert.Equal(t, models.SessionStatusAbandoned, dbSess.Status)
+		assert.Equal(t, models.SessionStatusIdle, listPending(t, sess.ID))
+	})
 }
 
 // TestSyncSession_RealGit tests session sync against a real git repo.
@@ -1195,6 +2349,72 @@ func TestSyncSession_RealGit(t *testing.T) {
 	assert.NoError(t, err, "synced file should exist in worktree")
 }
 
+// TestCancelOperation cancels a sync stuck in a git hook: the git process is
+// killed, the sync fails with 409 and the worktree is left as it was.
+func TestCancelOperation(t *testing.T) {
+	srv, s, _, repoPath := setupE2EServer(t)
+	router := srv.Router()
+
+	proj := createProject(t, s, "cancel-test", repoPath)
+	issue := createIssue(t, s, proj.ID, "Cancel test issue")
+	w := doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+		"project_id": proj.ID,
+		"issue_ids":  []string{issue.ID},
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	launchResp := decodeJSON[LaunchAgentResponse](t, w)
+	opsPath := fmt.Sprintf("/api/v1/sessions/%s/operations", launchResp.SessionID)
+
+	gitCommitFile(t, launchResp.WorktreePath, "feature.txt", "feature\n", "feature work")
+	gitCommitFile(t, repoPath, "main-update.txt", "from main\n", "main branch update")
+	head, err := exec.Command("git", "-C", launchResp.WorktreePath, "rev-parse", "HEAD").Output()
+	require.NoError(t, err)
+	hook := filepath.Join(repoPath, ".git", "hooks", "pre-rebase")
+	require.NoError(t, os.WriteFile(hook, []byte("#!/bin/sh\nsleep 30\n"), 0o755))
+
+	w = doJSON(t, router, "GET", opsPath, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, decodeJSON[[]sessions.Operation](t, w))
+
+	synced := make(chan *httptest.ResponseRecorder)
+	go func() {
+		synced <- doJSON(t, router, "POST", fmt.Sprintf("/api/v1/sessions/%s/sync", launchResp.SessionID), map[string]any{"rebase": true})
+	}()
+
+	var running sessions.Operation
+	require.Eventually(t, func() bool {
+		ops := decodeJSON[[]sessions.Operation](t, doJSON(t, router, "GET", opsPath, nil))
+		if len(ops) == 0 {
+			return false
+		}
+		running = ops[0]
+		return true
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Equal(t, sessions.OperationSync, running.Kind)
+	assert.Equal(t, sessions.OperationRunning, running.State)
+
+	w = doJSON(t, router, "POST", fmt.Sprintf("/api/v1/sessions/%s/merge", launchResp.SessionID), map[string]any{"cleanup": false})
+	assert.Equal(t, http.StatusConflict, w.Code, "merge body: %s", w.Body.String())
+
+	w = doJSON(t, router, "POST", opsPath+"/UNKNOWN/cancel", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doJSON(t, router, "POST", opsPath+"/"+running.ID+"/cancel", nil)
+	require.Equal(t, http.StatusOK, w.Code, "cancel body: %s", w.Body.String())
+	canceled := decodeJSON[sessions.Operation](t, w)
+	assert.Equal(t, sessions.OperationCanceled, canceled.State)
+	assert.Empty(t, canceled.RestoreError)
+
+	w = <-synced
+	assert.Equal(t, http.StatusConflict, w.Code, "sync body: %s", w.Body.String())
+	after, err := exec.Command("git", "-C", launchResp.WorktreePath, "rev-parse", "HEAD").Output()
+	require.NoError(t, err)
+	assert.Equal(t, string(head), string(after))
+
+	w = doJSON(t, router, "POST", opsPath+"/"+running.ID+"/cancel", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
 // TestSyncSession_NotFound verifies 404 for unknown session.
 func TestSyncSession_NotFound(t *testing.T) {
 	srv, _, _, _ := setupE2EServer(t)
@@ -1213,6 +2433,77 @@ func TestMergeSession_NotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
+// TestMergeSession_Strategies squash-merges a session with the project's
+// default strategy and refuses an ff-only merge of a branch behind main.
+func TestMergeSession_Strategies(t *testing.T) {
+	srv, s, _, repoPath := setupE2EServer(t)
+	router := srv.Router()
+	ctx := context.Background()
+
+	proj := createProject(t, s, "merge-test", repoPath)
+	proj.MergeStrategy = models.MergeStrategySquash
+	require.NoError(t, s.UpdateProject(ctx, proj))
+
+	launch := func(title string) LaunchAgentResponse {
+		issue := createIssue(t, s, proj.ID, title)
+		w := doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+			"project_id": proj.ID,
+			"issue_ids":  []string{issue.ID},
+		})
+		require.Equal(t, http.StatusOK, w.Code, "launch body: %s", w.Body.String())
+		return decodeJSON[LaunchAgentResponse](t, w)
+	}
+
+	w := doJSON(t, router, "POST", "/api/v1/sessions/x/merge", map[string]any{"strategy": "octopus"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	squashed := launch("Add squash feature")
+	gitCommitFile(t, squashed.WorktreePath, "a.txt", "a\n", "add a")
+	gitCommitFile(t, squashed.WorktreePath, "b.txt", "b\n", "add b")
+
+	w = doJSON(t, router, "POST", fmt.Sprintf("/api/v1/sessions/%s/merge", squashed.SessionID), map[string]any{"cleanup": false})
+	require.Equal(t, http.StatusOK, w.Code, "merge body: %s", w.Body.String())
+	result := decodeJSON[sessions.MergeResult](t, w)
+	assert.True(t, result.Success)
+	assert.Equal(t, models.MergeStrategySquash, result.Strategy)
+
+	head, err := exec.Command("git", "-C", repoPath, "rev-parse", "main").Output()
+	require.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(string(head)), result.MergeCommit)
+	msg, err := exec.Command("git", "-C", repoPath, "log", "-1", "--format=%B", "main").Output()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(msg), "Add squash feature\n"), "message: %s", msg)
+	assert.Contains(t, string(msg), "- add a\n- add b\n")
+
+	sess, err := s.GetAgentSession(ctx, squashed.SessionID)
+	require.NoError(t, err)
+	assert.Equal(t, result.MergeCommit, sess.MergeCommit)
+	issue, err := s.GetIssue(ctx, sess.IssueID)
+	require.NoError(t, err)
+	assert.Equal(t, result.MergeCommit, issue.MergeCommit)
+	assert.Equal(t, "main", issue.MergeBranch)
+	assert.NotNil(t, issue.MergedAt)
+
+	behind := launch("Behind main")
+	gitCommitFile(t, behind.WorktreePath, "c.txt", "c\n", "add c")
+	gitCommitFile(t, repoPath, "main-only.txt", "main\n", "main moves on")
+
+	w = doJSON(t, router, "POST", fmt.Sprintf("/api/v1/sessions/%s/merge", behind.SessionID), map[string]any{"strategy": "ff-only", "cleanup": false})
+	assert.Equal(t, http.StatusConflict, w.Code, "merge body: %s", w.Body.String())
+
+	// Cleanup deletes the session's branch by name, not by worktree directory
+	cleaned := launch("Clean up after merge")
+	gitCommitFile(t, cleaned.WorktreePath, "d.txt", "d\n", "add d")
+	w = doJSON(t, router, "POST", fmt.Sprintf("/api/v1/sessions/%s/merge", cleaned.SessionID), nil)
+	require.Equal(t, http.StatusOK, w.Code, "merge body: %s", w.Body.String())
+	result = decodeJSON[sessions.MergeResult](t, w)
+	assert.True(t, result.Cleaned)
+	require.NotNil(t, result.Cleanup)
+	assert.Equal(t, cleaned.Branch, result.Cleanup.Branch)
+	assert.True(t, result.Cleanup.BranchDeleted, "kept: %v", result.Cleanup.Kept)
+	assert.Error(t, exec.Command("git", "-C", repoPath, "show-ref", "--verify", "--quiet", "refs/heads/"+cleaned.Branch).Run())
+}
+
 // TestDiscoverWorktrees_RealGit tests discovery of untracked worktrees
 // against a real git repo.
 func TestDiscoverWorktrees_RealGit(t *testing.T) {
@@ -1301,3 +2592,43 @@ func TestIssueToBranch_Formatting(t *testing.T) {
 		})
 	}
 }
+
+func TestFocusSession(t *testing.T) {
+	srv, s, _, repoPath := setupE2EServer(t)
+	router := srv.Router()
+
+	sp := &fakeSpawner{}
+	WithTerminalSpawner(sp)(srv)
+	srv.terminals = func(backend string) (terminal.Terminal, error) {
+		if backend != terminal.BackendTmux {
+			return nil, fmt.Errorf("unexpected backend %q", backend)
+		}
+		return sp, nil
+	}
+
+	proj := createProject(t, s, "focus-test", repoPath)
+	issue := createIssue(t, s, proj.ID, "Add search")
+
+	w := doJSON(t, router, "POST", "/api/v1/agent/launch", map[string]any{
+		"project_id": proj.ID, "issue_ids": []string{issue.ID}, "spawn": true,
+	})
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	launched := decodeJSON[LaunchAgentResponse](t, w)
+
+	w = doJSON(t, router, "POST", "/api/v1/sessions/"+launched.SessionID+"/focus", nil)
+	require.Equal(t, http.StatusOK, w.Code, "body: %s", w.Body.String())
+	assert.Equal(t, []string{"pm-term-1"}, sp.focused)
+
+	// The terminal was closed by hand
+	sp.err = fmt.Errorf("focus tmux session pm-term-1: %w", terminal.ErrNotFound)
+	w = doJSON(t, router, "POST", "/api/v1/sessions/"+launched.SessionID+"/focus", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Sessions launched without a recorded terminal can't be focused
+	other := createSession(t, s, proj.ID, "", "feature/other", filepath.Join(repoPath+".worktrees", "other"), models.SessionStatusActive)
+	w = doJSON(t, router, "POST", "/api/v1/sessions/"+other.ID+"/focus", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doJSON(t, router, "POST", "/api/v1/sessions/nonexistent/focus", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
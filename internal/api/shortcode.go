@@ -0,0 +1,44 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// projectKeyStatus is the HTTP status for an error creating a project or
+// setting its key: 409 if another project holds the key.
+func projectKeyStatus(err error) int {
+	if errors.Is(err, store.ErrProjectKeyTaken) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// issueCodePath lets routes whose {id} is an issue take its short code
+// ("WID-12") too, replacing the code with the issue's ID before access
+// rules and the handler see it.
+func (s *Server) issueCodePath(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if code, isSession, ok := models.ParseShortCode(r.PathValue("id")); ok && !isSession {
+			if issue, err := s.store.GetIssueByCode(r.Context(), code); err == nil {
+				r.SetPathValue("id", issue.ID)
+			}
+		}
+		h(w, r)
+	}
+}
+
+// sessionCodePath is issueCodePath for routes whose {id} is a session.
+func (s *Server) sessionCodePath(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if code, isSession, ok := models.ParseShortCode(r.PathValue("id")); ok && isSession {
+			if sess, err := s.store.GetAgentSessionByCode(r.Context(), code); err == nil {
+				r.SetPathValue("id", sess.ID)
+			}
+		}
+		h(w, r)
+	}
+}
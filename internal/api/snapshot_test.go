@@ -0,0 +1,51 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/api"
+	"github.com/joescharf/pm/internal/apitest"
+	"github.com/joescharf/pm/internal/git"
+)
+
+func TestSnapshot_ProjectsAndIssues(t *testing.T) {
+	h := apitest.New(t)
+	p := h.Project("api")
+	h.Issue(p, "Add export")
+
+	h.Snapshot("projects", h.Get("/api/v1/projects"))
+
+	resp := h.Post("/api/v1/projects/"+p.ID+"/issues", map[string]any{
+		"title":    "Fix login crash",
+		"type":     "bug",
+		"priority": "high",
+	})
+	require.Equal(t, http.StatusCreated, resp.Code, string(resp.Body))
+	h.Snapshot("create-issue", resp)
+
+	h.Snapshot("issues", h.Get("/api/v1/issues?project_id="+p.ID))
+	h.Snapshot("missing-issue", h.Get("/api/v1/issues/01J5ABCD1234EFGH5678IJKLMN"))
+}
+
+func TestSnapshot_LaunchAgent(t *testing.T) {
+	h := apitest.New(t, api.WithCloseCheckEnforcement(false))
+	p := h.Project("api")
+	issue := h.Issue(p, "Add export")
+	h.Git.Commits = []git.Commit{{Hash: "abc123", Subject: "feat: export"}}
+
+	resp := h.Post("/api/v1/agent/launch", map[string]any{
+		"project_id": p.ID,
+		"issue_ids":  []string{issue.ID},
+	})
+	require.Equal(t, http.StatusOK, resp.Code, string(resp.Body))
+	h.Snapshot("launch", resp)
+	require.Len(t, h.WT.Created, 1)
+	assert.Equal(t, "feature/add-export", h.WT.Created[0].Branch)
+
+	h.Snapshot("sessions", h.Get("/api/v1/sessions?project_id="+p.ID))
+	h.Snapshot("issue", h.Get("/api/v1/issues/"+issue.ID))
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// listTags returns every tag with how many issues, and open issues, carry it.
+func (s *Server) listTags(w http.ResponseWriter, r *http.Request) {
+	usage, err := s.store.ListTagUsage(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if usage == nil {
+		usage = []*models.TagUsage{}
+	}
+	writeJSON(w, http.StatusOK, usage)
+}
+
+// RenameTagRequest is the JSON body for PUT /api/v1/tags/{id}.
+type RenameTagRequest struct {
+	Name string `json:"name"`
+}
+
+// renameTag renames the {id} tag, given by ID or name; its issues keep it.
+func (s *Server) renameTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tag, err := store.FindTag(ctx, s.store, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	var req RenameTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if err := s.store.RenameTag(ctx, tag.ID, req.Name); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	tag.Name = req.Name
+	writeJSON(w, http.StatusOK, tag)
+}
+
+// mergeTag moves the issues tagged {id} over to the {target} tag, both given
+// by ID or name, deletes {id} and returns the target with its new counts.
+func (s *Server) mergeTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tag, err := store.FindTag(ctx, s.store, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	target, err := store.FindTag(ctx, s.store, r.PathValue("target"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if tag.ID == target.ID {
+		writeError(w, http.StatusBadRequest, "cannot merge a tag into itself")
+		return
+	}
+	if err := s.store.MergeTag(ctx, tag.ID, target.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	usage, err := s.store.ListTagUsage(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, u := range usage {
+		if u.ID == target.ID {
+			writeJSON(w, http.StatusOK, u)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "tag not found: "+target.ID)
+}
+
+// TagBreakdownResponse is the response of GET /api/v1/tags/{id}/breakdown:
+// the tag's issues counted by status, priority, type and project name.
+type TagBreakdownResponse struct {
+	Tag        *models.Tag                  `json:"tag"`
+	Issues     int                          `json:"issues"`
+	ByStatus   map[models.IssueStatus]int   `json:"by_status"`
+	ByPriority map[models.IssuePriority]int `json:"by_priority"`
+	ByType     map[models.IssueType]int     `json:"by_type"`
+	ByProject  map[string]int               `json:"by_project"`
+}
+
+func (s *Server) tagBreakdown(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tag, err := store.FindTag(ctx, s.store, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	b, err := store.BreakdownTag(ctx, s.store, tag)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, TagBreakdownResponse{
+		Tag:        b.Tag,
+		Issues:     b.Issues,
+		ByStatus:   b.ByStatus,
+		ByPriority: b.ByPriority,
+		ByType:     b.ByType,
+		ByProject:  b.ByProject,
+	})
+}
+
+// TagCleanupResponse is the response of DELETE /api/v1/tags/orphans.
+type TagCleanupResponse struct {
+	Deleted int           `json:"deleted"`
+	DryRun  bool          `json:"dry_run"`
+	Tags    []*models.Tag `json:"tags"`
+}
+
+// deleteOrphanTags deletes the tags no issue carries. With dry_run=true it
+// only lists them.
+func (s *Server) deleteOrphanTags(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := TagCleanupResponse{DryRun: r.URL.Query().Get("dry_run") == "true", Tags: []*models.Tag{}}
+	if resp.DryRun {
+		usage, err := s.store.ListTagUsage(ctx)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		for _, u := range usage {
+			if u.Issues == 0 {
+				resp.Tags = append(resp.Tags, &u.Tag)
+			}
+		}
+	} else {
+		deleted, err := s.store.DeleteUnusedTags(ctx)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if deleted != nil {
+			resp.Tags = deleted
+		}
+	}
+	resp.Deleted = len(resp.Tags)
+	writeJSON(w, http.StatusOK, resp)
+}
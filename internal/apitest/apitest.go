@@ -0,0 +1,171 @@
+// Package apitest runs the REST API end to end for tests: a real Server and
+// router over HTTP, a migrated SQLite database in a temp directory, and
+// scripted git, GitHub and wt fakes. Responses can be checked against golden
+// JSON snapshots in the calling package's testdata/snapshots directory;
+// run the tests with -update to rewrite them.
+//
+//	h := apitest.New(t)
+//	p := h.Project("api")
+//	h.Snapshot("list", h.Get("/api/v1/projects/"+p.ID+"/issues"))
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/api"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// Harness is a running API server with its store and fakes.
+type Harness struct {
+	T      testing.TB
+	Dir    string // temp directory holding the database and project repos
+	Store  *store.SQLiteStore
+	Git    *FakeGit
+	GitHub *FakeGitHub
+	WT     *FakeWT
+	Server *api.Server
+	HTTP   *httptest.Server
+
+	// Header is sent with every request (for example Authorization).
+	Header http.Header
+
+	scrub []replacement
+	ids   map[string]bool // short IDs of records seen, for snapshots
+}
+
+// New starts a server with the given options on a fresh database. Everything
+// is torn down when the test ends.
+func New(t testing.TB, opts ...api.Option) *Harness {
+	t.Helper()
+	dir := t.TempDir()
+	s, err := store.NewSQLiteStore(filepath.Join(dir, "pm.db"))
+	require.NoError(t, err)
+	require.NoError(t, s.Migrate(context.Background()))
+	t.Cleanup(func() { _ = s.Close() })
+
+	h := &Harness{
+		T:      t,
+		Dir:    dir,
+		Store:  s,
+		Git:    NewFakeGit(),
+		GitHub: &FakeGitHub{},
+		WT:     &FakeWT{},
+		Header: http.Header{},
+		ids:    make(map[string]bool),
+	}
+	h.Server = api.NewServer(s, h.Git, h.GitHub, h.WT, nil, opts...)
+	h.HTTP = httptest.NewServer(h.Server.Router())
+	t.Cleanup(h.HTTP.Close)
+	h.Scrub(dir, "<dir>")
+	return h
+}
+
+// Project creates a project whose repo is a directory under the harness's
+// temp directory.
+func (h *Harness) Project(name string) *models.Project {
+	h.T.Helper()
+	path := filepath.Join(h.Dir, "repos", name)
+	require.NoError(h.T, os.MkdirAll(path, 0o755))
+	p := &models.Project{Name: name, Path: path, RepoURL: "https://github.com/acme/" + name}
+	require.NoError(h.T, h.Store.CreateProject(context.Background(), p))
+	h.ids[p.ID[:12]] = true
+	return p
+}
+
+// Issue creates an open, medium-priority feature issue in the project.
+func (h *Harness) Issue(p *models.Project, title string) *models.Issue {
+	h.T.Helper()
+	issue := &models.Issue{
+		ProjectID: p.ID,
+		Title:     title,
+		Status:    models.IssueStatusOpen,
+		Priority:  models.IssuePriorityMedium,
+		Type:      models.IssueTypeFeature,
+	}
+	require.NoError(h.T, h.Store.CreateIssue(context.Background(), issue))
+	h.ids[issue.ID[:12]] = true
+	return issue
+}
+
+// Response is a completed request.
+type Response struct {
+	Code   int
+	Header http.Header
+	Body   []byte
+}
+
+// JSON decodes the body into v, failing the test if it is not valid JSON.
+func (r *Response) JSON(t testing.TB, v any) {
+	t.Helper()
+	require.NoError(t, json.Unmarshal(r.Body, v), string(r.Body))
+}
+
+// Do sends a request. A non-nil body is encoded as JSON unless it is a
+// string or []byte, which are sent as is.
+func (h *Harness) Do(method, path string, body any) *Response {
+	h.T.Helper()
+	var r io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		r = bytes.NewBufferString(b)
+	case []byte:
+		r = bytes.NewReader(b)
+	default:
+		data, err := json.Marshal(b)
+		require.NoError(h.T, err)
+		r = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, h.HTTP.URL+path, r)
+	require.NoError(h.T, err)
+	for k, vs := range h.Header {
+		req.Header[k] = vs
+	}
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := h.HTTP.Client().Do(req)
+	require.NoError(h.T, err)
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(h.T, err)
+	return &Response{Code: resp.StatusCode, Header: resp.Header, Body: data}
+}
+
+// Get sends a GET request.
+func (h *Harness) Get(path string) *Response {
+	h.T.Helper()
+	return h.Do(http.MethodGet, path, nil)
+}
+
+// Post sends a POST request with a JSON body.
+func (h *Harness) Post(path string, body any) *Response {
+	h.T.Helper()
+	return h.Do(http.MethodPost, path, body)
+}
+
+// Put sends a PUT request with a JSON body.
+func (h *Harness) Put(path string, body any) *Response {
+	h.T.Helper()
+	return h.Do(http.MethodPut, path, body)
+}
+
+// Delete sends a DELETE request.
+func (h *Harness) Delete(path string) *Response {
+	h.T.Helper()
+	return h.Do(http.MethodDelete, path, nil)
+}
@@ -0,0 +1,274 @@
+package apitest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joescharf/wt/pkg/lifecycle"
+
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/wt"
+)
+
+// FakeGit is a scripted git.Client. Every repo answers with the same fields,
+// so tests set only what the endpoint under test reads. Calls records each
+// method called, as "Method path".
+type FakeGit struct {
+	mu sync.Mutex
+
+	Branch       string
+	Dirty        bool
+	LastCommit   time.Time
+	CommitMsg    string
+	CommitHash   string
+	Branches     []string
+	Remote       string
+	Tag          string
+	Ahead        int
+	Behind       int
+	Commits      []git.Commit // returned by CommitLog and, as messages, CommitMessagesSince
+	DiffText     string
+	ChangedFiles []string
+	Fingerprint  string
+	Worktrees    []git.WorktreeInfo
+
+	// Delay makes every call take this long, to test timeouts.
+	Delay time.Duration
+
+	Calls []string
+}
+
+// NewFakeGit returns a FakeGit for a clean repo on main with one commit.
+func NewFakeGit() *FakeGit {
+	return &FakeGit{
+		Branch:     "main",
+		LastCommit: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		CommitMsg:  "feat: initial commit",
+		CommitHash: "0123456789abcdef0123456789abcdef01234567",
+		Branches:   []string{"main"},
+		Remote:     "https://github.com/acme/app.git",
+		Tag:        "v1.0.0",
+	}
+}
+
+func (f *FakeGit) record(method, path string) {
+	f.mu.Lock()
+	f.Calls = append(f.Calls, method+" "+path)
+	delay := f.Delay
+	f.mu.Unlock()
+	time.Sleep(delay)
+}
+
+func (f *FakeGit) RepoRoot(_ context.Context, path string) (string, error) {
+	f.record("RepoRoot", path)
+	return path, nil
+}
+
+func (f *FakeGit) CurrentBranch(_ context.Context, path string) (string, error) {
+	f.record("CurrentBranch", path)
+	return f.Branch, nil
+}
+
+func (f *FakeGit) LastCommitDate(_ context.Context, path string) (time.Time, error) {
+	f.record("LastCommitDate", path)
+	return f.LastCommit, nil
+}
+
+func (f *FakeGit) LastCommitMessage(_ context.Context, path string) (string, error) {
+	f.record("LastCommitMessage", path)
+	return f.CommitMsg, nil
+}
+
+func (f *FakeGit) LastCommitHash(_ context.Context, path string) (string, error) {
+	f.record("LastCommitHash", path)
+	return f.CommitHash, nil
+}
+
+func (f *FakeGit) BranchList(_ context.Context, path string) ([]string, error) {
+	f.record("BranchList", path)
+	return f.Branches, nil
+}
+
+func (f *FakeGit) IsDirty(_ context.Context, path string) (bool, error) {
+	f.record("IsDirty", path)
+	return f.Dirty, nil
+}
+
+func (f *FakeGit) WorktreeList(_ context.Context, path string) ([]git.WorktreeInfo, error) {
+	f.record("WorktreeList", path)
+	return f.Worktrees, nil
+}
+
+func (f *FakeGit) RemoteURL(_ context.Context, path string) (string, error) {
+	f.record("RemoteURL", path)
+	return f.Remote, nil
+}
+
+func (f *FakeGit) LatestTag(_ context.Context, path string) (string, error) {
+	f.record("LatestTag", path)
+	if f.Tag == "" {
+		return "", fmt.Errorf("no tags")
+	}
+	return f.Tag, nil
+}
+
+func (f *FakeGit) CommitCountSince(_ context.Context, path, _ string) (int, error) {
+	f.record("CommitCountSince", path)
+	return len(f.Commits), nil
+}
+
+func (f *FakeGit) CommitMessagesSince(_ context.Context, path, _ string) ([]string, error) {
+	f.record("CommitMessagesSince", path)
+	msgs := make([]string, 0, len(f.Commits))
+	for i := len(f.Commits) - 1; i >= 0; i-- { // newest first
+		msgs = append(msgs, f.Commits[i].Subject)
+	}
+	return msgs, nil
+}
+
+func (f *FakeGit) CommitLog(_ context.Context, path, _, _ string) ([]git.Commit, error) {
+	f.record("CommitLog", path)
+	return f.Commits, nil
+}
+
+func (f *FakeGit) AheadBehind(_ context.Context, path, _ string) (int, int, error) {
+	f.record("AheadBehind", path)
+	return f.Ahead, f.Behind, nil
+}
+
+func (f *FakeGit) Diff(_ context.Context, path, _, _ string) (string, error) {
+	f.record("Diff", path)
+	return f.DiffText, nil
+}
+
+func (f *FakeGit) DiffStat(_ context.Context, path, _, _ string) (string, error) {
+	f.record("DiffStat", path)
+	return fmt.Sprintf("%d files changed", len(f.ChangedFiles)), nil
+}
+
+func (f *FakeGit) DiffNameOnly(_ context.Context, path, _, _ string) ([]string, error) {
+	f.record("DiffNameOnly", path)
+	return f.ChangedFiles, nil
+}
+
+func (f *FakeGit) Blame(_ context.Context, path, _, _ string, _ []git.LineRange) ([]git.BlameLine, error) {
+	f.record("Blame", path)
+	return nil, nil
+}
+
+func (f *FakeGit) WorktreeFingerprint(_ context.Context, path string) (string, error) {
+	f.record("WorktreeFingerprint", path)
+	return f.Fingerprint, nil
+}
+
+func (f *FakeGit) CommitAll(_ context.Context, path, message string) (string, error) {
+	f.record("CommitAll", path)
+	return f.CommitHash, nil
+}
+
+// FakeGitHub is a scripted git.GitHubClient. Nil fields answer with an error,
+// as gh does for repos without releases or GitHub metadata.
+type FakeGitHub struct {
+	Release *git.Release
+	PRs     []git.PullRequest
+	Repo    *git.RepoInfo
+	Pages   *git.PagesResult
+}
+
+func (f *FakeGitHub) LatestRelease(_, _ string) (*git.Release, error) {
+	if f.Release == nil {
+		return nil, fmt.Errorf("no release")
+	}
+	return f.Release, nil
+}
+
+func (f *FakeGitHub) OpenPRs(_, _ string) ([]git.PullRequest, error) { return f.PRs, nil }
+
+func (f *FakeGitHub) RepoInfo(_, _ string) (*git.RepoInfo, error) {
+	if f.Repo == nil {
+		return nil, fmt.Errorf("no repo info")
+	}
+	return f.Repo, nil
+}
+
+func (f *FakeGitHub) PagesInfo(_, _ string) (*git.PagesResult, error) { return f.Pages, nil }
+
+// FakeWT is a wt.Client that creates worktree directories where wt would
+// (<repo>.worktrees/<last branch segment>) without running git, and records
+// them. Set CreateErr to make creation fail.
+type FakeWT struct {
+	mu sync.Mutex
+
+	Created   []wt.WorktreeInfo
+	Deleted   []string // branches
+	CreateErr error
+}
+
+func (f *FakeWT) Create(repoPath, branch string) error {
+	_, err := f.CreateWith(repoPath, branch, wt.CreateOptions{})
+	return err
+}
+
+func (f *FakeWT) CreateHeadless(repoPath, branch string) error {
+	_, err := f.CreateWith(repoPath, branch, wt.CreateOptions{Headless: true})
+	return err
+}
+
+func (f *FakeWT) CreateWith(repoPath, branch string, opts wt.CreateOptions) ([]string, error) {
+	if f.CreateErr != nil {
+		return nil, f.CreateErr
+	}
+	parts := strings.Split(branch, "/")
+	path := filepath.Join(repoPath+".worktrees", parts[len(parts)-1])
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Created = append(f.Created, wt.WorktreeInfo{Path: path, Branch: branch, Repo: repoPath})
+	return opts.SparsePaths, nil
+}
+
+func (f *FakeWT) List(repoPath string) ([]wt.WorktreeInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []wt.WorktreeInfo
+	for _, w := range f.Created {
+		if w.Repo == repoPath {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+func (f *FakeWT) Delete(repoPath, branch string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Deleted = append(f.Deleted, branch)
+	for i, w := range f.Created {
+		if w.Repo == repoPath && w.Branch == branch {
+			f.Created = append(f.Created[:i], f.Created[i+1:]...)
+			return os.RemoveAll(w.Path)
+		}
+	}
+	return nil
+}
+
+func (f *FakeWT) TerminalID(_ string) string { return "" }
+
+// Lifecycle and LifecycleForRepo return nil: flows that need wt's lifecycle
+// manager (merge cleanup, worktree deletion) are out of the harness's reach.
+func (f *FakeWT) Lifecycle() *lifecycle.Manager { return nil }
+
+func (f *FakeWT) LifecycleForRepo(_ string) *lifecycle.Manager { return nil }
+
+var (
+	_ git.Client       = (*FakeGit)(nil)
+	_ git.GitHubClient = (*FakeGitHub)(nil)
+	_ wt.Client        = (*FakeWT)(nil)
+)
@@ -0,0 +1,182 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite apitest snapshots with the current responses")
+
+var (
+	ulidRe    = regexp.MustCompile(`\b[0-9A-HJKMNP-TV-Z]{26}\b`)
+	shortIDRe = regexp.MustCompile(`\b[0-9A-HJKMNP-TV-Z]{12}\b`)
+)
+
+type replacement struct{ old, new string }
+
+// Scrub replaces old with new in every snapshot string, for values that
+// differ between runs but are not IDs or timestamps (ports, generated tokens).
+// The harness's temp directory is scrubbed to "<dir>".
+func (h *Harness) Scrub(old, new string) {
+	if old != "" {
+		h.scrub = append(h.scrub, replacement{old, new})
+	}
+}
+
+// Snapshot compares the response's status and JSON body with the golden file
+// testdata/snapshots/<test name>/<name>.json, writing it instead when the
+// tests run with -update or the file does not exist yet.
+//
+// Bodies are normalized first: object keys are sorted, ULIDs and their
+// 12-character short forms become "<id:N>", and RFC 3339 timestamps become
+// "<time>", so snapshots are stable across runs.
+func (h *Harness) Snapshot(name string, resp *Response) {
+	h.T.Helper()
+	got := h.normalize(resp)
+	path := filepath.Join("testdata", "snapshots", filepath.FromSlash(h.T.Name()), name+".json")
+
+	want, err := os.ReadFile(path)
+	if *update || os.IsNotExist(err) {
+		require.NoError(h.T, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(h.T, os.WriteFile(path, got, 0o644))
+		return
+	}
+	require.NoError(h.T, err)
+	assert.Equal(h.T, string(want), string(got), "response differs from %s (run the tests with -update to accept it)", path)
+}
+
+// normalize renders a response as the indented, scrubbed JSON stored in
+// snapshots.
+func (h *Harness) normalize(resp *Response) []byte {
+	var body any
+	if len(resp.Body) > 0 {
+		if err := json.Unmarshal(resp.Body, &body); err != nil {
+			body = strings.TrimSpace(string(resp.Body)) // not JSON: keep the text
+		}
+	}
+	n := &normalizer{scrub: h.scrub, ids: make(map[string]string), known: h.ids}
+	n.collect(body)
+	for _, id := range n.order {
+		h.ids[id[:12]] = true
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	require.NoError(h.T, enc.Encode(map[string]any{
+		"status": resp.Code,
+		"body":   n.value(body),
+	}))
+	return buf.Bytes()
+}
+
+// normalizer rewrites the values of one response. IDs are numbered in the
+// order collect finds them, visiting object keys sorted, so the numbering is
+// the same on every run. The 12-character short form of an ID gets the same
+// placeholder, and short IDs of records seen before (created by the harness
+// or in earlier responses) get their own.
+type normalizer struct {
+	scrub []replacement
+	ids   map[string]string // full or short ID -> placeholder
+	order []string          // IDs in numbering order
+	known map[string]bool   // short IDs seen by the harness
+}
+
+func (n *normalizer) collect(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for _, k := range sortedKeys(v) {
+			n.collect(k)
+			n.collect(v[k])
+		}
+	case []any:
+		for _, e := range v {
+			n.collect(e)
+		}
+	case string:
+		v = n.scrubbed(v)
+		for _, id := range ulidRe.FindAllString(v, -1) {
+			n.number(id)
+		}
+		for _, id := range shortIDRe.FindAllString(v, -1) {
+			if n.known[id] {
+				n.number(id)
+			}
+		}
+	}
+}
+
+func (n *normalizer) number(id string) {
+	if _, ok := n.ids[id]; ok {
+		return
+	}
+	placeholder := fmt.Sprintf("<id:%d>", len(n.order)+1)
+	n.ids[id] = placeholder
+	if _, ok := n.ids[id[:12]]; !ok {
+		n.ids[id[:12]] = placeholder
+	}
+	n.order = append(n.order, id)
+}
+
+func (n *normalizer) value(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[n.str(k)] = n.value(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = n.value(e)
+		}
+		return out
+	case string:
+		return n.str(v)
+	default:
+		return v
+	}
+}
+
+func (n *normalizer) scrubbed(s string) string {
+	for _, r := range n.scrub {
+		s = strings.ReplaceAll(s, r.old, r.new)
+	}
+	return s
+}
+
+func (n *normalizer) str(s string) string {
+	s = n.scrubbed(s)
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil && !t.IsZero() {
+		return "<time>"
+	}
+	s = ulidRe.ReplaceAllStringFunc(s, func(id string) string { return n.ids[id] })
+	return shortIDRe.ReplaceAllStringFunc(s, func(id string) string {
+		if p, ok := n.ids[id]; ok {
+			return p
+		}
+		return id
+	})
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
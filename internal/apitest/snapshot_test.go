@@ -0,0 +1,38 @@
+package apitest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	h := &Harness{T: t, ids: map[string]bool{"01J5ABCD1234": true}}
+	h.Scrub("/tmp/x", "<dir>")
+	resp := &Response{Code: 200, Body: []byte(`{
+		"z": "01J5ZZZZZZZZZZZZZZZZZZZZZZ",
+		"a": {"id": "01J5YYYYYYYYYYYYYYYYYYYYYY", "at": "2025-01-02T03:04:05.123Z", "zero": "0001-01-01T00:00:00Z"},
+		"path": "/tmp/x/repos/a",
+		"cmd": "issue(s) 01J5ABCD1234 and 01J5ZZZZZZZZ",
+		"html": "a && b"
+	}`)}
+
+	assert.Equal(t, `{
+  "body": {
+    "a": {
+      "at": "<time>",
+      "id": "<id:1>",
+      "zero": "0001-01-01T00:00:00Z"
+    },
+    "cmd": "issue(s) <id:2> and <id:3>",
+    "html": "a && b",
+    "path": "<dir>/repos/a",
+    "z": "<id:3>"
+  },
+  "status": 200
+}
+`, string(h.normalize(resp)))
+
+	assert.Contains(t, string(h.normalize(&Response{Code: 204})), `"body": null`)
+	assert.Contains(t, string(h.normalize(&Response{Code: 500, Body: []byte("boom\n")})), `"body": "boom"`)
+}
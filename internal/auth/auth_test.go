@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+type tokenMap map[string]*models.APIToken
+
+func (m tokenMap) GetAPITokenByHash(_ context.Context, hash string) (*models.APIToken, error) {
+	if t, ok := m[hash]; ok {
+		return t, nil
+	}
+	return nil, fmt.Errorf("api token not found")
+}
+
+func TestAuthenticate(t *testing.T) {
+	token, hash, err := NewToken()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, TokenPrefix))
+	assert.Equal(t, HashToken(token), hash)
+	assert.NotContains(t, hash, token)
+
+	tokens := tokenMap{hash: {ID: "T1", ProjectID: "P1"}}
+	sc, err := Authenticate(context.Background(), tokens, token)
+	require.NoError(t, err)
+	assert.Equal(t, Scope{TokenID: "T1", ProjectID: "P1"}, sc)
+	assert.True(t, sc.Allows("P1"))
+	assert.False(t, sc.Allows("P2"))
+	assert.False(t, sc.Allows(""))
+
+	_, err = Authenticate(context.Background(), tokens, token+"x")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = Authenticate(context.Background(), tokens, "")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	share, shareHash, err := NewShareToken()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(share, ShareTokenPrefix))
+	tokens[shareHash] = &models.APIToken{ID: "T2", ProjectID: "P1"}
+	_, err = Authenticate(context.Background(), tokens, share)
+	assert.ErrorIs(t, err, ErrInvalidToken, "share link tokens are not API tokens")
+}
+
+func TestBearerToken(t *testing.T) {
+	assert.Equal(t, "pm_abc", BearerToken("Bearer pm_abc"))
+	assert.Equal(t, "pm_abc", BearerToken("bearer  pm_abc "))
+	assert.Empty(t, BearerToken("Basic dXNlcjpwYXNz"))
+	assert.Empty(t, BearerToken(""))
+}
+
+func TestContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+	sc, ok := FromContext(WithScope(context.Background(), Scope{ProjectID: "P1"}))
+	assert.True(t, ok)
+	assert.Equal(t, "P1", sc.ProjectID)
+}
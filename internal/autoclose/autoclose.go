@@ -0,0 +1,205 @@
+package autoclose
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// Store is the subset of store.Store needed to close and reopen issues.
+type Store interface {
+	GetProject(ctx context.Context, id string) (*models.Project, error)
+	GetIssue(ctx context.Context, id string) (*models.Issue, error)
+	ListIssues(ctx context.Context, filter store.IssueListFilter) ([]*models.Issue, error)
+	UpdateIssue(ctx context.Context, issue *models.Issue) error
+	RecordIssueClosure(ctx context.Context, c *models.IssueClosure) (bool, error)
+	GetIssueClosure(ctx context.Context, id string) (*models.IssueClosure, error)
+	ListIssueClosures(ctx context.Context, projectID, issueID string) ([]*models.IssueClosure, error)
+	RevertIssueClosure(ctx context.Context, id string) error
+}
+
+// GitHub closes and reopens the GitHub issues linked to pm issues.
+type GitHub interface {
+	CloseIssue(owner, repo string, number int, comment string) error
+	ReopenIssue(owner, repo string, number int) error
+}
+
+// Config controls automatic closure. The zero value disables it.
+type Config struct {
+	Enabled bool
+	// GitHub, when set, also closes the GitHub issue linked to a closed pm
+	// issue. References to GitHub issues in merged pull requests are left to
+	// GitHub, which closes those itself.
+	GitHub GitHub
+}
+
+// Source is a commit or merged pull request whose message may close issues.
+type Source struct {
+	Kind      string // models.ClosureSourceCommit or models.ClosureSourcePR
+	Ref       string // commit hash or "#<pr number>"
+	Message   string // full commit message, or pull request title and body
+	SessionID string
+}
+
+// Apply closes the project's issues referenced by the sources: open and
+// in-progress issues move to done and each closure is recorded. A reference
+// that already closed an issue, even if the closure was reverted since, is
+// not applied again. With dryRun nothing is changed and the closures that
+// would be made are returned.
+func Apply(ctx context.Context, s Store, cfg Config, project *models.Project, sources []Source, dryRun bool) ([]*models.IssueClosure, error) {
+	if !cfg.Enabled || len(sources) == 0 {
+		return nil, nil
+	}
+	issues, err := s.ListIssues(ctx, store.IssueListFilter{ProjectID: project.ID})
+	if err != nil {
+		return nil, fmt.Errorf("list issues: %w", err)
+	}
+	existing, err := s.ListIssueClosures(ctx, project.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	done := make(map[string]bool)
+	for _, c := range existing {
+		done[c.IssueID+" "+c.Source+" "+c.Ref] = true
+	}
+
+	var closures []*models.IssueClosure
+	for _, src := range sources {
+		for _, ref := range ParseRefs(src.Message) {
+			issue := resolve(issues, ref)
+			if issue == nil || issue.Status == models.IssueStatusDone || issue.Status == models.IssueStatusClosed {
+				continue
+			}
+			if done[issue.ID+" "+src.Kind+" "+src.Ref] {
+				continue
+			}
+			c := &models.IssueClosure{
+				IssueID:    issue.ID,
+				ProjectID:  project.ID,
+				SessionID:  src.SessionID,
+				Source:     src.Kind,
+				Ref:        src.Ref,
+				Message:    subject(src.Message),
+				FromStatus: issue.Status,
+				ToStatus:   models.IssueStatusDone,
+			}
+			issue.Status = models.IssueStatusDone
+			closures = append(closures, c)
+			if dryRun {
+				continue
+			}
+
+			if err := s.UpdateIssue(ctx, issue); err != nil {
+				return closures, fmt.Errorf("close issue %s: %w", issue.ID, err)
+			}
+			if cfg.GitHub != nil && issue.GitHubIssue > 0 && (src.Kind != models.ClosureSourcePR || ref.GitHub == 0) {
+				if owner, repo, err := git.ExtractOwnerRepo(project.RepoURL); err == nil {
+					comment := fmt.Sprintf("Closed by pm: %s %s referenced %s.", src.Kind, src.Ref, ref)
+					if cfg.GitHub.CloseIssue(owner, repo, issue.GitHubIssue, comment) == nil {
+						c.GitHubIssue = issue.GitHubIssue
+					}
+				}
+			}
+			if _, err := s.RecordIssueClosure(ctx, c); err != nil {
+				return closures, err
+			}
+		}
+	}
+	return closures, nil
+}
+
+// resolve finds the issue a reference points at: the issue linked to a GitHub
+// number or with a short code, or the one issue whose ID starts with a pm ID
+// prefix.
+func resolve(issues []*models.Issue, ref Ref) *models.Issue {
+	var match *models.Issue
+	for _, issue := range issues {
+		switch {
+		case ref.GitHub > 0 && issue.GitHubIssue == ref.GitHub:
+			return issue
+		case ref.Code != "" && issue.Code == ref.Code:
+			return issue
+		case ref.IssueID != "" && strings.HasPrefix(strings.ToUpper(issue.ID), ref.IssueID):
+			if match != nil {
+				return nil // ambiguous prefix
+			}
+			match = issue
+		}
+	}
+	return match
+}
+
+func subject(msg string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+	return strings.TrimSpace(line)
+}
+
+// Revert undoes a closure: the issue goes back to the status it had, the
+// GitHub issue closed with it is reopened, and the closure is marked
+// reverted. Issues that have left the status the closure gave them are not
+// touched.
+func Revert(ctx context.Context, s Store, gh GitHub, closureID string) (*models.IssueClosure, error) {
+	c, err := s.GetIssueClosure(ctx, closureID)
+	if err != nil {
+		return nil, err
+	}
+	if c.RevertedAt != nil {
+		return nil, fmt.Errorf("issue closure %s was already reverted", c.ID)
+	}
+	issue, err := s.GetIssue(ctx, c.IssueID)
+	if err != nil {
+		return nil, err
+	}
+	if issue.Status != c.ToStatus {
+		return nil, fmt.Errorf("issue %s is %s now, not %s; change its status instead", issue.ID, issue.Status, c.ToStatus)
+	}
+
+	if gh != nil && c.GitHubIssue > 0 {
+		if p, err := s.GetProject(ctx, c.ProjectID); err == nil {
+			if owner, repo, err := git.ExtractOwnerRepo(p.RepoURL); err == nil {
+				if err := gh.ReopenIssue(owner, repo, c.GitHubIssue); err != nil {
+					return nil, fmt.Errorf("reopen GitHub issue #%d: %w", c.GitHubIssue, err)
+				}
+			}
+		}
+	}
+	issue.Status = c.FromStatus
+	if err := s.UpdateIssue(ctx, issue); err != nil {
+		return nil, fmt.Errorf("reopen issue %s: %w", issue.ID, err)
+	}
+	if err := s.RevertIssueClosure(ctx, c.ID); err != nil {
+		return nil, err
+	}
+	return s.GetIssueClosure(ctx, c.ID)
+}
+
+// PRLister lists a repo's merged pull requests, newest first.
+type PRLister interface {
+	MergedPRs(owner, repo string, limit int) ([]git.PullRequest, error)
+}
+
+// ScanMergedPRs applies the closing references in the titles and bodies of
+// the project's last limit merged pull requests.
+func ScanMergedPRs(ctx context.Context, s Store, cfg Config, prs PRLister, project *models.Project, limit int, dryRun bool) ([]*models.IssueClosure, error) {
+	owner, repo, err := git.ExtractOwnerRepo(project.RepoURL)
+	if err != nil {
+		return nil, fmt.Errorf("project %s has no GitHub repo: %w", project.Name, err)
+	}
+	merged, err := prs.MergedPRs(owner, repo, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list merged pull requests: %w", err)
+	}
+	sources := make([]Source, 0, len(merged))
+	for _, pr := range merged {
+		sources = append(sources, Source{
+			Kind:    models.ClosureSourcePR,
+			Ref:     fmt.Sprintf("#%d", pr.Number),
+			Message: pr.Title + "\n\n" + pr.Body,
+		})
+	}
+	return Apply(ctx, s, cfg, project, sources, dryRun)
+}
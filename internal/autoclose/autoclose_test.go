@@ -0,0 +1,153 @@
+package autoclose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+func TestParseRefs(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want []Ref
+	}{
+		{"Fixes #12", []Ref{{GitHub: 12}}},
+		{"feat: parser\n\ncloses: pm-01j5abcd", []Ref{{IssueID: "01J5ABCD"}}},
+		{"Resolved #1, #2 and PM-01J5ABCD & #3", []Ref{{GitHub: 1}, {GitHub: 2}, {IssueID: "01J5ABCD"}, {GitHub: 3}}},
+		{"fix #4; also fixes #4", []Ref{{GitHub: 4}}},
+		{"See #12", nil},
+		{"Closes PM-abc", nil},
+		{"fixes wid-12 and API2-3", []Ref{{Code: "WID-12"}, {Code: "API2-3"}}},
+		{"Closes PM-12", []Ref{{Code: "PM-12"}}},
+		{"prefix #5", nil},
+		{"fixup #6", nil},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ParseRefs(tt.msg), tt.msg)
+	}
+}
+
+type fakeGitHub struct {
+	closed   []int
+	reopened []int
+}
+
+func (f *fakeGitHub) CloseIssue(_, _ string, number int, _ string) error {
+	f.closed = append(f.closed, number)
+	return nil
+}
+
+func (f *fakeGitHub) ReopenIssue(_, _ string, number int) error {
+	f.reopened = append(f.reopened, number)
+	return nil
+}
+
+func (f *fakeGitHub) MergedPRs(_, _ string, _ int) ([]git.PullRequest, error) {
+	return []git.PullRequest{{Number: 40, Title: "Add export", Body: "Fixes #7"}}, nil
+}
+
+func setup(t *testing.T) (*store.MemoryStore, *models.Project, *models.Issue, *models.Issue) {
+	t.Helper()
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: "/code/api", RepoURL: "https://github.com/acme/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	a := &models.Issue{ProjectID: p.ID, Title: "Crash", Status: models.IssueStatusInProgress, GitHubIssue: 7}
+	require.NoError(t, s.CreateIssue(ctx, a))
+	b := &models.Issue{ProjectID: p.ID, Title: "Docs", Status: models.IssueStatusOpen}
+	require.NoError(t, s.CreateIssue(ctx, b))
+	return s, p, a, b
+}
+
+func TestApplyAndRevert(t *testing.T) {
+	ctx := context.Background()
+	s, p, a, b := setup(t)
+	gh := &fakeGitHub{}
+	cfg := Config{Enabled: true, GitHub: gh}
+	sources := []Source{
+		{Kind: models.ClosureSourceCommit, Ref: "aaa", Message: "fix: crash\n\nFixes #7", SessionID: "s1"},
+		{Kind: models.ClosureSourceCommit, Ref: "bbb", Message: "Closes PM-" + b.ID[:16] + " and #99"},
+	}
+
+	dry, err := Apply(ctx, s, cfg, p, sources, true)
+	require.NoError(t, err)
+	assert.Len(t, dry, 2)
+	got, _ := s.GetIssue(ctx, a.ID)
+	assert.Equal(t, models.IssueStatusInProgress, got.Status, "dry run changes nothing")
+
+	closures, err := Apply(ctx, s, cfg, p, sources, false)
+	require.NoError(t, err)
+	require.Len(t, closures, 2)
+	assert.Equal(t, a.ID, closures[0].IssueID)
+	assert.Equal(t, models.IssueStatusInProgress, closures[0].FromStatus)
+	assert.Equal(t, "fix: crash", closures[0].Message)
+	assert.Equal(t, 7, closures[0].GitHubIssue)
+	assert.Equal(t, []int{7}, gh.closed)
+	got, _ = s.GetIssue(ctx, b.ID)
+	assert.Equal(t, models.IssueStatusDone, got.Status)
+
+	again, err := Apply(ctx, s, cfg, p, sources, false)
+	require.NoError(t, err)
+	assert.Empty(t, again, "done issues are not closed again")
+
+	reverted, err := Revert(ctx, s, gh, closures[0].ID)
+	require.NoError(t, err)
+	assert.NotNil(t, reverted.RevertedAt)
+	assert.Equal(t, []int{7}, gh.reopened)
+	got, _ = s.GetIssue(ctx, a.ID)
+	assert.Equal(t, models.IssueStatusInProgress, got.Status)
+
+	_, err = Revert(ctx, s, gh, closures[0].ID)
+	assert.ErrorContains(t, err, "already reverted")
+
+	again, err = Apply(ctx, s, cfg, p, sources, false)
+	require.NoError(t, err)
+	assert.Empty(t, again, "a reverted reference is not applied again")
+
+	got, _ = s.GetIssue(ctx, b.ID)
+	got.Status = models.IssueStatusClosed
+	require.NoError(t, s.UpdateIssue(ctx, got))
+	_, err = Revert(ctx, s, gh, closures[1].ID)
+	assert.ErrorContains(t, err, "is closed now")
+}
+
+func TestApply_ShortCode(t *testing.T) {
+	ctx := context.Background()
+	s, p, _, b := setup(t)
+	sources := []Source{{Kind: models.ClosureSourceCommit, Ref: "ccc", Message: "docs: guide\n\nCloses " + b.Code}}
+
+	closures, err := Apply(ctx, s, Config{Enabled: true}, p, sources, false)
+	require.NoError(t, err)
+	require.Len(t, closures, 1)
+	assert.Equal(t, b.ID, closures[0].IssueID)
+}
+
+func TestApplyDisabled(t *testing.T) {
+	ctx := context.Background()
+	s, p, a, _ := setup(t)
+	closures, err := Apply(ctx, s, Config{}, p, []Source{{Kind: models.ClosureSourceCommit, Ref: "aaa", Message: "Fixes #7"}}, false)
+	require.NoError(t, err)
+	assert.Empty(t, closures)
+	got, _ := s.GetIssue(ctx, a.ID)
+	assert.Equal(t, models.IssueStatusInProgress, got.Status)
+}
+
+func TestScanMergedPRs(t *testing.T) {
+	ctx := context.Background()
+	s, p, a, _ := setup(t)
+	gh := &fakeGitHub{}
+
+	closures, err := ScanMergedPRs(ctx, s, Config{Enabled: true, GitHub: gh}, gh, p, 10, false)
+	require.NoError(t, err)
+	require.Len(t, closures, 1)
+	assert.Equal(t, a.ID, closures[0].IssueID)
+	assert.Equal(t, models.ClosureSourcePR, closures[0].Source)
+	assert.Equal(t, "#40", closures[0].Ref)
+	assert.Empty(t, gh.closed, "GitHub closes issues its merged pull requests reference")
+}
@@ -0,0 +1,75 @@
+// Package autoclose closes pm issues referenced with closing keywords
+// ("Fixes #123", "Closes WID-12", "Closes PM-01J5ABCD") in session commits and merged pull
+// requests, recording each closure so it can be reverted.
+package autoclose
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Ref is an issue reference following a closing keyword.
+type Ref struct {
+	GitHub  int    // GitHub issue number, from "#123"
+	IssueID string // pm issue ID or ID prefix, from "PM-<id>"
+	Code    string // pm issue short code, e.g. "WID-12"
+}
+
+func (r Ref) String() string {
+	if r.GitHub > 0 {
+		return "#" + strconv.Itoa(r.GitHub)
+	}
+	if r.Code != "" {
+		return r.Code
+	}
+	return "PM-" + r.IssueID
+}
+
+var (
+	keywordRe = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?[ \t]+`)
+	refRe     = regexp.MustCompile(`^(?:#(\d+)|(?i:pm)-([0-9A-Za-z]{6,})|([A-Za-z][A-Za-z0-9]*-\d+))\b`)
+	refSepRe  = regexp.MustCompile(`^(?:[ \t]*,[ \t]*|[ \t]+and[ \t]+|[ \t]*&[ \t]*)`)
+)
+
+// ParseRefs returns the issues a message closes: every "#<n>", short code
+// ("WID-12") or "PM-<id>" after close/closes/closed, fix/fixes/fixed or
+// resolve/resolves/resolved, including lists like "Fixes #1, WID-2 and
+// PM-01J5ABCD". Keywords are case-insensitive; repeated references are
+// returned once. "PM-" followed by six or more characters is read as an ID
+// prefix, not as the code of a project keyed PM.
+func ParseRefs(msg string) []Ref {
+	var refs []Ref
+	seen := make(map[Ref]bool)
+	for _, loc := range keywordRe.FindAllStringIndex(msg, -1) {
+		rest := msg[loc[1]:]
+		for {
+			m := refRe.FindStringSubmatch(rest)
+			if m == nil {
+				break
+			}
+			var ref Ref
+			switch {
+			case m[1] != "":
+				ref.GitHub, _ = strconv.Atoi(m[1])
+			case m[2] != "":
+				ref.IssueID = strings.ToUpper(m[2])
+			default:
+				ref.Code = strings.ToUpper(m[3])
+			}
+			if ref.GitHub > 0 || ref.IssueID != "" || ref.Code != "" {
+				if !seen[ref] {
+					seen[ref] = true
+					refs = append(refs, ref)
+				}
+			}
+			rest = rest[len(m[0]):]
+			sep := refSepRe.FindString(rest)
+			if sep == "" {
+				break
+			}
+			rest = rest[len(sep):]
+		}
+	}
+	return refs
+}
@@ -0,0 +1,104 @@
+// Package badge renders flat, shields.io-style SVG status badges, so pm
+// project status can be embedded in a README as an image.
+package badge
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+)
+
+// Badge colors, matching the shields.io palette.
+const (
+	Green  = "#4c1"
+	Yellow = "#dfb317"
+	Red    = "#e05d44"
+	Blue   = "#007ec6"
+	Grey   = "#9f9f9f"
+)
+
+// Badge is a label on grey next to a message on Color.
+type Badge struct {
+	Label   string
+	Message string
+	Color   string
+}
+
+// HealthColor returns the color of a health score, with the thresholds of
+// the CLI's health column.
+func HealthColor(score int) string {
+	switch {
+	case score >= 80:
+		return Green
+	case score >= 50:
+		return Yellow
+	default:
+		return Red
+	}
+}
+
+// horizontal padding on each side of a text
+const padding = 6
+
+var svg = template.Must(template.New("badge").Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="20" role="img" aria-label="{{.Label}}: {{.Message}}">
+<title>{{.Label}}: {{.Message}}</title>
+<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="{{.Width}}" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)">
+<rect width="{{.LabelWidth}}" height="20" fill="#555"/>
+<rect x="{{.LabelWidth}}" width="{{.MessageWidth}}" height="20" fill="{{.Color}}"/>
+<rect width="{{.Width}}" height="20" fill="url(#s)"/>
+</g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="{{.LabelX}}" y="15" fill="#010101" fill-opacity=".3">{{.Label}}</text>
+<text x="{{.LabelX}}" y="14">{{.Label}}</text>
+<text x="{{.MessageX}}" y="15" fill="#010101" fill-opacity=".3">{{.Message}}</text>
+<text x="{{.MessageX}}" y="14">{{.Message}}</text>
+</g>
+</svg>
+`))
+
+// SVG renders the badge. Widths are estimated from Verdana's character
+// widths, so no font is needed to lay it out.
+func (b Badge) SVG() []byte {
+	color := b.Color
+	if !strings.HasPrefix(color, "#") {
+		color = Grey
+	}
+	lw := textWidth(b.Label) + 2*padding
+	mw := textWidth(b.Message) + 2*padding
+	var buf bytes.Buffer
+	_ = svg.Execute(&buf, map[string]any{
+		"Label":        b.Label,
+		"Message":      b.Message,
+		"Color":        color,
+		"Width":        lw + mw,
+		"LabelWidth":   lw,
+		"MessageWidth": mw,
+		"LabelX":       fmt.Sprintf("%.1f", float64(lw)/2),
+		"MessageX":     fmt.Sprintf("%.1f", float64(lw)+float64(mw)/2),
+	})
+	return buf.Bytes()
+}
+
+// textWidth estimates the width in pixels of s in 11px Verdana.
+func textWidth(s string) int {
+	var w float64
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune("iljI.,:;'|!", r):
+			w += 3.5
+		case strings.ContainsRune("frt()[] /", r):
+			w += 4.5
+		case strings.ContainsRune("mwMW%@", r):
+			w += 10.5
+		case r >= 'A' && r <= 'Z':
+			w += 7.5
+		default:
+			w += 7
+		}
+	}
+	return int(math.Ceil(w))
+}
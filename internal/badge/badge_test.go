@@ -0,0 +1,42 @@
+package badge
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBadge_SVG(t *testing.T) {
+	out := Badge{Label: "health", Message: "87/100", Color: HealthColor(87)}.SVG()
+
+	var doc struct {
+		Width string `xml:"width,attr"`
+		Title string `xml:"title"`
+	}
+	require.NoError(t, xml.Unmarshal(out, &doc), string(out))
+	assert.Equal(t, "health: 87/100", doc.Title)
+	assert.Contains(t, string(out), `fill="#4c1"`)
+
+	wide := Badge{Label: "health", Message: "100/100 and more", Color: Green}.SVG()
+	var wideDoc struct {
+		Width int `xml:"width,attr"`
+	}
+	require.NoError(t, xml.Unmarshal(wide, &wideDoc))
+	assert.Greater(t, wideDoc.Width, 100)
+}
+
+func TestBadge_EscapesText(t *testing.T) {
+	out := string(Badge{Label: "issues", Message: `<script>"x"</script>`, Color: `red" onload="alert(1)`}.SVG())
+	assert.NotContains(t, out, "<script>")
+	assert.NotContains(t, out, "onload")
+	assert.Contains(t, out, `fill="#9f9f9f"`, "colors that are not hex codes fall back to grey")
+}
+
+func TestHealthColor(t *testing.T) {
+	assert.Equal(t, Green, HealthColor(80))
+	assert.Equal(t, Yellow, HealthColor(79))
+	assert.Equal(t, Yellow, HealthColor(50))
+	assert.Equal(t, Red, HealthColor(49))
+}
@@ -0,0 +1,335 @@
+// Package boardsync keeps a GitHub Projects (v2) board in step with pm: the
+// status and priority of each issue linked to a GitHub issue are mirrored to
+// the issue's item on the project's board, and changes made on the board
+// come back to pm. When both sides changed since the last sync, the most
+// recent change wins.
+package boardsync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// Store is the subset of store.Store needed to sync a board.
+type Store interface {
+	ListIssues(ctx context.Context, filter store.IssueListFilter) ([]*models.Issue, error)
+	ListIssueStatusEvents(ctx context.Context, projectID string) ([]*models.IssueStatusEvent, error)
+	UpdateIssue(ctx context.Context, issue *models.Issue) error
+	ListBoardItems(ctx context.Context, projectID string) ([]*models.BoardItem, error)
+	SaveBoardItem(ctx context.Context, item *models.BoardItem) error
+}
+
+// GitHub reads and updates Projects (v2) boards.
+type GitHub interface {
+	Board(owner string, number int) (*git.Board, error)
+	BoardItems(owner string, number int) ([]git.BoardItem, error)
+	AddBoardItem(boardID, owner, repo string, number int) (string, error)
+	SetBoardItemOption(boardID, itemID, fieldID, optionID string) error
+}
+
+// DefaultColumns maps each issue status to an option of the board's status
+// field, as named on GitHub's default board template.
+var DefaultColumns = map[models.IssueStatus]string{
+	models.IssueStatusOpen:       "Todo",
+	models.IssueStatusInProgress: "In Progress",
+	models.IssueStatusInReview:   "In Progress",
+	models.IssueStatusDone:       "Done",
+	models.IssueStatusClosed:     "Done",
+}
+
+// DefaultPriorities maps each issue priority to an option of the board's
+// priority field.
+var DefaultPriorities = map[models.IssuePriority]string{
+	models.IssuePriorityLow:    "Low",
+	models.IssuePriorityMedium: "Medium",
+	models.IssuePriorityHigh:   "High",
+}
+
+// Options controls a sync.
+type Options struct {
+	StatusField   string // board field holding the column (default "Status")
+	PriorityField string // board field holding the priority (default "Priority"); not synced when the board has none
+	// Columns and Priorities override entries of DefaultColumns and
+	// DefaultPriorities, keyed by pm status or priority.
+	Columns    map[string]string
+	Priorities map[string]string
+	// DryRun changes nothing and returns the changes that would be made.
+	DryRun bool
+}
+
+// Direction is which side a change was made on.
+type Direction string
+
+const (
+	ToGitHub Direction = "github" // pm's value was set on the board
+	ToPM     Direction = "pm"     // the board's value was set on the issue
+)
+
+// Change is a value a sync copied from one side to the other. Field is
+// "status", "priority", or "item" for an issue added to the board.
+type Change struct {
+	IssueID     string    `json:"issue_id"`
+	GitHubIssue int       `json:"github_issue"`
+	Field       string    `json:"field"`
+	From        string    `json:"from,omitempty"`
+	To          string    `json:"to,omitempty"`
+	Direction   Direction `json:"direction"`
+}
+
+// mapping maps pm values of one field to the options of a board field. A
+// board option maps back to the first value, in order, mapped to it.
+type mapping[T ~string] struct {
+	field *git.BoardField
+	to    map[T]string
+	order []T
+}
+
+func newMapping[T ~string](name string, field *git.BoardField, defaults map[T]string, overrides map[string]string, order []T, valid func(T) bool) (*mapping[T], error) {
+	m := &mapping[T]{field: field, to: make(map[T]string, len(defaults)), order: order}
+	for v, opt := range defaults {
+		m.to[v] = opt
+	}
+	for k, opt := range overrides {
+		v := T(k)
+		if !valid(v) {
+			return nil, fmt.Errorf("invalid %s %q in the board %s mapping", name, k, name)
+		}
+		m.to[v] = opt
+	}
+	for _, v := range order {
+		if field.Option(m.to[v]) == nil {
+			return nil, fmt.Errorf("board field %q has no option %q for %s %s (options: %s)", field.Name, m.to[v], name, v, optionNames(field))
+		}
+	}
+	return m, nil
+}
+
+// value returns the pm value a board option maps back to.
+func (m *mapping[T]) value(option string) (T, bool) {
+	for _, v := range m.order {
+		if strings.EqualFold(m.to[v], option) {
+			return v, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// resolve decides a field on which the issue and the board disagree:
+// whichever side changed since the last sync wins, and when both did (or
+// there was no last sync) the most recent change does. A board option that
+// maps to nothing in pm always loses.
+func resolve[T ~string](m *mapping[T], pmValue, lastValue T, boardValue git.BoardValue, lastBoard string, synced bool, pmAt time.Time) (pull bool, ok bool) {
+	if strings.EqualFold(m.to[pmValue], boardValue.Option) {
+		return false, false
+	}
+	if _, known := m.value(boardValue.Option); !known {
+		return false, true
+	}
+	pmChanged := !synced || pmValue != lastValue
+	boardChanged := !synced || !strings.EqualFold(boardValue.Option, lastBoard)
+	switch {
+	case pmChanged && !boardChanged:
+		return false, true
+	case boardChanged && !pmChanged:
+		return true, true
+	default:
+		return boardValue.UpdatedAt.After(pmAt), true
+	}
+}
+
+func optionNames(f *git.BoardField) string {
+	names := make([]string, len(f.Options))
+	for i, o := range f.Options {
+		names[i] = o.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// Sync syncs the status and priority of the project's issues linked to
+// GitHub issues with their items on the project's board. Linked issues that
+// are not done or closed are added to the board when missing from it.
+func Sync(ctx context.Context, s Store, gh GitHub, project *models.Project, opts Options) ([]Change, error) {
+	if project.GitHubBoard == "" {
+		return nil, fmt.Errorf("project %s has no GitHub board", project.Name)
+	}
+	boardOwner, number, err := git.ParseBoardRef(project.GitHubBoard)
+	if err != nil {
+		return nil, err
+	}
+	owner, repo, err := git.ExtractOwnerRepo(project.RepoURL)
+	if err != nil {
+		return nil, fmt.Errorf("project %s has no GitHub repo: %w", project.Name, err)
+	}
+
+	board, err := gh.Board(boardOwner, number)
+	if err != nil {
+		return nil, err
+	}
+	statusName, priorityName := opts.StatusField, opts.PriorityField
+	if statusName == "" {
+		statusName = "Status"
+	}
+	if priorityName == "" {
+		priorityName = "Priority"
+	}
+	statusField := board.Field(statusName)
+	if statusField == nil {
+		return nil, fmt.Errorf("board %q has no single-select field %q", board.Title, statusName)
+	}
+	statuses, err := newMapping("status", statusField, DefaultColumns, opts.Columns, models.IssueStatuses, models.IssueStatus.Valid)
+	if err != nil {
+		return nil, err
+	}
+	var priorities *mapping[models.IssuePriority]
+	if field := board.Field(priorityName); field != nil {
+		if priorities, err = newMapping("priority", field, DefaultPriorities, opts.Priorities, models.IssuePriorities, models.IssuePriority.Valid); err != nil {
+			return nil, err
+		}
+	}
+
+	items, err := gh.BoardItems(boardOwner, number)
+	if err != nil {
+		return nil, fmt.Errorf("list board items: %w", err)
+	}
+	itemByNumber := make(map[int]git.BoardItem)
+	for _, item := range items {
+		if strings.EqualFold(item.Repo, owner+"/"+repo) {
+			itemByNumber[item.Number] = item
+		}
+	}
+	records, err := s.ListBoardItems(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	recordByIssue := make(map[string]*models.BoardItem, len(records))
+	for _, r := range records {
+		recordByIssue[r.IssueID] = r
+	}
+	issues, err := s.ListIssues(ctx, store.IssueListFilter{ProjectID: project.ID})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].GitHubIssue < issues[j].GitHubIssue })
+	// An issue's status changed when it last entered a status, which edits
+	// of other fields since do not move; events come oldest first.
+	events, err := s.ListIssueStatusEvents(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	statusAt := make(map[string]time.Time)
+	for _, e := range events {
+		statusAt[e.IssueID] = e.ChangedAt
+	}
+
+	var changes []Change
+	for _, issue := range issues {
+		if issue.GitHubIssue == 0 {
+			continue
+		}
+		item, onBoard := itemByNumber[issue.GitHubIssue]
+		if !onBoard {
+			if issue.Status == models.IssueStatusDone || issue.Status == models.IssueStatusClosed {
+				continue
+			}
+			changes = append(changes, Change{IssueID: issue.ID, GitHubIssue: issue.GitHubIssue, Field: "item", Direction: ToGitHub})
+			if !opts.DryRun {
+				id, err := gh.AddBoardItem(board.ID, owner, repo, issue.GitHubIssue)
+				if err != nil {
+					return changes, fmt.Errorf("add issue #%d to the board: %w", issue.GitHubIssue, err)
+				}
+				item = git.BoardItem{ID: id}
+			}
+		}
+
+		rec := recordByIssue[issue.ID]
+		synced := rec != nil && rec.ItemID == item.ID
+		last := rec
+		if !synced {
+			last = &models.BoardItem{}
+		}
+		statusChangedAt, ok := statusAt[issue.ID]
+		if !ok {
+			statusChangedAt = issue.UpdatedAt
+		}
+		boardStatus := item.Values[statusField.Name]
+		next := &models.BoardItem{
+			IssueID:     issue.ID,
+			ProjectID:   project.ID,
+			ItemID:      item.ID,
+			BoardStatus: boardStatus.Option,
+		}
+		pulled := false
+
+		if pull, differ := resolve(statuses, issue.Status, last.Status, boardStatus, last.BoardStatus, synced, statusChangedAt); differ {
+			c := Change{IssueID: issue.ID, GitHubIssue: issue.GitHubIssue, Field: "status"}
+			if pull {
+				to, _ := statuses.value(boardStatus.Option)
+				c.From, c.To, c.Direction = string(issue.Status), string(to), ToPM
+				issue.Status = to
+				if to == models.IssueStatusClosed {
+					now := time.Now().UTC()
+					issue.ClosedAt = &now
+				} else {
+					issue.ClosedAt = nil
+				}
+				pulled = true
+			} else {
+				opt := statusField.Option(statuses.to[issue.Status])
+				c.From, c.To, c.Direction = boardStatus.Option, opt.Name, ToGitHub
+				if !opts.DryRun {
+					if err := gh.SetBoardItemOption(board.ID, item.ID, statusField.ID, opt.ID); err != nil {
+						return changes, fmt.Errorf("set status of issue #%d on the board: %w", issue.GitHubIssue, err)
+					}
+				}
+				next.BoardStatus = opt.Name
+			}
+			changes = append(changes, c)
+		}
+
+		if priorities != nil {
+			boardPriority := item.Values[priorities.field.Name]
+			next.BoardPriority = boardPriority.Option
+			if pull, differ := resolve(priorities, issue.Priority, last.Priority, boardPriority, last.BoardPriority, synced, issue.UpdatedAt); differ {
+				c := Change{IssueID: issue.ID, GitHubIssue: issue.GitHubIssue, Field: "priority"}
+				if pull {
+					to, _ := priorities.value(boardPriority.Option)
+					c.From, c.To, c.Direction = string(issue.Priority), string(to), ToPM
+					issue.Priority = to
+					pulled = true
+				} else {
+					opt := priorities.field.Option(priorities.to[issue.Priority])
+					c.From, c.To, c.Direction = boardPriority.Option, opt.Name, ToGitHub
+					if !opts.DryRun {
+						if err := gh.SetBoardItemOption(board.ID, item.ID, priorities.field.ID, opt.ID); err != nil {
+							return changes, fmt.Errorf("set priority of issue #%d on the board: %w", issue.GitHubIssue, err)
+						}
+					}
+					next.BoardPriority = opt.Name
+				}
+				changes = append(changes, c)
+			}
+		}
+
+		if opts.DryRun {
+			continue
+		}
+		if pulled {
+			if err := s.UpdateIssue(ctx, issue); err != nil {
+				return changes, err
+			}
+		}
+		next.Status, next.Priority = issue.Status, issue.Priority
+		if err := s.SaveBoardItem(ctx, next); err != nil {
+			return changes, err
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,191 @@
+package boardsync
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// fakeBoard is a board with the default Status and Priority fields whose
+// items are kept in memory.
+type fakeBoard struct {
+	board *git.Board
+	items []git.BoardItem
+	set   []string // "item field option" per SetBoardItemOption call
+}
+
+func newFakeBoard() *fakeBoard {
+	return &fakeBoard{board: &git.Board{ID: "PVT_1", Title: "Roadmap", Fields: []git.BoardField{
+		{ID: "F_status", Name: "Status", Options: []git.BoardOption{{ID: "o_todo", Name: "Todo"}, {ID: "o_prog", Name: "In Progress"}, {ID: "o_done", Name: "Done"}}},
+		{ID: "F_prio", Name: "Priority", Options: []git.BoardOption{{ID: "o_low", Name: "Low"}, {ID: "o_med", Name: "Medium"}, {ID: "o_high", Name: "High"}}},
+	}}}
+}
+
+func (f *fakeBoard) Board(owner string, number int) (*git.Board, error) { return f.board, nil }
+
+func (f *fakeBoard) BoardItems(owner string, number int) ([]git.BoardItem, error) {
+	return f.items, nil
+}
+
+func (f *fakeBoard) AddBoardItem(boardID, owner, repo string, number int) (string, error) {
+	id := fmt.Sprintf("item_%d", number)
+	f.items = append(f.items, git.BoardItem{ID: id, Repo: owner + "/" + repo, Number: number, Values: map[string]git.BoardValue{}})
+	return id, nil
+}
+
+func (f *fakeBoard) SetBoardItemOption(boardID, itemID, fieldID, optionID string) error {
+	f.set = append(f.set, itemID+" "+fieldID+" "+optionID)
+	for _, item := range f.items {
+		if item.ID != itemID {
+			continue
+		}
+		field := f.board.Fields[0]
+		if fieldID != field.ID {
+			field = f.board.Fields[1]
+		}
+		for _, o := range field.Options {
+			if o.ID == optionID {
+				item.Values[field.Name] = git.BoardValue{Option: o.Name, UpdatedAt: time.Now().UTC()}
+			}
+		}
+	}
+	return nil
+}
+
+// move sets an item's option as if someone moved it on GitHub at at.
+func (f *fakeBoard) move(number int, field, option string, at time.Time) {
+	for _, item := range f.items {
+		if item.Number == number {
+			item.Values[field] = git.BoardValue{Option: option, UpdatedAt: at}
+		}
+	}
+}
+
+func setup(t *testing.T) (*store.MemoryStore, *models.Project) {
+	t.Helper()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: "/tmp/api", RepoURL: "https://github.com/acme/api", GitHubBoard: "acme/3"}
+	require.NoError(t, s.CreateProject(context.Background(), p))
+	return s, p
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	s, p := setup(t)
+	gh := newFakeBoard()
+
+	linked := &models.Issue{ProjectID: p.ID, Title: "Linked", Status: models.IssueStatusInProgress, Priority: models.IssuePriorityHigh, Type: models.IssueTypeFeature, GitHubIssue: 7}
+	done := &models.Issue{ProjectID: p.ID, Title: "Done", Status: models.IssueStatusDone, Priority: models.IssuePriorityLow, Type: models.IssueTypeFeature, GitHubIssue: 8}
+	local := &models.Issue{ProjectID: p.ID, Title: "Local", Status: models.IssueStatusOpen, Priority: models.IssuePriorityLow, Type: models.IssueTypeFeature}
+	for _, i := range []*models.Issue{linked, done, local} {
+		require.NoError(t, s.CreateIssue(ctx, i))
+	}
+
+	changes, err := Sync(ctx, s, gh, p, Options{DryRun: true})
+	require.NoError(t, err)
+	assert.Len(t, changes, 3, "added, then its status and priority set")
+	assert.Empty(t, gh.items, "a dry run changes nothing")
+
+	// The open linked issue is added with pm's values; the done one is not.
+	changes, err = Sync(ctx, s, gh, p, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{IssueID: linked.ID, GitHubIssue: 7, Field: "item", Direction: ToGitHub},
+		{IssueID: linked.ID, GitHubIssue: 7, Field: "status", To: "In Progress", Direction: ToGitHub},
+		{IssueID: linked.ID, GitHubIssue: 7, Field: "priority", To: "High", Direction: ToGitHub},
+	}, changes)
+	require.Len(t, gh.items, 1)
+
+	changes, err = Sync(ctx, s, gh, p, Options{})
+	require.NoError(t, err)
+	assert.Empty(t, changes, "nothing changed since")
+
+	// Moved on the board: the issue follows.
+	gh.move(7, "Status", "Done", time.Now().UTC())
+	changes, err = Sync(ctx, s, gh, p, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []Change{{IssueID: linked.ID, GitHubIssue: 7, Field: "status", From: "in_progress", To: "done", Direction: ToPM}}, changes)
+	got, err := s.GetIssue(ctx, linked.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.IssueStatusDone, got.Status)
+
+	// Changed in pm: the board follows.
+	got.Priority = models.IssuePriorityLow
+	require.NoError(t, s.UpdateIssue(ctx, got))
+	changes, err = Sync(ctx, s, gh, p, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []Change{{IssueID: linked.ID, GitHubIssue: 7, Field: "priority", From: "High", To: "Low", Direction: ToGitHub}}, changes)
+}
+
+func TestSync_Conflict(t *testing.T) {
+	ctx := context.Background()
+	s, p := setup(t)
+	gh := newFakeBoard()
+	issue := &models.Issue{ProjectID: p.ID, Title: "Linked", Status: models.IssueStatusOpen, Priority: models.IssuePriorityMedium, Type: models.IssueTypeFeature, GitHubIssue: 7}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	_, err := Sync(ctx, s, gh, p, Options{})
+	require.NoError(t, err)
+
+	// Both sides changed: the board's change is older, so pm wins.
+	gh.move(7, "Status", "Done", time.Now().UTC().Add(-time.Hour))
+	issue.Status = models.IssueStatusInProgress
+	require.NoError(t, s.UpdateIssue(ctx, issue))
+	changes, err := Sync(ctx, s, gh, p, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []Change{{IssueID: issue.ID, GitHubIssue: 7, Field: "status", From: "Done", To: "In Progress", Direction: ToGitHub}}, changes)
+
+	// Both changed again: the board's change is newer, so it wins.
+	issue.Status = models.IssueStatusOpen
+	require.NoError(t, s.UpdateIssue(ctx, issue))
+	gh.move(7, "Status", "Done", time.Now().UTC().Add(time.Minute))
+	changes, err = Sync(ctx, s, gh, p, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []Change{{IssueID: issue.ID, GitHubIssue: 7, Field: "status", From: "open", To: "done", Direction: ToPM}}, changes)
+
+	// Both changed again, and the issue was edited after the board moved: the
+	// issue's status change is still older, so the board wins.
+	issue.Status = models.IssueStatusInProgress
+	require.NoError(t, s.UpdateIssue(ctx, issue))
+	gh.move(7, "Status", "Todo", time.Now().UTC())
+	issue.Title = "Linked, retitled"
+	require.NoError(t, s.UpdateIssue(ctx, issue))
+	changes, err = Sync(ctx, s, gh, p, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []Change{{IssueID: issue.ID, GitHubIssue: 7, Field: "status", From: "in_progress", To: "open", Direction: ToPM}}, changes)
+
+	// An option pm has no status for is put back.
+	gh.board.Fields[0].Options = append(gh.board.Fields[0].Options, git.BoardOption{ID: "o_ice", Name: "Icebox"})
+	gh.move(7, "Status", "Icebox", time.Now().UTC().Add(time.Minute))
+	changes, err = Sync(ctx, s, gh, p, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []Change{{IssueID: issue.ID, GitHubIssue: 7, Field: "status", From: "Icebox", To: "Todo", Direction: ToGitHub}}, changes)
+}
+
+func TestSync_Columns(t *testing.T) {
+	ctx := context.Background()
+	s, p := setup(t)
+	gh := newFakeBoard()
+	gh.board.Fields[0].Options = append(gh.board.Fields[0].Options, git.BoardOption{ID: "o_review", Name: "Review"})
+	issue := &models.Issue{ProjectID: p.ID, Title: "Linked", Status: models.IssueStatusInReview, Priority: models.IssuePriorityMedium, Type: models.IssueTypeFeature, GitHubIssue: 7}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+
+	changes, err := Sync(ctx, s, gh, p, Options{Columns: map[string]string{"in_review": "Review"}})
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	assert.Equal(t, "Review", changes[1].To)
+
+	_, err = Sync(ctx, s, gh, p, Options{Columns: map[string]string{"blocked": "Review"}})
+	assert.ErrorContains(t, err, `invalid status "blocked"`)
+	_, err = Sync(ctx, s, gh, p, Options{Columns: map[string]string{"open": "Backlog"}})
+	assert.ErrorContains(t, err, `no option "Backlog"`)
+	_, err = Sync(ctx, s, gh, p, Options{StatusField: "Stage"})
+	assert.ErrorContains(t, err, `no single-select field "Stage"`)
+}
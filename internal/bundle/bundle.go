@@ -0,0 +1,426 @@
+package bundle
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// FormatVersion is the bundle format written by Write. Read refuses bundles
+// from a newer format.
+const FormatVersion = 1
+
+// Manifest describes a bundle: what it holds and where it came from.
+type Manifest struct {
+	Format      int            `json:"format"`
+	PMVersion   string         `json:"pm_version,omitempty"`
+	ExportedAt  time.Time      `json:"exported_at"`
+	ProjectID   string         `json:"project_id"`
+	ProjectName string         `json:"project_name"`
+	Counts      map[string]int `json:"counts"`
+}
+
+// Bundle is one project with its issues, reviews, relations, comments,
+// session history and notes, for moving the project to another pm database.
+// Sessions carry no worktree: their worktree paths, fingerprints and
+// terminals are cleared on export.
+type Bundle struct {
+	Manifest  Manifest
+	Project   *models.Project
+	Issues    []*models.Issue
+	Reviews   []*models.IssueReview
+	Relations []*models.IssueRelation // only between the bundle's issues
+	Comments  []*models.IssueComment
+	Sessions  []*models.AgentSession
+	Notes     []*models.ProjectNote
+}
+
+// The zip entries of a bundle file, one JSON document each.
+const (
+	entryManifest  = "manifest.json"
+	entryProject   = "project.json"
+	entryIssues    = "issues.json"
+	entryReviews   = "reviews.json"
+	entryRelations = "relations.json"
+	entryComments  = "comments.json"
+	entrySessions  = "sessions.json"
+	entryNotes     = "notes.json"
+)
+
+// Export collects a project and everything that belongs to it.
+func Export(ctx context.Context, s store.Store, projectID string) (*Bundle, error) {
+	p, err := s.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bundle{Project: p}
+
+	b.Issues, err = s.ListIssues(ctx, store.IssueListFilter{ProjectID: p.ID})
+	if err != nil {
+		return nil, fmt.Errorf("list issues: %w", err)
+	}
+	inBundle := make(map[string]bool, len(b.Issues))
+	for _, issue := range b.Issues {
+		inBundle[issue.ID] = true
+	}
+	seenRelations := make(map[string]bool)
+	for _, issue := range b.Issues {
+		tags, err := s.GetIssueTags(ctx, issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("issue tags: %w", err)
+		}
+		issue.Tags = nil
+		for _, t := range tags {
+			issue.Tags = append(issue.Tags, t.Name)
+		}
+		issue.Readiness = nil
+
+		reviews, err := s.ListIssueReviews(ctx, issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list issue reviews: %w", err)
+		}
+		b.Reviews = append(b.Reviews, reviews...)
+
+		comments, err := s.ListIssueComments(ctx, issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list issue comments: %w", err)
+		}
+		b.Comments = append(b.Comments, comments...)
+
+		rels, err := s.ListIssueRelations(ctx, issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list issue relations: %w", err)
+		}
+		for _, rel := range rels {
+			if seenRelations[rel.ID] || !inBundle[rel.SourceIssueID] || !inBundle[rel.TargetIssueID] {
+				continue
+			}
+			seenRelations[rel.ID] = true
+			b.Relations = append(b.Relations, rel)
+		}
+	}
+
+	b.Sessions, err = s.ListAgentSessions(ctx, p.ID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("list agent sessions: %w", err)
+	}
+	for _, sess := range b.Sessions {
+		sess.WorktreePath = ""
+		sess.WorktreeFingerprint = ""
+		sess.TerminalBackend, sess.TerminalID = "", ""
+	}
+
+	b.Notes, err = s.ListProjectNotes(ctx, p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list project notes: %w", err)
+	}
+
+	// Oldest first, so imports create records in their original order
+	sort.SliceStable(b.Issues, func(i, j int) bool { return b.Issues[i].CreatedAt.Before(b.Issues[j].CreatedAt) })
+	sort.SliceStable(b.Reviews, func(i, j int) bool { return b.Reviews[i].CreatedAt.Before(b.Reviews[j].CreatedAt) })
+	sort.SliceStable(b.Sessions, func(i, j int) bool { return b.Sessions[i].StartedAt.Before(b.Sessions[j].StartedAt) })
+
+	b.Manifest = Manifest{
+		Format:      FormatVersion,
+		ExportedAt:  time.Now().UTC(),
+		ProjectID:   p.ID,
+		ProjectName: p.Name,
+		Counts:      b.counts(),
+	}
+	return b, nil
+}
+
+func (b *Bundle) counts() map[string]int {
+	return map[string]int{
+		"issues":    len(b.Issues),
+		"reviews":   len(b.Reviews),
+		"relations": len(b.Relations),
+		"comments":  len(b.Comments),
+		"sessions":  len(b.Sessions),
+		"notes":     len(b.Notes),
+	}
+}
+
+// Write writes the bundle as a zip archive of JSON documents.
+func (b *Bundle) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	entries := []struct {
+		name string
+		v    any
+	}{
+		{entryManifest, b.Manifest},
+		{entryProject, b.Project},
+		{entryIssues, b.Issues},
+		{entryReviews, b.Reviews},
+		{entryRelations, b.Relations},
+		{entryComments, b.Comments},
+		{entrySessions, b.Sessions},
+		{entryNotes, b.Notes},
+	}
+	for _, e := range entries {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: e.name, Method: zip.Deflate, Modified: b.Manifest.ExportedAt})
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(e.v); err != nil {
+			return fmt.Errorf("write %s: %w", e.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+// Read reads a bundle written by Write.
+func Read(r io.ReaderAt, size int64) (*Bundle, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("not a pm bundle: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	decode := func(name string, v any) error {
+		f, ok := files[name]
+		if !ok {
+			return fmt.Errorf("not a pm bundle: missing %s", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer func() { _ = rc.Close() }()
+		if err := json.NewDecoder(rc).Decode(v); err != nil {
+			return fmt.Errorf("read %s: %w", name, err)
+		}
+		return nil
+	}
+
+	b := &Bundle{}
+	if err := decode(entryManifest, &b.Manifest); err != nil {
+		return nil, err
+	}
+	if b.Manifest.Format < 1 || b.Manifest.Format > FormatVersion {
+		return nil, fmt.Errorf("unsupported bundle format %d (this pm reads up to %d)", b.Manifest.Format, FormatVersion)
+	}
+	for name, v := range map[string]any{
+		entryProject:   &b.Project,
+		entryIssues:    &b.Issues,
+		entryReviews:   &b.Reviews,
+		entryRelations: &b.Relations,
+		entryComments:  &b.Comments,
+		entrySessions:  &b.Sessions,
+		entryNotes:     &b.Notes,
+	} {
+		if err := decode(name, v); err != nil {
+			return nil, err
+		}
+	}
+	if b.Project == nil {
+		return nil, fmt.Errorf("not a pm bundle: no project")
+	}
+	return b, nil
+}
+
+// ImportOptions adjust a bundle to the database it is imported into.
+type ImportOptions struct {
+	NewIDs bool   // give every record a new ID, so the bundle can sit next to its original
+	Name   string // project name ("" = the bundle's)
+	Path   string // project path on this machine ("" = the bundle's)
+}
+
+// ImportResult reports what an import created.
+type ImportResult struct {
+	Project *models.Project
+	Counts  map[string]int
+	// Sessions that were still open in the bundle; they have no worktree
+	// here, so they are imported as abandoned
+	Abandoned int
+}
+
+// Import creates the bundle's project and records in s, keeping their
+// timestamps. With NewIDs every record gets a new ID and references between
+// them are rewritten; otherwise IDs are kept and an import into a database
+// that already holds them fails. A failed import removes the project again.
+func Import(ctx context.Context, s store.Store, b *Bundle, opts ImportOptions) (*ImportResult, error) {
+	ctx = store.WithImportedTimestamps(ctx)
+	ids := idMap{fresh: opts.NewIDs, ids: make(map[string]string)}
+
+	p := *b.Project
+	p.ID = ids.get(p.ID)
+	if opts.Name != "" {
+		p.Name = opts.Name
+	}
+	if opts.Path != "" {
+		p.Path = opts.Path
+	}
+	if existing, err := s.GetProject(ctx, p.ID); err == nil {
+		return nil, fmt.Errorf("project %s (%s) already exists; import with new IDs", existing.Name, p.ID)
+	}
+	if existing, err := s.GetProjectByName(ctx, p.Name); err == nil {
+		return nil, fmt.Errorf("a project named %s already exists (%s); import under another name", p.Name, existing.ID)
+	}
+	if existing, err := s.GetProjectByPath(ctx, p.Path); err == nil {
+		return nil, fmt.Errorf("project %s already uses path %s; import with another path", existing.Name, p.Path)
+	}
+	err := s.CreateProject(ctx, &p)
+	if errors.Is(err, store.ErrProjectKeyTaken) {
+		// Another project has the bundle's key; the store derives a free one
+		p.Key = ""
+		err = s.CreateProject(ctx, &p)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := importRecords(ctx, s, b, p.ID, &ids)
+	if err != nil {
+		_ = s.DeleteProject(context.Background(), p.ID)
+		return nil, err
+	}
+	res.Project = &p
+	return res, nil
+}
+
+func importRecords(ctx context.Context, s store.Store, b *Bundle, projectID string, ids *idMap) (*ImportResult, error) {
+	res := &ImportResult{Counts: b.counts()}
+	// Follow-up sessions reference the review that failed before them
+	for _, r := range b.Reviews {
+		ids.get(r.ID)
+	}
+
+	tagIDs := make(map[string]string)
+	tags, err := s.ListTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+	for _, t := range tags {
+		tagIDs[t.Name] = t.ID
+	}
+
+	for _, src := range b.Issues {
+		issue := *src
+		issue.ID = ids.get(src.ID)
+		issue.ProjectID = projectID
+		if err := s.CreateIssue(ctx, &issue); err != nil {
+			return nil, fmt.Errorf("import issue %s: %w", src.Title, err)
+		}
+		for _, name := range src.Tags {
+			if _, ok := tagIDs[name]; !ok {
+				t := &models.Tag{Name: name}
+				if err := s.CreateTag(ctx, t); err != nil {
+					return nil, fmt.Errorf("create tag %s: %w", name, err)
+				}
+				tagIDs[name] = t.ID
+			}
+			if err := s.TagIssue(ctx, issue.ID, tagIDs[name]); err != nil {
+				return nil, fmt.Errorf("tag issue %s: %w", src.Title, err)
+			}
+		}
+	}
+
+	for _, src := range b.Sessions {
+		sess := *src
+		sess.ID = ids.get(src.ID)
+		sess.ProjectID = projectID
+		sess.IssueID = ids.ref(src.IssueID)
+		sess.ReviewID = ids.ref(src.ReviewID)
+		if sess.Status.IsOpen() {
+			sess.Status = models.SessionStatusAbandoned
+			end := sess.StartedAt
+			if sess.LastActiveAt != nil {
+				end = *sess.LastActiveAt
+			}
+			sess.EndedAt = &end
+			res.Abandoned++
+		}
+		if err := s.CreateAgentSession(ctx, &sess); err != nil {
+			return nil, fmt.Errorf("import session %s: %w", src.Branch, err)
+		}
+		// Sessions are created open-ended; restore when this one ended
+		if sess.EndedAt != nil {
+			if err := s.UpdateAgentSession(ctx, &sess); err != nil {
+				return nil, fmt.Errorf("import session %s: %w", src.Branch, err)
+			}
+		}
+	}
+
+	for _, src := range b.Reviews {
+		review := *src
+		review.ID = ids.get(src.ID)
+		review.IssueID = ids.ref(src.IssueID)
+		review.SessionID = ids.ref(src.SessionID)
+		if err := s.CreateIssueReview(ctx, &review); err != nil {
+			return nil, fmt.Errorf("import review: %w", err)
+		}
+	}
+
+	for _, src := range b.Relations {
+		rel := *src
+		rel.ID = ids.get(src.ID)
+		rel.SourceIssueID = ids.ref(src.SourceIssueID)
+		rel.TargetIssueID = ids.ref(src.TargetIssueID)
+		if err := s.CreateIssueRelation(ctx, &rel); err != nil {
+			return nil, fmt.Errorf("import relation: %w", err)
+		}
+	}
+
+	for _, src := range b.Comments {
+		c := *src
+		c.ID = ids.get(src.ID)
+		c.IssueID = ids.ref(src.IssueID)
+		if err := s.CreateIssueComment(ctx, &c); err != nil {
+			return nil, fmt.Errorf("import comment: %w", err)
+		}
+	}
+
+	for _, src := range b.Notes {
+		note := *src
+		note.ID = ids.get(src.ID)
+		note.ProjectID = projectID
+		if err := s.CreateProjectNote(ctx, &note); err != nil {
+			return nil, fmt.Errorf("import note %s: %w", src.Title, err)
+		}
+	}
+	return res, nil
+}
+
+// idMap assigns the IDs of imported records: the bundle's own, or fresh ones
+// when importing with new IDs.
+type idMap struct {
+	fresh bool
+	ids   map[string]string
+}
+
+// get returns the ID for a record with bundle ID id, assigning one if needed.
+func (m *idMap) get(id string) string {
+	if !m.fresh {
+		return id
+	}
+	if n, ok := m.ids[id]; ok {
+		return n
+	}
+	n := ulid.Make().String()
+	m.ids[id] = n
+	return n
+}
+
+// ref returns the ID a reference to bundle ID id points at. References to
+// records outside the bundle are kept as they are, and empty ones stay empty.
+func (m *idMap) ref(id string) string {
+	if n, ok := m.ids[id]; ok {
+		return n
+	}
+	return id
+}
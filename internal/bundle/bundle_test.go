@@ -0,0 +1,179 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// seedProject creates a project with an issue history spanning every record
+// kind a bundle carries.
+func seedProject(t *testing.T, s store.Store) *models.Project {
+	t.Helper()
+	ctx := context.Background()
+	p := &models.Project{Name: "api", Path: "/code/api", BuildCmd: "make"}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	bug := &models.Issue{ProjectID: p.ID, Title: "Login fails", Status: models.IssueStatusInProgress, Type: models.IssueTypeBug}
+	require.NoError(t, s.CreateIssue(ctx, bug))
+	feat := &models.Issue{ProjectID: p.ID, Title: "Add export", Status: models.IssueStatusDone}
+	require.NoError(t, s.CreateIssue(ctx, feat))
+	tag := &models.Tag{Name: "auth"}
+	require.NoError(t, s.CreateTag(ctx, tag))
+	require.NoError(t, s.TagIssue(ctx, bug.ID, tag.ID))
+	require.NoError(t, s.CreateIssueRelation(ctx, &models.IssueRelation{SourceIssueID: bug.ID, TargetIssueID: feat.ID, Type: models.RelationCausedBy}))
+	require.NoError(t, s.CreateIssueComment(ctx, &models.IssueComment{IssueID: bug.ID, Author: "dev", Body: "Repro attached"}))
+
+	first := &models.AgentSession{ProjectID: p.ID, IssueID: feat.ID, Branch: "feature/export", WorktreePath: "/wt/export", Status: models.SessionStatusCompleted, CommitCount: 3}
+	require.NoError(t, s.CreateAgentSession(ctx, first))
+	ended := time.Now().UTC().Add(-time.Hour)
+	first.EndedAt = &ended
+	require.NoError(t, s.UpdateAgentSession(ctx, first))
+	review := &models.IssueReview{IssueID: feat.ID, SessionID: first.ID, Verdict: models.ReviewVerdictFail, Summary: "Missing tests", ReviewedAt: ended}
+	require.NoError(t, s.CreateIssueReview(ctx, review))
+	followUp := &models.AgentSession{ProjectID: p.ID, IssueID: bug.ID, Branch: "fix/login", WorktreePath: "/wt/login", Status: models.SessionStatusIdle, ReviewID: review.ID, TerminalID: "pm-login"}
+	require.NoError(t, s.CreateAgentSession(ctx, followUp))
+
+	require.NoError(t, s.CreateProjectNote(ctx, &models.ProjectNote{ProjectID: p.ID, Title: "Plan", Body: "Ship it"}))
+	return p
+}
+
+func roundTrip(t *testing.T, b *Bundle) *Bundle {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, b.Write(&buf))
+	got, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	return got
+}
+
+func TestExportAndRead(t *testing.T) {
+	s := store.NewMemoryStore()
+	p := seedProject(t, s)
+
+	b, err := Export(context.Background(), s, p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"issues": 2, "reviews": 1, "relations": 1, "comments": 1, "sessions": 2, "notes": 1}, b.Manifest.Counts)
+	for _, sess := range b.Sessions {
+		assert.Empty(t, sess.WorktreePath, "bundles carry no worktrees")
+		assert.Empty(t, sess.TerminalID)
+	}
+
+	got := roundTrip(t, b)
+	assert.Equal(t, FormatVersion, got.Manifest.Format)
+	assert.Equal(t, "api", got.Project.Name)
+	require.Len(t, got.Issues, 2)
+	for _, issue := range got.Issues {
+		if issue.Title == "Login fails" {
+			assert.Equal(t, []string{"auth"}, issue.Tags)
+		}
+	}
+	assert.Len(t, got.Sessions, 2)
+	assert.Len(t, got.Notes, 1)
+
+	_, err = Read(bytes.NewReader([]byte("not a zip")), 9)
+	assert.Error(t, err)
+}
+
+func TestImport_KeepsIDsAndTimestamps(t *testing.T) {
+	ctx := context.Background()
+	src := store.NewMemoryStore()
+	p := seedProject(t, src)
+	b, err := Export(ctx, src, p.ID)
+	require.NoError(t, err)
+
+	dst := store.NewMemoryStore()
+	res, err := Import(ctx, dst, roundTrip(t, b), ImportOptions{Path: "/home/me/api"})
+	require.NoError(t, err)
+	assert.Equal(t, p.ID, res.Project.ID)
+	assert.Equal(t, "/home/me/api", res.Project.Path)
+	assert.Equal(t, 1, res.Abandoned)
+
+	for _, orig := range b.Issues {
+		got, err := dst.GetIssue(ctx, orig.ID)
+		require.NoError(t, err)
+		assert.True(t, orig.CreatedAt.Equal(got.CreatedAt))
+		assert.Equal(t, orig.Status, got.Status)
+		tags, err := dst.GetIssueTags(ctx, orig.ID)
+		require.NoError(t, err)
+		if orig.Title == "Login fails" {
+			require.Len(t, tags, 1)
+			assert.Equal(t, "auth", tags[0].Name)
+		}
+	}
+
+	sessions, err := dst.ListAgentSessions(ctx, p.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	for _, sess := range sessions {
+		assert.NotNil(t, sess.EndedAt)
+		if sess.Branch == "fix/login" {
+			assert.Equal(t, models.SessionStatusAbandoned, sess.Status, "open sessions have no worktree here")
+		}
+	}
+
+	// Importing the same IDs again is refused and leaves nothing behind
+	_, err = Import(ctx, dst, b, ImportOptions{Name: "api2", Path: "/elsewhere"})
+	assert.ErrorContains(t, err, "already exists")
+}
+
+func TestImport_NewIDs(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := seedProject(t, s)
+	b, err := Export(ctx, s, p.ID)
+	require.NoError(t, err)
+
+	// A clash on name alone is refused before anything is written
+	_, err = Import(ctx, s, b, ImportOptions{NewIDs: true, Path: "/code/api-copy"})
+	assert.ErrorContains(t, err, "named api")
+
+	res, err := Import(ctx, s, b, ImportOptions{NewIDs: true, Name: "api-copy", Path: "/code/api-copy"})
+	require.NoError(t, err)
+	require.NotEqual(t, p.ID, res.Project.ID)
+	assert.Equal(t, "AC", res.Project.Key, "the copy cannot share the original's key, so gets its new name's")
+
+	issues, err := s.ListIssues(ctx, store.IssueListFilter{ProjectID: res.Project.ID})
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	byTitle := map[string]*models.Issue{}
+	for _, issue := range issues {
+		byTitle[issue.Title] = issue
+		for _, orig := range b.Issues {
+			assert.NotEqual(t, orig.ID, issue.ID)
+		}
+	}
+
+	rels, err := s.ListIssueRelations(ctx, byTitle["Login fails"].ID)
+	require.NoError(t, err)
+	require.Len(t, rels, 1)
+	assert.Equal(t, byTitle["Add export"].ID, rels[0].TargetIssueID)
+
+	reviews, err := s.ListIssueReviews(ctx, byTitle["Add export"].ID)
+	require.NoError(t, err)
+	require.Len(t, reviews, 1)
+	sessions, err := s.ListAgentSessions(ctx, res.Project.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	byBranch := map[string]*models.AgentSession{}
+	for _, sess := range sessions {
+		byBranch[sess.Branch] = sess
+	}
+	assert.Equal(t, byBranch["feature/export"].ID, reviews[0].SessionID)
+	assert.Equal(t, reviews[0].ID, byBranch["fix/login"].ReviewID)
+	assert.Equal(t, byTitle["Login fails"].ID, byBranch["fix/login"].IssueID)
+
+	comments, err := s.ListIssueComments(ctx, byTitle["Login fails"].ID)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	notes, err := s.ListProjectNotes(ctx, res.Project.ID)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+}
@@ -0,0 +1,141 @@
+// Package capabilities reports which optional integrations pm can use: the
+// LLM, a code forge (GitHub through the gh CLI) and a terminal to spawn
+// agents in. They are detected once at startup, so commands, MCP tools and
+// API endpoints that need a missing one can be hidden or refuse with the
+// same explanation instead of failing in their own way.
+package capabilities
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/joescharf/pm/internal/terminal"
+)
+
+// Name identifies a capability.
+type Name string
+
+const (
+	LLM      Name = "llm"
+	Forge    Name = "forge"
+	Terminal Name = "terminal"
+)
+
+// Values a capability can take besides a backend name.
+const (
+	On          = "on"
+	Off         = "off"
+	None        = "none"
+	ForgeGitHub = "github"
+)
+
+// Reasons given for a missing capability.
+const (
+	ReasonNoLLM      = "LLM not configured (set ANTHROPIC_API_KEY)"
+	ReasonNoGH       = "GitHub not available: the gh CLI is not installed"
+	ReasonGHLogin    = "GitHub not available: gh is not logged in (run gh auth login)"
+	ReasonNoTerminal = "terminal spawning is not enabled; set agent.terminal to iterm or tmux"
+)
+
+// Capabilities is what pm can do on this host. Reasons says, for each
+// capability that is off, why and how to turn it on.
+type Capabilities struct {
+	LLM      string          `json:"llm"`      // on or off
+	Forge    string          `json:"forge"`    // github or none
+	Terminal string          `json:"terminal"` // iterm, tmux or none (on for a spawner of another kind)
+	Reasons  map[Name]string `json:"reasons"`
+}
+
+// Has reports whether the capability is available. A nil Capabilities was
+// never detected and has them all, leaving callers to their own checks.
+func (c *Capabilities) Has(name Name) bool {
+	if c == nil {
+		return true
+	}
+	switch name {
+	case LLM:
+		return c.LLM == On
+	case Forge:
+		return c.Forge != None
+	case Terminal:
+		return c.Terminal != None
+	}
+	return false
+}
+
+// Require returns an *UnavailableError when the capability is missing.
+func (c *Capabilities) Require(name Name) error {
+	if c.Has(name) {
+		return nil
+	}
+	return &UnavailableError{Capability: name, Reason: c.Reasons[name]}
+}
+
+// UnavailableError is returned for an operation that needs a missing
+// capability.
+type UnavailableError struct {
+	Capability Name
+	Reason     string
+}
+
+func (e *UnavailableError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("%s is not available", e.Capability)
+	}
+	return e.Reason
+}
+
+// Probe is what Detect looks at. Nil funcs use the host's.
+type Probe struct {
+	LLMConfigured   bool
+	TerminalBackend string // agent.terminal
+	ForgeURL        string // github.api_url, reached without gh
+
+	LookPath    func(file string) (string, error)
+	GitHubLogin func() error // fails when gh has no usable token
+}
+
+// Detect works out the capabilities from the probe. GitHub needs gh on the
+// PATH and logged in, unless a ForgeURL is set; a terminal backend needs its
+// program (osascript for iTerm, tmux).
+func Detect(p Probe) *Capabilities {
+	if p.LookPath == nil {
+		p.LookPath = exec.LookPath
+	}
+	if p.GitHubLogin == nil {
+		p.GitHubLogin = func() error { return exec.Command("gh", "auth", "token").Run() }
+	}
+	c := &Capabilities{LLM: On, Forge: ForgeGitHub, Terminal: None, Reasons: map[Name]string{}}
+
+	if !p.LLMConfigured {
+		c.LLM = Off
+		c.Reasons[LLM] = ReasonNoLLM
+	}
+
+	if p.ForgeURL == "" {
+		if _, err := p.LookPath("gh"); err != nil {
+			c.Forge = None
+			c.Reasons[Forge] = ReasonNoGH
+		} else if err := p.GitHubLogin(); err != nil {
+			c.Forge = None
+			c.Reasons[Forge] = ReasonGHLogin
+		}
+	}
+
+	backend := strings.ToLower(p.TerminalBackend)
+	program := map[string]string{terminal.BackendITerm: "osascript", terminal.BackendTmux: "tmux"}[backend]
+	switch {
+	case backend == "":
+		c.Reasons[Terminal] = ReasonNoTerminal
+	case program == "":
+		c.Reasons[Terminal] = fmt.Sprintf("unknown agent.terminal %q (use iterm or tmux)", p.TerminalBackend)
+	default:
+		if _, err := p.LookPath(program); err != nil {
+			c.Reasons[Terminal] = fmt.Sprintf("terminal spawning is not available: agent.terminal %s needs %s, which is not installed", backend, program)
+		} else {
+			c.Terminal = backend
+		}
+	}
+	return c
+}
@@ -0,0 +1,75 @@
+package capabilities
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// installed returns a LookPath that finds only programs.
+func installed(programs ...string) func(string) (string, error) {
+	return func(file string) (string, error) {
+		for _, p := range programs {
+			if p == file {
+				return "/usr/bin/" + file, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func loggedIn() error { return nil }
+
+func TestDetect(t *testing.T) {
+	c := Detect(Probe{LLMConfigured: true, TerminalBackend: "tmux", LookPath: installed("gh", "tmux"), GitHubLogin: loggedIn})
+	assert.Equal(t, &Capabilities{LLM: On, Forge: ForgeGitHub, Terminal: "tmux", Reasons: map[Name]string{}}, c)
+	for _, name := range []Name{LLM, Forge, Terminal} {
+		assert.True(t, c.Has(name), name)
+		assert.NoError(t, c.Require(name), name)
+	}
+
+	c = Detect(Probe{LookPath: installed(), GitHubLogin: loggedIn})
+	assert.Equal(t, Off, c.LLM)
+	assert.Equal(t, None, c.Forge)
+	assert.Equal(t, None, c.Terminal)
+	assert.Equal(t, map[Name]string{LLM: ReasonNoLLM, Forge: ReasonNoGH, Terminal: ReasonNoTerminal}, c.Reasons)
+
+	err := c.Require(Forge)
+	var unavailable *UnavailableError
+	assert.ErrorAs(t, err, &unavailable)
+	assert.Equal(t, Forge, unavailable.Capability)
+	assert.EqualError(t, err, ReasonNoGH)
+}
+
+func TestDetect_GitHubLogin(t *testing.T) {
+	c := Detect(Probe{LookPath: installed("gh"), GitHubLogin: func() error { return errors.New("exit status 1") }})
+	assert.Equal(t, None, c.Forge)
+	assert.Equal(t, ReasonGHLogin, c.Reasons[Forge])
+}
+
+func TestDetect_ForgeURL(t *testing.T) {
+	// An API URL is reached over HTTP, so gh does not matter.
+	c := Detect(Probe{ForgeURL: "http://127.0.0.1:8089", LookPath: installed()})
+	assert.Equal(t, ForgeGitHub, c.Forge)
+	assert.NotContains(t, c.Reasons, Forge)
+}
+
+func TestDetect_Terminal(t *testing.T) {
+	c := Detect(Probe{TerminalBackend: "iTerm", LookPath: installed("gh", "tmux"), GitHubLogin: loggedIn})
+	assert.Equal(t, None, c.Terminal)
+	assert.Contains(t, c.Reasons[Terminal], "needs osascript")
+
+	c = Detect(Probe{TerminalBackend: "iterm", LookPath: installed("osascript"), GitHubLogin: loggedIn})
+	assert.Equal(t, "iterm", c.Terminal)
+
+	c = Detect(Probe{TerminalBackend: "screen", LookPath: installed("screen"), GitHubLogin: loggedIn})
+	assert.Equal(t, None, c.Terminal)
+	assert.Contains(t, c.Reasons[Terminal], `unknown agent.terminal "screen"`)
+}
+
+func TestNilHasAll(t *testing.T) {
+	var c *Capabilities
+	assert.True(t, c.Has(LLM))
+	assert.NoError(t, c.Require(Terminal))
+}
@@ -0,0 +1,248 @@
+// Package codesearch searches the files of a project directory or session
+// worktree for a regular expression or literal string, in the manner of
+// ripgrep: dependency, build and hidden directories and binary files are
+// skipped, paths can be filtered with globs and the number of matches is
+// capped.
+package codesearch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits keep a search of a huge tree, or for a pattern matching everything,
+// bounded.
+const (
+	DefaultMaxResults = 100
+	MaxMaxResults     = 1000
+
+	maxFiles     = 50000
+	maxFileBytes = 1 << 20
+	// maxLineBytes is how much of a matching line is returned.
+	maxLineBytes = 300
+	// sniffBytes are read to tell binary files, which contain NUL, apart.
+	sniffBytes = 8000
+)
+
+// skipDirs are directories that hold dependencies or build output, not the
+// project's own code. Hidden directories are skipped too.
+var skipDirs = map[string]bool{"node_modules": true, "vendor": true, "dist": true, "build": true, "target": true, "__pycache__": true}
+
+// Options is a search.
+type Options struct {
+	// Pattern is a Go regular expression, or a literal string with Literal.
+	Pattern    string
+	Literal    bool
+	IgnoreCase bool
+	// Paths are glob filters on the file paths, relative to the root with
+	// "/" separators. A file must match one of the globs without a "!"
+	// prefix, if any, and none of those with one. A glob without "/" matches
+	// a file or directory name at any depth ("*.go", "testdata"); one with
+	// "/" matches from the root ("internal/api", "cmd/*.go").
+	Paths []string
+	// MaxResults caps the matches returned: DefaultMaxResults when zero, at
+	// most MaxMaxResults.
+	MaxResults int
+}
+
+// Match is a line matching the pattern. Line and Column count from 1;
+// Column is in bytes.
+type Match struct {
+	Path   string `json:"path"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Text   string `json:"text"`
+}
+
+// Result is the outcome of a search. Truncated is set when matches were left
+// out because of MaxResults, or files because there were too many to search.
+type Result struct {
+	Matches       []Match `json:"matches"`
+	FilesSearched int     `json:"files_searched"`
+	FilesMatched  int     `json:"files_matched"`
+	Truncated     bool    `json:"truncated"`
+}
+
+// Compile returns the regexp opts search for.
+func (o Options) Compile() (*regexp.Regexp, error) {
+	if o.Pattern == "" {
+		return nil, errors.New("empty pattern")
+	}
+	expr := o.Pattern
+	if o.Literal {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if o.IgnoreCase {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	return re, nil
+}
+
+// ParsePaths splits a comma-separated list of path globs, as for
+// Options.Paths, and checks they are valid.
+func ParsePaths(s string) ([]string, error) {
+	var globs []string
+	for _, g := range strings.Split(s, ",") {
+		g = strings.TrimSpace(g)
+		if g == "" {
+			continue
+		}
+		if _, err := path.Match(strings.Trim(strings.TrimPrefix(g, "!"), "/"), ""); err != nil {
+			return nil, fmt.Errorf("invalid path glob %q", g)
+		}
+		globs = append(globs, g)
+	}
+	return globs, nil
+}
+
+// Search searches the files under root in lexical order and returns the
+// matches, at most Options.MaxResults of them.
+func Search(ctx context.Context, root string, opts Options) (*Result, error) {
+	re, err := opts.Compile()
+	if err != nil {
+		return nil, err
+	}
+	limit := opts.MaxResults
+	if limit <= 0 {
+		limit = DefaultMaxResults
+	}
+	limit = min(limit, MaxMaxResults)
+	var include, exclude []string
+	for _, g := range opts.Paths {
+		if g, ok := strings.CutPrefix(g, "!"); ok {
+			exclude = append(exclude, g)
+		} else {
+			include = append(include, g)
+		}
+	}
+
+	res := &Result{Matches: []Match{}}
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if p != root && (strings.HasPrefix(d.Name(), ".") || skipDirs[d.Name()] || matchAny(exclude, rel)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || matchAny(exclude, rel) || (len(include) > 0 && !matchAny(include, rel)) {
+			return nil
+		}
+		if res.FilesSearched >= maxFiles {
+			res.Truncated = true
+			return fs.SkipAll
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		res.FilesSearched++
+		found, more := searchFile(p, rel, re, limit-len(res.Matches))
+		if len(found) > 0 {
+			res.FilesMatched++
+			res.Matches = append(res.Matches, found...)
+		}
+		if more {
+			res.Truncated = true
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// searchFile returns up to limit matching lines of the file at p, and
+// whether it has more. Unreadable, large and binary files have none.
+func searchFile(p, rel string, re *regexp.Regexp, limit int) ([]Match, bool) {
+	info, err := os.Stat(p)
+	if err != nil || info.Size() > maxFileBytes {
+		return nil, false
+	}
+	data, err := os.ReadFile(p)
+	if err != nil || bytes.IndexByte(data[:min(len(data), sniffBytes)], 0) >= 0 {
+		return nil, false
+	}
+
+	var matches []Match
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), maxFileBytes)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Bytes()
+		loc := re.FindIndex(line)
+		if loc == nil {
+			continue
+		}
+		if len(matches) == limit {
+			return matches, true
+		}
+		matches = append(matches, Match{Path: rel, Line: n, Column: loc[0] + 1, Text: truncate(string(line))})
+	}
+	return matches, false
+}
+
+// truncate shortens a line to maxLineBytes without splitting a character.
+func truncate(s string) string {
+	s = strings.TrimRight(s, "\r")
+	if len(s) <= maxLineBytes {
+		return s
+	}
+	cut := maxLineBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
+
+// matchAny reports whether the relative path rel matches one of globs.
+func matchAny(globs []string, rel string) bool {
+	for _, g := range globs {
+		if matchGlob(g, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob reports whether rel, or a directory it is in, matches glob: by
+// name at any depth for a glob without "/", else from the root.
+func matchGlob(glob, rel string) bool {
+	glob = strings.Trim(glob, "/")
+	if glob == "" {
+		return false
+	}
+	segments := strings.Split(rel, "/")
+	if !strings.Contains(glob, "/") {
+		for _, seg := range segments {
+			if ok, _ := path.Match(glob, seg); ok {
+				return true
+			}
+		}
+		return false
+	}
+	for i := range segments {
+		if ok, _ := path.Match(glob, strings.Join(segments[:i+1], "/")); ok {
+			return true
+		}
+	}
+	return false
+}
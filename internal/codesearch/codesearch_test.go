@@ -0,0 +1,106 @@
+package codesearch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+	return root
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	root := writeTree(t, map[string]string{
+		"main.go":                  "package main\n\nfunc main() {\n\tNewServer()\n}\n",
+		"internal/api/api.go":      "package api\n\n// NewServer creates the server.\nfunc NewServer() {}\n",
+		"internal/api/api_test.go": "package api\n\nfunc TestNewServer() { NewServer() }\n",
+		"docs/api.md":              "Call NewServer to start.\n",
+		"node_modules/x/index.js":  "NewServer()\n",
+		".git/config":              "NewServer\n",
+		"bin/pm":                   "NewServer\x00\x01",
+	})
+
+	res, err := Search(ctx, root, Options{Pattern: `func \w+\(`})
+	require.NoError(t, err)
+	assert.Equal(t, []Match{
+		{Path: "internal/api/api.go", Line: 4, Column: 1, Text: "func NewServer() {}"},
+		{Path: "internal/api/api_test.go", Line: 3, Column: 1, Text: "func TestNewServer() { NewServer() }"},
+		{Path: "main.go", Line: 3, Column: 1, Text: "func main() {"},
+	}, res.Matches)
+	assert.Equal(t, 3, res.FilesMatched)
+	assert.Equal(t, 5, res.FilesSearched, "dependency, hidden and binary files are not matched")
+	assert.False(t, res.Truncated)
+
+	res, err = Search(ctx, root, Options{Pattern: "newserver()", Literal: true, IgnoreCase: true, Paths: []string{"*.go", "!*_test.go"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Match{
+		{Path: "internal/api/api.go", Line: 4, Column: 6, Text: "func NewServer() {}"},
+		{Path: "main.go", Line: 4, Column: 2, Text: "\tNewServer()"},
+	}, res.Matches)
+
+	res, err = Search(ctx, root, Options{Pattern: "NewServer", Paths: []string{"internal/api"}})
+	require.NoError(t, err)
+	assert.Len(t, res.Matches, 3)
+
+	res, err = Search(ctx, root, Options{Pattern: "NewServer", MaxResults: 2})
+	require.NoError(t, err)
+	assert.Len(t, res.Matches, 2)
+	assert.True(t, res.Truncated)
+
+	_, err = Search(ctx, root, Options{Pattern: "("})
+	assert.ErrorContains(t, err, "invalid pattern")
+	_, err = Search(ctx, root, Options{})
+	assert.Error(t, err)
+}
+
+func TestSearch_LongLines(t *testing.T) {
+	root := writeTree(t, map[string]string{"data.txt": strings.Repeat("é", 400) + "needle"})
+	res, err := Search(context.Background(), root, Options{Pattern: "needle"})
+	require.NoError(t, err)
+	require.Len(t, res.Matches, 1)
+	assert.Equal(t, 801, res.Matches[0].Column)
+	assert.LessOrEqual(t, len(res.Matches[0].Text), maxLineBytes+len("…"))
+	assert.True(t, strings.HasSuffix(res.Matches[0].Text, "é…"))
+}
+
+func TestParsePaths(t *testing.T) {
+	globs, err := ParsePaths(" *.go, !vendor/ ,,cmd/*.go")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"*.go", "!vendor/", "cmd/*.go"}, globs)
+
+	_, err = ParsePaths("[a")
+	assert.Error(t, err)
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		glob, rel string
+		want      bool
+	}{
+		{"*.go", "internal/api/api.go", true},
+		{"*.go", "README.md", false},
+		{"testdata", "internal/api/testdata/x.json", true},
+		{"internal/api", "internal/api/api.go", true},
+		{"internal/api", "cmd/internal/api/x.go", false},
+		{"cmd/*.go", "cmd/root.go", true},
+		{"cmd/*.go", "cmd/sub/x.go", false},
+		{"/docs/", "docs/index.md", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchGlob(tt.glob, tt.rel), "%s ~ %s", tt.glob, tt.rel)
+	}
+}
@@ -0,0 +1,152 @@
+// Package commitmsg checks commit messages against a project's commit
+// conventions.
+package commitmsg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// StyleConventional requires Conventional Commits subjects, e.g.
+// "feat(api): add login".
+const StyleConventional = "conventional"
+
+// Types are the Conventional Commits types accepted in a subject.
+var Types = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+var conventionalRe = regexp.MustCompile(`^(` + strings.Join(Types, "|") + `)(\([^()]+\))?!?: \S`)
+
+// Convention is a project's commit message rules. The zero value accepts
+// every message.
+type Convention struct {
+	Style        string // "" or StyleConventional
+	TicketPrefix string // when set, messages must reference a ticket like PROJ-123
+}
+
+// ForProject returns a project's convention.
+func ForProject(p *models.Project) Convention {
+	if p == nil {
+		return Convention{}
+	}
+	return Convention{Style: p.CommitStyle, TicketPrefix: p.CommitTicketPrefix}
+}
+
+// ValidateStyle reports whether style is a known commit style.
+func ValidateStyle(style string) error {
+	switch style {
+	case "", StyleConventional:
+		return nil
+	default:
+		return fmt.Errorf("unknown commit style %q (valid: %s, or empty for none)", style, StyleConventional)
+	}
+}
+
+// Enabled reports whether the convention checks anything.
+func (c Convention) Enabled() bool {
+	return c.Style != "" || c.TicketPrefix != ""
+}
+
+// Describe summarizes the convention for prompts and messages.
+func (c Convention) Describe() string {
+	var rules []string
+	if c.Style == StyleConventional {
+		rules = append(rules, fmt.Sprintf("Conventional Commits subject \"<type>(<scope>): <summary>\" with type one of %s", strings.Join(Types, ", ")))
+	}
+	if c.TicketPrefix != "" {
+		rules = append(rules, fmt.Sprintf("a ticket reference like %s123 in the message", c.TicketPrefix))
+	}
+	if len(rules) == 0 {
+		return "none"
+	}
+	return strings.Join(rules, "; ")
+}
+
+// Check returns the problems with one commit message, or nil if it complies.
+// Merge commits and fixup!/squash! commits are exempt.
+func (c Convention) Check(msg string) []string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+	subject = strings.TrimSpace(subject)
+	if exempt(subject) {
+		return nil
+	}
+
+	var problems []string
+	if c.Style == StyleConventional && !conventionalRe.MatchString(subject) {
+		problems = append(problems, "subject is not a Conventional Commit (<type>(<scope>): <summary>)")
+	}
+	if c.TicketPrefix != "" && !c.ticketRe().MatchString(msg) {
+		problems = append(problems, fmt.Sprintf("no %s ticket reference", c.TicketPrefix))
+	}
+	return problems
+}
+
+func (c Convention) ticketRe() *regexp.Regexp {
+	return regexp.MustCompile(`(^|[^\w])` + regexp.QuoteMeta(c.TicketPrefix) + `\d+\b`)
+}
+
+// Tickets returns the distinct ticket references in msgs, in order of first
+// appearance, or nil when the convention has no ticket prefix.
+func (c Convention) Tickets(msgs []string) []string {
+	if c.TicketPrefix == "" {
+		return nil
+	}
+	re := regexp.MustCompile(`(?:^|[^\w])(` + regexp.QuoteMeta(c.TicketPrefix) + `\d+)\b`)
+	seen := map[string]bool{}
+	var out []string
+	for _, m := range msgs {
+		for _, match := range re.FindAllStringSubmatch(m, -1) {
+			if t := match[1]; !seen[t] {
+				seen[t] = true
+				out = append(out, t)
+			}
+		}
+	}
+	return out
+}
+
+func exempt(subject string) bool {
+	for _, p := range []string{"Merge ", "fixup! ", "squash! ", "amend! "} {
+		if strings.HasPrefix(subject, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Violation is a commit message that breaks the convention.
+type Violation struct {
+	Subject  string   `json:"subject"`
+	Problems []string `json:"problems"`
+}
+
+// CheckAll checks messages (e.g. from git.Client.CommitMessagesSince) and
+// returns the ones that break the convention.
+func (c Convention) CheckAll(msgs []string) []Violation {
+	if !c.Enabled() {
+		return nil
+	}
+	var out []Violation
+	for _, m := range msgs {
+		if problems := c.Check(m); len(problems) > 0 {
+			subject, _, _ := strings.Cut(strings.TrimSpace(m), "\n")
+			out = append(out, Violation{Subject: subject, Problems: problems})
+		}
+	}
+	return out
+}
+
+// Summarize renders violations as one line, listing at most the first three.
+func Summarize(vs []Violation) string {
+	parts := make([]string, 0, 3)
+	for i, v := range vs {
+		if i == 3 {
+			parts = append(parts, fmt.Sprintf("and %d more", len(vs)-3))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%q: %s", v.Subject, strings.Join(v.Problems, ", ")))
+	}
+	return fmt.Sprintf("%d commit(s) break the commit conventions: %s", len(vs), strings.Join(parts, "; "))
+}
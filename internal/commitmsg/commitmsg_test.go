@@ -0,0 +1,72 @@
+package commitmsg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+func TestCheck_Conventional(t *testing.T) {
+	c := Convention{Style: StyleConventional}
+	for _, msg := range []string{
+		"feat: add login",
+		"fix(api): handle empty body\n\nLonger explanation.",
+		"refactor(store/sqlite)!: drop legacy columns",
+		"Merge branch 'main' into feature/x",
+		"fixup! feat: add login",
+	} {
+		assert.Empty(t, c.Check(msg), msg)
+	}
+	for _, msg := range []string{
+		"Add login",
+		"feature: add login",
+		"feat:add login",
+		"feat(): add login",
+	} {
+		assert.Len(t, c.Check(msg), 1, msg)
+	}
+}
+
+func TestCheck_TicketPrefix(t *testing.T) {
+	c := Convention{TicketPrefix: "PROJ-"}
+	assert.Empty(t, c.Check("[PROJ-12] Add login"))
+	assert.Empty(t, c.Check("Add login\n\nRefs: PROJ-12"))
+	assert.Equal(t, []string{"no PROJ- ticket reference"}, c.Check("Add login for XPROJ-12"))
+	assert.NotEmpty(t, c.Check("Add login for PROJ-"))
+
+	both := Convention{Style: StyleConventional, TicketPrefix: "PROJ-"}
+	assert.Len(t, both.Check("Add login"), 2)
+	assert.Empty(t, both.Check("feat: add login (PROJ-7)"))
+}
+
+func TestCheckAll(t *testing.T) {
+	msgs := []string{"feat: ok", "Bad one\n\nbody", "fix: fine"}
+	assert.Nil(t, Convention{}.CheckAll(msgs), "zero convention accepts everything")
+
+	vs := Convention{Style: StyleConventional}.CheckAll(msgs)
+	assert.Equal(t, []Violation{{Subject: "Bad one", Problems: []string{"subject is not a Conventional Commit (<type>(<scope>): <summary>)"}}}, vs)
+	assert.Contains(t, Summarize(vs), `1 commit(s) break the commit conventions: "Bad one"`)
+
+	many := make([]Violation, 5)
+	assert.Contains(t, Summarize(many), "and 2 more")
+}
+
+func TestTickets(t *testing.T) {
+	msgs := []string{"fix: login PROJ-12", "feat: form\n\nRefs PROJ-7, PROJ-12", "chore: bump XPROJ-9"}
+	assert.Equal(t, []string{"PROJ-12", "PROJ-7"}, Convention{TicketPrefix: "PROJ-"}.Tickets(msgs))
+	assert.Nil(t, Convention{}.Tickets(msgs))
+}
+
+func TestForProject(t *testing.T) {
+	assert.False(t, ForProject(nil).Enabled())
+	c := ForProject(&models.Project{CommitStyle: StyleConventional, CommitTicketPrefix: "OPS-"})
+	assert.True(t, c.Enabled())
+	assert.Contains(t, c.Describe(), "Conventional Commits")
+	assert.Contains(t, c.Describe(), "OPS-123")
+	assert.Equal(t, "none", Convention{}.Describe())
+
+	assert.NoError(t, ValidateStyle(""))
+	assert.Error(t, ValidateStyle("angular"))
+}
@@ -0,0 +1,438 @@
+// Package demo seeds a store with sample projects, issues, sessions and
+// reviews, backed by disposable git repositories, for pm demo.
+package demo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// Result summarizes what Seed created.
+type Result struct {
+	Projects []*models.Project
+	Issues   int
+	Sessions int
+	Reviews  int
+	Comments int
+}
+
+type projectSpec struct {
+	name, description, repoURL, language string
+	files                                map[string]string
+	issues                               []issueSpec
+}
+
+type issueSpec struct {
+	title, description string
+	status             models.IssueStatus
+	priority           models.IssuePriority
+	typ                models.IssueType
+	tags               []string
+	estimate           string
+	criteria           []string
+	github             int           // linked issue of the demo forge repository
+	age                time.Duration // created this long ago
+	session            *sessionSpec
+	review             *reviewSpec
+	comments           []commentSpec
+}
+
+// sessionSpec is an agent session on branch. Open sessions get a worktree;
+// completed ones are merged into main.
+type sessionSpec struct {
+	branch  string
+	status  models.SessionStatus
+	commits []string
+	idle    time.Duration // since the last activity
+}
+
+type reviewSpec struct {
+	verdict models.ReviewVerdict
+	summary string
+	failure string // why the last acceptance criterion failed
+}
+
+type commentSpec struct {
+	author, body string
+}
+
+// projects is the sample data. widgets matches fakeforge.Demo, so GitHub
+// features work against a demo forge.
+var projects = []projectSpec{
+	{
+		name:        "widgets",
+		description: "Widgets for every occasion",
+		repoURL:     "https://github.com/acme/widgets",
+		language:    "Go",
+		files: map[string]string{
+			"go.mod":           "module github.com/acme/widgets\n\ngo 1.23\n",
+			"main.go":          "package main\n\nimport \"github.com/acme/widgets/render\"\n\nfunc main() {\n\trender.All()\n}\n",
+			"render/render.go": "// Package render draws widgets.\npackage render\n\n// All renders every widget.\nfunc All() {}\n",
+			"README.md":        "# widgets\n\nWidgets for every occasion.\n",
+		},
+		issues: []issueSpec{
+			{
+				title: "Export widgets as CSV", description: "Users want to load their widgets into a spreadsheet.",
+				status: models.IssueStatusDone, priority: models.IssuePriorityMedium, typ: models.IssueTypeFeature,
+				tags: []string{"export"}, estimate: "3pt", github: 3, age: 21 * 24 * time.Hour,
+				criteria: []string{"pm export writes one row per widget", "Columns have a header row"},
+				session:  &sessionSpec{branch: "feature/export", status: models.SessionStatusCompleted, commits: []string{"Add CSV exporter", "Write the header row"}},
+				review:   &reviewSpec{verdict: models.ReviewVerdictPass, summary: "Exporter is small and covered by tests."},
+			},
+			{
+				title: "Rendering is slow", description: "Rendering 1,000 widgets takes over a second; cache the computed layouts.",
+				status: models.IssueStatusInProgress, priority: models.IssuePriorityHigh, typ: models.IssueTypeBug,
+				tags: []string{"performance"}, estimate: "5pt", github: 4, age: 9 * 24 * time.Hour,
+				criteria: []string{"Rendering 1,000 widgets takes under 200ms", "The layout cache has a size limit"},
+				session:  &sessionSpec{branch: "feature/render", status: models.SessionStatusActive, commits: []string{"Cache widget layouts", "Benchmark rendering"}, idle: 4 * time.Minute},
+				comments: []commentSpec{{"alice", "Profiling points at `layout()`: it runs once per widget per frame."}},
+			},
+			{
+				title: "Validate widget sizes", description: "Negative or zero sizes reach the renderer and panic.",
+				status: models.IssueStatusInProgress, priority: models.IssuePriorityMedium, typ: models.IssueTypeBug,
+				estimate: "2pt", age: 6 * 24 * time.Hour,
+				criteria: []string{"New rejects sizes below 1", "The error names the widget"},
+				session:  &sessionSpec{branch: "fix/widget-sizes", status: models.SessionStatusIdle, commits: []string{"Reject non-positive sizes"}, idle: 3 * time.Hour},
+				review:   &reviewSpec{verdict: models.ReviewVerdictFail, summary: "Sizes are checked, but the error does not say which widget.", failure: "The error message is only \"invalid size\"."},
+			},
+			{
+				title: "Add dark mode to the widget gallery", description: "Follow the system color scheme in the gallery page.",
+				status: models.IssueStatusInReview, priority: models.IssuePriorityLow, typ: models.IssueTypeFeature,
+				tags: []string{"ui"}, estimate: "3pt", age: 12 * 24 * time.Hour,
+				criteria: []string{"The gallery follows prefers-color-scheme"},
+				session:  &sessionSpec{branch: "feature/dark-mode", status: models.SessionStatusIdle, commits: []string{"Add dark palette", "Switch palettes on prefers-color-scheme"}, idle: 26 * time.Hour},
+				comments: []commentSpec{{"bob", "Screenshots look good to me."}},
+			},
+			{
+				title: "Crash when a widget has no name", description: "render.All panics with a nil map entry for unnamed widgets.",
+				status: models.IssueStatusOpen, priority: models.IssuePriorityHigh, typ: models.IssueTypeBug,
+				age: 2 * 24 * time.Hour,
+			},
+			{
+				title: "Document the plugin API", description: "Explain how to register a custom widget kind.",
+				status: models.IssueStatusOpen, priority: models.IssuePriorityLow, typ: models.IssueTypeChore,
+				tags: []string{"docs", "good-first-issue"}, estimate: "1pt", age: 30 * 24 * time.Hour,
+			},
+		},
+	},
+	{
+		name:        "gateway",
+		description: "HTTP gateway in front of the widget services",
+		language:    "Go",
+		files: map[string]string{
+			"go.mod":    "module example.com/gateway\n\ngo 1.23\n",
+			"main.go":   "package main\n\nimport \"net/http\"\n\nfunc main() {\n\t_ = http.ListenAndServe(\":8000\", proxy())\n}\n",
+			"proxy.go":  "package main\n\nimport \"net/http\"\n\nfunc proxy() http.Handler {\n\treturn http.NotFoundHandler()\n}\n",
+			"README.md": "# gateway\n\nRoutes API traffic to the widget services.\n",
+		},
+		issues: []issueSpec{
+			{
+				title: "Rate limit per API token", description: "Apply a token bucket per token instead of per client IP.",
+				status: models.IssueStatusInProgress, priority: models.IssuePriorityHigh, typ: models.IssueTypeFeature,
+				tags: []string{"api"}, estimate: "5pt", age: 5 * 24 * time.Hour,
+				criteria: []string{"Requests over the limit get 429", "Limits are configurable per token"},
+				session:  &sessionSpec{branch: "feature/token-rate-limit", status: models.SessionStatusActive, commits: []string{"Add token bucket limiter"}, idle: 15 * time.Minute},
+			},
+			{
+				title: "Retry upstream 502s", description: "Retry idempotent requests once when an upstream returns 502.",
+				status: models.IssueStatusOpen, priority: models.IssuePriorityMedium, typ: models.IssueTypeBug,
+				tags: []string{"api"}, estimate: "2pt", age: 8 * 24 * time.Hour,
+			},
+			{
+				title: "Structured access logs", description: "Log requests as JSON with latency and status.",
+				status: models.IssueStatusOpen, priority: models.IssuePriorityLow, typ: models.IssueTypeFeature,
+				age: 14 * 24 * time.Hour,
+			},
+			{
+				title: "Upgrade to Go 1.23", description: "Pick up the new http.ServeMux patterns.",
+				status: models.IssueStatusClosed, priority: models.IssuePriorityMedium, typ: models.IssueTypeChore,
+				age:     40 * 24 * time.Hour,
+				session: &sessionSpec{branch: "chore/go-1.23", status: models.SessionStatusCompleted, commits: []string{"Bump Go to 1.23"}},
+				review:  &reviewSpec{verdict: models.ReviewVerdictPass, summary: "Builds and tests pass on 1.23."},
+			},
+		},
+	},
+	{
+		name:        "docs-site",
+		description: "Documentation site for widgets and the gateway",
+		language:    "Markdown",
+		files: map[string]string{
+			"mkdocs.yml":      "site_name: Acme docs\nnav:\n  - index.md\n  - install.md\n",
+			"docs/index.md":   "# Acme docs\n\nStart with [installing](install.md).\n",
+			"docs/install.md": "# Install\n\nDownload a release from GitHub.\n",
+		},
+		issues: []issueSpec{
+			{
+				title: "Fix broken links on the install page", description: "Release links point at the old organization.",
+				status: models.IssueStatusDone, priority: models.IssuePriorityMedium, typ: models.IssueTypeBug,
+				tags: []string{"docs"}, estimate: "1pt", age: 10 * 24 * time.Hour,
+				criteria: []string{"mkdocs build --strict passes"},
+				session:  &sessionSpec{branch: "fix/install-links", status: models.SessionStatusCompleted, commits: []string{"Point release links at acme"}},
+				review:   &reviewSpec{verdict: models.ReviewVerdictPass, summary: "All links resolve."},
+			},
+			{
+				title: "Add a changelog page", description: "Render the release notes of each version.",
+				status: models.IssueStatusInReview, priority: models.IssuePriorityLow, typ: models.IssueTypeFeature,
+				tags: []string{"docs"}, estimate: "2pt", age: 4 * 24 * time.Hour,
+				criteria: []string{"The changelog lists every release"},
+				session:  &sessionSpec{branch: "feature/changelog", status: models.SessionStatusIdle, commits: []string{"Add changelog page"}, idle: 5 * time.Hour},
+			},
+			{
+				title: "Search across versions", description: "Let readers search the docs of every released version.",
+				status: models.IssueStatusOpen, priority: models.IssuePriorityMedium, typ: models.IssueTypeFeature,
+				tags: []string{"ui"}, age: 18 * 24 * time.Hour,
+			},
+		},
+	},
+}
+
+// Seed creates the sample projects in s, with git repositories under dir
+// and a worktree next to each repository for every open session. Records
+// are back-dated over the last weeks to give reports something to show.
+func Seed(ctx context.Context, s store.Store, dir string) (*Result, error) {
+	ctx = store.WithImportedTimestamps(ctx)
+	now := time.Now().UTC()
+	res := &Result{}
+	tags := map[string]string{}
+
+	for _, spec := range projects {
+		repo := filepath.Join(dir, spec.name)
+		if err := initRepo(ctx, repo, spec.files, now.Add(-45*24*time.Hour)); err != nil {
+			return nil, fmt.Errorf("%s: %w", spec.name, err)
+		}
+		p := &models.Project{Name: spec.name, Path: repo, Description: spec.description, RepoURL: spec.repoURL, Language: spec.language, GroupName: "acme"}
+		if err := s.CreateProject(ctx, p); err != nil {
+			return nil, fmt.Errorf("create project %s: %w", spec.name, err)
+		}
+		res.Projects = append(res.Projects, p)
+
+		for _, is := range spec.issues {
+			if err := seedIssue(ctx, s, p, is, now, tags, res); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", spec.name, is.title, err)
+			}
+		}
+	}
+	return res, nil
+}
+
+func seedIssue(ctx context.Context, s store.Store, p *models.Project, spec issueSpec, now time.Time, tags map[string]string, res *Result) error {
+	created := now.Add(-spec.age)
+	issue := &models.Issue{
+		ProjectID:          p.ID,
+		Title:              spec.title,
+		Description:        spec.description,
+		Status:             spec.status,
+		Priority:           spec.priority,
+		Type:               spec.typ,
+		GitHubIssue:        spec.github,
+		Estimate:           spec.estimate,
+		AcceptanceCriteria: spec.criteria,
+		CreatedAt:          created,
+	}
+	if err := s.CreateIssue(ctx, issue); err != nil {
+		return err
+	}
+	res.Issues++
+
+	for _, name := range spec.tags {
+		id, ok := tags[name]
+		if !ok {
+			tag := &models.Tag{Name: name}
+			if err := s.CreateTag(ctx, tag); err != nil {
+				return err
+			}
+			id = tag.ID
+			tags[name] = id
+		}
+		if err := s.TagIssue(ctx, issue.ID, id); err != nil {
+			return err
+		}
+	}
+
+	var session *models.AgentSession
+	if spec.session != nil {
+		started := created.Add(spec.age / 4)
+		var err error
+		if session, err = seedSession(ctx, p, issue, spec.session, started, now); err != nil {
+			return err
+		}
+		if err := s.CreateAgentSession(ctx, session); err != nil {
+			return err
+		}
+		res.Sessions++
+	}
+
+	if spec.review != nil {
+		review := &models.IssueReview{
+			IssueID:           issue.ID,
+			Verdict:           spec.review.verdict,
+			Summary:           spec.review.summary,
+			CodeQuality:       "pass",
+			RequirementsMatch: "pass",
+			TestCoverage:      "pass",
+			UIUX:              "skip",
+			ReviewedAt:        now.Add(-spec.age / 8),
+		}
+		review.CreatedAt = review.ReviewedAt
+		if session != nil {
+			review.SessionID = session.ID
+		}
+		for i, c := range spec.criteria {
+			result := models.CriterionResult{Criterion: c, Passed: true}
+			if spec.review.verdict == models.ReviewVerdictFail && i == len(spec.criteria)-1 {
+				result.Passed, result.Note = false, spec.review.failure
+				review.RequirementsMatch = "fail"
+				review.FailureReasons = []string{spec.review.failure}
+			}
+			review.Criteria = append(review.Criteria, result)
+		}
+		if err := s.CreateIssueReview(ctx, review); err != nil {
+			return err
+		}
+		res.Reviews++
+	}
+
+	for i, c := range spec.comments {
+		comment := &models.IssueComment{IssueID: issue.ID, Author: c.author, Body: c.body, CreatedAt: created.Add(time.Duration(i+1) * spec.age / 3)}
+		if err := s.CreateIssueComment(ctx, comment); err != nil {
+			return err
+		}
+		res.Comments++
+	}
+	return nil
+}
+
+// seedSession commits the session's work on its branch and returns the
+// session to store. Open sessions work in a worktree at
+// <repo>.worktrees/<last branch segment>, as pm's own sessions do.
+func seedSession(ctx context.Context, p *models.Project, issue *models.Issue, spec *sessionSpec, started, now time.Time) (*models.AgentSession, error) {
+	segments := strings.Split(spec.branch, "/")
+	worktree := filepath.Join(p.Path+".worktrees", segments[len(segments)-1])
+	if err := git(ctx, p.Path, started, "worktree", "add", "-q", "-b", spec.branch, worktree, "main"); err != nil {
+		return nil, err
+	}
+	base := filepath.Base(worktree)
+	for i, msg := range spec.commits {
+		at := started.Add(time.Duration(i+1) * time.Hour)
+		file := filepath.Join(worktree, "changes", base+".md")
+		if err := appendLine(file, "- "+msg); err != nil {
+			return nil, err
+		}
+		if err := commit(ctx, worktree, msg, at); err != nil {
+			return nil, err
+		}
+	}
+	hash, err := output(ctx, worktree, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	last := now.Add(-spec.idle)
+	session := &models.AgentSession{
+		ProjectID:         p.ID,
+		IssueID:           issue.ID,
+		Branch:            spec.branch,
+		WorktreePath:      worktree,
+		Status:            spec.status,
+		CommitCount:       len(spec.commits),
+		LastCommitHash:    hash,
+		LastCommitMessage: spec.commits[len(spec.commits)-1],
+		LastActiveAt:      &last,
+		StartedAt:         started,
+	}
+	if spec.status.IsOpen() {
+		return session, nil
+	}
+
+	// Completed: merge the branch and remove its worktree.
+	ended := started.Add(time.Duration(len(spec.commits)+1) * time.Hour)
+	session.LastActiveAt, session.EndedAt = &ended, &ended
+	if err := git(ctx, p.Path, ended, "merge", "-q", "--no-ff", "-m", "Merge branch '"+spec.branch+"'", spec.branch); err != nil {
+		return nil, err
+	}
+	if err := git(ctx, p.Path, ended, "worktree", "remove", worktree); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// initRepo creates a git repository at dir with files as its first commit
+// on main.
+func initRepo(ctx context.Context, dir string, files map[string]string, at time.Time) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err := git(ctx, dir, at, "init", "-q", "-b", "main"); err != nil {
+		return err
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	return commit(ctx, dir, "Initial commit", at)
+}
+
+func appendLine(path, line string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func commit(ctx context.Context, dir, msg string, at time.Time) error {
+	if err := git(ctx, dir, at, "add", "-A"); err != nil {
+		return err
+	}
+	return git(ctx, dir, at, "commit", "-q", "-m", msg)
+}
+
+func git(ctx context.Context, dir string, at time.Time, args ...string) error {
+	cmd := gitCommand(ctx, dir, at, args...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func output(ctx context.Context, dir string, args ...string) (string, error) {
+	out, err := gitCommand(ctx, dir, time.Now(), args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// gitCommand runs git in dir as a fixed demo author at time at, independent
+// of the user's git configuration.
+func gitCommand(ctx context.Context, dir string, at time.Time, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	date := at.Format(time.RFC3339)
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=pm demo", "GIT_AUTHOR_EMAIL=demo@example.com", "GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME=pm demo", "GIT_COMMITTER_EMAIL=demo@example.com", "GIT_COMMITTER_DATE="+date,
+	)
+	return cmd
+}
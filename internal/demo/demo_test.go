@@ -0,0 +1,60 @@
+package demo
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+func TestSeed(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	res, err := Seed(ctx, s, t.TempDir())
+	require.NoError(t, err)
+	require.Len(t, res.Projects, 3)
+	assert.Equal(t, 13, res.Issues)
+	assert.Equal(t, 8, res.Sessions)
+	assert.Equal(t, 4, res.Reviews)
+
+	widgets := res.Projects[0]
+	assert.Equal(t, "https://github.com/acme/widgets", widgets.RepoURL)
+	issues, err := s.ListIssues(ctx, store.IssueListFilter{ProjectID: widgets.ID})
+	require.NoError(t, err)
+	require.Len(t, issues, 6)
+
+	sessions, err := s.ListAgentSessions(ctx, widgets.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 4)
+	for _, sess := range sessions {
+		out, err := exec.Command("git", "-C", widgets.Path, "rev-parse", sess.Branch).Output()
+		require.NoError(t, err, sess.Branch)
+		assert.Equal(t, sess.LastCommitHash, strings.TrimSpace(string(out)))
+		if !sess.Status.IsOpen() {
+			assert.NoDirExists(t, sess.WorktreePath, "completed sessions drop their worktree")
+			continue
+		}
+		out, err = exec.Command("git", "-C", sess.WorktreePath, "rev-parse", "--abbrev-ref", "HEAD").Output()
+		require.NoError(t, err)
+		assert.Equal(t, sess.Branch, strings.TrimSpace(string(out)))
+	}
+
+	for _, i := range issues {
+		if i.Title != "Validate widget sizes" {
+			continue
+		}
+		reviews, err := s.ListIssueReviews(ctx, i.ID)
+		require.NoError(t, err)
+		require.Len(t, reviews, 1)
+		assert.Equal(t, models.ReviewVerdictFail, reviews[0].Verdict)
+		require.Len(t, reviews[0].Criteria, 2)
+		assert.False(t, reviews[0].Criteria[1].Passed)
+		assert.True(t, i.CreatedAt.Before(reviews[0].ReviewedAt))
+	}
+}
@@ -0,0 +1,190 @@
+// Package deps models dependencies between tracked projects and derives
+// warnings for downstream projects from the state of their upstreams.
+package deps
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// FailingHealth is the health score below which an upstream is flagged.
+const FailingHealth = 50
+
+// Graph is the dependency graph between tracked projects.
+type Graph struct {
+	projects map[string]*models.Project
+	upstream map[string][]string // project ID -> IDs it depends on
+	order    []*models.Project   // sorted by name
+}
+
+// Build creates a Graph from projects and dependency edges. Edges that
+// reference unknown projects are ignored.
+func Build(projects []*models.Project, edges []*models.ProjectDependency) *Graph {
+	g := &Graph{
+		projects: make(map[string]*models.Project, len(projects)),
+		upstream: make(map[string][]string),
+	}
+	for _, p := range projects {
+		g.projects[p.ID] = p
+		g.order = append(g.order, p)
+	}
+	sort.Slice(g.order, func(i, j int) bool { return g.order[i].Name < g.order[j].Name })
+
+	for _, e := range edges {
+		if g.projects[e.ProjectID] == nil || g.projects[e.DependsOnID] == nil {
+			continue
+		}
+		g.upstream[e.ProjectID] = append(g.upstream[e.ProjectID], e.DependsOnID)
+	}
+	for id, ups := range g.upstream {
+		sort.Slice(ups, func(i, j int) bool { return g.projects[ups[i]].Name < g.projects[ups[j]].Name })
+		g.upstream[id] = ups
+	}
+	return g
+}
+
+// Upstream returns the projects projectID depends on directly.
+func (g *Graph) Upstream(projectID string) []*models.Project {
+	var out []*models.Project
+	for _, id := range g.upstream[projectID] {
+		out = append(out, g.projects[id])
+	}
+	return out
+}
+
+// Downstream returns the projects that depend directly on projectID.
+func (g *Graph) Downstream(projectID string) []*models.Project {
+	var out []*models.Project
+	for _, p := range g.order {
+		for _, id := range g.upstream[p.ID] {
+			if id == projectID {
+				out = append(out, p)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// WouldCycle reports whether adding "projectID depends on dependsOnID" would
+// create a cycle, i.e. dependsOnID already depends on projectID.
+func (g *Graph) WouldCycle(projectID, dependsOnID string) bool {
+	if projectID == dependsOnID {
+		return true
+	}
+	seen := map[string]bool{}
+	stack := []string{dependsOnID}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == projectID {
+			return true
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		stack = append(stack, g.upstream[id]...)
+	}
+	return false
+}
+
+// DOT renders the graph in Graphviz DOT format, with edges pointing from a
+// project to its upstreams.
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph projects {\n  rankdir=LR;\n")
+	for _, p := range g.order {
+		fmt.Fprintf(&b, "  %q;\n", p.Name)
+	}
+	for _, p := range g.order {
+		for _, up := range g.Upstream(p.ID) {
+			fmt.Fprintf(&b, "  %q -> %q;\n", p.Name, up.Name)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Text renders each project that has dependencies as "name -> up1, up2".
+func (g *Graph) Text() string {
+	var b strings.Builder
+	for _, p := range g.order {
+		ups := g.Upstream(p.ID)
+		if len(ups) == 0 {
+			continue
+		}
+		names := make([]string, len(ups))
+		for i, up := range ups {
+			names[i] = up.Name
+		}
+		fmt.Fprintf(&b, "%s -> %s\n", p.Name, strings.Join(names, ", "))
+	}
+	if b.Len() == 0 {
+		return "No project dependencies.\n"
+	}
+	return b.String()
+}
+
+// UpstreamState is what downstream projects are warned about for an upstream.
+type UpstreamState struct {
+	Health   int
+	Release  string   // latest release tag, "" if none
+	Breaking []string // subjects of breaking commits since Release
+}
+
+// Warnings lists, for each project with upstreams, upstreams whose health is
+// below FailingHealth or that have unreleased breaking changes. Upstreams
+// without a state are skipped.
+func Warnings(g *Graph, states map[string]UpstreamState) []string {
+	var warnings []string
+	for _, p := range g.order {
+		for _, up := range g.Upstream(p.ID) {
+			st, ok := states[up.ID]
+			if !ok {
+				continue
+			}
+			if st.Health < FailingHealth {
+				warnings = append(warnings, fmt.Sprintf("%s: upstream %s has failing health (%d)", p.Name, up.Name, st.Health))
+			}
+			if len(st.Breaking) > 0 {
+				warnings = append(warnings, fmt.Sprintf("%s: upstream %s has %d unreleased breaking change(s) since %s: %s",
+					p.Name, up.Name, len(st.Breaking), st.Release, strings.Join(st.Breaking, "; ")))
+			}
+		}
+	}
+	return warnings
+}
+
+var breakingSubject = regexp.MustCompile(`^\w+(\([^)]*\))?!:`)
+
+// IsBreaking reports whether a commit message marks a breaking change using
+// conventional commit syntax ("feat!:" or a "BREAKING CHANGE:" footer).
+func IsBreaking(msg string) bool {
+	subject, _, _ := strings.Cut(msg, "\n")
+	if breakingSubject.MatchString(subject) {
+		return true
+	}
+	for _, line := range strings.Split(msg, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			return true
+		}
+	}
+	return false
+}
+
+// BreakingSubjects returns the subject lines of the breaking commits in msgs.
+func BreakingSubjects(msgs []string) []string {
+	var out []string
+	for _, m := range msgs {
+		if IsBreaking(m) {
+			subject, _, _ := strings.Cut(m, "\n")
+			out = append(out, subject)
+		}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package deps
+
+import (
+	"testing"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testGraph() *Graph {
+	projects := []*models.Project{
+		{ID: "p-pm", Name: "pm"},
+		{ID: "p-wt", Name: "wt"},
+		{ID: "p-lib", Name: "lib"},
+		{ID: "p-solo", Name: "solo"},
+	}
+	edges := []*models.ProjectDependency{
+		{ProjectID: "p-pm", DependsOnID: "p-wt"},
+		{ProjectID: "p-pm", DependsOnID: "p-lib"},
+		{ProjectID: "p-wt", DependsOnID: "p-lib"},
+		{ProjectID: "p-pm", DependsOnID: "p-removed"},
+	}
+	return Build(projects, edges)
+}
+
+func TestGraph_UpstreamDownstream(t *testing.T) {
+	g := testGraph()
+
+	var names []string
+	for _, p := range g.Upstream("p-pm") {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"lib", "wt"}, names, "unknown projects are ignored, names sorted")
+
+	names = nil
+	for _, p := range g.Downstream("p-lib") {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"pm", "wt"}, names)
+	assert.Empty(t, g.Downstream("p-pm"))
+}
+
+func TestGraph_WouldCycle(t *testing.T) {
+	g := testGraph()
+	assert.True(t, g.WouldCycle("p-lib", "p-pm"), "lib <- wt <- pm")
+	assert.True(t, g.WouldCycle("p-wt", "p-wt"))
+	assert.False(t, g.WouldCycle("p-solo", "p-pm"))
+	assert.False(t, g.WouldCycle("p-pm", "p-solo"))
+}
+
+func TestGraph_Render(t *testing.T) {
+	g := testGraph()
+	assert.Equal(t, "pm -> lib, wt\nwt -> lib\n", g.Text())
+
+	dot := g.DOT()
+	assert.Contains(t, dot, "digraph projects {")
+	assert.Contains(t, dot, `  "solo";`)
+	assert.Contains(t, dot, `  "pm" -> "wt";`)
+	assert.Contains(t, dot, `  "wt" -> "lib";`)
+
+	assert.Equal(t, "No project dependencies.\n", Build(nil, nil).Text())
+}
+
+func TestWarnings(t *testing.T) {
+	g := testGraph()
+	warnings := Warnings(g, map[string]UpstreamState{
+		"p-wt":  {Health: 85, Release: "v0.7.0", Breaking: []string{"feat!: drop Open"}},
+		"p-lib": {Health: 30},
+	})
+	assert.Equal(t, []string{
+		"pm: upstream lib has failing health (30)",
+		"pm: upstream wt has 1 unreleased breaking change(s) since v0.7.0: feat!: drop Open",
+		"wt: upstream lib has failing health (30)",
+	}, warnings)
+
+	assert.Empty(t, Warnings(g, nil))
+}
+
+func TestIsBreaking(t *testing.T) {
+	assert.True(t, IsBreaking("feat!: remove v1 API"))
+	assert.True(t, IsBreaking("refactor(store)!: rename methods"))
+	assert.True(t, IsBreaking("feat: new config\n\nBREAKING CHANGE: old keys removed"))
+	assert.False(t, IsBreaking("fix: handle breaking change in parser"))
+	assert.False(t, IsBreaking("Merge branch 'main'"))
+
+	assert.Equal(t, []string{"feat!: remove v1 API"}, BreakingSubjects([]string{"fix: x", "feat!: remove v1 API"}))
+}
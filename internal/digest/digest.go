@@ -0,0 +1,252 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joescharf/pm/internal/agent"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// Period is the time window a digest covers.
+type Period string
+
+const (
+	PeriodDaily  Period = "daily"
+	PeriodWeekly Period = "weekly"
+)
+
+// ParsePeriod validates a period name.
+func ParsePeriod(s string) (Period, error) {
+	switch Period(strings.ToLower(strings.TrimSpace(s))) {
+	case PeriodDaily:
+		return PeriodDaily, nil
+	case PeriodWeekly:
+		return PeriodWeekly, nil
+	default:
+		return "", fmt.Errorf("unknown digest period: %q (use: daily, weekly)", s)
+	}
+}
+
+// Duration returns the length of the period's window.
+func (p Period) Duration() time.Duration {
+	if p == PeriodWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// Store is the subset of store.Store needed to build a digest.
+type Store interface {
+	agent.WIPStore
+	ListProjects(ctx context.Context, group string) ([]*models.Project, error)
+	ListAgentSessions(ctx context.Context, projectID string, limit int) ([]*models.AgentSession, error)
+}
+
+// ProjectDigest summarizes one project's activity within the digest window.
+type ProjectDigest struct {
+	Name              string   `json:"name"`
+	Opened            []string `json:"opened,omitempty"`
+	Closed            []string `json:"closed,omitempty"`
+	InProgress        []string `json:"in_progress,omitempty"`
+	SessionsCompleted int      `json:"sessions_completed"`
+	SessionsAbandoned int      `json:"sessions_abandoned"`
+	ActiveSessions    int      `json:"active_sessions"`
+	Commits           int      `json:"commits"`
+	WIPWarnings       []string `json:"wip_warnings,omitempty"`
+	BlockedTooLong    []string `json:"blocked_too_long,omitempty"`
+}
+
+// Empty reports whether the project had no activity worth reporting.
+func (p ProjectDigest) Empty() bool {
+	return len(p.Opened) == 0 && len(p.Closed) == 0 && len(p.InProgress) == 0 &&
+		p.SessionsCompleted == 0 && p.SessionsAbandoned == 0 && p.ActiveSessions == 0 &&
+		len(p.WIPWarnings) == 0 && len(p.BlockedTooLong) == 0
+}
+
+// Digest is the activity summary for a set of projects over a period.
+type Digest struct {
+	Period   Period          `json:"period"`
+	Since    time.Time       `json:"since"`
+	Until    time.Time       `json:"until"`
+	Projects []ProjectDigest `json:"projects"`
+}
+
+// Build collects activity for the period ending at until. When projects is
+// non-empty only those project names are included; otherwise every project is.
+// Sessions blocked for at least blockedAfter are listed as reminders (<= 0
+// disables them). Projects with no activity are omitted.
+func Build(ctx context.Context, s Store, period Period, until time.Time, projects []string, blockedAfter time.Duration) (*Digest, error) {
+	d := &Digest{
+		Period: period,
+		Since:  until.Add(-period.Duration()),
+		Until:  until,
+	}
+
+	all, err := s.ListProjects(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	include := make(map[string]bool, len(projects))
+	for _, name := range projects {
+		include[name] = true
+	}
+
+	for _, p := range all {
+		if len(include) > 0 && !include[p.Name] {
+			continue
+		}
+		pd, err := buildProject(ctx, s, p, d.Since, d.Until, blockedAfter)
+		if err != nil {
+			return nil, err
+		}
+		if pd.Empty() {
+			continue
+		}
+		d.Projects = append(d.Projects, pd)
+	}
+	sort.Slice(d.Projects, func(i, j int) bool { return d.Projects[i].Name < d.Projects[j].Name })
+	return d, nil
+}
+
+func buildProject(ctx context.Context, s Store, p *models.Project, since, until time.Time, blockedAfter time.Duration) (ProjectDigest, error) {
+	pd := ProjectDigest{Name: p.Name}
+	within := func(t time.Time) bool { return !t.Before(since) && t.Before(until) }
+
+	issues, err := s.ListIssues(ctx, store.IssueListFilter{ProjectID: p.ID})
+	if err != nil {
+		return pd, fmt.Errorf("list issues for %s: %w", p.Name, err)
+	}
+	for _, i := range issues {
+		if within(i.CreatedAt) {
+			pd.Opened = append(pd.Opened, i.Title)
+		}
+		if i.ClosedAt != nil && within(*i.ClosedAt) {
+			pd.Closed = append(pd.Closed, i.Title)
+		}
+		if i.Status == models.IssueStatusInProgress {
+			pd.InProgress = append(pd.InProgress, i.Title)
+		}
+	}
+
+	sessions, err := s.ListAgentSessions(ctx, p.ID, 0)
+	if err != nil {
+		return pd, fmt.Errorf("list sessions for %s: %w", p.Name, err)
+	}
+	for _, sess := range sessions {
+		switch sess.Status {
+		case models.SessionStatusActive, models.SessionStatusIdle:
+			pd.ActiveSessions++
+		case models.SessionStatusCompleted, models.SessionStatusAbandoned:
+			if sess.EndedAt == nil || !within(*sess.EndedAt) {
+				continue
+			}
+			if sess.Status == models.SessionStatusCompleted {
+				pd.SessionsCompleted++
+			} else {
+				pd.SessionsAbandoned++
+			}
+			pd.Commits += sess.CommitCount
+		}
+	}
+	for _, sess := range agent.OverdueBlocked(sessions, blockedAfter, until) {
+		item := fmt.Sprintf("%s, blocked %dd: %s", sess.Branch, int(until.Sub(*sess.BlockedAt).Hours()/24), sess.BlockedReason)
+		if sess.UnblockCondition != "" {
+			item += " (until: " + sess.UnblockCondition + ")"
+		}
+		pd.BlockedTooLong = append(pd.BlockedTooLong, item)
+	}
+
+	wip, err := agent.CheckWIP(ctx, s, p, 0, 0)
+	if err != nil {
+		return pd, err
+	}
+	pd.WIPWarnings = wip.Warnings
+	return pd, nil
+}
+
+// Subject returns the email subject line for the digest.
+func (d *Digest) Subject() string {
+	return fmt.Sprintf("pm %s digest: %s", d.Period, d.Until.Format("2006-01-02"))
+}
+
+// Text renders the digest as plain text.
+func (d *Digest) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "pm %s digest\n", d.Period)
+	fmt.Fprintf(&b, "%s to %s\n", d.Since.Format("2006-01-02 15:04"), d.Until.Format("2006-01-02 15:04"))
+
+	if len(d.Projects) == 0 {
+		b.WriteString("\nNo activity.\n")
+	}
+	for _, p := range d.Projects {
+		fmt.Fprintf(&b, "\n== %s ==\n", p.Name)
+		fmt.Fprintf(&b, "Sessions: %d completed, %d abandoned, %d active (%d commits)\n",
+			p.SessionsCompleted, p.SessionsAbandoned, p.ActiveSessions, p.Commits)
+		writeList(&b, "Opened", p.Opened)
+		writeList(&b, "Closed", p.Closed)
+		writeList(&b, "In progress", p.InProgress)
+		writeList(&b, "WIP warnings", p.WIPWarnings)
+		writeList(&b, "Blocked too long", p.BlockedTooLong)
+	}
+	return b.String()
+}
+
+func writeList(b *strings.Builder, label string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s (%d):\n", label, len(items))
+	for _, item := range items {
+		fmt.Fprintf(b, "  - %s\n", item)
+	}
+}
+
+var htmlTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"list": func(v ...any) []any { return v },
+}).Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; font-size: 14px;">
+<h2>pm {{ .Period }} digest</h2>
+<p>{{ .Since.Format "2006-01-02 15:04" }} to {{ .Until.Format "2006-01-02 15:04" }}</p>
+{{- if not .Projects }}
+<p>No activity.</p>
+{{- end }}
+{{- range .Projects }}
+<h3>{{ .Name }}</h3>
+<p>Sessions: {{ .SessionsCompleted }} completed, {{ .SessionsAbandoned }} abandoned, {{ .ActiveSessions }} active ({{ .Commits }} commits)</p>
+{{- template "list" (list "Opened" .Opened) }}
+{{- template "list" (list "Closed" .Closed) }}
+{{- template "list" (list "In progress" .InProgress) }}
+{{- template "list" (list "WIP warnings" .WIPWarnings) }}
+{{- template "list" (list "Blocked too long" .BlockedTooLong) }}
+{{- end }}
+</body>
+</html>
+{{- define "list" }}
+{{- $items := index . 1 }}
+{{- if $items }}
+<p><strong>{{ index . 0 }} ({{ len $items }})</strong></p>
+<ul>
+{{- range $items }}
+<li>{{ . }}</li>
+{{- end }}
+</ul>
+{{- end }}
+{{- end }}
+`))
+
+// HTML renders the digest as an HTML document. All project and issue text is escaped.
+func (d *Digest) HTML() (string, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, d); err != nil {
+		return "", fmt.Errorf("render digest html: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,154 @@
+package digest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStore returns a memory store with activity in projects alpha and
+// beta during the day before now, and none in quiet.
+func newTestStore(t *testing.T, now time.Time) *store.MemoryStore {
+	t.Helper()
+	ctx := store.WithImportedTimestamps(context.Background())
+	recent := now.Add(-2 * time.Hour)
+	old := now.Add(-72 * time.Hour)
+	s := store.NewMemoryStore()
+	for _, p := range []*models.Project{
+		{ID: "p1", Name: "alpha", Path: "/code/alpha", MaxInProgress: 1},
+		{ID: "p2", Name: "beta", Path: "/code/beta"},
+		{ID: "p3", Name: "quiet", Path: "/code/quiet"},
+	} {
+		require.NoError(t, s.CreateProject(ctx, p))
+	}
+	for _, i := range []*models.Issue{
+		{ID: "i1", ProjectID: "p1", Title: "New <feature>", Status: models.IssueStatusOpen, CreatedAt: recent},
+		{ID: "i2", ProjectID: "p1", Title: "Fixed bug", Status: models.IssueStatusDone, CreatedAt: old, ClosedAt: &recent},
+		{ID: "i3", ProjectID: "p1", Title: "Working A", Status: models.IssueStatusInProgress, CreatedAt: old},
+		{ID: "i4", ProjectID: "p1", Title: "Working B", Status: models.IssueStatusInProgress, CreatedAt: old},
+		{ID: "i5", ProjectID: "p2", Title: "Old closed", Status: models.IssueStatusClosed, CreatedAt: old, ClosedAt: &old},
+		{ID: "i6", ProjectID: "p2", Title: "Beta new", Status: models.IssueStatusOpen, CreatedAt: recent},
+		{ID: "i7", ProjectID: "p3", Title: "Stale", Status: models.IssueStatusOpen, CreatedAt: old},
+	} {
+		require.NoError(t, s.CreateIssue(ctx, i))
+	}
+	createSessions(t, s,
+		&models.AgentSession{ID: "s1", ProjectID: "p1", Branch: "feature/s1", Status: models.SessionStatusCompleted, CommitCount: 3, EndedAt: &recent},
+		&models.AgentSession{ID: "s2", ProjectID: "p1", Branch: "feature/s2", Status: models.SessionStatusAbandoned, CommitCount: 1, EndedAt: &recent},
+		&models.AgentSession{ID: "s3", ProjectID: "p1", Branch: "feature/s3", Status: models.SessionStatusCompleted, CommitCount: 5, EndedAt: &old},
+		&models.AgentSession{ID: "s4", ProjectID: "p1", Branch: "feature/s4", Status: models.SessionStatusActive},
+	)
+	return s
+}
+
+// createSessions adds sessions to s, keeping the EndedAt they were given.
+func createSessions(t *testing.T, s *store.MemoryStore, sessions ...*models.AgentSession) {
+	t.Helper()
+	ctx := store.WithImportedTimestamps(context.Background())
+	for _, sess := range sessions {
+		require.NoError(t, s.CreateAgentSession(ctx, sess))
+		if sess.EndedAt != nil {
+			require.NoError(t, s.UpdateAgentSession(ctx, sess))
+		}
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	p, err := ParsePeriod("Weekly")
+	require.NoError(t, err)
+	assert.Equal(t, PeriodWeekly, p)
+	assert.Equal(t, 7*24*time.Hour, p.Duration())
+
+	_, err = ParsePeriod("monthly")
+	assert.Error(t, err)
+}
+
+func TestBuild(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	d, err := Build(context.Background(), newTestStore(t, now), PeriodDaily, now, nil, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, now.Add(-24*time.Hour), d.Since)
+	require.Len(t, d.Projects, 2, "project without activity is omitted")
+
+	alpha := d.Projects[0]
+	assert.Equal(t, "alpha", alpha.Name)
+	assert.Equal(t, []string{"New <feature>"}, alpha.Opened)
+	assert.Equal(t, []string{"Fixed bug"}, alpha.Closed)
+	assert.Equal(t, []string{"Working A", "Working B"}, alpha.InProgress)
+	assert.Equal(t, 1, alpha.SessionsCompleted)
+	assert.Equal(t, 1, alpha.SessionsAbandoned)
+	assert.Equal(t, 1, alpha.ActiveSessions)
+	assert.Equal(t, 4, alpha.Commits)
+	require.Len(t, alpha.WIPWarnings, 1)
+
+	beta := d.Projects[1]
+	assert.Equal(t, []string{"Beta new"}, beta.Opened)
+	assert.Empty(t, beta.Closed)
+}
+
+func TestBuild_ProjectFilter(t *testing.T) {
+	now := time.Now()
+	d, err := Build(context.Background(), newTestStore(t, now), PeriodWeekly, now, []string{"beta"}, 0)
+	require.NoError(t, err)
+	require.Len(t, d.Projects, 1)
+	assert.Equal(t, "beta", d.Projects[0].Name)
+	assert.Equal(t, []string{"Old closed"}, d.Projects[0].Closed)
+}
+
+func TestBuild_BlockedReminders(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	ms := newTestStore(t, now)
+	longAgo, lately := now.Add(-4*24*time.Hour), now.Add(-time.Hour)
+	createSessions(t, ms,
+		&models.AgentSession{ID: "s5", ProjectID: "p3", Branch: "feature/sso", Status: models.SessionStatusBlocked,
+			BlockedReason: "waiting on IdP credentials", UnblockCondition: "ops shares the client secret", BlockedAt: &longAgo},
+		&models.AgentSession{ID: "s6", ProjectID: "p3", Branch: "feature/theme", Status: models.SessionStatusBlocked,
+			BlockedReason: "waiting on design", BlockedAt: &lately},
+	)
+
+	d, err := Build(context.Background(), ms, PeriodDaily, now, []string{"quiet"}, 3*24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, d.Projects, 1, "a long-blocked session makes a quiet project worth reporting")
+	quiet := d.Projects[0]
+	assert.Equal(t, []string{"feature/sso, blocked 4d: waiting on IdP credentials (until: ops shares the client secret)"}, quiet.BlockedTooLong)
+	assert.Zero(t, quiet.ActiveSessions, "blocked sessions are not active")
+	assert.Contains(t, d.Text(), "Blocked too long (1):")
+
+	d, err = Build(context.Background(), ms, PeriodDaily, now, []string{"quiet"}, 0)
+	require.NoError(t, err)
+	assert.Empty(t, d.Projects, "reminders are disabled")
+}
+
+func TestRender(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	d, err := Build(context.Background(), newTestStore(t, now), PeriodDaily, now, []string{"alpha"}, 0)
+	require.NoError(t, err)
+
+	text := d.Text()
+	assert.Contains(t, text, "pm daily digest")
+	assert.Contains(t, text, "== alpha ==")
+	assert.Contains(t, text, "  - New <feature>")
+
+	html, err := d.HTML()
+	require.NoError(t, err)
+	assert.Contains(t, html, "<h3>alpha</h3>")
+	assert.Contains(t, html, "<li>New &lt;feature&gt;</li>")
+	assert.NotContains(t, html, "<img", "no tracking pixels")
+
+	assert.Equal(t, "pm daily digest: 2026-03-10", d.Subject())
+}
+
+func TestRender_Empty(t *testing.T) {
+	d := &Digest{Period: PeriodDaily, Until: time.Now()}
+	assert.Contains(t, d.Text(), "No activity.")
+	html, err := d.HTML()
+	require.NoError(t, err)
+	assert.Contains(t, html, "No activity.")
+	assert.NotContains(t, html, "<hr>")
+}
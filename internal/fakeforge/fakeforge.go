@@ -0,0 +1,490 @@
+// Package fakeforge is an in-memory HTTP server emulating the subset of the
+// GitHub REST API pm uses: repository info, Pages, releases, pull requests
+// with their reviews, and issues. Pointing github.api_url at one sends pm's
+// GitHub client through its whole code path without gh or a network, for
+// integration tests and offline development. GitHub Projects boards are not
+// emulated.
+package fakeforge
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Repo is a repository with everything the forge serves about it.
+type Repo struct {
+	Owner       string `json:"owner"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Language    string `json:"language,omitempty"`
+	Stars       int    `json:"stars,omitempty"`
+	Private     bool   `json:"private,omitempty"`
+	PagesURL    string `json:"pages_url,omitempty"` // empty for no Pages site
+
+	Releases []Release      `json:"releases,omitempty"` // the last is the latest
+	Pulls    []*PullRequest `json:"pulls,omitempty"`
+	Issues   []*Issue       `json:"issues,omitempty"`
+}
+
+// Release is a published release.
+type Release struct {
+	Tag         string    `json:"tag"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []Asset   `json:"assets,omitempty"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name      string `json:"name"`
+	Downloads int    `json:"downloads,omitempty"`
+	Size      int64  `json:"size,omitempty"`
+}
+
+// PullRequest is a pull request. State is open or closed; a merged pull
+// request is closed with Merged set.
+type PullRequest struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body,omitempty"`
+	Branch    string    `json:"branch"`
+	Author    string    `json:"author,omitempty"`
+	State     string    `json:"state,omitempty"` // default open
+	Merged    bool      `json:"merged,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+	Reviews   []Review  `json:"reviews,omitempty"`
+}
+
+// Review is a submitted pull request review.
+type Review struct {
+	ID          int64           `json:"id"`
+	Author      string          `json:"author"`
+	Bot         bool            `json:"bot,omitempty"`
+	State       string          `json:"state"` // APPROVED, CHANGES_REQUESTED, COMMENTED or DISMISSED
+	Body        string          `json:"body,omitempty"`
+	SubmittedAt time.Time       `json:"submitted_at,omitzero"`
+	Comments    []ReviewComment `json:"comments,omitempty"`
+}
+
+// ReviewComment is a review's comment on a line of the diff.
+type ReviewComment struct {
+	ID   int64  `json:"id"`
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Body string `json:"body"`
+}
+
+// Issue is an issue. State is open or closed; Comments are the bodies of the
+// comments posted to it, oldest first.
+type Issue struct {
+	Number   int      `json:"number"`
+	Title    string   `json:"title"`
+	State    string   `json:"state,omitempty"` // default open
+	Comments []string `json:"comments,omitempty"`
+}
+
+// Forge holds the repositories and serves them over HTTP. It is safe for
+// concurrent use.
+type Forge struct {
+	mu    sync.Mutex
+	repos map[string]*Repo // by owner/name
+}
+
+// New returns a forge serving repos.
+func New(repos ...*Repo) *Forge {
+	f := &Forge{repos: make(map[string]*Repo)}
+	for _, r := range repos {
+		f.AddRepo(r)
+	}
+	return f
+}
+
+// Load returns a forge serving the repositories of a JSON array, such as a
+// seed file.
+func Load(r io.Reader) (*Forge, error) {
+	var repos []*Repo
+	if err := json.NewDecoder(r).Decode(&repos); err != nil {
+		return nil, fmt.Errorf("parse forge seed: %w", err)
+	}
+	return New(repos...), nil
+}
+
+// AddRepo adds a repository, replacing one with the same owner and name.
+func (f *Forge) AddRepo(r *Repo) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.repos[r.Owner+"/"+r.Name] = r
+}
+
+// AddPull adds a pull request to a repository added before.
+func (f *Forge) AddPull(owner, repo string, pr *PullRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r := f.repos[owner+"/"+repo]
+	if r == nil {
+		return fmt.Errorf("unknown repository %s/%s", owner, repo)
+	}
+	r.Pulls = append(r.Pulls, pr)
+	return nil
+}
+
+// Issue returns a copy of a repository's issue.
+func (f *Forge) Issue(owner, repo string, number int) (Issue, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r := f.repos[owner+"/"+repo]; r != nil {
+		if i := findIssue(r, number); i != nil {
+			c := *i
+			c.Comments = slices.Clone(i.Comments)
+			return c, true
+		}
+	}
+	return Issue{}, false
+}
+
+// Start serves the forge on a local port until the returned func is called,
+// returning its base URL.
+func (f *Forge) Start() (url string, stop func()) {
+	srv := httptest.NewServer(f.Handler())
+	return srv.URL, srv.Close
+}
+
+// Handler returns the forge's REST API, rooted at "/" as api.github.com is.
+func (f *Forge) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/{owner}/{repo}", f.repo(f.getRepo))
+	mux.HandleFunc("GET /repos/{owner}/{repo}/pages", f.repo(f.getPages))
+	mux.HandleFunc("GET /repos/{owner}/{repo}/releases/latest", f.repo(f.getLatestRelease))
+	mux.HandleFunc("GET /repos/{owner}/{repo}/pulls", f.repo(f.listPulls))
+	mux.HandleFunc("GET /repos/{owner}/{repo}/pulls/{number}/reviews", f.repo(f.listReviews))
+	mux.HandleFunc("GET /repos/{owner}/{repo}/pulls/{number}/comments", f.repo(f.listReviewComments))
+	mux.HandleFunc("GET /repos/{owner}/{repo}/issues/{number}", f.repo(f.getIssue))
+	mux.HandleFunc("PATCH /repos/{owner}/{repo}/issues/{number}", f.repo(f.updateIssue))
+	mux.HandleFunc("POST /repos/{owner}/{repo}/issues/{number}/comments", f.repo(f.commentIssue))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "Not Found")
+	})
+	return mux
+}
+
+// repo wraps a handler with the forge's lock and the repository of the
+// request path, answering 404 for an unknown one.
+func (f *Forge) repo(h func(w http.ResponseWriter, r *http.Request, repo *Repo)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		repo := f.repos[r.PathValue("owner")+"/"+r.PathValue("repo")]
+		if repo == nil {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		h(w, r, repo)
+	}
+}
+
+func (f *Forge) getRepo(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	var language any
+	if repo.Language != "" {
+		language = repo.Language
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"name":             repo.Name,
+		"full_name":        repo.Owner + "/" + repo.Name,
+		"description":      repo.Description,
+		"language":         language,
+		"stargazers_count": repo.Stars,
+		"private":          repo.Private,
+		"html_url":         htmlURL(repo),
+	})
+}
+
+func (f *Forge) getPages(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	if repo.PagesURL == "" {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"html_url": repo.PagesURL})
+}
+
+func (f *Forge) getLatestRelease(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	if len(repo.Releases) == 0 {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	rel := repo.Releases[len(repo.Releases)-1]
+	assets := make([]map[string]any, len(rel.Assets))
+	for i, a := range rel.Assets {
+		assets[i] = map[string]any{"name": a.Name, "download_count": a.Downloads, "size": a.Size}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"tag_name":     rel.Tag,
+		"published_at": rel.PublishedAt,
+		"assets":       assets,
+	})
+}
+
+// listPulls answers like GitHub: state open (the default), closed or all,
+// head as owner:branch, newest first, paged by page and per_page.
+func (f *Forge) listPulls(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	q := r.URL.Query()
+	state := q.Get("state")
+	if state == "" {
+		state = "open"
+	}
+	var head string
+	if h := q.Get("head"); h != "" {
+		_, head, _ = strings.Cut(h, ":")
+	}
+
+	var out []map[string]any
+	for _, pr := range slices.Backward(repo.Pulls) {
+		if (state != "all" && pullState(pr) != state) || (head != "" && pr.Branch != head) {
+			continue
+		}
+		out = append(out, pullJSON(repo, pr))
+	}
+	writeJSON(w, http.StatusOK, paginate(r, out))
+}
+
+func (f *Forge) listReviews(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	pr := findPull(repo, r.PathValue("number"))
+	if pr == nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	out := []map[string]any{}
+	for _, rv := range pr.Reviews {
+		userType := "User"
+		if rv.Bot {
+			userType = "Bot"
+		}
+		out = append(out, map[string]any{
+			"id":           rv.ID,
+			"user":         map[string]any{"login": rv.Author, "type": userType},
+			"state":        rv.State,
+			"body":         rv.Body,
+			"html_url":     fmt.Sprintf("%s/pull/%d#pullrequestreview-%d", htmlURL(repo), pr.Number, rv.ID),
+			"submitted_at": rv.SubmittedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, paginate(r, out))
+}
+
+func (f *Forge) listReviewComments(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	pr := findPull(repo, r.PathValue("number"))
+	if pr == nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	out := []map[string]any{}
+	for _, rv := range pr.Reviews {
+		for _, c := range rv.Comments {
+			out = append(out, map[string]any{
+				"id":                     c.ID,
+				"pull_request_review_id": rv.ID,
+				"path":                   c.Path,
+				"line":                   c.Line,
+				"body":                   c.Body,
+				"html_url":               fmt.Sprintf("%s/pull/%d#discussion_r%d", htmlURL(repo), pr.Number, c.ID),
+			})
+		}
+	}
+	writeJSON(w, http.StatusOK, paginate(r, out))
+}
+
+func (f *Forge) getIssue(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	issue := findIssue(repo, atoi(r.PathValue("number")))
+	if issue == nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, issueJSON(repo, issue))
+}
+
+func (f *Forge) updateIssue(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	issue := findIssue(repo, atoi(r.PathValue("number")))
+	if issue == nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	var req struct {
+		Title *string `json:"title"`
+		State *string `json:"state"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+	if req.State != nil {
+		if *req.State != "open" && *req.State != "closed" {
+			writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+			return
+		}
+		issue.State = *req.State
+	}
+	if req.Title != nil {
+		issue.Title = *req.Title
+	}
+	writeJSON(w, http.StatusOK, issueJSON(repo, issue))
+}
+
+func (f *Forge) commentIssue(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	issue := findIssue(repo, atoi(r.PathValue("number")))
+	if issue == nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Body == "" {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+	issue.Comments = append(issue.Comments, req.Body)
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id":   len(issue.Comments),
+		"body": req.Body,
+	})
+}
+
+func htmlURL(repo *Repo) string {
+	return "https://github.com/" + repo.Owner + "/" + repo.Name
+}
+
+func pullState(pr *PullRequest) string {
+	if pr.State == "" && !pr.Merged {
+		return "open"
+	}
+	if pr.Merged {
+		return "closed"
+	}
+	return pr.State
+}
+
+func pullJSON(repo *Repo, pr *PullRequest) map[string]any {
+	var mergedAt any
+	if pr.Merged {
+		mergedAt = pr.UpdatedAt
+	}
+	return map[string]any{
+		"number":     pr.Number,
+		"title":      pr.Title,
+		"body":       pr.Body,
+		"state":      pullState(pr),
+		"merged_at":  mergedAt,
+		"updated_at": pr.UpdatedAt,
+		"html_url":   fmt.Sprintf("%s/pull/%d", htmlURL(repo), pr.Number),
+		"user":       map[string]any{"login": pr.Author},
+		"head":       map[string]any{"ref": pr.Branch, "label": repo.Owner + ":" + pr.Branch},
+	}
+}
+
+func issueJSON(repo *Repo, issue *Issue) map[string]any {
+	state := issue.State
+	if state == "" {
+		state = "open"
+	}
+	return map[string]any{
+		"number":   issue.Number,
+		"node_id":  fmt.Sprintf("I_%s_%s_%d", repo.Owner, repo.Name, issue.Number),
+		"title":    issue.Title,
+		"state":    state,
+		"comments": len(issue.Comments),
+		"html_url": fmt.Sprintf("%s/issues/%d", htmlURL(repo), issue.Number),
+	}
+}
+
+func findPull(repo *Repo, number string) *PullRequest {
+	n := atoi(number)
+	for _, pr := range repo.Pulls {
+		if pr.Number == n {
+			return pr
+		}
+	}
+	return nil
+}
+
+func findIssue(repo *Repo, number int) *Issue {
+	for _, i := range repo.Issues {
+		if i.Number == number {
+			return i
+		}
+	}
+	return nil
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// paginate returns the page of items the request's page and per_page (30 by
+// default, as on GitHub) select.
+func paginate(r *http.Request, items []map[string]any) []map[string]any {
+	perPage, page := 30, 1
+	if n, err := strconv.Atoi(r.URL.Query().Get("per_page")); err == nil && n > 0 {
+		perPage = min(n, 100)
+	}
+	if n, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && n > 0 {
+		page = n
+	}
+	start := min((page-1)*perPage, len(items))
+	end := min(start+perPage, len(items))
+	if items[start:end] == nil {
+		return []map[string]any{}
+	}
+	return items[start:end]
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{
+		"message":           message,
+		"documentation_url": "https://docs.github.com/rest",
+	})
+}
+
+// Demo returns repositories to serve when no seed is given: acme/widgets,
+// with a release, a Pages site, open and merged pull requests, reviews and
+// issues for pm to sync against.
+func Demo() []*Repo {
+	at := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	return []*Repo{{
+		Owner:       "acme",
+		Name:        "widgets",
+		Description: "Widgets for every occasion",
+		Language:    "Go",
+		Stars:       42,
+		PagesURL:    "https://acme.github.io/widgets/",
+		Releases: []Release{
+			{Tag: "v1.0.0", PublishedAt: at.AddDate(0, -2, 0)},
+			{Tag: "v1.1.0", PublishedAt: at, Assets: []Asset{{Name: "widgets_linux_amd64.tar.gz", Downloads: 128, Size: 4 << 20}}},
+		},
+		Pulls: []*PullRequest{
+			{Number: 1, Title: "Add widget export", Body: "Fixes #3", Branch: "feature/export", Author: "dev", Merged: true, UpdatedAt: at.AddDate(0, 0, -3)},
+			{Number: 2, Title: "Speed up rendering", Branch: "feature/render", Author: "dev", UpdatedAt: at, Reviews: []Review{
+				{ID: 101, Author: "reviewer", State: "CHANGES_REQUESTED", Body: "The cache needs a size limit.", SubmittedAt: at.Add(time.Hour), Comments: []ReviewComment{
+					{ID: 1001, Path: "render/cache.go", Line: 12, Body: "Unbounded map."},
+				}},
+				{ID: 102, Author: "ci-bot", Bot: true, State: "COMMENTED", Body: "Coverage 81%", SubmittedAt: at.Add(2 * time.Hour)},
+			}},
+		},
+		Issues: []*Issue{
+			{Number: 3, Title: "Export widgets as CSV", State: "closed"},
+			{Number: 4, Title: "Rendering is slow"},
+		},
+	}}
+}
@@ -0,0 +1,152 @@
+package fakeforge_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/autoclose"
+	"github.com/joescharf/pm/internal/fakeforge"
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/prreviews"
+	"github.com/joescharf/pm/internal/store"
+)
+
+func startDemo(t *testing.T) (*fakeforge.Forge, *git.RealGitHubClient) {
+	t.Helper()
+	forge := fakeforge.New(fakeforge.Demo()...)
+	url, stop := forge.Start()
+	t.Cleanup(stop)
+	return forge, git.NewGitHubAPIClient(url, "")
+}
+
+func TestGitHubClient(t *testing.T) {
+	forge, gh := startDemo(t)
+	forge.AddRepo(&fakeforge.Repo{Owner: "acme", Name: "bare"})
+
+	info, err := gh.RepoInfo("acme", "widgets")
+	require.NoError(t, err)
+	assert.Equal(t, &git.RepoInfo{Name: "widgets", Description: "Widgets for every occasion", Stars: 42, Language: "Go", URL: "https://github.com/acme/widgets"}, info)
+	info, err = gh.RepoInfo("acme", "bare")
+	require.NoError(t, err)
+	assert.Empty(t, info.Language)
+	_, err = gh.RepoInfo("acme", "missing")
+	assert.ErrorContains(t, err, "404")
+
+	pages, err := gh.PagesInfo("acme", "widgets")
+	require.NoError(t, err)
+	assert.Equal(t, "https://acme.github.io/widgets/", pages.URL)
+	pages, err = gh.PagesInfo("acme", "bare")
+	require.NoError(t, err)
+	assert.Nil(t, pages, "no Pages site is not an error")
+
+	rel, err := gh.LatestRelease("acme", "widgets")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.0", rel.TagName)
+	assert.Equal(t, "2026-01-05T09:00:00Z", rel.PublishedAt)
+	require.Len(t, rel.Assets, 1)
+	assert.Equal(t, 128, rel.Assets[0].DownloadCount)
+	_, err = gh.LatestRelease("acme", "bare")
+	assert.Error(t, err)
+
+	open, err := gh.OpenPRs("acme", "widgets")
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	assert.Equal(t, "feature/render", open[0].Branch)
+	assert.Equal(t, "OPEN", open[0].State)
+	assert.Equal(t, "dev", open[0].Author.Login)
+
+	merged, err := gh.MergedPRs("acme", "widgets", 10)
+	require.NoError(t, err)
+	require.Len(t, merged, 1)
+	assert.Equal(t, "Fixes #3", merged[0].Body)
+	assert.Equal(t, "MERGED", merged[0].State)
+
+	branch, err := gh.BranchPRs("acme", "widgets", "feature/export")
+	require.NoError(t, err)
+	require.Len(t, branch, 1)
+	assert.Equal(t, 1, branch[0].Number)
+
+	reviews, err := gh.PRReviews("acme", "widgets", 2)
+	require.NoError(t, err)
+	require.Len(t, reviews, 2)
+	assert.Equal(t, "CHANGES_REQUESTED", reviews[0].State)
+	require.Len(t, reviews[0].Comments, 1)
+	assert.Equal(t, 12, reviews[0].Comments[0].Line)
+	assert.True(t, reviews[1].Bot)
+
+	require.NoError(t, gh.CloseIssue("acme", "widgets", 4, "Closed by pm"))
+	issue, ok := forge.Issue("acme", "widgets", 4)
+	require.True(t, ok)
+	assert.Equal(t, "closed", issue.State)
+	assert.Equal(t, []string{"Closed by pm"}, issue.Comments)
+	require.NoError(t, gh.ReopenIssue("acme", "widgets", 4))
+	issue, _ = forge.Issue("acme", "widgets", 4)
+	assert.Equal(t, "open", issue.State)
+	assert.Error(t, gh.CloseIssue("acme", "widgets", 99, ""))
+
+	_, err = gh.Board("acme", 1)
+	assert.ErrorIs(t, err, git.ErrBoardsUnsupported)
+}
+
+func TestLoad(t *testing.T) {
+	forge, err := fakeforge.Load(strings.NewReader(`[{"owner": "o", "name": "r", "issues": [{"number": 7, "title": "Bug"}]}]`))
+	require.NoError(t, err)
+	issue, ok := forge.Issue("o", "r", 7)
+	require.True(t, ok)
+	assert.Equal(t, "Bug", issue.Title)
+
+	_, err = fakeforge.Load(strings.NewReader(`{`))
+	assert.Error(t, err)
+}
+
+// TestSyncs runs pm's GitHub features end to end against the demo forge.
+func TestSyncs(t *testing.T) {
+	forge, gh := startDemo(t)
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "widgets", Path: "/code/widgets", RepoURL: "https://github.com/acme/widgets"}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	slow := &models.Issue{ProjectID: p.ID, Title: "Rendering is slow", Status: models.IssueStatusInProgress, GitHubIssue: 4}
+	require.NoError(t, s.CreateIssue(ctx, slow))
+	require.NoError(t, s.CreateAgentSession(ctx, &models.AgentSession{ProjectID: p.ID, IssueID: slow.ID, Branch: "feature/render", Status: models.SessionStatusActive}))
+	imports, err := prreviews.Sync(ctx, s, gh, p, prreviews.Options{})
+	require.NoError(t, err)
+	require.Len(t, imports, 1, "the bot's review is skipped")
+	assert.Equal(t, "reviewer", imports[0].Reviewer)
+	comments, err := s.ListIssueComments(ctx, slow.ID)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Contains(t, comments[0].Body, "The cache needs a size limit.")
+
+	// GitHub closes the issues merged pull requests reference by number;
+	// pm closes the GitHub issue of a pm issue referenced by ID.
+	export := &models.Issue{ProjectID: p.ID, Title: "Export widgets as CSV", Status: models.IssueStatusOpen, GitHubIssue: 3}
+	require.NoError(t, s.CreateIssue(ctx, export))
+	require.NoError(t, forge.AddPull("acme", "widgets", &fakeforge.PullRequest{Number: 5, Title: "Cache rendering", Body: "Closes PM-" + slow.ID, Branch: "feature/cache", Merged: true}))
+	closures, err := autoclose.ScanMergedPRs(ctx, s, autoclose.Config{Enabled: true, GitHub: gh}, gh, p, 10, false)
+	require.NoError(t, err)
+	require.Len(t, closures, 2)
+	byIssue := map[string]*models.IssueClosure{}
+	for _, c := range closures {
+		byIssue[c.IssueID] = c
+	}
+	require.Contains(t, byIssue, export.ID)
+	assert.Zero(t, byIssue[export.ID].GitHubIssue)
+	require.Contains(t, byIssue, slow.ID)
+	assert.Equal(t, 4, byIssue[slow.ID].GitHubIssue)
+	issue, _ := forge.Issue("acme", "widgets", 4)
+	assert.Equal(t, "closed", issue.State)
+	require.Len(t, issue.Comments, 1)
+	assert.Contains(t, issue.Comments[0], "Closed by pm")
+
+	_, err = autoclose.Revert(ctx, s, gh, byIssue[slow.ID].ID)
+	require.NoError(t, err)
+	issue, _ = forge.Issue("acme", "widgets", 4)
+	assert.Equal(t, "open", issue.State, "reverting reopens the GitHub issue")
+}
@@ -0,0 +1,318 @@
+// Package federation combines several pm databases ("profiles"), local files
+// or other pm servers, into one read-only view with projects namespaced by
+// profile.
+package federation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// Config is a profile as written under profiles in the config file. Exactly
+// one of DBPath and URL is set.
+type Config struct {
+	Name   string `mapstructure:"name"`
+	DBPath string `mapstructure:"db_path"`
+	URL    string `mapstructure:"url"`   // base URL of a pm server
+	Token  string `mapstructure:"token"` // API token for URL, if it requires one
+}
+
+// Source reads one profile's data.
+type Source interface {
+	ListProjects(ctx context.Context) ([]*models.Project, error)
+	ListIssues(ctx context.Context) ([]*models.Issue, error)
+	// ListOpenSessions lists the active and idle agent sessions.
+	ListOpenSessions(ctx context.Context) ([]*models.AgentSession, error)
+}
+
+// Profile is a named source of pm data.
+type Profile struct {
+	Name     string
+	Location string // database path or server URL, for display
+	Source   Source
+	close    func() error
+}
+
+// Close releases the profile's database, if it opened one.
+func (p *Profile) Close() error {
+	if p.close == nil {
+		return nil
+	}
+	return p.close()
+}
+
+// Local returns a profile reading an open store, such as the current
+// database.
+func Local(name, location string, s store.Store) *Profile {
+	return &Profile{Name: name, Location: location, Source: StoreSource{Store: s}}
+}
+
+// Open validates configured profiles and opens their sources. Databases are
+// opened as they are, without migrating them. A profile whose db_path is
+// skip (the current database, already included) is left out.
+func Open(cfgs []Config, skip string) ([]*Profile, error) {
+	var profiles []*Profile
+	seen := make(map[string]bool, len(cfgs))
+	for _, c := range cfgs {
+		if err := validName(c.Name); err != nil {
+			return nil, err
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("profile %s: duplicate name", c.Name)
+		}
+		seen[c.Name] = true
+
+		switch {
+		case (c.DBPath == "") == (c.URL == ""):
+			return nil, fmt.Errorf("profile %s: set exactly one of db_path and url", c.Name)
+		case c.URL != "":
+			profiles = append(profiles, &Profile{Name: c.Name, Location: c.URL, Source: NewHTTPSource(c.URL, c.Token)})
+		case skip != "" && filepath.Clean(c.DBPath) == filepath.Clean(skip):
+		default:
+			// Opening a missing file would create an empty database
+			if _, err := os.Stat(c.DBPath); err != nil {
+				profiles = append(profiles, &Profile{Name: c.Name, Location: c.DBPath, Source: errSource{err}})
+				continue
+			}
+			s, err := store.NewSQLiteStore(c.DBPath)
+			if err != nil {
+				CloseAll(profiles)
+				return nil, fmt.Errorf("profile %s: open database: %w", c.Name, err)
+			}
+			profiles = append(profiles, &Profile{Name: c.Name, Location: c.DBPath, Source: StoreSource{Store: s}, close: s.Close})
+		}
+	}
+	return profiles, nil
+}
+
+// errSource is a profile that cannot be read, reporting why on every read.
+type errSource struct{ err error }
+
+func (e errSource) ListProjects(context.Context) ([]*models.Project, error) { return nil, e.err }
+func (e errSource) ListIssues(context.Context) ([]*models.Issue, error)     { return nil, e.err }
+func (e errSource) ListOpenSessions(context.Context) ([]*models.AgentSession, error) {
+	return nil, e.err
+}
+
+// validName checks a profile name. It namespaces project names and IDs, so
+// it cannot contain "/" or ":".
+func validName(name string) error {
+	if name == "" {
+		return fmt.Errorf("profile: name is required")
+	}
+	if strings.ContainsAny(name, "/: ") {
+		return fmt.Errorf("profile %s: name cannot contain '/', ':' or spaces", name)
+	}
+	return nil
+}
+
+// CloseAll closes the profiles' databases.
+func CloseAll(profiles []*Profile) {
+	for _, p := range profiles {
+		_ = p.Close()
+	}
+}
+
+// ProfileStatus reports how reading one profile went.
+type ProfileStatus struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	Projects int    `json:"projects"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ProjectSummary is one profile's project in the combined view. ID and Name
+// are namespaced as "<profile>:<id>" and "<profile>/<name>".
+type ProjectSummary struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	Profile        string     `json:"profile"`
+	ProjectID      string     `json:"project_id"`
+	ProjectName    string     `json:"project_name"`
+	Path           string     `json:"path"`
+	GroupName      string     `json:"group_name,omitempty"`
+	OpenIssues     int        `json:"open_issues"`
+	InProgress     int        `json:"in_progress"`
+	DoneIssues     int        `json:"done_issues"`
+	OpenSessions   int        `json:"open_sessions"`
+	LastActivityAt *time.Time `json:"last_activity_at,omitempty"`
+}
+
+// Issue is one profile's issue in the combined view. Like the issue list,
+// it keeps the issue's own field names, adding its profile, its namespaced
+// ID and its project's namespaced name.
+type Issue struct {
+	*models.Issue
+	Profile      string `json:"Profile"`
+	NamespacedID string `json:"NamespacedID"`
+	ProjectName  string `json:"ProjectName"`
+}
+
+// View is the combined view of several profiles.
+type View struct {
+	Profiles []ProfileStatus  `json:"profiles"`
+	Projects []ProjectSummary `json:"projects"`
+}
+
+// IssueView is the combined issue list of several profiles.
+type IssueView struct {
+	Profiles []ProfileStatus `json:"profiles"`
+	Issues   []Issue         `json:"issues"`
+}
+
+// Namespace prefixes a project name or ID with its profile.
+func Namespace(profile, name string) string { return profile + "/" + name }
+
+// NamespaceID prefixes a record ID with its profile.
+func NamespaceID(profile, id string) string { return profile + ":" + id }
+
+// snapshot is what was read from one profile.
+type snapshot struct {
+	projects []*models.Project
+	issues   []*models.Issue
+	sessions []*models.AgentSession
+	err      error
+}
+
+// read reads every profile in parallel. withSessions skips the session list
+// when it is not needed.
+func read(ctx context.Context, profiles []*Profile, withSessions bool) []snapshot {
+	snaps := make([]snapshot, len(profiles))
+	var wg sync.WaitGroup
+	for i, p := range profiles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			snap := &snaps[i]
+			if snap.projects, snap.err = p.Source.ListProjects(ctx); snap.err != nil {
+				return
+			}
+			if snap.issues, snap.err = p.Source.ListIssues(ctx); snap.err != nil {
+				return
+			}
+			if withSessions {
+				snap.sessions, snap.err = p.Source.ListOpenSessions(ctx)
+			}
+		}()
+	}
+	wg.Wait()
+	return snaps
+}
+
+func statuses(profiles []*Profile, snaps []snapshot) []ProfileStatus {
+	out := make([]ProfileStatus, len(profiles))
+	for i, p := range profiles {
+		out[i] = ProfileStatus{Name: p.Name, Location: p.Location, Projects: len(snaps[i].projects)}
+		if err := snaps[i].err; err != nil {
+			out[i] = ProfileStatus{Name: p.Name, Location: p.Location, Error: err.Error()}
+		}
+	}
+	return out
+}
+
+// Combine reads the profiles and summarizes their projects, sorted by
+// namespaced name. A profile that cannot be read is reported in the view's
+// Profiles rather than failing the view.
+func Combine(ctx context.Context, profiles []*Profile) *View {
+	snaps := read(ctx, profiles, true)
+	view := &View{Profiles: statuses(profiles, snaps), Projects: []ProjectSummary{}}
+	for i, p := range profiles {
+		snap := snaps[i]
+		if snap.err != nil {
+			continue
+		}
+		sums := make([]ProjectSummary, len(snap.projects))
+		byID := make(map[string]*ProjectSummary, len(snap.projects))
+		for j, proj := range snap.projects {
+			sums[j] = ProjectSummary{
+				ID:          NamespaceID(p.Name, proj.ID),
+				Name:        Namespace(p.Name, proj.Name),
+				Profile:     p.Name,
+				ProjectID:   proj.ID,
+				ProjectName: proj.Name,
+				Path:        proj.Path,
+				GroupName:   proj.GroupName,
+			}
+			byID[proj.ID] = &sums[j]
+			touch(&sums[j], proj.UpdatedAt)
+		}
+		for _, issue := range snap.issues {
+			sum := byID[issue.ProjectID]
+			if sum == nil {
+				continue
+			}
+			switch issue.Status {
+			case models.IssueStatusOpen:
+				sum.OpenIssues++
+			case models.IssueStatusInProgress, models.IssueStatusInReview:
+				sum.InProgress++
+			case models.IssueStatusDone, models.IssueStatusClosed:
+				sum.DoneIssues++
+			}
+			touch(sum, issue.UpdatedAt)
+		}
+		for _, sess := range snap.sessions {
+			sum := byID[sess.ProjectID]
+			if sum == nil {
+				continue
+			}
+			sum.OpenSessions++
+			if sess.LastActiveAt != nil {
+				touch(sum, *sess.LastActiveAt)
+			}
+		}
+		view.Projects = append(view.Projects, sums...)
+	}
+	sort.SliceStable(view.Projects, func(i, j int) bool { return view.Projects[i].Name < view.Projects[j].Name })
+	return view
+}
+
+// touch moves the summary's last activity up to t.
+func touch(sum *ProjectSummary, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	if sum.LastActivityAt == nil || t.After(*sum.LastActivityAt) {
+		t = t.UTC()
+		sum.LastActivityAt = &t
+	}
+}
+
+// Issues reads the profiles' issues, keeping those match accepts (nil keeps
+// all), ordered by profile and then as each profile lists them.
+func Issues(ctx context.Context, profiles []*Profile, match func(*models.Issue) bool) *IssueView {
+	snaps := read(ctx, profiles, false)
+	view := &IssueView{Profiles: statuses(profiles, snaps), Issues: []Issue{}}
+	for i, p := range profiles {
+		snap := snaps[i]
+		if snap.err != nil {
+			continue
+		}
+		names := make(map[string]string, len(snap.projects))
+		for _, proj := range snap.projects {
+			names[proj.ID] = proj.Name
+		}
+		for _, issue := range snap.issues {
+			name, ok := names[issue.ProjectID]
+			if !ok || (match != nil && !match(issue)) {
+				continue // archived project, or filtered out
+			}
+			view.Issues = append(view.Issues, Issue{
+				Issue:        issue,
+				Profile:      p.Name,
+				NamespacedID: NamespaceID(p.Name, issue.ID),
+				ProjectName:  Namespace(p.Name, name),
+			})
+		}
+	}
+	return view
+}
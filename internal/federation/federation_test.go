@@ -0,0 +1,152 @@
+package federation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+func TestOpen(t *testing.T) {
+	for _, tc := range []struct {
+		cfgs []Config
+		err  string
+	}{
+		{[]Config{{DBPath: "a.db"}}, "name is required"},
+		{[]Config{{Name: "work/api", DBPath: "a.db"}}, "cannot contain"},
+		{[]Config{{Name: "work"}}, "exactly one of db_path and url"},
+		{[]Config{{Name: "work", DBPath: "a.db", URL: "http://pm"}}, "exactly one of db_path and url"},
+		{[]Config{{Name: "work", URL: "http://a"}, {Name: "work", URL: "http://b"}}, "duplicate name"},
+	} {
+		_, err := Open(tc.cfgs, "")
+		assert.ErrorContains(t, err, tc.err)
+	}
+
+	dir := t.TempDir()
+	current := filepath.Join(dir, "pm.db")
+	home := filepath.Join(dir, "home.db")
+	hs, err := store.NewSQLiteStore(home)
+	require.NoError(t, err)
+	require.NoError(t, hs.Migrate(context.Background()))
+	require.NoError(t, hs.Close())
+
+	profiles, err := Open([]Config{
+		{Name: "work", DBPath: current},
+		{Name: "home", DBPath: home},
+		{Name: "team", URL: "http://pm.internal:8080/", Token: "pm_x"},
+		{Name: "old", DBPath: filepath.Join(dir, "missing.db")},
+	}, current)
+	require.NoError(t, err)
+	defer CloseAll(profiles)
+	require.Len(t, profiles, 3, "the current database is left out")
+	assert.Equal(t, "home", profiles[0].Name)
+	assert.Equal(t, "team", profiles[1].Name)
+	assert.Equal(t, "http://pm.internal:8080", profiles[1].Source.(*HTTPSource).BaseURL)
+
+	view := Combine(context.Background(), []*Profile{profiles[0], profiles[2]})
+	assert.Empty(t, view.Profiles[0].Error)
+	assert.Contains(t, view.Profiles[1].Error, "no such file", "a missing database is reported, not created")
+	assert.NoFileExists(t, filepath.Join(dir, "missing.db"))
+}
+
+// failingSource is a profile that cannot be read.
+type failingSource struct{}
+
+func (failingSource) ListProjects(context.Context) ([]*models.Project, error) {
+	return nil, errors.New("unable to open database file")
+}
+func (failingSource) ListIssues(context.Context) ([]*models.Issue, error) { return nil, nil }
+func (failingSource) ListOpenSessions(context.Context) ([]*models.AgentSession, error) {
+	return nil, nil
+}
+
+func seed(t *testing.T, name string, statuses ...models.IssueStatus) (*store.MemoryStore, *models.Project) {
+	t.Helper()
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: name, Path: "/src/" + name}
+	require.NoError(t, s.CreateProject(ctx, p))
+	for _, st := range statuses {
+		require.NoError(t, s.CreateIssue(ctx, &models.Issue{ProjectID: p.ID, Title: string(st) + " issue", Status: st}))
+	}
+	return s, p
+}
+
+func TestCombine(t *testing.T) {
+	ctx := context.Background()
+	work, api := seed(t, "api", models.IssueStatusOpen, models.IssueStatusOpen, models.IssueStatusInProgress, models.IssueStatusDone)
+	require.NoError(t, work.CreateAgentSession(ctx, &models.AgentSession{ProjectID: api.ID, Branch: "feature/a", Status: models.SessionStatusActive}))
+	require.NoError(t, work.CreateAgentSession(ctx, &models.AgentSession{ProjectID: api.ID, Branch: "feature/b", Status: models.SessionStatusCompleted}))
+	home, _ := seed(t, "api", models.IssueStatusClosed)
+
+	profiles := []*Profile{
+		Local("work", "/pm/work.db", work),
+		Local("home", "/pm/home.db", home),
+		{Name: "broken", Location: "/pm/broken.db", Source: failingSource{}},
+	}
+	view := Combine(ctx, profiles)
+
+	assert.Equal(t, []ProfileStatus{
+		{Name: "work", Location: "/pm/work.db", Projects: 1},
+		{Name: "home", Location: "/pm/home.db", Projects: 1},
+		{Name: "broken", Location: "/pm/broken.db", Error: "unable to open database file"},
+	}, view.Profiles)
+
+	require.Len(t, view.Projects, 2, "same-named projects stay apart")
+	assert.Equal(t, "home/api", view.Projects[0].Name)
+	assert.Equal(t, 1, view.Projects[0].DoneIssues)
+	got := view.Projects[1]
+	assert.Equal(t, "work/api", got.Name)
+	assert.Equal(t, "work:"+api.ID, got.ID)
+	assert.Equal(t, api.ID, got.ProjectID)
+	assert.Equal(t, "api", got.ProjectName)
+	assert.Equal(t, 2, got.OpenIssues)
+	assert.Equal(t, 1, got.InProgress)
+	assert.Equal(t, 1, got.DoneIssues)
+	assert.Equal(t, 1, got.OpenSessions, "completed sessions are not counted")
+	require.NotNil(t, got.LastActivityAt)
+
+	issues := Issues(ctx, profiles, func(i *models.Issue) bool { return i.Status == models.IssueStatusOpen })
+	require.Len(t, issues.Issues, 2)
+	assert.Equal(t, "work", issues.Issues[0].Profile)
+	assert.Equal(t, "work/api", issues.Issues[0].ProjectName)
+	assert.Equal(t, "work:"+issues.Issues[0].ID, issues.Issues[0].NamespacedID)
+	assert.NotEmpty(t, issues.Profiles[2].Error)
+}
+
+func TestHTTPSource(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer pm_secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid API token"}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/projects":
+			_, _ = w.Write([]byte(`[{"ID":"p1","Name":"blog"}]`))
+		case "/api/v1/issues":
+			_, _ = w.Write([]byte(`[{"ID":"i1","ProjectID":"p1","Title":"Dark mode","Status":"open"}]`))
+		case "/api/v1/sessions":
+			assert.Equal(t, "active,idle", r.URL.Query().Get("status"))
+			_, _ = w.Write([]byte(`[{"ID":"s1","ProjectID":"p1","Status":"idle","ProjectName":"blog"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	view := Combine(context.Background(), []*Profile{{Name: "home", Location: ts.URL, Source: NewHTTPSource(ts.URL+"/", "pm_secret")}})
+	require.Len(t, view.Projects, 1)
+	assert.Equal(t, ProjectSummary{ID: "home:p1", Name: "home/blog", Profile: "home", ProjectID: "p1", ProjectName: "blog", OpenIssues: 1, OpenSessions: 1}, view.Projects[0])
+
+	_, err := NewHTTPSource(ts.URL, "wrong").ListProjects(context.Background())
+	assert.EqualError(t, err, "GET /api/v1/projects: 401 Unauthorized: invalid API token")
+}
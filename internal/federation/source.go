@@ -0,0 +1,96 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// openStatuses are the sessions ListOpenSessions returns.
+var openStatuses = []models.SessionStatus{models.SessionStatusActive, models.SessionStatusIdle}
+
+// StoreSource reads a profile from a store.
+type StoreSource struct {
+	Store store.Store
+}
+
+func (s StoreSource) ListProjects(ctx context.Context) ([]*models.Project, error) {
+	return s.Store.ListProjects(ctx, "")
+}
+
+func (s StoreSource) ListIssues(ctx context.Context) ([]*models.Issue, error) {
+	return s.Store.ListIssues(ctx, store.IssueListFilter{})
+}
+
+func (s StoreSource) ListOpenSessions(ctx context.Context) ([]*models.AgentSession, error) {
+	return s.Store.ListAgentSessionsFiltered(ctx, store.SessionListFilter{Statuses: openStatuses})
+}
+
+// HTTPSource reads a profile from another pm server's API.
+type HTTPSource struct {
+	BaseURL string
+	Token   string
+	Client  *http.Client
+}
+
+// NewHTTPSource returns a source reading the pm server at baseURL, sending
+// token as a bearer token when it is set.
+func NewHTTPSource(baseURL, token string) *HTTPSource {
+	return &HTTPSource{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Token:   token,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (h *HTTPSource) ListProjects(ctx context.Context) ([]*models.Project, error) {
+	var projects []*models.Project
+	return projects, h.get(ctx, "/api/v1/projects", &projects)
+}
+
+func (h *HTTPSource) ListIssues(ctx context.Context) ([]*models.Issue, error) {
+	var issues []*models.Issue
+	return issues, h.get(ctx, "/api/v1/issues", &issues)
+}
+
+func (h *HTTPSource) ListOpenSessions(ctx context.Context) ([]*models.AgentSession, error) {
+	var sessions []*models.AgentSession
+	return sessions, h.get(ctx, "/api/v1/sessions?status=active,idle", &sessions)
+}
+
+// get decodes the JSON response of a GET request into v.
+func (h *HTTPSource) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.BaseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	if h.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.Token)
+	}
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("GET %s: %s: %s", path, resp.Status, apiErr.Error)
+		}
+		return fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("GET %s: decode response: %w", path, err)
+	}
+	return nil
+}
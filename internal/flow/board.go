@@ -0,0 +1,212 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/sla"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// ParseSince parses the start of a diff window: a duration back from now
+// ("7d", "2w", "36h") or a date (YYYY-MM-DD, midnight local time).
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if day, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return day, nil
+	}
+	d, err := sla.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since %q: use a duration (7d, 2w, 36h) or a date (YYYY-MM-DD)", value)
+	}
+	return now.Add(-d), nil
+}
+
+// BoardIssue is an issue added to the board during a diff's window.
+type BoardIssue struct {
+	IssueID string             `json:"issue_id"`
+	Title   string             `json:"title"`
+	Status  models.IssueStatus `json:"status"`
+}
+
+// BoardMove is an issue that was on the board at the start of the window and
+// is in another status at its end. Issues that moved and came back are not
+// moves.
+type BoardMove struct {
+	IssueID string             `json:"issue_id"`
+	Title   string             `json:"title"`
+	From    models.IssueStatus `json:"from"`
+	To      models.IssueStatus `json:"to"`
+}
+
+// BoardTransition counts the moves from one status to another.
+type BoardTransition struct {
+	From  models.IssueStatus `json:"from"`
+	To    models.IssueStatus `json:"to"`
+	Count int                `json:"count"`
+}
+
+// ProjectBoardDiff is what changed on one project's issue board over a
+// window. The backlog is the issues not done or closed; deleted issues count
+// towards the backlog at the start unless they were added during the window,
+// as their statuses are deleted with them.
+type ProjectBoardDiff struct {
+	ProjectID     string            `json:"project_id"`
+	Project       string            `json:"project"`
+	Added         []BoardIssue      `json:"added"`
+	Removed       []string          `json:"removed"` // IDs of deleted issues; their titles are gone
+	Moved         []BoardMove       `json:"moved"`
+	Transitions   []BoardTransition `json:"transitions"`
+	BacklogBefore int               `json:"backlog_before"`
+	BacklogAfter  int               `json:"backlog_after"`
+	NetGrowth     int               `json:"net_growth"`
+}
+
+// Empty reports whether nothing changed on the board.
+func (d ProjectBoardDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Moved) == 0
+}
+
+// BoardDiff is what changed on the issue boards of projects between Since
+// and Until. Deleted issues come from the change feed; when it has been
+// pruned past Since, RemovedSince says from when deletions are known.
+type BoardDiff struct {
+	Since        time.Time          `json:"since"`
+	Until        time.Time          `json:"until"`
+	RemovedSince *time.Time         `json:"removed_since,omitempty"`
+	Projects     []ProjectBoardDiff `json:"projects"`
+	NetGrowth    int                `json:"net_growth"`
+}
+
+// inBacklog reports whether an issue in status is still to be finished.
+func inBacklog(status models.IssueStatus) bool {
+	return status != "" && status != models.IssueStatusDone && status != models.IssueStatusClosed
+}
+
+// DiffBoard diffs a project's board at since with its current issues, from
+// their status events and the issue changes (inserts and deletes) recorded
+// since then.
+func DiffBoard(project *models.Project, issues []*models.Issue, events []*models.IssueStatusEvent, changes []*models.Change, since time.Time) ProjectBoardDiff {
+	d := ProjectBoardDiff{
+		ProjectID:   project.ID,
+		Project:     project.Name,
+		Added:       []BoardIssue{},
+		Removed:     []string{},
+		Moved:       []BoardMove{},
+		Transitions: []BoardTransition{},
+	}
+
+	before := make(map[string]models.IssueStatus)
+	sorted := make([]*models.IssueStatusEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ChangedAt.Before(sorted[j].ChangedAt) })
+	for _, e := range sorted {
+		if !e.ChangedAt.Before(since) {
+			break
+		}
+		before[e.IssueID] = e.ToStatus
+	}
+
+	sortedIssues := make([]*models.Issue, len(issues))
+	copy(sortedIssues, issues)
+	sort.SliceStable(sortedIssues, func(i, j int) bool { return sortedIssues[i].CreatedAt.Before(sortedIssues[j].CreatedAt) })
+	transitions := make(map[[2]models.IssueStatus]int)
+	for _, issue := range sortedIssues {
+		if inBacklog(issue.Status) {
+			d.BacklogAfter++
+		}
+		from, existed := before[issue.ID]
+		switch {
+		case !existed:
+			d.Added = append(d.Added, BoardIssue{IssueID: issue.ID, Title: issue.Title, Status: issue.Status})
+		case from != issue.Status:
+			d.Moved = append(d.Moved, BoardMove{IssueID: issue.ID, Title: issue.Title, From: from, To: issue.Status})
+			transitions[[2]models.IssueStatus{from, issue.Status}]++
+		}
+		if inBacklog(from) {
+			d.BacklogBefore++
+		}
+	}
+
+	inserted := make(map[string]bool)
+	for _, c := range changes {
+		if c.Entity != models.ChangeIssue || c.At.Before(since) {
+			continue
+		}
+		switch c.Op {
+		case models.ChangeInsert:
+			inserted[c.EntityID] = true
+		case models.ChangeDelete:
+			d.Removed = append(d.Removed, c.EntityID)
+			if !inserted[c.EntityID] {
+				d.BacklogBefore++
+			}
+		}
+	}
+
+	for t, n := range transitions {
+		d.Transitions = append(d.Transitions, BoardTransition{From: t[0], To: t[1], Count: n})
+	}
+	sort.Slice(d.Transitions, func(i, j int) bool {
+		a, b := d.Transitions[i], d.Transitions[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	d.NetGrowth = d.BacklogAfter - d.BacklogBefore
+	return d
+}
+
+// BoardStore is the subset of store.Store needed to diff boards.
+type BoardStore interface {
+	ListIssues(ctx context.Context, filter store.IssueListFilter) ([]*models.Issue, error)
+	ListIssueStatusEvents(ctx context.Context, projectID string) ([]*models.IssueStatusEvent, error)
+	ListChanges(ctx context.Context, filter store.ChangeFilter) ([]*models.Change, error)
+	ChangeSeqRange(ctx context.Context) (first, last int64, err error)
+}
+
+// BuildBoardDiff diffs the boards of projects between since and now.
+func BuildBoardDiff(ctx context.Context, s BoardStore, projects []*models.Project, since, now time.Time) (*BoardDiff, error) {
+	diff := &BoardDiff{Since: since, Until: now, Projects: []ProjectBoardDiff{}}
+
+	first, _, err := s.ChangeSeqRange(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if first > 1 {
+		oldest, err := s.ListChanges(ctx, store.ChangeFilter{Since: first - 1, Limit: 1})
+		if err != nil {
+			return nil, err
+		}
+		if len(oldest) == 0 {
+			diff.RemovedSince = &now
+		} else if oldest[0].At.After(since) {
+			diff.RemovedSince = &oldest[0].At
+		}
+	}
+
+	for _, p := range projects {
+		issues, err := s.ListIssues(ctx, store.IssueListFilter{ProjectID: p.ID})
+		if err != nil {
+			return nil, err
+		}
+		events, err := s.ListIssueStatusEvents(ctx, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		changes, err := s.ListChanges(ctx, store.ChangeFilter{ProjectID: p.ID, Entities: []models.ChangeEntity{models.ChangeIssue}})
+		if err != nil {
+			return nil, err
+		}
+		d := DiffBoard(p, issues, events, changes, since)
+		diff.NetGrowth += d.NetGrowth
+		diff.Projects = append(diff.Projects, d)
+	}
+	return diff, nil
+}
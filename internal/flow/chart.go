@@ -0,0 +1,210 @@
+package flow
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Band is a series drawn in a chart.
+type Band int
+
+const (
+	BandNone Band = iota
+	BandClosed
+	BandDone
+	BandInReview
+	BandInProgress
+	BandOpen
+	BandRemaining
+	BandIdeal
+)
+
+var glyphs = map[Band]string{
+	BandNone:       " ",
+	BandClosed:     "#",
+	BandDone:       "%",
+	BandInReview:   "~",
+	BandInProgress: "=",
+	BandOpen:       ":",
+	BandRemaining:  "#",
+	BandIdeal:      ".",
+}
+
+// Painter styles a run of one band's glyphs, e.g. with ANSI colors. A nil
+// Painter draws plain ASCII.
+type Painter func(b Band, s string) string
+
+// RenderCFD draws a stacked cumulative-flow chart, closed at the bottom and
+// open on top, one column per day (sampled down to width columns).
+func RenderCFD(days []CFDDay, height, width int, paint Painter) string {
+	days = sample(days, width)
+	maxY, inReview := 0, false
+	for _, d := range days {
+		maxY = max(maxY, d.Total())
+		inReview = inReview || d.InReview > 0
+	}
+	grid := newGrid(height, len(days))
+	for x, d := range days {
+		stack := []struct {
+			band Band
+			n    int
+		}{{BandClosed, d.Closed}, {BandDone, d.Done}, {BandInReview, d.InReview}, {BandInProgress, d.InProgress}, {BandOpen, d.Open}}
+		for y := range height {
+			level := cellLevel(y, height, maxY)
+			top := 0.0
+			for _, s := range stack {
+				top += float64(s.n)
+				if level < top {
+					grid[y][x] = s.band
+					break
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	first, last := dateRange(days, func(d CFDDay) time.Time { return d.Date })
+	b.WriteString(render(grid, maxY, first, last, paint))
+	items := []legendItem{{BandClosed, "closed"}, {BandDone, "done"}}
+	if inReview {
+		// Only projects whose cascade rules use in_review get the band.
+		items = append(items, legendItem{BandInReview, "in review"})
+	}
+	items = append(items, legendItem{BandInProgress, "in progress"}, legendItem{BandOpen, "open"})
+	b.WriteString(legend(paint, items))
+	return b.String()
+}
+
+// RenderBurndown draws remaining issues as bars with the ideal line as dots,
+// one column per day (sampled down to width columns). Future days show only
+// the ideal line.
+func RenderBurndown(days []BurndownDay, height, width int, paint Painter) string {
+	days = sample(days, width)
+	maxY := 0
+	for _, d := range days {
+		maxY = max(maxY, d.Remaining, int(math.Ceil(d.Ideal)))
+	}
+	grid := newGrid(height, len(days))
+	for x, d := range days {
+		idealRow := -1
+		if maxY > 0 && d.Ideal > 0 {
+			idealRow = min(height-1, int(math.Ceil(d.Ideal*float64(height)/float64(maxY)))-1)
+		}
+		for y := range height {
+			switch {
+			case !d.Future && cellLevel(y, height, maxY) < float64(d.Remaining):
+				grid[y][x] = BandRemaining
+			case y == idealRow:
+				grid[y][x] = BandIdeal
+			}
+		}
+	}
+
+	var b strings.Builder
+	first, last := dateRange(days, func(d BurndownDay) time.Time { return d.Date })
+	b.WriteString(render(grid, maxY, first, last, paint))
+	b.WriteString(legend(paint, []legendItem{{BandRemaining, "remaining"}, {BandIdeal, "ideal"}}))
+	return b.String()
+}
+
+// sample keeps at most width evenly spaced items, always including the last.
+func sample[T any](items []T, width int) []T {
+	if width <= 0 || len(items) <= width {
+		return items
+	}
+	out := make([]T, width)
+	for i := range width {
+		out[i] = items[i*(len(items)-1)/(width-1)]
+	}
+	return out
+}
+
+// newGrid returns height rows of cols cells; row 0 is the bottom.
+func newGrid(height, cols int) [][]Band {
+	grid := make([][]Band, height)
+	for y := range grid {
+		grid[y] = make([]Band, cols)
+	}
+	return grid
+}
+
+// cellLevel is the value at the vertical center of row y.
+func cellLevel(y, height, maxY int) float64 {
+	return (float64(y) + 0.5) * float64(maxY) / float64(height)
+}
+
+// dateRange labels the first and last day for the x axis.
+func dateRange[T any](days []T, date func(T) time.Time) (first, last string) {
+	if len(days) == 0 {
+		return "", ""
+	}
+	return date(days[0]).Format("01-02"), date(days[len(days)-1]).Format("01-02")
+}
+
+func render(grid [][]Band, maxY int, first, last string, paint Painter) string {
+	if paint == nil {
+		paint = func(_ Band, s string) string { return s }
+	}
+	labelWidth := len(fmt.Sprint(maxY))
+	height := len(grid)
+	cols := 0
+	if height > 0 {
+		cols = len(grid[0])
+	}
+
+	var b strings.Builder
+	for y := height - 1; y >= 0; y-- {
+		label := ""
+		switch y {
+		case height - 1:
+			label = fmt.Sprint(maxY)
+		case 0:
+			label = "0"
+		}
+		fmt.Fprintf(&b, "%*s |", labelWidth, label)
+
+		row := grid[y]
+		for len(row) > 0 && row[len(row)-1] == BandNone {
+			row = row[:len(row)-1]
+		}
+		for x := 0; x < len(row); {
+			end := x
+			for end < len(row) && row[end] == row[x] {
+				end++
+			}
+			run := strings.Repeat(glyphs[row[x]], end-x)
+			if row[x] != BandNone {
+				run = paint(row[x], run)
+			}
+			b.WriteString(run)
+			x = end
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "%*s +%s\n", labelWidth, "", strings.Repeat("-", cols))
+
+	axis := first
+	if gap := cols - len(first) - len(last); gap > 0 && first != last {
+		axis += strings.Repeat(" ", gap) + last
+	}
+	fmt.Fprintf(&b, "%*s  %s\n", labelWidth, "", axis)
+	return b.String()
+}
+
+type legendItem struct {
+	band  Band
+	label string
+}
+
+func legend(paint Painter, items []legendItem) string {
+	if paint == nil {
+		paint = func(_ Band, s string) string { return s }
+	}
+	parts := make([]string, len(items))
+	for i, it := range items {
+		parts[i] = paint(it.band, glyphs[it.band]) + " " + it.label
+	}
+	return strings.Join(parts, "  ") + "\n"
+}
@@ -0,0 +1,93 @@
+package flow
+
+import (
+	"sort"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// Durations summarizes a set of durations in hours.
+type Durations struct {
+	Count  int     `json:"count"`
+	Mean   float64 `json:"mean_hours"`
+	Median float64 `json:"median_hours"`
+	P85    float64 `json:"p85_hours"`
+	Max    float64 `json:"max_hours"`
+}
+
+// CycleStats summarizes how long issues completed in a window took. Lead time
+// runs from creation to completion; cycle time from first being started to
+// completion, so issues completed without ever being in progress only count
+// towards lead time.
+type CycleStats struct {
+	Completed int       `json:"completed"`
+	LeadTime  Durations `json:"lead_time"`
+	CycleTime Durations `json:"cycle_time"`
+}
+
+// CycleReport is the overall cycle stats plus a breakdown by issue type.
+type CycleReport struct {
+	Since  time.Time                       `json:"since"`
+	Until  time.Time                       `json:"until"`
+	All    CycleStats                      `json:"all"`
+	ByType map[models.IssueType]CycleStats `json:"by_type"`
+}
+
+// CycleTimes computes lead and cycle times of the issues completed between
+// since and until from their recorded started and completed times.
+func CycleTimes(issues []*models.Issue, since, until time.Time) CycleReport {
+	var lead, cycle []time.Duration
+	typeLead := make(map[models.IssueType][]time.Duration)
+	typeCycle := make(map[models.IssueType][]time.Duration)
+	for _, i := range issues {
+		if i.CompletedAt == nil || i.CompletedAt.Before(since) || !i.CompletedAt.Before(until) {
+			continue
+		}
+		d, _ := i.LeadTime()
+		lead = append(lead, d)
+		typeLead[i.Type] = append(typeLead[i.Type], d)
+		if d, ok := i.CycleTime(); ok {
+			cycle = append(cycle, d)
+			typeCycle[i.Type] = append(typeCycle[i.Type], d)
+		}
+	}
+
+	r := CycleReport{
+		Since:  since,
+		Until:  until,
+		All:    CycleStats{Completed: len(lead), LeadTime: summarize(lead), CycleTime: summarize(cycle)},
+		ByType: make(map[models.IssueType]CycleStats, len(typeLead)),
+	}
+	for t, l := range typeLead {
+		r.ByType[t] = CycleStats{Completed: len(l), LeadTime: summarize(l), CycleTime: summarize(typeCycle[t])}
+	}
+	return r
+}
+
+func summarize(ds []time.Duration) Durations {
+	if len(ds) == 0 {
+		return Durations{}
+	}
+	sorted := make([]time.Duration, len(ds))
+	copy(sorted, ds)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	return Durations{
+		Count:  len(sorted),
+		Mean:   (total / time.Duration(len(sorted))).Hours(),
+		Median: percentile(sorted, 0.5).Hours(),
+		P85:    percentile(sorted, 0.85).Hours(),
+		Max:    sorted[len(sorted)-1].Hours(),
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(p*float64(len(sorted))+0.999999) - 1
+	return sorted[max(0, min(rank, len(sorted)-1))]
+}
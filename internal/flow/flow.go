@@ -0,0 +1,126 @@
+package flow
+
+import (
+	"sort"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// CFDDay counts issues by status at the end of a day.
+type CFDDay struct {
+	Date       time.Time `json:"date"`
+	Open       int       `json:"open"`
+	InProgress int       `json:"in_progress"`
+	InReview   int       `json:"in_review"`
+	Done       int       `json:"done"`
+	Closed     int       `json:"closed"`
+}
+
+// Total is the number of issues that existed at the end of the day.
+func (d CFDDay) Total() int {
+	return d.Open + d.InProgress + d.InReview + d.Done + d.Closed
+}
+
+// BurndownDay is the remaining work in scope at the end of a day.
+type BurndownDay struct {
+	Date      time.Time `json:"date"`
+	Scope     int       `json:"scope"`     // issues created so far
+	Remaining int       `json:"remaining"` // not yet done or closed
+	Ideal     float64   `json:"ideal"`     // straight line from the first day's remaining to zero
+	Future    bool      `json:"future,omitempty"`
+}
+
+// Days returns midnight of each day from from to to, inclusive, in loc.
+func Days(from, to time.Time, loc *time.Location) []time.Time {
+	start := midnight(from, loc)
+	end := midnight(to, loc)
+	var days []time.Time
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+	return days
+}
+
+func midnight(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// replay walks days in order, applying each event that happened before the
+// end of the day to a status map, and calls fn with the state at day's end.
+// Only issues in scope are tracked; a nil scope tracks all.
+func replay(events []*models.IssueStatusEvent, scope map[string]bool, days []time.Time, fn func(day time.Time, status map[string]models.IssueStatus)) {
+	sorted := make([]*models.IssueStatusEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ChangedAt.Before(sorted[j].ChangedAt) })
+
+	status := make(map[string]models.IssueStatus)
+	next := 0
+	for _, day := range days {
+		end := day.AddDate(0, 0, 1)
+		for ; next < len(sorted) && sorted[next].ChangedAt.Before(end); next++ {
+			e := sorted[next]
+			if scope == nil || scope[e.IssueID] {
+				status[e.IssueID] = e.ToStatus
+			}
+		}
+		fn(day, status)
+	}
+}
+
+// CFD computes a cumulative-flow series over days from status events.
+func CFD(events []*models.IssueStatusEvent, scope map[string]bool, days []time.Time) []CFDDay {
+	out := make([]CFDDay, 0, len(days))
+	replay(events, scope, days, func(day time.Time, status map[string]models.IssueStatus) {
+		d := CFDDay{Date: day}
+		for _, st := range status {
+			switch st {
+			case models.IssueStatusOpen:
+				d.Open++
+			case models.IssueStatusInProgress:
+				d.InProgress++
+			case models.IssueStatusInReview:
+				d.InReview++
+			case models.IssueStatusDone:
+				d.Done++
+			case models.IssueStatusClosed:
+				d.Closed++
+			}
+		}
+		out = append(out, d)
+	})
+	return out
+}
+
+// Burndown computes remaining work over days from status events. The ideal
+// line runs from the first day's remaining count to zero on the last day;
+// days starting after now are marked Future and have no counts.
+func Burndown(events []*models.IssueStatusEvent, scope map[string]bool, days []time.Time, now time.Time) []BurndownDay {
+	out := make([]BurndownDay, 0, len(days))
+	replay(events, scope, days, func(day time.Time, status map[string]models.IssueStatus) {
+		if day.After(now) {
+			out = append(out, BurndownDay{Date: day, Future: true})
+			return
+		}
+		d := BurndownDay{Date: day, Scope: len(status)}
+		for _, st := range status {
+			if st != models.IssueStatusDone && st != models.IssueStatusClosed {
+				d.Remaining++
+			}
+		}
+		out = append(out, d)
+	})
+	if len(out) > 0 {
+		start := float64(out[0].Remaining)
+		span := float64(len(out) - 1)
+		for i := range out {
+			if span == 0 {
+				out[i].Ideal = start
+				continue
+			}
+			out[i].Ideal = start * (1 - float64(i)/span)
+		}
+	}
+	return out
+}
@@ -0,0 +1,186 @@
+package flow
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+var day0 = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func at(day, hour int) time.Time {
+	return day0.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour)
+}
+
+func event(issue string, to models.IssueStatus, day, hour int) *models.IssueStatusEvent {
+	return &models.IssueStatusEvent{IssueID: issue, ToStatus: to, ChangedAt: at(day, hour)}
+}
+
+// Three issues: a is opened day 0, started day 1, done day 2, closed day 3;
+// b is opened day 1 and started day 3; c is opened day 2.
+var events = []*models.IssueStatusEvent{
+	event("a", models.IssueStatusOpen, 0, 9),
+	event("b", models.IssueStatusOpen, 1, 9),
+	event("a", models.IssueStatusInProgress, 1, 10),
+	event("c", models.IssueStatusOpen, 2, 9),
+	event("a", models.IssueStatusDone, 2, 17),
+	event("a", models.IssueStatusClosed, 3, 8),
+	event("b", models.IssueStatusInProgress, 3, 12),
+}
+
+func TestDays(t *testing.T) {
+	days := Days(at(0, 15), at(3, 1), time.UTC)
+	require.Len(t, days, 4)
+	assert.Equal(t, day0, days[0])
+	assert.Equal(t, at(3, 0), days[3])
+}
+
+func TestCFD(t *testing.T) {
+	days := Days(at(0, 0), at(3, 0), time.UTC)
+	cfd := CFD(events, nil, days)
+	require.Len(t, cfd, 4)
+	assert.Equal(t, CFDDay{Date: at(0, 0), Open: 1}, cfd[0])
+	assert.Equal(t, CFDDay{Date: at(1, 0), Open: 1, InProgress: 1}, cfd[1])
+	assert.Equal(t, CFDDay{Date: at(2, 0), Open: 2, Done: 1}, cfd[2])
+	assert.Equal(t, CFDDay{Date: at(3, 0), Open: 1, InProgress: 1, Closed: 1}, cfd[3])
+
+	scoped := CFD(events, map[string]bool{"b": true}, days)
+	assert.Equal(t, 0, scoped[0].Total())
+	assert.Equal(t, CFDDay{Date: at(3, 0), InProgress: 1}, scoped[3])
+}
+
+func TestBurndown(t *testing.T) {
+	days := Days(at(1, 0), at(4, 0), time.UTC)
+	bd := Burndown(events, map[string]bool{"a": true, "b": true}, days, at(3, 12))
+	require.Len(t, bd, 4)
+	assert.Equal(t, BurndownDay{Date: at(1, 0), Scope: 2, Remaining: 2, Ideal: 2}, bd[0])
+	assert.Equal(t, 1, bd[1].Remaining, "a is done on day 2")
+	assert.InDelta(t, 4.0/3, bd[1].Ideal, 0.001)
+	assert.Equal(t, BurndownDay{Date: at(4, 0), Future: true}, bd[3])
+
+	assert.Empty(t, Burndown(events, nil, nil, at(0, 0)))
+}
+
+func TestRenderCFD(t *testing.T) {
+	cfd := CFD(events, nil, Days(at(0, 0), at(3, 0), time.UTC))
+	chart := RenderCFD(cfd, 3, 0, nil)
+	lines := strings.Split(chart, "\n")
+	require.Len(t, lines, 7)
+	assert.Equal(t, "3 |  ::", lines[0])
+	assert.Equal(t, "  | ::=", lines[1])
+	assert.Equal(t, "0 |:=%#", lines[2])
+	assert.Equal(t, "  +----", lines[3])
+	assert.Equal(t, "   03-01", strings.TrimRight(lines[4], " ")[:8])
+	assert.Equal(t, "# closed  % done  = in progress  : open", lines[5])
+
+	painted := RenderCFD(cfd, 3, 0, func(b Band, s string) string { return "<" + s + ">" })
+	assert.Contains(t, painted, "0 |<:><=><%><#>")
+}
+
+func TestRenderBurndown(t *testing.T) {
+	bd := []BurndownDay{
+		{Date: at(0, 0), Remaining: 4, Ideal: 4},
+		{Date: at(1, 0), Remaining: 4, Ideal: 2},
+		{Date: at(2, 0), Remaining: 1, Ideal: 0},
+	}
+	chart := RenderBurndown(bd, 4, 0, nil)
+	lines := strings.Split(chart, "\n")
+	assert.Equal(t, "4 |##", lines[0])
+	assert.Equal(t, "  |##", lines[1])
+	assert.Equal(t, "  |##", lines[2])
+	assert.Equal(t, "0 |###", lines[3])
+	assert.Equal(t, "# remaining  . ideal", lines[6])
+
+	bd[1].Remaining = 0
+	lines = strings.Split(RenderBurndown(bd, 4, 0, nil), "\n")
+	assert.Equal(t, "  |#.", lines[2], "ideal shows where there is no bar")
+
+	bd[0].Future = true
+	lines = strings.Split(RenderBurndown(bd, 4, 0, nil), "\n")
+	assert.Equal(t, "4 |.", lines[0], "future days have no bar")
+}
+
+func TestSample(t *testing.T) {
+	assert.Equal(t, []int{1, 2, 3}, sample([]int{1, 2, 3}, 5))
+	assert.Equal(t, []int{0, 4, 9}, sample([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, 3))
+}
+
+func TestCycleTimes(t *testing.T) {
+	ptr := func(t time.Time) *time.Time { return &t }
+	issues := []*models.Issue{
+		// Started after a day, done two days later.
+		{Type: models.IssueTypeFeature, CreatedAt: at(0, 0), StartedAt: ptr(at(1, 0)), CompletedAt: ptr(at(3, 0))},
+		// Started immediately, done six hours later.
+		{Type: models.IssueTypeBug, CreatedAt: at(2, 0), StartedAt: ptr(at(2, 0)), CompletedAt: ptr(at(2, 6))},
+		// Closed without ever being started: lead time only.
+		{Type: models.IssueTypeBug, CreatedAt: at(1, 0), CompletedAt: ptr(at(4, 0))},
+		// Still in progress, and completed before the window.
+		{Type: models.IssueTypeBug, CreatedAt: at(0, 0), StartedAt: ptr(at(1, 0))},
+		{Type: models.IssueTypeChore, CreatedAt: at(0, 0), CompletedAt: ptr(at(0, 1))},
+	}
+
+	r := CycleTimes(issues, at(1, 0), at(5, 0))
+	assert.Equal(t, 3, r.All.Completed)
+	assert.Equal(t, Durations{Count: 3, Mean: 50, Median: 72, P85: 72, Max: 72}, r.All.LeadTime)
+	assert.Equal(t, Durations{Count: 2, Mean: 27, Median: 6, P85: 48, Max: 48}, r.All.CycleTime)
+
+	require.Len(t, r.ByType, 2, "the chore was completed before the window")
+	bugs := r.ByType[models.IssueTypeBug]
+	assert.Equal(t, 2, bugs.Completed)
+	assert.Equal(t, 1, bugs.CycleTime.Count)
+	assert.Equal(t, 6.0, bugs.CycleTime.Max)
+
+	assert.Equal(t, CycleStats{}, CycleTimes(nil, at(0, 0), at(5, 0)).All)
+}
+
+func TestDiffBoard(t *testing.T) {
+	project := &models.Project{ID: "p", Name: "api"}
+	issues := []*models.Issue{
+		{ID: "a", Title: "A", Status: models.IssueStatusClosed, CreatedAt: at(0, 9)},
+		{ID: "b", Title: "B", Status: models.IssueStatusInProgress, CreatedAt: at(1, 9)},
+		{ID: "c", Title: "C", Status: models.IssueStatusOpen, CreatedAt: at(2, 9)},
+	}
+	// d existed before the window and was deleted; e came and went within it.
+	changes := []*models.Change{
+		{Entity: models.ChangeIssue, EntityID: "c", Op: models.ChangeInsert, At: at(2, 9)},
+		{Entity: models.ChangeIssue, EntityID: "d", Op: models.ChangeDelete, At: at(2, 10)},
+		{Entity: models.ChangeIssue, EntityID: "e", Op: models.ChangeInsert, At: at(2, 11)},
+		{Entity: models.ChangeIssue, EntityID: "e", Op: models.ChangeDelete, At: at(2, 12)},
+	}
+
+	d := DiffBoard(project, issues, events, changes, at(2, 0))
+	assert.Equal(t, []BoardIssue{{IssueID: "c", Title: "C", Status: models.IssueStatusOpen}}, d.Added)
+	assert.Equal(t, []string{"d", "e"}, d.Removed)
+	assert.Equal(t, []BoardMove{
+		{IssueID: "a", Title: "A", From: models.IssueStatusInProgress, To: models.IssueStatusClosed},
+		{IssueID: "b", Title: "B", From: models.IssueStatusOpen, To: models.IssueStatusInProgress},
+	}, d.Moved)
+	assert.Len(t, d.Transitions, 2)
+	assert.Equal(t, 3, d.BacklogBefore, "a, b and the deleted d")
+	assert.Equal(t, 2, d.BacklogAfter)
+	assert.Equal(t, -1, d.NetGrowth)
+	assert.False(t, d.Empty())
+
+	quiet := DiffBoard(project, issues, events, nil, at(4, 0))
+	assert.True(t, quiet.Empty())
+	assert.Equal(t, 0, quiet.NetGrowth)
+}
+
+func TestParseSince(t *testing.T) {
+	now := at(10, 12)
+	since, err := ParseSince("7d", now)
+	require.NoError(t, err)
+	assert.Equal(t, at(3, 12), since)
+
+	since, err = ParseSince("2026-03-04", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 4, 0, 0, 0, 0, time.Local), since)
+
+	_, err = ParseSince("last week", now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,162 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LineRange is an inclusive, 1-based range of lines in a file.
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// BlameLine is the commit that last touched one line.
+type BlameLine struct {
+	Commit  string
+	Author  string
+	Time    time.Time
+	Summary string
+}
+
+// AuthorLines counts the blamed lines attributed to one author.
+type AuthorLines struct {
+	Name  string `json:"name"`
+	Lines int    `json:"lines"`
+}
+
+// BlameSummary condenses the blame of the code surrounding a file's changes.
+type BlameSummary struct {
+	File        string        `json:"file"`
+	Lines       int           `json:"lines"`
+	Authors     []AuthorLines `json:"authors"`
+	LastTouched time.Time     `json:"last_touched"`
+	LastCommit  string        `json:"last_commit"`
+	StableDays  int           `json:"stable_days"` // days since any blamed line last changed
+}
+
+// Blame runs git blame at rev for the given line ranges of file.
+func (c *RealClient) Blame(ctx context.Context, path, rev, file string, ranges []LineRange) ([]BlameLine, error) {
+	args := []string{"blame", "--line-porcelain"}
+	for _, r := range ranges {
+		args = append(args, "-L", fmt.Sprintf("%d,%d", r.Start, r.End))
+	}
+	args = append(args, rev, "--", file)
+	out, err := gitCmd(ctx, path, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseLinePorcelain(out), nil
+}
+
+// parseLinePorcelain parses `git blame --line-porcelain` output, in which
+// every line carries its commit's full header.
+func parseLinePorcelain(out string) []BlameLine {
+	var lines []BlameLine
+	var cur BlameLine
+	for _, l := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(l, "\t"):
+			lines = append(lines, cur)
+			cur = BlameLine{}
+		case cur.Commit == "":
+			if f := strings.Fields(l); len(f) >= 3 {
+				cur.Commit = f[0]
+			}
+		case strings.HasPrefix(l, "author "):
+			cur.Author = strings.TrimPrefix(l, "author ")
+		case strings.HasPrefix(l, "author-time "):
+			if sec, err := strconv.ParseInt(strings.TrimPrefix(l, "author-time "), 10, 64); err == nil {
+				cur.Time = time.Unix(sec, 0).UTC()
+			}
+		case strings.HasPrefix(l, "summary "):
+			cur.Summary = strings.TrimPrefix(l, "summary ")
+		}
+	}
+	return lines
+}
+
+// OldHunkRanges returns, per file, the pre-change line ranges of each hunk in
+// a unified diff. Hunks include their context lines, so the ranges cover the
+// code surrounding each change. Added files have no old lines and are omitted.
+func OldHunkRanges(diff string) map[string][]LineRange {
+	ranges := make(map[string][]LineRange)
+	var file string
+	for _, l := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(l, "--- "):
+			file = ""
+			if name, ok := strings.CutPrefix(l, "--- a/"); ok {
+				file = name
+			}
+		case strings.HasPrefix(l, "@@ ") && file != "":
+			if r, ok := parseOldHunk(l); ok {
+				ranges[file] = append(ranges[file], r)
+			}
+		}
+	}
+	return ranges
+}
+
+// parseOldHunk reads the "-start,count" part of a "@@ -a,b +c,d @@" header.
+func parseOldHunk(header string) (LineRange, bool) {
+	f := strings.Fields(header)
+	if len(f) < 2 || !strings.HasPrefix(f[1], "-") {
+		return LineRange{}, false
+	}
+	startStr, countStr, found := strings.Cut(f[1][1:], ",")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return LineRange{}, false
+	}
+	count := 1
+	if found {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return LineRange{}, false
+		}
+	}
+	if count == 0 {
+		return LineRange{}, false
+	}
+	return LineRange{Start: start, End: start + count - 1}, true
+}
+
+// SummarizeBlame reports who last touched the blamed lines and how long they
+// have been stable as of now.
+func SummarizeBlame(file string, lines []BlameLine, now time.Time) BlameSummary {
+	s := BlameSummary{File: file, Lines: len(lines), Authors: []AuthorLines{}}
+	counts := make(map[string]int)
+	var last BlameLine
+	for _, l := range lines {
+		counts[l.Author]++
+		if l.Time.After(last.Time) {
+			last = l
+		}
+	}
+	for name, n := range counts {
+		s.Authors = append(s.Authors, AuthorLines{Name: name, Lines: n})
+	}
+	sort.Slice(s.Authors, func(i, j int) bool {
+		if s.Authors[i].Lines != s.Authors[j].Lines {
+			return s.Authors[i].Lines > s.Authors[j].Lines
+		}
+		return s.Authors[i].Name < s.Authors[j].Name
+	})
+	if !last.Time.IsZero() {
+		s.LastTouched = last.Time
+		s.LastCommit = shortCommit(last.Commit) + " " + last.Summary
+		s.StableDays = int(now.Sub(last.Time).Hours() / 24)
+	}
+	return s
+}
+
+func shortCommit(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
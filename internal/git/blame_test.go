@@ -0,0 +1,72 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOldHunkRanges(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --git a/a.go b/a.go",
+		"--- a/a.go",
+		"+++ b/a.go",
+		"@@ -3,4 +3,5 @@ func a() {",
+		"@@ -20 +21,2 @@",
+		"diff --git a/new.go b/new.go",
+		"--- /dev/null",
+		"+++ b/new.go",
+		"@@ -0,0 +1,3 @@",
+		"diff --git a/gone.go b/gone.go",
+		"--- a/gone.go",
+		"+++ /dev/null",
+		"@@ -1,2 +0,0 @@",
+	}, "\n")
+
+	assert.Equal(t, map[string][]LineRange{
+		"a.go":    {{Start: 3, End: 6}, {Start: 20, End: 20}},
+		"gone.go": {{Start: 1, End: 2}},
+	}, OldHunkRanges(diff))
+}
+
+func TestSummarizeBlame(t *testing.T) {
+	now := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+	lines := []BlameLine{
+		{Commit: "aaaaaaaaaa", Author: "Bob", Time: now.AddDate(0, 0, -400), Summary: "old"},
+		{Commit: "bbbbbbbbbb", Author: "Ada", Time: now.AddDate(0, 0, -10), Summary: "recent"},
+		{Commit: "aaaaaaaaaa", Author: "Bob", Time: now.AddDate(0, 0, -400), Summary: "old"},
+	}
+
+	s := SummarizeBlame("a.go", lines, now)
+	assert.Equal(t, 3, s.Lines)
+	assert.Equal(t, []AuthorLines{{Name: "Bob", Lines: 2}, {Name: "Ada", Lines: 1}}, s.Authors)
+	assert.Equal(t, "bbbbbbb recent", s.LastCommit)
+	assert.Equal(t, 10, s.StableDays)
+}
+
+func TestRealClient_Blame(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	initTestRepo(t, dir)
+	require.NoError(t, os.WriteFile(dir+"/f.txt", []byte("one\ntwo\nthree\nfour\n"), 0644))
+	require.NoError(t, exec.Command("git", "-C", dir, "add", ".").Run())
+	require.NoError(t, exec.Command("git", "-C", dir, "commit", "-m", "add f").Run())
+	require.NoError(t, os.WriteFile(dir+"/f.txt", []byte("one\nTWO\nthree\nfour\n"), 0644))
+	require.NoError(t, exec.Command("git", "-C", dir, "commit", "-am", "shout two").Run())
+
+	c := NewClient()
+	lines, err := c.Blame(ctx, dir, "HEAD", "f.txt", []LineRange{{Start: 1, End: 2}, {Start: 4, End: 4}})
+	require.NoError(t, err)
+	require.Len(t, lines, 3)
+	assert.Equal(t, "add f", lines[0].Summary)
+	assert.Equal(t, "shout two", lines[1].Summary)
+	assert.Equal(t, "Test", lines[1].Author)
+	assert.False(t, lines[1].Time.IsZero())
+	assert.Len(t, lines[0].Commit, 40)
+}
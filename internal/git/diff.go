@@ -0,0 +1,147 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// FileDiff is one file's section of a unified diff.
+type FileDiff struct {
+	File      string `json:"file"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Bytes     int    `json:"bytes"`
+	Patch     string `json:"-"`
+}
+
+// SplitDiff splits `git diff` output into per-file sections, counting added
+// and removed lines. Files are named by their post-change path.
+func SplitDiff(diff string) []FileDiff {
+	var files []FileDiff
+	var cur *FileDiff
+	var patch strings.Builder
+	inHunk := false
+
+	flush := func() {
+		if cur == nil {
+			return
+		}
+		cur.Patch = strings.TrimSuffix(patch.String(), "\n")
+		cur.Bytes = len(cur.Patch)
+		files = append(files, *cur)
+		patch.Reset()
+	}
+
+	for _, l := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(l, "diff --git ") {
+			flush()
+			cur = &FileDiff{File: diffGitPath(l)}
+			inHunk = false
+		}
+		if cur == nil {
+			continue
+		}
+		patch.WriteString(l)
+		patch.WriteByte('\n')
+
+		switch {
+		case strings.HasPrefix(l, "@@ "):
+			inHunk = true
+		case !inHunk:
+		case strings.HasPrefix(l, "+"):
+			cur.Additions++
+		case strings.HasPrefix(l, "-"):
+			cur.Deletions++
+		}
+	}
+	flush()
+	return files
+}
+
+// diffGitPath extracts the b/ path from a "diff --git a/x b/y" header.
+func diffGitPath(header string) string {
+	rest := strings.TrimPrefix(header, "diff --git ")
+	if i := strings.LastIndex(rest, " b/"); i >= 0 {
+		return rest[i+3:]
+	}
+	return rest
+}
+
+// TruncateDiff caps s at max bytes, cutting at a line boundary and appending a
+// marker that says how much was dropped. It reports whether s was cut. A
+// line longer than max is cut before the character max falls in, so the
+// result stays valid UTF-8.
+func TruncateDiff(s string, max int) (string, bool) {
+	if max <= 0 || len(s) <= max {
+		return s, false
+	}
+	cut := s[:max]
+	if i := strings.LastIndexByte(cut, '\n'); i > 0 {
+		cut = cut[:i]
+	} else {
+		for len(cut) > 0 && !utf8.RuneStart(s[len(cut)]) {
+			cut = cut[:len(cut)-1]
+		}
+	}
+	return fmt.Sprintf("%s\n... [truncated %d of %d bytes]", cut, len(s)-len(cut), len(s)), true
+}
+
+// sourceExts are the extensions of files UntestedFiles expects tests for.
+var sourceExts = map[string]bool{
+	".go": true, ".ts": true, ".tsx": true, ".js": true, ".jsx": true, ".py": true, ".rs": true,
+	".rb": true, ".java": true, ".kt": true, ".swift": true, ".c": true, ".cc": true, ".cpp": true,
+	".cs": true, ".php": true,
+}
+
+// IsTestFile reports whether a path looks like a test: foo_test.go,
+// foo.test.ts, foo.spec.js, test_foo.py, or anything under a test, tests or
+// __tests__ directory.
+func IsTestFile(file string) bool {
+	base := path.Base(file)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	if strings.HasSuffix(stem, "_test") || strings.HasSuffix(stem, ".test") || strings.HasSuffix(stem, ".spec") || strings.HasPrefix(stem, "test_") {
+		return true
+	}
+	for _, dir := range strings.Split(path.Dir(file), "/") {
+		if dir == "test" || dir == "tests" || dir == "__tests__" {
+			return true
+		}
+	}
+	return false
+}
+
+// UntestedFiles returns the changed source files no changed test seems to
+// cover: none is in the same directory or named after the file (foo.ts and
+// foo.test.ts, foo.py and test_foo.py). It is a heuristic for reviews, not
+// coverage.
+func UntestedFiles(files []string) []string {
+	testDirs := make(map[string]bool)
+	testStems := make(map[string]bool)
+	for _, f := range files {
+		if !IsTestFile(f) {
+			continue
+		}
+		testDirs[path.Dir(f)] = true
+		stem := path.Base(f)
+		stem = strings.TrimSuffix(stem, path.Ext(stem))
+		for _, affix := range []string{"_test", ".test", ".spec"} {
+			stem = strings.TrimSuffix(stem, affix)
+		}
+		testStems[strings.TrimPrefix(stem, "test_")] = true
+	}
+
+	var untested []string
+	for _, f := range files {
+		ext := path.Ext(f)
+		if !sourceExts[ext] || IsTestFile(f) {
+			continue
+		}
+		if testDirs[path.Dir(f)] || testStems[strings.TrimSuffix(path.Base(f), ext)] {
+			continue
+		}
+		untested = append(untested, f)
+	}
+	return untested
+}
@@ -0,0 +1,85 @@
+package git
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitDiff(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --git a/a.go b/a.go",
+		"index 111..222 100644",
+		"--- a/a.go",
+		"+++ b/a.go",
+		"@@ -1,3 +1,3 @@",
+		" package a",
+		"-var x = 1",
+		"+var x = 2",
+		"+var y = 3",
+		"diff --git a/old name.go b/new name.go",
+		"similarity index 90%",
+		"rename from old name.go",
+		"rename to new name.go",
+		"--- a/old name.go",
+		"+++ b/new name.go",
+		"@@ -5 +5 @@",
+		"--- x",
+		"+++ y",
+	}, "\n")
+
+	files := SplitDiff(diff)
+	require.Len(t, files, 2)
+	assert.Equal(t, "a.go", files[0].File)
+	assert.Equal(t, 2, files[0].Additions)
+	assert.Equal(t, 1, files[0].Deletions)
+	assert.True(t, strings.HasPrefix(files[0].Patch, "diff --git a/a.go b/a.go\n"))
+	assert.True(t, strings.HasSuffix(files[0].Patch, "+var y = 3"))
+	assert.Equal(t, len(files[0].Patch), files[0].Bytes)
+
+	assert.Equal(t, "new name.go", files[1].File)
+	assert.Equal(t, 1, files[1].Additions, "changed lines inside a hunk count even if they look like headers")
+	assert.Equal(t, 1, files[1].Deletions)
+
+	assert.Empty(t, SplitDiff(""))
+}
+
+func TestTruncateDiff(t *testing.T) {
+	s := "line one\nline two\nline three"
+
+	out, cut := TruncateDiff(s, 100)
+	assert.False(t, cut)
+	assert.Equal(t, s, out)
+
+	out, cut = TruncateDiff(s, 12)
+	assert.True(t, cut)
+	assert.Equal(t, "line one\n... [truncated 20 of 28 bytes]", out)
+
+	// A single long line is cut before a multi-byte character, not inside it
+	out, cut = TruncateDiff("+héllo", 3)
+	assert.True(t, cut)
+	assert.Equal(t, "+h\n... [truncated 5 of 7 bytes]", out)
+	assert.True(t, utf8.ValidString(out))
+}
+
+func TestUntestedFiles(t *testing.T) {
+	files := []string{
+		"internal/store/sqlite.go",
+		"internal/store/memory_test.go",
+		"internal/api/api.go",
+		"ui/src/lib/api.ts",
+		"ui/src/lib/format.ts",
+		"ui/src/__tests__/format.test.ts",
+		"scripts/tool.py",
+		"tests/test_tool.py",
+		"docs/api.md",
+		"go.mod",
+	}
+	assert.Equal(t, []string{"internal/api/api.go", "ui/src/lib/api.ts"}, UntestedFiles(files))
+	assert.True(t, IsTestFile("pkg/foo.spec.js"))
+	assert.False(t, IsTestFile("pkg/contest.go"))
+	assert.Empty(t, UntestedFiles(nil))
+}
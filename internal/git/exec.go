@@ -0,0 +1,172 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults for the shared executor used by RealClient.
+const (
+	DefaultMaxConcurrent = 8
+	DefaultTimeout       = 60 * time.Second
+)
+
+// Executor runs git subprocesses with bounded concurrency and a per-command
+// timeout, so a hung git (e.g. a network fetch) cannot stall its caller
+// indefinitely. It records latency and failure counts per git subcommand.
+type Executor struct {
+	sem     chan struct{}
+	timeout time.Duration
+
+	mu    sync.Mutex
+	stats map[string]*commandStats
+}
+
+type commandStats struct {
+	count    int
+	failures int
+	timeouts int
+	total    time.Duration
+	max      time.Duration
+}
+
+// CommandStats summarizes the runs of one git subcommand.
+type CommandStats struct {
+	Command  string  `json:"command"`
+	Count    int     `json:"count"`
+	Failures int     `json:"failures"`
+	Timeouts int     `json:"timeouts"`
+	AvgMS    float64 `json:"avg_ms"`
+	MaxMS    float64 `json:"max_ms"`
+}
+
+// NewExecutor returns an Executor running at most maxConcurrent commands at a
+// time, each limited to timeout. Non-positive values use the defaults.
+func NewExecutor(maxConcurrent int, timeout time.Duration) *Executor {
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrent
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Executor{
+		sem:     make(chan struct{}, maxConcurrent),
+		timeout: timeout,
+		stats:   make(map[string]*commandStats),
+	}
+}
+
+var (
+	defaultMu       sync.RWMutex
+	defaultExecutor = NewExecutor(DefaultMaxConcurrent, DefaultTimeout)
+)
+
+// DefaultExecutor returns the executor shared by all RealClients.
+func DefaultExecutor() *Executor {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultExecutor
+}
+
+// SetDefaultExecutor replaces the executor shared by all RealClients.
+func SetDefaultExecutor(e *Executor) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultExecutor = e
+}
+
+// Run executes `git -C path args...` once a slot is free and returns its
+// trimmed stdout. It gives up when ctx is done or the command exceeds the
+// executor's timeout.
+func (e *Executor) Run(ctx context.Context, path string, args ...string) (string, error) {
+	select {
+	case e.sem <- struct{}{}:
+	case <-ctx.Done():
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), ctx.Err())
+	}
+	defer func() { <-e.sem }()
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", path}, args...)...)
+	// Don't wait forever on pipes held open by git's own children (ssh, hooks).
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	out, err := cmd.Output()
+	timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
+	e.record(subcommand(args), time.Since(start), err != nil, timedOut)
+
+	if err != nil {
+		if timedOut {
+			return "", fmt.Errorf("git %s: timed out after %s", strings.Join(args, " "), e.timeout)
+		}
+		if exitErr, ok := err.(*exec.ExitError); ok && ctx.Err() == nil {
+			return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (e *Executor) record(command string, elapsed time.Duration, failed, timedOut bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	st, ok := e.stats[command]
+	if !ok {
+		st = &commandStats{}
+		e.stats[command] = st
+	}
+	st.count++
+	st.total += elapsed
+	st.max = max(st.max, elapsed)
+	if failed {
+		st.failures++
+	}
+	if timedOut {
+		st.timeouts++
+	}
+}
+
+// Stats returns per-subcommand latency and failure counts, sorted by command.
+func (e *Executor) Stats() []CommandStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]CommandStats, 0, len(e.stats))
+	for name, st := range e.stats {
+		out = append(out, CommandStats{
+			Command:  name,
+			Count:    st.count,
+			Failures: st.failures,
+			Timeouts: st.timeouts,
+			AvgMS:    float64(st.total.Microseconds()) / float64(st.count) / 1000,
+			MaxMS:    float64(st.max.Microseconds()) / 1000,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Command < out[j].Command })
+	return out
+}
+
+// subcommand returns the git subcommand in args, skipping global options
+// such as "-c key=value".
+func subcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "-c" || args[i] == "-C":
+			i++
+		case !strings.HasPrefix(args[i], "-"):
+			return args[i]
+		}
+	}
+	return "git"
+}
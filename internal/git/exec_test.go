@@ -0,0 +1,64 @@
+package git
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_RunAndStats(t *testing.T) {
+	dir := t.TempDir()
+	initTestRepo(t, dir)
+	e := NewExecutor(2, 10*time.Second)
+
+	out, err := e.Run(context.Background(), dir, "rev-parse", "--is-inside-work-tree")
+	require.NoError(t, err)
+	assert.Equal(t, "true", out)
+
+	_, err = e.Run(context.Background(), dir, "rev-parse", "--verify", "no-such-ref")
+	assert.ErrorContains(t, err, "git rev-parse --verify no-such-ref")
+
+	stats := e.Stats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, "rev-parse", stats[0].Command)
+	assert.Equal(t, 2, stats[0].Count)
+	assert.Equal(t, 1, stats[0].Failures)
+	assert.Equal(t, 0, stats[0].Timeouts)
+	assert.GreaterOrEqual(t, stats[0].MaxMS, stats[0].AvgMS)
+}
+
+func TestExecutor_Timeout(t *testing.T) {
+	dir := t.TempDir()
+	initTestRepo(t, dir)
+	e := NewExecutor(1, 200*time.Millisecond)
+
+	start := time.Now()
+	_, err := e.Run(context.Background(), dir, "-c", "alias.hang=!sleep 10", "hang")
+	assert.ErrorContains(t, err, "timed out after 200ms")
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	stats := e.Stats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, "hang", stats[0].Command)
+	assert.Equal(t, 1, stats[0].Timeouts)
+}
+
+func TestExecutor_WaitsForSlot(t *testing.T) {
+	e := NewExecutor(1, time.Second)
+	e.sem <- struct{}{} // occupy the only slot
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := e.Run(ctx, t.TempDir(), "status")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, e.Stats(), "commands that never ran are not recorded")
+}
+
+func TestSubcommand(t *testing.T) {
+	assert.Equal(t, "log", subcommand([]string{"log", "-1"}))
+	assert.Equal(t, "blame", subcommand([]string{"-c", "core.x=1", "--no-pager", "blame"}))
+	assert.Equal(t, "git", subcommand(nil))
+}
@@ -1,8 +1,12 @@
 package git
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"os/exec"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -16,23 +20,37 @@ type WorktreeInfo struct {
 }
 
 // Client defines the interface for git operations on arbitrary repos.
-// All methods take a path parameter since pm operates on multiple repos.
+// All methods take a path parameter since pm operates on multiple repos, and
+// cancelling ctx stops the git command.
 type Client interface {
-	RepoRoot(path string) (string, error)
-	CurrentBranch(path string) (string, error)
-	LastCommitDate(path string) (time.Time, error)
-	LastCommitMessage(path string) (string, error)
-	LastCommitHash(path string) (string, error)
-	BranchList(path string) ([]string, error)
-	IsDirty(path string) (bool, error)
-	WorktreeList(path string) ([]WorktreeInfo, error)
-	RemoteURL(path string) (string, error)
-	LatestTag(path string) (string, error)
-	CommitCountSince(path, base string) (int, error)
-	AheadBehind(path, base string) (ahead int, behind int, err error)
-	Diff(path, base, head string) (string, error)
-	DiffStat(path, base, head string) (string, error)
-	DiffNameOnly(path, base, head string) ([]string, error)
+	RepoRoot(ctx context.Context, path string) (string, error)
+	CurrentBranch(ctx context.Context, path string) (string, error)
+	LastCommitDate(ctx context.Context, path string) (time.Time, error)
+	LastCommitMessage(ctx context.Context, path string) (string, error)
+	LastCommitHash(ctx context.Context, path string) (string, error)
+	BranchList(ctx context.Context, path string) ([]string, error)
+	IsDirty(ctx context.Context, path string) (bool, error)
+	WorktreeList(ctx context.Context, path string) ([]WorktreeInfo, error)
+	RemoteURL(ctx context.Context, path string) (string, error)
+	LatestTag(ctx context.Context, path string) (string, error)
+	CommitCountSince(ctx context.Context, path, base string) (int, error)
+	CommitMessagesSince(ctx context.Context, path, base string) ([]string, error)
+	CommitLog(ctx context.Context, path, base, head string) ([]Commit, error)
+	AheadBehind(ctx context.Context, path, base string) (ahead int, behind int, err error)
+	Diff(ctx context.Context, path, base, head string) (string, error)
+	DiffStat(ctx context.Context, path, base, head string) (string, error)
+	DiffNameOnly(ctx context.Context, path, base, head string) ([]string, error)
+	Blame(ctx context.Context, path, rev, file string, ranges []LineRange) ([]BlameLine, error)
+	WorktreeFingerprint(ctx context.Context, path string) (string, error)
+	CommitAll(ctx context.Context, path, message string) (string, error)
+}
+
+// Commit is one commit from a log.
+type Commit struct {
+	Hash    string
+	Date    time.Time
+	Subject string
+	Message string // full message; set by CommitsSince only
 }
 
 // RealClient implements Client using real git commands.
@@ -43,44 +61,38 @@ func NewClient() *RealClient {
 	return &RealClient{}
 }
 
-func gitCmd(path string, args ...string) (string, error) {
-	fullArgs := append([]string{"-C", path}, args...)
-	out, err := exec.Command("git", fullArgs...).Output()
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(exitErr.Stderr)))
-		}
-		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
-	}
-	return strings.TrimSpace(string(out)), nil
+// gitCmd runs git in path through the shared executor; cancelling ctx kills
+// the command.
+func gitCmd(ctx context.Context, path string, args ...string) (string, error) {
+	return DefaultExecutor().Run(ctx, path, args...)
 }
 
-func (c *RealClient) RepoRoot(path string) (string, error) {
-	return gitCmd(path, "rev-parse", "--show-toplevel")
+func (c *RealClient) RepoRoot(ctx context.Context, path string) (string, error) {
+	return gitCmd(ctx, path, "rev-parse", "--show-toplevel")
 }
 
-func (c *RealClient) CurrentBranch(path string) (string, error) {
-	return gitCmd(path, "rev-parse", "--abbrev-ref", "HEAD")
+func (c *RealClient) CurrentBranch(ctx context.Context, path string) (string, error) {
+	return gitCmd(ctx, path, "rev-parse", "--abbrev-ref", "HEAD")
 }
 
-func (c *RealClient) LastCommitDate(path string) (time.Time, error) {
-	out, err := gitCmd(path, "log", "-1", "--format=%aI")
+func (c *RealClient) LastCommitDate(ctx context.Context, path string) (time.Time, error) {
+	out, err := gitCmd(ctx, path, "log", "-1", "--format=%aI")
 	if err != nil {
 		return time.Time{}, err
 	}
 	return time.Parse(time.RFC3339, out)
 }
 
-func (c *RealClient) LastCommitMessage(path string) (string, error) {
-	return gitCmd(path, "log", "-1", "--format=%s")
+func (c *RealClient) LastCommitMessage(ctx context.Context, path string) (string, error) {
+	return gitCmd(ctx, path, "log", "-1", "--format=%s")
 }
 
-func (c *RealClient) LastCommitHash(path string) (string, error) {
-	return gitCmd(path, "log", "-1", "--format=%h")
+func (c *RealClient) LastCommitHash(ctx context.Context, path string) (string, error) {
+	return gitCmd(ctx, path, "log", "-1", "--format=%h")
 }
 
-func (c *RealClient) BranchList(path string) ([]string, error) {
-	out, err := gitCmd(path, "branch", "--format=%(refname:short)")
+func (c *RealClient) BranchList(ctx context.Context, path string) ([]string, error) {
+	out, err := gitCmd(ctx, path, "branch", "--format=%(refname:short)")
 	if err != nil {
 		return nil, err
 	}
@@ -96,44 +108,120 @@ func (c *RealClient) BranchList(path string) ([]string, error) {
 	return branches, nil
 }
 
-func (c *RealClient) IsDirty(path string) (bool, error) {
-	out, err := gitCmd(path, "status", "--porcelain")
+func (c *RealClient) IsDirty(ctx context.Context, path string) (bool, error) {
+	out, err := gitCmd(ctx, path, "status", "--porcelain")
 	if err != nil {
 		return false, err
 	}
 	return out != "", nil
 }
 
-func (c *RealClient) WorktreeList(path string) ([]WorktreeInfo, error) {
-	out, err := gitCmd(path, "worktree", "list", "--porcelain")
+func (c *RealClient) WorktreeList(ctx context.Context, path string) ([]WorktreeInfo, error) {
+	out, err := gitCmd(ctx, path, "worktree", "list", "--porcelain")
 	if err != nil {
 		return nil, err
 	}
 	return ParseWorktreeListPorcelain(out), nil
 }
 
-func (c *RealClient) RemoteURL(path string) (string, error) {
-	out, err := gitCmd(path, "remote", "get-url", "origin")
+func (c *RealClient) RemoteURL(ctx context.Context, path string) (string, error) {
+	out, err := gitCmd(ctx, path, "remote", "get-url", "origin")
 	if err != nil {
 		return "", nil // no remote is not an error
 	}
 	return out, nil
 }
 
-func (c *RealClient) LatestTag(path string) (string, error) {
-	return gitCmd(path, "describe", "--tags", "--abbrev=0")
+func (c *RealClient) LatestTag(ctx context.Context, path string) (string, error) {
+	return gitCmd(ctx, path, "describe", "--tags", "--abbrev=0")
 }
 
-func (c *RealClient) CommitCountSince(path, base string) (int, error) {
-	out, err := gitCmd(path, "rev-list", "--count", base+"..HEAD")
+func (c *RealClient) CommitCountSince(ctx context.Context, path, base string) (int, error) {
+	out, err := gitCmd(ctx, path, "rev-list", "--count", base+"..HEAD")
 	if err != nil {
 		return 0, err
 	}
 	return strconv.Atoi(out)
 }
 
-func (c *RealClient) AheadBehind(path, base string) (ahead int, behind int, err error) {
-	out, err := gitCmd(path, "rev-list", "--left-right", "--count", base+"...HEAD")
+// CommitMessagesSince returns the full messages of commits in base..HEAD,
+// newest first.
+func (c *RealClient) CommitMessagesSince(ctx context.Context, path, base string) ([]string, error) {
+	out, err := gitCmd(ctx, path, "log", "--format=%B%x1e", base+"..HEAD")
+	if err != nil {
+		return nil, err
+	}
+	var msgs []string
+	for _, m := range strings.Split(out, "\x1e") {
+		if m = strings.TrimSpace(m); m != "" {
+			msgs = append(msgs, m)
+		}
+	}
+	return msgs, nil
+}
+
+// CommitsSince returns the commits in base..HEAD with their full messages,
+// newest first.
+func (c *RealClient) CommitsSince(ctx context.Context, path, base string) ([]Commit, error) {
+	out, err := gitCmd(ctx, path, "log", "--format=%H%x1f%cI%x1f%B%x1e", base+"..HEAD")
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, entry := range strings.Split(out, "\x1e") {
+		parts := strings.SplitN(strings.TrimSpace(entry), "\x1f", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, parts[1])
+		msg := strings.TrimSpace(parts[2])
+		subject, _, _ := strings.Cut(msg, "\n")
+		commits = append(commits, Commit{Hash: parts[0], Date: date, Subject: subject, Message: msg})
+	}
+	return commits, nil
+}
+
+// CommitLog returns the commits in base..head, oldest first.
+func (c *RealClient) CommitLog(ctx context.Context, path, base, head string) ([]Commit, error) {
+	out, err := gitCmd(ctx, path, "log", "--reverse", "--format=%H%x1f%cI%x1f%s", base+".."+head)
+	if err != nil {
+		return nil, err
+	}
+	return parseCommitLog(out), nil
+}
+
+// TagsContaining returns the tags whose history includes commit, oldest
+// first.
+func (c *RealClient) TagsContaining(ctx context.Context, path, commit string) ([]string, error) {
+	out, err := gitCmd(ctx, path, "tag", "--contains", commit, "--sort=creatordate")
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for _, t := range strings.Split(out, "\n") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags, nil
+}
+
+// parseCommitLog parses `git log --format=%H%x1f%cI%x1f%s` output.
+func parseCommitLog(out string) []Commit {
+	var commits []Commit
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "\x1f", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, parts[1])
+		commits = append(commits, Commit{Hash: parts[0], Date: date, Subject: parts[2]})
+	}
+	return commits
+}
+
+func (c *RealClient) AheadBehind(ctx context.Context, path, base string) (ahead int, behind int, err error) {
+	out, err := gitCmd(ctx, path, "rev-list", "--left-right", "--count", base+"...HEAD")
 	if err != nil {
 		return 0, 0, err
 	}
@@ -152,16 +240,16 @@ func (c *RealClient) AheadBehind(path, base string) (ahead int, behind int, err
 	return ahead, behind, nil
 }
 
-func (c *RealClient) Diff(path, base, head string) (string, error) {
-	return gitCmd(path, "diff", base+"..."+head)
+func (c *RealClient) Diff(ctx context.Context, path, base, head string) (string, error) {
+	return gitCmd(ctx, path, "diff", base+"..."+head)
 }
 
-func (c *RealClient) DiffStat(path, base, head string) (string, error) {
-	return gitCmd(path, "diff", "--stat", base+"..."+head)
+func (c *RealClient) DiffStat(ctx context.Context, path, base, head string) (string, error) {
+	return gitCmd(ctx, path, "diff", "--stat", base+"..."+head)
 }
 
-func (c *RealClient) DiffNameOnly(path, base, head string) ([]string, error) {
-	out, err := gitCmd(path, "diff", "--name-only", base+"..."+head)
+func (c *RealClient) DiffNameOnly(ctx context.Context, path, base, head string) ([]string, error) {
+	out, err := gitCmd(ctx, path, "diff", "--name-only", base+"..."+head)
 	if err != nil {
 		return nil, err
 	}
@@ -171,6 +259,47 @@ func (c *RealClient) DiffNameOnly(path, base, head string) ([]string, error) {
 	return strings.Split(out, "\n"), nil
 }
 
+// WorktreeFingerprint hashes HEAD, the uncommitted diff against HEAD, and the
+// contents of untracked files, so any edit to the working tree changes it.
+func (c *RealClient) WorktreeFingerprint(ctx context.Context, path string) (string, error) {
+	head, err := gitCmd(ctx, path, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	diff, err := gitCmd(ctx, path, "diff", "HEAD", "--binary")
+	if err != nil {
+		return "", err
+	}
+	untracked, err := gitCmd(ctx, path, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n", head, diff)
+	if untracked != "" {
+		for _, name := range strings.Split(untracked, "\n") {
+			fmt.Fprintf(h, "%s\n", name)
+			if data, err := os.ReadFile(filepath.Join(path, name)); err == nil {
+				h.Write(data)
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// CommitAll stages every change in the working tree and commits it,
+// returning the short hash of the new commit.
+func (c *RealClient) CommitAll(ctx context.Context, path, message string) (string, error) {
+	if _, err := gitCmd(ctx, path, "add", "-A"); err != nil {
+		return "", err
+	}
+	if _, err := gitCmd(ctx, path, "commit", "-m", message); err != nil {
+		return "", err
+	}
+	return c.LastCommitHash(ctx, path)
+}
+
 // ParseWorktreeListPorcelain parses the output of `git worktree list --porcelain`.
 func ParseWorktreeListPorcelain(output string) []WorktreeInfo {
 	var worktrees []WorktreeInfo
@@ -224,3 +353,21 @@ func ExtractOwnerRepo(remoteURL string) (owner, repo string, err error) {
 	}
 	return segments[0], segments[1], nil
 }
+
+// HooksDir returns the directory git runs hooks from for the repo at path.
+// It honors core.hooksPath and is shared by all of the repo's worktrees.
+func HooksDir(ctx context.Context, path string) (string, error) {
+	dir, err := gitCmd(ctx, path, "rev-parse", "--path-format=absolute", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(path, dir)
+	}
+	return dir, nil
+}
+
+// StagedDiff returns the diff of changes staged for commit in path.
+func StagedDiff(ctx context.Context, path string) (string, error) {
+	return gitCmd(ctx, path, "diff", "--cached")
+}
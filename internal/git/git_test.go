@@ -1,6 +1,7 @@
 package git
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"testing"
@@ -74,29 +75,42 @@ func TestExtractOwnerRepo_Invalid(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestRealClient_CanceledContext(t *testing.T) {
+	dir := t.TempDir()
+	initTestRepo(t, dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewClient().RepoRoot(ctx, dir)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
 func TestLatestTag_NoTags(t *testing.T) {
+	ctx := context.Background()
 	dir := t.TempDir()
 	initTestRepo(t, dir)
 	require.NoError(t, exec.Command("git", "-C", dir, "commit", "--allow-empty", "-m", "init").Run())
 
 	c := NewClient()
-	_, err := c.LatestTag(dir)
+	_, err := c.LatestTag(ctx, dir)
 	assert.Error(t, err)
 }
 
 func TestLatestTag_WithTag(t *testing.T) {
+	ctx := context.Background()
 	dir := t.TempDir()
 	initTestRepo(t, dir)
 	require.NoError(t, exec.Command("git", "-C", dir, "commit", "--allow-empty", "-m", "init").Run())
 	require.NoError(t, exec.Command("git", "-C", dir, "tag", "v1.0.0").Run())
 
 	c := NewClient()
-	tag, err := c.LatestTag(dir)
+	tag, err := c.LatestTag(ctx, dir)
 	assert.NoError(t, err)
 	assert.Equal(t, "v1.0.0", tag)
 }
 
 func TestRealClient_Diff(t *testing.T) {
+	ctx := context.Background()
 	dir := t.TempDir()
 
 	// Init repo with main branch
@@ -124,27 +138,28 @@ func TestRealClient_Diff(t *testing.T) {
 	c := NewClient()
 
 	t.Run("Diff returns diff content", func(t *testing.T) {
-		diff, err := c.Diff(dir, "main", "feature")
+		diff, err := c.Diff(ctx, dir, "main", "feature")
 		require.NoError(t, err)
 		assert.Contains(t, diff, "hello world")
 		assert.Contains(t, diff, "file2.txt")
 	})
 
 	t.Run("DiffStat returns stat summary", func(t *testing.T) {
-		stat, err := c.DiffStat(dir, "main", "feature")
+		stat, err := c.DiffStat(ctx, dir, "main", "feature")
 		require.NoError(t, err)
 		assert.Contains(t, stat, "file")
 		assert.Contains(t, stat, "changed")
 	})
 
 	t.Run("DiffNameOnly returns changed file names", func(t *testing.T) {
-		names, err := c.DiffNameOnly(dir, "main", "feature")
+		names, err := c.DiffNameOnly(ctx, dir, "main", "feature")
 		require.NoError(t, err)
 		assert.ElementsMatch(t, []string{"file1.txt", "file2.txt"}, names)
 	})
 }
 
 func TestLatestTag_MultipleTagsReturnsLatest(t *testing.T) {
+	ctx := context.Background()
 	dir := t.TempDir()
 	initTestRepo(t, dir)
 	require.NoError(t, exec.Command("git", "-C", dir, "commit", "--allow-empty", "-m", "first").Run())
@@ -153,7 +168,88 @@ func TestLatestTag_MultipleTagsReturnsLatest(t *testing.T) {
 	require.NoError(t, exec.Command("git", "-C", dir, "tag", "v2.0.0").Run())
 
 	c := NewClient()
-	tag, err := c.LatestTag(dir)
+	tag, err := c.LatestTag(ctx, dir)
 	assert.NoError(t, err)
 	assert.Equal(t, "v2.0.0", tag)
 }
+
+func TestRealClient_WorktreeFingerprint(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	initTestRepo(t, dir)
+	require.NoError(t, os.WriteFile(dir+"/file1.txt", []byte("hello\n"), 0644))
+	require.NoError(t, exec.Command("git", "-C", dir, "add", ".").Run())
+	require.NoError(t, exec.Command("git", "-C", dir, "commit", "-m", "initial").Run())
+
+	c := NewClient()
+	clean, err := c.WorktreeFingerprint(ctx, dir)
+	require.NoError(t, err)
+
+	again, err := c.WorktreeFingerprint(ctx, dir)
+	require.NoError(t, err)
+	assert.Equal(t, clean, again, "fingerprint is stable")
+
+	// Untracked file changes the fingerprint, and so does editing it again
+	require.NoError(t, os.WriteFile(dir+"/new.txt", []byte("a\n"), 0644))
+	untracked, err := c.WorktreeFingerprint(ctx, dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, clean, untracked)
+
+	require.NoError(t, os.WriteFile(dir+"/new.txt", []byte("b\n"), 0644))
+	edited, err := c.WorktreeFingerprint(ctx, dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, untracked, edited)
+
+	// CommitAll snapshots everything and leaves a clean tree
+	hash, err := c.CommitAll(ctx, dir, "snapshot")
+	require.NoError(t, err)
+	assert.NotEmpty(t, hash)
+	dirty, err := c.IsDirty(ctx, dir)
+	require.NoError(t, err)
+	assert.False(t, dirty)
+	msg, err := c.LastCommitMessage(ctx, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "snapshot", msg)
+}
+
+func TestCommitMessagesSince(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	initTestRepo(t, dir)
+	require.NoError(t, exec.Command("git", "-C", dir, "commit", "--allow-empty", "-m", "init").Run())
+	require.NoError(t, exec.Command("git", "-C", dir, "tag", "v1.0.0").Run())
+	require.NoError(t, exec.Command("git", "-C", dir, "commit", "--allow-empty", "-m", "fix: small").Run())
+	require.NoError(t, exec.Command("git", "-C", dir, "commit", "--allow-empty", "-m", "feat: new api", "-m", "BREAKING CHANGE: removed old api").Run())
+
+	c := NewClient()
+	msgs, err := c.CommitMessagesSince(ctx, dir, "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"feat: new api\n\nBREAKING CHANGE: removed old api", "fix: small"}, msgs)
+
+	msgs, err = c.CommitMessagesSince(ctx, dir, "HEAD")
+	require.NoError(t, err)
+	assert.Empty(t, msgs)
+}
+
+func TestCommitLog(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	initTestRepo(t, dir)
+	require.NoError(t, exec.Command("git", "-C", dir, "commit", "--allow-empty", "-m", "init").Run())
+	require.NoError(t, exec.Command("git", "-C", dir, "tag", "base").Run())
+	require.NoError(t, exec.Command("git", "-C", dir, "commit", "--allow-empty", "-m", "first", "-m", "body").Run())
+	require.NoError(t, exec.Command("git", "-C", dir, "commit", "--allow-empty", "-m", "second").Run())
+
+	c := NewClient()
+	commits, err := c.CommitLog(ctx, dir, "base", "HEAD")
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "first", commits[0].Subject, "oldest first")
+	assert.Equal(t, "second", commits[1].Subject)
+	assert.Len(t, commits[0].Hash, 40)
+	assert.False(t, commits[0].Date.IsZero())
+
+	commits, err = c.CommitLog(ctx, dir, "HEAD", "HEAD")
+	require.NoError(t, err)
+	assert.Empty(t, commits)
+}
@@ -29,6 +29,32 @@ type PullRequest struct {
 	State  string `json:"state"`
 	Branch string `json:"headRefName"`
 	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"` // set by MergedPRs
+	Author struct {
+		Login string `json:"login"`
+	} `json:"author"` // set by OpenPRs
+}
+
+// PRReview is a submitted pull request review with its inline comments.
+type PRReview struct {
+	ID          int64             `json:"id"`
+	Author      string            `json:"author"`
+	Bot         bool              `json:"bot"`
+	State       string            `json:"state"` // APPROVED, CHANGES_REQUESTED, COMMENTED or DISMISSED
+	Body        string            `json:"body"`
+	URL         string            `json:"url"`
+	SubmittedAt string            `json:"submittedAt"`
+	Comments    []PRReviewComment `json:"comments,omitempty"`
+}
+
+// PRReviewComment is a review's comment on a line of the pull request's diff.
+type PRReviewComment struct {
+	ID       int64  `json:"id"`
+	ReviewID int64  `json:"reviewId"`
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Body     string `json:"body"`
+	URL      string `json:"url"`
 }
 
 // RepoInfo represents basic GitHub repository information.
@@ -54,8 +80,11 @@ type GitHubClient interface {
 	PagesInfo(owner, repo string) (*PagesResult, error)
 }
 
-// RealGitHubClient implements GitHubClient using the gh CLI.
-type RealGitHubClient struct{}
+// RealGitHubClient implements GitHubClient using the gh CLI, or a REST API
+// over HTTP when created with NewGitHubAPIClient.
+type RealGitHubClient struct {
+	rest *restClient
+}
 
 // NewGitHubClient returns a new RealGitHubClient.
 func NewGitHubClient() *RealGitHubClient {
@@ -74,6 +103,9 @@ func ghCmd(args ...string) (string, error) {
 }
 
 func (c *RealGitHubClient) LatestRelease(owner, repo string) (*Release, error) {
+	if c.rest != nil {
+		return c.rest.LatestRelease(owner, repo)
+	}
 	out, err := ghCmd("api",
 		fmt.Sprintf("repos/%s/%s/releases/latest", owner, repo),
 		"--jq", `{tagName: .tag_name, publishedAt: .published_at, isLatest: true, assets: [.assets[] | {name: .name, downloadCount: .download_count, size: .size}]}`,
@@ -90,9 +122,58 @@ func (c *RealGitHubClient) LatestRelease(owner, repo string) (*Release, error) {
 }
 
 func (c *RealGitHubClient) OpenPRs(owner, repo string) ([]PullRequest, error) {
+	if c.rest != nil {
+		return c.rest.OpenPRs(owner, repo)
+	}
 	out, err := ghCmd("pr", "list",
 		"--repo", fmt.Sprintf("%s/%s", owner, repo),
 		"--state", "open",
+		"--json", "number,title,state,headRefName,url,author",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []PullRequest
+	if err := json.Unmarshal([]byte(out), &prs); err != nil {
+		return nil, fmt.Errorf("parse PRs: %w", err)
+	}
+	return prs, nil
+}
+
+// MergedPRs returns the last limit merged pull requests of a repo, newest
+// first, with their bodies.
+func (c *RealGitHubClient) MergedPRs(owner, repo string, limit int) ([]PullRequest, error) {
+	if c.rest != nil {
+		return c.rest.MergedPRs(owner, repo, limit)
+	}
+	out, err := ghCmd("pr", "list",
+		"--repo", fmt.Sprintf("%s/%s", owner, repo),
+		"--state", "merged",
+		"--limit", fmt.Sprintf("%d", limit),
+		"--json", "number,title,state,headRefName,url,body",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []PullRequest
+	if err := json.Unmarshal([]byte(out), &prs); err != nil {
+		return nil, fmt.Errorf("parse PRs: %w", err)
+	}
+	return prs, nil
+}
+
+// BranchPRs returns the pull requests opened from a branch in any state,
+// newest first.
+func (c *RealGitHubClient) BranchPRs(owner, repo, branch string) ([]PullRequest, error) {
+	if c.rest != nil {
+		return c.rest.BranchPRs(owner, repo, branch)
+	}
+	out, err := ghCmd("pr", "list",
+		"--repo", fmt.Sprintf("%s/%s", owner, repo),
+		"--head", branch,
+		"--state", "all",
 		"--json", "number,title,state,headRefName,url",
 	)
 	if err != nil {
@@ -106,6 +187,84 @@ func (c *RealGitHubClient) OpenPRs(owner, repo string) ([]PullRequest, error) {
 	return prs, nil
 }
 
+// PRReviews returns the submitted reviews of a pull request, oldest first,
+// each with its inline comments.
+func (c *RealGitHubClient) PRReviews(owner, repo string, number int) ([]PRReview, error) {
+	if c.rest != nil {
+		return c.rest.PRReviews(owner, repo, number)
+	}
+	out, err := ghCmd("api", "--paginate",
+		fmt.Sprintf("repos/%s/%s/pulls/%d/reviews", owner, repo, number),
+		"--jq", `.[] | {id, author: .user.login, bot: (.user.type == "Bot"), state, body, url: .html_url, submittedAt: .submitted_at}`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := decodeLines[PRReview](out)
+	if err != nil {
+		return nil, fmt.Errorf("parse reviews: %w", err)
+	}
+
+	out, err = ghCmd("api", "--paginate",
+		fmt.Sprintf("repos/%s/%s/pulls/%d/comments", owner, repo, number),
+		"--jq", `.[] | {id, reviewId: .pull_request_review_id, path, line: (.line // .original_line), body, url: .html_url}`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := decodeLines[PRReviewComment](out)
+	if err != nil {
+		return nil, fmt.Errorf("parse review comments: %w", err)
+	}
+	byReview := make(map[int64]int, len(reviews))
+	for i, r := range reviews {
+		byReview[r.ID] = i
+	}
+	for _, cm := range comments {
+		if i, ok := byReview[cm.ReviewID]; ok {
+			reviews[i].Comments = append(reviews[i].Comments, cm)
+		}
+	}
+	return reviews, nil
+}
+
+// decodeLines decodes the JSON values gh prints one per line for --jq.
+func decodeLines[T any](out string) ([]T, error) {
+	var values []T
+	dec := json.NewDecoder(strings.NewReader(out))
+	for dec.More() {
+		var v T
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// CloseIssue closes a GitHub issue with a comment.
+func (c *RealGitHubClient) CloseIssue(owner, repo string, number int, comment string) error {
+	if c.rest != nil {
+		return c.rest.CloseIssue(owner, repo, number, comment)
+	}
+	_, err := ghCmd("issue", "close", fmt.Sprintf("%d", number),
+		"--repo", fmt.Sprintf("%s/%s", owner, repo),
+		"--comment", comment,
+	)
+	return err
+}
+
+// ReopenIssue reopens a GitHub issue.
+func (c *RealGitHubClient) ReopenIssue(owner, repo string, number int) error {
+	if c.rest != nil {
+		return c.rest.ReopenIssue(owner, repo, number)
+	}
+	_, err := ghCmd("issue", "reopen", fmt.Sprintf("%d", number),
+		"--repo", fmt.Sprintf("%s/%s", owner, repo),
+	)
+	return err
+}
+
 type repoInfoRaw struct {
 	Name            string `json:"name"`
 	Description     string `json:"description"`
@@ -118,6 +277,9 @@ type repoInfoRaw struct {
 }
 
 func (c *RealGitHubClient) PagesInfo(owner, repo string) (*PagesResult, error) {
+	if c.rest != nil {
+		return c.rest.PagesInfo(owner, repo)
+	}
 	out, err := ghCmd("api", fmt.Sprintf("repos/%s/%s/pages", owner, repo))
 	if err != nil {
 		// 404 means no pages configured — not an error
@@ -135,6 +297,9 @@ func (c *RealGitHubClient) PagesInfo(owner, repo string) (*PagesResult, error) {
 }
 
 func (c *RealGitHubClient) RepoInfo(owner, repo string) (*RepoInfo, error) {
+	if c.rest != nil {
+		return c.rest.RepoInfo(owner, repo)
+	}
 	out, err := ghCmd("repo", "view",
 		fmt.Sprintf("%s/%s", owner, repo),
 		"--json", "name,description,stargazerCount,primaryLanguage,isPrivate,url",
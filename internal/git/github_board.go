@@ -0,0 +1,212 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Board is a GitHub Projects (v2) board with its single-select fields.
+type Board struct {
+	ID     string       `json:"id"`
+	Title  string       `json:"title"`
+	Fields []BoardField `json:"fields"`
+}
+
+// Field returns the board's single-select field called name, matched without
+// regard to case, or nil.
+func (b *Board) Field(name string) *BoardField {
+	for i := range b.Fields {
+		if strings.EqualFold(b.Fields[i].Name, name) {
+			return &b.Fields[i]
+		}
+	}
+	return nil
+}
+
+// BoardField is a single-select field of a board, such as its Status column.
+type BoardField struct {
+	ID      string        `json:"id"`
+	Name    string        `json:"name"`
+	Options []BoardOption `json:"options"`
+}
+
+// Option returns the field's option called name, matched without regard to
+// case, or nil.
+func (f *BoardField) Option(name string) *BoardOption {
+	for i := range f.Options {
+		if strings.EqualFold(f.Options[i].Name, name) {
+			return &f.Options[i]
+		}
+	}
+	return nil
+}
+
+// BoardOption is an option of a single-select field.
+type BoardOption struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// BoardItem is an issue on a board with its single-select values by field
+// name. Draft issues and pull requests have no Repo.
+type BoardItem struct {
+	ID     string
+	Repo   string // owner/name of the issue's repository
+	Number int
+	Values map[string]BoardValue
+}
+
+// BoardValue is the option an item has for a field and when it was set.
+type BoardValue struct {
+	Option    string
+	UpdatedAt time.Time
+}
+
+// ParseBoardRef parses a board reference, "owner/number".
+func ParseBoardRef(ref string) (owner string, number int, err error) {
+	owner, num, ok := strings.Cut(ref, "/")
+	if ok {
+		number, err = strconv.Atoi(num)
+	}
+	if !ok || owner == "" || err != nil || number <= 0 {
+		return "", 0, fmt.Errorf("invalid GitHub board %q: want owner/number, e.g. octo-org/3", ref)
+	}
+	return owner, number, nil
+}
+
+// graphQL runs a GraphQL query or mutation through gh api. Int variables are
+// typed for GitHub; the rest are sent as strings.
+func graphQL(query string, vars map[string]any, extra ...string) (string, error) {
+	args := []string{"api", "graphql", "-f", "query=" + query}
+	for k, v := range vars {
+		switch v := v.(type) {
+		case int:
+			args = append(args, "-F", fmt.Sprintf("%s=%d", k, v))
+		default:
+			args = append(args, "-f", fmt.Sprintf("%s=%v", k, v))
+		}
+	}
+	return ghCmd(append(args, extra...)...)
+}
+
+const boardQuery = `query($owner: String!, $number: Int!) {
+  repositoryOwner(login: $owner) {
+    ... on ProjectV2Owner {
+      projectV2(number: $number) {
+        id
+        title
+        fields(first: 50) {
+          nodes { ... on ProjectV2SingleSelectField { id name options { id name } } }
+        }
+      }
+    }
+  }
+}`
+
+// Board returns a user's or organization's board by number.
+func (c *RealGitHubClient) Board(owner string, number int) (*Board, error) {
+	if c.rest != nil {
+		return nil, ErrBoardsUnsupported
+	}
+	out, err := graphQL(boardQuery, map[string]any{"owner": owner, "number": number},
+		"--jq", `.data.repositoryOwner.projectV2 | {id, title, fields: [.fields.nodes[] | select(.id != null)]}`)
+	if err != nil {
+		return nil, err
+	}
+	var b Board
+	if err := json.Unmarshal([]byte(out), &b); err != nil || b.ID == "" {
+		return nil, fmt.Errorf("GitHub board %s/%d not found", owner, number)
+	}
+	return &b, nil
+}
+
+const boardItemsQuery = `query($owner: String!, $number: Int!, $endCursor: String) {
+  repositoryOwner(login: $owner) {
+    ... on ProjectV2Owner {
+      projectV2(number: $number) {
+        items(first: 100, after: $endCursor) {
+          pageInfo { hasNextPage endCursor }
+          nodes {
+            id
+            content { ... on Issue { number repository { nameWithOwner } } }
+            fieldValues(first: 20) {
+              nodes {
+                ... on ProjectV2ItemFieldSingleSelectValue {
+                  name
+                  updatedAt
+                  field { ... on ProjectV2SingleSelectField { name } }
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+type boardItemRaw struct {
+	ID     string `json:"id"`
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+	Values []struct {
+		Field     string    `json:"field"`
+		Name      string    `json:"name"`
+		UpdatedAt time.Time `json:"updatedAt"`
+	} `json:"values"`
+}
+
+// BoardItems returns every item on a board.
+func (c *RealGitHubClient) BoardItems(owner string, number int) ([]BoardItem, error) {
+	if c.rest != nil {
+		return nil, ErrBoardsUnsupported
+	}
+	out, err := graphQL(boardItemsQuery, map[string]any{"owner": owner, "number": number}, "--paginate",
+		"--jq", `.data.repositoryOwner.projectV2.items.nodes[] | {id, repo: .content.repository.nameWithOwner, number: .content.number, values: [.fieldValues.nodes[] | select(.field.name != null) | {field: .field.name, name, updatedAt}]}`)
+	if err != nil {
+		return nil, err
+	}
+	raws, err := decodeLines[boardItemRaw](out)
+	if err != nil {
+		return nil, fmt.Errorf("parse board items: %w", err)
+	}
+	items := make([]BoardItem, 0, len(raws))
+	for _, raw := range raws {
+		item := BoardItem{ID: raw.ID, Repo: raw.Repo, Number: raw.Number, Values: make(map[string]BoardValue, len(raw.Values))}
+		for _, v := range raw.Values {
+			item.Values[v.Field] = BoardValue{Option: v.Name, UpdatedAt: v.UpdatedAt}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// AddBoardItem adds a repository's issue to a board and returns its item ID.
+// Adding an issue that is already on the board returns its existing item.
+func (c *RealGitHubClient) AddBoardItem(boardID, owner, repo string, number int) (string, error) {
+	if c.rest != nil {
+		return "", ErrBoardsUnsupported
+	}
+	contentID, err := ghCmd("api", fmt.Sprintf("repos/%s/%s/issues/%d", owner, repo, number), "--jq", ".node_id")
+	if err != nil {
+		return "", err
+	}
+	return graphQL(`mutation($project: ID!, $content: ID!) {
+  addProjectV2ItemById(input: {projectId: $project, contentId: $content}) { item { id } }
+}`, map[string]any{"project": boardID, "content": contentID},
+		"--jq", ".data.addProjectV2ItemById.item.id")
+}
+
+// SetBoardItemOption sets an item's single-select field to an option.
+func (c *RealGitHubClient) SetBoardItemOption(boardID, itemID, fieldID, optionID string) error {
+	if c.rest != nil {
+		return ErrBoardsUnsupported
+	}
+	_, err := graphQL(`mutation($project: ID!, $item: ID!, $field: ID!, $option: String!) {
+  updateProjectV2ItemFieldValue(input: {projectId: $project, itemId: $item, fieldId: $field, value: {singleSelectOptionId: $option}}) { projectV2Item { id } }
+}`, map[string]any{"project": boardID, "item": itemID, "field": fieldID, "option": optionID})
+	return err
+}
@@ -0,0 +1,312 @@
+package git
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrBoardsUnsupported is returned by the board methods of a client created
+// with NewGitHubAPIClient, which has no GraphQL API to reach boards through.
+var ErrBoardsUnsupported = errors.New("GitHub boards are not available through github.api_url")
+
+// NewGitHubAPIClient returns a RealGitHubClient that calls a GitHub-compatible
+// REST API at baseURL over HTTP instead of running gh, such as a fakeforge
+// server. A non-empty token is sent as a bearer token.
+func NewGitHubAPIClient(baseURL, token string) *RealGitHubClient {
+	return &RealGitHubClient{rest: &restClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}}
+}
+
+// restClient is the HTTP transport of a RealGitHubClient with an API URL.
+type restClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// restError is a non-2xx response.
+type restError struct {
+	Method, Path string
+	Status       int
+	Message      string
+}
+
+func (e *restError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.Status, e.Message)
+}
+
+// do sends a request with body (nil for none) as JSON and decodes the
+// response into out (nil to discard it).
+func (c *restClient) do(method, path string, body, out any) error {
+	var in io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		in = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseURL+"/"+path, in)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var msg struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&msg)
+		if msg.Message == "" {
+			msg.Message = http.StatusText(resp.StatusCode)
+		}
+		return &restError{Method: method, Path: path, Status: resp.StatusCode, Message: msg.Message}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// list fetches every page of a list endpoint.
+func list[T any](c *restClient, path string, query url.Values) ([]T, error) {
+	const perPage = 100
+	var all []T
+	for page := 1; ; page++ {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Set("per_page", fmt.Sprint(perPage))
+		q.Set("page", fmt.Sprint(page))
+		var items []T
+		if err := c.do(http.MethodGet, path+"?"+q.Encode(), nil, &items); err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < perPage {
+			return all, nil
+		}
+	}
+}
+
+type restPull struct {
+	Number   int     `json:"number"`
+	Title    string  `json:"title"`
+	Body     string  `json:"body"`
+	State    string  `json:"state"`
+	MergedAt *string `json:"merged_at"`
+	URL      string  `json:"html_url"`
+	User     struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Head struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+}
+
+// pullRequest converts a REST pull request to what gh pr list reports: an
+// upper-case state that is MERGED for merged pull requests.
+func (p restPull) pullRequest() PullRequest {
+	pr := PullRequest{Number: p.Number, Title: p.Title, State: strings.ToUpper(p.State), Branch: p.Head.Ref, URL: p.URL}
+	if p.MergedAt != nil {
+		pr.State = "MERGED"
+	}
+	pr.Author.Login = p.User.Login
+	return pr
+}
+
+func (c *restClient) pulls(owner, repo string, query url.Values) ([]restPull, error) {
+	return list[restPull](c, fmt.Sprintf("repos/%s/%s/pulls", owner, repo), query)
+}
+
+func (c *restClient) LatestRelease(owner, repo string) (*Release, error) {
+	var raw struct {
+		TagName     string `json:"tag_name"`
+		PublishedAt string `json:"published_at"`
+		Assets      []struct {
+			Name          string `json:"name"`
+			DownloadCount int    `json:"download_count"`
+			Size          int64  `json:"size"`
+		} `json:"assets"`
+	}
+	if err := c.do(http.MethodGet, fmt.Sprintf("repos/%s/%s/releases/latest", owner, repo), nil, &raw); err != nil {
+		return nil, err
+	}
+	r := &Release{TagName: raw.TagName, PublishedAt: raw.PublishedAt, IsLatest: true}
+	for _, a := range raw.Assets {
+		r.Assets = append(r.Assets, ReleaseAsset{Name: a.Name, DownloadCount: a.DownloadCount, Size: a.Size})
+	}
+	return r, nil
+}
+
+func (c *restClient) OpenPRs(owner, repo string) ([]PullRequest, error) {
+	raws, err := c.pulls(owner, repo, url.Values{"state": {"open"}})
+	if err != nil {
+		return nil, err
+	}
+	prs := make([]PullRequest, len(raws))
+	for i, raw := range raws {
+		prs[i] = raw.pullRequest()
+	}
+	return prs, nil
+}
+
+func (c *restClient) MergedPRs(owner, repo string, limit int) ([]PullRequest, error) {
+	query := url.Values{"state": {"closed"}, "sort": {"updated"}, "direction": {"desc"}}
+	var prs []PullRequest
+	for page := 1; len(prs) < limit; page++ {
+		query.Set("per_page", "100")
+		query.Set("page", fmt.Sprint(page))
+		var raws []restPull
+		if err := c.do(http.MethodGet, fmt.Sprintf("repos/%s/%s/pulls?%s", owner, repo, query.Encode()), nil, &raws); err != nil {
+			return nil, err
+		}
+		for _, raw := range raws {
+			if raw.MergedAt != nil && len(prs) < limit {
+				pr := raw.pullRequest()
+				pr.Body = raw.Body
+				prs = append(prs, pr)
+			}
+		}
+		if len(raws) < 100 {
+			break
+		}
+	}
+	return prs, nil
+}
+
+func (c *restClient) BranchPRs(owner, repo, branch string) ([]PullRequest, error) {
+	raws, err := c.pulls(owner, repo, url.Values{"state": {"all"}, "head": {owner + ":" + branch}})
+	if err != nil {
+		return nil, err
+	}
+	prs := make([]PullRequest, len(raws))
+	for i, raw := range raws {
+		prs[i] = raw.pullRequest()
+	}
+	return prs, nil
+}
+
+func (c *restClient) PRReviews(owner, repo string, number int) ([]PRReview, error) {
+	type rawReview struct {
+		ID   int64 `json:"id"`
+		User struct {
+			Login string `json:"login"`
+			Type  string `json:"type"`
+		} `json:"user"`
+		State       string `json:"state"`
+		Body        string `json:"body"`
+		URL         string `json:"html_url"`
+		SubmittedAt string `json:"submitted_at"`
+	}
+	type rawComment struct {
+		ID           int64  `json:"id"`
+		ReviewID     int64  `json:"pull_request_review_id"`
+		Path         string `json:"path"`
+		Line         *int   `json:"line"`
+		OriginalLine int    `json:"original_line"`
+		Body         string `json:"body"`
+		URL          string `json:"html_url"`
+	}
+
+	raws, err := list[rawReview](c, fmt.Sprintf("repos/%s/%s/pulls/%d/reviews", owner, repo, number), nil)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := list[rawComment](c, fmt.Sprintf("repos/%s/%s/pulls/%d/comments", owner, repo, number), nil)
+	if err != nil {
+		return nil, err
+	}
+	reviews := make([]PRReview, len(raws))
+	byReview := make(map[int64]int, len(raws))
+	for i, r := range raws {
+		reviews[i] = PRReview{ID: r.ID, Author: r.User.Login, Bot: r.User.Type == "Bot", State: r.State, Body: r.Body, URL: r.URL, SubmittedAt: r.SubmittedAt}
+		byReview[r.ID] = i
+	}
+	for _, cm := range comments {
+		i, ok := byReview[cm.ReviewID]
+		if !ok {
+			continue
+		}
+		line := cm.OriginalLine
+		if cm.Line != nil {
+			line = *cm.Line
+		}
+		reviews[i].Comments = append(reviews[i].Comments, PRReviewComment{ID: cm.ID, ReviewID: cm.ReviewID, Path: cm.Path, Line: line, Body: cm.Body, URL: cm.URL})
+	}
+	return reviews, nil
+}
+
+func (c *restClient) CloseIssue(owner, repo string, number int, comment string) error {
+	path := fmt.Sprintf("repos/%s/%s/issues/%d", owner, repo, number)
+	if comment != "" {
+		if err := c.do(http.MethodPost, path+"/comments", map[string]string{"body": comment}, nil); err != nil {
+			return err
+		}
+	}
+	return c.do(http.MethodPatch, path, map[string]string{"state": "closed"}, nil)
+}
+
+func (c *restClient) ReopenIssue(owner, repo string, number int) error {
+	return c.do(http.MethodPatch, fmt.Sprintf("repos/%s/%s/issues/%d", owner, repo, number), map[string]string{"state": "open"}, nil)
+}
+
+func (c *restClient) PagesInfo(owner, repo string) (*PagesResult, error) {
+	var result PagesResult
+	err := c.do(http.MethodGet, fmt.Sprintf("repos/%s/%s/pages", owner, repo), nil, &result)
+	var re *restError
+	if errors.As(err, &re) && re.Status == http.StatusNotFound {
+		return nil, nil // no pages configured
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *restClient) RepoInfo(owner, repo string) (*RepoInfo, error) {
+	var raw struct {
+		Name        string  `json:"name"`
+		Description *string `json:"description"`
+		Stars       int     `json:"stargazers_count"`
+		Language    *string `json:"language"`
+		Private     bool    `json:"private"`
+		URL         string  `json:"html_url"`
+	}
+	if err := c.do(http.MethodGet, fmt.Sprintf("repos/%s/%s", owner, repo), nil, &raw); err != nil {
+		return nil, err
+	}
+	info := &RepoInfo{Name: raw.Name, Stars: raw.Stars, IsPrivate: raw.Private, URL: raw.URL}
+	if raw.Description != nil {
+		info.Description = *raw.Description
+	}
+	if raw.Language != nil {
+		info.Language = *raw.Language
+	}
+	return info, nil
+}
@@ -0,0 +1,132 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Git, reviews and issues refer to files by their path from the repository
+// root. Agents report them relative to the directory they run in (a session
+// worktree, or the project's directory inside it when the project is a
+// subdirectory of a monorepo), or as absolute paths into a worktree. RepoPaths
+// translates them so they can be compared.
+
+// RepoPaths translates file paths for one checkout of a project.
+type RepoPaths struct {
+	Root    string   // absolute root of the checkout: a session worktree, or the repository
+	Subpath string   // the project's directory from the repository root, "" at the root
+	Aliases []string // absolute roots of other checkouts, e.g. the repository for a worktree
+}
+
+// NewRepoPaths returns the paths of the project at projectPath in the
+// repository at repoRoot, checked out in worktree when it is not empty. A
+// projectPath outside repoRoot is taken as the repository root.
+func NewRepoPaths(repoRoot, projectPath, worktree string) RepoPaths {
+	p := RepoPaths{Root: filepath.Clean(repoRoot)}
+	if rel, ok := within(p.Root, filepath.Clean(projectPath)); ok {
+		p.Subpath = rel
+	}
+	if worktree != "" && filepath.Clean(worktree) != p.Root {
+		p.Aliases = []string{p.Root}
+		p.Root = filepath.Clean(worktree)
+	}
+	return p
+}
+
+// ResolveRepoPaths looks up the repository root of the project at projectPath
+// and returns its paths, checked out in worktree when it is not empty. When
+// git fails the project directory is taken as the repository root.
+func ResolveRepoPaths(ctx context.Context, gc Client, projectPath, worktree string) RepoPaths {
+	root := projectPath
+	if gc != nil {
+		if r, err := gc.RepoRoot(ctx, projectPath); err == nil && r != "" {
+			root = r
+		}
+	}
+	// git resolves symlinks in the root it reports (/tmp on macOS).
+	if resolved, err := filepath.EvalSymlinks(projectPath); err == nil && resolved != projectPath {
+		if _, ok := within(filepath.Clean(root), resolved); ok {
+			projectPath = resolved
+		}
+	}
+	return NewRepoPaths(root, projectPath, worktree)
+}
+
+// Normalize returns path from the repository root, slash-separated. path may
+// be absolute in any of the checkouts, or relative to the project directory
+// or to the repository root: in a monorepo, a relative path not under Subpath
+// is taken as relative to the project unless only the repository root has
+// it. Absolute paths outside every checkout are returned cleaned.
+func (p RepoPaths) Normalize(path string) string {
+	if path == "" {
+		return ""
+	}
+	if filepath.IsAbs(path) {
+		path = filepath.Clean(path)
+		for _, root := range append([]string{p.Root}, p.Aliases...) {
+			if rel, ok := within(root, path); ok {
+				return rel
+			}
+		}
+		return path
+	}
+
+	rel := filepath.ToSlash(filepath.Clean(path))
+	rel = strings.TrimPrefix(rel, "./")
+	if p.Subpath == "" || rel == p.Subpath || strings.HasPrefix(rel, p.Subpath+"/") {
+		return rel
+	}
+	if !exists(filepath.Join(p.Root, p.Subpath, rel)) && exists(filepath.Join(p.Root, rel)) {
+		return rel
+	}
+	return strings.TrimPrefix(p.Subpath+"/"+rel, "/")
+}
+
+// NormalizeAll normalizes each of paths.
+func (p RepoPaths) NormalizeAll(paths []string) []string {
+	if paths == nil {
+		return nil
+	}
+	out := make([]string, len(paths))
+	for i, path := range paths {
+		out[i] = p.Normalize(path)
+	}
+	return out
+}
+
+// ProjectRelative returns a path from the repository root relative to the
+// project directory, and false when it is outside the project.
+func (p RepoPaths) ProjectRelative(repoPath string) (string, bool) {
+	if p.Subpath == "" {
+		return repoPath, true
+	}
+	if !strings.HasPrefix(repoPath, p.Subpath+"/") {
+		return repoPath, false
+	}
+	return strings.TrimPrefix(repoPath, p.Subpath+"/"), true
+}
+
+// Abs returns the absolute path of a path from the repository root in Root.
+func (p RepoPaths) Abs(repoPath string) string {
+	return filepath.Join(p.Root, filepath.FromSlash(repoPath))
+}
+
+// within returns path relative to root, slash-separated, and whether it is
+// inside root at all ("" for root itself).
+func within(root, path string) (string, bool) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if rel == "." {
+		return "", true
+	}
+	return filepath.ToSlash(rel), true
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,42 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoPaths_Normalize(t *testing.T) {
+	repo := t.TempDir()
+	worktree := t.TempDir()
+
+	p := NewRepoPaths(repo, repo, worktree)
+	assert.Empty(t, p.Subpath)
+	assert.Equal(t, "internal/api/api.go", p.Normalize(filepath.Join(worktree, "internal/api/api.go")))
+	assert.Equal(t, "internal/api/api.go", p.Normalize(filepath.Join(repo, "internal/api/api.go")))
+	assert.Equal(t, "internal/api/api.go", p.Normalize("./internal/api/../api/api.go"))
+	assert.Equal(t, "/etc/hosts", p.Normalize("/etc/hosts"))
+	assert.Equal(t, []string{"a.go", "b/c.go"}, p.NormalizeAll([]string{filepath.Join(worktree, "a.go"), "b/c.go"}))
+
+	// A project in a monorepo subdirectory.
+	p = NewRepoPaths(repo, filepath.Join(repo, "services", "api"), worktree)
+	assert.Equal(t, "services/api", p.Subpath)
+	assert.Equal(t, "services/api/main.go", p.Normalize("main.go"), "relative to the project")
+	assert.Equal(t, "services/api/main.go", p.Normalize("services/api/main.go"), "already from the root")
+	assert.Equal(t, "services/api/main.go", p.Normalize(filepath.Join(worktree, "services/api/main.go")))
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, "go.work"), []byte("go 1.24\n"), 0o644))
+	assert.Equal(t, "go.work", p.Normalize("go.work"), "only the repository root has it")
+
+	rel, ok := p.ProjectRelative("services/api/ui/app.tsx")
+	assert.True(t, ok)
+	assert.Equal(t, "ui/app.tsx", rel)
+	_, ok = p.ProjectRelative("services/web/ui/app.tsx")
+	assert.False(t, ok)
+	assert.Equal(t, filepath.Join(worktree, "services", "api", "main.go"), p.Abs("services/api/main.go"))
+
+	// A project outside the repository root is taken as the root.
+	assert.Empty(t, NewRepoPaths(repo, t.TempDir(), "").Subpath)
+}
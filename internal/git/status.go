@@ -0,0 +1,162 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FileChange is one uncommitted file in a working tree. A file can be both
+// staged and modified when it was changed again after `git add`.
+type FileChange struct {
+	Path      string `json:"path"`
+	Staged    bool   `json:"staged"`    // has changes in the index
+	Modified  bool   `json:"modified"`  // has unstaged changes in the working tree
+	Untracked bool   `json:"untracked"` // not tracked by git
+	Deleted   bool   `json:"deleted,omitempty"`
+	Unmerged  bool   `json:"unmerged,omitempty"` // has an unresolved merge conflict
+	// Lines added and removed against HEAD, staged and unstaged together; an
+	// untracked file counts all of its lines as added
+	Additions int  `json:"additions"`
+	Deletions int  `json:"deletions"`
+	Binary    bool `json:"binary,omitempty"`
+}
+
+// DirtyFiles lists the uncommitted files in the working tree at path, sorted
+// by their path from the repository root, with their line counts. Untracked
+// files in new directories are listed one by one.
+func (c *RealClient) DirtyFiles(ctx context.Context, path string) ([]FileChange, error) {
+	out, err := gitCmd(ctx, path, "status", "--porcelain=v2", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	files := ParseStatusV2(out)
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	// Without a HEAD commit there is nothing to count against.
+	counts := map[string]numstat{}
+	if diff, err := gitCmd(ctx, path, "diff", "HEAD", "--numstat", "-z", "--no-renames"); err == nil {
+		counts = parseNumstatZ(diff)
+	}
+	// Status paths are from the repository root, which path may be below.
+	root := path
+	if r, err := c.RepoRoot(ctx, path); err == nil {
+		root = r
+	}
+	paths := NewRepoPaths(root, path, "")
+	for i := range files {
+		f := &files[i]
+		if f.Untracked {
+			f.Additions, f.Binary = countFileLines(paths.Abs(f.Path))
+			continue
+		}
+		if n, ok := counts[f.Path]; ok {
+			f.Additions, f.Deletions, f.Binary = n.added, n.deleted, n.binary
+		}
+	}
+	return files, nil
+}
+
+// ParseStatusV2 parses `git status --porcelain=v2 -z` output. Ignored files
+// are skipped, and renamed files are listed under their new path.
+func ParseStatusV2(out string) []FileChange {
+	var files []FileChange
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		e := entries[i]
+		if len(e) < 2 {
+			continue
+		}
+		switch e[0] {
+		case '?':
+			files = append(files, FileChange{Path: e[2:], Untracked: true})
+		case '1', '2', 'u':
+			// "1 XY sub mH mI mW hH hI path", "2 ... Xscore path" followed
+			// by the original path, "u XY sub m1 m2 m3 mW h1 h2 h3 path".
+			fields := map[byte]int{'1': 8, '2': 9, 'u': 10}[e[0]]
+			parts := strings.SplitN(e, " ", fields+1)
+			if len(parts) != fields+1 {
+				continue
+			}
+			xy := parts[1]
+			f := FileChange{Path: parts[fields], Unmerged: e[0] == 'u'}
+			if len(xy) == 2 {
+				f.Staged = xy[0] != '.'
+				f.Modified = xy[1] != '.'
+				f.Deleted = xy[0] == 'D' || xy[1] == 'D'
+			}
+			files = append(files, f)
+			if e[0] == '2' {
+				i++ // skip the original path
+			}
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
+
+type numstat struct {
+	added, deleted int
+	binary         bool
+}
+
+// parseNumstatZ parses `git diff --numstat -z --no-renames` output, keyed by
+// path. Binary files show "-" for both counts.
+func parseNumstatZ(out string) map[string]numstat {
+	counts := map[string]numstat{}
+	for _, e := range strings.Split(out, "\x00") {
+		parts := strings.SplitN(e, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "-" && parts[1] == "-" {
+			counts[parts[2]] = numstat{binary: true}
+			continue
+		}
+		added, _ := strconv.Atoi(parts[0])
+		deleted, _ := strconv.Atoi(parts[1])
+		counts[parts[2]] = numstat{added: added, deleted: deleted}
+	}
+	return counts
+}
+
+// countFileLines counts a file's lines, reporting files with a NUL byte in
+// their first 8KB as binary, like git does.
+func countFileLines(path string) (lines int, binary bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, false
+	}
+	defer func() { _ = f.Close() }()
+
+	r := bufio.NewReader(f)
+	if head, _ := r.Peek(8000); bytes.IndexByte(head, 0) >= 0 {
+		return 0, true
+	}
+	buf := make([]byte, 32*1024)
+	last := byte('\n')
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			lines += bytes.Count(buf[:n], []byte{'\n'})
+			last = buf[n-1]
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return lines, false
+		}
+	}
+	if last != '\n' {
+		lines++ // final line without a newline
+	}
+	return lines, false
+}
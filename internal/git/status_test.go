@@ -0,0 +1,67 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatusV2(t *testing.T) {
+	out := "1 .M N... 100644 100644 100644 abc abc main.go\x00" +
+		"1 MM N... 100644 100644 100644 abc def go.mod\x00" +
+		"1 D. N... 100644 000000 000000 abc 000 old file.txt\x00" +
+		"2 R. N... 100644 100644 100644 abc abc R100 new.go\x00orig.go\x00" +
+		"u UU N... 100644 100644 100644 100644 a b c conflict.go\x00" +
+		"? notes/todo.md\x00" +
+		"! build/out\x00"
+
+	assert.Equal(t, []FileChange{
+		{Path: "conflict.go", Staged: true, Modified: true, Unmerged: true},
+		{Path: "go.mod", Staged: true, Modified: true},
+		{Path: "main.go", Modified: true},
+		{Path: "new.go", Staged: true},
+		{Path: "notes/todo.md", Untracked: true},
+		{Path: "old file.txt", Staged: true, Deleted: true},
+	}, ParseStatusV2(out))
+	assert.Empty(t, ParseStatusV2(""))
+}
+
+func TestRealClient_DirtyFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	initTestRepo(t, dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.sum"), []byte("a v1\n"), 0o644))
+	require.NoError(t, exec.Command("git", "-C", dir, "add", ".").Run())
+	require.NoError(t, exec.Command("git", "-C", dir, "commit", "-m", "init").Run())
+
+	c := NewClient()
+	files, err := c.DirtyFiles(ctx, dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.sum"), []byte("b v2\nc v3\n"), 0o644))
+	require.NoError(t, exec.Command("git", "-C", dir, "add", "go.sum").Run())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "notes.md"), []byte("one\ntwo\nthree"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "logo.png"), []byte{0x89, 'P', 'N', 'G', 0, 1}, 0o644))
+
+	files, err = c.DirtyFiles(ctx, dir)
+	require.NoError(t, err)
+	assert.Equal(t, []FileChange{
+		{Path: "docs/notes.md", Untracked: true, Additions: 3},
+		{Path: "go.sum", Staged: true, Additions: 2, Deletions: 1},
+		{Path: "logo.png", Untracked: true, Binary: true},
+		{Path: "main.go", Modified: true, Deletions: 2},
+	}, files)
+	// Paths stay from the repository root when listed from below it.
+	sub, err := c.DirtyFiles(ctx, filepath.Join(dir, "docs"))
+	require.NoError(t, err)
+	assert.Equal(t, files, sub)
+}
@@ -53,6 +53,45 @@ func parseGoModField(goModPath, prefix string) (string, error) {
 	return "", fmt.Errorf("field %q not found in %s", strings.TrimSpace(prefix), goModPath)
 }
 
+// Requires returns the module paths required by the go.mod in path, direct
+// and indirect, in file order.
+func Requires(path string) ([]string, error) {
+	f, err := os.Open(filepath.Join(path, "go.mod"))
+	if err != nil {
+		return nil, fmt.Errorf("open go.mod: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var mods []string
+	inBlock := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case line == "require (":
+			inBlock = true
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require "))
+		case !inBlock:
+			continue
+		}
+		if fields := strings.Fields(line); len(fields) > 0 {
+			mods = append(mods, fields[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read go.mod: %w", err)
+	}
+	return mods, nil
+}
+
 // IsGoProject returns true if the path contains a go.mod file.
 func IsGoProject(path string) bool {
 	_, err := os.Stat(filepath.Join(path, "go.mod"))
@@ -31,6 +31,30 @@ func TestModulePath(t *testing.T) {
 	assert.Equal(t, "example.com/test", mod)
 }
 
+func TestRequires(t *testing.T) {
+	dir := t.TempDir()
+	goMod := `module example.com/test
+
+go 1.25.0
+
+require github.com/joescharf/wt v0.4.0
+
+require (
+	github.com/spf13/cobra v1.9.1
+	// a comment
+	golang.org/x/sys v0.30.0 // indirect
+)
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644))
+
+	mods, err := Requires(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"github.com/joescharf/wt", "github.com/spf13/cobra", "golang.org/x/sys"}, mods)
+
+	_, err = Requires(t.TempDir())
+	assert.Error(t, err)
+}
+
 func TestGoVersion_NoFile(t *testing.T) {
 	dir := t.TempDir()
 	a := NewAnalyzer()
@@ -0,0 +1,309 @@
+// Package groom splits issues into smaller ones and merges duplicates.
+package groom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// Store is the subset of store.Store needed to split and merge issues.
+type Store interface {
+	CreateIssue(ctx context.Context, issue *models.Issue) error
+	GetIssueTags(ctx context.Context, issueID string) ([]*models.Tag, error)
+	TagIssue(ctx context.Context, issueID, tagID string) error
+	CreateIssueRelation(ctx context.Context, rel *models.IssueRelation) error
+	ListIssueRelations(ctx context.Context, issueID string) ([]*models.IssueRelation, error)
+	ListIssueWatchers(ctx context.Context, issueID string) ([]*models.IssueWatcher, error)
+	MergeIssues(ctx context.Context, merge *store.IssueMerge) error
+}
+
+// Author is the author of the comments left by splits and merges.
+const Author = "pm"
+
+// Part kinds.
+const (
+	PartItem    = "item"    // a checklist item, "- [ ] ..."
+	PartSection = "section" // a Markdown heading and the text below it
+)
+
+// Part is a piece of an issue's body that can become an issue of its own.
+type Part struct {
+	Kind  string `json:"kind"`
+	Title string `json:"title"`
+	Body  string `json:"body,omitempty"` // a section's text; "" for items
+	Done  bool   `json:"done,omitempty"` // a checked item
+}
+
+var (
+	checklistRe = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.+?)\s*$`)
+	headingRe   = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+)
+
+// Parts lists the checklist items and sections of an issue body in the order
+// they appear. A section runs to the next heading of the same or a higher
+// level; headings in fenced code blocks are ignored.
+func Parts(body string) []Part {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	type heading struct {
+		line, level int
+	}
+	var (
+		parts    []Part
+		headings []heading
+		fenced   bool
+	)
+	headingPart := make(map[int]int) // line -> index in parts
+	for i, line := range lines {
+		if t := strings.TrimSpace(line); strings.HasPrefix(t, "```") || strings.HasPrefix(t, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			continue
+		}
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			headings = append(headings, heading{line: i, level: len(m[1])})
+			headingPart[i] = len(parts)
+			parts = append(parts, Part{Kind: PartSection, Title: m[2]})
+			continue
+		}
+		if m := checklistRe.FindStringSubmatch(line); m != nil {
+			parts = append(parts, Part{Kind: PartItem, Title: m[2], Done: m[1] != " "})
+		}
+	}
+	for n, h := range headings {
+		end := len(lines)
+		for _, next := range headings[n+1:] {
+			if next.level <= h.level {
+				end = next.line
+				break
+			}
+		}
+		parts[headingPart[h.line]].Body = strings.TrimSpace(strings.Join(lines[h.line+1:end], "\n"))
+	}
+	return parts
+}
+
+// Split creates an issue for each part, in parent's project with its type,
+// priority and tags, and relates each to parent as split_from. The parent is
+// left as it is.
+func Split(ctx context.Context, s Store, parent *models.Issue, parts []Part) ([]*models.Issue, error) {
+	tags, err := s.GetIssueTags(ctx, parent.ID)
+	if err != nil {
+		return nil, err
+	}
+	var children []*models.Issue
+	for _, p := range parts {
+		title := strings.TrimSpace(p.Title)
+		if title == "" {
+			return children, fmt.Errorf("split part has no title")
+		}
+		child := &models.Issue{
+			ProjectID: parent.ProjectID,
+			Title:     title,
+			Body:      p.Body,
+			Status:    models.IssueStatusOpen,
+			Priority:  parent.Priority,
+			Type:      parent.Type,
+		}
+		if err := s.CreateIssue(ctx, child); err != nil {
+			return children, fmt.Errorf("create issue %q: %w", title, err)
+		}
+		for _, t := range tags {
+			if err := s.TagIssue(ctx, child.ID, t.ID); err != nil {
+				return children, err
+			}
+			child.Tags = append(child.Tags, t.Name)
+		}
+		rel := &models.IssueRelation{SourceIssueID: child.ID, TargetIssueID: parent.ID, Type: models.RelationSplitFrom}
+		if err := s.CreateIssueRelation(ctx, rel); err != nil {
+			return children, err
+		}
+		children = append(children, child)
+	}
+	return children, nil
+}
+
+// ErrInvalidMerge is returned by Merge, before anything is written, for
+// duplicates that cannot be merged into the target.
+var ErrInvalidMerge = errors.New("invalid merge")
+
+// CheckMerge reports why duplicates cannot be merged into target: none are
+// given, one is target itself, one is listed twice or belongs to another
+// project. The error wraps ErrInvalidMerge.
+func CheckMerge(target *models.Issue, duplicates []*models.Issue) error {
+	if len(duplicates) == 0 {
+		return fmt.Errorf("%w: no duplicates given", ErrInvalidMerge)
+	}
+	seen := make(map[string]bool, len(duplicates))
+	for _, dup := range duplicates {
+		switch {
+		case dup.ID == target.ID:
+			return fmt.Errorf("%w: cannot merge issue %s into itself", ErrInvalidMerge, shortID(target.ID))
+		case seen[dup.ID]:
+			return fmt.Errorf("%w: issue %s is listed more than once", ErrInvalidMerge, shortID(dup.ID))
+		case dup.ProjectID != target.ProjectID:
+			return fmt.Errorf("%w: issue %s belongs to another project", ErrInvalidMerge, shortID(dup.ID))
+		}
+		seen[dup.ID] = true
+	}
+	return nil
+}
+
+// Merge folds duplicates into target. Each duplicate is closed, related to
+// target as a duplicate and keeps its own comments, reviews and sessions;
+// target gains its text, acceptance criteria, tags, watchers and relations
+// to other issues, its GitHub link if target has none, and its priority if
+// that is higher. Both sides get a comment recording the merge. The merge is
+// checked with CheckMerge and written in one store transaction.
+func Merge(ctx context.Context, s Store, target *models.Issue, duplicates []*models.Issue) error {
+	if err := CheckMerge(target, duplicates); err != nil {
+		return err
+	}
+
+	merge := &store.IssueMerge{Target: target}
+	targetRels, err := s.ListIssueRelations(ctx, target.ID)
+	if err != nil {
+		return err
+	}
+	have := make(map[string]bool, len(targetRels))
+	for _, r := range targetRels {
+		have[relationKey(r.SourceIssueID, r.Type, r.TargetIssueID)] = true
+	}
+	for _, dup := range duplicates {
+		if text := mergedText(dup); text != "" {
+			target.Body = strings.TrimSpace(target.Body + "\n\n" + text)
+		}
+		for _, c := range dup.AcceptanceCriteria {
+			if !slices.Contains(target.AcceptanceCriteria, c) {
+				target.AcceptanceCriteria = append(target.AcceptanceCriteria, c)
+			}
+		}
+		if target.GitHubIssue == 0 {
+			target.GitHubIssue = dup.GitHubIssue
+		}
+		if priorityRank[dup.Priority] > priorityRank[target.Priority] {
+			target.Priority = dup.Priority
+		}
+		if err := planMerge(ctx, s, merge, dup, have); err != nil {
+			return fmt.Errorf("merge issue %s: %w", dup.ID, err)
+		}
+	}
+	return s.MergeIssues(ctx, merge)
+}
+
+var priorityRank = map[models.IssuePriority]int{
+	models.IssuePriorityLow:    1,
+	models.IssuePriorityMedium: 2,
+	models.IssuePriorityHigh:   3,
+}
+
+// mergedText is the section a duplicate adds to the body of the issue it is
+// merged into, or "" when it has no text.
+func mergedText(dup *models.Issue) string {
+	var text []string
+	for _, t := range []string{dup.Description, dup.Body} {
+		if t = strings.TrimSpace(t); t != "" && !slices.Contains(text, t) {
+			text = append(text, t)
+		}
+	}
+	if len(text) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("## Merged from %s (%s)\n\n%s", dup.Title, shortID(dup.ID), strings.Join(text, "\n\n"))
+}
+
+// planMerge adds to merge moving a duplicate's tags, watchers and relations
+// over to its target, the comments recording it, and closing it. have holds
+// the relations target has or is to gain.
+func planMerge(ctx context.Context, s Store, merge *store.IssueMerge, dup *models.Issue, have map[string]bool) error {
+	target := merge.Target
+	tags, err := s.GetIssueTags(ctx, dup.ID)
+	if err != nil {
+		return err
+	}
+	for _, t := range tags {
+		merge.TagIDs = append(merge.TagIDs, t.ID)
+		if !slices.Contains(target.Tags, t.Name) {
+			target.Tags = append(target.Tags, t.Name)
+		}
+	}
+
+	watchers, err := s.ListIssueWatchers(ctx, dup.ID)
+	if err != nil {
+		return err
+	}
+	for _, w := range watchers {
+		merge.Watchers = append(merge.Watchers, &models.IssueWatcher{IssueID: target.ID, Kind: w.Kind, Target: w.Target})
+	}
+
+	if err := planRelations(ctx, s, merge, dup, have); err != nil {
+		return err
+	}
+
+	merge.Comments = append(merge.Comments,
+		&models.IssueComment{
+			IssueID: target.ID, Author: Author,
+			Body: fmt.Sprintf("Merged %s (%s) into this issue.", dup.Title, shortID(dup.ID)),
+		},
+		&models.IssueComment{
+			IssueID: dup.ID, Author: Author,
+			Body: fmt.Sprintf("Merged into %s (%s).", target.Title, shortID(target.ID)),
+		})
+
+	dup.Status = models.IssueStatusClosed
+	merge.Duplicates = append(merge.Duplicates, dup)
+	return nil
+}
+
+// planRelations gives target the duplicate's relations to issues other than
+// target and relates the duplicate to target, skipping relations in have.
+func planRelations(ctx context.Context, s Store, merge *store.IssueMerge, dup *models.Issue, have map[string]bool) error {
+	target := merge.Target
+	dupRels, err := s.ListIssueRelations(ctx, dup.ID)
+	if err != nil {
+		return err
+	}
+	add := func(source string, typ models.RelationType, other string) {
+		key := relationKey(source, typ, other)
+		if have[key] {
+			return
+		}
+		merge.Relations = append(merge.Relations, &models.IssueRelation{SourceIssueID: source, TargetIssueID: other, Type: typ})
+		have[key] = true
+	}
+	for _, r := range dupRels {
+		source, other := r.SourceIssueID, r.TargetIssueID
+		if source == dup.ID {
+			source = target.ID
+		} else {
+			other = target.ID
+		}
+		if source == other || r.SourceIssueID == target.ID || r.TargetIssueID == target.ID {
+			continue
+		}
+		add(source, r.Type, other)
+	}
+	add(dup.ID, models.RelationDuplicates, target.ID)
+	return nil
+}
+
+// relationKey identifies a relation by its ends and type.
+func relationKey(source string, typ models.RelationType, target string) string {
+	return source + " " + string(typ) + " " + target
+}
+
+// shortID is the 12-character prefix issues are shown by.
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -0,0 +1,138 @@
+package groom
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+func TestParts(t *testing.T) {
+	body := "Intro\n\n- [ ] Add endpoint\n- [x] Write spec\n* not a task\n\n## API\nPOST /split\n\n### Errors\n404s\n\n```md\n# not a heading\n```\n## CLI\n- [ ] pm issue split"
+	parts := Parts(body)
+	require.Len(t, parts, 6)
+	assert.Equal(t, Part{Kind: PartItem, Title: "Add endpoint"}, parts[0])
+	assert.Equal(t, Part{Kind: PartItem, Title: "Write spec", Done: true}, parts[1])
+	assert.Equal(t, Part{Kind: PartSection, Title: "API", Body: "POST /split\n\n### Errors\n404s\n\n```md\n# not a heading\n```"}, parts[2])
+	assert.Equal(t, Part{Kind: PartSection, Title: "Errors", Body: "404s\n\n```md\n# not a heading\n```"}, parts[3])
+	assert.Equal(t, Part{Kind: PartSection, Title: "CLI", Body: "- [ ] pm issue split"}, parts[4])
+	assert.Equal(t, Part{Kind: PartItem, Title: "pm issue split"}, parts[5])
+
+	assert.Empty(t, Parts("no structure here"))
+}
+
+func setup(t *testing.T) (*store.MemoryStore, *models.Project) {
+	t.Helper()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: t.TempDir()}
+	require.NoError(t, s.CreateProject(context.Background(), p))
+	return s, p
+}
+
+func TestSplit(t *testing.T) {
+	ctx := context.Background()
+	s, p := setup(t)
+	tag := &models.Tag{Name: "backend"}
+	require.NoError(t, s.CreateTag(ctx, tag))
+	parent := &models.Issue{ProjectID: p.ID, Title: "Grooming", Body: "- [ ] Split\n- [ ] Merge", Status: models.IssueStatusInProgress, Priority: models.IssuePriorityHigh, Type: models.IssueTypeFeature}
+	require.NoError(t, s.CreateIssue(ctx, parent))
+	require.NoError(t, s.TagIssue(ctx, parent.ID, tag.ID))
+
+	children, err := Split(ctx, s, parent, Parts(parent.Body))
+	require.NoError(t, err)
+	require.Len(t, children, 2)
+	for i, title := range []string{"Split", "Merge"} {
+		got, err := s.GetIssue(ctx, children[i].ID)
+		require.NoError(t, err)
+		assert.Equal(t, title, got.Title)
+		assert.Equal(t, models.IssueStatusOpen, got.Status)
+		assert.Equal(t, models.IssuePriorityHigh, got.Priority)
+		assert.Equal(t, []string{"backend"}, got.Tags)
+	}
+
+	related, err := store.RelatedIssues(ctx, s, parent.ID)
+	require.NoError(t, err)
+	require.Len(t, related, 2)
+	assert.Equal(t, "split into", related[0].Label)
+
+	got, err := s.GetIssue(ctx, parent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.IssueStatusInProgress, got.Status, "the parent is left as it is")
+
+	_, err = Split(ctx, s, parent, []Part{{Title: " "}})
+	assert.ErrorContains(t, err, "no title")
+}
+
+func TestMerge(t *testing.T) {
+	ctx := context.Background()
+	s, p := setup(t)
+	tag := &models.Tag{Name: "ui"}
+	require.NoError(t, s.CreateTag(ctx, tag))
+	target := &models.Issue{ProjectID: p.ID, Title: "Login fails", Body: "Steps", Status: models.IssueStatusOpen, Priority: models.IssuePriorityLow, AcceptanceCriteria: []string{"Login works"}}
+	require.NoError(t, s.CreateIssue(ctx, target))
+	dup := &models.Issue{ProjectID: p.ID, Title: "Cannot sign in", Body: "Seen on Safari", Status: models.IssueStatusInProgress, Priority: models.IssuePriorityHigh, GitHubIssue: 12, AcceptanceCriteria: []string{"Login works", "Safari tested"}}
+	require.NoError(t, s.CreateIssue(ctx, dup))
+	require.NoError(t, s.TagIssue(ctx, dup.ID, tag.ID))
+	_, err := s.AddIssueWatcher(ctx, &models.IssueWatcher{IssueID: dup.ID, Kind: models.WatcherEmail, Target: "qa@example.com"})
+	require.NoError(t, err)
+	blocker := &models.Issue{ProjectID: p.ID, Title: "Auth service", Status: models.IssueStatusOpen}
+	require.NoError(t, s.CreateIssue(ctx, blocker))
+	require.NoError(t, s.CreateIssueRelation(ctx, &models.IssueRelation{SourceIssueID: blocker.ID, TargetIssueID: dup.ID, Type: models.RelationBlocks}))
+	require.NoError(t, s.CreateIssueRelation(ctx, &models.IssueRelation{SourceIssueID: dup.ID, TargetIssueID: target.ID, Type: models.RelationDuplicates}))
+	require.NoError(t, s.CreateIssueComment(ctx, &models.IssueComment{IssueID: dup.ID, Author: "dana", Body: "Also on iOS"}))
+
+	require.NoError(t, Merge(ctx, s, target, []*models.Issue{dup}))
+
+	got, err := s.GetIssue(ctx, target.ID)
+	require.NoError(t, err)
+	assert.Contains(t, got.Body, "## Merged from Cannot sign in")
+	assert.Contains(t, got.Body, "Seen on Safari")
+	assert.Equal(t, []string{"Login works", "Safari tested"}, got.AcceptanceCriteria)
+	assert.Equal(t, 12, got.GitHubIssue)
+	assert.Equal(t, models.IssuePriorityHigh, got.Priority)
+	assert.Equal(t, []string{"ui"}, got.Tags)
+	watchers, err := s.ListIssueWatchers(ctx, target.ID)
+	require.NoError(t, err)
+	require.Len(t, watchers, 1)
+	assert.Equal(t, "qa@example.com", watchers[0].Target)
+
+	related, err := store.RelatedIssues(ctx, s, target.ID)
+	require.NoError(t, err)
+	labels := make(map[string]string)
+	for _, ri := range related {
+		labels[ri.Issue.ID] = ri.Label
+	}
+	assert.Equal(t, map[string]string{blocker.ID: "blocked by", dup.ID: "duplicated by"}, labels)
+
+	closed, err := s.GetIssue(ctx, dup.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.IssueStatusClosed, closed.Status)
+	comments, err := s.ListIssueComments(ctx, dup.ID)
+	require.NoError(t, err)
+	require.Len(t, comments, 2, "the duplicate keeps its history")
+	assert.Contains(t, comments[1].Body, "Merged into Login fails")
+	comments, err = s.ListIssueComments(ctx, target.ID)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, Author, comments[0].Author)
+
+	assert.ErrorContains(t, Merge(ctx, s, target, []*models.Issue{target}), "into itself")
+}
+
+func TestCheckMerge(t *testing.T) {
+	target := &models.Issue{ID: "T1", ProjectID: "p1"}
+	dup := &models.Issue{ID: "D1", ProjectID: "p1"}
+	assert.NoError(t, CheckMerge(target, []*models.Issue{dup}))
+	for name, dups := range map[string][]*models.Issue{
+		"none":            nil,
+		"itself":          {target},
+		"repeated":        {dup, dup},
+		"another project": {dup, {ID: "D2", ProjectID: "p2"}},
+	} {
+		assert.ErrorIs(t, CheckMerge(target, dups), ErrInvalidMerge, name)
+	}
+}
@@ -99,7 +99,7 @@ func scoreIssues(issues []*models.Issue, maxPoints int) int {
 
 	open := 0
 	for _, i := range issues {
-		if i.Status == models.IssueStatusOpen || i.Status == models.IssueStatusInProgress {
+		if i.Status == models.IssueStatusOpen || i.Status == models.IssueStatusInProgress || i.Status == models.IssueStatusInReview {
 			open++
 		}
 	}
@@ -0,0 +1,70 @@
+package health
+
+import (
+	"fmt"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// Snapshot captures a computed score and the metadata behind it for storage.
+func Snapshot(projectID string, meta *ProjectMetadata, h *HealthScore) *models.HealthSnapshot {
+	return &models.HealthSnapshot{
+		ProjectID:        projectID,
+		Total:            h.Total,
+		GitCleanliness:   h.GitCleanliness,
+		ActivityRecency:  h.ActivityRecency,
+		IssueHealth:      h.IssueHealth,
+		ReleaseFreshness: h.ReleaseFreshness,
+		BranchHygiene:    h.BranchHygiene,
+		IsDirty:          meta.IsDirty,
+		BranchCount:      meta.BranchCount,
+		WorktreeCount:    meta.WorktreeCount,
+		LatestRelease:    meta.LatestRelease,
+	}
+}
+
+// Diff describes what changed between two snapshots, e.g. "branches 2 → 5 (+3)"
+// or "git dirty → clean". It returns nil when prev is nil or nothing changed.
+func Diff(prev, cur *models.HealthSnapshot) []string {
+	if prev == nil || cur == nil {
+		return nil
+	}
+
+	var changes []string
+	score := func(label string, from, to int) {
+		if from != to {
+			changes = append(changes, fmt.Sprintf("%s %d → %d (%+d)", label, from, to, to-from))
+		}
+	}
+
+	score("health", prev.Total, cur.Total)
+	score("git cleanliness", prev.GitCleanliness, cur.GitCleanliness)
+	score("activity recency", prev.ActivityRecency, cur.ActivityRecency)
+	score("issue health", prev.IssueHealth, cur.IssueHealth)
+	score("release freshness", prev.ReleaseFreshness, cur.ReleaseFreshness)
+	score("branch hygiene", prev.BranchHygiene, cur.BranchHygiene)
+
+	if prev.IsDirty != cur.IsDirty {
+		changes = append(changes, fmt.Sprintf("git %s → %s", dirtyLabel(prev.IsDirty), dirtyLabel(cur.IsDirty)))
+	}
+	score("branches", prev.BranchCount, cur.BranchCount)
+	score("worktrees", prev.WorktreeCount, cur.WorktreeCount)
+	if prev.LatestRelease != cur.LatestRelease {
+		changes = append(changes, fmt.Sprintf("release %s → %s", releaseLabel(prev.LatestRelease), releaseLabel(cur.LatestRelease)))
+	}
+	return changes
+}
+
+func dirtyLabel(dirty bool) string {
+	if dirty {
+		return "dirty"
+	}
+	return "clean"
+}
+
+func releaseLabel(tag string) string {
+	if tag == "" {
+		return "none"
+	}
+	return tag
+}
@@ -0,0 +1,28 @@
+package health
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+func TestDiff(t *testing.T) {
+	prev := &models.HealthSnapshot{Total: 60, GitCleanliness: 5, BranchHygiene: 20, IsDirty: true, BranchCount: 2}
+	cur := &models.HealthSnapshot{Total: 66, GitCleanliness: 15, BranchHygiene: 16, BranchCount: 5, LatestRelease: "v1.0.0"}
+
+	assert.Equal(t, []string{
+		"health 60 → 66 (+6)",
+		"git cleanliness 5 → 15 (+10)",
+		"branch hygiene 20 → 16 (-4)",
+		"git dirty → clean",
+		"branches 2 → 5 (+3)",
+		"release none → v1.0.0",
+	}, Diff(prev, cur))
+}
+
+func TestDiff_NoPrevious(t *testing.T) {
+	assert.Nil(t, Diff(nil, &models.HealthSnapshot{Total: 80}))
+	assert.Nil(t, Diff(&models.HealthSnapshot{Total: 80}, &models.HealthSnapshot{Total: 80}))
+}
@@ -0,0 +1,153 @@
+// Package hooks installs git hooks that link a repo's commits to pm issues
+// and sessions without the agent having to cooperate.
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joescharf/pm/internal/terminal"
+)
+
+// Marker identifies hook scripts written by pm, so reinstalling updates them
+// and uninstalling never removes hooks pm did not write.
+const Marker = "# pm-managed hook"
+
+// Names are the hooks pm installs.
+var Names = []string{"commit-msg", "post-commit"}
+
+// backupSuffix is appended to a foreign hook replaced with --force.
+const backupSuffix = ".pre-pm"
+
+// Script returns the body of the named hook. serverURL is the pm serve base
+// URL the post-commit hook reports to. Each hook first runs the hook Install
+// moved aside, if any: a failing commit-msg hook still rejects the commit.
+func Script(name, serverURL string) (string, error) {
+	switch name {
+	case "commit-msg":
+		return fmt.Sprintf(`#!/bin/sh
+%s: adds the PM-Issue and PM-Session trailers of this worktree's
+# pm session to the commit message. Remove with: pm hooks uninstall
+if [ -x "$0%s" ]; then "$0%s" "$@" || exit $?; fi
+command -v pm >/dev/null 2>&1 || exit 0
+pm hooks commit-msg "$1" >/dev/null 2>&1
+exit 0
+`, Marker, backupSuffix, backupSuffix), nil
+	case "post-commit":
+		return fmt.Sprintf(`#!/bin/sh
+%s: reports the commit to pm serve so the worktree's session
+# stays current. Set PM_URL to override the server, PM_TOKEN for auth.
+# Remove with: pm hooks uninstall
+if [ -x "$0%s" ]; then "$0%s" "$@"; fi
+default_url=%s
+url="${PM_URL:-$default_url}"
+top=$(git rev-parse --show-toplevel 2>/dev/null) || exit 0
+command -v curl >/dev/null 2>&1 || exit 0
+body=$(printf '{"worktree_path":"%%s"}' "$(printf '%%s' "$top" | sed 's/\\/\\\\/g; s/"/\\"/g')")
+curl -fsS -m 5 -X POST -H "Content-Type: application/json" \
+  ${PM_TOKEN:+-H "Authorization: Bearer $PM_TOKEN"} \
+  -d "$body" "$url/api/v1/agent/heartbeat" >/dev/null 2>&1 &
+exit 0
+`, Marker, backupSuffix, backupSuffix, terminal.ShellQuote(strings.TrimRight(serverURL, "/"))), nil
+	default:
+		return "", fmt.Errorf("unknown hook: %s", name)
+	}
+}
+
+// Result reports what happened to one hook.
+type Result struct {
+	Name   string
+	Path   string
+	Action string // installed, updated, skipped, replaced, removed, restored
+	Backup string // where a replaced foreign hook was moved
+}
+
+// Install writes pm's hooks into dir. A hook pm did not write is left alone
+// (skipped) unless force is set, in which case it is moved aside to
+// <name>.pre-pm and restored by Uninstall.
+func Install(dir, serverURL string, force bool) ([]Result, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create hooks dir: %w", err)
+	}
+	var results []Result
+	for _, name := range Names {
+		script, err := Script(name, serverURL)
+		if err != nil {
+			return results, err
+		}
+		r := Result{Name: name, Path: filepath.Join(dir, name), Action: "installed"}
+
+		managed, exists, err := inspect(r.Path)
+		if err != nil {
+			return results, err
+		}
+		switch {
+		case exists && managed:
+			r.Action = "updated"
+		case exists && !force:
+			r.Action = "skipped"
+			results = append(results, r)
+			continue
+		case exists:
+			r.Backup = r.Path + backupSuffix
+			if err := os.Rename(r.Path, r.Backup); err != nil {
+				return results, fmt.Errorf("back up %s: %w", name, err)
+			}
+			r.Action = "replaced"
+		}
+
+		if err := os.WriteFile(r.Path, []byte(script), 0o755); err != nil {
+			return results, fmt.Errorf("write %s: %w", name, err)
+		}
+		// WriteFile keeps the mode of an existing file
+		if err := os.Chmod(r.Path, 0o755); err != nil {
+			return results, fmt.Errorf("chmod %s: %w", name, err)
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// Uninstall removes pm's hooks from dir, restoring any hook Install moved
+// aside. Hooks pm did not write are left alone.
+func Uninstall(dir string) ([]Result, error) {
+	var results []Result
+	for _, name := range Names {
+		path := filepath.Join(dir, name)
+		managed, exists, err := inspect(path)
+		if err != nil {
+			return results, err
+		}
+		if !exists || !managed {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return results, fmt.Errorf("remove %s: %w", name, err)
+		}
+		r := Result{Name: name, Path: path, Action: "removed"}
+		if _, err := os.Stat(path + backupSuffix); err == nil {
+			if err := os.Rename(path+backupSuffix, path); err != nil {
+				return results, fmt.Errorf("restore %s: %w", name, err)
+			}
+			r.Action = "restored"
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// inspect reports whether a hook file exists and whether pm wrote it.
+func inspect(path string) (managed, exists bool, err error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("read hook: %w", err)
+	}
+	return strings.Contains(string(data), Marker), true, nil
+}
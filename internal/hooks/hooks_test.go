@@ -0,0 +1,140 @@
+package hooks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallUninstall(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "hooks")
+
+	results, err := Install(dir, "http://localhost:9090/", false)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, r := range results {
+		assert.Equal(t, "installed", r.Action)
+		info, err := os.Stat(r.Path)
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode()&0o100, "%s is executable", r.Name)
+	}
+	post, err := os.ReadFile(filepath.Join(dir, "post-commit"))
+	require.NoError(t, err)
+	assert.Contains(t, string(post), "default_url='http://localhost:9090'\n")
+	assert.Contains(t, string(post), "/api/v1/agent/heartbeat")
+
+	results, err = Install(dir, "http://localhost:9090", false)
+	require.NoError(t, err)
+	assert.Equal(t, "updated", results[0].Action)
+
+	results, err = Uninstall(dir)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.NoFileExists(t, filepath.Join(dir, "commit-msg"))
+	assert.NoFileExists(t, filepath.Join(dir, "post-commit"))
+}
+
+func TestInstall_ForeignHook(t *testing.T) {
+	dir := t.TempDir()
+	foreign := filepath.Join(dir, "commit-msg")
+	require.NoError(t, os.WriteFile(foreign, []byte("#!/bin/sh\nlint-commit \"$1\"\n"), 0o755))
+
+	results, err := Install(dir, "http://localhost:8080", false)
+	require.NoError(t, err)
+	assert.Equal(t, "skipped", results[0].Action)
+	assert.Equal(t, "installed", results[1].Action)
+	data, _ := os.ReadFile(foreign)
+	assert.Contains(t, string(data), "lint-commit", "foreign hook is untouched without force")
+
+	results, err = Install(dir, "http://localhost:8080", true)
+	require.NoError(t, err)
+	assert.Equal(t, "replaced", results[0].Action)
+	assert.Equal(t, foreign+".pre-pm", results[0].Backup)
+	data, _ = os.ReadFile(foreign)
+	assert.Contains(t, string(data), Marker)
+
+	// pm's hook runs the foreign one first and fails with it. No PATH is
+	// searched, so pm itself is not run.
+	msg := filepath.Join(dir, "COMMIT_EDITMSG")
+	require.NoError(t, os.WriteFile(foreign+".pre-pm", []byte("#!/bin/sh\necho linted >> \"$1\"\nexit 3\n"), 0o755))
+	cmd := exec.Command(foreign, msg)
+	cmd.Env = []string{"PATH=" + filepath.Join(dir, "nobin")}
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, exitErr.ExitCode())
+	data, _ = os.ReadFile(msg)
+	assert.Equal(t, "linted\n", string(data))
+
+	results, err = Uninstall(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "restored", results[0].Action)
+	assert.Equal(t, "removed", results[1].Action)
+	data, _ = os.ReadFile(foreign)
+	assert.Contains(t, string(data), "echo linted")
+	assert.NoFileExists(t, foreign+".pre-pm")
+}
+
+func TestScript_QuotesServerURL(t *testing.T) {
+	dir := t.TempDir()
+	script, err := Script("post-commit", "http://localhost:9090/$(touch pwned)'x")
+	require.NoError(t, err)
+	// Stop right after the URL is set, echoing it.
+	script = script[:strings.Index(script, "top=")] + `printf '%s' "$url"` + "\n"
+	cmd := exec.Command("/bin/sh", "-c", script)
+	cmd.Dir = dir
+	cmd.Env = []string{"PATH=" + filepath.Join(dir, "nobin")}
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:9090/$(touch pwned)'x", string(out))
+	assert.NoFileExists(t, filepath.Join(dir, "pwned"))
+}
+
+func TestAddTrailers(t *testing.T) {
+	trailers := []Trailer{{"PM-Issue", "01J5ABCD1234"}, {"PM-Session", "01J5EFGH5678"}}
+
+	tests := []struct {
+		name string
+		msg  string
+		want string
+	}{
+		{
+			name: "subject only",
+			msg:  "Fix login\n",
+			want: "Fix login\n\nPM-Issue: 01J5ABCD1234\nPM-Session: 01J5EFGH5678\n",
+		},
+		{
+			name: "joins existing trailers",
+			msg:  "Fix login\n\nBody text.\n\nSigned-off-by: A <a@example.com>\n",
+			want: "Fix login\n\nBody text.\n\nSigned-off-by: A <a@example.com>\nPM-Issue: 01J5ABCD1234\nPM-Session: 01J5EFGH5678\n",
+		},
+		{
+			name: "before comments",
+			msg:  "Fix login\n\n# Please enter the commit message\n# Lines starting with '#' will be ignored\n",
+			want: "Fix login\n\nPM-Issue: 01J5ABCD1234\nPM-Session: 01J5EFGH5678\n\n# Please enter the commit message\n# Lines starting with '#' will be ignored\n",
+		},
+		{
+			name: "already present",
+			msg:  "Fix login\n\npm-issue: 01J5ABCD1234\n",
+			want: "Fix login\n\npm-issue: 01J5ABCD1234\nPM-Session: 01J5EFGH5678\n",
+		},
+		{
+			name: "empty message stays empty",
+			msg:  "\n# Please enter the commit message\n",
+			want: "\n# Please enter the commit message\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, AddTrailers(tt.msg, trailers))
+		})
+	}
+
+	all := "Fix login\n\nPM-Issue: x\nPM-Session: y\n"
+	assert.Equal(t, all, AddTrailers(all, trailers))
+}
@@ -0,0 +1,77 @@
+package hooks
+
+import (
+	"strings"
+)
+
+// Trailer is a "Key: value" line at the end of a commit message.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+func (t Trailer) String() string {
+	return t.Key + ": " + t.Value
+}
+
+// AddTrailers appends trailers to a commit message as passed to commit-msg.
+// Trailers join an existing trailer block, are placed before any comment
+// lines, and are skipped when the message already carries the same key. An
+// empty message is returned unchanged so git still aborts the commit.
+func AddTrailers(msg string, trailers []Trailer) string {
+	lines := strings.Split(strings.TrimRight(msg, "\n"), "\n")
+
+	// Split off the trailing comment block (and blank lines around it)
+	end := len(lines)
+	for end > 0 && (strings.HasPrefix(lines[end-1], "#") || strings.TrimSpace(lines[end-1]) == "") {
+		end--
+	}
+	body := append([]string(nil), lines[:end]...)
+	comments := lines[end:]
+
+	var add []string
+	for _, t := range trailers {
+		if !hasTrailer(body, t.Key) {
+			add = append(add, t.String())
+		}
+	}
+	if len(add) == 0 || len(body) == 0 {
+		return msg
+	}
+
+	// Start a new paragraph unless the message already ends in trailers
+	if !isTrailerBlock(body) {
+		body = append(body, "")
+	}
+	out := append(append(body, add...), comments...)
+	return strings.Join(out, "\n") + "\n"
+}
+
+func hasTrailer(lines []string, key string) bool {
+	prefix := strings.ToLower(key) + ":"
+	for _, l := range lines {
+		if strings.HasPrefix(strings.ToLower(l), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTrailerBlock reports whether the last paragraph of a message (not its
+// subject) consists of trailer lines.
+func isTrailerBlock(lines []string) bool {
+	start := len(lines)
+	for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+	if start == 0 {
+		return false // the subject paragraph is never a trailer block
+	}
+	for _, l := range lines[start:] {
+		key, _, ok := strings.Cut(l, ": ")
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return false
+		}
+	}
+	return true
+}
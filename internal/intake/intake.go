@@ -0,0 +1,176 @@
+// Package intake turns inbound emails and webhook payloads into issues.
+package intake
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// Config controls who may submit and which project a message lands in.
+type Config struct {
+	// Secret must accompany every request; intake is disabled when empty.
+	Secret string
+	// Routes maps a recipient address (e.g. bugs+api@example.com) to a project name.
+	Routes map[string]string
+	// DefaultProject receives messages no route or subject tag matches.
+	DefaultProject string
+}
+
+// Enabled reports whether a secret is configured.
+func (c Config) Enabled() bool {
+	return c.Secret != ""
+}
+
+// Authorized compares secret to the configured one in constant time.
+func (c Config) Authorized(secret string) bool {
+	return c.Enabled() && subtle.ConstantTimeCompare([]byte(secret), []byte(c.Secret)) == 1
+}
+
+// Route returns the project configured for the message's recipient, falling
+// back to DefaultProject.
+func (c Config) Route(m Message) string {
+	if name, ok := c.Routes[strings.ToLower(m.To)]; ok {
+		return name
+	}
+	return c.DefaultProject
+}
+
+// Message is an inbound report.
+type Message struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Parse reads a raw payload. RFC 5322 messages (with From or Subject headers)
+// are parsed as email, taking the first text/plain part of multipart bodies.
+// Anything else is plain text whose first line is the subject.
+func Parse(raw []byte) (Message, error) {
+	raw = bytes.ReplaceAll(raw, []byte("\r\n"), []byte("\n"))
+	if msg, err := mail.ReadMessage(bytes.NewReader(raw)); err == nil && (msg.Header.Get("From") != "" || msg.Header.Get("Subject") != "") {
+		return parseEmail(msg)
+	}
+
+	text := strings.TrimSpace(string(raw))
+	if text == "" {
+		return Message{}, fmt.Errorf("empty message")
+	}
+	subject, body, _ := strings.Cut(text, "\n")
+	return Message{Subject: strings.TrimSpace(subject), Body: strings.TrimSpace(body)}, nil
+}
+
+func parseEmail(msg *mail.Message) (Message, error) {
+	dec := new(mime.WordDecoder)
+	header := func(key string) string {
+		v := msg.Header.Get(key)
+		if d, err := dec.DecodeHeader(v); err == nil {
+			return d
+		}
+		return v
+	}
+
+	m := Message{From: header("From"), Subject: strings.TrimSpace(header("Subject"))}
+	if addrs, err := msg.Header.AddressList("To"); err == nil && len(addrs) > 0 {
+		m.To = addrs[0].Address
+	}
+
+	body, err := textBody(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return Message{}, err
+	}
+	m.Body = strings.TrimSpace(body)
+	return m, nil
+}
+
+// textBody returns the text/plain content of a body, descending into
+// multipart containers and undoing transfer encodings.
+func textBody(contentType, encoding string, r io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return "", fmt.Errorf("no text/plain part in %s message", mediaType)
+			}
+			if err != nil {
+				return "", fmt.Errorf("read %s part: %w", mediaType, err)
+			}
+			partType := part.Header.Get("Content-Type")
+			if partType == "" {
+				partType = "text/plain"
+			}
+			if !strings.HasPrefix(partType, "text/plain") && !strings.HasPrefix(partType, "multipart/") {
+				continue
+			}
+			if text, err := textBody(partType, part.Header.Get("Content-Transfer-Encoding"), part); err == nil {
+				return text, nil
+			}
+		}
+	}
+	if mediaType != "text/plain" {
+		return "", fmt.Errorf("unsupported content type %s", mediaType)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		r = quotedprintable.NewReader(r)
+	case "base64":
+		r = base64.NewDecoder(base64.StdEncoding, newlineStripper{r})
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("read body: %w", err)
+	}
+	return string(data), nil
+}
+
+// newlineStripper drops line breaks, which base64 bodies wrap at 76 columns.
+type newlineStripper struct{ r io.Reader }
+
+func (n newlineStripper) Read(p []byte) (int, error) {
+	nr, err := n.r.Read(p)
+	out := p[:0]
+	for _, b := range p[:nr] {
+		if b != '\n' && b != '\r' {
+			out = append(out, b)
+		}
+	}
+	return len(out), err
+}
+
+var (
+	replyPrefix = regexp.MustCompile(`(?i)^\s*(re|fwd?|aw|wg)\s*:\s*`)
+	subjectTag  = regexp.MustCompile(`^\s*\[([^\]]+)\]\s*`)
+)
+
+// CleanSubject strips reply/forward prefixes and a leading [tag] from a
+// subject, returning the remaining title and the tag, if any.
+func CleanSubject(subject string) (title, tag string) {
+	title = subject
+	for {
+		switch {
+		case replyPrefix.MatchString(title):
+			title = replyPrefix.ReplaceAllString(title, "")
+		case tag == "" && subjectTag.MatchString(title):
+			tag = strings.TrimSpace(subjectTag.FindStringSubmatch(title)[1])
+			title = subjectTag.ReplaceAllString(title, "")
+		default:
+			return strings.TrimSpace(title), tag
+		}
+	}
+}
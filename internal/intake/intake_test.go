@@ -0,0 +1,87 @@
+package intake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_PlainText(t *testing.T) {
+	m, err := Parse([]byte("Login button does nothing\n\nClicking it on Safari has no effect.\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "Login button does nothing", m.Subject)
+	assert.Equal(t, "Clicking it on Safari has no effect.", m.Body)
+	assert.Empty(t, m.From)
+
+	_, err = Parse([]byte("  \n"))
+	assert.Error(t, err)
+}
+
+func TestParse_Email(t *testing.T) {
+	raw := "From: Ada Lovelace <ada@example.com>\r\n" +
+		"To: Bugs <bugs+api@example.com>\r\n" +
+		"Subject: =?UTF-8?Q?Fwd:_Crash_on_=C3=BCpload?=\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"The server crashes when a file name contains =C3=BC.=\r\n" +
+		" Stack trace attached.\r\n"
+	m, err := Parse([]byte(raw))
+	require.NoError(t, err)
+	assert.Equal(t, "Ada Lovelace <ada@example.com>", m.From)
+	assert.Equal(t, "bugs+api@example.com", m.To)
+	assert.Equal(t, "Fwd: Crash on üpload", m.Subject)
+	assert.Equal(t, "The server crashes when a file name contains ü. Stack trace attached.", m.Body)
+}
+
+func TestParse_Multipart(t *testing.T) {
+	raw := "From: ops@example.com\n" +
+		"Subject: Disk full\n" +
+		"Content-Type: multipart/mixed; boundary=outer\n" +
+		"\n" +
+		"--outer\n" +
+		"Content-Type: multipart/alternative; boundary=inner\n" +
+		"\n" +
+		"--inner\n" +
+		"Content-Type: text/html\n" +
+		"\n" +
+		"<p>ignored</p>\n" +
+		"--inner\n" +
+		"Content-Type: text/plain\n" +
+		"Content-Transfer-Encoding: base64\n" +
+		"\n" +
+		"L3ZhciBpcyBhdCAxMDAl\n" +
+		"--inner--\n" +
+		"--outer--\n"
+	m, err := Parse([]byte(raw))
+	require.NoError(t, err)
+	assert.Equal(t, "Disk full", m.Subject)
+	assert.Equal(t, "/var is at 100%", m.Body)
+}
+
+func TestCleanSubject(t *testing.T) {
+	tests := []struct {
+		subject, title, tag string
+	}{
+		{"Crash on save", "Crash on save", ""},
+		{"Re: Fwd: Crash on save", "Crash on save", ""},
+		{"FW: [api] Crash on save", "Crash on save", "api"},
+		{"[api] Re: [v2] Crash", "[v2] Crash", "api"},
+	}
+	for _, tt := range tests {
+		title, tag := CleanSubject(tt.subject)
+		assert.Equal(t, tt.title, title, tt.subject)
+		assert.Equal(t, tt.tag, tag, tt.subject)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	c := Config{Secret: "s3cret", Routes: map[string]string{"bugs+api@example.com": "api"}, DefaultProject: "inbox"}
+	assert.True(t, c.Authorized("s3cret"))
+	assert.False(t, c.Authorized("nope"))
+	assert.False(t, Config{}.Authorized(""), "intake without a secret is disabled")
+
+	assert.Equal(t, "api", c.Route(Message{To: "Bugs+API@example.com"}))
+	assert.Equal(t, "inbox", c.Route(Message{To: "other@example.com"}))
+}
@@ -0,0 +1,202 @@
+// Package langstats measures which languages a project is written in, by
+// counting the non-blank lines of its source files per file extension, and
+// holds the per-language guidance used in enrichment and agent prompts.
+package langstats
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// Limits keep a refresh of a huge or vendored tree bounded.
+const (
+	maxFiles     = 20000
+	maxFileBytes = 1 << 20
+	// Languages under this share of the lines are left out of the breakdown.
+	minPercent = 1.0
+)
+
+// skipDirs are directories that hold dependencies or build output, not the
+// project's own code. Hidden directories are skipped too.
+var skipDirs = map[string]bool{"node_modules": true, "vendor": true, "dist": true, "build": true, "target": true, "__pycache__": true}
+
+// extensions maps source file extensions to language names. Names match
+// Project.Language ("go", "javascript", ...). Markup, data and docs are not
+// counted.
+var extensions = map[string]string{
+	".go":     "go",
+	".ts":     "typescript",
+	".tsx":    "typescript",
+	".mts":    "typescript",
+	".js":     "javascript",
+	".jsx":    "javascript",
+	".mjs":    "javascript",
+	".cjs":    "javascript",
+	".vue":    "vue",
+	".svelte": "svelte",
+	".py":     "python",
+	".rs":     "rust",
+	".rb":     "ruby",
+	".java":   "java",
+	".kt":     "kotlin",
+	".swift":  "swift",
+	".c":      "c",
+	".h":      "c",
+	".cc":     "c++",
+	".cpp":    "c++",
+	".hpp":    "c++",
+	".cs":     "c#",
+	".php":    "php",
+	".sh":     "shell",
+	".bash":   "shell",
+	".sql":    "sql",
+	".css":    "css",
+	".scss":   "css",
+	".html":   "html",
+	".lua":    "lua",
+	".ex":     "elixir",
+	".exs":    "elixir",
+	".dart":   "dart",
+	".scala":  "scala",
+	".zig":    "zig",
+}
+
+// LanguageOf returns the language of a source file, or "" for files that are
+// not counted.
+func LanguageOf(path string) string {
+	return extensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// Compute walks the project tree and returns each language's share of the
+// source lines, largest first. Languages below 1% are left out.
+func Compute(ctx context.Context, root string) ([]models.LanguageShare, error) {
+	lines := make(map[string]int)
+	scanned := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || skipDirs[d.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		lang := LanguageOf(path)
+		if lang == "" || !d.Type().IsRegular() {
+			return nil
+		}
+		if scanned++; scanned > maxFiles {
+			return fs.SkipAll
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		lines[lang] += countLines(path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return Breakdown(lines), nil
+}
+
+// Breakdown turns line counts per language into shares, largest first (ties
+// by name), dropping languages below 1%.
+func Breakdown(lines map[string]int) []models.LanguageShare {
+	total := 0
+	for _, n := range lines {
+		total += n
+	}
+	if total == 0 {
+		return nil
+	}
+	var shares []models.LanguageShare
+	for lang, n := range lines {
+		pct := math.Round(float64(n)*1000/float64(total)) / 10
+		if pct < minPercent {
+			continue
+		}
+		shares = append(shares, models.LanguageShare{Language: lang, Lines: n, Percent: pct})
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Lines != shares[j].Lines {
+			return shares[i].Lines > shares[j].Lines
+		}
+		return shares[i].Language < shares[j].Language
+	})
+	return shares
+}
+
+// countLines counts the non-blank lines of a text file. Binary and oversized
+// files count as none.
+func countLines(path string) int {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > maxFileBytes {
+		return 0
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || bytes.IndexByte(data, 0) >= 0 {
+		return 0
+	}
+	n := 0
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), maxFileBytes)
+	for sc.Scan() {
+		if len(bytes.TrimSpace(sc.Bytes())) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary formats a breakdown as "go 72.4%, typescript 25%" (at most three
+// languages).
+func Summary(shares []models.LanguageShare) string {
+	var parts []string
+	for i, s := range shares {
+		if i == 3 {
+			break
+		}
+		parts = append(parts, s.Language+" "+strconv.FormatFloat(s.Percent, 'f', -1, 64)+"%")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// guidance holds short, language-specific instructions for agents and the
+// enrichment model. They end up inside a quoted shell argument, so they must
+// not contain double quotes, backticks or dollar signs.
+var guidance = map[string]string{
+	"go":         "Go: keep code gofmt-clean, return wrapped errors instead of panicking, and verify with go build ./... and go test ./... before finishing.",
+	"typescript": "TypeScript: keep types strict (no any), follow the existing lint and formatter setup, and run the type checker and tests before finishing.",
+	"javascript": "JavaScript: follow the existing lint and formatter setup and run the test suite before finishing.",
+	"python":     "Python: follow PEP 8 and the existing type hints, and run the test suite (pytest) before finishing.",
+	"rust":       "Rust: keep cargo fmt and cargo clippy clean, prefer Result over panics, and run cargo test before finishing.",
+	"ruby":       "Ruby: follow the existing RuboCop style and run the test suite before finishing.",
+	"java":       "Java: follow the existing build tool conventions and run the unit tests before finishing.",
+	"kotlin":     "Kotlin: follow the existing Gradle setup and ktlint style, and run the unit tests before finishing.",
+	"swift":      "Swift: follow the existing SwiftPM or Xcode setup and run swift test before finishing.",
+	"c":          "C: check every allocation and return code, avoid undefined behaviour, and build with warnings enabled before finishing.",
+	"c++":        "C++: prefer RAII and standard containers over raw memory, and build with warnings enabled and run the tests before finishing.",
+	"c#":         "C#: follow the existing .NET conventions and run dotnet build and dotnet test before finishing.",
+	"php":        "PHP: follow PSR-12 and run the test suite before finishing.",
+	"shell":      "Shell: quote variables, use set -euo pipefail in new scripts, and keep them shellcheck-clean.",
+	"sql":        "SQL: add new schema changes as migrations rather than editing applied ones.",
+	"vue":        "Vue: follow the existing component patterns and run the frontend build and tests before finishing.",
+	"svelte":     "Svelte: follow the existing component patterns and run the frontend build and tests before finishing.",
+}
+
+// Guidance returns the instructions for a language, or "" when there are none.
+func Guidance(lang string) string {
+	return guidance[strings.ToLower(lang)]
+}
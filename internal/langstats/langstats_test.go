@@ -0,0 +1,70 @@
+package langstats
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+func write(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestCompute(t *testing.T) {
+	root := t.TempDir()
+	write(t, root, "main.go", "package main\n\nfunc main() {\n}\n")
+	write(t, root, "internal/x/x.go", "package x\n\n\nvar X = 1\n")
+	write(t, root, "ui/src/app.tsx", "export const A = 1\n")
+	write(t, root, "ui/node_modules/dep/index.js", "a\nb\nc\nd\ne\nf\n")
+	write(t, root, ".github/scripts/release.sh", "echo hi\n")
+	write(t, root, "README.md", "# readme\n")
+	write(t, root, "blob.go", "package x\x00\n")
+
+	shares, err := Compute(context.Background(), root)
+	require.NoError(t, err)
+	assert.Equal(t, []models.LanguageShare{
+		{Language: "go", Lines: 5, Percent: 83.3},
+		{Language: "typescript", Lines: 1, Percent: 16.7},
+	}, shares)
+}
+
+func TestBreakdown(t *testing.T) {
+	assert.Nil(t, Breakdown(nil))
+	assert.Nil(t, Breakdown(map[string]int{"go": 0}))
+
+	shares := Breakdown(map[string]int{"go": 995, "shell": 5, "css": 995})
+	assert.Equal(t, []models.LanguageShare{
+		{Language: "css", Lines: 995, Percent: 49.9},
+		{Language: "go", Lines: 995, Percent: 49.9},
+	}, shares, "ties sort by name and languages under 1% are dropped")
+}
+
+func TestSummary(t *testing.T) {
+	shares := []models.LanguageShare{
+		{Language: "go", Percent: 70.5},
+		{Language: "typescript", Percent: 20},
+		{Language: "css", Percent: 5},
+		{Language: "shell", Percent: 4.5},
+	}
+	assert.Equal(t, "go 70.5%, typescript 20%, css 5%", Summary(shares))
+	assert.Empty(t, Summary(nil))
+}
+
+func TestGuidanceIsShellSafe(t *testing.T) {
+	for lang, g := range guidance {
+		assert.NotContains(t, g, `"`, lang)
+		assert.NotContains(t, g, "`", lang)
+		assert.NotContains(t, g, "$", lang)
+	}
+	assert.Contains(t, Guidance("Go"), "go test")
+	assert.Empty(t, Guidance("cobol"))
+}
@@ -0,0 +1,122 @@
+// Package lexorank generates ranks: strings whose byte order is the order of
+// the things they rank, so an item can be moved between two others by
+// rewriting its rank alone. Ranks are base-36 fractions (digits 0-9a-z after
+// an implied point) without trailing zeros, so there is always a rank between
+// two others until MaxLen is reached; then the list needs re-balancing with
+// Spread.
+package lexorank
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+// MaxLen is the longest rank Between returns.
+const MaxLen = 16
+
+// ErrDense is returned by Between when no rank of at most MaxLen fits
+// between its arguments.
+var ErrDense = errors.New("ranks too dense")
+
+// Valid reports whether r is a rank: base-36 digits, not ending in 0.
+func Valid(r string) bool {
+	if r == "" || r[len(r)-1] == '0' || len(r) > MaxLen {
+		return false
+	}
+	for i := 0; i < len(r); i++ {
+		if strings.IndexByte(digits, r[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Between returns a rank after a and before b. An empty a means the start of
+// the list and an empty b its end.
+func Between(a, b string) (string, error) {
+	if (a != "" && !Valid(a)) || (b != "" && !Valid(b)) {
+		return "", fmt.Errorf("invalid rank %q or %q", a, b)
+	}
+	if b != "" && a >= b {
+		return "", fmt.Errorf("rank %q is not before %q", a, b)
+	}
+	r := midpoint(a, b)
+	if len(r) > MaxLen {
+		return "", ErrDense
+	}
+	return r, nil
+}
+
+// midpoint returns a string between a and b (b empty for no upper bound),
+// digit by digit.
+func midpoint(a, b string) string {
+	if b != "" {
+		// Keep the prefix the two share, reading a past its end as zeros.
+		n := 0
+		for n < len(b) && digitAt(a, n) == strings.IndexByte(digits, b[n]) {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + midpoint(rest, b[n:])
+		}
+	}
+	lo, hi := digitAt(a, 0), base
+	if b != "" {
+		hi = strings.IndexByte(digits, b[0])
+	}
+	if hi-lo > 1 {
+		return string(digits[(lo+hi+1)/2])
+	}
+	// Consecutive digits: b's first digit alone is a shorter rank that fits
+	// when b goes on; otherwise continue after a's first digit.
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(digits[lo]) + midpoint(rest, "")
+}
+
+// digitAt returns the value of r's i-th digit, 0 past its end.
+func digitAt(r string, i int) int {
+	if i >= len(r) {
+		return 0
+	}
+	return strings.IndexByte(digits, r[i])
+}
+
+// Spread returns n ranks in order, evenly spaced with room for about a
+// thousand insertions between any two before ranks grow a digit.
+func Spread(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	width, space := 1, uint64(base)
+	for space < uint64(n+1)*uint64(base*base) && width < 12 {
+		width++
+		space *= uint64(base)
+	}
+	step := space / uint64(n+1)
+	ranks := make([]string, n)
+	for i := range ranks {
+		v := step * uint64(i+1)
+		buf := make([]byte, width)
+		for j := width - 1; j >= 0; j-- {
+			buf[j] = digits[v%uint64(base)]
+			v /= uint64(base)
+		}
+		ranks[i] = strings.TrimRight(string(buf), "0")
+	}
+	return ranks
+}
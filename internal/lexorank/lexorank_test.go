@@ -0,0 +1,73 @@
+package lexorank
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"", ""},
+		{"", "1"},
+		{"", "a"},
+		{"a", ""},
+		{"z", ""},
+		{"a", "b"},
+		{"a", "a1"},
+		{"az", "b"},
+		{"0i", "1"},
+		{"azzz", "b0001"},
+	}
+	for _, tt := range tests {
+		r, err := Between(tt.a, tt.b)
+		require.NoError(t, err, "%q..%q", tt.a, tt.b)
+		assert.True(t, Valid(r), "%q is a rank", r)
+		assert.Less(t, tt.a, r)
+		if tt.b != "" {
+			assert.Less(t, r, tt.b)
+		}
+	}
+
+	_, err := Between("b", "a")
+	assert.ErrorContains(t, err, "not before")
+	_, err = Between("a0", "")
+	assert.ErrorContains(t, err, "invalid rank")
+}
+
+func TestBetween_Dense(t *testing.T) {
+	// Inserting at the same spot again and again eventually runs out of room.
+	a, b := "a", "b"
+	var err error
+	for i := 0; i < 200 && err == nil; i++ {
+		var r string
+		if r, err = Between(a, b); err == nil {
+			b = r
+		}
+	}
+	assert.ErrorIs(t, err, ErrDense)
+}
+
+func TestSpread(t *testing.T) {
+	assert.Nil(t, Spread(0))
+	for _, n := range []int{1, 2, 35, 36, 1000, 50000} {
+		ranks := Spread(n)
+		require.Len(t, ranks, n)
+		assert.True(t, sort.StringsAreSorted(ranks), "n=%d", n)
+		for i, r := range ranks {
+			assert.True(t, Valid(r), "%q", r)
+			if i > 0 {
+				assert.NotEqual(t, ranks[i-1], r)
+			}
+		}
+		// There is room at both ends and between neighbors.
+		_, err := Between("", ranks[0])
+		assert.NoError(t, err)
+		_, err = Between(ranks[n-1], "")
+		assert.NoError(t, err)
+	}
+}
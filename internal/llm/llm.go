@@ -5,9 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
+
+	"github.com/joescharf/pm/internal/langstats"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/redact"
 )
 
 // ExtractedIssue holds a single issue extracted from markdown content.
@@ -22,8 +27,9 @@ type ExtractedIssue struct {
 
 // Client wraps the Anthropic API for issue extraction.
 type Client struct {
-	api   *anthropic.Client
-	model anthropic.Model
+	api    *anthropic.Client
+	model  anthropic.Model
+	redact *redact.Policy
 }
 
 // NewClient creates an LLM client with the given API key and model.
@@ -34,11 +40,18 @@ func NewClient(apiKey, model string) *Client {
 	}
 	client := anthropic.NewClient(opts...)
 	return &Client{
-		api:   &client,
-		model: anthropic.Model(model),
+		api:    &client,
+		model:  anthropic.Model(model),
+		redact: redact.Default(),
 	}
 }
 
+// SetRedaction replaces the policy used to redact text before it is sent to
+// the API. New clients use redact.Default().
+func (c *Client) SetRedaction(p *redact.Policy) {
+	c.redact = p
+}
+
 // buildPrompt constructs the system and user prompts for issue extraction.
 func buildPrompt(content string, projects []string) (system string, user string) {
 	system = `You extract structured issues from markdown content. Return ONLY a JSON array of objects with these fields:
@@ -71,9 +84,13 @@ Rules:
 	return
 }
 
-// ExtractIssues sends markdown content to the LLM and returns structured issues.
-func (c *Client) ExtractIssues(ctx context.Context, content string, projects []string) ([]ExtractedIssue, error) {
-	systemPrompt, userPrompt := buildPrompt(content, projects)
+// ExtractIssues sends markdown content to the LLM and returns structured issues
+// and what was redacted from the content first. The content may span
+// projects, so only the global redaction settings apply. The tokens spent are
+// returned even when the response cannot be parsed.
+func (c *Client) ExtractIssues(ctx context.Context, content string, projects []string) ([]ExtractedIssue, []models.Redaction, Usage, error) {
+	r := c.redact.For("")
+	systemPrompt, userPrompt := buildPrompt(r.Redact(content), projects)
 
 	msg, err := c.api.Messages.New(ctx, anthropic.MessageNewParams{
 		Model:     c.model,
@@ -86,8 +103,9 @@ func (c *Client) ExtractIssues(ctx context.Context, content string, projects []s
 		},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("anthropic API call: %w", err)
+		return nil, nil, Usage{}, fmt.Errorf("anthropic API call: %w", err)
 	}
+	usage := Usage{Model: string(msg.Model), InputTokens: msg.Usage.InputTokens, OutputTokens: msg.Usage.OutputTokens}
 
 	// Extract text from response
 	var text string
@@ -99,7 +117,7 @@ func (c *Client) ExtractIssues(ctx context.Context, content string, projects []s
 	}
 
 	if text == "" {
-		return nil, fmt.Errorf("no text content in API response")
+		return nil, nil, usage, fmt.Errorf("no text content in API response")
 	}
 
 	// Strip markdown fencing if present
@@ -117,24 +135,34 @@ func (c *Client) ExtractIssues(ctx context.Context, content string, projects []s
 
 	var issues []ExtractedIssue
 	if err := json.Unmarshal([]byte(text), &issues); err != nil {
-		return nil, fmt.Errorf("parse LLM response as JSON: %w\nraw response: %s", err, text)
+		return nil, nil, usage, fmt.Errorf("parse LLM response as JSON: %w\nraw response: %s", err, text)
 	}
 
-	return issues, nil
+	return issues, r.Report(), usage, nil
 }
 
 // EnrichedIssue holds the LLM-generated enrichment fields for an issue.
 type EnrichedIssue struct {
-	Description string `json:"description"`
-	AIPrompt    string `json:"ai_prompt"`
+	Description        string   `json:"description"`
+	AIPrompt           string   `json:"ai_prompt"`
+	AcceptanceCriteria []string `json:"acceptance_criteria"`
+
+	// Redactions reports what was removed from the issue text before it was
+	// sent to the LLM.
+	Redactions []models.Redaction `json:"-"`
+	// Usage is the token cost of the call.
+	Usage Usage `json:"-"`
 }
 
 // buildEnrichPrompt constructs the system and user prompts for issue enrichment.
-func buildEnrichPrompt(title, body, description string) (system string, user string) {
-	system = `You enrich issue data for a project management system. Given an issue's title, body, and optional description, return a JSON object with exactly two fields:
+// languages are the project's main languages, primary first; the ai_prompt is
+// tailored to them when known.
+func buildEnrichPrompt(title, body, description string, languages []string) (system string, user string) {
+	system = `You enrich issue data for a project management system. Given an issue's title, body, and optional description, return a JSON object with exactly three fields:
 
 - "description": A concise 1-3 sentence summary of what this issue is about. If a description is already provided, improve it for clarity. If no description exists, generate one from the title and body.
 - "ai_prompt": Detailed guidance (3-10 sentences) for an AI developer agent that will implement this issue. Include: what needs to be built or fixed, key technical considerations, suggested approach, files or areas likely affected, and acceptance criteria. Be specific and actionable.
+- "acceptance_criteria": An array of 2-6 short, testable conditions the finished work must meet, each one sentence (e.g. "Logging out clears the session cookie"). A reviewer will check each one, so avoid vague criteria such as "code is clean".
 
 Rules:
 - Return valid JSON only, no markdown fencing or explanation
@@ -142,6 +170,15 @@ Rules:
 - The ai_prompt should be specific enough that an AI agent can start working on the issue immediately
 - If the body is empty, infer as much as possible from the title alone`
 
+	if len(languages) > 0 {
+		system += "\n\nThe project is written mainly in " + strings.Join(languages, " and ") + ". Tailor the ai_prompt to it: use that language's idioms, tooling and test commands."
+		for _, lang := range languages {
+			if g := langstats.Guidance(lang); g != "" {
+				system += "\n- " + g
+			}
+		}
+	}
+
 	var sb strings.Builder
 	sb.WriteString("Issue title: ")
 	sb.WriteString(title)
@@ -160,9 +197,14 @@ Rules:
 	return
 }
 
-// EnrichIssue sends issue data to the LLM and returns enriched description and AI prompt.
-func (c *Client) EnrichIssue(ctx context.Context, title, body, description string) (*EnrichedIssue, error) {
-	systemPrompt, userPrompt := buildEnrichPrompt(title, body, description)
+// EnrichIssue sends issue data to the LLM and returns an enriched description,
+// AI prompt and acceptance criteria. Once the API has answered, the returned
+// issue carries the tokens spent even alongside an error.
+// The text is redacted first using the settings for project (a project name),
+// and the prompt is tailored to the project's main languages when given.
+func (c *Client) EnrichIssue(ctx context.Context, project string, languages []string, title, body, description string) (*EnrichedIssue, error) {
+	r := c.redact.For(project)
+	systemPrompt, userPrompt := buildEnrichPrompt(r.Redact(title), r.Redact(body), r.Redact(description), languages)
 
 	msg, err := c.api.Messages.New(ctx, anthropic.MessageNewParams{
 		Model:     c.model,
@@ -177,6 +219,7 @@ func (c *Client) EnrichIssue(ctx context.Context, title, body, description strin
 	if err != nil {
 		return nil, fmt.Errorf("anthropic API call: %w", err)
 	}
+	usage := Usage{Model: string(msg.Model), InputTokens: msg.Usage.InputTokens, OutputTokens: msg.Usage.OutputTokens}
 
 	// Extract text from response
 	var text string
@@ -188,7 +231,7 @@ func (c *Client) EnrichIssue(ctx context.Context, title, body, description strin
 	}
 
 	if text == "" {
-		return nil, fmt.Errorf("no text content in API response")
+		return &EnrichedIssue{Usage: usage}, fmt.Errorf("no text content in API response")
 	}
 
 	// Strip markdown fencing if present
@@ -206,8 +249,294 @@ func (c *Client) EnrichIssue(ctx context.Context, title, body, description strin
 
 	var enriched EnrichedIssue
 	if err := json.Unmarshal([]byte(text), &enriched); err != nil {
-		return nil, fmt.Errorf("parse LLM response as JSON: %w\nraw response: %s", err, text)
+		return &EnrichedIssue{Usage: usage}, fmt.Errorf("parse LLM response as JSON: %w\nraw response: %s", err, text)
 	}
 
+	enriched.Redactions = r.Report()
+	enriched.Usage = usage
 	return &enriched, nil
 }
+
+// maxCommitDiff caps how much of a diff is sent for a commit message
+// suggestion; the rest is summarized by its file list.
+const maxCommitDiff = 40000
+
+// buildCommitPrompt constructs the system and user prompts for commit
+// message generation.
+func buildCommitPrompt(diff, convention, issue string) (system string, user string) {
+	system = `You write git commit messages for a staged diff. Return ONLY the commit message, no markdown fencing or explanation.
+
+Rules:
+- A subject line of at most 72 characters in the imperative mood ("Add", "Fix", not "Added"), no trailing period
+- If the change needs explanation, a blank line and then a short body wrapped at 72 characters saying what changed and why
+- Describe the change itself, not the process of making it
+- Follow the project's commit conventions exactly when given`
+
+	var sb strings.Builder
+	if convention != "" {
+		sb.WriteString("Project commit conventions: ")
+		sb.WriteString(convention)
+		sb.WriteString("\n\n")
+	}
+	if issue != "" {
+		sb.WriteString("The change implements this issue: ")
+		sb.WriteString(issue)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("Staged diff:\n\n")
+	if len(diff) > maxCommitDiff {
+		sb.WriteString(truncate(diff, maxCommitDiff))
+		sb.WriteString("\n[diff truncated]\n")
+	} else {
+		sb.WriteString(diff)
+	}
+	user = sb.String()
+	return
+}
+
+// SuggestCommitMessage asks the LLM for a commit message describing diff
+// that follows convention (a human-readable rule summary; may be empty).
+// issue optionally names the issue being worked on. The diff and issue are
+// redacted first using the settings for project (a project name). The
+// tokens spent are returned even when no message could be read.
+func (c *Client) SuggestCommitMessage(ctx context.Context, project, diff, convention, issue string) (string, Usage, error) {
+	r := c.redact.For(project)
+	systemPrompt, userPrompt := buildCommitPrompt(r.Redact(diff), convention, r.Redact(issue))
+
+	msg, err := c.api.Messages.New(ctx, anthropic.MessageNewParams{
+		Model:     c.model,
+		MaxTokens: 1024,
+		System: []anthropic.TextBlockParam{
+			{Text: systemPrompt},
+		},
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock(userPrompt)),
+		},
+	})
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("anthropic API call: %w", err)
+	}
+	usage := Usage{Model: string(msg.Model), InputTokens: msg.Usage.InputTokens, OutputTokens: msg.Usage.OutputTokens}
+
+	var text string
+	for _, block := range msg.Content {
+		if block.Type == "text" {
+			text = block.Text
+			break
+		}
+	}
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "```") {
+		lines := strings.SplitN(text, "\n", 2)
+		if len(lines) > 1 {
+			text = lines[1]
+		}
+		if idx := strings.LastIndex(text, "```"); idx >= 0 {
+			text = text[:idx]
+		}
+		text = strings.TrimSpace(text)
+	}
+	if text == "" {
+		return "", usage, fmt.Errorf("no text content in API response")
+	}
+	return text, usage, nil
+}
+
+// buildReplayPrompt constructs the system and user prompts for an agent
+// session post-mortem.
+func buildReplayPrompt(narrative string) (system string, user string) {
+	system = `You write short post-mortems of AI coding agent sessions from their timeline. Steps marked "!" are where something went wrong.
+
+Write plain text, no markdown headings, in at most three short paragraphs:
+- What the agent did, in order, in a few sentences
+- Where things went wrong and the most likely cause, citing step numbers
+- What to change next time, if anything; say so if the session went smoothly`
+	user = "Session timeline:\n\n" + narrative
+	return
+}
+
+// SummarizeSession asks the LLM for a post-mortem of an agent session from
+// its replay narrative, redacted first using the settings for project. The
+// tokens spent are returned even when no summary could be read.
+func (c *Client) SummarizeSession(ctx context.Context, project, narrative string) (string, Usage, error) {
+	systemPrompt, userPrompt := buildReplayPrompt(c.redact.For(project).Redact(narrative))
+
+	msg, err := c.api.Messages.New(ctx, anthropic.MessageNewParams{
+		Model:     c.model,
+		MaxTokens: 1024,
+		System: []anthropic.TextBlockParam{
+			{Text: systemPrompt},
+		},
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock(userPrompt)),
+		},
+	})
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("anthropic API call: %w", err)
+	}
+	usage := Usage{Model: string(msg.Model), InputTokens: msg.Usage.InputTokens, OutputTokens: msg.Usage.OutputTokens}
+
+	for _, block := range msg.Content {
+		if block.Type == "text" {
+			if text := strings.TrimSpace(block.Text); text != "" {
+				return text, usage, nil
+			}
+		}
+	}
+	return "", usage, fmt.Errorf("no text content in API response")
+}
+
+// Usage is the token cost of an API call.
+type Usage struct {
+	Model        string `json:"model"`
+	InputTokens  int64  `json:"input_tokens"`
+	OutputTokens int64  `json:"output_tokens"`
+}
+
+// Record returns the usage as a record of the tokens spent for purpose on a
+// project and, optionally, one of its issues.
+func (u Usage) Record(projectID, issueID string, purpose models.LLMUsagePurpose) *models.LLMUsage {
+	return &models.LLMUsage{
+		ProjectID:    projectID,
+		IssueID:      issueID,
+		Purpose:      purpose,
+		Model:        u.Model,
+		InputTokens:  u.InputTokens,
+		OutputTokens: u.OutputTokens,
+	}
+}
+
+// ReviewFinding is a part of a change the LLM thinks a reviewer should look
+// at, and why.
+type ReviewFinding struct {
+	Area   string   `json:"area"`
+	Reason string   `json:"reason"`
+	Files  []string `json:"files,omitempty"`
+}
+
+// ReviewAnalysis is an LLM's first pass over a change under review: hints
+// for the reviewing agent, not a verdict.
+type ReviewAnalysis struct {
+	Summary       string             `json:"summary"`
+	RiskAreas     []ReviewFinding    `json:"risk_areas"`
+	ScrutinyFiles []ReviewFinding    `json:"scrutiny_files"` // one file each
+	MissingTests  []ReviewFinding    `json:"missing_tests"`
+	Usage         Usage              `json:"usage"`
+	Redactions    []models.Redaction `json:"redactions,omitempty"`
+}
+
+// maxReviewDiff caps how much of a diff is sent for a review pre-analysis.
+const maxReviewDiff = 60000
+
+// buildReviewPrompt constructs the system and user prompts for a review
+// pre-analysis. untested lists changed source files whose tests did not
+// change, a heuristic the LLM is asked to confirm or dismiss.
+func buildReviewPrompt(issue string, criteria []string, diff string, untested []string) (system string, user string) {
+	system = `You prepare a code review of a change made by an AI coding agent. You do not give a verdict; you point the reviewer at what deserves attention. Return ONLY a JSON object with these fields:
+- "summary": one or two sentences on what the change does
+- "risk_areas": array of {"area", "reason", "files"} for behavior most likely to be wrong or to break something else (concurrency, error handling, data migrations, security, edge cases), most important first
+- "scrutiny_files": array of {"area": the file path, "reason"} for the files a reviewer should read most carefully, most important first
+- "missing_tests": array of {"area", "reason", "files"} for changed behavior no test in the diff covers
+
+Keep each reason to one sentence. Use empty arrays when there is nothing to say. Do not pad the lists.`
+
+	var sb strings.Builder
+	sb.WriteString("The change implements this issue:\n")
+	sb.WriteString(issue)
+	sb.WriteString("\n\n")
+	if len(criteria) > 0 {
+		sb.WriteString("Acceptance criteria:\n")
+		for i, c := range criteria {
+			fmt.Fprintf(&sb, "%d. %s\n", i+1, c)
+		}
+		sb.WriteString("\n")
+	}
+	if len(untested) > 0 {
+		sb.WriteString("Changed source files whose tests did not change (a heuristic; confirm or dismiss):\n")
+		for _, f := range untested {
+			sb.WriteString("- ")
+			sb.WriteString(f)
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("Diff:\n\n")
+	if len(diff) > maxReviewDiff {
+		sb.WriteString(truncate(diff, maxReviewDiff))
+		sb.WriteString("\n[diff truncated]\n")
+	} else {
+		sb.WriteString(diff)
+	}
+	user = sb.String()
+	return
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// AnalyzeReview asks the LLM for a pre-analysis of a change under review:
+// risk areas, files deserving scrutiny and missing tests. The issue text and
+// diff are redacted first using the settings for project (a project name).
+// The analysis carries the call's token usage, and so does the one returned
+// with an error once the call was made, as its tokens were spent.
+func (c *Client) AnalyzeReview(ctx context.Context, project, issue string, criteria []string, diff string, untested []string) (*ReviewAnalysis, error) {
+	r := c.redact.For(project)
+	redactedCriteria := make([]string, len(criteria))
+	for i, cr := range criteria {
+		redactedCriteria[i] = r.Redact(cr)
+	}
+	systemPrompt, userPrompt := buildReviewPrompt(r.Redact(issue), redactedCriteria, r.Redact(diff), untested)
+
+	msg, err := c.api.Messages.New(ctx, anthropic.MessageNewParams{
+		Model:     c.model,
+		MaxTokens: 2048,
+		System: []anthropic.TextBlockParam{
+			{Text: systemPrompt},
+		},
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock(userPrompt)),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("anthropic API call: %w", err)
+	}
+	usage := Usage{Model: string(msg.Model), InputTokens: msg.Usage.InputTokens, OutputTokens: msg.Usage.OutputTokens}
+
+	var text string
+	for _, block := range msg.Content {
+		if block.Type == "text" {
+			text = block.Text
+			break
+		}
+	}
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "```") {
+		lines := strings.SplitN(text, "\n", 2)
+		if len(lines) > 1 {
+			text = lines[1]
+		}
+		if idx := strings.LastIndex(text, "```"); idx >= 0 {
+			text = text[:idx]
+		}
+		text = strings.TrimSpace(text)
+	}
+	if text == "" {
+		return &ReviewAnalysis{Usage: usage}, fmt.Errorf("no text content in API response")
+	}
+
+	var analysis ReviewAnalysis
+	if err := json.Unmarshal([]byte(text), &analysis); err != nil {
+		return &ReviewAnalysis{Usage: usage}, fmt.Errorf("parse LLM response as JSON: %w", err)
+	}
+	analysis.Usage = usage
+	analysis.Redactions = r.Report()
+	return &analysis, nil
+}
@@ -3,6 +3,7 @@ package llm
 import (
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -49,7 +50,7 @@ func TestBuildPromptContent(t *testing.T) {
 
 func TestBuildEnrichPrompt(t *testing.T) {
 	t.Run("with all fields", func(t *testing.T) {
-		system, user := buildEnrichPrompt("Fix login bug", "When user clicks login, page crashes", "Login page crashes on submit")
+		system, user := buildEnrichPrompt("Fix login bug", "When user clicks login, page crashes", "Login page crashes on submit", nil)
 
 		assert.Contains(t, system, "description")
 		assert.Contains(t, system, "ai_prompt")
@@ -61,7 +62,7 @@ func TestBuildEnrichPrompt(t *testing.T) {
 	})
 
 	t.Run("with only title", func(t *testing.T) {
-		system, user := buildEnrichPrompt("Add dark mode", "", "")
+		system, user := buildEnrichPrompt("Add dark mode", "", "", nil)
 
 		assert.Contains(t, system, "description")
 		assert.Contains(t, system, "ai_prompt")
@@ -69,7 +70,7 @@ func TestBuildEnrichPrompt(t *testing.T) {
 	})
 
 	t.Run("with title and body no description", func(t *testing.T) {
-		system, user := buildEnrichPrompt("Refactor auth", "The authentication module needs refactoring to use JWT tokens instead of session cookies", "")
+		system, user := buildEnrichPrompt("Refactor auth", "The authentication module needs refactoring to use JWT tokens instead of session cookies", "", nil)
 
 		assert.Contains(t, system, "JSON")
 		assert.Contains(t, user, "Refactor auth")
@@ -77,9 +78,55 @@ func TestBuildEnrichPrompt(t *testing.T) {
 	})
 
 	t.Run("system prompt specifies JSON format", func(t *testing.T) {
-		system, _ := buildEnrichPrompt("Test issue", "", "")
+		system, _ := buildEnrichPrompt("Test issue", "", "", nil)
 
 		assert.Contains(t, system, `"description"`)
 		assert.Contains(t, system, `"ai_prompt"`)
+		assert.Contains(t, system, `"acceptance_criteria"`)
+		assert.NotContains(t, system, "written mainly in")
 	})
+
+	t.Run("tailored to project languages", func(t *testing.T) {
+		system, _ := buildEnrichPrompt("Test issue", "", "", []string{"go", "typescript"})
+
+		assert.Contains(t, system, "written mainly in go and typescript")
+		assert.Contains(t, system, "go test ./...")
+		assert.Contains(t, system, "TypeScript:")
+	})
+}
+
+func TestBuildCommitPrompt(t *testing.T) {
+	_, user := buildCommitPrompt("diff --git a/x b/x\n+hello", "Conventional Commits", "Add greeting")
+	assert.Contains(t, user, "Project commit conventions: Conventional Commits")
+	assert.Contains(t, user, "Add greeting")
+	assert.Contains(t, user, "+hello")
+
+	_, user = buildCommitPrompt(strings.Repeat("y", maxCommitDiff+10), "", "")
+	assert.NotContains(t, user, "conventions")
+	assert.Contains(t, user, "[diff truncated]")
+	assert.Less(t, len(user), maxCommitDiff+100)
+}
+
+func TestBuildReplayPrompt(t *testing.T) {
+	system, user := buildReplayPrompt("! 3. 10-16 14:02  Merge hit conflicts")
+	assert.Contains(t, system, `marked "!"`)
+	assert.Contains(t, user, "Merge hit conflicts")
+}
+
+func TestBuildReviewPrompt(t *testing.T) {
+	system, user := buildReviewPrompt("Add login", []string{"Bad passwords are rejected"}, "+func login() {}", []string{"auth/login.go"})
+	assert.Contains(t, system, `"missing_tests"`)
+	assert.Contains(t, user, "Add login")
+	assert.Contains(t, user, "1. Bad passwords are rejected")
+	assert.Contains(t, user, "- auth/login.go")
+	assert.Contains(t, user, "+func login() {}")
+
+	_, user = buildReviewPrompt("Add login", nil, strings.Repeat("y", maxReviewDiff+10), nil)
+	assert.NotContains(t, user, "Acceptance criteria")
+	assert.NotContains(t, user, "tests did not change")
+	assert.Contains(t, user, "[diff truncated]")
+
+	// The cap falls inside a two-byte character
+	_, user = buildReviewPrompt("Add login", nil, strings.Repeat("y", maxReviewDiff-1)+strings.Repeat("é", 10), nil)
+	assert.True(t, utf8.ValidString(user))
 }
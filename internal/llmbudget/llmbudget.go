@@ -0,0 +1,217 @@
+// Package llmbudget caps how many tokens, or how much money, each project
+// may spend on the LLM in a calendar month. Spend is summed from the usage
+// pm records for every LLM call, so a cap takes effect from the call after
+// the one that crossed it.
+package llmbudget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// What happens to an LLM-backed step when its project is over budget.
+const (
+	// OnExceededHeuristics skips the LLM and falls back to what pm does
+	// without one: issues are created unenriched, imports are parsed by
+	// their headings and keywords and reviews get no pre-analysis.
+	OnExceededHeuristics = "heuristics"
+	// OnExceededReject fails the request instead.
+	OnExceededReject = "reject"
+)
+
+// ErrExceeded is returned, wrapped, by Check for a project over budget.
+var ErrExceeded = errors.New("monthly LLM budget exceeded")
+
+// Limit caps a project's monthly LLM spend. A zero field is not capped.
+type Limit struct {
+	MonthlyTokens int64   `mapstructure:"monthly_tokens" json:"monthly_tokens"`
+	MonthlyCost   float64 `mapstructure:"monthly_cost" json:"monthly_cost"`
+}
+
+// Capped reports whether the limit caps anything.
+func (l Limit) Capped() bool {
+	return l.MonthlyTokens > 0 || l.MonthlyCost > 0
+}
+
+// Config is the llm.budget section of the config file. The top-level limit
+// applies to every project; an entry under Projects, keyed by project name,
+// replaces it for that project. Names match case-insensitively, since viper
+// lowercases config keys.
+type Config struct {
+	Limit             `mapstructure:",squash"`
+	InputCostPerMTok  float64          `mapstructure:"input_cost_per_mtok"`  // USD per million input tokens
+	OutputCostPerMTok float64          `mapstructure:"output_cost_per_mtok"` // USD per million output tokens
+	OnExceeded        string           `mapstructure:"on_exceeded"`          // "heuristics" (default) or "reject"
+	Projects          map[string]Limit `mapstructure:"projects"`
+}
+
+// Budget checks projects' LLM spend against their limits. A nil Budget
+// caps nothing.
+type Budget struct {
+	mu  sync.RWMutex
+	cfg Config
+}
+
+// Validate checks cfg as New and Update do.
+func (c Config) Validate() error {
+	_, err := normalize(c)
+	return err
+}
+
+// New validates cfg.
+func New(cfg Config) (*Budget, error) {
+	cfg, err := normalize(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Budget{cfg: cfg}, nil
+}
+
+// Update validates cfg and replaces the budget's config with it, for
+// checks from then on. An invalid cfg leaves the config unchanged.
+func (b *Budget) Update(cfg Config) error {
+	cfg, err := normalize(cfg)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cfg = cfg
+	return nil
+}
+
+// config returns the current config.
+func (b *Budget) config() Config {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.cfg
+}
+
+// normalize validates cfg, defaults OnExceeded and lowercases the project
+// names.
+func normalize(cfg Config) (Config, error) {
+	switch cfg.OnExceeded {
+	case "":
+		cfg.OnExceeded = OnExceededHeuristics
+	case OnExceededHeuristics, OnExceededReject:
+	default:
+		return Config{}, fmt.Errorf("llm.budget.on_exceeded: unknown value %q (want %s or %s)", cfg.OnExceeded, OnExceededHeuristics, OnExceededReject)
+	}
+	if cfg.MonthlyTokens < 0 || cfg.MonthlyCost < 0 || cfg.InputCostPerMTok < 0 || cfg.OutputCostPerMTok < 0 {
+		return Config{}, errors.New("llm.budget: limits and prices must not be negative")
+	}
+	projects := make(map[string]Limit, len(cfg.Projects))
+	for name, l := range cfg.Projects {
+		if l.MonthlyTokens < 0 || l.MonthlyCost < 0 {
+			return Config{}, fmt.Errorf("llm.budget.projects.%s: limits must not be negative", name)
+		}
+		projects[strings.ToLower(name)] = l
+	}
+	cfg.Projects = projects
+	return cfg, nil
+}
+
+// Limit returns the limit for the named project.
+func (b *Budget) Limit(project string) Limit {
+	if b == nil {
+		return Limit{}
+	}
+	cfg := b.config()
+	if l, ok := cfg.Projects[strings.ToLower(project)]; ok {
+		return l
+	}
+	return cfg.Limit
+}
+
+// Degrade reports whether over-budget steps fall back to heuristics rather
+// than failing.
+func (b *Budget) Degrade() bool {
+	return b == nil || b.config().OnExceeded != OnExceededReject
+}
+
+// Cost prices tokens at the configured rates, in USD.
+func (b *Budget) Cost(input, output int64) float64 {
+	if b == nil {
+		return 0
+	}
+	cfg := b.config()
+	return (float64(input)*cfg.InputCostPerMTok + float64(output)*cfg.OutputCostPerMTok) / 1e6
+}
+
+// UsageLister lists recorded LLM usage; store.Store satisfies it.
+type UsageLister interface {
+	ListLLMUsage(ctx context.Context, projectID, issueID string) ([]*models.LLMUsage, error)
+}
+
+// Status is a project's LLM spend in the current month.
+type Status struct {
+	ProjectID    string           `json:"project_id"`
+	Month        string           `json:"month"` // UTC, "2006-01"
+	ResetsAt     time.Time        `json:"resets_at"`
+	InputTokens  int64            `json:"input_tokens"`
+	OutputTokens int64            `json:"output_tokens"`
+	TotalTokens  int64            `json:"total_tokens"`
+	Cost         float64          `json:"cost"` // USD at the configured prices
+	Limit        Limit            `json:"limit"`
+	Exceeded     bool             `json:"exceeded"`
+	OnExceeded   string           `json:"on_exceeded"`
+	ByPurpose    map[string]int64 `json:"by_purpose"` // total tokens
+}
+
+// Status sums the project's LLM usage since the start of now's month (UTC)
+// and compares it with the project's limit.
+func (b *Budget) Status(ctx context.Context, s UsageLister, p *models.Project, now time.Time) (*Status, error) {
+	now = now.UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	st := &Status{
+		ProjectID:  p.ID,
+		Month:      start.Format("2006-01"),
+		ResetsAt:   start.AddDate(0, 1, 0),
+		Limit:      b.Limit(p.Name),
+		OnExceeded: OnExceededHeuristics,
+		ByPurpose:  map[string]int64{},
+	}
+	if !b.Degrade() {
+		st.OnExceeded = OnExceededReject
+	}
+	usage, err := s.ListLLMUsage(ctx, p.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range usage {
+		if u.CreatedAt.Before(start) {
+			continue
+		}
+		st.InputTokens += u.InputTokens
+		st.OutputTokens += u.OutputTokens
+		st.ByPurpose[string(u.Purpose)] += u.InputTokens + u.OutputTokens
+	}
+	st.TotalTokens = st.InputTokens + st.OutputTokens
+	st.Cost = b.Cost(st.InputTokens, st.OutputTokens)
+	st.Exceeded = (st.Limit.MonthlyTokens > 0 && st.TotalTokens >= st.Limit.MonthlyTokens) ||
+		(st.Limit.MonthlyCost > 0 && st.Cost >= st.Limit.MonthlyCost)
+	return st, nil
+}
+
+// Check returns an error wrapping ErrExceeded if the project has used up
+// this month's budget. Projects without a limit are not looked up.
+func (b *Budget) Check(ctx context.Context, s UsageLister, p *models.Project, now time.Time) error {
+	if !b.Limit(p.Name).Capped() {
+		return nil
+	}
+	st, err := b.Status(ctx, s, p, now)
+	if err != nil {
+		return fmt.Errorf("check LLM budget: %w", err)
+	}
+	if st.Exceeded {
+		return fmt.Errorf("project %s: %w (%d tokens, $%.2f this month; resets %s)",
+			p.Name, ErrExceeded, st.TotalTokens, st.Cost, st.ResetsAt.Format("2006-01-02"))
+	}
+	return nil
+}
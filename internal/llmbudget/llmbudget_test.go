@@ -0,0 +1,100 @@
+package llmbudget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+func TestNew(t *testing.T) {
+	b, err := New(Config{})
+	require.NoError(t, err)
+	assert.True(t, b.Degrade(), "over-budget steps fall back to heuristics by default")
+
+	b, err = New(Config{OnExceeded: OnExceededReject})
+	require.NoError(t, err)
+	assert.False(t, b.Degrade())
+
+	_, err = New(Config{OnExceeded: "panic"})
+	assert.ErrorContains(t, err, "on_exceeded")
+	_, err = New(Config{Limit: Limit{MonthlyTokens: -1}})
+	assert.Error(t, err)
+	_, err = New(Config{Projects: map[string]Limit{"api": {MonthlyCost: -1}}})
+	assert.ErrorContains(t, err, "projects.api")
+
+	var none *Budget
+	assert.True(t, none.Degrade())
+	assert.False(t, none.Limit("api").Capped())
+}
+
+func TestBudget_Update(t *testing.T) {
+	b, err := New(Config{Limit: Limit{MonthlyTokens: 1000}})
+	require.NoError(t, err)
+
+	require.NoError(t, b.Update(Config{OnExceeded: OnExceededReject, Projects: map[string]Limit{"API": {MonthlyCost: 5}}}))
+	assert.False(t, b.Degrade())
+	assert.Equal(t, Limit{MonthlyCost: 5}, b.Limit("api"))
+	assert.False(t, b.Limit("web").Capped(), "the old top-level limit is gone")
+
+	assert.Error(t, b.Update(Config{OnExceeded: "panic"}))
+	assert.False(t, b.Degrade(), "an invalid config is not applied")
+	assert.Equal(t, Limit{MonthlyCost: 5}, b.Limit("api"))
+}
+
+func TestBudget_Status(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	api := &models.Project{Name: "api", Path: "/code/api"}
+	web := &models.Project{Name: "web", Path: "/code/web"}
+	require.NoError(t, s.CreateProject(ctx, api))
+	require.NoError(t, s.CreateProject(ctx, web))
+
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	record := func(p *models.Project, purpose models.LLMUsagePurpose, in, out int64, at time.Time) {
+		require.NoError(t, s.RecordLLMUsage(store.WithImportedTimestamps(ctx), &models.LLMUsage{
+			ProjectID: p.ID, Purpose: purpose, InputTokens: in, OutputTokens: out, CreatedAt: at,
+		}))
+	}
+	record(api, models.LLMUsageEnrichment, 6000, 1000, now.Add(-time.Hour))
+	record(api, models.LLMUsageReviewPreAnalysis, 2000, 1000, now.AddDate(0, 0, -14))
+	record(api, models.LLMUsageEnrichment, 50000, 50000, now.AddDate(0, -1, 0)) // last month
+	record(web, models.LLMUsageEnrichment, 500, 500, now)
+
+	b, err := New(Config{
+		Limit:             Limit{MonthlyTokens: 10000},
+		InputCostPerMTok:  3,
+		OutputCostPerMTok: 15,
+		Projects:          map[string]Limit{"Web": {MonthlyCost: 0.01}},
+	})
+	require.NoError(t, err)
+
+	st, err := b.Status(ctx, s, api, now)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-03", st.Month)
+	assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), st.ResetsAt)
+	assert.Equal(t, int64(8000), st.InputTokens)
+	assert.Equal(t, int64(2000), st.OutputTokens)
+	assert.Equal(t, int64(10000), st.TotalTokens)
+	assert.InDelta(t, 0.054, st.Cost, 1e-9)
+	assert.Equal(t, map[string]int64{"enrichment": 7000, "review_pre_analysis": 3000}, st.ByPurpose)
+	assert.True(t, st.Exceeded, "reaching the cap exhausts it")
+	assert.Equal(t, OnExceededHeuristics, st.OnExceeded)
+
+	err = b.Check(ctx, s, api, now)
+	assert.ErrorIs(t, err, ErrExceeded)
+	assert.ErrorContains(t, err, "resets 2026-04-01")
+	assert.NoError(t, b.Check(ctx, s, api, now.AddDate(0, 1, 0)), "a new month starts afresh")
+
+	st, err = b.Status(ctx, s, web, now)
+	require.NoError(t, err)
+	assert.Equal(t, Limit{MonthlyCost: 0.01}, st.Limit, "the project entry replaces the global limit")
+	assert.InDelta(t, 0.009, st.Cost, 1e-9)
+	assert.False(t, st.Exceeded)
+	assert.NoError(t, b.Check(ctx, s, web, now))
+}
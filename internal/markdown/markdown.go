@@ -0,0 +1,79 @@
+// Package markdown renders issue text to sanitized HTML, so the web UI and
+// exported pages share one renderer. Fenced code blocks are highlighted with
+// CSS classes (see HighlightCSS), and raw HTML in the source is kept only
+// where the sanitizer allows it.
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// HighlightStyle is the chroma style HighlightCSS is generated from.
+const HighlightStyle = "github"
+
+var (
+	md = goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			highlighting.NewHighlighting(
+				highlighting.WithStyle(HighlightStyle),
+				highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+			),
+		),
+		// Raw HTML is passed through to the sanitizer rather than dropped.
+		goldmark.WithRendererOptions(html.WithHardWraps(), html.WithUnsafe()),
+	)
+
+	policy = newPolicy()
+
+	cssOnce sync.Once
+	css     string
+)
+
+// newPolicy allows user-generated content plus the classes chroma puts on
+// highlighted code and the disabled checkboxes of GFM task lists.
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^[a-zA-Z0-9 _-]+$`)).OnElements("pre", "code", "span")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	p.RequireNoFollowOnLinks(true)
+	return p
+}
+
+// Render converts GitHub-flavored Markdown to sanitized HTML. Empty or
+// whitespace-only input renders as "".
+func Render(src string) (string, error) {
+	if strings.TrimSpace(src) == "" {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(src), &buf); err != nil {
+		return "", err
+	}
+	return policy.Sanitize(buf.String()), nil
+}
+
+// HighlightCSS returns the stylesheet for the classes Render puts on
+// highlighted code.
+func HighlightCSS() string {
+	cssOnce.Do(func() {
+		var buf bytes.Buffer
+		f := chromahtml.New(chromahtml.WithClasses(true))
+		if err := f.WriteCSS(&buf, styles.Get(HighlightStyle)); err == nil {
+			css = buf.String()
+		}
+	})
+	return css
+}
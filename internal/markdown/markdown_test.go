@@ -0,0 +1,49 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		contains []string
+		excludes []string
+	}{
+		{"empty", "  \n", nil, nil},
+		{"basic", "# Title\n\nSome **bold** text", []string{"<h1", "Title</h1>", "<strong>bold</strong>"}, nil},
+		{"gfm table", "| a | b |\n|---|---|\n| 1 | 2 |", []string{"<table>", "<td>1</td>"}, nil},
+		{"task list", "- [x] done\n- [ ] todo", []string{`<input checked="" disabled="" type="checkbox"`}, nil},
+		{"highlighting", "```go\nfunc main() {}\n```", []string{`<pre class="chroma">`, `<span class="kd">func</span>`}, []string{"style="}},
+		{"links", "[site](https://example.com)", []string{`href="https://example.com"`, `rel="nofollow"`}, nil},
+		{"script", "hi <script>alert(1)</script>", []string{"hi"}, []string{"<script", "alert(1)"}},
+		{"event handler", `<img src="x.png" onerror="alert(1)">`, []string{`<img src="x.png"`}, []string{"onerror"}},
+		{"javascript link", "[click](javascript:alert(1))", []string{"click"}, []string{"javascript:"}},
+		{"safe html", "<details><summary>More</summary>text</details>", []string{"<details>", "<summary>More</summary>"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.in)
+			require.NoError(t, err)
+			if tt.contains == nil && tt.excludes == nil {
+				assert.Empty(t, got)
+			}
+			for _, s := range tt.contains {
+				assert.Contains(t, got, s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, got, s)
+			}
+		})
+	}
+}
+
+func TestHighlightCSS(t *testing.T) {
+	css := HighlightCSS()
+	assert.Contains(t, css, ".chroma")
+	assert.Contains(t, css, ".kd")
+}
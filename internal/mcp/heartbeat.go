@@ -0,0 +1,56 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// beatTools is tool middleware counting successful tool calls as activity on
+// the session they name: the session_id argument's session, or else the open
+// sessions of the issue_id argument's issue. Heartbeats are best-effort and
+// never fail the call.
+func (s *Server) beatTools(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if err != nil || result == nil || result.IsError {
+			return result, err
+		}
+		for _, id := range s.toolSessions(ctx, request) {
+			_, _ = s.heartbeats.Beat(ctx, s.store, id)
+		}
+		return result, err
+	}
+}
+
+// toolSessions returns the IDs of the sessions a tool call is activity on.
+func (s *Server) toolSessions(ctx context.Context, request mcp.CallToolRequest) []string {
+	if ref := request.GetString("session_id", ""); ref != "" {
+		if sess, err := s.findSession(ctx, ref); err == nil {
+			return []string{sess.ID}
+		}
+		return nil
+	}
+	ref := request.GetString("issue_id", "")
+	if ref == "" {
+		return nil
+	}
+	issue, err := s.findIssue(ctx, ref)
+	if err != nil {
+		return nil
+	}
+	sessions, err := s.store.ListAgentSessionsByStatus(ctx, issue.ProjectID, []models.SessionStatus{models.SessionStatusActive, models.SessionStatusIdle, models.SessionStatusBlocked}, 0)
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	for _, sess := range sessions {
+		if sess.IssueID == issue.ID {
+			ids = append(ids, sess.ID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/joescharf/pm/internal/auth"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// Project notes are exposed as Markdown resources so agents can consult a
+// project's wiki:
+//
+//	pm://notes                            index of every project's notes
+//	pm://projects/{project}/notes         index of one project's notes
+//	pm://projects/{project}/notes/{note}  one note, by ID, ID prefix or title
+const (
+	notesIndexURI    = "pm://notes"
+	projectURIPrefix = "pm://projects/"
+	markdownMIME     = "text/markdown"
+)
+
+// addNoteResources registers the project note resources on srv.
+func (s *Server) addNoteResources(srv *server.MCPServer) {
+	srv.AddResource(mcp.NewResource(notesIndexURI, "Project notes",
+		mcp.WithResourceDescription("Index of the notes (architecture decisions, runbooks) in every project's wiki, with the URI of each note"),
+		mcp.WithMIMEType(markdownMIME),
+	), s.readNotesIndex)
+	srv.AddResourceTemplate(mcp.NewResourceTemplate(projectURIPrefix+"{project}/notes", "Project wiki",
+		mcp.WithTemplateDescription("Index of one project's notes; project is the project name or ID"),
+		mcp.WithTemplateMIMEType(markdownMIME),
+	), s.readProjectNotes)
+	srv.AddResourceTemplate(mcp.NewResourceTemplate(projectURIPrefix+"{project}/notes/{note}", "Project note",
+		mcp.WithTemplateDescription("One project note as Markdown; note is the note ID, ID prefix or title"),
+		mcp.WithTemplateMIMEType(markdownMIME),
+	), s.readProjectNote)
+}
+
+func (s *Server) readNotesIndex(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	if err, ok := ctx.Value(authErrKey{}).(error); ok {
+		return nil, err
+	}
+	projects, err := s.store.ListProjects(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	sc, scoped := auth.FromContext(ctx)
+
+	var b strings.Builder
+	b.WriteString("# Project notes\n")
+	for _, p := range projects {
+		if scoped && !sc.Allows(p.ID) {
+			continue
+		}
+		notes, err := s.store.ListProjectNotes(ctx, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(notes) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", p.Name)
+		writeNoteList(&b, p, notes)
+	}
+	return markdownContents(request.Params.URI, b.String()), nil
+}
+
+func (s *Server) readProjectNotes(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	p, _, err := s.noteResourceTarget(ctx, request.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := s.store.ListProjectNotes(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s notes\n\n", p.Name)
+	if len(notes) == 0 {
+		b.WriteString("No notes yet.\n")
+	}
+	writeNoteList(&b, p, notes)
+	return markdownContents(request.Params.URI, b.String()), nil
+}
+
+func (s *Server) readProjectNote(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	p, ref, err := s.noteResourceTarget(ctx, request.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+	note, err := store.FindProjectNote(ctx, s.store, p.ID, ref)
+	if err != nil {
+		return nil, err
+	}
+	text := fmt.Sprintf("# %s\n\n%s\n", note.Title, strings.TrimRight(note.Body, "\n"))
+	return markdownContents(request.Params.URI, text), nil
+}
+
+// noteResourceTarget parses pm://projects/{project}/notes[/{note}], resolves
+// the project and checks it against a project-scoped API token.
+func (s *Server) noteResourceTarget(ctx context.Context, uri string) (*models.Project, string, error) {
+	if err, ok := ctx.Value(authErrKey{}).(error); ok {
+		return nil, "", err
+	}
+	rest, ok := strings.CutPrefix(uri, projectURIPrefix)
+	if !ok {
+		return nil, "", fmt.Errorf("unknown resource: %s", uri)
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[1] != "notes" {
+		return nil, "", fmt.Errorf("unknown resource: %s", uri)
+	}
+	for i, part := range parts {
+		unescaped, err := url.PathUnescape(part)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid resource URI %s: %w", uri, err)
+		}
+		parts[i] = unescaped
+	}
+
+	p, err := s.resolveProject(ctx, parts[0])
+	if err != nil {
+		return nil, "", err
+	}
+	if sc, ok := auth.FromContext(ctx); ok && !sc.Allows(p.ID) {
+		return nil, "", fmt.Errorf("%s is not scoped to project %s", scopeHolder(sc), p.Name)
+	}
+	var ref string
+	if len(parts) == 3 {
+		ref = parts[2]
+	}
+	return p, ref, nil
+}
+
+// noteURI returns the resource URI of a note.
+func noteURI(p *models.Project, n *models.ProjectNote) string {
+	return projectURIPrefix + url.PathEscape(p.Name) + "/notes/" + n.ID
+}
+
+func writeNoteList(b *strings.Builder, p *models.Project, notes []*models.ProjectNote) {
+	for _, n := range notes {
+		fmt.Fprintf(b, "- %s (%s), updated %s\n", n.Title, noteURI(p, n), n.UpdatedAt.Format("2006-01-02"))
+	}
+}
+
+func markdownContents(uri, text string) []mcp.ResourceContents {
+	return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: markdownMIME, Text: text}}
+}
@@ -0,0 +1,185 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/joescharf/pm/internal/auth"
+	"github.com/joescharf/pm/internal/models"
+)
+
+// optionalProjectTools take an optional project argument; project-scoped
+// calls that omit it are limited to the scoped project.
+var optionalProjectTools = map[string]bool{
+	"pm_list_issues": true,
+	"pm_next_tasks":  true,
+	"pm_search_code": true,
+}
+
+type authErrKey struct{}
+
+// WithScope limits every tool call to one project, as if each request carried
+// a project-scoped API token. Used for stdio servers started with a token.
+func WithScope(sc auth.Scope) Option {
+	return func(s *Server) {
+		s.scope = &sc
+	}
+}
+
+// WithProject binds a stdio server to one project, as pm mcp --scope cwd
+// does: tools lose their project argument, which is always p, and calls
+// touching another project's issues or sessions are refused as for a token
+// scoped to p.
+func WithProject(p *models.Project) Option {
+	return func(s *Server) {
+		s.project = p
+		s.scope = &auth.Scope{ProjectID: p.ID}
+	}
+}
+
+// bindTools drops the project argument from the registered tools for a
+// server bound by WithProject; scopeTools supplies it on every call.
+func (s *Server) bindTools(srv *server.MCPServer) {
+	s.boundTools = make(map[string]bool)
+	for name, t := range srv.ListTools() {
+		schema := t.Tool.InputSchema
+		if _, ok := schema.Properties["project"]; !ok {
+			continue
+		}
+		props := maps.Clone(schema.Properties)
+		delete(props, "project")
+		schema.Properties = props
+		schema.Required = slices.DeleteFunc(slices.Clone(schema.Required), func(r string) bool { return r == "project" })
+		t.Tool.InputSchema = schema
+		srv.AddTool(t.Tool, t.Handler)
+		s.boundTools[name] = true
+	}
+}
+
+// HTTPContext authenticates the bearer token of a StreamableHTTP request, for
+// use with server.WithHTTPContextFunc. Requests without a token keep full
+// access; tool calls on requests with an invalid token fail.
+func (s *Server) HTTPContext(ctx context.Context, r *http.Request) context.Context {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return ctx
+	}
+	sc, err := auth.Authenticate(ctx, s.store, auth.BearerToken(header))
+	if err != nil {
+		return context.WithValue(ctx, authErrKey{}, err)
+	}
+	return auth.WithScope(ctx, sc)
+}
+
+// RequireToken wraps a StreamableHTTP handler so requests without a bearer
+// token are refused with 401, for servers exposed beyond localhost. Invalid
+// tokens are still reported by HTTPContext.
+func RequireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, "API token required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// scopeTools is tool middleware enforcing project-scoped API tokens: every
+// project, issue and session argument must belong to the scoped project, and
+// tools without one are refused.
+func (s *Server) scopeTools(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if err, ok := ctx.Value(authErrKey{}).(error); ok {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		sc, ok := auth.FromContext(ctx)
+		if !ok {
+			return next(ctx, request)
+		}
+		if err := s.authorizeTool(ctx, sc, &request); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return next(ctx, request)
+	}
+}
+
+func (s *Server) authorizeTool(ctx context.Context, sc auth.Scope, request *mcp.CallToolRequest) error {
+	name := request.Params.Name
+	if name == "pm_list_projects" {
+		return nil // the handler lists only the scoped project
+	}
+	args := request.GetArguments()
+	if args == nil {
+		args = make(map[string]any)
+		request.Params.Arguments = args
+	}
+
+	checked := false
+	if s.project != nil && s.boundTools[name] {
+		if ref := request.GetString("project", ""); ref != "" && ref != s.project.Name && ref != s.project.ID {
+			return fmt.Errorf("%s is not scoped to project %s", scopeHolder(sc), ref)
+		}
+		args["project"] = s.project.Name
+		checked = true
+	} else if optionalProjectTools[name] && request.GetString("project", "") == "" {
+		p, err := s.store.GetProject(ctx, sc.ProjectID)
+		if err != nil {
+			return err
+		}
+		args["project"] = p.Name
+		checked = true
+	}
+	if ref := request.GetString("project", ""); ref != "" {
+		p, err := s.resolveProject(ctx, ref)
+		if err != nil {
+			return err
+		}
+		if !sc.Allows(p.ID) {
+			return fmt.Errorf("%s is not scoped to project %s", scopeHolder(sc), p.Name)
+		}
+		checked = true
+	}
+	for _, key := range []string{"issue_id", "target_issue_id"} {
+		ref := request.GetString(key, "")
+		if ref == "" {
+			continue
+		}
+		issue, err := s.findIssue(ctx, ref)
+		if err != nil {
+			return err
+		}
+		if !sc.Allows(issue.ProjectID) {
+			return fmt.Errorf("%s is not scoped to the project of issue %s", scopeHolder(sc), ref)
+		}
+		checked = true
+	}
+	if ref := request.GetString("session_id", ""); ref != "" {
+		sess, err := s.findSession(ctx, ref)
+		if err != nil {
+			return err
+		}
+		if !sc.Allows(sess.ProjectID) {
+			return fmt.Errorf("%s is not scoped to the project of session %s", scopeHolder(sc), ref)
+		}
+		checked = true
+	}
+	if !checked {
+		return fmt.Errorf("%s is not available to project-scoped %ss", name, scopeHolder(sc))
+	}
+	return nil
+}
+
+// scopeHolder names what limits a call to one project: a project-scoped API
+// token, or a server bound by WithProject, which has no token.
+func scopeHolder(sc auth.Scope) string {
+	if sc.TokenID == "" {
+		return "MCP server"
+	}
+	return "API token"
+}
@@ -3,9 +3,12 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -14,12 +17,22 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 
 	"github.com/joescharf/pm/internal/agent"
+	"github.com/joescharf/pm/internal/auth"
+	"github.com/joescharf/pm/internal/autoclose"
+	"github.com/joescharf/pm/internal/capabilities"
+	"github.com/joescharf/pm/internal/codesearch"
+	"github.com/joescharf/pm/internal/commitmsg"
 	"github.com/joescharf/pm/internal/git"
 	"github.com/joescharf/pm/internal/health"
 	"github.com/joescharf/pm/internal/llm"
+	"github.com/joescharf/pm/internal/llmbudget"
 	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/notify"
+	"github.com/joescharf/pm/internal/refresh"
+	"github.com/joescharf/pm/internal/releases"
 	"github.com/joescharf/pm/internal/sessions"
 	"github.com/joescharf/pm/internal/store"
+	"github.com/joescharf/pm/internal/uireview"
 	"github.com/joescharf/pm/internal/wt"
 	"github.com/joescharf/wt/pkg/lifecycle"
 )
@@ -31,14 +44,115 @@ type Server struct {
 	gh       git.GitHubClient
 	wt       wt.Client
 	llm      *llm.Client
+	budget   *llmbudget.Budget
 	scorer   *health.Scorer
 	sessions *sessions.Manager
+
+	reviewRelaunch     bool
+	maxReviewAttempts  int
+	reviewPreAnalysis  bool
+	blockedRemindAfter time.Duration
+	issueLockExpiry    time.Duration
+	heartbeats         *agent.Heartbeats
+	caps               *capabilities.Capabilities
+	releaseSchedule    releases.Schedule
+
+	scope *auth.Scope // set by WithScope for stdio servers
+
+	// project is set by WithProject; boundTools are the tools whose project
+	// argument it replaced.
+	project    *models.Project
+	boundTools map[string]bool
+}
+
+// Option configures optional Server behavior.
+type Option func(*Server)
+
+// WithReviewRelaunch enables launching a follow-up session when pm_save_review
+// records a fail verdict. maxAttempts caps follow-ups per issue (0 = no limit).
+func WithReviewRelaunch(enabled bool, maxAttempts int) Option {
+	return func(s *Server) {
+		s.reviewRelaunch = enabled
+		s.maxReviewAttempts = maxAttempts
+	}
+}
+
+// WithReviewPreAnalysis makes pm_prepare_review include an LLM pre-analysis
+// of the change unless a call turns it off.
+func WithReviewPreAnalysis(enabled bool) Option {
+	return func(s *Server) {
+		s.reviewPreAnalysis = enabled
+	}
+}
+
+// WithLLMBudget caps each project's monthly LLM spend: issue enrichment and
+// review pre-analysis check it before calling the LLM.
+func WithLLMBudget(b *llmbudget.Budget) Option {
+	return func(s *Server) {
+		s.budget = b
+	}
+}
+
+// WithCapabilities sets the capabilities detected at startup; tools that
+// need a missing one are not registered.
+func WithCapabilities(c *capabilities.Capabilities) Option {
+	return func(s *Server) {
+		s.caps = c
+	}
+}
+
+// WithBlockedReminder sets how long a session may stay blocked before
+// pm_my_focus flags it as overdue (<= 0 never flags).
+func WithBlockedReminder(after time.Duration) Option {
+	return func(s *Server) {
+		s.blockedRemindAfter = after
+	}
+}
+
+// WithIssueLockExpiry sets how long an active session without activity keeps
+// its issue locked against other launches (<= 0 never expires).
+func WithIssueLockExpiry(d time.Duration) Option {
+	return func(s *Server) {
+		s.issueLockExpiry = d
+	}
+}
+
+// WithAutoClose configures closing the issues referenced by the commits of
+// sessions merged through pm_merge_session.
+func WithAutoClose(c autoclose.Config) Option {
+	return func(s *Server) {
+		s.sessions.SetAutoClose(c)
+	}
+}
+
+// WithReleaseSchedule sets the release polling schedule, which decides when
+// a stored release is old enough to fetch again on read.
+func WithReleaseSchedule(sched releases.Schedule) Option {
+	return func(s *Server) {
+		s.releaseSchedule = sched
+	}
+}
+
+// WithHeartbeats shares h with the server, so tool calls count toward the
+// per-session write interval of the other heartbeat sources.
+func WithHeartbeats(h *agent.Heartbeats) Option {
+	return func(s *Server) {
+		s.heartbeats = h
+	}
+}
+
+// WithOperations shares ops with the server, so the syncs and merges its tools
+// run can be listed and canceled through the REST API.
+func WithOperations(ops *sessions.Operations) Option {
+	return func(s *Server) {
+		s.sessions.SetOperations(ops)
+	}
 }
 
 // NewServer creates the MCP server wrapper with all required dependencies.
 // The llmClient may be nil if no API key is configured.
-func NewServer(s store.Store, gc git.Client, ghc git.GitHubClient, wtc wt.Client, llmClient *llm.Client) *Server {
-	return &Server{
+func NewServer(s store.Store, gc git.Client, ghc git.GitHubClient, wtc wt.Client, llmClient *llm.Client, opts ...Option) *Server {
+	srv := &Server{
 		store:    s,
 		git:      gc,
 		gh:       ghc,
@@ -46,17 +160,35 @@ func NewServer(s store.Store, gc git.Client, ghc git.GitHubClient, wtc wt.Client
 		llm:      llmClient,
 		scorer:   health.NewScorer(),
 		sessions: sessions.NewManager(s, wtc),
+
+		blockedRemindAfter: agent.DefaultBlockedRemindAfter,
+		issueLockExpiry:    agent.DefaultIssueLockExpiry,
+		heartbeats:         agent.NewHeartbeats(agent.DefaultHeartbeatInterval),
+		releaseSchedule:    releases.DefaultSchedule(),
+	}
+	if gc != nil {
+		srv.sessions.SetGitClient(gc)
 	}
+	for _, opt := range opts {
+		opt(srv)
+	}
+	return srv
 }
 
 // MCPServer returns a configured mcp-go server with all tools registered.
 func (s *Server) MCPServer() *server.MCPServer {
-	srv := server.NewMCPServer("pm", "1.0.0", server.WithToolCapabilities(true))
+	srv := server.NewMCPServer("pm", "1.0.0",
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(false, false),
+		server.WithToolHandlerMiddleware(s.scopeTools),
+		server.WithToolHandlerMiddleware(s.beatTools),
+	)
 
 	// Register all tools
 	srv.AddTool(s.listProjectsTool())
 	srv.AddTool(s.projectStatusTool())
 	srv.AddTool(s.listIssuesTool())
+	srv.AddTool(s.getIssueTool())
 	srv.AddTool(s.createIssueTool())
 	srv.AddTool(s.updateIssueTool())
 	srv.AddTool(s.healthScoreTool())
@@ -69,6 +201,22 @@ func (s *Server) MCPServer() *server.MCPServer {
 	srv.AddTool(s.prepareReviewTool())
 	srv.AddTool(s.saveReviewTool())
 	srv.AddTool(s.updateProjectTool())
+	srv.AddTool(s.relateIssuesTool())
+	srv.AddTool(s.watchIssueTool())
+	srv.AddTool(s.unwatchIssueTool())
+	srv.AddTool(s.issueCodeTool())
+	srv.AddTool(s.searchCodeTool())
+	srv.AddTool(s.myFocusTool())
+	srv.AddTool(s.nextTasksTool())
+	if s.caps.Has(capabilities.LLM) {
+		srv.AddTool(s.suggestCommitMessageTool())
+	}
+	if s.project != nil {
+		s.bindTools(srv)
+	}
+
+	// Project notes as Markdown resources
+	s.addNoteResources(srv)
 
 	return srv
 }
@@ -77,6 +225,12 @@ func (s *Server) MCPServer() *server.MCPServer {
 func (s *Server) ServeStdio(ctx context.Context) error {
 	srv := s.MCPServer()
 	stdioServer := server.NewStdioServer(srv)
+	if s.scope != nil {
+		sc := *s.scope
+		stdioServer.SetContextFunc(func(ctx context.Context) context.Context {
+			return auth.WithScope(ctx, sc)
+		})
+	}
 	return stdioServer.Listen(ctx, os.Stdin, os.Stdout)
 }
 
@@ -99,6 +253,15 @@ func (s *Server) handleListProjects(ctx context.Context, request mcp.CallToolReq
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list projects: %v", err)), nil
 	}
+	if sc, ok := auth.FromContext(ctx); ok {
+		var scoped []*models.Project
+		for _, p := range projects {
+			if sc.Allows(p.ID) {
+				scoped = append(scoped, p)
+			}
+		}
+		projects = scoped
+	}
 
 	type projectOut struct {
 		ID          string `json:"id"`
@@ -155,24 +318,26 @@ func (s *Server) handleProjectStatus(ctx context.Context, request mcp.CallToolRe
 	var branchCount int
 
 	if s.git != nil && p.Path != "" {
-		branch, _ = s.git.CurrentBranch(p.Path)
-		dirty, _ = s.git.IsDirty(p.Path)
-		lastCommitDate, _ = s.git.LastCommitDate(p.Path)
-		lastCommitHash, _ = s.git.LastCommitHash(p.Path)
-		lastCommitMsg, _ = s.git.LastCommitMessage(p.Path)
-		branches, _ := s.git.BranchList(p.Path)
+		branch, _ = s.git.CurrentBranch(ctx, p.Path)
+		dirty, _ = s.git.IsDirty(ctx, p.Path)
+		lastCommitDate, _ = s.git.LastCommitDate(ctx, p.Path)
+		lastCommitHash, _ = s.git.LastCommitHash(ctx, p.Path)
+		lastCommitMsg, _ = s.git.LastCommitMessage(ctx, p.Path)
+		branches, _ := s.git.BranchList(ctx, p.Path)
 		branchCount = len(branches)
 	}
 
 	// Gather issue counts
 	allIssues, _ := s.store.ListIssues(ctx, store.IssueListFilter{ProjectID: p.ID})
-	openCount, inProgressCount, doneCount, closedCount := 0, 0, 0, 0
+	openCount, inProgressCount, inReviewCount, doneCount, closedCount := 0, 0, 0, 0, 0
 	for _, issue := range allIssues {
 		switch issue.Status {
 		case models.IssueStatusOpen:
 			openCount++
 		case models.IssueStatusInProgress:
 			inProgressCount++
+		case models.IssueStatusInReview:
+			inReviewCount++
 		case models.IssueStatusDone:
 			doneCount++
 		case models.IssueStatusClosed:
@@ -187,15 +352,11 @@ func (s *Server) handleProjectStatus(ctx context.Context, request mcp.CallToolRe
 		BranchCount:    branchCount,
 	}
 
-	// Try to get release info for health score
-	if s.gh != nil && p.RepoURL != "" {
-		if owner, repo, err := git.ExtractOwnerRepo(p.RepoURL); err == nil {
-			if rel, err := s.gh.LatestRelease(owner, repo); err == nil {
-				meta.LatestRelease = rel.TagName
-				if t, err := time.Parse(time.RFC3339, rel.PublishedAt); err == nil {
-					meta.ReleaseDate = t
-				}
-			}
+	// Stored release info for health score
+	if rel := releases.Cached(ctx, s.store, s.gh, p, s.releaseSchedule); rel != nil {
+		meta.LatestRelease = rel.TagName
+		if rel.PublishedAt != nil {
+			meta.ReleaseDate = *rel.PublishedAt
 		}
 	}
 
@@ -208,6 +369,7 @@ func (s *Server) handleProjectStatus(ctx context.Context, request mcp.CallToolRe
 			"path":             p.Path,
 			"description":      p.Description,
 			"language":         p.Language,
+			"languages":        p.Languages,
 			"group":            p.GroupName,
 			"repo_url":         p.RepoURL,
 			"has_github_pages": p.HasGitHubPages,
@@ -216,7 +378,7 @@ func (s *Server) handleProjectStatus(ctx context.Context, request mcp.CallToolRe
 		"git": map[string]any{
 			"branch":          branch,
 			"dirty":           dirty,
-			"last_commit_date": lastCommitDate.Format(time.RFC3339),
+			"last_commit_date": lastCommitDate.UTC().Format(time.RFC3339),
 			"last_commit_hash": lastCommitHash,
 			"last_commit_msg":  lastCommitMsg,
 			"branch_count":     branchCount,
@@ -225,6 +387,7 @@ func (s *Server) handleProjectStatus(ctx context.Context, request mcp.CallToolRe
 			"total":       len(allIssues),
 			"open":        openCount,
 			"in_progress": inProgressCount,
+			"in_review":   inReviewCount,
 			"done":        doneCount,
 			"closed":      closedCount,
 		},
@@ -237,6 +400,9 @@ func (s *Server) handleProjectStatus(ctx context.Context, request mcp.CallToolRe
 			"branch_hygiene":    hscore.BranchHygiene,
 		},
 	}
+	if wip, err := agent.CheckWIP(ctx, s.store, p, 0, 0); err == nil {
+		result["wip"] = wip
+	}
 
 	data, err := json.Marshal(result)
 	if err != nil {
@@ -248,9 +414,9 @@ func (s *Server) handleProjectStatus(ctx context.Context, request mcp.CallToolRe
 // pm_list_issues
 func (s *Server) listIssuesTool() (mcp.Tool, server.ToolHandlerFunc) {
 	tool := mcp.NewTool("pm_list_issues",
-		mcp.WithDescription("List issues, optionally filtered by project, status, and/or priority. Returns a JSON array of issues. Each issue has: title, description (short summary), body (raw original text with full context — use this for implementation details), ai_prompt (LLM-generated guidance for AI agents), status (open/in_progress/done/closed), priority (low/medium/high), type (feature/bug/chore), and tags."),
+		mcp.WithDescription("List issues, optionally filtered by project, status, and/or priority. Returns a JSON array of issues. Each issue has: title, description (short summary), body (raw original text with full context — use this for implementation details), ai_prompt (LLM-generated guidance for AI agents), status (open/in_progress/done/closed), priority (low/medium/high), type (feature/bug/chore), tags, estimate, acceptance_criteria, and readiness_score (0-100: how well the issue is specified for an agent)."),
 		mcp.WithString("project", mcp.Description("Project name to filter by")),
-		mcp.WithString("status", mcp.Description("Status filter: open, in_progress, done, closed")),
+		mcp.WithString("status", mcp.Description("Status filter: open, in_progress, in_review, done, closed")),
 		mcp.WithString("priority", mcp.Description("Priority filter: low, medium, high")),
 	)
 	return tool, s.handleListIssues
@@ -268,14 +434,16 @@ func (s *Server) handleListIssues(ctx context.Context, request mcp.CallToolReque
 		filter.ProjectID = p.ID
 	}
 
-	status := request.GetString("status", "")
-	if status != "" {
-		filter.Status = models.IssueStatus(status)
+	var err error
+	if status := request.GetString("status", ""); status != "" {
+		if filter.Status, err = models.ParseIssueStatus(status); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 	}
-
-	priority := request.GetString("priority", "")
-	if priority != "" {
-		filter.Priority = models.IssuePriority(priority)
+	if priority := request.GetString("priority", ""); priority != "" {
+		if filter.Priority, err = models.ParseIssuePriority(priority); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 	}
 
 	issues, err := s.store.ListIssues(ctx, filter)
@@ -283,39 +451,9 @@ func (s *Server) handleListIssues(ctx context.Context, request mcp.CallToolReque
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list issues: %v", err)), nil
 	}
 
-	type issueOut struct {
-		ID          string   `json:"id"`
-		ProjectID   string   `json:"project_id"`
-		Title       string   `json:"title"`
-		Description string   `json:"description"`
-		Body        string   `json:"body,omitempty"`
-		AIPrompt    string   `json:"ai_prompt,omitempty"`
-		Status      string   `json:"status"`
-		Priority    string   `json:"priority"`
-		Type        string   `json:"type"`
-		Tags        []string `json:"tags"`
-		GitHubIssue int      `json:"github_issue,omitempty"`
-		CreatedAt   string   `json:"created_at"`
-		UpdatedAt   string   `json:"updated_at"`
-	}
-
 	out := make([]issueOut, len(issues))
 	for i, issue := range issues {
-		out[i] = issueOut{
-			ID:          issue.ID,
-			ProjectID:   issue.ProjectID,
-			Title:       issue.Title,
-			Description: issue.Description,
-			Body:        issue.Body,
-			AIPrompt:    issue.AIPrompt,
-			Status:      string(issue.Status),
-			Priority:    string(issue.Priority),
-			Type:        string(issue.Type),
-			Tags:        issue.Tags,
-			GitHubIssue: issue.GitHubIssue,
-			CreatedAt:   issue.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:   issue.UpdatedAt.Format(time.RFC3339),
-		}
+		out[i] = s.issueOut(ctx, issue)
 	}
 
 	data, err := json.Marshal(out)
@@ -325,10 +463,165 @@ func (s *Server) handleListIssues(ctx context.Context, request mcp.CallToolReque
 	return mcp.NewToolResultText(string(data)), nil
 }
 
+// issueOut is an issue as pm_list_issues and pm_get_issue return it.
+type issueOut struct {
+	ID          string   `json:"id"`
+	ProjectID   string   `json:"project_id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Body        string   `json:"body,omitempty"`
+	AIPrompt    string   `json:"ai_prompt,omitempty"`
+	Status      string   `json:"status"`
+	Priority    string   `json:"priority"`
+	Type        string   `json:"type"`
+	Tags        []string `json:"tags"`
+	GitHubIssue int      `json:"github_issue,omitempty"`
+	Estimate    string   `json:"estimate,omitempty"`
+	Readiness   int      `json:"readiness_score"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+
+	AcceptanceCriteria []string `json:"acceptance_criteria,omitempty"`
+}
+
+func (s *Server) issueOut(ctx context.Context, issue *models.Issue) issueOut {
+	return issueOut{
+		ID:          issue.ID,
+		ProjectID:   issue.ProjectID,
+		Title:       issue.Title,
+		Description: issue.Description,
+		Body:        issue.Body,
+		AIPrompt:    issue.AIPrompt,
+		Status:      string(issue.Status),
+		Priority:    string(issue.Priority),
+		Type:        string(issue.Type),
+		Tags:        issue.Tags,
+		GitHubIssue: issue.GitHubIssue,
+		Estimate:    issue.Estimate,
+		Readiness:   agent.AssessReadiness(ctx, s.store, issue).Score,
+		CreatedAt:   issue.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:   issue.UpdatedAt.UTC().Format(time.RFC3339),
+
+		AcceptanceCriteria: issue.AcceptanceCriteria,
+	}
+}
+
+// pm_get_issue
+func (s *Server) getIssueTool() (mcp.Tool, server.ToolHandlerFunc) {
+	tool := mcp.NewTool("pm_get_issue",
+		mcp.WithDescription("Get one issue with everything known about it, instead of scanning pm_list_issues: the issue (as pm_list_issues returns it, plus project, assignee, lifecycle timestamps and, once merged, merge_commit, merge_branch and merged_at), its agent sessions (newest first), its review history (newest first, with per-category results, failure reasons and acceptance criterion results) and its related issues."),
+		mcp.WithString("issue_id", mcp.Required(), mcp.Description("Issue short code (e.g. PM-12) or ID (full or prefix)")),
+	)
+	return tool, s.handleGetIssue
+}
+
+func (s *Server) handleGetIssue(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueID, err := request.RequireString("issue_id")
+	if err != nil {
+		return mcp.NewToolResultError("missing required parameter: issue_id"), nil
+	}
+	issue, err := s.findIssue(ctx, issueID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	detail := map[string]any{"issue": s.issueOut(ctx, issue)}
+	if p, err := s.store.GetProject(ctx, issue.ProjectID); err == nil {
+		detail["project"] = p.Name
+	}
+	if issue.Assignee != "" {
+		detail["assignee"] = issue.Assignee
+	}
+	for key, at := range map[string]*time.Time{"started_at": issue.StartedAt, "completed_at": issue.CompletedAt, "closed_at": issue.ClosedAt, "merged_at": issue.MergedAt} {
+		if at != nil {
+			detail[key] = at.UTC().Format(time.RFC3339)
+		}
+	}
+	if issue.MergeCommit != "" {
+		detail["merge_commit"] = issue.MergeCommit
+		detail["merge_branch"] = issue.MergeBranch
+	}
+
+	sessions, err := s.store.ListAgentSessionsFiltered(ctx, store.SessionListFilter{IssueID: issue.ID})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list sessions: %v", err)), nil
+	}
+	sessionsOut := make([]map[string]any, 0, len(sessions))
+	for _, sess := range sessions {
+		entry := map[string]any{
+			"id":            sess.ID,
+			"code":          sess.Code,
+			"branch":        sess.Branch,
+			"status":        string(sess.Status),
+			"worktree_path": sess.WorktreePath,
+			"commit_count":  sess.CommitCount,
+			"started_at":    sess.StartedAt.UTC().Format(time.RFC3339),
+		}
+		if sess.EndedAt != nil {
+			entry["ended_at"] = sess.EndedAt.UTC().Format(time.RFC3339)
+		}
+		if sess.OutcomeClass != "" {
+			entry["outcome_class"] = string(sess.OutcomeClass)
+		}
+		if sess.MergeCommit != "" {
+			entry["merge_commit"] = sess.MergeCommit
+		}
+		if sess.BlockedReason != "" {
+			entry["blocked_reason"] = sess.BlockedReason
+		}
+		sessionsOut = append(sessionsOut, entry)
+	}
+	detail["sessions"] = sessionsOut
+
+	reviews, err := s.store.ListIssueReviews(ctx, issue.ID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list reviews: %v", err)), nil
+	}
+	reviewsOut := make([]map[string]any, 0, len(reviews))
+	for _, r := range reviews {
+		entry := map[string]any{
+			"id":                 r.ID,
+			"verdict":            string(r.Verdict),
+			"summary":            r.Summary,
+			"code_quality":       string(r.CodeQuality),
+			"requirements_match": string(r.RequirementsMatch),
+			"test_coverage":      string(r.TestCoverage),
+			"ui_ux":              string(r.UIUX),
+			"reviewed_at":        r.ReviewedAt.UTC().Format(time.RFC3339),
+		}
+		if r.SessionID != "" {
+			entry["session_id"] = r.SessionID
+		}
+		if r.Reviewer != "" {
+			entry["reviewer"] = r.Reviewer
+		}
+		if len(r.FailureReasons) > 0 {
+			entry["failure_reasons"] = r.FailureReasons
+		}
+		if len(r.Criteria) > 0 {
+			entry["criteria"] = criterionResultsOut(r.Criteria)
+		}
+		reviewsOut = append(reviewsOut, entry)
+	}
+	detail["reviews"] = reviewsOut
+
+	related := s.relatedIssues(ctx, issue.ID)
+	if related == nil {
+		related = []map[string]any{}
+	}
+	detail["related_issues"] = related
+
+	data, err := json.Marshal(detail)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal issue: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
 // pm_create_issue
 func (s *Server) createIssueTool() (mcp.Tool, server.ToolHandlerFunc) {
 	tool := mcp.NewTool("pm_create_issue",
-		mcp.WithDescription("Create a new issue for a project. By default, uses LLM to generate a description and ai_prompt if not provided. Returns the created issue as JSON."),
+		mcp.WithDescription("Create a new issue for a project. By default, uses LLM to generate a description, ai_prompt and acceptance criteria if not provided. Returns the created issue as JSON."),
 		mcp.WithString("project", mcp.Required(), mcp.Description("Project name")),
 		mcp.WithString("title", mcp.Required(), mcp.Description("Issue title")),
 		mcp.WithString("description", mcp.Description("Issue description")),
@@ -336,7 +629,10 @@ func (s *Server) createIssueTool() (mcp.Tool, server.ToolHandlerFunc) {
 		mcp.WithString("ai_prompt", mcp.Description("AI prompt providing guidance for AI agents working on this issue")),
 		mcp.WithString("type", mcp.Description("Issue type: feature, bug, chore (default: feature)")),
 		mcp.WithString("priority", mcp.Description("Issue priority: low, medium, high (default: medium)")),
-		mcp.WithString("enrich", mcp.Description("Set to 'false' to skip LLM enrichment (default: true)")),
+		mcp.WithString("assignee", mcp.Description("Person or agent to assign the issue to")),
+		mcp.WithString("estimate", mcp.Description("Size estimate, e.g. 2h, 3pt, M")),
+		mcp.WithString("acceptance_criteria", mcp.Description("Newline-separated list of testable conditions the work must meet; reviews check each one")),
+		mcp.WithString("enrich", mcp.Description("Set to 'false' to skip LLM enrichment (default: true). Over the project's monthly LLM budget, enrichment is skipped (reported in enrichment_skipped) or the call fails, per llm.budget.on_exceeded")),
 	)
 	return tool, s.handleCreateIssue
 }
@@ -356,8 +652,14 @@ func (s *Server) handleCreateIssue(ctx context.Context, request mcp.CallToolRequ
 		return mcp.NewToolResultError(fmt.Sprintf("project not found: %s", projectName)), nil
 	}
 
-	issueType := request.GetString("type", "feature")
-	priority := request.GetString("priority", "medium")
+	issueType, err := models.ParseIssueType(request.GetString("type", "feature"))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	priority, err := models.ParseIssuePriority(request.GetString("priority", "medium"))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	description := request.GetString("description", "")
 	body := request.GetString("body", "")
 	aiPrompt := request.GetString("ai_prompt", "")
@@ -370,30 +672,54 @@ func (s *Server) handleCreateIssue(ctx context.Context, request mcp.CallToolRequ
 		Body:        body,
 		AIPrompt:    aiPrompt,
 		Status:      models.IssueStatusOpen,
-		Priority:    models.IssuePriority(priority),
-		Type:        models.IssueType(issueType),
+		Priority:    priority,
+		Type:        issueType,
+		Assignee:    request.GetString("assignee", ""),
+		Estimate:    request.GetString("estimate", ""),
+
+		AcceptanceCriteria: splitLines(request.GetString("acceptance_criteria", "")),
 	}
 
-	// LLM enrichment (non-fatal)
+	// LLM enrichment (non-fatal, but skipped or refused over budget)
+	var usage *llm.Usage
+	var enrichSkipped string
 	if enrich != "false" && s.llm != nil {
-		enriched, enrichErr := s.llm.EnrichIssue(ctx, issue.Title, issue.Body, issue.Description)
-		if enrichErr == nil {
-			if issue.Description == "" && enriched.Description != "" {
-				issue.Description = enriched.Description
+		if err := s.budget.Check(ctx, s.store, p, time.Now()); err != nil {
+			if errors.Is(err, llmbudget.ErrExceeded) && !s.budget.Degrade() {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			enrichSkipped = err.Error()
+		} else {
+			enriched, enrichErr := s.llm.EnrichIssue(ctx, p.Name, p.MainLanguages(), issue.Title, issue.Body, issue.Description)
+			if enriched != nil {
+				usage = &enriched.Usage
 			}
-			if issue.AIPrompt == "" && enriched.AIPrompt != "" {
-				issue.AIPrompt = enriched.AIPrompt
+			if enrichErr == nil {
+				issue.Redactions = enriched.Redactions
+				if issue.Description == "" && enriched.Description != "" {
+					issue.Description = enriched.Description
+				}
+				if issue.AIPrompt == "" && enriched.AIPrompt != "" {
+					issue.AIPrompt = enriched.AIPrompt
+				}
+				if len(issue.AcceptanceCriteria) == 0 {
+					issue.AcceptanceCriteria = enriched.AcceptanceCriteria
+				}
 			}
+			// Silently ignore enrichment errors — issue will still be created
 		}
-		// Silently ignore enrichment errors — issue will still be created
 	}
 
 	if err := s.store.CreateIssue(ctx, issue); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to create issue: %v", err)), nil
 	}
+	if usage != nil {
+		_ = s.store.RecordLLMUsage(ctx, usage.Record(p.ID, issue.ID, models.LLMUsageEnrichment))
+	}
 
 	result := map[string]any{
 		"id":          issue.ID,
+		"code":        issue.Code,
 		"project_id":  p.ID,
 		"project":     p.Name,
 		"title":       issue.Title,
@@ -403,7 +729,14 @@ func (s *Server) handleCreateIssue(ctx context.Context, request mcp.CallToolRequ
 		"status":      string(issue.Status),
 		"priority":    string(issue.Priority),
 		"type":        string(issue.Type),
-		"created_at":  issue.CreatedAt.Format(time.RFC3339),
+		"assignee":    issue.Assignee,
+		"estimate":    issue.Estimate,
+		"created_at":  issue.CreatedAt.UTC().Format(time.RFC3339),
+
+		"acceptance_criteria": issue.AcceptanceCriteria,
+	}
+	if enrichSkipped != "" {
+		result["enrichment_skipped"] = enrichSkipped
 	}
 
 	data, err := json.Marshal(result)
@@ -417,13 +750,16 @@ func (s *Server) handleCreateIssue(ctx context.Context, request mcp.CallToolRequ
 func (s *Server) updateIssueTool() (mcp.Tool, server.ToolHandlerFunc) {
 	tool := mcp.NewTool("pm_update_issue",
 		mcp.WithDescription("Update an existing issue. Provide the issue ID (full or prefix) and at least one field to update. Returns the updated issue as JSON."),
-		mcp.WithString("issue_id", mcp.Required(), mcp.Description("Issue ID (full ULID or unique prefix)")),
-		mcp.WithString("status", mcp.Description("New status: open, in_progress, done, closed")),
+		mcp.WithString("issue_id", mcp.Required(), mcp.Description("Issue short code (e.g. PM-12), full ULID or unique prefix")),
+		mcp.WithString("status", mcp.Description("New status: open, in_progress, in_review, done, closed")),
 		mcp.WithString("title", mcp.Description("New title")),
 		mcp.WithString("description", mcp.Description("New description")),
 		mcp.WithString("body", mcp.Description("New body text")),
 		mcp.WithString("ai_prompt", mcp.Description("New AI prompt (guidance for AI agents)")),
 		mcp.WithString("priority", mcp.Description("New priority: low, medium, high")),
+		mcp.WithString("assignee", mcp.Description("New assignee (empty string to unassign)")),
+		mcp.WithString("estimate", mcp.Description("New size estimate, e.g. 2h, 3pt, M (empty string to clear)")),
+		mcp.WithString("acceptance_criteria", mcp.Description("Newline-separated acceptance criteria, replacing the current ones (empty string to clear)")),
 	)
 	return tool, s.handleUpdateIssue
 }
@@ -443,7 +779,9 @@ func (s *Server) handleUpdateIssue(ctx context.Context, request mcp.CallToolRequ
 	updated := false
 
 	if status := request.GetString("status", ""); status != "" {
-		issue.Status = models.IssueStatus(status)
+		if issue.Status, err = models.ParseIssueStatus(status); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 		updated = true
 		// Set ClosedAt when closing
 		if status == string(models.IssueStatusClosed) || status == string(models.IssueStatusDone) {
@@ -468,12 +806,26 @@ func (s *Server) handleUpdateIssue(ctx context.Context, request mcp.CallToolRequ
 		updated = true
 	}
 	if priority := request.GetString("priority", ""); priority != "" {
-		issue.Priority = models.IssuePriority(priority)
+		if issue.Priority, err = models.ParseIssuePriority(priority); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		updated = true
+	}
+	if assignee, ok := request.GetArguments()["assignee"].(string); ok {
+		issue.Assignee = assignee
+		updated = true
+	}
+	if estimate, ok := request.GetArguments()["estimate"].(string); ok {
+		issue.Estimate = estimate
+		updated = true
+	}
+	if criteria, ok := request.GetArguments()["acceptance_criteria"].(string); ok {
+		issue.AcceptanceCriteria = splitLines(criteria)
 		updated = true
 	}
 
 	if !updated {
-		return mcp.NewToolResultError("no fields provided to update; specify at least one of: status, title, description, body, ai_prompt, priority"), nil
+		return mcp.NewToolResultError("no fields provided to update; specify at least one of: status, title, description, body, ai_prompt, priority, assignee, estimate, acceptance_criteria"), nil
 	}
 
 	if err := s.store.UpdateIssue(ctx, issue); err != nil {
@@ -482,6 +834,7 @@ func (s *Server) handleUpdateIssue(ctx context.Context, request mcp.CallToolRequ
 
 	result := map[string]any{
 		"id":          issue.ID,
+		"code":        issue.Code,
 		"project_id":  issue.ProjectID,
 		"title":       issue.Title,
 		"description": issue.Description,
@@ -490,7 +843,11 @@ func (s *Server) handleUpdateIssue(ctx context.Context, request mcp.CallToolRequ
 		"status":      string(issue.Status),
 		"priority":    string(issue.Priority),
 		"type":        string(issue.Type),
-		"updated_at":  issue.UpdatedAt.Format(time.RFC3339),
+		"assignee":    issue.Assignee,
+		"estimate":    issue.Estimate,
+		"updated_at":  issue.UpdatedAt.UTC().Format(time.RFC3339),
+
+		"acceptance_criteria": issue.AcceptanceCriteria,
 	}
 
 	data, err := json.Marshal(result)
@@ -524,23 +881,19 @@ func (s *Server) handleHealthScore(ctx context.Context, request mcp.CallToolRequ
 	meta := &health.ProjectMetadata{}
 
 	if s.git != nil && p.Path != "" {
-		meta.IsDirty, _ = s.git.IsDirty(p.Path)
-		meta.LastCommitDate, _ = s.git.LastCommitDate(p.Path)
-		branches, _ := s.git.BranchList(p.Path)
+		meta.IsDirty, _ = s.git.IsDirty(ctx, p.Path)
+		meta.LastCommitDate, _ = s.git.LastCommitDate(ctx, p.Path)
+		branches, _ := s.git.BranchList(ctx, p.Path)
 		meta.BranchCount = len(branches)
-		worktrees, _ := s.git.WorktreeList(p.Path)
+		worktrees, _ := s.git.WorktreeList(ctx, p.Path)
 		meta.WorktreeCount = len(worktrees)
 	}
 
-	// Try to get release info
-	if s.gh != nil && p.RepoURL != "" {
-		if owner, repo, err := git.ExtractOwnerRepo(p.RepoURL); err == nil {
-			if rel, err := s.gh.LatestRelease(owner, repo); err == nil {
-				meta.LatestRelease = rel.TagName
-				if t, err := time.Parse(time.RFC3339, rel.PublishedAt); err == nil {
-					meta.ReleaseDate = t
-				}
-			}
+	// Stored release info
+	if rel := releases.Cached(ctx, s.store, s.gh, p, s.releaseSchedule); rel != nil {
+		meta.LatestRelease = rel.TagName
+		if rel.PublishedAt != nil {
+			meta.ReleaseDate = *rel.PublishedAt
 		}
 	}
 
@@ -559,11 +912,11 @@ func (s *Server) handleHealthScore(ctx context.Context, request mcp.CallToolRequ
 		},
 		"metadata": map[string]any{
 			"is_dirty":        meta.IsDirty,
-			"last_commit":     meta.LastCommitDate.Format(time.RFC3339),
+			"last_commit":     meta.LastCommitDate.UTC().Format(time.RFC3339),
 			"branch_count":    meta.BranchCount,
 			"worktree_count":  meta.WorktreeCount,
 			"latest_release":  meta.LatestRelease,
-			"release_date":    meta.ReleaseDate.Format(time.RFC3339),
+			"release_date":    meta.ReleaseDate.UTC().Format(time.RFC3339),
 		},
 	}
 
@@ -581,6 +934,10 @@ func (s *Server) launchAgentTool() (mcp.Tool, server.ToolHandlerFunc) {
 		mcp.WithString("project", mcp.Required(), mcp.Description("Project name")),
 		mcp.WithString("issue_id", mcp.Description("Issue ID to work on (generates branch name from title)")),
 		mcp.WithString("branch", mcp.Description("Branch name (auto-generated from issue if not specified)")),
+		mcp.WithString("snapshot_human_edits", mcp.Description("Set to 'true' to commit edits made in an idle session's worktree before resuming it (default: false)")),
+		mcp.WithString("sparse", mcp.Description("Set to 'true' to create a sparse-checkout worktree limited to the project's sparse paths and paths the issue mentions (default: only when the project configures sparse paths)")),
+		mcp.WithString("sparse_paths", mcp.Description("Comma-separated directories to add to a sparse-checkout worktree (implies sparse)")),
+		mcp.WithString("labels", mcp.Description("Comma-separated labels for the session, e.g. 'experiment,spike-2024Q3', to group and compare sessions apart from issues")),
 	)
 	return tool, s.handleLaunchAgent
 }
@@ -600,8 +957,9 @@ func (s *Server) handleLaunchAgent(ctx context.Context, request mcp.CallToolRequ
 	branch := request.GetString("branch", "")
 
 	// If issue_id is provided, resolve the issue and optionally derive the branch name
+	var issue *models.Issue
 	if issueID != "" {
-		issue, err := s.findIssue(ctx, issueID)
+		issue, err = s.findIssue(ctx, issueID)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("issue not found: %s", issueID)), nil
 		}
@@ -610,12 +968,6 @@ func (s *Server) handleLaunchAgent(ctx context.Context, request mcp.CallToolRequ
 		if branch == "" {
 			branch = issueToBranch(issue.Title)
 		}
-
-		// Mark issue as in_progress
-		issue.Status = models.IssueStatusInProgress
-		if err := s.store.UpdateIssue(ctx, issue); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to update issue status: %v", err)), nil
-		}
 	}
 
 	if branch == "" {
@@ -627,32 +979,83 @@ func (s *Server) handleLaunchAgent(ctx context.Context, request mcp.CallToolRequ
 	worktreeDirname := branchParts[len(branchParts)-1]
 	worktreePath := filepath.Join(p.Path+".worktrees", worktreeDirname)
 
-	// Check for existing idle session on this branch
+	// Check WIP limits against the work this launch would start
 	existingSessions, _ := s.store.ListAgentSessions(ctx, p.ID, 0)
+	if err := agent.CheckBranchNotBlocked(existingSessions, branch); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := agent.CheckBranchNotInline(existingSessions, branch); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := agent.CheckBranchNotActive(existingSessions, branch); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if issue != nil {
+		if err := agent.CheckIssueNotHeld(existingSessions, issue.ID, s.issueLockExpiry, time.Now()); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	addSessions, addIssues := 1, 0
+	for _, sess := range existingSessions {
+		if sess.Branch == branch && sess.Status == models.SessionStatusIdle {
+			addSessions = 0
+		}
+	}
+	if issue != nil && issue.Status != models.IssueStatusInProgress {
+		addIssues = 1
+	}
+	wip, err := agent.CheckWIP(ctx, s.store, p, addIssues, addSessions)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to check WIP limits: %v", err)), nil
+	}
+	if wip.Blocked {
+		return mcp.NewToolResultError(fmt.Sprintf("launch blocked by WIP limits: %s", strings.Join(wip.Warnings, "; "))), nil
+	}
+	if issue != nil {
+		if err := agent.ReleaseExpiredIssueLocks(ctx, s.store, existingSessions, []string{issue.ID}, s.issueLockExpiry, time.Now()); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	// Mark issue as in_progress
+	if issue != nil {
+		issue.Status = models.IssueStatusInProgress
+		if err := s.store.UpdateIssue(ctx, issue); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to update issue status: %v", err)), nil
+		}
+	}
+
+	// Check for existing idle session on this branch
 	for _, sess := range existingSessions {
 		if sess.Branch == branch && sess.Status == models.SessionStatusIdle {
+			// Detect edits made in the worktree while the session was idle
+			coEdit, err := agent.PrepareResume(ctx, sess, s.git, request.GetString("snapshot_human_edits", "") == "true")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 			// Open iTerm window via wt open
 			if s.wt != nil {
 				if err := s.wt.Create(p.Path, branch); err != nil {
 					return mcp.NewToolResultError(fmt.Sprintf("wt open: %v", err)), nil
 				}
+				agent.RecordWTTerminal(sess, s.wt)
 			}
-			sess.Status = models.SessionStatusActive
-			now := time.Now().UTC()
-			sess.LastActiveAt = &now
-			if err := s.store.UpdateAgentSession(ctx, sess); err != nil {
+			sess.EditLabels([]string{request.GetString("labels", "")}, nil)
+			if err := agent.Transition(ctx, s.store, sess, models.SessionStatusActive, agent.CauseLaunch); err != nil {
 				return mcp.NewToolResultError(fmt.Sprintf("failed to reactivate session %s: %v", sess.ID, err)), nil
 			}
 			command := fmt.Sprintf("cd %s && claude", sess.WorktreePath)
 			if issueID != "" {
-				shortIssueID := issueID
-				if len(shortIssueID) > 12 {
-					shortIssueID = shortIssueID[:12]
+				shortIssueID := issue.Code
+				if shortIssueID == "" {
+					shortIssueID = issueID[:min(len(issueID), 12)]
 				}
-				command = fmt.Sprintf(`cd %s && claude "Use pm MCP tools to look up issue %s and implement it. Update the issue status when complete."`, sess.WorktreePath, shortIssueID)
+				prompt := agent.WithLanguageGuidance(fmt.Sprintf("Use pm MCP tools to look up issue %s and implement it. Update the issue status when complete.", shortIssueID), p.MainLanguages())
+				command = fmt.Sprintf(`cd %s && claude "%s"`, sess.WorktreePath, prompt)
 			}
 			result := map[string]any{
 				"session_id":    sess.ID,
+				"code":          sess.Code,
 				"project":       p.Name,
 				"branch":        branch,
 				"worktree_path": sess.WorktreePath,
@@ -660,6 +1063,11 @@ func (s *Server) handleLaunchAgent(ctx context.Context, request mcp.CallToolRequ
 				"status":        string(sess.Status),
 				"resumed":       true,
 				"command":       command,
+				"conventions":   s.projectConventions(ctx, p),
+				"wip_warnings":  wip.Warnings,
+			}
+			if coEdit != nil {
+				result["co_edit"] = coEdit
 			}
 			data, _ := json.Marshal(result)
 			return mcp.NewToolResultText(string(data)), nil
@@ -670,7 +1078,13 @@ func (s *Server) handleLaunchAgent(ctx context.Context, request mcp.CallToolRequ
 	if s.wt == nil {
 		return mcp.NewToolResultError("worktree client not available"), nil
 	}
-	if err := s.wt.Create(p.Path, branch); err != nil {
+	var issues []*models.Issue
+	if issue != nil {
+		issues = append(issues, issue)
+	}
+	sparse := agent.LaunchSparsePaths(p, issues, request.GetString("sparse", "") == "true", agent.ParseSparsePaths(request.GetString("sparse_paths", "")))
+	sparse, err = s.wt.CreateWith(p.Path, branch, wt.CreateOptions{SparsePaths: sparse})
+	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to create worktree: %v", err)), nil
 	}
 
@@ -681,8 +1095,11 @@ func (s *Server) handleLaunchAgent(ctx context.Context, request mcp.CallToolRequ
 		Branch:       branch,
 		WorktreePath: worktreePath,
 		Status:       models.SessionStatusActive,
-		StartedAt:    time.Now(),
+		StartedAt:    time.Now().UTC(),
+		SparsePaths:  strings.Join(sparse, ","),
 	}
+	session.EditLabels([]string{request.GetString("labels", "")}, nil)
+	agent.RecordWTTerminal(session, s.wt)
 	if err := s.store.CreateAgentSession(ctx, session); err != nil {
 		// Non-fatal: worktree was already created
 		return mcp.NewToolResultError(fmt.Sprintf("worktree created but session recording failed: %v", err)), nil
@@ -690,21 +1107,31 @@ func (s *Server) handleLaunchAgent(ctx context.Context, request mcp.CallToolRequ
 
 	command := fmt.Sprintf("cd %s && claude", worktreePath)
 	if issueID != "" {
-		shortIssueID := issueID
-		if len(shortIssueID) > 12 {
-			shortIssueID = shortIssueID[:12]
+		shortIssueID := issue.Code
+		if shortIssueID == "" {
+			shortIssueID = issueID[:min(len(issueID), 12)]
 		}
-		command = fmt.Sprintf(`cd %s && claude "Use pm MCP tools to look up issue %s and implement it. Update the issue status when complete."`, worktreePath, shortIssueID)
+		prompt := agent.WithLanguageGuidance(fmt.Sprintf("Use pm MCP tools to look up issue %s and implement it. Update the issue status when complete.", shortIssueID), p.MainLanguages())
+		command = fmt.Sprintf(`cd %s && claude "%s"`, worktreePath, prompt)
 	}
 
 	result := map[string]any{
 		"session_id":    session.ID,
+		"code":          session.Code,
 		"project":       p.Name,
 		"branch":        branch,
 		"worktree_path": worktreePath,
 		"issue_id":      issueID,
 		"status":        string(session.Status),
 		"command":        command,
+		"conventions":   s.projectConventions(ctx, p),
+		"wip_warnings":  wip.Warnings,
+	}
+	if len(sparse) > 0 {
+		result["sparse_paths"] = sparse
+	}
+	if labels := session.LabelList(); len(labels) > 0 {
+		result["labels"] = labels
 	}
 
 	data, err := json.Marshal(result)
@@ -717,9 +1144,11 @@ func (s *Server) handleLaunchAgent(ctx context.Context, request mcp.CallToolRequ
 // pm_close_agent
 func (s *Server) closeAgentTool() (mcp.Tool, server.ToolHandlerFunc) {
 	tool := mcp.NewTool("pm_close_agent",
-		mcp.WithDescription("Close an agent session. Default transitions to idle. Use status=completed to mark done (issues → done) or status=abandoned to abandon (issues → open)."),
-		mcp.WithString("session_id", mcp.Required(), mcp.Description("Session ID to close")),
-		mcp.WithString("status", mcp.Description("Target status: idle (default), completed, abandoned")),
+		mcp.WithDescription("Close an agent session. Default transitions to idle. Use status=completed to mark done (issues → done), status=abandoned to abandon (issues → open), or status=blocked with a reason when the work is waiting on something outside the session (a design, credentials, an answer). Closing a blocked session as idle unblocks it. Completing a live session returns close_check: warnings plus the worktree's uncommitted files (dirty_files, with line counts) and whether they are only lockfiles (dirty_kind)."),
+		mcp.WithString("session_id", mcp.Required(), mcp.Description("Session short code (e.g. PM-S3) or ID to close")),
+		mcp.WithString("status", mcp.Description("Target status: idle (default), completed, abandoned, blocked")),
+		mcp.WithString("reason", mcp.Description("What the session is waiting on (required for status=blocked)")),
+		mcp.WithString("unblock_condition", mcp.Description("What has to happen before work can resume (optional, for status=blocked)")),
 	)
 	return tool, s.handleCloseAgent
 }
@@ -729,34 +1158,59 @@ func (s *Server) handleCloseAgent(ctx context.Context, request mcp.CallToolReque
 	if err != nil {
 		return mcp.NewToolResultError("missing required parameter: session_id"), nil
 	}
+	if sess, err := s.findSession(ctx, sessionID); err == nil {
+		sessionID = sess.ID
+	}
 
 	targetStr := request.GetString("status", "idle")
 	target := models.SessionStatus(targetStr)
 
 	switch target {
 	case models.SessionStatusIdle, models.SessionStatusCompleted, models.SessionStatusAbandoned:
+	case models.SessionStatusBlocked:
+		if strings.TrimSpace(request.GetString("reason", "")) == "" {
+			return mcp.NewToolResultError("reason is required when status is blocked"), nil
+		}
 	default:
-		return mcp.NewToolResultError(fmt.Sprintf("invalid status: %s (must be idle, completed, or abandoned)", targetStr)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("invalid status: %s (must be idle, completed, abandoned, or blocked)", targetStr)), nil
 	}
 
 	// Enrich session with git info before closing; capture worktree path for cleanup
 	var worktreePath string
 	var projectPath string
+	var check *agent.CloseCheck
 	if sess, err := s.store.GetAgentSession(ctx, sessionID); err == nil {
-		worktreePath = sess.WorktreePath
-		agent.EnrichSessionWithGitInfo(sess, s.git)
-		_ = s.store.UpdateAgentSession(ctx, sess)
+		if !sess.Inline {
+			worktreePath = sess.WorktreePath
+		}
 		// Look up project path for lifecycle operations
-		if proj, projErr := s.store.GetProject(ctx, sess.ProjectID); projErr == nil {
+		proj, projErr := s.store.GetProject(ctx, sess.ProjectID)
+		if projErr == nil {
 			projectPath = proj.Path
 		}
+		// Report what completing leaves behind (uncommitted files, unmerged commits)
+		if target == models.SessionStatusCompleted && sess.Status.IsOpen() {
+			check = agent.CheckClose(ctx, sess, proj, s.git)
+		}
+		agent.EnrichSessionWithGitInfo(ctx, sess, s.git)
+		_ = s.store.UpdateAgentSession(ctx, sess)
 	}
 
-	session, err := agent.CloseSession(ctx, s.store, sessionID, target)
+	var session *models.AgentSession
+	if target == models.SessionStatusBlocked {
+		session, err = agent.BlockSession(ctx, s.store, sessionID, request.GetString("reason", ""), request.GetString("unblock_condition", ""))
+	} else {
+		session, err = agent.CloseSession(ctx, s.store, sessionID, target)
+	}
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	// Remember the worktree state so a later resume can detect outside edits
+	if target != models.SessionStatusAbandoned {
+		_ = agent.RecordWorktreeFingerprint(ctx, s.store, session, s.git)
+	}
+
 	// For abandoned: full worktree teardown via lifecycle (close iTerm + remove worktree + untrust + cleanup state)
 	if worktreePath != "" && target == models.SessionStatusAbandoned && s.wt != nil && projectPath != "" {
 		lm := s.wt.LifecycleForRepo(projectPath)
@@ -768,10 +1222,20 @@ func (s *Server) handleCloseAgent(ctx context.Context, request mcp.CallToolReque
 
 	result := map[string]any{
 		"session_id": session.ID,
+		"code":       session.Code,
 		"status":     string(session.Status),
 	}
 	if session.EndedAt != nil {
-		result["ended_at"] = session.EndedAt.Format(time.RFC3339)
+		result["ended_at"] = session.EndedAt.UTC().Format(time.RFC3339)
+	}
+	if session.Status == models.SessionStatusBlocked {
+		result["blocked_reason"] = session.BlockedReason
+		if session.UnblockCondition != "" {
+			result["unblock_condition"] = session.UnblockCondition
+		}
+	}
+	if check != nil {
+		result["close_check"] = check
 	}
 
 	data, _ := json.Marshal(result)
@@ -782,7 +1246,7 @@ func (s *Server) handleCloseAgent(ctx context.Context, request mcp.CallToolReque
 func (s *Server) syncSessionTool() (mcp.Tool, server.ToolHandlerFunc) {
 	tool := mcp.NewTool("pm_sync_session",
 		mcp.WithDescription("Sync a session's worktree with the base branch. Fetches latest changes and merges/rebases the base branch into the feature branch."),
-		mcp.WithString("session_id", mcp.Required(), mcp.Description("Session ID to sync")),
+		mcp.WithString("session_id", mcp.Required(), mcp.Description("Session short code (e.g. PM-S3) or ID to sync")),
 		mcp.WithString("rebase", mcp.Description("Set to 'true' to rebase instead of merge (default: false)")),
 		mcp.WithString("force", mcp.Description("Set to 'true' to skip dirty worktree check (default: false)")),
 		mcp.WithString("dry_run", mcp.Description("Set to 'true' for dry-run mode (default: false)")),
@@ -795,6 +1259,9 @@ func (s *Server) handleSyncSession(ctx context.Context, request mcp.CallToolRequ
 	if err != nil {
 		return mcp.NewToolResultError("missing required parameter: session_id"), nil
 	}
+	if sess, err := s.findSession(ctx, sessionID); err == nil {
+		sessionID = sess.ID
+	}
 
 	opts := sessions.SyncOptions{
 		Rebase: request.GetString("rebase", "") == "true",
@@ -814,14 +1281,16 @@ func (s *Server) handleSyncSession(ctx context.Context, request mcp.CallToolRequ
 // pm_merge_session
 func (s *Server) mergeSessionTool() (mcp.Tool, server.ToolHandlerFunc) {
 	tool := mcp.NewTool("pm_merge_session",
-		mcp.WithDescription("Merge a session's feature branch into the base branch. Can perform local merge or create a PR. After a successful local merge, automatically cleans up the worktree, branch, and iTerm window unless cleanup is disabled."),
-		mcp.WithString("session_id", mcp.Required(), mcp.Description("Session ID to merge")),
+		mcp.WithDescription("Merge a session's feature branch into the base branch. Can perform local merge or create a PR. After a successful local merge, closes the issues the merged commits reference (\"Fixes #12\", \"Closes PM-<id>\") and automatically cleans up the worktree, branch, and iTerm window unless cleanup is disabled."),
+		mcp.WithString("session_id", mcp.Required(), mcp.Description("Session short code (e.g. PM-S3) or ID to merge")),
 		mcp.WithString("base_branch", mcp.Description("Target branch (default: main)")),
+		mcp.WithString("strategy", mcp.Description("Merge strategy: merge, rebase, squash (one commit built from the issues) or ff-only (default: the project's default, else merge)")),
 		mcp.WithString("rebase", mcp.Description("Set to 'true' to rebase instead of merge")),
 		mcp.WithString("create_pr", mcp.Description("Set to 'true' to create a PR instead of local merge")),
 		mcp.WithString("force", mcp.Description("Set to 'true' to skip safety checks")),
 		mcp.WithString("dry_run", mcp.Description("Set to 'true' for dry-run mode")),
 		mcp.WithString("cleanup", mcp.Description("Set to 'false' to skip post-merge cleanup of worktree, branch, and iTerm window (default: true)")),
+		mcp.WithString("delete_remote", mcp.Description("Set to 'true' to also delete the remote branch the session's branch tracks during cleanup. Confirm with the user first")),
 	)
 	return tool, s.handleMergeSession
 }
@@ -831,17 +1300,27 @@ func (s *Server) handleMergeSession(ctx context.Context, request mcp.CallToolReq
 	if err != nil {
 		return mcp.NewToolResultError("missing required parameter: session_id"), nil
 	}
+	if sess, err := s.findSession(ctx, sessionID); err == nil {
+		sessionID = sess.ID
+	}
 
 	// Default cleanup to true unless explicitly set to "false"
 	cleanup := request.GetString("cleanup", "") != "false"
 
+	strategy, err := sessions.ParseMergeStrategy(request.GetString("strategy", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	opts := sessions.MergeOptions{
-		BaseBranch: request.GetString("base_branch", ""),
-		Rebase:     request.GetString("rebase", "") == "true",
-		CreatePR:   request.GetString("create_pr", "") == "true",
-		Force:      request.GetString("force", "") == "true",
-		DryRun:     request.GetString("dry_run", "") == "true",
-		Cleanup:    cleanup,
+		BaseBranch:   request.GetString("base_branch", ""),
+		Strategy:     strategy,
+		Rebase:       request.GetString("rebase", "") == "true",
+		CreatePR:     request.GetString("create_pr", "") == "true",
+		Force:        request.GetString("force", "") == "true",
+		DryRun:       request.GetString("dry_run", "") == "true",
+		Cleanup:      cleanup,
+		DeleteRemote: request.GetString("delete_remote", "") == "true",
 	}
 
 	result, err := s.sessions.MergeSession(ctx, sessionID, opts)
@@ -857,8 +1336,10 @@ func (s *Server) handleMergeSession(ctx context.Context, request mcp.CallToolReq
 func (s *Server) deleteWorktreeTool() (mcp.Tool, server.ToolHandlerFunc) {
 	tool := mcp.NewTool("pm_delete_worktree",
 		mcp.WithDescription("Delete a session's worktree. Marks the session as abandoned."),
-		mcp.WithString("session_id", mcp.Required(), mcp.Description("Session ID whose worktree to delete")),
+		mcp.WithString("session_id", mcp.Required(), mcp.Description("Session short code (e.g. PM-S3) or ID whose worktree to delete")),
 		mcp.WithString("force", mcp.Description("Set to 'true' to force removal even with uncommitted changes")),
+		mcp.WithString("delete_branch", mcp.Description("Set to 'true' to also delete the session's local branch")),
+		mcp.WithString("delete_remote", mcp.Description("Set to 'true' to also delete the remote branch the session's branch tracks. Confirm with the user first")),
 	)
 	return tool, s.handleDeleteWorktree
 }
@@ -868,10 +1349,16 @@ func (s *Server) handleDeleteWorktree(ctx context.Context, request mcp.CallToolR
 	if err != nil {
 		return mcp.NewToolResultError("missing required parameter: session_id"), nil
 	}
+	if sess, err := s.findSession(ctx, sessionID); err == nil {
+		sessionID = sess.ID
+	}
 
-	force := request.GetString("force", "") == "true"
-
-	if err := s.sessions.DeleteWorktree(ctx, sessionID, force); err != nil {
+	cleanup, err := s.sessions.DeleteWorktree(ctx, sessionID, sessions.CleanupOptions{
+		Force:        request.GetString("force", "") == "true",
+		DeleteBranch: request.GetString("delete_branch", "") == "true",
+		DeleteRemote: request.GetString("delete_remote", "") == "true",
+	})
+	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("delete worktree failed: %v", err)), nil
 	}
 
@@ -879,6 +1366,7 @@ func (s *Server) handleDeleteWorktree(ctx context.Context, request mcp.CallToolR
 		"session_id": sessionID,
 		"status":     "abandoned",
 		"message":    "worktree deleted successfully",
+		"cleanup":    cleanup,
 	}
 	data, _ := json.Marshal(result)
 	return mcp.NewToolResultText(string(data)), nil
@@ -913,6 +1401,7 @@ func (s *Server) handleDiscoverWorktrees(ctx context.Context, request mcp.CallTo
 	for _, sess := range discovered {
 		out = append(out, map[string]any{
 			"session_id":    sess.ID,
+			"code":          sess.Code,
 			"branch":        sess.Branch,
 			"worktree_path": sess.WorktreePath,
 			"status":        string(sess.Status),
@@ -931,11 +1420,16 @@ func (s *Server) handleDiscoverWorktrees(ctx context.Context, request mcp.CallTo
 // pm_prepare_review
 func (s *Server) prepareReviewTool() (mcp.Tool, server.ToolHandlerFunc) {
 	tool := mcp.NewTool("pm_prepare_review",
-		mcp.WithDescription("Gather all context needed to review an issue's implementation. Returns issue requirements, git diff, changed files, UI review flags, and review history. The calling agent should analyze this context and then call pm_save_review with the verdict."),
-		mcp.WithString("issue_id", mcp.Required(), mcp.Description("Issue ID (full ULID or unique prefix)")),
+		mcp.WithDescription("Gather all context needed to review an issue's implementation. Returns issue requirements, its numbered acceptance criteria (check each one and record the results in pm_save_review's criteria), a summary of the git diff (diff_stats, and per-file additions/deletions/bytes in file_diffs; pass file to fetch one file's diff, or include_diff for the whole diff), changed files, whether a UI review is needed (ui_review_needed, with the project's rules and the files each matched in ui_review), project conventions (AGENTS.md/CLAUDE.md/CONVENTIONS.md), related issues (e.g. the bug that caused a regression), review history and, with include_blame, who last touched the code around each change and how long it has been stable. File paths are from the repository root; project.subpath is the project's directory within it for a project in a monorepo. The calling agent should analyze this context and then call pm_save_review with the verdict."),
+		mcp.WithString("issue_id", mcp.Required(), mcp.Description("Issue short code (e.g. PM-12), full ULID or unique prefix")),
 		mcp.WithString("base_ref", mcp.Description("Base ref for diff (default: main, or auto-detected from session branch)")),
 		mcp.WithString("head_ref", mcp.Description("Head ref for diff (default: session branch, or HEAD)")),
 		mcp.WithString("app_url", mcp.Description("URL of running app for UI/UX review via rodney (e.g. http://localhost:3000)")),
+		mcp.WithString("include_blame", mcp.Description("Set to 'true' to include blame summaries for the code surrounding each change (default: false)")),
+		mcp.WithString("pre_analysis", mcp.Description("Set to 'true' to include an LLM pre-analysis of the diff in pre_analysis: a summary, risk areas, files deserving scrutiny and missing tests, as hints to check rather than findings. Its token usage is recorded; over the project's monthly LLM budget it is skipped (reported in pre_analysis_error) or the call fails, per llm.budget.on_exceeded. Also lists untested_files, changed source files whose tests did not change (default: 'false', or review.pre_analysis)")),
+		mcp.WithString("file", mcp.Description("Return only this changed file's diff instead of the full review context: a path from file_diffs, or one relative to the session worktree or project directory, or absolute")),
+		mcp.WithString("include_diff", mcp.Description("Set to 'true' to inline the whole diff in diff, capped at max_diff_bytes with a truncation marker (default: false; fetch files one at a time with file instead)")),
+		mcp.WithString("max_diff_bytes", mcp.Description("Cap on returned diff size in bytes as string, for file and include_diff (default: '50000'; '0' = no cap)")),
 	)
 	return tool, s.handlePrepareReview
 }
@@ -976,23 +1470,63 @@ func (s *Server) handlePrepareReview(ctx context.Context, request mcp.CallToolRe
 		headRef = "HEAD"
 	}
 
+	maxDiff, err := strconv.Atoi(request.GetString("max_diff_bytes", strconv.Itoa(defaultReviewDiffBytes)))
+	if err != nil || maxDiff < 0 {
+		return mcp.NewToolResultError("max_diff_bytes must be a non-negative integer"), nil
+	}
+
+	// Agents may name files relative to their worktree or the project
+	// directory; git reports them from the repository root.
+	worktree := ""
+	if session != nil {
+		worktree = session.WorktreePath
+	}
+	paths := git.ResolveRepoPaths(ctx, s.git, project.Path, worktree)
+
 	// Get diff (best-effort)
 	var diff, diffStat string
 	var filesChanged []string
 	if s.git != nil && project.Path != "" {
-		diff, _ = s.git.Diff(project.Path, baseRef, headRef)
-		diffStat, _ = s.git.DiffStat(project.Path, baseRef, headRef)
-		filesChanged, _ = s.git.DiffNameOnly(project.Path, baseRef, headRef)
+		diff, _ = s.git.Diff(ctx, project.Path, baseRef, headRef)
+		diffStat, _ = s.git.DiffStat(ctx, project.Path, baseRef, headRef)
+		filesChanged, _ = s.git.DiffNameOnly(ctx, project.Path, baseRef, headRef)
 	}
+	fileDiffs := git.SplitDiff(diff)
 
-	// Check if UI review is needed
-	uiReviewNeeded := false
+	if file := request.GetString("file", ""); file != "" {
+		file = paths.Normalize(file)
+		for _, fd := range fileDiffs {
+			if fd.File != file {
+				continue
+			}
+			patch, truncated := git.TruncateDiff(fd.Patch, maxDiff)
+			data, err := json.Marshal(map[string]any{
+				"issue_id":       issue.ID,
+				"file":           fd.File,
+				"additions":      fd.Additions,
+				"deletions":      fd.Deletions,
+				"diff":           patch,
+				"diff_truncated": truncated,
+			})
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to marshal file diff: %v", err)), nil
+			}
+			return mcp.NewToolResultText(string(data)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("file not in diff %s...%s: %s", baseRef, headRef, file)), nil
+	}
+	// Check if UI review is needed, by the project's rules
+	uiRules := uireview.Rules(project)
+	var projectFiles []string
 	for _, f := range filesChanged {
-		if strings.HasPrefix(f, "ui/") || strings.HasPrefix(f, "internal/ui/") {
-			uiReviewNeeded = true
-			break
+		if f, ok := paths.ProjectRelative(f); ok {
+			projectFiles = append(projectFiles, f)
 		}
 	}
+	uiMatches := uireview.Classify(uiRules, projectFiles)
+	if uiMatches == nil {
+		uiMatches = []uireview.Match{}
+	}
 
 	// Build UI context
 	appURL := request.GetString("app_url", "")
@@ -1007,11 +1541,15 @@ func (s *Server) handlePrepareReview(ctx context.Context, request mcp.CallToolRe
 	reviews, _ := s.store.ListIssueReviews(ctx, issue.ID)
 	var reviewHistory []map[string]any
 	for _, r := range reviews {
-		reviewHistory = append(reviewHistory, map[string]any{
+		entry := map[string]any{
 			"verdict":     string(r.Verdict),
 			"summary":     r.Summary,
-			"reviewed_at": r.ReviewedAt.Format(time.RFC3339),
-		})
+			"reviewed_at": r.ReviewedAt.UTC().Format(time.RFC3339),
+		}
+		if len(r.Criteria) > 0 {
+			entry["criteria"] = criterionResultsOut(r.Criteria)
+		}
+		reviewHistory = append(reviewHistory, entry)
 	}
 
 	// Build session info
@@ -1019,15 +1557,23 @@ func (s *Server) handlePrepareReview(ctx context.Context, request mcp.CallToolRe
 	if session != nil {
 		sessionOut = map[string]any{
 			"id":            session.ID,
+			"code":          session.Code,
 			"branch":        session.Branch,
 			"worktree_path": session.WorktreePath,
 			"commit_count":  session.CommitCount,
 		}
 	}
 
+	criteria := make([]map[string]any, len(issue.AcceptanceCriteria))
+	for i, c := range issue.AcceptanceCriteria {
+		criteria[i] = map[string]any{"number": i + 1, "criterion": c}
+	}
+
 	result := map[string]any{
+		"acceptance_criteria": criteria,
 		"issue": map[string]any{
 			"id":          issue.ID,
+			"code":        issue.Code,
 			"title":       issue.Title,
 			"description": issue.Description,
 			"body":        issue.Body,
@@ -1037,18 +1583,49 @@ func (s *Server) handlePrepareReview(ctx context.Context, request mcp.CallToolRe
 			"status":      string(issue.Status),
 		},
 		"session":          sessionOut,
-		"diff":             diff,
+		"diff_bytes":       len(diff),
 		"diff_stats":       diffStat,
+		"file_diffs":       fileDiffs,
 		"files_changed":    filesChanged,
-		"ui_review_needed": uiReviewNeeded,
+		"ui_review_needed": len(uiMatches) > 0,
+		"ui_review": map[string]any{
+			"rules":   uiRules,
+			"custom":  project.UIReviewPaths != "",
+			"matches": uiMatches,
+		},
 		"ui_context":       uiContext,
 		"review_history":   reviewHistory,
+		"related_issues":   s.relatedIssues(ctx, issue.ID),
 		"project": map[string]any{
-			"name":     project.Name,
-			"path":     project.Path,
-			"language": project.Language,
+			"name":        project.Name,
+			"path":        project.Path,
+			"subpath":     paths.Subpath,
+			"language":    project.Language,
+			"conventions": s.projectConventions(ctx, project),
 		},
 	}
+	// The diff can be large; by default agents fetch the files they need
+	if request.GetString("include_diff", "") == "true" {
+		result["diff"], result["diff_truncated"] = git.TruncateDiff(diff, maxDiff)
+	}
+	if request.GetString("include_blame", "") == "true" && s.git != nil && project.Path != "" {
+		result["blame"] = s.blameSummaries(ctx, project.Path, baseRef, diff)
+	}
+	if request.GetString("pre_analysis", strconv.FormatBool(s.reviewPreAnalysis)) == "true" {
+		untested := git.UntestedFiles(filesChanged)
+		if untested == nil {
+			untested = []string{}
+		}
+		result["untested_files"] = untested
+		if analysis, err := s.reviewPreAnalysisOf(ctx, project, issue, diff, untested); err != nil {
+			if errors.Is(err, llmbudget.ErrExceeded) && !s.budget.Degrade() {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			result["pre_analysis_error"] = err.Error()
+		} else {
+			result["pre_analysis"] = analysis
+		}
+	}
 
 	data, err := json.Marshal(result)
 	if err != nil {
@@ -1057,11 +1634,71 @@ func (s *Server) handlePrepareReview(ctx context.Context, request mcp.CallToolRe
 	return mcp.NewToolResultText(string(data)), nil
 }
 
+// reviewPreAnalysisOf asks the LLM for hints on the change under review and
+// records the tokens the call spent against the issue. It fails without
+// calling the LLM when the project is over its monthly budget.
+func (s *Server) reviewPreAnalysisOf(ctx context.Context, project *models.Project, issue *models.Issue, diff string, untested []string) (*llm.ReviewAnalysis, error) {
+	if s.llm == nil {
+		return nil, errors.New(capabilities.ReasonNoLLM)
+	}
+	if strings.TrimSpace(diff) == "" {
+		return nil, fmt.Errorf("no changes to analyze")
+	}
+	if err := s.budget.Check(ctx, s.store, project, time.Now()); err != nil {
+		return nil, err
+	}
+	issueText := issue.Title
+	if issue.Description != "" {
+		issueText += "\n\n" + issue.Description
+	}
+	analysis, err := s.llm.AnalyzeReview(ctx, project.Name, issueText, issue.AcceptanceCriteria, diff, untested)
+	if analysis != nil {
+		_ = s.store.RecordLLMUsage(ctx, analysis.Usage.Record(project.ID, issue.ID, models.LLMUsageReviewPreAnalysis))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pre-analysis: %w", err)
+	}
+	return analysis, nil
+}
+
+// defaultReviewDiffBytes caps the diff pm_prepare_review inlines so large
+// branches don't exhaust the reviewing agent's context.
+const defaultReviewDiffBytes = 50000
+
+// maxBlameFiles caps how many changed files pm_prepare_review blames.
+const maxBlameFiles = 20
+
+// blameSummaries blames, at the base ref, the pre-change lines of each diff
+// hunk (including its context), so reviewers can see whether a change touches
+// long-stable code. Files that fail to blame are skipped.
+func (s *Server) blameSummaries(ctx context.Context, repoPath, baseRef, diff string) []git.BlameSummary {
+	ranges := git.OldHunkRanges(diff)
+	files := make([]string, 0, len(ranges))
+	for f := range ranges {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	if len(files) > maxBlameFiles {
+		files = files[:maxBlameFiles]
+	}
+
+	summaries := []git.BlameSummary{}
+	now := time.Now()
+	for _, f := range files {
+		lines, err := s.git.Blame(ctx, repoPath, baseRef, f, ranges[f])
+		if err != nil || len(lines) == 0 {
+			continue
+		}
+		summaries = append(summaries, git.SummarizeBlame(f, lines, now))
+	}
+	return summaries
+}
+
 // pm_save_review
 func (s *Server) saveReviewTool() (mcp.Tool, server.ToolHandlerFunc) {
 	tool := mcp.NewTool("pm_save_review",
-		mcp.WithDescription("Save the result of an issue review. On pass, transitions issue to closed. On fail, transitions issue to in_progress with failure reasons. Creates a historical review record."),
-		mcp.WithString("issue_id", mcp.Required(), mcp.Description("Issue ID (full ULID or unique prefix)")),
+		mcp.WithDescription("Save the result of an issue review. On pass, transitions issue to closed; on fail, to in_progress (or what the project's cascade rules say) with failure reasons (including any failed acceptance criteria) and, if review auto-relaunch is enabled, starts a follow-up session on the same branch seeded with the failure reasons. Creates a historical review record."),
+		mcp.WithString("issue_id", mcp.Required(), mcp.Description("Issue short code (e.g. PM-12), full ULID or unique prefix")),
 		mcp.WithString("verdict", mcp.Required(), mcp.Description("Review verdict: pass or fail")),
 		mcp.WithString("summary", mcp.Required(), mcp.Description("Narrative review summary")),
 		mcp.WithString("code_quality", mcp.Description("Code quality assessment: pass, fail, or skip")),
@@ -1069,6 +1706,7 @@ func (s *Server) saveReviewTool() (mcp.Tool, server.ToolHandlerFunc) {
 		mcp.WithString("test_coverage", mcp.Description("Test coverage assessment: pass, fail, or skip")),
 		mcp.WithString("ui_ux", mcp.Description("UI/UX assessment: pass, fail, skip, or na")),
 		mcp.WithString("failure_reasons", mcp.Description("Newline-separated list of failure reasons (for fail verdicts)")),
+		mcp.WithString("criteria", mcp.Description(`JSON array of per-criterion results, e.g. [{"criterion": 1, "passed": true, "note": "covered by TestLogout"}]. criterion is an acceptance criterion's number or text from pm_prepare_review. A pass verdict cannot have failed criteria; failed ones are added to the failure reasons.`)),
 		mcp.WithString("diff_stats", mcp.Description("Diff statistics string")),
 	)
 	return tool, s.handleSaveReview
@@ -1088,8 +1726,17 @@ func (s *Server) handleSaveReview(ctx context.Context, request mcp.CallToolReque
 		return mcp.NewToolResultError("missing required parameter: summary"), nil
 	}
 
-	if verdict != "pass" && verdict != "fail" {
-		return mcp.NewToolResultError("verdict must be 'pass' or 'fail'"), nil
+	if _, err := models.ParseReviewVerdict(verdict); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	categories := map[string]string{"code_quality": "skip", "requirements_match": "skip", "test_coverage": "skip", "ui_ux": "na"}
+	ratings := make(map[string]models.ReviewCategory, len(categories))
+	for name, def := range categories {
+		c, err := models.ParseReviewCategory(request.GetString(name, def))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%s: %v", name, err)), nil
+		}
+		ratings[name] = c
 	}
 
 	issue, err := s.findIssue(ctx, issueID)
@@ -1097,25 +1744,37 @@ func (s *Server) handleSaveReview(ctx context.Context, request mcp.CallToolReque
 		return mcp.NewToolResultError(fmt.Sprintf("issue not found: %s", issueID)), nil
 	}
 
-	// Find linked session
+	// Find linked session, preferring a live one (e.g. a review follow-up)
 	var sessionID string
-	sessions, _ := s.store.ListAgentSessions(ctx, issue.ProjectID, 0)
+	sessions, _ := s.store.ListAgentSessionsFiltered(ctx, store.SessionListFilter{IssueID: issue.ID})
 	for _, sess := range sessions {
-		if sess.IssueID == issue.ID {
+		if sess.Status.IsOpen() {
 			sessionID = sess.ID
 			break
 		}
+		if sessionID == "" {
+			sessionID = sess.ID
+		}
 	}
 
-	// Parse failure reasons
-	var failureReasons []string
-	if fr := request.GetString("failure_reasons", ""); fr != "" {
-		for _, line := range strings.Split(fr, "\n") {
-			line = strings.TrimSpace(line)
-			if line != "" {
-				failureReasons = append(failureReasons, line)
-			}
+	failureReasons := splitLines(request.GetString("failure_reasons", ""))
+
+	results, err := parseCriterionResults(request.GetString("criteria", ""), issue.AcceptanceCriteria)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	for _, r := range results {
+		if r.Passed {
+			continue
+		}
+		if verdict == string(models.ReviewVerdictPass) {
+			return mcp.NewToolResultError(fmt.Sprintf("verdict is pass but acceptance criterion failed: %s", r.Criterion)), nil
+		}
+		reason := "Acceptance criterion not met: " + r.Criterion
+		if r.Note != "" {
+			reason += " (" + r.Note + ")"
 		}
+		failureReasons = append(failureReasons, reason)
 	}
 
 	review := &models.IssueReview{
@@ -1123,11 +1782,12 @@ func (s *Server) handleSaveReview(ctx context.Context, request mcp.CallToolReque
 		SessionID:         sessionID,
 		Verdict:           models.ReviewVerdict(verdict),
 		Summary:           summary,
-		CodeQuality:       models.ReviewCategory(request.GetString("code_quality", "skip")),
-		RequirementsMatch: models.ReviewCategory(request.GetString("requirements_match", "skip")),
-		TestCoverage:      models.ReviewCategory(request.GetString("test_coverage", "skip")),
-		UIUX:              models.ReviewCategory(request.GetString("ui_ux", "na")),
+		CodeQuality:       ratings["code_quality"],
+		RequirementsMatch: ratings["requirements_match"],
+		TestCoverage:      ratings["test_coverage"],
+		UIUX:              ratings["ui_ux"],
 		FailureReasons:    failureReasons,
+		Criteria:          results,
 		DiffStats:         request.GetString("diff_stats", ""),
 		ReviewedAt:        time.Now().UTC(),
 	}
@@ -1135,17 +1795,16 @@ func (s *Server) handleSaveReview(ctx context.Context, request mcp.CallToolReque
 	if err := s.store.CreateIssueReview(ctx, review); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to save review: %v", err)), nil
 	}
+	if _, err := agent.MarkReviewFailed(ctx, s.store, review); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("review saved but session outcome update failed: %v", err)), nil
+	}
 
-	// Transition issue status
+	// Transition issue status under the project's cascade rules
+	event := models.CascadeReviewFail
 	if verdict == "pass" {
-		issue.Status = models.IssueStatusClosed
-		now := time.Now().UTC()
-		issue.ClosedAt = &now
-	} else {
-		issue.Status = models.IssueStatusInProgress
-		issue.ClosedAt = nil
+		event = models.CascadeReviewPass
 	}
-	if err := s.store.UpdateIssue(ctx, issue); err != nil {
+	if _, err := agent.CascadeIssue(ctx, s.store, issue, event); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("review saved but issue update failed: %v", err)), nil
 	}
 
@@ -1156,10 +1815,129 @@ func (s *Server) handleSaveReview(ctx context.Context, request mcp.CallToolReque
 		"issue_status": string(issue.Status),
 		"summary":      summary,
 	}
+	if unchecked := uncheckedCriteria(issue.AcceptanceCriteria, results); len(unchecked) > 0 {
+		result["unchecked_criteria"] = unchecked
+	}
 
-	data, _ := json.Marshal(result)
-	return mcp.NewToolResultText(string(data)), nil
-}
+	// Close the loop: re-launch implementation with the failure reasons as the prompt
+	if verdict == "fail" && s.reviewRelaunch {
+		var wtc agent.WorktreeOpener
+		if s.wt != nil {
+			wtc = s.wt
+		}
+		followUp, err := agent.RelaunchAfterFailedReview(ctx, s.store, wtc, review, s.maxReviewAttempts)
+		if err != nil {
+			result["follow_up_error"] = err.Error()
+		} else {
+			result["follow_up"] = followUp
+		}
+	}
+
+	data, _ := json.Marshal(result)
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// criterionResultIn is one entry of pm_save_review's criteria parameter.
+// Criterion is an acceptance criterion's 1-based number or its text.
+type criterionResultIn struct {
+	Criterion json.RawMessage `json:"criterion"`
+	Passed    bool            `json:"passed"`
+	Note      string          `json:"note"`
+}
+
+// parseCriterionResults decodes pm_save_review's criteria parameter and
+// resolves each entry to one of the issue's acceptance criteria, so a review
+// can only record results for criteria the issue actually has.
+func parseCriterionResults(raw string, criteria []string) ([]models.CriterionResult, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var in []criterionResultIn
+	if err := json.Unmarshal([]byte(raw), &in); err != nil {
+		return nil, fmt.Errorf("criteria must be a JSON array of {criterion, passed, note}: %v", err)
+	}
+	if len(in) > 0 && len(criteria) == 0 {
+		return nil, fmt.Errorf("issue has no acceptance criteria to record results for")
+	}
+
+	seen := make(map[int]bool, len(in))
+	results := make([]models.CriterionResult, 0, len(in))
+	for _, r := range in {
+		i, err := resolveCriterion(r.Criterion, criteria)
+		if err != nil {
+			return nil, err
+		}
+		if seen[i] {
+			return nil, fmt.Errorf("acceptance criterion %d has more than one result", i+1)
+		}
+		seen[i] = true
+		results = append(results, models.CriterionResult{Criterion: criteria[i], Passed: r.Passed, Note: strings.TrimSpace(r.Note)})
+	}
+	return results, nil
+}
+
+// resolveCriterion returns the index in criteria of a criterion given by its
+// 1-based number (as a JSON number or string) or by its text, ignoring case.
+func resolveCriterion(ref json.RawMessage, criteria []string) (int, error) {
+	var text string
+	if err := json.Unmarshal(ref, &text); err != nil {
+		var n int
+		if err := json.Unmarshal(ref, &n); err != nil {
+			return 0, fmt.Errorf("criterion must be a number or text, got %s", ref)
+		}
+		text = strconv.Itoa(n)
+	}
+	text = strings.TrimSpace(text)
+	if n, err := strconv.Atoi(text); err == nil {
+		if n < 1 || n > len(criteria) {
+			return 0, fmt.Errorf("no acceptance criterion %d (issue has %d)", n, len(criteria))
+		}
+		return n - 1, nil
+	}
+	for i, c := range criteria {
+		if strings.EqualFold(c, text) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown acceptance criterion: %q", text)
+}
+
+// uncheckedCriteria returns the acceptance criteria a review recorded no
+// result for.
+func uncheckedCriteria(criteria []string, results []models.CriterionResult) []string {
+	checked := make(map[string]bool, len(results))
+	for _, r := range results {
+		checked[r.Criterion] = true
+	}
+	var out []string
+	for _, c := range criteria {
+		if !checked[c] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// criterionResultsOut renders a review's per-criterion results for tool
+// output.
+func criterionResultsOut(results []models.CriterionResult) []map[string]any {
+	out := make([]map[string]any, len(results))
+	for i, r := range results {
+		out[i] = map[string]any{"criterion": r.Criterion, "passed": r.Passed, "note": r.Note}
+	}
+	return out
+}
+
+// splitLines splits newline-separated tool input into its non-blank lines.
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
 
 // pm_update_project
 func (s *Server) updateProjectTool() (mcp.Tool, server.ToolHandlerFunc) {
@@ -1170,6 +1948,12 @@ func (s *Server) updateProjectTool() (mcp.Tool, server.ToolHandlerFunc) {
 		mcp.WithString("build_cmd", mcp.Description("Build command (e.g. 'npm run build', 'make ui-build')")),
 		mcp.WithString("serve_cmd", mcp.Description("Dev server command (e.g. 'npm run dev', 'bun run dev')")),
 		mcp.WithString("serve_port", mcp.Description("Dev server port as string (e.g. '3000', '5173')")),
+		mcp.WithString("max_in_progress", mcp.Description("WIP limit on in-progress issues as string ('0' = no limit)")),
+		mcp.WithString("max_active_sessions", mcp.Description("WIP limit on active/idle sessions as string ('0' = no limit)")),
+		mcp.WithString("wip_hard_block", mcp.Description("Block launches that would exceed WIP limits: 'true' or 'false'")),
+		mcp.WithString("commit_style", mcp.Description("Commit message style checked by close-check and merge: 'conventional', or 'none' to clear")),
+		mcp.WithString("commit_ticket_prefix", mcp.Description("Require commit messages to reference a ticket like <prefix>123 (e.g. 'PROJ-'), or 'none' to clear")),
+		mcp.WithString("ui_review_paths", mcp.Description("Comma-separated glob rules for files whose changes need a UI review (e.g. '*.tsx,templates/'), or 'none' for the defaults of the project's languages")),
 	)
 	return tool, s.handleUpdateProject
 }
@@ -1205,6 +1989,58 @@ func (s *Server) handleUpdateProject(ctx context.Context, request mcp.CallToolRe
 			updated = true
 		}
 	}
+	if v := request.GetString("max_in_progress", ""); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid max_in_progress %q: must be a non-negative integer", v)), nil
+		}
+		p.MaxInProgress = n
+		updated = true
+	}
+	if v := request.GetString("max_active_sessions", ""); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid max_active_sessions %q: must be a non-negative integer", v)), nil
+		}
+		p.MaxActiveSessions = n
+		updated = true
+	}
+	if v := request.GetString("wip_hard_block", ""); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid wip_hard_block %q: must be 'true' or 'false'", v)), nil
+		}
+		p.WIPHardBlock = b
+		updated = true
+	}
+	if v := request.GetString("commit_style", ""); v != "" {
+		if v == "none" {
+			v = ""
+		}
+		if err := commitmsg.ValidateStyle(v); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		p.CommitStyle = v
+		updated = true
+	}
+	if v := request.GetString("commit_ticket_prefix", ""); v != "" {
+		if v == "none" {
+			v = ""
+		}
+		p.CommitTicketPrefix = v
+		updated = true
+	}
+	if v := request.GetString("ui_review_paths", ""); v != "" {
+		if v == "none" {
+			v = ""
+		}
+		rules := uireview.ParseRules(v)
+		if err := uireview.Validate(rules); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		p.UIReviewPaths = strings.Join(rules, ",")
+		updated = true
+	}
 
 	if !updated {
 		return mcp.NewToolResultError("no fields provided to update"), nil
@@ -1221,8 +2057,395 @@ func (s *Server) handleUpdateProject(ctx context.Context, request mcp.CallToolRe
 		"build_cmd":   p.BuildCmd,
 		"serve_cmd":   p.ServeCmd,
 		"serve_port":  p.ServePort,
+
+		"max_in_progress":     p.MaxInProgress,
+		"max_active_sessions": p.MaxActiveSessions,
+		"wip_hard_block":      p.WIPHardBlock,
+
+		"commit_style":         p.CommitStyle,
+		"commit_ticket_prefix": p.CommitTicketPrefix,
+
+		"ui_review_paths": uireview.Rules(p),
+	}
+
+	data, _ := json.Marshal(result)
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// pm_relate_issues
+func (s *Server) relateIssuesTool() (mcp.Tool, server.ToolHandlerFunc) {
+	tool := mcp.NewTool("pm_relate_issues",
+		mcp.WithDescription("Add or remove a typed relation between two issues, which may belong to different projects. Relations are shown in pm_prepare_review so a fix agent sees, e.g., the bug that caused a regression."),
+		mcp.WithString("issue_id", mcp.Required(), mcp.Description("Source issue short code or ID (full or prefix)")),
+		mcp.WithString("type", mcp.Required(), mcp.Description("Relation type: relates_to, duplicates, caused_by, blocks, split_from")),
+		mcp.WithString("target_issue_id", mcp.Required(), mcp.Description("Target issue ID (full or prefix)")),
+		mcp.WithString("remove", mcp.Description("Set to 'true' to remove the relation instead of adding it (default: false)")),
+	)
+	return tool, s.handleRelateIssues
+}
+
+func (s *Server) handleRelateIssues(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueID, err := request.RequireString("issue_id")
+	if err != nil {
+		return mcp.NewToolResultError("missing required parameter: issue_id"), nil
+	}
+	typeStr, err := request.RequireString("type")
+	if err != nil {
+		return mcp.NewToolResultError("missing required parameter: type"), nil
+	}
+	targetID, err := request.RequireString("target_issue_id")
+	if err != nil {
+		return mcp.NewToolResultError("missing required parameter: target_issue_id"), nil
+	}
+
+	relType := models.RelationType(typeStr)
+	if !relType.Valid() {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid relation type: %s (must be relates_to, duplicates, caused_by, blocks, or split_from)", typeStr)), nil
+	}
+	source, err := s.findIssue(ctx, issueID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("issue not found: %s", issueID)), nil
+	}
+	target, err := s.findIssue(ctx, targetID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("target issue not found: %s", targetID)), nil
+	}
+
+	result := map[string]any{
+		"issue_id":        source.ID,
+		"type":            string(relType),
+		"target_issue_id": target.ID,
+	}
+
+	if request.GetString("remove", "") == "true" {
+		rels, err := s.store.ListIssueRelations(ctx, source.ID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list relations: %v", err)), nil
+		}
+		for _, rel := range rels {
+			if rel.SourceIssueID == source.ID && rel.TargetIssueID == target.ID && rel.Type == relType {
+				if err := s.store.DeleteIssueRelation(ctx, rel.ID); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("failed to remove relation: %v", err)), nil
+				}
+				result["removed"] = true
+				data, _ := json.Marshal(result)
+				return mcp.NewToolResultText(string(data)), nil
+			}
+		}
+		return mcp.NewToolResultError("relation not found"), nil
+	}
+
+	rel := &models.IssueRelation{SourceIssueID: source.ID, TargetIssueID: target.ID, Type: relType}
+	if err := s.store.CreateIssueRelation(ctx, rel); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create relation: %v", err)), nil
+	}
+	result["id"] = rel.ID
+	result["label"] = fmt.Sprintf("%s %s %s", source.Title, relType.Label(), target.Title)
+
+	data, _ := json.Marshal(result)
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// pm_watch_issue
+func (s *Server) watchIssueTool() (mcp.Tool, server.ToolHandlerFunc) {
+	tool := mcp.NewTool("pm_watch_issue",
+		mcp.WithDescription("Subscribe an email address or webhook URL to an issue. While pm serve runs, only an issue's watchers are notified of its status changes, comments and review verdicts. Watching twice is a no-op."),
+		mcp.WithString("issue_id", mcp.Required(), mcp.Description("Issue short code (e.g. PM-12) or ID (full or prefix)")),
+		mcp.WithString("target", mcp.Required(), mcp.Description("Email address, or http(s) URL to POST JSON activity to")),
+		mcp.WithString("kind", mcp.Description("Watcher kind: email or webhook (default: inferred from the target)")),
+	)
+	return tool, s.handleWatchIssue
+}
+
+func (s *Server) handleWatchIssue(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueID, err := request.RequireString("issue_id")
+	if err != nil {
+		return mcp.NewToolResultError("missing required parameter: issue_id"), nil
+	}
+	targetStr, err := request.RequireString("target")
+	if err != nil {
+		return mcp.NewToolResultError("missing required parameter: target"), nil
+	}
+	kind, target, err := models.ParseWatcherTarget(request.GetString("kind", ""), targetStr)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if _, scoped := auth.FromContext(ctx); scoped && kind == models.WatcherWebhook {
+		if err := notify.CheckWebhookHost(ctx, target); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	issue, err := s.findIssue(ctx, issueID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("issue not found: %s", issueID)), nil
+	}
+
+	added, err := s.store.AddIssueWatcher(ctx, &models.IssueWatcher{IssueID: issue.ID, Kind: kind, Target: target})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to add watcher: %v", err)), nil
+	}
+	watchers, err := s.store.ListIssueWatchers(ctx, issue.ID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list watchers: %v", err)), nil
+	}
+	result := map[string]any{
+		"issue_id": issue.ID,
+		"kind":     string(kind),
+		"target":   target,
+		"added":    added,
+		"watchers": len(watchers),
+	}
+	data, _ := json.Marshal(result)
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// pm_unwatch_issue
+func (s *Server) unwatchIssueTool() (mcp.Tool, server.ToolHandlerFunc) {
+	tool := mcp.NewTool("pm_unwatch_issue",
+		mcp.WithDescription("Stop notifying an email address or webhook URL about an issue."),
+		mcp.WithString("issue_id", mcp.Required(), mcp.Description("Issue short code (e.g. PM-12) or ID (full or prefix)")),
+		mcp.WithString("target", mcp.Required(), mcp.Description("Email address or webhook URL the issue is watched by")),
+	)
+	return tool, s.handleUnwatchIssue
+}
+
+func (s *Server) handleUnwatchIssue(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueID, err := request.RequireString("issue_id")
+	if err != nil {
+		return mcp.NewToolResultError("missing required parameter: issue_id"), nil
+	}
+	target, err := request.RequireString("target")
+	if err != nil {
+		return mcp.NewToolResultError("missing required parameter: target"), nil
+	}
+	if _, normalized, err := models.ParseWatcherTarget("", target); err == nil {
+		target = normalized
+	}
+	issue, err := s.findIssue(ctx, issueID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("issue not found: %s", issueID)), nil
 	}
 
+	if err := s.store.RemoveIssueWatcher(ctx, issue.ID, target); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result := map[string]any{
+		"issue_id": issue.ID,
+		"target":   target,
+		"removed":  true,
+	}
+	data, _ := json.Marshal(result)
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// pm_issue_code
+func (s *Server) issueCodeTool() (mcp.Tool, server.ToolHandlerFunc) {
+	tool := mcp.NewTool("pm_issue_code",
+		mcp.WithDescription("Trace an issue to its code: the branch, commits, pull requests, merge commit and merged range of every session that worked on it, the commits and pull requests that closed it, and the releases (tags) containing its merges. shipped_in is the first release with its latest merged work. Use it to write release notes or answer which release shipped an issue."),
+		mcp.WithString("issue_id", mcp.Required(), mcp.Description("Issue short code (e.g. PM-12) or ID (full or prefix)")),
+	)
+	return tool, s.handleIssueCode
+}
+
+func (s *Server) handleIssueCode(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	issueID, err := request.RequireString("issue_id")
+	if err != nil {
+		return mcp.NewToolResultError("missing required parameter: issue_id"), nil
+	}
+	issue, err := s.findIssue(ctx, issueID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("issue not found: %s", issueID)), nil
+	}
+
+	tags, _ := s.git.(agent.TagFinder)
+	prs, _ := s.gh.(agent.BranchPRLister)
+	code, err := agent.BuildIssueCode(ctx, s.store, s.git, tags, prs, issue.ID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to trace issue: %v", err)), nil
+	}
+	data, err := json.Marshal(code)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal issue code: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// pm_search_code
+func (s *Server) searchCodeTool() (mcp.Tool, server.ToolHandlerFunc) {
+	tool := mcp.NewTool("pm_search_code",
+		mcp.WithDescription("Search the files of a project, or of a session's worktree, for a regular expression (Go syntax) or literal string, like ripgrep. Dependency, build and hidden directories and binary files are skipped. Returns each matching line with its path, line and column, in path order; truncated is true when there were more than max_results."),
+		mcp.WithString("pattern", mcp.Required(), mcp.Description("Regular expression to search for, or a literal string with literal")),
+		mcp.WithString("project", mcp.Description("Project name, to search its directory")),
+		mcp.WithString("session_id", mcp.Description("Session short code (e.g. S-3) or ID, to search its worktree instead")),
+		mcp.WithString("paths", mcp.Description("Comma-separated path globs to search; prefix with ! to exclude. Globs without / match names at any depth (e.g. '*.go,!*_test.go'), others match from the root (e.g. 'internal/api')")),
+		mcp.WithString("literal", mcp.Description("Set to 'true' to search for pattern as a literal string")),
+		mcp.WithString("ignore_case", mcp.Description("Set to 'true' for a case-insensitive search")),
+		mcp.WithString("max_results", mcp.Description(fmt.Sprintf("Max matching lines to return as string (default: '%d', at most %d)", codesearch.DefaultMaxResults, codesearch.MaxMaxResults))),
+	)
+	return tool, s.handleSearchCode
+}
+
+func (s *Server) handleSearchCode(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pattern, err := request.RequireString("pattern")
+	if err != nil {
+		return mcp.NewToolResultError("missing required parameter: pattern"), nil
+	}
+	limit, err := strconv.Atoi(request.GetString("max_results", strconv.Itoa(codesearch.DefaultMaxResults)))
+	if err != nil || limit <= 0 {
+		return mcp.NewToolResultError("max_results must be a positive integer"), nil
+	}
+	paths, err := codesearch.ParsePaths(request.GetString("paths", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var p *models.Project
+	if name := request.GetString("project", ""); name != "" {
+		if p, err = s.resolveProject(ctx, name); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("project not found: %s", name)), nil
+		}
+	}
+	root := ""
+	result := map[string]any{}
+	if ref := request.GetString("session_id", ""); ref != "" {
+		sess, err := s.findSession(ctx, ref)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("session not found: %s", ref)), nil
+		}
+		if p != nil && sess.ProjectID != p.ID {
+			return mcp.NewToolResultError(fmt.Sprintf("session %s is not in project %s", ref, p.Name)), nil
+		}
+		if sess.WorktreePath == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("session %s has no worktree", ref)), nil
+		}
+		root = sess.WorktreePath
+		result["session_id"] = sess.ID
+	} else if p != nil {
+		root = p.Path
+		result["project"] = p.Name
+	} else {
+		return mcp.NewToolResultError("project or session_id is required"), nil
+	}
+
+	res, err := codesearch.Search(ctx, root, codesearch.Options{
+		Pattern:    pattern,
+		Literal:    request.GetString("literal", "") == "true",
+		IgnoreCase: request.GetString("ignore_case", "") == "true",
+		Paths:      paths,
+		MaxResults: limit,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
+	}
+	result["root"] = root
+	result["matches"] = res.Matches
+	result["count"] = len(res.Matches)
+	result["files_searched"] = res.FilesSearched
+	result["files_matched"] = res.FilesMatched
+	result["truncated"] = res.Truncated
+	data, _ := json.Marshal(result)
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// pm_my_focus
+func (s *Server) myFocusTool() (mcp.Tool, server.ToolHandlerFunc) {
+	tool := mcp.NewTool("pm_my_focus",
+		mcp.WithDescription("Cross-project summary of what needs attention: conflicted sessions, issues whose latest review failed, live sessions, blocked sessions (overdue ones have been blocked longer than the reminder threshold), WIP limit warnings, and the top-ranked issues to launch next. Use this instead of listing each project separately."),
+		mcp.WithString("limit", mcp.Description("Max next tasks to include as string (default: '5')")),
+	)
+	return tool, s.handleMyFocus
+}
+
+func (s *Server) handleMyFocus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit, err := strconv.Atoi(request.GetString("limit", "5"))
+	if err != nil || limit < 0 {
+		return mcp.NewToolResultError("limit must be a non-negative integer"), nil
+	}
+
+	wl, err := agent.BuildWorklist(ctx, s.store, "", time.Now())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to build worklist: %v", err)), nil
+	}
+
+	conflicted := make([]map[string]any, 0, len(wl.ConflictedSessions))
+	for _, ref := range wl.ConflictedSessions {
+		conflicted = append(conflicted, sessionRefOut(ref))
+	}
+	live := make([]map[string]any, 0, len(wl.LiveSessions))
+	for _, ref := range wl.LiveSessions {
+		live = append(live, sessionRefOut(ref))
+	}
+	now := time.Now()
+	blocked := make([]map[string]any, 0, len(wl.BlockedSessions))
+	for _, ref := range wl.BlockedSessions {
+		out := sessionRefOut(ref)
+		out["blocked_reason"] = ref.Session.BlockedReason
+		out["unblock_condition"] = ref.Session.UnblockCondition
+		if ref.Session.BlockedAt != nil {
+			out["blocked_at"] = ref.Session.BlockedAt.UTC().Format(time.RFC3339)
+		}
+		out["overdue"] = agent.BlockedTooLong(ref.Session, s.blockedRemindAfter, now)
+		blocked = append(blocked, out)
+	}
+	failing := make([]map[string]any, 0, len(wl.FailingReviews))
+	for _, fr := range wl.FailingReviews {
+		failing = append(failing, map[string]any{
+			"issue_id":        fr.Issue.ID,
+			"title":           fr.Issue.Title,
+			"project":         fr.Project.Name,
+			"status":          string(fr.Issue.Status),
+			"summary":         fr.Review.Summary,
+			"failure_reasons": fr.Review.FailureReasons,
+			"reviewed_at":     fr.Review.ReviewedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	result := map[string]any{
+		"conflicted_sessions": conflicted,
+		"failing_reviews":     failing,
+		"live_sessions":       live,
+		"blocked_sessions":    blocked,
+		"wip_warnings":        wl.WIPWarnings,
+		"next_tasks":          tasksOut(wl.Tasks, limit),
+	}
+	data, _ := json.Marshal(result)
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// pm_next_tasks
+func (s *Server) nextTasksTool() (mcp.Tool, server.ToolHandlerFunc) {
+	tool := mcp.NewTool("pm_next_tasks",
+		mcp.WithDescription("Ranked cross-project list of issues to launch next. Issues with a failed latest review rank first, then by priority, bugs before features, and age. Skips issues with a live session, issues blocked by unfinished issues, and projects hard-blocked by WIP limits."),
+		mcp.WithString("project", mcp.Description("Limit to one project (default: all projects)")),
+		mcp.WithString("limit", mcp.Description("Max tasks to return as string (default: '10')")),
+	)
+	return tool, s.handleNextTasks
+}
+
+func (s *Server) handleNextTasks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit, err := strconv.Atoi(request.GetString("limit", "10"))
+	if err != nil || limit < 0 {
+		return mcp.NewToolResultError("limit must be a non-negative integer"), nil
+	}
+
+	projectID := ""
+	if name := request.GetString("project", ""); name != "" {
+		p, err := s.resolveProject(ctx, name)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("project not found: %s", name)), nil
+		}
+		projectID = p.ID
+	}
+
+	wl, err := agent.BuildWorklist(ctx, s.store, projectID, time.Now())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to build worklist: %v", err)), nil
+	}
+
+	result := map[string]any{
+		"tasks": tasksOut(wl.Tasks, limit),
+		"total": len(wl.Tasks),
+	}
 	data, _ := json.Marshal(result)
 	return mcp.NewToolResultText(string(data)), nil
 }
@@ -1231,6 +2454,173 @@ func (s *Server) handleUpdateProject(ctx context.Context, request mcp.CallToolRe
 // Helpers
 // ---------------------------------------------------------------------------
 
+// tasksOut formats up to limit ranked tasks (0 = all).
+func tasksOut(tasks []agent.Task, limit int) []map[string]any {
+	if limit > 0 && len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	out := make([]map[string]any, 0, len(tasks))
+	for i, t := range tasks {
+		out = append(out, map[string]any{
+			"rank":     i + 1,
+			"score":    t.Score,
+			"reasons":  t.Reasons,
+			"issue_id": t.Issue.ID,
+			"title":    t.Issue.Title,
+			"project":  t.Project.Name,
+			"priority": string(t.Issue.Priority),
+			"type":     string(t.Issue.Type),
+			"status":   string(t.Issue.Status),
+		})
+	}
+	return out
+}
+
+func sessionRefOut(ref agent.SessionRef) map[string]any {
+	out := map[string]any{
+		"session_id":     ref.Session.ID,
+		"project":        ref.Project.Name,
+		"branch":         ref.Session.Branch,
+		"issue_id":       ref.Session.IssueID,
+		"status":         string(ref.Session.Status),
+		"conflict_state": string(ref.Session.ConflictState),
+	}
+	// Conflicting files are stored from the repository root.
+	var files []string
+	if err := json.Unmarshal([]byte(ref.Session.ConflictFiles), &files); err == nil && len(files) > 0 {
+		out["conflict_files"] = files
+	}
+	return out
+}
+
+// relatedIssues returns the issue's relations with enough of each related
+// issue (including its body) for an agent to use as context.
+func (s *Server) relatedIssues(ctx context.Context, issueID string) []map[string]any {
+	related, err := store.RelatedIssues(ctx, s.store, issueID)
+	if err != nil {
+		return nil
+	}
+	var out []map[string]any
+	for _, ri := range related {
+		projectName := ""
+		if p, err := s.store.GetProject(ctx, ri.Issue.ProjectID); err == nil {
+			projectName = p.Name
+		}
+		out = append(out, map[string]any{
+			"relation":    ri.Label,
+			"type":        string(ri.Relation.Type),
+			"id":          ri.Issue.ID,
+			"title":       ri.Issue.Title,
+			"project":     projectName,
+			"status":      string(ri.Issue.Status),
+			"issue_type":  string(ri.Issue.Type),
+			"description": ri.Issue.Description,
+			"body":        ri.Issue.Body,
+		})
+	}
+	return out
+}
+
+// projectConventions returns the project's cached conventions, re-ingesting
+// them first if the repo's convention files changed since the last refresh.
+func (s *Server) projectConventions(ctx context.Context, p *models.Project) string {
+	if refresh.SyncConventions(p) {
+		if err := s.store.UpdateProject(ctx, p); err != nil {
+			slog.Warn("failed to update project conventions", "project", p.Name, "error", err)
+		}
+	}
+	return p.Conventions
+}
+
+// pm_suggest_commit_message
+func (s *Server) suggestCommitMessageTool() (mcp.Tool, server.ToolHandlerFunc) {
+	tool := mcp.NewTool("pm_suggest_commit_message",
+		mcp.WithDescription("Generate a commit message for the changes staged in a session's worktree (or a project's repo), following the project's commit conventions. Stage your changes with git add first, then commit with the returned message. Its token usage is recorded; over the project's monthly LLM budget the call fails."),
+		mcp.WithString("session_id", mcp.Description("Session whose worktree holds the staged changes")),
+		mcp.WithString("project", mcp.Description("Project name, to use its repo instead of a session worktree")),
+	)
+	return tool, s.handleSuggestCommitMessage
+}
+
+func (s *Server) handleSuggestCommitMessage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.llm == nil {
+		return mcp.NewToolResultError(capabilities.ReasonNoLLM), nil
+	}
+
+	var p *models.Project
+	var path, issueID, issueText string
+	switch {
+	case request.GetString("session_id", "") != "":
+		sess, err := s.findSession(ctx, request.GetString("session_id", ""))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("session not found: %v", err)), nil
+		}
+		if p, err = s.store.GetProject(ctx, sess.ProjectID); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("project not found for session: %v", err)), nil
+		}
+		path = sess.WorktreePath
+		issueID = sess.IssueID
+		if sess.IssueID != "" {
+			if issue, err := s.store.GetIssue(ctx, sess.IssueID); err == nil {
+				issueText = issue.Title
+				if len(issue.ID) > 12 {
+					issueText += " (" + issue.ID[:12] + ")"
+				}
+			}
+		}
+	case request.GetString("project", "") != "":
+		var err error
+		if p, err = s.resolveProject(ctx, request.GetString("project", "")); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		path = p.Path
+	default:
+		return mcp.NewToolResultError("session_id or project is required"), nil
+	}
+	if path == "" {
+		return mcp.NewToolResultError("session has no worktree"), nil
+	}
+
+	diff, err := git.StagedDiff(ctx, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("read staged diff: %v", err)), nil
+	}
+	if strings.TrimSpace(diff) == "" {
+		return mcp.NewToolResultError("nothing is staged; stage changes with git add first"), nil
+	}
+
+	conv := commitmsg.ForProject(p)
+	convention := ""
+	if conv.Enabled() {
+		convention = conv.Describe()
+	}
+	// There is no heuristic for a commit message, so over budget is an error
+	if err := s.budget.Check(ctx, s.store, p, time.Now()); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	message, usage, err := s.llm.SuggestCommitMessage(ctx, p.Name, diff, convention, issueText)
+	if usage != (llm.Usage{}) {
+		_ = s.store.RecordLLMUsage(ctx, usage.Record(p.ID, issueID, models.LLMUsageCommitMessage))
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("generate commit message: %v", err)), nil
+	}
+
+	// The LLM may still get the convention wrong; report what the gates would say
+	problems := conv.Check(message)
+	result := map[string]any{
+		"message":    message,
+		"convention": conv.Describe(),
+		"files":      len(git.SplitDiff(diff)),
+		"compliant":  len(problems) == 0,
+	}
+	if len(problems) > 0 {
+		result["problems"] = problems
+	}
+	data, _ := json.Marshal(result)
+	return mcp.NewToolResultText(string(data)), nil
+}
+
 // resolveProject tries to find a project by name first, then by ID.
 func (s *Server) resolveProject(ctx context.Context, name string) (*models.Project, error) {
 	if p, err := s.store.GetProjectByName(ctx, name); err == nil {
@@ -1248,6 +2638,11 @@ func (s *Server) findIssue(ctx context.Context, id string) (*models.Issue, error
 	if issue, err := s.store.GetIssue(ctx, id); err == nil {
 		return issue, nil
 	}
+	if code, isSession, ok := models.ParseShortCode(id); ok && !isSession {
+		if issue, err := s.store.GetIssueByCode(ctx, code); err == nil {
+			return issue, nil
+		}
+	}
 
 	// Try prefix match - list all and filter
 	upper := strings.ToUpper(id)
@@ -1274,6 +2669,18 @@ func (s *Server) findIssue(ctx context.Context, id string) (*models.Issue, error
 	}
 }
 
+// findSession looks up a session by ID or short code.
+func (s *Server) findSession(ctx context.Context, ref string) (*models.AgentSession, error) {
+	sess, err := s.store.GetAgentSession(ctx, ref)
+	if err == nil {
+		return sess, nil
+	}
+	if code, isSession, ok := models.ParseShortCode(ref); ok && isSession {
+		return s.store.GetAgentSessionByCode(ctx, code)
+	}
+	return nil, err
+}
+
 // issueToBranch converts an issue title to a branch name.
 func issueToBranch(title string) string {
 	s := strings.ToLower(title)
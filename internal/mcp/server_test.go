@@ -4,6 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -13,6 +17,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/joescharf/pm/internal/agent"
+	"github.com/joescharf/pm/internal/auth"
+	"github.com/joescharf/pm/internal/capabilities"
 	"github.com/joescharf/pm/internal/git"
 	"github.com/joescharf/pm/internal/models"
 	"github.com/joescharf/pm/internal/store"
@@ -24,294 +31,32 @@ import (
 // Mock implementations
 // ---------------------------------------------------------------------------
 
-// mockStore implements store.Store for testing.
-type mockStore struct {
-	projects []*models.Project
-	issues   []*models.Issue
-	sessions []*models.AgentSession
-	tags     []*models.Tag
-
-	reviews        []*models.IssueReview
-	createdReviews []*models.IssueReview
-
-	// Track calls for verification.
-	createdIssues   []*models.Issue
-	updatedIssues   []*models.Issue
-	createdSessions []*models.AgentSession
-
-	// Optional error injection.
+// failingStore is a memory store whose ListProjects, ListIssues and
+// CreateIssue fail with the errors set.
+type failingStore struct {
+	*store.MemoryStore
 	listProjectsErr error
 	listIssuesErr   error
 	createIssueErr  error
-	getIssueErr     error
-	updateIssueErr  error
-}
-
-func (m *mockStore) CreateProject(_ context.Context, p *models.Project) error {
-	m.projects = append(m.projects, p)
-	return nil
-}
-func (m *mockStore) GetProject(_ context.Context, id string) (*models.Project, error) {
-	for _, p := range m.projects {
-		if p.ID == id {
-			return p, nil
-		}
-	}
-	return nil, fmt.Errorf("project not found: %s", id)
-}
-func (m *mockStore) GetProjectByName(_ context.Context, name string) (*models.Project, error) {
-	for _, p := range m.projects {
-		if p.Name == name {
-			return p, nil
-		}
-	}
-	return nil, fmt.Errorf("project not found: %s", name)
-}
-func (m *mockStore) GetProjectByPath(_ context.Context, path string) (*models.Project, error) {
-	for _, p := range m.projects {
-		if p.Path == path {
-			return p, nil
-		}
-	}
-	return nil, fmt.Errorf("project not found by path: %s", path)
-}
-func (m *mockStore) ListProjects(_ context.Context, group string) ([]*models.Project, error) {
-	if m.listProjectsErr != nil {
-		return nil, m.listProjectsErr
-	}
-	if group == "" {
-		return m.projects, nil
-	}
-	var filtered []*models.Project
-	for _, p := range m.projects {
-		if p.GroupName == group {
-			filtered = append(filtered, p)
-		}
-	}
-	return filtered, nil
-}
-func (m *mockStore) UpdateProject(_ context.Context, p *models.Project) error {
-	for i, existing := range m.projects {
-		if existing.ID == p.ID {
-			m.projects[i] = p
-			return nil
-		}
-	}
-	return fmt.Errorf("project not found: %s", p.ID)
-}
-func (m *mockStore) DeleteProject(_ context.Context, _ string) error          { return nil }
-
-func (m *mockStore) CreateIssue(_ context.Context, issue *models.Issue) error {
-	if m.createIssueErr != nil {
-		return m.createIssueErr
-	}
-	if issue.ID == "" {
-		issue.ID = fmt.Sprintf("issue-%d", len(m.issues)+1)
-	}
-	issue.CreatedAt = time.Now()
-	issue.UpdatedAt = time.Now()
-	m.issues = append(m.issues, issue)
-	m.createdIssues = append(m.createdIssues, issue)
-	return nil
-}
-func (m *mockStore) GetIssue(_ context.Context, id string) (*models.Issue, error) {
-	if m.getIssueErr != nil {
-		return nil, m.getIssueErr
-	}
-	for _, i := range m.issues {
-		if i.ID == id {
-			return i, nil
-		}
-	}
-	return nil, fmt.Errorf("issue not found: %s", id)
-}
-func (m *mockStore) ListIssues(_ context.Context, filter store.IssueListFilter) ([]*models.Issue, error) {
-	if m.listIssuesErr != nil {
-		return nil, m.listIssuesErr
-	}
-	var result []*models.Issue
-	for _, i := range m.issues {
-		if filter.ProjectID != "" && i.ProjectID != filter.ProjectID {
-			continue
-		}
-		if filter.Status != "" && i.Status != filter.Status {
-			continue
-		}
-		if filter.Priority != "" && i.Priority != filter.Priority {
-			continue
-		}
-		if filter.Type != "" && i.Type != filter.Type {
-			continue
-		}
-		result = append(result, i)
-	}
-	return result, nil
-}
-func (m *mockStore) UpdateIssue(_ context.Context, issue *models.Issue) error {
-	if m.updateIssueErr != nil {
-		return m.updateIssueErr
-	}
-	for idx, i := range m.issues {
-		if i.ID == issue.ID {
-			m.issues[idx] = issue
-			m.updatedIssues = append(m.updatedIssues, issue)
-			return nil
-		}
-	}
-	return fmt.Errorf("issue not found: %s", issue.ID)
-}
-func (m *mockStore) DeleteIssue(_ context.Context, _ string) error { return nil }
-func (m *mockStore) BulkUpdateIssueStatus(_ context.Context, ids []string, status models.IssueStatus) (int64, error) {
-	var n int64
-	for _, id := range ids {
-		for _, i := range m.issues {
-			if i.ID == id {
-				i.Status = status
-				n++
-			}
-		}
-	}
-	return n, nil
-}
-func (m *mockStore) BulkDeleteIssues(_ context.Context, ids []string) (int64, error) {
-	var n int64
-	for _, id := range ids {
-		for idx, i := range m.issues {
-			if i.ID == id {
-				m.issues = append(m.issues[:idx], m.issues[idx+1:]...)
-				n++
-				break
-			}
-		}
-	}
-	return n, nil
-}
-
-func (m *mockStore) CreateTag(_ context.Context, tag *models.Tag) error {
-	m.tags = append(m.tags, tag)
-	return nil
-}
-func (m *mockStore) ListTags(_ context.Context) ([]*models.Tag, error) { return m.tags, nil }
-func (m *mockStore) DeleteTag(_ context.Context, _ string) error       { return nil }
-func (m *mockStore) TagIssue(_ context.Context, _, _ string) error     { return nil }
-func (m *mockStore) UntagIssue(_ context.Context, _, _ string) error   { return nil }
-func (m *mockStore) GetIssueTags(_ context.Context, _ string) ([]*models.Tag, error) {
-	return nil, nil
-}
-
-func (m *mockStore) CreateAgentSession(_ context.Context, session *models.AgentSession) error {
-	if session.ID == "" {
-		session.ID = fmt.Sprintf("session-%d", len(m.sessions)+1)
-	}
-	m.sessions = append(m.sessions, session)
-	m.createdSessions = append(m.createdSessions, session)
-	return nil
-}
-func (m *mockStore) ListAgentSessions(_ context.Context, projectID string, limit int) ([]*models.AgentSession, error) {
-	var result []*models.AgentSession
-	for _, s := range m.sessions {
-		if projectID != "" && s.ProjectID != projectID {
-			continue
-		}
-		result = append(result, s)
-		if limit > 0 && len(result) >= limit {
-			break
-		}
-	}
-	return result, nil
-}
-func (m *mockStore) GetAgentSession(_ context.Context, id string) (*models.AgentSession, error) {
-	for _, s := range m.sessions {
-		if s.ID == id {
-			return s, nil
-		}
-	}
-	return nil, fmt.Errorf("agent session not found: %s", id)
-}
-
-func (m *mockStore) GetAgentSessionByWorktreePath(_ context.Context, path string) (*models.AgentSession, error) {
-	for _, s := range m.sessions {
-		if s.WorktreePath == path && (s.Status == models.SessionStatusActive || s.Status == models.SessionStatusIdle) {
-			return s, nil
-		}
-	}
-	return nil, fmt.Errorf("no active/idle session for worktree: %s", path)
 }
 
-func (m *mockStore) UpdateAgentSession(_ context.Context, session *models.AgentSession) error {
-	for idx, s := range m.sessions {
-		if s.ID == session.ID {
-			m.sessions[idx] = session
-			return nil
-		}
+func (f *failingStore) ListProjects(ctx context.Context, group string) ([]*models.Project, error) {
+	if f.listProjectsErr != nil {
+		return nil, f.listProjectsErr
 	}
-	return fmt.Errorf("session not found: %s", session.ID)
+	return f.MemoryStore.ListProjects(ctx, group)
 }
-func (m *mockStore) ListAgentSessionsByStatus(_ context.Context, projectID string, statuses []models.SessionStatus, limit int) ([]*models.AgentSession, error) {
-	var result []*models.AgentSession
-	for _, s := range m.sessions {
-		if projectID != "" && s.ProjectID != projectID {
-			continue
-		}
-		if len(statuses) > 0 {
-			found := false
-			for _, st := range statuses {
-				if s.Status == st {
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue
-			}
-		}
-		result = append(result, s)
-		if limit > 0 && len(result) >= limit {
-			break
-		}
+func (f *failingStore) ListIssues(ctx context.Context, filter store.IssueListFilter) ([]*models.Issue, error) {
+	if f.listIssuesErr != nil {
+		return nil, f.listIssuesErr
 	}
-	return result, nil
+	return f.MemoryStore.ListIssues(ctx, filter)
 }
-func (m *mockStore) ListAgentSessionsByWorktreePaths(_ context.Context, paths []string) ([]*models.AgentSession, error) {
-	pathSet := make(map[string]bool)
-	for _, p := range paths {
-		pathSet[p] = true
-	}
-	var result []*models.AgentSession
-	for _, s := range m.sessions {
-		if pathSet[s.WorktreePath] {
-			result = append(result, s)
-		}
+func (f *failingStore) CreateIssue(ctx context.Context, issue *models.Issue) error {
+	if f.createIssueErr != nil {
+		return f.createIssueErr
 	}
-	return result, nil
-}
-func (m *mockStore) DeleteStaleSessions(_ context.Context, _, _ string) (int64, error) {
-	return 0, nil
-}
-func (m *mockStore) DeleteAllStaleSessions(_ context.Context) (int64, error) {
-	return 0, nil
-}
-func (m *mockStore) Migrate(_ context.Context) error { return nil }
-func (m *mockStore) Close() error                    { return nil }
-
-func (m *mockStore) CreateIssueReview(_ context.Context, review *models.IssueReview) error {
-	if review.ID == "" {
-		review.ID = fmt.Sprintf("review-%d", len(m.reviews)+1)
-	}
-	review.CreatedAt = time.Now()
-	m.reviews = append(m.reviews, review)
-	m.createdReviews = append(m.createdReviews, review)
-	return nil
-}
-
-func (m *mockStore) ListIssueReviews(_ context.Context, issueID string) ([]*models.IssueReview, error) {
-	var result []*models.IssueReview
-	for _, r := range m.reviews {
-		if r.IssueID == issueID {
-			result = append(result, r)
-		}
-	}
-	return result, nil
+	return f.MemoryStore.CreateIssue(ctx, issue)
 }
 
 // mockGitClient implements git.Client for testing.
@@ -324,33 +69,71 @@ type mockGitClient struct {
 	branches   []string
 	remoteURL  string
 	latestTag  string
+	diff       string
+	changed    []string
+	blame      map[string][]git.BlameLine // by file
+	dirtyFiles []git.FileChange
 
 	// Error injection.
 	currentBranchErr error
 }
 
-func (m *mockGitClient) RepoRoot(_ string) (string, error) { return "/mock/repo", nil }
-func (m *mockGitClient) CurrentBranch(_ string) (string, error) {
+func (m *mockGitClient) RepoRoot(_ context.Context, _ string) (string, error) {
+	return "/mock/repo", nil
+}
+func (m *mockGitClient) CurrentBranch(_ context.Context, _ string) (string, error) {
 	if m.currentBranchErr != nil {
 		return "", m.currentBranchErr
 	}
 	return m.branch, nil
 }
-func (m *mockGitClient) LastCommitDate(_ string) (time.Time, error) { return m.lastCommit, nil }
-func (m *mockGitClient) LastCommitMessage(_ string) (string, error) { return m.commitMsg, nil }
-func (m *mockGitClient) LastCommitHash(_ string) (string, error)    { return m.commitHash, nil }
-func (m *mockGitClient) BranchList(_ string) ([]string, error)      { return m.branches, nil }
-func (m *mockGitClient) IsDirty(_ string) (bool, error)             { return m.dirty, nil }
-func (m *mockGitClient) WorktreeList(_ string) ([]git.WorktreeInfo, error) {
+func (m *mockGitClient) LastCommitDate(_ context.Context, _ string) (time.Time, error) {
+	return m.lastCommit, nil
+}
+func (m *mockGitClient) LastCommitMessage(_ context.Context, _ string) (string, error) {
+	return m.commitMsg, nil
+}
+func (m *mockGitClient) LastCommitHash(_ context.Context, _ string) (string, error) {
+	return m.commitHash, nil
+}
+func (m *mockGitClient) BranchList(_ context.Context, _ string) ([]string, error) {
+	return m.branches, nil
+}
+func (m *mockGitClient) IsDirty(_ context.Context, _ string) (bool, error) { return m.dirty, nil }
+func (m *mockGitClient) WorktreeList(_ context.Context, _ string) ([]git.WorktreeInfo, error) {
 	return nil, nil
 }
-func (m *mockGitClient) RemoteURL(_ string) (string, error) { return m.remoteURL, nil }
-func (m *mockGitClient) LatestTag(_ string) (string, error) { return m.latestTag, nil }
-func (m *mockGitClient) CommitCountSince(_, _ string) (int, error) { return 0, nil }
-func (m *mockGitClient) AheadBehind(_, _ string) (int, int, error) { return 0, 0, nil }
-func (m *mockGitClient) Diff(_, _, _ string) (string, error)       { return "", nil }
-func (m *mockGitClient) DiffStat(_, _, _ string) (string, error)   { return "", nil }
-func (m *mockGitClient) DiffNameOnly(_, _, _ string) ([]string, error) { return nil, nil }
+func (m *mockGitClient) RemoteURL(_ context.Context, _ string) (string, error) {
+	return m.remoteURL, nil
+}
+func (m *mockGitClient) LatestTag(_ context.Context, _ string) (string, error) {
+	return m.latestTag, nil
+}
+func (m *mockGitClient) CommitCountSince(_ context.Context, _, _ string) (int, error) { return 0, nil }
+func (m *mockGitClient) AheadBehind(_ context.Context, _, _ string) (int, int, error) {
+	return 0, 0, nil
+}
+func (m *mockGitClient) Diff(_ context.Context, _, _, _ string) (string, error)     { return m.diff, nil }
+func (m *mockGitClient) DiffStat(_ context.Context, _, _, _ string) (string, error) { return "", nil }
+func (m *mockGitClient) DiffNameOnly(_ context.Context, _, _, _ string) ([]string, error) {
+	return m.changed, nil
+}
+func (m *mockGitClient) WorktreeFingerprint(_ context.Context, _ string) (string, error) {
+	return "", nil
+}
+func (m *mockGitClient) CommitAll(_ context.Context, _, _ string) (string, error) { return "", nil }
+func (m *mockGitClient) DirtyFiles(_ context.Context, _ string) ([]git.FileChange, error) {
+	return m.dirtyFiles, nil
+}
+func (m *mockGitClient) CommitMessagesSince(_ context.Context, _, _ string) ([]string, error) {
+	return nil, nil
+}
+func (m *mockGitClient) CommitLog(_ context.Context, _, _, _ string) ([]git.Commit, error) {
+	return nil, nil
+}
+func (m *mockGitClient) Blame(_ context.Context, _, _, file string, _ []git.LineRange) ([]git.BlameLine, error) {
+	return m.blame[file], nil
+}
 
 // mockGHClient implements git.GitHubClient for testing.
 type mockGHClient struct {
@@ -382,9 +165,10 @@ func (m *mockGHClient) PagesInfo(_, _ string) (*git.PagesResult, error) {
 
 // mockWTClient implements wt.Client for testing.
 type mockWTClient struct {
-	created   []struct{ repo, branch string }
-	worktrees []wt.WorktreeInfo
-	createErr error
+	created    []struct{ repo, branch string }
+	worktrees  []wt.WorktreeInfo
+	createErr  error
+	terminalID string
 }
 
 func (m *mockWTClient) Create(repoPath, branch string) error {
@@ -394,20 +178,28 @@ func (m *mockWTClient) Create(repoPath, branch string) error {
 	m.created = append(m.created, struct{ repo, branch string }{repoPath, branch})
 	return nil
 }
-func (m *mockWTClient) List(_ string) ([]wt.WorktreeInfo, error)          { return m.worktrees, nil }
-func (m *mockWTClient) Delete(_, _ string) error                          { return nil }
-func (m *mockWTClient) Lifecycle() *lifecycle.Manager                     { return nil }
-func (m *mockWTClient) LifecycleForRepo(_ string) *lifecycle.Manager      { return nil }
+func (m *mockWTClient) CreateHeadless(repoPath, branch string) error {
+	return m.Create(repoPath, branch)
+}
+func (m *mockWTClient) CreateWith(repoPath, branch string, opts wt.CreateOptions) ([]string, error) {
+	return opts.SparsePaths, m.Create(repoPath, branch)
+}
+func (m *mockWTClient) List(_ string) ([]wt.WorktreeInfo, error)     { return m.worktrees, nil }
+func (m *mockWTClient) Delete(_, _ string) error                     { return nil }
+func (m *mockWTClient) TerminalID(_ string) string                   { return m.terminalID }
+func (m *mockWTClient) Lifecycle() *lifecycle.Manager                { return nil }
+func (m *mockWTClient) LifecycleForRepo(_ string) *lifecycle.Manager { return nil }
 
 // ---------------------------------------------------------------------------
 // Test helpers
 // ---------------------------------------------------------------------------
 
-// newTestServer creates a Server with mock dependencies and seed data.
-func newTestServer(t *testing.T) (*Server, *mockStore, *mockGitClient, *mockGHClient, *mockWTClient) {
+// newTestServer creates a Server on an empty memory store with mock git,
+// GitHub and worktree clients.
+func newTestServer(t *testing.T) (*Server, *store.MemoryStore, *mockGitClient, *mockGHClient, *mockWTClient) {
 	t.Helper()
 
-	ms := &mockStore{}
+	ms := store.NewMemoryStore()
 	gc := &mockGitClient{
 		branch:     "main",
 		dirty:      false,
@@ -450,8 +242,8 @@ func resultText(t *testing.T, result *mcpgo.CallToolResult) string {
 	return b.String()
 }
 
-// seedProject adds a project to the mock store and returns it.
-func seedProject(t *testing.T, ms *mockStore, name, path string) *models.Project {
+// seedProject adds a project to the store and returns it.
+func seedProject(t *testing.T, ms *store.MemoryStore, name, path string) *models.Project {
 	t.Helper()
 	p := &models.Project{
 		ID:        fmt.Sprintf("proj-%s", name),
@@ -459,30 +251,106 @@ func seedProject(t *testing.T, ms *mockStore, name, path string) *models.Project
 		Path:      path,
 		Language:  "go",
 		GroupName: "default",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
 	}
-	ms.projects = append(ms.projects, p)
+	seed(t, ms, p)
 	return p
 }
 
-// seedIssue adds an issue to the mock store and returns it.
-func seedIssue(t *testing.T, ms *mockStore, projectID, title string, status models.IssueStatus) *models.Issue {
+// seedIssue adds an issue to the store and returns it.
+func seedIssue(t *testing.T, ms *store.MemoryStore, projectID, title string, status models.IssueStatus) *models.Issue {
 	t.Helper()
 	i := &models.Issue{
-		ID:        fmt.Sprintf("issue-%s-%d", title, len(ms.issues)+1),
 		ProjectID: projectID,
 		Title:     title,
 		Status:    status,
 		Priority:  models.IssuePriorityMedium,
 		Type:      models.IssueTypeFeature,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
 	}
-	ms.issues = append(ms.issues, i)
+	seed(t, ms, i)
 	return i
 }
 
+// seed adds projects, issues, sessions, reviews, relations and notes to the
+// store, in the order given.
+func seed(t *testing.T, ms *store.MemoryStore, records ...any) {
+	t.Helper()
+	ctx := context.Background()
+	for _, r := range records {
+		var err error
+		switch r := r.(type) {
+		case *models.Project:
+			err = ms.CreateProject(ctx, r)
+		case *models.Issue:
+			err = ms.CreateIssue(ctx, r)
+		case *models.AgentSession:
+			err = ms.CreateAgentSession(ctx, r)
+		case *models.IssueReview:
+			err = ms.CreateIssueReview(ctx, r)
+		case *models.IssueRelation:
+			err = ms.CreateIssueRelation(ctx, r)
+		case *models.ProjectNote:
+			err = ms.CreateProjectNote(ctx, r)
+		default:
+			t.Fatalf("seed: unsupported record %T", r)
+		}
+		require.NoError(t, err)
+	}
+}
+
+// getIssue reads an issue back from the store.
+func getIssue(t *testing.T, ms *store.MemoryStore, id string) *models.Issue {
+	t.Helper()
+	issue, err := ms.GetIssue(context.Background(), id)
+	require.NoError(t, err)
+	return issue
+}
+
+// getProject reads a project back from the store.
+func getProject(t *testing.T, ms *store.MemoryStore, id string) *models.Project {
+	t.Helper()
+	p, err := ms.GetProject(context.Background(), id)
+	require.NoError(t, err)
+	return p
+}
+
+// getSession reads a session back from the store.
+func getSession(t *testing.T, ms *store.MemoryStore, id string) *models.AgentSession {
+	t.Helper()
+	sess, err := ms.GetAgentSession(context.Background(), id)
+	require.NoError(t, err)
+	return sess
+}
+
+// listIssues returns every issue in the store.
+func listIssues(t *testing.T, ms *store.MemoryStore) []*models.Issue {
+	t.Helper()
+	issues, err := ms.ListIssues(context.Background(), store.IssueListFilter{})
+	require.NoError(t, err)
+	return issues
+}
+
+// listSessions returns every session in the store, except those in skip.
+func listSessions(t *testing.T, ms *store.MemoryStore, skip ...string) []*models.AgentSession {
+	t.Helper()
+	all, err := ms.ListAgentSessions(context.Background(), "", 0)
+	require.NoError(t, err)
+	var sessions []*models.AgentSession
+	for _, sess := range all {
+		if !slices.Contains(skip, sess.ID) {
+			sessions = append(sessions, sess)
+		}
+	}
+	return sessions
+}
+
+// listReviews returns an issue's reviews.
+func listReviews(t *testing.T, ms *store.MemoryStore, issueID string) []*models.IssueReview {
+	t.Helper()
+	reviews, err := ms.ListIssueReviews(context.Background(), issueID)
+	require.NoError(t, err)
+	return reviews
+}
+
 // ---------------------------------------------------------------------------
 // Tests: MCPServer registration
 // ---------------------------------------------------------------------------
@@ -533,10 +401,10 @@ func TestHandleListProjects_WithGroupFilter(t *testing.T) {
 	srv, ms, _, _, _ := newTestServer(t)
 	ctx := context.Background()
 
-	p1 := seedProject(t, ms, "alpha", "/tmp/alpha")
-	p1.GroupName = "team-a"
-	p2 := seedProject(t, ms, "beta", "/tmp/beta")
-	p2.GroupName = "team-b"
+	seed(t, ms,
+		&models.Project{Name: "alpha", Path: "/tmp/alpha", GroupName: "team-a"},
+		&models.Project{Name: "beta", Path: "/tmp/beta", GroupName: "team-b"},
+	)
 
 	req := callToolReq("pm_list_projects", map[string]any{"group": "team-a"})
 	result, err := srv.handleListProjects(ctx, req)
@@ -550,11 +418,10 @@ func TestHandleListProjects_WithGroupFilter(t *testing.T) {
 }
 
 func TestHandleListProjects_StoreError(t *testing.T) {
-	srv, ms, _, _, _ := newTestServer(t)
+	_, ms, gc, ghc, wtc := newTestServer(t)
+	srv := NewServer(&failingStore{MemoryStore: ms, listProjectsErr: fmt.Errorf("db connection failed")}, gc, ghc, wtc, nil)
 	ctx := context.Background()
 
-	ms.listProjectsErr = fmt.Errorf("db connection failed")
-
 	req := callToolReq("pm_list_projects", nil)
 	result, err := srv.handleListProjects(ctx, req)
 	require.NoError(t, err, "handler should not return Go error; should wrap in result")
@@ -679,10 +546,10 @@ func TestHandleListIssues_FilterByPriority(t *testing.T) {
 	ctx := context.Background()
 
 	p := seedProject(t, ms, "myapp", "/tmp/myapp")
-	i1 := seedIssue(t, ms, p.ID, "High priority", models.IssueStatusOpen)
-	i1.Priority = models.IssuePriorityHigh
-	i2 := seedIssue(t, ms, p.ID, "Low priority", models.IssueStatusOpen)
-	i2.Priority = models.IssuePriorityLow
+	seed(t, ms,
+		&models.Issue{ProjectID: p.ID, Title: "High priority", Status: models.IssueStatusOpen, Priority: models.IssuePriorityHigh},
+		&models.Issue{ProjectID: p.ID, Title: "Low priority", Status: models.IssueStatusOpen, Priority: models.IssuePriorityLow},
+	)
 
 	req := callToolReq("pm_list_issues", map[string]any{"priority": "high"})
 	result, err := srv.handleListIssues(ctx, req)
@@ -707,11 +574,10 @@ func TestHandleListIssues_Empty(t *testing.T) {
 }
 
 func TestHandleListIssues_StoreError(t *testing.T) {
-	srv, ms, _, _, _ := newTestServer(t)
+	_, ms, gc, ghc, wtc := newTestServer(t)
+	srv := NewServer(&failingStore{MemoryStore: ms, listIssuesErr: fmt.Errorf("database locked")}, gc, ghc, wtc, nil)
 	ctx := context.Background()
 
-	ms.listIssuesErr = fmt.Errorf("database locked")
-
 	req := callToolReq("pm_list_issues", nil)
 	result, err := srv.handleListIssues(ctx, req)
 	require.NoError(t, err)
@@ -744,8 +610,9 @@ func TestHandleCreateIssue(t *testing.T) {
 	assert.False(t, result.IsError)
 
 	// Verify issue was created in store.
-	require.Len(t, ms.createdIssues, 1)
-	created := ms.createdIssues[0]
+	issues := listIssues(t, ms)
+	require.Len(t, issues, 1)
+	created := issues[0]
 	assert.Equal(t, "Implement caching", created.Title)
 	assert.Equal(t, "Add Redis caching layer", created.Description)
 	assert.Equal(t, models.IssuePriorityHigh, created.Priority)
@@ -769,14 +636,36 @@ func TestHandleCreateIssue_DefaultPriorityAndType(t *testing.T) {
 	require.NotNil(t, result)
 	assert.False(t, result.IsError)
 
-	require.Len(t, ms.createdIssues, 1)
-	created := ms.createdIssues[0]
+	issues := listIssues(t, ms)
+	require.Len(t, issues, 1)
+	created := issues[0]
 	assert.Equal(t, "Quick fix", created.Title)
 	// Default values should be applied.
 	assert.Equal(t, models.IssuePriorityMedium, created.Priority)
 	assert.Equal(t, models.IssueTypeFeature, created.Type)
 }
 
+func TestHandleCreateIssue_InvalidEnums(t *testing.T) {
+	srv, ms, _, _, _ := newTestServer(t)
+	ctx := context.Background()
+
+	seedProject(t, ms, "myapp", "/tmp/myapp")
+
+	for _, args := range []map[string]any{
+		{"project": "myapp", "title": "x", "priority": "urgent"},
+		{"project": "myapp", "title": "x", "type": "epic"},
+	} {
+		result, err := srv.handleCreateIssue(ctx, callToolReq("pm_create_issue", args))
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%v", args)
+	}
+	assert.Empty(t, listIssues(t, ms))
+
+	result, err := srv.handleListIssues(ctx, callToolReq("pm_list_issues", map[string]any{"status": "opne"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
 func TestHandleCreateIssue_MissingTitle(t *testing.T) {
 	srv, ms, _, _, _ := newTestServer(t)
 	ctx := context.Background()
@@ -823,11 +712,11 @@ func TestHandleCreateIssue_UnknownProject(t *testing.T) {
 }
 
 func TestHandleCreateIssue_StoreError(t *testing.T) {
-	srv, ms, _, _, _ := newTestServer(t)
+	_, ms, gc, ghc, wtc := newTestServer(t)
+	srv := NewServer(&failingStore{MemoryStore: ms, createIssueErr: fmt.Errorf("disk full")}, gc, ghc, wtc, nil)
 	ctx := context.Background()
 
 	seedProject(t, ms, "myapp", "/tmp/myapp")
-	ms.createIssueErr = fmt.Errorf("disk full")
 
 	req := callToolReq("pm_create_issue", map[string]any{
 		"project": "myapp",
@@ -862,8 +751,7 @@ func TestHandleUpdateIssue_ChangeStatus(t *testing.T) {
 	require.NotNil(t, result)
 	assert.False(t, result.IsError)
 
-	require.Len(t, ms.updatedIssues, 1)
-	assert.Equal(t, models.IssueStatusInProgress, ms.updatedIssues[0].Status)
+	assert.Equal(t, models.IssueStatusInProgress, getIssue(t, ms, issue.ID).Status)
 }
 
 func TestHandleUpdateIssue_ChangePriority(t *testing.T) {
@@ -883,8 +771,7 @@ func TestHandleUpdateIssue_ChangePriority(t *testing.T) {
 	require.NotNil(t, result)
 	assert.False(t, result.IsError)
 
-	require.Len(t, ms.updatedIssues, 1)
-	assert.Equal(t, models.IssuePriorityHigh, ms.updatedIssues[0].Priority)
+	assert.Equal(t, models.IssuePriorityHigh, getIssue(t, ms, issue.ID).Priority)
 }
 
 func TestHandleUpdateIssue_ChangeTitle(t *testing.T) {
@@ -904,8 +791,31 @@ func TestHandleUpdateIssue_ChangeTitle(t *testing.T) {
 	require.NotNil(t, result)
 	assert.False(t, result.IsError)
 
-	require.Len(t, ms.updatedIssues, 1)
-	assert.Equal(t, "New title", ms.updatedIssues[0].Title)
+	assert.Equal(t, "New title", getIssue(t, ms, issue.ID).Title)
+}
+
+func TestHandleUpdateIssue_AcceptanceCriteria(t *testing.T) {
+	srv, ms, _, _, _ := newTestServer(t)
+	ctx := context.Background()
+
+	p := seedProject(t, ms, "myapp", "/tmp/myapp")
+	issue := seedIssue(t, ms, p.ID, "Add logout", models.IssueStatusOpen)
+
+	result, err := srv.handleUpdateIssue(ctx, callToolReq("pm_update_issue", map[string]any{
+		"issue_id":            issue.ID,
+		"acceptance_criteria": "Logout clears the session cookie\n\n  Logout redirects to the login page  \n",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Equal(t, []string{"Logout clears the session cookie", "Logout redirects to the login page"}, getIssue(t, ms, issue.ID).AcceptanceCriteria)
+
+	result, err = srv.handleUpdateIssue(ctx, callToolReq("pm_update_issue", map[string]any{
+		"issue_id":            issue.ID,
+		"acceptance_criteria": "",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Empty(t, getIssue(t, ms, issue.ID).AcceptanceCriteria)
 }
 
 func TestHandleUpdateIssue_MissingID(t *testing.T) {
@@ -954,8 +864,7 @@ func TestHandleUpdateIssue_CloseIssue(t *testing.T) {
 	require.NotNil(t, result)
 	assert.False(t, result.IsError)
 
-	require.Len(t, ms.updatedIssues, 1)
-	assert.Equal(t, models.IssueStatusClosed, ms.updatedIssues[0].Status)
+	assert.Equal(t, models.IssueStatusClosed, getIssue(t, ms, issue.ID).Status)
 }
 
 // ---------------------------------------------------------------------------
@@ -1042,7 +951,7 @@ func TestHandleLaunchAgent(t *testing.T) {
 	issue := seedIssue(t, ms, p.ID, "Implement feature X", models.IssueStatusOpen)
 
 	req := callToolReq("pm_launch_agent", map[string]any{
-		"project": "myapp",
+		"project":  "myapp",
 		"issue_id": issue.ID,
 	})
 
@@ -1056,13 +965,35 @@ func TestHandleLaunchAgent(t *testing.T) {
 	assert.Equal(t, "/tmp/myapp", wtc.created[0].repo)
 
 	// Verify agent session was recorded.
-	require.Len(t, ms.createdSessions, 1)
-	session := ms.createdSessions[0]
+	sessions := listSessions(t, ms)
+	require.Len(t, sessions, 1)
+	session := sessions[0]
 	assert.Equal(t, p.ID, session.ProjectID)
 	assert.Equal(t, issue.ID, session.IssueID)
 	assert.Equal(t, models.SessionStatusActive, session.Status)
 }
 
+func TestHandleLaunchAgent_RecordsTerminal(t *testing.T) {
+	srv, ms, _, _, wtc := newTestServer(t)
+	wtc.terminalID = "w0t0p0:ITERM-GUID"
+	ctx := context.Background()
+
+	p := seedProject(t, ms, "myapp", "/tmp/myapp")
+	issue := seedIssue(t, ms, p.ID, "Implement feature X", models.IssueStatusOpen)
+
+	result, err := srv.handleLaunchAgent(ctx, callToolReq("pm_launch_agent", map[string]any{
+		"project":  "myapp",
+		"issue_id": issue.ID,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	sessions := listSessions(t, ms)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "iterm", sessions[0].TerminalBackend)
+	assert.Equal(t, "w0t0p0:ITERM-GUID", sessions[0].TerminalID)
+}
+
 func TestHandleLaunchAgent_MissingProject(t *testing.T) {
 	srv, _, _, _, _ := newTestServer(t)
 	ctx := context.Background()
@@ -1084,7 +1015,7 @@ func TestHandleLaunchAgent_MissingIssue(t *testing.T) {
 	seedProject(t, ms, "myapp", "/tmp/myapp")
 
 	req := callToolReq("pm_launch_agent", map[string]any{
-		"project": "myapp",
+		"project":  "myapp",
 		"issue_id": "nonexistent-issue",
 	})
 
@@ -1104,7 +1035,7 @@ func TestHandleLaunchAgent_WorktreeCreateFails(t *testing.T) {
 	wtc.createErr = fmt.Errorf("branch already exists")
 
 	req := callToolReq("pm_launch_agent", map[string]any{
-		"project": "myapp",
+		"project":  "myapp",
 		"issue_id": issue.ID,
 	})
 
@@ -1123,9 +1054,9 @@ func TestHandleLaunchAgent_WithCustomBranch(t *testing.T) {
 	issue := seedIssue(t, ms, p.ID, "Custom branch issue", models.IssueStatusOpen)
 
 	req := callToolReq("pm_launch_agent", map[string]any{
-		"project": "myapp",
+		"project":  "myapp",
 		"issue_id": issue.ID,
-		"branch":  "custom/my-branch",
+		"branch":   "custom/my-branch",
 	})
 
 	result, err := srv.handleLaunchAgent(ctx, req)
@@ -1145,7 +1076,7 @@ func TestCloseAgentTool_Idle(t *testing.T) {
 	srv, ms, _, _, _ := newTestServer(t)
 	p := seedProject(t, ms, "myapp", "/tmp/myapp")
 
-	ms.sessions = append(ms.sessions, &models.AgentSession{
+	seed(t, ms, &models.AgentSession{
 		ID:        "sess-123",
 		ProjectID: p.ID,
 		IssueID:   "",
@@ -1168,7 +1099,7 @@ func TestCloseAgentTool_Completed(t *testing.T) {
 	p := seedProject(t, ms, "myapp", "/tmp/myapp")
 	issue := seedIssue(t, ms, p.ID, "Fix bug", models.IssueStatusInProgress)
 
-	ms.sessions = append(ms.sessions, &models.AgentSession{
+	seed(t, ms, &models.AgentSession{
 		ID:        "sess-123",
 		ProjectID: p.ID,
 		IssueID:   issue.ID,
@@ -1184,7 +1115,36 @@ func TestCloseAgentTool_Completed(t *testing.T) {
 
 	text := resultText(t, result)
 	assert.Contains(t, text, `"status":"completed"`)
-	assert.Equal(t, models.IssueStatusDone, ms.issues[0].Status)
+	assert.Equal(t, models.IssueStatusDone, getIssue(t, ms, issue.ID).Status)
+}
+
+func TestCloseAgentTool_CompletedReportsCloseCheck(t *testing.T) {
+	srv, ms, mg, _, _ := newTestServer(t)
+	p := seedProject(t, ms, "myapp", "/tmp/myapp")
+	mg.dirty = true
+	mg.dirtyFiles = []git.FileChange{{Path: "go.sum", Modified: true, Additions: 3, Deletions: 1}}
+
+	seed(t, ms, &models.AgentSession{
+		ID:           "sess-123",
+		ProjectID:    p.ID,
+		Branch:       "feature/deps",
+		WorktreePath: t.TempDir(),
+		Status:       models.SessionStatusActive,
+	})
+
+	result, err := srv.handleCloseAgent(context.Background(), callToolReq("pm_close_agent", map[string]any{"session_id": "sess-123", "status": "completed"}))
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+
+	var out struct {
+		Status     string           `json:"status"`
+		CloseCheck agent.CloseCheck `json:"close_check"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+	assert.Equal(t, "completed", out.Status)
+	assert.True(t, out.CloseCheck.IsDirty)
+	assert.Equal(t, mg.dirtyFiles, out.CloseCheck.DirtyFiles)
+	assert.Equal(t, agent.DirtyLockfileOnly, out.CloseCheck.DirtyKind)
 }
 
 func TestCloseAgentTool_MissingSessionID(t *testing.T) {
@@ -1202,20 +1162,21 @@ func TestCloseAgentTool_MissingSessionID(t *testing.T) {
 // ---------------------------------------------------------------------------
 
 func TestPrepareReview(t *testing.T) {
-	ms := &mockStore{
-		projects: []*models.Project{{ID: "p1", Name: "myproject", Path: "/tmp/myproject", Language: "go"}},
-		issues: []*models.Issue{{
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "myproject", Path: "/tmp/myproject", Language: "go"},
+		&models.Issue{
 			ID: "ISSUE001", ProjectID: "p1", Title: "Add login",
 			Description: "Implement login page", Body: "Full requirements...",
 			Status: models.IssueStatusDone, Priority: models.IssuePriorityMedium,
 			Type: models.IssueTypeFeature,
-		}},
-		sessions: []*models.AgentSession{{
+		},
+		&models.AgentSession{
 			ID: "s1", ProjectID: "p1", IssueID: "ISSUE001",
 			Branch: "feature/add-login", WorktreePath: "/tmp/myproject-feature-add-login",
 			Status: models.SessionStatusCompleted, CommitCount: 3,
-		}},
-	}
+		},
+	)
 	mg := &mockGitClient{
 		branches: []string{"main", "feature/add-login"},
 	}
@@ -1236,20 +1197,676 @@ func TestPrepareReview(t *testing.T) {
 	assert.Equal(t, false, out["ui_review_needed"])
 }
 
+func TestPrepareReview_AcceptanceCriteria(t *testing.T) {
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "myproject"},
+		&models.Issue{
+			ID: "ISSUE001", ProjectID: "p1", Title: "Add logout",
+			Status: models.IssueStatusDone, Priority: models.IssuePriorityMedium,
+			Type:               models.IssueTypeFeature,
+			AcceptanceCriteria: []string{"Logout clears the session cookie"},
+		},
+		&models.IssueReview{
+			ID: "r1", IssueID: "ISSUE001", Verdict: models.ReviewVerdictFail,
+			Criteria: []models.CriterionResult{{Criterion: "Logout clears the session cookie", Note: "cookie kept"}},
+		},
+	)
+	srv := NewServer(ms, nil, nil, nil, nil)
+
+	result, err := srv.handlePrepareReview(context.Background(), callToolReq("pm_prepare_review", map[string]any{"issue_id": "ISSUE001"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var out struct {
+		AcceptanceCriteria []struct {
+			Number    int    `json:"number"`
+			Criterion string `json:"criterion"`
+		} `json:"acceptance_criteria"`
+		ReviewHistory []struct {
+			Criteria []map[string]any `json:"criteria"`
+		} `json:"review_history"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+	require.Len(t, out.AcceptanceCriteria, 1)
+	assert.Equal(t, 1, out.AcceptanceCriteria[0].Number)
+	assert.Equal(t, "Logout clears the session cookie", out.AcceptanceCriteria[0].Criterion)
+	require.Len(t, out.ReviewHistory, 1)
+	assert.Equal(t, false, out.ReviewHistory[0].Criteria[0]["passed"])
+}
+
 // ---------------------------------------------------------------------------
 // Tests: pm_save_review
 // ---------------------------------------------------------------------------
 
+func TestPrepareReview_IncludesConventions(t *testing.T) {
+	projDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projDir, "AGENTS.md"), []byte("Prefer small PRs.\n"), 0o644))
+
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "myproject", Path: projDir, Conventions: "# AGENTS.md\n\nstale"},
+		&models.Issue{
+			ID: "ISSUE001", ProjectID: "p1", Title: "Add login",
+			Status: models.IssueStatusDone, Priority: models.IssuePriorityMedium,
+			Type: models.IssueTypeFeature,
+		},
+	)
+	srv := NewServer(ms, &mockGitClient{}, nil, nil, nil)
+
+	result, err := srv.handlePrepareReview(context.Background(), callToolReq("pm_prepare_review", map[string]any{
+		"issue_id": "ISSUE001",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var out map[string]any
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+	assert.Equal(t, "# AGENTS.md\n\nPrefer small PRs.", out["project"].(map[string]any)["conventions"])
+	assert.Equal(t, "# AGENTS.md\n\nPrefer small PRs.", getProject(t, ms, "p1").Conventions, "stale cache should be updated")
+}
+
+func TestPrepareReview_IncludeBlame(t *testing.T) {
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "myproject", Path: "/tmp/myproject"},
+		&models.Issue{
+			ID: "ISSUE001", ProjectID: "p1", Title: "Tweak parser",
+			Status: models.IssueStatusDone, Priority: models.IssuePriorityMedium,
+			Type: models.IssueTypeFeature,
+		},
+	)
+	old := time.Now().AddDate(-2, 0, 0)
+	mg := &mockGitClient{
+		diff: "diff --git a/parser.go b/parser.go\n--- a/parser.go\n+++ b/parser.go\n@@ -10,7 +10,8 @@ func parse() {\n" +
+			"diff --git a/new.go b/new.go\nnew file mode 100644\n--- /dev/null\n+++ b/new.go\n@@ -0,0 +1,3 @@\n",
+		blame: map[string][]git.BlameLine{
+			"parser.go": {
+				{Commit: "abcdef1234567", Author: "Ada", Time: old, Summary: "Initial parser"},
+				{Commit: "abcdef1234567", Author: "Ada", Time: old, Summary: "Initial parser"},
+				{Commit: "1234567abcdef", Author: "Bob", Time: old.AddDate(0, 1, 0), Summary: "Handle tabs"},
+			},
+		},
+	}
+	srv := NewServer(ms, mg, nil, nil, nil)
+
+	// Blame is opt-in
+	result, err := srv.handlePrepareReview(context.Background(), callToolReq("pm_prepare_review", map[string]any{
+		"issue_id": "ISSUE001",
+	}))
+	require.NoError(t, err)
+	var out map[string]any
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+	assert.NotContains(t, out, "blame")
+
+	result, err = srv.handlePrepareReview(context.Background(), callToolReq("pm_prepare_review", map[string]any{
+		"issue_id":      "ISSUE001",
+		"include_blame": "true",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var withBlame struct {
+		Blame []git.BlameSummary `json:"blame"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &withBlame))
+	require.Len(t, withBlame.Blame, 1, "added files have no prior lines to blame")
+	b := withBlame.Blame[0]
+	assert.Equal(t, "parser.go", b.File)
+	assert.Equal(t, 3, b.Lines)
+	assert.Equal(t, []git.AuthorLines{{Name: "Ada", Lines: 2}, {Name: "Bob", Lines: 1}}, b.Authors)
+	assert.Equal(t, "1234567 Handle tabs", b.LastCommit)
+	assert.Greater(t, b.StableDays, 300)
+}
+
+func TestPrepareReview_PreAnalysis(t *testing.T) {
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "myproject", Path: "/tmp/myproject"},
+		&models.Issue{
+			ID: "ISSUE001", ProjectID: "p1", Title: "Tweak parser",
+			Status: models.IssueStatusDone, Priority: models.IssuePriorityMedium,
+			Type: models.IssueTypeFeature,
+		},
+	)
+	mg := &mockGitClient{
+		diff:    "diff --git a/parser.go b/parser.go\n--- a/parser.go\n+++ b/parser.go\n@@ -1 +1 @@\n-a\n+b\n",
+		changed: []string{"parser.go", "lex/lexer.go", "lex/lexer_test.go", "README.md"},
+	}
+	srv := NewServer(ms, mg, nil, nil, nil)
+
+	// Off unless asked for or enabled
+	result, err := srv.handlePrepareReview(context.Background(), callToolReq("pm_prepare_review", map[string]any{"issue_id": "ISSUE001"}))
+	require.NoError(t, err)
+	var out map[string]any
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+	assert.NotContains(t, out, "untested_files")
+	assert.NotContains(t, out, "pre_analysis_error")
+
+	// Without an LLM the heuristics are still returned and the call succeeds
+	WithReviewPreAnalysis(true)(srv)
+	result, err = srv.handlePrepareReview(context.Background(), callToolReq("pm_prepare_review", map[string]any{"issue_id": "ISSUE001"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	out = nil
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+	assert.Equal(t, []any{"parser.go"}, out["untested_files"])
+	assert.Contains(t, out["pre_analysis_error"], "LLM not configured")
+	assert.NotContains(t, out, "pre_analysis")
+
+	result, err = srv.handlePrepareReview(context.Background(), callToolReq("pm_prepare_review", map[string]any{"issue_id": "ISSUE001", "pre_analysis": "false"}))
+	require.NoError(t, err)
+	out = nil
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+	assert.NotContains(t, out, "untested_files")
+}
+
+func TestPrepareReview_LargeDiff(t *testing.T) {
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "myproject", Path: "/tmp/myproject"},
+		&models.Issue{
+			ID: "ISSUE001", ProjectID: "p1", Title: "Big refactor",
+			Status: models.IssueStatusDone, Priority: models.IssuePriorityMedium,
+			Type: models.IssueTypeFeature,
+		},
+	)
+	small := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1,2 +1,2 @@\n-old\n+new\n"
+	big := "diff --git a/b.go b/b.go\n--- a/b.go\n+++ b/b.go\n@@ -1,0 +1,200 @@\n" + strings.Repeat("+generated line\n", 200)
+	srv := NewServer(ms, &mockGitClient{diff: small + big}, nil, nil, nil)
+
+	// By default only the summary is returned
+	result, err := srv.handlePrepareReview(context.Background(), callToolReq("pm_prepare_review", map[string]any{
+		"issue_id": "ISSUE001",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	var summary map[string]any
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &summary))
+	assert.NotContains(t, summary, "diff")
+	assert.Equal(t, float64(len(small+big)), summary["diff_bytes"])
+	assert.Len(t, summary["file_diffs"], 2)
+
+	result, err = srv.handlePrepareReview(context.Background(), callToolReq("pm_prepare_review", map[string]any{
+		"issue_id":       "ISSUE001",
+		"include_diff":   "true",
+		"max_diff_bytes": "500",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var out struct {
+		Diff          string         `json:"diff"`
+		DiffTruncated bool           `json:"diff_truncated"`
+		FileDiffs     []git.FileDiff `json:"file_diffs"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+	assert.True(t, out.DiffTruncated)
+	assert.LessOrEqual(t, len(out.Diff), 600)
+	assert.Contains(t, out.Diff, "... [truncated")
+	require.Len(t, out.FileDiffs, 2)
+	assert.Equal(t, "a.go", out.FileDiffs[0].File)
+	assert.Equal(t, 1, out.FileDiffs[0].Additions)
+	assert.Equal(t, 1, out.FileDiffs[0].Deletions)
+	assert.Equal(t, "b.go", out.FileDiffs[1].File)
+	assert.Equal(t, 200, out.FileDiffs[1].Additions)
+
+	// A single file can be fetched on its own
+	result, err = srv.handlePrepareReview(context.Background(), callToolReq("pm_prepare_review", map[string]any{
+		"issue_id": "ISSUE001",
+		"file":     "a.go",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	var file map[string]any
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &file))
+	assert.Equal(t, "a.go", file["file"])
+	assert.Equal(t, strings.TrimSuffix(small, "\n"), file["diff"])
+	assert.Equal(t, false, file["diff_truncated"])
+	assert.NotContains(t, file, "issue")
+
+	result, err = srv.handlePrepareReview(context.Background(), callToolReq("pm_prepare_review", map[string]any{
+		"issue_id": "ISSUE001",
+		"file":     "missing.go",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "file not in diff")
+
+	result, err = srv.handlePrepareReview(context.Background(), callToolReq("pm_prepare_review", map[string]any{
+		"issue_id":       "ISSUE001",
+		"max_diff_bytes": "-1",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestPrepareReview_MonorepoPaths(t *testing.T) {
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "api", Path: "/mock/repo/services/api"},
+		&models.Issue{
+			ID: "ISSUE001", ProjectID: "p1", Title: "Settings page",
+			Status: models.IssueStatusDone, Priority: models.IssuePriorityMedium,
+			Type: models.IssueTypeFeature,
+		},
+		&models.AgentSession{ID: "s1", ProjectID: "p1", IssueID: "ISSUE001", Status: models.SessionStatusCompleted, Branch: "feature/settings", WorktreePath: "/mock/wt/settings"},
+	)
+	diff := "diff --git a/services/api/ui/app.tsx b/services/api/ui/app.tsx\n--- a/services/api/ui/app.tsx\n+++ b/services/api/ui/app.tsx\n@@ -1 +1 @@\n-old\n+new\n"
+	srv := NewServer(ms, &mockGitClient{diff: diff, changed: []string{"services/api/ui/app.tsx"}}, nil, nil, nil)
+
+	result, err := srv.handlePrepareReview(context.Background(), callToolReq("pm_prepare_review", map[string]any{"issue_id": "ISSUE001"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	var out map[string]any
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+	assert.Equal(t, "services/api", out["project"].(map[string]any)["subpath"])
+	assert.Equal(t, true, out["ui_review_needed"], "ui/ is judged from the project directory")
+
+	// Agents may name the file relative to the project or as an absolute worktree path.
+	for _, name := range []string{"ui/app.tsx", "services/api/ui/app.tsx", "/mock/wt/settings/services/api/ui/app.tsx"} {
+		result, err = srv.handlePrepareReview(context.Background(), callToolReq("pm_prepare_review", map[string]any{
+			"issue_id": "ISSUE001",
+			"file":     name,
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, name)
+		var file map[string]any
+		require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &file))
+		assert.Equal(t, "services/api/ui/app.tsx", file["file"], name)
+	}
+}
+
+func TestPrepareReview_UIReviewRules(t *testing.T) {
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "shop", Path: "/mock/repo", Language: "python"},
+		&models.Issue{
+			ID: "ISSUE001", ProjectID: "p1", Title: "Checkout page",
+			Status: models.IssueStatusDone, Priority: models.IssuePriorityMedium,
+			Type: models.IssueTypeFeature,
+		},
+	)
+	gc := &mockGitClient{changed: []string{"shop/views.py", "shop/templates/checkout.html", "shop/templates/email.j2"}}
+	srv := NewServer(ms, gc, nil, nil, nil)
+	prepare := func() map[string]any {
+		result, err := srv.handlePrepareReview(context.Background(), callToolReq("pm_prepare_review", map[string]any{"issue_id": "ISSUE001"}))
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		var out map[string]any
+		require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+		return out
+	}
+
+	out := prepare()
+	assert.Equal(t, true, out["ui_review_needed"], "templates/ is a default rule")
+	review := out["ui_review"].(map[string]any)
+	assert.Equal(t, false, review["custom"])
+	assert.Contains(t, review["rules"], "*.j2", "python defaults")
+	assert.Equal(t, []any{
+		map[string]any{"file": "shop/templates/checkout.html", "rule": "templates/"},
+		map[string]any{"file": "shop/templates/email.j2", "rule": "templates/"},
+	}, review["matches"])
+
+	// The project's own rules replace the defaults.
+	p := getProject(t, ms, "p1")
+	p.UIReviewPaths = "*.css"
+	require.NoError(t, ms.UpdateProject(context.Background(), p))
+	out = prepare()
+	assert.Equal(t, false, out["ui_review_needed"])
+	review = out["ui_review"].(map[string]any)
+	assert.Equal(t, true, review["custom"])
+	assert.Equal(t, []any{"*.css"}, review["rules"])
+	assert.Equal(t, []any{}, review["matches"])
+}
+
+func TestUpdateProject_UIReviewPaths(t *testing.T) {
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "myproject", Path: "/tmp/myproject"},
+	)
+	srv := NewServer(ms, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	result, err := srv.handleUpdateProject(ctx, callToolReq("pm_update_project", map[string]any{
+		"project":         "myproject",
+		"ui_review_paths": "*.tsx, templates/",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, "*.tsx,templates/", getProject(t, ms, "p1").UIReviewPaths)
+
+	result, err = srv.handleUpdateProject(ctx, callToolReq("pm_update_project", map[string]any{
+		"project":         "myproject",
+		"ui_review_paths": "[ui",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, err = srv.handleUpdateProject(ctx, callToolReq("pm_update_project", map[string]any{
+		"project":         "myproject",
+		"ui_review_paths": "none",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Empty(t, getProject(t, ms, "p1").UIReviewPaths)
+}
+
+func TestRelateIssues_IncludedInPrepareReview(t *testing.T) {
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "app", Path: "/tmp/app"},
+		&models.Project{ID: "p2", Name: "lib", Path: "/tmp/lib"},
+		&models.Issue{ID: "REGRESS01", ProjectID: "p1", Title: "Login broken again", Status: models.IssueStatusInProgress, Type: models.IssueTypeBug},
+		&models.Issue{ID: "ORIGBUG01", ProjectID: "p2", Title: "Token refresh bug", Body: "Refresh fails after 1h", Status: models.IssueStatusDone, Type: models.IssueTypeBug},
+	)
+	srv := NewServer(ms, &mockGitClient{}, nil, nil, nil)
+	ctx := context.Background()
+
+	result, err := srv.handleRelateIssues(ctx, callToolReq("pm_relate_issues", map[string]any{
+		"issue_id":        "REGRESS",
+		"type":            "caused_by",
+		"target_issue_id": "ORIGBUG",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	relations, err := ms.ListIssueRelations(ctx, "REGRESS01")
+	require.NoError(t, err)
+	require.Len(t, relations, 1)
+	assert.Equal(t, "REGRESS01", relations[0].SourceIssueID)
+
+	// Invalid type is rejected
+	result, err = srv.handleRelateIssues(ctx, callToolReq("pm_relate_issues", map[string]any{
+		"issue_id": "REGRESS01", "type": "parent_of", "target_issue_id": "ORIGBUG01",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	// The regression's review context includes the originating bug
+	result, err = srv.handlePrepareReview(ctx, callToolReq("pm_prepare_review", map[string]any{"issue_id": "REGRESS01"}))
+	require.NoError(t, err)
+	var out map[string]any
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+	related := out["related_issues"].([]any)
+	require.Len(t, related, 1)
+	rel := related[0].(map[string]any)
+	assert.Equal(t, "caused by", rel["relation"])
+	assert.Equal(t, "lib", rel["project"])
+	assert.Equal(t, "Refresh fails after 1h", rel["body"])
+
+	// Seen from the other side the label is inverted
+	result, err = srv.handlePrepareReview(ctx, callToolReq("pm_prepare_review", map[string]any{"issue_id": "ORIGBUG01"}))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+	assert.Equal(t, "causes", out["related_issues"].([]any)[0].(map[string]any)["relation"])
+
+	// Remove
+	result, err = srv.handleRelateIssues(ctx, callToolReq("pm_relate_issues", map[string]any{
+		"issue_id": "REGRESS01", "type": "caused_by", "target_issue_id": "ORIGBUG01", "remove": "true",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	relations, err = ms.ListIssueRelations(ctx, "REGRESS01")
+	require.NoError(t, err)
+	assert.Empty(t, relations)
+}
+
+func TestGetIssue(t *testing.T) {
+	ctx := context.Background()
+	ended := time.Now().Add(-time.Hour)
+	abandoned := &models.AgentSession{ID: "sess-1", ProjectID: "p1", IssueID: "LOGIN0001", Branch: "feature/login", Status: models.SessionStatusAbandoned, EndedAt: &ended}
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "app"},
+		&models.Issue{ID: "LOGIN0001", ProjectID: "p1", Title: "Login broken", Status: models.IssueStatusInProgress, AcceptanceCriteria: []string{"Login works"}, Assignee: "dev"},
+		&models.Issue{ID: "LOGOUT001", ProjectID: "p1", Title: "Logout broken", Status: models.IssueStatusOpen},
+		abandoned,
+		&models.AgentSession{ID: "sess-2", ProjectID: "p1", IssueID: "LOGOUT001", Branch: "feature/logout", Status: models.SessionStatusActive},
+		&models.IssueReview{ID: "rev-1", IssueID: "LOGIN0001", SessionID: "sess-1", Verdict: models.ReviewVerdictFail, Summary: "No tests", FailureReasons: []string{"missing tests"},
+			Criteria: []models.CriterionResult{{Criterion: "Login works", Passed: false, Note: "still 500s"}}},
+		&models.IssueRelation{ID: "rel-1", SourceIssueID: "LOGOUT001", TargetIssueID: "LOGIN0001", Type: models.RelationBlocks},
+	)
+	require.NoError(t, ms.UpdateAgentSession(ctx, abandoned), "record when it ended")
+	tag := &models.Tag{Name: "auth"}
+	require.NoError(t, ms.CreateTag(ctx, tag))
+	require.NoError(t, ms.TagIssue(ctx, "LOGIN0001", tag.ID))
+	srv := NewServer(ms, &mockGitClient{}, nil, nil, nil)
+
+	result, err := srv.handleGetIssue(ctx, callToolReq("pm_get_issue", map[string]any{"issue_id": "LOGIN"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	var out struct {
+		Issue    issueOut         `json:"issue"`
+		Project  string           `json:"project"`
+		Assignee string           `json:"assignee"`
+		Sessions []map[string]any `json:"sessions"`
+		Reviews  []map[string]any `json:"reviews"`
+		Related  []map[string]any `json:"related_issues"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+	assert.Equal(t, "LOGIN0001", out.Issue.ID)
+	assert.Equal(t, []string{"auth"}, out.Issue.Tags)
+	assert.Equal(t, []string{"Login works"}, out.Issue.AcceptanceCriteria)
+	assert.Equal(t, "app", out.Project)
+	assert.Equal(t, "dev", out.Assignee)
+
+	require.Len(t, out.Sessions, 1, "only the issue's sessions")
+	assert.Equal(t, "sess-1", out.Sessions[0]["id"])
+	assert.Contains(t, out.Sessions[0], "ended_at")
+
+	require.Len(t, out.Reviews, 1)
+	assert.Equal(t, "fail", out.Reviews[0]["verdict"])
+	assert.Equal(t, []any{"missing tests"}, out.Reviews[0]["failure_reasons"])
+	assert.Len(t, out.Reviews[0]["criteria"], 1)
+
+	require.Len(t, out.Related, 1)
+	assert.Equal(t, "LOGOUT001", out.Related[0]["id"])
+
+	// An issue with no history still returns empty lists
+	result, err = srv.handleGetIssue(ctx, callToolReq("pm_get_issue", map[string]any{"issue_id": "LOGOUT001"}))
+	require.NoError(t, err)
+	assert.Contains(t, resultText(t, result), `"reviews":[]`)
+
+	result, err = srv.handleGetIssue(ctx, callToolReq("pm_get_issue", map[string]any{"issue_id": "NOPE"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestWatchAndUnwatchIssue(t *testing.T) {
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "app"},
+		&models.Issue{ID: "WATCHED01", ProjectID: "p1", Title: "Flaky login", Status: models.IssueStatusOpen},
+	)
+	srv := NewServer(ms, &mockGitClient{}, nil, nil, nil)
+	ctx := context.Background()
+
+	result, err := srv.handleWatchIssue(ctx, callToolReq("pm_watch_issue", map[string]any{
+		"issue_id": "WATCHED", "target": "Dev <DEV@example.com>",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	var out map[string]any
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+	assert.Equal(t, "email", out["kind"])
+	assert.Equal(t, true, out["added"])
+	watchers := func() []*models.IssueWatcher {
+		w, err := ms.ListIssueWatchers(ctx, "WATCHED01")
+		require.NoError(t, err)
+		return w
+	}
+	require.Len(t, watchers(), 1)
+	assert.Equal(t, "WATCHED01", watchers()[0].IssueID)
+
+	// Webhooks are inferred from the URL; watching twice is a no-op
+	for range 2 {
+		result, err = srv.handleWatchIssue(ctx, callToolReq("pm_watch_issue", map[string]any{
+			"issue_id": "WATCHED01", "target": "https://hooks.example.com/pm",
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, resultText(t, result))
+	}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+	assert.Equal(t, "webhook", out["kind"])
+	assert.Equal(t, false, out["added"])
+	assert.Len(t, watchers(), 2)
+
+	// Invalid targets and kinds are rejected
+	result, err = srv.handleWatchIssue(ctx, callToolReq("pm_watch_issue", map[string]any{
+		"issue_id": "WATCHED01", "target": "not an address",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	result, err = srv.handleWatchIssue(ctx, callToolReq("pm_watch_issue", map[string]any{
+		"issue_id": "WATCHED01", "target": "dev@example.com", "kind": "webhook",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	// Project-scoped callers cannot point webhooks at local addresses
+	scoped := auth.WithScope(ctx, auth.Scope{ProjectID: "p1"})
+	result, err = srv.handleWatchIssue(scoped, callToolReq("pm_watch_issue", map[string]any{
+		"issue_id": "WATCHED01", "target": "http://169.254.169.254/latest",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "local or private address")
+	assert.Len(t, watchers(), 2)
+
+	// Unwatch matches the normalized target
+	result, err = srv.handleUnwatchIssue(ctx, callToolReq("pm_unwatch_issue", map[string]any{
+		"issue_id": "WATCHED01", "target": "dev@example.com",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	require.Len(t, watchers(), 1)
+	assert.Equal(t, "https://hooks.example.com/pm", watchers()[0].Target)
+
+	result, err = srv.handleUnwatchIssue(ctx, callToolReq("pm_unwatch_issue", map[string]any{
+		"issue_id": "WATCHED01", "target": "dev@example.com",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestSearchCode(t *testing.T) {
+	srv, ms, _, _, _ := newTestServer(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "cmd"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() { run() }\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cmd", "run.go"), []byte("package cmd\n\nfunc run() {}\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("Calls run()\n"), 0o644))
+	p := seedProject(t, ms, "searchable", dir)
+
+	search := func(args map[string]any) (map[string]any, bool) {
+		t.Helper()
+		result, err := srv.handleSearchCode(ctx, callToolReq("pm_search_code", args))
+		require.NoError(t, err)
+		if result.IsError {
+			return map[string]any{"error": resultText(t, result)}, true
+		}
+		var out map[string]any
+		require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+		return out, false
+	}
+
+	out, isErr := search(map[string]any{"project": "searchable", "pattern": "run()", "literal": "true", "paths": "*.go"})
+	require.False(t, isErr, out["error"])
+	assert.Equal(t, "searchable", out["project"])
+	assert.Equal(t, float64(2), out["count"])
+	matches := out["matches"].([]any)
+	assert.Equal(t, "cmd/run.go", matches[0].(map[string]any)["path"])
+	assert.Equal(t, float64(3), matches[0].(map[string]any)["line"])
+	assert.Equal(t, "main.go", matches[1].(map[string]any)["path"])
+
+	out, _ = search(map[string]any{"project": "searchable", "pattern": "RUN", "ignore_case": "true", "max_results": "1"})
+	assert.Equal(t, float64(1), out["count"])
+	assert.Equal(t, true, out["truncated"])
+
+	// A session's worktree is searched instead of the project directory
+	wtDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(wtDir, "new.go"), []byte("func run2() {}\n"), 0o644))
+	seed(t, ms, &models.AgentSession{ID: "sess-search", ProjectID: p.ID, WorktreePath: wtDir, Status: models.SessionStatusActive})
+	out, isErr = search(map[string]any{"session_id": "sess-search", "pattern": `run\d`})
+	require.False(t, isErr, out["error"])
+	assert.Equal(t, "sess-search", out["session_id"])
+	assert.Equal(t, float64(1), out["count"])
+
+	for _, args := range []map[string]any{
+		{"pattern": "run"},
+		{"project": "searchable", "pattern": "("},
+		{"project": "searchable", "pattern": "run", "max_results": "0"},
+		{"project": "searchable", "pattern": "run", "paths": "[a"},
+		{"project": "missing", "pattern": "run"},
+	} {
+		_, isErr := search(args)
+		assert.True(t, isErr, "%v", args)
+	}
+}
+
+func TestNextTasksAndMyFocus(t *testing.T) {
+	now := time.Now()
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "app", Path: "/tmp/app"},
+		&models.Project{ID: "p2", Name: "lib", Path: "/tmp/lib"},
+		&models.Issue{ID: "LOWISSUE1", ProjectID: "p1", Title: "Polish docs", Status: models.IssueStatusOpen, Priority: models.IssuePriorityLow, CreatedAt: now},
+		&models.Issue{ID: "HIGHISSUE", ProjectID: "p2", Title: "Crash on start", Status: models.IssueStatusOpen, Priority: models.IssuePriorityHigh, Type: models.IssueTypeBug, CreatedAt: now},
+		&models.Issue{ID: "FAILREVW1", ProjectID: "p1", Title: "Add export", Status: models.IssueStatusInProgress, Priority: models.IssuePriorityMedium, CreatedAt: now},
+		&models.AgentSession{ID: "sess-conflict", ProjectID: "p2", Branch: "feature/x", Status: models.SessionStatusIdle, ConflictState: models.ConflictStateSyncConflict},
+		&models.IssueReview{ID: "rev-1", IssueID: "FAILREVW1", Verdict: models.ReviewVerdictFail, Summary: "Tests missing", ReviewedAt: now},
+	)
+	srv := NewServer(ms, &mockGitClient{}, nil, nil, nil)
+	ctx := context.Background()
+
+	result, err := srv.handleNextTasks(ctx, callToolReq("pm_next_tasks", map[string]any{"limit": "2"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	var next map[string]any
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &next))
+	tasks := next["tasks"].([]any)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, float64(3), next["total"])
+	assert.Equal(t, "FAILREVW1", tasks[0].(map[string]any)["issue_id"])
+	assert.Equal(t, "HIGHISSUE", tasks[1].(map[string]any)["issue_id"])
+	assert.Equal(t, "lib", tasks[1].(map[string]any)["project"])
+
+	// Project filter
+	result, err = srv.handleNextTasks(ctx, callToolReq("pm_next_tasks", map[string]any{"project": "lib"}))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &next))
+	assert.Len(t, next["tasks"].([]any), 1)
+
+	result, err = srv.handleNextTasks(ctx, callToolReq("pm_next_tasks", map[string]any{"limit": "many"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, err = srv.handleMyFocus(ctx, callToolReq("pm_my_focus", map[string]any{}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	var focus map[string]any
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &focus))
+	conflicted := focus["conflicted_sessions"].([]any)
+	require.Len(t, conflicted, 1)
+	assert.Equal(t, "sess-conflict", conflicted[0].(map[string]any)["session_id"])
+	failing := focus["failing_reviews"].([]any)
+	require.Len(t, failing, 1)
+	assert.Equal(t, "Tests missing", failing[0].(map[string]any)["summary"])
+	assert.Len(t, focus["next_tasks"].([]any), 3)
+}
+
 func TestSaveReview_Pass(t *testing.T) {
 	issue := &models.Issue{
 		ID: "ISSUE001", ProjectID: "p1", Title: "Add login",
 		Status: models.IssueStatusDone, Priority: models.IssuePriorityMedium,
 		Type: models.IssueTypeFeature,
 	}
-	ms := &mockStore{
-		projects: []*models.Project{{ID: "p1", Name: "myproject"}},
-		issues:   []*models.Issue{issue},
-	}
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "myproject"},
+		issue,
+	)
 	srv := NewServer(ms, nil, nil, nil, nil)
 	ctx := context.Background()
 
@@ -1266,12 +1883,13 @@ func TestSaveReview_Pass(t *testing.T) {
 	require.NoError(t, err)
 	require.False(t, result.IsError)
 
-	require.Len(t, ms.createdReviews, 1)
-	assert.Equal(t, models.ReviewVerdictPass, ms.createdReviews[0].Verdict)
+	reviews := listReviews(t, ms, "ISSUE001")
+	require.Len(t, reviews, 1)
+	assert.Equal(t, models.ReviewVerdictPass, reviews[0].Verdict)
 
-	require.Len(t, ms.updatedIssues, 1)
-	assert.Equal(t, models.IssueStatusClosed, ms.updatedIssues[0].Status)
-	assert.NotNil(t, ms.updatedIssues[0].ClosedAt)
+	updated := getIssue(t, ms, "ISSUE001")
+	assert.Equal(t, models.IssueStatusClosed, updated.Status)
+	assert.NotNil(t, updated.ClosedAt)
 }
 
 func TestSaveReview_Fail(t *testing.T) {
@@ -1280,10 +1898,11 @@ func TestSaveReview_Fail(t *testing.T) {
 		Status: models.IssueStatusDone, Priority: models.IssuePriorityMedium,
 		Type: models.IssueTypeFeature,
 	}
-	ms := &mockStore{
-		projects: []*models.Project{{ID: "p1", Name: "myproject"}},
-		issues:   []*models.Issue{issue},
-	}
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "myproject"},
+		issue,
+	)
 	srv := NewServer(ms, nil, nil, nil, nil)
 	ctx := context.Background()
 
@@ -1301,9 +1920,176 @@ func TestSaveReview_Fail(t *testing.T) {
 	require.NoError(t, err)
 	require.False(t, result.IsError)
 
-	require.Len(t, ms.updatedIssues, 1)
-	assert.Equal(t, models.IssueStatusInProgress, ms.updatedIssues[0].Status)
-	assert.Nil(t, ms.updatedIssues[0].ClosedAt)
+	updated := getIssue(t, ms, "ISSUE002")
+	assert.Equal(t, models.IssueStatusInProgress, updated.Status)
+	assert.Nil(t, updated.ClosedAt)
+}
+
+func TestSaveReview_InvalidCategory(t *testing.T) {
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "myproject"},
+		&models.Issue{
+			ID: "ISSUE002", ProjectID: "p1", Title: "Add search",
+			Status: models.IssueStatusDone, Priority: models.IssuePriorityMedium,
+			Type: models.IssueTypeFeature,
+		},
+	)
+	srv := NewServer(ms, nil, nil, nil, nil)
+
+	result, err := srv.handleSaveReview(context.Background(), callToolReq("pm_save_review", map[string]any{
+		"issue_id":      "ISSUE002",
+		"verdict":       "pass",
+		"summary":       "Looks good",
+		"test_coverage": "good",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "test_coverage: invalid review category")
+	assert.Empty(t, listReviews(t, ms, "ISSUE002"))
+}
+
+func TestSaveReview_AcceptanceCriteria(t *testing.T) {
+	newServer := func() (*Server, *store.MemoryStore) {
+		ms := store.NewMemoryStore()
+		seed(t, ms,
+			&models.Project{ID: "p1", Name: "myproject"},
+			&models.Issue{
+				ID: "ISSUE010", ProjectID: "p1", Title: "Add logout",
+				Status: models.IssueStatusDone, Priority: models.IssuePriorityMedium,
+				Type:               models.IssueTypeFeature,
+				AcceptanceCriteria: []string{"Logout clears the session cookie", "Logout redirects to the login page"},
+			},
+		)
+		return NewServer(ms, nil, nil, nil, nil), ms
+	}
+	ctx := context.Background()
+	save := func(srv *Server, verdict, criteria string) *mcpgo.CallToolResult {
+		result, err := srv.handleSaveReview(ctx, callToolReq("pm_save_review", map[string]any{
+			"issue_id": "ISSUE010", "verdict": verdict, "summary": "Reviewed", "criteria": criteria,
+		}))
+		require.NoError(t, err)
+		return result
+	}
+
+	srv, ms := newServer()
+	result := save(srv, "fail", `[{"criterion": 1, "passed": true, "note": "TestLogout"}, {"criterion": "logout redirects to the login page", "passed": false, "note": "stays on /home"}]`)
+	require.False(t, result.IsError, resultText(t, result))
+	reviews := listReviews(t, ms, "ISSUE010")
+	require.Len(t, reviews, 1)
+	assert.Equal(t, []models.CriterionResult{
+		{Criterion: "Logout clears the session cookie", Passed: true, Note: "TestLogout"},
+		{Criterion: "Logout redirects to the login page", Passed: false, Note: "stays on /home"},
+	}, reviews[0].Criteria)
+	assert.Equal(t, []string{"Acceptance criterion not met: Logout redirects to the login page (stays on /home)"}, reviews[0].FailureReasons)
+
+	srv, ms = newServer()
+	result = save(srv, "pass", `[{"criterion": "2", "passed": true}]`)
+	require.False(t, result.IsError, resultText(t, result))
+	var out map[string]any
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+	assert.Equal(t, []any{"Logout clears the session cookie"}, out["unchecked_criteria"])
+
+	for name, criteria := range map[string]string{
+		"pass with a failed criterion": `[{"criterion": 1, "passed": false}]`,
+		"unknown criterion":            `[{"criterion": "Works offline", "passed": true}]`,
+		"out of range":                 `[{"criterion": 3, "passed": true}]`,
+		"duplicate":                    `[{"criterion": 1, "passed": true}, {"criterion": "Logout clears the session cookie", "passed": true}]`,
+		"not JSON":                     `1: ok`,
+	} {
+		srv, ms = newServer()
+		assert.True(t, save(srv, "pass", criteria).IsError, name)
+		assert.Empty(t, listReviews(t, ms, "ISSUE010"), name)
+	}
+}
+
+func TestSaveReview_FailRelaunch(t *testing.T) {
+	issue := &models.Issue{
+		ID: "ISSUE003", ProjectID: "p1", Title: "Add export",
+		Status: models.IssueStatusDone, Priority: models.IssuePriorityMedium,
+		Type: models.IssueTypeFeature,
+	}
+	prev := &models.AgentSession{
+		ID: "sess-prev", ProjectID: "p1", IssueID: "ISSUE003",
+		Branch: "feature/add-export", WorktreePath: "/tmp/myproject.worktrees/add-export",
+		Status: models.SessionStatusIdle,
+	}
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "myproject", Path: "/tmp/myproject"},
+		issue,
+		prev,
+	)
+	wtc := &mockWTClient{}
+	srv := NewServer(ms, nil, nil, wtc, nil, WithReviewRelaunch(true, 2))
+	ctx := context.Background()
+
+	req := callToolReq("pm_save_review", map[string]any{
+		"issue_id":        "ISSUE003",
+		"verdict":         "fail",
+		"summary":         "Export drops headers",
+		"failure_reasons": "CSV header row missing",
+	})
+	result, err := srv.handleSaveReview(ctx, req)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var out map[string]any
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+	require.Contains(t, out, "follow_up", "result: %v", out)
+
+	followUps := listSessions(t, ms, prev.ID)
+	require.Len(t, followUps, 1)
+	followUp := followUps[0]
+	assert.Equal(t, "feature/add-export", followUp.Branch)
+	assert.Equal(t, prev.WorktreePath, followUp.WorktreePath)
+	assert.Equal(t, 1, followUp.ReviewAttempt)
+	assert.Equal(t, listReviews(t, ms, "ISSUE003")[0].ID, followUp.ReviewID)
+	assert.Equal(t, models.SessionStatusActive, followUp.Status)
+	assert.Equal(t, models.SessionStatusCompleted, getSession(t, ms, prev.ID).Status, "reviewed session should be ended")
+	require.Len(t, wtc.created, 1)
+	assert.Contains(t, out["follow_up"].(map[string]any)["command"], "CSV header row missing")
+
+	// Second failure builds on the follow-up; third exceeds the limit
+	for i, wantErr := range []bool{false, true} {
+		result, err = srv.handleSaveReview(ctx, req)
+		require.NoError(t, err)
+		out = map[string]any{}
+		require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &out))
+		if wantErr {
+			assert.Contains(t, out["follow_up_error"], "limit reached")
+		} else {
+			assert.Equal(t, 2, listSessions(t, ms, prev.ID, followUp.ID)[0].ReviewAttempt, "iteration %d", i)
+		}
+	}
+	assert.Len(t, listSessions(t, ms, prev.ID), 2)
+}
+
+func TestSaveReview_FailNoRelaunchByDefault(t *testing.T) {
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "myproject", Path: "/tmp/myproject"},
+		&models.Issue{
+			ID: "ISSUE004", ProjectID: "p1", Title: "Add import",
+			Status: models.IssueStatusDone, Priority: models.IssuePriorityMedium,
+			Type: models.IssueTypeFeature,
+		},
+		&models.AgentSession{
+			ID: "sess-1", ProjectID: "p1", IssueID: "ISSUE004",
+			Branch: "feature/add-import", Status: models.SessionStatusIdle,
+		},
+	)
+	srv := NewServer(ms, nil, nil, &mockWTClient{}, nil)
+
+	result, err := srv.handleSaveReview(context.Background(), callToolReq("pm_save_review", map[string]any{
+		"issue_id": "ISSUE004",
+		"verdict":  "fail",
+		"summary":  "Broken",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Empty(t, listSessions(t, ms, "sess-1"))
+	assert.NotContains(t, resultText(t, result), "follow_up")
 }
 
 // ---------------------------------------------------------------------------
@@ -1311,9 +2097,10 @@ func TestSaveReview_Fail(t *testing.T) {
 // ---------------------------------------------------------------------------
 
 func TestUpdateProject(t *testing.T) {
-	ms := &mockStore{
-		projects: []*models.Project{{ID: "p1", Name: "myproject", Path: "/tmp/myproject"}},
-	}
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "myproject", Path: "/tmp/myproject"},
+	)
 	srv := NewServer(ms, nil, nil, nil, nil)
 	ctx := context.Background()
 
@@ -1335,10 +2122,103 @@ func TestUpdateProject(t *testing.T) {
 	assert.Equal(t, float64(3000), out["serve_port"])
 }
 
+func TestUpdateProject_CommitConventions(t *testing.T) {
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "myproject", Path: "/tmp/myproject"},
+	)
+	srv := NewServer(ms, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	result, err := srv.handleUpdateProject(ctx, callToolReq("pm_update_project", map[string]any{
+		"project":              "myproject",
+		"commit_style":         "conventional",
+		"commit_ticket_prefix": "PROJ-",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, "conventional", getProject(t, ms, "p1").CommitStyle)
+	assert.Equal(t, "PROJ-", getProject(t, ms, "p1").CommitTicketPrefix)
+
+	result, err = srv.handleUpdateProject(ctx, callToolReq("pm_update_project", map[string]any{
+		"project":      "myproject",
+		"commit_style": "angular",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "conventional", getProject(t, ms, "p1").CommitStyle)
+
+	result, err = srv.handleUpdateProject(ctx, callToolReq("pm_update_project", map[string]any{
+		"project":              "myproject",
+		"commit_style":         "none",
+		"commit_ticket_prefix": "none",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Empty(t, getProject(t, ms, "p1").CommitStyle)
+	assert.Empty(t, getProject(t, ms, "p1").CommitTicketPrefix)
+}
+
+func TestUpdateProject_WIPLimits(t *testing.T) {
+	ms := store.NewMemoryStore()
+	seed(t, ms,
+		&models.Project{ID: "p1", Name: "myproject", Path: "/tmp/myproject", MaxInProgress: 3},
+	)
+	srv := NewServer(ms, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	for _, args := range []map[string]any{
+		{"max_in_progress": "-1"},
+		{"max_active_sessions": "two"},
+		{"wip_hard_block": "sometimes"},
+	} {
+		args["project"] = "myproject"
+		result, err := srv.handleUpdateProject(ctx, callToolReq("pm_update_project", args))
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "args %v", args)
+	}
+	assert.Equal(t, 3, getProject(t, ms, "p1").MaxInProgress)
+
+	result, err := srv.handleUpdateProject(ctx, callToolReq("pm_update_project", map[string]any{
+		"project":         "myproject",
+		"max_in_progress": "0",
+		"wip_hard_block":  "true",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Zero(t, getProject(t, ms, "p1").MaxInProgress)
+	assert.True(t, getProject(t, ms, "p1").WIPHardBlock)
+}
+
+func TestSuggestCommitMessage_NoLLM(t *testing.T) {
+	srv, ms, _, _, _ := newTestServer(t)
+	seedProject(t, ms, "demo", "/tmp/demo")
+
+	result, err := srv.handleSuggestCommitMessage(context.Background(), callToolReq("pm_suggest_commit_message", map[string]any{
+		"project": "demo",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "LLM not configured")
+}
+
 // ---------------------------------------------------------------------------
 // Tests: Integration -- verify all tools are registered via HandleMessage
 // ---------------------------------------------------------------------------
 
+func TestMCPServer_HidesToolsWithoutCapability(t *testing.T) {
+	srv, _, _, _, _ := newTestServer(t)
+	WithCapabilities(&capabilities.Capabilities{LLM: capabilities.Off, Forge: capabilities.ForgeGitHub, Terminal: capabilities.None})(srv)
+
+	mcpSrv := srv.MCPServer()
+	respMsg := mcpSrv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list","params":{}}`))
+	respBytes, err := json.Marshal(respMsg)
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(respBytes), "pm_suggest_commit_message")
+	assert.Contains(t, string(respBytes), "pm_prepare_review")
+}
+
 func TestMCPIntegration_ListTools(t *testing.T) {
 	srv, ms, _, _, _ := newTestServer(t)
 
@@ -1375,6 +2255,7 @@ func TestMCPIntegration_ListTools(t *testing.T) {
 		"pm_list_projects",
 		"pm_project_status",
 		"pm_list_issues",
+		"pm_get_issue",
 		"pm_create_issue",
 		"pm_update_issue",
 		"pm_health_score",
@@ -1383,18 +2264,251 @@ func TestMCPIntegration_ListTools(t *testing.T) {
 		"pm_prepare_review",
 		"pm_save_review",
 		"pm_update_project",
+		"pm_relate_issues",
+		"pm_watch_issue",
+		"pm_unwatch_issue",
+		"pm_issue_code",
+		"pm_search_code",
+		"pm_my_focus",
+		"pm_next_tasks",
+		"pm_suggest_commit_message",
 	}
 	for _, name := range expectedTools {
 		assert.True(t, toolNames[name], "expected tool %q to be registered", name)
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Tests: project-scoped API tokens
+// ---------------------------------------------------------------------------
+
+func TestScopeTools(t *testing.T) {
+	srv, ms, _, _, _ := newTestServer(t)
+	mine := seedProject(t, ms, "mine", "/tmp/mine")
+	other := seedProject(t, ms, "other", "/tmp/other")
+	seedIssue(t, ms, mine.ID, "Mine", models.IssueStatusOpen)
+	otherIssue := seedIssue(t, ms, other.ID, "Theirs", models.IssueStatusOpen)
+	seed(t, ms, &models.AgentSession{ID: "sess-other", ProjectID: other.ID, Status: models.SessionStatusIdle})
+
+	mcpSrv := srv.MCPServer()
+	ctx := auth.WithScope(context.Background(), auth.Scope{TokenID: "tok-1", ProjectID: mine.ID})
+	call := func(ctx context.Context, name string, args map[string]any) (string, bool) {
+		t.Helper()
+		params, err := json.Marshal(map[string]any{"name": name, "arguments": args})
+		require.NoError(t, err)
+		msg := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":%s}`, params)
+		respBytes, err := json.Marshal(mcpSrv.HandleMessage(ctx, []byte(msg)))
+		require.NoError(t, err)
+		var resp struct {
+			Result struct {
+				IsError bool `json:"isError"`
+				Content []struct {
+					Text string `json:"text"`
+				} `json:"content"`
+			} `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(respBytes, &resp))
+		require.NotEmpty(t, resp.Result.Content, string(respBytes))
+		return resp.Result.Content[0].Text, resp.Result.IsError
+	}
+
+	text, isErr := call(ctx, "pm_list_issues", nil)
+	assert.False(t, isErr, text)
+	assert.Contains(t, text, "Mine")
+	assert.NotContains(t, text, "Theirs", "an omitted project defaults to the scoped one")
+
+	text, isErr = call(ctx, "pm_list_projects", nil)
+	assert.False(t, isErr, text)
+	assert.Contains(t, text, "mine")
+	assert.NotContains(t, text, "other")
+
+	for _, tc := range []struct {
+		tool string
+		args map[string]any
+	}{
+		{"pm_list_issues", map[string]any{"project": "other"}},
+		{"pm_update_issue", map[string]any{"issue_id": otherIssue.ID, "status": "done"}},
+		{"pm_sync_session", map[string]any{"session_id": "sess-other"}},
+		{"pm_my_focus", nil},
+	} {
+		text, isErr := call(ctx, tc.tool, tc.args)
+		assert.True(t, isErr, "%s should be refused", tc.tool)
+		assert.Contains(t, text, "scoped", tc.tool)
+	}
+	assert.Equal(t, models.IssueStatusOpen, getIssue(t, ms, otherIssue.ID).Status)
+
+	text, isErr = call(context.Background(), "pm_list_issues", map[string]any{"project": "other"})
+	assert.False(t, isErr, "unscoped calls keep full access: %s", text)
+
+	bad := srv.HTTPContext(context.Background(), httptest.NewRequest("POST", "/mcp", nil))
+	text, isErr = call(bad, "pm_list_issues", nil)
+	assert.False(t, isErr, "requests without a token are unscoped: %s", text)
+	req := httptest.NewRequest("POST", "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer pm_unknown")
+	_, isErr = call(srv.HTTPContext(context.Background(), req), "pm_list_issues", nil)
+	assert.True(t, isErr, "an invalid token is refused")
+}
+
+func TestWithProject_BindsTools(t *testing.T) {
+	ms := store.NewMemoryStore()
+	mine := seedProject(t, ms, "mine", "/tmp/mine")
+	other := seedProject(t, ms, "other", "/tmp/other")
+	seedIssue(t, ms, mine.ID, "Mine", models.IssueStatusOpen)
+	otherIssue := seedIssue(t, ms, other.ID, "Theirs", models.IssueStatusOpen)
+	srv := NewServer(ms, &mockGitClient{}, &mockGHClient{}, &mockWTClient{}, nil, WithProject(mine))
+
+	mcpSrv := srv.MCPServer()
+	for _, name := range []string{"pm_project_status", "pm_create_issue", "pm_list_issues", "pm_next_tasks"} {
+		tool := mcpSrv.GetTool(name)
+		require.NotNil(t, tool, name)
+		assert.NotContains(t, tool.Tool.InputSchema.Properties, "project", name)
+		assert.NotContains(t, tool.Tool.InputSchema.Required, "project", name)
+	}
+	tool, _ := srv.listIssuesTool()
+	assert.Contains(t, tool.InputSchema.Properties, "project", "tool definitions are left alone")
+
+	// ServeStdio scopes every call to the bound project.
+	ctx := auth.WithScope(context.Background(), *srv.scope)
+	call := func(name string, args map[string]any) (string, bool) {
+		t.Helper()
+		params, err := json.Marshal(map[string]any{"name": name, "arguments": args})
+		require.NoError(t, err)
+		msg := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":%s}`, params)
+		respBytes, err := json.Marshal(mcpSrv.HandleMessage(ctx, []byte(msg)))
+		require.NoError(t, err)
+		var resp struct {
+			Result struct {
+				IsError bool `json:"isError"`
+				Content []struct {
+					Text string `json:"text"`
+				} `json:"content"`
+			} `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(respBytes, &resp))
+		require.NotEmpty(t, resp.Result.Content, string(respBytes))
+		return resp.Result.Content[0].Text, resp.Result.IsError
+	}
+
+	text, isErr := call("pm_project_status", nil)
+	assert.False(t, isErr, text)
+	assert.Contains(t, text, `"name":"mine"`)
+
+	text, isErr = call("pm_list_issues", nil)
+	assert.False(t, isErr, text)
+	assert.Contains(t, text, "Mine")
+	assert.NotContains(t, text, "Theirs")
+
+	text, isErr = call("pm_create_issue", map[string]any{"title": "New", "description": "d"})
+	assert.False(t, isErr, text)
+	idx := slices.IndexFunc(listIssues(t, ms), func(i *models.Issue) bool { return i.Title == "New" })
+	require.GreaterOrEqual(t, idx, 0)
+	assert.Equal(t, mine.ID, listIssues(t, ms)[idx].ProjectID)
+
+	text, isErr = call("pm_list_issues", map[string]any{"project": "other"})
+	assert.True(t, isErr)
+	assert.Contains(t, text, "MCP server is not scoped to project other")
+	text, isErr = call("pm_update_issue", map[string]any{"issue_id": otherIssue.ID, "status": "done"})
+	assert.True(t, isErr)
+	assert.Contains(t, text, "MCP server is not scoped")
+	assert.Equal(t, models.IssueStatusOpen, getIssue(t, ms, otherIssue.ID).Status)
+}
+
+func TestBeatTools(t *testing.T) {
+	srv, ms, _, _, _ := newTestServer(t)
+	p := seedProject(t, ms, "api", "/tmp/api")
+	issue := seedIssue(t, ms, p.ID, "Busy", models.IssueStatusInProgress)
+	open := &models.AgentSession{ID: "sess-open", ProjectID: p.ID, IssueID: issue.ID, Status: models.SessionStatusActive}
+	ended := &models.AgentSession{ID: "sess-ended", ProjectID: p.ID, IssueID: issue.ID, Status: models.SessionStatusCompleted}
+	seed(t, ms, open, ended)
+
+	mcpSrv := srv.MCPServer()
+	call := func(name string, args map[string]any) {
+		t.Helper()
+		params, err := json.Marshal(map[string]any{"name": name, "arguments": args})
+		require.NoError(t, err)
+		mcpSrv.HandleMessage(context.Background(), []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":%s}`, params)))
+	}
+
+	call("pm_update_issue", map[string]any{"issue_id": issue.ID, "status": "bogus"})
+	assert.Nil(t, getSession(t, ms, open.ID).LastActiveAt, "failed calls are not activity")
+
+	call("pm_update_issue", map[string]any{"issue_id": issue.ID, "priority": "high"})
+	first := getSession(t, ms, open.ID).LastActiveAt
+	require.NotNil(t, first, "the issue's open session is beaten")
+	assert.Nil(t, getSession(t, ms, ended.ID).LastActiveAt)
+
+	call("pm_update_issue", map[string]any{"issue_id": issue.ID, "priority": "low"})
+	assert.Equal(t, first, getSession(t, ms, open.ID).LastActiveAt, "beats within the interval are coalesced")
+}
+
+// ---------------------------------------------------------------------------
+// Tests: project note resources
+// ---------------------------------------------------------------------------
+
+func TestNoteResources(t *testing.T) {
+	srv, ms, _, _, _ := newTestServer(t)
+	mine := seedProject(t, ms, "mine", "/tmp/mine")
+	other := seedProject(t, ms, "other", "/tmp/other")
+	seed(t, ms,
+		&models.ProjectNote{ID: "01NOTERUNBOOK", ProjectID: mine.ID, Title: "Runbook", Body: "Deploy with `make release`."},
+		&models.ProjectNote{ID: "01NOTESECRET", ProjectID: other.ID, Title: "Other runbook", Body: "Not yours."},
+	)
+
+	mcpSrv := srv.MCPServer()
+	read := func(ctx context.Context, uri string) (string, string) {
+		t.Helper()
+		msg := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":%q}}`, uri)
+		respBytes, err := json.Marshal(mcpSrv.HandleMessage(ctx, []byte(msg)))
+		require.NoError(t, err)
+		var resp struct {
+			Result struct {
+				Contents []struct {
+					Text string `json:"text"`
+				} `json:"contents"`
+			} `json:"result"`
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(respBytes, &resp))
+		if len(resp.Result.Contents) == 0 {
+			return "", resp.Error.Message
+		}
+		return resp.Result.Contents[0].Text, ""
+	}
+	ctx := context.Background()
+
+	text, errMsg := read(ctx, "pm://notes")
+	require.Empty(t, errMsg)
+	assert.Contains(t, text, "pm://projects/mine/notes/01NOTERUNBOOK")
+	assert.Contains(t, text, "Other runbook")
+
+	text, errMsg = read(ctx, "pm://projects/mine/notes")
+	require.Empty(t, errMsg)
+	assert.Contains(t, text, "Runbook")
+	assert.NotContains(t, text, "Other runbook")
+
+	for _, uri := range []string{"pm://projects/mine/notes/01NOTERUNBOOK", "pm://projects/mine/notes/runbook", "pm://projects/proj-mine/notes/01NOTERUN"} {
+		text, errMsg = read(ctx, uri)
+		require.Empty(t, errMsg, uri)
+		assert.Equal(t, "# Runbook\n\nDeploy with `make release`.\n", text, uri)
+	}
+	_, errMsg = read(ctx, "pm://projects/mine/notes/01NOTESECRET")
+	assert.Contains(t, errMsg, "not found", "notes of other projects are not reachable")
+
+	scoped := auth.WithScope(ctx, auth.Scope{TokenID: "tok-1", ProjectID: mine.ID})
+	_, errMsg = read(scoped, "pm://projects/other/notes")
+	assert.Contains(t, errMsg, "scoped")
+	text, errMsg = read(scoped, "pm://notes")
+	require.Empty(t, errMsg)
+	assert.NotContains(t, text, "Other runbook")
+}
+
 // Compile-time interface checks for mocks.
 var (
-	_ store.Store        = (*mockStore)(nil)
-	_ git.Client         = (*mockGitClient)(nil)
-	_ git.GitHubClient   = (*mockGHClient)(nil)
-	_ wt.Client          = (*mockWTClient)(nil)
+	_ git.Client       = (*mockGitClient)(nil)
+	_ git.GitHubClient = (*mockGHClient)(nil)
+	_ wt.Client        = (*mockWTClient)(nil)
 )
 
 // Reference mcpserver to keep the import active (used by MCPServer return type).
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"slices"
+	"strings"
+	"time"
+)
 
 // SessionStatus represents the state of an agent session.
 type SessionStatus string
@@ -10,8 +14,18 @@ const (
 	SessionStatusIdle      SessionStatus = "idle"
 	SessionStatusCompleted SessionStatus = "completed"
 	SessionStatusAbandoned SessionStatus = "abandoned"
+	SessionStatusBlocked   SessionStatus = "blocked"
 )
 
+// OpenSessionStatuses are the statuses of sessions that still own their
+// worktree: everything except completed and abandoned.
+var OpenSessionStatuses = []SessionStatus{SessionStatusActive, SessionStatusIdle, SessionStatusBlocked}
+
+// IsOpen reports whether a session in this status still owns its worktree.
+func (s SessionStatus) IsOpen() bool {
+	return s == SessionStatusActive || s == SessionStatusIdle || s == SessionStatusBlocked
+}
+
 // ConflictState represents the conflict state of a session's worktree.
 type ConflictState string
 
@@ -21,9 +35,21 @@ const (
 	ConflictStateMergeConflict ConflictState = "merge_conflict"
 )
 
+// OutcomeClass classifies how an ended session went, for outcome reports.
+type OutcomeClass string
+
+const (
+	OutcomeCleanSuccess          OutcomeClass = "clean_success"           // completed without conflicts
+	OutcomeSuccessAfterConflicts OutcomeClass = "success_after_conflicts" // completed after a sync or merge conflict
+	OutcomeAbandonedNoCommits    OutcomeClass = "abandoned_no_commits"    // abandoned before committing anything
+	OutcomeAbandoned             OutcomeClass = "abandoned"               // abandoned with commits
+	OutcomeReviewFailed          OutcomeClass = "review_failed"           // its work failed an issue review
+)
+
 // AgentSession represents a Claude Code agent session tied to a project and issue.
 type AgentSession struct {
 	ID                string
+	Code              string // short code, e.g. "WID-S3"; assigned by the store on creation
 	ProjectID         string
 	IssueID           string
 	Branch            string
@@ -43,4 +69,156 @@ type AgentSession struct {
 	ConflictState ConflictState // "none", "sync_conflict", "merge_conflict"
 	ConflictFiles string        // JSON array of conflicting file paths
 	Discovered    bool          // true if auto-discovered (not created by pm)
+
+	// Inline sessions work in the project repo itself: WorktreePath is the
+	// repo path, and there is no worktree to sync, merge or delete.
+	Inline bool
+
+	// Comma-separated cone paths the worktree was created with ("" = full checkout)
+	SparsePaths string
+
+	// Comma-separated labels grouping sessions apart from issues
+	// ("experiment,hotfix")
+	Labels string
+
+	// Comma-separated issues the session was launched on besides IssueID,
+	// set on creation; the session locks them all while active
+	ExtraIssueIDs string
+
+	// Commit the session's merge left on the base branch (the squash or merge
+	// commit, or the fast-forwarded tip)
+	MergeCommit string
+
+	// How the session went, set when it ends ("" while open)
+	OutcomeClass OutcomeClass
+
+	// Time spent working, added up from heartbeats by the store: the gaps of
+	// at most FocusGap between consecutive activity. See IdleTime.
+	FocusSeconds float64
+
+	// Review round-trip fields
+	ReviewAttempt int    // 0 for the original session, incremented per failed-review follow-up
+	ReviewID      string // Failed review that triggered this follow-up session
+
+	// Co-editing guard
+	WorktreeFingerprint string // Worktree content hash recorded when the session last went idle or closed
+
+	// Terminal hosting the session, when pm spawned it
+	TerminalBackend string // "iterm" or "tmux"
+	TerminalID      string // iTerm session unique ID or tmux session name
+
+	// Blocked state, set while Status is blocked
+	BlockedReason    string     // What the session is waiting on
+	UnblockCondition string     // Optional: what has to happen before work resumes
+	BlockedAt        *time.Time // When the session was blocked
+}
+
+// FocusGap is the longest gap between two heartbeats of a session that
+// counts as focus time; longer gaps are idle time.
+const FocusGap = 5 * time.Minute
+
+// FocusAfter returns the focus time a heartbeat at at adds to a session last
+// active at last: the gap between them, unless it exceeds FocusGap.
+func FocusAfter(last *time.Time, at time.Time) time.Duration {
+	if last == nil {
+		return 0
+	}
+	gap := at.Sub(*last)
+	if gap <= 0 || gap > FocusGap {
+		return 0
+	}
+	return gap
+}
+
+// Duration returns the session's wall-clock duration: until it ended, or
+// until now while it is open.
+func (s *AgentSession) Duration(now time.Time) time.Duration {
+	if s.EndedAt != nil {
+		return s.EndedAt.Sub(s.StartedAt)
+	}
+	return now.Sub(s.StartedAt)
+}
+
+// FocusTime returns the time the session spent working.
+func (s *AgentSession) FocusTime() time.Duration {
+	return time.Duration(s.FocusSeconds * float64(time.Second))
+}
+
+// IdleTime returns the part of the session's duration that was not focus
+// time.
+func (s *AgentSession) IdleTime(now time.Time) time.Duration {
+	return max(s.Duration(now)-s.FocusTime(), 0)
+}
+
+// IssueIDs returns the issues the session works on: IssueID, if set, then
+// ExtraIssueIDs.
+func (s *AgentSession) IssueIDs() []string {
+	var ids []string
+	for _, id := range strings.Split(s.IssueID+","+s.ExtraIssueIDs, ",") {
+		if id != "" && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// LabelList returns the session's labels.
+func (s *AgentSession) LabelList() []string {
+	return ParseLabels(s.Labels)
+}
+
+// HasLabel reports whether the session carries label.
+func (s *AgentSession) HasLabel(label string) bool {
+	return slices.Contains(ParseLabels(s.Labels), label)
+}
+
+// EditLabels adds labels to the session and then removes others. Both lists
+// may hold comma-separated entries.
+func (s *AgentSession) EditLabels(add, remove []string) {
+	drop := ParseLabels(strings.Join(remove, ","))
+	var labels []string
+	for _, l := range ParseLabels(s.Labels + "," + strings.Join(add, ",")) {
+		if !slices.Contains(drop, l) {
+			labels = append(labels, l)
+		}
+	}
+	s.Labels = strings.Join(labels, ",")
+}
+
+// ParseLabels splits a comma-separated list of session labels, trimming
+// spaces and dropping empty and repeated entries.
+func ParseLabels(s string) []string {
+	var labels []string
+	for _, l := range strings.Split(s, ",") {
+		if l = strings.TrimSpace(l); l != "" && !slices.Contains(labels, l) {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+// SessionEventKind is what a SessionEvent records.
+type SessionEventKind string
+
+const (
+	SessionEventStarted  SessionEventKind = "started"  // Value is the initial status, Detail the branch
+	SessionEventStatus   SessionEventKind = "status"   // Value is the new status, From the old; Detail the blocked reason or outcome
+	SessionEventSync     SessionEventKind = "sync"     // Value is the resulting conflict state, Detail the error
+	SessionEventConflict SessionEventKind = "conflict" // Value is the new conflict state, Detail the conflicting files
+	SessionEventError    SessionEventKind = "error"    // Detail is the error of an operation other than sync
+
+	// Value is the ID of the session's deleted issue, Detail its title
+	SessionEventIssueDeleted SessionEventKind = "issue_deleted"
+)
+
+// SessionEvent records a change to an agent session, for replays.
+type SessionEvent struct {
+	ID        int64
+	SessionID string
+	Kind      SessionEventKind
+	Value     string
+	From      string // status events: the status the session left
+	Cause     string // status events: what made the transition ("" for plain updates)
+	Detail    string
+	At        time.Time
 }
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// APIToken is a bearer token that limits REST and MCP access to a single
+// project's issues and sessions. Only the SHA-256 hash of the token is stored.
+type APIToken struct {
+	ID        string
+	Name      string
+	ProjectID string
+	TokenHash string `json:"-"`
+	CreatedAt time.Time
+}
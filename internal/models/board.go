@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// BoardItem is an issue's item on its project's GitHub Projects (v2) board
+// and what the last board sync left both sides agreeing on: the issue's
+// status and priority, and the board option each maps to. A sync compares
+// each side with it to tell which changed since.
+type BoardItem struct {
+	IssueID       string
+	ProjectID     string
+	ItemID        string // GitHub project item node ID
+	Status        IssueStatus
+	Priority      IssuePriority
+	BoardStatus   string // option of the board's status field ("" = unset)
+	BoardPriority string // option of the board's priority field ("" = unset or no field)
+	SyncedAt      time.Time
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CascadeEvent is a session or review event that moves its issue to another
+// status.
+type CascadeEvent string
+
+const (
+	CascadeCompleted  CascadeEvent = "completed"   // the session completed, by close or merge
+	CascadeAbandoned  CascadeEvent = "abandoned"   // the session was abandoned or its worktree deleted
+	CascadeReviewPass CascadeEvent = "review_pass" // a review of the issue passed
+	CascadeReviewFail CascadeEvent = "review_fail" // a review of the issue failed
+)
+
+// CascadeEvents lists the cascade events.
+var CascadeEvents = []CascadeEvent{CascadeCompleted, CascadeAbandoned, CascadeReviewPass, CascadeReviewFail}
+
+// CascadeNone maps an event to no status change.
+const CascadeNone = "none"
+
+// DefaultCascade is the status each event moves its issue to unless the
+// project overrides it.
+var DefaultCascade = map[CascadeEvent]IssueStatus{
+	CascadeCompleted:  IssueStatusDone,
+	CascadeAbandoned:  IssueStatusOpen,
+	CascadeReviewPass: IssueStatusClosed,
+	CascadeReviewFail: IssueStatusInProgress,
+}
+
+// Cascade maps events to the status they move their issue to; "" leaves the
+// issue alone.
+type Cascade map[CascadeEvent]IssueStatus
+
+// ParseCascade parses comma-separated event=status overrides, such as
+// "completed=in_review,abandoned=none", on top of DefaultCascade.
+func ParseCascade(s string) (Cascade, error) {
+	c := make(Cascade, len(DefaultCascade))
+	for e, st := range DefaultCascade {
+		c[e] = st
+	}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		event, status, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid cascade rule %q (want event=status)", part)
+		}
+		e, err := parseEnum("cascade event", strings.TrimSpace(event), CascadeEvents)
+		if err != nil {
+			return nil, err
+		}
+		status = strings.TrimSpace(status)
+		if status == CascadeNone {
+			c[e] = ""
+			continue
+		}
+		st, err := ParseIssueStatus(status)
+		if err != nil {
+			return nil, err
+		}
+		c[e] = st
+	}
+	return c, nil
+}
+
+// String renders the rules that differ from DefaultCascade in the form
+// ParseCascade reads ("" when none do).
+func (c Cascade) String() string {
+	var parts []string
+	for _, e := range CascadeEvents {
+		st, ok := c[e]
+		if !ok || st == DefaultCascade[e] {
+			continue
+		}
+		if st == "" {
+			parts = append(parts, string(e)+"="+CascadeNone)
+		} else {
+			parts = append(parts, string(e)+"="+string(st))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Status returns the status event moves an issue to, "" for none.
+func (c Cascade) Status(event CascadeEvent) IssueStatus {
+	if st, ok := c[event]; ok {
+		return st
+	}
+	return DefaultCascade[event]
+}
+
+// CascadeRules returns the project's cascade, falling back to DefaultCascade
+// when its overrides cannot be parsed.
+func (p *Project) CascadeRules() Cascade {
+	c, err := ParseCascade(p.Cascade)
+	if err != nil {
+		c, _ = ParseCascade("")
+	}
+	return c
+}
+
+// Valid reports whether e is a known cascade event.
+func (e CascadeEvent) Valid() bool { return slices.Contains(CascadeEvents, e) }
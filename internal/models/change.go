@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// ChangeEntity is the kind of record a Change is about.
+type ChangeEntity string
+
+const (
+	ChangeProject  ChangeEntity = "project"
+	ChangeIssue    ChangeEntity = "issue"
+	ChangeSession  ChangeEntity = "session"
+	ChangeReview   ChangeEntity = "review"
+	ChangeRelation ChangeEntity = "relation"
+	ChangeComment  ChangeEntity = "comment"
+	ChangeNote     ChangeEntity = "note"
+)
+
+// ChangeEntities lists the record kinds the change feed follows.
+var ChangeEntities = []ChangeEntity{ChangeProject, ChangeIssue, ChangeSession, ChangeReview, ChangeRelation, ChangeComment, ChangeNote}
+
+// ChangeOp is what happened to the record.
+type ChangeOp string
+
+const (
+	ChangeInsert ChangeOp = "insert"
+	ChangeUpdate ChangeOp = "update"
+	ChangeDelete ChangeOp = "delete"
+)
+
+// Change notes that a record was inserted, updated or deleted, for clients
+// that follow the store incrementally. It carries no record contents: a
+// follower fetches the record by ID, or drops it on delete. Seq increases
+// with every change and is the feed's cursor.
+type Change struct {
+	Seq       int64
+	Entity    ChangeEntity
+	EntityID  string
+	ProjectID string // "" when the owning record was already gone, e.g. cascaded deletes
+	Op        ChangeOp
+	At        time.Time
+}
+
+// ParseChangeEntity converts user input to a ChangeEntity, rejecting unknown
+// values.
+func ParseChangeEntity(s string) (ChangeEntity, error) {
+	return parseEnum("change entity", s, ChangeEntities)
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Closure sources: what referenced the issue with a closing keyword.
+const (
+	ClosureSourceCommit = "commit"
+	ClosureSourcePR     = "pr"
+)
+
+// IssueClosure records an issue moved to done because a commit or merged pull
+// request referenced it ("Fixes #123", "Closes PM-01J5ABCD"), so the change
+// can be traced and reverted.
+type IssueClosure struct {
+	ID         string
+	IssueID    string
+	ProjectID  string
+	SessionID  string // session whose merge carried the commit ("" for pull requests)
+	Source     string // ClosureSourceCommit or ClosureSourcePR
+	Ref        string // commit hash or pull request number ("#42")
+	Message    string // subject of the commit or title of the pull request
+	FromStatus IssueStatus
+	ToStatus   IssueStatus
+	// GitHub issue closed along with the pm issue (0 = none)
+	GitHubIssue int
+	CreatedAt   time.Time
+	RevertedAt  *time.Time
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// ProjectDependency records that one tracked project depends on another
+// (its upstream).
+type ProjectDependency struct {
+	ID          string
+	ProjectID   string
+	DependsOnID string
+	CreatedAt   time.Time
+}
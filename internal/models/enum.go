@@ -0,0 +1,121 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// IssueStatuses lists the valid issue statuses.
+var IssueStatuses = []IssueStatus{IssueStatusOpen, IssueStatusInProgress, IssueStatusInReview, IssueStatusDone, IssueStatusClosed}
+
+// IssuePriorities lists the valid issue priorities, lowest first.
+var IssuePriorities = []IssuePriority{IssuePriorityLow, IssuePriorityMedium, IssuePriorityHigh}
+
+// IssueTypes lists the valid issue types.
+var IssueTypes = []IssueType{IssueTypeFeature, IssueTypeBug, IssueTypeChore}
+
+// SessionStatuses lists the valid session statuses.
+var SessionStatuses = []SessionStatus{SessionStatusActive, SessionStatusIdle, SessionStatusBlocked, SessionStatusCompleted, SessionStatusAbandoned}
+
+// ReviewVerdicts lists the valid review verdicts.
+var ReviewVerdicts = []ReviewVerdict{ReviewVerdictPass, ReviewVerdictFail}
+
+// ReviewCategories lists the valid review category ratings.
+var ReviewCategories = []ReviewCategory{ReviewCategoryPass, ReviewCategoryFail, ReviewCategorySkip, ReviewCategoryNA}
+
+// Valid reports whether s is a known issue status.
+func (s IssueStatus) Valid() bool { return slices.Contains(IssueStatuses, s) }
+
+// Valid reports whether p is a known issue priority.
+func (p IssuePriority) Valid() bool { return slices.Contains(IssuePriorities, p) }
+
+// Valid reports whether t is a known issue type.
+func (t IssueType) Valid() bool { return slices.Contains(IssueTypes, t) }
+
+// Valid reports whether s is a known session status.
+func (s SessionStatus) Valid() bool { return slices.Contains(SessionStatuses, s) }
+
+// Valid reports whether v is a known review verdict.
+func (v ReviewVerdict) Valid() bool { return slices.Contains(ReviewVerdicts, v) }
+
+// Valid reports whether c is a known review category rating.
+func (c ReviewCategory) Valid() bool { return slices.Contains(ReviewCategories, c) }
+
+// ParseIssueStatus converts user input to an IssueStatus, rejecting unknown
+// values.
+func ParseIssueStatus(s string) (IssueStatus, error) {
+	return parseEnum("issue status", s, IssueStatuses)
+}
+
+// ParseIssuePriority converts user input to an IssuePriority, rejecting
+// unknown values.
+func ParseIssuePriority(s string) (IssuePriority, error) {
+	return parseEnum("issue priority", s, IssuePriorities)
+}
+
+// ParseIssueType converts user input to an IssueType, rejecting unknown values.
+func ParseIssueType(s string) (IssueType, error) {
+	return parseEnum("issue type", s, IssueTypes)
+}
+
+// ParseSessionStatus converts user input to a SessionStatus, rejecting unknown
+// values.
+func ParseSessionStatus(s string) (SessionStatus, error) {
+	return parseEnum("session status", s, SessionStatuses)
+}
+
+// ParseReviewVerdict converts user input to a ReviewVerdict, rejecting unknown
+// values.
+func ParseReviewVerdict(s string) (ReviewVerdict, error) {
+	return parseEnum("review verdict", s, ReviewVerdicts)
+}
+
+// ParseReviewCategory converts user input to a ReviewCategory, rejecting
+// unknown values.
+func ParseReviewCategory(s string) (ReviewCategory, error) {
+	return parseEnum("review category", s, ReviewCategories)
+}
+
+// parseEnum returns s as a T when it is one of valid. The error names the
+// valid values so a typo is easy to fix.
+func parseEnum[T ~string](kind, s string, valid []T) (T, error) {
+	v := T(s)
+	if slices.Contains(valid, v) {
+		return v, nil
+	}
+	names := make([]string, len(valid))
+	for i, x := range valid {
+		names[i] = string(x)
+	}
+	return "", fmt.Errorf("invalid %s %q (want %s)", kind, s, strings.Join(names, ", "))
+}
+
+// Validate checks that the issue's status, priority and type are known values.
+func (i *Issue) Validate() error {
+	if _, err := ParseIssueStatus(string(i.Status)); err != nil {
+		return err
+	}
+	if _, err := ParseIssuePriority(string(i.Priority)); err != nil {
+		return err
+	}
+	_, err := ParseIssueType(string(i.Type))
+	return err
+}
+
+// Validate checks that the review's verdict and category ratings are known
+// values. An empty category is an aspect the review did not rate.
+func (r *IssueReview) Validate() error {
+	if _, err := ParseReviewVerdict(string(r.Verdict)); err != nil {
+		return err
+	}
+	for _, c := range []ReviewCategory{r.CodeQuality, r.RequirementsMatch, r.TestCoverage, r.UIUX} {
+		if c == "" {
+			continue
+		}
+		if _, err := ParseReviewCategory(string(c)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
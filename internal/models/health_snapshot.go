@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// HealthSnapshot records a project's health score components and the raw
+// metadata behind them at the time of a refresh.
+type HealthSnapshot struct {
+	ID               string
+	ProjectID        string
+	Total            int
+	GitCleanliness   int
+	ActivityRecency  int
+	IssueHealth      int
+	ReleaseFreshness int
+	BranchHygiene    int
+	IsDirty          bool
+	BranchCount      int
+	WorktreeCount    int
+	LatestRelease    string
+	CreatedAt        time.Time
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // IssueStatus represents the state of an issue.
 type IssueStatus string
@@ -8,6 +11,7 @@ type IssueStatus string
 const (
 	IssueStatusOpen       IssueStatus = "open"
 	IssueStatusInProgress IssueStatus = "in_progress"
+	IssueStatusInReview   IssueStatus = "in_review" // work finished, waiting on a review
 	IssueStatusDone       IssueStatus = "done"
 	IssueStatusClosed     IssueStatus = "closed"
 )
@@ -33,6 +37,7 @@ const (
 // Issue represents a tracked issue/feature for a project.
 type Issue struct {
 	ID          string
+	Code        string // short code, e.g. "WID-12"; assigned by the store on creation
 	ProjectID   string
 	Title       string
 	Description string
@@ -42,8 +47,98 @@ type Issue struct {
 	Priority    IssuePriority
 	Type        IssueType
 	Tags        []string
-	GitHubIssue int // linked GitHub issue number (0 = none)
+	GitHubIssue int         // linked GitHub issue number (0 = none)
+	Assignee    string      // person or agent working on the issue ("" = unassigned)
+	Estimate    string      // free-form size estimate, e.g. "2h", "3pt", "M" ("" = not estimated)
+	Rank        string      // hand-ordered position in its status column (lexorank; "" = unranked, after the ranked); set by store.RankIssue, cleared by a status change
+	SLAState    SLAState    // set by SLA policy evaluation
+	Redactions  []Redaction // removed from the text sent to the LLM by the last enrichment
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	ClosedAt    *time.Time
+	StartedAt   *time.Time // first moved to in_progress; maintained by the store
+	CompletedAt *time.Time // reached done or closed; cleared on reopen; maintained by the store
+
+	// Where the issue's code landed, set when one of its sessions merges:
+	// the base branch tip after the merge, the base branch, and when
+	MergeCommit string
+	MergeBranch string
+	MergedAt    *time.Time
+
+	// Testable conditions the work must meet; reviews record a result for each
+	AcceptanceCriteria []string
+
+	Readiness *IssueReadiness // computed for API and CLI output by agent.AssessReadiness; never stored
+}
+
+// TrimCriteria returns acceptance criteria with surrounding whitespace
+// removed and blank ones dropped, or nil if none are left.
+func TrimCriteria(criteria []string) []string {
+	var out []string
+	for _, c := range criteria {
+		if c = strings.TrimSpace(c); c != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// LeadTime is how long a completed issue took from creation to completion.
+func (i *Issue) LeadTime() (time.Duration, bool) {
+	if i.CompletedAt == nil {
+		return 0, false
+	}
+	return i.CompletedAt.Sub(i.CreatedAt), true
+}
+
+// CycleTime is how long a completed issue took from first being started to
+// completion. Issues completed without ever being in progress have none.
+func (i *Issue) CycleTime() (time.Duration, bool) {
+	if i.StartedAt == nil || i.CompletedAt == nil {
+		return 0, false
+	}
+	return i.CompletedAt.Sub(*i.StartedAt), true
+}
+
+// IssueStatusEvent records an issue entering a status. The first event of an
+// issue has an empty FromStatus.
+type IssueStatusEvent struct {
+	ID         int64
+	IssueID    string
+	ProjectID  string
+	FromStatus IssueStatus
+	ToStatus   IssueStatus
+	ChangedAt  time.Time
+}
+
+// Redaction counts the matches of one redaction rule removed from text sent
+// to the LLM.
+type Redaction struct {
+	Rule  string
+	Count int
+}
+
+// IssueReadiness is how well an issue is specified for an agent to work on.
+type IssueReadiness struct {
+	Score  int  // 0-100: the share of checks that pass
+	Ready  bool // every check passes
+	Checks []ReadinessCheck
+}
+
+// ReadinessCheck is one item of the "ready for agent" checklist.
+type ReadinessCheck struct {
+	Name   string // body, ai_prompt, acceptance_criteria, no_open_blockers, estimate
+	Passed bool
+	Hint   string // what to do when the check fails
+}
+
+// Missing returns the hints of the failed checks.
+func (r *IssueReadiness) Missing() []string {
+	var out []string
+	for _, c := range r.Checks {
+		if !c.Passed {
+			out = append(out, c.Hint)
+		}
+	}
+	return out
 }
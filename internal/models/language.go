@@ -0,0 +1,29 @@
+package models
+
+// LanguageShare is one language's part of a project's source lines.
+type LanguageShare struct {
+	Language string  `json:"language"`
+	Lines    int     `json:"lines"`
+	Percent  float64 `json:"percent"` // of all counted lines, one decimal
+}
+
+// minSecondaryPercent is the share a second language needs to count as one
+// of the project's main languages.
+const minSecondaryPercent = 10
+
+// MainLanguages returns the project's primary language and, when it makes
+// up a real part of the code, its secondary one. Without a breakdown it
+// falls back to Language.
+func (p *Project) MainLanguages() []string {
+	if len(p.Languages) == 0 {
+		if p.Language == "" {
+			return nil
+		}
+		return []string{p.Language}
+	}
+	langs := []string{p.Languages[0].Language}
+	if len(p.Languages) > 1 && p.Languages[1].Percent >= minSecondaryPercent {
+		langs = append(langs, p.Languages[1].Language)
+	}
+	return langs
+}
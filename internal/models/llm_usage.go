@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// LLMUsagePurpose is what an LLM call was made for.
+type LLMUsagePurpose string
+
+const (
+	LLMUsageReviewPreAnalysis LLMUsagePurpose = "review_pre_analysis"
+	LLMUsageEnrichment        LLMUsagePurpose = "enrichment"
+	LLMUsageImport            LLMUsagePurpose = "import"
+	LLMUsageCommitMessage     LLMUsagePurpose = "commit_message"
+	LLMUsageSessionSummary    LLMUsagePurpose = "session_summary"
+)
+
+// LLMUsage records the tokens an LLM call spent, so its cost can be
+// reported per project and issue. It outlives the issue: IssueID is kept
+// after the issue is deleted.
+type LLMUsage struct {
+	ID           string
+	ProjectID    string
+	IssueID      string // "" = not for an issue
+	Purpose      LLMUsagePurpose
+	Model        string
+	InputTokens  int64
+	OutputTokens int64
+	CreatedAt    time.Time
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// ProjectNote is a free-form Markdown page in a project's wiki, such as an
+// architecture decision record or a runbook. Titles are unique per project.
+type ProjectNote struct {
+	ID        string
+	ProjectID string
+	Title     string
+	Body      string // Markdown
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// GitHub pull request review states imported by the review sync.
+const (
+	PRReviewApproved         = "APPROVED"
+	PRReviewChangesRequested = "CHANGES_REQUESTED"
+	PRReviewCommented        = "COMMENTED"
+)
+
+// PRReviewImport records a GitHub pull request review attached as a comment
+// to the issue whose session opened the pull request, so a review is imported
+// once.
+type PRReviewImport struct {
+	ID        string
+	IssueID   string
+	ProjectID string
+	PRNumber  int
+	ReviewID  int64  // GitHub review ID
+	Reviewer  string // GitHub login
+	State     string // PRReviewApproved, PRReviewChangesRequested or PRReviewCommented
+	URL       string // review on GitHub
+	CommentID string // issue comment holding the review
+	// Follow-up issue created for a changes-requested review ("" = none)
+	FollowUpIssueID string
+	CreatedAt       time.Time
+}
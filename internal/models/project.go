@@ -6,10 +6,12 @@ import "time"
 type Project struct {
 	ID             string
 	Name           string
+	Key            string // prefixes its issue and session short codes, e.g. "WID"; derived from Name on creation if unset, changed by Store.SetProjectKey
 	Path           string
 	Description    string
 	RepoURL        string
 	Language       string
+	Languages      []LanguageShare // Line-count breakdown from refresh, largest first
 	GroupName      string
 	BranchCount    int
 	HasGitHubPages bool
@@ -17,6 +19,52 @@ type Project struct {
 	BuildCmd       string
 	ServeCmd       string
 	ServePort      int
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	Conventions    string // Cached AGENTS.md/CLAUDE.md/CONVENTIONS.md contents
+
+	// WIP limits (0 = no limit)
+	MaxInProgress     int  // Max issues in progress
+	MaxActiveSessions int  // Max active/idle agent sessions
+	WIPHardBlock      bool // Refuse launches that would exceed a limit instead of warning
+
+	// Commit conventions checked by close-check and merge ("" = no check)
+	CommitStyle        string // "conventional" for Conventional Commits
+	CommitTicketPrefix string // Messages must reference a ticket like <prefix>123
+
+	RefreshSkip string // Comma-separated refresh steps not run for this project
+
+	// Comma-separated paths every worktree of the project checks out sparsely
+	// ("" = full checkout unless a launch asks for sparse)
+	SparsePaths string
+
+	MergeStrategy MergeStrategy // Default strategy for merging session branches ("" = merge)
+
+	// Comma-separated event=status overrides of DefaultCascade, e.g.
+	// "completed=in_review" ("" = defaults)
+	Cascade string
+
+	// GitHub Projects (v2) board the issues' status and priority are synced
+	// with, as "owner/number" ("" = none)
+	GitHubBoard string
+
+	// Comma-separated glob rules ("*.tsx", "templates/") for the files whose
+	// changes need a UI review ("" = defaults for the project's languages)
+	UIReviewPaths string
+
+	Archived bool // Hidden from project lists, status and refresh-all
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
+
+// MergeStrategy is how a session's branch lands on its base branch.
+type MergeStrategy string
+
+const (
+	MergeStrategyMerge  MergeStrategy = "merge"   // merge commit
+	MergeStrategyRebase MergeStrategy = "rebase"  // rebase onto base, then fast-forward
+	MergeStrategySquash MergeStrategy = "squash"  // one commit with a message built from the issues
+	MergeStrategyFFOnly MergeStrategy = "ff-only" // fast-forward only; refused when the branch is behind
+)
+
+// MergeStrategies lists the valid merge strategies.
+var MergeStrategies = []MergeStrategy{MergeStrategyMerge, MergeStrategyRebase, MergeStrategySquash, MergeStrategyFFOnly}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// PushSubscription is a browser's Web Push subscription, registered by the
+// dashboard so `pm serve` can notify it about sessions.
+type PushSubscription struct {
+	ID        string
+	Endpoint  string // push service URL, unique per browser
+	P256dh    string // the browser's public key (base64url)
+	Auth      string // authentication secret (base64url)
+	UserAgent string
+	CreatedAt time.Time
+}
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// RelationType is the kind of link between two issues.
+type RelationType string
+
+const (
+	RelationRelatesTo  RelationType = "relates_to"
+	RelationDuplicates RelationType = "duplicates"
+	RelationCausedBy   RelationType = "caused_by"
+	RelationBlocks     RelationType = "blocks"
+	RelationSplitFrom  RelationType = "split_from" // from an issue split off to the issue it came from
+)
+
+// RelationTypes lists the supported relation types.
+var RelationTypes = []RelationType{RelationRelatesTo, RelationDuplicates, RelationCausedBy, RelationBlocks, RelationSplitFrom}
+
+// Valid reports whether t is a supported relation type.
+func (t RelationType) Valid() bool {
+	for _, rt := range RelationTypes {
+		if t == rt {
+			return true
+		}
+	}
+	return false
+}
+
+// Label describes the relation as read from the source issue, e.g. "caused by".
+func (t RelationType) Label() string {
+	switch t {
+	case RelationRelatesTo:
+		return "relates to"
+	case RelationDuplicates:
+		return "duplicates"
+	case RelationCausedBy:
+		return "caused by"
+	case RelationBlocks:
+		return "blocks"
+	case RelationSplitFrom:
+		return "split from"
+	}
+	return string(t)
+}
+
+// InverseLabel describes the relation as read from the target issue, e.g. "causes".
+func (t RelationType) InverseLabel() string {
+	switch t {
+	case RelationRelatesTo:
+		return "relates to"
+	case RelationDuplicates:
+		return "duplicated by"
+	case RelationCausedBy:
+		return "causes"
+	case RelationBlocks:
+		return "blocked by"
+	case RelationSplitFrom:
+		return "split into"
+	}
+	return string(t)
+}
+
+// IssueRelation is a typed, directed link from one issue to another.
+// The issues may belong to different projects.
+type IssueRelation struct {
+	ID            string
+	SourceIssueID string
+	TargetIssueID string
+	Type          RelationType
+	CreatedAt     time.Time
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// ProjectRelease is a project's latest GitHub release as last fetched by the
+// release poller, so reads do not wait on GitHub. A failed fetch keeps the
+// release fetched before and records why.
+type ProjectRelease struct {
+	ProjectID   string
+	TagName     string // "" until a fetch finds a release
+	PublishedAt *time.Time
+	Assets      []ReleaseAsset
+	FetchedAt   *time.Time // last successful fetch (nil = none yet)
+	CheckedAt   time.Time  // last fetch, successful or not
+	Error       string     // why the last fetch failed ("" = it succeeded)
+}
+
+// ReleaseAsset is a file attached to a release.
+type ReleaseAsset struct {
+	Name          string `json:"name"`
+	DownloadCount int    `json:"downloadCount"`
+	Size          int64  `json:"size"`
+}
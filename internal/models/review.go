@@ -13,11 +13,28 @@ const (
 // ReviewCategory is a per-aspect rating.
 type ReviewCategory string
 
-// IssueReview records a single AI review of an issue's implementation.
+const (
+	ReviewCategoryPass ReviewCategory = "pass"
+	ReviewCategoryFail ReviewCategory = "fail"
+	ReviewCategorySkip ReviewCategory = "skip"
+	ReviewCategoryNA   ReviewCategory = "na"
+)
+
+// CriterionResult is a review's finding for one of the issue's acceptance
+// criteria.
+type CriterionResult struct {
+	Criterion string
+	Passed    bool
+	Note      string // evidence or what is missing
+}
+
+// IssueReview records a single review of an issue's implementation, by an
+// agent or, through a ReviewShare link, by a person.
 type IssueReview struct {
 	ID                string
 	IssueID           string
 	SessionID         string
+	Reviewer          string // the person who reviewed through a share link; "" for agents
 	Verdict           ReviewVerdict
 	Summary           string
 	CodeQuality       ReviewCategory
@@ -25,6 +42,7 @@ type IssueReview struct {
 	TestCoverage      ReviewCategory
 	UIUX              ReviewCategory
 	FailureReasons    []string
+	Criteria          []CriterionResult // one per acceptance criterion the review checked
 	DiffStats         string
 	ReviewedAt        time.Time
 	CreatedAt         time.Time
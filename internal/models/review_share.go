@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// ReviewShare is a time-limited link that lets someone without pm read an
+// issue's review context and submit one verdict. Only the SHA-256 hash of the
+// link's token is stored.
+type ReviewShare struct {
+	ID        string
+	IssueID   string
+	Reviewer  string // who the link is for, recorded on the review
+	TokenHash string `json:"-"`
+	ExpiresAt time.Time
+	ReviewID  string // the review submitted through the link; "" until then
+	CreatedAt time.Time
+}
+
+// Expired reports whether the link no longer grants access at now.
+func (rs *ReviewShare) Expired(now time.Time) bool {
+	return !now.Before(rs.ExpiresAt)
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MaxProjectKeyLength caps a project key.
+const MaxProjectKeyLength = 10
+
+var (
+	projectKeyRe = regexp.MustCompile(`^[A-Z][A-Z0-9]*$`)
+	shortCodeRe  = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]*)-([Ss]?)([0-9]+)$`)
+)
+
+// ValidateProjectKey checks that key can prefix short codes: an uppercase
+// letter followed by uppercase letters and digits.
+func ValidateProjectKey(key string) error {
+	if !projectKeyRe.MatchString(key) || len(key) > MaxProjectKeyLength {
+		return fmt.Errorf("invalid project key %q: want an uppercase letter followed by up to %d uppercase letters or digits", key, MaxProjectKeyLength-1)
+	}
+	return nil
+}
+
+// DeriveProjectKey suggests a key for a project name: the initials of a
+// name of several words ("web-app" → "WA"), else its first three letters
+// and digits ("widgets" → "WID").
+func DeriveProjectKey(name string) string {
+	words := strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool {
+		return r > unicode.MaxASCII || !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var key string
+	if len(words) > 1 {
+		for _, w := range words {
+			if len(key) == 4 {
+				break
+			}
+			key += w[:1]
+		}
+	} else if len(words) == 1 {
+		key = words[0][:min(3, len(words[0]))]
+	}
+	if key == "" || !unicode.IsLetter(rune(key[0])) {
+		key = "P" + key
+	}
+	return key
+}
+
+// IssueCode is the short code of a project's nth issue, e.g. "WID-12".
+func IssueCode(key string, n int) string {
+	return key + "-" + strconv.Itoa(n)
+}
+
+// SessionCode is the short code of a project's nth session, e.g. "WID-S3".
+func SessionCode(key string, n int) string {
+	return key + "-S" + strconv.Itoa(n)
+}
+
+// ParseShortCode reports whether ref looks like an issue or session short
+// code, returning it normalized to upper case.
+func ParseShortCode(ref string) (code string, session bool, ok bool) {
+	m := shortCodeRe.FindStringSubmatch(strings.TrimSpace(ref))
+	if m == nil {
+		return "", false, false
+	}
+	return strings.ToUpper(m[0]), m[2] != "", true
+}
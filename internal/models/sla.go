@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// SLAState is how an issue stands against the SLA policy that applies to it.
+type SLAState string
+
+const (
+	SLAStateNone     SLAState = ""         // no policy applies
+	SLAStateOK       SLAState = "ok"       // within all deadlines
+	SLAStateAtRisk   SLAState = "at_risk"  // most of a deadline has elapsed
+	SLAStateBreached SLAState = "breached" // a deadline was missed
+)
+
+// SLABreachKind identifies which deadline of a policy was missed.
+type SLABreachKind string
+
+const (
+	SLABreachStart    SLABreachKind = "start"    // not started in time
+	SLABreachComplete SLABreachKind = "complete" // not completed in time
+)
+
+// SLABreach records the first time an issue missed a policy deadline.
+type SLABreach struct {
+	ID         string
+	IssueID    string
+	ProjectID  string
+	Policy     string
+	Kind       SLABreachKind
+	BreachedAt time.Time
+}
@@ -8,3 +8,10 @@ type Tag struct {
 	Name      string
 	CreatedAt time.Time
 }
+
+// TagUsage is a tag with the number of issues it is applied to.
+type TagUsage struct {
+	Tag
+	Issues int // issues with the tag
+	Open   int // of those, not done or closed
+}
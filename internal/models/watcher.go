@@ -0,0 +1,75 @@
+package models
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// WatcherKind is how a watcher is notified about an issue.
+type WatcherKind string
+
+const (
+	WatcherEmail   WatcherKind = "email"   // an email address, sent through notify.email
+	WatcherWebhook WatcherKind = "webhook" // an http(s) URL that receives a JSON POST
+)
+
+// WatcherKinds lists the valid watcher kinds.
+var WatcherKinds = []WatcherKind{WatcherEmail, WatcherWebhook}
+
+// IssueWatcher subscribes a person or a webhook to an issue's status changes,
+// comments and review verdicts. Targets are unique per issue.
+type IssueWatcher struct {
+	ID        string
+	IssueID   string
+	Kind      WatcherKind
+	Target    string // email address or webhook URL
+	CreatedAt time.Time
+}
+
+// IssueComment is a remark left on an issue by a person or an agent.
+type IssueComment struct {
+	ID        string
+	IssueID   string
+	Author    string
+	Body      string // Markdown
+	CreatedAt time.Time
+}
+
+// ParseWatcherTarget validates a watcher target and returns its kind: an
+// http(s) URL is a webhook, anything else must be an email address. A
+// non-empty kind is checked against the target instead of inferred. Email
+// targets are returned as the bare, lower-cased address.
+func ParseWatcherTarget(kind, target string) (WatcherKind, string, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "", "", fmt.Errorf("watcher target is required")
+	}
+	if kind == "" {
+		kind = string(WatcherEmail)
+		if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+			kind = string(WatcherWebhook)
+		}
+	}
+	k, err := parseEnum("watcher kind", kind, WatcherKinds)
+	if err != nil {
+		return "", "", err
+	}
+
+	switch k {
+	case WatcherWebhook:
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", "", fmt.Errorf("invalid webhook URL %q (want http:// or https://)", target)
+		}
+	case WatcherEmail:
+		addr, err := mail.ParseAddress(target)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid email address %q", target)
+		}
+		target = strings.ToLower(addr.Address)
+	}
+	return k, target, nil
+}
@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// EmailConfig holds SMTP settings for the email notifier.
+type EmailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// Message is a multipart email with plain-text and HTML alternatives.
+type Message struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// sendMailFunc matches smtp.SendMail; overridden in tests.
+type sendMailFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+// EmailNotifier sends messages over SMTP.
+type EmailNotifier struct {
+	cfg      EmailConfig
+	sendMail sendMailFunc
+	now      func() time.Time
+}
+
+// NewEmailNotifier validates cfg and returns a notifier.
+func NewEmailNotifier(cfg EmailConfig) (*EmailNotifier, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("smtp host is not configured")
+	}
+	if cfg.From == "" {
+		return nil, errors.New("sender address is not configured")
+	}
+	if len(cfg.To) == 0 {
+		return nil, errors.New("no recipients configured")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &EmailNotifier{cfg: cfg, sendMail: smtp.SendMail, now: time.Now}, nil
+}
+
+// Send delivers msg to each configured recipient as a separate message, so
+// recipients never see each other's addresses. A failed delivery does not
+// stop the rest; all failures are returned together.
+func (n *EmailNotifier) Send(msg Message) error {
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+	var errs []error
+	for _, to := range n.cfg.To {
+		body, err := n.build(msg, to)
+		if err != nil {
+			return err
+		}
+		if err := n.sendMail(addr, auth, n.cfg.From, []string{to}, body); err != nil {
+			errs = append(errs, fmt.Errorf("send email to %s via %s: %w", to, addr, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// build renders msg for one recipient as a multipart/alternative MIME message.
+func (n *EmailNotifier) build(msg Message, to string) ([]byte, error) {
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&b, "%s: %s\r\n", k, v) }
+	header("From", n.cfg.From)
+	header("To", to)
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", n.now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Auto-Submitted", "auto-generated")
+	header("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", boundary))
+	b.WriteString("\r\n")
+
+	parts := []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	}
+	for _, part := range parts {
+		if part.body == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "--%s\r\n", boundary)
+		header("Content-Type", part.contentType)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		b.WriteString("\r\n")
+		qp := quotedprintable.NewWriter(&b)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+		b.WriteString("\r\n")
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return b.Bytes(), nil
+}
+
+func randomBoundary() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate mime boundary: %w", err)
+	}
+	return "pm-" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEmailNotifier_Validation(t *testing.T) {
+	_, err := NewEmailNotifier(EmailConfig{From: "pm@example.com", To: []string{"a@example.com"}})
+	assert.ErrorContains(t, err, "host")
+
+	_, err = NewEmailNotifier(EmailConfig{Host: "smtp.example.com", To: []string{"a@example.com"}})
+	assert.ErrorContains(t, err, "sender")
+
+	_, err = NewEmailNotifier(EmailConfig{Host: "smtp.example.com", From: "pm@example.com"})
+	assert.ErrorContains(t, err, "recipients")
+
+	n, err := NewEmailNotifier(EmailConfig{Host: "smtp.example.com", From: "pm@example.com", To: []string{"a@example.com"}})
+	require.NoError(t, err)
+	assert.Equal(t, 587, n.cfg.Port)
+}
+
+func TestEmailNotifier_Send(t *testing.T) {
+	n, err := NewEmailNotifier(EmailConfig{
+		Host:     "smtp.example.com",
+		Port:     2525,
+		Username: "user",
+		Password: "secret",
+		From:     "pm@example.com",
+		To:       []string{"a@example.com", "b@example.com"},
+	})
+	require.NoError(t, err)
+	n.now = func() time.Time { return time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC) }
+
+	var gotAddr, gotFrom string
+	var gotTo [][]string
+	var gotAuth smtp.Auth
+	var raws [][]byte
+	n.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotAuth, gotFrom = addr, a, from
+		gotTo, raws = append(gotTo, to), append(raws, msg)
+		return nil
+	}
+
+	err = n.Send(Message{Subject: "pm daily digest", Text: "plain body", HTML: "<p>html body</p>"})
+	require.NoError(t, err)
+	assert.Equal(t, "smtp.example.com:2525", gotAddr)
+	assert.NotNil(t, gotAuth)
+	assert.Equal(t, "pm@example.com", gotFrom)
+	assert.Equal(t, [][]string{{"a@example.com"}, {"b@example.com"}}, gotTo, "one message per recipient")
+
+	m, err := mail.ReadMessage(strings.NewReader(string(raws[1])))
+	require.NoError(t, err)
+	assert.Equal(t, "b@example.com", m.Header.Get("To"), "recipients do not see each other")
+	assert.Equal(t, "pm daily digest", m.Header.Get("Subject"))
+	assert.Empty(t, m.Header.Get("List-Unsubscribe"))
+
+	mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	mr := multipart.NewReader(m.Body, params["boundary"])
+	var types, bodies []string
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		types = append(types, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+	}
+	assert.Equal(t, []string{"text/plain; charset=utf-8", "text/html; charset=utf-8"}, types)
+	assert.Equal(t, []string{"plain body", "<p>html body</p>"}, bodies)
+}
+
+func TestEmailNotifier_SendError(t *testing.T) {
+	n, err := NewEmailNotifier(EmailConfig{Host: "smtp.example.com", From: "pm@example.com", To: []string{"a@example.com", "b@example.com"}})
+	require.NoError(t, err)
+	var sent []string
+	n.sendMail = func(_ string, _ smtp.Auth, _ string, to []string, _ []byte) error {
+		if to[0] == "a@example.com" {
+			return errors.New("connection refused")
+		}
+		sent = append(sent, to[0])
+		return nil
+	}
+	err = n.Send(Message{Subject: "s", Text: "t"})
+	assert.ErrorContains(t, err, "a@example.com via smtp.example.com:587")
+	assert.ErrorContains(t, err, "connection refused")
+	assert.Equal(t, []string{"b@example.com"}, sent, "one failure does not stop the rest")
+}
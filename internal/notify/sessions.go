@@ -0,0 +1,151 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// SubscriptionStore holds the browsers' push subscriptions. store.Store
+// satisfies this interface.
+type SubscriptionStore interface {
+	ListPushSubscriptions(ctx context.Context) ([]*models.PushSubscription, error)
+	DeletePushSubscription(ctx context.Context, endpoint string) error
+}
+
+// Broadcast sends msg to every subscription and returns how many received it.
+// Subscriptions the push service reports as gone are deleted.
+func Broadcast(ctx context.Context, s SubscriptionStore, n *PushNotifier, msg PushMessage) (int, error) {
+	subs, err := s.ListPushSubscriptions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	var errs []error
+	for _, ps := range subs {
+		var sub Subscription
+		sub.Endpoint = ps.Endpoint
+		sub.Keys.P256dh, sub.Keys.Auth = ps.P256dh, ps.Auth
+		err := n.Send(ctx, sub, msg)
+		switch {
+		case errors.Is(err, ErrSubscriptionGone):
+			_ = s.DeletePushSubscription(ctx, ps.Endpoint)
+		case err != nil:
+			errs = append(errs, err)
+		default:
+			sent++
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+// SessionStore is the subset of store.Store the session watcher reads.
+type SessionStore interface {
+	SubscriptionStore
+	ListProjects(ctx context.Context, group string) ([]*models.Project, error)
+	ListAgentSessionsFiltered(ctx context.Context, filter store.SessionListFilter) ([]*models.AgentSession, error)
+}
+
+// sessionState is what the watcher remembers of a session between checks.
+type sessionState struct {
+	status   models.SessionStatus
+	conflict models.ConflictState
+}
+
+// SessionWatcher pushes a notification when a session completes or runs into
+// a sync or merge conflict. It compares the sessions on every Check with what
+// it saw the time before, so it notices changes made by any pm process.
+type SessionWatcher struct {
+	store  SessionStore
+	pusher *PushNotifier
+	seen   map[string]sessionState // nil until the first Check
+}
+
+// NewSessionWatcher returns a watcher that notifies through n.
+func NewSessionWatcher(s SessionStore, n *PushNotifier) *SessionWatcher {
+	return &SessionWatcher{store: s, pusher: n}
+}
+
+// Check looks for session changes since the last check and pushes them. The
+// first check only records the current state.
+func (w *SessionWatcher) Check(ctx context.Context) error {
+	sessions, err := w.store.ListAgentSessionsFiltered(ctx, store.SessionListFilter{})
+	if err != nil {
+		return fmt.Errorf("list sessions: %w", err)
+	}
+	projects, err := w.store.ListProjects(ctx, "")
+	if err != nil {
+		return fmt.Errorf("list projects: %w", err)
+	}
+	names := make(map[string]string, len(projects))
+	for _, p := range projects {
+		names[p.ID] = p.Name
+	}
+
+	msgs, seen := sessionAlerts(w.seen, sessions, names)
+	w.seen = seen
+	var errs []error
+	for _, msg := range msgs {
+		if _, err := Broadcast(ctx, w.store, w.pusher, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sessionAlerts returns the notifications for the changes between seen and
+// sessions, and the state to compare the next check with. A nil seen yields
+// no notifications.
+func sessionAlerts(seen map[string]sessionState, sessions []*models.AgentSession, projectNames map[string]string) ([]PushMessage, map[string]sessionState) {
+	next := make(map[string]sessionState, len(sessions))
+	var msgs []PushMessage
+	for _, sess := range sessions {
+		cur := sessionState{status: sess.Status, conflict: sess.ConflictState}
+		next[sess.ID] = cur
+		if seen == nil {
+			continue
+		}
+		prev, known := seen[sess.ID]
+
+		where := sess.Branch
+		if name := projectNames[sess.ProjectID]; name != "" {
+			where = name + ": " + sess.Branch
+		}
+		link := "/sessions/" + sess.ID
+
+		if cur.status == models.SessionStatusCompleted && known && prev.status != models.SessionStatusCompleted {
+			msgs = append(msgs, PushMessage{Title: "Session completed", Body: where, URL: link, Tag: "session-" + sess.ID})
+		}
+		if isConflict(cur.conflict) && cur.conflict != prev.conflict {
+			title := "Sync conflict"
+			if cur.conflict == models.ConflictStateMergeConflict {
+				title = "Merge conflict"
+			}
+			body := where
+			if files := conflictFiles(sess); files != "" {
+				body += " — " + files
+			}
+			msgs = append(msgs, PushMessage{Title: title, Body: body, URL: link, Tag: "conflict-" + sess.ID})
+		}
+	}
+	return msgs, next
+}
+
+func isConflict(c models.ConflictState) bool {
+	return c == models.ConflictStateSyncConflict || c == models.ConflictStateMergeConflict
+}
+
+// conflictFiles lists up to three of a session's conflicting files.
+func conflictFiles(sess *models.AgentSession) string {
+	var files []string
+	_ = json.Unmarshal([]byte(sess.ConflictFiles), &files)
+	if len(files) > 3 {
+		return strings.Join(files[:3], ", ") + fmt.Sprintf(" and %d more", len(files)-3)
+	}
+	return strings.Join(files, ", ")
+}
@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+func TestSessionAlerts(t *testing.T) {
+	names := map[string]string{"p1": "api"}
+	sess := func(id string, status models.SessionStatus, conflict models.ConflictState) *models.AgentSession {
+		return &models.AgentSession{ID: id, ProjectID: "p1", Branch: "feature/" + id, Status: status, ConflictState: conflict,
+			ConflictFiles: `["a.go","b.go","c.go","d.go"]`}
+	}
+
+	msgs, seen := sessionAlerts(nil, []*models.AgentSession{
+		sess("a", models.SessionStatusActive, models.ConflictStateNone),
+		sess("b", models.SessionStatusCompleted, models.ConflictStateNone),
+	}, names)
+	assert.Empty(t, msgs, "the first check only records")
+
+	msgs, seen = sessionAlerts(seen, []*models.AgentSession{
+		sess("a", models.SessionStatusCompleted, models.ConflictStateNone),
+		sess("b", models.SessionStatusCompleted, models.ConflictStateNone),
+		sess("c", models.SessionStatusActive, models.ConflictStateMergeConflict),
+	}, names)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, PushMessage{Title: "Session completed", Body: "api: feature/a", URL: "/sessions/a", Tag: "session-a"}, msgs[0])
+	assert.Equal(t, "Merge conflict", msgs[1].Title)
+	assert.Equal(t, "api: feature/c — a.go, b.go, c.go and 1 more", msgs[1].Body)
+
+	msgs, _ = sessionAlerts(seen, []*models.AgentSession{
+		sess("a", models.SessionStatusCompleted, models.ConflictStateNone),
+		sess("c", models.SessionStatusActive, models.ConflictStateMergeConflict),
+	}, names)
+	assert.Empty(t, msgs, "nothing changed")
+}
+
+func TestBroadcast(t *testing.T) {
+	ctx := context.Background()
+	keys, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	n, err := NewPushNotifier(PushConfig{Keys: keys, Subject: "mailto:pm@example.com"})
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	s := store.NewMemoryStore()
+	for _, path := range []string{"/ok", "/gone"} {
+		uaKey, err := ecdh.P256().GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		require.NoError(t, s.SavePushSubscription(ctx, &models.PushSubscription{
+			Endpoint: srv.URL + path, P256dh: b64.EncodeToString(uaKey.PublicKey().Bytes()), Auth: b64.EncodeToString(make([]byte, 16)),
+		}))
+	}
+
+	sent, err := Broadcast(ctx, s, n, PushMessage{Title: "Session completed"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	subs, err := s.ListPushSubscriptions(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 1, "gone subscriptions are deleted")
+	assert.Equal(t, srv.URL+"/ok", subs[0].Endpoint)
+}
@@ -0,0 +1,307 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// Kinds of issue activity delivered to watchers.
+const (
+	IssueEventStatus  = "status_changed"
+	IssueEventComment = "comment"
+	IssueEventReview  = "review"
+)
+
+// WebhookSignatureHeader carries the HMAC-SHA256 of a webhook body, keyed by
+// notify.webhook.secret, as "sha256=<hex>".
+const WebhookSignatureHeader = "X-PM-Signature-256"
+
+// IssueActivity is one change to a watched issue. Webhook watchers receive
+// it as the JSON body of a POST.
+type IssueActivity struct {
+	Event      string               `json:"event"`
+	IssueID    string               `json:"issue_id"`
+	IssueTitle string               `json:"issue_title"`
+	Project    string               `json:"project"`
+	Status     models.IssueStatus   `json:"status"`
+	FromStatus models.IssueStatus   `json:"from_status,omitempty"` // status_changed
+	Verdict    models.ReviewVerdict `json:"verdict,omitempty"`     // review
+	Summary    string               `json:"summary,omitempty"`     // review
+	Author     string               `json:"author,omitempty"`      // comment
+	Comment    string               `json:"comment,omitempty"`     // comment
+	At         time.Time            `json:"at"`
+}
+
+// subject is a one-line description of the activity.
+func (a IssueActivity) subject() string {
+	switch a.Event {
+	case IssueEventStatus:
+		return fmt.Sprintf("%s: %s -> %s", a.IssueTitle, a.FromStatus, a.Status)
+	case IssueEventReview:
+		return fmt.Sprintf("%s: review %s", a.IssueTitle, a.Verdict)
+	case IssueEventComment:
+		if a.Author != "" {
+			return fmt.Sprintf("%s: comment from %s", a.IssueTitle, a.Author)
+		}
+		return a.IssueTitle + ": new comment"
+	}
+	return a.IssueTitle
+}
+
+// message renders the activity as an email.
+func (a IssueActivity) message() Message {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", a.subject())
+	if a.Project != "" {
+		fmt.Fprintf(&b, "Project: %s\n", a.Project)
+	}
+	fmt.Fprintf(&b, "Issue:   %s (%s)\n", a.IssueID, a.Status)
+	if a.Summary != "" {
+		fmt.Fprintf(&b, "\n%s\n", a.Summary)
+	}
+	if a.Comment != "" {
+		fmt.Fprintf(&b, "\n%s\n", a.Comment)
+	}
+	b.WriteString("\nYou are receiving this because you watch the issue. Stop with `pm issue unwatch`.\n")
+	return Message{Subject: "[pm] " + a.subject(), Text: b.String()}
+}
+
+// IssueWatchStore is the subset of store.Store the issue notifier reads.
+type IssueWatchStore interface {
+	ListIssueWatchers(ctx context.Context, issueID string) ([]*models.IssueWatcher, error)
+	GetIssue(ctx context.Context, id string) (*models.Issue, error)
+	GetProject(ctx context.Context, id string) (*models.Project, error)
+	ListIssueReviews(ctx context.Context, issueID string) ([]*models.IssueReview, error)
+	ListIssueComments(ctx context.Context, issueID string) ([]*models.IssueComment, error)
+}
+
+// issueState is what the notifier remembers of a watched issue between checks.
+type issueState struct {
+	status models.IssueStatus
+	seen   map[string]bool // IDs of the reviews and comments already delivered
+}
+
+// IssueNotifier delivers status changes, comments and review verdicts of
+// watched issues to their watchers only. Like SessionWatcher it compares the
+// issues on every Check with what it saw the time before, so it notices
+// changes made by any pm process.
+type IssueNotifier struct {
+	store    IssueWatchStore
+	email    *EmailConfig // nil: email watchers are skipped
+	client   *http.Client
+	sendMail sendMailFunc // overrides smtp.SendMail in tests
+	secret   string       // signs webhook bodies when set
+	seen     map[string]issueState
+}
+
+// NewIssueNotifier returns a notifier that emails watchers through email, or
+// skips them when email is nil, and POSTs to webhook watchers.
+func NewIssueNotifier(s IssueWatchStore, email *EmailConfig) *IssueNotifier {
+	return &IssueNotifier{
+		store:  s,
+		email:  email,
+		client: &http.Client{Timeout: 10 * time.Second},
+		seen:   make(map[string]issueState),
+	}
+}
+
+// SetWebhookSecret makes the notifier sign webhook bodies with secret in the
+// WebhookSignatureHeader header, so receivers can tell them from forgeries.
+func (n *IssueNotifier) SetWebhookSecret(secret string) {
+	n.secret = secret
+}
+
+// Check delivers the activity on watched issues since the last check. An
+// issue's first check, after it gains its first watcher or the notifier
+// starts, only records its current state.
+func (n *IssueNotifier) Check(ctx context.Context) error {
+	watchers, err := n.store.ListIssueWatchers(ctx, "")
+	if err != nil {
+		return fmt.Errorf("list issue watchers: %w", err)
+	}
+	byIssue := make(map[string][]*models.IssueWatcher)
+	for _, w := range watchers {
+		byIssue[w.IssueID] = append(byIssue[w.IssueID], w)
+	}
+
+	next := make(map[string]issueState, len(byIssue))
+	var errs []error
+	for issueID, ws := range byIssue {
+		activity, state, err := n.issueActivity(ctx, issueID)
+		if err != nil {
+			errs = append(errs, err)
+			if prev, ok := n.seen[issueID]; ok {
+				next[issueID] = prev
+			}
+			continue
+		}
+		next[issueID] = state
+		for _, a := range activity {
+			for _, w := range ws {
+				if err := n.deliver(ctx, w, a); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	n.seen = next
+	return errors.Join(errs...)
+}
+
+// issueActivity returns the activity on an issue since the last check, and
+// the state to compare the next check with.
+func (n *IssueNotifier) issueActivity(ctx context.Context, issueID string) ([]IssueActivity, issueState, error) {
+	issue, err := n.store.GetIssue(ctx, issueID)
+	if err != nil {
+		return nil, issueState{}, fmt.Errorf("get issue: %w", err)
+	}
+	reviews, err := n.store.ListIssueReviews(ctx, issueID)
+	if err != nil {
+		return nil, issueState{}, fmt.Errorf("list issue reviews: %w", err)
+	}
+	comments, err := n.store.ListIssueComments(ctx, issueID)
+	if err != nil {
+		return nil, issueState{}, fmt.Errorf("list issue comments: %w", err)
+	}
+
+	cur := issueState{status: issue.Status, seen: make(map[string]bool, len(reviews)+len(comments))}
+	for _, r := range reviews {
+		cur.seen[r.ID] = true
+	}
+	for _, c := range comments {
+		cur.seen[c.ID] = true
+	}
+	prev, known := n.seen[issueID]
+	if !known {
+		return nil, cur, nil
+	}
+
+	base := IssueActivity{IssueID: issue.ID, IssueTitle: issue.Title, Status: issue.Status}
+	if p, err := n.store.GetProject(ctx, issue.ProjectID); err == nil {
+		base.Project = p.Name
+	}
+	var activity []IssueActivity
+	if issue.Status != prev.status {
+		a := base
+		a.Event, a.FromStatus, a.At = IssueEventStatus, prev.status, issue.UpdatedAt
+		activity = append(activity, a)
+	}
+	// Reviews are listed newest first; deliver them oldest first.
+	for i := len(reviews) - 1; i >= 0; i-- {
+		r := reviews[i]
+		if prev.seen[r.ID] {
+			continue
+		}
+		a := base
+		a.Event, a.Verdict, a.Summary, a.At = IssueEventReview, r.Verdict, r.Summary, r.ReviewedAt
+		activity = append(activity, a)
+	}
+	for _, c := range comments {
+		if prev.seen[c.ID] {
+			continue
+		}
+		a := base
+		a.Event, a.Author, a.Comment, a.At = IssueEventComment, c.Author, c.Body, c.CreatedAt
+		activity = append(activity, a)
+	}
+	return activity, cur, nil
+}
+
+// deliver sends a to one watcher. Watchers are not told about their own
+// comments.
+func (n *IssueNotifier) deliver(ctx context.Context, w *models.IssueWatcher, a IssueActivity) error {
+	switch w.Kind {
+	case models.WatcherWebhook:
+		return n.post(ctx, w.Target, a)
+	case models.WatcherEmail:
+		if n.email == nil || (a.Event == IssueEventComment && strings.EqualFold(a.Author, w.Target)) {
+			return nil
+		}
+		cfg := *n.email
+		cfg.To = []string{w.Target}
+		en, err := NewEmailNotifier(cfg)
+		if err != nil {
+			return fmt.Errorf("email %s: %w", w.Target, err)
+		}
+		if n.sendMail != nil {
+			en.sendMail = n.sendMail
+		}
+		return en.Send(a.message())
+	}
+	return nil
+}
+
+// post sends a to a webhook as JSON.
+func (n *IssueNotifier) post(ctx context.Context, url string, a IssueActivity) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", url, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pm")
+	if n.secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(n.secret, body))
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s: %s", url, resp.Status)
+	}
+	return nil
+}
+
+// SignWebhook returns the WebhookSignatureHeader value for body.
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CheckWebhookHost refuses a webhook URL whose host is, or resolves to, a
+// loopback, link-local, private or unspecified address. Project-scoped
+// callers are held to it so their webhooks cannot probe the network pm runs
+// in.
+func CheckWebhookHost(ctx context.Context, target string) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL %q", target)
+	}
+	host := u.Hostname()
+	addrs := []netip.Addr{}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, addr)
+	} else {
+		addrs, err = net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return fmt.Errorf("webhook host %s: %w", host, err)
+		}
+	}
+	for _, addr := range addrs {
+		addr = addr.Unmap()
+		if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsUnspecified() {
+			return fmt.Errorf("webhook host %s is a local or private address", host)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+func TestIssueNotifier_Check(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	watched := &models.Issue{ProjectID: p.ID, Title: "Fix login"}
+	quiet := &models.Issue{ProjectID: p.ID, Title: "Unwatched"}
+	require.NoError(t, s.CreateIssue(ctx, watched))
+	require.NoError(t, s.CreateIssue(ctx, quiet))
+
+	var mu sync.Mutex
+	var posted []IssueActivity
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, SignWebhook("s3cret", body), r.Header.Get(WebhookSignatureHeader))
+		var a IssueActivity
+		assert.NoError(t, json.Unmarshal(body, &a))
+		mu.Lock()
+		posted = append(posted, a)
+		mu.Unlock()
+	}))
+	defer hook.Close()
+
+	_, err := s.AddIssueWatcher(ctx, &models.IssueWatcher{IssueID: watched.ID, Kind: models.WatcherWebhook, Target: hook.URL})
+	require.NoError(t, err)
+	_, err = s.AddIssueWatcher(ctx, &models.IssueWatcher{IssueID: watched.ID, Kind: models.WatcherEmail, Target: "dev@example.com"})
+	require.NoError(t, err)
+
+	n := NewIssueNotifier(s, &EmailConfig{Host: "smtp.example.com", From: "pm@example.com"})
+	n.SetWebhookSecret("s3cret")
+	var mailed [][]string
+	n.sendMail = func(_ string, _ smtp.Auth, _ string, to []string, _ []byte) error {
+		mailed = append(mailed, to)
+		return nil
+	}
+	require.NoError(t, n.Check(ctx))
+	assert.Empty(t, posted, "the first check only records")
+
+	watched.Status = models.IssueStatusInProgress
+	require.NoError(t, s.UpdateIssue(ctx, watched))
+	quiet.Status = models.IssueStatusDone
+	require.NoError(t, s.UpdateIssue(ctx, quiet))
+	require.NoError(t, s.CreateIssueReview(ctx, &models.IssueReview{IssueID: watched.ID, Verdict: models.ReviewVerdictFail, Summary: "tests missing"}))
+	require.NoError(t, s.CreateIssueComment(ctx, &models.IssueComment{IssueID: watched.ID, Author: "dev@example.com", Body: "on it"}))
+
+	require.NoError(t, n.Check(ctx))
+	require.Len(t, posted, 3, "only the watched issue's activity is delivered")
+	assert.Equal(t, IssueEventStatus, posted[0].Event)
+	assert.Equal(t, models.IssueStatusOpen, posted[0].FromStatus)
+	assert.Equal(t, models.IssueStatusInProgress, posted[0].Status)
+	assert.Equal(t, "api", posted[0].Project)
+	assert.Equal(t, IssueEventReview, posted[1].Event)
+	assert.Equal(t, models.ReviewVerdictFail, posted[1].Verdict)
+	assert.Equal(t, IssueEventComment, posted[2].Event)
+	assert.Equal(t, "on it", posted[2].Comment)
+	assert.Equal(t, [][]string{{"dev@example.com"}, {"dev@example.com"}}, mailed, "no email about the watcher's own comment")
+
+	require.NoError(t, n.Check(ctx))
+	assert.Len(t, posted, 3, "nothing new")
+}
+
+func TestIssueNotifier_WebhookError(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Fix login"}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer hook.Close()
+	_, err := s.AddIssueWatcher(ctx, &models.IssueWatcher{IssueID: issue.ID, Kind: models.WatcherWebhook, Target: hook.URL})
+	require.NoError(t, err)
+	_, err = s.AddIssueWatcher(ctx, &models.IssueWatcher{IssueID: issue.ID, Kind: models.WatcherEmail, Target: "dev@example.com"})
+	require.NoError(t, err)
+
+	n := NewIssueNotifier(s, nil)
+	require.NoError(t, n.Check(ctx))
+	require.NoError(t, s.CreateIssueComment(ctx, &models.IssueComment{IssueID: issue.ID, Body: "ping"}))
+	err = n.Check(ctx)
+	assert.ErrorContains(t, err, "502", "email watchers are skipped without SMTP settings")
+}
+
+func TestSignWebhook(t *testing.T) {
+	// echo -n '{}' | openssl dgst -sha256 -hmac key
+	assert.Equal(t, "sha256=a777724d943eb48dc69bca8a4a6d57a04db3f9ec7e1de4e581e860265bdf3032", SignWebhook("key", []byte("{}")))
+}
+
+func TestCheckWebhookHost(t *testing.T) {
+	ctx := context.Background()
+	for _, target := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://[::1]/hook",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://0.0.0.0/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+	} {
+		assert.Error(t, CheckWebhookHost(ctx, target), target)
+	}
+	assert.NoError(t, CheckWebhookHost(ctx, "https://93.184.216.34/hook"))
+}
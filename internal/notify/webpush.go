@@ -0,0 +1,279 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrSubscriptionGone is returned by PushNotifier.Send when the push service
+// reports that a subscription has expired or was unsubscribed (404/410); it
+// should be deleted.
+var ErrSubscriptionGone = errors.New("push subscription is gone")
+
+// maxPushPayload keeps an encrypted message inside a single 4096-byte record.
+const maxPushPayload = 3993
+
+// b64 is the unpadded base64url encoding Web Push uses for keys.
+var b64 = base64.RawURLEncoding
+
+// VAPIDKeys is the P-256 key pair that identifies the server to push services
+// (RFC 8292). Both keys are unpadded base64url: the public key as an
+// uncompressed point, the private key as its 32-byte scalar.
+type VAPIDKeys struct {
+	PublicKey  string `json:"public_key"`
+	PrivateKey string `json:"private_key"`
+}
+
+// GenerateVAPIDKeys creates a new VAPID key pair.
+func GenerateVAPIDKeys() (VAPIDKeys, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return VAPIDKeys{}, fmt.Errorf("generate vapid key: %w", err)
+	}
+	priv, err := key.Bytes()
+	if err != nil {
+		return VAPIDKeys{}, err
+	}
+	pub, err := key.PublicKey.Bytes()
+	if err != nil {
+		return VAPIDKeys{}, err
+	}
+	return VAPIDKeys{PublicKey: b64.EncodeToString(pub), PrivateKey: b64.EncodeToString(priv)}, nil
+}
+
+// PushConfig holds the settings for the Web Push notifier.
+type PushConfig struct {
+	Keys    VAPIDKeys
+	Subject string        // contact for push services: a mailto: or https: URL
+	TTL     time.Duration // how long push services keep undelivered messages
+}
+
+// Subscription is a browser's PushSubscription as serialized by
+// PushSubscription.toJSON().
+type Subscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// PushMessage is the JSON payload the dashboard's service worker shows as a
+// notification.
+type PushMessage struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url,omitempty"` // page opened when the notification is clicked
+	Tag   string `json:"tag,omitempty"` // a newer notification with the same tag replaces the older one
+}
+
+// PushNotifier sends encrypted Web Push messages (RFC 8291) signed with the
+// server's VAPID key.
+type PushNotifier struct {
+	cfg    PushConfig
+	key    *ecdsa.PrivateKey
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewPushNotifier validates cfg and returns a notifier.
+func NewPushNotifier(cfg PushConfig) (*PushNotifier, error) {
+	raw, err := b64.DecodeString(cfg.Keys.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode vapid private key: %w", err)
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse vapid private key: %w", err)
+	}
+	pub, err := key.PublicKey.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	if b64.EncodeToString(pub) != cfg.Keys.PublicKey {
+		return nil, errors.New("vapid public key does not match the private key")
+	}
+	if cfg.Subject == "" {
+		return nil, errors.New("vapid subject is not configured")
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	return &PushNotifier{cfg: cfg, key: key, client: &http.Client{Timeout: 30 * time.Second}, now: time.Now}, nil
+}
+
+// PublicKey returns the VAPID public key browsers subscribe with
+// (applicationServerKey).
+func (n *PushNotifier) PublicKey() string {
+	return n.cfg.Keys.PublicKey
+}
+
+// Send delivers msg to one subscription.
+func (n *PushNotifier) Send(ctx context.Context, sub Subscription, msg PushMessage) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxPushPayload {
+		return fmt.Errorf("push payload is %d bytes, the limit is %d", len(payload), maxPushPayload)
+	}
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil || endpoint.Scheme != "https" && endpoint.Scheme != "http" {
+		return fmt.Errorf("invalid push endpoint %q", sub.Endpoint)
+	}
+
+	body, err := encryptPayload(sub, payload)
+	if err != nil {
+		return err
+	}
+	token, err := n.vapidToken(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(n.cfg.TTL.Seconds())))
+	req.Header.Set("Urgency", "high")
+	req.Header.Set("Authorization", "vapid t="+token+", k="+n.cfg.Keys.PublicKey)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send push to %s: %w", endpoint.Host, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrSubscriptionGone
+	case resp.StatusCode >= 300:
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("push service %s returned %s: %s", endpoint.Host, resp.Status, bytes.TrimSpace(detail))
+	}
+	return nil
+}
+
+// vapidToken returns the ES256 JWT that authorizes a push to audience (the
+// push service's origin).
+func (n *PushNotifier) vapidToken(audience string) (string, error) {
+	header := b64.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	claims, err := json.Marshal(map[string]any{
+		"aud": audience,
+		"exp": n.now().Add(12 * time.Hour).Unix(),
+		"sub": n.cfg.Subject,
+	})
+	if err != nil {
+		return "", err
+	}
+	signingInput := header + "." + b64.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, n.key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("sign vapid token: %w", err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return signingInput + "." + b64.EncodeToString(sig), nil
+}
+
+// encryptPayload encrypts payload for sub with the aes128gcm content coding
+// (RFC 8188) and the Web Push key derivation (RFC 8291), as a single record.
+func encryptPayload(sub Subscription, payload []byte) ([]byte, error) {
+	uaRaw, err := b64.DecodeString(sub.Keys.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("decode subscription key: %w", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaRaw)
+	if err != nil {
+		return nil, fmt.Errorf("parse subscription key: %w", err)
+	}
+	authSecret, err := b64.DecodeString(sub.Keys.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("decode subscription auth secret: %w", err)
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+	shared, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	cek, nonce, err := deriveContentKeys(shared, authSecret, salt, uaRaw, asPublic)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	const recordSize = 4096
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+	// 0x02 marks the last (and only) record.
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// deriveContentKeys derives the content encryption key and nonce from the
+// ECDH secret shared by the server's ephemeral key (asPublic) and the
+// browser's key (uaPublic).
+func deriveContentKeys(shared, authSecret, salt, uaPublic, asPublic []byte) (cek, nonce []byte, err error) {
+	prkKey, err := hkdf.Extract(sha256.New, shared, authSecret)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyInfo := "WebPush: info\x00" + string(uaPublic) + string(asPublic)
+	ikm, err := hkdf.Expand(sha256.New, prkKey, keyInfo, 32)
+	if err != nil {
+		return nil, nil, err
+	}
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cek, err = hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16); err != nil {
+		return nil, nil, err
+	}
+	if nonce, err = hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12); err != nil {
+		return nil, nil, err
+	}
+	return cek, nonce, nil
+}
@@ -0,0 +1,166 @@
+package notify
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPushNotifier_Validation(t *testing.T) {
+	keys, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+
+	_, err = NewPushNotifier(PushConfig{Keys: keys})
+	assert.ErrorContains(t, err, "subject")
+
+	other, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	_, err = NewPushNotifier(PushConfig{Keys: VAPIDKeys{PublicKey: other.PublicKey, PrivateKey: keys.PrivateKey}, Subject: "mailto:pm@example.com"})
+	assert.ErrorContains(t, err, "does not match")
+
+	_, err = NewPushNotifier(PushConfig{Keys: VAPIDKeys{PrivateKey: "!"}, Subject: "mailto:pm@example.com"})
+	assert.Error(t, err)
+
+	n, err := NewPushNotifier(PushConfig{Keys: keys, Subject: "mailto:pm@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, keys.PublicKey, n.PublicKey())
+	assert.Equal(t, 24*time.Hour, n.cfg.TTL)
+}
+
+func TestPushNotifier_Send(t *testing.T) {
+	keys, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	n, err := NewPushNotifier(PushConfig{Keys: keys, Subject: "mailto:pm@example.com", TTL: time.Hour})
+	require.NoError(t, err)
+
+	// The browser's side of the subscription.
+	uaKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	authSecret := make([]byte, 16)
+	_, err = rand.Read(authSecret)
+	require.NoError(t, err)
+
+	var req *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	var sub Subscription
+	sub.Endpoint = srv.URL + "/push/abc"
+	sub.Keys.P256dh = b64.EncodeToString(uaKey.PublicKey().Bytes())
+	sub.Keys.Auth = b64.EncodeToString(authSecret)
+
+	msg := PushMessage{Title: "Session completed", Body: "api: feature/login", URL: "/sessions/1", Tag: "session-1"}
+	require.NoError(t, n.Send(context.Background(), sub, msg))
+
+	assert.Equal(t, "aes128gcm", req.Header.Get("Content-Encoding"))
+	assert.Equal(t, "3600", req.Header.Get("TTL"))
+	auth := req.Header.Get("Authorization")
+	require.True(t, strings.HasPrefix(auth, "vapid t="), auth)
+	token, pub, ok := strings.Cut(strings.TrimPrefix(auth, "vapid t="), ", k=")
+	require.True(t, ok)
+	assert.Equal(t, keys.PublicKey, pub)
+	verifyVAPIDToken(t, token, pub, srv.URL)
+
+	// Decrypt as the browser would.
+	salt := body[:16]
+	assert.Equal(t, uint32(4096), binary.BigEndian.Uint32(body[16:20]))
+	idLen := int(body[20])
+	asPublic := body[21 : 21+idLen]
+	asKey, err := ecdh.P256().NewPublicKey(asPublic)
+	require.NoError(t, err)
+	shared, err := uaKey.ECDH(asKey)
+	require.NoError(t, err)
+	cek, nonce, err := deriveContentKeys(shared, authSecret, salt, uaKey.PublicKey().Bytes(), asPublic)
+	require.NoError(t, err)
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	plain, err := gcm.Open(nil, nonce, body[21+idLen:], nil)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x02), plain[len(plain)-1], "last record delimiter")
+
+	var got PushMessage
+	require.NoError(t, json.Unmarshal(plain[:len(plain)-1], &got))
+	assert.Equal(t, msg, got)
+}
+
+func TestPushNotifier_SendGone(t *testing.T) {
+	keys, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	n, err := NewPushNotifier(PushConfig{Keys: keys, Subject: "mailto:pm@example.com"})
+	require.NoError(t, err)
+	uaKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	status := http.StatusGone
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	var sub Subscription
+	sub.Endpoint = srv.URL
+	sub.Keys.P256dh = b64.EncodeToString(uaKey.PublicKey().Bytes())
+	sub.Keys.Auth = b64.EncodeToString(make([]byte, 16))
+
+	assert.ErrorIs(t, n.Send(context.Background(), sub, PushMessage{Title: "x"}), ErrSubscriptionGone)
+	status = http.StatusBadRequest
+	err = n.Send(context.Background(), sub, PushMessage{Title: "x"})
+	assert.ErrorContains(t, err, "400")
+	assert.NotErrorIs(t, err, ErrSubscriptionGone)
+
+	sub.Keys.P256dh = "bad"
+	assert.Error(t, n.Send(context.Background(), sub, PushMessage{Title: "x"}))
+}
+
+func verifyVAPIDToken(t *testing.T, token, publicKey, audience string) {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	claimsJSON, err := b64.DecodeString(parts[1])
+	require.NoError(t, err)
+	var claims struct {
+		Aud string `json:"aud"`
+		Exp int64  `json:"exp"`
+		Sub string `json:"sub"`
+	}
+	require.NoError(t, json.Unmarshal(claimsJSON, &claims))
+	assert.Equal(t, audience, claims.Aud)
+	assert.Equal(t, "mailto:pm@example.com", claims.Sub)
+	assert.Greater(t, claims.Exp, time.Now().Unix())
+
+	raw, err := b64.DecodeString(publicKey)
+	require.NoError(t, err)
+	pub, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), raw)
+	require.NoError(t, err)
+	sig, err := b64.DecodeString(parts[2])
+	require.NoError(t, err)
+	require.Len(t, sig, 64)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	assert.True(t, ecdsa.Verify(pub, digest[:], r, s), "signed with the VAPID key")
+}
@@ -1,9 +1,11 @@
 package output
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"strings"
 
 	"github.com/fatih/color"
@@ -11,19 +13,26 @@ import (
 	"github.com/olekukonko/tablewriter/tw"
 )
 
-// UI provides colored output and respects verbose/dry-run modes.
+// UI provides colored output and respects verbose/dry-run/quiet modes.
+//
+// Data (tables, JSON, values asked for) goes to Out; warnings, errors and
+// prompts go to ErrOut. Info, Success and VerboseLog are decorative: they go
+// to Out but Quiet drops them, leaving only data there.
 type UI struct {
 	Verbose bool
 	DryRun  bool
+	Quiet   bool
 	Out     io.Writer
 	ErrOut  io.Writer
+	In      io.Reader
 }
 
-// New creates a UI with default stdout/stderr writers.
+// New creates a UI with default stdin/stdout/stderr streams.
 func New() *UI {
 	return &UI{
 		Out:    os.Stdout,
 		ErrOut: os.Stderr,
+		In:     os.Stdin,
 	}
 }
 
@@ -37,6 +46,9 @@ var (
 	green         = color.New(color.FgHiGreen).SprintFunc()
 	yellow        = color.New(color.FgHiYellow).SprintFunc()
 	red           = color.New(color.FgHiRed).SprintFunc()
+	magenta       = color.New(color.FgHiMagenta).SprintFunc()
+	highlight     = color.New(color.ReverseVideo).SprintFunc()
+	ansiEscape    = regexp.MustCompile("\x1b\\[[0-9;]*m")
 )
 
 // Cyan returns a cyan-colored string.
@@ -51,6 +63,12 @@ func Yellow(s string) string { return yellow(s) }
 // Red returns a red-colored string.
 func Red(s string) string { return red(s) }
 
+// Magenta returns a magenta-colored string.
+func Magenta(s string) string { return magenta(s) }
+
+// Highlight returns s in reverse video, dropping its own colors.
+func Highlight(s string) string { return highlight(ansiEscape.ReplaceAllString(s, "")) }
+
 // StatusColor returns the string colored by issue status.
 func StatusColor(status string) string {
 	switch strings.ToLower(status) {
@@ -58,6 +76,8 @@ func StatusColor(status string) string {
 		return green(status)
 	case "in_progress":
 		return yellow(status)
+	case "in_review":
+		return magenta(status)
 	case "done", "completed":
 		return cyan(status)
 	case "closed":
@@ -81,13 +101,27 @@ func HealthColor(score int) string {
 }
 
 func (u *UI) Info(format string, a ...any) {
+	if u.Quiet {
+		return
+	}
 	fmt.Fprintf(u.Out, "%s %s\n", infoPrefix, fmt.Sprintf(format, a...))
 }
 
 func (u *UI) Success(format string, a ...any) {
+	if u.Quiet {
+		return
+	}
 	fmt.Fprintf(u.Out, "%s %s\n", successPrefix, fmt.Sprintf(format, a...))
 }
 
+// Result prints the ID or value a command created, for scripts: with Quiet it
+// is all the command prints on Out, otherwise its Success message shows it.
+func (u *UI) Result(v string) {
+	if u.Quiet {
+		fmt.Fprintln(u.Out, v)
+	}
+}
+
 func (u *UI) Warning(format string, a ...any) {
 	fmt.Fprintf(u.ErrOut, "%s %s\n", warningPrefix, fmt.Sprintf(format, a...))
 }
@@ -97,7 +131,7 @@ func (u *UI) Error(format string, a ...any) {
 }
 
 func (u *UI) VerboseLog(format string, a ...any) {
-	if u.Verbose {
+	if u.Verbose && !u.Quiet {
 		fmt.Fprintf(u.Out, "%s %s\n", verbosePrefix, fmt.Sprintf(format, a...))
 	}
 }
@@ -108,6 +142,37 @@ func (u *UI) DryRunMsg(format string, a ...any) {
 	}
 }
 
+// Interactive reports whether In is a terminal someone can answer prompts on.
+func (u *UI) Interactive() bool {
+	f, ok := u.In.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Confirm asks a yes/no question on ErrOut and returns true only for an
+// explicit "y" or "yes". A nil In or read error (e.g. non-interactive stdin)
+// counts as "no".
+func (u *UI) Confirm(format string, a ...any) bool {
+	fmt.Fprintf(u.ErrOut, "%s %s [y/N]: ", warningPrefix, fmt.Sprintf(format, a...))
+	if u.In == nil {
+		_, _ = fmt.Fprintln(u.ErrOut)
+		return false
+	}
+	line, err := bufio.NewReader(u.In).ReadString('\n')
+	if err != nil && line == "" {
+		_, _ = fmt.Fprintln(u.ErrOut)
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
 // Table creates a new tablewriter configured with consistent styling.
 func (u *UI) Table(headers []string) *tablewriter.Table {
 	table := tablewriter.NewTable(u.Out,
@@ -2,6 +2,7 @@ package output
 
 import (
 	"bytes"
+	"os"
 	"strings"
 	"testing"
 
@@ -53,6 +54,21 @@ func TestVerboseLog_Disabled(t *testing.T) {
 	assert.Empty(t, out.String())
 }
 
+func TestQuiet(t *testing.T) {
+	u, out, errOut := newTestUI()
+	u.Quiet = true
+	u.Verbose = true
+	u.Info("hello")
+	u.Success("done")
+	u.VerboseLog("detail")
+	assert.Empty(t, out.String())
+
+	u.Warning("careful")
+	u.Error("failed")
+	assert.Contains(t, errOut.String(), "careful")
+	assert.Contains(t, errOut.String(), "failed")
+}
+
 func TestDryRunMsg_Enabled(t *testing.T) {
 	u, _, errOut := newTestUI()
 	u.DryRun = true
@@ -76,6 +92,11 @@ func TestColorHelpers(t *testing.T) {
 	assert.NotEmpty(t, Red("test"))
 }
 
+func TestHighlight(t *testing.T) {
+	assert.Contains(t, Highlight("\x1b[91mdirty\x1b[0m"), "dirty")
+	assert.NotContains(t, Highlight("\x1b[91mdirty\x1b[0m"), "\x1b[91m")
+}
+
 func TestStatusColor(t *testing.T) {
 	assert.NotEmpty(t, StatusColor("open"))
 	assert.NotEmpty(t, StatusColor("in_progress"))
@@ -106,3 +127,36 @@ func TestTable(t *testing.T) {
 	assert.True(t, strings.Contains(result, "wt") || strings.Contains(result, "WT"),
 		"table output should contain project names")
 }
+
+func TestConfirm(t *testing.T) {
+	for input, want := range map[string]bool{
+		"y\n":   true,
+		"YES\n": true,
+		"n\n":   false,
+		"\n":    false,
+		"":      false,
+	} {
+		u, out, errOut := newTestUI()
+		u.In = strings.NewReader(input)
+		assert.Equal(t, want, u.Confirm("Proceed with %s?", "close"), "input %q", input)
+		assert.Contains(t, errOut.String(), "Proceed with close? [y/N]")
+		assert.Empty(t, out.String(), "prompts stay off stdout")
+	}
+}
+
+func TestInteractive(t *testing.T) {
+	u, _, _ := newTestUI()
+	assert.False(t, u.Interactive(), "no input")
+	u.In = strings.NewReader("y\n")
+	assert.False(t, u.Interactive(), "piped input")
+	f, err := os.CreateTemp(t.TempDir(), "stdin")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+	u.In = f
+	assert.False(t, u.Interactive(), "redirected file")
+}
+
+func TestConfirm_NilInput(t *testing.T) {
+	u, _, _ := newTestUI()
+	assert.False(t, u.Confirm("Proceed?"))
+}
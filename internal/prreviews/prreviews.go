@@ -0,0 +1,222 @@
+// Package prreviews brings human reviews of the pull requests opened from pm
+// sessions back into pm: each review, with its inline comments, becomes a
+// comment on the session's issue, and a review requesting changes can open a
+// follow-up issue for the agent loop to pick up.
+package prreviews
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// Store is the subset of store.Store needed to import reviews.
+type Store interface {
+	ListAgentSessionsFiltered(ctx context.Context, filter store.SessionListFilter) ([]*models.AgentSession, error)
+	GetIssue(ctx context.Context, id string) (*models.Issue, error)
+	CreateIssue(ctx context.Context, issue *models.Issue) error
+	CreateIssueComment(ctx context.Context, c *models.IssueComment) error
+	CreateIssueRelation(ctx context.Context, rel *models.IssueRelation) error
+	RecordPRReviewImport(ctx context.Context, r *models.PRReviewImport) (bool, error)
+	UpdatePRReviewImport(ctx context.Context, r *models.PRReviewImport) error
+	DeletePRReviewImport(ctx context.Context, id string) error
+	ListPRReviewImports(ctx context.Context, projectID, issueID string) ([]*models.PRReviewImport, error)
+}
+
+// GitHub lists a repo's open pull requests and their reviews.
+type GitHub interface {
+	OpenPRs(owner, repo string) ([]git.PullRequest, error)
+	PRReviews(owner, repo string, number int) ([]git.PRReview, error)
+}
+
+// Options controls a sync.
+type Options struct {
+	// FollowUps opens a follow-up issue, related to the session's issue, for
+	// each review requesting changes.
+	FollowUps bool
+	// DryRun changes nothing and returns the imports that would be made.
+	DryRun bool
+}
+
+// Sync imports the new reviews of the project's open pull requests whose
+// head branch is a session's branch into the session's issue. Reviews by
+// bots and by the pull request's author (replies from the agent) are
+// skipped, as are dismissed reviews and reviews with nothing to say. A
+// review is imported into an issue once, so syncing again is safe.
+func Sync(ctx context.Context, s Store, gh GitHub, project *models.Project, opts Options) ([]*models.PRReviewImport, error) {
+	owner, repo, err := git.ExtractOwnerRepo(project.RepoURL)
+	if err != nil {
+		return nil, fmt.Errorf("project %s has no GitHub repo: %w", project.Name, err)
+	}
+
+	sessions, err := s.ListAgentSessionsFiltered(ctx, store.SessionListFilter{ProjectID: project.ID})
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	issuesByBranch := make(map[string][]string)
+	for _, sess := range sessions {
+		if sess.IssueID == "" || sess.Branch == "" || slices.Contains(issuesByBranch[sess.Branch], sess.IssueID) {
+			continue
+		}
+		issuesByBranch[sess.Branch] = append(issuesByBranch[sess.Branch], sess.IssueID)
+	}
+	if len(issuesByBranch) == 0 {
+		return nil, nil
+	}
+
+	prs, err := gh.OpenPRs(owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("list open pull requests: %w", err)
+	}
+	existing, err := s.ListPRReviewImports(ctx, project.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	done := make(map[string]bool, len(existing))
+	for _, r := range existing {
+		done[fmt.Sprintf("%s %d", r.IssueID, r.ReviewID)] = true
+	}
+
+	var imports []*models.PRReviewImport
+	for _, pr := range prs {
+		issueIDs := issuesByBranch[pr.Branch]
+		if len(issueIDs) == 0 {
+			continue
+		}
+		reviews, err := gh.PRReviews(owner, repo, pr.Number)
+		if err != nil {
+			return imports, fmt.Errorf("list reviews of pull request #%d: %w", pr.Number, err)
+		}
+		for _, review := range reviews {
+			if !wanted(pr, review) {
+				continue
+			}
+			for _, issueID := range issueIDs {
+				if done[fmt.Sprintf("%s %d", issueID, review.ID)] {
+					continue
+				}
+				issue, err := s.GetIssue(ctx, issueID)
+				if err != nil {
+					return imports, err
+				}
+				imp := &models.PRReviewImport{
+					IssueID:   issue.ID,
+					ProjectID: project.ID,
+					PRNumber:  pr.Number,
+					ReviewID:  review.ID,
+					Reviewer:  review.Author,
+					State:     review.State,
+					URL:       review.URL,
+				}
+				if !opts.DryRun {
+					claimed, err := apply(ctx, s, issue, pr, review, imp, opts.FollowUps)
+					if err != nil {
+						return imports, err
+					}
+					if !claimed {
+						continue // a concurrent sync imported it
+					}
+				}
+				imports = append(imports, imp)
+			}
+		}
+	}
+	return imports, nil
+}
+
+// wanted reports whether a review is a person's and has something to say.
+func wanted(pr git.PullRequest, review git.PRReview) bool {
+	if review.Bot || (pr.Author.Login != "" && review.Author == pr.Author.Login) {
+		return false
+	}
+	switch review.State {
+	case models.PRReviewApproved, models.PRReviewChangesRequested:
+		return true
+	case models.PRReviewCommented:
+		return strings.TrimSpace(review.Body) != "" || len(review.Comments) > 0
+	default: // DISMISSED, PENDING
+		return false
+	}
+}
+
+// apply records the import, then comments the review on the issue and opens
+// its follow-up issue when asked. Recording first claims the review: it
+// reports false, doing nothing, when another sync already claimed it. When
+// the comment fails the claim is released so the next sync retries.
+func apply(ctx context.Context, s Store, issue *models.Issue, pr git.PullRequest, review git.PRReview, imp *models.PRReviewImport, followUps bool) (bool, error) {
+	claimed, err := s.RecordPRReviewImport(ctx, imp)
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	c := &models.IssueComment{IssueID: issue.ID, Author: review.Author + " (GitHub)", Body: Format(pr, review)}
+	if err := s.CreateIssueComment(ctx, c); err != nil {
+		if derr := s.DeletePRReviewImport(ctx, imp.ID); derr != nil {
+			err = errors.Join(err, derr)
+		}
+		return true, fmt.Errorf("comment review on issue %s: %w", issue.ID, err)
+	}
+	imp.CommentID = c.ID
+
+	if followUps && review.State == models.PRReviewChangesRequested {
+		followUp := &models.Issue{
+			ProjectID:   issue.ProjectID,
+			Title:       fmt.Sprintf("Address review of PR #%d: %s", pr.Number, issue.Title),
+			Description: fmt.Sprintf("%s requested changes on %s.", review.Author, pr.URL),
+			Body:        Format(pr, review),
+			Status:      models.IssueStatusOpen,
+			Priority:    issue.Priority,
+			Type:        issue.Type,
+		}
+		if err := s.CreateIssue(ctx, followUp); err != nil {
+			return true, errors.Join(fmt.Errorf("create follow-up issue: %w", err), s.UpdatePRReviewImport(ctx, imp))
+		}
+		imp.FollowUpIssueID = followUp.ID
+		rel := &models.IssueRelation{SourceIssueID: followUp.ID, TargetIssueID: issue.ID, Type: models.RelationRelatesTo}
+		if err := s.CreateIssueRelation(ctx, rel); err != nil {
+			return true, errors.Join(err, s.UpdatePRReviewImport(ctx, imp))
+		}
+	}
+
+	return true, s.UpdatePRReviewImport(ctx, imp)
+}
+
+// Format renders a review and its inline comments as Markdown.
+func Format(pr git.PullRequest, review git.PRReview) string {
+	verb := "commented on"
+	switch review.State {
+	case models.PRReviewApproved:
+		verb = "approved"
+	case models.PRReviewChangesRequested:
+		verb = "requested changes on"
+	}
+	link := review.URL
+	if link == "" {
+		link = pr.URL
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**@%s** %s [PR #%d](%s)", review.Author, verb, pr.Number, link)
+	if body := strings.TrimSpace(review.Body); body != "" {
+		b.WriteString("\n\n" + body)
+	}
+	for _, c := range review.Comments {
+		where := c.Path
+		if c.Line > 0 {
+			where = fmt.Sprintf("%s:%d", c.Path, c.Line)
+		}
+		fmt.Fprintf(&b, "\n\n[`%s`](%s)\n\n%s", where, c.URL, quote(c.Body))
+	}
+	return b.String()
+}
+
+// quote renders text as a Markdown blockquote.
+func quote(text string) string {
+	return "> " + strings.ReplaceAll(strings.TrimSpace(text), "\n", "\n> ")
+}
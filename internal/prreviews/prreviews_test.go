@@ -0,0 +1,160 @@
+package prreviews
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+type fakeGitHub struct {
+	prs     []git.PullRequest
+	reviews map[int][]git.PRReview
+}
+
+func (f *fakeGitHub) OpenPRs(_, _ string) ([]git.PullRequest, error) { return f.prs, nil }
+
+func (f *fakeGitHub) PRReviews(_, _ string, number int) ([]git.PRReview, error) {
+	return f.reviews[number], nil
+}
+
+func newPR(number int, branch, author string) git.PullRequest {
+	pr := git.PullRequest{Number: number, Branch: branch, URL: "https://github.com/acme/api/pull/" + branch}
+	pr.Author.Login = author
+	return pr
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: "/code/api", RepoURL: "https://github.com/acme/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Rate limiting", Status: models.IssueStatusDone, Priority: models.IssuePriorityHigh, Type: models.IssueTypeFeature}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	require.NoError(t, s.CreateAgentSession(ctx, &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "feature/rate-limit", Status: models.SessionStatusCompleted}))
+
+	gh := &fakeGitHub{
+		prs: []git.PullRequest{newPR(7, "feature/rate-limit", "pm-bot"), newPR(8, "feature/other", "pm-bot")},
+		reviews: map[int][]git.PRReview{
+			7: {
+				{ID: 1, Author: "ada", State: models.PRReviewChangesRequested, Body: "Limits should be per token.", URL: "https://github.com/acme/api/pull/7#pullrequestreview-1",
+					Comments: []git.PRReviewComment{{ID: 10, ReviewID: 1, Path: "limit.go", Line: 12, Body: "Use a token bucket\nhere.", URL: "https://github.com/acme/api/pull/7#discussion_r10"}}},
+				{ID: 2, Author: "pm-bot", State: models.PRReviewCommented, Body: "Done."},
+				{ID: 3, Author: "dependabot[bot]", Bot: true, State: models.PRReviewCommented, Body: "Bump"},
+				{ID: 4, Author: "grace", State: models.PRReviewCommented},
+				{ID: 5, Author: "grace", State: "DISMISSED", Body: "Outdated"},
+				{ID: 6, Author: "grace", State: models.PRReviewApproved},
+			},
+			8: {{ID: 9, Author: "ada", State: models.PRReviewApproved}},
+		},
+	}
+
+	imports, err := Sync(ctx, s, gh, p, Options{FollowUps: true, DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, imports, 2)
+	comments, err := s.ListIssueComments(ctx, issue.ID)
+	require.NoError(t, err)
+	assert.Empty(t, comments, "dry run changes nothing")
+
+	imports, err = Sync(ctx, s, gh, p, Options{FollowUps: true})
+	require.NoError(t, err)
+	require.Len(t, imports, 2, "only people's reviews with something to say, on session branches")
+	assert.Equal(t, int64(1), imports[0].ReviewID)
+	assert.Equal(t, int64(6), imports[1].ReviewID)
+	assert.NotEmpty(t, imports[0].FollowUpIssueID)
+	assert.Empty(t, imports[1].FollowUpIssueID, "approvals need no follow-up")
+
+	comments, err = s.ListIssueComments(ctx, issue.ID)
+	require.NoError(t, err)
+	require.Len(t, comments, 2)
+	assert.Equal(t, "ada (GitHub)", comments[0].Author)
+	assert.Equal(t, "**@ada** requested changes on [PR #7](https://github.com/acme/api/pull/7#pullrequestreview-1)\n\n"+
+		"Limits should be per token.\n\n"+
+		"[`limit.go:12`](https://github.com/acme/api/pull/7#discussion_r10)\n\n"+
+		"> Use a token bucket\n> here.", comments[0].Body)
+	assert.Equal(t, imports[0].CommentID, comments[0].ID)
+	stored, err := s.ListPRReviewImports(ctx, "", issue.ID)
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	for _, r := range stored {
+		assert.NotEmpty(t, r.CommentID, "the import records its comment")
+	}
+
+	followUp, err := s.GetIssue(ctx, imports[0].FollowUpIssueID)
+	require.NoError(t, err)
+	assert.Equal(t, "Address review of PR #7: Rate limiting", followUp.Title)
+	assert.Equal(t, models.IssueStatusOpen, followUp.Status)
+	assert.Equal(t, models.IssuePriorityHigh, followUp.Priority)
+	assert.Equal(t, comments[0].Body, followUp.Body)
+	rels, err := s.ListIssueRelations(ctx, followUp.ID)
+	require.NoError(t, err)
+	require.Len(t, rels, 1)
+	assert.Equal(t, issue.ID, rels[0].TargetIssueID)
+
+	imports, err = Sync(ctx, s, gh, p, Options{FollowUps: true})
+	require.NoError(t, err)
+	assert.Empty(t, imports, "reviews are imported once")
+}
+
+// racingStore hides existing imports, as when another sync records them
+// after this one listed them, and can fail to comment.
+type racingStore struct {
+	*store.MemoryStore
+	commentErr error
+}
+
+func (racingStore) ListPRReviewImports(context.Context, string, string) ([]*models.PRReviewImport, error) {
+	return nil, nil
+}
+
+func (r racingStore) CreateIssueComment(ctx context.Context, c *models.IssueComment) error {
+	if r.commentErr != nil {
+		return r.commentErr
+	}
+	return r.MemoryStore.CreateIssueComment(ctx, c)
+}
+
+func TestSync_Claim(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: "/code/api", RepoURL: "https://github.com/acme/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Rate limiting", Status: models.IssueStatusDone, Priority: models.IssuePriorityHigh, Type: models.IssueTypeFeature}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	require.NoError(t, s.CreateAgentSession(ctx, &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "feature/rate-limit", Status: models.SessionStatusCompleted}))
+	gh := &fakeGitHub{
+		prs:     []git.PullRequest{newPR(7, "feature/rate-limit", "pm-bot")},
+		reviews: map[int][]git.PRReview{7: {{ID: 1, Author: "ada", State: models.PRReviewChangesRequested, Body: "Per token."}}},
+	}
+
+	_, err := Sync(ctx, racingStore{MemoryStore: s, commentErr: errors.New("disk full")}, gh, p, Options{FollowUps: true})
+	require.ErrorContains(t, err, "disk full")
+	stored, err := s.ListPRReviewImports(ctx, p.ID, "")
+	require.NoError(t, err)
+	assert.Empty(t, stored, "a failed comment releases the claim")
+
+	imports, err := Sync(ctx, s, gh, p, Options{FollowUps: true})
+	require.NoError(t, err)
+	require.Len(t, imports, 1)
+
+	imports, err = Sync(ctx, racingStore{MemoryStore: s}, gh, p, Options{FollowUps: true})
+	require.NoError(t, err)
+	assert.Empty(t, imports, "a review claimed by another sync is skipped")
+	comments, err := s.ListIssueComments(ctx, issue.ID)
+	require.NoError(t, err)
+	assert.Len(t, comments, 1)
+	issues, err := s.ListIssues(ctx, store.IssueListFilter{ProjectID: p.ID})
+	require.NoError(t, err)
+	assert.Len(t, issues, 2, "one follow-up")
+}
+
+func TestSync_NoGitHubRepo(t *testing.T) {
+	_, err := Sync(context.Background(), store.NewMemoryStore(), &fakeGitHub{}, &models.Project{Name: "local"}, Options{})
+	assert.ErrorContains(t, err, "has no GitHub repo")
+}
@@ -0,0 +1,253 @@
+// Package redact removes secrets and personal data from text before it is
+// sent to the LLM. Known credential formats are matched by pattern, other
+// high-entropy tokens by their Shannon entropy, and each project can allow
+// or deny further patterns.
+package redact
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// DefaultMinEntropy is the Shannon entropy (bits per character) above which
+// a long mixed-case alphanumeric token is treated as a secret.
+const DefaultMinEntropy = 4.0
+
+// minEntropyLength is the shortest token checked for entropy.
+const minEntropyLength = 20
+
+// Config is the redaction section of the config file.
+type Config struct {
+	Enabled    bool                     `mapstructure:"enabled"`
+	MinEntropy float64                  `mapstructure:"min_entropy"` // 0 disables entropy detection
+	Allow      []string                 `mapstructure:"allow"`       // regexes for matches to keep
+	Deny       []string                 `mapstructure:"deny"`        // extra regexes to always redact
+	Projects   map[string]ProjectConfig `mapstructure:"projects"`    // keyed by project name
+}
+
+// ProjectConfig adds to the global allow and deny lists for one project.
+type ProjectConfig struct {
+	Disabled bool     `mapstructure:"disabled"`
+	Allow    []string `mapstructure:"allow"`
+	Deny     []string `mapstructure:"deny"`
+}
+
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+	group   int // submatch to replace; 0 replaces the whole match
+}
+
+// builtinRules match well-known credential and personal data formats.
+var builtinRules = []rule{
+	{"private_key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), 0},
+	{"aws_access_key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), 0},
+	{"github_token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`), 0},
+	{"api_key", regexp.MustCompile(`\bsk-(?:ant-)?[A-Za-z0-9_-]{20,}`), 0},
+	{"slack_token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`), 0},
+	{"pm_token", regexp.MustCompile(`\bpm_[0-9a-f]{48}\b`), 0},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`), 0},
+	{"url_credentials", regexp.MustCompile(`://[^/\s:@]+:([^/\s@]+)@`), 1},
+	{"assignment", regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|secret|api[_-]?key|access[_-]?token|auth[_-]?token|client[_-]?secret)\b\s*[:=]\s*("[^"\n]*"|'[^'\n]*'|[^\s"']+)`), 1},
+	{"email", regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`), 0},
+}
+
+// entropyToken finds candidate tokens for entropy detection.
+var entropyToken = regexp.MustCompile(`[A-Za-z0-9+/=_-]{20,}`)
+
+// Policy holds compiled redaction settings for all projects. A nil Policy
+// redacts nothing.
+type Policy struct {
+	enabled    bool
+	minEntropy float64
+	allow      []*regexp.Regexp
+	deny       []*regexp.Regexp
+	projects   map[string]projectPolicy
+}
+
+type projectPolicy struct {
+	disabled bool
+	allow    []*regexp.Regexp
+	deny     []*regexp.Regexp
+}
+
+// NewPolicy compiles cfg, reporting the first invalid pattern.
+func NewPolicy(cfg Config) (*Policy, error) {
+	p := &Policy{enabled: cfg.Enabled, minEntropy: cfg.MinEntropy, projects: make(map[string]projectPolicy)}
+	var err error
+	if p.allow, err = compile("redaction.allow", cfg.Allow); err != nil {
+		return nil, err
+	}
+	if p.deny, err = compile("redaction.deny", cfg.Deny); err != nil {
+		return nil, err
+	}
+	for name, pc := range cfg.Projects {
+		pp := projectPolicy{disabled: pc.Disabled}
+		if pp.allow, err = compile("redaction.projects."+name+".allow", pc.Allow); err != nil {
+			return nil, err
+		}
+		if pp.deny, err = compile("redaction.projects."+name+".deny", pc.Deny); err != nil {
+			return nil, err
+		}
+		p.projects[name] = pp
+	}
+	return p, nil
+}
+
+// Default returns a policy with the built-in rules and default entropy
+// threshold and no allow or deny patterns.
+func Default() *Policy {
+	return &Policy{enabled: true, minEntropy: DefaultMinEntropy}
+}
+
+func compile(key string, patterns []string) ([]*regexp.Regexp, error) {
+	out := make([]*regexp.Regexp, 0, len(patterns))
+	for _, s := range patterns {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid pattern %q: %w", key, s, err)
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+// For returns a Redactor applying the policy for project (a project name, or
+// "" for text not tied to one project).
+func (p *Policy) For(project string) *Redactor {
+	r := &Redactor{counts: make(map[string]int)}
+	if p == nil || !p.enabled {
+		return r
+	}
+	pp := p.projects[project]
+	if pp.disabled {
+		return r
+	}
+	r.active = true
+	r.minEntropy = p.minEntropy
+	r.allow = append(append(r.allow, p.allow...), pp.allow...)
+	r.rules = append(r.rules, builtinRules...)
+	for _, re := range append(append([]*regexp.Regexp{}, p.deny...), pp.deny...) {
+		r.rules = append(r.rules, rule{name: "deny", pattern: re})
+	}
+	return r
+}
+
+// Redactor redacts text for one project and tallies what it removed.
+type Redactor struct {
+	active     bool
+	minEntropy float64
+	allow      []*regexp.Regexp
+	rules      []rule
+	counts     map[string]int
+}
+
+// Marker returns the placeholder that replaces text matched by rule.
+func Marker(rule string) string {
+	return "[REDACTED:" + rule + "]"
+}
+
+// Redact returns text with secrets replaced by markers.
+func (r *Redactor) Redact(text string) string {
+	if !r.active || text == "" {
+		return text
+	}
+	for _, ru := range r.rules {
+		text = r.apply(text, ru)
+	}
+	if r.minEntropy > 0 {
+		text = entropyToken.ReplaceAllStringFunc(text, func(tok string) string {
+			if !r.allowed(tok) && highEntropy(tok, r.minEntropy) {
+				r.counts["high_entropy"]++
+				return Marker("high_entropy")
+			}
+			return tok
+		})
+	}
+	return text
+}
+
+// apply replaces every match of ru (or its submatch) not covered by an allow
+// pattern.
+func (r *Redactor) apply(text string, ru rule) string {
+	matches := ru.pattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[2*ru.group], m[2*ru.group+1]
+		if start < 0 || strings.HasPrefix(text[start:end], "[REDACTED:") || r.allowed(text[m[0]:m[1]]) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(Marker(ru.name))
+		last = end
+		r.counts[ru.name]++
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func (r *Redactor) allowed(s string) bool {
+	for _, re := range r.allow {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Report lists what Redact has removed so far, by rule.
+func (r *Redactor) Report() []models.Redaction {
+	var out []models.Redaction
+	for name, n := range r.counts {
+		out = append(out, models.Redaction{Rule: name, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Rule < out[j].Rule })
+	return out
+}
+
+// highEntropy reports whether tok mixes upper case, lower case and digits
+// and its entropy reaches min. Requiring all three classes skips hex hashes,
+// ULIDs and ordinary identifiers.
+func highEntropy(tok string, min float64) bool {
+	if len(tok) < minEntropyLength {
+		return false
+	}
+	var upper, lower, digit bool
+	for _, c := range tok {
+		switch {
+		case unicode.IsUpper(c):
+			upper = true
+		case unicode.IsLower(c):
+			lower = true
+		case unicode.IsDigit(c):
+			digit = true
+		}
+	}
+	return upper && lower && digit && entropy(tok) >= min
+}
+
+// entropy is the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	freq := make(map[rune]int)
+	n := 0
+	for _, c := range s {
+		freq[c]++
+		n++
+	}
+	var h float64
+	for _, f := range freq {
+		p := float64(f) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
@@ -0,0 +1,55 @@
+package refresh
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// ConventionFiles are the repo-root files ingested as project conventions, in order.
+var ConventionFiles = []string{"AGENTS.md", "CLAUDE.md", "CONVENTIONS.md"}
+
+// maxConventionFileBytes caps how much of each convention file is cached.
+const maxConventionFileBytes = 64 * 1024
+
+// ReadConventions concatenates the convention files present in repoPath,
+// each under a "# <filename>" header. Returns "" if none exist.
+func ReadConventions(repoPath string) string {
+	var parts []string
+	for _, name := range ConventionFiles {
+		data, err := os.ReadFile(filepath.Join(repoPath, name))
+		if err != nil {
+			continue
+		}
+		if len(data) > maxConventionFileBytes {
+			data = data[:maxConventionFileBytes]
+		}
+		content := strings.TrimSpace(string(data))
+		if content == "" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("# %s\n\n%s", name, content))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// SyncConventions re-reads the project's convention files and updates
+// p.Conventions when they differ from the cached copy. The cache is left
+// untouched if the project path is missing. Returns true if p was changed.
+func SyncConventions(p *models.Project) bool {
+	if p.Path == "" {
+		return false
+	}
+	if _, err := os.Stat(p.Path); err != nil {
+		return false
+	}
+	conventions := ReadConventions(p.Path)
+	if conventions == p.Conventions {
+		return false
+	}
+	p.Conventions = conventions
+	return true
+}
@@ -0,0 +1,159 @@
+package refresh
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// Env is what refresh steps work with.
+type Env struct {
+	Store  store.Store
+	Git    git.Client
+	GitHub git.GitHubClient
+}
+
+// StepFunc runs one refresh step on p, updating its fields in place. It
+// reports whether it changed p and a short summary of what it found.
+type StepFunc func(ctx context.Context, env Env, p *models.Project) (changed bool, summary string, err error)
+
+// Step is a named stage of the refresh pipeline.
+type Step struct {
+	Name        string
+	Description string
+	Run         StepFunc
+}
+
+// Step statuses reported in StepResult.
+const (
+	StepChanged   = "changed"
+	StepUnchanged = "unchanged"
+	StepSkipped   = "skipped"
+	StepFailed    = "failed"
+)
+
+// StepResult is the outcome of one step for one project.
+type StepResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"` // changed, unchanged, skipped, failed
+	Summary    string `json:"summary,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+var (
+	stepsMu sync.RWMutex
+	steps   []Step
+)
+
+// Register appends a step to the pipeline. Steps run in registration order.
+// It panics if the name is empty or already registered.
+func Register(step Step) {
+	stepsMu.Lock()
+	defer stepsMu.Unlock()
+	if step.Name == "" || step.Run == nil {
+		panic("refresh: step needs a name and a Run func")
+	}
+	for _, s := range steps {
+		if s.Name == step.Name {
+			panic("refresh: step registered twice: " + step.Name)
+		}
+	}
+	steps = append(steps, step)
+}
+
+// Steps returns the registered steps in the order they run.
+func Steps() []Step {
+	stepsMu.RLock()
+	defer stepsMu.RUnlock()
+	return slices.Clone(steps)
+}
+
+// StepNames returns the names of the registered steps in the order they run.
+func StepNames() []string {
+	var names []string
+	for _, s := range Steps() {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+// SkippedSteps parses a project's RefreshSkip list.
+func SkippedSteps(p *models.Project) []string {
+	var names []string
+	for _, name := range strings.Split(p.RefreshSkip, ",") {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// FormatSkip validates step names and joins them into a RefreshSkip value.
+func FormatSkip(names []string) (string, error) {
+	known := StepNames()
+	var out []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || slices.Contains(out, name) {
+			continue
+		}
+		if !slices.Contains(known, name) {
+			return "", fmt.Errorf("unknown refresh step %q (steps: %s)", name, strings.Join(known, ", "))
+		}
+		out = append(out, name)
+	}
+	return strings.Join(out, ","), nil
+}
+
+// Run runs the registered steps on p, except those the project skips, and
+// saves p if any step changed it. A failing step is recorded in its result
+// and the remaining steps still run; the error is for the project as a whole
+// (missing path, failed save).
+func Run(ctx context.Context, env Env, p *models.Project) ([]StepResult, bool, error) {
+	if err := checkPath(p); err != nil {
+		return nil, false, err
+	}
+
+	skip := SkippedSteps(p)
+	var results []StepResult
+	changed := false
+	for _, step := range Steps() {
+		res := StepResult{Name: step.Name}
+		if slices.Contains(skip, step.Name) {
+			res.Status = StepSkipped
+			results = append(results, res)
+			continue
+		}
+
+		start := time.Now()
+		stepChanged, summary, err := step.Run(ctx, env, p)
+		res.DurationMS = time.Since(start).Milliseconds()
+		res.Summary = summary
+		switch {
+		case err != nil:
+			res.Status = StepFailed
+			res.Error = err.Error()
+		case stepChanged:
+			res.Status = StepChanged
+			changed = true
+		default:
+			res.Status = StepUnchanged
+		}
+		results = append(results, res)
+	}
+
+	if changed {
+		if err := env.Store.UpdateProject(ctx, p); err != nil {
+			return results, false, fmt.Errorf("update project: %w", err)
+		}
+	}
+	return results, changed, nil
+}
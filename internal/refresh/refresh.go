@@ -3,19 +3,23 @@ package refresh
 import (
 	"context"
 	"fmt"
-	"os"
+	"sync"
 
 	"github.com/joescharf/pm/internal/git"
-	"github.com/joescharf/pm/internal/golang"
+	"github.com/joescharf/pm/internal/health"
 	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/releases"
 	"github.com/joescharf/pm/internal/store"
 )
 
 // Result holds the outcome of refreshing a single project.
 type Result struct {
-	Name    string `json:"name"`
-	Changed bool   `json:"changed"`
-	Error   string `json:"error,omitempty"`
+	ID      string       `json:"id"`
+	Name    string       `json:"name"`
+	Changed bool         `json:"changed"`
+	Changes []string     `json:"changes,omitempty"` // health deltas since the previous refresh
+	Steps   []StepResult `json:"steps,omitempty"`
+	Error   string       `json:"error,omitempty"`
 }
 
 // AllResult holds the outcome of refreshing all projects.
@@ -26,97 +30,120 @@ type AllResult struct {
 	Results   []Result `json:"results"`
 }
 
-// Project re-detects metadata for a single project and persists changes.
-// Returns true if any field was updated.
+// Project runs the refresh pipeline on a single project and persists
+// changes. Returns true if any field was updated.
 func Project(ctx context.Context, s store.Store, p *models.Project, gc git.Client, ghc git.GitHubClient) (bool, error) {
-	changed := false
+	_, changed, err := Run(ctx, Env{Store: s, Git: gc, GitHub: ghc}, p)
+	return changed, err
+}
 
-	// Validate path still exists
-	if _, err := os.Stat(p.Path); err != nil {
-		return false, fmt.Errorf("project path missing: %s", p.Path)
+// Snapshot scores the project's current health, stores the components and
+// returns what changed since the previous snapshot (nil on the first one).
+func Snapshot(ctx context.Context, s store.Store, p *models.Project, gc git.Client, ghc git.GitHubClient) ([]string, error) {
+	meta := &health.ProjectMetadata{}
+	if dirty, err := gc.IsDirty(ctx, p.Path); err == nil {
+		meta.IsDirty = dirty
 	}
-
-	// Re-detect language
-	if lang := golang.DetectLanguage(p.Path); lang != "" && lang != p.Language {
-		p.Language = lang
-		changed = true
+	if date, err := gc.LastCommitDate(ctx, p.Path); err == nil {
+		meta.LastCommitDate = date
 	}
-
-	// Re-detect remote URL
-	if url, _ := gc.RemoteURL(p.Path); url != "" && url != p.RepoURL {
-		p.RepoURL = url
-		changed = true
+	if branches, err := gc.BranchList(ctx, p.Path); err == nil {
+		meta.BranchCount = len(branches)
 	}
-
-	// Update branch count
-	if branches, err := gc.BranchList(p.Path); err == nil {
-		count := len(branches)
-		if count != p.BranchCount {
-			p.BranchCount = count
-			changed = true
-		}
+	if wts, err := gc.WorktreeList(ctx, p.Path); err == nil {
+		meta.WorktreeCount = len(wts)
 	}
-
-	// Fetch GitHub metadata if we have a repo URL
-	if p.RepoURL != "" {
-		if owner, repo, err := git.ExtractOwnerRepo(p.RepoURL); err == nil {
-			if info, err := ghc.RepoInfo(owner, repo); err == nil && info != nil {
-				if info.Description != "" && info.Description != p.Description {
-					p.Description = info.Description
-					changed = true
-				}
-				if p.Language == "" && info.Language != "" {
-					p.Language = info.Language
-					changed = true
-				}
-			}
-
-			// Check GitHub Pages configuration
-			if pages, err := ghc.PagesInfo(owner, repo); err == nil && pages != nil {
-				if !p.HasGitHubPages || p.PagesURL != pages.URL {
-					p.HasGitHubPages = true
-					p.PagesURL = pages.URL
-					changed = true
-				}
-			} else if p.HasGitHubPages {
-				p.HasGitHubPages = false
-				p.PagesURL = ""
-				changed = true
+	// Refreshing fetches the release now; a failed fetch falls back to the
+	// stored one.
+	if ghc != nil {
+		if rel, _ := releases.Fetch(ctx, s, ghc, p); rel != nil && rel.FetchedAt != nil {
+			meta.LatestRelease = rel.TagName
+			if rel.PublishedAt != nil {
+				meta.ReleaseDate = *rel.PublishedAt
 			}
 		}
 	}
-
-	if changed {
-		if err := s.UpdateProject(ctx, p); err != nil {
-			return false, fmt.Errorf("update project: %w", err)
+	if meta.LatestRelease == "" {
+		if tag, err := gc.LatestTag(ctx, p.Path); err == nil {
+			meta.LatestRelease = tag
 		}
 	}
 
-	return changed, nil
+	issues, err := s.ListIssues(ctx, store.IssueListFilter{ProjectID: p.ID})
+	if err != nil {
+		return nil, fmt.Errorf("list issues: %w", err)
+	}
+
+	var prev *models.HealthSnapshot
+	if snaps, err := s.ListHealthSnapshots(ctx, p.ID, 1); err == nil && len(snaps) > 0 {
+		prev = snaps[0]
+	}
+
+	cur := health.Snapshot(p.ID, meta, health.NewScorer().Score(p, meta, issues))
+	if err := s.CreateHealthSnapshot(ctx, cur); err != nil {
+		return nil, fmt.Errorf("record health snapshot: %w", err)
+	}
+	return health.Diff(prev, cur), nil
 }
 
+// DefaultWorkers is how many projects All refreshes concurrently.
+const DefaultWorkers = 4
+
 // All refreshes metadata for all tracked projects.
 func All(ctx context.Context, s store.Store, gc git.Client, ghc git.GitHubClient) (*AllResult, error) {
 	projects, err := s.ListProjects(ctx, "")
 	if err != nil {
 		return nil, err
 	}
+	return Projects(ctx, s, projects, gc, ghc, DefaultWorkers), nil
+}
 
-	result := &AllResult{Total: len(projects)}
-	for _, p := range projects {
-		r := Result{Name: p.Name}
-		changed, err := Project(ctx, s, p, gc, ghc)
-		if err != nil {
-			r.Error = err.Error()
-			result.Failed++
-		} else {
-			r.Changed = changed
-			if changed {
-				result.Refreshed++
+// Projects refreshes the given projects using up to workers goroutines. A
+// failure only marks that project's result; results keep the input order.
+func Projects(ctx context.Context, s store.Store, projects []*models.Project, gc git.Client, ghc git.GitHubClient, workers int) *AllResult {
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]Result, len(projects))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(projects)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = one(ctx, s, projects[i], gc, ghc)
 			}
+		}()
+	}
+	for i := range projects {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	result := &AllResult{Total: len(projects), Results: results}
+	for _, r := range results {
+		if r.Error != "" {
+			result.Failed++
+		} else if r.Changed {
+			result.Refreshed++
 		}
-		result.Results = append(result.Results, r)
 	}
+	return result
+}
 
-	return result, nil
+// one refreshes a single project and records its health snapshot.
+func one(ctx context.Context, s store.Store, p *models.Project, gc git.Client, ghc git.GitHubClient) Result {
+	r := Result{ID: p.ID, Name: p.Name}
+	steps, changed, err := Run(ctx, Env{Store: s, Git: gc, GitHub: ghc}, p)
+	r.Steps = steps
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	r.Changed = changed
+	r.Changes, _ = Snapshot(ctx, s, p, gc, ghc)
+	return r
 }
@@ -0,0 +1,259 @@
+package refresh
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/joescharf/pm/internal/deps"
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/golang"
+	"github.com/joescharf/pm/internal/langstats"
+	"github.com/joescharf/pm/internal/models"
+)
+
+// The built-in steps, in the order they run.
+func init() {
+	Register(Step{Name: "language", Description: "Detect the primary language and measure the per-language line breakdown", Run: languageStep})
+	Register(Step{Name: "git", Description: "Read the remote URL and branch count", Run: gitStep})
+	Register(Step{Name: "github", Description: "Fetch the GitHub description, language and Pages site", Run: githubStep})
+	Register(Step{Name: "conventions", Description: "Re-ingest AGENTS.md/CLAUDE.md/CONVENTIONS.md", Run: conventionsStep})
+	Register(Step{Name: "deps", Description: "Record dependencies on tracked projects required in go.mod", Run: depsStep})
+	Register(Step{Name: "todos", Description: "Count TODO/FIXME comments in the source tree", Run: todosStep})
+}
+
+func checkPath(p *models.Project) error {
+	if _, err := os.Stat(p.Path); err != nil {
+		return fmt.Errorf("project path missing: %s", p.Path)
+	}
+	return nil
+}
+
+// languageStep takes the primary language from manifest files, falling back
+// to the largest share of the line breakdown when there is none.
+func languageStep(ctx context.Context, _ Env, p *models.Project) (bool, string, error) {
+	shares, err := langstats.Compute(ctx, p.Path)
+	if err != nil {
+		return false, "", fmt.Errorf("count lines: %w", err)
+	}
+	changed := !reflect.DeepEqual(shares, p.Languages)
+	p.Languages = shares
+
+	lang := golang.DetectLanguage(p.Path)
+	if lang == "" && len(shares) > 0 {
+		lang = shares[0].Language
+	}
+	if lang != "" && lang != p.Language {
+		p.Language = lang
+		changed = true
+	}
+	if len(shares) == 0 {
+		return changed, p.Language, nil
+	}
+	return changed, langstats.Summary(shares), nil
+}
+
+func gitStep(ctx context.Context, env Env, p *models.Project) (bool, string, error) {
+	changed := false
+	if url, _ := env.Git.RemoteURL(ctx, p.Path); url != "" && url != p.RepoURL {
+		p.RepoURL = url
+		changed = true
+	}
+	branches, err := env.Git.BranchList(ctx, p.Path)
+	if err != nil {
+		return changed, "", fmt.Errorf("list branches: %w", err)
+	}
+	if len(branches) != p.BranchCount {
+		p.BranchCount = len(branches)
+		changed = true
+	}
+	return changed, fmt.Sprintf("%d branches", p.BranchCount), nil
+}
+
+func githubStep(_ context.Context, env Env, p *models.Project) (bool, string, error) {
+	if p.RepoURL == "" {
+		return false, "no remote", nil
+	}
+	owner, repo, err := git.ExtractOwnerRepo(p.RepoURL)
+	if err != nil {
+		return false, "not a GitHub remote", nil
+	}
+
+	changed := false
+	if info, err := env.GitHub.RepoInfo(owner, repo); err == nil && info != nil {
+		if info.Description != "" && info.Description != p.Description {
+			p.Description = info.Description
+			changed = true
+		}
+		if p.Language == "" && info.Language != "" {
+			p.Language = info.Language
+			changed = true
+		}
+	}
+
+	// Check GitHub Pages configuration
+	if pages, err := env.GitHub.PagesInfo(owner, repo); err == nil && pages != nil {
+		if !p.HasGitHubPages || p.PagesURL != pages.URL {
+			p.HasGitHubPages = true
+			p.PagesURL = pages.URL
+			changed = true
+		}
+	} else if p.HasGitHubPages {
+		p.HasGitHubPages = false
+		p.PagesURL = ""
+		changed = true
+	}
+	return changed, owner + "/" + repo, nil
+}
+
+func conventionsStep(_ context.Context, _ Env, p *models.Project) (bool, string, error) {
+	changed := SyncConventions(p)
+	if p.Conventions == "" {
+		return changed, "none", nil
+	}
+	return changed, fmt.Sprintf("%d bytes", len(p.Conventions)), nil
+}
+
+// depsStep adds a dependency edge for every tracked project whose module the
+// project's go.mod requires. Existing edges, including ones declared by hand,
+// are never removed.
+func depsStep(ctx context.Context, env Env, p *models.Project) (bool, string, error) {
+	if !golang.IsGoProject(p.Path) {
+		return false, "no go.mod", nil
+	}
+	required, err := golang.Requires(p.Path)
+	if err != nil {
+		return false, "", err
+	}
+
+	projects, err := env.Store.ListProjects(ctx, "")
+	if err != nil {
+		return false, "", fmt.Errorf("list projects: %w", err)
+	}
+	edges, err := env.Store.ListProjectDependencies(ctx)
+	if err != nil {
+		return false, "", fmt.Errorf("list dependencies: %w", err)
+	}
+	g := deps.Build(projects, edges)
+	existing := make(map[string]bool)
+	for _, up := range g.Upstream(p.ID) {
+		existing[up.ID] = true
+	}
+
+	var found, added []string
+	for _, other := range projects {
+		if other.ID == p.ID || !requiresModuleOf(required, other) {
+			continue
+		}
+		found = append(found, other.Name)
+		if existing[other.ID] || g.WouldCycle(p.ID, other.ID) {
+			continue
+		}
+		if err := env.Store.CreateProjectDependency(ctx, &models.ProjectDependency{ProjectID: p.ID, DependsOnID: other.ID}); err != nil {
+			return false, "", fmt.Errorf("add dependency on %s: %w", other.Name, err)
+		}
+		added = append(added, other.Name)
+	}
+
+	summary := fmt.Sprintf("%d modules, %d tracked", len(required), len(found))
+	if len(added) > 0 {
+		summary += "; added " + strings.Join(added, ", ")
+	}
+	// Dependencies live in their own table, so p itself is unchanged.
+	return false, summary, nil
+}
+
+// requiresModuleOf reports whether one of the required module paths is the
+// other project's module, by its go.mod or its GitHub remote.
+func requiresModuleOf(required []string, other *models.Project) bool {
+	var modules []string
+	if mod, err := golang.NewAnalyzer().ModulePath(other.Path); err == nil {
+		modules = append(modules, mod)
+	}
+	if owner, repo, err := git.ExtractOwnerRepo(other.RepoURL); err == nil {
+		modules = append(modules, "github.com/"+owner+"/"+repo)
+	}
+	for _, req := range required {
+		for _, mod := range modules {
+			if req == mod || strings.HasPrefix(req, mod+"/v") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// todoPattern matches the comment markers counted by the todos step.
+var todoPattern = regexp.MustCompile(`\b(TODO|FIXME|XXX|HACK)\b`)
+
+// Limits that keep the todos step cheap on large trees.
+const (
+	maxTodoFiles     = 20000
+	maxTodoFileBytes = 1 << 20
+)
+
+// todoSkipDirs are directories the todos step does not descend into.
+var todoSkipDirs = map[string]bool{"node_modules": true, "vendor": true, "dist": true, "build": true}
+
+func todosStep(ctx context.Context, _ Env, p *models.Project) (bool, string, error) {
+	count, files, scanned := 0, 0, 0
+	err := filepath.WalkDir(p.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != p.Path && (strings.HasPrefix(d.Name(), ".") || todoSkipDirs[d.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if scanned++; scanned > maxTodoFiles {
+			return fs.SkipAll
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n := countTodos(path)
+		if n > 0 {
+			count += n
+			files++
+		}
+		return nil
+	})
+	if err != nil {
+		return false, "", err
+	}
+	return false, fmt.Sprintf("%d in %d files", count, files), nil
+}
+
+// countTodos counts the lines of a text file that carry a TODO marker.
+// Binary and oversized files count as none.
+func countTodos(path string) int {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > maxTodoFileBytes {
+		return 0
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || bytes.IndexByte(data, 0) >= 0 {
+		return 0
+	}
+	n := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxTodoFileBytes)
+	for scanner.Scan() {
+		if todoPattern.Match(scanner.Bytes()) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,253 @@
+// Package releases keeps each project's latest GitHub release in the store.
+// A background poller fetches it, more often for active projects, and
+// status, health and CLI reads use the stored copy with how fresh it is
+// instead of calling GitHub themselves.
+package releases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// Store is the subset of store.Store the poller and readers need.
+type Store interface {
+	ListProjects(ctx context.Context, group string) ([]*models.Project, error)
+	ListAgentSessionsFiltered(ctx context.Context, filter store.SessionListFilter) ([]*models.AgentSession, error)
+	SaveProjectRelease(ctx context.Context, r *models.ProjectRelease) error
+	GetProjectRelease(ctx context.Context, projectID string) (*models.ProjectRelease, error)
+	ListProjectReleases(ctx context.Context) ([]*models.ProjectRelease, error)
+}
+
+// GitHub fetches a repo's latest release.
+type GitHub interface {
+	LatestRelease(owner, repo string) (*git.Release, error)
+}
+
+// Git reads when a project was last committed to.
+type Git interface {
+	LastCommitDate(ctx context.Context, repoPath string) (time.Time, error)
+}
+
+// Default polling schedule.
+const (
+	DefaultActiveInterval = 15 * time.Minute
+	DefaultIdleInterval   = 6 * time.Hour
+	DefaultActiveWithin   = 7 * 24 * time.Hour
+)
+
+// Schedule is how often a project's release is fetched.
+type Schedule struct {
+	// Active is the interval for projects with an open agent session or a
+	// commit within ActiveWithin.
+	Active time.Duration
+	// Idle is the interval for the other projects.
+	Idle         time.Duration
+	ActiveWithin time.Duration
+}
+
+// DefaultSchedule polls active projects every 15 minutes and the others
+// every 6 hours.
+func DefaultSchedule() Schedule {
+	return Schedule{Active: DefaultActiveInterval, Idle: DefaultIdleInterval, ActiveWithin: DefaultActiveWithin}
+}
+
+// Freshness describes how current a stored release is.
+type Freshness struct {
+	FetchedAt  *time.Time `json:"fetched_at"` // last successful fetch (null = none yet)
+	CheckedAt  time.Time  `json:"checked_at"` // last fetch, successful or not
+	AgeSeconds int64      `json:"age_seconds"`
+	// Stale is set when the release was last fetched more than twice the
+	// idle interval ago, so the poller is behind or failing.
+	Stale bool   `json:"stale"`
+	Error string `json:"error,omitempty"` // why the last fetch failed
+}
+
+// FreshnessOf reports how current r is at now.
+func FreshnessOf(r *models.ProjectRelease, sched Schedule, now time.Time) Freshness {
+	f := Freshness{FetchedAt: r.FetchedAt, CheckedAt: r.CheckedAt, Error: r.Error, Stale: true}
+	if r.FetchedAt != nil {
+		age := now.Sub(*r.FetchedAt)
+		f.AgeSeconds = int64(age.Seconds())
+		f.Stale = age > 2*sched.Idle
+	}
+	return f
+}
+
+// Fetch fetches the project's latest release from GitHub now and stores it.
+// A failed fetch keeps the release stored before, recording the error, and
+// is returned with it. Projects without a GitHub repo are not fetched.
+func Fetch(ctx context.Context, s Store, gh GitHub, p *models.Project) (*models.ProjectRelease, error) {
+	owner, repo, err := git.ExtractOwnerRepo(p.RepoURL)
+	if err != nil {
+		return nil, fmt.Errorf("project %s has no GitHub repo: %w", p.Name, err)
+	}
+	r, err := s.GetProjectRelease(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		r = &models.ProjectRelease{ProjectID: p.ID}
+	}
+
+	now := time.Now().UTC()
+	r.CheckedAt = now
+	rel, fetchErr := gh.LatestRelease(owner, repo)
+	if fetchErr == nil && rel == nil {
+		fetchErr = fmt.Errorf("no release")
+	}
+	if fetchErr != nil {
+		r.Error = fetchErr.Error()
+	} else {
+		r.Error = ""
+		r.FetchedAt = &now
+		r.TagName = rel.TagName
+		r.PublishedAt = nil
+		if t, err := time.Parse(time.RFC3339, rel.PublishedAt); err == nil {
+			t = t.UTC()
+			r.PublishedAt = &t
+		}
+		r.Assets = nil
+		for _, a := range rel.Assets {
+			r.Assets = append(r.Assets, models.ReleaseAsset{Name: a.Name, DownloadCount: a.DownloadCount, Size: a.Size})
+		}
+	}
+	if err := s.SaveProjectRelease(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, fetchErr
+}
+
+// Cached returns the project's stored release, fetching it first if it has
+// never been fetched or was last fetched more than sched's idle interval ago,
+// as when no poller runs. While fetches fail they are retried at most once
+// per active interval, and the older release is returned. It returns nil for
+// projects without a GitHub repo or whose release is unknown.
+func Cached(ctx context.Context, s Store, gh GitHub, p *models.Project, sched Schedule) *models.ProjectRelease {
+	if _, _, err := git.ExtractOwnerRepo(p.RepoURL); err != nil {
+		return nil
+	}
+	r, err := s.GetProjectRelease(ctx, p.ID)
+	if err != nil {
+		return nil
+	}
+	if gh != nil && refetchDue(r, sched, time.Now()) {
+		if fetched, _ := Fetch(ctx, s, gh, p); fetched != nil {
+			r = fetched
+		}
+	}
+	if r == nil || r.FetchedAt == nil {
+		return nil
+	}
+	return r
+}
+
+// refetchDue reports whether Cached should fetch r again at now.
+func refetchDue(r *models.ProjectRelease, sched Schedule, now time.Time) bool {
+	if r == nil {
+		return true
+	}
+	if r.FetchedAt != nil && (sched.Idle <= 0 || now.Sub(*r.FetchedAt) <= sched.Idle) {
+		return false
+	}
+	retry := sched.Active
+	if retry <= 0 {
+		retry = DefaultActiveInterval
+	}
+	return now.Sub(r.CheckedAt) > retry
+}
+
+// Poller fetches the releases of all projects in the background, each on
+// its schedule.
+type Poller struct {
+	store    Store
+	gh       GitHub
+	git      Git
+	schedule Schedule
+	now      func() time.Time
+}
+
+// NewPoller returns a poller fetching releases from gh on sched. gc tells
+// which projects were committed to recently; it may be nil.
+func NewPoller(s Store, gh GitHub, gc Git, sched Schedule) *Poller {
+	return &Poller{store: s, gh: gh, git: gc, schedule: sched, now: time.Now}
+}
+
+// Interval returns how often the project's release is fetched: the active
+// interval if it has an open agent session or a recent commit, the idle
+// interval otherwise.
+func (pl *Poller) Interval(ctx context.Context, p *models.Project) time.Duration {
+	open, err := pl.store.ListAgentSessionsFiltered(ctx, store.SessionListFilter{
+		ProjectID: p.ID,
+		Statuses:  models.OpenSessionStatuses,
+		Limit:     1,
+	})
+	if err == nil && len(open) > 0 {
+		return pl.schedule.Active
+	}
+	if pl.git != nil {
+		if date, err := pl.git.LastCommitDate(ctx, p.Path); err == nil && pl.now().Sub(date) < pl.schedule.ActiveWithin {
+			return pl.schedule.Active
+		}
+	}
+	return pl.schedule.Idle
+}
+
+// PollDue fetches the releases of the projects with a GitHub repo whose
+// last fetch is older than their interval, returning how many it fetched.
+// A project's failed fetch is retried on its next interval and does not
+// stop the others.
+func (pl *Poller) PollDue(ctx context.Context) (int, error) {
+	projects, err := pl.store.ListProjects(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	stored, err := pl.store.ListProjectReleases(ctx)
+	if err != nil {
+		return 0, err
+	}
+	checked := make(map[string]time.Time, len(stored))
+	for _, r := range stored {
+		checked[r.ProjectID] = r.CheckedAt
+	}
+
+	fetched := 0
+	for _, p := range projects {
+		if ctx.Err() != nil {
+			return fetched, ctx.Err()
+		}
+		if _, _, err := git.ExtractOwnerRepo(p.RepoURL); err != nil {
+			continue
+		}
+		if last, ok := checked[p.ID]; ok && pl.now().Sub(last) < pl.Interval(ctx, p) {
+			continue
+		}
+		_, _ = Fetch(ctx, pl.store, pl.gh, p)
+		fetched++
+	}
+	return fetched, nil
+}
+
+// Run polls every tick until ctx is done, reporting errors to onErr.
+func (pl *Poller) Run(ctx context.Context, tick time.Duration, onErr func(error)) {
+	poll := func() {
+		if _, err := pl.PollDue(ctx); err != nil && ctx.Err() == nil && onErr != nil {
+			onErr(err)
+		}
+	}
+	poll()
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			poll()
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package releases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/git"
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+type fakeGitHub struct {
+	release *git.Release
+	err     error
+	calls   int
+}
+
+func (f *fakeGitHub) LatestRelease(_, _ string) (*git.Release, error) {
+	f.calls++
+	return f.release, f.err
+}
+
+type fakeGit map[string]time.Time
+
+func (f fakeGit) LastCommitDate(_ context.Context, path string) (time.Time, error) {
+	if d, ok := f[path]; ok {
+		return d, nil
+	}
+	return time.Time{}, errors.New("no commits")
+}
+
+func TestFetch(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: "/code/api", RepoURL: "https://github.com/acme/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	gh := &fakeGitHub{release: &git.Release{
+		TagName:     "v1.0.0",
+		PublishedAt: "2026-05-01T12:00:00Z",
+		Assets:      []git.ReleaseAsset{{Name: "api.tar.gz", DownloadCount: 3, Size: 100}},
+	}}
+	r, err := Fetch(ctx, s, gh, p)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", r.TagName)
+	require.NotNil(t, r.PublishedAt)
+	assert.Equal(t, time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC), *r.PublishedAt)
+	assert.Equal(t, []models.ReleaseAsset{{Name: "api.tar.gz", DownloadCount: 3, Size: 100}}, r.Assets)
+	require.NotNil(t, r.FetchedAt)
+	fetched := *r.FetchedAt
+
+	// A failed fetch keeps the stored release and records why
+	gh.err = errors.New("rate limited")
+	r, err = Fetch(ctx, s, gh, p)
+	require.Error(t, err)
+	require.NotNil(t, r)
+	stored, err := s.GetProjectRelease(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", stored.TagName)
+	assert.Equal(t, "rate limited", stored.Error)
+	assert.True(t, stored.FetchedAt.Equal(fetched))
+	assert.False(t, stored.CheckedAt.Before(fetched))
+
+	_, err = Fetch(ctx, s, gh, &models.Project{Name: "local", Path: "/code/local"})
+	assert.Error(t, err, "no GitHub repo")
+}
+
+func TestCached(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	p := &models.Project{Name: "api", Path: "/code/api", RepoURL: "https://github.com/acme/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	gh := &fakeGitHub{release: &git.Release{TagName: "v1.0.0"}}
+	sched := DefaultSchedule()
+
+	r := Cached(ctx, s, gh, p, sched)
+	require.NotNil(t, r)
+	assert.Equal(t, "v1.0.0", r.TagName)
+	assert.Equal(t, 1, gh.calls, "fetched on first read")
+
+	gh.release = &git.Release{TagName: "v2.0.0"}
+	r = Cached(ctx, s, gh, p, sched)
+	require.NotNil(t, r)
+	assert.Equal(t, "v1.0.0", r.TagName, "later reads use the stored copy")
+	assert.Equal(t, 1, gh.calls)
+
+	// A copy older than the idle interval is fetched again
+	old := time.Now().UTC().Add(-sched.Idle - time.Hour)
+	r.FetchedAt, r.CheckedAt = &old, old
+	require.NoError(t, s.SaveProjectRelease(ctx, r))
+	r = Cached(ctx, s, gh, p, sched)
+	require.NotNil(t, r)
+	assert.Equal(t, "v2.0.0", r.TagName)
+	assert.Equal(t, 2, gh.calls)
+
+	// While fetches fail, the old copy is kept and retried once per active interval
+	gh.err = errors.New("rate limited")
+	r.FetchedAt, r.CheckedAt = &old, old
+	require.NoError(t, s.SaveProjectRelease(ctx, r))
+	for range 2 {
+		r = Cached(ctx, s, gh, p, sched)
+		require.NotNil(t, r)
+		assert.Equal(t, "v2.0.0", r.TagName)
+	}
+	assert.Equal(t, 3, gh.calls)
+	gh.err = nil
+
+	local := &models.Project{Name: "local", Path: "/code/local"}
+	require.NoError(t, s.CreateProject(ctx, local))
+	assert.Nil(t, Cached(ctx, s, gh, local, sched))
+	assert.Equal(t, 3, gh.calls)
+
+	none := &models.Project{Name: "new", Path: "/code/new", RepoURL: "https://github.com/acme/new"}
+	require.NoError(t, s.CreateProject(ctx, none))
+	assert.Nil(t, Cached(ctx, s, &fakeGitHub{err: errors.New("not found")}, none, sched), "no release yet")
+}
+
+func TestFreshnessOf(t *testing.T) {
+	now := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	sched := DefaultSchedule()
+
+	fetched := now.Add(-time.Hour)
+	f := FreshnessOf(&models.ProjectRelease{FetchedAt: &fetched, CheckedAt: now}, sched, now)
+	assert.Equal(t, int64(3600), f.AgeSeconds)
+	assert.False(t, f.Stale)
+
+	old := now.Add(-13 * time.Hour)
+	f = FreshnessOf(&models.ProjectRelease{FetchedAt: &old, CheckedAt: now, Error: "rate limited"}, sched, now)
+	assert.True(t, f.Stale, "older than twice the idle interval")
+	assert.Equal(t, "rate limited", f.Error)
+
+	assert.True(t, FreshnessOf(&models.ProjectRelease{CheckedAt: now}, sched, now).Stale, "never fetched")
+}
+
+func TestPoller(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	now := time.Now()
+
+	busy := &models.Project{Name: "busy", Path: "/code/busy", RepoURL: "https://github.com/acme/busy"}
+	recent := &models.Project{Name: "recent", Path: "/code/recent", RepoURL: "https://github.com/acme/recent"}
+	quiet := &models.Project{Name: "quiet", Path: "/code/quiet", RepoURL: "https://github.com/acme/quiet"}
+	local := &models.Project{Name: "local", Path: "/code/local"}
+	for _, p := range []*models.Project{busy, recent, quiet, local} {
+		require.NoError(t, s.CreateProject(ctx, p))
+	}
+	require.NoError(t, s.CreateAgentSession(ctx, &models.AgentSession{ProjectID: busy.ID, Branch: "feature/x", Status: models.SessionStatusActive}))
+
+	gh := &fakeGitHub{release: &git.Release{TagName: "v1.0.0"}}
+	gc := fakeGit{
+		"/code/recent": now.Add(-24 * time.Hour),
+		"/code/quiet":  now.Add(-30 * 24 * time.Hour),
+	}
+	pl := NewPoller(s, gh, gc, DefaultSchedule())
+
+	assert.Equal(t, DefaultActiveInterval, pl.Interval(ctx, busy), "open session")
+	assert.Equal(t, DefaultActiveInterval, pl.Interval(ctx, recent), "recent commit")
+	assert.Equal(t, DefaultIdleInterval, pl.Interval(ctx, quiet))
+
+	n, err := pl.PollDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n, "every project with a repo is fetched first")
+
+	n, err = pl.PollDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "none is due yet")
+
+	pl.now = func() time.Time { return now.Add(time.Hour) }
+	n, err = pl.PollDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n, "active projects are due after their interval")
+
+	pl.now = func() time.Time { return now.Add(7 * time.Hour) }
+	n, err = pl.PollDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n, "idle projects too after theirs")
+}
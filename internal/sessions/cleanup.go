@@ -0,0 +1,111 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/wt/pkg/lifecycle"
+)
+
+// CleanupOptions configures how a session's worktree is torn down.
+type CleanupOptions struct {
+	Force        bool // remove a dirty worktree and delete an unmerged branch
+	DeleteBranch bool // delete the session's local branch
+	// DeleteRemote also deletes the branch the local one tracks on its
+	// remote (for sessions checked out from a pushed or PR branch). Callers
+	// confirm this with the user first; it is never implied by Force.
+	DeleteRemote bool
+}
+
+// CleanupResult reports what tearing down a session's worktree removed and
+// what it left in place, and why.
+type CleanupResult struct {
+	WorktreeRemoved bool   `json:"worktree_removed"`
+	Branch          string `json:"branch"`
+	BranchDeleted   bool   `json:"branch_deleted"`
+	// Upstream is the remote-tracking branch the local one follows, such as
+	// "origin/feature/login"; empty for local-only branches.
+	Upstream      string   `json:"upstream,omitempty"`
+	RemoteDeleted bool     `json:"remote_deleted"`
+	Kept          []string `json:"kept,omitempty"` // what was not deleted, and why
+}
+
+// upstream is a branch's remote-tracking configuration.
+type upstream struct {
+	remote string // remote name, e.g. "origin"
+	branch string // branch name on the remote
+}
+
+func (u upstream) String() string { return u.remote + "/" + u.branch }
+
+// branchUpstream returns the remote branch a local branch tracks, or false
+// when it has none or tracks another local branch.
+func (c *repoBoundClient) branchUpstream(branch string) (upstream, bool) {
+	remote, err := c.git("config", "--get", "branch."+branch+".remote")
+	if err != nil || remote == "" || remote == "." {
+		return upstream{}, false
+	}
+	merge, err := c.git("config", "--get", "branch."+branch+".merge")
+	if err != nil || !strings.HasPrefix(merge, "refs/heads/") {
+		return upstream{}, false
+	}
+	return upstream{remote: remote, branch: strings.TrimPrefix(merge, "refs/heads/")}, true
+}
+
+// cleanupWorktree removes a session's worktree via lifecycle (close iTerm +
+// remove git worktree + untrust + cleanup state), then deletes its branch as
+// asked. Branches are deleted by the session's branch name rather than by
+// lifecycle, which assumes the worktree directory is named after a local
+// branch. Failing to delete a branch does not fail the cleanup; it is
+// reported in the result's Kept.
+func (m *Manager) cleanupWorktree(ctx context.Context, project *models.Project, session *models.AgentSession, opts CleanupOptions) (*CleanupResult, error) {
+	result := &CleanupResult{Branch: session.Branch}
+	gitClient := &repoBoundClient{repoPath: project.Path}
+
+	// Read the tracking config before the branch (and its config) is deleted
+	up, tracked := upstream{}, false
+	if session.Branch != "" {
+		up, tracked = gitClient.branchUpstream(session.Branch)
+	}
+	if tracked {
+		result.Upstream = up.String()
+	}
+
+	m.closeTerminal(session)
+	lm := m.wt.LifecycleForRepo(project.Path)
+	if err := lm.Delete(ctx, session.WorktreePath, lifecycle.DeleteOptions{Force: opts.Force}); err != nil {
+		return result, fmt.Errorf("delete worktree: %w", err)
+	}
+	result.WorktreeRemoved = true
+
+	switch {
+	case session.Branch == "":
+	case !opts.DeleteBranch:
+		result.Kept = append(result.Kept, fmt.Sprintf("local branch %s: not requested", session.Branch))
+	default:
+		err := gitClient.BranchDelete(session.Branch, false)
+		if err != nil && opts.Force {
+			err = gitClient.BranchDelete(session.Branch, true)
+		}
+		if err != nil {
+			result.Kept = append(result.Kept, fmt.Sprintf("local branch %s: %v", session.Branch, err))
+		} else {
+			result.BranchDeleted = true
+		}
+	}
+
+	switch {
+	case !tracked:
+	case !opts.DeleteRemote:
+		result.Kept = append(result.Kept, fmt.Sprintf("remote branch %s: not requested", up))
+	default:
+		if _, err := gitClient.git("push", up.remote, "--delete", up.branch); err != nil {
+			result.Kept = append(result.Kept, fmt.Sprintf("remote branch %s: %v", up, err))
+		} else {
+			result.RemoteDeleted = true
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,162 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// Discovery defaults for DiscoverAll.
+const (
+	DefaultDiscoverWorkers = 4
+	DefaultDiscoverTimeout = 30 * time.Second
+)
+
+// DiscoverOptions configures discovery across several projects.
+type DiscoverOptions struct {
+	// Workers is how many projects are scanned at once (< 1 uses
+	// DefaultDiscoverWorkers).
+	Workers int
+	// Timeout bounds the scan of one project (<= 0 uses
+	// DefaultDiscoverTimeout); its git commands are killed once it passes.
+	Timeout time.Duration
+	// Exclude lists project names and path prefixes ("/Volumes/backup")
+	// that are never scanned.
+	Exclude []string
+}
+
+// DiscoverProjectResult is the outcome of discovery in one project.
+type DiscoverProjectResult struct {
+	ProjectID  string `json:"project_id"`
+	Name       string `json:"name"`
+	Discovered int    `json:"discovered"`
+	// Skipped says why the project was not scanned: "archived", "excluded"
+	// or "path missing" (e.g. a disconnected volume).
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// DiscoverAllResult is the outcome of DiscoverAll.
+type DiscoverAllResult struct {
+	Discovered []*models.AgentSession
+	// Projects has one result per project, in the input order.
+	Projects []DiscoverProjectResult
+	Failed   int
+	Skipped  int
+}
+
+// DiscoverAll runs DiscoverWorktrees on projects concurrently. Projects
+// that are archived, excluded or whose path is missing are skipped without
+// running git, and a failure only marks that project's result.
+func (m *Manager) DiscoverAll(ctx context.Context, projects []*models.Project, opts DiscoverOptions) *DiscoverAllResult {
+	workers := opts.Workers
+	if workers < 1 {
+		workers = DefaultDiscoverWorkers
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultDiscoverTimeout
+	}
+
+	results := make([]DiscoverProjectResult, len(projects))
+	found := make([][]*models.AgentSession, len(projects))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(projects)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				p := projects[i]
+				results[i] = DiscoverProjectResult{ProjectID: p.ID, Name: p.Name}
+				if reason := discoverSkipReason(p, opts.Exclude); reason != "" {
+					results[i].Skipped = reason
+					continue
+				}
+				pctx, cancel := context.WithTimeout(ctx, timeout)
+				var discovered []*models.AgentSession
+				err := statContext(pctx, p.Path)
+				if err == nil {
+					discovered, err = m.DiscoverWorktrees(pctx, p.ID)
+				} else if pctx.Err() == nil {
+					results[i].Skipped = "path missing"
+					err = nil
+				}
+				if err != nil && pctx.Err() == context.DeadlineExceeded {
+					err = fmt.Errorf("timed out after %s", timeout)
+				}
+				cancel()
+				if err != nil {
+					results[i].Error = err.Error()
+				}
+				results[i].Discovered = len(discovered)
+				found[i] = discovered
+			}
+		}()
+	}
+	for i := range projects {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	result := &DiscoverAllResult{Projects: results}
+	for i, r := range results {
+		result.Discovered = append(result.Discovered, found[i]...)
+		switch {
+		case r.Error != "":
+			result.Failed++
+		case r.Skipped != "":
+			result.Skipped++
+		}
+	}
+	return result
+}
+
+// discoverSkipReason returns why discovery should leave p alone, or "". A
+// missing path is found later, within the project's timeout.
+func discoverSkipReason(p *models.Project, exclude []string) string {
+	if p.Archived {
+		return "archived"
+	}
+	for _, e := range exclude {
+		if e == "" {
+			continue
+		}
+		if strings.EqualFold(e, p.Name) || pathWithin(p.Path, e) {
+			return "excluded"
+		}
+	}
+	return ""
+}
+
+// statContext stats path, giving up when ctx ends: a stat on a hung network
+// mount can block for minutes.
+func statContext(ctx context.Context, path string) error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := os.Stat(path)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// pathWithin reports whether path is dir or lies under it.
+func pathWithin(path, dir string) bool {
+	if !filepath.IsAbs(dir) {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
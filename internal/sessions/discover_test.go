@@ -0,0 +1,68 @@
+package sessions
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+func TestDiscoverAll(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+
+	repo := initRepo(t)
+	wtPath := filepath.Join(t.TempDir(), "feature")
+	gitRun(t, repo, "worktree", "add", "-q", "-b", "feature", wtPath)
+
+	excluded := t.TempDir()
+	projects := []*models.Project{
+		{Name: "app", Path: repo},
+		{Name: "offline", Path: filepath.Join(t.TempDir(), "unmounted")},
+		{Name: "excluded-by-path", Path: filepath.Join(excluded, "app")},
+		{Name: "Excluded-By-Name", Path: initRepo(t)},
+		{Name: "not-a-repo", Path: t.TempDir()},
+		{Name: "old", Path: initRepo(t), Archived: true},
+	}
+	for _, p := range projects {
+		require.NoError(t, s.CreateProject(ctx, p))
+	}
+
+	m := NewManager(s, nil)
+	result := m.DiscoverAll(ctx, projects, DiscoverOptions{
+		Workers: 2,
+		Exclude: []string{excluded, "excluded-by-name"},
+	})
+
+	require.Len(t, result.Projects, len(projects))
+	assert.Equal(t, 1, result.Projects[0].Discovered)
+	assert.Empty(t, result.Projects[0].Error)
+	assert.Equal(t, "path missing", result.Projects[1].Skipped)
+	assert.Equal(t, "excluded", result.Projects[2].Skipped)
+	assert.Equal(t, "excluded", result.Projects[3].Skipped)
+	assert.NotEmpty(t, result.Projects[4].Error, "a directory outside git fails on its own")
+	assert.Equal(t, "archived", result.Projects[5].Skipped)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 4, result.Skipped)
+
+	require.Len(t, result.Discovered, 1)
+	assert.Equal(t, "feature", result.Discovered[0].Branch)
+
+	// A second pass finds nothing new.
+	again := m.DiscoverAll(ctx, projects[:1], DiscoverOptions{})
+	assert.Empty(t, again.Discovered)
+	assert.Equal(t, 0, again.Projects[0].Discovered)
+}
+
+func TestPathWithin(t *testing.T) {
+	assert.True(t, pathWithin("/Volumes/backup/code/app", "/Volumes/backup"))
+	assert.True(t, pathWithin("/Volumes/backup", "/Volumes/backup/"))
+	assert.False(t, pathWithin("/Volumes/backup2/app", "/Volumes/backup"))
+	assert.False(t, pathWithin("/Volumes/app", "/Volumes/backup"))
+	assert.False(t, pathWithin("/code/backup", "backup"), "relative prefixes never match")
+}
@@ -1,12 +1,15 @@
 package sessions
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/joescharf/pm/internal/models"
 	"github.com/joescharf/wt/pkg/gitops"
 )
 
@@ -15,6 +18,17 @@ import (
 // which expects a single-repo gitops.Client.
 type repoBoundClient struct {
 	repoPath string
+	// ctx, when set, kills the git commands it runs once it is canceled.
+	ctx context.Context
+
+	// Merge lands a branch with this strategy: squash commits it as one
+	// commit with squashMessage, ff-only refuses anything but a fast-forward.
+	// Others run a plain git merge.
+	strategy      models.MergeStrategy
+	squashMessage string
+	// squashConflicts are the files a squash merge conflicted on before it
+	// was undone.
+	squashConflicts []string
 }
 
 // newRepoBoundClient creates a gitops.Client bound to the given repo path.
@@ -22,9 +36,21 @@ func newRepoBoundClient(repoPath string) gitops.Client {
 	return &repoBoundClient{repoPath: repoPath}
 }
 
+// command returns a git command bound to the client's context. Once git is
+// killed, its output is waited for only briefly, as hooks or ssh it started
+// may still hold it open.
+func (c *repoBoundClient) command(args ...string) *exec.Cmd {
+	if c.ctx == nil {
+		return exec.Command("git", args...)
+	}
+	cmd := exec.CommandContext(c.ctx, "git", args...)
+	cmd.WaitDelay = time.Second
+	return cmd
+}
+
 func (c *repoBoundClient) git(args ...string) (string, error) {
 	fullArgs := append([]string{"-C", c.repoPath}, args...)
-	out, err := exec.Command("git", fullArgs...).Output()
+	out, err := c.command(fullArgs...).Output()
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(exitErr.Stderr)))
@@ -36,7 +62,7 @@ func (c *repoBoundClient) git(args ...string) (string, error) {
 
 func (c *repoBoundClient) gitAt(path string, args ...string) (string, error) {
 	fullArgs := append([]string{"-C", path}, args...)
-	out, err := exec.Command("git", fullArgs...).Output()
+	out, err := c.command(fullArgs...).Output()
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(exitErr.Stderr)))
@@ -73,7 +99,7 @@ func (c *repoBoundClient) WorktreeAdd(path, branch, base string, newBranch bool)
 	} else {
 		args = []string{"-C", c.repoPath, "worktree", "add", path, branch}
 	}
-	out, err := exec.Command("git", args...).CombinedOutput()
+	out, err := c.command(args...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("git worktree add failed: %s: %w", strings.TrimSpace(string(out)), err)
 	}
@@ -86,7 +112,7 @@ func (c *repoBoundClient) WorktreeRemove(path string, force bool) error {
 		args = append(args, "--force")
 	}
 	args = append(args, path)
-	out, err := exec.Command("git", args...).CombinedOutput()
+	out, err := c.command(args...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("git worktree remove failed: %s: %w", strings.TrimSpace(string(out)), err)
 	}
@@ -94,7 +120,7 @@ func (c *repoBoundClient) WorktreeRemove(path string, force bool) error {
 }
 
 func (c *repoBoundClient) BranchExists(branch string) (bool, error) {
-	err := exec.Command("git", "-C", c.repoPath, "show-ref", "--verify", "--quiet", "refs/heads/"+branch).Run()
+	err := c.command("-C", c.repoPath, "show-ref", "--verify", "--quiet", "refs/heads/"+branch).Run()
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
 			return false, nil
@@ -161,15 +187,47 @@ func (c *repoBoundClient) WorktreePrune() error {
 }
 
 func (c *repoBoundClient) Merge(repoPath, branch string) error {
-	out, err := exec.Command("git", "-C", repoPath, "merge", branch).CombinedOutput()
+	args := []string{"-C", repoPath, "merge", branch}
+	switch c.strategy {
+	case models.MergeStrategySquash:
+		return c.squashMerge(repoPath, branch)
+	case models.MergeStrategyFFOnly:
+		args = []string{"-C", repoPath, "merge", "--ff-only", branch}
+	}
+	out, err := c.command(args...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("merge failed: %s: %w", strings.TrimSpace(string(out)), err)
 	}
 	return nil
 }
 
+// squashMerge commits branch's changes on the checked-out base branch as one
+// commit. A squash that conflicts is undone so the base is left clean, since
+// git has no squash merge in progress to continue.
+func (c *repoBoundClient) squashMerge(repoPath, branch string) error {
+	out, err := c.command("-C", repoPath, "merge", "--squash", branch).CombinedOutput()
+	if err != nil {
+		if files, _ := c.gitAt(repoPath, "diff", "--name-only", "--diff-filter=U"); files != "" {
+			c.squashConflicts = strings.Split(files, "\n")
+		}
+		_, _ = c.gitAt(repoPath, "reset", "--merge")
+		return fmt.Errorf("squash merge failed: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	// Nothing staged: the base already has the branch's changes
+	if _, err := c.gitAt(repoPath, "diff", "--cached", "--quiet"); err == nil {
+		return nil
+	}
+	cmd := c.command("-C", repoPath, "commit", "-F", "-")
+	cmd.Stdin = strings.NewReader(c.squashMessage)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		_, _ = c.gitAt(repoPath, "reset", "--merge")
+		return fmt.Errorf("squash commit failed: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
 func (c *repoBoundClient) MergeContinue(repoPath string) error {
-	out, err := exec.Command("git", "-C", repoPath, "merge", "--continue").CombinedOutput()
+	out, err := c.command("-C", repoPath, "merge", "--continue").CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("merge --continue failed: %s: %w", strings.TrimSpace(string(out)), err)
 	}
@@ -190,7 +248,7 @@ func (c *repoBoundClient) HasConflicts(repoPath string) (bool, error) {
 }
 
 func (c *repoBoundClient) Rebase(repoPath, branch string) error {
-	out, err := exec.Command("git", "-C", repoPath, "rebase", branch).CombinedOutput()
+	out, err := c.command("-C", repoPath, "rebase", branch).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("rebase failed: %s: %w", strings.TrimSpace(string(out)), err)
 	}
@@ -198,7 +256,7 @@ func (c *repoBoundClient) Rebase(repoPath, branch string) error {
 }
 
 func (c *repoBoundClient) RebaseContinue(repoPath string) error {
-	out, err := exec.Command("git", "-C", repoPath, "rebase", "--continue").CombinedOutput()
+	out, err := c.command("-C", repoPath, "rebase", "--continue").CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("rebase --continue failed: %s: %w", strings.TrimSpace(string(out)), err)
 	}
@@ -206,7 +264,7 @@ func (c *repoBoundClient) RebaseContinue(repoPath string) error {
 }
 
 func (c *repoBoundClient) RebaseAbort(repoPath string) error {
-	out, err := exec.Command("git", "-C", repoPath, "rebase", "--abort").CombinedOutput()
+	out, err := c.command("-C", repoPath, "rebase", "--abort").CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("rebase --abort failed: %s: %w", strings.TrimSpace(string(out)), err)
 	}
@@ -231,7 +289,7 @@ func (c *repoBoundClient) IsRebaseInProgress(repoPath string) (bool, error) {
 }
 
 func (c *repoBoundClient) Pull(repoPath string) error {
-	out, err := exec.Command("git", "-C", repoPath, "pull").CombinedOutput()
+	out, err := c.command("-C", repoPath, "pull").CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("pull failed: %s: %w", strings.TrimSpace(string(out)), err)
 	}
@@ -245,7 +303,7 @@ func (c *repoBoundClient) Push(worktreePath, branch string, setUpstream bool) er
 	} else {
 		args = append(args, "origin", branch)
 	}
-	out, err := exec.Command("git", args...).CombinedOutput()
+	out, err := c.command(args...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("push failed: %s: %w", strings.TrimSpace(string(out)), err)
 	}
@@ -261,7 +319,7 @@ func (c *repoBoundClient) HasRemote() (bool, error) {
 }
 
 func (c *repoBoundClient) Fetch(repoPath string) error {
-	out, err := exec.Command("git", "-C", repoPath, "fetch").CombinedOutput()
+	out, err := c.command("-C", repoPath, "fetch").CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("fetch failed: %s: %w", strings.TrimSpace(string(out)), err)
 	}
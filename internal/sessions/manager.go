@@ -3,27 +3,81 @@ package sessions
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"time"
 
+	"github.com/joescharf/pm/internal/agent"
+	"github.com/joescharf/pm/internal/autoclose"
+	"github.com/joescharf/pm/internal/commitmsg"
+	"github.com/joescharf/pm/internal/git"
 	"github.com/joescharf/pm/internal/models"
 	"github.com/joescharf/pm/internal/store"
+	"github.com/joescharf/pm/internal/terminal"
 	pmwt "github.com/joescharf/pm/internal/wt"
-	"github.com/joescharf/wt/pkg/lifecycle"
 	"github.com/joescharf/wt/pkg/ops"
 )
 
 // Manager orchestrates wt ops with pm's session store.
 type Manager struct {
-	store store.Store
-	wt    pmwt.Client
+	store     store.Store
+	git       git.Client
+	wt        pmwt.Client
+	terminals func(backend string) (terminal.Terminal, error)
+	autoClose autoclose.Config
+	ops       *Operations
+}
+
+// CommitLister lists a branch's commits with their full messages, for
+// closing the issues they reference. *git.RealClient satisfies this
+// interface; merges through a git client that does not close no issues.
+type CommitLister interface {
+	CommitsSince(ctx context.Context, path, base string) ([]git.Commit, error)
 }
 
 // NewManager creates a new sessions manager.
 // The wt client may be nil (worktree lifecycle operations will be skipped).
 func NewManager(s store.Store, wtc pmwt.Client) *Manager {
-	return &Manager{store: s, wt: wtc}
+	return &Manager{store: s, git: git.NewClient(), wt: wtc, terminals: terminal.New, autoClose: autoclose.Config{Enabled: true}, ops: NewOperations()}
+}
+
+// SetGitClient sets the git client the manager reads commits and repo
+// layouts with, instead of the default CLI-backed one.
+func (m *Manager) SetGitClient(gc git.Client) {
+	m.git = gc
+}
+
+// SetOperations makes the manager track its syncs and merges in ops, so
+// that managers sharing it see and can cancel each other's operations.
+func (m *Manager) SetOperations(ops *Operations) {
+	m.ops = ops
+}
+
+// Operations returns the registry tracking the manager's syncs and merges.
+func (m *Manager) Operations() *Operations {
+	return m.ops
+}
+
+// SetAutoClose configures how issues referenced by merged session commits
+// ("Fixes #12", "Closes PM-01J5ABCD") are closed. Enabled by default, without
+// closing GitHub issues.
+func (m *Manager) SetAutoClose(cfg autoclose.Config) {
+	m.autoClose = cfg
+}
+
+// closeTerminal closes the terminal recorded on the session (best-effort) and
+// clears it. The caller persists the session.
+func (m *Manager) closeTerminal(session *models.AgentSession) {
+	if session.TerminalID == "" {
+		return
+	}
+	if t, err := m.terminals(session.TerminalBackend); err == nil {
+		_ = t.Close(session.TerminalID)
+	}
+	session.TerminalBackend = ""
+	session.TerminalID = ""
 }
 
 // SyncOptions configures a session sync operation.
@@ -48,26 +102,49 @@ type SyncResult struct {
 // MergeOptions configures a session merge operation.
 type MergeOptions struct {
 	BaseBranch string
-	Rebase     bool
-	CreatePR   bool
-	PRTitle    string
-	PRBody     string
-	PRDraft    bool
-	Force      bool
-	DryRun     bool
-	Cleanup    bool
+	// Strategy picks how the branch lands; empty uses the project's default
+	// (merge unless set). Rebase is shorthand for the rebase strategy.
+	Strategy models.MergeStrategy
+	Rebase   bool
+	CreatePR bool
+	PRTitle  string
+	PRBody   string
+	PRDraft  bool
+	Force    bool
+	DryRun   bool
+	Cleanup  bool
+	// DeleteRemote also deletes the session branch's upstream on its remote
+	// during cleanup
+	DeleteRemote bool
 }
 
 // MergeResult holds the result of merging a session's worktree.
 type MergeResult struct {
 	SessionID string
 	Branch    string
+	Strategy  models.MergeStrategy
 	Success   bool
-	PRCreated bool
-	PRURL     string
-	Conflicts []string
-	Error     string
-	Cleaned   bool
+	// MergeCommit is the base branch's tip after a local merge: the merge or
+	// squash commit, or the fast-forwarded branch tip.
+	MergeCommit string
+	PRCreated   bool
+	PRURL       string
+	Conflicts   []string
+	Error       string
+	Cleaned     bool
+	// Cleanup reports what the post-merge cleanup removed and kept
+	Cleanup *CleanupResult
+	// Closures are the issues closed by references in the merged commits
+	Closures []*models.IssueClosure
+}
+
+// conflictPaths returns conflicting files from the repository root, however
+// git reported them, so they match the paths of reviews and diffs.
+func (m *Manager) conflictPaths(ctx context.Context, project *models.Project, session *models.AgentSession, files []string) []string {
+	if len(files) == 0 {
+		return files
+	}
+	return git.ResolveRepoPaths(ctx, m.git, project.Path, session.WorktreePath).NormalizeAll(files)
 }
 
 // SyncSession syncs a session's worktree with the base branch.
@@ -77,6 +154,9 @@ func (m *Manager) SyncSession(ctx context.Context, sessionID string, opts SyncOp
 		return nil, fmt.Errorf("get session: %w", err)
 	}
 
+	if session.Inline {
+		return nil, fmt.Errorf("session %s: %w", sessionID, ErrInlineSession)
+	}
 	if session.WorktreePath == "" {
 		return nil, fmt.Errorf("session %s has no worktree path", sessionID)
 	}
@@ -90,9 +170,6 @@ func (m *Manager) SyncSession(ctx context.Context, sessionID string, opts SyncOp
 		return nil, fmt.Errorf("get project: %w", err)
 	}
 
-	// Create gitops client bound to the project's repo
-	gitClient := newRepoBoundClient(project.Path)
-
 	strategy := "merge"
 	if opts.Rebase {
 		strategy = "rebase"
@@ -106,7 +183,20 @@ func (m *Manager) SyncSession(ctx context.Context, sessionID string, opts SyncOp
 	}
 
 	logger := &nopLogger{}
-	syncResult, err := ops.Sync(ctx, gitClient, nil, logger, session.WorktreePath, syncOpts)
+	var syncResult *ops.SyncResult
+	err = m.ops.run(ctx, sessionID, OperationSync, []string{session.WorktreePath}, func(ctx context.Context) error {
+		// Create gitops client bound to the project's repo
+		gitClient := &repoBoundClient{repoPath: project.Path, ctx: ctx}
+		var err error
+		syncResult, err = ops.Sync(ctx, gitClient, nil, logger, session.WorktreePath, syncOpts)
+		return err
+	})
+	if errors.Is(err, ErrOperationInProgress) || errors.Is(err, ErrOperationCanceled) {
+		return nil, err
+	}
+	if syncResult != nil {
+		syncResult.ConflictFiles = m.conflictPaths(ctx, project, session, syncResult.ConflictFiles)
+	}
 
 	result := &SyncResult{
 		SessionID: sessionID,
@@ -157,13 +247,30 @@ func (m *Manager) SyncSession(ctx context.Context, sessionID string, opts SyncOp
 	return result, nil
 }
 
-// MergeSession merges a session's worktree branch into the base branch.
+// ErrInlineSession is returned by operations that need a worktree when the
+// session works inline in the project repo.
+var ErrInlineSession = errors.New("inline session has no worktree")
+
+// ErrCommitConventions is returned by MergeSession when the branch has commits
+// that break the project's commit conventions.
+var ErrCommitConventions = errors.New("commit conventions not met")
+
+// ErrNotFastForward is returned by an ff-only MergeSession when the branch
+// does not contain the base branch's tip.
+var ErrNotFastForward = errors.New("branch cannot be fast-forwarded")
+
+// MergeSession merges a session's worktree branch into the base branch with
+// the requested strategy or the project's default. Unless forced, it refuses
+// branches whose commits break the project's commit conventions.
 func (m *Manager) MergeSession(ctx context.Context, sessionID string, opts MergeOptions) (*MergeResult, error) {
 	session, err := m.store.GetAgentSession(ctx, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("get session: %w", err)
 	}
 
+	if session.Inline {
+		return nil, fmt.Errorf("session %s: %w", sessionID, ErrInlineSession)
+	}
 	if session.WorktreePath == "" {
 		return nil, fmt.Errorf("session %s has no worktree path", sessionID)
 	}
@@ -177,21 +284,67 @@ func (m *Manager) MergeSession(ctx context.Context, sessionID string, opts Merge
 		return nil, fmt.Errorf("get project: %w", err)
 	}
 
-	gitClient := newRepoBoundClient(project.Path)
-
 	baseBranch := opts.BaseBranch
 	if baseBranch == "" {
 		baseBranch = "main"
 	}
 
-	strategy := "merge"
-	if opts.Rebase {
-		strategy = "rebase"
+	strategy := opts.Strategy
+	if strategy == "" && opts.Rebase {
+		strategy = models.MergeStrategyRebase
+	}
+	if strategy == "" {
+		strategy = project.MergeStrategy
+	}
+	if strategy == "" {
+		strategy = models.MergeStrategyMerge
+	}
+	if _, err := ParseMergeStrategy(string(strategy)); err != nil {
+		return nil, err
+	}
+
+	// Refuse to merge commits that break the project's commit conventions
+	conv := commitmsg.ForProject(project)
+	msgs, msgsErr := m.git.CommitMessagesSince(ctx, session.WorktreePath, baseBranch)
+	if conv.Enabled() && !opts.Force && msgsErr == nil {
+		if vs := conv.CheckAll(msgs); len(vs) > 0 {
+			return nil, fmt.Errorf("%w: %s (reword them or merge with force)", ErrCommitConventions, commitmsg.Summarize(vs))
+		}
+	}
+
+	// Collect the branch's commits before the merge moves them onto the base
+	var closeSources []autoclose.Source
+	if m.autoClose.Enabled && !opts.CreatePR {
+		var commits []git.Commit
+		if cl, ok := m.git.(CommitLister); ok {
+			commits, _ = cl.CommitsSince(ctx, session.WorktreePath, baseBranch)
+		}
+		for _, c := range commits {
+			closeSources = append(closeSources, autoclose.Source{
+				Kind:      models.ClosureSourceCommit,
+				Ref:       c.Hash,
+				Message:   c.Message,
+				SessionID: sessionID,
+			})
+		}
+	}
+
+	gitClient := &repoBoundClient{repoPath: project.Path, strategy: strategy}
+	if strategy == models.MergeStrategySquash {
+		slices.Reverse(msgs) // oldest first
+		gitClient.squashMessage = SquashMessage(conv, session.Branch, m.branchIssues(ctx, session), msgs)
+	}
+
+	// wt's merge only knows merge and rebase; squash and ff-only are merges
+	// that the client carries out its own way.
+	opsStrategy := "merge"
+	if strategy == models.MergeStrategyRebase {
+		opsStrategy = "rebase"
 	}
 
 	mergeOpts := ops.MergeOptions{
 		BaseBranch: baseBranch,
-		Strategy:   strategy,
+		Strategy:   opsStrategy,
 		Force:      opts.Force,
 		DryRun:     opts.DryRun,
 		CreatePR:   opts.CreatePR,
@@ -201,17 +354,46 @@ func (m *Manager) MergeSession(ctx context.Context, sessionID string, opts Merge
 	}
 
 	logger := &nopLogger{}
-	mergeResult, err := ops.Merge(ctx, gitClient, nil, logger, session.WorktreePath, mergeOpts, nil)
+	var mergeResult *ops.MergeResult
+	err = m.ops.run(ctx, sessionID, OperationMerge, []string{session.WorktreePath, project.Path}, func(ctx context.Context) error {
+		gitClient.ctx = ctx
+		defer func() { gitClient.ctx = nil }()
+		var err error
+		mergeResult, err = ops.Merge(ctx, gitClient, nil, logger, session.WorktreePath, mergeOpts, nil)
+		return err
+	})
+	if errors.Is(err, ErrOperationInProgress) || errors.Is(err, ErrOperationCanceled) {
+		return nil, err
+	}
 
 	result := &MergeResult{
 		SessionID: sessionID,
 		Branch:    session.Branch,
+		Strategy:  strategy,
+	}
+
+	// wt reports every failed merge as a conflict. A refused fast-forward is
+	// not one, and a squash conflict was undone, so its files come from the client.
+	if mergeResult != nil && mergeResult.HasConflicts {
+		switch {
+		case strategy == models.MergeStrategyFFOnly:
+			mergeResult.HasConflicts = false
+			err = fmt.Errorf("%w: %s is behind %s; sync it with rebase, then merge again", ErrNotFastForward, session.Branch, baseBranch)
+			mergeResult.Error = err
+		case strategy == models.MergeStrategySquash:
+			mergeResult.ConflictFiles = gitClient.squashConflicts
+			mergeResult.Error = fmt.Errorf("squash merge conflicts with %s; sync the session, then merge again: %w", baseBranch, mergeResult.Error)
+		}
+		mergeResult.ConflictFiles = m.conflictPaths(ctx, project, session, mergeResult.ConflictFiles)
 	}
 
 	if mergeResult != nil {
 		result.Success = mergeResult.Success
 		result.PRCreated = mergeResult.PRCreated
 		result.PRURL = mergeResult.PRURL
+		if result.Success && !result.PRCreated && !opts.DryRun {
+			result.MergeCommit, _ = gitClient.git("rev-parse", baseBranch)
+		}
 
 		if mergeResult.HasConflicts {
 			result.Conflicts = mergeResult.ConflictFiles
@@ -239,23 +421,31 @@ func (m *Manager) MergeSession(ctx context.Context, sessionID string, opts Merge
 
 			if mergeResult != nil && mergeResult.Success {
 				session.LastError = ""
+				session.MergeCommit = result.MergeCommit
+				// Stamp the branch's issues with where their code landed (best-effort)
+				if result.MergeCommit != "" {
+					mergedAt := time.Now().UTC()
+					for _, issue := range m.branchIssues(ctx, session) {
+						_ = m.store.RecordIssueMerge(ctx, issue.ID, result.MergeCommit, baseBranch, mergedAt)
+					}
+				}
 				// Mark session as completed on successful merge
-				now := time.Now().UTC()
-				session.Status = models.SessionStatusCompleted
-				session.EndedAt = &now
-				// Cascade issue status
-				if session.IssueID != "" {
-					issue, issErr := m.store.GetIssue(ctx, session.IssueID)
-					if issErr == nil && issue.Status == models.IssueStatusInProgress {
-						issue.Status = models.IssueStatusDone
-						_ = m.store.UpdateIssue(ctx, issue)
+				if agent.Transition(ctx, m.store, session, models.SessionStatusCompleted, agent.CauseMerge) == nil {
+					// Cascade issue status
+					if session.IssueID != "" {
+						if issue, issErr := m.store.GetIssue(ctx, session.IssueID); issErr == nil {
+							_, _ = agent.CascadeIssue(ctx, m.store, issue, models.CascadeCompleted)
+						}
 					}
 				}
+				// Close issues the merged commits reference (best-effort)
+				result.Closures, _ = autoclose.Apply(ctx, m.store, m.autoClose, project, closeSources, false)
 			} else if err != nil {
 				session.LastError = err.Error()
 			}
 		}
-		_ = m.store.UpdateAgentSession(ctx, session)
+		// Guarded on the status, so a concurrent close or reconcile is not undone
+		_ = agent.Transition(ctx, m.store, session, session.Status, agent.CauseMerge)
 	}
 
 	if err != nil && (mergeResult == nil || !mergeResult.HasConflicts) {
@@ -265,11 +455,13 @@ func (m *Manager) MergeSession(ctx context.Context, sessionID string, opts Merge
 	// Post-merge cleanup: close iTerm + remove worktree + untrust + cleanup state via lifecycle
 	if result.Success && !opts.CreatePR && opts.Cleanup && !opts.DryRun && session.WorktreePath != "" {
 		if m.wt != nil {
-			lm := m.wt.LifecycleForRepo(project.Path)
-			if delErr := lm.Delete(ctx, session.WorktreePath, lifecycle.DeleteOptions{
+			cleanup, delErr := m.cleanupWorktree(ctx, project, session, CleanupOptions{
 				Force:        true,
 				DeleteBranch: true,
-			}); delErr == nil {
+				DeleteRemote: opts.DeleteRemote,
+			})
+			result.Cleanup = cleanup
+			if delErr == nil {
 				session.WorktreePath = ""
 				_ = m.store.UpdateAgentSession(ctx, session)
 				result.Cleaned = true
@@ -280,61 +472,58 @@ func (m *Manager) MergeSession(ctx context.Context, sessionID string, opts Merge
 	return result, nil
 }
 
-// DeleteWorktree removes a session's worktree via lifecycle (close iTerm + remove git worktree + untrust + cleanup state).
-func (m *Manager) DeleteWorktree(ctx context.Context, sessionID string, force bool) error {
+// DeleteWorktree removes a session's worktree via lifecycle (close iTerm + remove git worktree + untrust + cleanup state)
+// and deletes its local and remote branches as opts asks, reporting what was and wasn't deleted.
+func (m *Manager) DeleteWorktree(ctx context.Context, sessionID string, opts CleanupOptions) (*CleanupResult, error) {
 	session, err := m.store.GetAgentSession(ctx, sessionID)
 	if err != nil {
-		return fmt.Errorf("get session: %w", err)
+		return nil, fmt.Errorf("get session: %w", err)
 	}
 
+	if session.Inline {
+		return nil, fmt.Errorf("session %s: %w", sessionID, ErrInlineSession)
+	}
 	if session.WorktreePath == "" {
-		return fmt.Errorf("session %s has no worktree path", sessionID)
+		return nil, fmt.Errorf("session %s has no worktree path", sessionID)
 	}
 
+	result := &CleanupResult{Branch: session.Branch}
 	if m.wt != nil {
 		project, projErr := m.store.GetProject(ctx, session.ProjectID)
 		if projErr != nil {
-			return fmt.Errorf("get project: %w", projErr)
+			return nil, fmt.Errorf("get project: %w", projErr)
 		}
-		lm := m.wt.LifecycleForRepo(project.Path)
-		if err := lm.Delete(ctx, session.WorktreePath, lifecycle.DeleteOptions{
-			Force: force,
-		}); err != nil {
-			return fmt.Errorf("delete worktree: %w", err)
+		if result, err = m.cleanupWorktree(ctx, project, session, opts); err != nil {
+			return result, err
 		}
 	}
 
 	// Update session
-	now := time.Now().UTC()
-	session.Status = models.SessionStatusAbandoned
-	session.EndedAt = &now
 	session.WorktreePath = ""
-
-	if err := m.store.UpdateAgentSession(ctx, session); err != nil {
-		return fmt.Errorf("update session: %w", err)
+	if err := agent.Transition(ctx, m.store, session, models.SessionStatusAbandoned, agent.CauseWorktreeDeleted); err != nil {
+		return result, fmt.Errorf("update session: %w", err)
 	}
 
 	// Cascade issue status
 	if session.IssueID != "" {
-		issue, issErr := m.store.GetIssue(ctx, session.IssueID)
-		if issErr == nil && issue.Status == models.IssueStatusInProgress {
-			issue.Status = models.IssueStatusOpen
-			_ = m.store.UpdateIssue(ctx, issue)
+		if issue, issErr := m.store.GetIssue(ctx, session.IssueID); issErr == nil {
+			_, _ = agent.CascadeIssue(ctx, m.store, issue, models.CascadeAbandoned)
 		}
 	}
 
-	return nil
+	return result, nil
 }
 
 // DiscoverWorktrees scans a project's git repo for worktrees not tracked by pm.
-// Returns newly created session records for discovered worktrees.
+// Returns newly created session records for discovered worktrees. Its git
+// commands are killed once ctx is done.
 func (m *Manager) DiscoverWorktrees(ctx context.Context, projectID string) ([]*models.AgentSession, error) {
 	project, err := m.store.GetProject(ctx, projectID)
 	if err != nil {
 		return nil, fmt.Errorf("get project: %w", err)
 	}
 
-	gitClient := newRepoBoundClient(project.Path)
+	gitClient := &repoBoundClient{repoPath: project.Path, ctx: ctx}
 
 	worktrees, err := gitClient.WorktreeList()
 	if err != nil {
@@ -369,7 +558,7 @@ func (m *Manager) DiscoverWorktrees(ctx context.Context, projectID string) ([]*m
 
 	knownPaths := make(map[string]bool)
 	for _, s := range existingSessions {
-		if s.Status == models.SessionStatusActive || s.Status == models.SessionStatusIdle {
+		if s.Status.IsOpen() {
 			knownPaths[s.WorktreePath] = true
 		}
 	}
@@ -424,7 +613,7 @@ func (m *Manager) Reconcile(ctx context.Context) (int, error) {
 		}
 
 		for _, sess := range sessions {
-			if sess.Status == models.SessionStatusCompleted {
+			if sess.Status == models.SessionStatusCompleted || sess.Inline {
 				continue
 			}
 			if sess.WorktreePath == "" {
@@ -436,25 +625,19 @@ func (m *Manager) Reconcile(ctx context.Context) (int, error) {
 				wtExists = false
 			}
 
-			updated := false
+			var err error
 			switch {
-			case !wtExists && (sess.Status == models.SessionStatusActive || sess.Status == models.SessionStatusIdle):
-				now := time.Now().UTC()
-				sess.Status = models.SessionStatusAbandoned
-				sess.EndedAt = &now
-				updated = true
+			case !wtExists && sess.Status.IsOpen():
+				err = agent.Transition(ctx, m.store, sess, models.SessionStatusAbandoned, agent.CauseReconcile)
 			case wtExists && sess.Status == models.SessionStatusAbandoned:
 				now := time.Now().UTC()
 				sess.LastActiveAt = &now
-				sess.Status = models.SessionStatusIdle
-				sess.EndedAt = nil
-				updated = true
+				err = agent.Transition(ctx, m.store, sess, models.SessionStatusIdle, agent.CauseReconcile)
+			default:
+				continue
 			}
-
-			if updated {
-				if err := m.store.UpdateAgentSession(ctx, sess); err == nil {
-					totalUpdated++
-				}
+			if err == nil {
+				totalUpdated++
 			}
 		}
 	}
@@ -470,3 +653,26 @@ func (l *nopLogger) Success(format string, args ...interface{}) {}
 func (l *nopLogger) Warning(format string, args ...interface{}) {}
 func (l *nopLogger) Error(format string, args ...interface{})   {}
 func (l *nopLogger) Verbose(format string, args ...interface{}) {}
+
+// branchIssues returns the issues worked on the session's branch, the
+// session's own issue first.
+func (m *Manager) branchIssues(ctx context.Context, session *models.AgentSession) []*models.Issue {
+	ids := []string{}
+	if session.IssueID != "" {
+		ids = append(ids, session.IssueID)
+	}
+	if all, err := m.store.ListAgentSessionsFiltered(ctx, store.SessionListFilter{ProjectID: session.ProjectID, Branch: session.Branch}); err == nil {
+		for _, s := range all {
+			if s.IssueID != "" && !slices.Contains(ids, s.IssueID) {
+				ids = append(ids, s.IssueID)
+			}
+		}
+	}
+	var issues []*models.Issue
+	for _, id := range ids {
+		if issue, err := m.store.GetIssue(ctx, id); err == nil {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
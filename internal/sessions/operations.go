@@ -0,0 +1,311 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// Syncs and merges run git in a session's worktree and its project's
+// repository, and a long rebase or a merge stuck on a hook can hang. Each runs
+// as an Operation that can be canceled from another request: canceling kills
+// its git process and restores the snapshot of the checkouts taken before it
+// started. Operations live in memory, so only those run by this process can
+// be listed and canceled.
+//
+// A merge also works in the project's checkout, which all its sessions share.
+// An operation therefore holds every checkout it uses, so no two operations
+// touch one at a time and a cancel restores nothing another has since done.
+
+// OperationKind is what an operation does.
+type OperationKind string
+
+const (
+	OperationSync  OperationKind = "sync"
+	OperationMerge OperationKind = "merge"
+)
+
+// OperationState is where an operation is in its run.
+type OperationState string
+
+const (
+	OperationRunning   OperationState = "running"
+	OperationSucceeded OperationState = "succeeded"
+	OperationFailed    OperationState = "failed"
+	OperationCanceled  OperationState = "canceled"
+)
+
+var (
+	// ErrOperationInProgress is returned when a sync or merge is started on a
+	// session that already has one running, or on a checkout another
+	// session's operation is using.
+	ErrOperationInProgress = errors.New("another operation is running on the session or its checkouts")
+	// ErrOperationCanceled is returned by a sync or merge that was canceled.
+	ErrOperationCanceled = errors.New("operation canceled")
+	// ErrOperationNotFound is returned for an unknown operation ID.
+	ErrOperationNotFound = errors.New("operation not found")
+	// ErrOperationFinished is returned when canceling an operation that has
+	// already ended.
+	ErrOperationFinished = errors.New("operation already finished")
+)
+
+// maxOperationHistory is how many finished operations are kept per session.
+const maxOperationHistory = 10
+
+// Operation is a sync or merge of a session, running or recently finished.
+type Operation struct {
+	ID        string
+	SessionID string
+	Kind      OperationKind
+	State     OperationState
+	StartedAt time.Time
+	EndedAt   *time.Time
+	Error     string
+	// RestoreError says what could not be put back after a cancel; empty
+	// when the snapshot was restored.
+	RestoreError string
+
+	cancel   context.CancelFunc
+	canceled bool
+	done     chan struct{}
+}
+
+// Operations tracks the sync and merge operations of sessions.
+type Operations struct {
+	mu        sync.Mutex
+	running   map[string]*Operation   // by session ID
+	history   map[string][]*Operation // by session ID, newest first
+	checkouts map[string]string       // session ID of the operation using each checkout path
+}
+
+// NewOperations returns an empty operation registry.
+func NewOperations() *Operations {
+	return &Operations{
+		running:   make(map[string]*Operation),
+		history:   make(map[string][]*Operation),
+		checkouts: make(map[string]string),
+	}
+}
+
+// List returns the session's running operation, if any, and then its recently
+// finished ones, newest first.
+func (o *Operations) List(sessionID string) []Operation {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	out := []Operation{}
+	if op := o.running[sessionID]; op != nil {
+		out = append(out, *op)
+	}
+	for _, op := range o.history[sessionID] {
+		out = append(out, *op)
+	}
+	return out
+}
+
+// Cancel cancels a running operation of the session and waits until its git
+// process is gone and the checkouts are restored, or ctx is done.
+func (o *Operations) Cancel(ctx context.Context, sessionID, opID string) (Operation, error) {
+	o.mu.Lock()
+	op := o.running[sessionID]
+	if op == nil || op.ID != opID {
+		defer o.mu.Unlock()
+		for _, done := range o.history[sessionID] {
+			if done.ID == opID {
+				return *done, ErrOperationFinished
+			}
+		}
+		return Operation{}, ErrOperationNotFound
+	}
+	op.canceled = true
+	op.cancel()
+	o.mu.Unlock()
+
+	select {
+	case <-op.done:
+	case <-ctx.Done():
+		return Operation{}, ctx.Err()
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return *op, nil
+}
+
+// run runs fn as an operation of kind on the session after taking the
+// checkouts at paths and snapshotting them. fn's context is canceled by
+// Cancel, in which case the snapshot is restored and ErrOperationCanceled
+// returned; otherwise run returns fn's error.
+func (o *Operations) run(ctx context.Context, sessionID string, kind OperationKind, paths []string, fn func(ctx context.Context) error) error {
+	cleaned := make([]string, len(paths))
+	for i, path := range paths {
+		cleaned[i] = filepath.Clean(path)
+	}
+	paths = cleaned
+
+	// Only Cancel stops the operation, not the caller going away mid-merge.
+	opCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+	op := &Operation{
+		ID:        ulid.Make().String(),
+		SessionID: sessionID,
+		Kind:      kind,
+		State:     OperationRunning,
+		StartedAt: time.Now().UTC(),
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	o.mu.Lock()
+	if o.running[sessionID] != nil {
+		o.mu.Unlock()
+		return fmt.Errorf("session %s: %w", sessionID, ErrOperationInProgress)
+	}
+	for _, path := range paths {
+		if holder, ok := o.checkouts[path]; ok {
+			o.mu.Unlock()
+			return fmt.Errorf("%s is in use by session %s: %w", path, holder, ErrOperationInProgress)
+		}
+	}
+	o.running[sessionID] = op
+	for _, path := range paths {
+		o.checkouts[path] = sessionID
+	}
+	o.mu.Unlock()
+
+	snapshots, err := takeSnapshots(paths)
+	if err != nil {
+		o.mu.Lock()
+		o.release(sessionID, paths)
+		o.mu.Unlock()
+		close(op.done)
+		return fmt.Errorf("snapshot before %s: %w", kind, err)
+	}
+
+	err = fn(opCtx)
+
+	o.mu.Lock()
+	canceled := op.canceled
+	o.mu.Unlock()
+	var restoreErrs []string
+	if canceled {
+		for _, snap := range snapshots {
+			if rerr := snap.restore(); rerr != nil {
+				restoreErrs = append(restoreErrs, rerr.Error())
+			}
+		}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := time.Now().UTC()
+	op.EndedAt = &now
+	switch {
+	case canceled:
+		op.State = OperationCanceled
+		op.RestoreError = strings.Join(restoreErrs, "; ")
+		err = ErrOperationCanceled
+		if op.RestoreError != "" {
+			err = fmt.Errorf("%w; restoring failed: %s", ErrOperationCanceled, op.RestoreError)
+		}
+	case err != nil:
+		op.State = OperationFailed
+		op.Error = err.Error()
+	default:
+		op.State = OperationSucceeded
+	}
+	o.release(sessionID, paths)
+	history := append([]*Operation{op}, o.history[sessionID]...)
+	if len(history) > maxOperationHistory {
+		history = history[:maxOperationHistory]
+	}
+	o.history[sessionID] = history
+	close(op.done)
+	return err
+}
+
+// release ends the session's hold on its running operation and the checkouts
+// at paths. o.mu must be held.
+func (o *Operations) release(sessionID string, paths []string) {
+	delete(o.running, sessionID)
+	for _, path := range paths {
+		delete(o.checkouts, path)
+	}
+}
+
+// checkoutSnapshot is the state of a checkout before an operation: its
+// branch, HEAD and, when it had uncommitted changes, a stash commit holding
+// them.
+type checkoutSnapshot struct {
+	path   string
+	branch string // "HEAD" when detached
+	head   string
+	stash  string
+}
+
+func takeSnapshots(paths []string) ([]checkoutSnapshot, error) {
+	var snapshots []checkoutSnapshot
+	for _, path := range paths {
+		c := &repoBoundClient{repoPath: path}
+		snap := checkoutSnapshot{path: path}
+		var err error
+		if snap.head, err = c.git("rev-parse", "HEAD"); err != nil {
+			return nil, err
+		}
+		if snap.branch, err = c.git("rev-parse", "--abbrev-ref", "HEAD"); err != nil {
+			return nil, err
+		}
+		// Records tracked changes as a commit without touching the checkout.
+		if snap.stash, err = c.git("stash", "create"); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snap)
+	}
+	return snapshots, nil
+}
+
+// restore puts the checkout back as it was, after the operation's git was
+// killed: it drops the index lock git left behind, stops any merge or rebase
+// in progress, resets the branch to its old HEAD and reapplies uncommitted
+// changes. Untracked files are left alone throughout.
+func (s checkoutSnapshot) restore() error {
+	c := &repoBoundClient{repoPath: s.path}
+	gitDir, err := c.git("rev-parse", "--git-dir")
+	if err != nil {
+		return fmt.Errorf("restore %s: %w", s.path, err)
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(s.path, gitDir)
+	}
+	if err := os.Remove(filepath.Join(gitDir, "index.lock")); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("restore %s: %w", s.path, err)
+	}
+
+	if merging, _ := c.IsMergeInProgress(s.path); merging {
+		_, _ = c.git("merge", "--abort")
+	}
+	if rebasing, _ := c.IsRebaseInProgress(s.path); rebasing {
+		if err := c.RebaseAbort(s.path); err != nil {
+			_, _ = c.git("rebase", "--quit")
+		}
+	}
+
+	if s.branch != "HEAD" {
+		if _, err := c.git("checkout", "-f", s.branch); err != nil {
+			return fmt.Errorf("restore %s: %w", s.path, err)
+		}
+	}
+	if _, err := c.git("reset", "--hard", s.head); err != nil {
+		return fmt.Errorf("restore %s: %w", s.path, err)
+	}
+	if s.stash != "" {
+		if _, err := c.git("stash", "apply", "--index", s.stash); err != nil {
+			return fmt.Errorf("restore %s: uncommitted changes are in stash commit %s: %w", s.path, s.stash, err)
+		}
+	}
+	return nil
+}
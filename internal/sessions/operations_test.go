@@ -0,0 +1,161 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gitRun(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, out)
+	return strings.TrimSpace(string(out))
+}
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	gitRun(t, dir, "init", "-q", "-b", "main")
+	gitRun(t, dir, "config", "user.email", "test@test.com")
+	gitRun(t, dir, "config", "user.name", "Test")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("base\n"), 0o644))
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+func TestCheckoutSnapshot_Restore(t *testing.T) {
+	dir := initRepo(t)
+	gitRun(t, dir, "checkout", "-q", "-b", "feature")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("feature\n"), 0o644))
+	gitRun(t, dir, "commit", "-q", "-am", "feature")
+	gitRun(t, dir, "checkout", "-q", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("main\n"), 0o644))
+	gitRun(t, dir, "commit", "-q", "-am", "main")
+	head := gitRun(t, dir, "rev-parse", "HEAD")
+
+	// Uncommitted work, then an interrupted merge that conflicts and a lock
+	// left by the killed git.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b\n"), 0o644))
+	gitRun(t, dir, "add", "b.txt")
+	snapshots, err := takeSnapshots([]string{dir})
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.NotEmpty(t, snapshots[0].stash)
+
+	gitRun(t, dir, "commit", "-q", "-m", "b")
+	assert.Error(t, exec.Command("git", "-C", dir, "merge", "feature").Run())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "index.lock"), nil, 0o644))
+
+	require.NoError(t, snapshots[0].restore())
+	assert.Equal(t, head, gitRun(t, dir, "rev-parse", "HEAD"))
+	assert.Equal(t, "main", gitRun(t, dir, "rev-parse", "--abbrev-ref", "HEAD"))
+	assert.NoFileExists(t, filepath.Join(dir, ".git", "index.lock"))
+	assert.NoFileExists(t, filepath.Join(dir, ".git", "MERGE_HEAD"))
+	assert.Equal(t, "A  b.txt", gitRun(t, dir, "status", "--porcelain"), "the staged change is back")
+}
+
+func TestOperations_Cancel(t *testing.T) {
+	dir := initRepo(t)
+	ops := NewOperations()
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	result := make(chan error)
+	go func() {
+		result <- ops.run(ctx, "s1", OperationSync, []string{dir}, func(ctx context.Context) error {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("half done\n"), 0o644))
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+	<-started
+
+	list := ops.List("s1")
+	require.Len(t, list, 1)
+	op := list[0]
+	assert.Equal(t, OperationRunning, op.State)
+	assert.Equal(t, OperationSync, op.Kind)
+
+	err := ops.run(ctx, "s1", OperationMerge, nil, func(context.Context) error { return nil })
+	assert.ErrorIs(t, err, ErrOperationInProgress)
+	_, err = ops.Cancel(ctx, "s1", "unknown")
+	assert.ErrorIs(t, err, ErrOperationNotFound)
+
+	canceled, err := ops.Cancel(ctx, "s1", op.ID)
+	require.NoError(t, err)
+	assert.Equal(t, OperationCanceled, canceled.State)
+	assert.NotNil(t, canceled.EndedAt)
+	assert.Empty(t, canceled.RestoreError)
+	assert.ErrorIs(t, <-result, ErrOperationCanceled)
+
+	content, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "base\n", string(content), "the checkout is restored")
+
+	_, err = ops.Cancel(ctx, "s1", op.ID)
+	assert.ErrorIs(t, err, ErrOperationFinished)
+
+	require.NoError(t, ops.run(ctx, "s1", OperationMerge, nil, func(context.Context) error { return nil }))
+	require.Error(t, ops.run(ctx, "s1", OperationSync, nil, func(context.Context) error { return errors.New("boom") }))
+	list = ops.List("s1")
+	require.Len(t, list, 3)
+	assert.Equal(t, []OperationState{OperationFailed, OperationSucceeded, OperationCanceled},
+		[]OperationState{list[0].State, list[1].State, list[2].State}, "newest first")
+	assert.Equal(t, "boom", list[0].Error)
+}
+
+func TestOperations_SharedCheckout(t *testing.T) {
+	repo := initRepo(t)
+	ops := NewOperations()
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	result := make(chan error)
+	go func() {
+		result <- ops.run(ctx, "s1", OperationMerge, []string{initRepo(t), repo}, func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	// Another session's merge into the same repository waits its turn
+	err := ops.run(ctx, "s2", OperationMerge, []string{repo + "/"}, func(context.Context) error { return nil })
+	assert.ErrorIs(t, err, ErrOperationInProgress)
+	assert.ErrorContains(t, err, "in use by session s1")
+	assert.Empty(t, ops.List("s2"))
+
+	close(release)
+	require.NoError(t, <-result)
+	require.NoError(t, ops.run(ctx, "s2", OperationMerge, []string{repo}, func(context.Context) error { return nil }))
+}
+
+func TestRepoBoundClient_KilledOnCancel(t *testing.T) {
+	dir := initRepo(t)
+	hook := filepath.Join(dir, ".git", "hooks", "pre-rebase")
+	require.NoError(t, os.WriteFile(hook, []byte("#!/bin/sh\nsleep 30\n"), 0o755))
+	gitRun(t, dir, "checkout", "-q", "-b", "feature")
+	gitRun(t, dir, "commit", "-q", "--allow-empty", "-m", "feature")
+	gitRun(t, dir, "checkout", "-q", "main")
+	gitRun(t, dir, "commit", "-q", "--allow-empty", "-m", "main")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &repoBoundClient{repoPath: dir, ctx: ctx}
+	time.AfterFunc(200*time.Millisecond, cancel)
+	start := time.Now()
+	assert.Error(t, c.Rebase(dir, "feature"))
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
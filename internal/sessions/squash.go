@@ -0,0 +1,83 @@
+package sessions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joescharf/pm/internal/commitmsg"
+	"github.com/joescharf/pm/internal/models"
+)
+
+// ParseMergeStrategy validates a merge strategy name. "" is valid and means
+// the project's default.
+func ParseMergeStrategy(s string) (models.MergeStrategy, error) {
+	if s == "" {
+		return "", nil
+	}
+	for _, st := range models.MergeStrategies {
+		if string(st) == s {
+			return st, nil
+		}
+	}
+	names := make([]string, len(models.MergeStrategies))
+	for i, st := range models.MergeStrategies {
+		names[i] = string(st)
+	}
+	return "", fmt.Errorf("unknown merge strategy %q (valid: %s)", s, strings.Join(names, ", "))
+}
+
+// SquashMessage builds the commit message for squash-merging branch. The
+// subject is the first issue's title, the body lists the issues and the
+// squashed commits (msgs, oldest first). Under Conventional Commits the
+// subject gets a type from the first issue, and ticket references from the
+// squashed commits are carried over.
+func SquashMessage(conv commitmsg.Convention, branch string, issues []*models.Issue, msgs []string) string {
+	subject := "Squash " + branch
+	var issueType models.IssueType
+	if len(issues) > 0 {
+		subject = issues[0].Title
+		if len(issues) > 1 {
+			subject += fmt.Sprintf(" (+%d more)", len(issues)-1)
+		}
+		issueType = issues[0].Type
+	}
+	if conv.Style == commitmsg.StyleConventional && (commitmsg.Convention{Style: conv.Style}).Check(subject) != nil {
+		subject = conventionalType(issueType) + ": " + subject
+	}
+
+	var b strings.Builder
+	b.WriteString(subject + "\n")
+	if len(issues) > 0 {
+		b.WriteString("\nIssues:\n")
+		for _, issue := range issues {
+			id := issue.ID
+			if len(id) > 12 {
+				id = id[:12]
+			}
+			fmt.Fprintf(&b, "- %s (%s)\n", issue.Title, id)
+		}
+	}
+	if len(msgs) > 0 {
+		b.WriteString("\nCommits:\n")
+		for _, m := range msgs {
+			first, _, _ := strings.Cut(strings.TrimSpace(m), "\n")
+			fmt.Fprintf(&b, "- %s\n", first)
+		}
+	}
+	if tickets := conv.Tickets(msgs); len(tickets) > 0 {
+		fmt.Fprintf(&b, "\nRefs: %s\n", strings.Join(tickets, ", "))
+	}
+	return b.String()
+}
+
+// conventionalType maps an issue type to a Conventional Commits type.
+func conventionalType(t models.IssueType) string {
+	switch t {
+	case models.IssueTypeBug:
+		return "fix"
+	case models.IssueTypeChore:
+		return "chore"
+	default:
+		return "feat"
+	}
+}
@@ -0,0 +1,52 @@
+package sessions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/commitmsg"
+	"github.com/joescharf/pm/internal/models"
+)
+
+func TestParseMergeStrategy(t *testing.T) {
+	st, err := ParseMergeStrategy("ff-only")
+	require.NoError(t, err)
+	assert.Equal(t, models.MergeStrategyFFOnly, st)
+
+	st, err = ParseMergeStrategy("")
+	require.NoError(t, err)
+	assert.Empty(t, st)
+
+	_, err = ParseMergeStrategy("octopus")
+	assert.ErrorContains(t, err, "merge, rebase, squash, ff-only")
+}
+
+func TestSquashMessage(t *testing.T) {
+	issues := []*models.Issue{
+		{ID: "01J5ABCD1234EFGH", Title: "Login fails on Safari", Type: models.IssueTypeBug},
+		{ID: "01J5WXYZ", Title: "Remember me checkbox"},
+	}
+	msgs := []string{"Fix cookie flags PROJ-3", "Add checkbox\n\nSee PROJ-4"}
+
+	assert.Equal(t, `Login fails on Safari (+1 more)
+
+Issues:
+- Login fails on Safari (01J5ABCD1234)
+- Remember me checkbox (01J5WXYZ)
+
+Commits:
+- Fix cookie flags PROJ-3
+- Add checkbox
+`, SquashMessage(commitmsg.Convention{}, "feature/login", issues, msgs))
+
+	conv := commitmsg.Convention{Style: commitmsg.StyleConventional, TicketPrefix: "PROJ-"}
+	msg := SquashMessage(conv, "feature/login", issues[:1], msgs)
+	assert.Contains(t, msg, "fix: Login fails on Safari\n")
+	assert.Contains(t, msg, "\nRefs: PROJ-3, PROJ-4\n")
+	assert.Empty(t, conv.Check(msg))
+
+	assert.Equal(t, "Squash feature/x\n", SquashMessage(commitmsg.Convention{}, "feature/x", nil, nil))
+	assert.Equal(t, "feat: Squash feature/x\n", SquashMessage(conv, "feature/x", nil, nil))
+}
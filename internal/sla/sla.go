@@ -0,0 +1,381 @@
+package sla
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+// AtRiskFraction is how much of a deadline may elapse before an issue is
+// reported as at risk.
+const AtRiskFraction = 0.75
+
+// RecentWindow is how far back Report.RecentBreaches looks.
+const RecentWindow = 7 * 24 * time.Hour
+
+// PolicyConfig is a policy as written under sla.policies in the config file.
+type PolicyConfig struct {
+	Name           string `mapstructure:"name"`
+	Priority       string `mapstructure:"priority"`
+	Type           string `mapstructure:"type"`
+	StartWithin    string `mapstructure:"start_within"`
+	CompleteWithin string `mapstructure:"complete_within"`
+}
+
+// Policy sets deadlines for issues matching Priority and Type; an empty
+// Priority or Type matches any. A zero deadline is not enforced.
+type Policy struct {
+	Name           string
+	Priority       models.IssuePriority
+	Type           models.IssueType
+	StartWithin    time.Duration
+	CompleteWithin time.Duration
+}
+
+// Matches reports whether the policy applies to the issue.
+func (p Policy) Matches(i *models.Issue) bool {
+	return (p.Priority == "" || p.Priority == i.Priority) && (p.Type == "" || p.Type == i.Type)
+}
+
+// deadline returns when the issue's deadline of kind passes.
+func (p Policy) deadline(i *models.Issue, kind models.SLABreachKind) time.Time {
+	if kind == models.SLABreachStart {
+		return i.CreatedAt.Add(p.StartWithin)
+	}
+	return i.CreatedAt.Add(p.CompleteWithin)
+}
+
+// ParsePolicies validates configured policies.
+func ParsePolicies(cfgs []PolicyConfig) ([]Policy, error) {
+	policies := make([]Policy, 0, len(cfgs))
+	seen := make(map[string]bool, len(cfgs))
+	for _, c := range cfgs {
+		if c.Name == "" {
+			return nil, fmt.Errorf("sla policy: name is required")
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("sla policy %s: duplicate name", c.Name)
+		}
+		seen[c.Name] = true
+
+		p := Policy{Name: c.Name}
+		var err error
+		if c.Priority != "" {
+			if p.Priority, err = models.ParseIssuePriority(c.Priority); err != nil {
+				return nil, fmt.Errorf("sla policy %s: %w", c.Name, err)
+			}
+		}
+		if c.Type != "" {
+			if p.Type, err = models.ParseIssueType(c.Type); err != nil {
+				return nil, fmt.Errorf("sla policy %s: %w", c.Name, err)
+			}
+		}
+		if c.StartWithin != "" {
+			if p.StartWithin, err = ParseDuration(c.StartWithin); err != nil {
+				return nil, fmt.Errorf("sla policy %s: start_within: %w", c.Name, err)
+			}
+		}
+		if c.CompleteWithin != "" {
+			if p.CompleteWithin, err = ParseDuration(c.CompleteWithin); err != nil {
+				return nil, fmt.Errorf("sla policy %s: complete_within: %w", c.Name, err)
+			}
+		}
+		if p.StartWithin == 0 && p.CompleteWithin == 0 {
+			return nil, fmt.Errorf("sla policy %s: set start_within or complete_within", c.Name)
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+// ParseDuration accepts Go durations ("36h") plus whole days ("3d") and weeks ("2w").
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.Atoi(n)
+			if err != nil || v < 0 {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(v) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// Evaluation is an issue's standing against the first policy matching it.
+type Evaluation struct {
+	Policy string
+	State  models.SLAState
+	Missed []models.SLABreachKind
+}
+
+// Evaluate checks an issue against the first matching policy. The start
+// deadline only applies while the issue is still open; the complete deadline
+// runs until the issue is done or closed.
+func Evaluate(policies []Policy, i *models.Issue, now time.Time) (Evaluation, bool) {
+	for _, p := range policies {
+		if p.Matches(i) {
+			return evaluate(p, i, now), true
+		}
+	}
+	return Evaluation{State: models.SLAStateNone}, false
+}
+
+func evaluate(p Policy, i *models.Issue, now time.Time) Evaluation {
+	e := Evaluation{Policy: p.Name, State: models.SLAStateOK}
+	atRisk := false
+	finished := i.Status == models.IssueStatusDone || i.Status == models.IssueStatusClosed
+
+	check := func(limit, elapsed time.Duration, kind models.SLABreachKind, running bool) {
+		switch {
+		case elapsed > limit:
+			e.Missed = append(e.Missed, kind)
+		case running && float64(elapsed) >= AtRiskFraction*float64(limit):
+			atRisk = true
+		}
+	}
+
+	if p.StartWithin > 0 {
+		switch {
+		case i.Status == models.IssueStatusOpen:
+			check(p.StartWithin, now.Sub(i.CreatedAt), models.SLABreachStart, true)
+		case i.StartedAt != nil:
+			check(p.StartWithin, i.StartedAt.Sub(i.CreatedAt), models.SLABreachStart, false)
+		}
+	}
+	if p.CompleteWithin > 0 {
+		end := now
+		if finished {
+			switch {
+			case i.CompletedAt != nil:
+				end = *i.CompletedAt
+			case i.ClosedAt != nil:
+				end = *i.ClosedAt
+			default:
+				end = i.UpdatedAt
+			}
+		}
+		check(p.CompleteWithin, end.Sub(i.CreatedAt), models.SLABreachComplete, !finished)
+	}
+
+	switch {
+	case len(e.Missed) > 0:
+		e.State = models.SLAStateBreached
+	case atRisk:
+		e.State = models.SLAStateAtRisk
+	}
+	return e
+}
+
+// Reader is the subset of store.Store needed to report on policies.
+type Reader interface {
+	ListProjects(ctx context.Context, group string) ([]*models.Project, error)
+	ListIssues(ctx context.Context, filter store.IssueListFilter) ([]*models.Issue, error)
+	ListSLABreaches(ctx context.Context, since time.Time) ([]*models.SLABreach, error)
+}
+
+// Store is the subset of store.Store needed to evaluate policies and record
+// the results.
+type Store interface {
+	Reader
+	SetIssueSLAState(ctx context.Context, issueID string, state models.SLAState) error
+	RecordSLABreach(ctx context.Context, b *models.SLABreach) (bool, error)
+}
+
+// IssueSLA is an unfinished issue that is at risk of or has breached its policy.
+type IssueSLA struct {
+	IssueID  string                 `json:"issue_id"`
+	Project  string                 `json:"project"`
+	Title    string                 `json:"title"`
+	Priority models.IssuePriority   `json:"priority"`
+	Type     models.IssueType       `json:"type"`
+	Policy   string                 `json:"policy"`
+	State    models.SLAState        `json:"state"`
+	Missed   []models.SLABreachKind `json:"missed,omitempty"`
+	AgeDays  int                    `json:"age_days"`
+}
+
+// PolicySummary counts the issues a policy applies to by state.
+type PolicySummary struct {
+	Name     string `json:"name"`
+	Issues   int    `json:"issues"`
+	OK       int    `json:"ok"`
+	AtRisk   int    `json:"at_risk"`
+	Breached int    `json:"breached"`
+}
+
+// Breach is a recorded breach event.
+type Breach struct {
+	IssueID    string               `json:"issue_id"`
+	Project    string               `json:"project"`
+	Title      string               `json:"title"`
+	Policy     string               `json:"policy"`
+	Kind       models.SLABreachKind `json:"kind"`
+	BreachedAt time.Time            `json:"breached_at"`
+}
+
+// Report summarizes an evaluation run.
+type Report struct {
+	GeneratedAt    time.Time       `json:"generated_at"`
+	Policies       []PolicySummary `json:"policies"`
+	Breached       []IssueSLA      `json:"breached"`
+	AtRisk         []IssueSLA      `json:"at_risk"`
+	NewBreaches    int             `json:"new_breaches"`
+	RecentBreaches []Breach        `json:"recent_breaches"`
+}
+
+// Run evaluates every issue of non-archived projects, stores changed SLA
+// states and new breach events, and returns a report. Once an issue has
+// breached a policy it stays breached even after it is finished. A breach is
+// dated when its deadline passed.
+func Run(ctx context.Context, s Store, policies []Policy, now time.Time) (*Report, error) {
+	return run(ctx, s, s, policies, now)
+}
+
+// Check evaluates like Run but stores nothing; NewBreaches counts the
+// breaches the next Run will record.
+func Check(ctx context.Context, s Reader, policies []Policy, now time.Time) (*Report, error) {
+	return run(ctx, s, nil, policies, now)
+}
+
+// run evaluates the policies, recording the results in w unless it is nil.
+func run(ctx context.Context, s Reader, w Store, policies []Policy, now time.Time) (*Report, error) {
+	r := &Report{GeneratedAt: now, Breached: []IssueSLA{}, AtRisk: []IssueSLA{}, RecentBreaches: []Breach{}}
+	summaries := make(map[string]*PolicySummary, len(policies))
+	byName := make(map[string]Policy, len(policies))
+	for _, p := range policies {
+		r.Policies = append(r.Policies, PolicySummary{Name: p.Name})
+		byName[p.Name] = p
+	}
+	for i := range r.Policies {
+		summaries[r.Policies[i].Name] = &r.Policies[i]
+	}
+
+	existing, err := s.ListSLABreaches(ctx, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	breachedBefore := make(map[string]bool, len(existing))
+	recorded := make(map[string]bool, len(existing))
+	for _, b := range existing {
+		breachedBefore[b.IssueID+"\x00"+b.Policy] = true
+		recorded[b.IssueID+"\x00"+b.Policy+"\x00"+string(b.Kind)] = true
+	}
+
+	projects, err := s.ListProjects(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	type issueRef struct{ project, title string }
+	refs := make(map[string]issueRef)
+
+	for _, p := range projects {
+		issues, err := s.ListIssues(ctx, store.IssueListFilter{ProjectID: p.ID})
+		if err != nil {
+			return nil, fmt.Errorf("list issues for %s: %w", p.Name, err)
+		}
+		for _, i := range issues {
+			refs[i.ID] = issueRef{project: p.Name, title: i.Title}
+
+			e, _ := Evaluate(policies, i, now)
+			if e.Policy != "" && breachedBefore[i.ID+"\x00"+e.Policy] {
+				e.State = models.SLAStateBreached
+			}
+			for _, kind := range e.Missed {
+				if w == nil {
+					if !recorded[i.ID+"\x00"+e.Policy+"\x00"+string(kind)] {
+						r.NewBreaches++
+					}
+					continue
+				}
+				created, err := w.RecordSLABreach(ctx, &models.SLABreach{
+					IssueID: i.ID, ProjectID: p.ID, Policy: e.Policy, Kind: kind,
+					BreachedAt: byName[e.Policy].deadline(i, kind),
+				})
+				if err != nil {
+					return nil, err
+				}
+				if created {
+					r.NewBreaches++
+				}
+			}
+			if w != nil && e.State != i.SLAState {
+				if err := w.SetIssueSLAState(ctx, i.ID, e.State); err != nil {
+					return nil, err
+				}
+				i.SLAState = e.State
+			}
+			if e.Policy == "" {
+				continue
+			}
+
+			sum := summaries[e.Policy]
+			sum.Issues++
+			switch e.State {
+			case models.SLAStateOK:
+				sum.OK++
+			case models.SLAStateAtRisk:
+				sum.AtRisk++
+			case models.SLAStateBreached:
+				sum.Breached++
+			}
+
+			if i.Status == models.IssueStatusDone || i.Status == models.IssueStatusClosed || e.State == models.SLAStateOK {
+				continue
+			}
+			item := IssueSLA{
+				IssueID:  i.ID,
+				Project:  p.Name,
+				Title:    i.Title,
+				Priority: i.Priority,
+				Type:     i.Type,
+				Policy:   e.Policy,
+				State:    e.State,
+				Missed:   e.Missed,
+				AgeDays:  int(now.Sub(i.CreatedAt).Hours() / 24),
+			}
+			if e.State == models.SLAStateBreached {
+				r.Breached = append(r.Breached, item)
+			} else {
+				r.AtRisk = append(r.AtRisk, item)
+			}
+		}
+	}
+
+	oldestFirst := func(items []IssueSLA) {
+		sort.SliceStable(items, func(a, b int) bool { return items[a].AgeDays > items[b].AgeDays })
+	}
+	oldestFirst(r.Breached)
+	oldestFirst(r.AtRisk)
+
+	recent, err := s.ListSLABreaches(ctx, now.Add(-RecentWindow))
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range recent {
+		ref, ok := refs[b.IssueID]
+		if !ok {
+			continue // archived project
+		}
+		r.RecentBreaches = append(r.RecentBreaches, Breach{
+			IssueID:    b.IssueID,
+			Project:    ref.project,
+			Title:      ref.title,
+			Policy:     b.Policy,
+			Kind:       b.Kind,
+			BreachedAt: b.BreachedAt,
+		})
+	}
+	return r, nil
+}
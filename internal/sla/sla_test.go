@@ -0,0 +1,169 @@
+package sla
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+	"github.com/joescharf/pm/internal/store"
+)
+
+var highBugs = Policy{
+	Name:           "high-bugs",
+	Priority:       models.IssuePriorityHigh,
+	Type:           models.IssueTypeBug,
+	StartWithin:    3 * 24 * time.Hour,
+	CompleteWithin: 14 * 24 * time.Hour,
+}
+
+func TestParsePolicies(t *testing.T) {
+	policies, err := ParsePolicies([]PolicyConfig{
+		{Name: "high-bugs", Priority: "high", Type: "bug", StartWithin: "3d", CompleteWithin: "2w"},
+		{Name: "everything", CompleteWithin: "720h"},
+	})
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	assert.Equal(t, highBugs, policies[0])
+	assert.Equal(t, 30*24*time.Hour, policies[1].CompleteWithin)
+
+	_, err = ParsePolicies([]PolicyConfig{{Name: "x", StartWithin: "soon"}})
+	assert.ErrorContains(t, err, "start_within")
+	_, err = ParsePolicies([]PolicyConfig{{Name: "x"}})
+	assert.Error(t, err, "a policy needs at least one deadline")
+	_, err = ParsePolicies([]PolicyConfig{{Name: "x", StartWithin: "1d"}, {Name: "x", StartWithin: "2d"}})
+	assert.ErrorContains(t, err, "duplicate")
+	_, err = ParsePolicies([]PolicyConfig{{Name: "x", Priority: "urgent", StartWithin: "1d"}})
+	assert.ErrorContains(t, err, "invalid issue priority")
+	_, err = ParsePolicies([]PolicyConfig{{Name: "x", Type: "incident", StartWithin: "1d"}})
+	assert.ErrorContains(t, err, "invalid issue type")
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	issue := func(status models.IssueStatus, age time.Duration) *models.Issue {
+		return &models.Issue{Status: status, Priority: models.IssuePriorityHigh, Type: models.IssueTypeBug, CreatedAt: now.Add(-age)}
+	}
+	closedAfter := func(age, took time.Duration) *models.Issue {
+		i := issue(models.IssueStatusDone, age)
+		closed := i.CreatedAt.Add(took)
+		i.ClosedAt = &closed
+		return i
+	}
+
+	startedAfter := func(age, took time.Duration) *models.Issue {
+		i := issue(models.IssueStatusInProgress, age)
+		started := i.CreatedAt.Add(took)
+		i.StartedAt = &started
+		return i
+	}
+	completedAfter := func(age, took, closedAfter time.Duration) *models.Issue {
+		i := issue(models.IssueStatusClosed, age)
+		completed, closed := i.CreatedAt.Add(took), i.CreatedAt.Add(closedAfter)
+		i.CompletedAt, i.ClosedAt = &completed, &closed
+		return i
+	}
+
+	tests := []struct {
+		name   string
+		issue  *models.Issue
+		state  models.SLAState
+		missed []models.SLABreachKind
+	}{
+		{"fresh", issue(models.IssueStatusOpen, day), models.SLAStateOK, nil},
+		{"start at risk", issue(models.IssueStatusOpen, 60*time.Hour), models.SLAStateAtRisk, nil},
+		{"not started in time", issue(models.IssueStatusOpen, 4*day), models.SLAStateBreached, []models.SLABreachKind{models.SLABreachStart}},
+		{"started, complete at risk", issue(models.IssueStatusInProgress, 11*day), models.SLAStateAtRisk, nil},
+		{"never started or completed", issue(models.IssueStatusOpen, 15*day), models.SLAStateBreached,
+			[]models.SLABreachKind{models.SLABreachStart, models.SLABreachComplete}},
+		{"completed in time", closedAfter(30*day, 13*day), models.SLAStateOK, nil},
+		{"completed late", closedAfter(30*day, 20*day), models.SLAStateBreached, []models.SLABreachKind{models.SLABreachComplete}},
+		{"started late", startedAfter(5*day, 4*day), models.SLAStateBreached, []models.SLABreachKind{models.SLABreachStart}},
+		{"completed in time, closed later", completedAfter(30*day, 13*day, 20*day), models.SLAStateOK, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, ok := Evaluate([]Policy{highBugs}, tt.issue, now)
+			require.True(t, ok)
+			assert.Equal(t, "high-bugs", e.Policy)
+			assert.Equal(t, tt.state, e.State)
+			assert.Equal(t, tt.missed, e.Missed)
+		})
+	}
+
+	e, ok := Evaluate([]Policy{highBugs}, &models.Issue{Priority: models.IssuePriorityLow, Type: models.IssueTypeBug, CreatedAt: now}, now)
+	assert.False(t, ok)
+	assert.Equal(t, models.SLAStateNone, e.State)
+}
+
+func TestRun(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	ctx := store.WithImportedTimestamps(context.Background())
+	ms := store.NewMemoryStore()
+	require.NoError(t, ms.CreateProject(ctx, &models.Project{ID: "p1", Name: "pm", Path: "/code/pm"}))
+	for _, i := range []*models.Issue{
+		{ID: "i1", ProjectID: "p1", Title: "Crash", Status: models.IssueStatusOpen,
+			Priority: models.IssuePriorityHigh, Type: models.IssueTypeBug, CreatedAt: now.Add(-4 * day)},
+		{ID: "i2", ProjectID: "p1", Title: "Leak", Status: models.IssueStatusOpen,
+			Priority: models.IssuePriorityHigh, Type: models.IssueTypeBug, CreatedAt: now.Add(-60 * time.Hour)},
+		{ID: "i3", ProjectID: "p1", Title: "Docs", Status: models.IssueStatusOpen,
+			Priority: models.IssuePriorityLow, Type: models.IssueTypeChore, CreatedAt: now.Add(-90 * day)},
+	} {
+		require.NoError(t, ms.CreateIssue(ctx, i))
+	}
+	require.NoError(t, ms.SetIssueSLAState(ctx, "i3", models.SLAStateOK))
+	state := func(id string) models.SLAState {
+		i, err := ms.GetIssue(ctx, id)
+		require.NoError(t, err)
+		return i.SLAState
+	}
+
+	// Check reports what Run will record without storing anything
+	r, err := Check(context.Background(), ms, []Policy{highBugs}, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, r.NewBreaches)
+	require.Len(t, r.Breached, 1)
+	assert.Empty(t, r.RecentBreaches)
+	assert.Equal(t, models.SLAStateOK, state("i3"))
+	breaches, err := ms.ListSLABreaches(ctx, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, breaches)
+
+	r, err = Run(context.Background(), ms, []Policy{highBugs}, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, r.NewBreaches)
+	require.Len(t, r.Policies, 1)
+	assert.Equal(t, PolicySummary{Name: "high-bugs", Issues: 2, AtRisk: 1, Breached: 1}, r.Policies[0])
+	require.Len(t, r.Breached, 1)
+	assert.Equal(t, "Crash", r.Breached[0].Title)
+	assert.Equal(t, 4, r.Breached[0].AgeDays)
+	require.Len(t, r.AtRisk, 1)
+	assert.Equal(t, "Leak", r.AtRisk[0].Title)
+	require.Len(t, r.RecentBreaches, 1)
+	assert.Equal(t, models.SLABreachStart, r.RecentBreaches[0].Kind)
+	assert.Equal(t, now.Add(-day), r.RecentBreaches[0].BreachedAt.UTC(), "dated when the start deadline passed")
+
+	assert.Equal(t, models.SLAStateBreached, state("i1"))
+	assert.Equal(t, models.SLAStateAtRisk, state("i2"))
+	assert.Equal(t, models.SLAStateNone, state("i3"), "state cleared when no policy applies")
+
+	// Starting the late issue does not clear the breach, and it is not recorded twice
+	_, err = ms.BulkUpdateIssueStatus(ctx, []string{"i1"}, models.IssueStatusInProgress)
+	require.NoError(t, err)
+	r, err = Run(context.Background(), ms, []Policy{highBugs}, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, r.NewBreaches)
+	assert.Equal(t, models.SLAStateBreached, state("i1"))
+	breaches, err = ms.ListSLABreaches(ctx, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, breaches, 1)
+
+	r, err = Check(context.Background(), ms, []Policy{highBugs}, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, r.NewBreaches, "already recorded")
+}
@@ -0,0 +1,293 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Large text columns (issue bodies and AI prompts, review summaries) can be
+// stored compressed. A compressed value is a BLOB starting with its codec's
+// magic bytes; plain values stay TEXT. Reads accept both, whatever codec is
+// configured now, so changing the settings only affects new writes until
+// RepackText rewrites the existing rows.
+
+// TextCodec compresses large text column values.
+type TextCodec interface {
+	Name() string
+	// Magic is the prefix of every compressed value, which identifies the
+	// codec on read. It must not be a valid UTF-8 prefix.
+	Magic() []byte
+	Compress(text string) []byte
+	Decompress(data []byte) (string, error)
+}
+
+// textCodecs are the codecs compressed values are read with, by name.
+var textCodecs = map[string]TextCodec{}
+
+// RegisterTextCodec makes a codec available to Compression.Codec and to reads.
+func RegisterTextCodec(c TextCodec) {
+	textCodecs[c.Name()] = c
+}
+
+func init() {
+	RegisterTextCodec(newZstdCodec())
+}
+
+// zstdCodec compresses with Zstandard.
+type zstdCodec struct {
+	enc *zstd.Encoder
+	dec *zstd.Decoder
+}
+
+func newZstdCodec() *zstdCodec {
+	// EncodeAll and DecodeAll are safe for concurrent use.
+	enc, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	dec, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+	return &zstdCodec{enc: enc, dec: dec}
+}
+
+func (z *zstdCodec) Name() string  { return "zstd" }
+func (z *zstdCodec) Magic() []byte { return []byte{0x28, 0xb5, 0x2f, 0xfd} }
+
+func (z *zstdCodec) Compress(text string) []byte {
+	return z.enc.EncodeAll([]byte(text), nil)
+}
+
+func (z *zstdCodec) Decompress(data []byte) (string, error) {
+	out, err := z.dec.DecodeAll(data, nil)
+	return string(out), err
+}
+
+// Compression configures how large text columns are stored.
+type Compression struct {
+	Codec   string // a registered codec, or "none" to store text as is
+	MinSize int    // compress values of at least this many bytes
+}
+
+// DefaultCompression compresses values of 1 KiB and more with zstd.
+var DefaultCompression = Compression{Codec: "zstd", MinSize: 1024}
+
+// codec returns the configured codec, or nil when compression is off.
+func (c Compression) codec() (TextCodec, error) {
+	if c.Codec == "" || c.Codec == "none" {
+		return nil, nil
+	}
+	codec, ok := textCodecs[c.Codec]
+	if !ok {
+		return nil, fmt.Errorf("unknown compression codec %q (valid: %s)", c.Codec, strings.Join(textCodecNames(), ", "))
+	}
+	return codec, nil
+}
+
+func textCodecNames() []string {
+	names := []string{"none"}
+	for name := range textCodecs {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// pack returns text as it is stored under c.
+func (c Compression) pack(text string) any {
+	codec, err := c.codec()
+	if err != nil || codec == nil || len(text) < c.MinSize {
+		return text
+	}
+	packed := codec.Compress(text)
+	if len(packed) >= len(text) {
+		return text // incompressible
+	}
+	return packed
+}
+
+// unpackText decodes a stored large text value.
+func unpackText(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		for _, codec := range textCodecs {
+			if bytes.HasPrefix(v, codec.Magic()) {
+				text, err := codec.Decompress(v)
+				if err != nil {
+					return "", fmt.Errorf("decompress %s text: %w", codec.Name(), err)
+				}
+				return text, nil
+			}
+		}
+		return string(v), nil
+	default:
+		return "", fmt.Errorf("unexpected text column type %T", v)
+	}
+}
+
+// textColumn scans a large text column into dst, decompressing it.
+type textColumn struct{ dst *string }
+
+func (t textColumn) Scan(v any) error {
+	text, err := unpackText(v)
+	if err != nil {
+		return err
+	}
+	*t.dst = text
+	return nil
+}
+
+// SetCompression sets how large text columns are written from now on.
+func (s *SQLiteStore) SetCompression(c Compression) error {
+	if _, err := c.codec(); err != nil {
+		return err
+	}
+	s.compression = c
+	return nil
+}
+
+// packText returns text as the store writes it to a large text column.
+func (s *SQLiteStore) packText(text string) any {
+	return s.compression.pack(text)
+}
+
+// largeTextColumns are the table columns stored through packText.
+var largeTextColumns = []struct{ table, column string }{
+	{"issues", "body"},
+	{"issues", "ai_prompt"},
+	{"issue_reviews", "summary"},
+}
+
+// RepackText rewrites every large text value under the current compression
+// settings, compressing or decompressing it, and returns how many values
+// changed. Vacuum gives the pages freed by compressing back afterwards.
+func (s *SQLiteStore) RepackText(ctx context.Context) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("repack text: %w", err)
+	}
+	n, err := repackText(ctx, tx, s.compression)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("repack text: %w", err)
+	}
+	if n > 0 {
+		s.notifyWrite()
+	}
+	return n, nil
+}
+
+// repackText rewrites the large text values c stores differently than they
+// are stored now.
+func repackText(ctx context.Context, tx *sql.Tx, c Compression) (int, error) {
+	changed := 0
+	for _, col := range largeTextColumns {
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT rowid, %s FROM %s", col.column, col.table))
+		if err != nil {
+			return changed, fmt.Errorf("repack %s.%s: %w", col.table, col.column, err)
+		}
+		updates := make(map[int64]any)
+		for rows.Next() {
+			var rowid int64
+			var stored any
+			if err := rows.Scan(&rowid, &stored); err != nil {
+				_ = rows.Close()
+				return changed, fmt.Errorf("repack %s.%s: %w", col.table, col.column, err)
+			}
+			text, err := unpackText(stored)
+			if err != nil {
+				_ = rows.Close()
+				return changed, fmt.Errorf("repack %s.%s row %d: %w", col.table, col.column, rowid, err)
+			}
+			if packed := c.pack(text); !sameStored(stored, packed) {
+				updates[rowid] = packed
+			}
+		}
+		_ = rows.Close()
+		if err := rows.Err(); err != nil {
+			return changed, err
+		}
+
+		// A repacked value reads back the same, so updated_at is left alone.
+		query := fmt.Sprintf("UPDATE %s SET %s = ? WHERE rowid = ?", col.table, col.column)
+		for rowid, v := range updates {
+			if _, err := tx.ExecContext(ctx, query, v, rowid); err != nil {
+				return changed, fmt.Errorf("repack %s.%s row %d: %w", col.table, col.column, rowid, err)
+			}
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// sameStored reports whether a stored value and a packed one are stored the
+// same way: both plain text, or both the same compressed bytes.
+func sameStored(stored, packed any) bool {
+	switch p := packed.(type) {
+	case string:
+		s, ok := stored.(string)
+		return ok && s == p
+	case []byte:
+		s, ok := stored.([]byte)
+		return ok && bytes.Equal(s, p)
+	}
+	return false
+}
+
+// TextColumnStats reports how one large text column is stored.
+type TextColumnStats struct {
+	Table       string `json:"table"`
+	Column      string `json:"column"`
+	Values      int    `json:"values"`       // non-empty values
+	Compressed  int    `json:"compressed"`   // values stored compressed
+	StoredBytes int64  `json:"stored_bytes"` // bytes on disk, before page overhead
+	TextBytes   int64  `json:"text_bytes"`   // bytes once decompressed
+}
+
+// textColumnStats measures how each large text column is stored.
+func (s *SQLiteStore) textColumnStats(ctx context.Context) ([]TextColumnStats, error) {
+	var stats []TextColumnStats
+	for _, col := range largeTextColumns {
+		cs := TextColumnStats{Table: col.table, Column: col.column}
+		rows, err := s.db.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s WHERE length(%s) > 0", col.column, col.table, col.column))
+		if err != nil {
+			return nil, fmt.Errorf("text column stats: %s.%s: %w", col.table, col.column, err)
+		}
+		for rows.Next() {
+			var stored any
+			if err := rows.Scan(&stored); err != nil {
+				_ = rows.Close()
+				return nil, fmt.Errorf("text column stats: %s.%s: %w", col.table, col.column, err)
+			}
+			text, err := unpackText(stored)
+			if err != nil {
+				_ = rows.Close()
+				return nil, err
+			}
+			cs.Values++
+			cs.TextBytes += int64(len(text))
+			if b, ok := stored.([]byte); ok {
+				cs.StoredBytes += int64(len(b))
+				if len(b) > 0 && string(b) != text {
+					cs.Compressed++
+				}
+			} else {
+				cs.StoredBytes += int64(len(text))
+			}
+		}
+		_ = rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		stats = append(stats, cs)
+	}
+	return stats, nil
+}
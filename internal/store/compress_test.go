@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// storedType returns SQLite's storage class of a column value.
+func storedType(t *testing.T, s *SQLiteStore, table, column, id string) string {
+	t.Helper()
+	var typ string
+	require.NoError(t, s.db.QueryRow("SELECT typeof("+column+") FROM "+table+" WHERE id = ?", id).Scan(&typ))
+	return typ
+}
+
+func TestCompression_RoundTrip(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	long := strings.Repeat("Rate limit every token bucket per client. ", 100)
+	issue := &models.Issue{ProjectID: p.ID, Title: "Rate limiting", Body: long, AIPrompt: "short prompt"}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	assert.Equal(t, "blob", storedType(t, s, "issues", "body", issue.ID), "large values are compressed")
+	assert.Equal(t, "text", storedType(t, s, "issues", "ai_prompt", issue.ID), "small values are not")
+
+	got, err := s.GetIssue(ctx, issue.ID)
+	require.NoError(t, err)
+	assert.Equal(t, long, got.Body)
+	assert.Equal(t, "short prompt", got.AIPrompt)
+
+	review := &models.IssueReview{IssueID: issue.ID, Verdict: models.ReviewVerdictPass, Summary: long}
+	require.NoError(t, s.CreateIssueReview(ctx, review))
+	reviews, err := s.ListIssueReviews(ctx, issue.ID)
+	require.NoError(t, err)
+	require.Len(t, reviews, 1)
+	assert.Equal(t, long, reviews[0].Summary)
+
+	// Turning compression off affects new writes; compressed values still read.
+	require.NoError(t, s.SetCompression(Compression{Codec: "none"}))
+	got.AIPrompt = long
+	require.NoError(t, s.UpdateIssue(ctx, got))
+	assert.Equal(t, "text", storedType(t, s, "issues", "ai_prompt", issue.ID))
+	issues, err := s.ListIssues(ctx, IssueListFilter{ProjectID: p.ID})
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, long, issues[0].Body)
+	assert.Equal(t, long, issues[0].AIPrompt)
+
+	assert.ErrorContains(t, s.SetCompression(Compression{Codec: "lz9"}), `unknown compression codec "lz9"`)
+}
+
+func TestCompression_RepackAndStats(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	require.NoError(t, s.SetCompression(Compression{Codec: "none"}))
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	long := strings.Repeat("Store diffs and summaries compressed. ", 200)
+	issue := &models.Issue{ProjectID: p.ID, Title: "Compress", Body: long}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+
+	stats, err := s.Stats(ctx)
+	require.NoError(t, err)
+	assert.Positive(t, stats.SizeBytes())
+	require.Len(t, stats.Columns, 3)
+	body := stats.Columns[0]
+	assert.Equal(t, TextColumnStats{Table: "issues", Column: "body", Values: 1, StoredBytes: int64(len(long)), TextBytes: int64(len(long))}, body)
+
+	require.NoError(t, s.SetCompression(DefaultCompression))
+	n, err := s.RepackText(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = s.RepackText(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "repacking again changes nothing")
+	require.NoError(t, s.Vacuum(ctx))
+
+	stats, err = s.Stats(ctx)
+	require.NoError(t, err)
+	body = stats.Columns[0]
+	assert.Equal(t, 1, body.Compressed)
+	assert.Less(t, body.StoredBytes, body.TextBytes/10)
+
+	got, err := s.GetIssue(ctx, issue.ID)
+	require.NoError(t, err)
+	assert.Equal(t, long, got.Body)
+}
+
+func TestCompression_Migration(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	long := strings.Repeat("Older binaries read text only. ", 100)
+	issue := &models.Issue{ProjectID: p.ID, Title: "Compress", Body: long}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	require.Equal(t, "blob", storedType(t, s, "issues", "body", issue.ID))
+
+	// Reverting the migration decompresses every value.
+	plan, err := s.PlanMigration(ctx, 50)
+	require.NoError(t, err)
+	_, err = s.ApplyMigration(ctx, plan, false)
+	require.NoError(t, err)
+	assert.Equal(t, "text", storedType(t, s, "issues", "body", issue.ID))
+
+	// Applying it compresses the existing ones.
+	require.NoError(t, s.Migrate(ctx))
+	assert.Equal(t, "blob", storedType(t, s, "issues", "body", issue.ID))
+	got, err := s.GetIssue(ctx, issue.ID)
+	require.NoError(t, err)
+	assert.Equal(t, long, got.Body)
+}
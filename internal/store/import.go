@@ -0,0 +1,32 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+type importKey struct{}
+
+// WithImportedTimestamps returns a context under which the Create methods keep
+// the timestamps already set on a record instead of stamping the current
+// time: CreatedAt and UpdatedAt, a session's StartedAt, and an issue's
+// ClosedAt, StartedAt and CompletedAt, along with its merge. Used to import
+// records exported from another pm database.
+func WithImportedTimestamps(ctx context.Context) context.Context {
+	return context.WithValue(ctx, importKey{}, true)
+}
+
+// importing reports whether ctx came from WithImportedTimestamps.
+func importing(ctx context.Context) bool {
+	v, _ := ctx.Value(importKey{}).(bool)
+	return v
+}
+
+// createdTime returns t when ctx imports records and t is set, and now
+// otherwise.
+func createdTime(ctx context.Context, t, now time.Time) time.Time {
+	if importing(ctx) && !t.IsZero() {
+		return t.UTC()
+	}
+	return now
+}
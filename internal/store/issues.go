@@ -0,0 +1,34 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/joescharf/pm/internal/lexorank"
+	"github.com/joescharf/pm/internal/models"
+)
+
+// setIssueDefaults fills in a new issue's unset status, priority and type.
+func setIssueDefaults(issue *models.Issue) {
+	if issue.Status == "" {
+		issue.Status = models.IssueStatusOpen
+	}
+	if issue.Priority == "" {
+		issue.Priority = models.IssuePriorityMedium
+	}
+	if issue.Type == "" {
+		issue.Type = models.IssueTypeFeature
+	}
+}
+
+// validateIssue rejects an issue whose status, priority or type is not one of
+// the known values (migration 044 enforces the same rule in the database), or
+// whose rank is not a lexorank.
+func validateIssue(op string, issue *models.Issue) error {
+	if err := issue.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if issue.Rank != "" && !lexorank.Valid(issue.Rank) {
+		return fmt.Errorf("%s: invalid rank %q", op, issue.Rank)
+	}
+	return nil
+}
@@ -0,0 +1,355 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// TableStats reports the size of one table.
+type TableStats struct {
+	Name  string `json:"name"`
+	Rows  int64  `json:"rows"`
+	Bytes int64  `json:"bytes"` // pages the table takes, indexes excluded
+}
+
+// IndexStats reports the size of one index and, once ANALYZE has run, how
+// selective it is. SQLite keeps no count of how often an index is used; an
+// index whose keys each match many rows rarely helps a query.
+type IndexStats struct {
+	Name       string `json:"name"`
+	Table      string `json:"table"`
+	Bytes      int64  `json:"bytes"`
+	RowsPerKey int64  `json:"rows_per_key"` // average rows per full key; 0 until analyzed
+}
+
+// DBStats reports the size of the database file, its tables and indexes and
+// its large text columns.
+type DBStats struct {
+	Path        string            `json:"path"`
+	PageSize    int64             `json:"page_size"`
+	Pages       int64             `json:"pages"`
+	FreePages   int64             `json:"free_pages"`
+	WALBytes    int64             `json:"wal_bytes"`
+	Compression Compression       `json:"compression"`
+	Tables      []TableStats      `json:"tables"`
+	Indexes     []IndexStats      `json:"indexes"`
+	Columns     []TextColumnStats `json:"columns"`
+}
+
+// SizeBytes is the size of the database file, not counting its WAL.
+func (d *DBStats) SizeBytes() int64 { return d.PageSize * d.Pages }
+
+// Stats measures the database file, its tables and indexes and its large
+// text columns.
+func (s *SQLiteStore) Stats(ctx context.Context) (*DBStats, error) {
+	stats := &DBStats{Path: s.path, Compression: s.compression}
+	if err := s.pageCounts(ctx, stats); err != nil {
+		return nil, err
+	}
+	if fi, err := os.Stat(s.path + "-wal"); err == nil {
+		stats.WALBytes = fi.Size()
+	}
+
+	// Bytes per table and index, from the dbstat virtual table.
+	sizes := make(map[string]int64)
+	rows, err := s.db.QueryContext(ctx, "SELECT name, SUM(pgsize) FROM dbstat GROUP BY name")
+	if err != nil {
+		return nil, fmt.Errorf("database stats: %w", err)
+	}
+	for rows.Next() {
+		var name string
+		var size int64
+		if err := rows.Scan(&name, &size); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("database stats: %w", err)
+		}
+		sizes[name] = size
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	analyzed, err := s.indexAnalysis(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.QueryContext(ctx,
+		`SELECT type, name, tbl_name FROM sqlite_master
+		WHERE type IN ('table', 'index') AND name NOT LIKE 'sqlite_stat%'
+		ORDER BY tbl_name, type DESC, name`)
+	if err != nil {
+		return nil, fmt.Errorf("database stats: %w", err)
+	}
+	for rows.Next() {
+		var typ, name, table string
+		if err := rows.Scan(&typ, &name, &table); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("database stats: %w", err)
+		}
+		if typ == "table" {
+			stats.Tables = append(stats.Tables, TableStats{Name: name, Bytes: sizes[name]})
+		} else {
+			stats.Indexes = append(stats.Indexes, IndexStats{Name: name, Table: table, Bytes: sizes[name], RowsPerKey: analyzed[name]})
+		}
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range stats.Tables {
+		t := &stats.Tables[i]
+		if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM "`+t.Name+`"`).Scan(&t.Rows); err != nil {
+			return nil, fmt.Errorf("database stats: count %s: %w", t.Name, err)
+		}
+	}
+
+	if stats.Columns, err = s.textColumnStats(ctx); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// pageCounts fills in the page size and counts of stats.
+func (s *SQLiteStore) pageCounts(ctx context.Context, stats *DBStats) error {
+	for pragma, dst := range map[string]*int64{"page_size": &stats.PageSize, "page_count": &stats.Pages, "freelist_count": &stats.FreePages} {
+		if err := s.db.QueryRowContext(ctx, "PRAGMA "+pragma).Scan(dst); err != nil {
+			return fmt.Errorf("database stats: %s: %w", pragma, err)
+		}
+	}
+	return nil
+}
+
+// indexAnalysis returns the average rows per full key of each index ANALYZE
+// has measured, from sqlite_stat1.
+func (s *SQLiteStore) indexAnalysis(ctx context.Context) (map[string]int64, error) {
+	var exists int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'sqlite_stat1'").Scan(&exists); err != nil {
+		return nil, fmt.Errorf("database stats: %w", err)
+	}
+	perKey := make(map[string]int64)
+	if exists == 0 {
+		return perKey, nil
+	}
+	rows, err := s.db.QueryContext(ctx, "SELECT idx, stat FROM sqlite_stat1 WHERE idx IS NOT NULL")
+	if err != nil {
+		return nil, fmt.Errorf("database stats: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var idx, stat string
+		if err := rows.Scan(&idx, &stat); err != nil {
+			return nil, fmt.Errorf("database stats: %w", err)
+		}
+		// stat is "<rows> <rows per first column> ... <rows per full key>",
+		// possibly followed by keywords such as "unordered".
+		var last int64
+		for _, f := range strings.Fields(stat)[1:] {
+			n, err := strconv.ParseInt(f, 10, 64)
+			if err != nil {
+				break
+			}
+			last = n
+		}
+		perKey[idx] = last
+	}
+	return perKey, rows.Err()
+}
+
+// OrphanRows counts the rows of a table whose reference to another table's
+// row is dangling.
+type OrphanRows struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	Parent string `json:"parent"`
+	Rows   int    `json:"rows"`
+	// Declared is set for foreign keys SQLite enforces, which only dangle
+	// when rows were written with foreign key checks off. Other references
+	// are kept by pm alone and may dangle after a delete.
+	Declared bool `json:"declared"`
+}
+
+// softReferences are the columns that refer to rows of other tables without
+// a foreign key; an empty value refers to nothing.
+var softReferences = []struct{ table, column, parent string }{
+	{"agent_sessions", "issue_id", "issues"},
+	{"issue_reviews", "session_id", "agent_sessions"},
+	{"issue_closures", "session_id", "agent_sessions"},
+	{"review_shares", "review_id", "issue_reviews"},
+	{"pr_review_imports", "comment_id", "issue_comments"},
+	{"pr_review_imports", "follow_up_issue_id", "issues"},
+}
+
+// Orphans reports the dangling references in the database, declared foreign
+// keys first. It changes nothing.
+func (s *SQLiteStore) Orphans(ctx context.Context) ([]OrphanRows, error) {
+	var orphans []OrphanRows
+
+	rows, err := s.db.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return nil, fmt.Errorf("foreign key check: %w", err)
+	}
+	type fk struct {
+		table string
+		id    int
+	}
+	counts := make(map[fk]int)
+	parents := make(map[fk]string)
+	var order []fk
+	for rows.Next() {
+		var table, parent string
+		var rowid any
+		var id int
+		if err := rows.Scan(&table, &rowid, &parent, &id); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("foreign key check: %w", err)
+		}
+		k := fk{table, id}
+		if counts[k] == 0 {
+			order = append(order, k)
+		}
+		counts[k]++
+		parents[k] = parent
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, k := range order {
+		column, err := s.foreignKeyColumn(ctx, k.table, k.id)
+		if err != nil {
+			return nil, err
+		}
+		orphans = append(orphans, OrphanRows{Table: k.table, Column: column, Parent: parents[k], Rows: counts[k], Declared: true})
+	}
+
+	for _, ref := range softReferences {
+		var n int
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM %[1]s WHERE %[2]s != '' AND NOT EXISTS (SELECT 1 FROM %[3]s WHERE %[3]s.id = %[1]s.%[2]s)`,
+			ref.table, ref.column, ref.parent)
+		if err := s.db.QueryRowContext(ctx, query).Scan(&n); err != nil {
+			return nil, fmt.Errorf("orphan check %s.%s: %w", ref.table, ref.column, err)
+		}
+		if n > 0 {
+			orphans = append(orphans, OrphanRows{Table: ref.table, Column: ref.column, Parent: ref.parent, Rows: n})
+		}
+	}
+	return orphans, nil
+}
+
+// foreignKeyColumn returns the column(s) of a table's foreign key, by the
+// id foreign_key_check reports.
+func (s *SQLiteStore) foreignKeyColumn(ctx context.Context, table string, id int) (string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, "from" FROM pragma_foreign_key_list(?)`, table)
+	if err != nil {
+		return "", fmt.Errorf("foreign keys of %s: %w", table, err)
+	}
+	defer func() { _ = rows.Close() }()
+	var columns []string
+	for rows.Next() {
+		var fkID int
+		var from string
+		if err := rows.Scan(&fkID, &from); err != nil {
+			return "", fmt.Errorf("foreign keys of %s: %w", table, err)
+		}
+		if fkID == id {
+			columns = append(columns, from)
+		}
+	}
+	return strings.Join(columns, ", "), rows.Err()
+}
+
+// MaintenanceReport is the outcome of Maintain.
+type MaintenanceReport struct {
+	// Integrity lists the problems SQLite's integrity check found; empty
+	// when the database is sound.
+	Integrity  []string     `json:"integrity"`
+	Orphans    []OrphanRows `json:"orphans"`
+	Analyzed   bool         `json:"analyzed"`
+	Vacuumed   bool         `json:"vacuumed"`
+	SizeBefore int64        `json:"size_before"`
+	SizeAfter  int64        `json:"size_after"`
+}
+
+// ErrIntegrity is returned by Maintain, with its report, when the integrity
+// check fails; the database is then left as it is.
+var ErrIntegrity = errors.New("database failed its integrity check")
+
+// Maintain checks the database's integrity and reports its orphan rows;
+// unless checkOnly, it then updates the query planner's statistics (ANALYZE),
+// truncates the WAL and rebuilds the file (VACUUM). A database that fails
+// the integrity check is not changed and ErrIntegrity is returned.
+func (s *SQLiteStore) Maintain(ctx context.Context, checkOnly bool) (*MaintenanceReport, error) {
+	report := &MaintenanceReport{Integrity: []string{}, Orphans: []OrphanRows{}}
+	var before DBStats
+	if err := s.pageCounts(ctx, &before); err != nil {
+		return nil, err
+	}
+	report.SizeBefore, report.SizeAfter = before.SizeBytes(), before.SizeBytes()
+
+	rows, err := s.db.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("integrity check: %w", err)
+	}
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("integrity check: %w", err)
+		}
+		if line != "ok" {
+			report.Integrity = append(report.Integrity, line)
+		}
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	orphans, err := s.Orphans(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.Orphans = append(report.Orphans, orphans...)
+
+	if len(report.Integrity) > 0 {
+		return report, ErrIntegrity
+	}
+	if checkOnly {
+		return report, nil
+	}
+
+	if _, err := s.db.ExecContext(ctx, "ANALYZE"); err != nil {
+		return report, fmt.Errorf("analyze database: %w", err)
+	}
+	report.Analyzed = true
+	if err := s.Vacuum(ctx); err != nil {
+		return report, err
+	}
+	report.Vacuumed = true
+
+	var after DBStats
+	if err := s.pageCounts(ctx, &after); err != nil {
+		return report, err
+	}
+	report.SizeAfter = after.SizeBytes()
+	return report, nil
+}
+
+// Vacuum rebuilds the database file, returning its free pages to the
+// filesystem, and truncates the WAL.
+func (s *SQLiteStore) Vacuum(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("vacuum database: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("checkpoint WAL: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+func TestStats(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	require.NoError(t, s.CreateIssue(ctx, &models.Issue{ProjectID: p.ID, Title: "One"}))
+	require.NoError(t, s.CreateIssue(ctx, &models.Issue{ProjectID: p.ID, Title: "Two"}))
+
+	stats, err := s.Stats(ctx)
+	require.NoError(t, err)
+	assert.Positive(t, stats.WALBytes)
+	tables := make(map[string]TableStats)
+	for _, tbl := range stats.Tables {
+		tables[tbl.Name] = tbl
+	}
+	assert.Equal(t, int64(2), tables["issues"].Rows)
+	assert.Equal(t, int64(1), tables["projects"].Rows)
+	assert.Positive(t, tables["issues"].Bytes)
+
+	var idx *IndexStats
+	for i := range stats.Indexes {
+		if stats.Indexes[i].Name == "idx_issues_project_id" {
+			idx = &stats.Indexes[i]
+		}
+	}
+	require.NotNil(t, idx)
+	assert.Equal(t, "issues", idx.Table)
+	assert.Zero(t, idx.RowsPerKey, "not analyzed yet")
+
+	_, err = s.Maintain(ctx, false)
+	require.NoError(t, err)
+	stats, err = s.Stats(ctx)
+	require.NoError(t, err)
+	for _, i := range stats.Indexes {
+		if i.Name == "idx_issues_project_id" {
+			assert.Equal(t, int64(2), i.RowsPerKey, "both issues share the project")
+		}
+	}
+}
+
+func TestMaintain(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	// Deleting an issue clears its sessions' issue_id; older databases may
+	// still point at issues long gone.
+	require.NoError(t, s.CreateAgentSession(ctx, &models.AgentSession{ProjectID: p.ID, IssueID: "01GONE", Branch: "feature/a", Status: models.SessionStatusCompleted}))
+
+	// A row written with foreign key checks off.
+	_, err := s.db.Exec("PRAGMA foreign_keys=OFF")
+	require.NoError(t, err)
+	_, err = s.db.Exec("INSERT INTO issue_comments (id, issue_id, author, body, created_at) VALUES ('c1', 'missing', 'me', 'hi', datetime('now'))")
+	require.NoError(t, err)
+	_, err = s.db.Exec("PRAGMA foreign_keys=ON")
+	require.NoError(t, err)
+
+	report, err := s.Maintain(ctx, true)
+	require.NoError(t, err)
+	assert.Empty(t, report.Integrity)
+	assert.Equal(t, []OrphanRows{
+		{Table: "issue_comments", Column: "issue_id", Parent: "issues", Rows: 1, Declared: true},
+		{Table: "agent_sessions", Column: "issue_id", Parent: "issues", Rows: 1},
+	}, report.Orphans)
+	assert.False(t, report.Analyzed)
+	assert.False(t, report.Vacuumed)
+
+	report, err = s.Maintain(ctx, false)
+	require.NoError(t, err)
+	assert.True(t, report.Analyzed)
+	assert.True(t, report.Vacuumed)
+	assert.Positive(t, report.SizeAfter)
+	assert.Len(t, report.Orphans, 2, "orphans are reported, not deleted")
+}
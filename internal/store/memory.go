@@ -0,0 +1,2547 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joescharf/pm/internal/lexorank"
+	"github.com/joescharf/pm/internal/models"
+)
+
+// MemoryStore is a Store held entirely in memory, for embedding pm's API and
+// MCP servers without SQLite and for tests. It mirrors SQLiteStore: the same
+// uniqueness and foreign key rules, cascading deletes, issue status history
+// and cycle timestamps (triggers in SQLite), result ordering and error
+// messages. Stored values are copied in and out, so callers never share
+// memory with the store. Nothing survives the process.
+type MemoryStore struct {
+	mu           sync.RWMutex
+	projects     map[string]*models.Project
+	issues       map[string]*models.Issue
+	tags         map[string]*models.Tag
+	issueTags    map[string]map[string]bool // issue ID -> set of tag IDs
+	sessions     map[string]*models.AgentSession
+	reviews      map[string]*models.IssueReview
+	shares       map[string]*models.ReviewShare
+	relations    map[string]*models.IssueRelation
+	dependencies map[string]*models.ProjectDependency
+	snapshots    map[string]*models.HealthSnapshot
+	breaches     map[string]*models.SLABreach
+	statusEvents []*models.IssueStatusEvent
+	lastEventID  int64
+	sessEvents   []*models.SessionEvent
+	lastSessEvID int64
+	tokens       map[string]*models.APIToken
+	notes        map[string]*models.ProjectNote
+	closures     map[string]*models.IssueClosure
+	prReviews    map[string]*models.PRReviewImport
+	boardItems   map[string]*models.BoardItem      // by issue ID
+	releases     map[string]*models.ProjectRelease // by project ID
+	llmUsage     []*models.LLMUsage
+	pushSubs     map[string]*models.PushSubscription // by endpoint
+	watchers     map[string]*models.IssueWatcher
+	comments     map[string]*models.IssueComment
+	changes      []*models.Change
+	changeSeq    int64
+	codeCounters map[codeCounter]int // last short code number handed out
+
+	hooksMu    sync.RWMutex
+	writeHooks []func()
+}
+
+// NewMemoryStore returns an empty in-memory store. It needs no migration.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		projects:     make(map[string]*models.Project),
+		issues:       make(map[string]*models.Issue),
+		tags:         make(map[string]*models.Tag),
+		issueTags:    make(map[string]map[string]bool),
+		sessions:     make(map[string]*models.AgentSession),
+		reviews:      make(map[string]*models.IssueReview),
+		shares:       make(map[string]*models.ReviewShare),
+		relations:    make(map[string]*models.IssueRelation),
+		dependencies: make(map[string]*models.ProjectDependency),
+		snapshots:    make(map[string]*models.HealthSnapshot),
+		breaches:     make(map[string]*models.SLABreach),
+		tokens:       make(map[string]*models.APIToken),
+		notes:        make(map[string]*models.ProjectNote),
+		closures:     make(map[string]*models.IssueClosure),
+		prReviews:    make(map[string]*models.PRReviewImport),
+		boardItems:   make(map[string]*models.BoardItem),
+		releases:     make(map[string]*models.ProjectRelease),
+		pushSubs:     make(map[string]*models.PushSubscription),
+		watchers:     make(map[string]*models.IssueWatcher),
+		comments:     make(map[string]*models.IssueComment),
+		codeCounters: make(map[codeCounter]int),
+	}
+}
+
+// OnWrite registers fn to be called after every successful write.
+func (m *MemoryStore) OnWrite(fn func()) {
+	m.hooksMu.Lock()
+	defer m.hooksMu.Unlock()
+	m.writeHooks = append(m.writeHooks, fn)
+}
+
+// write runs fn under the write lock and, if it succeeds, notifies write
+// hooks once the lock is released.
+func (m *MemoryStore) write(fn func() error) error {
+	m.mu.Lock()
+	err := fn()
+	m.mu.Unlock()
+	if err == nil {
+		m.hooksMu.RLock()
+		defer m.hooksMu.RUnlock()
+		for _, hook := range m.writeHooks {
+			hook()
+		}
+	}
+	return err
+}
+
+// errUnique and errForeignKey word constraint failures the way SQLite does,
+// so callers that match on the message behave the same with either store.
+func errUnique(op, columns string) error {
+	return fmt.Errorf("%s: UNIQUE constraint failed: %s", op, columns)
+}
+
+func errForeignKey(op string) error {
+	return fmt.Errorf("%s: FOREIGN KEY constraint failed", op)
+}
+
+// checkSessionStatus rejects an unknown session status, as the
+// session_enums triggers do.
+func checkSessionStatus(op string, status models.SessionStatus) error {
+	if _, err := models.ParseSessionStatus(string(status)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+func copyProject(p *models.Project) *models.Project {
+	c := *p
+	c.Languages = slices.Clone(p.Languages)
+	return &c
+}
+
+func copyIssue(i *models.Issue) *models.Issue {
+	c := *i
+	c.Tags = nil
+	c.Readiness = nil
+	c.Redactions = slices.Clone(i.Redactions)
+	c.AcceptanceCriteria = slices.Clone(i.AcceptanceCriteria)
+	c.ClosedAt = cloneTime(i.ClosedAt)
+	c.StartedAt = cloneTime(i.StartedAt)
+	c.CompletedAt = cloneTime(i.CompletedAt)
+	c.MergedAt = cloneTime(i.MergedAt)
+	return &c
+}
+
+func copySession(s *models.AgentSession) *models.AgentSession {
+	c := *s
+	c.LastActiveAt = cloneTime(s.LastActiveAt)
+	c.EndedAt = cloneTime(s.EndedAt)
+	c.LastSyncAt = cloneTime(s.LastSyncAt)
+	c.BlockedAt = cloneTime(s.BlockedAt)
+	return &c
+}
+
+// --- Projects ---
+
+func (m *MemoryStore) CreateProject(ctx context.Context, p *models.Project) error {
+	return m.write(func() error {
+		if p.ID == "" {
+			p.ID = newULID()
+		}
+		if _, ok := m.projects[p.ID]; ok {
+			return errUnique("create project", "projects.id")
+		}
+		for _, other := range m.projects {
+			if other.Name == p.Name {
+				return errUnique("create project", "projects.name")
+			}
+			if other.Path == p.Path {
+				return errUnique("create project", "projects.path")
+			}
+		}
+		key, err := projectKeyFor(p, func(key string) (bool, error) {
+			return m.projectKeyInUse(key, p.ID), nil
+		})
+		if err != nil {
+			return fmt.Errorf("create project: %w", err)
+		}
+		p.Key = key
+		now := time.Now().UTC()
+		p.CreatedAt = createdTime(ctx, p.CreatedAt, now)
+		p.UpdatedAt = createdTime(ctx, p.UpdatedAt, now)
+		m.projects[p.ID] = copyProject(p)
+		m.recordChange(models.ChangeProject, p.ID, p.ID, models.ChangeInsert)
+		return nil
+	})
+}
+
+func (m *MemoryStore) GetProject(_ context.Context, id string) (*models.Project, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if p, ok := m.projects[id]; ok {
+		return copyProject(p), nil
+	}
+	return nil, fmt.Errorf("project not found: %s", id)
+}
+
+func (m *MemoryStore) GetProjectByName(_ context.Context, name string) (*models.Project, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, p := range m.projects {
+		if p.Name == name {
+			return copyProject(p), nil
+		}
+	}
+	return nil, fmt.Errorf("project not found: %s", name)
+}
+
+func (m *MemoryStore) GetProjectByPath(_ context.Context, path string) (*models.Project, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, p := range m.projects {
+		if p.Path == path {
+			return copyProject(p), nil
+		}
+	}
+	return nil, fmt.Errorf("project not found at path: %s", path)
+}
+
+func (m *MemoryStore) ListProjects(_ context.Context, group string) ([]*models.Project, error) {
+	return m.listProjects(func(p *models.Project) bool {
+		return !p.Archived && (group == "" || p.GroupName == group)
+	}), nil
+}
+
+// ListArchivedProjects returns archived projects, which ListProjects omits.
+func (m *MemoryStore) ListArchivedProjects(_ context.Context) ([]*models.Project, error) {
+	return m.listProjects(func(p *models.Project) bool { return p.Archived }), nil
+}
+
+func (m *MemoryStore) listProjects(keep func(*models.Project) bool) []*models.Project {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var projects []*models.Project
+	for _, p := range m.projects {
+		if keep(p) {
+			projects = append(projects, copyProject(p))
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+	return projects
+}
+
+func (m *MemoryStore) UpdateProject(_ context.Context, p *models.Project) error {
+	return m.write(func() error {
+		existing, ok := m.projects[p.ID]
+		if !ok {
+			return fmt.Errorf("project not found: %s", p.ID)
+		}
+		for _, other := range m.projects {
+			if other.ID == p.ID {
+				continue
+			}
+			if other.Name == p.Name {
+				return errUnique("update project", "projects.name")
+			}
+			if other.Path == p.Path {
+				return errUnique("update project", "projects.path")
+			}
+		}
+		p.UpdatedAt = time.Now().UTC()
+		stored := copyProject(p)
+		stored.Key = existing.Key
+		stored.CreatedAt = existing.CreatedAt
+		m.projects[p.ID] = stored
+		m.recordChange(models.ChangeProject, p.ID, p.ID, models.ChangeUpdate)
+		return nil
+	})
+}
+
+func (m *MemoryStore) DeleteProject(_ context.Context, id string) error {
+	return m.write(func() error {
+		if _, ok := m.projects[id]; !ok {
+			return fmt.Errorf("project not found: %s", id)
+		}
+		m.deleteProject(id)
+		return nil
+	})
+}
+
+// deleteProject removes a project and everything that cascades from it.
+func (m *MemoryStore) deleteProject(id string) {
+	delete(m.projects, id)
+	delete(m.codeCounters, codeCounter{id, codeKindIssue})
+	delete(m.codeCounters, codeCounter{id, codeKindSession})
+	delete(m.releases, id)
+	m.recordChange(models.ChangeProject, id, id, models.ChangeDelete)
+	for issueID, issue := range m.issues {
+		if issue.ProjectID == id {
+			m.deleteIssue(issueID)
+		}
+	}
+	for sid, s := range m.sessions {
+		if s.ProjectID == id {
+			delete(m.sessions, sid)
+			m.recordChange(models.ChangeSession, sid, id, models.ChangeDelete)
+		}
+	}
+	for did, d := range m.dependencies {
+		if d.ProjectID == id || d.DependsOnID == id {
+			delete(m.dependencies, did)
+		}
+	}
+	for hid, h := range m.snapshots {
+		if h.ProjectID == id {
+			delete(m.snapshots, hid)
+		}
+	}
+	for bid, b := range m.breaches {
+		if b.ProjectID == id {
+			delete(m.breaches, bid)
+		}
+	}
+	for tid, t := range m.tokens {
+		if t.ProjectID == id {
+			delete(m.tokens, tid)
+		}
+	}
+	m.llmUsage = slices.DeleteFunc(m.llmUsage, func(u *models.LLMUsage) bool {
+		return u.ProjectID == id
+	})
+	for nid, n := range m.notes {
+		if n.ProjectID == id {
+			delete(m.notes, nid)
+			m.recordChange(models.ChangeNote, nid, id, models.ChangeDelete)
+		}
+	}
+	m.statusEvents = slices.DeleteFunc(m.statusEvents, func(e *models.IssueStatusEvent) bool {
+		return e.ProjectID == id
+	})
+	m.pruneSessionEvents()
+}
+
+// codeCounter identifies a project's sequence of short codes of one kind.
+type codeCounter struct{ projectID, kind string }
+
+// projectKeyInUse mirrors the SQLite check; the caller holds the lock.
+func (m *MemoryStore) projectKeyInUse(key, projectID string) bool {
+	for _, p := range m.projects {
+		if p.Key == key && p.ID != projectID {
+			return true
+		}
+	}
+	prefix := key + "-"
+	for _, i := range m.issues {
+		if strings.HasPrefix(i.Code, prefix) && i.ProjectID != projectID {
+			return true
+		}
+	}
+	for _, s := range m.sessions {
+		if strings.HasPrefix(s.Code, prefix) && s.ProjectID != projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// nextShortCode hands out the project's next code of kind, or "" for a
+// missing project; the caller holds the write lock.
+func (m *MemoryStore) nextShortCode(projectID, kind string) string {
+	p, ok := m.projects[projectID]
+	if !ok || p.Key == "" {
+		return ""
+	}
+	c := codeCounter{projectID, kind}
+	m.codeCounters[c]++
+	return formatCode(p.Key, kind, m.codeCounters[c])
+}
+
+// SetProjectKey changes a project's key and re-prefixes the codes that
+// carried the old one, keeping their numbers.
+func (m *MemoryStore) SetProjectKey(_ context.Context, projectID, key string) error {
+	if err := models.ValidateProjectKey(key); err != nil {
+		return err
+	}
+	return m.write(func() error {
+		p, ok := m.projects[projectID]
+		if !ok {
+			return fmt.Errorf("project not found: %s", projectID)
+		}
+		old := p.Key
+		if old == key {
+			return nil
+		}
+		if m.projectKeyInUse(key, projectID) {
+			return fmt.Errorf("%w: %s", ErrProjectKeyTaken, key)
+		}
+		p.Key = key
+		p.UpdatedAt = time.Now().UTC()
+		m.recordChange(models.ChangeProject, p.ID, p.ID, models.ChangeUpdate)
+		if old == "" {
+			return nil
+		}
+		for _, i := range m.issues {
+			if rest, ok := strings.CutPrefix(i.Code, old+"-"); ok {
+				i.Code = key + "-" + rest
+			}
+		}
+		for _, s := range m.sessions {
+			if rest, ok := strings.CutPrefix(s.Code, old+"-"); ok {
+				s.Code = key + "-" + rest
+			}
+		}
+		return nil
+	})
+}
+
+// --- Issues ---
+
+func (m *MemoryStore) CreateIssue(ctx context.Context, issue *models.Issue) error {
+	setIssueDefaults(issue)
+	if err := validateIssue("create issue", issue); err != nil {
+		return err
+	}
+	return m.write(func() error {
+		if issue.ID == "" {
+			issue.ID = newULID()
+		}
+		if _, ok := m.issues[issue.ID]; ok {
+			return errUnique("create issue", "issues.id")
+		}
+		if _, ok := m.projects[issue.ProjectID]; !ok {
+			return errForeignKey("create issue")
+		}
+		issue.Code = m.nextShortCode(issue.ProjectID, codeKindIssue)
+		now := time.Now().UTC()
+		issue.CreatedAt = createdTime(ctx, issue.CreatedAt, now)
+		issue.UpdatedAt = createdTime(ctx, issue.UpdatedAt, now)
+
+		stored := copyIssue(issue)
+		stored.SLAState = ""
+		if !importing(ctx) {
+			stored.ClosedAt = nil
+			stored.StartedAt, stored.CompletedAt = nil, nil
+			stored.MergeCommit, stored.MergeBranch, stored.MergedAt = "", "", nil
+			switch stored.Status {
+			case models.IssueStatusInProgress:
+				stored.StartedAt = cloneTime(&now)
+			case models.IssueStatusDone, models.IssueStatusClosed:
+				stored.CompletedAt = cloneTime(&now)
+			}
+		}
+		m.issues[issue.ID] = stored
+		m.recordStatusEvent(stored, "", issue.CreatedAt)
+		m.recordChange(models.ChangeIssue, issue.ID, issue.ProjectID, models.ChangeInsert)
+
+		issue.StartedAt = cloneTime(stored.StartedAt)
+		issue.CompletedAt = cloneTime(stored.CompletedAt)
+		issue.Rank = stored.Rank
+		return nil
+	})
+}
+
+func (m *MemoryStore) GetIssue(_ context.Context, id string) (*models.Issue, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.issues[id]
+	if !ok {
+		return nil, fmt.Errorf("issue not found: %s", id)
+	}
+	issue := copyIssue(stored)
+	for _, t := range m.issueTagList(id) {
+		issue.Tags = append(issue.Tags, t.Name)
+	}
+	return issue, nil
+}
+
+// GetIssueByCode returns the issue with a short code such as "WID-12".
+func (m *MemoryStore) GetIssueByCode(ctx context.Context, code string) (*models.Issue, error) {
+	m.mu.RLock()
+	var id string
+	for _, i := range m.issues {
+		if i.Code == code {
+			id = i.ID
+			break
+		}
+	}
+	m.mu.RUnlock()
+	if id == "" {
+		return nil, fmt.Errorf("issue not found: %s", code)
+	}
+	return m.GetIssue(ctx, id)
+}
+
+var (
+	issueStatusRank   = map[models.IssueStatus]int{"open": 0, "in_progress": 1, "in_review": 2, "done": 3, "closed": 4}
+	issuePriorityRank = map[models.IssuePriority]int{"high": 0, "medium": 1, "low": 2}
+)
+
+func rank[K comparable](ranks map[K]int, k K) int {
+	if r, ok := ranks[k]; ok {
+		return r
+	}
+	return len(ranks)
+}
+
+func (m *MemoryStore) ListIssues(_ context.Context, filter IssueListFilter) ([]*models.Issue, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var issues []*models.Issue
+	for _, issue := range m.issues {
+		if filter.ProjectID != "" && issue.ProjectID != filter.ProjectID {
+			continue
+		}
+		if filter.Status != "" && issue.Status != filter.Status {
+			continue
+		}
+		if filter.Priority != "" && issue.Priority != filter.Priority {
+			continue
+		}
+		if filter.Type != "" && issue.Type != filter.Type {
+			continue
+		}
+		if filter.Assignee != "" && issue.Assignee != filter.Assignee {
+			continue
+		}
+		if filter.Tag != "" && !slices.ContainsFunc(m.issueTagList(issue.ID), func(t *models.Tag) bool {
+			return t.Name == filter.Tag
+		}) {
+			continue
+		}
+		issues = append(issues, copyIssue(issue))
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if ra, rb := rank(issueStatusRank, a.Status), rank(issueStatusRank, b.Status); ra != rb {
+			return ra < rb
+		}
+		if (a.Rank == "") != (b.Rank == "") {
+			return b.Rank == ""
+		}
+		if a.Rank != b.Rank {
+			return a.Rank < b.Rank
+		}
+		if ra, rb := rank(issuePriorityRank, a.Priority), rank(issuePriorityRank, b.Priority); ra != rb {
+			return ra < rb
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+	if filter.Sort == IssueSortSmart {
+		sortIssuesSmart(issues, m.blockedIssueIDs(), time.Now())
+	}
+	return issues, nil
+}
+
+// blockedIssueIDs returns the IDs of issues an unfinished issue blocks. The
+// caller holds m.mu.
+func (m *MemoryStore) blockedIssueIDs() map[string]bool {
+	blocked := make(map[string]bool)
+	for _, rel := range m.relations {
+		if rel.Type != models.RelationBlocks {
+			continue
+		}
+		if src, ok := m.issues[rel.SourceIssueID]; ok && src.Status != models.IssueStatusDone && src.Status != models.IssueStatusClosed {
+			blocked[rel.TargetIssueID] = true
+		}
+	}
+	return blocked
+}
+
+func (m *MemoryStore) UpdateIssue(_ context.Context, issue *models.Issue) error {
+	if err := validateIssue("update issue", issue); err != nil {
+		return err
+	}
+	return m.write(func() error {
+		return m.updateIssue(issue)
+	})
+}
+
+// updateIssue writes issue; m.mu must be held.
+func (m *MemoryStore) updateIssue(issue *models.Issue) error {
+	stored, ok := m.issues[issue.ID]
+	if !ok {
+		return fmt.Errorf("issue not found: %s", issue.ID)
+	}
+	issue.UpdatedAt = time.Now().UTC()
+
+	from := stored.Status
+	stored.Title = issue.Title
+	stored.Description = issue.Description
+	stored.Body = issue.Body
+	stored.AIPrompt = issue.AIPrompt
+	stored.Status = issue.Status
+	stored.Priority = issue.Priority
+	stored.Type = issue.Type
+	stored.GitHubIssue = issue.GitHubIssue
+	stored.Assignee = issue.Assignee
+	stored.Estimate = issue.Estimate
+	stored.Redactions = slices.Clone(issue.Redactions)
+	stored.AcceptanceCriteria = slices.Clone(issue.AcceptanceCriteria)
+	stored.UpdatedAt = issue.UpdatedAt
+	stored.ClosedAt = cloneTime(issue.ClosedAt)
+	m.statusChanged(stored, from)
+	m.recordChange(models.ChangeIssue, stored.ID, stored.ProjectID, models.ChangeUpdate)
+
+	issue.StartedAt = cloneTime(stored.StartedAt)
+	issue.CompletedAt = cloneTime(stored.CompletedAt)
+	return nil
+}
+
+// RecordIssueMerge stamps an issue with where its code landed.
+func (m *MemoryStore) RecordIssueMerge(_ context.Context, issueID, commit, branch string, at time.Time) error {
+	return m.write(func() error {
+		stored, ok := m.issues[issueID]
+		if !ok {
+			return fmt.Errorf("issue not found: %s", issueID)
+		}
+		at = at.UTC()
+		stored.MergeCommit = commit
+		stored.MergeBranch = branch
+		stored.MergedAt = &at
+		stored.UpdatedAt = time.Now().UTC()
+		m.recordChange(models.ChangeIssue, stored.ID, stored.ProjectID, models.ChangeUpdate)
+		return nil
+	})
+}
+
+// statusChanged does what the issue status triggers do in SQLite: record
+// the change and maintain the started/completed timestamps.
+func (m *MemoryStore) statusChanged(issue *models.Issue, from models.IssueStatus) {
+	if issue.Status == from {
+		return
+	}
+	m.recordStatusEvent(issue, from, issue.UpdatedAt)
+	issue.Rank = ""
+
+	if issue.Status == models.IssueStatusInProgress && issue.StartedAt == nil {
+		issue.StartedAt = cloneTime(&issue.UpdatedAt)
+	}
+	switch issue.Status {
+	case models.IssueStatusDone, models.IssueStatusClosed:
+		if issue.CompletedAt == nil {
+			issue.CompletedAt = cloneTime(&issue.UpdatedAt)
+		}
+	default:
+		issue.CompletedAt = nil
+	}
+}
+
+func (m *MemoryStore) recordStatusEvent(issue *models.Issue, from models.IssueStatus, at time.Time) {
+	m.lastEventID++
+	m.statusEvents = append(m.statusEvents, &models.IssueStatusEvent{
+		ID:         m.lastEventID,
+		IssueID:    issue.ID,
+		ProjectID:  issue.ProjectID,
+		FromStatus: from,
+		ToStatus:   issue.Status,
+		ChangedAt:  at,
+	})
+}
+
+func (m *MemoryStore) DeleteIssue(_ context.Context, id string) error {
+	return m.write(func() error {
+		if _, ok := m.issues[id]; !ok {
+			return fmt.Errorf("issue not found: %s", id)
+		}
+		m.detachIssueSessions(id)
+		m.deleteIssue(id)
+		return nil
+	})
+}
+
+// detachIssueSessions unlinks the sessions of an issue about to be deleted,
+// recording an issue_deleted event on each.
+func (m *MemoryStore) detachIssueSessions(id string) {
+	issue := m.issues[id]
+	now := time.Now().UTC()
+	for _, sid := range slices.Sorted(maps.Keys(m.sessions)) {
+		sess := m.sessions[sid]
+		if sess.IssueID != id {
+			continue
+		}
+		m.recordSessionEvent(sid, models.SessionEventIssueDeleted, id, issue.Title, now)
+		sess.IssueID, sess.ReviewID = "", ""
+		m.recordChange(models.ChangeSession, sid, sess.ProjectID, models.ChangeUpdate)
+	}
+	for _, sess := range m.sessions {
+		if extra := strings.Split(sess.ExtraIssueIDs, ","); slices.Contains(extra, id) {
+			sess.ExtraIssueIDs = strings.Join(slices.DeleteFunc(extra, func(e string) bool { return e == id }), ",")
+		}
+	}
+}
+
+// deleteIssue removes an issue and everything that cascades from it. As in
+// SQLite, the changes of cascaded records whose project is the issue's have
+// no project.
+func (m *MemoryStore) deleteIssue(id string) {
+	issue := m.issues[id]
+	delete(m.issues, id)
+	delete(m.issueTags, id)
+	m.recordChange(models.ChangeIssue, id, issue.ProjectID, models.ChangeDelete)
+	for rid, r := range m.reviews {
+		if r.IssueID == id {
+			delete(m.reviews, rid)
+			m.recordChange(models.ChangeReview, rid, "", models.ChangeDelete)
+		}
+	}
+	for sid, rs := range m.shares {
+		if rs.IssueID == id {
+			delete(m.shares, sid)
+		}
+	}
+	for rid, r := range m.relations {
+		if r.SourceIssueID == id || r.TargetIssueID == id {
+			delete(m.relations, rid)
+			m.recordChange(models.ChangeRelation, rid, m.issueProject(r.SourceIssueID), models.ChangeDelete)
+		}
+	}
+	for bid, b := range m.breaches {
+		if b.IssueID == id {
+			delete(m.breaches, bid)
+		}
+	}
+	for cid, c := range m.closures {
+		if c.IssueID == id {
+			delete(m.closures, cid)
+		}
+	}
+	for rid, r := range m.prReviews {
+		if r.IssueID == id {
+			delete(m.prReviews, rid)
+		}
+	}
+	delete(m.boardItems, id)
+	for wid, w := range m.watchers {
+		if w.IssueID == id {
+			delete(m.watchers, wid)
+		}
+	}
+	for cid, c := range m.comments {
+		if c.IssueID == id {
+			delete(m.comments, cid)
+			m.recordChange(models.ChangeComment, cid, "", models.ChangeDelete)
+		}
+	}
+	m.statusEvents = slices.DeleteFunc(m.statusEvents, func(e *models.IssueStatusEvent) bool {
+		return e.IssueID == id
+	})
+}
+
+func (m *MemoryStore) BulkUpdateIssueStatus(_ context.Context, ids []string, status models.IssueStatus) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	if !status.Valid() {
+		return 0, fmt.Errorf("bulk update issue status: invalid issue status %q", status)
+	}
+	var n int64
+	err := m.write(func() error {
+		now := time.Now().UTC()
+		for _, id := range uniqueIDs(ids) {
+			issue, ok := m.issues[id]
+			if !ok {
+				continue
+			}
+			from := issue.Status
+			issue.Status = status
+			issue.UpdatedAt = now
+			m.statusChanged(issue, from)
+			m.recordChange(models.ChangeIssue, id, issue.ProjectID, models.ChangeUpdate)
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
+
+func (m *MemoryStore) BulkDeleteIssues(_ context.Context, ids []string, force bool) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	var n int64
+	err := m.write(func() error {
+		ids = uniqueIDs(ids)
+		if !force {
+			var busy []string
+			for _, id := range ids {
+				for _, sess := range m.sessions {
+					if sess.IssueID == id && sess.Status.IsOpen() {
+						busy = append(busy, id)
+						break
+					}
+				}
+			}
+			if len(busy) > 0 {
+				return fmt.Errorf("bulk delete issues: %w: %s", ErrIssuesHaveOpenSessions, strings.Join(busy, ", "))
+			}
+		}
+		for _, id := range ids {
+			if _, ok := m.issues[id]; ok {
+				m.detachIssueSessions(id)
+				m.deleteIssue(id)
+				n++
+			}
+		}
+		return nil
+	})
+	return n, err
+}
+
+func uniqueIDs(ids []string) []string {
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// --- Tags ---
+
+func (m *MemoryStore) CreateTag(_ context.Context, tag *models.Tag) error {
+	return m.write(func() error {
+		if tag.ID == "" {
+			tag.ID = newULID()
+		}
+		if _, ok := m.tags[tag.ID]; ok {
+			return errUnique("create tag", "tags.id")
+		}
+		for _, other := range m.tags {
+			if other.Name == tag.Name {
+				return errUnique("create tag", "tags.name")
+			}
+		}
+		tag.CreatedAt = time.Now().UTC()
+		c := *tag
+		m.tags[tag.ID] = &c
+		return nil
+	})
+}
+
+func (m *MemoryStore) ListTags(_ context.Context) ([]*models.Tag, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var tags []*models.Tag
+	for _, t := range m.tags {
+		c := *t
+		tags = append(tags, &c)
+	}
+	sortTags(tags)
+	return tags, nil
+}
+
+func sortTags(tags []*models.Tag) {
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+}
+
+func (m *MemoryStore) DeleteTag(_ context.Context, id string) error {
+	return m.write(func() error {
+		if _, ok := m.tags[id]; !ok {
+			return fmt.Errorf("tag not found: %s", id)
+		}
+		delete(m.tags, id)
+		for _, tagIDs := range m.issueTags {
+			delete(tagIDs, id)
+		}
+		return nil
+	})
+}
+
+func (m *MemoryStore) ListTagUsage(_ context.Context) ([]*models.TagUsage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var usage []*models.TagUsage
+	for _, t := range m.tags {
+		u := &models.TagUsage{Tag: *t}
+		for issueID, tagIDs := range m.issueTags {
+			issue, ok := m.issues[issueID]
+			if !ok || !tagIDs[t.ID] {
+				continue
+			}
+			u.Issues++
+			if issue.Status != models.IssueStatusDone && issue.Status != models.IssueStatusClosed {
+				u.Open++
+			}
+		}
+		usage = append(usage, u)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Name < usage[j].Name })
+	return usage, nil
+}
+
+func (m *MemoryStore) RenameTag(_ context.Context, id, name string) error {
+	return m.write(func() error {
+		tag, ok := m.tags[id]
+		if !ok {
+			return fmt.Errorf("tag not found: %s", id)
+		}
+		for _, other := range m.tags {
+			if other.ID != id && other.Name == name {
+				return fmt.Errorf("rename tag: a tag named %q already exists", name)
+			}
+		}
+		tag.Name = name
+		return nil
+	})
+}
+
+func (m *MemoryStore) MergeTag(_ context.Context, id, targetID string) error {
+	if id == targetID {
+		return fmt.Errorf("merge tag: cannot merge a tag into itself")
+	}
+	return m.write(func() error {
+		for _, tagID := range []string{id, targetID} {
+			if _, ok := m.tags[tagID]; !ok {
+				return fmt.Errorf("tag not found: %s", tagID)
+			}
+		}
+		for _, tagIDs := range m.issueTags {
+			if tagIDs[id] {
+				delete(tagIDs, id)
+				tagIDs[targetID] = true
+			}
+		}
+		delete(m.tags, id)
+		return nil
+	})
+}
+
+func (m *MemoryStore) DeleteUnusedTags(_ context.Context) ([]*models.Tag, error) {
+	var deleted []*models.Tag
+	err := m.write(func() error {
+		used := make(map[string]bool)
+		for _, tagIDs := range m.issueTags {
+			for tagID := range tagIDs {
+				used[tagID] = true
+			}
+		}
+		for id, t := range m.tags {
+			if !used[id] {
+				c := *t
+				deleted = append(deleted, &c)
+				delete(m.tags, id)
+			}
+		}
+		return nil
+	})
+	sortTags(deleted)
+	return deleted, err
+}
+
+func (m *MemoryStore) TagIssue(_ context.Context, issueID, tagID string) error {
+	return m.write(func() error {
+		_, issueOK := m.issues[issueID]
+		_, tagOK := m.tags[tagID]
+		if !issueOK || !tagOK {
+			return errForeignKey("tag issue")
+		}
+		if m.issueTags[issueID] == nil {
+			m.issueTags[issueID] = make(map[string]bool)
+		}
+		m.issueTags[issueID][tagID] = true
+		return nil
+	})
+}
+
+func (m *MemoryStore) UntagIssue(_ context.Context, issueID, tagID string) error {
+	return m.write(func() error {
+		delete(m.issueTags[issueID], tagID)
+		return nil
+	})
+}
+
+func (m *MemoryStore) GetIssueTags(_ context.Context, issueID string) ([]*models.Tag, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.issueTagList(issueID), nil
+}
+
+// issueTagList returns copies of an issue's tags ordered by name.
+func (m *MemoryStore) issueTagList(issueID string) []*models.Tag {
+	var tags []*models.Tag
+	for tagID := range m.issueTags[issueID] {
+		if t, ok := m.tags[tagID]; ok {
+			c := *t
+			tags = append(tags, &c)
+		}
+	}
+	sortTags(tags)
+	return tags
+}
+
+// --- Agent Sessions ---
+
+// openSessionStatuses are the statuses under which a branch may have only one
+// session per project (the partial unique index in SQLite).
+var openSessionStatuses = []models.SessionStatus{
+	models.SessionStatusActive, models.SessionStatusIdle, models.SessionStatusBlocked,
+}
+
+// checkOpenBranch enforces one open session per project branch.
+func (m *MemoryStore) checkOpenBranch(op string, s *models.AgentSession) error {
+	if !slices.Contains(openSessionStatuses, s.Status) {
+		return nil
+	}
+	for _, other := range m.sessions {
+		if other.ID != s.ID && other.ProjectID == s.ProjectID && other.Branch == s.Branch &&
+			slices.Contains(openSessionStatuses, other.Status) {
+			return fmt.Errorf("%s: %w", op, branchReservedBy(s.Branch, other))
+		}
+	}
+	return nil
+}
+
+// checkIssueLock enforces one active session per issue, over each session's
+// IssueID and ExtraIssueIDs (agent_session_issues in SQLite).
+func (m *MemoryStore) checkIssueLock(op string, s *models.AgentSession) error {
+	if s.Status != models.SessionStatusActive {
+		return nil
+	}
+	for _, issueID := range s.IssueIDs() {
+		for _, sid := range slices.Sorted(maps.Keys(m.sessions)) {
+			other := m.sessions[sid]
+			if other.ID != s.ID && other.Status == models.SessionStatusActive && slices.Contains(other.IssueIDs(), issueID) {
+				return fmt.Errorf("%s: %w", op, issueLockedBy(issueID, other))
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) CreateAgentSession(ctx context.Context, session *models.AgentSession) error {
+	return m.write(func() error {
+		if session.ID == "" {
+			session.ID = newULID()
+		}
+		if _, ok := m.sessions[session.ID]; ok {
+			return errUnique("create agent session", "agent_sessions.id")
+		}
+		if _, ok := m.projects[session.ProjectID]; !ok {
+			return errForeignKey("create agent session")
+		}
+		if err := checkSessionStatus("create agent session", session.Status); err != nil {
+			return err
+		}
+		if err := m.checkOpenBranch("create agent session", session); err != nil {
+			return err
+		}
+		if err := m.checkIssueLock("create agent session", session); err != nil {
+			return err
+		}
+		session.Code = m.nextShortCode(session.ProjectID, codeKindSession)
+		session.StartedAt = createdTime(ctx, session.StartedAt, time.Now().UTC())
+		if session.ConflictState == "" {
+			session.ConflictState = models.ConflictStateNone
+		}
+		if session.ConflictFiles == "" {
+			session.ConflictFiles = "[]"
+		}
+		session.ExtraIssueIDs = extraIssueIDs(session)
+		stored := copySession(session)
+		stored.EndedAt = nil
+		m.sessions[session.ID] = stored
+		m.recordSessionEvent(stored.ID, models.SessionEventStarted, string(stored.Status), stored.Branch, stored.StartedAt)
+		m.recordChange(models.ChangeSession, stored.ID, stored.ProjectID, models.ChangeInsert)
+		return nil
+	})
+}
+
+func (m *MemoryStore) GetAgentSession(_ context.Context, id string) (*models.AgentSession, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if s, ok := m.sessions[id]; ok {
+		return copySession(s), nil
+	}
+	return nil, fmt.Errorf("agent session not found: %s", id)
+}
+
+// GetAgentSessionByCode returns the session with a short code such as
+// "WID-S3".
+func (m *MemoryStore) GetAgentSessionByCode(_ context.Context, code string) (*models.AgentSession, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, s := range m.sessions {
+		if s.Code == code {
+			return copySession(s), nil
+		}
+	}
+	return nil, fmt.Errorf("agent session not found: %s", code)
+}
+
+func (m *MemoryStore) GetAgentSessionByWorktreePath(_ context.Context, path string) (*models.AgentSession, error) {
+	sessions := m.listSessions(0, func(s *models.AgentSession) bool {
+		return s.WorktreePath == path && slices.Contains(openSessionStatuses, s.Status)
+	})
+	if len(sessions) == 0 {
+		return nil, fmt.Errorf("no open session for worktree: %s", path)
+	}
+	return sessions[0], nil
+}
+
+func (m *MemoryStore) ListAgentSessions(_ context.Context, projectID string, limit int) ([]*models.AgentSession, error) {
+	return m.listSessions(limit, func(s *models.AgentSession) bool {
+		return projectID == "" || s.ProjectID == projectID
+	}), nil
+}
+
+func (m *MemoryStore) ListAgentSessionsByStatus(_ context.Context, projectID string, statuses []models.SessionStatus, limit int) ([]*models.AgentSession, error) {
+	return m.listSessions(limit, func(s *models.AgentSession) bool {
+		return (projectID == "" || s.ProjectID == projectID) &&
+			(len(statuses) == 0 || slices.Contains(statuses, s.Status))
+	}), nil
+}
+
+func (m *MemoryStore) ListAgentSessionsFiltered(_ context.Context, filter SessionListFilter) ([]*models.AgentSession, error) {
+	return m.listSessions(filter.Limit, func(s *models.AgentSession) bool {
+		return (filter.ProjectID == "" || s.ProjectID == filter.ProjectID) &&
+			(filter.IssueID == "" || s.IssueID == filter.IssueID) &&
+			(filter.Branch == "" || s.Branch == filter.Branch) &&
+			(len(filter.Statuses) == 0 || slices.Contains(filter.Statuses, s.Status)) &&
+			(filter.Label == "" || s.HasLabel(filter.Label))
+	}), nil
+}
+
+func (m *MemoryStore) ListAgentSessionsByWorktreePaths(_ context.Context, paths []string) ([]*models.AgentSession, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	return m.listSessions(0, func(s *models.AgentSession) bool {
+		return slices.Contains(paths, s.WorktreePath)
+	}), nil
+}
+
+// listSessions returns copies of the matching sessions, newest first,
+// keeping at most limit of them (0 = all).
+func (m *MemoryStore) listSessions(limit int, keep func(*models.AgentSession) bool) []*models.AgentSession {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var sessions []*models.AgentSession
+	for _, s := range m.sessions {
+		if keep(s) {
+			sessions = append(sessions, copySession(s))
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		a, b := sessions[i], sessions[j]
+		if !a.StartedAt.Equal(b.StartedAt) {
+			return a.StartedAt.After(b.StartedAt)
+		}
+		return a.ID < b.ID
+	})
+	if limit > 0 && len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	return sessions
+}
+
+func (m *MemoryStore) UpdateAgentSession(_ context.Context, session *models.AgentSession) error {
+	return m.write(func() error { return m.updateSession(session, "") })
+}
+
+// TransitionAgentSession writes a session like UpdateAgentSession, but only if
+// the stored session's status is still from; otherwise it returns
+// ErrSessionStatusChanged and writes nothing. The status event of the change
+// records cause.
+func (m *MemoryStore) TransitionAgentSession(_ context.Context, session *models.AgentSession, from models.SessionStatus, cause string) error {
+	return m.write(func() error {
+		stored, ok := m.sessions[session.ID]
+		if !ok {
+			return fmt.Errorf("agent session not found: %s", session.ID)
+		}
+		if stored.Status != from {
+			return fmt.Errorf("%w: session %s is %s, not %s", ErrSessionStatusChanged, session.ID, stored.Status, from)
+		}
+		return m.updateSession(session, cause)
+	})
+}
+
+// TouchAgentSession sets an open session's LastActiveAt to at and adds the
+// focus time since its last activity, reporting false if the session is
+// missing or has ended.
+func (m *MemoryStore) TouchAgentSession(_ context.Context, id string, at time.Time) (bool, error) {
+	touched := false
+	err := m.write(func() error {
+		stored, ok := m.sessions[id]
+		if !ok || !slices.Contains(openSessionStatuses, stored.Status) {
+			return nil
+		}
+		updated := copySession(stored)
+		at = at.UTC()
+		updated.FocusSeconds += models.FocusAfter(stored.LastActiveAt, at).Seconds()
+		updated.LastActiveAt = &at
+		m.sessions[id] = updated
+		m.recordChange(models.ChangeSession, id, updated.ProjectID, models.ChangeUpdate)
+		touched = true
+		return nil
+	})
+	return touched, err
+}
+
+// updateSession stores a session update; the caller holds the write lock.
+func (m *MemoryStore) updateSession(session *models.AgentSession, cause string) error {
+	stored, ok := m.sessions[session.ID]
+	if !ok {
+		return fmt.Errorf("agent session not found: %s", session.ID)
+	}
+	if err := checkSessionStatus("update agent session", session.Status); err != nil {
+		return err
+	}
+	// Identity fields (code, project, issue, branch, start, review, inline
+	// and sparse paths) are fixed at creation, and focus time is only added
+	// by TouchAgentSession, as in SQLiteStore.
+	updated := copySession(session)
+	updated.Code = stored.Code
+	updated.FocusSeconds = stored.FocusSeconds
+	updated.ProjectID = stored.ProjectID
+	updated.IssueID = stored.IssueID
+	updated.Branch = stored.Branch
+	updated.StartedAt = stored.StartedAt
+	updated.ReviewAttempt = stored.ReviewAttempt
+	updated.ReviewID = stored.ReviewID
+	updated.Inline = stored.Inline
+	updated.SparsePaths = stored.SparsePaths
+	updated.ExtraIssueIDs = stored.ExtraIssueIDs
+	if err := m.checkOpenBranch("update agent session", updated); err != nil {
+		return err
+	}
+	if err := m.checkIssueLock("update agent session", updated); err != nil {
+		return err
+	}
+	m.sessions[session.ID] = updated
+	m.recordSessionChanges(stored, updated, cause)
+	m.recordChange(models.ChangeSession, updated.ID, updated.ProjectID, models.ChangeUpdate)
+	return nil
+}
+
+// recordSessionChanges records the events the session_events triggers
+// record for an update from old to cur, tagging a status change with cause.
+func (m *MemoryStore) recordSessionChanges(old, cur *models.AgentSession, cause string) {
+	now := time.Now().UTC()
+	if old.Status != cur.Status {
+		detail := ""
+		switch cur.Status {
+		case models.SessionStatusBlocked:
+			detail = cur.BlockedReason
+		case models.SessionStatusCompleted, models.SessionStatusAbandoned:
+			detail = cur.Outcome
+		}
+		at := now
+		switch {
+		case cur.Status == models.SessionStatusBlocked && cur.BlockedAt != nil:
+			at = *cur.BlockedAt
+		case cur.EndedAt != nil && old.EndedAt == nil:
+			at = *cur.EndedAt
+		}
+		e := m.recordSessionEvent(cur.ID, models.SessionEventStatus, string(cur.Status), detail, at)
+		e.From, e.Cause = string(old.Status), cause
+	}
+	synced := cur.LastSyncAt != nil && (old.LastSyncAt == nil || !old.LastSyncAt.Equal(*cur.LastSyncAt))
+	sameSync := (old.LastSyncAt == nil && cur.LastSyncAt == nil) ||
+		(old.LastSyncAt != nil && cur.LastSyncAt != nil && old.LastSyncAt.Equal(*cur.LastSyncAt))
+	if synced {
+		m.recordSessionEvent(cur.ID, models.SessionEventSync, string(cur.ConflictState), cur.LastError, *cur.LastSyncAt)
+	}
+	if old.ConflictState != cur.ConflictState && sameSync {
+		files := cur.ConflictFiles
+		if cur.ConflictState == models.ConflictStateNone {
+			files = ""
+		}
+		m.recordSessionEvent(cur.ID, models.SessionEventConflict, string(cur.ConflictState), files, now)
+	}
+	if cur.LastError != "" && old.LastError != cur.LastError && sameSync && old.ConflictState == cur.ConflictState {
+		m.recordSessionEvent(cur.ID, models.SessionEventError, "", cur.LastError, now)
+	}
+}
+
+func (m *MemoryStore) recordSessionEvent(sessionID string, kind models.SessionEventKind, value, detail string, at time.Time) *models.SessionEvent {
+	m.lastSessEvID++
+	e := &models.SessionEvent{
+		ID:        m.lastSessEvID,
+		SessionID: sessionID,
+		Kind:      kind,
+		Value:     value,
+		Detail:    detail,
+		At:        at,
+	}
+	m.sessEvents = append(m.sessEvents, e)
+	return e
+}
+
+// pruneSessionEvents drops the events of deleted sessions.
+func (m *MemoryStore) pruneSessionEvents() {
+	m.sessEvents = slices.DeleteFunc(m.sessEvents, func(e *models.SessionEvent) bool {
+		_, ok := m.sessions[e.SessionID]
+		return !ok
+	})
+}
+
+// DeleteStaleSessions removes the sessions matching criteria, which must be
+// valid.
+func (m *MemoryStore) DeleteStaleSessions(_ context.Context, criteria StaleSessionCriteria) (int64, error) {
+	if err := criteria.Validate(); err != nil {
+		return 0, fmt.Errorf("delete stale sessions: %w", err)
+	}
+	return m.deleteSessions(func(s *models.AgentSession) bool { return isStale(s, criteria, time.Now()) })
+}
+
+// DeleteAllStaleSessions removes all sessions matching the default stale criteria
+// (abandoned, 0 commits, duration < 60s).
+func (m *MemoryStore) DeleteAllStaleSessions(_ context.Context) (int64, error) {
+	criteria := DefaultStaleSessionCriteria()
+	return m.deleteSessions(func(s *models.AgentSession) bool { return isStale(s, criteria, time.Now()) })
+}
+
+// ListStaleSessions returns ended sessions matching the given stale criteria.
+func (m *MemoryStore) ListStaleSessions(_ context.Context, criteria StaleSessionCriteria) ([]*models.AgentSession, error) {
+	now := time.Now()
+	return m.listSessions(0, func(s *models.AgentSession) bool { return isStale(s, criteria, now) }), nil
+}
+
+// DeleteStaleSessionsByID removes the sessions with the given IDs that still
+// match criteria.
+func (m *MemoryStore) DeleteStaleSessionsByID(_ context.Context, criteria StaleSessionCriteria, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	now := time.Now()
+	return m.deleteSessions(func(s *models.AgentSession) bool {
+		return slices.Contains(ids, s.ID) && isStale(s, criteria, now)
+	})
+}
+
+func (m *MemoryStore) deleteSessions(match func(*models.AgentSession) bool) (int64, error) {
+	var n int64
+	err := m.write(func() error {
+		for id, s := range m.sessions {
+			if match(s) {
+				delete(m.sessions, id)
+				m.recordChange(models.ChangeSession, id, s.ProjectID, models.ChangeDelete)
+				n++
+			}
+		}
+		m.pruneSessionEvents()
+		return nil
+	})
+	return n, err
+}
+
+// isStale applies StaleSessionCriteria the way staleSessionWhere does,
+// comparing times at whole-second precision.
+func isStale(s *models.AgentSession, c StaleSessionCriteria, now time.Time) bool {
+	statuses := c.Statuses
+	if len(statuses) == 0 {
+		statuses = []models.SessionStatus{models.SessionStatusAbandoned}
+	}
+	if !slices.Contains(statuses, s.Status) || s.CommitCount > c.MaxCommits || s.EndedAt == nil {
+		return false
+	}
+	if c.ProjectID != "" && s.ProjectID != c.ProjectID {
+		return false
+	}
+	if c.Branch != "" && s.Branch != c.Branch {
+		return false
+	}
+	ended := s.EndedAt.Truncate(time.Second)
+	if c.MaxDuration > 0 && ended.Sub(s.StartedAt.Truncate(time.Second)) >= c.MaxDuration {
+		return false
+	}
+	if c.MinAge > 0 && now.Sub(ended) < c.MinAge {
+		return false
+	}
+	return true
+}
+
+// --- Issue Reviews ---
+
+func (m *MemoryStore) CreateIssueReview(ctx context.Context, review *models.IssueReview) error {
+	return m.write(func() error {
+		return m.insertReview(ctx, review)
+	})
+}
+
+// insertReview stores a new review; the caller holds m.mu.
+func (m *MemoryStore) insertReview(ctx context.Context, review *models.IssueReview) error {
+	if review.ID == "" {
+		review.ID = newULID()
+	}
+	if _, ok := m.reviews[review.ID]; ok {
+		return errUnique("create issue review", "issue_reviews.id")
+	}
+	if _, ok := m.issues[review.IssueID]; !ok {
+		return errForeignKey("create issue review")
+	}
+	if err := review.Validate(); err != nil {
+		return fmt.Errorf("create issue review: %w", err)
+	}
+	review.CreatedAt = createdTime(ctx, review.CreatedAt, time.Now().UTC())
+	c := *review
+	c.FailureReasons = slices.Clone(review.FailureReasons)
+	c.Criteria = slices.Clone(review.Criteria)
+	m.reviews[review.ID] = &c
+	m.recordChange(models.ChangeReview, review.ID, m.issueProject(review.IssueID), models.ChangeInsert)
+	return nil
+}
+
+func (m *MemoryStore) ListIssueReviews(_ context.Context, issueID string) ([]*models.IssueReview, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var reviews []*models.IssueReview
+	for _, r := range m.reviews {
+		if r.IssueID == issueID {
+			c := *r
+			c.FailureReasons = slices.Clone(r.FailureReasons)
+			c.Criteria = slices.Clone(r.Criteria)
+			reviews = append(reviews, &c)
+		}
+	}
+	sort.Slice(reviews, func(i, j int) bool {
+		a, b := reviews[i], reviews[j]
+		if !a.ReviewedAt.Equal(b.ReviewedAt) {
+			return a.ReviewedAt.After(b.ReviewedAt)
+		}
+		return a.ID < b.ID
+	})
+	return reviews, nil
+}
+
+// --- Review Shares ---
+
+func (m *MemoryStore) CreateReviewShare(_ context.Context, share *models.ReviewShare) error {
+	return m.write(func() error {
+		if share.ID == "" {
+			share.ID = newULID()
+		}
+		if _, ok := m.shares[share.ID]; ok {
+			return errUnique("create review share", "review_shares.id")
+		}
+		if _, ok := m.issues[share.IssueID]; !ok {
+			return errForeignKey("create review share")
+		}
+		for _, other := range m.shares {
+			if other.TokenHash == share.TokenHash {
+				return errUnique("create review share", "review_shares.token_hash")
+			}
+		}
+		share.CreatedAt = time.Now().UTC()
+		share.ExpiresAt = share.ExpiresAt.UTC()
+		c := *share
+		m.shares[share.ID] = &c
+		return nil
+	})
+}
+
+func (m *MemoryStore) GetReviewShareByHash(_ context.Context, hash string) (*models.ReviewShare, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, rs := range m.shares {
+		if rs.TokenHash == hash {
+			c := *rs
+			return &c, nil
+		}
+	}
+	return nil, fmt.Errorf("review share not found")
+}
+
+// ListReviewShares lists an issue's review links, newest first.
+func (m *MemoryStore) ListReviewShares(_ context.Context, issueID string) ([]*models.ReviewShare, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var shares []*models.ReviewShare
+	for _, rs := range m.shares {
+		if rs.IssueID == issueID {
+			c := *rs
+			shares = append(shares, &c)
+		}
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		a, b := shares[i], shares[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	})
+	return shares, nil
+}
+
+func (m *MemoryStore) DeleteReviewShare(_ context.Context, id string) error {
+	return m.write(func() error {
+		if _, ok := m.shares[id]; !ok {
+			return fmt.Errorf("review share not found: %s", id)
+		}
+		delete(m.shares, id)
+		return nil
+	})
+}
+
+// SubmitReviewShare records review as the verdict of a review link, unless
+// the link already has one (ErrReviewShareUsed), in which case nothing is
+// written.
+func (m *MemoryStore) SubmitReviewShare(ctx context.Context, shareID string, review *models.IssueReview) error {
+	return m.write(func() error {
+		rs, ok := m.shares[shareID]
+		if !ok {
+			return fmt.Errorf("review share not found: %s", shareID)
+		}
+		if rs.ReviewID != "" {
+			return fmt.Errorf("%w: review %s", ErrReviewShareUsed, rs.ReviewID)
+		}
+		if err := m.insertReview(ctx, review); err != nil {
+			return err
+		}
+		c := *rs
+		c.ReviewID = review.ID
+		m.shares[shareID] = &c
+		return nil
+	})
+}
+
+// --- Issue Relations ---
+
+func (m *MemoryStore) CreateIssueRelation(ctx context.Context, rel *models.IssueRelation) error {
+	if rel.SourceIssueID == rel.TargetIssueID {
+		return fmt.Errorf("create issue relation: an issue cannot relate to itself")
+	}
+	return m.write(func() error {
+		if rel.ID == "" {
+			rel.ID = newULID()
+		}
+		_, sourceOK := m.issues[rel.SourceIssueID]
+		_, targetOK := m.issues[rel.TargetIssueID]
+		if !sourceOK || !targetOK {
+			return errForeignKey("create issue relation")
+		}
+		for _, other := range m.relations {
+			if other.ID == rel.ID || (other.SourceIssueID == rel.SourceIssueID &&
+				other.TargetIssueID == rel.TargetIssueID && other.Type == rel.Type) {
+				return fmt.Errorf("create issue relation: relation already exists")
+			}
+		}
+		rel.CreatedAt = createdTime(ctx, rel.CreatedAt, time.Now().UTC())
+		c := *rel
+		m.relations[rel.ID] = &c
+		m.recordChange(models.ChangeRelation, rel.ID, m.issueProject(rel.SourceIssueID), models.ChangeInsert)
+		return nil
+	})
+}
+
+// MergeIssues writes merge. Everything it refers to is checked first, so a
+// failed merge writes nothing.
+func (m *MemoryStore) MergeIssues(_ context.Context, merge *IssueMerge) error {
+	issues := append([]*models.Issue{merge.Target}, merge.Duplicates...)
+	for _, issue := range issues {
+		if err := validateIssue("update issue", issue); err != nil {
+			return fmt.Errorf("merge issues: %w", err)
+		}
+	}
+	return m.write(func() error {
+		for _, issue := range issues {
+			if _, ok := m.issues[issue.ID]; !ok {
+				return fmt.Errorf("merge issues: issue not found: %s", issue.ID)
+			}
+		}
+		for _, tagID := range merge.TagIDs {
+			if _, ok := m.tags[tagID]; !ok {
+				return errForeignKey("merge issues")
+			}
+		}
+		for _, rel := range merge.Relations {
+			if rel.SourceIssueID == rel.TargetIssueID {
+				return fmt.Errorf("merge issues: an issue cannot relate to itself")
+			}
+			if m.issues[rel.SourceIssueID] == nil || m.issues[rel.TargetIssueID] == nil {
+				return errForeignKey("merge issues")
+			}
+		}
+		for _, w := range merge.Watchers {
+			if m.issues[w.IssueID] == nil {
+				return errForeignKey("merge issues")
+			}
+		}
+		for _, c := range merge.Comments {
+			if m.issues[c.IssueID] == nil {
+				return errForeignKey("merge issues")
+			}
+		}
+
+		for _, issue := range issues {
+			if err := m.updateIssue(issue); err != nil {
+				return err
+			}
+		}
+		if m.issueTags[merge.Target.ID] == nil && len(merge.TagIDs) > 0 {
+			m.issueTags[merge.Target.ID] = make(map[string]bool)
+		}
+		for _, tagID := range merge.TagIDs {
+			m.issueTags[merge.Target.ID][tagID] = true
+		}
+	watchers:
+		for _, w := range merge.Watchers {
+			for _, other := range m.watchers {
+				if other.IssueID == w.IssueID && other.Target == w.Target {
+					continue watchers
+				}
+			}
+			if w.ID == "" {
+				w.ID = newULID()
+			}
+			w.CreatedAt = time.Now().UTC()
+			c := *w
+			m.watchers[w.ID] = &c
+		}
+	relations:
+		for _, rel := range merge.Relations {
+			for _, other := range m.relations {
+				if other.SourceIssueID == rel.SourceIssueID && other.TargetIssueID == rel.TargetIssueID && other.Type == rel.Type {
+					continue relations
+				}
+			}
+			if rel.ID == "" {
+				rel.ID = newULID()
+			}
+			rel.CreatedAt = time.Now().UTC()
+			c := *rel
+			m.relations[rel.ID] = &c
+			m.recordChange(models.ChangeRelation, rel.ID, m.issueProject(rel.SourceIssueID), models.ChangeInsert)
+		}
+		for _, c := range merge.Comments {
+			if c.ID == "" {
+				c.ID = newULID()
+			}
+			c.CreatedAt = time.Now().UTC()
+			cp := *c
+			m.comments[c.ID] = &cp
+			m.recordChange(models.ChangeComment, c.ID, m.issueProject(c.IssueID), models.ChangeInsert)
+		}
+		return nil
+	})
+}
+
+// ListIssueRelations returns relations where the issue is either the source or the target.
+func (m *MemoryStore) ListIssueRelations(_ context.Context, issueID string) ([]*models.IssueRelation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var rels []*models.IssueRelation
+	for _, r := range m.relations {
+		if r.SourceIssueID == issueID || r.TargetIssueID == issueID {
+			c := *r
+			rels = append(rels, &c)
+		}
+	}
+	sort.Slice(rels, func(i, j int) bool {
+		if !rels[i].CreatedAt.Equal(rels[j].CreatedAt) {
+			return rels[i].CreatedAt.Before(rels[j].CreatedAt)
+		}
+		return rels[i].ID < rels[j].ID
+	})
+	return rels, nil
+}
+
+func (m *MemoryStore) DeleteIssueRelation(_ context.Context, id string) error {
+	return m.write(func() error {
+		rel, ok := m.relations[id]
+		if !ok {
+			return fmt.Errorf("issue relation not found: %s", id)
+		}
+		delete(m.relations, id)
+		m.recordChange(models.ChangeRelation, id, m.issueProject(rel.SourceIssueID), models.ChangeDelete)
+		return nil
+	})
+}
+
+// --- Issue Watchers and Comments ---
+
+// AddIssueWatcher subscribes w.Target to the issue and reports whether it was
+// added; a target already watching the issue is left as is.
+func (m *MemoryStore) AddIssueWatcher(_ context.Context, w *models.IssueWatcher) (bool, error) {
+	added := false
+	err := m.write(func() error {
+		if _, ok := m.issues[w.IssueID]; !ok {
+			return errForeignKey("add issue watcher")
+		}
+		for _, other := range m.watchers {
+			if other.IssueID == w.IssueID && other.Target == w.Target {
+				return nil
+			}
+		}
+		if w.ID == "" {
+			w.ID = newULID()
+		}
+		w.CreatedAt = time.Now().UTC()
+		c := *w
+		m.watchers[w.ID] = &c
+		added = true
+		return nil
+	})
+	return added, err
+}
+
+// ListIssueWatchers returns an issue's watchers, oldest first. An empty
+// issueID lists the watchers of every issue.
+func (m *MemoryStore) ListIssueWatchers(_ context.Context, issueID string) ([]*models.IssueWatcher, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var watchers []*models.IssueWatcher
+	for _, w := range m.watchers {
+		if issueID == "" || w.IssueID == issueID {
+			c := *w
+			watchers = append(watchers, &c)
+		}
+	}
+	sort.Slice(watchers, func(i, j int) bool {
+		if !watchers[i].CreatedAt.Equal(watchers[j].CreatedAt) {
+			return watchers[i].CreatedAt.Before(watchers[j].CreatedAt)
+		}
+		return watchers[i].ID < watchers[j].ID
+	})
+	return watchers, nil
+}
+
+func (m *MemoryStore) RemoveIssueWatcher(_ context.Context, issueID, target string) error {
+	return m.write(func() error {
+		for id, w := range m.watchers {
+			if w.IssueID == issueID && w.Target == target {
+				delete(m.watchers, id)
+				return nil
+			}
+		}
+		return fmt.Errorf("issue watcher not found: %s", target)
+	})
+}
+
+func (m *MemoryStore) CreateIssueComment(ctx context.Context, c *models.IssueComment) error {
+	return m.write(func() error {
+		if _, ok := m.issues[c.IssueID]; !ok {
+			return errForeignKey("create issue comment")
+		}
+		if c.ID == "" {
+			c.ID = newULID()
+		}
+		c.CreatedAt = createdTime(ctx, c.CreatedAt, time.Now().UTC())
+		cp := *c
+		m.comments[c.ID] = &cp
+		m.recordChange(models.ChangeComment, c.ID, m.issueProject(c.IssueID), models.ChangeInsert)
+		return nil
+	})
+}
+
+// ListIssueComments returns an issue's comments, oldest first.
+func (m *MemoryStore) ListIssueComments(_ context.Context, issueID string) ([]*models.IssueComment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var comments []*models.IssueComment
+	for _, c := range m.comments {
+		if c.IssueID == issueID {
+			cp := *c
+			comments = append(comments, &cp)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		}
+		return comments[i].ID < comments[j].ID
+	})
+	return comments, nil
+}
+
+// --- Issue Closures ---
+
+func (m *MemoryStore) RecordIssueClosure(_ context.Context, c *models.IssueClosure) (bool, error) {
+	if c.ID == "" {
+		c.ID = newULID()
+	}
+	c.CreatedAt = time.Now().UTC()
+	created := false
+	err := m.write(func() error {
+		_, issueOK := m.issues[c.IssueID]
+		_, projectOK := m.projects[c.ProjectID]
+		if !issueOK || !projectOK {
+			return errForeignKey("record issue closure")
+		}
+		for _, other := range m.closures {
+			if other.ID == c.ID || (other.IssueID == c.IssueID && other.Source == c.Source && other.Ref == c.Ref) {
+				return nil
+			}
+		}
+		cp := *c
+		m.closures[c.ID] = &cp
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+func (m *MemoryStore) GetIssueClosure(_ context.Context, id string) (*models.IssueClosure, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.closures[id]
+	if !ok {
+		return nil, fmt.Errorf("issue closure not found: %s", id)
+	}
+	return copyClosure(c), nil
+}
+
+// ListIssueClosures returns the closures of a project or an issue (either
+// may be empty), newest first.
+func (m *MemoryStore) ListIssueClosures(_ context.Context, projectID, issueID string) ([]*models.IssueClosure, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var closures []*models.IssueClosure
+	for _, c := range m.closures {
+		if (projectID == "" || c.ProjectID == projectID) && (issueID == "" || c.IssueID == issueID) {
+			closures = append(closures, copyClosure(c))
+		}
+	}
+	sort.Slice(closures, func(i, j int) bool {
+		if !closures[i].CreatedAt.Equal(closures[j].CreatedAt) {
+			return closures[i].CreatedAt.After(closures[j].CreatedAt)
+		}
+		return closures[i].ID > closures[j].ID
+	})
+	return closures, nil
+}
+
+func (m *MemoryStore) RevertIssueClosure(_ context.Context, id string) error {
+	return m.write(func() error {
+		c, ok := m.closures[id]
+		if !ok || c.RevertedAt != nil {
+			return fmt.Errorf("issue closure not found or already reverted: %s", id)
+		}
+		now := time.Now().UTC()
+		c.RevertedAt = &now
+		return nil
+	})
+}
+
+func copyClosure(c *models.IssueClosure) *models.IssueClosure {
+	cp := *c
+	if c.RevertedAt != nil {
+		at := *c.RevertedAt
+		cp.RevertedAt = &at
+	}
+	return &cp
+}
+
+// --- Pull Request Review Imports ---
+
+func (m *MemoryStore) RecordPRReviewImport(_ context.Context, r *models.PRReviewImport) (bool, error) {
+	if r.ID == "" {
+		r.ID = newULID()
+	}
+	r.CreatedAt = time.Now().UTC()
+	created := false
+	err := m.write(func() error {
+		_, issueOK := m.issues[r.IssueID]
+		_, projectOK := m.projects[r.ProjectID]
+		if !issueOK || !projectOK {
+			return errForeignKey("record pull request review import")
+		}
+		for _, other := range m.prReviews {
+			if other.ID == r.ID || (other.IssueID == r.IssueID && other.ReviewID == r.ReviewID) {
+				return nil
+			}
+		}
+		cp := *r
+		m.prReviews[r.ID] = &cp
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+func (m *MemoryStore) UpdatePRReviewImport(_ context.Context, r *models.PRReviewImport) error {
+	return m.write(func() error {
+		existing, ok := m.prReviews[r.ID]
+		if !ok {
+			return fmt.Errorf("pull request review import not found: %s", r.ID)
+		}
+		existing.CommentID = r.CommentID
+		existing.FollowUpIssueID = r.FollowUpIssueID
+		return nil
+	})
+}
+
+func (m *MemoryStore) DeletePRReviewImport(_ context.Context, id string) error {
+	return m.write(func() error {
+		delete(m.prReviews, id)
+		return nil
+	})
+}
+
+func (m *MemoryStore) ListPRReviewImports(_ context.Context, projectID, issueID string) ([]*models.PRReviewImport, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var imports []*models.PRReviewImport
+	for _, r := range m.prReviews {
+		if (projectID != "" && r.ProjectID != projectID) || (issueID != "" && r.IssueID != issueID) {
+			continue
+		}
+		cp := *r
+		imports = append(imports, &cp)
+	}
+	sort.Slice(imports, func(i, j int) bool {
+		if !imports[i].CreatedAt.Equal(imports[j].CreatedAt) {
+			return imports[i].CreatedAt.After(imports[j].CreatedAt)
+		}
+		return imports[i].ID > imports[j].ID
+	})
+	return imports, nil
+}
+
+// --- GitHub Board Items ---
+
+func (m *MemoryStore) SaveBoardItem(_ context.Context, item *models.BoardItem) error {
+	item.SyncedAt = time.Now().UTC()
+	return m.write(func() error {
+		_, issueOK := m.issues[item.IssueID]
+		_, projectOK := m.projects[item.ProjectID]
+		if !issueOK || !projectOK {
+			return errForeignKey("save board item")
+		}
+		cp := *item
+		m.boardItems[item.IssueID] = &cp
+		return nil
+	})
+}
+
+func (m *MemoryStore) ListBoardItems(_ context.Context, projectID string) ([]*models.BoardItem, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var items []*models.BoardItem
+	for _, item := range m.boardItems {
+		if item.ProjectID != projectID {
+			continue
+		}
+		cp := *item
+		items = append(items, &cp)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].IssueID < items[j].IssueID })
+	return items, nil
+}
+
+// --- GitHub Releases ---
+
+func copyRelease(r *models.ProjectRelease) *models.ProjectRelease {
+	c := *r
+	c.PublishedAt = cloneTime(r.PublishedAt)
+	c.FetchedAt = cloneTime(r.FetchedAt)
+	c.Assets = slices.Clone(r.Assets)
+	return &c
+}
+
+func (m *MemoryStore) SaveProjectRelease(_ context.Context, r *models.ProjectRelease) error {
+	return m.write(func() error {
+		if _, ok := m.projects[r.ProjectID]; !ok {
+			return errForeignKey("save project release")
+		}
+		m.releases[r.ProjectID] = copyRelease(r)
+		return nil
+	})
+}
+
+func (m *MemoryStore) GetProjectRelease(_ context.Context, projectID string) (*models.ProjectRelease, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	r, ok := m.releases[projectID]
+	if !ok {
+		return nil, nil
+	}
+	return copyRelease(r), nil
+}
+
+func (m *MemoryStore) ListProjectReleases(_ context.Context) ([]*models.ProjectRelease, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var releases []*models.ProjectRelease
+	for _, r := range m.releases {
+		releases = append(releases, copyRelease(r))
+	}
+	sort.Slice(releases, func(i, j int) bool { return releases[i].ProjectID < releases[j].ProjectID })
+	return releases, nil
+}
+
+// --- LLM Usage ---
+
+func (m *MemoryStore) RecordLLMUsage(ctx context.Context, u *models.LLMUsage) error {
+	if u.ID == "" {
+		u.ID = newULID()
+	}
+	u.CreatedAt = createdTime(ctx, u.CreatedAt, time.Now().UTC())
+	return m.write(func() error {
+		if _, ok := m.projects[u.ProjectID]; !ok {
+			return errForeignKey("record LLM usage")
+		}
+		cp := *u
+		m.llmUsage = append(m.llmUsage, &cp)
+		return nil
+	})
+}
+
+func (m *MemoryStore) ListLLMUsage(_ context.Context, projectID, issueID string) ([]*models.LLMUsage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var usage []*models.LLMUsage
+	for _, u := range m.llmUsage {
+		if (projectID != "" && u.ProjectID != projectID) || (issueID != "" && u.IssueID != issueID) {
+			continue
+		}
+		cp := *u
+		usage = append(usage, &cp)
+	}
+	sort.SliceStable(usage, func(i, j int) bool {
+		if !usage[i].CreatedAt.Equal(usage[j].CreatedAt) {
+			return usage[i].CreatedAt.After(usage[j].CreatedAt)
+		}
+		return usage[i].ID > usage[j].ID
+	})
+	return usage, nil
+}
+
+// --- Project Dependencies ---
+
+func (m *MemoryStore) CreateProjectDependency(_ context.Context, dep *models.ProjectDependency) error {
+	if dep.ProjectID == dep.DependsOnID {
+		return fmt.Errorf("create project dependency: a project cannot depend on itself")
+	}
+	return m.write(func() error {
+		if dep.ID == "" {
+			dep.ID = newULID()
+		}
+		_, projectOK := m.projects[dep.ProjectID]
+		_, dependsOK := m.projects[dep.DependsOnID]
+		if !projectOK || !dependsOK {
+			return errForeignKey("create project dependency")
+		}
+		for _, other := range m.dependencies {
+			if other.ID == dep.ID || (other.ProjectID == dep.ProjectID && other.DependsOnID == dep.DependsOnID) {
+				return fmt.Errorf("create project dependency: dependency already exists")
+			}
+		}
+		dep.CreatedAt = time.Now().UTC()
+		c := *dep
+		m.dependencies[dep.ID] = &c
+		return nil
+	})
+}
+
+// ListProjectDependencies returns every dependency edge between tracked projects.
+func (m *MemoryStore) ListProjectDependencies(_ context.Context) ([]*models.ProjectDependency, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var deps []*models.ProjectDependency
+	for _, d := range m.dependencies {
+		c := *d
+		deps = append(deps, &c)
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		if !deps[i].CreatedAt.Equal(deps[j].CreatedAt) {
+			return deps[i].CreatedAt.Before(deps[j].CreatedAt)
+		}
+		return deps[i].ID < deps[j].ID
+	})
+	return deps, nil
+}
+
+func (m *MemoryStore) DeleteProjectDependency(_ context.Context, projectID, dependsOnID string) error {
+	return m.write(func() error {
+		for id, d := range m.dependencies {
+			if d.ProjectID == projectID && d.DependsOnID == dependsOnID {
+				delete(m.dependencies, id)
+				return nil
+			}
+		}
+		return fmt.Errorf("project dependency not found")
+	})
+}
+
+// --- Health Snapshots ---
+
+func (m *MemoryStore) CreateHealthSnapshot(_ context.Context, snap *models.HealthSnapshot) error {
+	return m.write(func() error {
+		if snap.ID == "" {
+			snap.ID = newULID()
+		}
+		if _, ok := m.snapshots[snap.ID]; ok {
+			return errUnique("create health snapshot", "health_snapshots.id")
+		}
+		if _, ok := m.projects[snap.ProjectID]; !ok {
+			return errForeignKey("create health snapshot")
+		}
+		snap.CreatedAt = time.Now().UTC()
+		c := *snap
+		m.snapshots[snap.ID] = &c
+		return nil
+	})
+}
+
+// ListHealthSnapshots returns a project's snapshots, newest first. A limit of
+// 0 returns all of them.
+func (m *MemoryStore) ListHealthSnapshots(_ context.Context, projectID string, limit int) ([]*models.HealthSnapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var snaps []*models.HealthSnapshot
+	for _, h := range m.snapshots {
+		if h.ProjectID == projectID {
+			c := *h
+			snaps = append(snaps, &c)
+		}
+	}
+	sort.Slice(snaps, func(i, j int) bool {
+		if !snaps[i].CreatedAt.Equal(snaps[j].CreatedAt) {
+			return snaps[i].CreatedAt.After(snaps[j].CreatedAt)
+		}
+		return snaps[i].ID > snaps[j].ID
+	})
+	if limit > 0 && len(snaps) > limit {
+		snaps = snaps[:limit]
+	}
+	return snaps, nil
+}
+
+// --- SLA ---
+
+// SetIssueSLAState records an issue's SLA state without touching updated_at,
+// so evaluation does not count as activity on the issue.
+func (m *MemoryStore) SetIssueRanks(_ context.Context, ranks map[string]string) error {
+	for id, r := range ranks {
+		if r != "" && !lexorank.Valid(r) {
+			return fmt.Errorf("set issue ranks: invalid rank %q for issue %s", r, id)
+		}
+	}
+	return m.write(func() error {
+		for id := range ranks {
+			if _, ok := m.issues[id]; !ok {
+				return fmt.Errorf("issue not found: %s", id)
+			}
+		}
+		for id, r := range ranks {
+			issue := m.issues[id]
+			if issue.Rank != r {
+				issue.Rank = r
+				m.recordChange(models.ChangeIssue, id, issue.ProjectID, models.ChangeUpdate)
+			}
+		}
+		return nil
+	})
+}
+
+func (m *MemoryStore) SetIssueSLAState(_ context.Context, issueID string, state models.SLAState) error {
+	return m.write(func() error {
+		issue, ok := m.issues[issueID]
+		if !ok {
+			return fmt.Errorf("issue not found: %s", issueID)
+		}
+		if issue.SLAState != state {
+			issue.SLAState = state
+			m.recordChange(models.ChangeIssue, issueID, issue.ProjectID, models.ChangeUpdate)
+		}
+		return nil
+	})
+}
+
+// RecordSLABreach stores a breach unless the issue already breached the same
+// policy deadline, and reports whether a new one was recorded.
+func (m *MemoryStore) RecordSLABreach(_ context.Context, b *models.SLABreach) (bool, error) {
+	if b.ID == "" {
+		b.ID = newULID()
+	}
+	if b.BreachedAt.IsZero() {
+		b.BreachedAt = time.Now().UTC()
+	}
+	created := false
+	err := m.write(func() error {
+		_, issueOK := m.issues[b.IssueID]
+		_, projectOK := m.projects[b.ProjectID]
+		if !issueOK || !projectOK {
+			return errForeignKey("record sla breach")
+		}
+		for _, other := range m.breaches {
+			if other.ID == b.ID || (other.IssueID == b.IssueID && other.Policy == b.Policy && other.Kind == b.Kind) {
+				return nil
+			}
+		}
+		c := *b
+		m.breaches[b.ID] = &c
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+// ListSLABreaches returns breaches at or after since, newest first. A zero
+// since returns all of them.
+func (m *MemoryStore) ListSLABreaches(_ context.Context, since time.Time) ([]*models.SLABreach, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var breaches []*models.SLABreach
+	for _, b := range m.breaches {
+		if !b.BreachedAt.Before(since) {
+			c := *b
+			breaches = append(breaches, &c)
+		}
+	}
+	sort.Slice(breaches, func(i, j int) bool {
+		if !breaches[i].BreachedAt.Equal(breaches[j].BreachedAt) {
+			return breaches[i].BreachedAt.After(breaches[j].BreachedAt)
+		}
+		return breaches[i].ID < breaches[j].ID
+	})
+	return breaches, nil
+}
+
+// --- Issue Status History ---
+
+// ListIssueStatusEvents returns a project's issue status changes, oldest
+// first.
+func (m *MemoryStore) ListIssueStatusEvents(_ context.Context, projectID string) ([]*models.IssueStatusEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var events []*models.IssueStatusEvent
+	for _, e := range m.statusEvents {
+		if e.ProjectID == projectID {
+			c := *e
+			events = append(events, &c)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].ChangedAt.Equal(events[j].ChangedAt) {
+			return events[i].ChangedAt.Before(events[j].ChangedAt)
+		}
+		return events[i].ID < events[j].ID
+	})
+	return events, nil
+}
+
+// --- Agent Session History ---
+
+// ListSessionEvents returns a session's recorded changes in the order they
+// happened.
+func (m *MemoryStore) ListSessionEvents(_ context.Context, sessionID string) ([]*models.SessionEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var events []*models.SessionEvent
+	for _, e := range m.sessEvents {
+		if e.SessionID == sessionID {
+			c := *e
+			events = append(events, &c)
+		}
+	}
+	return events, nil
+}
+
+// --- API Tokens ---
+
+func (m *MemoryStore) CreateAPIToken(_ context.Context, token *models.APIToken) error {
+	return m.write(func() error {
+		if token.ID == "" {
+			token.ID = newULID()
+		}
+		if _, ok := m.tokens[token.ID]; ok {
+			return errUnique("create api token", "api_tokens.id")
+		}
+		if _, ok := m.projects[token.ProjectID]; !ok {
+			return errForeignKey("create api token")
+		}
+		for _, other := range m.tokens {
+			if other.TokenHash == token.TokenHash {
+				return errUnique("create api token", "api_tokens.token_hash")
+			}
+		}
+		token.CreatedAt = time.Now().UTC()
+		c := *token
+		m.tokens[token.ID] = &c
+		return nil
+	})
+}
+
+func (m *MemoryStore) GetAPITokenByHash(_ context.Context, hash string) (*models.APIToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, t := range m.tokens {
+		if t.TokenHash == hash {
+			c := *t
+			return &c, nil
+		}
+	}
+	return nil, fmt.Errorf("api token not found")
+}
+
+func (m *MemoryStore) ListAPITokens(_ context.Context) ([]*models.APIToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var tokens []*models.APIToken
+	for _, t := range m.tokens {
+		c := *t
+		tokens = append(tokens, &c)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+		}
+		return tokens[i].ID < tokens[j].ID
+	})
+	return tokens, nil
+}
+
+func (m *MemoryStore) DeleteAPIToken(_ context.Context, id string) error {
+	return m.write(func() error {
+		if _, ok := m.tokens[id]; !ok {
+			return fmt.Errorf("api token not found: %s", id)
+		}
+		delete(m.tokens, id)
+		return nil
+	})
+}
+
+// --- Web Push Subscriptions ---
+
+func (m *MemoryStore) SavePushSubscription(_ context.Context, sub *models.PushSubscription) error {
+	return m.write(func() error {
+		if old, ok := m.pushSubs[sub.Endpoint]; ok {
+			sub.ID, sub.CreatedAt = old.ID, old.CreatedAt
+		} else {
+			if sub.ID == "" {
+				sub.ID = newULID()
+			}
+			sub.CreatedAt = time.Now().UTC()
+		}
+		c := *sub
+		m.pushSubs[sub.Endpoint] = &c
+		return nil
+	})
+}
+
+func (m *MemoryStore) ListPushSubscriptions(_ context.Context) ([]*models.PushSubscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var subs []*models.PushSubscription
+	for _, sub := range m.pushSubs {
+		c := *sub
+		subs = append(subs, &c)
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		if !subs[i].CreatedAt.Equal(subs[j].CreatedAt) {
+			return subs[i].CreatedAt.Before(subs[j].CreatedAt)
+		}
+		return subs[i].ID < subs[j].ID
+	})
+	return subs, nil
+}
+
+func (m *MemoryStore) DeletePushSubscription(_ context.Context, endpoint string) error {
+	return m.write(func() error {
+		if _, ok := m.pushSubs[endpoint]; !ok {
+			return fmt.Errorf("push subscription not found")
+		}
+		delete(m.pushSubs, endpoint)
+		return nil
+	})
+}
+
+// --- Change Feed ---
+
+// recordChange appends to the change feed what the changes triggers record
+// in SQLite.
+func (m *MemoryStore) recordChange(entity models.ChangeEntity, id, projectID string, op models.ChangeOp) {
+	m.changeSeq++
+	m.changes = append(m.changes, &models.Change{
+		Seq:       m.changeSeq,
+		Entity:    entity,
+		EntityID:  id,
+		ProjectID: projectID,
+		Op:        op,
+		At:        time.Now().UTC(),
+	})
+}
+
+// issueProject returns the project of an issue, or "" if it is gone.
+func (m *MemoryStore) issueProject(issueID string) string {
+	if issue, ok := m.issues[issueID]; ok {
+		return issue.ProjectID
+	}
+	return ""
+}
+
+func (m *MemoryStore) ListChanges(_ context.Context, filter ChangeFilter) ([]*models.Change, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	i, _ := slices.BinarySearchFunc(m.changes, filter.Since+1, func(c *models.Change, seq int64) int {
+		return cmp.Compare(c.Seq, seq)
+	})
+	var changes []*models.Change
+	for _, c := range m.changes[i:] {
+		if filter.ProjectID != "" && c.ProjectID != filter.ProjectID {
+			continue
+		}
+		if len(filter.Entities) > 0 && !slices.Contains(filter.Entities, c.Entity) {
+			continue
+		}
+		cp := *c
+		changes = append(changes, &cp)
+		if filter.Limit > 0 && len(changes) == filter.Limit {
+			break
+		}
+	}
+	return changes, nil
+}
+
+func (m *MemoryStore) ChangeSeqRange(_ context.Context) (first, last int64, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.changes) == 0 {
+		return m.changeSeq + 1, m.changeSeq, nil
+	}
+	return m.changes[0].Seq, m.changeSeq, nil
+}
+
+func (m *MemoryStore) PruneChanges(_ context.Context, before time.Time) (int64, error) {
+	var n int64
+	err := m.write(func() error {
+		kept := slices.DeleteFunc(m.changes, func(c *models.Change) bool { return c.At.Before(before) })
+		n = int64(len(m.changes) - len(kept))
+		m.changes = kept
+		return nil
+	})
+	return n, err
+}
+
+// --- Project Notes ---
+
+// checkNoteTitle enforces unique, case-insensitive titles within a project.
+func (m *MemoryStore) checkNoteTitle(op string, note *models.ProjectNote) error {
+	for _, other := range m.notes {
+		if other.ID != note.ID && other.ProjectID == note.ProjectID && strings.EqualFold(other.Title, note.Title) {
+			return fmt.Errorf("%s: a note titled %q already exists", op, note.Title)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) CreateProjectNote(ctx context.Context, note *models.ProjectNote) error {
+	return m.write(func() error {
+		if note.ID == "" {
+			note.ID = newULID()
+		}
+		if _, ok := m.notes[note.ID]; ok {
+			return errUnique("create project note", "project_notes.id")
+		}
+		if _, ok := m.projects[note.ProjectID]; !ok {
+			return errForeignKey("create project note")
+		}
+		if err := m.checkNoteTitle("create project note", note); err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		note.CreatedAt = createdTime(ctx, note.CreatedAt, now)
+		note.UpdatedAt = createdTime(ctx, note.UpdatedAt, now)
+		c := *note
+		m.notes[note.ID] = &c
+		m.recordChange(models.ChangeNote, note.ID, note.ProjectID, models.ChangeInsert)
+		return nil
+	})
+}
+
+func (m *MemoryStore) GetProjectNote(_ context.Context, id string) (*models.ProjectNote, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if n, ok := m.notes[id]; ok {
+		c := *n
+		return &c, nil
+	}
+	return nil, fmt.Errorf("project note not found: %s", id)
+}
+
+// ListProjectNotes returns a project's notes ordered by title.
+func (m *MemoryStore) ListProjectNotes(_ context.Context, projectID string) ([]*models.ProjectNote, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var notes []*models.ProjectNote
+	for _, n := range m.notes {
+		if n.ProjectID == projectID {
+			c := *n
+			notes = append(notes, &c)
+		}
+	}
+	sort.Slice(notes, func(i, j int) bool {
+		a, b := strings.ToLower(notes[i].Title), strings.ToLower(notes[j].Title)
+		if a != b {
+			return a < b
+		}
+		return notes[i].ID < notes[j].ID
+	})
+	return notes, nil
+}
+
+func (m *MemoryStore) UpdateProjectNote(_ context.Context, note *models.ProjectNote) error {
+	return m.write(func() error {
+		stored, ok := m.notes[note.ID]
+		if !ok {
+			return fmt.Errorf("project note not found: %s", note.ID)
+		}
+		check := *note
+		check.ProjectID = stored.ProjectID
+		if err := m.checkNoteTitle("update project note", &check); err != nil {
+			return err
+		}
+		note.UpdatedAt = time.Now().UTC()
+		stored.Title = note.Title
+		stored.Body = note.Body
+		stored.UpdatedAt = note.UpdatedAt
+		m.recordChange(models.ChangeNote, stored.ID, stored.ProjectID, models.ChangeUpdate)
+		return nil
+	})
+}
+
+func (m *MemoryStore) DeleteProjectNote(_ context.Context, id string) error {
+	return m.write(func() error {
+		stored, ok := m.notes[id]
+		if !ok {
+			return fmt.Errorf("project note not found: %s", id)
+		}
+		delete(m.notes, id)
+		m.recordChange(models.ChangeNote, id, stored.ProjectID, models.ChangeDelete)
+		return nil
+	})
+}
+
+// --- Lifecycle ---
+
+// Migrate is a no-op: a MemoryStore always has the latest schema.
+func (m *MemoryStore) Migrate(_ context.Context) error { return nil }
+
+// SchemaVersion reports the latest schema version, which a MemoryStore
+// always behaves as.
+func (m *MemoryStore) SchemaVersion(_ context.Context) (int, error) {
+	return LatestSchemaVersion(), nil
+}
+
+// Close is a no-op; the data stays readable until the store is discarded.
+func (m *MemoryStore) Close() error { return nil }
+
+var (
+	_ Store         = (*MemoryStore)(nil)
+	_ WriteNotifier = (*MemoryStore)(nil)
+)
@@ -0,0 +1,1559 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// forEachStore runs fn against a fresh SQLiteStore and MemoryStore, so the
+// two implementations are held to the same behaviour.
+func forEachStore(t *testing.T, fn func(t *testing.T, s Store)) {
+	t.Run("sqlite", func(t *testing.T) { fn(t, newTestStore(t)) })
+	t.Run("memory", func(t *testing.T) { fn(t, NewMemoryStore()) })
+}
+
+func TestStores_ProjectCascade(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		other := &models.Project{Name: "web", Path: "/code/web"}
+		require.NoError(t, s.CreateProject(ctx, other))
+
+		assert.Error(t, s.CreateProject(ctx, &models.Project{Name: "api", Path: "/elsewhere"}), "duplicate name")
+		assert.Error(t, s.CreateIssue(ctx, &models.Issue{ProjectID: "missing", Title: "x"}), "unknown project")
+
+		issue := &models.Issue{ProjectID: p.ID, Title: "Bug", Status: models.IssueStatusOpen}
+		require.NoError(t, s.CreateIssue(ctx, issue))
+		require.NoError(t, s.CreateAgentSession(ctx, &models.AgentSession{ProjectID: p.ID, Branch: "feature/a", Status: models.SessionStatusActive}))
+		require.NoError(t, s.CreateProjectNote(ctx, &models.ProjectNote{ProjectID: p.ID, Title: "Runbook"}))
+		require.NoError(t, s.CreateProjectDependency(ctx, &models.ProjectDependency{ProjectID: other.ID, DependsOnID: p.ID}))
+
+		require.NoError(t, s.DeleteProject(ctx, p.ID))
+		assert.Error(t, s.DeleteProject(ctx, p.ID))
+
+		_, err := s.GetIssue(ctx, issue.ID)
+		assert.Error(t, err, "issues cascade")
+		sessions, err := s.ListAgentSessions(ctx, "", 0)
+		require.NoError(t, err)
+		assert.Empty(t, sessions, "sessions cascade")
+		notes, err := s.ListProjectNotes(ctx, p.ID)
+		require.NoError(t, err)
+		assert.Empty(t, notes, "notes cascade")
+		deps, err := s.ListProjectDependencies(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, deps, "dependencies cascade")
+		events, err := s.ListIssueStatusEvents(ctx, p.ID)
+		require.NoError(t, err)
+		assert.Empty(t, events, "status history cascades")
+
+		projects, err := s.ListProjects(ctx, "")
+		require.NoError(t, err)
+		require.Len(t, projects, 1)
+		assert.Equal(t, "web", projects[0].Name)
+	})
+}
+
+func TestStores_IssueListingAndTags(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+
+		mk := func(title string, status models.IssueStatus, priority models.IssuePriority) *models.Issue {
+			i := &models.Issue{ProjectID: p.ID, Title: title, Status: status, Priority: priority, Type: models.IssueTypeFeature}
+			require.NoError(t, s.CreateIssue(ctx, i))
+			return i
+		}
+		done := mk("done", models.IssueStatusDone, models.IssuePriorityHigh)
+		low := mk("low", models.IssueStatusOpen, models.IssuePriorityLow)
+		high := mk("high", models.IssueStatusOpen, models.IssuePriorityHigh)
+
+		issues, err := s.ListIssues(ctx, IssueListFilter{ProjectID: p.ID})
+		require.NoError(t, err)
+		require.Len(t, issues, 3)
+		assert.Equal(t, []string{high.ID, low.ID, done.ID}, []string{issues[0].ID, issues[1].ID, issues[2].ID})
+
+		high.Assignee = "bot"
+		high.Estimate = "3pt"
+		require.NoError(t, s.UpdateIssue(ctx, high))
+		assigned, err := s.ListIssues(ctx, IssueListFilter{Assignee: "bot"})
+		require.NoError(t, err)
+		require.Len(t, assigned, 1)
+		assert.Equal(t, "bot", assigned[0].Assignee)
+		assert.Equal(t, "3pt", assigned[0].Estimate)
+
+		tag := &models.Tag{Name: "backend"}
+		require.NoError(t, s.CreateTag(ctx, tag))
+		assert.Error(t, s.CreateTag(ctx, &models.Tag{Name: "backend"}))
+		require.NoError(t, s.TagIssue(ctx, low.ID, tag.ID))
+		require.NoError(t, s.TagIssue(ctx, low.ID, tag.ID), "tagging twice is a no-op")
+
+		tagged, err := s.ListIssues(ctx, IssueListFilter{Tag: "backend"})
+		require.NoError(t, err)
+		require.Len(t, tagged, 1)
+		assert.Equal(t, low.ID, tagged[0].ID)
+		assert.Empty(t, tagged[0].Tags, "lists do not load tags")
+
+		got, err := s.GetIssue(ctx, low.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"backend"}, got.Tags)
+
+		require.NoError(t, s.DeleteTag(ctx, tag.ID))
+		got, err = s.GetIssue(ctx, low.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got.Tags)
+
+		n, err := s.BulkDeleteIssues(ctx, []string{done.ID, high.ID, "missing"}, false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+	})
+}
+
+func TestStores_TagManagement(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		open := &models.Issue{ProjectID: p.ID, Title: "Open", Status: models.IssueStatusOpen, Type: models.IssueTypeBug}
+		require.NoError(t, s.CreateIssue(ctx, open))
+		done := &models.Issue{ProjectID: p.ID, Title: "Done", Status: models.IssueStatusDone}
+		require.NoError(t, s.CreateIssue(ctx, done))
+
+		tags := make(map[string]*models.Tag)
+		for _, name := range []string{"bug", "bugs", "frontend", "unused"} {
+			tags[name] = &models.Tag{Name: name}
+			require.NoError(t, s.CreateTag(ctx, tags[name]))
+		}
+		require.NoError(t, s.TagIssue(ctx, open.ID, tags["bug"].ID))
+		require.NoError(t, s.TagIssue(ctx, open.ID, tags["bugs"].ID))
+		require.NoError(t, s.TagIssue(ctx, done.ID, tags["bugs"].ID))
+		require.NoError(t, s.TagIssue(ctx, done.ID, tags["frontend"].ID))
+
+		usage, err := s.ListTagUsage(ctx)
+		require.NoError(t, err)
+		require.Len(t, usage, 4)
+		assert.Equal(t, "bugs", usage[1].Name)
+		assert.Equal(t, 2, usage[1].Issues)
+		assert.Equal(t, 1, usage[1].Open)
+		assert.Equal(t, 0, usage[3].Issues)
+		assert.Equal(t, 0, usage[3].Open)
+
+		require.NoError(t, s.MergeTag(ctx, tags["bugs"].ID, tags["bug"].ID))
+		assert.Error(t, s.MergeTag(ctx, tags["bugs"].ID, tags["bug"].ID), "merged tags are gone")
+		assert.Error(t, s.MergeTag(ctx, tags["bug"].ID, tags["bug"].ID))
+		got, err := s.GetIssue(ctx, done.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bug", "frontend"}, got.Tags)
+
+		assert.ErrorContains(t, s.RenameTag(ctx, tags["frontend"].ID, "bug"), "already exists")
+		assert.Error(t, s.RenameTag(ctx, "missing", "ui"))
+		require.NoError(t, s.RenameTag(ctx, tags["frontend"].ID, "ui"))
+		got, err = s.GetIssue(ctx, done.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bug", "ui"}, got.Tags)
+
+		deleted, err := s.DeleteUnusedTags(ctx)
+		require.NoError(t, err)
+		require.Len(t, deleted, 1)
+		assert.Equal(t, "unused", deleted[0].Name)
+		remaining, err := s.ListTags(ctx)
+		require.NoError(t, err)
+		assert.Len(t, remaining, 2)
+
+		b, err := BreakdownTag(ctx, s, tags["bug"])
+		require.NoError(t, err)
+		assert.Equal(t, 2, b.Issues)
+		assert.Equal(t, map[models.IssueStatus]int{models.IssueStatusOpen: 1, models.IssueStatusDone: 1}, b.ByStatus)
+		assert.Equal(t, map[string]int{"api": 2}, b.ByProject)
+
+		found, err := FindTag(ctx, s, "ui")
+		require.NoError(t, err)
+		assert.Equal(t, tags["frontend"].ID, found.ID)
+		_, err = FindTag(ctx, s, "frontend")
+		assert.Error(t, err)
+	})
+}
+
+func TestStores_IssueEnumValidation(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+
+		issue := &models.Issue{ProjectID: p.ID, Title: "defaults"}
+		require.NoError(t, s.CreateIssue(ctx, issue))
+		assert.Equal(t, models.IssueStatusOpen, issue.Status)
+		assert.Equal(t, models.IssuePriorityMedium, issue.Priority)
+		assert.Equal(t, models.IssueTypeFeature, issue.Type)
+
+		assert.ErrorContains(t, s.CreateIssue(ctx, &models.Issue{ProjectID: p.ID, Title: "x", Status: "opne"}), "invalid issue status")
+		assert.ErrorContains(t, s.CreateIssue(ctx, &models.Issue{ProjectID: p.ID, Title: "x", Priority: "urgent"}), "invalid issue priority")
+
+		issue.Type = "epic"
+		assert.ErrorContains(t, s.UpdateIssue(ctx, issue), "invalid issue type")
+		_, err := s.BulkUpdateIssueStatus(ctx, []string{issue.ID}, "finished")
+		assert.ErrorContains(t, err, "invalid issue status")
+
+		got, err := s.GetIssue(ctx, issue.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.IssueTypeFeature, got.Type, "rejected updates change nothing")
+	})
+}
+
+func TestStores_SessionReviewEnumValidation(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		issue := &models.Issue{ProjectID: p.ID, Title: "x"}
+		require.NoError(t, s.CreateIssue(ctx, issue))
+
+		assert.ErrorContains(t, s.CreateAgentSession(ctx, &models.AgentSession{ProjectID: p.ID, Branch: "a", Status: "running"}), "invalid session status")
+		session := &models.AgentSession{ProjectID: p.ID, Branch: "b", Status: models.SessionStatusActive}
+		require.NoError(t, s.CreateAgentSession(ctx, session))
+		session.Status = "done"
+		assert.ErrorContains(t, s.UpdateAgentSession(ctx, session), "invalid session status")
+		got, err := s.GetAgentSession(ctx, session.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.SessionStatusActive, got.Status, "rejected updates change nothing")
+
+		assert.ErrorContains(t, s.CreateIssueReview(ctx, &models.IssueReview{IssueID: issue.ID, Verdict: "ok"}), "invalid review")
+		assert.ErrorContains(t, s.CreateIssueReview(ctx, &models.IssueReview{IssueID: issue.ID, Verdict: models.ReviewVerdictPass, CodeQuality: "great"}), "invalid review")
+		require.NoError(t, s.CreateIssueReview(ctx, &models.IssueReview{
+			IssueID: issue.ID, Verdict: models.ReviewVerdictPass,
+			CodeQuality: models.ReviewCategoryPass, UIUX: models.ReviewCategoryNA,
+		}), "an unrated category is empty")
+	})
+}
+
+func TestStores_IssueSmartSort(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+
+		mk := func(title string, status models.IssueStatus, priority models.IssuePriority) *models.Issue {
+			i := &models.Issue{ProjectID: p.ID, Title: title, Status: status, Priority: priority, Type: models.IssueTypeFeature}
+			require.NoError(t, s.CreateIssue(ctx, i))
+			return i
+		}
+		done := mk("done", models.IssueStatusDone, models.IssuePriorityHigh)
+		high := mk("high", models.IssueStatusOpen, models.IssuePriorityHigh)
+		breached := mk("breached", models.IssueStatusOpen, models.IssuePriorityMedium)
+		blocked := mk("blocked", models.IssueStatusOpen, models.IssuePriorityHigh)
+		low := mk("low", models.IssueStatusInProgress, models.IssuePriorityLow)
+		unblocked := mk("unblocked", models.IssueStatusOpen, models.IssuePriorityLow)
+
+		require.NoError(t, s.SetIssueSLAState(ctx, breached.ID, models.SLAStateBreached))
+		require.NoError(t, s.CreateIssueRelation(ctx, &models.IssueRelation{SourceIssueID: low.ID, TargetIssueID: blocked.ID, Type: models.RelationBlocks}))
+		require.NoError(t, s.CreateIssueRelation(ctx, &models.IssueRelation{SourceIssueID: done.ID, TargetIssueID: unblocked.ID, Type: models.RelationBlocks}))
+
+		issues, err := s.ListIssues(ctx, IssueListFilter{ProjectID: p.ID, Sort: IssueSortSmart})
+		require.NoError(t, err)
+		var ids []string
+		for _, i := range issues {
+			ids = append(ids, i.ID)
+		}
+		assert.Equal(t, []string{high.ID, breached.ID, low.ID, unblocked.ID, blocked.ID, done.ID}, ids,
+			"a breach ties with a priority level and the older issue wins; blocked issues sink; finished issues come last")
+	})
+}
+
+func TestStores_IssueStatusHistory(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+
+		issue := &models.Issue{ProjectID: p.ID, Title: "Work", Status: models.IssueStatusOpen}
+		require.NoError(t, s.CreateIssue(ctx, issue))
+		assert.Nil(t, issue.StartedAt)
+
+		issue.Status = models.IssueStatusInProgress
+		require.NoError(t, s.UpdateIssue(ctx, issue))
+		require.NotNil(t, issue.StartedAt)
+		started := *issue.StartedAt
+
+		issue.Status = models.IssueStatusDone
+		require.NoError(t, s.UpdateIssue(ctx, issue))
+		require.NotNil(t, issue.CompletedAt)
+
+		// Reopening clears completion but keeps the first start.
+		n, err := s.BulkUpdateIssueStatus(ctx, []string{issue.ID}, models.IssueStatusInProgress)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+		got, err := s.GetIssue(ctx, issue.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got.CompletedAt)
+		require.NotNil(t, got.StartedAt)
+		assert.True(t, got.StartedAt.Equal(started))
+
+		require.NoError(t, s.SetIssueSLAState(ctx, issue.ID, models.SLAStateAtRisk))
+		updated := got.UpdatedAt
+		got, err = s.GetIssue(ctx, issue.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.SLAStateAtRisk, got.SLAState)
+		assert.True(t, got.UpdatedAt.Equal(updated), "SLA evaluation is not activity")
+
+		events, err := s.ListIssueStatusEvents(ctx, p.ID)
+		require.NoError(t, err)
+		var path []models.IssueStatus
+		for _, e := range events {
+			path = append(path, e.ToStatus)
+		}
+		assert.Equal(t, []models.IssueStatus{"open", "in_progress", "done", "in_progress"}, path)
+		assert.Equal(t, models.IssueStatus("done"), events[3].FromStatus)
+	})
+}
+
+func TestStores_AgentSessions(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+
+		first := &models.AgentSession{ProjectID: p.ID, Branch: "feature/a", WorktreePath: "/wt/a", Status: models.SessionStatusActive}
+		require.NoError(t, s.CreateAgentSession(ctx, first))
+		assert.Equal(t, models.ConflictStateNone, first.ConflictState)
+		assert.Error(t, s.CreateAgentSession(ctx, &models.AgentSession{ProjectID: p.ID, Branch: "feature/a", Status: models.SessionStatusIdle}),
+			"one open session per branch")
+
+		// Updates cannot move a session to another branch.
+		first.Branch = "feature/b"
+		first.Status = models.SessionStatusAbandoned
+		ended := time.Now().UTC()
+		first.EndedAt = &ended
+		require.NoError(t, s.UpdateAgentSession(ctx, first))
+		got, err := s.GetAgentSession(ctx, first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "feature/a", got.Branch)
+		assert.Equal(t, models.SessionStatusAbandoned, got.Status)
+
+		_, err = s.GetAgentSessionByWorktreePath(ctx, "/wt/a")
+		assert.Error(t, err, "no open session at the path any more")
+
+		second := &models.AgentSession{ProjectID: p.ID, Branch: "feature/a", WorktreePath: "/wt/a", Status: models.SessionStatusIdle}
+		require.NoError(t, s.CreateAgentSession(ctx, second))
+		open, err := s.GetAgentSessionByWorktreePath(ctx, "/wt/a")
+		require.NoError(t, err)
+		assert.Equal(t, second.ID, open.ID)
+
+		all, err := s.ListAgentSessions(ctx, p.ID, 1)
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		assert.Equal(t, second.ID, all[0].ID, "newest first")
+
+		byStatus, err := s.ListAgentSessionsByStatus(ctx, "", []models.SessionStatus{models.SessionStatusAbandoned}, 0)
+		require.NoError(t, err)
+		require.Len(t, byStatus, 1)
+		assert.Equal(t, first.ID, byStatus[0].ID)
+
+		stale, err := s.ListStaleSessions(ctx, DefaultStaleSessionCriteria())
+		require.NoError(t, err)
+		require.Len(t, stale, 1, "abandoned quickly with no commits")
+		n, err := s.DeleteAllStaleSessions(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+
+		idle := DefaultStaleSessionCriteria()
+		idle.Statuses = []models.SessionStatus{models.SessionStatusIdle}
+		n, err = s.DeleteStaleSessionsByID(ctx, idle, []string{second.ID})
+		require.NoError(t, err)
+		assert.Zero(t, n, "sessions not matching the criteria are kept")
+		_, err = s.GetAgentSession(ctx, second.ID)
+		require.NoError(t, err)
+	})
+}
+
+func TestStores_SessionHistory(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+
+		sess := &models.AgentSession{ProjectID: p.ID, Branch: "feature/a", Status: models.SessionStatusActive}
+		require.NoError(t, s.CreateAgentSession(ctx, sess))
+
+		// A sync that conflicts is one sync event, not a conflict and an error too.
+		synced := time.Now().UTC().Truncate(time.Second)
+		sess.LastSyncAt = &synced
+		sess.ConflictState = models.ConflictStateSyncConflict
+		sess.ConflictFiles = `["main.go"]`
+		sess.LastError = "conflict in main.go"
+		require.NoError(t, s.UpdateAgentSession(ctx, sess))
+
+		// Unchanged saves record nothing.
+		require.NoError(t, s.UpdateAgentSession(ctx, sess))
+
+		sess.ConflictState = models.ConflictStateNone
+		sess.ConflictFiles = "[]"
+		sess.LastError = ""
+		require.NoError(t, s.UpdateAgentSession(ctx, sess))
+
+		sess.LastError = "push rejected"
+		require.NoError(t, s.UpdateAgentSession(ctx, sess))
+
+		blocked := time.Now().UTC().Truncate(time.Second)
+		sess.Status = models.SessionStatusBlocked
+		sess.BlockedReason = "needs API key"
+		sess.BlockedAt = &blocked
+		require.NoError(t, s.UpdateAgentSession(ctx, sess))
+
+		sess.Status = models.SessionStatusCompleted
+		sess.Outcome = "merged"
+		ended := time.Now().UTC().Truncate(time.Second)
+		sess.EndedAt = &ended
+		require.NoError(t, s.UpdateAgentSession(ctx, sess))
+
+		events, err := s.ListSessionEvents(ctx, sess.ID)
+		require.NoError(t, err)
+		var got []string
+		for _, e := range events {
+			got = append(got, string(e.Kind)+":"+e.Value+":"+e.Detail)
+		}
+		assert.Equal(t, []string{
+			"started:active:feature/a",
+			"sync:sync_conflict:conflict in main.go",
+			"conflict:none:",
+			"error::push rejected",
+			"status:blocked:needs API key",
+			"status:completed:merged",
+		}, got)
+		assert.True(t, events[1].At.Equal(synced), "sync stamped with its sync time")
+		assert.True(t, events[4].At.Equal(blocked), "block stamped with its block time")
+		assert.True(t, events[5].At.Equal(ended), "end stamped with its end time")
+
+		n, err := s.DeleteStaleSessionsByID(ctx, StaleSessionCriteria{Statuses: []models.SessionStatus{models.SessionStatusCompleted}}, []string{sess.ID})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), n)
+		events, err = s.ListSessionEvents(ctx, sess.ID)
+		require.NoError(t, err)
+		assert.Empty(t, events, "history cascades")
+	})
+}
+
+func TestStores_TransitionAgentSession(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		sess := &models.AgentSession{ProjectID: p.ID, Branch: "feature/a", Status: models.SessionStatusActive}
+		require.NoError(t, s.CreateAgentSession(ctx, sess))
+
+		stale, err := s.GetAgentSession(ctx, sess.ID)
+		require.NoError(t, err)
+
+		sess.Status = models.SessionStatusIdle
+		require.NoError(t, s.TransitionAgentSession(ctx, sess, models.SessionStatusActive, "reconcile"))
+
+		stale.Status = models.SessionStatusAbandoned
+		stale.Outcome = "gone"
+		err = s.TransitionAgentSession(ctx, stale, models.SessionStatusActive, "close")
+		require.ErrorIs(t, err, ErrSessionStatusChanged)
+		assert.ErrorContains(t, err, "is idle, not active")
+		got, err := s.GetAgentSession(ctx, sess.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.SessionStatusIdle, got.Status)
+		assert.Empty(t, got.Outcome, "a refused transition writes nothing")
+
+		// Staying in the same status writes the other fields without an event
+		got.CommitCount = 3
+		require.NoError(t, s.TransitionAgentSession(ctx, got, models.SessionStatusIdle, "merge"))
+		got, err = s.GetAgentSession(ctx, sess.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, got.CommitCount)
+
+		// Plain updates still record status events, without a cause
+		got.Status = models.SessionStatusActive
+		require.NoError(t, s.UpdateAgentSession(ctx, got))
+
+		events, err := s.ListSessionEvents(ctx, sess.ID)
+		require.NoError(t, err)
+		var trail []string
+		for _, e := range events {
+			trail = append(trail, string(e.Kind)+":"+e.From+"->"+e.Value+":"+e.Cause)
+		}
+		assert.Equal(t, []string{"started:->active:", "status:active->idle:reconcile", "status:idle->active:"}, trail)
+
+		err = s.TransitionAgentSession(ctx, &models.AgentSession{ID: "nope"}, models.SessionStatusActive, "close")
+		assert.ErrorContains(t, err, "not found")
+		assert.NotErrorIs(t, err, ErrSessionStatusChanged)
+	})
+}
+
+func TestStores_TouchAgentSession(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		sess := &models.AgentSession{ProjectID: p.ID, Branch: "feature/a", Status: models.SessionStatusActive}
+		require.NoError(t, s.CreateAgentSession(ctx, sess))
+
+		// A touch from a stale read doesn't undo a concurrent status change
+		sess.Status = models.SessionStatusBlocked
+		require.NoError(t, s.UpdateAgentSession(ctx, sess))
+		at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		touched, err := s.TouchAgentSession(ctx, sess.ID, at)
+		require.NoError(t, err)
+		assert.True(t, touched)
+		got, err := s.GetAgentSession(ctx, sess.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.SessionStatusBlocked, got.Status)
+		require.NotNil(t, got.LastActiveAt)
+		assert.True(t, at.Equal(*got.LastActiveAt))
+
+		got.Status = models.SessionStatusCompleted
+		require.NoError(t, s.UpdateAgentSession(ctx, got))
+		touched, err = s.TouchAgentSession(ctx, sess.ID, at.Add(time.Hour))
+		require.NoError(t, err)
+		assert.False(t, touched, "ended sessions are not touched")
+
+		touched, err = s.TouchAgentSession(ctx, "nope", at)
+		require.NoError(t, err)
+		assert.False(t, touched)
+	})
+}
+
+func TestStores_TouchAgentSession_Focus(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		sess := &models.AgentSession{ProjectID: p.ID, Branch: "feature/a", Status: models.SessionStatusActive}
+		require.NoError(t, s.CreateAgentSession(ctx, sess))
+
+		at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		for _, d := range []time.Duration{0, time.Minute, 90 * time.Second, time.Hour, time.Hour + 2*time.Minute} {
+			_, err := s.TouchAgentSession(ctx, sess.ID, at.Add(d))
+			require.NoError(t, err)
+		}
+		got, err := s.GetAgentSession(ctx, sess.ID)
+		require.NoError(t, err)
+		assert.InDelta(t, 210, got.FocusSeconds, 0.001, "the hour-long gap is idle time")
+
+		// A full update from a stale read keeps the focus time.
+		sess.Labels = "x"
+		require.NoError(t, s.UpdateAgentSession(ctx, sess))
+		got, err = s.GetAgentSession(ctx, sess.ID)
+		require.NoError(t, err)
+		assert.InDelta(t, 210, got.FocusSeconds, 0.001)
+	})
+}
+
+func TestStores_BulkDeleteIssues_Sessions(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		busy := &models.Issue{ProjectID: p.ID, Title: "Busy"}
+		require.NoError(t, s.CreateIssue(ctx, busy))
+		done := &models.Issue{ProjectID: p.ID, Title: "Done"}
+		require.NoError(t, s.CreateIssue(ctx, done))
+		open := &models.AgentSession{ProjectID: p.ID, IssueID: busy.ID, Branch: "feature/busy", Status: models.SessionStatusIdle}
+		require.NoError(t, s.CreateAgentSession(ctx, open))
+		review := &models.IssueReview{IssueID: done.ID, Verdict: models.ReviewVerdictFail, Summary: "nope"}
+		require.NoError(t, s.CreateIssueReview(ctx, review))
+		ended := &models.AgentSession{ProjectID: p.ID, IssueID: done.ID, Branch: "feature/done", Status: models.SessionStatusCompleted, ReviewID: review.ID}
+		require.NoError(t, s.CreateAgentSession(ctx, ended))
+
+		_, err := s.BulkDeleteIssues(ctx, []string{busy.ID, done.ID}, false)
+		require.ErrorIs(t, err, ErrIssuesHaveOpenSessions)
+		assert.ErrorContains(t, err, busy.ID)
+		_, err = s.GetIssue(ctx, done.ID)
+		require.NoError(t, err, "a refused delete deletes nothing")
+
+		n, err := s.BulkDeleteIssues(ctx, []string{done.ID}, false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+		got, err := s.GetAgentSession(ctx, ended.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got.IssueID)
+		assert.Empty(t, got.ReviewID)
+		events, err := s.ListSessionEvents(ctx, ended.ID)
+		require.NoError(t, err)
+		last := events[len(events)-1]
+		assert.Equal(t, models.SessionEventIssueDeleted, last.Kind)
+		assert.Equal(t, done.ID, last.Value)
+		assert.Equal(t, "Done", last.Detail)
+		reviews, err := s.ListIssueReviews(ctx, done.ID)
+		require.NoError(t, err)
+		assert.Empty(t, reviews)
+
+		n, err = s.BulkDeleteIssues(ctx, []string{busy.ID}, true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+		got, err = s.GetAgentSession(ctx, open.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got.IssueID)
+		assert.Equal(t, models.SessionStatusIdle, got.Status, "forcing leaves the session open")
+
+		// Deleting a single issue unlinks its sessions too
+		single := &models.Issue{ProjectID: p.ID, Title: "Single"}
+		require.NoError(t, s.CreateIssue(ctx, single))
+		got.IssueID = single.ID
+		require.NoError(t, s.UpdateAgentSession(ctx, got))
+		require.NoError(t, s.DeleteIssue(ctx, single.ID))
+		got, err = s.GetAgentSession(ctx, open.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got.IssueID)
+	})
+}
+
+func TestStores_ReviewShares(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		issue := &models.Issue{ProjectID: p.ID, Title: "Logout", Status: models.IssueStatusInProgress}
+		require.NoError(t, s.CreateIssue(ctx, issue))
+
+		share := &models.ReviewShare{IssueID: issue.ID, Reviewer: "Dana", TokenHash: "h1", ExpiresAt: time.Now().Add(time.Hour)}
+		require.NoError(t, s.CreateReviewShare(ctx, share))
+		assert.Error(t, s.CreateReviewShare(ctx, &models.ReviewShare{IssueID: issue.ID, TokenHash: "h1"}), "duplicate token")
+		assert.Error(t, s.CreateReviewShare(ctx, &models.ReviewShare{IssueID: "missing", TokenHash: "h2"}), "unknown issue")
+
+		got, err := s.GetReviewShareByHash(ctx, "h1")
+		require.NoError(t, err)
+		assert.Equal(t, "Dana", got.Reviewer)
+		assert.Empty(t, got.ReviewID)
+		_, err = s.GetReviewShareByHash(ctx, "nope")
+		assert.Error(t, err)
+
+		review := &models.IssueReview{IssueID: issue.ID, Reviewer: "Dana", Verdict: models.ReviewVerdictFail, Summary: "No redirect", ReviewedAt: time.Now().UTC()}
+		require.NoError(t, s.SubmitReviewShare(ctx, share.ID, review))
+		second := &models.IssueReview{IssueID: issue.ID, Verdict: models.ReviewVerdictPass, ReviewedAt: time.Now().UTC()}
+		assert.ErrorIs(t, s.SubmitReviewShare(ctx, share.ID, second), ErrReviewShareUsed)
+
+		reviews, err := s.ListIssueReviews(ctx, issue.ID)
+		require.NoError(t, err)
+		require.Len(t, reviews, 1, "a refused submission writes nothing")
+		assert.Equal(t, "Dana", reviews[0].Reviewer)
+		shares, err := s.ListReviewShares(ctx, issue.ID)
+		require.NoError(t, err)
+		require.Len(t, shares, 1)
+		assert.Equal(t, review.ID, shares[0].ReviewID)
+
+		require.NoError(t, s.DeleteIssue(ctx, issue.ID))
+		_, err = s.GetReviewShareByHash(ctx, "h1")
+		assert.Error(t, err, "shares cascade with their issue")
+		assert.Error(t, s.DeleteReviewShare(ctx, share.ID))
+	})
+}
+
+func TestStores_SparsePaths(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "mono", Path: "/code/mono", SparsePaths: "tools,services/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		got, err := s.GetProject(ctx, p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "tools,services/api", got.SparsePaths)
+
+		sess := &models.AgentSession{ProjectID: p.ID, Branch: "feature/a", Status: models.SessionStatusActive, SparsePaths: "services/api"}
+		require.NoError(t, s.CreateAgentSession(ctx, sess))
+		sess.SparsePaths = ""
+		require.NoError(t, s.UpdateAgentSession(ctx, sess))
+		gotSess, err := s.GetAgentSession(ctx, sess.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "services/api", gotSess.SparsePaths, "fixed at creation")
+	})
+}
+
+func TestStores_CascadeAndInReview(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api", Cascade: "completed=in_review"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		got, err := s.GetProject(ctx, p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "completed=in_review", got.Cascade)
+		p.Cascade = ""
+		require.NoError(t, s.UpdateProject(ctx, p))
+		got, err = s.GetProject(ctx, p.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got.Cascade)
+
+		review := &models.Issue{ProjectID: p.ID, Title: "Review me", Status: models.IssueStatusInReview}
+		require.NoError(t, s.CreateIssue(ctx, review))
+		wip := &models.Issue{ProjectID: p.ID, Title: "Working", Status: models.IssueStatusInProgress}
+		require.NoError(t, s.CreateIssue(ctx, wip))
+		issues, err := s.ListIssues(ctx, IssueListFilter{ProjectID: p.ID})
+		require.NoError(t, err)
+		require.Len(t, issues, 2)
+		assert.Equal(t, wip.ID, issues[0].ID, "in_review sorts after in_progress")
+		issues, err = s.ListIssues(ctx, IssueListFilter{ProjectID: p.ID, Status: models.IssueStatusInReview})
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, review.ID, issues[0].ID)
+	})
+}
+
+func TestStores_MergeStrategy(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api", MergeStrategy: models.MergeStrategySquash}
+		require.NoError(t, s.CreateProject(ctx, p))
+		p.MergeStrategy = models.MergeStrategyFFOnly
+		require.NoError(t, s.UpdateProject(ctx, p))
+		got, err := s.GetProject(ctx, p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.MergeStrategyFFOnly, got.MergeStrategy)
+
+		sess := &models.AgentSession{ProjectID: p.ID, Branch: "feature/a", Status: models.SessionStatusActive}
+		require.NoError(t, s.CreateAgentSession(ctx, sess))
+		sess.MergeCommit = "abc123"
+		require.NoError(t, s.UpdateAgentSession(ctx, sess))
+		gotSess, err := s.GetAgentSession(ctx, sess.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "abc123", gotSess.MergeCommit)
+	})
+}
+
+func TestStores_ProjectLanguages(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api", Language: "go",
+			Languages: []models.LanguageShare{{Language: "go", Lines: 900, Percent: 90}, {Language: "typescript", Lines: 100, Percent: 10}}}
+		require.NoError(t, s.CreateProject(ctx, p))
+
+		got, err := s.GetProject(ctx, p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, p.Languages, got.Languages)
+
+		got.Languages = nil
+		require.NoError(t, s.UpdateProject(ctx, got))
+		list, err := s.ListProjects(ctx, "")
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Empty(t, list[0].Languages)
+	})
+}
+
+func TestStores_SessionLabels(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		a := &models.AgentSession{ProjectID: p.ID, Branch: "feature/a", Status: models.SessionStatusActive, Labels: "experiment,hotfix"}
+		require.NoError(t, s.CreateAgentSession(ctx, a))
+		b := &models.AgentSession{ProjectID: p.ID, Branch: "feature/b", Status: models.SessionStatusIdle, Labels: "experiment-2"}
+		require.NoError(t, s.CreateAgentSession(ctx, b))
+
+		got, err := s.ListAgentSessionsFiltered(ctx, SessionListFilter{Label: "experiment"})
+		require.NoError(t, err)
+		require.Len(t, got, 1, "whole labels only")
+		assert.Equal(t, a.ID, got[0].ID)
+
+		b.Labels = "experiment"
+		require.NoError(t, s.UpdateAgentSession(ctx, b))
+		got, err = s.ListAgentSessionsFiltered(ctx, SessionListFilter{ProjectID: p.ID, Label: "experiment", Statuses: []models.SessionStatus{models.SessionStatusIdle}})
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, b.ID, got[0].ID)
+
+		got, err = s.ListAgentSessionsFiltered(ctx, SessionListFilter{Label: "experiment", Limit: 1})
+		require.NoError(t, err)
+		assert.Len(t, got, 1)
+	})
+}
+
+func TestStores_SessionsByIssueAndBranch(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		issue := &models.Issue{ProjectID: p.ID, Title: "Bug"}
+		require.NoError(t, s.CreateIssue(ctx, issue))
+		first := &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "feature/bug", Status: models.SessionStatusAbandoned}
+		require.NoError(t, s.CreateAgentSession(ctx, first))
+		retry := &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "feature/bug-2", Status: models.SessionStatusActive}
+		require.NoError(t, s.CreateAgentSession(ctx, retry))
+		other := &models.AgentSession{ProjectID: p.ID, Branch: "feature/bug", Status: models.SessionStatusActive}
+		require.NoError(t, s.CreateAgentSession(ctx, other))
+
+		got, err := s.ListAgentSessionsFiltered(ctx, SessionListFilter{IssueID: issue.ID})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{first.ID, retry.ID}, sessionIDs(got))
+
+		got, err = s.ListAgentSessionsFiltered(ctx, SessionListFilter{Branch: "feature/bug"})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{first.ID, other.ID}, sessionIDs(got))
+
+		got, err = s.ListAgentSessionsFiltered(ctx, SessionListFilter{IssueID: issue.ID, Branch: "feature/bug"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{first.ID}, sessionIDs(got))
+	})
+}
+
+func TestStores_BranchReserved(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		holder := &models.AgentSession{ProjectID: p.ID, Branch: "feature/fix-login", Status: models.SessionStatusActive}
+		require.NoError(t, s.CreateAgentSession(ctx, holder))
+
+		err := s.CreateAgentSession(ctx, &models.AgentSession{ProjectID: p.ID, Branch: "feature/fix-login", Status: models.SessionStatusActive})
+		require.ErrorIs(t, err, ErrBranchReserved)
+		assert.Contains(t, err.Error(), holder.ID)
+		assert.Contains(t, err.Error(), "active session")
+
+		ended := &models.AgentSession{ProjectID: p.ID, Branch: "feature/fix-login", Status: models.SessionStatusAbandoned}
+		require.NoError(t, s.CreateAgentSession(ctx, ended), "ended sessions hold no reservation")
+		ended.Status = models.SessionStatusIdle
+		err = s.TransitionAgentSession(ctx, ended, models.SessionStatusAbandoned, "test")
+		require.ErrorIs(t, err, ErrBranchReserved)
+		assert.Contains(t, err.Error(), holder.ID)
+
+		holder.Status = models.SessionStatusCompleted
+		require.NoError(t, s.UpdateAgentSession(ctx, holder))
+		require.NoError(t, s.UpdateAgentSession(ctx, ended), "closing the holder releases the branch")
+	})
+}
+
+func TestStores_IssueLocked(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		login := &models.Issue{ProjectID: p.ID, Title: "Fix login"}
+		logout := &models.Issue{ProjectID: p.ID, Title: "Add logout"}
+		require.NoError(t, s.CreateIssue(ctx, login))
+		require.NoError(t, s.CreateIssue(ctx, logout))
+
+		holder := &models.AgentSession{ProjectID: p.ID, IssueID: login.ID, ExtraIssueIDs: logout.ID + "," + login.ID, Branch: "feature/fix-login", Status: models.SessionStatusActive}
+		require.NoError(t, s.CreateAgentSession(ctx, holder))
+		got, err := s.GetAgentSession(ctx, holder.ID)
+		require.NoError(t, err)
+		assert.Equal(t, logout.ID, got.ExtraIssueIDs)
+		assert.Equal(t, []string{login.ID, logout.ID}, got.IssueIDs())
+
+		err = s.CreateAgentSession(ctx, &models.AgentSession{ProjectID: p.ID, IssueID: logout.ID, Branch: "feature/add-logout", Status: models.SessionStatusActive})
+		require.ErrorIs(t, err, ErrIssueLocked, "extra issues are locked too")
+		assert.Contains(t, err.Error(), holder.ID)
+
+		second := &models.AgentSession{ProjectID: p.ID, IssueID: login.ID, Branch: "feature/login-v2", Status: models.SessionStatusIdle}
+		require.NoError(t, s.CreateAgentSession(ctx, second), "idle sessions hold no lock")
+		second.Status = models.SessionStatusIdle
+		err = s.TransitionAgentSession(ctx, &models.AgentSession{ID: second.ID, ProjectID: p.ID, IssueID: login.ID, Branch: second.Branch, Status: models.SessionStatusActive}, models.SessionStatusIdle, "test")
+		require.ErrorIs(t, err, ErrIssueLocked)
+		assert.Contains(t, err.Error(), holder.ID)
+
+		holder.Status = models.SessionStatusIdle
+		require.NoError(t, s.UpdateAgentSession(ctx, holder))
+		second.Status = models.SessionStatusActive
+		require.NoError(t, s.UpdateAgentSession(ctx, second), "the holder going idle releases the lock")
+
+		require.NoError(t, s.DeleteIssue(ctx, logout.ID))
+		got, err = s.GetAgentSession(ctx, holder.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got.ExtraIssueIDs, "deleted issues are unlinked")
+	})
+}
+
+func sessionIDs(sessions []*models.AgentSession) []string {
+	ids := make([]string, len(sessions))
+	for i, sess := range sessions {
+		ids[i] = sess.ID
+	}
+	return ids
+}
+
+func TestStores_IssueClosures(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		issue := &models.Issue{ProjectID: p.ID, Title: "Bug", Status: models.IssueStatusDone}
+		require.NoError(t, s.CreateIssue(ctx, issue))
+
+		c := &models.IssueClosure{IssueID: issue.ID, ProjectID: p.ID, Source: models.ClosureSourceCommit, Ref: "abc123",
+			Message: "Fix the bug", FromStatus: models.IssueStatusOpen, ToStatus: models.IssueStatusDone, GitHubIssue: 12}
+		added, err := s.RecordIssueClosure(ctx, c)
+		require.NoError(t, err)
+		assert.True(t, added)
+		added, err = s.RecordIssueClosure(ctx, &models.IssueClosure{IssueID: issue.ID, ProjectID: p.ID, Source: models.ClosureSourceCommit, Ref: "abc123"})
+		require.NoError(t, err)
+		assert.False(t, added, "same reference recorded once")
+
+		got, err := s.GetIssueClosure(ctx, c.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.IssueStatusOpen, got.FromStatus)
+		assert.Equal(t, 12, got.GitHubIssue)
+		assert.Nil(t, got.RevertedAt)
+
+		list, err := s.ListIssueClosures(ctx, p.ID, "")
+		require.NoError(t, err)
+		assert.Len(t, list, 1)
+		list, err = s.ListIssueClosures(ctx, "", "other")
+		require.NoError(t, err)
+		assert.Empty(t, list)
+
+		require.NoError(t, s.RevertIssueClosure(ctx, c.ID))
+		assert.Error(t, s.RevertIssueClosure(ctx, c.ID), "already reverted")
+		got, err = s.GetIssueClosure(ctx, c.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, got.RevertedAt)
+
+		require.NoError(t, s.DeleteIssue(ctx, issue.ID))
+		list, err = s.ListIssueClosures(ctx, p.ID, "")
+		require.NoError(t, err)
+		assert.Empty(t, list, "closures go with their issue")
+	})
+}
+
+func TestStores_PRReviewImports(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		issue := &models.Issue{ProjectID: p.ID, Title: "Bug"}
+		require.NoError(t, s.CreateIssue(ctx, issue))
+
+		r := &models.PRReviewImport{IssueID: issue.ID, ProjectID: p.ID, PRNumber: 7, ReviewID: 9001, Reviewer: "ada",
+			State: models.PRReviewChangesRequested, URL: "https://github.com/o/api/pull/7#pullrequestreview-9001", CommentID: "c1"}
+		added, err := s.RecordPRReviewImport(ctx, r)
+		require.NoError(t, err)
+		assert.True(t, added)
+		added, err = s.RecordPRReviewImport(ctx, &models.PRReviewImport{IssueID: issue.ID, ProjectID: p.ID, PRNumber: 7, ReviewID: 9001})
+		require.NoError(t, err)
+		assert.False(t, added, "same review imported once")
+
+		list, err := s.ListPRReviewImports(ctx, p.ID, "")
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, int64(9001), list[0].ReviewID)
+		assert.Equal(t, "ada", list[0].Reviewer)
+		assert.Equal(t, models.PRReviewChangesRequested, list[0].State)
+		assert.Equal(t, "c1", list[0].CommentID)
+		list, err = s.ListPRReviewImports(ctx, "", "other")
+		require.NoError(t, err)
+		assert.Empty(t, list)
+
+		r.CommentID, r.FollowUpIssueID = "c2", "f1"
+		require.NoError(t, s.UpdatePRReviewImport(ctx, r))
+		list, err = s.ListPRReviewImports(ctx, p.ID, "")
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, "c2", list[0].CommentID)
+		assert.Equal(t, "f1", list[0].FollowUpIssueID)
+		assert.Error(t, s.UpdatePRReviewImport(ctx, &models.PRReviewImport{ID: "missing"}))
+
+		require.NoError(t, s.DeletePRReviewImport(ctx, r.ID))
+		added, err = s.RecordPRReviewImport(ctx, &models.PRReviewImport{IssueID: issue.ID, ProjectID: p.ID, PRNumber: 7, ReviewID: 9001})
+		require.NoError(t, err)
+		assert.True(t, added, "a deleted import can be recorded again")
+
+		require.NoError(t, s.DeleteIssue(ctx, issue.ID))
+		list, err = s.ListPRReviewImports(ctx, p.ID, "")
+		require.NoError(t, err)
+		assert.Empty(t, list, "imports go with their issue")
+	})
+}
+
+func TestStores_BoardItems(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api", GitHubBoard: "acme/3"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		got, err := s.GetProject(ctx, p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "acme/3", got.GitHubBoard)
+		issue := &models.Issue{ProjectID: p.ID, Title: "Bug"}
+		require.NoError(t, s.CreateIssue(ctx, issue))
+
+		item := &models.BoardItem{IssueID: issue.ID, ProjectID: p.ID, ItemID: "PVTI_1", Status: models.IssueStatusOpen, BoardStatus: "Todo"}
+		require.NoError(t, s.SaveBoardItem(ctx, item))
+		item.Status, item.BoardStatus, item.BoardPriority = models.IssueStatusDone, "Done", "High"
+		require.NoError(t, s.SaveBoardItem(ctx, item))
+		assert.Error(t, s.SaveBoardItem(ctx, &models.BoardItem{IssueID: "missing", ProjectID: p.ID}))
+
+		items, err := s.ListBoardItems(ctx, p.ID)
+		require.NoError(t, err)
+		require.Len(t, items, 1, "saving again replaces the record")
+		assert.Equal(t, "PVTI_1", items[0].ItemID)
+		assert.Equal(t, models.IssueStatusDone, items[0].Status)
+		assert.Equal(t, "Done", items[0].BoardStatus)
+		assert.Equal(t, "High", items[0].BoardPriority)
+		assert.False(t, items[0].SyncedAt.IsZero())
+
+		require.NoError(t, s.DeleteIssue(ctx, issue.ID))
+		items, err = s.ListBoardItems(ctx, p.ID)
+		require.NoError(t, err)
+		assert.Empty(t, items, "records go with their issue")
+	})
+}
+
+func TestStores_LLMUsage(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		issue := &models.Issue{ProjectID: p.ID, Title: "Bug"}
+		require.NoError(t, s.CreateIssue(ctx, issue))
+
+		require.NoError(t, s.RecordLLMUsage(ctx, &models.LLMUsage{ProjectID: p.ID, IssueID: issue.ID, Purpose: models.LLMUsageReviewPreAnalysis,
+			Model: "claude-x", InputTokens: 1200, OutputTokens: 300}))
+		require.NoError(t, s.RecordLLMUsage(ctx, &models.LLMUsage{ProjectID: p.ID, Purpose: models.LLMUsageReviewPreAnalysis, InputTokens: 10}))
+		assert.Error(t, s.RecordLLMUsage(ctx, &models.LLMUsage{ProjectID: "missing", Purpose: models.LLMUsageReviewPreAnalysis}))
+
+		usage, err := s.ListLLMUsage(ctx, p.ID, "")
+		require.NoError(t, err)
+		assert.Len(t, usage, 2)
+		usage, err = s.ListLLMUsage(ctx, "", issue.ID)
+		require.NoError(t, err)
+		require.Len(t, usage, 1)
+		assert.Equal(t, int64(1200), usage[0].InputTokens)
+		assert.Equal(t, int64(300), usage[0].OutputTokens)
+		assert.Equal(t, "claude-x", usage[0].Model)
+
+		require.NoError(t, s.DeleteIssue(ctx, issue.ID))
+		usage, err = s.ListLLMUsage(ctx, "", issue.ID)
+		require.NoError(t, err)
+		assert.Len(t, usage, 1, "usage outlives its issue")
+
+		require.NoError(t, s.DeleteProject(ctx, p.ID))
+		usage, err = s.ListLLMUsage(ctx, p.ID, "")
+		require.NoError(t, err)
+		assert.Empty(t, usage, "usage goes with its project")
+	})
+}
+
+func TestStores_PushSubscriptions(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		sub := &models.PushSubscription{Endpoint: "https://push.example/a", P256dh: "key1", Auth: "auth1"}
+		require.NoError(t, s.SavePushSubscription(ctx, sub))
+		assert.NotEmpty(t, sub.ID)
+		require.NoError(t, s.SavePushSubscription(ctx, &models.PushSubscription{Endpoint: "https://push.example/b", P256dh: "key2", Auth: "auth2"}))
+
+		again := &models.PushSubscription{Endpoint: "https://push.example/a", P256dh: "key3", Auth: "auth3"}
+		require.NoError(t, s.SavePushSubscription(ctx, again))
+		assert.Equal(t, sub.ID, again.ID, "same browser, same subscription")
+
+		subs, err := s.ListPushSubscriptions(ctx)
+		require.NoError(t, err)
+		require.Len(t, subs, 2)
+		assert.Equal(t, "key3", subs[0].P256dh)
+
+		require.NoError(t, s.DeletePushSubscription(ctx, "https://push.example/a"))
+		assert.Error(t, s.DeletePushSubscription(ctx, "https://push.example/a"))
+		subs, err = s.ListPushSubscriptions(ctx)
+		require.NoError(t, err)
+		assert.Len(t, subs, 1)
+	})
+}
+
+func TestStores_IssueWatchersAndComments(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		issue := &models.Issue{ProjectID: p.ID, Title: "Bug"}
+		other := &models.Issue{ProjectID: p.ID, Title: "Other"}
+		require.NoError(t, s.CreateIssue(ctx, issue))
+		require.NoError(t, s.CreateIssue(ctx, other))
+
+		added, err := s.AddIssueWatcher(ctx, &models.IssueWatcher{IssueID: issue.ID, Kind: models.WatcherEmail, Target: "dev@example.com"})
+		require.NoError(t, err)
+		assert.True(t, added)
+		added, err = s.AddIssueWatcher(ctx, &models.IssueWatcher{IssueID: issue.ID, Kind: models.WatcherEmail, Target: "dev@example.com"})
+		require.NoError(t, err)
+		assert.False(t, added, "watching twice is a no-op")
+		_, err = s.AddIssueWatcher(ctx, &models.IssueWatcher{IssueID: other.ID, Kind: models.WatcherWebhook, Target: "https://hooks.example.com/pm"})
+		require.NoError(t, err)
+
+		watchers, err := s.ListIssueWatchers(ctx, issue.ID)
+		require.NoError(t, err)
+		require.Len(t, watchers, 1)
+		assert.Equal(t, models.WatcherEmail, watchers[0].Kind)
+		all, err := s.ListIssueWatchers(ctx, "")
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+
+		require.NoError(t, s.RemoveIssueWatcher(ctx, issue.ID, "dev@example.com"))
+		assert.Error(t, s.RemoveIssueWatcher(ctx, issue.ID, "dev@example.com"))
+
+		require.NoError(t, s.CreateIssueComment(ctx, &models.IssueComment{IssueID: issue.ID, Author: "joe", Body: "first"}))
+		require.NoError(t, s.CreateIssueComment(ctx, &models.IssueComment{IssueID: issue.ID, Author: "bot", Body: "second"}))
+		assert.Error(t, s.CreateIssueComment(ctx, &models.IssueComment{IssueID: "missing", Body: "x"}))
+		comments, err := s.ListIssueComments(ctx, issue.ID)
+		require.NoError(t, err)
+		require.Len(t, comments, 2)
+		assert.Equal(t, "first", comments[0].Body)
+		assert.Equal(t, "bot", comments[1].Author)
+
+		require.NoError(t, s.DeleteIssue(ctx, other.ID))
+		all, err = s.ListIssueWatchers(ctx, "")
+		require.NoError(t, err)
+		assert.Empty(t, all, "watchers cascade")
+	})
+}
+
+func TestStores_ChangeFeed(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		type entry struct {
+			Entity    models.ChangeEntity
+			ID        string
+			ProjectID string
+			Op        models.ChangeOp
+		}
+		entries := func(changes []*models.Change) []entry {
+			var out []entry
+			for _, c := range changes {
+				out = append(out, entry{c.Entity, c.EntityID, c.ProjectID, c.Op})
+			}
+			return out
+		}
+
+		first, last, err := s.ChangeSeqRange(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), last)
+		assert.Equal(t, int64(1), first, "an empty feed starts after the last sequence number")
+
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		other := &models.Project{Name: "web", Path: "/code/web"}
+		require.NoError(t, s.CreateProject(ctx, other))
+		issue := &models.Issue{ProjectID: p.ID, Title: "Bug"}
+		require.NoError(t, s.CreateIssue(ctx, issue))
+		_, cursor, err := s.ChangeSeqRange(ctx)
+		require.NoError(t, err)
+
+		// Status changes update the cycle timestamps too, but are one change
+		issue.Status = models.IssueStatusInProgress
+		require.NoError(t, s.UpdateIssue(ctx, issue))
+		require.NoError(t, s.SetIssueSLAState(ctx, issue.ID, models.SLAStateAtRisk))
+		require.NoError(t, s.SetIssueSLAState(ctx, issue.ID, models.SLAStateAtRisk))
+		comment := &models.IssueComment{IssueID: issue.ID, Body: "looking"}
+		require.NoError(t, s.CreateIssueComment(ctx, comment))
+		sess := &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "fix", Status: models.SessionStatusActive}
+		require.NoError(t, s.CreateAgentSession(ctx, sess))
+		sess.Status = models.SessionStatusIdle
+		require.NoError(t, s.UpdateAgentSession(ctx, sess))
+		note := &models.ProjectNote{ProjectID: other.ID, Title: "Plan"}
+		require.NoError(t, s.CreateProjectNote(ctx, note))
+
+		changes, err := s.ListChanges(ctx, ChangeFilter{Since: cursor})
+		require.NoError(t, err)
+		assert.Equal(t, []entry{
+			{models.ChangeIssue, issue.ID, p.ID, models.ChangeUpdate},
+			{models.ChangeIssue, issue.ID, p.ID, models.ChangeUpdate},
+			{models.ChangeComment, comment.ID, p.ID, models.ChangeInsert},
+			{models.ChangeSession, sess.ID, p.ID, models.ChangeInsert},
+			{models.ChangeSession, sess.ID, p.ID, models.ChangeUpdate},
+			{models.ChangeNote, note.ID, other.ID, models.ChangeInsert},
+		}, entries(changes))
+		for i := 1; i < len(changes); i++ {
+			assert.Greater(t, changes[i].Seq, changes[i-1].Seq)
+		}
+
+		filtered, err := s.ListChanges(ctx, ChangeFilter{Since: cursor, ProjectID: p.ID, Entities: []models.ChangeEntity{models.ChangeSession}, Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []entry{{models.ChangeSession, sess.ID, p.ID, models.ChangeInsert}}, entries(filtered))
+
+		// Cascaded deletes are in the feed; records whose project came from
+		// the deleted issue have none. Its session is unlinked.
+		_, cursor, err = s.ChangeSeqRange(ctx)
+		require.NoError(t, err)
+		require.NoError(t, s.DeleteIssue(ctx, issue.ID))
+		changes, err = s.ListChanges(ctx, ChangeFilter{Since: cursor})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []entry{
+			{models.ChangeIssue, issue.ID, p.ID, models.ChangeDelete},
+			{models.ChangeComment, comment.ID, "", models.ChangeDelete},
+			{models.ChangeSession, sess.ID, p.ID, models.ChangeUpdate},
+		}, entries(changes))
+
+		// Pruning keeps the sequence: new changes continue after the old ones
+		_, last, err = s.ChangeSeqRange(ctx)
+		require.NoError(t, err)
+		n, err := s.PruneChanges(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, last, n)
+		first, got, err := s.ChangeSeqRange(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, last, got)
+		assert.Equal(t, last+1, first)
+		require.NoError(t, s.DeleteProject(ctx, other.ID))
+		changes, err = s.ListChanges(ctx, ChangeFilter{})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []entry{
+			{models.ChangeProject, other.ID, other.ID, models.ChangeDelete},
+			{models.ChangeNote, note.ID, other.ID, models.ChangeDelete},
+		}, entries(changes))
+		assert.Greater(t, changes[0].Seq, last)
+	})
+}
+
+func TestStores_AcceptanceCriteria(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		issue := &models.Issue{ProjectID: p.ID, Title: "Export", AcceptanceCriteria: []string{"CSV download", "Handles 10k rows"}}
+		require.NoError(t, s.CreateIssue(ctx, issue))
+
+		got, err := s.GetIssue(ctx, issue.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"CSV download", "Handles 10k rows"}, got.AcceptanceCriteria)
+		got.AcceptanceCriteria = nil
+		require.NoError(t, s.UpdateIssue(ctx, got))
+		listed, err := s.ListIssues(ctx, IssueListFilter{ProjectID: p.ID})
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Empty(t, listed[0].AcceptanceCriteria)
+
+		results := []models.CriterionResult{{Criterion: "CSV download", Passed: true}, {Criterion: "Handles 10k rows", Note: "times out"}}
+		require.NoError(t, s.CreateIssueReview(ctx, &models.IssueReview{IssueID: issue.ID, Verdict: models.ReviewVerdictFail, Summary: "slow", Criteria: results, ReviewedAt: time.Now()}))
+		require.NoError(t, s.CreateIssueReview(ctx, &models.IssueReview{IssueID: issue.ID, Verdict: models.ReviewVerdictPass, Summary: "ok", ReviewedAt: time.Now().Add(-time.Hour)}))
+		reviews, err := s.ListIssueReviews(ctx, issue.ID)
+		require.NoError(t, err)
+		require.Len(t, reviews, 2)
+		assert.Equal(t, results, reviews[0].Criteria)
+		assert.Empty(t, reviews[1].Criteria)
+	})
+}
+
+func TestStores_UniqueRecords(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		a := &models.Issue{ProjectID: p.ID, Title: "A", Status: models.IssueStatusOpen}
+		b := &models.Issue{ProjectID: p.ID, Title: "B", Status: models.IssueStatusOpen}
+		require.NoError(t, s.CreateIssue(ctx, a))
+		require.NoError(t, s.CreateIssue(ctx, b))
+
+		rel := &models.IssueRelation{SourceIssueID: a.ID, TargetIssueID: b.ID, Type: models.RelationBlocks}
+		require.NoError(t, s.CreateIssueRelation(ctx, rel))
+		err := s.CreateIssueRelation(ctx, &models.IssueRelation{SourceIssueID: a.ID, TargetIssueID: b.ID, Type: models.RelationBlocks})
+		assert.ErrorContains(t, err, "relation already exists")
+		rels, err := s.ListIssueRelations(ctx, b.ID)
+		require.NoError(t, err)
+		assert.Len(t, rels, 1)
+
+		token := &models.APIToken{ProjectID: p.ID, TokenHash: "abc"}
+		require.NoError(t, s.CreateAPIToken(ctx, token))
+		assert.Error(t, s.CreateAPIToken(ctx, &models.APIToken{ProjectID: p.ID, TokenHash: "abc"}))
+		got, err := s.GetAPITokenByHash(ctx, "abc")
+		require.NoError(t, err)
+		assert.Equal(t, token.ID, got.ID)
+
+		breach := &models.SLABreach{IssueID: a.ID, ProjectID: p.ID, Policy: "high", Kind: models.SLABreachStart}
+		created, err := s.RecordSLABreach(ctx, breach)
+		require.NoError(t, err)
+		assert.True(t, created)
+		created, err = s.RecordSLABreach(ctx, &models.SLABreach{IssueID: a.ID, ProjectID: p.ID, Policy: "high", Kind: models.SLABreachStart})
+		require.NoError(t, err)
+		assert.False(t, created, "same policy deadline breached once")
+		breaches, err := s.ListSLABreaches(ctx, time.Time{})
+		require.NoError(t, err)
+		assert.Len(t, breaches, 1)
+
+		for range 3 {
+			require.NoError(t, s.CreateHealthSnapshot(ctx, &models.HealthSnapshot{ProjectID: p.ID}))
+		}
+		snaps, err := s.ListHealthSnapshots(ctx, p.ID, 2)
+		require.NoError(t, err)
+		assert.Len(t, snaps, 2)
+
+		note := &models.ProjectNote{ProjectID: p.ID, Title: "ADR"}
+		require.NoError(t, s.CreateProjectNote(ctx, note))
+		require.NoError(t, s.CreateProjectNote(ctx, &models.ProjectNote{ProjectID: p.ID, Title: "runbook"}))
+		note.Title = "Runbook"
+		assert.ErrorContains(t, s.UpdateProjectNote(ctx, note), "already exists")
+	})
+}
+
+func TestMemoryStore_CopiesValues(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+	writes := 0
+	s.OnWrite(func() { writes++ })
+
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	p.Name = "changed"
+
+	got, err := s.GetProject(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "api", got.Name, "later changes to the caller's value are not stored")
+	got.Name = "also changed"
+
+	again, err := s.GetProjectByPath(ctx, "/code/api")
+	require.NoError(t, err)
+	assert.Equal(t, "api", again.Name, "returned values are copies")
+
+	assert.Error(t, s.UpdateProject(ctx, &models.Project{ID: "missing"}))
+	assert.Equal(t, 1, writes, "only successful writes notify")
+
+	v, err := s.SchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, LatestSchemaVersion(), v)
+}
+
+func TestStores_ImportedTimestamps(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := WithImportedTimestamps(context.Background())
+		created := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+		started := created.Add(2 * time.Hour)
+		completed := created.Add(48 * time.Hour)
+
+		p := &models.Project{Name: "api", Path: "/code/api", CreatedAt: created, UpdatedAt: completed}
+		require.NoError(t, s.CreateProject(ctx, p))
+		issue := &models.Issue{ProjectID: p.ID, Title: "Bug", Status: models.IssueStatusDone,
+			CreatedAt: created, UpdatedAt: completed, StartedAt: &started, CompletedAt: &completed}
+		require.NoError(t, s.CreateIssue(ctx, issue))
+		sess := &models.AgentSession{ProjectID: p.ID, IssueID: issue.ID, Branch: "fix", Status: models.SessionStatusCompleted, StartedAt: started}
+		require.NoError(t, s.CreateAgentSession(ctx, sess))
+		note := &models.ProjectNote{ProjectID: p.ID, Title: "Plan", CreatedAt: created, UpdatedAt: created}
+		require.NoError(t, s.CreateProjectNote(ctx, note))
+
+		gotProject, err := s.GetProject(ctx, p.ID)
+		require.NoError(t, err)
+		assert.True(t, created.Equal(gotProject.CreatedAt))
+		gotIssue, err := s.GetIssue(ctx, issue.ID)
+		require.NoError(t, err)
+		assert.True(t, created.Equal(gotIssue.CreatedAt))
+		require.NotNil(t, gotIssue.StartedAt)
+		assert.True(t, started.Equal(*gotIssue.StartedAt))
+		require.NotNil(t, gotIssue.CompletedAt)
+		assert.True(t, completed.Equal(*gotIssue.CompletedAt))
+		gotSession, err := s.GetAgentSession(ctx, sess.ID)
+		require.NoError(t, err)
+		assert.True(t, started.Equal(gotSession.StartedAt))
+		gotNote, err := s.GetProjectNote(ctx, note.ID)
+		require.NoError(t, err)
+		assert.True(t, created.Equal(gotNote.CreatedAt))
+
+		// Without the import context, timestamps are stamped as usual
+		fresh := &models.Issue{ProjectID: p.ID, Title: "New", CreatedAt: created}
+		require.NoError(t, s.CreateIssue(context.Background(), fresh))
+		assert.True(t, fresh.CreatedAt.After(created))
+	})
+}
+
+func TestStores_ShortCodes(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "widgets", Path: "/code/widgets"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		assert.Equal(t, "WID", p.Key)
+		clash := &models.Project{Name: "widget-docs", Path: "/code/wd", Key: "WID"}
+		assert.ErrorIs(t, s.CreateProject(ctx, clash), ErrProjectKeyTaken)
+		assert.Error(t, s.CreateProject(ctx, &models.Project{Name: "x", Path: "/code/x", Key: "bad-key"}))
+		same := &models.Project{Name: "widgets2", Path: "/code/widgets2"}
+		require.NoError(t, s.CreateProject(ctx, same))
+		assert.Equal(t, "WID2", same.Key, "derived keys are made unique")
+
+		first := &models.Issue{ProjectID: p.ID, Title: "First"}
+		require.NoError(t, s.CreateIssue(ctx, first))
+		second := &models.Issue{ProjectID: p.ID, Title: "Second"}
+		require.NoError(t, s.CreateIssue(ctx, second))
+		other := &models.Issue{ProjectID: same.ID, Title: "Other"}
+		require.NoError(t, s.CreateIssue(ctx, other))
+		assert.Equal(t, "WID-1", first.Code)
+		assert.Equal(t, "WID-2", second.Code)
+		assert.Equal(t, "WID2-1", other.Code, "each project counts from 1")
+		sess := &models.AgentSession{ProjectID: p.ID, IssueID: second.ID, Branch: "feature/second", Status: models.SessionStatusActive}
+		require.NoError(t, s.CreateAgentSession(ctx, sess))
+		assert.Equal(t, "WID-S1", sess.Code)
+
+		// Deleted issues keep their numbers used up
+		require.NoError(t, s.DeleteIssue(ctx, second.ID))
+		third := &models.Issue{ProjectID: p.ID, Title: "Third"}
+		require.NoError(t, s.CreateIssue(ctx, third))
+		assert.Equal(t, "WID-3", third.Code)
+
+		got, err := s.GetIssueByCode(ctx, "WID-3")
+		require.NoError(t, err)
+		assert.Equal(t, third.ID, got.ID)
+		_, err = s.GetIssueByCode(ctx, "WID-2")
+		assert.Error(t, err)
+		gotSess, err := s.GetAgentSessionByCode(ctx, "WID-S1")
+		require.NoError(t, err)
+		assert.Equal(t, sess.ID, gotSess.ID)
+
+		// Updates keep codes and keys
+		first.Title = "First, retitled"
+		first.Code = ""
+		require.NoError(t, s.UpdateIssue(ctx, first))
+		got, err = s.GetIssue(ctx, first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "WID-1", got.Code)
+		p.Key = ""
+		require.NoError(t, s.UpdateProject(ctx, p))
+		gotProject, err := s.GetProject(ctx, p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "WID", gotProject.Key)
+
+		// Changing the key re-prefixes the project's codes
+		assert.ErrorIs(t, s.SetProjectKey(ctx, p.ID, "WID2"), ErrProjectKeyTaken)
+		require.NoError(t, s.SetProjectKey(ctx, p.ID, "GIZ"))
+		got, err = s.GetIssue(ctx, third.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "GIZ-3", got.Code)
+		gotSess, err = s.GetAgentSession(ctx, sess.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "GIZ-S1", gotSess.Code)
+		got, err = s.GetIssue(ctx, other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "WID2-1", got.Code, "prefixes that merely start alike are untouched")
+		fourth := &models.Issue{ProjectID: p.ID, Title: "Fourth"}
+		require.NoError(t, s.CreateIssue(ctx, fourth))
+		assert.Equal(t, "GIZ-4", fourth.Code)
+	})
+}
+
+func TestStores_RecordIssueMerge(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "app", Path: "/code/app"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		issue := &models.Issue{ProjectID: p.ID, Title: "Ship it", MergeCommit: "ignored"}
+		require.NoError(t, s.CreateIssue(ctx, issue))
+		got, err := s.GetIssue(ctx, issue.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got.MergeCommit, "only a merge sets it")
+
+		at := time.Date(2026, 5, 4, 10, 30, 0, 0, time.UTC)
+		require.NoError(t, s.RecordIssueMerge(ctx, issue.ID, "abc123", "main", at))
+		got, err = s.GetIssue(ctx, issue.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "abc123", got.MergeCommit)
+		assert.Equal(t, "main", got.MergeBranch)
+		require.NotNil(t, got.MergedAt)
+		assert.True(t, got.MergedAt.Equal(at))
+
+		// Editing the issue keeps its merge
+		got.Title = "Shipped"
+		got.Status = models.IssueStatusDone
+		require.NoError(t, s.UpdateIssue(ctx, got))
+		issues, err := s.ListIssues(ctx, IssueListFilter{ProjectID: p.ID})
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "abc123", issues[0].MergeCommit)
+		assert.Equal(t, "main", issues[0].MergeBranch)
+
+		assert.Error(t, s.RecordIssueMerge(ctx, "missing", "abc123", "main", at))
+	})
+}
+
+func TestStores_ProjectReleases(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "app", Path: "/code/app"}
+		require.NoError(t, s.CreateProject(ctx, p))
+
+		got, err := s.GetProjectRelease(ctx, p.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got, "never fetched")
+
+		fetched := time.Date(2026, 5, 4, 10, 30, 0, 0, time.UTC)
+		published := fetched.Add(-48 * time.Hour)
+		require.NoError(t, s.SaveProjectRelease(ctx, &models.ProjectRelease{
+			ProjectID:   p.ID,
+			TagName:     "v1.2.0",
+			PublishedAt: &published,
+			Assets:      []models.ReleaseAsset{{Name: "app.tar.gz", DownloadCount: 12, Size: 2048}},
+			FetchedAt:   &fetched,
+			CheckedAt:   fetched,
+		}))
+		got, err = s.GetProjectRelease(ctx, p.ID)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, "v1.2.0", got.TagName)
+		require.NotNil(t, got.PublishedAt)
+		assert.True(t, got.PublishedAt.Equal(published))
+		assert.Equal(t, []models.ReleaseAsset{{Name: "app.tar.gz", DownloadCount: 12, Size: 2048}}, got.Assets)
+
+		// A failed fetch is saved over the previous one, keeping its release
+		got.CheckedAt = fetched.Add(time.Hour)
+		got.Error = "rate limited"
+		require.NoError(t, s.SaveProjectRelease(ctx, got))
+		all, err := s.ListProjectReleases(ctx)
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		assert.Equal(t, "v1.2.0", all[0].TagName)
+		assert.Equal(t, "rate limited", all[0].Error)
+		assert.True(t, all[0].FetchedAt.Equal(fetched))
+		assert.True(t, all[0].CheckedAt.Equal(fetched.Add(time.Hour)))
+
+		assert.Error(t, s.SaveProjectRelease(ctx, &models.ProjectRelease{ProjectID: "missing", CheckedAt: fetched}))
+
+		require.NoError(t, s.DeleteProject(ctx, p.ID))
+		all, err = s.ListProjectReleases(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, all)
+	})
+}
+
+func TestStores_MergeIssues(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		target := &models.Issue{ProjectID: p.ID, Title: "Login fails"}
+		dup := &models.Issue{ProjectID: p.ID, Title: "Cannot sign in"}
+		require.NoError(t, s.CreateIssue(ctx, target))
+		require.NoError(t, s.CreateIssue(ctx, dup))
+		tag := &models.Tag{Name: "ui"}
+		require.NoError(t, s.CreateTag(ctx, tag))
+		_, err := s.AddIssueWatcher(ctx, &models.IssueWatcher{IssueID: target.ID, Kind: models.WatcherEmail, Target: "qa@example.com"})
+		require.NoError(t, err)
+
+		merge := func(relTarget string) *IssueMerge {
+			target.Body = "merged"
+			dup.Status = models.IssueStatusClosed
+			return &IssueMerge{
+				Target:     target,
+				Duplicates: []*models.Issue{dup},
+				TagIDs:     []string{tag.ID},
+				Watchers:   []*models.IssueWatcher{{IssueID: target.ID, Kind: models.WatcherEmail, Target: "qa@example.com"}},
+				Relations:  []*models.IssueRelation{{SourceIssueID: dup.ID, TargetIssueID: relTarget, Type: models.RelationDuplicates}},
+				Comments:   []*models.IssueComment{{IssueID: dup.ID, Author: "pm", Body: "Merged"}},
+			}
+		}
+
+		// A merge that fails part way writes nothing
+		assert.Error(t, s.MergeIssues(ctx, merge("missing")))
+		got, err := s.GetIssue(ctx, dup.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.IssueStatusOpen, got.Status)
+		got, err = s.GetIssue(ctx, target.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got.Body)
+		comments, err := s.ListIssueComments(ctx, dup.ID)
+		require.NoError(t, err)
+		assert.Empty(t, comments)
+
+		require.NoError(t, s.MergeIssues(ctx, merge(target.ID)))
+		got, err = s.GetIssue(ctx, dup.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.IssueStatusClosed, got.Status)
+		got, err = s.GetIssue(ctx, target.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "merged", got.Body)
+		assert.Equal(t, []string{"ui"}, got.Tags)
+		watchers, err := s.ListIssueWatchers(ctx, target.ID)
+		require.NoError(t, err)
+		assert.Len(t, watchers, 1, "existing watchers are skipped")
+		rels, err := s.ListIssueRelations(ctx, target.ID)
+		require.NoError(t, err)
+		assert.Len(t, rels, 1)
+		comments, err = s.ListIssueComments(ctx, dup.ID)
+		require.NoError(t, err)
+		assert.Len(t, comments, 1)
+
+		require.NoError(t, s.MergeIssues(ctx, merge(target.ID)), "existing relations are skipped")
+	})
+}
@@ -0,0 +1,371 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migrations are embedded SQL files named NNN_description.sql, where NNN is
+// the schema version they bring the database to. An optional
+// NNN_description.down.sql reverts one; migrations without it are
+// irreversible. Applied migrations are recorded in schema_migrations by
+// filename, and the schema version is the highest one applied.
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+const downSuffix = ".down.sql"
+
+// migrationHooks run Go code in a migration's transaction, after its SQL, for
+// changes SQL cannot express. They are keyed by the up migration's filename.
+var migrationHooks = map[string]func(ctx context.Context, tx *sql.Tx, s *SQLiteStore, down bool) error{
+	// Compress the existing large text values; reverting decompresses them
+	// all, since older binaries read text only.
+	"051_compress_large_text.sql": func(ctx context.Context, tx *sql.Tx, s *SQLiteStore, down bool) error {
+		c := s.compression
+		if down {
+			c = Compression{Codec: "none"}
+		}
+		_, err := repackText(ctx, tx, c)
+		return err
+	},
+	// Key the existing projects and number their issues and sessions;
+	// reverting drops the columns, so there is nothing to undo.
+	"058_add_short_codes.sql": func(ctx context.Context, tx *sql.Tx, s *SQLiteStore, down bool) error {
+		if down {
+			return nil
+		}
+		return backfillShortCodes(ctx, tx)
+	},
+	// Rewrite the existing timestamps in UTC; UTC reads the same to older
+	// binaries, so reverting leaves them.
+	"064_utc_timestamps.sql": func(ctx context.Context, tx *sql.Tx, s *SQLiteStore, down bool) error {
+		if down {
+			return nil
+		}
+		_, err := utcTimestamps(ctx, tx)
+		return err
+	},
+}
+
+// Migration is one embedded schema migration and, for a database, whether it
+// has been applied.
+type Migration struct {
+	Version    int
+	Name       string // filename of the up migration
+	Reversible bool   // a down migration exists
+	AppliedAt  *time.Time
+}
+
+// MigrationPlan lists the migrations that take a database from one schema
+// version to another, in the order they run.
+type MigrationPlan struct {
+	From  int
+	To    int
+	Down  bool
+	Steps []Migration
+}
+
+// SchemaVersionError reports a database migrated by a newer binary than the
+// one running. Carrying on could write rows the newer schema does not expect.
+type SchemaVersionError struct {
+	Database int // schema version of the database
+	Binary   int // latest schema version this binary knows
+}
+
+func (e *SchemaVersionError) Error() string {
+	return fmt.Sprintf("database schema version %d is newer than this binary supports (%d): upgrade pm, or migrate down with the newer binary (pm db migrate --to %d)",
+		e.Database, e.Binary, e.Binary)
+}
+
+// migrationVersion parses the version prefix of a migration filename.
+func migrationVersion(name string) (int, error) {
+	prefix, _, ok := strings.Cut(name, "_")
+	if !ok {
+		return 0, fmt.Errorf("migration %s: name must be NNN_description.sql", name)
+	}
+	v, err := strconv.Atoi(prefix)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("migration %s: invalid version %q", name, prefix)
+	}
+	return v, nil
+}
+
+// embeddedMigrations returns the embedded up migrations ordered by version.
+func embeddedMigrations() ([]Migration, error) {
+	entries, err := migrationsFS.ReadDir("migrations")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations dir: %w", err)
+	}
+
+	names := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		names[e.Name()] = true
+	}
+
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasSuffix(name, downSuffix) {
+			continue
+		}
+		v, err := migrationVersion(name)
+		if err != nil {
+			return nil, err
+		}
+		if other, dup := seen[v]; dup {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, name, v)
+		}
+		seen[v] = name
+		migrations = append(migrations, Migration{
+			Version:    v,
+			Name:       name,
+			Reversible: names[downName(name)],
+		})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func downName(name string) string {
+	return strings.TrimSuffix(name, ".sql") + downSuffix
+}
+
+// LatestSchemaVersion returns the schema version this binary migrates to.
+func LatestSchemaVersion() int {
+	migrations, err := embeddedMigrations()
+	if err != nil || len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+func (s *SQLiteStore) ensureMigrationsTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		filename TEXT PRIMARY KEY,
+		applied_at DATETIME NOT NULL DEFAULT (datetime('now'))
+	)`)
+	if err != nil {
+		return fmt.Errorf("create migrations table: %w", err)
+	}
+	return nil
+}
+
+// appliedMigrations returns the applied_at time of each recorded migration,
+// keyed by filename.
+func (s *SQLiteStore) appliedMigrations(ctx context.Context) (map[string]time.Time, error) {
+	if err := s.ensureMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, "SELECT filename, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("list applied migrations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	applied := make(map[string]time.Time)
+	for rows.Next() {
+		var name string
+		var at time.Time
+		if err := rows.Scan(&name, &at); err != nil {
+			return nil, fmt.Errorf("scan applied migration: %w", err)
+		}
+		applied[name] = at
+	}
+	return applied, rows.Err()
+}
+
+// SchemaVersion returns the highest migration version applied to the
+// database, including migrations this binary does not know about.
+func (s *SQLiteStore) SchemaVersion(ctx context.Context) (int, error) {
+	applied, err := s.appliedMigrations(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return schemaVersion(applied), nil
+}
+
+func schemaVersion(applied map[string]time.Time) int {
+	version := 0
+	for name := range applied {
+		if v, err := migrationVersion(name); err == nil && v > version {
+			version = v
+		}
+	}
+	return version
+}
+
+// Migrations returns every embedded migration with its applied time, if any.
+func (s *SQLiteStore) Migrations(ctx context.Context) ([]Migration, error) {
+	migrations, _, err := s.migrationState(ctx)
+	return migrations, err
+}
+
+func (s *SQLiteStore) migrationState(ctx context.Context) ([]Migration, map[string]time.Time, error) {
+	migrations, err := embeddedMigrations()
+	if err != nil {
+		return nil, nil, err
+	}
+	applied, err := s.appliedMigrations(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range migrations {
+		if at, ok := applied[migrations[i].Name]; ok {
+			migrations[i].AppliedAt = &at
+		}
+	}
+	return migrations, applied, nil
+}
+
+// PlanMigration works out which migrations bring the database to schema
+// version target without changing anything. Moving up applies every pending
+// migration up to target; moving down reverts applied migrations above it
+// and fails if one of them is irreversible.
+func (s *SQLiteStore) PlanMigration(ctx context.Context, target int) (*MigrationPlan, error) {
+	migrations, applied, err := s.migrationState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := 0
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].Version
+	}
+	current := schemaVersion(applied)
+	if current > latest {
+		return nil, &SchemaVersionError{Database: current, Binary: latest}
+	}
+	if target < 0 || target > latest {
+		return nil, fmt.Errorf("unknown schema version %d (this binary knows 0 to %d)", target, latest)
+	}
+
+	plan := &MigrationPlan{From: current, To: target, Down: target < current}
+	if !plan.Down {
+		for _, m := range migrations {
+			if m.AppliedAt == nil && m.Version <= target {
+				plan.Steps = append(plan.Steps, m)
+			}
+		}
+		return plan, nil
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.AppliedAt == nil || m.Version <= target {
+			continue
+		}
+		if !m.Reversible {
+			return nil, fmt.Errorf("cannot migrate down to version %d: migration %s is irreversible", target, m.Name)
+		}
+		plan.Steps = append(plan.Steps, m)
+	}
+	return plan, nil
+}
+
+// Preflight checks the database is sound before migrating it.
+func (s *SQLiteStore) Preflight(ctx context.Context) error {
+	var result string
+	if err := s.db.QueryRowContext(ctx, "PRAGMA quick_check").Scan(&result); err != nil {
+		return fmt.Errorf("preflight: integrity check: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("preflight: database failed its integrity check: %s", result)
+	}
+	return nil
+}
+
+// Backup writes a consistent copy of the database to dest.
+func (s *SQLiteStore) Backup(ctx context.Context, dest string) error {
+	if _, err := s.db.ExecContext(ctx, "VACUUM INTO ?", dest); err != nil {
+		return fmt.Errorf("back up database to %s: %w", dest, err)
+	}
+	return nil
+}
+
+// Migrate brings the database to the latest schema version, backing it up
+// first if it already has a schema.
+func (s *SQLiteStore) Migrate(ctx context.Context) error {
+	plan, err := s.PlanMigration(ctx, LatestSchemaVersion())
+	if err != nil {
+		return err
+	}
+	_, err = s.ApplyMigration(ctx, plan, true)
+	return err
+}
+
+// ApplyMigration runs a plan from PlanMigration, each step in its own
+// transaction. Unless the plan is empty or the database is new, it first runs
+// Preflight and, when backup is set, copies the database next to its file;
+// the returned path is that copy ("" when none was made).
+func (s *SQLiteStore) ApplyMigration(ctx context.Context, plan *MigrationPlan, backup bool) (string, error) {
+	if len(plan.Steps) == 0 {
+		return "", nil
+	}
+
+	var backupPath string
+	if plan.From > 0 {
+		if err := s.Preflight(ctx); err != nil {
+			return "", err
+		}
+		if backup && s.path != ":memory:" {
+			backupPath = fmt.Sprintf("%s.v%03d-%s.bak", s.path, plan.From, time.Now().Format("20060102-150405"))
+			if err := s.Backup(ctx, backupPath); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	for _, m := range plan.Steps {
+		if err := s.runMigration(ctx, m, plan.Down); err != nil {
+			return backupPath, err
+		}
+	}
+	return backupPath, nil
+}
+
+// runMigration applies (or, when down, reverts) m and records it in one
+// transaction, so a failing migration leaves no trace.
+func (s *SQLiteStore) runMigration(ctx context.Context, m Migration, down bool) error {
+	file, record, verb := m.Name, "INSERT INTO schema_migrations (filename) VALUES (?)", "apply"
+	if down {
+		file, record, verb = downName(m.Name), "DELETE FROM schema_migrations WHERE filename = ?", "revert"
+	}
+
+	data, err := migrationsFS.ReadFile("migrations/" + file)
+	if err != nil {
+		return fmt.Errorf("read migration %s: %w", file, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s migration %s: %w", verb, m.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, string(data)); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%s migration %s: %w", verb, m.Name, err)
+	}
+	if hook := migrationHooks[m.Name]; hook != nil {
+		if err := hook(ctx, tx, s, down); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("%s migration %s: %w", verb, m.Name, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, record, m.Name); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("record migration %s: %w", m.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s migration %s: %w", verb, m.Name, err)
+	}
+	return nil
+}
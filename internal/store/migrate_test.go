@@ -0,0 +1,147 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := embeddedMigrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.Version, "migration versions are contiguous: %s", m.Name)
+	}
+	assert.Equal(t, migrations[len(migrations)-1].Version, LatestSchemaVersion())
+
+	// Every down migration reverts an up migration.
+	entries, err := migrationsFS.ReadDir("migrations")
+	require.NoError(t, err)
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), downSuffix) {
+			continue
+		}
+		up := strings.TrimSuffix(e.Name(), downSuffix) + ".sql"
+		_, err := migrationsFS.ReadFile("migrations/" + up)
+		assert.NoError(t, err, "down migration %s has no up migration", e.Name())
+	}
+}
+
+func TestMigrate_DownAndUp(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	latest := LatestSchemaVersion()
+
+	v, err := s.SchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, latest, v)
+
+	p := &models.Project{Name: "keep", Path: "/tmp/keep"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	require.NoError(t, s.CreateProjectNote(ctx, &models.ProjectNote{ProjectID: p.ID, Title: "Runbook"}))
+
+	plan, err := s.PlanMigration(ctx, latest)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Steps, "nothing pending")
+
+	// Down to version 6, the oldest that every later migration can revert to.
+	plan, err = s.PlanMigration(ctx, 6)
+	require.NoError(t, err)
+	assert.True(t, plan.Down)
+	require.Len(t, plan.Steps, latest-6)
+	assert.Equal(t, latest, plan.Steps[0].Version, "reverted newest first")
+
+	_, err = s.ApplyMigration(ctx, plan, false)
+	require.NoError(t, err)
+	v, err = s.SchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 6, v)
+
+	var name string
+	require.NoError(t, s.db.QueryRowContext(ctx, "SELECT name FROM projects WHERE id = ?", p.ID).Scan(&name))
+	assert.Equal(t, "keep", name, "rows of surviving tables are kept")
+
+	_, err = s.PlanMigration(ctx, 5)
+	assert.ErrorContains(t, err, "006_rename_session_statuses.sql is irreversible")
+
+	// And back up again.
+	require.NoError(t, s.Migrate(ctx))
+	v, err = s.SchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, latest, v)
+	notes, err := s.ListProjectNotes(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Empty(t, notes, "reverted tables come back empty")
+}
+
+func TestMigrate_PlanTo(t *testing.T) {
+	s, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+
+	plan, err := s.PlanMigration(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 0, plan.From)
+	require.Len(t, plan.Steps, 3)
+
+	v, err := s.SchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, v, "planning changes nothing")
+
+	_, err = s.PlanMigration(ctx, LatestSchemaVersion()+1)
+	assert.ErrorContains(t, err, "unknown schema version")
+}
+
+func TestMigrate_BackupBeforeApplying(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "pm.db")
+	s, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+
+	plan, err := s.PlanMigration(ctx, LatestSchemaVersion()-1)
+	require.NoError(t, err)
+	backup, err := s.ApplyMigration(ctx, plan, true)
+	require.NoError(t, err)
+	assert.Empty(t, backup, "a new database is not backed up")
+
+	plan, err = s.PlanMigration(ctx, LatestSchemaVersion())
+	require.NoError(t, err)
+	backup, err = s.ApplyMigration(ctx, plan, true)
+	require.NoError(t, err)
+	require.NotEmpty(t, backup)
+	assert.True(t, strings.HasPrefix(filepath.Base(backup), "pm.db.v"))
+
+	old, err := NewSQLiteStore(backup)
+	require.NoError(t, err)
+	defer func() { _ = old.Close() }()
+	v, err := old.SchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, LatestSchemaVersion()-1, v, "backup holds the schema before migrating")
+}
+
+func TestMigrate_SchemaTooNew(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	_, err := s.db.ExecContext(ctx, "INSERT INTO schema_migrations (filename) VALUES ('999_from_the_future.sql')")
+	require.NoError(t, err)
+
+	err = s.Migrate(ctx)
+	var tooNew *SchemaVersionError
+	require.ErrorAs(t, err, &tooNew)
+	assert.Equal(t, 999, tooNew.Database)
+	assert.Equal(t, LatestSchemaVersion(), tooNew.Binary)
+
+	v, err := s.SchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 999, v)
+}
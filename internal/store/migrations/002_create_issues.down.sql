@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_issues_status;
+DROP INDEX IF EXISTS idx_issues_project_id;
+DROP TABLE IF EXISTS issues;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_agent_sessions_project_id;
+DROP TABLE IF EXISTS agent_sessions;
@@ -0,0 +1,3 @@
+ALTER TABLE agent_sessions DROP COLUMN last_active_at;
+ALTER TABLE agent_sessions DROP COLUMN last_commit_message;
+ALTER TABLE agent_sessions DROP COLUMN last_commit_hash;
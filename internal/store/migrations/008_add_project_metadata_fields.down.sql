@@ -0,0 +1,3 @@
+ALTER TABLE projects DROP COLUMN pages_url;
+ALTER TABLE projects DROP COLUMN has_github_pages;
+ALTER TABLE projects DROP COLUMN branch_count;
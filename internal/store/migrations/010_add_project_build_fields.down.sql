@@ -0,0 +1,3 @@
+ALTER TABLE projects DROP COLUMN serve_port;
+ALTER TABLE projects DROP COLUMN serve_cmd;
+ALTER TABLE projects DROP COLUMN build_cmd;
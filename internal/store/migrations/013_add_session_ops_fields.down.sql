@@ -0,0 +1,5 @@
+ALTER TABLE agent_sessions DROP COLUMN discovered;
+ALTER TABLE agent_sessions DROP COLUMN conflict_files;
+ALTER TABLE agent_sessions DROP COLUMN conflict_state;
+ALTER TABLE agent_sessions DROP COLUMN last_sync_at;
+ALTER TABLE agent_sessions DROP COLUMN last_error;
@@ -0,0 +1,2 @@
+-- Sessions abandoned as duplicates stay abandoned; only the index is removed.
+DROP INDEX IF EXISTS idx_agent_sessions_active_branch;
@@ -0,0 +1,2 @@
+ALTER TABLE agent_sessions DROP COLUMN review_id;
+ALTER TABLE agent_sessions DROP COLUMN review_attempt;
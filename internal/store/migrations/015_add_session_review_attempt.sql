@@ -0,0 +1,3 @@
+-- Track review round-trips: follow-up sessions launched after a failed review
+ALTER TABLE agent_sessions ADD COLUMN review_attempt INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE agent_sessions ADD COLUMN review_id TEXT NOT NULL DEFAULT '';
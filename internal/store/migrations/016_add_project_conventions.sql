@@ -0,0 +1 @@
+ALTER TABLE projects ADD COLUMN conventions TEXT DEFAULT '';
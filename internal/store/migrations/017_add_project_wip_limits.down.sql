@@ -0,0 +1,3 @@
+ALTER TABLE projects DROP COLUMN wip_hard_block;
+ALTER TABLE projects DROP COLUMN max_active_sessions;
+ALTER TABLE projects DROP COLUMN max_in_progress;
@@ -0,0 +1,4 @@
+-- Per-project work-in-progress limits (0 = no limit)
+ALTER TABLE projects ADD COLUMN max_in_progress INTEGER DEFAULT 0;
+ALTER TABLE projects ADD COLUMN max_active_sessions INTEGER DEFAULT 0;
+ALTER TABLE projects ADD COLUMN wip_hard_block INTEGER DEFAULT 0;
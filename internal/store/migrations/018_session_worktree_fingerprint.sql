@@ -0,0 +1,2 @@
+-- Content fingerprint of a session's worktree, recorded when it goes idle or closes
+ALTER TABLE agent_sessions ADD COLUMN worktree_fingerprint TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_issue_relations_target;
+DROP INDEX IF EXISTS idx_issue_relations_source;
+DROP TABLE IF EXISTS issue_relations;
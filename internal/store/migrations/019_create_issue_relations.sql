@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS issue_relations (
+    id TEXT PRIMARY KEY,
+    source_issue_id TEXT NOT NULL REFERENCES issues(id) ON DELETE CASCADE,
+    target_issue_id TEXT NOT NULL REFERENCES issues(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE(source_issue_id, target_issue_id, type)
+);
+CREATE INDEX IF NOT EXISTS idx_issue_relations_source ON issue_relations(source_issue_id);
+CREATE INDEX IF NOT EXISTS idx_issue_relations_target ON issue_relations(target_issue_id);
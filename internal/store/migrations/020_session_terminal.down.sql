@@ -0,0 +1,2 @@
+ALTER TABLE agent_sessions DROP COLUMN terminal_id;
+ALTER TABLE agent_sessions DROP COLUMN terminal_backend;
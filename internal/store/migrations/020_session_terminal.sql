@@ -0,0 +1,3 @@
+-- Terminal window/session hosting an agent session, when pm spawned it
+ALTER TABLE agent_sessions ADD COLUMN terminal_backend TEXT NOT NULL DEFAULT '';
+ALTER TABLE agent_sessions ADD COLUMN terminal_id TEXT NOT NULL DEFAULT '';
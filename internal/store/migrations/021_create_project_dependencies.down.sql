@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_project_dependencies_depends_on;
+DROP TABLE IF EXISTS project_dependencies;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS project_dependencies (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    depends_on_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL,
+    UNIQUE(project_id, depends_on_id)
+);
+CREATE INDEX IF NOT EXISTS idx_project_dependencies_depends_on ON project_dependencies(depends_on_id);
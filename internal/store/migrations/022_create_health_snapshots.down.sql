@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_health_snapshots_project;
+DROP TABLE IF EXISTS health_snapshots;
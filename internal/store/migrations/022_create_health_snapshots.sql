@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS health_snapshots (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    total INTEGER NOT NULL DEFAULT 0,
+    git_cleanliness INTEGER NOT NULL DEFAULT 0,
+    activity_recency INTEGER NOT NULL DEFAULT 0,
+    issue_health INTEGER NOT NULL DEFAULT 0,
+    release_freshness INTEGER NOT NULL DEFAULT 0,
+    branch_hygiene INTEGER NOT NULL DEFAULT 0,
+    is_dirty INTEGER NOT NULL DEFAULT 0,
+    branch_count INTEGER NOT NULL DEFAULT 0,
+    worktree_count INTEGER NOT NULL DEFAULT 0,
+    latest_release TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_health_snapshots_project ON health_snapshots(project_id, created_at);
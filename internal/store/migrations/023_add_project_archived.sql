@@ -0,0 +1,2 @@
+-- Archived projects are hidden from lists, status and refresh-all
+ALTER TABLE projects ADD COLUMN archived INTEGER DEFAULT 0;
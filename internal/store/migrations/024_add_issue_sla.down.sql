@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_sla_breaches_breached_at;
+DROP TABLE IF EXISTS sla_breaches;
+ALTER TABLE issues DROP COLUMN sla_state;
@@ -0,0 +1,13 @@
+-- SLA policy evaluation: current state per issue and one row per missed deadline
+ALTER TABLE issues ADD COLUMN sla_state TEXT DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS sla_breaches (
+    id TEXT PRIMARY KEY,
+    issue_id TEXT NOT NULL REFERENCES issues(id) ON DELETE CASCADE,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    policy TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    breached_at DATETIME NOT NULL,
+    UNIQUE(issue_id, policy, kind)
+);
+CREATE INDEX IF NOT EXISTS idx_sla_breaches_breached_at ON sla_breaches(breached_at);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS issue_status_update;
+DROP TRIGGER IF EXISTS issue_status_insert;
+DROP INDEX IF EXISTS idx_issue_status_events_project;
+DROP TABLE IF EXISTS issue_status_events;
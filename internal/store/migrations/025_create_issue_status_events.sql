@@ -0,0 +1,31 @@
+-- Issue status history for burndown and cumulative-flow reports. Triggers
+-- record every status an issue enters, whichever code path writes it.
+CREATE TABLE IF NOT EXISTS issue_status_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    issue_id TEXT NOT NULL REFERENCES issues(id) ON DELETE CASCADE,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    from_status TEXT NOT NULL DEFAULT '',
+    to_status TEXT NOT NULL,
+    changed_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_issue_status_events_project ON issue_status_events(project_id, changed_at);
+
+CREATE TRIGGER IF NOT EXISTS issue_status_insert AFTER INSERT ON issues
+BEGIN
+    INSERT INTO issue_status_events (issue_id, project_id, from_status, to_status, changed_at)
+    VALUES (NEW.id, NEW.project_id, '', NEW.status, NEW.created_at);
+END;
+
+CREATE TRIGGER IF NOT EXISTS issue_status_update AFTER UPDATE OF status ON issues
+WHEN OLD.status <> NEW.status
+BEGIN
+    INSERT INTO issue_status_events (issue_id, project_id, from_status, to_status, changed_at)
+    VALUES (NEW.id, NEW.project_id, OLD.status, NEW.status, NEW.updated_at);
+END;
+
+-- Backfill: every existing issue was created open and, if it has moved on,
+-- reached its current status when it was last updated.
+INSERT INTO issue_status_events (issue_id, project_id, from_status, to_status, changed_at)
+SELECT id, project_id, '', 'open', created_at FROM issues;
+INSERT INTO issue_status_events (issue_id, project_id, from_status, to_status, changed_at)
+SELECT id, project_id, 'open', status, COALESCE(closed_at, updated_at) FROM issues WHERE status <> 'open';
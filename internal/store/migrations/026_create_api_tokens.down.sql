@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_api_tokens_project;
+DROP TABLE IF EXISTS api_tokens;
@@ -0,0 +1,10 @@
+-- Project-scoped bearer tokens for sandboxed agents. Only a SHA-256 hash of
+-- each token is kept; the token itself is shown once when created.
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_api_tokens_project ON api_tokens(project_id);
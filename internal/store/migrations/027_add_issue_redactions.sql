@@ -0,0 +1,3 @@
+-- What the redaction pass removed from an issue's text before its last LLM
+-- enrichment, as a JSON array of {Rule, Count}.
+ALTER TABLE issues ADD COLUMN redactions TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,11 @@
+-- Blocked sessions go back to idle, the closest status that still exists.
+UPDATE agent_sessions SET status = 'idle' WHERE status = 'blocked';
+
+DROP INDEX IF EXISTS idx_agent_sessions_active_branch;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_agent_sessions_active_branch
+ON agent_sessions(project_id, branch)
+WHERE status IN ('active', 'idle');
+
+ALTER TABLE agent_sessions DROP COLUMN blocked_at;
+ALTER TABLE agent_sessions DROP COLUMN unblock_condition;
+ALTER TABLE agent_sessions DROP COLUMN blocked_reason;
@@ -0,0 +1,11 @@
+-- Blocked sessions: waiting on something outside the session (a design, an
+-- API key, an answer), with the reason and what would unblock it.
+ALTER TABLE agent_sessions ADD COLUMN blocked_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE agent_sessions ADD COLUMN unblock_condition TEXT NOT NULL DEFAULT '';
+ALTER TABLE agent_sessions ADD COLUMN blocked_at DATETIME;
+
+-- A blocked session still owns its branch.
+DROP INDEX IF EXISTS idx_agent_sessions_active_branch;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_agent_sessions_active_branch
+ON agent_sessions(project_id, branch)
+WHERE status IN ('active', 'idle', 'blocked');
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS issue_timestamps_update;
+DROP TRIGGER IF EXISTS issue_timestamps_insert;
+ALTER TABLE issues DROP COLUMN completed_at;
+ALTER TABLE issues DROP COLUMN started_at;
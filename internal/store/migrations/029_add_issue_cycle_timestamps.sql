@@ -0,0 +1,38 @@
+-- When an issue first went in progress and when it was completed, for
+-- cycle-time metrics. Triggers keep them current whichever code path changes
+-- the status (API, MCP, CLI, session cascades).
+ALTER TABLE issues ADD COLUMN started_at DATETIME;
+ALTER TABLE issues ADD COLUMN completed_at DATETIME;
+
+CREATE TRIGGER IF NOT EXISTS issue_timestamps_insert AFTER INSERT ON issues
+WHEN NEW.status <> 'open'
+BEGIN
+    UPDATE issues SET
+        started_at = CASE WHEN NEW.status = 'in_progress' THEN NEW.created_at END,
+        completed_at = CASE WHEN NEW.status IN ('done', 'closed') THEN NEW.created_at END
+    WHERE id = NEW.id;
+END;
+
+-- started_at is set once, on the first move to in_progress. completed_at is
+-- set on reaching done or closed (done -> closed keeps the first completion)
+-- and cleared when the issue is reopened.
+CREATE TRIGGER IF NOT EXISTS issue_timestamps_update AFTER UPDATE OF status ON issues
+WHEN OLD.status <> NEW.status
+BEGIN
+    UPDATE issues SET
+        started_at = CASE WHEN NEW.status = 'in_progress' AND started_at IS NULL THEN NEW.updated_at ELSE started_at END,
+        completed_at = CASE WHEN NEW.status IN ('done', 'closed') THEN COALESCE(completed_at, NEW.updated_at) END
+    WHERE id = NEW.id;
+END;
+
+-- Backfill from the recorded status history.
+UPDATE issues SET started_at = (
+    SELECT MIN(changed_at) FROM issue_status_events e
+    WHERE e.issue_id = issues.id AND e.to_status = 'in_progress'
+);
+UPDATE issues SET completed_at = (
+    SELECT MAX(changed_at) FROM issue_status_events e
+    WHERE e.issue_id = issues.id AND e.to_status IN ('done', 'closed')
+      AND e.from_status NOT IN ('done', 'closed')
+)
+WHERE status IN ('done', 'closed');
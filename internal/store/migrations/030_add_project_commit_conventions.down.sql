@@ -0,0 +1,2 @@
+ALTER TABLE projects DROP COLUMN commit_ticket_prefix;
+ALTER TABLE projects DROP COLUMN commit_style;
@@ -0,0 +1,4 @@
+-- Per-project commit message conventions checked by close-check and merge
+-- ('' = no check)
+ALTER TABLE projects ADD COLUMN commit_style TEXT DEFAULT '';
+ALTER TABLE projects ADD COLUMN commit_ticket_prefix TEXT DEFAULT '';
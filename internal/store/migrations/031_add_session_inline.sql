@@ -0,0 +1,3 @@
+-- Inline sessions work directly in the project repo instead of a worktree;
+-- their worktree_path is the repo path and worktree reconciliation skips them.
+ALTER TABLE agent_sessions ADD COLUMN inline INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_project_notes_project;
+DROP TABLE IF EXISTS project_notes;
@@ -0,0 +1,12 @@
+-- Project notes: free-form Markdown pages (ADRs, runbooks) forming a small
+-- per-project wiki that agents can read over MCP.
+CREATE TABLE IF NOT EXISTS project_notes (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    title TEXT NOT NULL COLLATE NOCASE,
+    body TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE(project_id, title)
+);
+CREATE INDEX IF NOT EXISTS idx_project_notes_project ON project_notes(project_id);
@@ -0,0 +1,3 @@
+-- Who is working on an issue: a person or an agent name. Empty means
+-- unassigned, which any agent may pull.
+ALTER TABLE issues ADD COLUMN assignee TEXT NOT NULL DEFAULT '';
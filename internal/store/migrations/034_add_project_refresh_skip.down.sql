@@ -0,0 +1 @@
+ALTER TABLE projects DROP COLUMN refresh_skip;
@@ -0,0 +1,2 @@
+-- Refresh pipeline steps a project opts out of, comma-separated step names.
+ALTER TABLE projects ADD COLUMN refresh_skip TEXT NOT NULL DEFAULT '';
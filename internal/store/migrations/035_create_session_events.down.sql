@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS session_event_error;
+DROP TRIGGER IF EXISTS session_event_conflict;
+DROP TRIGGER IF EXISTS session_event_sync;
+DROP TRIGGER IF EXISTS session_event_status;
+DROP TRIGGER IF EXISTS session_event_insert;
+DROP INDEX IF EXISTS idx_session_events_session;
+DROP TABLE IF EXISTS session_events;
@@ -0,0 +1,71 @@
+-- Agent session history for replays. Triggers record a session starting,
+-- every status it enters, every sync attempt, and the conflicts and errors of
+-- merges, whichever code path writes them. Sessions have no updated_at, so
+-- changes without a timestamp of their own are stamped with the current time.
+CREATE TABLE IF NOT EXISTS session_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL REFERENCES agent_sessions(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    value TEXT NOT NULL DEFAULT '',
+    detail TEXT NOT NULL DEFAULT '',
+    at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_session_events_session ON session_events(session_id, id);
+
+CREATE TRIGGER IF NOT EXISTS session_event_insert AFTER INSERT ON agent_sessions
+BEGIN
+    INSERT INTO session_events (session_id, kind, value, detail, at)
+    VALUES (NEW.id, 'started', NEW.status, NEW.branch, NEW.started_at);
+END;
+
+CREATE TRIGGER IF NOT EXISTS session_event_status AFTER UPDATE OF status ON agent_sessions
+WHEN OLD.status <> NEW.status
+BEGIN
+    INSERT INTO session_events (session_id, kind, value, detail, at)
+    VALUES (NEW.id, 'status', NEW.status,
+        CASE NEW.status
+            WHEN 'blocked' THEN NEW.blocked_reason
+            WHEN 'completed' THEN NEW.outcome
+            WHEN 'abandoned' THEN NEW.outcome
+            ELSE '' END,
+        CASE
+            WHEN NEW.status = 'blocked' AND NEW.blocked_at IS NOT NULL THEN NEW.blocked_at
+            WHEN NEW.ended_at IS NOT NULL AND OLD.ended_at IS NULL THEN NEW.ended_at
+            ELSE strftime('%Y-%m-%d %H:%M:%f', 'now') END);
+END;
+
+CREATE TRIGGER IF NOT EXISTS session_event_sync AFTER UPDATE OF last_sync_at ON agent_sessions
+WHEN NEW.last_sync_at IS NOT NULL AND OLD.last_sync_at IS NOT NEW.last_sync_at
+BEGIN
+    INSERT INTO session_events (session_id, kind, value, detail, at)
+    VALUES (NEW.id, 'sync', NEW.conflict_state, NEW.last_error, NEW.last_sync_at);
+END;
+
+-- Conflict and error changes that are part of a sync are in its sync event.
+CREATE TRIGGER IF NOT EXISTS session_event_conflict AFTER UPDATE OF conflict_state ON agent_sessions
+WHEN OLD.conflict_state <> NEW.conflict_state AND OLD.last_sync_at IS NEW.last_sync_at
+BEGIN
+    INSERT INTO session_events (session_id, kind, value, detail, at)
+    VALUES (NEW.id, 'conflict', NEW.conflict_state,
+        CASE WHEN NEW.conflict_state = 'none' THEN '' ELSE NEW.conflict_files END,
+        strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS session_event_error AFTER UPDATE OF last_error ON agent_sessions
+WHEN NEW.last_error <> '' AND OLD.last_error <> NEW.last_error
+    AND OLD.last_sync_at IS NEW.last_sync_at AND OLD.conflict_state = NEW.conflict_state
+BEGIN
+    INSERT INTO session_events (session_id, kind, value, detail, at)
+    VALUES (NEW.id, 'error', '', NEW.last_error, strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+-- Backfill: every existing session started, and ended, was blocked or last
+-- synced at the times it records.
+INSERT INTO session_events (session_id, kind, value, detail, at)
+SELECT id, 'started', 'active', branch, started_at FROM agent_sessions;
+INSERT INTO session_events (session_id, kind, value, detail, at)
+SELECT id, 'sync', conflict_state, last_error, last_sync_at FROM agent_sessions WHERE last_sync_at IS NOT NULL;
+INSERT INTO session_events (session_id, kind, value, detail, at)
+SELECT id, 'status', 'blocked', blocked_reason, blocked_at FROM agent_sessions WHERE status = 'blocked' AND blocked_at IS NOT NULL;
+INSERT INTO session_events (session_id, kind, value, detail, at)
+SELECT id, 'status', status, outcome, ended_at FROM agent_sessions WHERE status IN ('completed', 'abandoned') AND ended_at IS NOT NULL;
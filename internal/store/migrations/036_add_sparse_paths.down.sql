@@ -0,0 +1,2 @@
+ALTER TABLE agent_sessions DROP COLUMN sparse_paths;
+ALTER TABLE projects DROP COLUMN sparse_paths;
@@ -0,0 +1,5 @@
+-- Sparse-checkout worktrees for large repos: the paths a project's worktrees
+-- always check out, and the paths a session's worktree was created with.
+-- Both are comma-separated; empty means a full checkout.
+ALTER TABLE projects ADD COLUMN sparse_paths TEXT NOT NULL DEFAULT '';
+ALTER TABLE agent_sessions ADD COLUMN sparse_paths TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE agent_sessions DROP COLUMN merge_commit;
+ALTER TABLE projects DROP COLUMN merge_strategy;
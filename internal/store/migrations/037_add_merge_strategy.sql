@@ -0,0 +1,5 @@
+-- Merge strategies: a project's default strategy for merging session
+-- branches ('' = merge), and the commit a session's merge produced on the
+-- base branch.
+ALTER TABLE projects ADD COLUMN merge_strategy TEXT NOT NULL DEFAULT '';
+ALTER TABLE agent_sessions ADD COLUMN merge_commit TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- A free-form size estimate for an issue (e.g. "2h", "3pt", "M"). Empty
+-- means not estimated, which counts against the issue's readiness.
+ALTER TABLE issues ADD COLUMN estimate TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- Ad-hoc session labels ("experiment", "hotfix"), comma-separated, for
+-- grouping and comparing sessions independently of their issues.
+ALTER TABLE agent_sessions ADD COLUMN labels TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_issue_closures_project;
+DROP TABLE IF EXISTS issue_closures;
@@ -0,0 +1,19 @@
+-- Issues closed by "Fixes #123" / "Closes PM-<id>" references in session
+-- commits and merged pull requests. Reverting a closure keeps its row, so the
+-- same reference never closes the issue again.
+CREATE TABLE IF NOT EXISTS issue_closures (
+    id TEXT PRIMARY KEY,
+    issue_id TEXT NOT NULL REFERENCES issues(id) ON DELETE CASCADE,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    session_id TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL,
+    ref TEXT NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    github_issue INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    reverted_at DATETIME,
+    UNIQUE(issue_id, source, ref)
+);
+CREATE INDEX IF NOT EXISTS idx_issue_closures_project ON issue_closures(project_id, created_at);
@@ -0,0 +1,3 @@
+-- Per-language share of a project's source lines, as JSON, computed by the
+-- refresh language step. Language keeps the primary language.
+ALTER TABLE projects ADD COLUMN languages TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,19 @@
+DROP TRIGGER IF EXISTS session_event_status;
+CREATE TRIGGER IF NOT EXISTS session_event_status AFTER UPDATE OF status ON agent_sessions
+WHEN OLD.status <> NEW.status
+BEGIN
+    INSERT INTO session_events (session_id, kind, value, detail, at)
+    VALUES (NEW.id, 'status', NEW.status,
+        CASE NEW.status
+            WHEN 'blocked' THEN NEW.blocked_reason
+            WHEN 'completed' THEN NEW.outcome
+            WHEN 'abandoned' THEN NEW.outcome
+            ELSE '' END,
+        CASE
+            WHEN NEW.status = 'blocked' AND NEW.blocked_at IS NOT NULL THEN NEW.blocked_at
+            WHEN NEW.ended_at IS NOT NULL AND OLD.ended_at IS NULL THEN NEW.ended_at
+            ELSE strftime('%Y-%m-%d %H:%M:%f', 'now') END);
+END;
+
+ALTER TABLE session_events DROP COLUMN cause;
+ALTER TABLE session_events DROP COLUMN from_value;
@@ -0,0 +1,31 @@
+-- Status events record the status a session left and what moved it (launch,
+-- close, reconcile, ...). from_value comes from the trigger; cause is set by
+-- guarded transitions and stays empty for plain updates.
+ALTER TABLE session_events ADD COLUMN from_value TEXT NOT NULL DEFAULT '';
+ALTER TABLE session_events ADD COLUMN cause TEXT NOT NULL DEFAULT '';
+
+DROP TRIGGER IF EXISTS session_event_status;
+CREATE TRIGGER IF NOT EXISTS session_event_status AFTER UPDATE OF status ON agent_sessions
+WHEN OLD.status <> NEW.status
+BEGIN
+    INSERT INTO session_events (session_id, kind, value, from_value, detail, at)
+    VALUES (NEW.id, 'status', NEW.status, OLD.status,
+        CASE NEW.status
+            WHEN 'blocked' THEN NEW.blocked_reason
+            WHEN 'completed' THEN NEW.outcome
+            WHEN 'abandoned' THEN NEW.outcome
+            ELSE '' END,
+        CASE
+            WHEN NEW.status = 'blocked' AND NEW.blocked_at IS NOT NULL THEN NEW.blocked_at
+            WHEN NEW.ended_at IS NOT NULL AND OLD.ended_at IS NULL THEN NEW.ended_at
+            ELSE strftime('%Y-%m-%d %H:%M:%f', 'now') END);
+END;
+
+-- Backfill: a status event left the status of the session's previous
+-- started or status event.
+UPDATE session_events SET from_value = COALESCE((
+    SELECT prev.value FROM session_events prev
+    WHERE prev.session_id = session_events.session_id
+      AND prev.kind IN ('started', 'status') AND prev.id < session_events.id
+    ORDER BY prev.id DESC LIMIT 1), '')
+WHERE kind = 'status';
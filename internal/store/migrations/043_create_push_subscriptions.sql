@@ -0,0 +1,10 @@
+-- Web Push subscriptions of dashboard browsers. Subscribing again from the
+-- same browser replaces its keys.
+CREATE TABLE IF NOT EXISTS push_subscriptions (
+    id TEXT PRIMARY KEY,
+    endpoint TEXT NOT NULL UNIQUE,
+    p256dh TEXT NOT NULL,
+    auth TEXT NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL
+);
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS issue_enums_update;
+DROP TRIGGER IF EXISTS issue_enums_insert;
@@ -0,0 +1,26 @@
+-- Issue status, priority and type must be one of the known values. SQLite
+-- cannot add a CHECK constraint to an existing table, so triggers enforce it.
+-- Existing values are normalized first ("In-Progress" becomes in_progress),
+-- and those still unknown are reset to the defaults.
+UPDATE issues SET status = lower(trim(replace(status, '-', '_'))) WHERE status != lower(trim(replace(status, '-', '_')));
+UPDATE issues SET priority = lower(trim(replace(priority, '-', '_'))) WHERE priority != lower(trim(replace(priority, '-', '_')));
+UPDATE issues SET type = lower(trim(replace(type, '-', '_'))) WHERE type != lower(trim(replace(type, '-', '_')));
+UPDATE issues SET status = 'open' WHERE status NOT IN ('open', 'in_progress', 'done', 'closed');
+UPDATE issues SET priority = 'medium' WHERE priority NOT IN ('low', 'medium', 'high');
+UPDATE issues SET type = 'feature' WHERE type NOT IN ('feature', 'bug', 'chore');
+
+CREATE TRIGGER IF NOT EXISTS issue_enums_insert BEFORE INSERT ON issues
+WHEN NEW.status NOT IN ('open', 'in_progress', 'done', 'closed')
+  OR NEW.priority NOT IN ('low', 'medium', 'high')
+  OR NEW.type NOT IN ('feature', 'bug', 'chore')
+BEGIN
+    SELECT RAISE(ABORT, 'invalid issue status, priority or type');
+END;
+
+CREATE TRIGGER IF NOT EXISTS issue_enums_update BEFORE UPDATE OF status, priority, type ON issues
+WHEN NEW.status NOT IN ('open', 'in_progress', 'done', 'closed')
+  OR NEW.priority NOT IN ('low', 'medium', 'high')
+  OR NEW.type NOT IN ('feature', 'bug', 'chore')
+BEGIN
+    SELECT RAISE(ABORT, 'invalid issue status, priority or type');
+END;
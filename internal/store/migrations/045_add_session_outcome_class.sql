@@ -0,0 +1,17 @@
+-- How each ended session went (clean_success, success_after_conflicts,
+-- abandoned_no_commits, abandoned, review_failed), for outcome reports.
+-- Existing sessions are classified from their status, commits, conflict
+-- history and failed reviews.
+ALTER TABLE agent_sessions ADD COLUMN outcome_class TEXT NOT NULL DEFAULT '';
+
+UPDATE agent_sessions SET outcome_class = CASE
+    WHEN id IN (SELECT session_id FROM issue_reviews WHERE verdict = 'fail') THEN 'review_failed'
+    WHEN status = 'completed' AND (conflict_state != 'none' OR EXISTS (
+        SELECT 1 FROM session_events e
+        WHERE e.session_id = agent_sessions.id AND e.kind = 'conflict' AND e.value != 'none'
+    )) THEN 'success_after_conflicts'
+    WHEN status = 'completed' THEN 'clean_success'
+    WHEN status = 'abandoned' AND commit_count = 0 THEN 'abandoned_no_commits'
+    WHEN status = 'abandoned' THEN 'abandoned'
+    ELSE ''
+END;
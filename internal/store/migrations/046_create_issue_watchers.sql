@@ -0,0 +1,19 @@
+-- Watchers notified about an issue's status changes, comments and review
+-- verdicts, and the comments themselves.
+CREATE TABLE IF NOT EXISTS issue_watchers (
+    id TEXT PRIMARY KEY,
+    issue_id TEXT NOT NULL REFERENCES issues(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('email', 'webhook')),
+    target TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE (issue_id, target)
+);
+
+CREATE TABLE IF NOT EXISTS issue_comments (
+    id TEXT PRIMARY KEY,
+    issue_id TEXT NOT NULL REFERENCES issues(id) ON DELETE CASCADE,
+    author TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_issue_comments_issue ON issue_comments(issue_id, created_at);
@@ -0,0 +1,24 @@
+DROP TRIGGER IF EXISTS changes_note_delete;
+DROP TRIGGER IF EXISTS changes_note_update;
+DROP TRIGGER IF EXISTS changes_note_insert;
+DROP TRIGGER IF EXISTS changes_comment_delete;
+DROP TRIGGER IF EXISTS changes_comment_update;
+DROP TRIGGER IF EXISTS changes_comment_insert;
+DROP TRIGGER IF EXISTS changes_relation_delete;
+DROP TRIGGER IF EXISTS changes_relation_update;
+DROP TRIGGER IF EXISTS changes_relation_insert;
+DROP TRIGGER IF EXISTS changes_review_delete;
+DROP TRIGGER IF EXISTS changes_review_update;
+DROP TRIGGER IF EXISTS changes_review_insert;
+DROP TRIGGER IF EXISTS changes_session_delete;
+DROP TRIGGER IF EXISTS changes_session_update;
+DROP TRIGGER IF EXISTS changes_session_insert;
+DROP TRIGGER IF EXISTS changes_issue_delete;
+DROP TRIGGER IF EXISTS changes_issue_update;
+DROP TRIGGER IF EXISTS changes_issue_insert;
+DROP TRIGGER IF EXISTS changes_project_delete;
+DROP TRIGGER IF EXISTS changes_project_update;
+DROP TRIGGER IF EXISTS changes_project_insert;
+DROP INDEX IF EXISTS idx_changes_changed_at;
+DROP INDEX IF EXISTS idx_changes_project;
+DROP TABLE IF EXISTS changes;
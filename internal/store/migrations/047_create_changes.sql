@@ -0,0 +1,144 @@
+-- Change feed: triggers note every insert, update and delete of the records
+-- clients follow, so they can poll for what changed since a cursor instead of
+-- diffing full lists. Rows carry no record contents. Records that have no
+-- project_id column of their own take their issue's, which is gone already
+-- when the delete cascades from the issue.
+CREATE TABLE IF NOT EXISTS changes (
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    entity TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    project_id TEXT NOT NULL DEFAULT '',
+    op TEXT NOT NULL CHECK (op IN ('insert', 'update', 'delete')),
+    changed_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_changes_project ON changes(project_id, seq);
+CREATE INDEX IF NOT EXISTS idx_changes_changed_at ON changes(changed_at);
+
+CREATE TRIGGER IF NOT EXISTS changes_project_insert AFTER INSERT ON projects
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('project', NEW.id, NEW.id, 'insert', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_project_update AFTER UPDATE ON projects
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('project', NEW.id, NEW.id, 'update', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_project_delete AFTER DELETE ON projects
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('project', OLD.id, OLD.id, 'delete', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_issue_insert AFTER INSERT ON issues
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('issue', NEW.id, NEW.project_id, 'insert', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+-- The cycle timestamp triggers update issues again; only updates that touch
+-- updated_at or sla_state are changes of their own.
+CREATE TRIGGER IF NOT EXISTS changes_issue_update AFTER UPDATE ON issues
+WHEN OLD.updated_at IS NOT NEW.updated_at OR OLD.sla_state IS NOT NEW.sla_state
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('issue', NEW.id, NEW.project_id, 'update', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_issue_delete AFTER DELETE ON issues
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('issue', OLD.id, OLD.project_id, 'delete', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_session_insert AFTER INSERT ON agent_sessions
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('session', NEW.id, NEW.project_id, 'insert', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_session_update AFTER UPDATE ON agent_sessions
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('session', NEW.id, NEW.project_id, 'update', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_session_delete AFTER DELETE ON agent_sessions
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('session', OLD.id, OLD.project_id, 'delete', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_review_insert AFTER INSERT ON issue_reviews
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('review', NEW.id, COALESCE((SELECT project_id FROM issues WHERE id = NEW.issue_id), ''), 'insert', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_review_update AFTER UPDATE ON issue_reviews
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('review', NEW.id, COALESCE((SELECT project_id FROM issues WHERE id = NEW.issue_id), ''), 'update', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_review_delete AFTER DELETE ON issue_reviews
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('review', OLD.id, COALESCE((SELECT project_id FROM issues WHERE id = OLD.issue_id), ''), 'delete', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_relation_insert AFTER INSERT ON issue_relations
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('relation', NEW.id, COALESCE((SELECT project_id FROM issues WHERE id = NEW.source_issue_id), ''), 'insert', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_relation_update AFTER UPDATE ON issue_relations
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('relation', NEW.id, COALESCE((SELECT project_id FROM issues WHERE id = NEW.source_issue_id), ''), 'update', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_relation_delete AFTER DELETE ON issue_relations
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('relation', OLD.id, COALESCE((SELECT project_id FROM issues WHERE id = OLD.source_issue_id), ''), 'delete', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_comment_insert AFTER INSERT ON issue_comments
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('comment', NEW.id, COALESCE((SELECT project_id FROM issues WHERE id = NEW.issue_id), ''), 'insert', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_comment_update AFTER UPDATE ON issue_comments
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('comment', NEW.id, COALESCE((SELECT project_id FROM issues WHERE id = NEW.issue_id), ''), 'update', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_comment_delete AFTER DELETE ON issue_comments
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('comment', OLD.id, COALESCE((SELECT project_id FROM issues WHERE id = OLD.issue_id), ''), 'delete', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_note_insert AFTER INSERT ON project_notes
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('note', NEW.id, NEW.project_id, 'insert', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_note_update AFTER UPDATE ON project_notes
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('note', NEW.id, NEW.project_id, 'update', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_note_delete AFTER DELETE ON project_notes
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('note', OLD.id, OLD.project_id, 'delete', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
@@ -0,0 +1,2 @@
+ALTER TABLE issue_reviews DROP COLUMN criteria;
+ALTER TABLE issues DROP COLUMN acceptance_criteria;
@@ -0,0 +1,5 @@
+-- Acceptance criteria an issue's work must meet, as a JSON array of strings
+-- (empty: none listed), and the per-criterion results a review records, as a
+-- JSON array of {Criterion, Passed, Note}.
+ALTER TABLE issues ADD COLUMN acceptance_criteria TEXT NOT NULL DEFAULT '';
+ALTER TABLE issue_reviews ADD COLUMN criteria TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,3 @@
+ALTER TABLE issue_reviews DROP COLUMN reviewer;
+DROP INDEX IF EXISTS idx_review_shares_issue;
+DROP TABLE IF EXISTS review_shares;
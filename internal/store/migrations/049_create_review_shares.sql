@@ -0,0 +1,15 @@
+-- Time-limited links for reviewers without pm. Only a SHA-256 hash of each
+-- link's token is kept. review_id is set once the link's one verdict is in.
+CREATE TABLE IF NOT EXISTS review_shares (
+    id TEXT PRIMARY KEY,
+    issue_id TEXT NOT NULL REFERENCES issues(id) ON DELETE CASCADE,
+    reviewer TEXT NOT NULL DEFAULT '',
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at DATETIME NOT NULL,
+    review_id TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_review_shares_issue ON review_shares(issue_id);
+
+-- Who reviewed through a share link ('' for agent reviews).
+ALTER TABLE issue_reviews ADD COLUMN reviewer TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_pr_review_imports_project;
+DROP TABLE IF EXISTS pr_review_imports;
@@ -0,0 +1,18 @@
+-- GitHub pull request reviews attached to the issue whose session opened the
+-- pull request. A review is imported once per issue, so syncing again is
+-- safe.
+CREATE TABLE IF NOT EXISTS pr_review_imports (
+    id TEXT PRIMARY KEY,
+    issue_id TEXT NOT NULL REFERENCES issues(id) ON DELETE CASCADE,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    pr_number INTEGER NOT NULL,
+    review_id INTEGER NOT NULL,
+    reviewer TEXT NOT NULL DEFAULT '',
+    state TEXT NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    comment_id TEXT NOT NULL DEFAULT '',
+    follow_up_issue_id TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    UNIQUE(issue_id, review_id)
+);
+CREATE INDEX IF NOT EXISTS idx_pr_review_imports_project ON pr_review_imports(project_id, created_at);
@@ -0,0 +1,2 @@
+-- The migration's Go hook decompresses every large text value back to TEXT.
+SELECT 1;
@@ -0,0 +1,5 @@
+-- Large text values (issue bodies and AI prompts, review summaries) may be
+-- stored as zstd-compressed BLOBs from now on. The columns keep their TEXT
+-- affinity, which leaves BLOBs untouched; the existing values are compressed
+-- by this migration's Go hook (see migrationHooks).
+SELECT 1;
@@ -0,0 +1,23 @@
+ALTER TABLE projects DROP COLUMN cascade_rules;
+
+DROP TRIGGER IF EXISTS issue_enums_insert;
+DROP TRIGGER IF EXISTS issue_enums_update;
+
+-- Issues in review go back in progress, the closest older status.
+UPDATE issues SET status = 'in_progress' WHERE status = 'in_review';
+
+CREATE TRIGGER IF NOT EXISTS issue_enums_insert BEFORE INSERT ON issues
+WHEN NEW.status NOT IN ('open', 'in_progress', 'done', 'closed')
+  OR NEW.priority NOT IN ('low', 'medium', 'high')
+  OR NEW.type NOT IN ('feature', 'bug', 'chore')
+BEGIN
+    SELECT RAISE(ABORT, 'invalid issue status, priority or type');
+END;
+
+CREATE TRIGGER IF NOT EXISTS issue_enums_update BEFORE UPDATE OF status, priority, type ON issues
+WHEN NEW.status NOT IN ('open', 'in_progress', 'done', 'closed')
+  OR NEW.priority NOT IN ('low', 'medium', 'high')
+  OR NEW.type NOT IN ('feature', 'bug', 'chore')
+BEGIN
+    SELECT RAISE(ABORT, 'invalid issue status, priority or type');
+END;
@@ -0,0 +1,23 @@
+-- The in_review issue status, for work that is finished and waiting on a
+-- review, and a project's overrides of which status session and review
+-- events move their issue to ('' = the defaults).
+DROP TRIGGER IF EXISTS issue_enums_insert;
+DROP TRIGGER IF EXISTS issue_enums_update;
+
+CREATE TRIGGER IF NOT EXISTS issue_enums_insert BEFORE INSERT ON issues
+WHEN NEW.status NOT IN ('open', 'in_progress', 'in_review', 'done', 'closed')
+  OR NEW.priority NOT IN ('low', 'medium', 'high')
+  OR NEW.type NOT IN ('feature', 'bug', 'chore')
+BEGIN
+    SELECT RAISE(ABORT, 'invalid issue status, priority or type');
+END;
+
+CREATE TRIGGER IF NOT EXISTS issue_enums_update BEFORE UPDATE OF status, priority, type ON issues
+WHEN NEW.status NOT IN ('open', 'in_progress', 'in_review', 'done', 'closed')
+  OR NEW.priority NOT IN ('low', 'medium', 'high')
+  OR NEW.type NOT IN ('feature', 'bug', 'chore')
+BEGIN
+    SELECT RAISE(ABORT, 'invalid issue status, priority or type');
+END;
+
+ALTER TABLE projects ADD COLUMN cascade_rules TEXT NOT NULL DEFAULT '';
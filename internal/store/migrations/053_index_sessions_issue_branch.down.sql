@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_agent_sessions_branch;
+DROP INDEX IF EXISTS idx_agent_sessions_issue_id;
@@ -0,0 +1,3 @@
+-- Sessions are listed by issue ("all sessions for this issue") and by branch.
+CREATE INDEX IF NOT EXISTS idx_agent_sessions_issue_id ON agent_sessions(issue_id);
+CREATE INDEX IF NOT EXISTS idx_agent_sessions_branch ON agent_sessions(branch);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_github_board_items_project;
+DROP TABLE IF EXISTS github_board_items;
+ALTER TABLE projects DROP COLUMN github_board;
@@ -0,0 +1,16 @@
+-- The GitHub Projects (v2) board a project's issues are synced with
+-- ('' = none), as owner/number, and what each synced issue's board item last
+-- agreed on, so a sync can tell which side changed since.
+ALTER TABLE projects ADD COLUMN github_board TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS github_board_items (
+    issue_id TEXT PRIMARY KEY REFERENCES issues(id) ON DELETE CASCADE,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    item_id TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT '',
+    priority TEXT NOT NULL DEFAULT '',
+    board_status TEXT NOT NULL DEFAULT '',
+    board_priority TEXT NOT NULL DEFAULT '',
+    synced_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_github_board_items_project ON github_board_items(project_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_llm_usage_issue;
+DROP INDEX IF EXISTS idx_llm_usage_project;
+DROP TABLE IF EXISTS llm_usage;
@@ -0,0 +1,14 @@
+-- Tokens spent on LLM calls made for an issue, such as review pre-analyses,
+-- so their cost can be reported.
+CREATE TABLE IF NOT EXISTS llm_usage (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    issue_id TEXT NOT NULL DEFAULT '',
+    purpose TEXT NOT NULL,
+    model TEXT NOT NULL DEFAULT '',
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_llm_usage_project ON llm_usage(project_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_llm_usage_issue ON llm_usage(issue_id);
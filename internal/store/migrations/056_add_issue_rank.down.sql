@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS changes_issue_update;
+CREATE TRIGGER IF NOT EXISTS changes_issue_update AFTER UPDATE ON issues
+WHEN OLD.updated_at IS NOT NEW.updated_at OR OLD.sla_state IS NOT NEW.sla_state
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('issue', NEW.id, NEW.project_id, 'update', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+DROP INDEX IF EXISTS idx_issues_rank;
+ALTER TABLE issues DROP COLUMN rank;
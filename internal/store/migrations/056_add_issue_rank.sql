@@ -0,0 +1,13 @@
+-- An issue's hand-ordered position within its project's status column, as a
+-- lexorank string ('' = unranked, listed after the ranked issues). Ranking is
+-- a change of its own for the change feed, though it leaves updated_at alone.
+ALTER TABLE issues ADD COLUMN rank TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_issues_rank ON issues(project_id, status, rank);
+
+DROP TRIGGER IF EXISTS changes_issue_update;
+CREATE TRIGGER IF NOT EXISTS changes_issue_update AFTER UPDATE ON issues
+WHEN OLD.updated_at IS NOT NEW.updated_at OR OLD.sla_state IS NOT NEW.sla_state OR OLD.rank IS NOT NEW.rank
+BEGIN
+    INSERT INTO changes (entity, entity_id, project_id, op, changed_at)
+    VALUES ('issue', NEW.id, NEW.project_id, 'update', strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
@@ -0,0 +1 @@
+ALTER TABLE agent_sessions DROP COLUMN focus_seconds;
@@ -0,0 +1,4 @@
+-- Time a session spent working, accumulated from heartbeats: each heartbeat
+-- within models.FocusGap of the session's last activity adds the gap. The rest
+-- of the session's wall-clock duration is idle time.
+ALTER TABLE agent_sessions ADD COLUMN focus_seconds REAL NOT NULL DEFAULT 0;
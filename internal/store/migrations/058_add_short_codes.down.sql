@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS short_code_counters;
+DROP INDEX IF EXISTS idx_agent_sessions_code;
+DROP INDEX IF EXISTS idx_issues_code;
+DROP INDEX IF EXISTS idx_projects_key;
+ALTER TABLE agent_sessions DROP COLUMN code;
+ALTER TABLE issues DROP COLUMN code;
+ALTER TABLE projects DROP COLUMN key;
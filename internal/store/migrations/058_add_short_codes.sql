@@ -0,0 +1,20 @@
+-- Human-friendly short codes beside the ULIDs: a project's key prefixes its
+-- issues' codes ("WID-12") and sessions' codes ("WID-S3"), numbered per
+-- project by short_code_counters and never reused. Existing projects, issues
+-- and sessions are given keys and codes by the migration's Go hook, in
+-- creation order.
+ALTER TABLE projects ADD COLUMN key TEXT NOT NULL DEFAULT '';
+ALTER TABLE issues ADD COLUMN code TEXT NOT NULL DEFAULT '';
+ALTER TABLE agent_sessions ADD COLUMN code TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_projects_key ON projects(key) WHERE key != '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_issues_code ON issues(code) WHERE code != '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_agent_sessions_code ON agent_sessions(code) WHERE code != '';
+
+-- The last number handed out per project and kind ('issue' or 'session').
+CREATE TABLE IF NOT EXISTS short_code_counters (
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    last INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (project_id, kind)
+);
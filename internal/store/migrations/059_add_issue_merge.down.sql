@@ -0,0 +1,3 @@
+ALTER TABLE issues DROP COLUMN merged_at;
+ALTER TABLE issues DROP COLUMN merge_branch;
+ALTER TABLE issues DROP COLUMN merge_commit;
@@ -0,0 +1,17 @@
+-- Where an issue's code landed: the base branch tip after its last session
+-- merge, the base branch and when. Issues whose sessions were merged before
+-- get the latest merge commit and the session's end; their branch is unknown.
+ALTER TABLE issues ADD COLUMN merge_commit TEXT NOT NULL DEFAULT '';
+ALTER TABLE issues ADD COLUMN merge_branch TEXT NOT NULL DEFAULT '';
+ALTER TABLE issues ADD COLUMN merged_at DATETIME;
+
+UPDATE issues SET
+    merge_commit = s.merge_commit,
+    merged_at = s.ended_at
+FROM (
+    SELECT issue_id, merge_commit, ended_at,
+        ROW_NUMBER() OVER (PARTITION BY issue_id ORDER BY ended_at DESC, started_at DESC) AS n
+    FROM agent_sessions
+    WHERE issue_id != '' AND merge_commit != ''
+) AS s
+WHERE s.issue_id = issues.id AND s.n = 1;
@@ -0,0 +1,12 @@
+-- Each project's latest GitHub release as last fetched by the release
+-- poller, which status and health reads use instead of calling GitHub.
+-- fetched_at is the last successful fetch, checked_at the last attempt.
+CREATE TABLE IF NOT EXISTS project_releases (
+    project_id TEXT PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    tag_name TEXT NOT NULL DEFAULT '',
+    published_at DATETIME,
+    assets TEXT NOT NULL DEFAULT '',
+    fetched_at DATETIME,
+    checked_at DATETIME NOT NULL,
+    error TEXT NOT NULL DEFAULT ''
+);
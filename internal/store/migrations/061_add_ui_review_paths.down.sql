@@ -0,0 +1 @@
+ALTER TABLE projects DROP COLUMN ui_review_paths;
@@ -0,0 +1,3 @@
+-- Comma-separated glob rules for the files whose changes need a UI review
+-- ('' = defaults for the project's languages).
+ALTER TABLE projects ADD COLUMN ui_review_paths TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS agent_session_issues_issue_deleted;
+DROP TRIGGER IF EXISTS agent_session_issues_unlink;
+DROP TRIGGER IF EXISTS agent_session_issues_status;
+DROP TRIGGER IF EXISTS agent_session_issues_insert;
+DROP INDEX IF EXISTS idx_agent_session_issues_active;
+DROP TABLE IF EXISTS agent_session_issues;
@@ -0,0 +1,47 @@
+-- Issue locks: an active session holds every issue it was launched on. Each
+-- session has a row per issue, active while the session is, and the partial
+-- unique index lets only one active session hold an issue, whichever surface
+-- launched it. Triggers add the session's own issue and follow its status.
+CREATE TABLE IF NOT EXISTS agent_session_issues (
+    session_id TEXT NOT NULL REFERENCES agent_sessions(id) ON DELETE CASCADE,
+    issue_id   TEXT NOT NULL,
+    active     INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (session_id, issue_id)
+);
+
+-- Of the active sessions already sharing an issue, the newest keeps the lock.
+INSERT INTO agent_session_issues (session_id, issue_id, active)
+SELECT s.id, s.issue_id, s.status = 'active' AND NOT EXISTS (
+    SELECT 1 FROM agent_sessions o
+    WHERE o.issue_id = s.issue_id AND o.status = 'active' AND o.id > s.id)
+FROM agent_sessions s WHERE s.issue_id != '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_agent_session_issues_active
+ON agent_session_issues(issue_id)
+WHERE active = 1;
+
+CREATE TRIGGER IF NOT EXISTS agent_session_issues_insert AFTER INSERT ON agent_sessions
+WHEN NEW.issue_id != ''
+BEGIN
+    INSERT INTO agent_session_issues (session_id, issue_id, active)
+    VALUES (NEW.id, NEW.issue_id, NEW.status = 'active');
+END;
+
+CREATE TRIGGER IF NOT EXISTS agent_session_issues_status AFTER UPDATE OF status ON agent_sessions
+WHEN (OLD.status = 'active') != (NEW.status = 'active')
+BEGIN
+    UPDATE agent_session_issues SET active = (NEW.status = 'active') WHERE session_id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS agent_session_issues_unlink AFTER UPDATE OF issue_id ON agent_sessions
+WHEN OLD.issue_id != NEW.issue_id
+BEGIN
+    DELETE FROM agent_session_issues WHERE session_id = NEW.id AND issue_id = OLD.issue_id;
+    INSERT OR IGNORE INTO agent_session_issues (session_id, issue_id, active)
+    SELECT NEW.id, NEW.issue_id, NEW.status = 'active' WHERE NEW.issue_id != '';
+END;
+
+CREATE TRIGGER IF NOT EXISTS agent_session_issues_issue_deleted AFTER DELETE ON issues
+BEGIN
+    DELETE FROM agent_session_issues WHERE issue_id = OLD.id;
+END;
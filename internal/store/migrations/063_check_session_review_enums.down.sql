@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS review_enums_update;
+DROP TRIGGER IF EXISTS review_enums_insert;
+DROP TRIGGER IF EXISTS session_enums_update;
+DROP TRIGGER IF EXISTS session_enums_insert;
@@ -0,0 +1,49 @@
+-- Session status and review verdict and category ratings must be one of the
+-- known values, enforced by triggers like the issue enums. An empty category
+-- is an aspect the review did not rate. Existing values are normalized
+-- first ("Completed" becomes completed), and those still unknown are reset:
+-- sessions to abandoned, verdicts to fail and categories to unrated.
+UPDATE agent_sessions SET status = lower(trim(replace(status, '-', '_'))) WHERE status != lower(trim(replace(status, '-', '_')));
+UPDATE issue_reviews SET verdict = lower(trim(replace(verdict, '-', '_'))) WHERE verdict != lower(trim(replace(verdict, '-', '_')));
+UPDATE issue_reviews SET code_quality = lower(trim(replace(code_quality, '-', '_'))) WHERE code_quality != lower(trim(replace(code_quality, '-', '_')));
+UPDATE issue_reviews SET requirements_match = lower(trim(replace(requirements_match, '-', '_'))) WHERE requirements_match != lower(trim(replace(requirements_match, '-', '_')));
+UPDATE issue_reviews SET test_coverage = lower(trim(replace(test_coverage, '-', '_'))) WHERE test_coverage != lower(trim(replace(test_coverage, '-', '_')));
+UPDATE issue_reviews SET ui_ux = lower(trim(replace(ui_ux, '-', '_'))) WHERE ui_ux != lower(trim(replace(ui_ux, '-', '_')));
+UPDATE agent_sessions SET status = 'abandoned' WHERE status NOT IN ('active', 'idle', 'blocked', 'completed', 'abandoned');
+UPDATE issue_reviews SET verdict = 'fail' WHERE verdict NOT IN ('pass', 'fail');
+UPDATE issue_reviews SET code_quality = '' WHERE code_quality NOT IN ('', 'pass', 'fail', 'skip', 'na');
+UPDATE issue_reviews SET requirements_match = '' WHERE requirements_match NOT IN ('', 'pass', 'fail', 'skip', 'na');
+UPDATE issue_reviews SET test_coverage = '' WHERE test_coverage NOT IN ('', 'pass', 'fail', 'skip', 'na');
+UPDATE issue_reviews SET ui_ux = '' WHERE ui_ux NOT IN ('', 'pass', 'fail', 'skip', 'na');
+
+CREATE TRIGGER IF NOT EXISTS session_enums_insert BEFORE INSERT ON agent_sessions
+WHEN NEW.status NOT IN ('active', 'idle', 'blocked', 'completed', 'abandoned')
+BEGIN
+    SELECT RAISE(ABORT, 'invalid session status');
+END;
+
+CREATE TRIGGER IF NOT EXISTS session_enums_update BEFORE UPDATE OF status ON agent_sessions
+WHEN NEW.status NOT IN ('active', 'idle', 'blocked', 'completed', 'abandoned')
+BEGIN
+    SELECT RAISE(ABORT, 'invalid session status');
+END;
+
+CREATE TRIGGER IF NOT EXISTS review_enums_insert BEFORE INSERT ON issue_reviews
+WHEN NEW.verdict NOT IN ('pass', 'fail')
+  OR NEW.code_quality NOT IN ('', 'pass', 'fail', 'skip', 'na')
+  OR NEW.requirements_match NOT IN ('', 'pass', 'fail', 'skip', 'na')
+  OR NEW.test_coverage NOT IN ('', 'pass', 'fail', 'skip', 'na')
+  OR NEW.ui_ux NOT IN ('', 'pass', 'fail', 'skip', 'na')
+BEGIN
+    SELECT RAISE(ABORT, 'invalid review verdict or category');
+END;
+
+CREATE TRIGGER IF NOT EXISTS review_enums_update BEFORE UPDATE OF verdict, code_quality, requirements_match, test_coverage, ui_ux ON issue_reviews
+WHEN NEW.verdict NOT IN ('pass', 'fail')
+  OR NEW.code_quality NOT IN ('', 'pass', 'fail', 'skip', 'na')
+  OR NEW.requirements_match NOT IN ('', 'pass', 'fail', 'skip', 'na')
+  OR NEW.test_coverage NOT IN ('', 'pass', 'fail', 'skip', 'na')
+  OR NEW.ui_ux NOT IN ('', 'pass', 'fail', 'skip', 'na')
+BEGIN
+    SELECT RAISE(ABORT, 'invalid review verdict or category');
+END;
@@ -0,0 +1 @@
+-- Nothing to undo: older binaries read UTC timestamps as they read any other.
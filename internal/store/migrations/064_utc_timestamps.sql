@@ -0,0 +1,4 @@
+-- Timestamps written before they were stored in UTC keep the offset of the
+-- zone they were built in, so they compare wrongly with UTC ones as text and
+-- through julianday(substr(..., 1, 19)). The migration's Go hook rewrites
+-- every such value in UTC, in the layout the store writes.
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// FindProjectNote resolves a note of a project by ID, case-insensitive title
+// or unique ID prefix.
+func FindProjectNote(ctx context.Context, s Store, projectID, ref string) (*models.ProjectNote, error) {
+	if ref == "" {
+		return nil, fmt.Errorf("note not found: empty reference")
+	}
+	notes, err := s.ListProjectNotes(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range notes {
+		if n.ID == ref {
+			return n, nil
+		}
+	}
+	for _, n := range notes {
+		if strings.EqualFold(n.Title, ref) {
+			return n, nil
+		}
+	}
+
+	var matches []*models.ProjectNote
+	upper := strings.ToUpper(ref)
+	for _, n := range notes {
+		if strings.HasPrefix(n.ID, upper) {
+			matches = append(matches, n)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("note not found: %s", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("ambiguous note ID prefix %s matches %d notes", ref, len(matches))
+	}
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/joescharf/pm/internal/lexorank"
+	"github.com/joescharf/pm/internal/models"
+)
+
+// RankPlacement says where RankIssue moves an issue within its status
+// column: directly before or after another issue of the column, or to its
+// top or bottom. Exactly one must be set.
+type RankPlacement struct {
+	Before string // issue ID
+	After  string // issue ID
+	Top    bool
+	Bottom bool
+}
+
+// ErrRankPlacement is returned, wrapped, by RankIssue for a placement that
+// names no spot in the issue's column.
+var ErrRankPlacement = errors.New("invalid rank placement")
+
+// RankIssue moves an issue within its project's status column, in the order
+// ListIssues returns, by giving it a rank between its new neighbors. When the
+// issue above the new spot is unranked, or the neighbors' ranks are too close
+// for another between them, the whole column is re-ranked in its new order
+// with evenly spaced ranks.
+func RankIssue(ctx context.Context, s Store, id string, at RankPlacement) (*models.Issue, error) {
+	set := 0
+	for _, b := range []bool{at.Before != "", at.After != "", at.Top, at.Bottom} {
+		if b {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("%w: give one of before, after, top or bottom", ErrRankPlacement)
+	}
+	if at.Before == id || at.After == id {
+		return nil, fmt.Errorf("%w: cannot place an issue next to itself", ErrRankPlacement)
+	}
+	issue, err := s.GetIssue(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	column, err := s.ListIssues(ctx, IssueListFilter{ProjectID: issue.ProjectID, Status: issue.Status})
+	if err != nil {
+		return nil, err
+	}
+	column = slices.DeleteFunc(column, func(i *models.Issue) bool { return i.ID == id })
+
+	pos := 0
+	switch {
+	case at.Bottom:
+		pos = len(column)
+	case at.Before != "", at.After != "":
+		ref := at.Before + at.After
+		pos = slices.IndexFunc(column, func(i *models.Issue) bool { return i.ID == ref })
+		if pos < 0 {
+			return nil, fmt.Errorf("%w: issue %s is not in the %s column of issue %s's project", ErrRankPlacement, ref, issue.Status, id)
+		}
+		if at.After != "" {
+			pos++
+		}
+	}
+
+	var above, below string
+	if pos > 0 {
+		above = column[pos-1].Rank
+	}
+	if pos < len(column) {
+		below = column[pos].Rank
+	}
+	if pos == 0 || above != "" {
+		// An unranked issue below is listed after every ranked one, so
+		// ranking right after the issue above is enough. Neighbors without
+		// room between them (or out of order) fall through to a re-rank.
+		if r, err := lexorank.Between(above, below); err == nil {
+			if err := s.SetIssueRanks(ctx, map[string]string{id: r}); err != nil {
+				return nil, err
+			}
+			issue.Rank = r
+			return issue, nil
+		}
+	}
+
+	order := slices.Insert(column, pos, issue)
+	spread := lexorank.Spread(len(order))
+	ranks := make(map[string]string, len(order))
+	for i, o := range order {
+		ranks[o.ID] = spread[i]
+	}
+	if err := s.SetIssueRanks(ctx, ranks); err != nil {
+		return nil, err
+	}
+	issue.Rank = ranks[id]
+	return issue, nil
+}
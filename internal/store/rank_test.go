@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+func TestStores_RankIssue(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		p := &models.Project{Name: "api", Path: "/code/api"}
+		require.NoError(t, s.CreateProject(ctx, p))
+		newIssue := func(title string, priority models.IssuePriority) *models.Issue {
+			i := &models.Issue{ProjectID: p.ID, Title: title, Status: models.IssueStatusOpen, Priority: priority}
+			require.NoError(t, s.CreateIssue(ctx, i))
+			return i
+		}
+		a := newIssue("A", models.IssuePriorityHigh)
+		b := newIssue("B", models.IssuePriorityMedium)
+		c := newIssue("C", models.IssuePriorityLow)
+		order := func() []string {
+			issues, err := s.ListIssues(ctx, IssueListFilter{ProjectID: p.ID, Status: models.IssueStatusOpen})
+			require.NoError(t, err)
+			var titles []string
+			for _, i := range issues {
+				titles = append(titles, i.Title)
+			}
+			return titles
+		}
+		rank := func(id string, at RankPlacement) *models.Issue {
+			got, err := RankIssue(ctx, s, id, at)
+			require.NoError(t, err)
+			return got
+		}
+
+		// Unranked issues keep the priority order.
+		assert.Equal(t, []string{"A", "B", "C"}, order())
+
+		// Ranking below an unranked issue ranks the whole column.
+		got := rank(a.ID, RankPlacement{After: c.ID})
+		assert.NotEmpty(t, got.Rank)
+		assert.Equal(t, []string{"B", "C", "A"}, order())
+
+		// New issues come after the ranked ones.
+		d := newIssue("D", models.IssuePriorityHigh)
+		assert.Equal(t, []string{"B", "C", "A", "D"}, order())
+
+		rank(d.ID, RankPlacement{Top: true})
+		assert.Equal(t, []string{"D", "B", "C", "A"}, order())
+		rank(b.ID, RankPlacement{Bottom: true})
+		assert.Equal(t, []string{"D", "C", "A", "B"}, order())
+		rank(b.ID, RankPlacement{Before: c.ID})
+		assert.Equal(t, []string{"D", "B", "C", "A"}, order())
+
+		// Moving an issue only rewrites its own rank while there is room.
+		before, err := s.GetIssue(ctx, c.ID)
+		require.NoError(t, err)
+		rank(a.ID, RankPlacement{After: d.ID})
+		after, err := s.GetIssue(ctx, c.ID)
+		require.NoError(t, err)
+		assert.Equal(t, before.Rank, after.Rank)
+		assert.Equal(t, []string{"D", "A", "B", "C"}, order())
+
+		// Inserting at the same spot until the ranks run out of room
+		// re-balances the column without losing the order.
+		for i := 0; i < 120; i++ {
+			moving := []string{b.ID, a.ID}[i%2]
+			rank(moving, RankPlacement{After: d.ID})
+		}
+		assert.Equal(t, []string{"D", "A", "B", "C"}, order())
+		for _, i := range []*models.Issue{a, b, c, d} {
+			got, err := s.GetIssue(ctx, i.ID)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(got.Rank), 16)
+		}
+
+		// A status change drops the rank; the issue rejoins the unranked
+		// issues in its new column.
+		got, err = s.GetIssue(ctx, d.ID)
+		require.NoError(t, err)
+		got.Status = models.IssueStatusInProgress
+		require.NoError(t, s.UpdateIssue(ctx, got))
+		got, err = s.GetIssue(ctx, d.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got.Rank)
+		assert.Equal(t, []string{"A", "B", "C"}, order())
+
+		_, err = RankIssue(ctx, s, a.ID, RankPlacement{})
+		assert.ErrorIs(t, err, ErrRankPlacement)
+		_, err = RankIssue(ctx, s, a.ID, RankPlacement{Top: true, Before: b.ID})
+		assert.ErrorIs(t, err, ErrRankPlacement)
+		_, err = RankIssue(ctx, s, a.ID, RankPlacement{Before: a.ID})
+		assert.ErrorIs(t, err, ErrRankPlacement)
+		_, err = RankIssue(ctx, s, a.ID, RankPlacement{Before: d.ID})
+		assert.ErrorIs(t, err, ErrRankPlacement, "other column")
+
+		assert.Error(t, s.SetIssueRanks(ctx, map[string]string{a.ID: "a0"}), "invalid rank")
+	})
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// IssueMerge is duplicate issues folded into a target, as groom.Merge plans
+// it. MergeIssues writes it in one transaction: either all of it lands or
+// none does.
+type IssueMerge struct {
+	Target     *models.Issue
+	Duplicates []*models.Issue         // written as given, closed
+	TagIDs     []string                // tags Target gains
+	Watchers   []*models.IssueWatcher  // targets already watching are skipped
+	Relations  []*models.IssueRelation // relations that exist are skipped
+	Comments   []*models.IssueComment
+}
+
+// RelatedIssue is one relation viewed from a particular issue: the label reads
+// from that issue's side (e.g. "caused by" vs "causes") and Issue is the other end.
+type RelatedIssue struct {
+	Relation *models.IssueRelation
+	Label    string
+	Outgoing bool
+	Issue    *models.Issue
+}
+
+// RelatedIssues resolves the relations of issueID to the issues on the other
+// end. Relations whose other issue can no longer be loaded are skipped.
+func RelatedIssues(ctx context.Context, s Store, issueID string) ([]RelatedIssue, error) {
+	rels, err := s.ListIssueRelations(ctx, issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []RelatedIssue
+	for _, rel := range rels {
+		ri := RelatedIssue{Relation: rel}
+		otherID := rel.TargetIssueID
+		if rel.SourceIssueID == issueID {
+			ri.Outgoing = true
+			ri.Label = rel.Type.Label()
+		} else {
+			otherID = rel.SourceIssueID
+			ri.Label = rel.Type.InverseLabel()
+		}
+		other, err := s.GetIssue(ctx, otherID)
+		if err != nil {
+			continue
+		}
+		ri.Issue = other
+		out = append(out, ri)
+	}
+	return out, nil
+}
@@ -0,0 +1,227 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// Short code kinds, numbered independently per project.
+const (
+	codeKindIssue   = "issue"
+	codeKindSession = "session"
+)
+
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// projectKeyInUse reports whether key belongs to another project than
+// projectID or prefixes any issue or session code.
+func projectKeyInUse(ctx context.Context, q queryer, key, projectID string) (bool, error) {
+	var n int
+	err := q.QueryRowContext(ctx,
+		`SELECT (SELECT COUNT(*) FROM projects WHERE key = ?1 AND id != ?2)
+		+ (SELECT COUNT(*) FROM issues WHERE code LIKE ?1 || '-%' AND project_id != ?2)
+		+ (SELECT COUNT(*) FROM agent_sessions WHERE code LIKE ?1 || '-%' AND project_id != ?2)`,
+		key, projectID).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("check project key: %w", err)
+	}
+	return n > 0, nil
+}
+
+// uniqueProjectKey returns base, or base with the lowest number from 2 up
+// appended, whichever inUse says is free.
+func uniqueProjectKey(base string, inUse func(key string) (bool, error)) (string, error) {
+	for n := 1; ; n++ {
+		key := base
+		if n > 1 {
+			suffix := strconv.Itoa(n)
+			key = base[:min(len(base), models.MaxProjectKeyLength-len(suffix))] + suffix
+		}
+		used, err := inUse(key)
+		if err != nil {
+			return "", err
+		}
+		if !used {
+			return key, nil
+		}
+	}
+}
+
+// projectKeyFor validates the key given to a new project, or derives a free
+// one from its name.
+func projectKeyFor(p *models.Project, inUse func(key string) (bool, error)) (string, error) {
+	if p.Key == "" {
+		return uniqueProjectKey(models.DeriveProjectKey(p.Name), inUse)
+	}
+	if err := models.ValidateProjectKey(p.Key); err != nil {
+		return "", err
+	}
+	used, err := inUse(p.Key)
+	if err != nil {
+		return "", err
+	}
+	if used {
+		return "", fmt.Errorf("%w: %s", ErrProjectKeyTaken, p.Key)
+	}
+	return p.Key, nil
+}
+
+// formatCode formats the nth short code of kind under key.
+func formatCode(key, kind string, n int) string {
+	if kind == codeKindSession {
+		return models.SessionCode(key, n)
+	}
+	return models.IssueCode(key, n)
+}
+
+// nextShortCode hands out the project's next code of kind. It returns ""
+// for a missing project, leaving the insert to report it.
+func (s *SQLiteStore) nextShortCode(ctx context.Context, projectID, kind string) (string, error) {
+	var key string
+	err := s.db.QueryRowContext(ctx, `SELECT key FROM projects WHERE id = ?`, projectID).Scan(&key)
+	if err == sql.ErrNoRows || err == nil && key == "" {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get project key: %w", err)
+	}
+	var n int
+	if err := s.db.QueryRowContext(ctx,
+		`INSERT INTO short_code_counters (project_id, kind, last) VALUES (?, ?, 1)
+		ON CONFLICT (project_id, kind) DO UPDATE SET last = last + 1
+		RETURNING last`, projectID, kind).Scan(&n); err != nil {
+		return "", fmt.Errorf("number %s: %w", kind, err)
+	}
+	return formatCode(key, kind, n), nil
+}
+
+// GetIssueByCode returns the issue with a short code such as "WID-12".
+func (s *SQLiteStore) GetIssueByCode(ctx context.Context, code string) (*models.Issue, error) {
+	var id string
+	if err := s.db.QueryRowContext(ctx, `SELECT id FROM issues WHERE code = ?`, code).Scan(&id); err != nil {
+		return nil, fmt.Errorf("issue not found: %s", code)
+	}
+	return s.GetIssue(ctx, id)
+}
+
+// GetAgentSessionByCode returns the session with a short code such as
+// "WID-S3".
+func (s *SQLiteStore) GetAgentSessionByCode(ctx context.Context, code string) (*models.AgentSession, error) {
+	var id string
+	if err := s.db.QueryRowContext(ctx, `SELECT id FROM agent_sessions WHERE code = ?`, code).Scan(&id); err != nil {
+		return nil, fmt.Errorf("agent session not found: %s", code)
+	}
+	return s.GetAgentSession(ctx, id)
+}
+
+// SetProjectKey changes a project's key and re-prefixes the codes that
+// carried the old one, keeping their numbers.
+func (s *SQLiteStore) SetProjectKey(ctx context.Context, projectID, key string) error {
+	if err := models.ValidateProjectKey(key); err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var old string
+	if err := tx.QueryRowContext(ctx, `SELECT key FROM projects WHERE id = ?`, projectID).Scan(&old); err != nil {
+		return fmt.Errorf("project not found: %s", projectID)
+	}
+	if old == key {
+		return nil
+	}
+	used, err := projectKeyInUse(ctx, tx, key, projectID)
+	if err != nil {
+		return err
+	}
+	if used {
+		return fmt.Errorf("%w: %s", ErrProjectKeyTaken, key)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE projects SET key = ?, updated_at = ? WHERE id = ?`, key, time.Now().UTC(), projectID); err != nil {
+		return fmt.Errorf("set project key: %w", err)
+	}
+	if old != "" {
+		for _, table := range []string{"issues", "agent_sessions"} {
+			if _, err := tx.ExecContext(ctx,
+				`UPDATE `+table+` SET code = ?1 || substr(code, length(?2) + 1) WHERE code LIKE ?2 || '-%'`, key, old); err != nil {
+				return fmt.Errorf("set project key: re-prefix %s: %w", table, err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	s.notifyWrite()
+	return nil
+}
+
+// backfillShortCodes gives existing projects keys and their issues and
+// sessions codes, numbered in creation order, when short codes are added.
+func backfillShortCodes(ctx context.Context, tx *sql.Tx) error {
+	type row struct{ id, ref string }
+	list := func(query string) ([]row, error) {
+		rows, err := tx.QueryContext(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = rows.Close() }()
+		var out []row
+		for rows.Next() {
+			var r row
+			if err := rows.Scan(&r.id, &r.ref); err != nil {
+				return nil, err
+			}
+			out = append(out, r)
+		}
+		return out, rows.Err()
+	}
+
+	projects, err := list(`SELECT id, name FROM projects ORDER BY created_at, id`)
+	if err != nil {
+		return fmt.Errorf("list projects: %w", err)
+	}
+	keys := make(map[string]string, len(projects)) // project ID -> key
+	taken := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		key, _ := uniqueProjectKey(models.DeriveProjectKey(p.ref), func(k string) (bool, error) { return taken[k], nil })
+		taken[key] = true
+		keys[p.id] = key
+		if _, err := tx.ExecContext(ctx, `UPDATE projects SET key = ? WHERE id = ?`, key, p.id); err != nil {
+			return fmt.Errorf("set project key: %w", err)
+		}
+	}
+
+	for _, k := range []struct{ kind, table, order string }{
+		{codeKindIssue, "issues", "created_at"},
+		{codeKindSession, "agent_sessions", "started_at"},
+	} {
+		records, err := list(`SELECT id, project_id FROM ` + k.table + ` ORDER BY ` + k.order + `, id`)
+		if err != nil {
+			return fmt.Errorf("list %s: %w", k.table, err)
+		}
+		counts := make(map[string]int)
+		for _, r := range records {
+			counts[r.ref]++
+			code := formatCode(keys[r.ref], k.kind, counts[r.ref])
+			if _, err := tx.ExecContext(ctx, `UPDATE `+k.table+` SET code = ? WHERE id = ?`, code, r.id); err != nil {
+				return fmt.Errorf("set %s code: %w", k.kind, err)
+			}
+		}
+		for projectID, n := range counts {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO short_code_counters (project_id, kind, last) VALUES (?, ?, ?)`, projectID, k.kind, n); err != nil {
+				return fmt.Errorf("set %s counter: %w", k.kind, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// IssueSort selects the order ListIssues returns issues in.
+type IssueSort string
+
+const (
+	// IssueSortDefault orders by status, then rank (ranked issues first), then
+	// priority, then newest first.
+	IssueSortDefault IssueSort = ""
+	// IssueSortSmart orders unfinished issues by SmartScore, highest first,
+	// ahead of done and closed ones.
+	IssueSortSmart IssueSort = "smart"
+)
+
+// ParseIssueSort validates a sort name from user input.
+func ParseIssueSort(s string) (IssueSort, error) {
+	switch IssueSort(s) {
+	case IssueSortDefault, IssueSortSmart:
+		return IssueSort(s), nil
+	}
+	return "", fmt.Errorf("unknown sort %q (want smart)", s)
+}
+
+// Smart-order weights. Priority sets the band, a missed SLA is worth a
+// priority level, and age breaks ties within a band. A blocked issue cannot
+// be worked on, so it sinks below everything that can.
+const (
+	smartSLABreached    = 100
+	smartSLAAtRisk      = 50
+	smartMaxAge         = 30 // points, one per day waiting
+	smartBlockedPenalty = 1000
+)
+
+var smartPriorityScores = map[models.IssuePriority]int{
+	models.IssuePriorityHigh:   300,
+	models.IssuePriorityMedium: 200,
+	models.IssuePriorityLow:    100,
+}
+
+// SmartScore rates how urgently an issue should be picked up from its
+// priority, its SLA state, how long it has waited and whether an unfinished
+// issue blocks it. The reasons explain the score.
+func SmartScore(issue *models.Issue, blocked bool, now time.Time) (int, []string) {
+	score := smartPriorityScores[issue.Priority]
+	reasons := []string{fmt.Sprintf("%s priority", issue.Priority)}
+	switch issue.SLAState {
+	case models.SLAStateBreached:
+		score += smartSLABreached
+		reasons = append(reasons, "SLA breached")
+	case models.SLAStateAtRisk:
+		score += smartSLAAtRisk
+		reasons = append(reasons, "SLA at risk")
+	}
+	if days := int(now.Sub(issue.CreatedAt).Hours() / 24); days > 0 {
+		score += min(days, smartMaxAge)
+		reasons = append(reasons, fmt.Sprintf("waiting %dd", days))
+	}
+	if blocked {
+		score -= smartBlockedPenalty
+		reasons = append(reasons, "blocked")
+	}
+	return score, reasons
+}
+
+// sortIssuesSmart orders issues for IssueSortSmart. blocked holds the IDs of
+// issues an unfinished issue blocks.
+func sortIssuesSmart(issues []*models.Issue, blocked map[string]bool, now time.Time) {
+	scores := make(map[string]int, len(issues))
+	for _, issue := range issues {
+		scores[issue.ID], _ = SmartScore(issue, blocked[issue.ID], now)
+	}
+	finished := func(i *models.Issue) bool {
+		return i.Status == models.IssueStatusDone || i.Status == models.IssueStatusClosed
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if fa, fb := finished(a), finished(b); fa != fb {
+			return fb
+		}
+		if scores[a.ID] != scores[b.ID] {
+			return scores[a.ID] > scores[b.ID]
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+}
@@ -0,0 +1,37 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+func TestSmartScore(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	score, reasons := SmartScore(&models.Issue{Priority: models.IssuePriorityMedium, CreatedAt: now.Add(-3 * day)}, false, now)
+	assert.Equal(t, 203, score)
+	assert.Equal(t, []string{"medium priority", "waiting 3d"}, reasons)
+
+	score, _ = SmartScore(&models.Issue{Priority: models.IssuePriorityLow, CreatedAt: now.Add(-90 * day)}, false, now)
+	assert.Equal(t, 130, score, "age is capped")
+
+	score, reasons = SmartScore(&models.Issue{Priority: models.IssuePriorityHigh, SLAState: models.SLAStateAtRisk, CreatedAt: now}, true, now)
+	assert.Equal(t, 300+50-1000, score)
+	assert.Equal(t, []string{"high priority", "SLA at risk", "blocked"}, reasons)
+}
+
+func TestParseIssueSort(t *testing.T) {
+	got, err := ParseIssueSort("smart")
+	assert.NoError(t, err)
+	assert.Equal(t, IssueSortSmart, got)
+	got, err = ParseIssueSort("")
+	assert.NoError(t, err)
+	assert.Equal(t, IssueSortDefault, got)
+	_, err = ParseIssueSort("age")
+	assert.Error(t, err)
+}
@@ -3,29 +3,31 @@ package store
 import (
 	"context"
 	"database/sql"
-	"embed"
 	"encoding/json"
 	"fmt"
 	"math/rand"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/oklog/ulid/v2"
 
+	"github.com/joescharf/pm/internal/lexorank"
 	"github.com/joescharf/pm/internal/models"
 
 	_ "modernc.org/sqlite"
 )
 
-//go:embed migrations/*.sql
-var migrationsFS embed.FS
-
 // SQLiteStore implements Store using modernc.org/sqlite (pure Go, no CGO).
 type SQLiteStore struct {
-	db *sql.DB
+	db          *sql.DB
+	path        string
+	compression Compression
+
+	hooksMu    sync.RWMutex
+	writeHooks []func()
 }
 
 // NewSQLiteStore opens (or creates) a SQLite database at the given path.
@@ -64,76 +66,139 @@ func NewSQLiteStore(dbPath string) (*SQLiteStore, error) {
 		return nil, fmt.Errorf("enable foreign keys: %w", err)
 	}
 
-	return &SQLiteStore{db: db}, nil
+	return &SQLiteStore{db: db, path: dbPath, compression: DefaultCompression}, nil
 }
 
-// boolToInt converts a bool to 0 or 1 for SQLite storage.
-func boolToInt(b bool) int {
-	if b {
-		return 1
-	}
-	return 0
+// OnWrite registers fn to be called after every successful write, e.g. to
+// invalidate caches of data derived from the store.
+func (s *SQLiteStore) OnWrite(fn func()) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.writeHooks = append(s.writeHooks, fn)
 }
 
-// newULID generates a new ULID string.
-func newULID() string {
-	entropy := rand.New(rand.NewSource(time.Now().UnixNano()))
-	return ulid.MustNew(ulid.Timestamp(time.Now()), ulid.Monotonic(entropy, 0)).String()
+func (s *SQLiteStore) notifyWrite() {
+	s.hooksMu.RLock()
+	defer s.hooksMu.RUnlock()
+	for _, fn := range s.writeHooks {
+		fn()
+	}
 }
 
-// Migrate runs all embedded SQL migration files in order.
-func (s *SQLiteStore) Migrate(ctx context.Context) error {
-	// Create migrations tracking table
-	_, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
-		filename TEXT PRIMARY KEY,
-		applied_at DATETIME NOT NULL DEFAULT (datetime('now'))
-	)`)
-	if err != nil {
-		return fmt.Errorf("create migrations table: %w", err)
+// exec runs a write statement and notifies write hooks on success.
+func (s *SQLiteStore) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	result, err := s.db.ExecContext(ctx, query, utcArgs(args)...)
+	if err == nil {
+		s.notifyWrite()
 	}
+	return result, err
+}
 
-	entries, err := migrationsFS.ReadDir("migrations")
-	if err != nil {
-		return fmt.Errorf("read migrations dir: %w", err)
+// utcArgs converts time arguments to UTC, so every timestamp is stored in the
+// same zone and timestamps compare correctly as text whatever zone the caller
+// built them in.
+func utcArgs(args []any) []any {
+	for i, a := range args {
+		switch t := a.(type) {
+		case time.Time:
+			args[i] = t.UTC()
+		case *time.Time:
+			if t != nil {
+				args[i] = t.UTC()
+			}
+		}
 	}
+	return args
+}
 
-	// Sort by filename
-	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].Name() < entries[j].Name()
-	})
+// utcTimestamps rewrites in UTC the stored timestamps that carry another
+// zone's offset, returning how many it changed. The change feed and session
+// events the rewrites trigger are removed again, as no record changed.
+func utcTimestamps(ctx context.Context, tx *sql.Tx) (int, error) {
+	var lastChange, lastEvent int64
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(seq), 0) FROM changes`).Scan(&lastChange); err != nil {
+		return 0, fmt.Errorf("utc timestamps: %w", err)
+	}
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM session_events`).Scan(&lastEvent); err != nil {
+		return 0, fmt.Errorf("utc timestamps: %w", err)
+	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
+	type column struct{ table, name string }
+	var columns []column
+	rows, err := tx.QueryContext(ctx, `SELECT m.name, c.name FROM sqlite_master m JOIN pragma_table_info(m.name) c
+		WHERE m.type = 'table' AND c.type = 'DATETIME' ORDER BY m.name, c.cid`)
+	if err != nil {
+		return 0, fmt.Errorf("utc timestamps: %w", err)
+	}
+	for rows.Next() {
+		var c column
+		if err := rows.Scan(&c.table, &c.name); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("utc timestamps: %w", err)
 		}
+		columns = append(columns, c)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("utc timestamps: %w", err)
+	}
 
-		name := entry.Name()
-
-		// Check if already applied
-		var count int
-		err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migrations WHERE filename = ?", name).Scan(&count)
+	changed := 0
+	for _, col := range columns {
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT rowid, %s FROM %s WHERE %s IS NOT NULL", col.name, col.table, col.name))
 		if err != nil {
-			return fmt.Errorf("check migration %s: %w", name, err)
+			return changed, fmt.Errorf("utc timestamps %s.%s: %w", col.table, col.name, err)
 		}
-		if count > 0 {
-			continue
+		updates := make(map[int64]time.Time)
+		for rows.Next() {
+			var rowid int64
+			var stored any
+			if err := rows.Scan(&rowid, &stored); err != nil {
+				_ = rows.Close()
+				return changed, fmt.Errorf("utc timestamps %s.%s: %w", col.table, col.name, err)
+			}
+			// Values the driver cannot read as a time are left as they are.
+			if t, ok := stored.(time.Time); ok {
+				if _, offset := t.Zone(); offset != 0 {
+					updates[rowid] = t.UTC()
+				}
+			}
 		}
-
-		data, err := migrationsFS.ReadFile("migrations/" + name)
-		if err != nil {
-			return fmt.Errorf("read migration %s: %w", name, err)
+		_ = rows.Close()
+		if err := rows.Err(); err != nil {
+			return changed, err
 		}
 
-		if _, err := s.db.ExecContext(ctx, string(data)); err != nil {
-			return fmt.Errorf("apply migration %s: %w", name, err)
+		query := fmt.Sprintf("UPDATE %s SET %s = ? WHERE rowid = ?", col.table, col.name)
+		for rowid, t := range updates {
+			if _, err := tx.ExecContext(ctx, query, t, rowid); err != nil {
+				return changed, fmt.Errorf("utc timestamps %s.%s row %d: %w", col.table, col.name, rowid, err)
+			}
+			changed++
 		}
+	}
 
-		if _, err := s.db.ExecContext(ctx, "INSERT INTO schema_migrations (filename) VALUES (?)", name); err != nil {
-			return fmt.Errorf("record migration %s: %w", name, err)
-		}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM changes WHERE seq > ?`, lastChange); err != nil {
+		return changed, fmt.Errorf("utc timestamps: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM session_events WHERE id > ?`, lastEvent); err != nil {
+		return changed, fmt.Errorf("utc timestamps: %w", err)
 	}
+	return changed, nil
+}
 
-	return nil
+// boolToInt converts a bool to 0 or 1 for SQLite storage.
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// newULID generates a new ULID string.
+func newULID() string {
+	entropy := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return ulid.MustNew(ulid.Timestamp(time.Now()), ulid.Monotonic(entropy, 0)).String()
 }
 
 // Close closes the database connection.
@@ -148,14 +213,21 @@ func (s *SQLiteStore) CreateProject(ctx context.Context, p *models.Project) erro
 		p.ID = newULID()
 	}
 	now := time.Now().UTC()
-	p.CreatedAt = now
-	p.UpdatedAt = now
+	p.CreatedAt = createdTime(ctx, p.CreatedAt, now)
+	p.UpdatedAt = createdTime(ctx, p.UpdatedAt, now)
+	key, err := projectKeyFor(p, func(key string) (bool, error) {
+		return projectKeyInUse(ctx, s.db, key, p.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("create project: %w", err)
+	}
+	p.Key = key
 
-	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO projects (id, name, path, description, repo_url, language, group_name, branch_count, has_github_pages, pages_url, build_cmd, serve_cmd, serve_port, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		p.ID, p.Name, p.Path, p.Description, p.RepoURL, p.Language, p.GroupName,
-		p.BranchCount, boolToInt(p.HasGitHubPages), p.PagesURL, p.BuildCmd, p.ServeCmd, p.ServePort, p.CreatedAt, p.UpdatedAt,
+	_, err = s.exec(ctx,
+		`INSERT INTO projects (id, name, key, path, description, repo_url, language, languages, group_name, branch_count, has_github_pages, pages_url, build_cmd, serve_cmd, serve_port, conventions, max_in_progress, max_active_sessions, wip_hard_block, commit_style, commit_ticket_prefix, refresh_skip, sparse_paths, merge_strategy, cascade_rules, github_board, ui_review_paths, archived, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		p.ID, p.Name, p.Key, p.Path, p.Description, p.RepoURL, p.Language, languagesJSON(p.Languages), p.GroupName,
+		p.BranchCount, boolToInt(p.HasGitHubPages), p.PagesURL, p.BuildCmd, p.ServeCmd, p.ServePort, p.Conventions, p.MaxInProgress, p.MaxActiveSessions, boolToInt(p.WIPHardBlock), p.CommitStyle, p.CommitTicketPrefix, p.RefreshSkip, p.SparsePaths, p.MergeStrategy, p.Cascade, p.GitHubBoard, p.UIReviewPaths, boolToInt(p.Archived), p.CreatedAt, p.UpdatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("create project: %w", err)
@@ -166,9 +238,9 @@ func (s *SQLiteStore) CreateProject(ctx context.Context, p *models.Project) erro
 func (s *SQLiteStore) GetProject(ctx context.Context, id string) (*models.Project, error) {
 	p := &models.Project{}
 	err := s.db.QueryRowContext(ctx,
-		`SELECT id, name, path, description, repo_url, language, group_name, branch_count, has_github_pages, pages_url, build_cmd, serve_cmd, serve_port, created_at, updated_at
+		`SELECT id, name, key, path, description, repo_url, language, languages, group_name, branch_count, has_github_pages, pages_url, build_cmd, serve_cmd, serve_port, conventions, max_in_progress, max_active_sessions, wip_hard_block, commit_style, commit_ticket_prefix, refresh_skip, sparse_paths, merge_strategy, cascade_rules, github_board, ui_review_paths, archived, created_at, updated_at
 		FROM projects WHERE id = ?`, id,
-	).Scan(&p.ID, &p.Name, &p.Path, &p.Description, &p.RepoURL, &p.Language, &p.GroupName, &p.BranchCount, &p.HasGitHubPages, &p.PagesURL, &p.BuildCmd, &p.ServeCmd, &p.ServePort, &p.CreatedAt, &p.UpdatedAt)
+	).Scan(&p.ID, &p.Name, &p.Key, &p.Path, &p.Description, &p.RepoURL, &p.Language, languagesColumn{&p.Languages}, &p.GroupName, &p.BranchCount, &p.HasGitHubPages, &p.PagesURL, &p.BuildCmd, &p.ServeCmd, &p.ServePort, &p.Conventions, &p.MaxInProgress, &p.MaxActiveSessions, &p.WIPHardBlock, &p.CommitStyle, &p.CommitTicketPrefix, &p.RefreshSkip, &p.SparsePaths, &p.MergeStrategy, &p.Cascade, &p.GitHubBoard, &p.UIReviewPaths, &p.Archived, &p.CreatedAt, &p.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("project not found: %s", id)
 	}
@@ -181,9 +253,9 @@ func (s *SQLiteStore) GetProject(ctx context.Context, id string) (*models.Projec
 func (s *SQLiteStore) GetProjectByName(ctx context.Context, name string) (*models.Project, error) {
 	p := &models.Project{}
 	err := s.db.QueryRowContext(ctx,
-		`SELECT id, name, path, description, repo_url, language, group_name, branch_count, has_github_pages, pages_url, build_cmd, serve_cmd, serve_port, created_at, updated_at
+		`SELECT id, name, key, path, description, repo_url, language, languages, group_name, branch_count, has_github_pages, pages_url, build_cmd, serve_cmd, serve_port, conventions, max_in_progress, max_active_sessions, wip_hard_block, commit_style, commit_ticket_prefix, refresh_skip, sparse_paths, merge_strategy, cascade_rules, github_board, ui_review_paths, archived, created_at, updated_at
 		FROM projects WHERE name = ?`, name,
-	).Scan(&p.ID, &p.Name, &p.Path, &p.Description, &p.RepoURL, &p.Language, &p.GroupName, &p.BranchCount, &p.HasGitHubPages, &p.PagesURL, &p.BuildCmd, &p.ServeCmd, &p.ServePort, &p.CreatedAt, &p.UpdatedAt)
+	).Scan(&p.ID, &p.Name, &p.Key, &p.Path, &p.Description, &p.RepoURL, &p.Language, languagesColumn{&p.Languages}, &p.GroupName, &p.BranchCount, &p.HasGitHubPages, &p.PagesURL, &p.BuildCmd, &p.ServeCmd, &p.ServePort, &p.Conventions, &p.MaxInProgress, &p.MaxActiveSessions, &p.WIPHardBlock, &p.CommitStyle, &p.CommitTicketPrefix, &p.RefreshSkip, &p.SparsePaths, &p.MergeStrategy, &p.Cascade, &p.GitHubBoard, &p.UIReviewPaths, &p.Archived, &p.CreatedAt, &p.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("project not found: %s", name)
 	}
@@ -196,9 +268,9 @@ func (s *SQLiteStore) GetProjectByName(ctx context.Context, name string) (*model
 func (s *SQLiteStore) GetProjectByPath(ctx context.Context, path string) (*models.Project, error) {
 	p := &models.Project{}
 	err := s.db.QueryRowContext(ctx,
-		`SELECT id, name, path, description, repo_url, language, group_name, branch_count, has_github_pages, pages_url, build_cmd, serve_cmd, serve_port, created_at, updated_at
+		`SELECT id, name, key, path, description, repo_url, language, languages, group_name, branch_count, has_github_pages, pages_url, build_cmd, serve_cmd, serve_port, conventions, max_in_progress, max_active_sessions, wip_hard_block, commit_style, commit_ticket_prefix, refresh_skip, sparse_paths, merge_strategy, cascade_rules, github_board, ui_review_paths, archived, created_at, updated_at
 		FROM projects WHERE path = ?`, path,
-	).Scan(&p.ID, &p.Name, &p.Path, &p.Description, &p.RepoURL, &p.Language, &p.GroupName, &p.BranchCount, &p.HasGitHubPages, &p.PagesURL, &p.BuildCmd, &p.ServeCmd, &p.ServePort, &p.CreatedAt, &p.UpdatedAt)
+	).Scan(&p.ID, &p.Name, &p.Key, &p.Path, &p.Description, &p.RepoURL, &p.Language, languagesColumn{&p.Languages}, &p.GroupName, &p.BranchCount, &p.HasGitHubPages, &p.PagesURL, &p.BuildCmd, &p.ServeCmd, &p.ServePort, &p.Conventions, &p.MaxInProgress, &p.MaxActiveSessions, &p.WIPHardBlock, &p.CommitStyle, &p.CommitTicketPrefix, &p.RefreshSkip, &p.SparsePaths, &p.MergeStrategy, &p.Cascade, &p.GitHubBoard, &p.UIReviewPaths, &p.Archived, &p.CreatedAt, &p.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("project not found at path: %s", path)
 	}
@@ -213,22 +285,37 @@ func (s *SQLiteStore) ListProjects(ctx context.Context, group string) ([]*models
 	var err error
 	if group != "" {
 		rows, err = s.db.QueryContext(ctx,
-			`SELECT id, name, path, description, repo_url, language, group_name, branch_count, has_github_pages, pages_url, build_cmd, serve_cmd, serve_port, created_at, updated_at
-			FROM projects WHERE group_name = ? ORDER BY name`, group)
+			`SELECT id, name, key, path, description, repo_url, language, languages, group_name, branch_count, has_github_pages, pages_url, build_cmd, serve_cmd, serve_port, conventions, max_in_progress, max_active_sessions, wip_hard_block, commit_style, commit_ticket_prefix, refresh_skip, sparse_paths, merge_strategy, cascade_rules, github_board, ui_review_paths, archived, created_at, updated_at
+			FROM projects WHERE group_name = ? AND archived = 0 ORDER BY name`, group)
 	} else {
 		rows, err = s.db.QueryContext(ctx,
-			`SELECT id, name, path, description, repo_url, language, group_name, branch_count, has_github_pages, pages_url, build_cmd, serve_cmd, serve_port, created_at, updated_at
-			FROM projects ORDER BY name`)
+			`SELECT id, name, key, path, description, repo_url, language, languages, group_name, branch_count, has_github_pages, pages_url, build_cmd, serve_cmd, serve_port, conventions, max_in_progress, max_active_sessions, wip_hard_block, commit_style, commit_ticket_prefix, refresh_skip, sparse_paths, merge_strategy, cascade_rules, github_board, ui_review_paths, archived, created_at, updated_at
+			FROM projects WHERE archived = 0 ORDER BY name`)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("list projects: %w", err)
 	}
+	return scanProjects(rows)
+}
+
+// ListArchivedProjects returns archived projects, which ListProjects omits.
+func (s *SQLiteStore) ListArchivedProjects(ctx context.Context) ([]*models.Project, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, name, key, path, description, repo_url, language, languages, group_name, branch_count, has_github_pages, pages_url, build_cmd, serve_cmd, serve_port, conventions, max_in_progress, max_active_sessions, wip_hard_block, commit_style, commit_ticket_prefix, refresh_skip, sparse_paths, merge_strategy, cascade_rules, github_board, ui_review_paths, archived, created_at, updated_at
+		FROM projects WHERE archived = 1 ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("list archived projects: %w", err)
+	}
+	return scanProjects(rows)
+}
+
+func scanProjects(rows *sql.Rows) ([]*models.Project, error) {
 	defer func() { _ = rows.Close() }()
 
 	var projects []*models.Project
 	for rows.Next() {
 		p := &models.Project{}
-		if err := rows.Scan(&p.ID, &p.Name, &p.Path, &p.Description, &p.RepoURL, &p.Language, &p.GroupName, &p.BranchCount, &p.HasGitHubPages, &p.PagesURL, &p.BuildCmd, &p.ServeCmd, &p.ServePort, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		if err := rows.Scan(&p.ID, &p.Name, &p.Key, &p.Path, &p.Description, &p.RepoURL, &p.Language, languagesColumn{&p.Languages}, &p.GroupName, &p.BranchCount, &p.HasGitHubPages, &p.PagesURL, &p.BuildCmd, &p.ServeCmd, &p.ServePort, &p.Conventions, &p.MaxInProgress, &p.MaxActiveSessions, &p.WIPHardBlock, &p.CommitStyle, &p.CommitTicketPrefix, &p.RefreshSkip, &p.SparsePaths, &p.MergeStrategy, &p.Cascade, &p.GitHubBoard, &p.UIReviewPaths, &p.Archived, &p.CreatedAt, &p.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan project: %w", err)
 		}
 		projects = append(projects, p)
@@ -236,13 +323,44 @@ func (s *SQLiteStore) ListProjects(ctx context.Context, group string) ([]*models
 	return projects, rows.Err()
 }
 
+// languagesJSON encodes a project's language breakdown; none is stored as
+// the empty string.
+func languagesJSON(l []models.LanguageShare) string {
+	if len(l) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// languagesColumn scans the languages column into a breakdown.
+type languagesColumn struct{ dst *[]models.LanguageShare }
+
+func (c languagesColumn) Scan(src any) error {
+	*c.dst = nil
+	var data []byte
+	switch v := src.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, c.dst)
+}
+
 func (s *SQLiteStore) UpdateProject(ctx context.Context, p *models.Project) error {
 	p.UpdatedAt = time.Now().UTC()
-	result, err := s.db.ExecContext(ctx,
-		`UPDATE projects SET name=?, path=?, description=?, repo_url=?, language=?, group_name=?, branch_count=?, has_github_pages=?, pages_url=?, build_cmd=?, serve_cmd=?, serve_port=?, updated_at=?
+	result, err := s.exec(ctx,
+		`UPDATE projects SET name=?, path=?, description=?, repo_url=?, language=?, languages=?, group_name=?, branch_count=?, has_github_pages=?, pages_url=?, build_cmd=?, serve_cmd=?, serve_port=?, conventions=?, max_in_progress=?, max_active_sessions=?, wip_hard_block=?, commit_style=?, commit_ticket_prefix=?, refresh_skip=?, sparse_paths=?, merge_strategy=?, cascade_rules=?, github_board=?, ui_review_paths=?, archived=?, updated_at=?
 		WHERE id=?`,
-		p.Name, p.Path, p.Description, p.RepoURL, p.Language, p.GroupName,
-		p.BranchCount, boolToInt(p.HasGitHubPages), p.PagesURL, p.BuildCmd, p.ServeCmd, p.ServePort, p.UpdatedAt, p.ID,
+		p.Name, p.Path, p.Description, p.RepoURL, p.Language, languagesJSON(p.Languages), p.GroupName,
+		p.BranchCount, boolToInt(p.HasGitHubPages), p.PagesURL, p.BuildCmd, p.ServeCmd, p.ServePort, p.Conventions, p.MaxInProgress, p.MaxActiveSessions, boolToInt(p.WIPHardBlock), p.CommitStyle, p.CommitTicketPrefix, p.RefreshSkip, p.SparsePaths, p.MergeStrategy, p.Cascade, p.GitHubBoard, p.UIReviewPaths, boolToInt(p.Archived), p.UpdatedAt, p.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("update project: %w", err)
@@ -255,7 +373,7 @@ func (s *SQLiteStore) UpdateProject(ctx context.Context, p *models.Project) erro
 }
 
 func (s *SQLiteStore) DeleteProject(ctx context.Context, id string) error {
-	result, err := s.db.ExecContext(ctx, "DELETE FROM projects WHERE id = ?", id)
+	result, err := s.exec(ctx, "DELETE FROM projects WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("delete project: %w", err)
 	}
@@ -269,37 +387,55 @@ func (s *SQLiteStore) DeleteProject(ctx context.Context, id string) error {
 // --- Issues ---
 
 func (s *SQLiteStore) CreateIssue(ctx context.Context, issue *models.Issue) error {
+	setIssueDefaults(issue)
+	if err := validateIssue("create issue", issue); err != nil {
+		return err
+	}
 	if issue.ID == "" {
 		issue.ID = newULID()
 	}
 	now := time.Now().UTC()
-	issue.CreatedAt = now
-	issue.UpdatedAt = now
+	issue.CreatedAt = createdTime(ctx, issue.CreatedAt, now)
+	issue.UpdatedAt = createdTime(ctx, issue.UpdatedAt, now)
+	code, err := s.nextShortCode(ctx, issue.ProjectID, codeKindIssue)
+	if err != nil {
+		return fmt.Errorf("create issue: %w", err)
+	}
+	issue.Code = code
 
-	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO issues (id, project_id, title, description, body, ai_prompt, status, priority, type, github_issue, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		issue.ID, issue.ProjectID, issue.Title, issue.Description, issue.Body, issue.AIPrompt,
+	_, err = s.exec(ctx,
+		`INSERT INTO issues (id, code, project_id, title, description, body, ai_prompt, status, priority, type, github_issue, assignee, estimate, rank, acceptance_criteria, redactions, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		issue.ID, issue.Code, issue.ProjectID, issue.Title, issue.Description, s.packText(issue.Body), s.packText(issue.AIPrompt),
 		string(issue.Status), string(issue.Priority), string(issue.Type),
-		issue.GitHubIssue, issue.CreatedAt, issue.UpdatedAt,
+		issue.GitHubIssue, issue.Assignee, issue.Estimate, issue.Rank, criteriaJSON(issue.AcceptanceCriteria), redactionsJSON(issue.Redactions), issue.CreatedAt, issue.UpdatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("create issue: %w", err)
 	}
-	return nil
+	if importing(ctx) {
+		// Replace the cycle timestamps the insert trigger derived, and keep
+		// where the issue was merged
+		if _, err := s.exec(ctx, `UPDATE issues SET closed_at = ?, started_at = ?, completed_at = ?, merge_commit = ?, merge_branch = ?, merged_at = ? WHERE id = ?`,
+			issue.ClosedAt, issue.StartedAt, issue.CompletedAt, issue.MergeCommit, issue.MergeBranch, issue.MergedAt, issue.ID); err != nil {
+			return fmt.Errorf("create issue: %w", err)
+		}
+	}
+	return s.loadIssueTimestamps(ctx, issue)
 }
 
 func (s *SQLiteStore) GetIssue(ctx context.Context, id string) (*models.Issue, error) {
 	issue := &models.Issue{}
-	var status, priority, issueType string
-	var closedAt sql.NullTime
+	var status, priority, issueType, criteria, slaState, redactions string
+	var closedAt, startedAt, completedAt, mergedAt sql.NullTime
 
 	err := s.db.QueryRowContext(ctx,
-		`SELECT id, project_id, title, description, body, ai_prompt, status, priority, type, github_issue, created_at, updated_at, closed_at
+		`SELECT id, code, project_id, title, description, body, ai_prompt, status, priority, type, github_issue, assignee, estimate, rank, acceptance_criteria, sla_state, redactions, created_at, updated_at, closed_at, started_at, completed_at, merge_commit, merge_branch, merged_at
 		FROM issues WHERE id = ?`, id,
-	).Scan(&issue.ID, &issue.ProjectID, &issue.Title, &issue.Description, &issue.Body, &issue.AIPrompt,
+	).Scan(&issue.ID, &issue.Code, &issue.ProjectID, &issue.Title, &issue.Description, textColumn{&issue.Body}, textColumn{&issue.AIPrompt},
 		&status, &priority, &issueType,
-		&issue.GitHubIssue, &issue.CreatedAt, &issue.UpdatedAt, &closedAt)
+		&issue.GitHubIssue, &issue.Assignee, &issue.Estimate, &issue.Rank, &criteria, &slaState, &redactions, &issue.CreatedAt, &issue.UpdatedAt, &closedAt, &startedAt, &completedAt,
+		&issue.MergeCommit, &issue.MergeBranch, &mergedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("issue not found: %s", id)
@@ -311,9 +447,16 @@ func (s *SQLiteStore) GetIssue(ctx context.Context, id string) (*models.Issue, e
 	issue.Status = models.IssueStatus(status)
 	issue.Priority = models.IssuePriority(priority)
 	issue.Type = models.IssueType(issueType)
+	issue.SLAState = models.SLAState(slaState)
+	_ = json.Unmarshal([]byte(redactions), &issue.Redactions)
+	_ = json.Unmarshal([]byte(criteria), &issue.AcceptanceCriteria)
 	if closedAt.Valid {
 		issue.ClosedAt = &closedAt.Time
 	}
+	setIssueTimestamps(issue, startedAt, completedAt)
+	if mergedAt.Valid {
+		issue.MergedAt = &mergedAt.Time
+	}
 
 	// Load tags
 	tags, err := s.GetIssueTags(ctx, issue.ID)
@@ -328,7 +471,7 @@ func (s *SQLiteStore) GetIssue(ctx context.Context, id string) (*models.Issue, e
 }
 
 func (s *SQLiteStore) ListIssues(ctx context.Context, filter IssueListFilter) ([]*models.Issue, error) {
-	query := `SELECT id, project_id, title, description, body, ai_prompt, status, priority, type, github_issue, created_at, updated_at, closed_at FROM issues`
+	query := `SELECT id, code, project_id, title, description, body, ai_prompt, status, priority, type, github_issue, assignee, estimate, rank, acceptance_criteria, sla_state, redactions, created_at, updated_at, closed_at, started_at, completed_at, merge_commit, merge_branch, merged_at FROM issues`
 	var conditions []string
 	var args []any
 
@@ -348,6 +491,10 @@ func (s *SQLiteStore) ListIssues(ctx context.Context, filter IssueListFilter) ([
 		conditions = append(conditions, "type = ?")
 		args = append(args, string(filter.Type))
 	}
+	if filter.Assignee != "" {
+		conditions = append(conditions, "assignee = ?")
+		args = append(args, filter.Assignee)
+	}
 	if filter.Tag != "" {
 		conditions = append(conditions, "id IN (SELECT issue_id FROM issue_tags JOIN tags ON tags.id = issue_tags.tag_id WHERE tags.name = ?)")
 		args = append(args, filter.Tag)
@@ -357,7 +504,8 @@ func (s *SQLiteStore) ListIssues(ctx context.Context, filter IssueListFilter) ([
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
 	query += ` ORDER BY
-		CASE status WHEN 'open' THEN 0 WHEN 'in_progress' THEN 1 WHEN 'done' THEN 2 WHEN 'closed' THEN 3 ELSE 4 END,
+		CASE status WHEN 'open' THEN 0 WHEN 'in_progress' THEN 1 WHEN 'in_review' THEN 2 WHEN 'done' THEN 3 WHEN 'closed' THEN 4 ELSE 5 END,
+		rank = '', rank,
 		CASE priority WHEN 'high' THEN 0 WHEN 'medium' THEN 1 WHEN 'low' THEN 2 ELSE 3 END,
 		created_at DESC`
 
@@ -370,35 +518,87 @@ func (s *SQLiteStore) ListIssues(ctx context.Context, filter IssueListFilter) ([
 	var issues []*models.Issue
 	for rows.Next() {
 		issue := &models.Issue{}
-		var status, priority, issueType string
-		var closedAt sql.NullTime
+		var status, priority, issueType, criteria, slaState, redactions string
+		var closedAt, startedAt, completedAt, mergedAt sql.NullTime
 
-		if err := rows.Scan(&issue.ID, &issue.ProjectID, &issue.Title, &issue.Description, &issue.Body, &issue.AIPrompt,
+		if err := rows.Scan(&issue.ID, &issue.Code, &issue.ProjectID, &issue.Title, &issue.Description, textColumn{&issue.Body}, textColumn{&issue.AIPrompt},
 			&status, &priority, &issueType,
-			&issue.GitHubIssue, &issue.CreatedAt, &issue.UpdatedAt, &closedAt); err != nil {
+			&issue.GitHubIssue, &issue.Assignee, &issue.Estimate, &issue.Rank, &criteria, &slaState, &redactions, &issue.CreatedAt, &issue.UpdatedAt, &closedAt, &startedAt, &completedAt,
+			&issue.MergeCommit, &issue.MergeBranch, &mergedAt); err != nil {
 			return nil, fmt.Errorf("scan issue: %w", err)
 		}
 
 		issue.Status = models.IssueStatus(status)
 		issue.Priority = models.IssuePriority(priority)
 		issue.Type = models.IssueType(issueType)
+		issue.SLAState = models.SLAState(slaState)
+		_ = json.Unmarshal([]byte(redactions), &issue.Redactions)
+		_ = json.Unmarshal([]byte(criteria), &issue.AcceptanceCriteria)
 		if closedAt.Valid {
 			issue.ClosedAt = &closedAt.Time
 		}
+		setIssueTimestamps(issue, startedAt, completedAt)
+		if mergedAt.Valid {
+			issue.MergedAt = &mergedAt.Time
+		}
 
 		issues = append(issues, issue)
 	}
-	return issues, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if filter.Sort == IssueSortSmart {
+		blocked, err := s.blockedIssueIDs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		sortIssuesSmart(issues, blocked, time.Now())
+	}
+	return issues, nil
+}
+
+// blockedIssueIDs returns the IDs of issues an unfinished issue blocks.
+func (s *SQLiteStore) blockedIssueIDs(ctx context.Context) (map[string]bool, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT DISTINCT r.target_issue_id FROM issue_relations r
+		JOIN issues b ON b.id = r.source_issue_id
+		WHERE r.type = ? AND b.status NOT IN ('done', 'closed')`, string(models.RelationBlocks))
+	if err != nil {
+		return nil, fmt.Errorf("list blocked issues: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	blocked := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan blocked issue: %w", err)
+		}
+		blocked[id] = true
+	}
+	return blocked, rows.Err()
 }
 
 func (s *SQLiteStore) UpdateIssue(ctx context.Context, issue *models.Issue) error {
+	if err := s.updateIssue(ctx, s.db, issue); err != nil {
+		return err
+	}
+	s.notifyWrite()
+	return s.loadIssueTimestamps(ctx, issue)
+}
+
+// updateIssue writes issue through q, the database or a write's transaction.
+func (s *SQLiteStore) updateIssue(ctx context.Context, q execer, issue *models.Issue) error {
+	if err := validateIssue("update issue", issue); err != nil {
+		return err
+	}
 	issue.UpdatedAt = time.Now().UTC()
-	result, err := s.db.ExecContext(ctx,
-		`UPDATE issues SET title=?, description=?, body=?, ai_prompt=?, status=?, priority=?, type=?, github_issue=?, updated_at=?, closed_at=?
-		WHERE id=?`,
-		issue.Title, issue.Description, issue.Body, issue.AIPrompt, string(issue.Status), string(issue.Priority), string(issue.Type),
-		issue.GitHubIssue, issue.UpdatedAt, issue.ClosedAt, issue.ID,
-	)
+	args := utcArgs([]any{
+		issue.Title, issue.Description, s.packText(issue.Body), s.packText(issue.AIPrompt), string(issue.Status), string(issue.Status), string(issue.Priority), string(issue.Type),
+		issue.GitHubIssue, issue.Assignee, issue.Estimate, criteriaJSON(issue.AcceptanceCriteria), redactionsJSON(issue.Redactions), issue.UpdatedAt, issue.ClosedAt, issue.ID,
+	})
+	result, err := q.ExecContext(ctx,
+		`UPDATE issues SET title=?, description=?, body=?, ai_prompt=?, status=?, rank=CASE WHEN status = ? THEN rank ELSE '' END, priority=?, type=?, github_issue=?, assignee=?, estimate=?, acceptance_criteria=?, redactions=?, updated_at=?, closed_at=?
+		WHERE id=?`, args...)
 	if err != nil {
 		return fmt.Errorf("update issue: %w", err)
 	}
@@ -409,8 +609,84 @@ func (s *SQLiteStore) UpdateIssue(ctx context.Context, issue *models.Issue) erro
 	return nil
 }
 
+// RecordIssueMerge stamps an issue with where its code landed: the base
+// branch tip after a session merge, the base branch, and when.
+func (s *SQLiteStore) RecordIssueMerge(ctx context.Context, issueID, commit, branch string, at time.Time) error {
+	result, err := s.exec(ctx, `UPDATE issues SET merge_commit = ?, merge_branch = ?, merged_at = ?, updated_at = ? WHERE id = ?`,
+		commit, branch, at, time.Now().UTC(), issueID)
+	if err != nil {
+		return fmt.Errorf("record issue merge: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("issue not found: %s", issueID)
+	}
+	return nil
+}
+
+// loadIssueTimestamps refreshes the started/completed times the status
+// triggers maintain, and the rank a status change clears, so callers see them
+// right after a write.
+func (s *SQLiteStore) loadIssueTimestamps(ctx context.Context, issue *models.Issue) error {
+	var startedAt, completedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `SELECT started_at, completed_at, rank FROM issues WHERE id = ?`, issue.ID).
+		Scan(&startedAt, &completedAt, &issue.Rank)
+	if err != nil {
+		return fmt.Errorf("load issue timestamps: %w", err)
+	}
+	setIssueTimestamps(issue, startedAt, completedAt)
+	return nil
+}
+
+func setIssueTimestamps(issue *models.Issue, startedAt, completedAt sql.NullTime) {
+	issue.StartedAt, issue.CompletedAt = nil, nil
+	if startedAt.Valid {
+		issue.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		issue.CompletedAt = &completedAt.Time
+	}
+}
+
+// redactionsJSON encodes an issue's redaction report; an empty report is
+// stored as an empty string.
+func redactionsJSON(r []models.Redaction) string {
+	if len(r) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// criteriaJSON encodes an issue's acceptance criteria; none are stored as an
+// empty string.
+func criteriaJSON(c []string) string {
+	if len(c) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// DeleteIssue deletes an issue and what cascades from it, and unlinks its
+// sessions.
 func (s *SQLiteStore) DeleteIssue(ctx context.Context, id string) error {
-	result, err := s.db.ExecContext(ctx, "DELETE FROM issues WHERE id = ?", id)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := detachIssueSessions(ctx, tx, "?", []any{id}); err != nil {
+		return fmt.Errorf("delete issue: %w", err)
+	}
+	result, err := tx.ExecContext(ctx, "DELETE FROM issues WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("delete issue: %w", err)
 	}
@@ -418,6 +694,29 @@ func (s *SQLiteStore) DeleteIssue(ctx context.Context, id string) error {
 	if n == 0 {
 		return fmt.Errorf("issue not found: %s", id)
 	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	s.notifyWrite()
+	return nil
+}
+
+// detachIssueSessions unlinks the sessions of the issues matched by the IN
+// list in, recording an issue_deleted event on each so its history still
+// names the issue.
+func detachIssueSessions(ctx context.Context, tx *sql.Tx, in string, args []any) error {
+	now := time.Now().UTC()
+	eventQuery := fmt.Sprintf(`INSERT INTO session_events (session_id, kind, value, detail, at)
+		SELECT s.id, ?, i.id, i.title, ? FROM agent_sessions s JOIN issues i ON i.id = s.issue_id
+		WHERE s.issue_id IN (%s) ORDER BY s.id`, in)
+	eventArgs := append([]any{string(models.SessionEventIssueDeleted), now}, args...)
+	if _, err := tx.ExecContext(ctx, eventQuery, utcArgs(eventArgs)...); err != nil {
+		return fmt.Errorf("record issue deletion: %w", err)
+	}
+	query := fmt.Sprintf("UPDATE agent_sessions SET issue_id = '', review_id = '' WHERE issue_id IN (%s)", in)
+	if _, err := tx.ExecContext(ctx, query, utcArgs(args)...); err != nil {
+		return fmt.Errorf("unlink sessions: %w", err)
+	}
 	return nil
 }
 
@@ -425,6 +724,9 @@ func (s *SQLiteStore) BulkUpdateIssueStatus(ctx context.Context, ids []string, s
 	if len(ids) == 0 {
 		return 0, nil
 	}
+	if !status.Valid() {
+		return 0, fmt.Errorf("bulk update issue status: invalid issue status %q", status)
+	}
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return 0, fmt.Errorf("begin tx: %w", err)
@@ -432,18 +734,18 @@ func (s *SQLiteStore) BulkUpdateIssueStatus(ctx context.Context, ids []string, s
 	defer func() { _ = tx.Rollback() }()
 
 	placeholders := make([]string, len(ids))
-	args := make([]any, 0, len(ids)+2)
-	args = append(args, string(status), time.Now().UTC())
+	args := make([]any, 0, len(ids)+3)
+	args = append(args, string(status), string(status), time.Now().UTC())
 	for i, id := range ids {
 		placeholders[i] = "?"
 		args = append(args, id)
 	}
 
 	query := fmt.Sprintf(
-		"UPDATE issues SET status=?, updated_at=? WHERE id IN (%s)",
+		"UPDATE issues SET status=?, rank=CASE WHEN status = ? THEN rank ELSE '' END, updated_at=? WHERE id IN (%s)",
 		strings.Join(placeholders, ","),
 	)
-	result, err := tx.ExecContext(ctx, query, args...)
+	result, err := tx.ExecContext(ctx, query, utcArgs(args)...)
 	if err != nil {
 		return 0, fmt.Errorf("bulk update issue status: %w", err)
 	}
@@ -451,10 +753,14 @@ func (s *SQLiteStore) BulkUpdateIssueStatus(ctx context.Context, ids []string, s
 	if err := tx.Commit(); err != nil {
 		return 0, fmt.Errorf("commit tx: %w", err)
 	}
+	s.notifyWrite()
 	return n, nil
 }
 
-func (s *SQLiteStore) BulkDeleteIssues(ctx context.Context, ids []string) (int64, error) {
+// BulkDeleteIssues deletes issues with their tags and reviews, and unlinks
+// their sessions. Unless force is set, nothing is deleted when an open session
+// works on one of them: ErrIssuesHaveOpenSessions names those issues.
+func (s *SQLiteStore) BulkDeleteIssues(ctx context.Context, ids []string, force bool) (int64, error) {
 	if len(ids) == 0 {
 		return 0, nil
 	}
@@ -470,15 +776,33 @@ func (s *SQLiteStore) BulkDeleteIssues(ctx context.Context, ids []string) (int64
 		placeholders[i] = "?"
 		args[i] = id
 	}
+	in := strings.Join(placeholders, ",")
+
+	if !force {
+		busy, err := issuesWithOpenSessions(ctx, tx, in, args)
+		if err != nil {
+			return 0, err
+		}
+		if len(busy) > 0 {
+			return 0, fmt.Errorf("bulk delete issues: %w: %s", ErrIssuesHaveOpenSessions, strings.Join(busy, ", "))
+		}
+	}
+	if err := detachIssueSessions(ctx, tx, in, args); err != nil {
+		return 0, fmt.Errorf("bulk delete issues: %w", err)
+	}
 
-	// Delete issue_tags first (foreign key)
-	tagQuery := fmt.Sprintf("DELETE FROM issue_tags WHERE issue_id IN (%s)", strings.Join(placeholders, ","))
-	if _, err := tx.ExecContext(ctx, tagQuery, args...); err != nil {
+	// Delete issue_tags and reviews first (foreign keys)
+	tagQuery := fmt.Sprintf("DELETE FROM issue_tags WHERE issue_id IN (%s)", in)
+	if _, err := tx.ExecContext(ctx, tagQuery, utcArgs(args)...); err != nil {
 		return 0, fmt.Errorf("bulk delete issue tags: %w", err)
 	}
+	reviewQuery := fmt.Sprintf("DELETE FROM issue_reviews WHERE issue_id IN (%s)", in)
+	if _, err := tx.ExecContext(ctx, reviewQuery, utcArgs(args)...); err != nil {
+		return 0, fmt.Errorf("bulk delete issue reviews: %w", err)
+	}
 
-	query := fmt.Sprintf("DELETE FROM issues WHERE id IN (%s)", strings.Join(placeholders, ","))
-	result, err := tx.ExecContext(ctx, query, args...)
+	query := fmt.Sprintf("DELETE FROM issues WHERE id IN (%s)", in)
+	result, err := tx.ExecContext(ctx, query, utcArgs(args)...)
 	if err != nil {
 		return 0, fmt.Errorf("bulk delete issues: %w", err)
 	}
@@ -486,9 +810,31 @@ func (s *SQLiteStore) BulkDeleteIssues(ctx context.Context, ids []string) (int64
 	if err := tx.Commit(); err != nil {
 		return 0, fmt.Errorf("commit tx: %w", err)
 	}
+	s.notifyWrite()
 	return n, nil
 }
 
+// issuesWithOpenSessions returns the IDs, among the IN list in, of issues
+// that open sessions work on.
+func issuesWithOpenSessions(ctx context.Context, tx *sql.Tx, in string, args []any) ([]string, error) {
+	query := fmt.Sprintf(`SELECT DISTINCT issue_id FROM agent_sessions
+		WHERE issue_id IN (%s) AND status IN ('active', 'idle', 'blocked') ORDER BY issue_id`, in)
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("find open sessions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 // --- Tags ---
 
 func (s *SQLiteStore) CreateTag(ctx context.Context, tag *models.Tag) error {
@@ -497,7 +843,7 @@ func (s *SQLiteStore) CreateTag(ctx context.Context, tag *models.Tag) error {
 	}
 	tag.CreatedAt = time.Now().UTC()
 
-	_, err := s.db.ExecContext(ctx,
+	_, err := s.exec(ctx,
 		`INSERT INTO tags (id, name, created_at) VALUES (?, ?, ?)`,
 		tag.ID, tag.Name, tag.CreatedAt,
 	)
@@ -526,7 +872,7 @@ func (s *SQLiteStore) ListTags(ctx context.Context) ([]*models.Tag, error) {
 }
 
 func (s *SQLiteStore) DeleteTag(ctx context.Context, id string) error {
-	result, err := s.db.ExecContext(ctx, "DELETE FROM tags WHERE id = ?", id)
+	result, err := s.exec(ctx, "DELETE FROM tags WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("delete tag: %w", err)
 	}
@@ -537,8 +883,126 @@ func (s *SQLiteStore) DeleteTag(ctx context.Context, id string) error {
 	return nil
 }
 
+// ListTagUsage returns every tag, ordered by name, with how many issues carry it.
+func (s *SQLiteStore) ListTagUsage(ctx context.Context) ([]*models.TagUsage, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT t.id, t.name, t.created_at, COUNT(i.id),
+			COALESCE(SUM(CASE WHEN i.id IS NULL OR i.status IN ('done', 'closed') THEN 0 ELSE 1 END), 0)
+		FROM tags t
+		LEFT JOIN issue_tags it ON it.tag_id = t.id
+		LEFT JOIN issues i ON i.id = it.issue_id
+		GROUP BY t.id ORDER BY t.name`)
+	if err != nil {
+		return nil, fmt.Errorf("list tag usage: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var usage []*models.TagUsage
+	for rows.Next() {
+		u := &models.TagUsage{}
+		if err := rows.Scan(&u.ID, &u.Name, &u.CreatedAt, &u.Issues, &u.Open); err != nil {
+			return nil, fmt.Errorf("scan tag usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
+
+func (s *SQLiteStore) RenameTag(ctx context.Context, id, name string) error {
+	result, err := s.exec(ctx, "UPDATE tags SET name = ? WHERE id = ?", name, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return fmt.Errorf("rename tag: a tag named %q already exists", name)
+		}
+		return fmt.Errorf("rename tag: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("tag not found: %s", id)
+	}
+	return nil
+}
+
+// MergeTag moves the issues tagged id over to targetID and deletes tag id.
+func (s *SQLiteStore) MergeTag(ctx context.Context, id, targetID string) error {
+	if id == targetID {
+		return fmt.Errorf("merge tag: cannot merge a tag into itself")
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, tagID := range []string{id, targetID} {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM tags WHERE id = ?)", tagID).Scan(&exists); err != nil {
+			return fmt.Errorf("merge tag: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("tag not found: %s", tagID)
+		}
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT OR IGNORE INTO issue_tags (issue_id, tag_id) SELECT issue_id, ? FROM issue_tags WHERE tag_id = ?`,
+		targetID, id); err != nil {
+		return fmt.Errorf("merge tag: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tags WHERE id = ?", id); err != nil {
+		return fmt.Errorf("merge tag: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	s.notifyWrite()
+	return nil
+}
+
+// DeleteUnusedTags deletes the tags no issue carries and returns them.
+func (s *SQLiteStore) DeleteUnusedTags(ctx context.Context) ([]*models.Tag, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, name, created_at FROM tags
+		WHERE id NOT IN (SELECT tag_id FROM issue_tags) ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("list unused tags: %w", err)
+	}
+	var tags []*models.Tag
+	for rows.Next() {
+		t := &models.Tag{}
+		if err := rows.Scan(&t.ID, &t.Name, &t.CreatedAt); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+		tags = append(tags, t)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	for _, t := range tags {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM tags WHERE id = ?", t.ID); err != nil {
+			return nil, fmt.Errorf("delete tag %s: %w", t.Name, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	s.notifyWrite()
+	return tags, nil
+}
+
 func (s *SQLiteStore) TagIssue(ctx context.Context, issueID, tagID string) error {
-	_, err := s.db.ExecContext(ctx,
+	_, err := s.exec(ctx,
 		"INSERT OR IGNORE INTO issue_tags (issue_id, tag_id) VALUES (?, ?)", issueID, tagID)
 	if err != nil {
 		return fmt.Errorf("tag issue: %w", err)
@@ -547,7 +1011,7 @@ func (s *SQLiteStore) TagIssue(ctx context.Context, issueID, tagID string) error
 }
 
 func (s *SQLiteStore) UntagIssue(ctx context.Context, issueID, tagID string) error {
-	_, err := s.db.ExecContext(ctx,
+	_, err := s.exec(ctx,
 		"DELETE FROM issue_tags WHERE issue_id = ? AND tag_id = ?", issueID, tagID)
 	if err != nil {
 		return fmt.Errorf("untag issue: %w", err)
@@ -582,47 +1046,161 @@ func (s *SQLiteStore) CreateAgentSession(ctx context.Context, session *models.Ag
 	if session.ID == "" {
 		session.ID = newULID()
 	}
-	session.StartedAt = time.Now().UTC()
+	session.StartedAt = createdTime(ctx, session.StartedAt, time.Now().UTC())
 	if session.ConflictState == "" {
 		session.ConflictState = models.ConflictStateNone
 	}
 	if session.ConflictFiles == "" {
 		session.ConflictFiles = "[]"
 	}
+	session.ExtraIssueIDs = extraIssueIDs(session)
+	code, err := s.nextShortCode(ctx, session.ProjectID, codeKindSession)
+	if err != nil {
+		return fmt.Errorf("create agent session: %w", err)
+	}
+	session.Code = code
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
 
-	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO agent_sessions (id, project_id, issue_id, branch, worktree_path, status, outcome, commit_count, last_commit_hash, last_commit_message, last_active_at, started_at, last_error, last_sync_at, conflict_state, conflict_files, discovered)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		session.ID, session.ProjectID, session.IssueID, session.Branch,
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO agent_sessions (id, code, project_id, issue_id, branch, worktree_path, status, outcome, commit_count, last_commit_hash, last_commit_message, last_active_at, started_at, last_error, last_sync_at, conflict_state, conflict_files, discovered, review_attempt, review_id, worktree_fingerprint, terminal_backend, terminal_id, blocked_reason, unblock_condition, blocked_at, inline, sparse_paths, merge_commit, labels, outcome_class, focus_seconds)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		session.ID, session.Code, session.ProjectID, session.IssueID, session.Branch,
 		session.WorktreePath, string(session.Status), session.Outcome,
 		session.CommitCount, session.LastCommitHash, session.LastCommitMessage,
 		session.LastActiveAt, session.StartedAt,
 		session.LastError, session.LastSyncAt, string(session.ConflictState),
 		session.ConflictFiles, session.Discovered,
+		session.ReviewAttempt, session.ReviewID, session.WorktreeFingerprint,
+		session.TerminalBackend, session.TerminalID,
+		session.BlockedReason, session.UnblockCondition, session.BlockedAt,
+		session.Inline, session.SparsePaths, session.MergeCommit, session.Labels,
+		string(session.OutcomeClass), session.FocusSeconds,
 	)
 	if err != nil {
-		return fmt.Errorf("create agent session: %w", err)
+		return fmt.Errorf("create agent session: %w", sessionConflict(ctx, tx, session, err))
+	}
+	// The IssueID row is added by the agent_session_issues_insert trigger
+	for _, issueID := range strings.Split(session.ExtraIssueIDs, ",") {
+		if issueID == "" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO agent_session_issues (session_id, issue_id, active) VALUES (?, ?, ?)`,
+			session.ID, issueID, session.Status == models.SessionStatusActive); err != nil {
+			return fmt.Errorf("create agent session: %w", sessionConflict(ctx, tx, session, err))
+		}
 	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	s.notifyWrite()
 	return nil
 }
 
-func (s *SQLiteStore) GetAgentSession(ctx context.Context, id string) (*models.AgentSession, error) {
-	session := &models.AgentSession{}
-	var status, conflictState string
-	var endedAt, lastActiveAt, lastSyncAt sql.NullTime
+// branchReservedBy is ErrBranchReserved naming holder, the open session that
+// holds branch.
+func branchReservedBy(branch string, holder *models.AgentSession) error {
+	return fmt.Errorf("%w: %s is held by %s session %s", ErrBranchReserved, branch, holder.Status, holder.ID)
+}
 
-	err := s.db.QueryRowContext(ctx,
-		`SELECT id, project_id, issue_id, branch, worktree_path, status, outcome, commit_count, last_commit_hash, last_commit_message, last_active_at, started_at, ended_at, last_error, last_sync_at, conflict_state, conflict_files, discovered
-		FROM agent_sessions WHERE id = ?`, id,
-	).Scan(&session.ID, &session.ProjectID, &session.IssueID,
-		&session.Branch, &session.WorktreePath, &status,
-		&session.Outcome, &session.CommitCount,
-		&session.LastCommitHash, &session.LastCommitMessage, &lastActiveAt,
-		&session.StartedAt, &endedAt,
-		&session.LastError, &lastSyncAt, &conflictState,
-		&session.ConflictFiles, &session.Discovered)
-	if err != nil {
-		return nil, fmt.Errorf("agent session not found: %s", id)
+// sessionConflict turns the unique index violation of a session write that
+// would take a reserved branch or a locked issue into ErrBranchReserved or
+// ErrIssueLocked, naming the holder. Other errors are returned as they are.
+func sessionConflict(ctx context.Context, q queryRower, session *models.AgentSession, err error) error {
+	if strings.Contains(err.Error(), "agent_session_issues.issue_id") {
+		return issueConflict(ctx, q, session)
+	}
+	return branchConflict(ctx, q, session, err)
+}
+
+// execer runs statements: *sql.DB or a write's *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// queryRower runs single-row queries: *sql.DB or a write's *sql.Tx.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// issueLockedBy is ErrIssueLocked naming holder, the active session that
+// holds issueID.
+func issueLockedBy(issueID string, holder *models.AgentSession) error {
+	return fmt.Errorf("%w: %s is held by active session %s", ErrIssueLocked, issueID, holder.ID)
+}
+
+// issueConflict returns ErrIssueLocked for a write that would make session
+// active on an issue another active session holds, looking up the holder
+// through q.
+func issueConflict(ctx context.Context, q queryRower, session *models.AgentSession) error {
+	for _, issueID := range session.IssueIDs() {
+		holder := &models.AgentSession{}
+		if q.QueryRowContext(ctx,
+			`SELECT session_id FROM agent_session_issues WHERE issue_id = ? AND session_id != ? AND active = 1`,
+			issueID, session.ID).Scan(&holder.ID) == nil {
+			return issueLockedBy(issueID, holder)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrIssueLocked, strings.Join(session.IssueIDs(), ", "))
+}
+
+// branchConflict turns the unique index violation of a write that would open
+// session on a reserved branch into ErrBranchReserved, looking up the holder
+// through q (the write's transaction, if any). Other errors are returned as
+// they are.
+func branchConflict(ctx context.Context, q queryRower, session *models.AgentSession, err error) error {
+	if !strings.Contains(err.Error(), "agent_sessions.project_id, agent_sessions.branch") {
+		return err
+	}
+	holder := &models.AgentSession{}
+	if q.QueryRowContext(ctx,
+		`SELECT id, status FROM agent_sessions WHERE project_id = ? AND branch = ? AND id != ? AND status IN ('active', 'idle', 'blocked')`,
+		session.ProjectID, session.Branch, session.ID).Scan(&holder.ID, &holder.Status) != nil {
+		return fmt.Errorf("%w: %s", ErrBranchReserved, session.Branch)
+	}
+	return branchReservedBy(session.Branch, holder)
+}
+
+// sessionExtraIssueIDs selects a session's ExtraIssueIDs from
+// agent_session_issues.
+const sessionExtraIssueIDs = `COALESCE((SELECT group_concat(i.issue_id, ',' ORDER BY i.rowid) FROM agent_session_issues i
+		WHERE i.session_id = agent_sessions.id AND i.issue_id != agent_sessions.issue_id), '')`
+
+// extraIssueIDs returns session's ExtraIssueIDs as stored: without repeats
+// or its IssueID.
+func extraIssueIDs(session *models.AgentSession) string {
+	ids := session.IssueIDs()
+	if session.IssueID != "" {
+		ids = ids[1:]
+	}
+	return strings.Join(ids, ",")
+}
+
+func (s *SQLiteStore) GetAgentSession(ctx context.Context, id string) (*models.AgentSession, error) {
+	session := &models.AgentSession{}
+	var status, conflictState string
+	var endedAt, lastActiveAt, lastSyncAt, blockedAt sql.NullTime
+
+	err := s.db.QueryRowContext(ctx,
+		`SELECT id, code, project_id, issue_id, branch, worktree_path, status, outcome, commit_count, last_commit_hash, last_commit_message, last_active_at, started_at, ended_at, last_error, last_sync_at, conflict_state, conflict_files, discovered, review_attempt, review_id, worktree_fingerprint, terminal_backend, terminal_id, blocked_reason, unblock_condition, blocked_at, inline, sparse_paths, merge_commit, labels, outcome_class, focus_seconds, `+sessionExtraIssueIDs+`
+		FROM agent_sessions WHERE id = ?`, id,
+	).Scan(&session.ID, &session.Code, &session.ProjectID, &session.IssueID,
+		&session.Branch, &session.WorktreePath, &status,
+		&session.Outcome, &session.CommitCount,
+		&session.LastCommitHash, &session.LastCommitMessage, &lastActiveAt,
+		&session.StartedAt, &endedAt,
+		&session.LastError, &lastSyncAt, &conflictState,
+		&session.ConflictFiles, &session.Discovered,
+		&session.ReviewAttempt, &session.ReviewID, &session.WorktreeFingerprint,
+		&session.TerminalBackend, &session.TerminalID,
+		&session.BlockedReason, &session.UnblockCondition, &blockedAt, &session.Inline, &session.SparsePaths, &session.MergeCommit, &session.Labels, &session.OutcomeClass, &session.FocusSeconds, &session.ExtraIssueIDs)
+	if err != nil {
+		return nil, fmt.Errorf("agent session not found: %s", id)
 	}
 
 	session.Status = models.SessionStatus(status)
@@ -636,27 +1214,33 @@ func (s *SQLiteStore) GetAgentSession(ctx context.Context, id string) (*models.A
 	if lastSyncAt.Valid {
 		session.LastSyncAt = &lastSyncAt.Time
 	}
+	if blockedAt.Valid {
+		session.BlockedAt = &blockedAt.Time
+	}
 	return session, nil
 }
 
 func (s *SQLiteStore) GetAgentSessionByWorktreePath(ctx context.Context, path string) (*models.AgentSession, error) {
 	session := &models.AgentSession{}
 	var status, conflictState string
-	var endedAt, lastActiveAt, lastSyncAt sql.NullTime
+	var endedAt, lastActiveAt, lastSyncAt, blockedAt sql.NullTime
 
 	err := s.db.QueryRowContext(ctx,
-		`SELECT id, project_id, issue_id, branch, worktree_path, status, outcome, commit_count, last_commit_hash, last_commit_message, last_active_at, started_at, ended_at, last_error, last_sync_at, conflict_state, conflict_files, discovered
-		FROM agent_sessions WHERE worktree_path = ? AND status IN ('active', 'idle')
+		`SELECT id, code, project_id, issue_id, branch, worktree_path, status, outcome, commit_count, last_commit_hash, last_commit_message, last_active_at, started_at, ended_at, last_error, last_sync_at, conflict_state, conflict_files, discovered, review_attempt, review_id, worktree_fingerprint, terminal_backend, terminal_id, blocked_reason, unblock_condition, blocked_at, inline, sparse_paths, merge_commit, labels, outcome_class, focus_seconds, `+sessionExtraIssueIDs+`
+		FROM agent_sessions WHERE worktree_path = ? AND status IN ('active', 'idle', 'blocked')
 		ORDER BY started_at DESC LIMIT 1`, path,
-	).Scan(&session.ID, &session.ProjectID, &session.IssueID,
+	).Scan(&session.ID, &session.Code, &session.ProjectID, &session.IssueID,
 		&session.Branch, &session.WorktreePath, &status,
 		&session.Outcome, &session.CommitCount,
 		&session.LastCommitHash, &session.LastCommitMessage, &lastActiveAt,
 		&session.StartedAt, &endedAt,
 		&session.LastError, &lastSyncAt, &conflictState,
-		&session.ConflictFiles, &session.Discovered)
+		&session.ConflictFiles, &session.Discovered,
+		&session.ReviewAttempt, &session.ReviewID, &session.WorktreeFingerprint,
+		&session.TerminalBackend, &session.TerminalID,
+		&session.BlockedReason, &session.UnblockCondition, &blockedAt, &session.Inline, &session.SparsePaths, &session.MergeCommit, &session.Labels, &session.OutcomeClass, &session.FocusSeconds, &session.ExtraIssueIDs)
 	if err != nil {
-		return nil, fmt.Errorf("no active/idle session for worktree: %s", path)
+		return nil, fmt.Errorf("no open session for worktree: %s", path)
 	}
 
 	session.Status = models.SessionStatus(status)
@@ -670,11 +1254,14 @@ func (s *SQLiteStore) GetAgentSessionByWorktreePath(ctx context.Context, path st
 	if lastSyncAt.Valid {
 		session.LastSyncAt = &lastSyncAt.Time
 	}
+	if blockedAt.Valid {
+		session.BlockedAt = &blockedAt.Time
+	}
 	return session, nil
 }
 
 func (s *SQLiteStore) ListAgentSessions(ctx context.Context, projectID string, limit int) ([]*models.AgentSession, error) {
-	query := `SELECT id, project_id, issue_id, branch, worktree_path, status, outcome, commit_count, last_commit_hash, last_commit_message, last_active_at, started_at, ended_at, last_error, last_sync_at, conflict_state, conflict_files, discovered
+	query := `SELECT id, code, project_id, issue_id, branch, worktree_path, status, outcome, commit_count, last_commit_hash, last_commit_message, last_active_at, started_at, ended_at, last_error, last_sync_at, conflict_state, conflict_files, discovered, review_attempt, review_id, worktree_fingerprint, terminal_backend, terminal_id, blocked_reason, unblock_condition, blocked_at, inline, sparse_paths, merge_commit, labels, outcome_class, focus_seconds, ` + sessionExtraIssueIDs + `
 		FROM agent_sessions`
 	var args []any
 
@@ -692,7 +1279,7 @@ func (s *SQLiteStore) ListAgentSessions(ctx context.Context, projectID string, l
 }
 
 func (s *SQLiteStore) ListAgentSessionsByStatus(ctx context.Context, projectID string, statuses []models.SessionStatus, limit int) ([]*models.AgentSession, error) {
-	query := `SELECT id, project_id, issue_id, branch, worktree_path, status, outcome, commit_count, last_commit_hash, last_commit_message, last_active_at, started_at, ended_at, last_error, last_sync_at, conflict_state, conflict_files, discovered
+	query := `SELECT id, code, project_id, issue_id, branch, worktree_path, status, outcome, commit_count, last_commit_hash, last_commit_message, last_active_at, started_at, ended_at, last_error, last_sync_at, conflict_state, conflict_files, discovered, review_attempt, review_id, worktree_fingerprint, terminal_backend, terminal_id, blocked_reason, unblock_condition, blocked_at, inline, sparse_paths, merge_commit, labels, outcome_class, focus_seconds, ` + sessionExtraIssueIDs + `
 		FROM agent_sessions WHERE 1=1`
 	var args []any
 
@@ -720,6 +1307,44 @@ func (s *SQLiteStore) ListAgentSessionsByStatus(ctx context.Context, projectID s
 	return s.scanAgentSessions(ctx, query, args...)
 }
 
+// ListAgentSessionsFiltered returns the sessions matching filter, newest first.
+func (s *SQLiteStore) ListAgentSessionsFiltered(ctx context.Context, filter SessionListFilter) ([]*models.AgentSession, error) {
+	query := `SELECT id, code, project_id, issue_id, branch, worktree_path, status, outcome, commit_count, last_commit_hash, last_commit_message, last_active_at, started_at, ended_at, last_error, last_sync_at, conflict_state, conflict_files, discovered, review_attempt, review_id, worktree_fingerprint, terminal_backend, terminal_id, blocked_reason, unblock_condition, blocked_at, inline, sparse_paths, merge_commit, labels, outcome_class, focus_seconds, ` + sessionExtraIssueIDs + `
+		FROM agent_sessions WHERE 1=1`
+	var args []any
+
+	if filter.ProjectID != "" {
+		query += " AND project_id = ?"
+		args = append(args, filter.ProjectID)
+	}
+	if filter.IssueID != "" {
+		query += " AND issue_id = ?"
+		args = append(args, filter.IssueID)
+	}
+	if filter.Branch != "" {
+		query += " AND branch = ?"
+		args = append(args, filter.Branch)
+	}
+	if len(filter.Statuses) > 0 {
+		query += " AND status IN (?" + strings.Repeat(", ?", len(filter.Statuses)-1) + ")"
+		for _, st := range filter.Statuses {
+			args = append(args, string(st))
+		}
+	}
+	if filter.Label != "" {
+		// labels are stored without spaces, so ",label," matches whole labels only
+		query += " AND instr(',' || labels || ',', ?) > 0"
+		args = append(args, ","+filter.Label+",")
+	}
+	query += " ORDER BY started_at DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	return s.scanAgentSessions(ctx, query, args...)
+}
+
 func (s *SQLiteStore) ListAgentSessionsByWorktreePaths(ctx context.Context, paths []string) ([]*models.AgentSession, error) {
 	if len(paths) == 0 {
 		return nil, nil
@@ -735,7 +1360,7 @@ func (s *SQLiteStore) ListAgentSessionsByWorktreePaths(ctx context.Context, path
 		args = append(args, p)
 	}
 
-	query := `SELECT id, project_id, issue_id, branch, worktree_path, status, outcome, commit_count, last_commit_hash, last_commit_message, last_active_at, started_at, ended_at, last_error, last_sync_at, conflict_state, conflict_files, discovered
+	query := `SELECT id, code, project_id, issue_id, branch, worktree_path, status, outcome, commit_count, last_commit_hash, last_commit_message, last_active_at, started_at, ended_at, last_error, last_sync_at, conflict_state, conflict_files, discovered, review_attempt, review_id, worktree_fingerprint, terminal_backend, terminal_id, blocked_reason, unblock_condition, blocked_at, inline, sparse_paths, merge_commit, labels, outcome_class, focus_seconds, ` + sessionExtraIssueIDs + `
 		FROM agent_sessions WHERE worktree_path IN (` + placeholders + `) ORDER BY started_at DESC`
 
 	return s.scanAgentSessions(ctx, query, args...)
@@ -753,15 +1378,18 @@ func (s *SQLiteStore) scanAgentSessions(ctx context.Context, query string, args
 	for rows.Next() {
 		session := &models.AgentSession{}
 		var status, conflictState string
-		var endedAt, lastActiveAt, lastSyncAt sql.NullTime
+		var endedAt, lastActiveAt, lastSyncAt, blockedAt sql.NullTime
 
-		if err := rows.Scan(&session.ID, &session.ProjectID, &session.IssueID,
+		if err := rows.Scan(&session.ID, &session.Code, &session.ProjectID, &session.IssueID,
 			&session.Branch, &session.WorktreePath, &status,
 			&session.Outcome, &session.CommitCount,
 			&session.LastCommitHash, &session.LastCommitMessage, &lastActiveAt,
 			&session.StartedAt, &endedAt,
 			&session.LastError, &lastSyncAt, &conflictState,
-			&session.ConflictFiles, &session.Discovered); err != nil {
+			&session.ConflictFiles, &session.Discovered,
+			&session.ReviewAttempt, &session.ReviewID, &session.WorktreeFingerprint,
+			&session.TerminalBackend, &session.TerminalID,
+			&session.BlockedReason, &session.UnblockCondition, &blockedAt, &session.Inline, &session.SparsePaths, &session.MergeCommit, &session.Labels, &session.OutcomeClass, &session.FocusSeconds, &session.ExtraIssueIDs); err != nil {
 			return nil, fmt.Errorf("scan agent session: %w", err)
 		}
 
@@ -776,24 +1404,36 @@ func (s *SQLiteStore) scanAgentSessions(ctx context.Context, query string, args
 		if lastSyncAt.Valid {
 			session.LastSyncAt = &lastSyncAt.Time
 		}
+		if blockedAt.Valid {
+			session.BlockedAt = &blockedAt.Time
+		}
 		sessions = append(sessions, session)
 	}
 	return sessions, rows.Err()
 }
 
-func (s *SQLiteStore) UpdateAgentSession(ctx context.Context, session *models.AgentSession) error {
-	result, err := s.db.ExecContext(ctx,
-		`UPDATE agent_sessions SET status=?, outcome=?, commit_count=?, last_commit_hash=?, last_commit_message=?, last_active_at=?, ended_at=?, last_error=?, last_sync_at=?, conflict_state=?, conflict_files=?, discovered=?, worktree_path=? WHERE id=?`,
+// updateSessionSQL writes a session's mutable fields; sessionUpdateArgs
+// supplies its arguments.
+const updateSessionSQL = `UPDATE agent_sessions SET status=?, outcome=?, commit_count=?, last_commit_hash=?, last_commit_message=?, last_active_at=?, ended_at=?, last_error=?, last_sync_at=?, conflict_state=?, conflict_files=?, discovered=?, worktree_path=?, worktree_fingerprint=?, terminal_backend=?, terminal_id=?, blocked_reason=?, unblock_condition=?, blocked_at=?, merge_commit=?, labels=?, outcome_class=? WHERE id=?`
+
+func sessionUpdateArgs(session *models.AgentSession) []any {
+	return []any{
 		string(session.Status), session.Outcome, session.CommitCount,
 		session.LastCommitHash, session.LastCommitMessage, session.LastActiveAt,
 		session.EndedAt,
 		session.LastError, session.LastSyncAt, string(session.ConflictState),
 		session.ConflictFiles, session.Discovered,
-		session.WorktreePath,
-		session.ID,
-	)
+		session.WorktreePath, session.WorktreeFingerprint,
+		session.TerminalBackend, session.TerminalID,
+		session.BlockedReason, session.UnblockCondition, session.BlockedAt,
+		session.MergeCommit, session.Labels, string(session.OutcomeClass), session.ID,
+	}
+}
+
+func (s *SQLiteStore) UpdateAgentSession(ctx context.Context, session *models.AgentSession) error {
+	result, err := s.exec(ctx, updateSessionSQL, sessionUpdateArgs(session)...)
 	if err != nil {
-		return fmt.Errorf("update agent session: %w", err)
+		return fmt.Errorf("update agent session: %w", sessionConflict(ctx, s.db, session, err))
 	}
 	n, _ := result.RowsAffected()
 	if n == 0 {
@@ -802,67 +1442,219 @@ func (s *SQLiteStore) UpdateAgentSession(ctx context.Context, session *models.Ag
 	return nil
 }
 
-func (s *SQLiteStore) DeleteStaleSessions(ctx context.Context, projectID, branch string) (int64, error) {
-	res, err := s.db.ExecContext(ctx,
-		`DELETE FROM agent_sessions
-		WHERE project_id = ? AND branch = ?
-		AND status = 'abandoned' AND commit_count = 0
-		AND ended_at IS NOT NULL
-		AND (julianday(substr(ended_at, 1, 19)) - julianday(substr(started_at, 1, 19))) * 86400 < 60`,
-		projectID, branch,
-	)
+// TransitionAgentSession writes a session like UpdateAgentSession, but only if
+// the stored session's status is still from; otherwise it returns
+// ErrSessionStatusChanged and writes nothing. The status event of the change
+// records cause.
+func (s *SQLiteStore) TransitionAgentSession(ctx context.Context, session *models.AgentSession, from models.SessionStatus, cause string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx, updateSessionSQL+" AND status=?", utcArgs(append(sessionUpdateArgs(session), string(from)))...)
+	if err != nil {
+		return fmt.Errorf("transition agent session: %w", sessionConflict(ctx, tx, session, err))
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		var current string
+		if err := tx.QueryRowContext(ctx, "SELECT status FROM agent_sessions WHERE id = ?", session.ID).Scan(&current); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("agent session not found: %s", session.ID)
+			}
+			return fmt.Errorf("transition agent session: %w", err)
+		}
+		return fmt.Errorf("%w: session %s is %s, not %s", ErrSessionStatusChanged, session.ID, current, from)
+	}
+	if session.Status != from {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE session_events SET cause = ? WHERE id = (
+				SELECT MAX(id) FROM session_events WHERE session_id = ? AND kind = 'status')`,
+			cause, session.ID); err != nil {
+			return fmt.Errorf("record transition cause: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	s.notifyWrite()
+	return nil
+}
+
+// TouchAgentSession sets an open session's LastActiveAt to at and adds the
+// focus time since its last activity, reporting false if the session is
+// missing or has ended. It writes no other column, so it cannot undo a
+// concurrent update of the session.
+func (s *SQLiteStore) TouchAgentSession(ctx context.Context, id string, at time.Time) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var last sql.NullTime
+	err = tx.QueryRowContext(ctx,
+		`SELECT last_active_at FROM agent_sessions WHERE id = ? AND status IN ('active', 'idle', 'blocked')`, id).Scan(&last)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("touch agent session: %w", err)
+	}
+	var prev *time.Time
+	if last.Valid {
+		prev = &last.Time
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE agent_sessions SET last_active_at = ?, focus_seconds = focus_seconds + ? WHERE id = ?`,
+		at.UTC(), models.FocusAfter(prev, at).Seconds(), id); err != nil {
+		return false, fmt.Errorf("touch agent session: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit tx: %w", err)
+	}
+	s.notifyWrite()
+	return true, nil
+}
+
+// DeleteStaleSessions removes the sessions matching criteria, which must be
+// valid.
+func (s *SQLiteStore) DeleteStaleSessions(ctx context.Context, criteria StaleSessionCriteria) (int64, error) {
+	if err := criteria.Validate(); err != nil {
+		return 0, fmt.Errorf("delete stale sessions: %w", err)
+	}
+	where, args := staleSessionWhere(criteria)
+	res, err := s.exec(ctx, "DELETE FROM agent_sessions WHERE "+where, args...)
 	if err != nil {
 		return 0, fmt.Errorf("delete stale sessions: %w", err)
 	}
 	return res.RowsAffected()
 }
 
-// DeleteAllStaleSessions removes all abandoned sessions with 0 commits and duration < 60s.
+// DeleteAllStaleSessions removes all sessions matching the default stale criteria
+// (abandoned, 0 commits, duration < 60s).
 func (s *SQLiteStore) DeleteAllStaleSessions(ctx context.Context) (int64, error) {
-	res, err := s.db.ExecContext(ctx,
-		`DELETE FROM agent_sessions
-		WHERE status = 'abandoned' AND commit_count = 0
-		AND ended_at IS NOT NULL
-		AND (julianday(substr(ended_at, 1, 19)) - julianday(substr(started_at, 1, 19))) * 86400 < 60`,
-	)
+	where, args := staleSessionWhere(DefaultStaleSessionCriteria())
+	res, err := s.exec(ctx, "DELETE FROM agent_sessions WHERE "+where, args...)
 	if err != nil {
 		return 0, fmt.Errorf("delete all stale sessions: %w", err)
 	}
 	return res.RowsAffected()
 }
 
+// ListStaleSessions returns ended sessions matching the given stale criteria.
+func (s *SQLiteStore) ListStaleSessions(ctx context.Context, criteria StaleSessionCriteria) ([]*models.AgentSession, error) {
+	where, args := staleSessionWhere(criteria)
+	query := `SELECT id, code, project_id, issue_id, branch, worktree_path, status, outcome, commit_count, last_commit_hash, last_commit_message, last_active_at, started_at, ended_at, last_error, last_sync_at, conflict_state, conflict_files, discovered, review_attempt, review_id, worktree_fingerprint, terminal_backend, terminal_id, blocked_reason, unblock_condition, blocked_at, inline, sparse_paths, merge_commit, labels, outcome_class, focus_seconds, ` + sessionExtraIssueIDs + `
+		FROM agent_sessions WHERE ` + where + ` ORDER BY started_at DESC`
+	return s.scanAgentSessions(ctx, query, args...)
+}
+
+// DeleteStaleSessionsByID removes the sessions with the given IDs that still
+// match criteria, so a session that changed since it was listed (say, it was
+// reopened) is kept.
+func (s *SQLiteStore) DeleteStaleSessionsByID(ctx context.Context, criteria StaleSessionCriteria, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	where, args := staleSessionWhere(criteria)
+	placeholders := make([]string, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+	query := fmt.Sprintf("DELETE FROM agent_sessions WHERE %s AND id IN (%s)", where, strings.Join(placeholders, ","))
+	res, err := s.exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("delete stale sessions: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// staleSessionWhere builds the WHERE clause (without the keyword) selecting stale sessions.
+func staleSessionWhere(c StaleSessionCriteria) (string, []any) {
+	statuses := c.Statuses
+	if len(statuses) == 0 {
+		statuses = []models.SessionStatus{models.SessionStatusAbandoned}
+	}
+
+	placeholders := make([]string, len(statuses))
+	var args []any
+	for i, st := range statuses {
+		placeholders[i] = "?"
+		args = append(args, string(st))
+	}
+
+	conditions := []string{
+		"status IN (" + strings.Join(placeholders, ", ") + ")",
+		"commit_count <= ?",
+		"ended_at IS NOT NULL",
+	}
+	args = append(args, c.MaxCommits)
+
+	if c.ProjectID != "" {
+		conditions = append(conditions, "project_id = ?")
+		args = append(args, c.ProjectID)
+	}
+	if c.Branch != "" {
+		conditions = append(conditions, "branch = ?")
+		args = append(args, c.Branch)
+	}
+	if c.MaxDuration > 0 {
+		conditions = append(conditions, "(julianday(substr(ended_at, 1, 19)) - julianday(substr(started_at, 1, 19))) * 86400 < ?")
+		args = append(args, c.MaxDuration.Seconds())
+	}
+	if c.MinAge > 0 {
+		conditions = append(conditions, "(julianday('now') - julianday(substr(ended_at, 1, 19))) * 86400 >= ?")
+		args = append(args, c.MinAge.Seconds())
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
 // --- Issue Reviews ---
 
 func (s *SQLiteStore) CreateIssueReview(ctx context.Context, review *models.IssueReview) error {
+	query, args := s.reviewInsert(ctx, review)
+	if _, err := s.exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("create issue review: %w", err)
+	}
+	return nil
+}
+
+// reviewInsert assigns a new review its ID and creation time and returns the
+// statement inserting it.
+func (s *SQLiteStore) reviewInsert(ctx context.Context, review *models.IssueReview) (string, []any) {
 	if review.ID == "" {
 		review.ID = newULID()
 	}
-	review.CreatedAt = time.Now().UTC()
+	review.CreatedAt = createdTime(ctx, review.CreatedAt, time.Now().UTC())
 
 	failureJSON, err := json.Marshal(review.FailureReasons)
 	if err != nil {
 		failureJSON = []byte("[]")
 	}
-
-	_, err = s.db.ExecContext(ctx,
-		`INSERT INTO issue_reviews (id, issue_id, session_id, verdict, summary, code_quality, requirements_match, test_coverage, ui_ux, failure_reasons, diff_stats, reviewed_at, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		review.ID, review.IssueID, review.SessionID,
-		string(review.Verdict), review.Summary,
-		string(review.CodeQuality), string(review.RequirementsMatch),
-		string(review.TestCoverage), string(review.UIUX),
-		string(failureJSON), review.DiffStats,
-		review.ReviewedAt, review.CreatedAt,
-	)
-	if err != nil {
-		return fmt.Errorf("create issue review: %w", err)
+	resultsJSON, err := json.Marshal(review.Criteria)
+	if err != nil || review.Criteria == nil {
+		resultsJSON = []byte("[]")
 	}
-	return nil
+
+	return `INSERT INTO issue_reviews (id, issue_id, session_id, reviewer, verdict, summary, code_quality, requirements_match, test_coverage, ui_ux, failure_reasons, criteria, diff_stats, reviewed_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		[]any{
+			review.ID, review.IssueID, review.SessionID, review.Reviewer,
+			string(review.Verdict), s.packText(review.Summary),
+			string(review.CodeQuality), string(review.RequirementsMatch),
+			string(review.TestCoverage), string(review.UIUX),
+			string(failureJSON), string(resultsJSON), review.DiffStats,
+			review.ReviewedAt, review.CreatedAt,
+		}
 }
 
 func (s *SQLiteStore) ListIssueReviews(ctx context.Context, issueID string) ([]*models.IssueReview, error) {
 	rows, err := s.db.QueryContext(ctx,
-		`SELECT id, issue_id, session_id, verdict, summary, code_quality, requirements_match, test_coverage, ui_ux, failure_reasons, diff_stats, reviewed_at, created_at
+		`SELECT id, issue_id, session_id, reviewer, verdict, summary, code_quality, requirements_match, test_coverage, ui_ux, failure_reasons, criteria, diff_stats, reviewed_at, created_at
 		FROM issue_reviews WHERE issue_id = ? ORDER BY reviewed_at DESC`, issueID)
 	if err != nil {
 		return nil, fmt.Errorf("list issue reviews: %w", err)
@@ -872,17 +1664,1200 @@ func (s *SQLiteStore) ListIssueReviews(ctx context.Context, issueID string) ([]*
 	var reviews []*models.IssueReview
 	for rows.Next() {
 		r := &models.IssueReview{}
-		var failureJSON string
-		if err := rows.Scan(&r.ID, &r.IssueID, &r.SessionID,
-			&r.Verdict, &r.Summary,
+		var failureJSON, resultsJSON string
+		if err := rows.Scan(&r.ID, &r.IssueID, &r.SessionID, &r.Reviewer,
+			&r.Verdict, textColumn{&r.Summary},
 			&r.CodeQuality, &r.RequirementsMatch,
 			&r.TestCoverage, &r.UIUX,
-			&failureJSON, &r.DiffStats,
+			&failureJSON, &resultsJSON, &r.DiffStats,
 			&r.ReviewedAt, &r.CreatedAt); err != nil {
 			return nil, fmt.Errorf("scan issue review: %w", err)
 		}
 		_ = json.Unmarshal([]byte(failureJSON), &r.FailureReasons)
+		_ = json.Unmarshal([]byte(resultsJSON), &r.Criteria)
 		reviews = append(reviews, r)
 	}
 	return reviews, rows.Err()
 }
+
+// --- Review Shares ---
+
+const reviewShareColumns = `id, issue_id, reviewer, token_hash, expires_at, review_id, created_at`
+
+func scanReviewShare(row interface{ Scan(...any) error }) (*models.ReviewShare, error) {
+	rs := &models.ReviewShare{}
+	err := row.Scan(&rs.ID, &rs.IssueID, &rs.Reviewer, &rs.TokenHash, &rs.ExpiresAt, &rs.ReviewID, &rs.CreatedAt)
+	return rs, err
+}
+
+func (s *SQLiteStore) CreateReviewShare(ctx context.Context, share *models.ReviewShare) error {
+	if share.ID == "" {
+		share.ID = newULID()
+	}
+	share.CreatedAt = time.Now().UTC()
+	share.ExpiresAt = share.ExpiresAt.UTC()
+
+	_, err := s.exec(ctx,
+		`INSERT INTO review_shares (`+reviewShareColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		share.ID, share.IssueID, share.Reviewer, share.TokenHash, share.ExpiresAt, share.ReviewID, share.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create review share: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) GetReviewShareByHash(ctx context.Context, hash string) (*models.ReviewShare, error) {
+	rs, err := scanReviewShare(s.db.QueryRowContext(ctx,
+		`SELECT `+reviewShareColumns+` FROM review_shares WHERE token_hash = ?`, hash))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("review share not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get review share: %w", err)
+	}
+	return rs, nil
+}
+
+// ListReviewShares lists an issue's review links, newest first.
+func (s *SQLiteStore) ListReviewShares(ctx context.Context, issueID string) ([]*models.ReviewShare, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+reviewShareColumns+` FROM review_shares WHERE issue_id = ? ORDER BY created_at DESC, id DESC`, issueID)
+	if err != nil {
+		return nil, fmt.Errorf("list review shares: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var shares []*models.ReviewShare
+	for rows.Next() {
+		rs, err := scanReviewShare(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan review share: %w", err)
+		}
+		shares = append(shares, rs)
+	}
+	return shares, rows.Err()
+}
+
+func (s *SQLiteStore) DeleteReviewShare(ctx context.Context, id string) error {
+	result, err := s.exec(ctx, "DELETE FROM review_shares WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("delete review share: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("review share not found: %s", id)
+	}
+	return nil
+}
+
+// SubmitReviewShare records review as the verdict of a review link, unless
+// the link already has one (ErrReviewShareUsed), in which case nothing is
+// written.
+func (s *SQLiteStore) SubmitReviewShare(ctx context.Context, shareID string, review *models.IssueReview) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query, args := s.reviewInsert(ctx, review)
+	if _, err := tx.ExecContext(ctx, query, utcArgs(args)...); err != nil {
+		return fmt.Errorf("create issue review: %w", err)
+	}
+	result, err := tx.ExecContext(ctx, `UPDATE review_shares SET review_id = ? WHERE id = ? AND review_id = ''`, review.ID, shareID)
+	if err != nil {
+		return fmt.Errorf("submit review share: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		var reviewID string
+		if err := tx.QueryRowContext(ctx, "SELECT review_id FROM review_shares WHERE id = ?", shareID).Scan(&reviewID); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("review share not found: %s", shareID)
+			}
+			return fmt.Errorf("submit review share: %w", err)
+		}
+		return fmt.Errorf("%w: review %s", ErrReviewShareUsed, reviewID)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	s.notifyWrite()
+	return nil
+}
+
+func (s *SQLiteStore) CreateIssueRelation(ctx context.Context, rel *models.IssueRelation) error {
+	if rel.SourceIssueID == rel.TargetIssueID {
+		return fmt.Errorf("create issue relation: an issue cannot relate to itself")
+	}
+	if rel.ID == "" {
+		rel.ID = newULID()
+	}
+	rel.CreatedAt = createdTime(ctx, rel.CreatedAt, time.Now().UTC())
+
+	_, err := s.exec(ctx,
+		`INSERT INTO issue_relations (id, source_issue_id, target_issue_id, type, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		rel.ID, rel.SourceIssueID, rel.TargetIssueID, string(rel.Type), rel.CreatedAt,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return fmt.Errorf("create issue relation: relation already exists")
+		}
+		return fmt.Errorf("create issue relation: %w", err)
+	}
+	return nil
+}
+
+// MergeIssues writes merge in one transaction.
+func (s *SQLiteStore) MergeIssues(ctx context.Context, merge *IssueMerge) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	issues := append([]*models.Issue{merge.Target}, merge.Duplicates...)
+	for _, issue := range issues {
+		if err := s.updateIssue(ctx, tx, issue); err != nil {
+			return fmt.Errorf("merge issues: %w", err)
+		}
+	}
+	for _, tagID := range merge.TagIDs {
+		if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO issue_tags (issue_id, tag_id) VALUES (?, ?)", merge.Target.ID, tagID); err != nil {
+			return fmt.Errorf("merge issues: tag issue: %w", err)
+		}
+	}
+	for _, w := range merge.Watchers {
+		if w.ID == "" {
+			w.ID = newULID()
+		}
+		w.CreatedAt = time.Now().UTC()
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO issue_watchers (id, issue_id, kind, target, created_at) VALUES (?, ?, ?, ?, ?)`,
+			w.ID, w.IssueID, string(w.Kind), w.Target, w.CreatedAt); err != nil {
+			return fmt.Errorf("merge issues: add issue watcher: %w", err)
+		}
+	}
+	for _, rel := range merge.Relations {
+		if rel.SourceIssueID == rel.TargetIssueID {
+			return fmt.Errorf("merge issues: an issue cannot relate to itself")
+		}
+		if rel.ID == "" {
+			rel.ID = newULID()
+		}
+		rel.CreatedAt = time.Now().UTC()
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO issue_relations (id, source_issue_id, target_issue_id, type, created_at) VALUES (?, ?, ?, ?, ?)`,
+			rel.ID, rel.SourceIssueID, rel.TargetIssueID, string(rel.Type), rel.CreatedAt); err != nil {
+			return fmt.Errorf("merge issues: create issue relation: %w", err)
+		}
+	}
+	for _, c := range merge.Comments {
+		if c.ID == "" {
+			c.ID = newULID()
+		}
+		c.CreatedAt = time.Now().UTC()
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO issue_comments (id, issue_id, author, body, created_at) VALUES (?, ?, ?, ?, ?)`,
+			c.ID, c.IssueID, c.Author, c.Body, c.CreatedAt); err != nil {
+			return fmt.Errorf("merge issues: create issue comment: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	s.notifyWrite()
+	for _, issue := range issues {
+		if err := s.loadIssueTimestamps(ctx, issue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListIssueRelations returns relations where the issue is either the source or the target.
+func (s *SQLiteStore) ListIssueRelations(ctx context.Context, issueID string) ([]*models.IssueRelation, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, source_issue_id, target_issue_id, type, created_at
+		FROM issue_relations WHERE source_issue_id = ? OR target_issue_id = ? ORDER BY created_at`, issueID, issueID)
+	if err != nil {
+		return nil, fmt.Errorf("list issue relations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var rels []*models.IssueRelation
+	for rows.Next() {
+		r := &models.IssueRelation{}
+		var relType string
+		if err := rows.Scan(&r.ID, &r.SourceIssueID, &r.TargetIssueID, &relType, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan issue relation: %w", err)
+		}
+		r.Type = models.RelationType(relType)
+		rels = append(rels, r)
+	}
+	return rels, rows.Err()
+}
+
+func (s *SQLiteStore) DeleteIssueRelation(ctx context.Context, id string) error {
+	result, err := s.exec(ctx, "DELETE FROM issue_relations WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("delete issue relation: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("issue relation not found: %s", id)
+	}
+	return nil
+}
+
+// --- Issue Watchers and Comments ---
+
+// AddIssueWatcher subscribes w.Target to the issue and reports whether it was
+// added; a target already watching the issue is left as is.
+func (s *SQLiteStore) AddIssueWatcher(ctx context.Context, w *models.IssueWatcher) (bool, error) {
+	if w.ID == "" {
+		w.ID = newULID()
+	}
+	w.CreatedAt = time.Now().UTC()
+
+	result, err := s.exec(ctx,
+		`INSERT OR IGNORE INTO issue_watchers (id, issue_id, kind, target, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		w.ID, w.IssueID, string(w.Kind), w.Target, w.CreatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("add issue watcher: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// ListIssueWatchers returns an issue's watchers, oldest first. An empty
+// issueID lists the watchers of every issue.
+func (s *SQLiteStore) ListIssueWatchers(ctx context.Context, issueID string) ([]*models.IssueWatcher, error) {
+	query := `SELECT id, issue_id, kind, target, created_at FROM issue_watchers`
+	var args []any
+	if issueID != "" {
+		query += ` WHERE issue_id = ?`
+		args = append(args, issueID)
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY created_at, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list issue watchers: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var watchers []*models.IssueWatcher
+	for rows.Next() {
+		w := &models.IssueWatcher{}
+		if err := rows.Scan(&w.ID, &w.IssueID, &w.Kind, &w.Target, &w.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan issue watcher: %w", err)
+		}
+		watchers = append(watchers, w)
+	}
+	return watchers, rows.Err()
+}
+
+func (s *SQLiteStore) RemoveIssueWatcher(ctx context.Context, issueID, target string) error {
+	result, err := s.exec(ctx, "DELETE FROM issue_watchers WHERE issue_id = ? AND target = ?", issueID, target)
+	if err != nil {
+		return fmt.Errorf("remove issue watcher: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("issue watcher not found: %s", target)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) CreateIssueComment(ctx context.Context, c *models.IssueComment) error {
+	if c.ID == "" {
+		c.ID = newULID()
+	}
+	c.CreatedAt = createdTime(ctx, c.CreatedAt, time.Now().UTC())
+
+	_, err := s.exec(ctx,
+		`INSERT INTO issue_comments (id, issue_id, author, body, created_at) VALUES (?, ?, ?, ?, ?)`,
+		c.ID, c.IssueID, c.Author, c.Body, c.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create issue comment: %w", err)
+	}
+	return nil
+}
+
+// ListIssueComments returns an issue's comments, oldest first.
+func (s *SQLiteStore) ListIssueComments(ctx context.Context, issueID string) ([]*models.IssueComment, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, issue_id, author, body, created_at
+		FROM issue_comments WHERE issue_id = ? ORDER BY created_at, id`, issueID)
+	if err != nil {
+		return nil, fmt.Errorf("list issue comments: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var comments []*models.IssueComment
+	for rows.Next() {
+		c := &models.IssueComment{}
+		if err := rows.Scan(&c.ID, &c.IssueID, &c.Author, &c.Body, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan issue comment: %w", err)
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+// --- Issue Closures ---
+
+// RecordIssueClosure stores a closure unless the same reference already
+// closed the issue, and reports whether a new row was written.
+func (s *SQLiteStore) RecordIssueClosure(ctx context.Context, c *models.IssueClosure) (bool, error) {
+	if c.ID == "" {
+		c.ID = newULID()
+	}
+	c.CreatedAt = time.Now().UTC()
+
+	result, err := s.exec(ctx,
+		`INSERT OR IGNORE INTO issue_closures (id, issue_id, project_id, session_id, source, ref, message, from_status, to_status, github_issue, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.ID, c.IssueID, c.ProjectID, c.SessionID, c.Source, c.Ref, c.Message,
+		string(c.FromStatus), string(c.ToStatus), c.GitHubIssue, c.CreatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("record issue closure: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+func (s *SQLiteStore) GetIssueClosure(ctx context.Context, id string) (*models.IssueClosure, error) {
+	closures, err := s.scanIssueClosures(ctx,
+		`SELECT id, issue_id, project_id, session_id, source, ref, message, from_status, to_status, github_issue, created_at, reverted_at FROM issue_closures WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(closures) == 0 {
+		return nil, fmt.Errorf("issue closure not found: %s", id)
+	}
+	return closures[0], nil
+}
+
+// ListIssueClosures returns the closures of a project or an issue (either
+// may be empty), newest first.
+func (s *SQLiteStore) ListIssueClosures(ctx context.Context, projectID, issueID string) ([]*models.IssueClosure, error) {
+	query := `SELECT id, issue_id, project_id, session_id, source, ref, message, from_status, to_status, github_issue, created_at, reverted_at FROM issue_closures WHERE 1=1`
+	var args []any
+	if projectID != "" {
+		query += " AND project_id = ?"
+		args = append(args, projectID)
+	}
+	if issueID != "" {
+		query += " AND issue_id = ?"
+		args = append(args, issueID)
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+	return s.scanIssueClosures(ctx, query, args...)
+}
+
+func (s *SQLiteStore) scanIssueClosures(ctx context.Context, query string, args ...any) ([]*models.IssueClosure, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list issue closures: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var closures []*models.IssueClosure
+	for rows.Next() {
+		c := &models.IssueClosure{}
+		var from, to string
+		var revertedAt sql.NullTime
+		if err := rows.Scan(&c.ID, &c.IssueID, &c.ProjectID, &c.SessionID, &c.Source, &c.Ref, &c.Message,
+			&from, &to, &c.GitHubIssue, &c.CreatedAt, &revertedAt); err != nil {
+			return nil, fmt.Errorf("scan issue closure: %w", err)
+		}
+		c.FromStatus = models.IssueStatus(from)
+		c.ToStatus = models.IssueStatus(to)
+		if revertedAt.Valid {
+			c.RevertedAt = &revertedAt.Time
+		}
+		closures = append(closures, c)
+	}
+	return closures, rows.Err()
+}
+
+// RevertIssueClosure marks a closure reverted. The caller restores the issue.
+func (s *SQLiteStore) RevertIssueClosure(ctx context.Context, id string) error {
+	result, err := s.exec(ctx,
+		`UPDATE issue_closures SET reverted_at = ? WHERE id = ? AND reverted_at IS NULL`, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("revert issue closure: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("issue closure not found or already reverted: %s", id)
+	}
+	return nil
+}
+
+// RecordPRReviewImport records an imported pull request review. It returns
+// false, recording nothing, when the review was already imported for the issue.
+func (s *SQLiteStore) RecordPRReviewImport(ctx context.Context, r *models.PRReviewImport) (bool, error) {
+	if r.ID == "" {
+		r.ID = newULID()
+	}
+	r.CreatedAt = time.Now().UTC()
+
+	result, err := s.exec(ctx,
+		`INSERT OR IGNORE INTO pr_review_imports (id, issue_id, project_id, pr_number, review_id, reviewer, state, url, comment_id, follow_up_issue_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.ID, r.IssueID, r.ProjectID, r.PRNumber, r.ReviewID, r.Reviewer, r.State, r.URL,
+		r.CommentID, r.FollowUpIssueID, r.CreatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("record pull request review import: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// UpdatePRReviewImport records the comment and follow-up issue of an import.
+func (s *SQLiteStore) UpdatePRReviewImport(ctx context.Context, r *models.PRReviewImport) error {
+	result, err := s.exec(ctx,
+		`UPDATE pr_review_imports SET comment_id = ?, follow_up_issue_id = ? WHERE id = ?`,
+		r.CommentID, r.FollowUpIssueID, r.ID)
+	if err != nil {
+		return fmt.Errorf("update pull request review import: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("pull request review import not found: %s", r.ID)
+	}
+	return nil
+}
+
+// DeletePRReviewImport deletes an import so the review is imported again.
+func (s *SQLiteStore) DeletePRReviewImport(ctx context.Context, id string) error {
+	if _, err := s.exec(ctx, `DELETE FROM pr_review_imports WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete pull request review import: %w", err)
+	}
+	return nil
+}
+
+// ListPRReviewImports returns the review imports of a project or an issue
+// (either may be empty), newest first.
+func (s *SQLiteStore) ListPRReviewImports(ctx context.Context, projectID, issueID string) ([]*models.PRReviewImport, error) {
+	query := `SELECT id, issue_id, project_id, pr_number, review_id, reviewer, state, url, comment_id, follow_up_issue_id, created_at FROM pr_review_imports WHERE 1=1`
+	var args []any
+	if projectID != "" {
+		query += " AND project_id = ?"
+		args = append(args, projectID)
+	}
+	if issueID != "" {
+		query += " AND issue_id = ?"
+		args = append(args, issueID)
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list pull request review imports: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var imports []*models.PRReviewImport
+	for rows.Next() {
+		r := &models.PRReviewImport{}
+		if err := rows.Scan(&r.ID, &r.IssueID, &r.ProjectID, &r.PRNumber, &r.ReviewID, &r.Reviewer, &r.State, &r.URL,
+			&r.CommentID, &r.FollowUpIssueID, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan pull request review import: %w", err)
+		}
+		imports = append(imports, r)
+	}
+	return imports, rows.Err()
+}
+
+// SaveBoardItem records what an issue and its GitHub board item agree on
+// after a board sync, replacing what the last sync recorded.
+func (s *SQLiteStore) SaveBoardItem(ctx context.Context, item *models.BoardItem) error {
+	item.SyncedAt = time.Now().UTC()
+	_, err := s.exec(ctx,
+		`INSERT INTO github_board_items (issue_id, project_id, item_id, status, priority, board_status, board_priority, synced_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(issue_id) DO UPDATE SET project_id = excluded.project_id, item_id = excluded.item_id,
+			status = excluded.status, priority = excluded.priority, board_status = excluded.board_status,
+			board_priority = excluded.board_priority, synced_at = excluded.synced_at`,
+		item.IssueID, item.ProjectID, item.ItemID, item.Status, item.Priority, item.BoardStatus, item.BoardPriority, item.SyncedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("save board item: %w", err)
+	}
+	return nil
+}
+
+// ListBoardItems returns the board items recorded for a project's issues.
+func (s *SQLiteStore) ListBoardItems(ctx context.Context, projectID string) ([]*models.BoardItem, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT issue_id, project_id, item_id, status, priority, board_status, board_priority, synced_at
+		FROM github_board_items WHERE project_id = ? ORDER BY issue_id`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("list board items: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []*models.BoardItem
+	for rows.Next() {
+		item := &models.BoardItem{}
+		if err := rows.Scan(&item.IssueID, &item.ProjectID, &item.ItemID, &item.Status, &item.Priority,
+			&item.BoardStatus, &item.BoardPriority, &item.SyncedAt); err != nil {
+			return nil, fmt.Errorf("scan board item: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// SaveProjectRelease stores the result of fetching a project's latest
+// release, replacing the one stored before.
+func (s *SQLiteStore) SaveProjectRelease(ctx context.Context, r *models.ProjectRelease) error {
+	assets := ""
+	if len(r.Assets) > 0 {
+		data, err := json.Marshal(r.Assets)
+		if err != nil {
+			return fmt.Errorf("save project release: %w", err)
+		}
+		assets = string(data)
+	}
+	_, err := s.exec(ctx,
+		`INSERT INTO project_releases (project_id, tag_name, published_at, assets, fetched_at, checked_at, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(project_id) DO UPDATE SET tag_name = excluded.tag_name, published_at = excluded.published_at,
+			assets = excluded.assets, fetched_at = excluded.fetched_at, checked_at = excluded.checked_at, error = excluded.error`,
+		r.ProjectID, r.TagName, r.PublishedAt, assets, r.FetchedAt, r.CheckedAt, r.Error,
+	)
+	if err != nil {
+		return fmt.Errorf("save project release: %w", err)
+	}
+	return nil
+}
+
+// GetProjectRelease returns a project's stored release, or nil if it has
+// never been fetched.
+func (s *SQLiteStore) GetProjectRelease(ctx context.Context, projectID string) (*models.ProjectRelease, error) {
+	releases, err := s.listProjectReleases(ctx, `WHERE project_id = ?`, projectID)
+	if err != nil || len(releases) == 0 {
+		return nil, err
+	}
+	return releases[0], nil
+}
+
+// ListProjectReleases returns the stored releases of all projects.
+func (s *SQLiteStore) ListProjectReleases(ctx context.Context) ([]*models.ProjectRelease, error) {
+	return s.listProjectReleases(ctx, ``)
+}
+
+func (s *SQLiteStore) listProjectReleases(ctx context.Context, where string, args ...any) ([]*models.ProjectRelease, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT project_id, tag_name, published_at, assets, fetched_at, checked_at, error
+		FROM project_releases `+where+` ORDER BY project_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list project releases: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var releases []*models.ProjectRelease
+	for rows.Next() {
+		r := &models.ProjectRelease{}
+		var publishedAt, fetchedAt sql.NullTime
+		var assets string
+		if err := rows.Scan(&r.ProjectID, &r.TagName, &publishedAt, &assets, &fetchedAt, &r.CheckedAt, &r.Error); err != nil {
+			return nil, fmt.Errorf("scan project release: %w", err)
+		}
+		if publishedAt.Valid {
+			r.PublishedAt = &publishedAt.Time
+		}
+		if fetchedAt.Valid {
+			r.FetchedAt = &fetchedAt.Time
+		}
+		if assets != "" {
+			_ = json.Unmarshal([]byte(assets), &r.Assets)
+		}
+		releases = append(releases, r)
+	}
+	return releases, rows.Err()
+}
+
+// RecordLLMUsage records the tokens an LLM call spent.
+func (s *SQLiteStore) RecordLLMUsage(ctx context.Context, u *models.LLMUsage) error {
+	if u.ID == "" {
+		u.ID = newULID()
+	}
+	u.CreatedAt = createdTime(ctx, u.CreatedAt, time.Now().UTC())
+	_, err := s.exec(ctx,
+		`INSERT INTO llm_usage (id, project_id, issue_id, purpose, model, input_tokens, output_tokens, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		u.ID, u.ProjectID, u.IssueID, u.Purpose, u.Model, u.InputTokens, u.OutputTokens, u.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("record LLM usage: %w", err)
+	}
+	return nil
+}
+
+// ListLLMUsage returns the LLM usage of a project or an issue (either may be
+// empty), newest first.
+func (s *SQLiteStore) ListLLMUsage(ctx context.Context, projectID, issueID string) ([]*models.LLMUsage, error) {
+	query := `SELECT id, project_id, issue_id, purpose, model, input_tokens, output_tokens, created_at FROM llm_usage WHERE 1=1`
+	var args []any
+	if projectID != "" {
+		query += " AND project_id = ?"
+		args = append(args, projectID)
+	}
+	if issueID != "" {
+		query += " AND issue_id = ?"
+		args = append(args, issueID)
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list LLM usage: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var usage []*models.LLMUsage
+	for rows.Next() {
+		u := &models.LLMUsage{}
+		if err := rows.Scan(&u.ID, &u.ProjectID, &u.IssueID, &u.Purpose, &u.Model, &u.InputTokens, &u.OutputTokens, &u.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan LLM usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
+
+func (s *SQLiteStore) CreateProjectDependency(ctx context.Context, dep *models.ProjectDependency) error {
+	if dep.ProjectID == dep.DependsOnID {
+		return fmt.Errorf("create project dependency: a project cannot depend on itself")
+	}
+	if dep.ID == "" {
+		dep.ID = newULID()
+	}
+	dep.CreatedAt = time.Now().UTC()
+
+	_, err := s.exec(ctx,
+		`INSERT INTO project_dependencies (id, project_id, depends_on_id, created_at)
+		VALUES (?, ?, ?, ?)`,
+		dep.ID, dep.ProjectID, dep.DependsOnID, dep.CreatedAt,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return fmt.Errorf("create project dependency: dependency already exists")
+		}
+		return fmt.Errorf("create project dependency: %w", err)
+	}
+	return nil
+}
+
+// ListProjectDependencies returns every dependency edge between tracked projects.
+func (s *SQLiteStore) ListProjectDependencies(ctx context.Context) ([]*models.ProjectDependency, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, project_id, depends_on_id, created_at FROM project_dependencies ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("list project dependencies: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var deps []*models.ProjectDependency
+	for rows.Next() {
+		d := &models.ProjectDependency{}
+		if err := rows.Scan(&d.ID, &d.ProjectID, &d.DependsOnID, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan project dependency: %w", err)
+		}
+		deps = append(deps, d)
+	}
+	return deps, rows.Err()
+}
+
+func (s *SQLiteStore) DeleteProjectDependency(ctx context.Context, projectID, dependsOnID string) error {
+	result, err := s.exec(ctx,
+		"DELETE FROM project_dependencies WHERE project_id = ? AND depends_on_id = ?", projectID, dependsOnID)
+	if err != nil {
+		return fmt.Errorf("delete project dependency: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("project dependency not found")
+	}
+	return nil
+}
+
+// --- Health Snapshots ---
+
+func (s *SQLiteStore) CreateHealthSnapshot(ctx context.Context, snap *models.HealthSnapshot) error {
+	if snap.ID == "" {
+		snap.ID = newULID()
+	}
+	snap.CreatedAt = time.Now().UTC()
+
+	_, err := s.exec(ctx,
+		`INSERT INTO health_snapshots (id, project_id, total, git_cleanliness, activity_recency, issue_health,
+			release_freshness, branch_hygiene, is_dirty, branch_count, worktree_count, latest_release, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		snap.ID, snap.ProjectID, snap.Total, snap.GitCleanliness, snap.ActivityRecency, snap.IssueHealth,
+		snap.ReleaseFreshness, snap.BranchHygiene, snap.IsDirty, snap.BranchCount, snap.WorktreeCount,
+		snap.LatestRelease, snap.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create health snapshot: %w", err)
+	}
+	return nil
+}
+
+// ListHealthSnapshots returns a project's snapshots, newest first. A limit of
+// 0 returns all of them.
+func (s *SQLiteStore) ListHealthSnapshots(ctx context.Context, projectID string, limit int) ([]*models.HealthSnapshot, error) {
+	query := `SELECT id, project_id, total, git_cleanliness, activity_recency, issue_health,
+		release_freshness, branch_hygiene, is_dirty, branch_count, worktree_count, latest_release, created_at
+		FROM health_snapshots WHERE project_id = ? ORDER BY created_at DESC, id DESC`
+	args := []any{projectID}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list health snapshots: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var snaps []*models.HealthSnapshot
+	for rows.Next() {
+		h := &models.HealthSnapshot{}
+		if err := rows.Scan(&h.ID, &h.ProjectID, &h.Total, &h.GitCleanliness, &h.ActivityRecency, &h.IssueHealth,
+			&h.ReleaseFreshness, &h.BranchHygiene, &h.IsDirty, &h.BranchCount, &h.WorktreeCount,
+			&h.LatestRelease, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan health snapshot: %w", err)
+		}
+		snaps = append(snaps, h)
+	}
+	return snaps, rows.Err()
+}
+
+// SetIssueRanks sets the ranks of issues, by ID, in one transaction and
+// without touching updated_at, as reordering is not an edit of the issue.
+func (s *SQLiteStore) SetIssueRanks(ctx context.Context, ranks map[string]string) error {
+	for id, r := range ranks {
+		if r != "" && !lexorank.Valid(r) {
+			return fmt.Errorf("set issue ranks: invalid rank %q for issue %s", r, id)
+		}
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for id, r := range ranks {
+		result, err := tx.ExecContext(ctx, "UPDATE issues SET rank = ? WHERE id = ?", r, id)
+		if err != nil {
+			return fmt.Errorf("set issue rank: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return fmt.Errorf("issue not found: %s", id)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	s.notifyWrite()
+	return nil
+}
+
+// --- SLA ---
+
+// SetIssueSLAState records an issue's SLA state without touching updated_at,
+// so evaluation does not count as activity on the issue.
+func (s *SQLiteStore) SetIssueSLAState(ctx context.Context, issueID string, state models.SLAState) error {
+	result, err := s.exec(ctx, "UPDATE issues SET sla_state = ? WHERE id = ?", string(state), issueID)
+	if err != nil {
+		return fmt.Errorf("set issue sla state: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("issue not found: %s", issueID)
+	}
+	return nil
+}
+
+// RecordSLABreach stores a breach unless the issue already breached the same
+// policy deadline, and reports whether a new row was written.
+func (s *SQLiteStore) RecordSLABreach(ctx context.Context, b *models.SLABreach) (bool, error) {
+	if b.ID == "" {
+		b.ID = newULID()
+	}
+	if b.BreachedAt.IsZero() {
+		b.BreachedAt = time.Now().UTC()
+	}
+
+	result, err := s.exec(ctx,
+		`INSERT OR IGNORE INTO sla_breaches (id, issue_id, project_id, policy, kind, breached_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		b.ID, b.IssueID, b.ProjectID, b.Policy, string(b.Kind), b.BreachedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("record sla breach: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// ListSLABreaches returns breaches at or after since, newest first. A zero
+// since returns all of them.
+func (s *SQLiteStore) ListSLABreaches(ctx context.Context, since time.Time) ([]*models.SLABreach, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, issue_id, project_id, policy, kind, breached_at FROM sla_breaches
+		WHERE breached_at >= ? ORDER BY breached_at DESC`, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("list sla breaches: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var breaches []*models.SLABreach
+	for rows.Next() {
+		b := &models.SLABreach{}
+		var kind string
+		if err := rows.Scan(&b.ID, &b.IssueID, &b.ProjectID, &b.Policy, &kind, &b.BreachedAt); err != nil {
+			return nil, fmt.Errorf("scan sla breach: %w", err)
+		}
+		b.Kind = models.SLABreachKind(kind)
+		breaches = append(breaches, b)
+	}
+	return breaches, rows.Err()
+}
+
+// --- Issue Status History ---
+
+// ListIssueStatusEvents returns a project's issue status changes, oldest
+// first. Events are recorded by triggers on the issues table.
+func (s *SQLiteStore) ListIssueStatusEvents(ctx context.Context, projectID string) ([]*models.IssueStatusEvent, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, issue_id, project_id, from_status, to_status, changed_at FROM issue_status_events
+		WHERE project_id = ? ORDER BY changed_at, id`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("list issue status events: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var events []*models.IssueStatusEvent
+	for rows.Next() {
+		e := &models.IssueStatusEvent{}
+		var from, to string
+		if err := rows.Scan(&e.ID, &e.IssueID, &e.ProjectID, &from, &to, &e.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scan issue status event: %w", err)
+		}
+		e.FromStatus = models.IssueStatus(from)
+		e.ToStatus = models.IssueStatus(to)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// --- Agent Session History ---
+
+// ListSessionEvents returns a session's recorded changes in the order they
+// happened. Events are recorded by triggers on the agent_sessions table.
+func (s *SQLiteStore) ListSessionEvents(ctx context.Context, sessionID string) ([]*models.SessionEvent, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, session_id, kind, value, from_value, cause, detail, at FROM session_events
+		WHERE session_id = ? ORDER BY id`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("list session events: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var events []*models.SessionEvent
+	for rows.Next() {
+		e := &models.SessionEvent{}
+		var kind string
+		if err := rows.Scan(&e.ID, &e.SessionID, &kind, &e.Value, &e.From, &e.Cause, &e.Detail, &e.At); err != nil {
+			return nil, fmt.Errorf("scan session event: %w", err)
+		}
+		e.Kind = models.SessionEventKind(kind)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// --- API Tokens ---
+
+func (s *SQLiteStore) CreateAPIToken(ctx context.Context, token *models.APIToken) error {
+	if token.ID == "" {
+		token.ID = newULID()
+	}
+	token.CreatedAt = time.Now().UTC()
+
+	_, err := s.exec(ctx,
+		`INSERT INTO api_tokens (id, name, project_id, token_hash, created_at) VALUES (?, ?, ?, ?, ?)`,
+		token.ID, token.Name, token.ProjectID, token.TokenHash, token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create api token: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) GetAPITokenByHash(ctx context.Context, hash string) (*models.APIToken, error) {
+	t := &models.APIToken{}
+	err := s.db.QueryRowContext(ctx,
+		`SELECT id, name, project_id, token_hash, created_at FROM api_tokens WHERE token_hash = ?`, hash,
+	).Scan(&t.ID, &t.Name, &t.ProjectID, &t.TokenHash, &t.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("api token not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get api token: %w", err)
+	}
+	return t, nil
+}
+
+func (s *SQLiteStore) ListAPITokens(ctx context.Context) ([]*models.APIToken, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, name, project_id, token_hash, created_at FROM api_tokens ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("list api tokens: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var tokens []*models.APIToken
+	for rows.Next() {
+		t := &models.APIToken{}
+		if err := rows.Scan(&t.ID, &t.Name, &t.ProjectID, &t.TokenHash, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan api token: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+func (s *SQLiteStore) DeleteAPIToken(ctx context.Context, id string) error {
+	result, err := s.exec(ctx, "DELETE FROM api_tokens WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("delete api token: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("api token not found: %s", id)
+	}
+	return nil
+}
+
+// --- Web Push Subscriptions ---
+
+// SavePushSubscription stores a subscription, replacing the keys of an
+// existing one with the same endpoint (which keeps its ID).
+func (s *SQLiteStore) SavePushSubscription(ctx context.Context, sub *models.PushSubscription) error {
+	if sub.ID == "" {
+		sub.ID = newULID()
+	}
+	sub.CreatedAt = time.Now().UTC()
+
+	_, err := s.exec(ctx,
+		`INSERT INTO push_subscriptions (id, endpoint, p256dh, auth, user_agent, created_at) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(endpoint) DO UPDATE SET p256dh = excluded.p256dh, auth = excluded.auth, user_agent = excluded.user_agent`,
+		sub.ID, sub.Endpoint, sub.P256dh, sub.Auth, sub.UserAgent, sub.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("save push subscription: %w", err)
+	}
+	return s.db.QueryRowContext(ctx,
+		`SELECT id, created_at FROM push_subscriptions WHERE endpoint = ?`, sub.Endpoint,
+	).Scan(&sub.ID, &sub.CreatedAt)
+}
+
+func (s *SQLiteStore) ListPushSubscriptions(ctx context.Context) ([]*models.PushSubscription, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, endpoint, p256dh, auth, user_agent, created_at FROM push_subscriptions ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("list push subscriptions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var subs []*models.PushSubscription
+	for rows.Next() {
+		sub := &models.PushSubscription{}
+		if err := rows.Scan(&sub.ID, &sub.Endpoint, &sub.P256dh, &sub.Auth, &sub.UserAgent, &sub.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan push subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+func (s *SQLiteStore) DeletePushSubscription(ctx context.Context, endpoint string) error {
+	result, err := s.exec(ctx, "DELETE FROM push_subscriptions WHERE endpoint = ?", endpoint)
+	if err != nil {
+		return fmt.Errorf("delete push subscription: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("push subscription not found")
+	}
+	return nil
+}
+
+// --- Change Feed ---
+
+// ListChanges returns the change feed entries after filter.Since, oldest
+// first. Entries are recorded by triggers on the followed tables.
+func (s *SQLiteStore) ListChanges(ctx context.Context, filter ChangeFilter) ([]*models.Change, error) {
+	query := `SELECT seq, entity, entity_id, project_id, op, changed_at FROM changes WHERE seq > ?`
+	args := []any{filter.Since}
+	if filter.ProjectID != "" {
+		query += " AND project_id = ?"
+		args = append(args, filter.ProjectID)
+	}
+	if len(filter.Entities) > 0 {
+		query += " AND entity IN (?" + strings.Repeat(", ?", len(filter.Entities)-1) + ")"
+		for _, e := range filter.Entities {
+			args = append(args, string(e))
+		}
+	}
+	query += " ORDER BY seq"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list changes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var changes []*models.Change
+	for rows.Next() {
+		c := &models.Change{}
+		var entity, op string
+		if err := rows.Scan(&c.Seq, &entity, &c.EntityID, &c.ProjectID, &op, &c.At); err != nil {
+			return nil, fmt.Errorf("scan change: %w", err)
+		}
+		c.Entity = models.ChangeEntity(entity)
+		c.Op = models.ChangeOp(op)
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// ChangeSeqRange returns the sequence numbers of the oldest and newest
+// retained changes. With no changes retained, first is last+1, where last is
+// the newest sequence number ever handed out.
+func (s *SQLiteStore) ChangeSeqRange(ctx context.Context) (first, last int64, err error) {
+	var minSeq sql.NullInt64
+	err = s.db.QueryRowContext(ctx,
+		`SELECT MIN(seq), COALESCE((SELECT seq FROM sqlite_sequence WHERE name = 'changes'), 0) FROM changes`).
+		Scan(&minSeq, &last)
+	if err != nil {
+		return 0, 0, fmt.Errorf("change seq range: %w", err)
+	}
+	if !minSeq.Valid {
+		return last + 1, last, nil
+	}
+	return minSeq.Int64, last, nil
+}
+
+// PruneChanges deletes the changes recorded before the given time.
+func (s *SQLiteStore) PruneChanges(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.exec(ctx, `DELETE FROM changes WHERE changed_at < ?`, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("prune changes: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// --- Project Notes ---
+
+func (s *SQLiteStore) CreateProjectNote(ctx context.Context, note *models.ProjectNote) error {
+	if note.ID == "" {
+		note.ID = newULID()
+	}
+	now := time.Now().UTC()
+	note.CreatedAt = createdTime(ctx, note.CreatedAt, now)
+	note.UpdatedAt = createdTime(ctx, note.UpdatedAt, now)
+
+	_, err := s.exec(ctx,
+		`INSERT INTO project_notes (id, project_id, title, body, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		note.ID, note.ProjectID, note.Title, note.Body, note.CreatedAt, note.UpdatedAt,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return fmt.Errorf("create project note: a note titled %q already exists", note.Title)
+		}
+		return fmt.Errorf("create project note: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) GetProjectNote(ctx context.Context, id string) (*models.ProjectNote, error) {
+	n := &models.ProjectNote{}
+	err := s.db.QueryRowContext(ctx,
+		`SELECT id, project_id, title, body, created_at, updated_at FROM project_notes WHERE id = ?`, id,
+	).Scan(&n.ID, &n.ProjectID, &n.Title, &n.Body, &n.CreatedAt, &n.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("project note not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get project note: %w", err)
+	}
+	return n, nil
+}
+
+// ListProjectNotes returns a project's notes ordered by title.
+func (s *SQLiteStore) ListProjectNotes(ctx context.Context, projectID string) ([]*models.ProjectNote, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, project_id, title, body, created_at, updated_at FROM project_notes WHERE project_id = ? ORDER BY title COLLATE NOCASE, id`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("list project notes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var notes []*models.ProjectNote
+	for rows.Next() {
+		n := &models.ProjectNote{}
+		if err := rows.Scan(&n.ID, &n.ProjectID, &n.Title, &n.Body, &n.CreatedAt, &n.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan project note: %w", err)
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
+
+func (s *SQLiteStore) UpdateProjectNote(ctx context.Context, note *models.ProjectNote) error {
+	note.UpdatedAt = time.Now().UTC()
+	result, err := s.exec(ctx,
+		`UPDATE project_notes SET title=?, body=?, updated_at=? WHERE id=?`,
+		note.Title, note.Body, note.UpdatedAt, note.ID,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return fmt.Errorf("update project note: a note titled %q already exists", note.Title)
+		}
+		return fmt.Errorf("update project note: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("project note not found: %s", note.ID)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) DeleteProjectNote(ctx context.Context, id string) error {
+	result, err := s.exec(ctx, "DELETE FROM project_notes WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("delete project note: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("project note not found: %s", id)
+	}
+	return nil
+}
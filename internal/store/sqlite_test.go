@@ -49,8 +49,55 @@ func TestMigrate_Idempotent(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestTimestampsStoredInUTC(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	p := &models.Project{Name: "zones", Path: "/tmp/zones"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	session := &models.AgentSession{ProjectID: p.ID, Branch: "feature/zones", Status: models.SessionStatusActive}
+	require.NoError(t, s.CreateAgentSession(ctx, session))
+
+	pdt := time.FixedZone("PDT", -7*60*60)
+	ended := time.Date(2026, 3, 1, 18, 30, 0, 0, pdt)
+	session.Status = models.SessionStatusCompleted
+	session.EndedAt = &ended
+	require.NoError(t, s.UpdateAgentSession(ctx, session))
+
+	var raw string
+	require.NoError(t, s.db.QueryRowContext(ctx, "SELECT ended_at || '' FROM agent_sessions WHERE id = ?", session.ID).Scan(&raw))
+	assert.Equal(t, "2026-03-02 01:30:00 +0000 UTC", raw, "stored as UTC")
+
+	got, err := s.GetAgentSession(ctx, session.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.EndedAt)
+	assert.Equal(t, time.UTC, got.EndedAt.Location())
+	assert.True(t, ended.Equal(*got.EndedAt))
+}
+
 // --- Project CRUD ---
 
+func TestOnWrite(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	writes := 0
+	s.OnWrite(func() { writes++ })
+
+	p := &models.Project{Name: "hooked", Path: "/tmp/hooked"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	assert.Equal(t, 1, writes)
+
+	_, err := s.ListProjects(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, writes, "reads do not notify")
+
+	require.Error(t, s.CreateProject(ctx, &models.Project{Name: "hooked", Path: "/tmp/hooked"}))
+	assert.Equal(t, 1, writes, "failed writes do not notify")
+
+	_, err = s.BulkDeleteIssues(ctx, []string{"none"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, writes)
+}
+
 func TestProjectCRUD(t *testing.T) {
 	s := newTestStore(t)
 	ctx := context.Background()
@@ -118,6 +165,38 @@ func TestProjectCRUD(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestProjectArchived(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	active := &models.Project{Name: "active", Path: "/tmp/active", GroupName: "client-x"}
+	old := &models.Project{Name: "old", Path: "/tmp/old", GroupName: "client-x"}
+	require.NoError(t, s.CreateProject(ctx, active))
+	require.NoError(t, s.CreateProject(ctx, old))
+
+	old.Archived = true
+	require.NoError(t, s.UpdateProject(ctx, old))
+
+	projects, err := s.ListProjects(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, "active", projects[0].Name)
+
+	projects, err = s.ListProjects(ctx, "client-x")
+	require.NoError(t, err)
+	assert.Len(t, projects, 1)
+
+	archived, err := s.ListArchivedProjects(ctx)
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+	assert.True(t, archived[0].Archived)
+
+	// Archived projects can still be looked up directly
+	got, err := s.GetProjectByName(ctx, "old")
+	require.NoError(t, err)
+	assert.True(t, got.Archived)
+}
+
 func TestProjectUniqueConstraints(t *testing.T) {
 	s := newTestStore(t)
 	ctx := context.Background()
@@ -557,6 +636,132 @@ func TestSessionNewFields(t *testing.T) {
 	require.NotNil(t, got2.LastActiveAt)
 }
 
+func TestSessionReviewFields(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	p := &models.Project{Name: "review-proj", Path: "/tmp/review-proj"}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	session := &models.AgentSession{
+		ProjectID:     p.ID,
+		Branch:        "feature/review-followup",
+		Status:        models.SessionStatusActive,
+		ReviewAttempt: 2,
+		ReviewID:      "REVIEW123",
+	}
+	require.NoError(t, s.CreateAgentSession(ctx, session))
+
+	got, err := s.GetAgentSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.ReviewAttempt)
+	assert.Equal(t, "REVIEW123", got.ReviewID)
+
+	list, err := s.ListAgentSessions(ctx, p.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, 2, list[0].ReviewAttempt)
+}
+
+func TestSessionWorktreeFingerprint(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	p := &models.Project{Name: "fp-proj", Path: "/tmp/fp-proj"}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	session := &models.AgentSession{ProjectID: p.ID, Branch: "feature/fp", Status: models.SessionStatusActive}
+	require.NoError(t, s.CreateAgentSession(ctx, session))
+
+	session.Status = models.SessionStatusIdle
+	session.WorktreeFingerprint = "abc123"
+	require.NoError(t, s.UpdateAgentSession(ctx, session))
+
+	got, err := s.GetAgentSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", got.WorktreeFingerprint)
+}
+
+func TestSessionTerminal(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	p := &models.Project{Name: "term-proj", Path: "/tmp/term-proj"}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	session := &models.AgentSession{ProjectID: p.ID, Branch: "feature/term", Status: models.SessionStatusActive, TerminalBackend: "iterm", TerminalID: "w0t0p0:ABC"}
+	require.NoError(t, s.CreateAgentSession(ctx, session))
+
+	got, err := s.GetAgentSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "iterm", got.TerminalBackend)
+	assert.Equal(t, "w0t0p0:ABC", got.TerminalID)
+
+	session.TerminalBackend, session.TerminalID = "tmux", "pm-term-proj-term"
+	require.NoError(t, s.UpdateAgentSession(ctx, session))
+	got, err = s.GetAgentSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "pm-term-proj-term", got.TerminalID)
+}
+
+func TestSessionInline(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	p := &models.Project{Name: "inline-proj", Path: "/tmp/inline-proj"}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	session := &models.AgentSession{ProjectID: p.ID, Branch: "main", WorktreePath: p.Path, Status: models.SessionStatusActive, Inline: true}
+	require.NoError(t, s.CreateAgentSession(ctx, session))
+
+	got, err := s.GetAgentSessionByWorktreePath(ctx, p.Path)
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, got.ID)
+	assert.True(t, got.Inline)
+
+	sessions, err := s.ListAgentSessions(ctx, p.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.True(t, sessions[0].Inline)
+}
+
+func TestSessionBlocked(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	p := &models.Project{Name: "blocked-proj", Path: "/tmp/blocked-proj"}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	session := &models.AgentSession{ProjectID: p.ID, Branch: "feature/sso", WorktreePath: "/tmp/blocked-proj-sso", Status: models.SessionStatusIdle}
+	require.NoError(t, s.CreateAgentSession(ctx, session))
+
+	blockedAt := time.Now().UTC().Truncate(time.Second)
+	session.Status = models.SessionStatusBlocked
+	session.BlockedReason = "waiting on IdP credentials"
+	session.UnblockCondition = "ops shares the client secret"
+	session.BlockedAt = &blockedAt
+	require.NoError(t, s.UpdateAgentSession(ctx, session))
+
+	got, err := s.GetAgentSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SessionStatusBlocked, got.Status)
+	assert.Equal(t, "waiting on IdP credentials", got.BlockedReason)
+	assert.Equal(t, "ops shares the client secret", got.UnblockCondition)
+	require.NotNil(t, got.BlockedAt)
+	assert.True(t, blockedAt.Equal(*got.BlockedAt))
+
+	got, err = s.GetAgentSessionByWorktreePath(ctx, "/tmp/blocked-proj-sso")
+	require.NoError(t, err, "a blocked session still owns its worktree")
+	assert.Equal(t, session.ID, got.ID)
+
+	blocked, err := s.ListAgentSessionsByStatus(ctx, p.ID, []models.SessionStatus{models.SessionStatusBlocked}, 0)
+	require.NoError(t, err)
+	require.Len(t, blocked, 1)
+
+	dup := &models.AgentSession{ProjectID: p.ID, Branch: "feature/sso", Status: models.SessionStatusActive}
+	assert.Error(t, s.CreateAgentSession(ctx, dup), "a blocked session keeps its branch")
+}
+
 func TestProjectBuildFields(t *testing.T) {
 	s := newTestStore(t)
 	ctx := context.Background()
@@ -584,6 +789,26 @@ func TestProjectBuildFields(t *testing.T) {
 	assert.Equal(t, "make build", got2.BuildCmd)
 }
 
+func TestProjectCommitConventions(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	p := &models.Project{Name: "commits-test", Path: "/tmp/commits-test", CommitStyle: "conventional"}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	got, err := s.GetProject(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "conventional", got.CommitStyle)
+	assert.Empty(t, got.CommitTicketPrefix)
+
+	got.CommitTicketPrefix = "PROJ-"
+	require.NoError(t, s.UpdateProject(ctx, got))
+	projects, err := s.ListProjects(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, "PROJ-", projects[0].CommitTicketPrefix)
+}
+
 func TestIssueReviewCRUD(t *testing.T) {
 	s := newTestStore(t)
 	ctx := context.Background()
@@ -742,7 +967,10 @@ func TestDeleteStaleSessions(t *testing.T) {
 	require.NoError(t, s.UpdateAgentSession(ctx, nonStaleSession))
 
 	// Call DeleteStaleSessions
-	count, err := s.DeleteStaleSessions(ctx, p.ID, "feature/stale-branch")
+	criteria := DefaultStaleSessionCriteria()
+	criteria.ProjectID = p.ID
+	criteria.Branch = "feature/stale-branch"
+	count, err := s.DeleteStaleSessions(ctx, criteria)
 	require.NoError(t, err)
 	assert.Equal(t, int64(1), count)
 
@@ -752,6 +980,15 @@ func TestDeleteStaleSessions(t *testing.T) {
 	require.Len(t, sessions, 1)
 	assert.Equal(t, nonStaleSession.ID, sessions[0].ID)
 	assert.Equal(t, 3, sessions[0].CommitCount)
+
+	// Without a duration bound every ended session would be stale
+	criteria.MaxCommits = 3
+	criteria.MaxDuration = 0
+	_, err = s.DeleteStaleSessions(ctx, criteria)
+	assert.Error(t, err)
+	sessions, err = s.ListAgentSessions(ctx, p.ID, 0)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1)
 }
 
 func TestListIssues_SortedByStatusThenPriority(t *testing.T) {
@@ -846,3 +1083,638 @@ func TestDeleteAllStaleSessions(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, sessions, 1)
 }
+
+func TestListStaleSessions_Criteria(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	p := &models.Project{Name: "criteria-test", Path: "/tmp/criteria-test"}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	mk := func(branch string, status models.SessionStatus, commits int, dur time.Duration) *models.AgentSession {
+		sess := &models.AgentSession{ProjectID: p.ID, Branch: branch, Status: status}
+		require.NoError(t, s.CreateAgentSession(ctx, sess))
+		end := sess.StartedAt.Add(dur)
+		sess.CommitCount = commits
+		sess.EndedAt = &end
+		require.NoError(t, s.UpdateAgentSession(ctx, sess))
+		return sess
+	}
+
+	quick := mk("feature/quick", models.SessionStatusAbandoned, 0, 10*time.Second)
+	slow := mk("feature/slow", models.SessionStatusAbandoned, 1, 10*time.Minute)
+	done := mk("feature/done", models.SessionStatusCompleted, 0, 10*time.Second)
+
+	got, err := s.ListStaleSessions(ctx, DefaultStaleSessionCriteria())
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, quick.ID, got[0].ID)
+
+	wide := StaleSessionCriteria{
+		Statuses:   []models.SessionStatus{models.SessionStatusAbandoned, models.SessionStatusCompleted},
+		MaxCommits: 1,
+	}
+	got, err = s.ListStaleSessions(ctx, wide)
+	require.NoError(t, err)
+	assert.Len(t, got, 3)
+
+	wide.MinAge = time.Hour
+	got, err = s.ListStaleSessions(ctx, wide)
+	require.NoError(t, err)
+	assert.Empty(t, got, "recently ended sessions are kept when min age is set")
+
+	wide.MinAge = 0
+	n, err := s.DeleteStaleSessionsByID(ctx, wide, []string{slow.ID, done.ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	remaining, err := s.ListAgentSessions(ctx, p.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, quick.ID, remaining[0].ID)
+}
+
+func TestIssueRelationCRUD(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	// Issues in two projects to exercise cross-project relations
+	p1 := &models.Project{Name: "rel-a", Path: "/tmp/rel-a"}
+	p2 := &models.Project{Name: "rel-b", Path: "/tmp/rel-b"}
+	require.NoError(t, s.CreateProject(ctx, p1))
+	require.NoError(t, s.CreateProject(ctx, p2))
+	bug := &models.Issue{ProjectID: p1.ID, Title: "original bug", Status: models.IssueStatusDone, Priority: models.IssuePriorityMedium, Type: models.IssueTypeBug}
+	regression := &models.Issue{ProjectID: p2.ID, Title: "regression", Status: models.IssueStatusOpen, Priority: models.IssuePriorityHigh, Type: models.IssueTypeBug}
+	require.NoError(t, s.CreateIssue(ctx, bug))
+	require.NoError(t, s.CreateIssue(ctx, regression))
+
+	rel := &models.IssueRelation{SourceIssueID: regression.ID, TargetIssueID: bug.ID, Type: models.RelationCausedBy}
+	require.NoError(t, s.CreateIssueRelation(ctx, rel))
+	assert.NotEmpty(t, rel.ID)
+
+	// Duplicate and self relations are rejected
+	err := s.CreateIssueRelation(ctx, &models.IssueRelation{SourceIssueID: regression.ID, TargetIssueID: bug.ID, Type: models.RelationCausedBy})
+	assert.ErrorContains(t, err, "already exists")
+	err = s.CreateIssueRelation(ctx, &models.IssueRelation{SourceIssueID: bug.ID, TargetIssueID: bug.ID, Type: models.RelationRelatesTo})
+	assert.Error(t, err)
+
+	// Listed from both ends
+	for _, id := range []string{bug.ID, regression.ID} {
+		rels, err := s.ListIssueRelations(ctx, id)
+		require.NoError(t, err)
+		require.Len(t, rels, 1)
+		assert.Equal(t, models.RelationCausedBy, rels[0].Type)
+		assert.Equal(t, regression.ID, rels[0].SourceIssueID)
+	}
+
+	require.NoError(t, s.DeleteIssueRelation(ctx, rel.ID))
+	rels, err := s.ListIssueRelations(ctx, bug.ID)
+	require.NoError(t, err)
+	assert.Empty(t, rels)
+	assert.ErrorContains(t, s.DeleteIssueRelation(ctx, rel.ID), "not found")
+
+	// Deleting an issue cascades to its relations
+	require.NoError(t, s.CreateIssueRelation(ctx, &models.IssueRelation{SourceIssueID: regression.ID, TargetIssueID: bug.ID, Type: models.RelationRelatesTo}))
+	require.NoError(t, s.DeleteIssue(ctx, bug.ID))
+	rels, err = s.ListIssueRelations(ctx, regression.ID)
+	require.NoError(t, err)
+	assert.Empty(t, rels)
+}
+
+func TestProjectDependencyCRUD(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	pm := &models.Project{Name: "pm", Path: "/tmp/pm"}
+	wt := &models.Project{Name: "wt", Path: "/tmp/wt"}
+	require.NoError(t, s.CreateProject(ctx, pm))
+	require.NoError(t, s.CreateProject(ctx, wt))
+
+	dep := &models.ProjectDependency{ProjectID: pm.ID, DependsOnID: wt.ID}
+	require.NoError(t, s.CreateProjectDependency(ctx, dep))
+	assert.NotEmpty(t, dep.ID)
+
+	// Duplicate and self dependencies are rejected
+	err := s.CreateProjectDependency(ctx, &models.ProjectDependency{ProjectID: pm.ID, DependsOnID: wt.ID})
+	assert.ErrorContains(t, err, "already exists")
+	err = s.CreateProjectDependency(ctx, &models.ProjectDependency{ProjectID: pm.ID, DependsOnID: pm.ID})
+	assert.Error(t, err)
+
+	deps, err := s.ListProjectDependencies(ctx)
+	require.NoError(t, err)
+	require.Len(t, deps, 1)
+	assert.Equal(t, pm.ID, deps[0].ProjectID)
+	assert.Equal(t, wt.ID, deps[0].DependsOnID)
+
+	require.NoError(t, s.DeleteProjectDependency(ctx, pm.ID, wt.ID))
+	assert.ErrorContains(t, s.DeleteProjectDependency(ctx, pm.ID, wt.ID), "not found")
+
+	// Deleting a project cascades to its dependencies
+	require.NoError(t, s.CreateProjectDependency(ctx, &models.ProjectDependency{ProjectID: pm.ID, DependsOnID: wt.ID}))
+	require.NoError(t, s.DeleteProject(ctx, wt.ID))
+	deps, err = s.ListProjectDependencies(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, deps)
+}
+
+func TestHealthSnapshots(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	p := &models.Project{Name: "pm", Path: "/tmp/pm"}
+	require.NoError(t, s.CreateProject(ctx, p))
+
+	require.NoError(t, s.CreateHealthSnapshot(ctx, &models.HealthSnapshot{ProjectID: p.ID, Total: 60, IsDirty: true, BranchCount: 2}))
+	require.NoError(t, s.CreateHealthSnapshot(ctx, &models.HealthSnapshot{ProjectID: p.ID, Total: 75, BranchCount: 5, LatestRelease: "v1.0.0"}))
+
+	snaps, err := s.ListHealthSnapshots(ctx, p.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, snaps, 2)
+	assert.Equal(t, 75, snaps[0].Total, "newest first")
+	assert.Equal(t, "v1.0.0", snaps[0].LatestRelease)
+	assert.True(t, snaps[1].IsDirty)
+
+	snaps, err = s.ListHealthSnapshots(ctx, p.ID, 1)
+	require.NoError(t, err)
+	require.Len(t, snaps, 1)
+	assert.Equal(t, 5, snaps[0].BranchCount)
+
+	require.NoError(t, s.DeleteProject(ctx, p.ID))
+	snaps, err = s.ListHealthSnapshots(ctx, p.ID, 0)
+	require.NoError(t, err)
+	assert.Empty(t, snaps)
+}
+
+func TestIssueEnumTriggers(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "x"}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+
+	// Writes that bypass the store's validation are rejected by the database.
+	_, err := s.db.ExecContext(ctx, "UPDATE issues SET status = 'opne' WHERE id = ?", issue.ID)
+	assert.ErrorContains(t, err, "invalid issue status, priority or type")
+	_, err = s.db.ExecContext(ctx, `INSERT INTO issues (id, project_id, title, status, priority, type, created_at, updated_at)
+		VALUES ('raw', ?, 'raw', 'open', 'urgent', 'bug', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, p.ID)
+	assert.ErrorContains(t, err, "invalid issue status, priority or type")
+	_, err = s.db.ExecContext(ctx, "UPDATE issues SET title = 'renamed' WHERE id = ?", issue.ID)
+	assert.NoError(t, err)
+}
+
+func TestSessionReviewEnumTriggers(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "x"}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	session := &models.AgentSession{ProjectID: p.ID, Branch: "a", Status: models.SessionStatusActive}
+	require.NoError(t, s.CreateAgentSession(ctx, session))
+	review := &models.IssueReview{IssueID: issue.ID, Verdict: models.ReviewVerdictPass}
+	require.NoError(t, s.CreateIssueReview(ctx, review))
+
+	_, err := s.db.ExecContext(ctx, "UPDATE agent_sessions SET status = 'running' WHERE id = ?", session.ID)
+	assert.ErrorContains(t, err, "invalid session status")
+	_, err = s.db.ExecContext(ctx, "UPDATE issue_reviews SET verdict = 'ok' WHERE id = ?", review.ID)
+	assert.ErrorContains(t, err, "invalid review verdict or category")
+	_, err = s.db.ExecContext(ctx, "UPDATE issue_reviews SET ui_ux = 'great' WHERE id = ?", review.ID)
+	assert.ErrorContains(t, err, "invalid review verdict or category")
+	_, err = s.db.ExecContext(ctx, "UPDATE issue_reviews SET ui_ux = 'na', summary = 'ok' WHERE id = ?", review.ID)
+	assert.NoError(t, err)
+}
+
+func TestIssueSLA(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	p := &models.Project{Name: "pm", Path: "/tmp/pm"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Crash", Status: models.IssueStatusOpen, Priority: models.IssuePriorityHigh, Type: models.IssueTypeBug}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+
+	require.NoError(t, s.SetIssueSLAState(ctx, issue.ID, models.SLAStateBreached))
+	got, err := s.GetIssue(ctx, issue.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SLAStateBreached, got.SLAState)
+	assert.Equal(t, issue.UpdatedAt.Unix(), got.UpdatedAt.Unix(), "SLA state does not bump updated_at")
+
+	issues, err := s.ListIssues(ctx, IssueListFilter{ProjectID: p.ID})
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, models.SLAStateBreached, issues[0].SLAState)
+
+	// A breach is recorded once per issue, policy and kind
+	b := &models.SLABreach{IssueID: issue.ID, ProjectID: p.ID, Policy: "high-bugs", Kind: models.SLABreachStart}
+	created, err := s.RecordSLABreach(ctx, b)
+	require.NoError(t, err)
+	assert.True(t, created)
+	created, err = s.RecordSLABreach(ctx, &models.SLABreach{IssueID: issue.ID, ProjectID: p.ID, Policy: "high-bugs", Kind: models.SLABreachStart})
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	breaches, err := s.ListSLABreaches(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, breaches, 1)
+	assert.Equal(t, models.SLABreachStart, breaches[0].Kind)
+
+	breaches, err = s.ListSLABreaches(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, breaches)
+}
+
+func TestIssueStatusEvents(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	p := &models.Project{Name: "pm", Path: "/tmp/pm"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Flow", Status: models.IssueStatusOpen, Priority: models.IssuePriorityMedium, Type: models.IssueTypeFeature}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+
+	issue.Title = "Flow chart"
+	require.NoError(t, s.UpdateIssue(ctx, issue))
+	issue.Status = models.IssueStatusInProgress
+	require.NoError(t, s.UpdateIssue(ctx, issue))
+	_, err := s.BulkUpdateIssueStatus(ctx, []string{issue.ID}, models.IssueStatusDone)
+	require.NoError(t, err)
+	require.NoError(t, s.SetIssueSLAState(ctx, issue.ID, models.SLAStateOK))
+
+	events, err := s.ListIssueStatusEvents(ctx, p.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 3, "only status changes are recorded")
+	assert.Equal(t, models.IssueStatus(""), events[0].FromStatus)
+	assert.Equal(t, models.IssueStatusOpen, events[0].ToStatus)
+	assert.Equal(t, issue.CreatedAt.Unix(), events[0].ChangedAt.Unix())
+	assert.Equal(t, models.IssueStatusOpen, events[1].FromStatus)
+	assert.Equal(t, models.IssueStatusInProgress, events[1].ToStatus)
+	assert.Equal(t, models.IssueStatusDone, events[2].ToStatus)
+	assert.False(t, events[2].ChangedAt.Before(events[1].ChangedAt))
+
+	require.NoError(t, s.DeleteIssue(ctx, issue.ID))
+	events, err = s.ListIssueStatusEvents(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestIssueCycleTimestamps(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	p := &models.Project{Name: "pm", Path: "/tmp/pm"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Cycle", Status: models.IssueStatusOpen, Priority: models.IssuePriorityMedium, Type: models.IssueTypeFeature}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	assert.Nil(t, issue.StartedAt)
+	assert.Nil(t, issue.CompletedAt)
+
+	issue.Status = models.IssueStatusInProgress
+	require.NoError(t, s.UpdateIssue(ctx, issue))
+	require.NotNil(t, issue.StartedAt, "UpdateIssue reloads trigger-maintained timestamps")
+	started := *issue.StartedAt
+	assert.Equal(t, issue.UpdatedAt.Unix(), started.Unix())
+
+	_, err := s.BulkUpdateIssueStatus(ctx, []string{issue.ID}, models.IssueStatusDone)
+	require.NoError(t, err)
+	got, err := s.GetIssue(ctx, issue.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.CompletedAt)
+	completed := *got.CompletedAt
+	cycle, ok := got.CycleTime()
+	require.True(t, ok)
+	assert.GreaterOrEqual(t, cycle, time.Duration(0))
+
+	got.Status = models.IssueStatusClosed
+	require.NoError(t, s.UpdateIssue(ctx, got))
+	assert.True(t, completed.Equal(*got.CompletedAt), "done -> closed keeps the first completion")
+
+	// Reopening clears completion; restarting keeps the first start
+	got.Status = models.IssueStatusOpen
+	require.NoError(t, s.UpdateIssue(ctx, got))
+	assert.Nil(t, got.CompletedAt)
+	got.Status = models.IssueStatusInProgress
+	require.NoError(t, s.UpdateIssue(ctx, got))
+	assert.True(t, started.Equal(*got.StartedAt))
+
+	created := &models.Issue{ProjectID: p.ID, Title: "Already done", Status: models.IssueStatusDone, Priority: models.IssuePriorityLow, Type: models.IssueTypeChore}
+	require.NoError(t, s.CreateIssue(ctx, created))
+	require.NotNil(t, created.CompletedAt)
+	assert.Nil(t, created.StartedAt)
+	_, ok = created.CycleTime()
+	assert.False(t, ok, "never started")
+	lead, ok := created.LeadTime()
+	require.True(t, ok)
+	assert.Zero(t, lead)
+}
+
+func TestAPITokens(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	p := &models.Project{Name: "pm", Path: "/tmp/pm"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	tok := &models.APIToken{Name: "agent", ProjectID: p.ID, TokenHash: "abc123"}
+	require.NoError(t, s.CreateAPIToken(ctx, tok))
+	assert.NotEmpty(t, tok.ID)
+
+	got, err := s.GetAPITokenByHash(ctx, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, tok.ID, got.ID)
+	assert.Equal(t, p.ID, got.ProjectID)
+	_, err = s.GetAPITokenByHash(ctx, "nope")
+	assert.Error(t, err)
+
+	tokens, err := s.ListAPITokens(ctx)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, "agent", tokens[0].Name)
+
+	require.NoError(t, s.DeleteAPIToken(ctx, tok.ID))
+	assert.Error(t, s.DeleteAPIToken(ctx, tok.ID))
+	_, err = s.GetAPITokenByHash(ctx, "abc123")
+	assert.Error(t, err, "revoked tokens no longer authenticate")
+}
+
+func TestProjectNotes(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	p := &models.Project{Name: "pm", Path: "/tmp/pm"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	runbook := &models.ProjectNote{ProjectID: p.ID, Title: "Runbook", Body: "# Deploy\n\nRun `make release`."}
+	require.NoError(t, s.CreateProjectNote(ctx, runbook))
+	assert.NotEmpty(t, runbook.ID)
+	require.NoError(t, s.CreateProjectNote(ctx, &models.ProjectNote{ProjectID: p.ID, Title: "ADR 1: SQLite"}))
+
+	err := s.CreateProjectNote(ctx, &models.ProjectNote{ProjectID: p.ID, Title: "runbook"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+
+	notes, err := s.ListProjectNotes(ctx, p.ID)
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	assert.Equal(t, "ADR 1: SQLite", notes[0].Title, "ordered by title")
+
+	runbook.Body = "Run `make deploy`."
+	require.NoError(t, s.UpdateProjectNote(ctx, runbook))
+	got, err := s.GetProjectNote(ctx, runbook.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Run `make deploy`.", got.Body)
+	assert.False(t, got.UpdatedAt.Before(got.CreatedAt))
+
+	require.NoError(t, s.DeleteProjectNote(ctx, runbook.ID))
+	assert.Error(t, s.DeleteProjectNote(ctx, runbook.ID))
+
+	require.NoError(t, s.DeleteProject(ctx, p.ID))
+	notes, err = s.ListProjectNotes(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Empty(t, notes, "notes are deleted with their project")
+}
+
+func TestIssueRedactions(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	p := &models.Project{Name: "pm", Path: "/tmp/pm"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "Leaky", Status: models.IssueStatusOpen, Priority: models.IssuePriorityMedium, Type: models.IssueTypeBug,
+		Redactions: []models.Redaction{{Rule: "email", Count: 2}}}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+
+	got, err := s.GetIssue(ctx, issue.ID)
+	require.NoError(t, err)
+	assert.Equal(t, issue.Redactions, got.Redactions)
+
+	got.Redactions = nil
+	require.NoError(t, s.UpdateIssue(ctx, got))
+	issues, err := s.ListIssues(ctx, IssueListFilter{ProjectID: p.ID})
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Empty(t, issues[0].Redactions)
+}
+
+func TestSessionOutcomeClassBackfill(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	p := &models.Project{Name: "api", Path: "/code/api"}
+	require.NoError(t, s.CreateProject(ctx, p))
+	issue := &models.Issue{ProjectID: p.ID, Title: "x"}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+
+	plan, err := s.PlanMigration(ctx, 44)
+	require.NoError(t, err)
+	_, err = s.ApplyMigration(ctx, plan, false)
+	require.NoError(t, err)
+
+	insert := func(id, status string, commits int) {
+		_, err := s.db.ExecContext(ctx, `INSERT INTO agent_sessions (id, project_id, issue_id, branch, worktree_path, status, commit_count, started_at)
+			VALUES (?, ?, ?, ?, '', ?, ?, CURRENT_TIMESTAMP)`, id, p.ID, issue.ID, "feature/"+id, status, commits)
+		require.NoError(t, err)
+	}
+	insert("clean", "completed", 2)
+	insert("conflicted", "completed", 2)
+	insert("empty", "abandoned", 0)
+	insert("partial", "abandoned", 3)
+	insert("reviewed", "completed", 1)
+	insert("open", "idle", 1)
+	_, err = s.db.ExecContext(ctx, "UPDATE agent_sessions SET conflict_state = 'sync_conflict' WHERE id = 'conflicted'")
+	require.NoError(t, err)
+	_, err = s.db.ExecContext(ctx, "UPDATE agent_sessions SET conflict_state = 'none' WHERE id = 'conflicted'")
+	require.NoError(t, err)
+	// Raw SQL: the review columns of later migrations do not exist yet
+	_, err = s.db.ExecContext(ctx, `INSERT INTO issue_reviews (id, issue_id, session_id, verdict, summary, reviewed_at, created_at)
+		VALUES ('r1', ?, 'reviewed', 'fail', 'no tests', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, issue.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Migrate(ctx))
+	want := map[string]models.OutcomeClass{
+		"clean":      models.OutcomeCleanSuccess,
+		"conflicted": models.OutcomeSuccessAfterConflicts,
+		"empty":      models.OutcomeAbandonedNoCommits,
+		"partial":    models.OutcomeAbandoned,
+		"reviewed":   models.OutcomeReviewFailed,
+		"open":       "",
+	}
+	for id, class := range want {
+		got, err := s.GetAgentSession(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, class, got.OutcomeClass, id)
+	}
+}
+
+func TestEnumMigrationsNormalize(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	migrateTo := func(version int) {
+		plan, err := s.PlanMigration(ctx, version)
+		require.NoError(t, err)
+		_, err = s.ApplyMigration(ctx, plan, false)
+		require.NoError(t, err)
+	}
+	// Raw SQL: the enum triggers do not exist yet
+	exec := func(query string, args ...any) {
+		_, err := s.db.ExecContext(ctx, query, args...)
+		require.NoError(t, err)
+	}
+	migrateTo(43)
+	exec(`INSERT INTO projects (id, name, path, created_at, updated_at) VALUES ('api', 'api', '/code/api', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`)
+	exec(`INSERT INTO issues (id, project_id, title, status, priority, type, created_at, updated_at)
+		VALUES ('i1', 'api', 'x', ' In-Progress', 'HIGH', 'Bug ', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`)
+	exec(`INSERT INTO issues (id, project_id, title, status, priority, type, created_at, updated_at)
+		VALUES ('i2', 'api', 'y', 'started', 'urgent', 'task', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`)
+
+	migrateTo(62)
+	exec(`INSERT INTO agent_sessions (id, project_id, issue_id, branch, worktree_path, status, started_at)
+		VALUES ('s1', 'api', 'i1', 'fix', '', 'Completed', CURRENT_TIMESTAMP)`)
+	exec(`INSERT INTO issue_reviews (id, issue_id, session_id, verdict, summary, code_quality, ui_ux, reviewed_at, created_at)
+		VALUES ('r1', 'i1', 's1', 'PASS', 'ok', 'Skip', ' NA', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`)
+
+	require.NoError(t, s.Migrate(ctx))
+	issue, err := s.GetIssue(ctx, "i1")
+	require.NoError(t, err)
+	assert.Equal(t, models.IssueStatusInProgress, issue.Status)
+	assert.Equal(t, models.IssuePriorityHigh, issue.Priority)
+	assert.Equal(t, models.IssueTypeBug, issue.Type)
+	issue, err = s.GetIssue(ctx, "i2")
+	require.NoError(t, err)
+	assert.Equal(t, models.IssueStatusOpen, issue.Status, "unknown values fall back to the default")
+	assert.Equal(t, models.IssuePriorityMedium, issue.Priority)
+	assert.Equal(t, models.IssueTypeFeature, issue.Type)
+
+	sess, err := s.GetAgentSession(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, models.SessionStatusCompleted, sess.Status)
+	reviews, err := s.ListIssueReviews(ctx, "i1")
+	require.NoError(t, err)
+	require.Len(t, reviews, 1)
+	assert.Equal(t, models.ReviewVerdictPass, reviews[0].Verdict)
+	assert.Equal(t, "skip", string(reviews[0].CodeQuality))
+	assert.Equal(t, "na", string(reviews[0].UIUX))
+}
+
+func TestShortCodeBackfill(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	plan, err := s.PlanMigration(ctx, 57)
+	require.NoError(t, err)
+	_, err = s.ApplyMigration(ctx, plan, false)
+	require.NoError(t, err)
+
+	// Raw SQL: the key and code columns do not exist yet
+	exec := func(query string, args ...any) {
+		_, err := s.db.ExecContext(ctx, query, args...)
+		require.NoError(t, err)
+	}
+	for i, name := range []string{"api", "api-gateway", "apis"} {
+		exec(`INSERT INTO projects (id, name, path, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+			name, name, "/code/"+name, time.Date(2025, 1, i+1, 0, 0, 0, 0, time.UTC), time.Now())
+	}
+	for i, id := range []string{"i2", "i1", "i3"} {
+		exec(`INSERT INTO issues (id, project_id, title, created_at, updated_at) VALUES (?, 'api', ?, ?, CURRENT_TIMESTAMP)`,
+			id, id, time.Date(2025, 2, 3-i, 0, 0, 0, 0, time.UTC))
+	}
+	exec(`INSERT INTO agent_sessions (id, project_id, branch, worktree_path, status, started_at) VALUES ('s1', 'apis', 'fix', '', 'completed', CURRENT_TIMESTAMP)`)
+
+	require.NoError(t, s.Migrate(ctx))
+	keys := map[string]string{}
+	projects, err := s.ListProjects(ctx, "")
+	require.NoError(t, err)
+	for _, p := range projects {
+		keys[p.Name] = p.Key
+	}
+	assert.Equal(t, map[string]string{"api": "API", "api-gateway": "AG", "apis": "API2"}, keys)
+	for id, code := range map[string]string{"i3": "API-1", "i1": "API-2", "i2": "API-3"} {
+		issue, err := s.GetIssue(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, code, issue.Code, id)
+	}
+	sess, err := s.GetAgentSession(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, "API2-S1", sess.Code)
+
+	issue := &models.Issue{ProjectID: "api", Title: "New"}
+	require.NoError(t, s.CreateIssue(ctx, issue))
+	assert.Equal(t, "API-4", issue.Code, "numbering continues after the backfill")
+}
+
+func TestUTCTimestampsMigration(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	plan, err := s.PlanMigration(ctx, 63)
+	require.NoError(t, err)
+	_, err = s.ApplyMigration(ctx, plan, false)
+	require.NoError(t, err)
+
+	// Raw SQL skips utcArgs, as writes did before timestamps were UTC.
+	pdt := time.FixedZone("PDT", -7*60*60)
+	local := time.Date(2026, 3, 1, 18, 30, 0, 0, pdt)
+	exec := func(query string, args ...any) {
+		_, err := s.db.ExecContext(ctx, query, args...)
+		require.NoError(t, err)
+	}
+	exec(`INSERT INTO projects (id, name, path, created_at, updated_at) VALUES ('p1', 'api', '/code/api', ?, CURRENT_TIMESTAMP)`, local)
+	exec(`INSERT INTO agent_sessions (id, project_id, branch, worktree_path, status, started_at, last_sync_at) VALUES ('s1', 'p1', 'fix', '', 'idle', ?, ?)`, local, local)
+	count := func(query string) int {
+		var n int
+		require.NoError(t, s.db.QueryRowContext(ctx, query).Scan(&n))
+		return n
+	}
+	changes, events := count(`SELECT COUNT(*) FROM changes`), count(`SELECT COUNT(*) FROM session_events`)
+	var updatedAt string
+	require.NoError(t, s.db.QueryRowContext(ctx, `SELECT updated_at || '' FROM projects WHERE id = 'p1'`).Scan(&updatedAt))
+
+	require.NoError(t, s.Migrate(ctx))
+	raw := func(query string) string {
+		var v string
+		require.NoError(t, s.db.QueryRowContext(ctx, query).Scan(&v))
+		return v
+	}
+	assert.Equal(t, "2026-03-02 01:30:00 +0000 UTC", raw(`SELECT created_at || '' FROM projects WHERE id = 'p1'`))
+	assert.Equal(t, "2026-03-02 01:30:00 +0000 UTC", raw(`SELECT started_at || '' FROM agent_sessions WHERE id = 's1'`))
+	assert.Equal(t, "2026-03-02 01:30:00 +0000 UTC", raw(`SELECT last_sync_at || '' FROM agent_sessions WHERE id = 's1'`))
+	assert.Equal(t, updatedAt, raw(`SELECT updated_at || '' FROM projects WHERE id = 'p1'`), "UTC values are left alone")
+	assert.Equal(t, changes, count(`SELECT COUNT(*) FROM changes`), "no change feed entries")
+	assert.Equal(t, events, count(`SELECT COUNT(*) FROM session_events`), "no sync events")
+
+	got, err := s.GetAgentSession(ctx, "s1")
+	require.NoError(t, err)
+	assert.True(t, local.Equal(got.StartedAt))
+}
+
+func TestIssueMergeBackfill(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	plan, err := s.PlanMigration(ctx, 58)
+	require.NoError(t, err)
+	_, err = s.ApplyMigration(ctx, plan, false)
+	require.NoError(t, err)
+
+	// Raw SQL: the issue merge columns do not exist yet
+	exec := func(query string, args ...any) {
+		_, err := s.db.ExecContext(ctx, query, args...)
+		require.NoError(t, err)
+	}
+	exec(`INSERT INTO projects (id, name, path, created_at, updated_at) VALUES ('p', 'p', '/code/p', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`)
+	exec(`INSERT INTO issues (id, project_id, title, created_at, updated_at) VALUES ('merged', 'p', 'Merged', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`)
+	exec(`INSERT INTO issues (id, project_id, title, created_at, updated_at) VALUES ('open', 'p', 'Open', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`)
+	first := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, commit := range []string{"aaa111", "bbb222"} {
+		exec(`INSERT INTO agent_sessions (id, project_id, issue_id, branch, worktree_path, status, merge_commit, started_at, ended_at) VALUES (?, 'p', 'merged', ?, '', 'completed', ?, ?, ?)`,
+			commit, commit, commit, first.Add(time.Duration(i)*time.Hour), first.Add(time.Duration(i)*time.Hour+time.Minute))
+	}
+	exec(`INSERT INTO agent_sessions (id, project_id, issue_id, branch, worktree_path, status, started_at) VALUES ('s3', 'p', 'open', 'wip', '', 'active', CURRENT_TIMESTAMP)`)
+
+	require.NoError(t, s.Migrate(ctx))
+	merged, err := s.GetIssue(ctx, "merged")
+	require.NoError(t, err)
+	assert.Equal(t, "bbb222", merged.MergeCommit, "the latest merge wins")
+	assert.Empty(t, merged.MergeBranch)
+	require.NotNil(t, merged.MergedAt)
+	assert.True(t, merged.MergedAt.Equal(first.Add(time.Hour+time.Minute)))
+
+	open, err := s.GetIssue(ctx, "open")
+	require.NoError(t, err)
+	assert.Empty(t, open.MergeCommit)
+	assert.Nil(t, open.MergedAt)
+}
@@ -2,6 +2,9 @@ package store
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/joescharf/pm/internal/models"
 )
@@ -13,6 +16,99 @@ type IssueListFilter struct {
 	Priority  models.IssuePriority
 	Type      models.IssueType
 	Tag       string
+	Assignee  string
+	Sort      IssueSort
+}
+
+// SessionListFilter specifies filters for listing agent sessions.
+type SessionListFilter struct {
+	ProjectID string
+	IssueID   string
+	Branch    string
+	Statuses  []models.SessionStatus // empty: every status
+	Label     string
+	Limit     int // 0 = all
+}
+
+// ChangeFilter specifies which change feed entries to list.
+type ChangeFilter struct {
+	Since     int64                 // list changes after this sequence number
+	ProjectID string                // "" = every project
+	Entities  []models.ChangeEntity // empty: every entity
+	Limit     int                   // 0 = all
+}
+
+// ErrIssuesHaveOpenSessions is returned, with the issues' IDs, by
+// BulkDeleteIssues without force when open agent sessions work on issues it
+// was asked to delete.
+var ErrIssuesHaveOpenSessions = errors.New("issues have open agent sessions")
+
+// ErrSessionStatusChanged is returned by TransitionAgentSession when the
+// stored session no longer has the status the transition starts from: another
+// writer moved it first.
+var ErrSessionStatusChanged = errors.New("session status changed concurrently")
+
+// ErrBranchReserved is returned, naming the session that holds the branch,
+// when a session would open on a branch of its project that another open
+// (active, idle or blocked) session already works on. An open session's row
+// is its branch's reservation: it is checked and taken in the same write, so
+// of two launches racing for a branch from different surfaces one fails here.
+var ErrBranchReserved = errors.New("branch reserved by another open session")
+
+// ErrIssueLocked is returned, naming the session that holds the issue, when
+// a session would become active on an issue (its IssueID or one of its
+// ExtraIssueIDs) that another active session holds. Like a branch
+// reservation, the lock is checked and taken in the same write.
+var ErrIssueLocked = errors.New("issue locked by another active session")
+
+// ErrReviewShareUsed is returned by SubmitReviewShare when the link already
+// has its verdict.
+var ErrReviewShareUsed = errors.New("review link already used")
+
+// ErrProjectKeyTaken is returned when a project key is already another
+// project's, or still prefixes the codes of issues and sessions that moved
+// away from it.
+var ErrProjectKeyTaken = errors.New("project key is taken")
+
+// StaleSessionCriteria specifies which ended sessions count as stale and may be purged.
+// Zero-valued MaxDuration and MinAge disable the respective check when
+// listing; DeleteStaleSessions requires a MaxDuration (see Validate).
+type StaleSessionCriteria struct {
+	ProjectID   string
+	Branch      string
+	Statuses    []models.SessionStatus // default: abandoned
+	MaxCommits  int                    // sessions with more commits are kept
+	MaxDuration time.Duration          // sessions that ran at least this long are kept
+	MinAge      time.Duration          // sessions that ended more recently are kept
+}
+
+// DefaultStaleSessionCriteria returns the built-in cleanup thresholds:
+// abandoned sessions with no commits that lasted under a minute.
+func DefaultStaleSessionCriteria() StaleSessionCriteria {
+	return StaleSessionCriteria{
+		Statuses:    []models.SessionStatus{models.SessionStatusAbandoned},
+		MaxCommits:  0,
+		MaxDuration: 60 * time.Second,
+	}
+}
+
+// Validate checks c before stale sessions are deleted by it. MaxDuration must
+// be set: without it every ended session with the statuses would be stale.
+func (c StaleSessionCriteria) Validate() error {
+	switch {
+	case c.MaxCommits < 0:
+		return fmt.Errorf("max commits must not be negative, got %d", c.MaxCommits)
+	case c.MaxDuration <= 0:
+		return fmt.Errorf("max duration must be positive, got %s", c.MaxDuration)
+	case c.MinAge < 0:
+		return fmt.Errorf("min age must not be negative, got %s", c.MinAge)
+	}
+	return nil
+}
+
+// WriteNotifier is implemented by stores that can report successful writes.
+type WriteNotifier interface {
+	OnWrite(fn func())
 }
 
 // Store defines the persistence interface for pm.
@@ -23,22 +119,33 @@ type Store interface {
 	GetProjectByName(ctx context.Context, name string) (*models.Project, error)
 	GetProjectByPath(ctx context.Context, path string) (*models.Project, error)
 	ListProjects(ctx context.Context, group string) ([]*models.Project, error)
+	ListArchivedProjects(ctx context.Context) ([]*models.Project, error)
 	UpdateProject(ctx context.Context, p *models.Project) error
+	SetProjectKey(ctx context.Context, projectID, key string) error
 	DeleteProject(ctx context.Context, id string) error
 
 	// Issues
 	CreateIssue(ctx context.Context, issue *models.Issue) error
 	GetIssue(ctx context.Context, id string) (*models.Issue, error)
+	GetIssueByCode(ctx context.Context, code string) (*models.Issue, error)
 	ListIssues(ctx context.Context, filter IssueListFilter) ([]*models.Issue, error)
 	UpdateIssue(ctx context.Context, issue *models.Issue) error
+	// RecordIssueMerge stamps an issue with the commit, base branch and time
+	// a session merge landed its code at.
+	RecordIssueMerge(ctx context.Context, issueID, commit, branch string, at time.Time) error
 	DeleteIssue(ctx context.Context, id string) error
 	BulkUpdateIssueStatus(ctx context.Context, ids []string, status models.IssueStatus) (int64, error)
-	BulkDeleteIssues(ctx context.Context, ids []string) (int64, error)
+	BulkDeleteIssues(ctx context.Context, ids []string, force bool) (int64, error)
+	SetIssueRanks(ctx context.Context, ranks map[string]string) error
 
 	// Tags
 	CreateTag(ctx context.Context, tag *models.Tag) error
 	ListTags(ctx context.Context) ([]*models.Tag, error)
 	DeleteTag(ctx context.Context, id string) error
+	ListTagUsage(ctx context.Context) ([]*models.TagUsage, error)
+	RenameTag(ctx context.Context, id, name string) error
+	MergeTag(ctx context.Context, id, targetID string) error
+	DeleteUnusedTags(ctx context.Context) ([]*models.Tag, error)
 	TagIssue(ctx context.Context, issueID, tagID string) error
 	UntagIssue(ctx context.Context, issueID, tagID string) error
 	GetIssueTags(ctx context.Context, issueID string) ([]*models.Tag, error)
@@ -46,19 +153,114 @@ type Store interface {
 	// Agent Sessions
 	CreateAgentSession(ctx context.Context, session *models.AgentSession) error
 	GetAgentSession(ctx context.Context, id string) (*models.AgentSession, error)
+	GetAgentSessionByCode(ctx context.Context, code string) (*models.AgentSession, error)
 	GetAgentSessionByWorktreePath(ctx context.Context, path string) (*models.AgentSession, error)
 	ListAgentSessions(ctx context.Context, projectID string, limit int) ([]*models.AgentSession, error)
 	ListAgentSessionsByStatus(ctx context.Context, projectID string, statuses []models.SessionStatus, limit int) ([]*models.AgentSession, error)
+	ListAgentSessionsFiltered(ctx context.Context, filter SessionListFilter) ([]*models.AgentSession, error)
 	ListAgentSessionsByWorktreePaths(ctx context.Context, paths []string) ([]*models.AgentSession, error)
 	UpdateAgentSession(ctx context.Context, session *models.AgentSession) error
-	DeleteStaleSessions(ctx context.Context, projectID, branch string) (int64, error)
+	TransitionAgentSession(ctx context.Context, session *models.AgentSession, from models.SessionStatus, cause string) error
+	TouchAgentSession(ctx context.Context, id string, at time.Time) (bool, error)
+	DeleteStaleSessions(ctx context.Context, criteria StaleSessionCriteria) (int64, error)
 	DeleteAllStaleSessions(ctx context.Context) (int64, error)
+	ListStaleSessions(ctx context.Context, criteria StaleSessionCriteria) ([]*models.AgentSession, error)
+	DeleteStaleSessionsByID(ctx context.Context, criteria StaleSessionCriteria, ids []string) (int64, error)
 
 	// Issue Reviews
 	CreateIssueReview(ctx context.Context, review *models.IssueReview) error
 	ListIssueReviews(ctx context.Context, issueID string) ([]*models.IssueReview, error)
 
+	// Review Shares
+	CreateReviewShare(ctx context.Context, share *models.ReviewShare) error
+	GetReviewShareByHash(ctx context.Context, hash string) (*models.ReviewShare, error)
+	ListReviewShares(ctx context.Context, issueID string) ([]*models.ReviewShare, error)
+	DeleteReviewShare(ctx context.Context, id string) error
+	SubmitReviewShare(ctx context.Context, shareID string, review *models.IssueReview) error
+
+	// Issue Relations
+	CreateIssueRelation(ctx context.Context, rel *models.IssueRelation) error
+	MergeIssues(ctx context.Context, merge *IssueMerge) error
+	ListIssueRelations(ctx context.Context, issueID string) ([]*models.IssueRelation, error)
+	DeleteIssueRelation(ctx context.Context, id string) error
+
+	// Project Dependencies
+	CreateProjectDependency(ctx context.Context, dep *models.ProjectDependency) error
+	ListProjectDependencies(ctx context.Context) ([]*models.ProjectDependency, error)
+	DeleteProjectDependency(ctx context.Context, projectID, dependsOnID string) error
+
+	// Health Snapshots
+	CreateHealthSnapshot(ctx context.Context, snap *models.HealthSnapshot) error
+	ListHealthSnapshots(ctx context.Context, projectID string, limit int) ([]*models.HealthSnapshot, error)
+
+	// SLA
+	SetIssueSLAState(ctx context.Context, issueID string, state models.SLAState) error
+	RecordSLABreach(ctx context.Context, b *models.SLABreach) (bool, error)
+	ListSLABreaches(ctx context.Context, since time.Time) ([]*models.SLABreach, error)
+
+	// Issue Watchers and Comments
+	AddIssueWatcher(ctx context.Context, w *models.IssueWatcher) (bool, error)
+	ListIssueWatchers(ctx context.Context, issueID string) ([]*models.IssueWatcher, error)
+	RemoveIssueWatcher(ctx context.Context, issueID, target string) error
+	CreateIssueComment(ctx context.Context, c *models.IssueComment) error
+	ListIssueComments(ctx context.Context, issueID string) ([]*models.IssueComment, error)
+
+	// Issue Closures
+	RecordIssueClosure(ctx context.Context, c *models.IssueClosure) (bool, error)
+	GetIssueClosure(ctx context.Context, id string) (*models.IssueClosure, error)
+	ListIssueClosures(ctx context.Context, projectID, issueID string) ([]*models.IssueClosure, error)
+	RevertIssueClosure(ctx context.Context, id string) error
+
+	// Pull Request Review Imports
+	RecordPRReviewImport(ctx context.Context, r *models.PRReviewImport) (bool, error)
+	UpdatePRReviewImport(ctx context.Context, r *models.PRReviewImport) error
+	DeletePRReviewImport(ctx context.Context, id string) error
+	ListPRReviewImports(ctx context.Context, projectID, issueID string) ([]*models.PRReviewImport, error)
+
+	// GitHub Board Items
+	SaveBoardItem(ctx context.Context, item *models.BoardItem) error
+	ListBoardItems(ctx context.Context, projectID string) ([]*models.BoardItem, error)
+
+	// GitHub Releases
+	SaveProjectRelease(ctx context.Context, r *models.ProjectRelease) error
+	GetProjectRelease(ctx context.Context, projectID string) (*models.ProjectRelease, error)
+	ListProjectReleases(ctx context.Context) ([]*models.ProjectRelease, error)
+
+	// LLM Usage
+	RecordLLMUsage(ctx context.Context, u *models.LLMUsage) error
+	ListLLMUsage(ctx context.Context, projectID, issueID string) ([]*models.LLMUsage, error)
+
+	// Issue Status History
+	ListIssueStatusEvents(ctx context.Context, projectID string) ([]*models.IssueStatusEvent, error)
+
+	// Agent Session History
+	ListSessionEvents(ctx context.Context, sessionID string) ([]*models.SessionEvent, error)
+
+	// API Tokens
+	CreateAPIToken(ctx context.Context, token *models.APIToken) error
+	GetAPITokenByHash(ctx context.Context, hash string) (*models.APIToken, error)
+	ListAPITokens(ctx context.Context) ([]*models.APIToken, error)
+	DeleteAPIToken(ctx context.Context, id string) error
+
+	// Web Push Subscriptions
+	SavePushSubscription(ctx context.Context, sub *models.PushSubscription) error
+	ListPushSubscriptions(ctx context.Context) ([]*models.PushSubscription, error)
+	DeletePushSubscription(ctx context.Context, endpoint string) error
+
+	// Change Feed
+	ListChanges(ctx context.Context, filter ChangeFilter) ([]*models.Change, error)
+	ChangeSeqRange(ctx context.Context) (first, last int64, err error)
+	PruneChanges(ctx context.Context, before time.Time) (int64, error)
+
+	// Project Notes
+	CreateProjectNote(ctx context.Context, note *models.ProjectNote) error
+	GetProjectNote(ctx context.Context, id string) (*models.ProjectNote, error)
+	ListProjectNotes(ctx context.Context, projectID string) ([]*models.ProjectNote, error)
+	UpdateProjectNote(ctx context.Context, note *models.ProjectNote) error
+	DeleteProjectNote(ctx context.Context, id string) error
+
 	// Lifecycle
 	Migrate(ctx context.Context) error
+	SchemaVersion(ctx context.Context) (int, error)
 	Close() error
 }
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// FindTag resolves a tag by ID or name.
+func FindTag(ctx context.Context, s Store, ref string) (*models.Tag, error) {
+	tags, err := s.ListTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		if t.ID == ref || t.Name == ref {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("tag not found: %s", ref)
+}
+
+// TagBreakdown counts the issues carrying a tag by status, priority, type and
+// project.
+type TagBreakdown struct {
+	Tag        *models.Tag
+	Issues     int
+	ByStatus   map[models.IssueStatus]int
+	ByPriority map[models.IssuePriority]int
+	ByType     map[models.IssueType]int
+	ByProject  map[string]int // keyed by project name
+}
+
+// BreakdownTag counts the issues carrying tag.
+func BreakdownTag(ctx context.Context, s Store, tag *models.Tag) (*TagBreakdown, error) {
+	issues, err := s.ListIssues(ctx, IssueListFilter{Tag: tag.Name})
+	if err != nil {
+		return nil, err
+	}
+	b := &TagBreakdown{
+		Tag:        tag,
+		Issues:     len(issues),
+		ByStatus:   make(map[models.IssueStatus]int),
+		ByPriority: make(map[models.IssuePriority]int),
+		ByType:     make(map[models.IssueType]int),
+		ByProject:  make(map[string]int),
+	}
+	projects := make(map[string]string)
+	for _, issue := range issues {
+		b.ByStatus[issue.Status]++
+		b.ByPriority[issue.Priority]++
+		b.ByType[issue.Type]++
+		name, ok := projects[issue.ProjectID]
+		if !ok {
+			name = issue.ProjectID
+			if p, err := s.GetProject(ctx, issue.ProjectID); err == nil {
+				name = p.Name
+			}
+			projects[issue.ProjectID] = name
+		}
+		b.ByProject[name]++
+	}
+	return b, nil
+}
@@ -0,0 +1,160 @@
+// Package terminal spawns agent commands in a terminal on the host running pm.
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/joescharf/wt/pkg/iterm"
+)
+
+// Supported backends.
+const (
+	BackendITerm = "iterm"
+	BackendTmux  = "tmux"
+)
+
+// ErrNotFound is returned when the terminal no longer exists.
+var ErrNotFound = errors.New("terminal not found")
+
+// Result identifies the terminal a command was spawned in.
+type Result struct {
+	Backend string `json:"backend"`
+	ID      string `json:"id"` // iTerm session unique ID or tmux session name
+}
+
+// Spawner runs a command in a new terminal window or session.
+type Spawner interface {
+	Spawn(dir, name, command string) (*Result, error)
+}
+
+// Controller acts on a terminal by the ID Spawn returned.
+type Controller interface {
+	Focus(id string) error
+	Close(id string) error
+}
+
+// Terminal is a backend that can spawn, focus and close terminals.
+type Terminal interface {
+	Spawner
+	Controller
+}
+
+// New returns the Terminal for backend ("iterm" or "tmux").
+func New(backend string) (Terminal, error) {
+	switch strings.ToLower(backend) {
+	case BackendITerm:
+		return &ITerm{run: runOutput}, nil
+	case BackendTmux:
+		return &Tmux{run: runOutput}, nil
+	default:
+		return nil, fmt.Errorf("unknown terminal backend %q (use iterm or tmux)", backend)
+	}
+}
+
+func runOutput(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ITerm spawns commands in a new iTerm2 window via osascript.
+type ITerm struct {
+	run func(name string, args ...string) (string, error)
+}
+
+// Spawn opens an iTerm2 window in dir, runs command, and returns the
+// window's session unique ID.
+func (t *ITerm) Spawn(dir, name, command string) (*Result, error) {
+	line := fmt.Sprintf("cd %s && %s", ShellQuote(dir), command)
+	script := fmt.Sprintf(`tell application "iTerm2"
+	set newWindow to (create window with default profile)
+	tell current session of current tab of newWindow
+		set name to "%s"
+		write text "%s"
+		return unique ID
+	end tell
+end tell`, escapeAppleScript(name), escapeAppleScript(line))
+
+	id, err := t.run("osascript", "-e", script)
+	if err != nil {
+		return nil, fmt.Errorf("spawn iTerm window: %w", err)
+	}
+	if id == "" {
+		return nil, fmt.Errorf("spawn iTerm window: no session ID returned")
+	}
+	return &Result{Backend: BackendITerm, ID: id}, nil
+}
+
+// Focus brings the window containing the iTerm session to the front.
+func (t *ITerm) Focus(id string) error {
+	out, err := t.run("osascript", "-e", iterm.ScriptFocusWindow(id))
+	if err != nil {
+		return fmt.Errorf("focus iTerm window: %w", err)
+	}
+	if out == "false" {
+		return fmt.Errorf("focus iTerm session %s: %w", id, ErrNotFound)
+	}
+	return nil
+}
+
+// Close closes the window containing the iTerm session.
+func (t *ITerm) Close(id string) error {
+	if _, err := t.run("osascript", "-e", iterm.ScriptCloseWindow(id)); err != nil {
+		return fmt.Errorf("close iTerm window: %w", err)
+	}
+	return nil
+}
+
+// Tmux spawns commands in a new detached tmux session.
+type Tmux struct {
+	run func(name string, args ...string) (string, error)
+}
+
+// Spawn starts a detached tmux session named after name running command in
+// dir, and returns the session name.
+func (t *Tmux) Spawn(dir, name, command string) (*Result, error) {
+	session := tmuxSessionName(name)
+	if _, err := t.run("tmux", "new-session", "-d", "-s", session, "-c", dir, command); err != nil {
+		return nil, fmt.Errorf("spawn tmux session: %w", err)
+	}
+	return &Result{Backend: BackendTmux, ID: session}, nil
+}
+
+// Focus switches the attached tmux client to the session.
+func (t *Tmux) Focus(id string) error {
+	if _, err := t.run("tmux", "switch-client", "-t", id); err != nil {
+		if strings.Contains(err.Error(), "can't find session") {
+			return fmt.Errorf("focus tmux session %s: %w", id, ErrNotFound)
+		}
+		return fmt.Errorf("focus tmux session: %w", err)
+	}
+	return nil
+}
+
+// Close kills the tmux session.
+func (t *Tmux) Close(id string) error {
+	if _, err := t.run("tmux", "kill-session", "-t", id); err != nil {
+		return fmt.Errorf("close tmux session: %w", err)
+	}
+	return nil
+}
+
+// tmuxSessionName replaces characters tmux does not allow in session names.
+func tmuxSessionName(name string) string {
+	return strings.NewReplacer(".", "-", ":", "-", " ", "-").Replace(name)
+}
+
+// escapeAppleScript escapes s for use inside an AppleScript string literal.
+func escapeAppleScript(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// ShellQuote single-quotes s for a POSIX shell.
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
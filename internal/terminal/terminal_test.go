@@ -0,0 +1,88 @@
+package terminal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	name string
+	args []string
+	out  string
+	err  error
+}
+
+func (r *recorder) run(name string, args ...string) (string, error) {
+	r.name, r.args = name, args
+	return r.out, r.err
+}
+
+func TestNew(t *testing.T) {
+	s, err := New("iTerm")
+	require.NoError(t, err)
+	assert.IsType(t, &ITerm{}, s)
+
+	s, err = New("tmux")
+	require.NoError(t, err)
+	assert.IsType(t, &Tmux{}, s)
+
+	_, err = New("kitty")
+	assert.ErrorContains(t, err, "unknown terminal backend")
+}
+
+func TestITerm_Spawn(t *testing.T) {
+	rec := &recorder{out: "w0t0p0:ABC-123"}
+	res, err := (&ITerm{run: rec.run}).Spawn("/repo.worktrees/it's", "pm:app:fix", `claude "Use pm MCP tools"`)
+	require.NoError(t, err)
+	assert.Equal(t, &Result{Backend: BackendITerm, ID: "w0t0p0:ABC-123"}, res)
+
+	assert.Equal(t, "osascript", rec.name)
+	script := rec.args[1]
+	assert.Contains(t, script, `set name to "pm:app:fix"`)
+	assert.Contains(t, script, `write text "cd '/repo.worktrees/it'\\''s' && claude \"Use pm MCP tools\""`)
+}
+
+func TestITerm_SpawnError(t *testing.T) {
+	rec := &recorder{err: errors.New("iTerm2 not installed")}
+	_, err := (&ITerm{run: rec.run}).Spawn("/repo", "pm", "claude")
+	assert.ErrorContains(t, err, "iTerm2 not installed")
+
+	rec = &recorder{}
+	_, err = (&ITerm{run: rec.run}).Spawn("/repo", "pm", "claude")
+	assert.ErrorContains(t, err, "no session ID")
+}
+
+func TestTmux_Spawn(t *testing.T) {
+	rec := &recorder{}
+	res, err := (&Tmux{run: rec.run}).Spawn("/repo.worktrees/fix", "pm:app:v1.2", "claude")
+	require.NoError(t, err)
+	assert.Equal(t, &Result{Backend: BackendTmux, ID: "pm-app-v1-2"}, res)
+	assert.Equal(t, "tmux", rec.name)
+	assert.Equal(t, []string{"new-session", "-d", "-s", "pm-app-v1-2", "-c", "/repo.worktrees/fix", "claude"}, rec.args)
+}
+
+func TestFocusAndClose(t *testing.T) {
+	rec := &recorder{}
+	it := &ITerm{run: rec.run}
+	require.NoError(t, it.Focus("ABC-123"))
+	assert.Equal(t, "osascript", rec.name)
+	assert.Contains(t, rec.args[1], `"ABC-123"`)
+	require.NoError(t, it.Close("ABC-123"))
+	assert.Contains(t, rec.args[1], "close")
+
+	tm := &Tmux{run: rec.run}
+	require.NoError(t, tm.Focus("pm-app-fix"))
+	assert.Equal(t, []string{"switch-client", "-t", "pm-app-fix"}, rec.args)
+	require.NoError(t, tm.Close("pm-app-fix"))
+	assert.Equal(t, []string{"kill-session", "-t", "pm-app-fix"}, rec.args)
+
+	rec.err = errors.New("can't find session: gone")
+	assert.ErrorIs(t, tm.Focus("gone"), ErrNotFound)
+	assert.ErrorContains(t, tm.Close("gone"), "close tmux session")
+
+	rec = &recorder{out: "false"}
+	assert.ErrorIs(t, (&ITerm{run: rec.run}).Focus("closed"), ErrNotFound)
+}
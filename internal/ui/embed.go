@@ -11,6 +11,13 @@ import (
 //go:embed all:dist
 var distFS embed.FS
 
+// pwaFS holds the service worker, web app manifest and icon. They are served
+// at fixed root paths (a service worker only controls pages under its own
+// path), outside the hashed bundle in dist.
+//
+//go:embed pwa
+var pwaFS embed.FS
+
 // DistFS returns the embedded dist/ filesystem with the "dist" prefix stripped.
 func DistFS() (fs.FS, error) {
 	return fs.Sub(distFS, "dist")
@@ -26,10 +33,25 @@ func Handler() (http.Handler, error) {
 	}
 
 	fileServer := http.FileServerFS(sub)
+	pwa, err := fs.Sub(pwaFS, "pwa")
+	if err != nil {
+		return nil, err
+	}
+	pwaServer := http.FileServerFS(pwa)
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Clean the path
 		p := path.Clean(r.URL.Path)
+		switch p {
+		case "/sw.js", "/manifest.webmanifest", "/icon.svg":
+			// Browsers must see service worker updates right away.
+			w.Header().Set("Cache-Control", "no-cache")
+			if p == "/manifest.webmanifest" {
+				w.Header().Set("Content-Type", "application/manifest+json")
+			}
+			pwaServer.ServeHTTP(w, r)
+			return
+		}
 		if p == "/" {
 			fileServer.ServeHTTP(w, r)
 			return
@@ -0,0 +1,41 @@
+// Service worker for pm serve: shows Web Push notifications about sessions
+// while the dashboard tab is in the background or closed.
+
+self.addEventListener("install", () => self.skipWaiting());
+self.addEventListener("activate", (event) => event.waitUntil(self.clients.claim()));
+
+self.addEventListener("push", (event) => {
+  let msg = {};
+  if (event.data) {
+    try {
+      msg = event.data.json();
+    } catch {
+      msg = { body: event.data.text() };
+    }
+  }
+  event.waitUntil(
+    self.registration.showNotification(msg.title || "pm", {
+      body: msg.body || "",
+      tag: msg.tag,
+      icon: "/icon.svg",
+      data: { url: msg.url || "/" },
+    }),
+  );
+});
+
+// Clicking a notification focuses an open dashboard tab on the linked page,
+// or opens one.
+self.addEventListener("notificationclick", (event) => {
+  event.notification.close();
+  const url = new URL(event.notification.data?.url || "/", self.location.origin).href;
+  event.waitUntil(
+    self.clients.matchAll({ type: "window", includeUncontrolled: true }).then((windows) => {
+      for (const win of windows) {
+        if (new URL(win.url).origin === self.location.origin && "focus" in win) {
+          return win.navigate(url).then((w) => (w || win).focus());
+        }
+      }
+      return self.clients.openWindow(url);
+    }),
+  );
+});
@@ -0,0 +1,138 @@
+// Package uireview decides whether a change needs a UI review by matching
+// the files it changes against glob rules: a project's own rules, or
+// defaults for the languages and frameworks it is written in.
+package uireview
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+// commonRules apply to every project without rules of its own.
+var commonRules = []string{
+	"ui/", "internal/ui/", "web/", "frontend/", "templates/", "static/", "public/",
+	"*.html", "*.css", "*.scss", "*.sass", "*.less",
+}
+
+// languageRules add rules by Project.Language and the languages of
+// Project.Languages.
+var languageRules = map[string][]string{
+	"typescript": {"*.tsx", "*.jsx", "*.vue", "*.svelte", "*.astro", "components/", "pages/"},
+	"javascript": {"*.tsx", "*.jsx", "*.vue", "*.svelte", "*.astro", "components/", "pages/"},
+	"vue":        {"*.vue"},
+	"svelte":     {"*.svelte"},
+	"go":         {"*.templ", "*.tmpl", "*.gohtml"},
+	"python":     {"*.jinja", "*.jinja2", "*.j2"},
+	"ruby":       {"app/views/", "app/assets/", "*.erb", "*.haml", "*.slim"},
+	"php":        {"resources/views/", "*.blade.php", "*.twig"},
+	"swift":      {"*.storyboard", "*.xib"},
+	"kotlin":     {"res/layout/", "*.compose.kt"},
+	"java":       {"res/layout/"},
+	"c#":         {"*.razor", "*.cshtml", "*.xaml"},
+}
+
+// Match is a changed file and the rule it matched.
+type Match struct {
+	File string `json:"file"`
+	Rule string `json:"rule"`
+}
+
+// ParseRules splits a comma-separated list of rules, dropping blanks and
+// repeats.
+func ParseRules(s string) []string {
+	var rules []string
+	seen := make(map[string]bool)
+	for _, r := range strings.Split(s, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" || seen[r] {
+			continue
+		}
+		seen[r] = true
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// Validate returns an error for the first rule that is not a valid glob.
+func Validate(rules []string) error {
+	for _, r := range rules {
+		if _, err := path.Match(strings.Trim(r, "/"), ""); err != nil || strings.Trim(r, "/") == "" {
+			return fmt.Errorf("invalid UI review rule %q", r)
+		}
+	}
+	return nil
+}
+
+// DefaultRules returns the rules for projects written in languages: the
+// common ones plus those of each language, without repeats.
+func DefaultRules(languages ...string) []string {
+	all := append([]string(nil), commonRules...)
+	for _, lang := range languages {
+		all = append(all, languageRules[strings.ToLower(lang)]...)
+	}
+	return ParseRules(strings.Join(all, ","))
+}
+
+// Rules returns the project's rules: Project.UIReviewPaths when set,
+// otherwise the defaults for its languages.
+func Rules(p *models.Project) []string {
+	if rules := ParseRules(p.UIReviewPaths); len(rules) > 0 {
+		return rules
+	}
+	languages := []string{p.Language}
+	for _, l := range p.Languages {
+		languages = append(languages, l.Language)
+	}
+	return DefaultRules(languages...)
+}
+
+// Classify returns the files that match a rule, each with the first rule it
+// matched, in the order of files. Files are paths from the project
+// directory.
+func Classify(rules, files []string) []Match {
+	var matches []Match
+	for _, f := range files {
+		for _, r := range rules {
+			if MatchRule(r, f) {
+				matches = append(matches, Match{File: f, Rule: r})
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// MatchRule reports whether file matches rule. A rule ending in "/" names a
+// directory and matches the files under it; other rules are globs matched
+// with path.Match. Like .gitignore patterns, a rule with a "/" other than a
+// trailing one is anchored at the project directory ("internal/ui/",
+// "docs/*.html"), and one without matches at any depth ("templates/",
+// "*.tsx").
+func MatchRule(rule, file string) bool {
+	file = strings.TrimPrefix(path.Clean(file), "./")
+	dir := strings.HasSuffix(rule, "/")
+	pattern := strings.TrimPrefix(strings.TrimSuffix(rule, "/"), "/")
+	if pattern == "" {
+		return false
+	}
+	anchored := strings.Contains(strings.TrimSuffix(rule, "/"), "/")
+	segments := strings.Split(file, "/")
+	size := strings.Count(pattern, "/") + 1
+
+	for start := 0; start+size <= len(segments); start++ {
+		if anchored && start > 0 {
+			break
+		}
+		end := start + size
+		if dir && end == len(segments) {
+			break // the file itself is not a directory
+		}
+		if ok, _ := path.Match(pattern, strings.Join(segments[start:end], "/")); ok {
+			return true
+		}
+	}
+	return false
+}
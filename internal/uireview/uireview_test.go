@@ -0,0 +1,70 @@
+package uireview
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joescharf/pm/internal/models"
+)
+
+func TestMatchRule(t *testing.T) {
+	tests := []struct {
+		rule, file string
+		want       bool
+	}{
+		{"ui/", "ui/src/App.tsx", true},
+		{"ui/", "web/ui/index.html", true},
+		{"ui/", "ui", false},
+		{"ui/", "build/gui/main.go", false},
+		{"internal/ui/", "internal/ui/dist/index.html", true},
+		{"internal/ui/", "cmd/internal/ui/x.go", false},
+		{"templates/", "app/templates/base.html", true},
+		{"*.tsx", "src/components/Button.tsx", true},
+		{"*.tsx", "src/components/Button.ts", false},
+		{"*.css", "style.css", true},
+		{"docs/*.html", "docs/index.html", true},
+		{"docs/*.html", "docs/api/index.html", false},
+		{"docs/*.html", "site/docs/index.html", false},
+		{"/static/", "static/logo.png", true},
+		{"/static/", "web/static/logo.png", false},
+		{"app/views/", "app/views/users/show.html.erb", true},
+		{"", "ui/app.tsx", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchRule(tt.rule, tt.file), "%s ~ %s", tt.rule, tt.file)
+	}
+}
+
+func TestRules(t *testing.T) {
+	goRules := Rules(&models.Project{Language: "go"})
+	assert.Contains(t, goRules, "ui/")
+	assert.Contains(t, goRules, "*.templ")
+	assert.NotContains(t, goRules, "*.tsx")
+
+	// The languages of the breakdown count too: a Go project with a React UI.
+	mixed := Rules(&models.Project{Language: "Go", Languages: []models.LanguageShare{{Language: "go"}, {Language: "typescript"}}})
+	assert.Contains(t, mixed, "*.templ")
+	assert.Contains(t, mixed, "*.tsx")
+
+	custom := Rules(&models.Project{Language: "go", UIReviewPaths: "assets/, *.gotmpl"})
+	assert.Equal(t, []string{"assets/", "*.gotmpl"}, custom, "the project's rules replace the defaults")
+}
+
+func TestClassify(t *testing.T) {
+	rules := DefaultRules("typescript")
+	matches := Classify(rules, []string{"README.md", "src/pages/Home.tsx", "internal/api/api.go", "ui/index.css"})
+	assert.Equal(t, []Match{
+		{File: "src/pages/Home.tsx", Rule: "*.tsx"},
+		{File: "ui/index.css", Rule: "ui/"},
+	}, matches)
+	assert.Empty(t, Classify(rules, []string{"main.go"}))
+}
+
+func TestParseAndValidate(t *testing.T) {
+	rules := ParseRules(" *.tsx, ,templates/,*.tsx")
+	assert.Equal(t, []string{"*.tsx", "templates/"}, rules)
+	assert.NoError(t, Validate(rules))
+	assert.Error(t, Validate([]string{"[ui"}))
+	assert.Error(t, Validate([]string{"/"}))
+}
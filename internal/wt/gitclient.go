@@ -2,6 +2,7 @@ package wt
 
 import (
 	"fmt"
+	"log/slog"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -12,6 +13,7 @@ import (
 // repoBoundGitopsClient implements gitops.Client for a specific repository path.
 type repoBoundGitopsClient struct {
 	repoPath string
+	sparse   []string // cone paths for worktrees it adds; empty = full checkout
 }
 
 func newRepoBoundGitopsClient(repoPath string) *repoBoundGitopsClient {
@@ -51,19 +53,53 @@ func (c *repoBoundGitopsClient) WorktreeList() ([]gitops.WorktreeInfo, error) {
 }
 
 func (c *repoBoundGitopsClient) WorktreeAdd(path, branch, base string, newBranch bool) error {
-	var args []string
+	args := []string{"-C", c.repoPath, "worktree", "add"}
+	if len(c.sparse) > 0 {
+		args = append(args, "--no-checkout")
+	}
 	if newBranch {
-		args = []string{"-C", c.repoPath, "worktree", "add", "-b", branch, path, base}
+		args = append(args, "-b", branch, path, base)
 	} else {
-		args = []string{"-C", c.repoPath, "worktree", "add", path, branch}
+		args = append(args, path, branch)
 	}
 	out, err := exec.Command("git", args...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("git worktree add: %s", strings.TrimSpace(string(out)))
 	}
+	if len(c.sparse) > 0 {
+		return c.checkoutSparse(path)
+	}
 	return nil
 }
 
+// checkoutSparse populates a worktree added with --no-checkout, limited to
+// the client's sparse paths. When git cannot set up the sparse-checkout (too
+// old, or a bad path) the worktree falls back to a full checkout.
+func (c *repoBoundGitopsClient) checkoutSparse(path string) error {
+	args := append([]string{"sparse-checkout", "set", "--cone", "--"}, c.sparse...)
+	if _, err := c.gitAt(path, args...); err != nil {
+		slog.Warn("sparse-checkout failed; checking out the full worktree", "path", path, "error", err)
+		_, _ = c.gitAt(path, "sparse-checkout", "disable")
+	}
+	if _, err := c.gitAt(path, "checkout"); err != nil {
+		return fmt.Errorf("check out worktree: %w", err)
+	}
+	return nil
+}
+
+// sparsePaths returns the cone paths of a sparse worktree, or nil for a full
+// checkout.
+func (c *repoBoundGitopsClient) sparsePaths(path string) []string {
+	if on, _ := c.gitAt(path, "config", "--get", "core.sparseCheckout"); on != "true" {
+		return nil
+	}
+	out, err := c.gitAt(path, "sparse-checkout", "list")
+	if err != nil || out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
+
 func (c *repoBoundGitopsClient) WorktreeRemove(path string, force bool) error {
 	args := []string{"-C", c.repoPath, "worktree", "remove"}
 	if force {
@@ -22,12 +22,25 @@ type WorktreeInfo struct {
 // Client wraps the wt lifecycle for worktree operations.
 type Client interface {
 	Create(repoPath, branch string) error
+	CreateHeadless(repoPath, branch string) error
+	CreateWith(repoPath, branch string, opts CreateOptions) (sparse []string, err error)
 	List(repoPath string) ([]WorktreeInfo, error)
 	Delete(repoPath, branch string) error
+	TerminalID(worktreePath string) string
 	Lifecycle() *lifecycle.Manager
 	LifecycleForRepo(repoPath string) *lifecycle.Manager
 }
 
+// CreateOptions configures CreateWith.
+type CreateOptions struct {
+	// Headless skips opening an iTerm window, for callers that spawn their
+	// own terminal. An existing worktree is left as is.
+	Headless bool
+	// SparsePaths limits a new worktree to these directories with a cone-mode
+	// sparse-checkout. Empty means a full checkout.
+	SparsePaths []string
+}
+
 // RealClient implements Client using wt library packages.
 type RealClient struct {
 	itermClient iterm.Client
@@ -57,12 +70,43 @@ func NewClient() *RealClient {
 }
 
 func (c *RealClient) Create(repoPath, branch string) error {
+	_, err := c.CreateWith(repoPath, branch, CreateOptions{})
+	return err
+}
+
+// CreateHeadless creates the worktree without opening an iTerm window, for
+// callers that spawn their own terminal. An existing worktree is left as is.
+func (c *RealClient) CreateHeadless(repoPath, branch string) error {
+	_, err := c.CreateWith(repoPath, branch, CreateOptions{Headless: true})
+	return err
+}
+
+// CreateWith creates the worktree for branch as configured by opts. It
+// returns the sparse-checkout paths the worktree ends up with: nil for a full
+// checkout, including when git could not set up the sparse-checkout and the
+// worktree fell back to a full one, and the existing paths of a worktree that
+// was already there.
+func (c *RealClient) CreateWith(repoPath, branch string, opts CreateOptions) ([]string, error) {
+	wtPath := filepath.Join(repoPath+".worktrees", gitops.BranchToDirname(branch))
 	git := newRepoBoundGitopsClient(repoPath)
+	git.sparse = opts.SparsePaths
+
+	if opts.Headless {
+		if info, err := os.Stat(wtPath); err == nil && info.IsDir() {
+			return git.sparsePaths(wtPath), nil
+		}
+		lm := lifecycle.NewManager(git, nil, c.stateMgr, c.trustMgr, nil)
+		if _, err := lm.Create(context.Background(), lifecycle.CreateOptions{Branch: branch}); err != nil {
+			return nil, err
+		}
+		return git.sparsePaths(wtPath), nil
+	}
+
 	lm := lifecycle.NewManager(git, c.itermClient, c.stateMgr, c.trustMgr, nil)
-	_, err := lm.Create(context.Background(), lifecycle.CreateOptions{
-		Branch: branch,
-	})
-	return err
+	if _, err := lm.Create(context.Background(), lifecycle.CreateOptions{Branch: branch}); err != nil {
+		return nil, err
+	}
+	return git.sparsePaths(wtPath), nil
 }
 
 func (c *RealClient) List(repoPath string) ([]WorktreeInfo, error) {
@@ -96,6 +140,16 @@ func (c *RealClient) Delete(repoPath, branch string) error {
 	})
 }
 
+// TerminalID returns the iTerm session unique ID of the window wt opened for
+// the worktree, or "" if wt has none recorded.
+func (c *RealClient) TerminalID(worktreePath string) string {
+	ws, err := c.stateMgr.GetWorktree(worktreePath)
+	if err != nil || ws == nil {
+		return ""
+	}
+	return ws.ClaudeSessionID
+}
+
 // Lifecycle returns a lifecycle.Manager with shared iTerm/state/trust dependencies
 // but NO git client (caller must provide repo-specific context).
 func (c *RealClient) Lifecycle() *lifecycle.Manager {
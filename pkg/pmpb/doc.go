@@ -0,0 +1,4 @@
+// Package pmpb holds the protobuf types and gRPC client and server stubs for
+// the pm gRPC API served by pm serve --grpc. The code is generated from
+// pm.proto; run make proto after editing it.
+package pmpb
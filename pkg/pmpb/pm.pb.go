@@ -0,0 +1,2077 @@
+// The pm gRPC API. It mirrors the REST API under /api/v1 for projects,
+// issues, sessions and agent operations; see docs/docs/api.md for the
+// behavior of each call. Regenerate the Go code with `make proto`.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: pkg/pmpb/pm.proto
+
+package pmpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Project struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name              string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Path              string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Description       string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	RepoUrl           string                 `protobuf:"bytes,5,opt,name=repo_url,json=repoUrl,proto3" json:"repo_url,omitempty"`
+	Language          string                 `protobuf:"bytes,6,opt,name=language,proto3" json:"language,omitempty"`
+	GroupName         string                 `protobuf:"bytes,7,opt,name=group_name,json=groupName,proto3" json:"group_name,omitempty"`
+	MaxInProgress     int32                  `protobuf:"varint,8,opt,name=max_in_progress,json=maxInProgress,proto3" json:"max_in_progress,omitempty"`
+	MaxActiveSessions int32                  `protobuf:"varint,9,opt,name=max_active_sessions,json=maxActiveSessions,proto3" json:"max_active_sessions,omitempty"`
+	WipHardBlock      bool                   `protobuf:"varint,10,opt,name=wip_hard_block,json=wipHardBlock,proto3" json:"wip_hard_block,omitempty"`
+	Archived          bool                   `protobuf:"varint,11,opt,name=archived,proto3" json:"archived,omitempty"`
+	CreatedAt         *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt         *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Project) Reset() {
+	*x = Project{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Project) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Project) ProtoMessage() {}
+
+func (x *Project) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Project.ProtoReflect.Descriptor instead.
+func (*Project) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Project) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Project) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Project) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Project) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Project) GetRepoUrl() string {
+	if x != nil {
+		return x.RepoUrl
+	}
+	return ""
+}
+
+func (x *Project) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *Project) GetGroupName() string {
+	if x != nil {
+		return x.GroupName
+	}
+	return ""
+}
+
+func (x *Project) GetMaxInProgress() int32 {
+	if x != nil {
+		return x.MaxInProgress
+	}
+	return 0
+}
+
+func (x *Project) GetMaxActiveSessions() int32 {
+	if x != nil {
+		return x.MaxActiveSessions
+	}
+	return 0
+}
+
+func (x *Project) GetWipHardBlock() bool {
+	if x != nil {
+		return x.WipHardBlock
+	}
+	return false
+}
+
+func (x *Project) GetArchived() bool {
+	if x != nil {
+		return x.Archived
+	}
+	return false
+}
+
+func (x *Project) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Project) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ListProjectsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Archived      bool                   `protobuf:"varint,2,opt,name=archived,proto3" json:"archived,omitempty"` // list archived projects instead
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProjectsRequest) Reset() {
+	*x = ListProjectsRequest{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProjectsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProjectsRequest) ProtoMessage() {}
+
+func (x *ListProjectsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProjectsRequest.ProtoReflect.Descriptor instead.
+func (*ListProjectsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListProjectsRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *ListProjectsRequest) GetArchived() bool {
+	if x != nil {
+		return x.Archived
+	}
+	return false
+}
+
+type ListProjectsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Projects      []*Project             `protobuf:"bytes,1,rep,name=projects,proto3" json:"projects,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProjectsResponse) Reset() {
+	*x = ListProjectsResponse{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProjectsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProjectsResponse) ProtoMessage() {}
+
+func (x *ListProjectsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProjectsResponse.ProtoReflect.Descriptor instead.
+func (*ListProjectsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListProjectsResponse) GetProjects() []*Project {
+	if x != nil {
+		return x.Projects
+	}
+	return nil
+}
+
+type GetProjectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProjectRequest) Reset() {
+	*x = GetProjectRequest{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProjectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProjectRequest) ProtoMessage() {}
+
+func (x *GetProjectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProjectRequest.ProtoReflect.Descriptor instead.
+func (*GetProjectRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetProjectRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type CreateProjectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	RepoUrl       string                 `protobuf:"bytes,4,opt,name=repo_url,json=repoUrl,proto3" json:"repo_url,omitempty"`
+	Language      string                 `protobuf:"bytes,5,opt,name=language,proto3" json:"language,omitempty"`
+	GroupName     string                 `protobuf:"bytes,6,opt,name=group_name,json=groupName,proto3" json:"group_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateProjectRequest) Reset() {
+	*x = CreateProjectRequest{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateProjectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateProjectRequest) ProtoMessage() {}
+
+func (x *CreateProjectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateProjectRequest.ProtoReflect.Descriptor instead.
+func (*CreateProjectRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateProjectRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateProjectRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *CreateProjectRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateProjectRequest) GetRepoUrl() string {
+	if x != nil {
+		return x.RepoUrl
+	}
+	return ""
+}
+
+func (x *CreateProjectRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *CreateProjectRequest) GetGroupName() string {
+	if x != nil {
+		return x.GroupName
+	}
+	return ""
+}
+
+type DeleteProjectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProjectRequest) Reset() {
+	*x = DeleteProjectRequest{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProjectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProjectRequest) ProtoMessage() {}
+
+func (x *DeleteProjectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProjectRequest.ProtoReflect.Descriptor instead.
+func (*DeleteProjectRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteProjectRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type Issue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProjectId     string                 `protobuf:"bytes,2,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Body          string                 `protobuf:"bytes,5,opt,name=body,proto3" json:"body,omitempty"`
+	AiPrompt      string                 `protobuf:"bytes,6,opt,name=ai_prompt,json=aiPrompt,proto3" json:"ai_prompt,omitempty"`
+	Status        string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`     // open, in_progress, in_review, done, closed
+	Priority      string                 `protobuf:"bytes,8,opt,name=priority,proto3" json:"priority,omitempty"` // low, medium, high
+	Type          string                 `protobuf:"bytes,9,opt,name=type,proto3" json:"type,omitempty"`         // feature, bug, chore
+	Tags          []string               `protobuf:"bytes,10,rep,name=tags,proto3" json:"tags,omitempty"`
+	GithubIssue   int32                  `protobuf:"varint,11,opt,name=github_issue,json=githubIssue,proto3" json:"github_issue,omitempty"`
+	Assignee      string                 `protobuf:"bytes,12,opt,name=assignee,proto3" json:"assignee,omitempty"`
+	Estimate      string                 `protobuf:"bytes,13,opt,name=estimate,proto3" json:"estimate,omitempty"`
+	SlaState      string                 `protobuf:"bytes,14,opt,name=sla_state,json=slaState,proto3" json:"sla_state,omitempty"` // read-only
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	ClosedAt      *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=closed_at,json=closedAt,proto3" json:"closed_at,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`       // read-only
+	CompletedAt   *timestamppb.Timestamp `protobuf:"bytes,19,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"` // read-only
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Issue) Reset() {
+	*x = Issue{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Issue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Issue) ProtoMessage() {}
+
+func (x *Issue) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Issue.ProtoReflect.Descriptor instead.
+func (*Issue) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Issue) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Issue) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *Issue) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Issue) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Issue) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *Issue) GetAiPrompt() string {
+	if x != nil {
+		return x.AiPrompt
+	}
+	return ""
+}
+
+func (x *Issue) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Issue) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *Issue) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Issue) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Issue) GetGithubIssue() int32 {
+	if x != nil {
+		return x.GithubIssue
+	}
+	return 0
+}
+
+func (x *Issue) GetAssignee() string {
+	if x != nil {
+		return x.Assignee
+	}
+	return ""
+}
+
+func (x *Issue) GetEstimate() string {
+	if x != nil {
+		return x.Estimate
+	}
+	return ""
+}
+
+func (x *Issue) GetSlaState() string {
+	if x != nil {
+		return x.SlaState
+	}
+	return ""
+}
+
+func (x *Issue) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Issue) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Issue) GetClosedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ClosedAt
+	}
+	return nil
+}
+
+func (x *Issue) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Issue) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+type ListIssuesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId     string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Priority      string                 `protobuf:"bytes,3,opt,name=priority,proto3" json:"priority,omitempty"`
+	Tag           string                 `protobuf:"bytes,4,opt,name=tag,proto3" json:"tag,omitempty"`
+	Assignee      string                 `protobuf:"bytes,5,opt,name=assignee,proto3" json:"assignee,omitempty"`
+	Sort          string                 `protobuf:"bytes,6,opt,name=sort,proto3" json:"sort,omitempty"` // "smart" or empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListIssuesRequest) Reset() {
+	*x = ListIssuesRequest{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListIssuesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListIssuesRequest) ProtoMessage() {}
+
+func (x *ListIssuesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListIssuesRequest.ProtoReflect.Descriptor instead.
+func (*ListIssuesRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListIssuesRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *ListIssuesRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListIssuesRequest) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *ListIssuesRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *ListIssuesRequest) GetAssignee() string {
+	if x != nil {
+		return x.Assignee
+	}
+	return ""
+}
+
+func (x *ListIssuesRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+type ListIssuesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Issues        []*Issue               `protobuf:"bytes,1,rep,name=issues,proto3" json:"issues,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListIssuesResponse) Reset() {
+	*x = ListIssuesResponse{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListIssuesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListIssuesResponse) ProtoMessage() {}
+
+func (x *ListIssuesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListIssuesResponse.ProtoReflect.Descriptor instead.
+func (*ListIssuesResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListIssuesResponse) GetIssues() []*Issue {
+	if x != nil {
+		return x.Issues
+	}
+	return nil
+}
+
+type GetIssueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetIssueRequest) Reset() {
+	*x = GetIssueRequest{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetIssueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetIssueRequest) ProtoMessage() {}
+
+func (x *GetIssueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetIssueRequest.ProtoReflect.Descriptor instead.
+func (*GetIssueRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetIssueRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type CreateIssueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId     string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Body          string                 `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	AiPrompt      string                 `protobuf:"bytes,5,opt,name=ai_prompt,json=aiPrompt,proto3" json:"ai_prompt,omitempty"`
+	Status        string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`     // default open
+	Priority      string                 `protobuf:"bytes,7,opt,name=priority,proto3" json:"priority,omitempty"` // default medium
+	Type          string                 `protobuf:"bytes,8,opt,name=type,proto3" json:"type,omitempty"`         // default feature
+	Assignee      string                 `protobuf:"bytes,9,opt,name=assignee,proto3" json:"assignee,omitempty"`
+	Estimate      string                 `protobuf:"bytes,10,opt,name=estimate,proto3" json:"estimate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateIssueRequest) Reset() {
+	*x = CreateIssueRequest{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateIssueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateIssueRequest) ProtoMessage() {}
+
+func (x *CreateIssueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateIssueRequest.ProtoReflect.Descriptor instead.
+func (*CreateIssueRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CreateIssueRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *CreateIssueRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateIssueRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateIssueRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *CreateIssueRequest) GetAiPrompt() string {
+	if x != nil {
+		return x.AiPrompt
+	}
+	return ""
+}
+
+func (x *CreateIssueRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CreateIssueRequest) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *CreateIssueRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *CreateIssueRequest) GetAssignee() string {
+	if x != nil {
+		return x.Assignee
+	}
+	return ""
+}
+
+func (x *CreateIssueRequest) GetEstimate() string {
+	if x != nil {
+		return x.Estimate
+	}
+	return ""
+}
+
+type DeleteIssueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteIssueRequest) Reset() {
+	*x = DeleteIssueRequest{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteIssueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteIssueRequest) ProtoMessage() {}
+
+func (x *DeleteIssueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteIssueRequest.ProtoReflect.Descriptor instead.
+func (*DeleteIssueRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DeleteIssueRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type Session struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProjectId         string                 `protobuf:"bytes,2,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	ProjectName       string                 `protobuf:"bytes,3,opt,name=project_name,json=projectName,proto3" json:"project_name,omitempty"`
+	IssueId           string                 `protobuf:"bytes,4,opt,name=issue_id,json=issueId,proto3" json:"issue_id,omitempty"`
+	Branch            string                 `protobuf:"bytes,5,opt,name=branch,proto3" json:"branch,omitempty"`
+	WorktreePath      string                 `protobuf:"bytes,6,opt,name=worktree_path,json=worktreePath,proto3" json:"worktree_path,omitempty"`
+	Status            string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"` // active, idle, blocked, completed, abandoned
+	Outcome           string                 `protobuf:"bytes,8,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	CommitCount       int32                  `protobuf:"varint,9,opt,name=commit_count,json=commitCount,proto3" json:"commit_count,omitempty"`
+	LastCommitHash    string                 `protobuf:"bytes,10,opt,name=last_commit_hash,json=lastCommitHash,proto3" json:"last_commit_hash,omitempty"`
+	LastCommitMessage string                 `protobuf:"bytes,11,opt,name=last_commit_message,json=lastCommitMessage,proto3" json:"last_commit_message,omitempty"`
+	ConflictState     string                 `protobuf:"bytes,12,opt,name=conflict_state,json=conflictState,proto3" json:"conflict_state,omitempty"`
+	BlockedReason     string                 `protobuf:"bytes,13,opt,name=blocked_reason,json=blockedReason,proto3" json:"blocked_reason,omitempty"`
+	UnblockCondition  string                 `protobuf:"bytes,14,opt,name=unblock_condition,json=unblockCondition,proto3" json:"unblock_condition,omitempty"`
+	Labels            []string               `protobuf:"bytes,15,rep,name=labels,proto3" json:"labels,omitempty"`
+	StartedAt         *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	EndedAt           *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=ended_at,json=endedAt,proto3" json:"ended_at,omitempty"`
+	LastActiveAt      *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=last_active_at,json=lastActiveAt,proto3" json:"last_active_at,omitempty"`
+	OutcomeClass      string                 `protobuf:"bytes,19,opt,name=outcome_class,json=outcomeClass,proto3" json:"outcome_class,omitempty"` // set when the session ends
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Session) Reset() {
+	*x = Session{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Session) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Session) ProtoMessage() {}
+
+func (x *Session) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Session.ProtoReflect.Descriptor instead.
+func (*Session) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Session) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Session) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *Session) GetProjectName() string {
+	if x != nil {
+		return x.ProjectName
+	}
+	return ""
+}
+
+func (x *Session) GetIssueId() string {
+	if x != nil {
+		return x.IssueId
+	}
+	return ""
+}
+
+func (x *Session) GetBranch() string {
+	if x != nil {
+		return x.Branch
+	}
+	return ""
+}
+
+func (x *Session) GetWorktreePath() string {
+	if x != nil {
+		return x.WorktreePath
+	}
+	return ""
+}
+
+func (x *Session) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Session) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+func (x *Session) GetCommitCount() int32 {
+	if x != nil {
+		return x.CommitCount
+	}
+	return 0
+}
+
+func (x *Session) GetLastCommitHash() string {
+	if x != nil {
+		return x.LastCommitHash
+	}
+	return ""
+}
+
+func (x *Session) GetLastCommitMessage() string {
+	if x != nil {
+		return x.LastCommitMessage
+	}
+	return ""
+}
+
+func (x *Session) GetConflictState() string {
+	if x != nil {
+		return x.ConflictState
+	}
+	return ""
+}
+
+func (x *Session) GetBlockedReason() string {
+	if x != nil {
+		return x.BlockedReason
+	}
+	return ""
+}
+
+func (x *Session) GetUnblockCondition() string {
+	if x != nil {
+		return x.UnblockCondition
+	}
+	return ""
+}
+
+func (x *Session) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Session) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Session) GetEndedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndedAt
+	}
+	return nil
+}
+
+func (x *Session) GetLastActiveAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastActiveAt
+	}
+	return nil
+}
+
+func (x *Session) GetOutcomeClass() string {
+	if x != nil {
+		return x.OutcomeClass
+	}
+	return ""
+}
+
+type ListSessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId     string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	Statuses      []string               `protobuf:"bytes,2,rep,name=statuses,proto3" json:"statuses,omitempty"` // empty: every status
+	Label         string                 `protobuf:"bytes,3,opt,name=label,proto3" json:"label,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsRequest) Reset() {
+	*x = ListSessionsRequest{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsRequest) ProtoMessage() {}
+
+func (x *ListSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsRequest.ProtoReflect.Descriptor instead.
+func (*ListSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListSessionsRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *ListSessionsRequest) GetStatuses() []string {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *ListSessionsRequest) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+type ListSessionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sessions      []*Session             `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsResponse) Reset() {
+	*x = ListSessionsResponse{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsResponse) ProtoMessage() {}
+
+func (x *ListSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsResponse.ProtoReflect.Descriptor instead.
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListSessionsResponse) GetSessions() []*Session {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+type GetSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSessionRequest) Reset() {
+	*x = GetSessionRequest{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSessionRequest) ProtoMessage() {}
+
+func (x *GetSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSessionRequest.ProtoReflect.Descriptor instead.
+func (*GetSessionRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetSessionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type LaunchAgentRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId          string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	IssueIds           []string               `protobuf:"bytes,2,rep,name=issue_ids,json=issueIds,proto3" json:"issue_ids,omitempty"`
+	Spawn              bool                   `protobuf:"varint,3,opt,name=spawn,proto3" json:"spawn,omitempty"`
+	SnapshotHumanEdits bool                   `protobuf:"varint,4,opt,name=snapshot_human_edits,json=snapshotHumanEdits,proto3" json:"snapshot_human_edits,omitempty"`
+	Sparse             bool                   `protobuf:"varint,5,opt,name=sparse,proto3" json:"sparse,omitempty"`
+	SparsePaths        []string               `protobuf:"bytes,6,rep,name=sparse_paths,json=sparsePaths,proto3" json:"sparse_paths,omitempty"`
+	Labels             []string               `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *LaunchAgentRequest) Reset() {
+	*x = LaunchAgentRequest{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LaunchAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LaunchAgentRequest) ProtoMessage() {}
+
+func (x *LaunchAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LaunchAgentRequest.ProtoReflect.Descriptor instead.
+func (*LaunchAgentRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *LaunchAgentRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *LaunchAgentRequest) GetIssueIds() []string {
+	if x != nil {
+		return x.IssueIds
+	}
+	return nil
+}
+
+func (x *LaunchAgentRequest) GetSpawn() bool {
+	if x != nil {
+		return x.Spawn
+	}
+	return false
+}
+
+func (x *LaunchAgentRequest) GetSnapshotHumanEdits() bool {
+	if x != nil {
+		return x.SnapshotHumanEdits
+	}
+	return false
+}
+
+func (x *LaunchAgentRequest) GetSparse() bool {
+	if x != nil {
+		return x.Sparse
+	}
+	return false
+}
+
+func (x *LaunchAgentRequest) GetSparsePaths() []string {
+	if x != nil {
+		return x.SparsePaths
+	}
+	return nil
+}
+
+func (x *LaunchAgentRequest) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type LaunchAgentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Branch        string                 `protobuf:"bytes,2,opt,name=branch,proto3" json:"branch,omitempty"`
+	WorktreePath  string                 `protobuf:"bytes,3,opt,name=worktree_path,json=worktreePath,proto3" json:"worktree_path,omitempty"`
+	Command       string                 `protobuf:"bytes,4,opt,name=command,proto3" json:"command,omitempty"`
+	Conventions   string                 `protobuf:"bytes,5,opt,name=conventions,proto3" json:"conventions,omitempty"`
+	WipWarnings   []string               `protobuf:"bytes,6,rep,name=wip_warnings,json=wipWarnings,proto3" json:"wip_warnings,omitempty"`
+	SparsePaths   []string               `protobuf:"bytes,7,rep,name=sparse_paths,json=sparsePaths,proto3" json:"sparse_paths,omitempty"`
+	SpawnError    string                 `protobuf:"bytes,8,opt,name=spawn_error,json=spawnError,proto3" json:"spawn_error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LaunchAgentResponse) Reset() {
+	*x = LaunchAgentResponse{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LaunchAgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LaunchAgentResponse) ProtoMessage() {}
+
+func (x *LaunchAgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LaunchAgentResponse.ProtoReflect.Descriptor instead.
+func (*LaunchAgentResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *LaunchAgentResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *LaunchAgentResponse) GetBranch() string {
+	if x != nil {
+		return x.Branch
+	}
+	return ""
+}
+
+func (x *LaunchAgentResponse) GetWorktreePath() string {
+	if x != nil {
+		return x.WorktreePath
+	}
+	return ""
+}
+
+func (x *LaunchAgentResponse) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *LaunchAgentResponse) GetConventions() string {
+	if x != nil {
+		return x.Conventions
+	}
+	return ""
+}
+
+func (x *LaunchAgentResponse) GetWipWarnings() []string {
+	if x != nil {
+		return x.WipWarnings
+	}
+	return nil
+}
+
+func (x *LaunchAgentResponse) GetSparsePaths() []string {
+	if x != nil {
+		return x.SparsePaths
+	}
+	return nil
+}
+
+func (x *LaunchAgentResponse) GetSpawnError() string {
+	if x != nil {
+		return x.SpawnError
+	}
+	return ""
+}
+
+type CloseAgentRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	SessionId        string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Status           string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // idle (default), completed, abandoned, blocked
+	Force            bool                   `protobuf:"varint,3,opt,name=force,proto3" json:"force,omitempty"`
+	Reason           string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	UnblockCondition string                 `protobuf:"bytes,5,opt,name=unblock_condition,json=unblockCondition,proto3" json:"unblock_condition,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *CloseAgentRequest) Reset() {
+	*x = CloseAgentRequest{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseAgentRequest) ProtoMessage() {}
+
+func (x *CloseAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseAgentRequest.ProtoReflect.Descriptor instead.
+func (*CloseAgentRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CloseAgentRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *CloseAgentRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CloseAgentRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+func (x *CloseAgentRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *CloseAgentRequest) GetUnblockCondition() string {
+	if x != nil {
+		return x.UnblockCondition
+	}
+	return ""
+}
+
+type CloseAgentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	EndedAt       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=ended_at,json=endedAt,proto3" json:"ended_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseAgentResponse) Reset() {
+	*x = CloseAgentResponse{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseAgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseAgentResponse) ProtoMessage() {}
+
+func (x *CloseAgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseAgentResponse.ProtoReflect.Descriptor instead.
+func (*CloseAgentResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CloseAgentResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *CloseAgentResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CloseAgentResponse) GetEndedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndedAt
+	}
+	return nil
+}
+
+type NextAgentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId     string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	Assignee      string                 `protobuf:"bytes,2,opt,name=assignee,proto3" json:"assignee,omitempty"`
+	Spawn         bool                   `protobuf:"varint,3,opt,name=spawn,proto3" json:"spawn,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NextAgentRequest) Reset() {
+	*x = NextAgentRequest{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NextAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NextAgentRequest) ProtoMessage() {}
+
+func (x *NextAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NextAgentRequest.ProtoReflect.Descriptor instead.
+func (*NextAgentRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *NextAgentRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *NextAgentRequest) GetAssignee() string {
+	if x != nil {
+		return x.Assignee
+	}
+	return ""
+}
+
+func (x *NextAgentRequest) GetSpawn() bool {
+	if x != nil {
+		return x.Spawn
+	}
+	return false
+}
+
+type NextAgentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Launch        *LaunchAgentResponse   `protobuf:"bytes,1,opt,name=launch,proto3" json:"launch,omitempty"`
+	ProjectId     string                 `protobuf:"bytes,2,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	ProjectName   string                 `protobuf:"bytes,3,opt,name=project_name,json=projectName,proto3" json:"project_name,omitempty"`
+	IssueId       string                 `protobuf:"bytes,4,opt,name=issue_id,json=issueId,proto3" json:"issue_id,omitempty"`
+	IssueTitle    string                 `protobuf:"bytes,5,opt,name=issue_title,json=issueTitle,proto3" json:"issue_title,omitempty"`
+	Score         int32                  `protobuf:"varint,6,opt,name=score,proto3" json:"score,omitempty"`
+	Reasons       []string               `protobuf:"bytes,7,rep,name=reasons,proto3" json:"reasons,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NextAgentResponse) Reset() {
+	*x = NextAgentResponse{}
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NextAgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NextAgentResponse) ProtoMessage() {}
+
+func (x *NextAgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pmpb_pm_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NextAgentResponse.ProtoReflect.Descriptor instead.
+func (*NextAgentResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pmpb_pm_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *NextAgentResponse) GetLaunch() *LaunchAgentResponse {
+	if x != nil {
+		return x.Launch
+	}
+	return nil
+}
+
+func (x *NextAgentResponse) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *NextAgentResponse) GetProjectName() string {
+	if x != nil {
+		return x.ProjectName
+	}
+	return ""
+}
+
+func (x *NextAgentResponse) GetIssueId() string {
+	if x != nil {
+		return x.IssueId
+	}
+	return ""
+}
+
+func (x *NextAgentResponse) GetIssueTitle() string {
+	if x != nil {
+		return x.IssueTitle
+	}
+	return ""
+}
+
+func (x *NextAgentResponse) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *NextAgentResponse) GetReasons() []string {
+	if x != nil {
+		return x.Reasons
+	}
+	return nil
+}
+
+var File_pkg_pmpb_pm_proto protoreflect.FileDescriptor
+
+const file_pkg_pmpb_pm_proto_rawDesc = "" +
+	"\n" +
+	"\x11pkg/pmpb/pm.proto\x12\x05pm.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc9\x03\n" +
+	"\aProject\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x19\n" +
+	"\brepo_url\x18\x05 \x01(\tR\arepoUrl\x12\x1a\n" +
+	"\blanguage\x18\x06 \x01(\tR\blanguage\x12\x1d\n" +
+	"\n" +
+	"group_name\x18\a \x01(\tR\tgroupName\x12&\n" +
+	"\x0fmax_in_progress\x18\b \x01(\x05R\rmaxInProgress\x12.\n" +
+	"\x13max_active_sessions\x18\t \x01(\x05R\x11maxActiveSessions\x12$\n" +
+	"\x0ewip_hard_block\x18\n" +
+	" \x01(\bR\fwipHardBlock\x12\x1a\n" +
+	"\barchived\x18\v \x01(\bR\barchived\x129\n" +
+	"\n" +
+	"created_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"G\n" +
+	"\x13ListProjectsRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12\x1a\n" +
+	"\barchived\x18\x02 \x01(\bR\barchived\"B\n" +
+	"\x14ListProjectsResponse\x12*\n" +
+	"\bprojects\x18\x01 \x03(\v2\x0e.pm.v1.ProjectR\bprojects\"#\n" +
+	"\x11GetProjectRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xb6\x01\n" +
+	"\x14CreateProjectRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x19\n" +
+	"\brepo_url\x18\x04 \x01(\tR\arepoUrl\x12\x1a\n" +
+	"\blanguage\x18\x05 \x01(\tR\blanguage\x12\x1d\n" +
+	"\n" +
+	"group_name\x18\x06 \x01(\tR\tgroupName\"&\n" +
+	"\x14DeleteProjectRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x9c\x05\n" +
+	"\x05Issue\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x02 \x01(\tR\tprojectId\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04body\x18\x05 \x01(\tR\x04body\x12\x1b\n" +
+	"\tai_prompt\x18\x06 \x01(\tR\baiPrompt\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12\x1a\n" +
+	"\bpriority\x18\b \x01(\tR\bpriority\x12\x12\n" +
+	"\x04type\x18\t \x01(\tR\x04type\x12\x12\n" +
+	"\x04tags\x18\n" +
+	" \x03(\tR\x04tags\x12!\n" +
+	"\fgithub_issue\x18\v \x01(\x05R\vgithubIssue\x12\x1a\n" +
+	"\bassignee\x18\f \x01(\tR\bassignee\x12\x1a\n" +
+	"\bestimate\x18\r \x01(\tR\bestimate\x12\x1b\n" +
+	"\tsla_state\x18\x0e \x01(\tR\bslaState\x129\n" +
+	"\n" +
+	"created_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x127\n" +
+	"\tclosed_at\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\bclosedAt\x129\n" +
+	"\n" +
+	"started_at\x18\x12 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12=\n" +
+	"\fcompleted_at\x18\x13 \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\"\xa8\x01\n" +
+	"\x11ListIssuesRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1a\n" +
+	"\bpriority\x18\x03 \x01(\tR\bpriority\x12\x10\n" +
+	"\x03tag\x18\x04 \x01(\tR\x03tag\x12\x1a\n" +
+	"\bassignee\x18\x05 \x01(\tR\bassignee\x12\x12\n" +
+	"\x04sort\x18\x06 \x01(\tR\x04sort\":\n" +
+	"\x12ListIssuesResponse\x12$\n" +
+	"\x06issues\x18\x01 \x03(\v2\f.pm.v1.IssueR\x06issues\"!\n" +
+	"\x0fGetIssueRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x9c\x02\n" +
+	"\x12CreateIssueRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04body\x18\x04 \x01(\tR\x04body\x12\x1b\n" +
+	"\tai_prompt\x18\x05 \x01(\tR\baiPrompt\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12\x1a\n" +
+	"\bpriority\x18\a \x01(\tR\bpriority\x12\x12\n" +
+	"\x04type\x18\b \x01(\tR\x04type\x12\x1a\n" +
+	"\bassignee\x18\t \x01(\tR\bassignee\x12\x1a\n" +
+	"\bestimate\x18\n" +
+	" \x01(\tR\bestimate\"$\n" +
+	"\x12DeleteIssueRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xce\x05\n" +
+	"\aSession\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x02 \x01(\tR\tprojectId\x12!\n" +
+	"\fproject_name\x18\x03 \x01(\tR\vprojectName\x12\x19\n" +
+	"\bissue_id\x18\x04 \x01(\tR\aissueId\x12\x16\n" +
+	"\x06branch\x18\x05 \x01(\tR\x06branch\x12#\n" +
+	"\rworktree_path\x18\x06 \x01(\tR\fworktreePath\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12\x18\n" +
+	"\aoutcome\x18\b \x01(\tR\aoutcome\x12!\n" +
+	"\fcommit_count\x18\t \x01(\x05R\vcommitCount\x12(\n" +
+	"\x10last_commit_hash\x18\n" +
+	" \x01(\tR\x0elastCommitHash\x12.\n" +
+	"\x13last_commit_message\x18\v \x01(\tR\x11lastCommitMessage\x12%\n" +
+	"\x0econflict_state\x18\f \x01(\tR\rconflictState\x12%\n" +
+	"\x0eblocked_reason\x18\r \x01(\tR\rblockedReason\x12+\n" +
+	"\x11unblock_condition\x18\x0e \x01(\tR\x10unblockCondition\x12\x16\n" +
+	"\x06labels\x18\x0f \x03(\tR\x06labels\x129\n" +
+	"\n" +
+	"started_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x125\n" +
+	"\bended_at\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\aendedAt\x12@\n" +
+	"\x0elast_active_at\x18\x12 \x01(\v2\x1a.google.protobuf.TimestampR\flastActiveAt\x12#\n" +
+	"\routcome_class\x18\x13 \x01(\tR\foutcomeClass\"f\n" +
+	"\x13ListSessionsRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x1a\n" +
+	"\bstatuses\x18\x02 \x03(\tR\bstatuses\x12\x14\n" +
+	"\x05label\x18\x03 \x01(\tR\x05label\"B\n" +
+	"\x14ListSessionsResponse\x12*\n" +
+	"\bsessions\x18\x01 \x03(\v2\x0e.pm.v1.SessionR\bsessions\"#\n" +
+	"\x11GetSessionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xeb\x01\n" +
+	"\x12LaunchAgentRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x1b\n" +
+	"\tissue_ids\x18\x02 \x03(\tR\bissueIds\x12\x14\n" +
+	"\x05spawn\x18\x03 \x01(\bR\x05spawn\x120\n" +
+	"\x14snapshot_human_edits\x18\x04 \x01(\bR\x12snapshotHumanEdits\x12\x16\n" +
+	"\x06sparse\x18\x05 \x01(\bR\x06sparse\x12!\n" +
+	"\fsparse_paths\x18\x06 \x03(\tR\vsparsePaths\x12\x16\n" +
+	"\x06labels\x18\a \x03(\tR\x06labels\"\x94\x02\n" +
+	"\x13LaunchAgentResponse\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x16\n" +
+	"\x06branch\x18\x02 \x01(\tR\x06branch\x12#\n" +
+	"\rworktree_path\x18\x03 \x01(\tR\fworktreePath\x12\x18\n" +
+	"\acommand\x18\x04 \x01(\tR\acommand\x12 \n" +
+	"\vconventions\x18\x05 \x01(\tR\vconventions\x12!\n" +
+	"\fwip_warnings\x18\x06 \x03(\tR\vwipWarnings\x12!\n" +
+	"\fsparse_paths\x18\a \x03(\tR\vsparsePaths\x12\x1f\n" +
+	"\vspawn_error\x18\b \x01(\tR\n" +
+	"spawnError\"\xa5\x01\n" +
+	"\x11CloseAgentRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x14\n" +
+	"\x05force\x18\x03 \x01(\bR\x05force\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12+\n" +
+	"\x11unblock_condition\x18\x05 \x01(\tR\x10unblockCondition\"\x82\x01\n" +
+	"\x12CloseAgentResponse\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x125\n" +
+	"\bended_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\aendedAt\"c\n" +
+	"\x10NextAgentRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x1a\n" +
+	"\bassignee\x18\x02 \x01(\tR\bassignee\x12\x14\n" +
+	"\x05spawn\x18\x03 \x01(\bR\x05spawn\"\xf5\x01\n" +
+	"\x11NextAgentResponse\x122\n" +
+	"\x06launch\x18\x01 \x01(\v2\x1a.pm.v1.LaunchAgentResponseR\x06launch\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x02 \x01(\tR\tprojectId\x12!\n" +
+	"\fproject_name\x18\x03 \x01(\tR\vprojectName\x12\x19\n" +
+	"\bissue_id\x18\x04 \x01(\tR\aissueId\x12\x1f\n" +
+	"\vissue_title\x18\x05 \x01(\tR\n" +
+	"issueTitle\x12\x14\n" +
+	"\x05score\x18\x06 \x01(\x05R\x05score\x12\x18\n" +
+	"\areasons\x18\a \x03(\tR\areasons2\xed\x06\n" +
+	"\x02PM\x12G\n" +
+	"\fListProjects\x12\x1a.pm.v1.ListProjectsRequest\x1a\x1b.pm.v1.ListProjectsResponse\x126\n" +
+	"\n" +
+	"GetProject\x12\x18.pm.v1.GetProjectRequest\x1a\x0e.pm.v1.Project\x12<\n" +
+	"\rCreateProject\x12\x1b.pm.v1.CreateProjectRequest\x1a\x0e.pm.v1.Project\x12D\n" +
+	"\rDeleteProject\x12\x1b.pm.v1.DeleteProjectRequest\x1a\x16.google.protobuf.Empty\x12A\n" +
+	"\n" +
+	"ListIssues\x12\x18.pm.v1.ListIssuesRequest\x1a\x19.pm.v1.ListIssuesResponse\x120\n" +
+	"\bGetIssue\x12\x16.pm.v1.GetIssueRequest\x1a\f.pm.v1.Issue\x126\n" +
+	"\vCreateIssue\x12\x19.pm.v1.CreateIssueRequest\x1a\f.pm.v1.Issue\x12)\n" +
+	"\vUpdateIssue\x12\f.pm.v1.Issue\x1a\f.pm.v1.Issue\x12@\n" +
+	"\vDeleteIssue\x12\x19.pm.v1.DeleteIssueRequest\x1a\x16.google.protobuf.Empty\x12G\n" +
+	"\fListSessions\x12\x1a.pm.v1.ListSessionsRequest\x1a\x1b.pm.v1.ListSessionsResponse\x126\n" +
+	"\n" +
+	"GetSession\x12\x18.pm.v1.GetSessionRequest\x1a\x0e.pm.v1.Session\x12D\n" +
+	"\vLaunchAgent\x12\x19.pm.v1.LaunchAgentRequest\x1a\x1a.pm.v1.LaunchAgentResponse\x12A\n" +
+	"\n" +
+	"CloseAgent\x12\x18.pm.v1.CloseAgentRequest\x1a\x19.pm.v1.CloseAgentResponse\x12>\n" +
+	"\tNextAgent\x12\x17.pm.v1.NextAgentRequest\x1a\x18.pm.v1.NextAgentResponseB\"Z github.com/joescharf/pm/pkg/pmpbb\x06proto3"
+
+var (
+	file_pkg_pmpb_pm_proto_rawDescOnce sync.Once
+	file_pkg_pmpb_pm_proto_rawDescData []byte
+)
+
+func file_pkg_pmpb_pm_proto_rawDescGZIP() []byte {
+	file_pkg_pmpb_pm_proto_rawDescOnce.Do(func() {
+		file_pkg_pmpb_pm_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pkg_pmpb_pm_proto_rawDesc), len(file_pkg_pmpb_pm_proto_rawDesc)))
+	})
+	return file_pkg_pmpb_pm_proto_rawDescData
+}
+
+var file_pkg_pmpb_pm_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_pkg_pmpb_pm_proto_goTypes = []any{
+	(*Project)(nil),               // 0: pm.v1.Project
+	(*ListProjectsRequest)(nil),   // 1: pm.v1.ListProjectsRequest
+	(*ListProjectsResponse)(nil),  // 2: pm.v1.ListProjectsResponse
+	(*GetProjectRequest)(nil),     // 3: pm.v1.GetProjectRequest
+	(*CreateProjectRequest)(nil),  // 4: pm.v1.CreateProjectRequest
+	(*DeleteProjectRequest)(nil),  // 5: pm.v1.DeleteProjectRequest
+	(*Issue)(nil),                 // 6: pm.v1.Issue
+	(*ListIssuesRequest)(nil),     // 7: pm.v1.ListIssuesRequest
+	(*ListIssuesResponse)(nil),    // 8: pm.v1.ListIssuesResponse
+	(*GetIssueRequest)(nil),       // 9: pm.v1.GetIssueRequest
+	(*CreateIssueRequest)(nil),    // 10: pm.v1.CreateIssueRequest
+	(*DeleteIssueRequest)(nil),    // 11: pm.v1.DeleteIssueRequest
+	(*Session)(nil),               // 12: pm.v1.Session
+	(*ListSessionsRequest)(nil),   // 13: pm.v1.ListSessionsRequest
+	(*ListSessionsResponse)(nil),  // 14: pm.v1.ListSessionsResponse
+	(*GetSessionRequest)(nil),     // 15: pm.v1.GetSessionRequest
+	(*LaunchAgentRequest)(nil),    // 16: pm.v1.LaunchAgentRequest
+	(*LaunchAgentResponse)(nil),   // 17: pm.v1.LaunchAgentResponse
+	(*CloseAgentRequest)(nil),     // 18: pm.v1.CloseAgentRequest
+	(*CloseAgentResponse)(nil),    // 19: pm.v1.CloseAgentResponse
+	(*NextAgentRequest)(nil),      // 20: pm.v1.NextAgentRequest
+	(*NextAgentResponse)(nil),     // 21: pm.v1.NextAgentResponse
+	(*timestamppb.Timestamp)(nil), // 22: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),         // 23: google.protobuf.Empty
+}
+var file_pkg_pmpb_pm_proto_depIdxs = []int32{
+	22, // 0: pm.v1.Project.created_at:type_name -> google.protobuf.Timestamp
+	22, // 1: pm.v1.Project.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 2: pm.v1.ListProjectsResponse.projects:type_name -> pm.v1.Project
+	22, // 3: pm.v1.Issue.created_at:type_name -> google.protobuf.Timestamp
+	22, // 4: pm.v1.Issue.updated_at:type_name -> google.protobuf.Timestamp
+	22, // 5: pm.v1.Issue.closed_at:type_name -> google.protobuf.Timestamp
+	22, // 6: pm.v1.Issue.started_at:type_name -> google.protobuf.Timestamp
+	22, // 7: pm.v1.Issue.completed_at:type_name -> google.protobuf.Timestamp
+	6,  // 8: pm.v1.ListIssuesResponse.issues:type_name -> pm.v1.Issue
+	22, // 9: pm.v1.Session.started_at:type_name -> google.protobuf.Timestamp
+	22, // 10: pm.v1.Session.ended_at:type_name -> google.protobuf.Timestamp
+	22, // 11: pm.v1.Session.last_active_at:type_name -> google.protobuf.Timestamp
+	12, // 12: pm.v1.ListSessionsResponse.sessions:type_name -> pm.v1.Session
+	22, // 13: pm.v1.CloseAgentResponse.ended_at:type_name -> google.protobuf.Timestamp
+	17, // 14: pm.v1.NextAgentResponse.launch:type_name -> pm.v1.LaunchAgentResponse
+	1,  // 15: pm.v1.PM.ListProjects:input_type -> pm.v1.ListProjectsRequest
+	3,  // 16: pm.v1.PM.GetProject:input_type -> pm.v1.GetProjectRequest
+	4,  // 17: pm.v1.PM.CreateProject:input_type -> pm.v1.CreateProjectRequest
+	5,  // 18: pm.v1.PM.DeleteProject:input_type -> pm.v1.DeleteProjectRequest
+	7,  // 19: pm.v1.PM.ListIssues:input_type -> pm.v1.ListIssuesRequest
+	9,  // 20: pm.v1.PM.GetIssue:input_type -> pm.v1.GetIssueRequest
+	10, // 21: pm.v1.PM.CreateIssue:input_type -> pm.v1.CreateIssueRequest
+	6,  // 22: pm.v1.PM.UpdateIssue:input_type -> pm.v1.Issue
+	11, // 23: pm.v1.PM.DeleteIssue:input_type -> pm.v1.DeleteIssueRequest
+	13, // 24: pm.v1.PM.ListSessions:input_type -> pm.v1.ListSessionsRequest
+	15, // 25: pm.v1.PM.GetSession:input_type -> pm.v1.GetSessionRequest
+	16, // 26: pm.v1.PM.LaunchAgent:input_type -> pm.v1.LaunchAgentRequest
+	18, // 27: pm.v1.PM.CloseAgent:input_type -> pm.v1.CloseAgentRequest
+	20, // 28: pm.v1.PM.NextAgent:input_type -> pm.v1.NextAgentRequest
+	2,  // 29: pm.v1.PM.ListProjects:output_type -> pm.v1.ListProjectsResponse
+	0,  // 30: pm.v1.PM.GetProject:output_type -> pm.v1.Project
+	0,  // 31: pm.v1.PM.CreateProject:output_type -> pm.v1.Project
+	23, // 32: pm.v1.PM.DeleteProject:output_type -> google.protobuf.Empty
+	8,  // 33: pm.v1.PM.ListIssues:output_type -> pm.v1.ListIssuesResponse
+	6,  // 34: pm.v1.PM.GetIssue:output_type -> pm.v1.Issue
+	6,  // 35: pm.v1.PM.CreateIssue:output_type -> pm.v1.Issue
+	6,  // 36: pm.v1.PM.UpdateIssue:output_type -> pm.v1.Issue
+	23, // 37: pm.v1.PM.DeleteIssue:output_type -> google.protobuf.Empty
+	14, // 38: pm.v1.PM.ListSessions:output_type -> pm.v1.ListSessionsResponse
+	12, // 39: pm.v1.PM.GetSession:output_type -> pm.v1.Session
+	17, // 40: pm.v1.PM.LaunchAgent:output_type -> pm.v1.LaunchAgentResponse
+	19, // 41: pm.v1.PM.CloseAgent:output_type -> pm.v1.CloseAgentResponse
+	21, // 42: pm.v1.PM.NextAgent:output_type -> pm.v1.NextAgentResponse
+	29, // [29:43] is the sub-list for method output_type
+	15, // [15:29] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_pkg_pmpb_pm_proto_init() }
+func file_pkg_pmpb_pm_proto_init() {
+	if File_pkg_pmpb_pm_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_pmpb_pm_proto_rawDesc), len(file_pkg_pmpb_pm_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   22,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pkg_pmpb_pm_proto_goTypes,
+		DependencyIndexes: file_pkg_pmpb_pm_proto_depIdxs,
+		MessageInfos:      file_pkg_pmpb_pm_proto_msgTypes,
+	}.Build()
+	File_pkg_pmpb_pm_proto = out.File
+	file_pkg_pmpb_pm_proto_goTypes = nil
+	file_pkg_pmpb_pm_proto_depIdxs = nil
+}
@@ -9,6 +9,7 @@ import { IssuesPage } from "@/components/issues/issues-page";
 import { IssueDetail } from "@/components/issues/issue-detail";
 import { SessionsPage } from "@/components/sessions/sessions-page";
 import { SessionDetail } from "@/components/sessions/session-detail";
+import { SharedReviewPage } from "@/components/review/shared-review-page";
 import "./index.css";
 
 const queryClient = new QueryClient({
@@ -34,6 +35,7 @@ export function App() {
             <Route path="sessions" element={<SessionsPage />} />
             <Route path="sessions/:id" element={<SessionDetail />} />
           </Route>
+          <Route path="review/:token" element={<SharedReviewPage />} />
         </Routes>
       </BrowserRouter>
       <Toaster position="bottom-right" />
@@ -115,7 +115,7 @@ export function AgentLaunchDialog({
                 {issues.map((issue) => (
                   <div key={issue.ID} className="flex items-center gap-2 text-sm">
                     <Badge variant="outline" className="font-mono text-xs">
-                      {issue.ID.slice(0, 12)}
+                      {issue.Code || issue.ID.slice(0, 12)}
                     </Badge>
                     <span>{issue.Title}</span>
                   </div>
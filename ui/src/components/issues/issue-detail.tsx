@@ -1,8 +1,9 @@
 import { useState } from "react";
 import { useParams, useNavigate, Link } from "react-router";
 import { Pencil, Trash2, ArrowLeft, Sparkles } from "lucide-react";
-import { useIssue, useDeleteIssue, useEnrichIssue } from "@/hooks/use-issues";
+import { useIssue, useDeleteIssue, useEnrichIssue, useRenderedIssue } from "@/hooks/use-issues";
 import { useIssueReviews } from "@/hooks/use-reviews";
+import { useCapabilities } from "@/hooks/use-status";
 import { Button } from "@/components/ui/button";
 import { Badge } from "@/components/ui/badge";
 import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
@@ -99,6 +100,9 @@ function ReviewHistory({ issueId }: { issueId: string }) {
                         {review.DiffStats}
                       </span>
                     )}
+                    {review.Reviewer && (
+                      <span className="text-xs text-muted-foreground">by {review.Reviewer}</span>
+                    )}
                   </div>
                   <TimeAgo date={review.ReviewedAt} className="text-sm text-muted-foreground" />
                 </div>
@@ -135,8 +139,10 @@ export function IssueDetail() {
   const { id } = useParams<{ id: string }>();
   const navigate = useNavigate();
   const { data: issue, isLoading, error } = useIssue(id!);
+  const { data: rendered } = useRenderedIssue(id!, !!issue?.Description);
   const deleteIssue = useDeleteIssue();
   const enrichIssue = useEnrichIssue();
+  const { data: capabilities } = useCapabilities();
   const [editOpen, setEditOpen] = useState(false);
 
   const handleDelete = () => {
@@ -199,20 +205,22 @@ export function IssueDetail() {
           </div>
         </div>
         <div className="flex items-center gap-2 shrink-0">
-          <Button
-            variant="outline"
-            size="sm"
-            onClick={() => {
-              enrichIssue.mutate(issue.ID, {
-                onSuccess: () => toast.success("Issue enriched"),
-                onError: (err) => toast.error(`Enrichment failed: ${(err as Error).message}`),
-              });
-            }}
-            disabled={enrichIssue.isPending}
-          >
-            <Sparkles />
-            {enrichIssue.isPending ? "Enriching..." : "Enrich"}
-          </Button>
+          {capabilities?.llm !== "off" && (
+            <Button
+              variant="outline"
+              size="sm"
+              onClick={() => {
+                enrichIssue.mutate(issue.ID, {
+                  onSuccess: () => toast.success("Issue enriched"),
+                  onError: (err) => toast.error(`Enrichment failed: ${(err as Error).message}`),
+                });
+              }}
+              disabled={enrichIssue.isPending}
+            >
+              <Sparkles />
+              {enrichIssue.isPending ? "Enriching..." : "Enrich"}
+            </Button>
+          )}
           <Button variant="outline" size="sm" onClick={() => setEditOpen(true)}>
             <Pencil />
             Edit
@@ -299,6 +307,22 @@ export function IssueDetail() {
                 </dd>
               </div>
             )}
+
+            {issue.MergeCommit && (
+              <div>
+                <dt className="text-muted-foreground">Merged</dt>
+                <dd className="mt-1">
+                  <code className="font-mono text-xs">{issue.MergeCommit.slice(0, 7)}</code>
+                  {issue.MergeBranch && <> into {issue.MergeBranch}</>}
+                  {issue.MergedAt && (
+                    <>
+                      {" \u00B7 "}
+                      <TimeAgo date={issue.MergedAt} />
+                    </>
+                  )}
+                </dd>
+              </div>
+            )}
           </dl>
         </CardContent>
       </Card>
@@ -310,7 +334,18 @@ export function IssueDetail() {
             <CardTitle>Description</CardTitle>
           </CardHeader>
           <CardContent>
-            <p className="text-sm whitespace-pre-wrap">{issue.Description}</p>
+            {rendered?.description_html ? (
+              <>
+                {rendered.highlight_css && <style>{rendered.highlight_css}</style>}
+                {/* Sanitized server-side by GET /api/v1/issues/{id}/rendered */}
+                <div
+                  className="text-sm space-y-2 [&_a]:underline [&_ol]:list-decimal [&_ol]:pl-5 [&_ul]:list-disc [&_ul]:pl-5 [&_pre]:overflow-x-auto [&_pre]:rounded-md [&_pre]:p-4"
+                  dangerouslySetInnerHTML={{ __html: rendered.description_html }}
+                />
+              </>
+            ) : (
+              <p className="text-sm whitespace-pre-wrap">{issue.Description}</p>
+            )}
           </CardContent>
         </Card>
       )}
@@ -12,6 +12,7 @@ interface IssueFilterValues {
   status?: IssueStatus;
   priority?: IssuePriority;
   tag?: string;
+  sort?: "smart";
 }
 
 interface IssueFiltersProps {
@@ -41,6 +42,7 @@ export function IssueFilters({ filters, onChange }: IssueFiltersProps) {
           <SelectItem value="__all__">All Statuses</SelectItem>
           <SelectItem value="open">Open</SelectItem>
           <SelectItem value="in_progress">In Progress</SelectItem>
+          <SelectItem value="in_review">In Review</SelectItem>
           <SelectItem value="done">Done</SelectItem>
           <SelectItem value="closed">Closed</SelectItem>
         </SelectContent>
@@ -87,6 +89,24 @@ export function IssueFilters({ filters, onChange }: IssueFiltersProps) {
           ))}
         </SelectContent>
       </Select>
+
+      <Select
+        value={filters.sort ?? "__default__"}
+        onValueChange={(value) =>
+          onChange({
+            ...filters,
+            sort: value === "__default__" ? undefined : "smart",
+          })
+        }
+      >
+        <SelectTrigger className="w-[150px]">
+          <SelectValue placeholder="Order" />
+        </SelectTrigger>
+        <SelectContent>
+          <SelectItem value="__default__">By Status</SelectItem>
+          <SelectItem value="smart">Smart Order</SelectItem>
+        </SelectContent>
+      </Select>
     </div>
   );
 }
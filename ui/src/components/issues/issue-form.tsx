@@ -242,6 +242,7 @@ export function IssueForm({ open, onOpenChange, issue, defaultProjectId }: Issue
                 <SelectContent>
                   <SelectItem value="open">Open</SelectItem>
                   <SelectItem value="in_progress">In Progress</SelectItem>
+                  <SelectItem value="in_review">In Review</SelectItem>
                   <SelectItem value="done">Done</SelectItem>
                   <SelectItem value="closed">Closed</SelectItem>
                 </SelectContent>
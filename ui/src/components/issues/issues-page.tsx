@@ -1,8 +1,8 @@
 import { useEffect, useMemo, useState } from "react";
 import { Link } from "react-router";
-import { ChevronDown, ChevronRight, Plus, Rocket, Trash2, X } from "lucide-react";
+import { ArrowDown, ArrowUp, ChevronDown, ChevronRight, Plus, Rocket, Trash2, X } from "lucide-react";
 import { toast } from "sonner";
-import { useIssues, useUpdateIssue, useDeleteIssue, useBulkUpdateIssueStatus, useBulkDeleteIssues } from "@/hooks/use-issues";
+import { useIssues, useUpdateIssue, useDeleteIssue, useRankIssue, useBulkUpdateIssueStatus, useBulkDeleteIssues } from "@/hooks/use-issues";
 import { useProjects } from "@/hooks/use-projects";
 import { Button } from "@/components/ui/button";
 import { Badge } from "@/components/ui/badge";
@@ -42,6 +42,7 @@ interface FilterValues {
   status?: IssueStatus;
   priority?: IssuePriority;
   tag?: string;
+  sort?: "smart";
 }
 
 const typeLabels: Record<string, string> = {
@@ -69,6 +70,7 @@ export function IssuesPage() {
   const { data: projectsData, isLoading: projectsLoading } = useProjects();
   const updateIssue = useUpdateIssue();
   const deleteIssue = useDeleteIssue();
+  const rankIssue = useRankIssue();
   const bulkUpdateStatus = useBulkUpdateIssueStatus();
   const bulkDelete = useBulkDeleteIssues();
   const allIssues = issuesData ?? [];
@@ -145,6 +147,25 @@ export function IssuesPage() {
     setSelectedIssues(new Set());
   };
 
+  // Issues are listed by status, then rank, so an issue moves past its
+  // neighbor in the same status column. Smart order is not the rank order.
+  const canRank = filters.sort !== "smart";
+
+  function rankNeighbor(groupIssues: Issue[], index: number, step: -1 | 1) {
+    const neighbor = groupIssues[index + step];
+    return neighbor && neighbor.Status === groupIssues[index].Status ? neighbor : undefined;
+  }
+
+  async function handleMove(issue: Issue, neighbor: Issue, step: -1 | 1) {
+    try {
+      await rankIssue.mutateAsync(
+        step < 0 ? { id: issue.ID, before: neighbor.ID } : { id: issue.ID, after: neighbor.ID }
+      );
+    } catch (err) {
+      toast.error(`Failed to move issue: ${(err as Error).message}`);
+    }
+  }
+
   async function handleBulkStatusChange(status: IssueStatus) {
     const ids = Array.from(selectedIssues);
     try {
@@ -156,14 +177,22 @@ export function IssuesPage() {
     }
   }
 
-  async function handleBulkDelete() {
+  async function handleBulkDelete(force = false) {
     const ids = Array.from(selectedIssues);
     try {
-      await bulkDelete.mutateAsync(ids);
+      await bulkDelete.mutateAsync({ ids, force });
       toast.success(`Deleted ${ids.length} issue${ids.length > 1 ? "s" : ""}`);
       clearSelection();
     } catch (err) {
-      toast.error(`Failed to delete issues: ${(err as Error).message}`);
+      const message = (err as Error).message;
+      if (message.startsWith("409")) {
+        toast.error("Some issues have open agent sessions", {
+          description: "Their sessions will be unlinked from the issues.",
+          action: { label: "Delete anyway", onClick: () => handleBulkDelete(true) },
+        });
+      } else {
+        toast.error(`Failed to delete issues: ${message}`);
+      }
     }
     setDeleteConfirmOpen(false);
   }
@@ -249,46 +278,79 @@ export function IssuesPage() {
                         <TableHead>Priority</TableHead>
                         <TableHead>Type</TableHead>
                         <TableHead>Updated</TableHead>
+                        {canRank && (
+                          <TableHead className="w-20">
+                            <span className="sr-only">Order</span>
+                          </TableHead>
+                        )}
                       </TableRow>
                     </TableHeader>
                     <TableBody>
-                      {groupIssues.map((issue) => (
-                        <TableRow key={issue.ID}>
-                          <TableCell className="w-10">
-                            <input
-                              type="checkbox"
-                              checked={selectedIssues.has(issue.ID)}
-                              onChange={() => toggleIssueSelection(issue)}
-                              className="rounded border-gray-300"
-                            />
-                          </TableCell>
-                          <TableCell>
-                            <Link
-                              to={`/issues/${issue.ID}`}
-                              className="font-medium text-foreground hover:underline"
-                            >
-                              {issue.Title}
-                            </Link>
-                          </TableCell>
-                          <TableCell>
-                            <StatusBadge status={issue.Status} />
-                          </TableCell>
-                          <TableCell>
-                            <PriorityBadge priority={issue.Priority} />
-                          </TableCell>
-                          <TableCell>
-                            <Badge variant="outline">
-                              {typeLabels[issue.Type] ?? issue.Type}
-                            </Badge>
-                          </TableCell>
-                          <TableCell>
-                            <TimeAgo
-                              date={issue.UpdatedAt}
-                              className="text-muted-foreground text-sm"
-                            />
-                          </TableCell>
-                        </TableRow>
-                      ))}
+                      {groupIssues.map((issue, index) => {
+                        const above = rankNeighbor(groupIssues, index, -1);
+                        const below = rankNeighbor(groupIssues, index, 1);
+                        return (
+                          <TableRow key={issue.ID}>
+                            <TableCell className="w-10">
+                              <input
+                                type="checkbox"
+                                checked={selectedIssues.has(issue.ID)}
+                                onChange={() => toggleIssueSelection(issue)}
+                                className="rounded border-gray-300"
+                              />
+                            </TableCell>
+                            <TableCell>
+                              <Link
+                                to={`/issues/${issue.ID}`}
+                                className="font-medium text-foreground hover:underline"
+                              >
+                                {issue.Title}
+                              </Link>
+                            </TableCell>
+                            <TableCell>
+                              <StatusBadge status={issue.Status} />
+                            </TableCell>
+                            <TableCell>
+                              <PriorityBadge priority={issue.Priority} />
+                            </TableCell>
+                            <TableCell>
+                              <Badge variant="outline">
+                                {typeLabels[issue.Type] ?? issue.Type}
+                              </Badge>
+                            </TableCell>
+                            <TableCell>
+                              <TimeAgo
+                                date={issue.UpdatedAt}
+                                className="text-muted-foreground text-sm"
+                              />
+                            </TableCell>
+                            {canRank && (
+                              <TableCell className="w-20">
+                                <div className="flex gap-1">
+                                  <Button
+                                    size="icon-sm"
+                                    variant="ghost"
+                                    disabled={!above || rankIssue.isPending}
+                                    onClick={() => above && handleMove(issue, above, -1)}
+                                    title="Move up"
+                                  >
+                                    <ArrowUp className="h-4 w-4" />
+                                  </Button>
+                                  <Button
+                                    size="icon-sm"
+                                    variant="ghost"
+                                    disabled={!below || rankIssue.isPending}
+                                    onClick={() => below && handleMove(issue, below, 1)}
+                                    title="Move down"
+                                  >
+                                    <ArrowDown className="h-4 w-4" />
+                                  </Button>
+                                </div>
+                              </TableCell>
+                            )}
+                          </TableRow>
+                        );
+                      })}
                     </TableBody>
                   </Table>
                 )}
@@ -312,6 +374,7 @@ export function IssuesPage() {
             <SelectContent>
               <SelectItem value="open">Open</SelectItem>
               <SelectItem value="in_progress">In Progress</SelectItem>
+              <SelectItem value="in_review">In Review</SelectItem>
               <SelectItem value="done">Done</SelectItem>
               <SelectItem value="closed">Closed</SelectItem>
             </SelectContent>
@@ -363,7 +426,7 @@ export function IssuesPage() {
             <Button variant="outline" onClick={() => setDeleteConfirmOpen(false)}>
               Cancel
             </Button>
-            <Button variant="destructive" onClick={handleBulkDelete}>
+            <Button variant="destructive" onClick={() => handleBulkDelete()}>
               Delete
             </Button>
           </DialogFooter>
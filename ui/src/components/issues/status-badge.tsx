@@ -11,6 +11,10 @@ const statusConfig: Record<IssueStatus, { label: string; className: string }> =
     label: "In Progress",
     className: "bg-yellow-100 text-yellow-800 dark:bg-yellow-900/40 dark:text-yellow-300",
   },
+  in_review: {
+    label: "In Review",
+    className: "bg-purple-100 text-purple-800 dark:bg-purple-900/40 dark:text-purple-300",
+  },
   done: {
     label: "Done",
     className: "bg-green-100 text-green-800 dark:bg-green-900/40 dark:text-green-300",
@@ -1,6 +1,7 @@
 import { Outlet } from "react-router";
 import { Sidebar } from "./sidebar";
 import { Header } from "./header";
+import { TokenDialog } from "./token-dialog";
 
 export function AppLayout() {
   return (
@@ -12,6 +13,7 @@ export function AppLayout() {
           <Outlet />
         </main>
       </div>
+      <TokenDialog />
     </div>
   );
 }
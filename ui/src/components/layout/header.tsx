@@ -1,5 +1,7 @@
-import { Moon, Sun } from "lucide-react";
+import { Bell, BellOff, Moon, Sun } from "lucide-react";
+import { toast } from "sonner";
 import { Button } from "@/components/ui/button";
+import { usePushNotifications } from "@/hooks/use-push";
 import { useEffect, useState } from "react";
 
 export function Header() {
@@ -11,23 +13,51 @@ export function Header() {
     }
     return false;
   });
+  const push = usePushNotifications();
 
   useEffect(() => {
     document.documentElement.classList.toggle("dark", dark);
     localStorage.setItem("pm-dark-mode", String(dark));
   }, [dark]);
 
+  const togglePush = () => {
+    const action = push.enabled ? push.disable() : push.enable();
+    action
+      .then(() =>
+        toast.success(
+          push.enabled
+            ? "Browser notifications turned off"
+            : "You'll be notified when sessions complete or hit conflicts",
+        ),
+      )
+      .catch((err: Error) => toast.error(err.message));
+  };
+
   return (
     <header className="h-12 border-b flex items-center justify-between px-4">
       <div />
-      <Button
-        variant="ghost"
-        size="icon-sm"
-        onClick={() => setDark(!dark)}
-        aria-label="Toggle dark mode"
-      >
-        {dark ? <Sun className="h-4 w-4" /> : <Moon className="h-4 w-4" />}
-      </Button>
+      <div className="flex items-center gap-1">
+        {push.supported && (
+          <Button
+            variant="ghost"
+            size="icon-sm"
+            onClick={togglePush}
+            disabled={push.busy}
+            aria-label={push.enabled ? "Turn off notifications" : "Turn on notifications"}
+            title={push.enabled ? "Turn off notifications" : "Notify me about sessions"}
+          >
+            {push.enabled ? <Bell className="h-4 w-4" /> : <BellOff className="h-4 w-4" />}
+          </Button>
+        )}
+        <Button
+          variant="ghost"
+          size="icon-sm"
+          onClick={() => setDark(!dark)}
+          aria-label="Toggle dark mode"
+        >
+          {dark ? <Sun className="h-4 w-4" /> : <Moon className="h-4 w-4" />}
+        </Button>
+      </div>
     </header>
   );
 }
@@ -0,0 +1,72 @@
+import { useEffect, useState } from "react";
+import { useQueryClient } from "@tanstack/react-query";
+import { toast } from "sonner";
+import {
+  Dialog,
+  DialogContent,
+  DialogDescription,
+  DialogHeader,
+  DialogTitle,
+  DialogFooter,
+} from "@/components/ui/dialog";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Button } from "@/components/ui/button";
+import { UNAUTHORIZED_EVENT, getAPIToken, setAPIToken } from "@/lib/api";
+
+// TokenDialog asks for an API token when the server requires one
+// (api.require_auth) and the UI has none, or a revoked one.
+export function TokenDialog() {
+  const qc = useQueryClient();
+  const [open, setOpen] = useState(false);
+  const [token, setToken] = useState("");
+
+  useEffect(() => {
+    const onUnauthorized = () => {
+      setToken(getAPIToken());
+      setOpen(true);
+    };
+    window.addEventListener(UNAUTHORIZED_EVENT, onUnauthorized);
+    return () => window.removeEventListener(UNAUTHORIZED_EVENT, onUnauthorized);
+  }, []);
+
+  function handleSubmit(e: React.FormEvent) {
+    e.preventDefault();
+    if (!token.trim()) return;
+    setAPIToken(token.trim());
+    setOpen(false);
+    toast("API token saved");
+    qc.invalidateQueries();
+  }
+
+  return (
+    <Dialog open={open} onOpenChange={setOpen}>
+      <DialogContent className="sm:max-w-md">
+        <DialogHeader>
+          <DialogTitle>API Token Required</DialogTitle>
+          <DialogDescription>
+            This server requires a token. Create one with{" "}
+            <code>pm token create</code> and paste it here; it is kept in this
+            browser.
+          </DialogDescription>
+        </DialogHeader>
+        <form onSubmit={handleSubmit} className="space-y-4">
+          <div className="space-y-2">
+            <Label htmlFor="apiToken">Token</Label>
+            <Input
+              id="apiToken"
+              type="password"
+              value={token}
+              onChange={(e) => setToken(e.target.value)}
+              placeholder="pm_..."
+              required
+            />
+          </div>
+          <DialogFooter>
+            <Button type="submit">Save</Button>
+          </DialogFooter>
+        </form>
+      </DialogContent>
+    </Dialog>
+  );
+}
@@ -0,0 +1,219 @@
+import { useState } from "react";
+import { useParams } from "react-router";
+import { useSharedReview, useSubmitSharedReview } from "@/hooks/use-reviews";
+import { Button } from "@/components/ui/button";
+import { Badge } from "@/components/ui/badge";
+import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Skeleton } from "@/components/ui/skeleton";
+import { Textarea } from "@/components/ui/textarea";
+import { toast } from "sonner";
+import type { ReviewVerdict, SharedReview } from "@/lib/types";
+
+interface CriterionState {
+  passed: boolean;
+  note: string;
+}
+
+// SharedReviewPage is opened from a review link by someone without pm: it
+// shows the issue and its diff and takes one verdict.
+export function SharedReviewPage() {
+  const { token = "" } = useParams<{ token: string }>();
+  const { data, isLoading, error } = useSharedReview(token);
+
+  if (isLoading) {
+    return (
+      <div className="mx-auto max-w-5xl p-6 space-y-4">
+        <Skeleton className="h-8 w-64" />
+        <Skeleton className="h-48 rounded-lg" />
+      </div>
+    );
+  }
+
+  if (error || !data) {
+    const message = error?.message ?? "";
+    return (
+      <div className="mx-auto max-w-5xl p-6">
+        <p className="text-muted-foreground">
+          {message.startsWith("410")
+            ? "This review link has expired."
+            : "This review link is not valid. It may have been revoked."}
+        </p>
+      </div>
+    );
+  }
+
+  return (
+    <div className="mx-auto max-w-5xl p-6 space-y-6">
+      <div className="space-y-1">
+        <p className="text-sm text-muted-foreground">
+          {data.project} · review requested{data.reviewer && ` from ${data.reviewer}`} · open until{" "}
+          {new Date(data.expires_at).toLocaleString()}
+        </p>
+        <h1 className="text-2xl font-bold">{data.issue.title}</h1>
+        <div className="flex gap-2">
+          <Badge variant="outline">{data.issue.type}</Badge>
+          <Badge variant="outline">{data.issue.priority}</Badge>
+          <Badge variant="outline">{data.issue.status}</Badge>
+        </div>
+      </div>
+
+      <Card>
+        <CardHeader>
+          <CardTitle>Requirements</CardTitle>
+        </CardHeader>
+        <CardContent className="space-y-3">
+          {data.issue.description && <p className="text-sm">{data.issue.description}</p>}
+          {data.issue.body && (
+            <p className="text-sm whitespace-pre-wrap text-muted-foreground">{data.issue.body}</p>
+          )}
+          {data.issue.acceptance_criteria && data.issue.acceptance_criteria.length > 0 && (
+            <ul className="list-disc list-inside text-sm space-y-0.5">
+              {data.issue.acceptance_criteria.map((c) => (
+                <li key={c}>{c}</li>
+              ))}
+            </ul>
+          )}
+        </CardContent>
+      </Card>
+
+      <Card>
+        <CardHeader>
+          <CardTitle>
+            Changes{" "}
+            <span className="text-sm font-normal font-mono text-muted-foreground">
+              {data.base_ref}...{data.head_ref} {data.diff_stats}
+            </span>
+          </CardTitle>
+        </CardHeader>
+        <CardContent className="space-y-2">
+          {data.diff ? (
+            <pre className="text-xs font-mono overflow-x-auto max-h-[60vh] rounded-md bg-muted p-3">
+              {data.diff}
+            </pre>
+          ) : (
+            <p className="text-sm text-muted-foreground">No changes on the branch yet</p>
+          )}
+          {data.diff_truncated && (
+            <p className="text-xs text-muted-foreground">The diff was truncated.</p>
+          )}
+        </CardContent>
+      </Card>
+
+      {data.submitted ? (
+        <Card>
+          <CardHeader>
+            <CardTitle>Review submitted</CardTitle>
+          </CardHeader>
+          <CardContent className="space-y-2">
+            <Badge variant="outline">{data.submitted.Verdict === "pass" ? "Pass" : "Fail"}</Badge>
+            <p className="text-sm">{data.submitted.Summary}</p>
+          </CardContent>
+        </Card>
+      ) : (
+        <ReviewForm token={token} review={data} />
+      )}
+    </div>
+  );
+}
+
+function ReviewForm({ token, review }: { token: string; review: SharedReview }) {
+  const submit = useSubmitSharedReview(token);
+  const criteria = review.issue.acceptance_criteria ?? [];
+  const [reviewer, setReviewer] = useState("");
+  const [summary, setSummary] = useState("");
+  const [reasons, setReasons] = useState("");
+  const [results, setResults] = useState<Record<string, CriterionState>>(() =>
+    Object.fromEntries(criteria.map((c) => [c, { passed: true, note: "" }])),
+  );
+
+  const anyFailed = Object.values(results).some((r) => !r.passed);
+
+  const send = (verdict: ReviewVerdict) => {
+    submit.mutate(
+      {
+        verdict,
+        summary,
+        reviewer: review.reviewer ? undefined : reviewer,
+        failure_reasons: reasons
+          .split("\n")
+          .map((r) => r.trim())
+          .filter(Boolean),
+        criteria: criteria.map((c) => ({ criterion: c, ...results[c] })),
+      },
+      {
+        onSuccess: () => toast.success("Review submitted"),
+        onError: (err) => toast.error(`Failed to submit review: ${err.message}`),
+      },
+    );
+  };
+
+  return (
+    <Card>
+      <CardHeader>
+        <CardTitle>Your review</CardTitle>
+      </CardHeader>
+      <CardContent className="space-y-4">
+        {!review.reviewer && (
+          <div className="space-y-1">
+            <Label htmlFor="reviewer">Your name</Label>
+            <Input id="reviewer" value={reviewer} onChange={(e) => setReviewer(e.target.value)} />
+          </div>
+        )}
+
+        {criteria.length > 0 && (
+          <div className="space-y-2">
+            <Label>Acceptance criteria</Label>
+            {criteria.map((c) => (
+              <div key={c} className="flex items-center gap-3">
+                <input
+                  type="checkbox"
+                  checked={results[c]?.passed ?? true}
+                  onChange={(e) =>
+                    setResults({ ...results, [c]: { ...results[c], passed: e.target.checked } })
+                  }
+                />
+                <span className="text-sm flex-1">{c}</span>
+                <Input
+                  className="max-w-xs"
+                  placeholder="Note"
+                  value={results[c]?.note ?? ""}
+                  onChange={(e) =>
+                    setResults({ ...results, [c]: { ...results[c], note: e.target.value } })
+                  }
+                />
+              </div>
+            ))}
+          </div>
+        )}
+
+        <div className="space-y-1">
+          <Label htmlFor="summary">Summary</Label>
+          <Textarea id="summary" value={summary} onChange={(e) => setSummary(e.target.value)} />
+        </div>
+
+        <div className="space-y-1">
+          <Label htmlFor="reasons">Failure reasons (one per line)</Label>
+          <Textarea id="reasons" value={reasons} onChange={(e) => setReasons(e.target.value)} />
+        </div>
+
+        <div className="flex gap-2">
+          <Button
+            onClick={() => send("pass")}
+            disabled={!summary.trim() || anyFailed || submit.isPending}
+          >
+            Approve
+          </Button>
+          <Button
+            variant="destructive"
+            onClick={() => send("fail")}
+            disabled={!summary.trim() || submit.isPending}
+          >
+            Request changes
+          </Button>
+        </div>
+      </CardContent>
+    </Card>
+  );
+}
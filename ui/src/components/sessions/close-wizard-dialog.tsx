@@ -104,7 +104,8 @@ export function CloseWizardDialog({ session, open, onOpenChange }: CloseWizardDi
 
   const handleComplete = () => {
     closeAgent.mutate(
-      { session_id: session.ID, status: "completed" },
+      // "Complete Anyway" is the user's confirmation of the close-check warnings
+      { session_id: session.ID, status: "completed", force: !check?.ready_to_close },
       {
         onSuccess: () => {
           toast.success("Session completed");
@@ -197,6 +198,30 @@ export function CloseWizardDialog({ session, open, onOpenChange }: CloseWizardDi
                   )}
                 </div>
 
+                {/* Uncommitted files */}
+                {check.dirty_files && check.dirty_files.length > 0 && (
+                  <div className="rounded-md border p-2 max-h-40 overflow-y-auto">
+                    {check.dirty_files.map((f) => (
+                      <div key={f.path} className="flex items-center justify-between gap-2 text-xs font-mono">
+                        <span className="truncate" title={f.path}>
+                          {f.path}
+                        </span>
+                        <span className="shrink-0 text-muted-foreground">
+                          {f.untracked ? "untracked" : f.staged && !f.modified ? "staged" : "modified"}{" "}
+                          {f.binary ? (
+                            "binary"
+                          ) : (
+                            <>
+                              <span className="text-green-600 dark:text-green-400">+{f.additions}</span>{" "}
+                              <span className="text-red-600 dark:text-red-400">-{f.deletions}</span>
+                            </>
+                          )}
+                        </span>
+                      </div>
+                    ))}
+                  </div>
+                )}
+
                 {/* Warnings */}
                 {check.warnings.length > 0 && (
                   <div className="rounded-md border border-amber-200 bg-amber-50 dark:border-amber-900 dark:bg-amber-950/30 p-3 space-y-1.5">
@@ -145,7 +145,7 @@ export function SessionDetail() {
                 {" \u00B7 "}
               </>
             )}
-            ID: {session.ID.slice(0, 12)}
+            ID: {session.Code || session.ID.slice(0, 12)}
           </p>
         </div>
         {isLive && (
@@ -41,6 +41,7 @@ import type { SessionStatus, AgentSession } from "@/lib/types";
 
 const STATUS_TABS: { label: string; value: string; statuses?: SessionStatus[] }[] = [
   { label: "Active", value: "active_idle", statuses: ["active", "idle"] },
+  { label: "Blocked", value: "blocked", statuses: ["blocked"] },
   { label: "Completed", value: "completed", statuses: ["completed"] },
   { label: "Abandoned", value: "abandoned", statuses: ["abandoned"] },
   { label: "All", value: "all" },
@@ -52,6 +53,8 @@ function sessionColor(status: SessionStatus): string {
       return "bg-green-100 text-green-800 dark:bg-green-900/40 dark:text-green-300";
     case "idle":
       return "bg-yellow-100 text-yellow-800 dark:bg-yellow-900/40 dark:text-yellow-300";
+    case "blocked":
+      return "bg-orange-100 text-orange-800 dark:bg-orange-900/40 dark:text-orange-300";
     case "completed":
       return "bg-emerald-100 text-emerald-800 dark:bg-emerald-900/40 dark:text-emerald-300";
     case "abandoned":
@@ -92,6 +95,7 @@ export function SessionsPage() {
   const { data, isLoading, error } = useSessions(
     projectFilter && projectFilter !== "__all__" ? projectFilter : undefined,
     activeTab?.statuses,
+    statusTab === "active_idle" ? ["git"] : undefined,
   );
   const sessions = data ?? [];
 
@@ -271,10 +275,26 @@ export function SessionsPage() {
                     {s.Status}
                   </Badge>
                 </TableCell>
-                <TableCell className="text-sm max-w-[200px] truncate">
-                  {s.Outcome || "\u2014"}
+                <TableCell
+                  className="text-sm max-w-[200px] truncate"
+                  title={s.Status === "blocked" && s.UnblockCondition ? `Until: ${s.UnblockCondition}` : undefined}
+                >
+                  {s.Status === "blocked" ? (
+                    <span className={cn(s.BlockedOverdue && "text-orange-600 dark:text-orange-400")}>
+                      {s.BlockedReason}
+                    </span>
+                  ) : (
+                    s.Outcome || "\u2014"
+                  )}
+                </TableCell>
+                <TableCell className="text-center">
+                  {s.CommitCount}
+                  {s.Git?.IsDirty && (
+                    <span className="ml-1 text-xs text-yellow-600 dark:text-yellow-400" title="Uncommitted changes">
+                      *
+                    </span>
+                  )}
                 </TableCell>
-                <TableCell className="text-center">{s.CommitCount}</TableCell>
                 <TableCell className="text-xs">
                   {formatDuration(s.StartedAt, s.EndedAt)}
                 </TableCell>
@@ -285,6 +305,19 @@ export function SessionsPage() {
                   />
                 </TableCell>
                 <TableCell>
+                  {s.Status === "blocked" && (
+                    <div onClick={(e) => e.stopPropagation()}>
+                      <Button
+                        variant="ghost"
+                        size="sm"
+                        className="h-7 text-xs"
+                        onClick={() => handleClose(s.ID, "idle")}
+                        disabled={closeAgent.isPending}
+                      >
+                        Unblock
+                      </Button>
+                    </div>
+                  )}
                   {(s.Status === "active" || s.Status === "idle") && (
                     <div onClick={(e) => e.stopPropagation()}>
                       <DropdownMenu>
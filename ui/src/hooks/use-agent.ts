@@ -1,9 +1,11 @@
 import { useMutation, useQueryClient } from "@tanstack/react-query";
 import { apiFetch } from "@/lib/api";
+import type { LaunchPlanResponse } from "@/lib/types";
 
 interface LaunchAgentRequest {
   issue_ids: string[];
   project_id: string;
+  spawn?: boolean;
 }
 
 interface LaunchAgentResponse {
@@ -11,6 +13,8 @@ interface LaunchAgentResponse {
   branch: string;
   worktree_path: string;
   command: string;
+  terminal?: { backend: string; id: string };
+  spawn_error?: string;
 }
 
 export function useLaunchAgent() {
@@ -28,8 +32,20 @@ export function useLaunchAgent() {
   });
 }
 
+// useLaunchPlan previews a launch (?plan=true) without side effects.
+export function useLaunchPlan() {
+  return useMutation({
+    mutationFn: (req: LaunchAgentRequest) =>
+      apiFetch<LaunchPlanResponse>("/api/v1/agent/launch?plan=true", {
+        method: "POST",
+        body: JSON.stringify(req),
+      }),
+  });
+}
+
 interface ResumeAgentRequest {
   session_id: string;
+  spawn?: boolean;
 }
 
 export function useResumeAgent() {
@@ -49,7 +65,10 @@ export function useResumeAgent() {
 
 interface CloseAgentRequest {
   session_id: string;
-  status?: "idle" | "completed" | "abandoned";
+  status?: "idle" | "completed" | "abandoned" | "blocked";
+  force?: boolean;
+  reason?: string;
+  unblock_condition?: string;
 }
 
 interface CloseAgentResponse {
@@ -1,11 +1,12 @@
 import { useQuery, useMutation, useQueryClient } from "@tanstack/react-query";
 import { apiFetch } from "@/lib/api";
-import type { Issue, IssueStatus, IssuePriority } from "@/lib/types";
+import type { Issue, IssueStatus, IssuePriority, RenderedIssue } from "@/lib/types";
 
 interface IssueFilters {
   status?: IssueStatus;
   priority?: IssuePriority;
   tag?: string;
+  sort?: "smart";
 }
 
 export function useIssues(filters?: IssueFilters) {
@@ -13,6 +14,7 @@ export function useIssues(filters?: IssueFilters) {
   if (filters?.status) params.set("status", filters.status);
   if (filters?.priority) params.set("priority", filters.priority);
   if (filters?.tag) params.set("tag", filters.tag);
+  if (filters?.sort) params.set("sort", filters.sort);
   const qs = params.toString();
   return useQuery({
     queryKey: ["issues", filters ?? {}],
@@ -36,6 +38,14 @@ export function useIssue(id: string) {
   });
 }
 
+export function useRenderedIssue(id: string, enabled = true) {
+  return useQuery({
+    queryKey: ["issues", id, "rendered"],
+    queryFn: () => apiFetch<RenderedIssue>(`/api/v1/issues/${id}/rendered`),
+    enabled: !!id && enabled,
+  });
+}
+
 export function useCreateIssue() {
   const qc = useQueryClient();
   return useMutation({
@@ -69,6 +79,28 @@ export function useUpdateIssue() {
   });
 }
 
+export function useRankIssue() {
+  const qc = useQueryClient();
+  return useMutation({
+    mutationFn: ({
+      id,
+      ...placement
+    }: {
+      id: string;
+      before?: string;
+      after?: string;
+      position?: "top" | "bottom";
+    }) =>
+      apiFetch<Issue>(`/api/v1/issues/${id}/rank`, {
+        method: "PATCH",
+        body: JSON.stringify(placement),
+      }),
+    onSuccess: () => {
+      qc.invalidateQueries({ queryKey: ["issues"] });
+    },
+  });
+}
+
 export function useDeleteIssue() {
   const qc = useQueryClient();
   return useMutation({
@@ -111,10 +143,10 @@ export function useEnrichIssue() {
 export function useBulkDeleteIssues() {
   const qc = useQueryClient();
   return useMutation({
-    mutationFn: (ids: string[]) =>
+    mutationFn: ({ ids, force }: { ids: string[]; force?: boolean }) =>
       apiFetch<{ deleted: number }>(`/api/v1/issues/bulk-delete`, {
         method: "POST",
-        body: JSON.stringify({ ids }),
+        body: JSON.stringify({ ids, force }),
       }),
     onSuccess: () => {
       qc.invalidateQueries({ queryKey: ["issues"] });
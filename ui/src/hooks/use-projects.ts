@@ -60,11 +60,28 @@ export function useDeleteProject() {
   });
 }
 
+export interface ProjectResult {
+  id: string;
+  name: string;
+  changed: boolean;
+  changes?: string[];
+  error?: string;
+}
+
 export interface RefreshResult {
   refreshed: number;
   total: number;
   failed: number;
-  results: { name: string; changed: boolean; error?: string }[];
+  results: ProjectResult[];
+}
+
+export type BulkProjectAction = "archive" | "unarchive" | "set_group" | "refresh";
+
+export interface BulkProjectsResult {
+  action: BulkProjectAction;
+  total: number;
+  failed: number;
+  results: ProjectResult[];
 }
 
 export function useRefreshAllProjects() {
@@ -78,3 +95,18 @@ export function useRefreshAllProjects() {
     },
   });
 }
+
+export function useBulkProjects() {
+  const qc = useQueryClient();
+  return useMutation({
+    mutationFn: (req: { ids: string[]; action: BulkProjectAction; group?: string }) =>
+      apiFetch<BulkProjectsResult>("/api/v1/projects/bulk", {
+        method: "POST",
+        body: JSON.stringify(req),
+      }),
+    onSuccess: () => {
+      qc.invalidateQueries({ queryKey: ["projects"] });
+      qc.invalidateQueries({ queryKey: ["status"] });
+    },
+  });
+}
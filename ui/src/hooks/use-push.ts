@@ -0,0 +1,79 @@
+import { useCallback, useEffect, useState } from "react";
+import { apiFetch, BASE } from "@/lib/api";
+import type { PushKey } from "@/lib/types";
+
+// Web Push needs the service worker pm serve hosts at /sw.js, so it is only
+// offered on the embedded UI (not the dev server, which calls the API on
+// another origin).
+const supported =
+  typeof window !== "undefined" &&
+  BASE === "" &&
+  "serviceWorker" in navigator &&
+  "PushManager" in window &&
+  "Notification" in window;
+
+function decodeKey(base64url: string): Uint8Array {
+  const base64 = (base64url + "=".repeat((4 - (base64url.length % 4)) % 4))
+    .replace(/-/g, "+")
+    .replace(/_/g, "/");
+  return Uint8Array.from(atob(base64), (c) => c.charCodeAt(0));
+}
+
+async function currentSubscription(): Promise<PushSubscription | null> {
+  const reg = await navigator.serviceWorker.getRegistration("/");
+  return reg ? reg.pushManager.getSubscription() : null;
+}
+
+export function usePushNotifications() {
+  const [enabled, setEnabled] = useState(false);
+  const [busy, setBusy] = useState(false);
+
+  useEffect(() => {
+    if (!supported) return;
+    currentSubscription()
+      .then((sub) => setEnabled(sub !== null))
+      .catch(() => setEnabled(false));
+  }, []);
+
+  const enable = useCallback(async () => {
+    setBusy(true);
+    try {
+      if ((await Notification.requestPermission()) !== "granted") {
+        throw new Error("Notification permission was denied");
+      }
+      const { public_key } = await apiFetch<PushKey>("/api/v1/push/key");
+      const reg = await navigator.serviceWorker.register("/sw.js", { scope: "/" });
+      await navigator.serviceWorker.ready;
+      const sub = await reg.pushManager.subscribe({
+        userVisibleOnly: true,
+        applicationServerKey: decodeKey(public_key),
+      });
+      await apiFetch("/api/v1/push/subscriptions", {
+        method: "POST",
+        body: JSON.stringify(sub.toJSON()),
+      });
+      setEnabled(true);
+    } finally {
+      setBusy(false);
+    }
+  }, []);
+
+  const disable = useCallback(async () => {
+    setBusy(true);
+    try {
+      const sub = await currentSubscription();
+      if (sub) {
+        await apiFetch("/api/v1/push/subscriptions", {
+          method: "DELETE",
+          body: JSON.stringify({ endpoint: sub.endpoint }),
+        }).catch(() => undefined);
+        await sub.unsubscribe();
+      }
+      setEnabled(false);
+    } finally {
+      setBusy(false);
+    }
+  }, []);
+
+  return { supported, enabled, busy, enable, disable };
+}
@@ -0,0 +1,46 @@
+import { useQuery, useMutation, useQueryClient } from "@tanstack/react-query";
+import { apiFetch } from "@/lib/api";
+import type { IssueRelation, RelationType } from "@/lib/types";
+
+export function useIssueRelations(issueId: string) {
+  return useQuery({
+    queryKey: ["issue-relations", issueId],
+    queryFn: () => apiFetch<IssueRelation[]>(`/api/v1/issues/${issueId}/relations`),
+    enabled: !!issueId,
+  });
+}
+
+export function useCreateIssueRelation() {
+  const qc = useQueryClient();
+  return useMutation({
+    mutationFn: ({
+      issueId,
+      targetIssueId,
+      type,
+    }: {
+      issueId: string;
+      targetIssueId: string;
+      type: RelationType;
+    }) =>
+      apiFetch<IssueRelation>(`/api/v1/issues/${issueId}/relations`, {
+        method: "POST",
+        body: JSON.stringify({ target_issue_id: targetIssueId, type }),
+      }),
+    onSuccess: () => {
+      qc.invalidateQueries({ queryKey: ["issue-relations"] });
+    },
+  });
+}
+
+export function useDeleteIssueRelation() {
+  const qc = useQueryClient();
+  return useMutation({
+    mutationFn: ({ issueId, relationId }: { issueId: string; relationId: string }) =>
+      apiFetch<void>(`/api/v1/issues/${issueId}/relations/${relationId}`, {
+        method: "DELETE",
+      }),
+    onSuccess: () => {
+      qc.invalidateQueries({ queryKey: ["issue-relations"] });
+    },
+  });
+}
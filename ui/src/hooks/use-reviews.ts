@@ -1,6 +1,6 @@
-import { useQuery } from "@tanstack/react-query";
+import { useQuery, useMutation, useQueryClient } from "@tanstack/react-query";
 import { apiFetch } from "@/lib/api";
-import type { IssueReview } from "@/lib/types";
+import type { IssueReview, SharedReview, SharedReviewSubmission } from "@/lib/types";
 
 export function useIssueReviews(issueId: string) {
   return useQuery({
@@ -9,3 +9,26 @@ export function useIssueReviews(issueId: string) {
     enabled: !!issueId,
   });
 }
+
+export function useSharedReview(token: string) {
+  return useQuery({
+    queryKey: ["shared-review", token],
+    queryFn: () => apiFetch<SharedReview>(`/api/v1/review-shares/${token}`),
+    enabled: !!token,
+    retry: false,
+  });
+}
+
+export function useSubmitSharedReview(token: string) {
+  const qc = useQueryClient();
+  return useMutation({
+    mutationFn: (data: SharedReviewSubmission) =>
+      apiFetch<IssueReview>(`/api/v1/review-shares/${token}/review`, {
+        method: "POST",
+        body: JSON.stringify(data),
+      }),
+    onSuccess: () => {
+      qc.invalidateQueries({ queryKey: ["shared-review", token] });
+    },
+  });
+}
@@ -3,23 +3,34 @@ import { apiFetch } from "@/lib/api";
 import type {
   AgentSession,
   SessionDetail,
+  SessionExpand,
   SessionStatus,
   SyncSessionRequest,
   SyncSessionResponse,
   MergeSessionRequest,
   MergeSessionResponse,
+  DeleteWorktreeRequest,
+  WorktreeCleanup,
   DiscoverWorktreesResponse,
   CloseCheckResponse,
   ReactivateResponse,
+  FileListResponse,
+  FileContentResponse,
+  FocusSessionResponse,
 } from "@/lib/types";
 
-export function useSessions(projectId?: string, status?: SessionStatus[]) {
+export function useSessions(
+  projectId?: string,
+  status?: SessionStatus[],
+  expand?: SessionExpand[],
+) {
   const params = new URLSearchParams();
   if (projectId) params.set("project_id", projectId);
   if (status?.length) params.set("status", status.join(","));
+  if (expand?.length) params.set("expand", expand.join(","));
   const qs = params.toString();
   return useQuery({
-    queryKey: ["sessions", projectId ?? "", status?.join(",") ?? ""],
+    queryKey: ["sessions", projectId ?? "", status?.join(",") ?? "", expand?.join(",") ?? ""],
     queryFn: () => apiFetch<AgentSession[]>(`/api/v1/sessions${qs ? `?${qs}` : ""}`),
     refetchInterval: 30000,
   });
@@ -68,9 +79,10 @@ export function useMergeSession() {
 export function useDeleteWorktree() {
   const qc = useQueryClient();
   return useMutation({
-    mutationFn: ({ sessionId, force }: { sessionId: string; force?: boolean }) =>
-      apiFetch<void>(`/api/v1/sessions/${sessionId}/worktree${force ? "?force=true" : ""}`, {
+    mutationFn: ({ sessionId, ...opts }: { sessionId: string } & DeleteWorktreeRequest) =>
+      apiFetch<WorktreeCleanup>(`/api/v1/sessions/${sessionId}/worktree`, {
         method: "DELETE",
+        body: JSON.stringify(opts),
       }),
     onSuccess: () => {
       qc.invalidateQueries({ queryKey: ["sessions"] });
@@ -117,6 +129,28 @@ export function useCloseCheck(sessionId: string, enabled: boolean) {
   });
 }
 
+export function useSessionFiles(sessionId: string, path: string, enabled = true) {
+  return useQuery({
+    queryKey: ["session-files", sessionId, path],
+    queryFn: () =>
+      apiFetch<FileListResponse>(
+        `/api/v1/sessions/${sessionId}/files?path=${encodeURIComponent(path)}`,
+      ),
+    enabled: enabled && !!sessionId,
+  });
+}
+
+export function useSessionFile(sessionId: string, path: string | null) {
+  return useQuery({
+    queryKey: ["session-file", sessionId, path],
+    queryFn: () =>
+      apiFetch<FileContentResponse>(
+        `/api/v1/sessions/${sessionId}/file?path=${encodeURIComponent(path ?? "")}`,
+      ),
+    enabled: !!sessionId && !!path,
+  });
+}
+
 export function useReactivateSession() {
   const qc = useQueryClient();
   return useMutation({
@@ -131,3 +165,12 @@ export function useReactivateSession() {
     },
   });
 }
+
+export function useFocusSession() {
+  return useMutation({
+    mutationFn: (sessionId: string) =>
+      apiFetch<FocusSessionResponse>(`/api/v1/sessions/${sessionId}/focus`, {
+        method: "POST",
+      }),
+  });
+}
@@ -1,6 +1,6 @@
 import { useQuery } from "@tanstack/react-query";
 import { apiFetch } from "@/lib/api";
-import type { StatusEntry, HealthScore } from "@/lib/types";
+import type { StatusEntry, HealthScore, SLAReport, Capabilities } from "@/lib/types";
 
 export function useStatusOverview() {
   return useQuery({
@@ -24,3 +24,18 @@ export function useProjectHealth(id: string) {
     enabled: !!id,
   });
 }
+
+export function useSLAReport() {
+  return useQuery({
+    queryKey: ["reports", "sla"],
+    queryFn: () => apiFetch<SLAReport>("/api/v1/reports/sla"),
+  });
+}
+
+export function useCapabilities() {
+  return useQuery({
+    queryKey: ["capabilities"],
+    queryFn: () => apiFetch<Capabilities>("/api/v1/capabilities"),
+    staleTime: Infinity,
+  });
+}
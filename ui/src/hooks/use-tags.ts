@@ -1,10 +1,10 @@
 import { useQuery } from "@tanstack/react-query";
 import { apiFetch } from "@/lib/api";
-import type { Tag } from "@/lib/types";
+import type { TagUsage } from "@/lib/types";
 
 export function useTags() {
   return useQuery({
     queryKey: ["tags"],
-    queryFn: () => apiFetch<Tag[]>("/api/v1/tags"),
+    queryFn: () => apiFetch<TagUsage[]>("/api/v1/tags"),
   });
 }
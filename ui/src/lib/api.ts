@@ -13,18 +13,47 @@ function getBaseURL(): string {
 
 const BASE = getBaseURL();
 
+const TOKEN_KEY = "pm-api-token";
+
+// UNAUTHORIZED_EVENT fires on window when the API refuses a request for
+// want of a token (api.require_auth).
+export const UNAUTHORIZED_EVENT = "pm:unauthorized";
+
+export function getAPIToken(): string {
+  return localStorage.getItem(TOKEN_KEY) ?? "";
+}
+
+export function setAPIToken(token: string) {
+  if (token) {
+    localStorage.setItem(TOKEN_KEY, token);
+  } else {
+    localStorage.removeItem(TOKEN_KEY);
+  }
+}
+
 export async function apiFetch<T>(
   path: string,
   init?: RequestInit,
 ): Promise<T> {
   const url = `${BASE}${path}`;
+  const token = getAPIToken();
+  // Review shares authenticate by their own token; a stale API token would
+  // only get them refused.
+  const auth: Record<string, string> =
+    token && !path.startsWith("/api/v1/review-shares/")
+      ? { Authorization: `Bearer ${token}` }
+      : {};
   const res = await fetch(url, {
     ...init,
     headers: {
       "Content-Type": "application/json",
+      ...auth,
       ...init?.headers,
     },
   });
+  if (res.status === 401) {
+    window.dispatchEvent(new Event(UNAUTHORIZED_EVENT));
+  }
   if (!res.ok) {
     const body = await res.text();
     throw new Error(`${res.status}: ${body}`);
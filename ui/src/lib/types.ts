@@ -3,10 +3,12 @@
 export interface Project {
   ID: string;
   Name: string;
+  Key: string;
   Path: string;
   Description: string;
   RepoURL: string;
   Language: string;
+  Languages: LanguageShare[] | null;
   GroupName: string;
   BranchCount: number;
   HasGitHubPages: boolean;
@@ -14,16 +16,37 @@ export interface Project {
   BuildCmd: string;
   ServeCmd: string;
   ServePort: number;
+  Conventions: string;
+  MaxInProgress: number;
+  MaxActiveSessions: number;
+  WIPHardBlock: boolean;
+  CommitStyle: string;
+  CommitTicketPrefix: string;
+  RefreshSkip: string;
+  SparsePaths: string;
+  MergeStrategy: MergeStrategy | "";
+  Cascade: string;
+  GitHubBoard: string;
+  UIReviewPaths: string;
+  Archived: boolean;
   CreatedAt: string;
   UpdatedAt: string;
 }
 
-export type IssueStatus = "open" | "in_progress" | "done" | "closed";
+export interface LanguageShare {
+  language: string;
+  lines: number;
+  percent: number;
+}
+
+export type IssueStatus = "open" | "in_progress" | "in_review" | "done" | "closed";
 export type IssuePriority = "low" | "medium" | "high";
 export type IssueType = "feature" | "bug" | "chore";
+export type SLAState = "" | "ok" | "at_risk" | "breached";
 
 export interface Issue {
   ID: string;
+  Code: string;
   ProjectID: string;
   Title: string;
   Description: string;
@@ -34,16 +57,57 @@ export interface Issue {
   Type: IssueType;
   Tags: string[] | null;
   GitHubIssue: number;
+  Assignee: string;
+  Estimate: string;
+  Rank: string;
+  SLAState: SLAState;
   CreatedAt: string;
   UpdatedAt: string;
   ClosedAt: string | null;
+  StartedAt: string | null;
+  CompletedAt: string | null;
+  MergeCommit: string;
+  MergeBranch: string;
+  MergedAt: string | null;
+  AcceptanceCriteria: string[] | null;
+  Readiness: IssueReadiness | null;
+}
+
+export interface RenderedIssue {
+  issue_id: string;
+  title: string;
+  description_html: string;
+  body_html: string;
+  highlight_css?: string;
+}
+
+export type ReadinessCheckName = "body" | "ai_prompt" | "acceptance_criteria" | "no_open_blockers" | "estimate";
+
+export interface ReadinessCheck {
+  Name: ReadinessCheckName;
+  Passed: boolean;
+  Hint: string;
+}
+
+export interface IssueReadiness {
+  Score: number;
+  Ready: boolean;
+  Checks: ReadinessCheck[];
 }
 
-export type SessionStatus = "active" | "idle" | "completed" | "abandoned";
+export type SessionStatus = "active" | "idle" | "blocked" | "completed" | "abandoned";
 export type ConflictState = "none" | "sync_conflict" | "merge_conflict";
 
+export type OutcomeClass =
+  | "clean_success"
+  | "success_after_conflicts"
+  | "abandoned_no_commits"
+  | "abandoned"
+  | "review_failed";
+
 export interface AgentSession {
   ID: string;
+  Code: string;
   ProjectID: string;
   IssueID: string;
   Branch: string;
@@ -62,6 +126,48 @@ export interface AgentSession {
   ConflictState: ConflictState;
   ConflictFiles: string;
   Discovered: boolean;
+  Inline: boolean;
+  SparsePaths: string;
+  Labels: string;
+  ExtraIssueIDs: string;
+  MergeCommit: string;
+  OutcomeClass: OutcomeClass | "";
+  FocusSeconds: number;
+  ReviewAttempt: number;
+  ReviewID: string;
+  WorktreeFingerprint: string;
+  TerminalBackend: string;
+  TerminalID: string;
+  BlockedReason: string;
+  UnblockCondition: string;
+  BlockedAt: string | null;
+  BlockedOverdue?: boolean;
+  PendingStatus?: SessionStatus;
+  Git?: SessionGit;
+  CloseCheck?: CloseCheckResponse;
+  ExpandErrors?: Partial<Record<SessionExpand, string>>;
+}
+
+export type SessionExpand = "git" | "close_check";
+
+export interface SessionGit {
+  WorktreeExists: boolean;
+  IsDirty?: boolean;
+  CurrentBranch?: string;
+  AheadCount?: number;
+  BehindCount?: number;
+  LastCommitHash?: string;
+  LastCommitMessage?: string;
+}
+
+export interface SessionLabelStats {
+  label: string;
+  sessions: number;
+  open: number;
+  completed: number;
+  abandoned: number;
+  commits: number;
+  avg_duration_seconds: number;
 }
 
 export interface SessionDetail extends AgentSession {
@@ -90,8 +196,11 @@ export interface SyncSessionResponse {
   Error: string;
 }
 
+export type MergeStrategy = "merge" | "rebase" | "squash" | "ff-only";
+
 export interface MergeSessionRequest {
   base_branch?: string;
+  strategy?: MergeStrategy;
   rebase?: boolean;
   create_pr?: boolean;
   pr_title?: string;
@@ -100,17 +209,37 @@ export interface MergeSessionRequest {
   force?: boolean;
   dry_run?: boolean;
   cleanup?: boolean;
+  delete_remote?: boolean;
 }
 
 export interface MergeSessionResponse {
   SessionID: string;
   Branch: string;
+  Strategy: MergeStrategy;
   Success: boolean;
+  MergeCommit: string;
   PRCreated: boolean;
   PRURL: string;
   Conflicts: string[] | null;
   Error: string;
   Cleaned: boolean;
+  Cleanup: WorktreeCleanup | null;
+  Closures: IssueClosure[] | null;
+}
+
+export interface DeleteWorktreeRequest {
+  force?: boolean;
+  delete_branch?: boolean;
+  delete_remote?: boolean;
+}
+
+export interface WorktreeCleanup {
+  worktree_removed: boolean;
+  branch: string;
+  branch_deleted: boolean;
+  upstream?: string;
+  remote_deleted: boolean;
+  kept?: string[];
 }
 
 export interface DiscoverWorktreesResponse {
@@ -123,6 +252,23 @@ export interface CloseCheckWarning {
   message: string;
 }
 
+export interface CommitViolation {
+  subject: string;
+  problems: string[];
+}
+
+export interface FileChange {
+  path: string;
+  staged: boolean;
+  modified: boolean;
+  untracked: boolean;
+  deleted?: boolean;
+  unmerged?: boolean;
+  additions: number;
+  deletions: number;
+  binary?: boolean;
+}
+
 export interface CloseCheckResponse {
   session_id: string;
   worktree_exists: boolean;
@@ -134,6 +280,55 @@ export interface CloseCheckResponse {
   base_branch: string;
   ready_to_close: boolean;
   warnings: CloseCheckWarning[];
+  commit_violations?: CommitViolation[];
+  dirty_files?: FileChange[];
+  dirty_kind?: "lockfile_only" | "source";
+}
+
+export interface ReplayEntry {
+  at: string;
+  kind: string; // started, status, sync, conflict, error, commit, review, issue
+  summary: string;
+  detail?: string;
+  cause?: string; // status entries: launch, close, reactivate, block, unblock, reconcile, merge, worktree_deleted, review, issue_lock_expired
+  problem?: boolean;
+}
+
+export interface SessionReplay {
+  session_id: string;
+  project_name: string;
+  issue_id?: string;
+  issue_title?: string;
+  branch: string;
+  status: string;
+  started_at: string;
+  ended_at?: string;
+  duration_seconds?: number;
+  entries: ReplayEntry[];
+  problems: number;
+  summary?: string;
+}
+
+export interface FileEntry {
+  name: string;
+  path: string;
+  is_dir: boolean;
+  size: number;
+  mod_time: string;
+}
+
+export interface FileListResponse {
+  path: string;
+  entries: FileEntry[];
+}
+
+export interface FileContentResponse {
+  path: string;
+  size: number;
+  binary: boolean;
+  truncated: boolean;
+  encoding: "utf-8" | "base64";
+  content: string;
 }
 
 export interface ReactivateResponse {
@@ -147,6 +342,27 @@ export interface Tag {
   CreatedAt: string;
 }
 
+export interface TagUsage extends Tag {
+  Issues: number;
+  Open: number;
+}
+
+export interface ProjectNote {
+  ID: string;
+  ProjectID: string;
+  Title: string;
+  Body: string;
+  CreatedAt: string;
+  UpdatedAt: string;
+}
+
+export interface Capabilities {
+  llm: "on" | "off";
+  forge: "github" | "none";
+  terminal: string; // "iterm", "tmux" or "none"
+  reasons: Partial<Record<"llm" | "forge" | "terminal", string>>;
+}
+
 export interface HealthScore {
   Total: number;
   GitCleanliness: number;
@@ -162,6 +378,14 @@ export interface ReleaseAsset {
   size: number;
 }
 
+export interface ReleaseFreshness {
+  fetched_at: string | null;
+  checked_at: string;
+  age_seconds: number;
+  stale: boolean;
+  error?: string;
+}
+
 // statusEntry has explicit camelCase json tags in Go
 export interface StatusEntry {
   project: Project;
@@ -175,11 +399,19 @@ export interface StatusEntry {
   releaseDate?: string;
   versionSource?: string;
   releaseAssets?: ReleaseAsset[];
+  releaseCache?: ReleaseFreshness;
+  wipWarnings?: string[];
 }
 
 export type ReviewVerdict = "pass" | "fail";
 export type ReviewCategory = "pass" | "fail" | "skip" | "na";
 
+export interface CriterionResult {
+  Criterion: string;
+  Passed: boolean;
+  Note: string;
+}
+
 export interface IssueReview {
   ID: string;
   IssueID: string;
@@ -191,14 +423,185 @@ export interface IssueReview {
   TestCoverage: ReviewCategory;
   UIUX: ReviewCategory;
   FailureReasons: string[] | null;
+  Criteria: CriterionResult[] | null;
   DiffStats: string;
+  Reviewer: string;
   ReviewedAt: string;
   CreatedAt: string;
 }
 
+export interface ReviewShare {
+  id: string;
+  issue_id: string;
+  reviewer: string;
+  expires_at: string;
+  expires_in_seconds: number;
+  expired: boolean;
+  review_id?: string;
+  created_at: string;
+  token?: string;
+  url?: string;
+}
+
+export interface SharedFileDiff {
+  file: string;
+  additions: number;
+  deletions: number;
+  bytes: number;
+}
+
+export interface SharedReview {
+  reviewer: string;
+  expires_at: string;
+  expires_in_seconds: number;
+  project: string;
+  issue: {
+    id: string;
+    title: string;
+    description: string;
+    body: string;
+    type: string;
+    priority: string;
+    status: string;
+    acceptance_criteria: string[] | null;
+  };
+  base_ref: string;
+  head_ref: string;
+  diff: string;
+  diff_truncated: boolean;
+  diff_stats: string;
+  file_diffs: SharedFileDiff[] | null;
+  reviews: IssueReview[] | null;
+  submitted?: IssueReview;
+}
+
+export interface SharedReviewSubmission {
+  verdict: ReviewVerdict;
+  summary: string;
+  reviewer?: string;
+  failure_reasons?: string[];
+  criteria?: { criterion: string; passed: boolean; note: string }[];
+}
+
+export type WatcherKind = "email" | "webhook";
+
+export interface IssueWatcher {
+  ID: string;
+  IssueID: string;
+  Kind: WatcherKind;
+  Target: string;
+  CreatedAt: string;
+}
+
+export interface IssueComment {
+  ID: string;
+  IssueID: string;
+  Author: string;
+  Body: string;
+  CreatedAt: string;
+}
+
+export type ChangeEntity = "project" | "issue" | "session" | "review" | "relation" | "comment" | "note";
+
+export interface Change {
+  Seq: number;
+  Entity: ChangeEntity;
+  EntityID: string;
+  ProjectID: string;
+  Op: "insert" | "update" | "delete";
+  At: string;
+}
+
+export interface ChangesResponse {
+  changes: Change[];
+  cursor: number;
+  has_more: boolean;
+  reset: boolean;
+}
+
+export type RelationType = "relates_to" | "duplicates" | "caused_by" | "blocks" | "split_from";
+
+export interface IssueRelation {
+  id: string;
+  type: RelationType;
+  label: string;
+  direction: "outgoing" | "incoming";
+  issue_id: string;
+  title: string;
+  status: IssueStatus;
+  project_id: string;
+  created_at: string;
+}
+
+export interface IssueClosure {
+  ID: string;
+  IssueID: string;
+  ProjectID: string;
+  SessionID: string;
+  Source: "commit" | "pr";
+  Ref: string;
+  Message: string;
+  FromStatus: IssueStatus;
+  ToStatus: IssueStatus;
+  GitHubIssue: number;
+  CreatedAt: string;
+  RevertedAt: string | null;
+}
+
+export interface PullRequestRef {
+  number: number;
+  title: string;
+  state: string;
+  headRefName: string;
+  url: string;
+}
+
+export interface CodeCommit {
+  hash: string;
+  date: string;
+  subject: string;
+}
+
+export interface BranchCode {
+  session_id: string;
+  branch: string;
+  status: SessionStatus;
+  started_at: string;
+  ended_at?: string;
+  commits: CodeCommit[];
+  merge_commit?: string;
+  merged_range?: string;
+  releases?: string[];
+  pull_requests?: PullRequestRef[];
+}
+
+export interface IssueCode {
+  issue_id: string;
+  issue_title: string;
+  issue_status: IssueStatus;
+  project_name: string;
+  branches: BranchCode[];
+  pull_requests: PullRequestRef[];
+  closures: IssueClosure[];
+  shipped_in?: string;
+}
+
+export interface PushKey {
+  public_key: string;
+}
+
+export interface AutoCloseRequest {
+  limit?: number;
+  dry_run?: boolean;
+}
+
 export interface LaunchAgentRequest {
   issue_ids: string[];
   project_id: string;
+  spawn?: boolean;
+  sparse?: boolean;
+  sparse_paths?: string[];
+  labels?: string[];
 }
 
 export interface LaunchAgentResponse {
@@ -206,11 +609,63 @@ export interface LaunchAgentResponse {
   branch: string;
   worktree_path: string;
   command: string;
+  conventions?: string;
+  wip_warnings?: string[];
+  co_edit?: CoEditCheck;
+  sparse_paths?: string[];
+  terminal?: TerminalResult;
+  spawn_error?: string;
+}
+
+export interface LaunchPlanResponse {
+  branch: string;
+  worktree_path: string;
+  resume: boolean;
+  resume_session_id?: string;
+  purge_session_ids: string[];
+  start_issue_ids: string[];
+  command: string;
+  sparse_paths?: string[];
+  wip_warnings?: string[];
+}
+
+export interface NextAgentRequest {
+  project_id?: string;
+  assignee?: string;
+  spawn?: boolean;
+}
+
+export interface NextAgentResponse extends LaunchAgentResponse {
+  project_id: string;
+  project_name: string;
+  issue_id: string;
+  issue_title: string;
+  score: number;
+  reasons: string[];
+}
+
+export interface TerminalResult {
+  backend: "iterm" | "tmux";
+  id: string;
+}
+
+export interface FocusSessionResponse {
+  session_id: string;
+  terminal: TerminalResult;
+}
+
+export interface CoEditCheck {
+  changed: boolean;
+  dirty: boolean;
+  warning?: string;
+  snapshot_commit?: string;
 }
 
 export interface CloseAgentRequest {
   session_id: string;
-  status?: "idle" | "completed" | "abandoned";
+  status?: "idle" | "completed" | "abandoned" | "blocked";
+  reason?: string;
+  unblock_condition?: string;
 }
 
 export interface CloseAgentResponse {
@@ -218,3 +673,31 @@ export interface CloseAgentResponse {
   status: string;
   ended_at?: string;
 }
+
+export interface SLAIssue {
+  issue_id: string;
+  project: string;
+  title: string;
+  priority: IssuePriority;
+  type: IssueType;
+  policy: string;
+  state: SLAState;
+  missed?: ("start" | "complete")[];
+  age_days: number;
+}
+
+export interface SLAReport {
+  generated_at: string;
+  policies: { name: string; issues: number; ok: number; at_risk: number; breached: number }[] | null;
+  breached: SLAIssue[];
+  at_risk: SLAIssue[];
+  new_breaches: number;
+  recent_breaches: {
+    issue_id: string;
+    project: string;
+    title: string;
+    policy: string;
+    kind: "start" | "complete";
+    breached_at: string;
+  }[];
+}